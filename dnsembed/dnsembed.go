@@ -0,0 +1,207 @@
+// Package dnsembed is the API for embedding the DNS request processing of
+// AdGuard DNS, that is the filtering, cache, and forwarding middlewares, into
+// other DNS servers written in Go.  Profiles, the backend, and other AdGuard
+// DNS-specific services aren't used.
+//
+// The types of this package are aliases of the types from the internal packages
+// of AdGuard DNS, which allows using them outside of this module.
+//
+// NOTE:  This API is experimental.  Since the aliased types follow the changes
+// in the internal packages, it may change in incompatible ways between any
+// releases.
+package dnsembed
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/ratelimit"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/filterstorage"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+)
+
+// Config is the configuration of the embedded DNS handler.  See [NewHandler].
+type Config = dnssvc.EmbeddedConfig
+
+// CacheConfig is the configuration of the DNS cache.
+type CacheConfig = dnssvc.CacheConfig
+
+// CacheType is the type of the cache to use.
+type CacheType = dnssvc.CacheType
+
+// CacheType constants.
+const (
+	CacheTypeNone   = dnssvc.CacheTypeNone
+	CacheTypeSimple = dnssvc.CacheTypeSimple
+	CacheTypeECS    = dnssvc.CacheTypeECS
+)
+
+// DefaultFilteredResponseTTL is the TTL of the filtered responses, if
+// [Config.Messages] is nil.
+const DefaultFilteredResponseTTL = dnssvc.EmbeddedDefaultFilteredResponseTTL
+
+// Handler is the interface of a DNS handler.
+type Handler = dnsserver.Handler
+
+// HandlerFunc is a function that implements the [Handler] interface.
+type HandlerFunc = dnsserver.HandlerFunc
+
+// ResponseWriter is the interface of the writer of the DNS responses.
+type ResponseWriter = dnsserver.ResponseWriter
+
+// Protocol is the DNS protocol of the front end that serves the queries.
+type Protocol = agd.Protocol
+
+// Protocol value constants.
+const (
+	ProtoDNS      = agd.ProtoDNS
+	ProtoDoH      = agd.ProtoDoH
+	ProtoDoQ      = agd.ProtoDoQ
+	ProtoDoT      = agd.ProtoDoT
+	ProtoDNSCrypt = agd.ProtoDNSCrypt
+)
+
+// ServerInfo is the information about the server that serves the query.
+type ServerInfo = dnsserver.ServerInfo
+
+// RequestInfo is the information about the DNS request.
+type RequestInfo = dnsserver.RequestInfo
+
+// ContextWithServerInfo returns a new context with the server information.
+// The context passed to the ServeDNS method of the handler returned by
+// [NewHandler] must contain it.
+func ContextWithServerInfo(parent context.Context, si *ServerInfo) (ctx context.Context) {
+	return dnsserver.ContextWithServerInfo(parent, si)
+}
+
+// ContextWithRequestInfo returns a new context with the request information.
+// The context passed to the ServeDNS method of the handler returned by
+// [NewHandler] must contain it.
+func ContextWithRequestInfo(parent context.Context, ri *RequestInfo) (ctx context.Context) {
+	return dnsserver.ContextWithRequestInfo(parent, ri)
+}
+
+// FilteringGroup is the set of filtering settings applied to all queries.
+type FilteringGroup = agd.FilteringGroup
+
+// FilteringGroupID is the ID of a filtering group.
+type FilteringGroupID = agd.FilteringGroupID
+
+// FilterConfigGroup is the filtering configuration of a [FilteringGroup].
+type FilterConfigGroup = filter.ConfigGroup
+
+// FilterConfigParental is the configuration of the parental-protection
+// filtering.
+type FilterConfigParental = filter.ConfigParental
+
+// FilterConfigRuleList is the configuration of the rule-list filtering.
+type FilterConfigRuleList = filter.ConfigRuleList
+
+// FilterConfigSafeBrowsing is the configuration of the safe-browsing
+// filtering.
+type FilterConfigSafeBrowsing = filter.ConfigSafeBrowsing
+
+// FilterStorage is the interface of the storage of the filters.
+type FilterStorage = filter.Storage
+
+// FilterConfig is the configuration of a filter returned by a [FilterStorage].
+type FilterConfig = filter.Config
+
+// FilterID is the ID of a filter list.
+type FilterID = filter.ID
+
+// Filter is the interface of a DNS request and response filter.
+type Filter = filter.Interface
+
+// EmptyFilter is a [Filter] implementation that never filters anything.
+type EmptyFilter = filter.Empty
+
+// FilterRequest contains information about a request being filtered.
+type FilterRequest = filter.Request
+
+// FilterResponse contains information about a response being filtered.
+type FilterResponse = filter.Response
+
+// FilterResult is the result of filtering.  Custom [Filter] implementations
+// must return one of the following types:
+//
+//   - [*FilterResultAllowed]
+//   - [*FilterResultBlocked]
+//   - [*FilterResultModifiedResponse]
+//   - [*FilterResultModifiedRequest]
+type FilterResult = filter.Result
+
+// FilterResultAllowed means that the request or response was allowed by an
+// allowlist rule.
+type FilterResultAllowed = filter.ResultAllowed
+
+// FilterResultBlocked means that the request or response was blocked by a
+// blocklist rule.
+type FilterResultBlocked = filter.ResultBlocked
+
+// FilterResultModifiedResponse means that the response was rewritten or
+// modified by a rewrite rule.
+type FilterResultModifiedResponse = filter.ResultModifiedResponse
+
+// FilterResultModifiedRequest means that the request was modified by a rewrite
+// rule.
+type FilterResultModifiedRequest = filter.ResultModifiedRequest
+
+// FilterRuleText is the text of a single filtering rule.
+type FilterRuleText = filter.RuleText
+
+// FilterIDCustom is the ID of the filter list used in the results of the
+// filters returned by [NewRuleListFilter].
+const FilterIDCustom = filter.IDCustom
+
+// NewFilterID converts s into a [FilterID] and makes sure that it's valid.
+func NewFilterID(s string) (id FilterID, err error) { return filter.NewID(s) }
+
+// NewFilterRuleText converts s into a [FilterRuleText] and makes sure that it's
+// valid.
+func NewFilterRuleText(s string) (text FilterRuleText, err error) {
+	return filter.NewRuleText(s)
+}
+
+// NewRuleListFilter returns a new [Filter] that filters requests and responses
+// using the rules from text, which must be in the AdGuard DNS filtering-rule
+// syntax.  The results of the filter have [FilterIDCustom] as their list ID.
+// cacheCount is the number of cached results; if it is zero or less, the
+// results are not cached.
+func NewRuleListFilter(text string, cacheCount int) (f Filter, err error) {
+	return filterstorage.NewRuleListFilter(text, cacheCount)
+}
+
+// NewStaticFilterStorage returns a new [FilterStorage] that returns f for every
+// filtering configuration.  f must not be nil.
+func NewStaticFilterStorage(f Filter) (s FilterStorage) {
+	return filterstorage.NewStatic(f)
+}
+
+// ErrorCollector is the interface for the collection of the errors.
+type ErrorCollector = errcoll.Interface
+
+// GeoIP is the interface of the GeoIP database.
+type GeoIP = geoip.Interface
+
+// MessageConstructor is the constructor of the DNS messages, such as the
+// responses to the blocked requests.
+type MessageConstructor = dnsmsg.Constructor
+
+// RateLimit is the interface of the rate limiter.
+type RateLimit = ratelimit.Interface
+
+// NewHandler returns the DNS handler with the filtering, cache, and forwarding
+// middlewares that uses c.Handler as the ultimate handler.  c must not be nil.
+//
+// The context passed to the ServeDNS method of the returned handler must
+// contain the [RequestInfo] and the [ServerInfo], see [ContextWithRequestInfo]
+// and [ContextWithServerInfo].
+func NewHandler(ctx context.Context, c *Config) (h Handler, err error) {
+	return dnssvc.NewEmbeddedHandler(ctx, c)
+}
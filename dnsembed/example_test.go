@@ -0,0 +1,124 @@
+package dnsembed_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/dnsembed"
+	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// errorCollector is a [dnsembed.ErrorCollector] that prints the errors.
+type errorCollector struct{}
+
+// Collect implements the [dnsembed.ErrorCollector] interface for
+// errorCollector.
+func (errorCollector) Collect(_ context.Context, err error) { fmt.Println("error:", err) }
+
+// responseWriter is a [dnsembed.ResponseWriter] that keeps the response.
+type responseWriter struct {
+	resp *dns.Msg
+}
+
+// LocalAddr implements the [dnsembed.ResponseWriter] interface for
+// *responseWriter.
+func (rw *responseWriter) LocalAddr() (a net.Addr) {
+	return &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 53}
+}
+
+// RemoteAddr implements the [dnsembed.ResponseWriter] interface for
+// *responseWriter.
+func (rw *responseWriter) RemoteAddr() (a net.Addr) {
+	return &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 12345}
+}
+
+// WriteMsg implements the [dnsembed.ResponseWriter] interface for
+// *responseWriter.
+func (rw *responseWriter) WriteMsg(_ context.Context, _, resp *dns.Msg) (err error) {
+	rw.resp = resp
+
+	return nil
+}
+
+func ExampleNewHandler() {
+	// Use a handler that answers all A queries with the same address instead
+	// of a forwarding one.
+	upstream := dnsembed.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsembed.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		resp := (&dns.Msg{}).SetReply(req)
+		resp.Answer = append(resp.Answer, &dns.A{
+			Hdr: dns.RR_Header{
+				Name:   req.Question[0].Name,
+				Rrtype: dns.TypeA,
+				Class:  dns.ClassINET,
+				Ttl:    60,
+			},
+			A: net.IP{192, 0, 2, 1},
+		})
+
+		return rw.WriteMsg(ctx, req, resp)
+	})
+
+	// Block a domain using a rule-list filter.
+	flt, err := dnsembed.NewRuleListFilter("||blocked.example^\n", 0)
+	if err != nil {
+		panic(err)
+	}
+
+	ctx := context.Background()
+	h, err := dnsembed.NewHandler(ctx, &dnsembed.Config{
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+		Cache: &dnsembed.CacheConfig{
+			NoECSCount: 1_000,
+			Type:       dnsembed.CacheTypeSimple,
+		},
+		ErrColl:              errorCollector{},
+		FilterStorage:        dnsembed.NewStaticFilterStorage(flt),
+		Handler:              upstream,
+		PrometheusRegisterer: prometheus.NewRegistry(),
+		FilteringGroup: &dnsembed.FilteringGroup{
+			FilterConfig: &dnsembed.FilterConfigGroup{
+				Parental:     &dnsembed.FilterConfigParental{},
+				RuleList:     &dnsembed.FilterConfigRuleList{},
+				SafeBrowsing: &dnsembed.FilterConfigSafeBrowsing{},
+			},
+			ID: "default",
+		},
+		MetricsNamespace: "example",
+		Protocol:         dnsembed.ProtoDNS,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	ctx = dnsembed.ContextWithServerInfo(ctx, &dnsembed.ServerInfo{
+		Name:  "example",
+		Proto: dnsembed.ProtoDNS,
+	})
+	ctx = dnsembed.ContextWithRequestInfo(ctx, &dnsembed.RequestInfo{
+		StartTime: time.Now(),
+	})
+
+	for _, name := range []string{"www.example.com.", "blocked.example."} {
+		req := (&dns.Msg{}).SetQuestion(name, dns.TypeA)
+		rw := &responseWriter{}
+		err = h.ServeDNS(ctx, rw, req)
+		if err != nil {
+			panic(err)
+		}
+
+		fmt.Println(name, dns.RcodeToString[rw.resp.Rcode], rw.resp.Answer[0].(*dns.A).A)
+	}
+
+	// Output:
+	// www.example.com. NOERROR 192.0.2.1
+	// blocked.example. NOERROR 0.0.0.0
+}
@@ -27,17 +27,41 @@ type Interface interface {
 	IsBlockedIP(ip netip.Addr) (blocked bool)
 }
 
+// type check
+var _ Interface = Empty{}
+
+// Empty is an [Interface] implementation that never blocks anything.
+type Empty struct{}
+
+// IsBlockedHost implements the [Interface] interface for Empty.  blocked is
+// always false.
+func (Empty) IsBlockedHost(_ string, _ uint16) (blocked bool) { return false }
+
+// IsBlockedIP implements the [Interface] interface for Empty.  blocked is
+// always false.
+func (Empty) IsBlockedIP(_ netip.Addr) (blocked bool) { return false }
+
 // Global controls IP and client blocking that takes place before all other
 // processing.  Global is safe for concurrent use.
 type Global struct {
 	blockedHostsEng *urlfilter.DNSEngine
 	blockedNets     netutil.SubnetSet
+
+	// standard, if not nil, contains the standard access rules from the
+	// backend, which are checked in addition to the blocked domains.
+	standard *StandardBlocker
 }
 
-// NewGlobal create a new Global from provided parameters.
-func NewGlobal(blockedDomains []string, blockedSubnets []netip.Prefix) (g *Global, err error) {
+// NewGlobal create a new Global from provided parameters.  standard may be nil,
+// in which case only blockedDomains and blockedSubnets are checked.
+func NewGlobal(
+	blockedDomains []string,
+	blockedSubnets []netip.Prefix,
+	standard *StandardBlocker,
+) (g *Global, err error) {
 	g = &Global{
 		blockedNets: netutil.SliceSubnetSet(blockedSubnets),
+		standard:    standard,
 	}
 
 	b := &strings.Builder{}
@@ -75,9 +99,11 @@ func (g *Global) IsBlockedHost(host string, qt uint16) (blocked bool) {
 
 	if matched && res.NetworkRule != nil {
 		return !res.NetworkRule.Whitelist
+	} else if matched {
+		return true
 	}
 
-	return matched
+	return g.standard != nil && g.standard.IsBlockedHost(host, qt)
 }
 
 // IsBlockedIP implements the [Interface] interface for *Global.
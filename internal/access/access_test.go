@@ -17,7 +17,7 @@ func TestGlobal_IsBlockedHost(t *testing.T) {
 		"||block_aaaa.test^$dnstype=AAAA",
 		"||allowlist.test^",
 		"@@||allow.allowlist.test^",
-	}, nil)
+	}, nil, nil)
 	require.NoError(t, err)
 
 	testCases := []struct {
@@ -79,7 +79,7 @@ func TestGlobal_IsBlockedIP(t *testing.T) {
 	global, err := access.NewGlobal([]string{}, []netip.Prefix{
 		netip.MustParsePrefix("1.1.1.1/32"),
 		netip.MustParsePrefix("2.2.2.0/8"),
-	})
+	}, nil)
 	require.NoError(t, err)
 
 	testCases := []struct {
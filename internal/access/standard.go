@@ -0,0 +1,256 @@
+package access
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/golibs/stringutil"
+	"github.com/AdguardTeam/urlfilter"
+	"github.com/AdguardTeam/urlfilter/filterlist"
+)
+
+// StandardRule is a domain blocklist rule of the standard access settings,
+// which apply to all requests, with an optional validity window.
+type StandardRule struct {
+	// Start is the time since which the rule is in effect.  If it is zero, the
+	// rule is in effect since it has been added.
+	Start time.Time
+
+	// End is the time since which the rule is no longer in effect.  The rule
+	// is removed automatically once it has ended.  If it is zero, the rule
+	// never ends.
+	End time.Time
+
+	// ID is the unique ID of the rule.  It must not be empty.
+	ID string
+
+	// Text is the AdBlock-style rule.  It must not be empty.
+	Text string
+}
+
+// isActive returns true if the rule is in effect at now.
+func (r *StandardRule) isActive(now time.Time) (ok bool) {
+	return !now.Before(r.Start) && !r.isEnded(now)
+}
+
+// isEnded returns true if the rule is no longer in effect at now and will never
+// be in effect again.
+func (r *StandardRule) isEnded(now time.Time) (ok bool) {
+	return !r.End.IsZero() && !now.Before(r.End)
+}
+
+// StandardBlockerConfig is the configuration structure for
+// [NewStandardBlocker].  All fields must not be empty.
+type StandardBlockerConfig struct {
+	// Logger is used to log the changes of the rules.
+	Logger *slog.Logger
+
+	// Clock is used to check the validity windows of the rules.
+	Clock agdtime.Clock
+}
+
+// StandardBlocker is the [Interface] implementation that blocks the requests
+// for the domains matching the standard access rules, which are updated from
+// the backend.  The rules are only in effect within their validity windows,
+// which are checked on each update and on each call to
+// [StandardBlocker.Refresh].  StandardBlocker is safe for concurrent use.
+type StandardBlocker struct {
+	logger *slog.Logger
+	clock  agdtime.Clock
+
+	// engineMu protects engine.
+	engineMu *sync.RWMutex
+
+	// engine matches the hosts against the rules currently in effect.  It is
+	// nil if there are no such rules.
+	engine *urlfilter.DNSEngine
+
+	// rulesMu protects rules and nextChange.
+	rulesMu *sync.Mutex
+
+	// rules are all known rules by their IDs, including the ones that aren't
+	// in effect yet.
+	rules map[string]*StandardRule
+
+	// nextChange is the earliest time at which a rule starts or ends.  It is
+	// zero if no rules are going to start or end.
+	nextChange time.Time
+}
+
+// NewStandardBlocker returns a new properly initialized *StandardBlocker
+// without any rules.  c must be valid.
+func NewStandardBlocker(c *StandardBlockerConfig) (b *StandardBlocker) {
+	return &StandardBlocker{
+		logger:   c.Logger,
+		clock:    c.Clock,
+		engineMu: &sync.RWMutex{},
+		rulesMu:  &sync.Mutex{},
+		rules:    map[string]*StandardRule{},
+	}
+}
+
+// type check
+var _ Interface = (*StandardBlocker)(nil)
+
+// IsBlockedHost implements the [Interface] interface for *StandardBlocker.
+func (b *StandardBlocker) IsBlockedHost(host string, qt uint16) (blocked bool) {
+	b.engineMu.RLock()
+	defer b.engineMu.RUnlock()
+
+	if b.engine == nil {
+		return false
+	}
+
+	res, matched := b.engine.MatchRequest(&urlfilter.DNSRequest{
+		Hostname: host,
+		DNSType:  qt,
+	})
+
+	if matched && res.NetworkRule != nil {
+		return !res.NetworkRule.Whitelist
+	}
+
+	return matched
+}
+
+// IsBlockedIP implements the [Interface] interface for *StandardBlocker.
+// blocked is always false, since the standard access rules only match domains.
+func (b *StandardBlocker) IsBlockedIP(_ netip.Addr) (blocked bool) { return false }
+
+// Update updates the rules.  If isFull is true, rules replace all the current
+// rules.  Otherwise, rules are added to the current ones, replacing the rules
+// with the same IDs, and the rules with IDs from deleted are removed.  The
+// items of rules must not be nil and must not be modified after calling
+// Update.
+func (b *StandardBlocker) Update(
+	ctx context.Context,
+	rules []*StandardRule,
+	deleted []string,
+	isFull bool,
+) {
+	b.rulesMu.Lock()
+	defer b.rulesMu.Unlock()
+
+	if isFull {
+		clear(b.rules)
+	}
+
+	for _, id := range deleted {
+		delete(b.rules, id)
+	}
+
+	for _, r := range rules {
+		b.rules[r.ID] = r
+	}
+
+	b.logger.DebugContext(
+		ctx,
+		"updated rules",
+		"is_full", isFull,
+		"updated", len(rules),
+		"deleted", len(deleted),
+	)
+
+	b.rebuild(ctx, b.clock.Now())
+}
+
+// Refresh puts the rules that have started into effect and removes the rules
+// that have ended.  It is intended to be called periodically, for example by a
+// refresh worker.  err is always nil.
+func (b *StandardBlocker) Refresh(ctx context.Context) (err error) {
+	b.rulesMu.Lock()
+	defer b.rulesMu.Unlock()
+
+	now := b.clock.Now()
+	if b.nextChange.IsZero() || now.Before(b.nextChange) {
+		return nil
+	}
+
+	b.rebuild(ctx, now)
+
+	return nil
+}
+
+// rebuild removes the ended rules, recalculates the time of the next change,
+// and replaces the engine with the one containing the rules in effect at now.
+// b.rulesMu must be locked.
+func (b *StandardBlocker) rebuild(ctx context.Context, now time.Time) {
+	b.nextChange = time.Time{}
+
+	var active []string
+	for _, id := range slices.Sorted(maps.Keys(b.rules)) {
+		r := b.rules[id]
+		if r.isEnded(now) {
+			delete(b.rules, id)
+
+			continue
+		}
+
+		if r.isActive(now) {
+			active = append(active, r.Text)
+			b.updateNextChange(r.End)
+		} else {
+			b.updateNextChange(r.Start)
+		}
+	}
+
+	eng := newStandardEngine(active)
+
+	b.engineMu.Lock()
+	defer b.engineMu.Unlock()
+
+	b.engine = eng
+
+	b.logger.InfoContext(
+		ctx,
+		"rebuilt engine",
+		"active", len(active),
+		"total", len(b.rules),
+		"next_change", b.nextChange,
+	)
+}
+
+// updateNextChange sets the time of the next change to t if t is non-zero and
+// earlier than the current one.  b.rulesMu must be locked.
+func (b *StandardBlocker) updateNextChange(t time.Time) {
+	if !t.IsZero() && (b.nextChange.IsZero() || t.Before(b.nextChange)) {
+		b.nextChange = t
+	}
+}
+
+// newStandardEngine returns a new DNS engine for rules.  eng is nil if there
+// are no rules.
+func newStandardEngine(rules []string) (eng *urlfilter.DNSEngine) {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	b := &strings.Builder{}
+	for _, r := range rules {
+		stringutil.WriteToBuilder(b, strings.ToLower(r), "\n")
+	}
+
+	lists := []filterlist.RuleList{
+		&filterlist.StringRuleList{
+			ID:             blocklistFilterID,
+			RulesText:      b.String(),
+			IgnoreCosmetic: true,
+		},
+	}
+
+	rulesStrg, err := filterlist.NewRuleStorage(lists)
+	if err != nil {
+		// Should never happen, since the storage has only one list.
+		panic(fmt.Errorf("unexpected standard access error: %w", err))
+	}
+
+	return urlfilter.NewDNSEngine(rulesStrg)
+}
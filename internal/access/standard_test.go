@@ -0,0 +1,129 @@
+package access_test
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/access"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// newStandardBlocker is a helper that returns a new *access.StandardBlocker for
+// tests.  now is the pointer to the current time of the clock.
+func newStandardBlocker(tb testing.TB, now *time.Time) (b *access.StandardBlocker) {
+	tb.Helper()
+
+	return access.NewStandardBlocker(&access.StandardBlockerConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		Clock: &agdtest.Clock{
+			OnNow: func() (n time.Time) { return *now },
+		},
+	})
+}
+
+func TestStandardBlocker_Update(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	b := newStandardBlocker(t, &now)
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	assert.False(t, b.IsBlockedHost("first.test", dns.TypeA))
+
+	b.Update(ctx, []*access.StandardRule{{
+		ID:   "1",
+		Text: "||first.test^",
+	}, {
+		ID:   "2",
+		Text: "||Second.test^",
+	}}, nil, true)
+
+	assert.True(t, b.IsBlockedHost("first.test", dns.TypeA))
+	assert.True(t, b.IsBlockedHost("second.test", dns.TypeA))
+	assert.False(t, b.IsBlockedIP(netip.MustParseAddr("192.0.2.1")))
+
+	// An incremental update only changes the given rules.
+	b.Update(ctx, []*access.StandardRule{{
+		ID:   "3",
+		Text: "||third.test^",
+	}}, []string{"1"}, false)
+
+	assert.False(t, b.IsBlockedHost("first.test", dns.TypeA))
+	assert.True(t, b.IsBlockedHost("second.test", dns.TypeA))
+	assert.True(t, b.IsBlockedHost("third.test", dns.TypeA))
+
+	// A full update replaces all rules.
+	b.Update(ctx, []*access.StandardRule{{
+		ID:   "1",
+		Text: "||first.test^",
+	}}, nil, true)
+
+	assert.True(t, b.IsBlockedHost("first.test", dns.TypeA))
+	assert.False(t, b.IsBlockedHost("second.test", dns.TypeA))
+	assert.False(t, b.IsBlockedHost("third.test", dns.TypeA))
+}
+
+func TestStandardBlocker_Refresh(t *testing.T) {
+	t.Parallel()
+
+	const (
+		hostScheduled = "scheduled.test"
+		hostExpiring  = "expiring.test"
+	)
+
+	now := time.Unix(1_000, 0)
+	b := newStandardBlocker(t, &now)
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	b.Update(ctx, []*access.StandardRule{{
+		Start: now.Add(time.Minute),
+		ID:    "scheduled",
+		Text:  "||" + hostScheduled + "^",
+	}, {
+		End:  now.Add(2 * time.Minute),
+		ID:   "expiring",
+		Text: "||" + hostExpiring + "^",
+	}}, nil, true)
+
+	assert.False(t, b.IsBlockedHost(hostScheduled, dns.TypeA))
+	assert.True(t, b.IsBlockedHost(hostExpiring, dns.TypeA))
+
+	now = now.Add(time.Minute)
+	require.NoError(t, b.Refresh(ctx))
+
+	assert.True(t, b.IsBlockedHost(hostScheduled, dns.TypeA))
+	assert.True(t, b.IsBlockedHost(hostExpiring, dns.TypeA))
+
+	now = now.Add(time.Minute)
+	require.NoError(t, b.Refresh(ctx))
+
+	assert.True(t, b.IsBlockedHost(hostScheduled, dns.TypeA))
+	assert.False(t, b.IsBlockedHost(hostExpiring, dns.TypeA))
+}
+
+func TestGlobal_IsBlockedHost_standard(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	std := newStandardBlocker(t, &now)
+	std.Update(testutil.ContextWithTimeout(t, testTimeout), []*access.StandardRule{{
+		ID:   "1",
+		Text: "||standard.test^",
+	}}, nil, true)
+
+	global, err := access.NewGlobal([]string{"global.test"}, nil, std)
+	require.NoError(t, err)
+
+	assert.True(t, global.IsBlockedHost("global.test", dns.TypeA))
+	assert.True(t, global.IsBlockedHost("standard.test", dns.TypeA))
+	assert.False(t, global.IsBlockedHost("pass.test", dns.TypeA))
+}
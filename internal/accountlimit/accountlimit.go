@@ -0,0 +1,68 @@
+// Package accountlimit contains the enforcement of the aggregate limits of
+// accounts, which apply to the requests of all devices of all profiles of an
+// account together.
+package accountlimit
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// Result is the result of checking a request against the limits of an account.
+// It is used as a metrics label.
+type Result string
+
+// Result constants.
+const (
+	// ResultPass means that the request is within the limits of the account.
+	ResultPass Result = "pass"
+
+	// ResultRPS means that the request exceeds the requests-per-second limit
+	// of the account and should be dropped.
+	ResultRPS Result = "rps"
+
+	// ResultDevices means that the request is from a new device and the
+	// account already has the maximum number of active devices, so it should
+	// be dropped.
+	ResultDevices Result = "devices"
+)
+
+// Interface is the interface for the enforcement of the aggregate limits of
+// accounts.  All methods must be safe for concurrent use.
+type Interface interface {
+	// Check counts the request of the device d of the profile p and returns
+	// the result of checking it against the limits of the account of p.  p
+	// must not be nil.  d may be nil if the device is unknown.
+	Check(ctx context.Context, p *agd.Profile, d *agd.Device) (res Result)
+}
+
+// Empty is an [Interface] implementation that never limits requests.
+type Empty struct{}
+
+// type check
+var _ Interface = Empty{}
+
+// Check implements the [Interface] interface for Empty.  It always returns
+// [ResultPass].
+func (Empty) Check(_ context.Context, _ *agd.Profile, _ *agd.Device) (res Result) {
+	return ResultPass
+}
+
+// Metrics is an interface for collection of the statistics of the account
+// limits.
+type Metrics interface {
+	// OnCheck records the result of checking a request of an account with the
+	// given tier.  result is one of the [Result] values.
+	OnCheck(ctx context.Context, tier, result string)
+}
+
+// EmptyMetrics is an implementation of the [Metrics] interface that does
+// nothing.
+type EmptyMetrics struct{}
+
+// type check
+var _ Metrics = EmptyMetrics{}
+
+// OnCheck implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) OnCheck(_ context.Context, _, _ string) {}
@@ -0,0 +1,191 @@
+package accountlimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/sharedcounter"
+)
+
+// DefaultConfig is the configuration structure for [Default].  All fields must
+// not be empty.
+type DefaultConfig struct {
+	// Metrics is used for the collection of the account-limit statistics.
+	Metrics Metrics
+
+	// SharedCounter is used to count the requests of accounts across all
+	// nodes.  If it is [sharedcounter.Empty], only the requests received by
+	// the current node are counted.
+	SharedCounter sharedcounter.Interface
+
+	// Clock is used to get the current time.
+	Clock agdtime.Clock
+
+	// DeviceTTL is the duration after the last request of a device during
+	// which the device is considered active.  It must be positive.
+	DeviceTTL time.Duration
+}
+
+// Default is the default [Interface] implementation.  It counts the requests
+// and the active devices of the accounts locally and, optionally, coordinates
+// the request counts with the other nodes using a shared counter.  It is safe
+// for concurrent use.
+type Default struct {
+	metrics       Metrics
+	sharedCounter sharedcounter.Interface
+	clock         agdtime.Clock
+
+	// mu protects accounts.
+	mu       *sync.Mutex
+	accounts map[agd.AccountID]*accountState
+
+	deviceTTL time.Duration
+}
+
+// accountState is the node-local state of the limits of a single account.
+type accountState struct {
+	// devices are the times of the last requests of the active devices of the
+	// account.
+	devices map[agd.DeviceID]time.Time
+
+	// sec is the Unix time in seconds of the second being counted.
+	sec int64
+
+	// count is the number of requests received by this node during sec.
+	count uint64
+}
+
+// NewDefault returns a new properly initialized *Default.  c must be valid.
+func NewDefault(c *DefaultConfig) (d *Default) {
+	return &Default{
+		metrics:       c.Metrics,
+		sharedCounter: c.SharedCounter,
+		clock:         c.Clock,
+		mu:            &sync.Mutex{},
+		accounts:      map[agd.AccountID]*accountState{},
+		deviceTTL:     c.DeviceTTL,
+	}
+}
+
+// type check
+var _ Interface = (*Default)(nil)
+
+// Check implements the [Interface] interface for *Default.  The requests of
+// the profiles without an account or without account limits always pass and
+// are not recorded in the metrics.
+func (d *Default) Check(ctx context.Context, p *agd.Profile, dev *agd.Device) (res Result) {
+	l := p.AccountLimits
+	if l == nil || p.AccountID == "" {
+		return ResultPass
+	}
+
+	res, local := d.checkLocal(p.AccountID, l, dev)
+	if res == ResultPass && l.RPS > 0 {
+		total := max(local, d.sharedCounter.AddAccount(ctx, p.AccountID))
+		if total > uint64(l.RPS) {
+			res = ResultRPS
+		}
+	}
+
+	d.metrics.OnCheck(ctx, string(l.Tier), string(res))
+
+	return res
+}
+
+// checkLocal checks the device limit of the account and counts the request
+// locally.  local is the number of requests of the account received by this
+// node during the current second, including this one.  l must not be nil.
+func (d *Default) checkLocal(
+	id agd.AccountID,
+	l *agd.AccountLimits,
+	dev *agd.Device,
+) (res Result, local uint64) {
+	now := d.clock.Now()
+	sec := now.Unix()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	st := d.accounts[id]
+	if st == nil {
+		st = &accountState{
+			devices: map[agd.DeviceID]time.Time{},
+			sec:     sec,
+		}
+
+		d.accounts[id] = st
+	}
+
+	if l.MaxDevices > 0 && dev != nil && !st.addDevice(dev.ID, l.MaxDevices, now, d.deviceTTL) {
+		return ResultDevices, 0
+	}
+
+	if st.sec != sec {
+		st.sec, st.count = sec, 0
+	}
+
+	st.count++
+
+	return ResultPass, st.count
+}
+
+// addDevice marks the device as active at now and returns true if it's either
+// already active or the account has fewer than maxDevices active devices.
+// Otherwise, it returns false.
+func (st *accountState) addDevice(
+	id agd.DeviceID,
+	maxDevices uint32,
+	now time.Time,
+	ttl time.Duration,
+) (ok bool) {
+	if _, ok = st.devices[id]; !ok && len(st.devices) >= int(maxDevices) {
+		// Only remove the inactive devices when they are in the way, since
+		// Refresh removes them eventually anyway.
+		st.removeInactive(now.Add(-ttl))
+		if len(st.devices) >= int(maxDevices) {
+			return false
+		}
+	}
+
+	st.devices[id] = now
+
+	return true
+}
+
+// removeInactive removes the devices that haven't sent any requests since
+// the given time.
+func (st *accountState) removeInactive(since time.Time) {
+	for id, seen := range st.devices {
+		if seen.Before(since) {
+			delete(st.devices, id)
+		}
+	}
+}
+
+// type check
+var _ agdservice.Refresher = (*Default)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for *Default.  It
+// removes the inactive devices and the states of the inactive accounts.  err is
+// always nil.
+func (d *Default) Refresh(_ context.Context) (err error) {
+	now := d.clock.Now()
+	since := now.Add(-d.deviceTTL)
+	sec := now.Unix()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for id, st := range d.accounts {
+		st.removeInactive(since)
+		if len(st.devices) == 0 && st.sec < sec {
+			delete(d.accounts, id)
+		}
+	}
+
+	return nil
+}
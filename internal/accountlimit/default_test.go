@@ -0,0 +1,164 @@
+package accountlimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/accountlimit"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/sharedcounter"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// testDeviceTTL is the common device TTL for tests.
+const testDeviceTTL = 1 * time.Minute
+
+// Common IDs for tests.
+const (
+	testAccountID  agd.AccountID = "acc1234"
+	testDevID      agd.DeviceID  = "dev1234"
+	testOtherDevID agd.DeviceID  = "dev5678"
+)
+
+// testSharedCounter is a [sharedcounter.Interface] implementation for tests.
+type testSharedCounter struct {
+	onAddAccount func(ctx context.Context, id agd.AccountID) (total uint64)
+}
+
+// type check
+var _ sharedcounter.Interface = (*testSharedCounter)(nil)
+
+// Add implements the [sharedcounter.Interface] interface for
+// *testSharedCounter.
+func (c *testSharedCounter) Add(_ context.Context, _ agd.ProfileID) (total uint64) {
+	panic("not implemented")
+}
+
+// AddAccount implements the [sharedcounter.Interface] interface for
+// *testSharedCounter.
+func (c *testSharedCounter) AddAccount(ctx context.Context, id agd.AccountID) (total uint64) {
+	return c.onAddAccount(ctx, id)
+}
+
+// newTestDefault returns a new *accountlimit.Default for tests.  now is the
+// pointer to the current time of the clock.
+func newTestDefault(
+	tb testing.TB,
+	now *time.Time,
+	shared sharedcounter.Interface,
+) (d *accountlimit.Default) {
+	tb.Helper()
+
+	return accountlimit.NewDefault(&accountlimit.DefaultConfig{
+		Metrics:       accountlimit.EmptyMetrics{},
+		SharedCounter: shared,
+		Clock: &agdtest.Clock{
+			OnNow: func() (n time.Time) { return *now },
+		},
+		DeviceTTL: testDeviceTTL,
+	})
+}
+
+// newTestProfile returns a new profile of the test account with the given
+// limits.
+func newTestProfile(rps, maxDevices uint32) (p *agd.Profile) {
+	return &agd.Profile{
+		AccountLimits: &agd.AccountLimits{
+			Tier:       "team",
+			RPS:        rps,
+			MaxDevices: maxDevices,
+		},
+		ID:        "prof1234",
+		AccountID: testAccountID,
+	}
+}
+
+func TestDefault_Check_rps(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	l := newTestDefault(t, &now, sharedcounter.Empty{})
+	p := newTestProfile(2, 0)
+	dev := &agd.Device{ID: testDevID}
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	assert.Equal(t, accountlimit.ResultPass, l.Check(ctx, p, dev))
+	assert.Equal(t, accountlimit.ResultPass, l.Check(ctx, p, dev))
+	assert.Equal(t, accountlimit.ResultRPS, l.Check(ctx, p, dev))
+
+	// Another profile of the same account shares the limit.
+	other := newTestProfile(2, 0)
+	other.ID = "prof5678"
+	assert.Equal(t, accountlimit.ResultRPS, l.Check(ctx, other, nil))
+
+	// A new second starts a new count.
+	now = now.Add(time.Second)
+	assert.Equal(t, accountlimit.ResultPass, l.Check(ctx, p, dev))
+}
+
+func TestDefault_Check_shared(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	shared := &testSharedCounter{
+		onAddAccount: func(_ context.Context, id agd.AccountID) (total uint64) {
+			require.Equal(t, testAccountID, id)
+
+			// Simulate the requests received by the other nodes.
+			return 10
+		},
+	}
+
+	l := newTestDefault(t, &now, shared)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	assert.Equal(t, accountlimit.ResultPass, l.Check(ctx, newTestProfile(10, 0), nil))
+	assert.Equal(t, accountlimit.ResultRPS, l.Check(ctx, newTestProfile(9, 0), nil))
+}
+
+func TestDefault_Check_devices(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	l := newTestDefault(t, &now, sharedcounter.Empty{})
+	p := newTestProfile(0, 1)
+	dev := &agd.Device{ID: testDevID}
+	otherDev := &agd.Device{ID: testOtherDevID}
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	assert.Equal(t, accountlimit.ResultPass, l.Check(ctx, p, dev))
+	assert.Equal(t, accountlimit.ResultDevices, l.Check(ctx, p, otherDev))
+	assert.Equal(t, accountlimit.ResultPass, l.Check(ctx, p, dev))
+
+	// The first device becomes inactive, so the other one can take its place.
+	now = now.Add(testDeviceTTL + time.Second)
+	assert.Equal(t, accountlimit.ResultPass, l.Check(ctx, p, otherDev))
+	assert.Equal(t, accountlimit.ResultDevices, l.Check(ctx, p, dev))
+
+	now = now.Add(testDeviceTTL + time.Second)
+	require.NoError(t, l.Refresh(ctx))
+
+	assert.Equal(t, accountlimit.ResultPass, l.Check(ctx, p, dev))
+}
+
+func TestDefault_Check_noLimits(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	l := newTestDefault(t, &now, &testSharedCounter{
+		onAddAccount: func(_ context.Context, _ agd.AccountID) (total uint64) {
+			panic("unexpected call to AddAccount")
+		},
+	})
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	p := &agd.Profile{ID: "prof1234", AccountID: testAccountID}
+	assert.Equal(t, accountlimit.ResultPass, l.Check(ctx, p, nil))
+}
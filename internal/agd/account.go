@@ -0,0 +1,28 @@
+package agd
+
+// AccountID is the ID of an account, which can have several profiles.  It is
+// an opaque string.
+type AccountID string
+
+// AccountTier is the name of the tier of an account, for example "personal" or
+// "team".  It is used as a metrics label, so the number of tiers must be small.
+type AccountTier string
+
+// AccountLimits are the aggregate limits of an account, which apply to the
+// requests of all devices of all profiles of the account together.
+//
+// NOTE: Do not change fields of this structure without incrementing
+// [internal/profiledb/internal.FileCacheVersion].
+type AccountLimits struct {
+	// Tier is the tier of the account.
+	Tier AccountTier
+
+	// RPS is the maximum number of requests per second from all devices of the
+	// account.  If it is zero, the number of requests is not limited.
+	RPS uint32
+
+	// MaxDevices is the maximum number of devices of the account that can send
+	// requests at the same time.  If it is zero, the number of devices is not
+	// limited.
+	MaxDevices uint32
+}
@@ -0,0 +1,83 @@
+package agd
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/netip"
+	"slices"
+)
+
+// ClientKeyLen is the length of a [ClientKey] in bytes.
+const ClientKeyLen = 16
+
+// ClientKey is an opaque key that identifies the same client across all
+// protocols.  For clients with a known device it is derived from the profile
+// and the device; for anonymous clients it is a salted hash of the IP address.
+// The zero value means that the key has not been set.
+type ClientKey [ClientKeyLen]byte
+
+// type check
+var _ fmt.Stringer = ClientKey{}
+
+// String implements the [fmt.Stringer] interface for ClientKey.
+func (k ClientKey) String() (s string) {
+	enc := base64.URLEncoding.WithPadding(base64.NoPadding)
+	n := enc.EncodedLen(ClientKeyLen)
+	data := make([]byte, n)
+	enc.Encode(data, k[:])
+
+	return string(data)
+}
+
+// Client-key kinds.  These are mixed into the hash so that keys of different
+// kinds never collide.
+const (
+	clientKeyKindDevice byte = 'd'
+	clientKeyKindIP     byte = 'i'
+)
+
+// ClientKeyHasher creates [ClientKey]s.  The salt should be the same on all
+// nodes and stable across restarts for the keys to stay the same.
+type ClientKeyHasher struct {
+	salt []byte
+}
+
+// NewClientKeyHasher returns a new properly initialized *ClientKeyHasher.  salt
+// is copied.
+func NewClientKeyHasher(salt []byte) (h *ClientKeyHasher) {
+	return &ClientKeyHasher{
+		salt: slices.Clone(salt),
+	}
+}
+
+// ForDevice returns the client key for the device with the given profile and
+// device IDs.
+func (h *ClientKeyHasher) ForDevice(profID ProfileID, devID DeviceID) (k ClientKey) {
+	return h.sum(clientKeyKindDevice, []byte(profID), []byte{0}, []byte(devID))
+}
+
+// ForIP returns the client key for an anonymous client with the given IP
+// address.  IPv4-mapped IPv6 addresses are unmapped, so that the same IPv4
+// client always has the same key.
+func (h *ClientKeyHasher) ForIP(ip netip.Addr) (k ClientKey) {
+	ipData := ip.Unmap().AsSlice()
+
+	return h.sum(clientKeyKindIP, ipData)
+}
+
+// sum returns the truncated salted SHA-256 hash of kind and parts.
+func (h *ClientKeyHasher) sum(kind byte, parts ...[]byte) (k ClientKey) {
+	hash := sha256.New()
+
+	// Don't check the errors, since [hash.Hash.Write] never returns them.
+	_, _ = hash.Write(h.salt)
+	_, _ = hash.Write([]byte{kind})
+	for _, p := range parts {
+		_, _ = hash.Write(p)
+	}
+
+	copy(k[:], hash.Sum(nil))
+
+	return k
+}
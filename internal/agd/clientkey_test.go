@@ -0,0 +1,56 @@
+package agd_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientKeyHasher(t *testing.T) {
+	t.Parallel()
+
+	const (
+		profID agd.ProfileID = "prof1234"
+		devID  agd.DeviceID  = "dev1234"
+	)
+
+	var (
+		ip     = netip.MustParseAddr("192.0.2.1")
+		ipMapd = netip.AddrFrom16(ip.As16())
+		ipOth  = netip.MustParseAddr("192.0.2.2")
+	)
+
+	h := agd.NewClientKeyHasher([]byte("salt"))
+	hOth := agd.NewClientKeyHasher([]byte("other_salt"))
+
+	t.Run("device", func(t *testing.T) {
+		t.Parallel()
+
+		k := h.ForDevice(profID, devID)
+		assert.NotEqual(t, agd.ClientKey{}, k)
+		assert.Equal(t, k, h.ForDevice(profID, devID))
+		assert.NotEqual(t, k, h.ForDevice(profID, "dev5678"))
+		assert.NotEqual(t, k, h.ForDevice("prof5678", devID))
+		assert.NotEqual(t, k, hOth.ForDevice(profID, devID))
+	})
+
+	t.Run("ip", func(t *testing.T) {
+		t.Parallel()
+
+		k := h.ForIP(ip)
+		assert.NotEqual(t, agd.ClientKey{}, k)
+		assert.Equal(t, k, h.ForIP(ip))
+		assert.Equal(t, k, h.ForIP(ipMapd))
+		assert.NotEqual(t, k, h.ForIP(ipOth))
+		assert.NotEqual(t, k, hOth.ForIP(ip))
+	})
+
+	t.Run("string", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "AAAAAAAAAAAAAAAAAAAAAA", agd.ClientKey{}.String())
+		assert.Len(t, h.ForIP(ip).String(), 22)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"net/netip"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
 	"github.com/AdguardTeam/golibs/errors"
 )
@@ -63,6 +64,9 @@ func RequestIDFromContext(ctx context.Context) (id RequestID, ok bool) {
 // RequestInfo contains information about the current request.  A RequestInfo
 // put into the context must not be modified.
 type RequestInfo struct {
+	// RemoteIP is the remote IP address of the client.
+	RemoteIP netip.Addr
+
 	// DeviceResult is the result of finding the device.
 	DeviceResult DeviceResult
 
@@ -73,7 +77,8 @@ type RequestInfo struct {
 	// any.
 	ECS *dnsmsg.ECS
 
-	// FilteringGroup is the server's default filtering group.
+	// FilteringGroup is the server's default filtering group or the filtering
+	// group of View, if it's set.
 	FilteringGroup *FilteringGroup
 
 	// Messages is the message constructor to be used for the filtered responses
@@ -83,8 +88,9 @@ type RequestInfo struct {
 	// ServerGroup is the server group which handles this request.
 	ServerGroup *ServerGroup
 
-	// RemoteIP is the remote IP address of the client.
-	RemoteIP netip.Addr
+	// View is the view of the server group matching the client, if any.  It is
+	// only set for the handlers that run after the initial middleware.
+	View *View
 
 	// Server is the name of the server which handles this request.
 	Server ServerName
@@ -93,9 +99,15 @@ type RequestInfo struct {
 	// question of the request.
 	Host string
 
-	// ID is the unique ID of the request.  It is resurfaced here to optimize
-	// context lookups.
-	ID RequestID
+	// TLSFingerprint is the JA4 fingerprint of the TLS client, if any.  It is
+	// only set for the DoT and DoH servers with the TLS fingerprinting enabled.
+	// It identifies the client software regardless of the remote IP address.
+	TLSFingerprint string
+
+	// Categories are the content categories of the requested host, if any.
+	// They are only set for the handlers that run after the request has been
+	// classified by the main middleware.  Categories must not be modified.
+	Categories []filter.Category
 
 	// QType is the type of question for this request.
 	QType dnsmsg.RRType
@@ -103,8 +115,21 @@ type RequestInfo struct {
 	// QClass is the class of question for this request.
 	QClass dnsmsg.Class
 
+	// ClientKey identifies the client across all protocols.  It is derived
+	// from the device, if any, or from the remote IP address otherwise.
+	ClientKey ClientKey
+
+	// ID is the unique ID of the request.  It is resurfaced here to optimize
+	// context lookups.
+	ID RequestID
+
 	// Proto is the protocol by which this request is made.
 	Proto Protocol
+
+	// FilteringOutcome is the class of the outcome of the request filtering.
+	// It is only set for the handlers that run after the request has been
+	// filtered.
+	FilteringOutcome FilteringOutcome
 }
 
 // DeviceData returns the profile and device data if any.  Either both p and d
@@ -117,6 +142,55 @@ func (ri *RequestInfo) DeviceData() (p *Profile, d *Device) {
 	return nil, nil
 }
 
+// ECSMode returns the EDNS Client Subnet mode actually applied to the request,
+// based on the mode of the profile, if any, and the ECS option of the request.
+// A request with an explicitly declined ECS, that is with a zero-length prefix,
+// always has [ECSModeStrip].
+func (ri *RequestInfo) ECSMode() (m ECSMode) {
+	if ri.ECS != nil && ri.ECS.Subnet.Bits() == 0 {
+		return ECSModeStrip
+	}
+
+	p, _ := ri.DeviceData()
+	if p == nil {
+		return ECSModeReplace
+	}
+
+	if p.ECSMode == ECSModeForward && ri.ECS == nil {
+		return ECSModeReplace
+	}
+
+	return p.ECSMode
+}
+
+// FilteringOutcome is the class of the outcome of the request filtering.  It is
+// used to partition the DNS cache so that the responses to filtered and
+// unfiltered requests never share cache entries.
+type FilteringOutcome uint8
+
+// FilteringOutcome values.
+const (
+	// FilteringOutcomeUnfiltered means that the request has either not matched
+	// any filtering rules or has been explicitly allowed.
+	FilteringOutcomeUnfiltered FilteringOutcome = iota
+
+	// FilteringOutcomeFiltered means that the request has been either blocked
+	// or modified.
+	FilteringOutcomeFiltered
+)
+
+// String implements the [fmt.Stringer] interface for FilteringOutcome.
+func (o FilteringOutcome) String() (s string) {
+	switch o {
+	case FilteringOutcomeUnfiltered:
+		return "unfiltered"
+	case FilteringOutcomeFiltered:
+		return "filtered"
+	default:
+		return fmt.Sprintf("!bad_filtering_outcome_%d", o)
+	}
+}
+
 // ContextWithRequestInfo returns a copy of the parent context with the request
 // and server group information added.  ri must not be modified after calling
 // ContextWithRequestInfo.
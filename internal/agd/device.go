@@ -3,6 +3,7 @@ package agd
 import (
 	"fmt"
 	"net/netip"
+	"time"
 	"unicode/utf8"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdpasswd"
@@ -21,6 +22,10 @@ type Device struct {
 	// ID is the unique ID of the device.
 	ID DeviceID
 
+	// CreatedAt is the time of creation of the device.  If it is zero, the
+	// device is never in the learning mode, see [Device.IsLearning].
+	CreatedAt time.Time
+
 	// LinkedIP, when non-empty, allows AdGuard DNS to identify a device by its
 	// IP address when it can only use plain DNS.
 	LinkedIP netip.Addr
@@ -37,9 +42,27 @@ type Device struct {
 	// address for AdGuard DNS to recognize it.
 	DedicatedIPs []netip.Addr
 
+	// DedicatedPrefixes are the destination (server) IPv6 prefixes dedicated to
+	// this device, if any.  A device can use any address within one of these
+	// prefixes as a DNS server address for AdGuard DNS to recognize it, which
+	// is useful for CPEs that change the interface IDs of their addresses.  All
+	// prefixes must be masked.
+	DedicatedPrefixes []netip.Prefix
+
 	// FilteringEnabled defines whether queries from the device should be
 	// filtered in any way at all.
 	FilteringEnabled bool
+
+	// Quarantined shows whether the device has been isolated by the backend, in
+	// which case all of its queries are blocked except the ones for the
+	// remediation domains.
+	Quarantined bool
+}
+
+// IsLearning returns true if the device is in the learning mode at now, that
+// is, if less than period has passed since its creation.
+func (d *Device) IsLearning(period time.Duration, now time.Time) (ok bool) {
+	return period > 0 && !d.CreatedAt.IsZero() && now.Before(d.CreatedAt.Add(period))
 }
 
 // DeviceID is the ID of a device attached to a profile.  It is an opaque
@@ -119,6 +142,12 @@ type AuthSettings struct {
 	// PasswordHash is the hash of the auth password.  It is never nil.
 	PasswordHash agdpasswd.Authenticator
 
+	// DoHQueryKey is the optional secret key used to verify the signed
+	// authentication tokens passed in the query parameters of DoH requests by
+	// the clients that cannot use the basic authentication.  If empty, such
+	// tokens are not accepted.
+	DoHQueryKey []byte
+
 	// Enabled tells whether the authentication should be enabled at all.
 	// This must be true in order for all parameters to work.
 	Enabled bool
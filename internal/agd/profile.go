@@ -32,9 +32,21 @@ type Profile struct {
 	// nil.
 	Ratelimiter Ratelimiter
 
+	// AccountLimits are the aggregate limits of the account of this profile.
+	// If it is nil, the account has no aggregate limits.
+	AccountLimits *AccountLimits
+
+	// QueryTypeActions are the actions taken on the requests with the
+	// corresponding query types from devices of this profile.  The requests
+	// with other query types are processed as usual.
+	QueryTypeActions map[dnsmsg.RRType]QueryTypeAction
+
 	// ID is the unique ID of this profile.  It must not be empty.
 	ID ProfileID
 
+	// AccountID is the ID of the account of this profile, if any.
+	AccountID AccountID
+
 	// DeviceIDs are the IDs of devices attached to this profile.
 	DeviceIDs []DeviceID
 
@@ -42,6 +54,10 @@ type Profile struct {
 	// the devices of this profile.
 	FilteredResponseTTL time.Duration
 
+	// ECSMode defines the EDNS Client Subnet data sent to the upstreams for the
+	// requests from the devices of this profile.
+	ECSMode ECSMode
+
 	// AutoDevicesEnabled shows if the automatic creation of devices using
 	// HumanIDs should be enabled for this profile.
 	AutoDevicesEnabled bool
@@ -65,12 +81,103 @@ type Profile struct {
 	// should be filtered in any way at all.
 	FilteringEnabled bool
 
+	// ForwardLocalZones shows if the queries for the locally-served zones,
+	// such as the private reverse zones, from devices of this profile are
+	// forwarded upstream instead of being answered locally, for example
+	// because the profile has custom upstream rules for them.  See RFC 6303.
+	ForwardLocalZones bool
+
 	// IPLogEnabled shows if client IP addresses are logged.
 	IPLogEnabled bool
 
 	// QueryLogEnabled defines whether query logs should be saved for the
 	// devices of this profile.
 	QueryLogEnabled bool
+
+	// RequireDNSSEC shows if the upstream responses for the devices of this
+	// profile must contain DNSSEC-validated data.  Responses for unvalidated
+	// zones are replaced with SERVFAIL ones.
+	RequireDNSSEC bool
+}
+
+// ECSMode defines the EDNS Client Subnet data sent to the upstreams.
+type ECSMode uint8
+
+// ECSMode values.
+//
+// NOTE: DO NOT change the numerical values or use iota, because they are
+// stored in the profile cache.
+const (
+	// ECSModeReplace means that the subnet of the client's location is sent
+	// instead of the client's data.  It is the default mode.
+	ECSModeReplace ECSMode = 0
+
+	// ECSModeForward means that the subnet from the ECS option of the client's
+	// request, if any, is sent upstream, reduced to at most
+	// [ECSForwardMaxBitsIPv4] or [ECSForwardMaxBitsIPv6] bits.  If the request
+	// has no ECS option, ECSModeReplace is used.
+	ECSModeForward ECSMode = 1
+
+	// ECSModeStrip means that an ECS option with a zero-length prefix is sent,
+	// so that no client data is used by the upstreams.
+	ECSModeStrip ECSMode = 2
+)
+
+// Maximum prefix lengths of the forwarded ECS subnets as recommended by RFC
+// 7871, Section 11.1.
+const (
+	ECSForwardMaxBitsIPv4 = 24
+	ECSForwardMaxBitsIPv6 = 56
+)
+
+// type check
+var _ fmt.Stringer = ECSMode(0)
+
+// String implements the [fmt.Stringer] interface for ECSMode.
+func (m ECSMode) String() (s string) {
+	switch m {
+	case ECSModeReplace:
+		return "replace"
+	case ECSModeForward:
+		return "forward"
+	case ECSModeStrip:
+		return "strip"
+	default:
+		return fmt.Sprintf("!bad_ecs_mode_%d", uint8(m))
+	}
+}
+
+// QueryTypeAction is the action taken on a request with a certain query type
+// before filtering.
+type QueryTypeAction uint8
+
+// QueryTypeAction values.
+//
+// NOTE: DO NOT change the numerical values or use iota, because they are
+// stored in the profile cache.
+const (
+	// QueryTypeActionRefused means that the request is answered with a
+	// REFUSED response.
+	QueryTypeActionRefused QueryTypeAction = 1
+
+	// QueryTypeActionNODATA means that the request is answered with a NODATA
+	// response, that is a NOERROR response without any answers.
+	QueryTypeActionNODATA QueryTypeAction = 2
+)
+
+// type check
+var _ fmt.Stringer = QueryTypeAction(0)
+
+// String implements the [fmt.Stringer] interface for QueryTypeAction.
+func (a QueryTypeAction) String() (s string) {
+	switch a {
+	case QueryTypeActionRefused:
+		return "refused"
+	case QueryTypeActionNODATA:
+		return "nodata"
+	default:
+		return fmt.Sprintf("!bad_query_type_action_%d", uint8(a))
+	}
 }
 
 // ProfileID is the ID of a profile.  It is an opaque string.
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdnet"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/ameshkov/dnscrypt/v2"
@@ -30,6 +31,29 @@ type Server struct {
 	// UDPConf is the UDP configuration for this server.
 	UDPConf *UDPConfig
 
+	// WebSocketConf is the DNS-over-WebSocket configuration for this server.
+	// It is only used for DoH servers and may be nil.
+	WebSocketConf *WebSocketConfig
+
+	// HTTPLimitsConf is the configuration of the limits of the DoH server.  It
+	// is only used for DoH servers.  If it is nil, the defaults are used.
+	HTTPLimitsConf *HTTPLimitsConfig
+
+	// HTTPStreamsConf is the configuration of the HTTP/2 and HTTP/3 streams of
+	// the DoH server.  It is only used for DoH servers.  If it is nil, the
+	// defaults are used.
+	HTTPStreamsConf *HTTPStreamsConfig
+
+	// TLSFingerprintConf is the configuration of the TLS client fingerprinting
+	// for this server.  It is only used for DoT and DoH servers.  If it is nil,
+	// the fingerprinting is disabled.
+	TLSFingerprintConf *TLSFingerprintConfig
+
+	// ControlConf is the configuration of the socket options for this server,
+	// which overrides the global one.  It is not used for the bind data with
+	// ListenConfig set and may be nil.
+	ControlConf *netext.ControlConfig
+
 	// Name is the unique name of the server.  Not to be confused with a TLS
 	// Server Name.
 	Name ServerName
@@ -56,6 +80,11 @@ type Server struct {
 	// LinkedIPEnabled shows if the linked IP addresses should be used to detect
 	// profiles on this server.
 	LinkedIPEnabled bool
+
+	// StrictSNI, if true, makes the server reject connections the TLS server
+	// names of which match a device domain but point to no known and
+	// authenticated device.  It is only used for DoT servers.
+	StrictSNI bool
 }
 
 // BindData returns the bind data of this server.  The elements of the slice
@@ -189,6 +218,14 @@ type UDPConfig struct {
 	// MaxRespSize is the maximum size in bytes of DNS response over UDP
 	// protocol.
 	MaxRespSize uint16
+
+	// TruncationPolicy is the policy of truncating DNS responses over UDP.
+	TruncationPolicy dnsserver.UDPTruncationPolicy
+
+	// TruncateUnknownClients, if true, makes the server truncate responses
+	// larger than 512 bytes to the clients, which aren't associated with any
+	// profile, to make them retry over TCP.
+	TruncateUnknownClients bool
 }
 
 // QUICConfig is the QUIC configuration of a DNS server.
@@ -201,6 +238,74 @@ type QUICConfig struct {
 	QUICLimitsEnabled bool
 }
 
+// WebSocketConfig is the DNS-over-WebSocket configuration of a DoH server.
+type WebSocketConfig struct {
+	// MaxQueries is the maximum number of queries a client is allowed to send
+	// over a single WebSocket connection.  If it is zero, the number of queries
+	// is not limited.
+	MaxQueries uint
+
+	// Enabled, if true, enables the DNS-over-WebSocket endpoint.
+	Enabled bool
+}
+
+// HTTPLimitsConfig is the configuration of the limits of a DoH server, which
+// protect it from the slow-loris style resource exhaustion.
+type HTTPLimitsConfig struct {
+	// ReadHeaderTimeout is the amount of time allowed to read the request
+	// headers.  If it is zero, the default is used.
+	ReadHeaderTimeout time.Duration
+
+	// ReadTimeout is the amount of time allowed to read the entire request.
+	// If it is zero, the default is used.
+	ReadTimeout time.Duration
+
+	// MaxBodySize is the maximum size of the body of a request, in bytes.  If
+	// it is zero, the default is used.
+	MaxBodySize uint
+
+	// ConnRequestsPerSecond is the maximum number of requests per second a
+	// client is allowed to send over a single connection.  If it is zero, the
+	// rate is not limited.
+	ConnRequestsPerSecond uint
+
+	// CloseAbusiveConns, if true, makes the server close the connections on
+	// which a limit has been exceeded.
+	CloseAbusiveConns bool
+}
+
+// HTTPStreamsConfig is the configuration of the streams of the HTTP/2 and
+// HTTP/3 connections of a DoH server.
+type HTTPStreamsConfig struct {
+	// WriteScheduler is the scheduler of the HTTP/2 response frames.
+	WriteScheduler dnsserver.HTTPWriteScheduler
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams a
+	// client is allowed to open over a single connection.  If it is zero, the
+	// default is used.
+	MaxConcurrentStreams uint32
+
+	// HeaderTableSize is the maximum size of the HTTP/2 header compression
+	// tables, in bytes.  If it is zero, the default is used.
+	HeaderTableSize uint32
+
+	// StreamWindowSize is the size of the flow-control window of a single
+	// stream, in bytes.  If it is zero, the default is used.
+	StreamWindowSize uint32
+
+	// ConnWindowSize is the size of the flow-control window of a whole
+	// connection, in bytes.  If it is zero, the default is used.
+	ConnWindowSize uint32
+}
+
+// TLSFingerprintConfig is the configuration of the JA3 and JA4 fingerprinting
+// of the TLS clients of a DoT or DoH server.
+type TLSFingerprintConfig struct {
+	// KeepRaw, if true, makes the server keep the raw fingerprints along with
+	// the hashed ones.
+	KeepRaw bool
+}
+
 // TLSConfig is the TLS configuration of a DNS server.  Metrics and ALPs must be
 // set for saved configurations.
 type TLSConfig struct {
@@ -1,6 +1,8 @@
 package agd
 
 import (
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
 	"github.com/AdguardTeam/golibs/container"
 	"github.com/miekg/dns"
 )
@@ -24,12 +26,39 @@ type ServerGroup struct {
 	// FilteringGroup is the ID of the filtering group for this server group.
 	FilteringGroup FilteringGroupID
 
+	// FilterVerdictKey, if not empty, is the key used to sign the filtering
+	// verdicts added to the responses to the clients that request them.  See
+	// package filterverdict.
+	FilterVerdictKey []byte
+
+	// ResponseJitter, if not nil, is the randomization of the synthesized
+	// responses of this server group.
+	ResponseJitter *dnsmsg.JitterConfig
+
 	// Servers are the settings for servers.  Each element must be non-nil.
 	Servers []*Server
 
+	// Views are the optional views of this server group.  The first view
+	// matching the client is used.  Each element must be non-nil.
+	Views []*View
+
+	// AggressiveNSECEnabled, if true, enables the synthesis of negative
+	// responses from the cached NSEC and NSEC3 records for this server group.
+	AggressiveNSECEnabled bool
+
+	// LocalZonesEnabled, if true, makes the queries for the locally-served
+	// zones, such as the private reverse zones, be answered locally instead of
+	// being forwarded upstream for this server group.  See RFC 6303.
+	LocalZonesEnabled bool
+
 	// ProfilesEnabled, if true, enables recognition of user devices and
 	// profiles for this server group.
 	ProfilesEnabled bool
+
+	// ResponsePaddingBlockSize, if not zero, is the block size to which the
+	// responses to the padded queries over DoT and DoH are padded.  See RFC
+	// 8467.  If it is zero, the responses are padded randomly.
+	ResponsePaddingBlockSize uint16
 }
 
 // ServerGroupName is the name of a server group.
@@ -39,22 +68,65 @@ type ServerGroupName string
 // Resolvers (DDR) handlers.
 type DDR struct {
 	// DeviceTargets is the set of all domain names, subdomains of which should
-	// be checked for DDR queries with device IDs.
+	// be checked for DDR queries with device IDs, including the ones from
+	// CountryRecordTemplates.
 	DeviceTargets *container.MapSet[string]
 
 	// PublicTargets is the set of all public domain names, DDR queries for
-	// which should be processed.
+	// which should be processed, including the ones from
+	// CountryRecordTemplates.
 	PublicTargets *container.MapSet[string]
 
+	// CountryRecordTemplates are the record templates used for the clients
+	// from the countries instead of DeviceRecordTemplates and
+	// PublicRecordTemplates, so that the clients are directed to the nearby
+	// resolvers.  Each value must not be nil.
+	CountryRecordTemplates map[geoip.Country]*DDRCountryRecordTemplates
+
+	// CountryPriorities are the priorities of the designated resolvers for the
+	// clients from the countries.  If there is no entry for the country of the
+	// client or for the protocol of a record, the priority of the record
+	// template is used.
+	CountryPriorities map[geoip.Country]DDRPriorities
+
 	// DeviceRecordTemplates are used to respond to DDR queries from recognized
-	// devices.
-	DeviceRecordTemplates []*dns.SVCB
+	// devices.  They must be sorted by priority.
+	DeviceRecordTemplates []*DDRRecordTemplate
 
 	// PubilcRecordTemplates are used to respond to DDR queries from
-	// unrecognized devices.
-	PublicRecordTemplates []*dns.SVCB
+	// unrecognized devices.  They must be sorted by priority.
+	PublicRecordTemplates []*DDRRecordTemplate
 
 	// Enabled shows if DDR queries are processed.  If it is false, DDR domain
 	// name queries receive an NXDOMAIN response.
 	Enabled bool
 }
+
+// DDRCountryRecordTemplates are the templates of the records for the responses
+// to DDR queries from the clients from a country.
+type DDRCountryRecordTemplates struct {
+	// DeviceRecordTemplates are used to respond to DDR queries from recognized
+	// devices.  They must be sorted by priority.  If empty,
+	// [DDR.DeviceRecordTemplates] are used.
+	DeviceRecordTemplates []*DDRRecordTemplate
+
+	// PublicRecordTemplates are used to respond to DDR queries from
+	// unrecognized devices.  They must be sorted by priority.  If empty,
+	// [DDR.PublicRecordTemplates] are used.
+	PublicRecordTemplates []*DDRRecordTemplate
+}
+
+// DDRRecordTemplate is a template of an SVCB record for the responses to DDR
+// queries.
+type DDRRecordTemplate struct {
+	// Record is the SVCB record template.  It must not be nil.
+	Record *dns.SVCB
+
+	// Proto is the protocol of the designated resolver.  It must be one of
+	// [ProtoDoH], [ProtoDoQ], and [ProtoDoT].
+	Proto Protocol
+}
+
+// DDRPriorities are the SvcPriority values of the designated resolvers by their
+// protocols.  All values must be positive.
+type DDRPriorities map[Protocol]uint16
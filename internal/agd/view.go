@@ -0,0 +1,43 @@
+package agd
+
+import (
+	"net/netip"
+	"slices"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+)
+
+// View is a logical configuration of a server group that is applied to the
+// requests from the clients in certain subnets or countries.  It allows serving
+// different policies to different pools of clients on the same server.
+type View struct {
+	// FilteringGroup is the filtering group used for the requests matching the
+	// view instead of the one of the server group.  It must not be nil.
+	FilteringGroup *FilteringGroup
+
+	// Name is the unique name of the view within its server group.  It is also
+	// used to partition the cache and to select the upstreams for the view.
+	// It must not be empty.
+	Name ViewName
+
+	// Countries are the countries of the clients matching the view.
+	Countries []geoip.Country
+
+	// Subnets are the subnets of the clients matching the view.
+	Subnets []netip.Prefix
+}
+
+// ViewName is the name of a view.
+type ViewName string
+
+// Match returns true if a client with the remote IP address ip from the
+// country ctry matches v.  ctry may be [geoip.CountryNone].
+func (v *View) Match(ip netip.Addr, ctry geoip.Country) (ok bool) {
+	for _, subnet := range v.Subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+
+	return ctry != geoip.CountryNone && slices.Contains(v.Countries, ctry)
+}
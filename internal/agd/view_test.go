@@ -0,0 +1,63 @@
+package agd_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestView_Match(t *testing.T) {
+	t.Parallel()
+
+	v := &agd.View{
+		FilteringGroup: &agd.FilteringGroup{},
+		Name:           "test_view",
+		Countries:      []geoip.Country{geoip.CountryCY},
+		Subnets:        []netip.Prefix{prefixV4},
+	}
+
+	otherAddr := netip.MustParseAddr("5.6.7.8")
+
+	testCases := []struct {
+		ip   netip.Addr
+		name string
+		ctry geoip.Country
+		want bool
+	}{{
+		ip:   addrV4,
+		name: "subnet",
+		ctry: geoip.CountryNone,
+		want: true,
+	}, {
+		ip:   otherAddr,
+		name: "country",
+		ctry: geoip.CountryCY,
+		want: true,
+	}, {
+		ip:   otherAddr,
+		name: "other_country",
+		ctry: geoip.CountryAD,
+		want: false,
+	}, {
+		ip:   otherAddr,
+		name: "no_country",
+		ctry: geoip.CountryNone,
+		want: false,
+	}, {
+		ip:   addrV6,
+		name: "other_family",
+		ctry: geoip.CountryNone,
+		want: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, v.Match(tc.ip, tc.ctry))
+		})
+	}
+}
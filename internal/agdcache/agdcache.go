@@ -29,6 +29,30 @@ type Clearer interface {
 	Clear()
 }
 
+// HostFlusher is a partial cache interface for the caches the items of which
+// are related to hostnames.
+type HostFlusher interface {
+	// FlushHosts removes the items related to the hostnames for which match
+	// returns true.  n is the number of removed items.  match must not be nil.
+	FlushHosts(match func(host string) (ok bool)) (n int)
+}
+
+// Ranger is a partial cache interface for the caches that can iterate over
+// their items.
+type Ranger[K, T any] interface {
+	// Range calls f for each unexpired item in the cache until f returns false.
+	// f may modify the cache.  f must not be nil.
+	Range(f func(key K, val T) (cont bool))
+}
+
+// HostItem is the interface for the cache items related to a hostname.  The
+// caches implementing [HostFlusher] use it to find the items to remove.
+type HostItem interface {
+	// CachedHost returns the lowercased, non-FQDN hostname the item is related
+	// to.
+	CachedHost() (host string)
+}
+
 // Empty is an [Interface] implementation that does nothing.
 type Empty[K, T any] struct{}
 
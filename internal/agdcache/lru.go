@@ -79,6 +79,44 @@ func (c *LRU[K, T]) Clear() {
 	c.cache.Purge()
 }
 
+// type check
+var _ HostFlusher = (*LRU[any, any])(nil)
+
+// FlushHosts implements the [HostFlusher] interface for *LRU.  Only the items
+// implementing [HostItem] are checked.
+func (c *LRU[K, T]) FlushHosts(match func(host string) (ok bool)) (n int) {
+	const checkExpired = false
+
+	for k, v := range c.cache.GetALL(checkExpired) {
+		item, ok := v.(HostItem)
+		if ok && match(item.CachedHost()) && c.cache.Remove(k) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// type check
+var _ Ranger[any, any] = (*LRU[any, any])(nil)
+
+// Range implements the [Ranger] interface for *LRU.  The items are iterated in
+// no particular order.
+func (c *LRU[K, T]) Range(f func(key K, val T) (cont bool)) {
+	const checkExpired = true
+
+	for k, v := range c.cache.GetALL(checkExpired) {
+		var val T
+		if v != nil {
+			val = v.(T)
+		}
+
+		if !f(k.(K), val) {
+			return
+		}
+	}
+}
+
 // Len implements the [Interface] interface for *LRU.  n may include items
 // that have expired, but have not yet been cleaned up.
 func (c *LRU[K, T]) Len() (n int) {
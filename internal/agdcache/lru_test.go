@@ -2,6 +2,7 @@ package agdcache_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
 	"github.com/stretchr/testify/assert"
@@ -35,3 +36,80 @@ func TestLRU(t *testing.T) {
 
 	assert.Equal(t, 0, cache.Len())
 }
+
+// testHostItem is a [agdcache.HostItem] for tests.
+type testHostItem string
+
+// type check
+var _ agdcache.HostItem = testHostItem("")
+
+// CachedHost implements the [agdcache.HostItem] interface for testHostItem.
+func (i testHostItem) CachedHost() (host string) {
+	return string(i)
+}
+
+func TestLRU_FlushHosts(t *testing.T) {
+	cache := agdcache.NewLRU[int, agdcache.HostItem](&agdcache.LRUConfig{
+		Count: 10,
+	})
+
+	cache.Set(1, testHostItem("example.com"))
+	cache.Set(2, testHostItem("www.example.com"))
+	cache.Set(3, testHostItem("example.org"))
+	cache.Set(4, nil)
+
+	n := cache.FlushHosts(func(host string) (ok bool) {
+		return host == "example.com"
+	})
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 3, cache.Len())
+
+	_, ok := cache.Get(1)
+	assert.False(t, ok)
+
+	_, ok = cache.Get(2)
+	assert.True(t, ok)
+
+	m := agdcache.NewDefaultManager()
+	m.Add("hosts", cache)
+	m.Add("empty", agdcache.Empty[any, any]{})
+
+	n, ok = m.FlushHostsByID("hosts", func(_ string) (ok bool) { return true })
+	assert.True(t, ok)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 1, cache.Len())
+
+	_, ok = m.FlushHostsByID("empty", func(_ string) (ok bool) { return true })
+	assert.False(t, ok)
+}
+
+func TestLRU_Range(t *testing.T) {
+	cache := agdcache.NewLRU[int, string](&agdcache.LRUConfig{
+		Count: 10,
+	})
+
+	cache.Set(1, "one")
+	cache.Set(2, "two")
+	cache.SetWithExpire(3, "three", time.Nanosecond)
+
+	// Make sure that the item expires.
+	time.Sleep(time.Millisecond)
+
+	got := map[int]string{}
+	cache.Range(func(key int, val string) (cont bool) {
+		got[key] = val
+
+		return true
+	})
+
+	assert.Equal(t, map[int]string{1: "one", 2: "two"}, got)
+
+	n := 0
+	cache.Range(func(_ int, _ string) (cont bool) {
+		n++
+
+		return false
+	})
+
+	assert.Equal(t, 1, n)
+}
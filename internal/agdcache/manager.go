@@ -56,6 +56,22 @@ func (m *DefaultManager) ClearByID(id string) {
 	}
 }
 
+// FlushHostsByID removes the items related to the hostnames for which match
+// returns true from the cache with the given id.  ok is false if there is no
+// such cache or if it doesn't implement [HostFlusher].  n is the number of
+// removed items.  match must not be nil.
+func (m *DefaultManager) FlushHostsByID(
+	id string,
+	match func(host string) (ok bool),
+) (n int, ok bool) {
+	f, ok := m.findByID(id).(HostFlusher)
+	if !ok {
+		return 0, false
+	}
+
+	return f.FlushHosts(match), true
+}
+
 // findByID returns the stored cache by id or nil.
 func (m *DefaultManager) findByID(id string) (cache Clearer) {
 	m.mu.Lock()
@@ -8,13 +8,23 @@ import "github.com/AdguardTeam/AdGuardDNS/internal/version"
 
 // Common Constants, Functions And Types
 
+// HTTP header constants that are missing from package httphdr.
+const (
+	HdrReferrerPolicy = "Referrer-Policy"
+	HdrXFrameOptions  = "X-Frame-Options"
+)
+
 // HTTP header value constants.
 const (
 	HdrValApplicationJSON        = "application/json"
 	HdrValApplicationOctetStream = "application/octet-stream"
+	HdrValDeny                   = "DENY"
 	HdrValGzip                   = "gzip"
+	HdrValNoReferrer             = "no-referrer"
+	HdrValNoSniff                = "nosniff"
 	HdrValTextCSV                = "text/csv"
 	HdrValTextHTML               = "text/html"
+	HdrValTextJavaScript         = "text/javascript"
 	HdrValTextPlain              = "text/plain"
 	HdrValWildcard               = "*"
 )
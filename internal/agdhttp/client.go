@@ -40,7 +40,20 @@ func NewClient(conf *ClientConfig) (c *Client) {
 // When err is nil, resp always contains a non-nil resp.Body.  Caller should
 // close resp.Body when done reading from it.
 func (c *Client) Get(ctx context.Context, u *url.URL) (resp *http.Response, err error) {
-	return c.do(ctx, http.MethodGet, u, "", nil)
+	return c.do(ctx, http.MethodGet, u, nil, "", nil)
+}
+
+// GetWithHeader is like [Client.Get] but also sets the headers from h, which
+// may be nil, on the request.
+//
+// When err is nil, resp always contains a non-nil resp.Body.  Caller should
+// close resp.Body when done reading from it.
+func (c *Client) GetWithHeader(
+	ctx context.Context,
+	u *url.URL,
+	h http.Header,
+) (resp *http.Response, err error) {
+	return c.do(ctx, http.MethodGet, u, h, "", nil)
 }
 
 // Post is a wrapper around [http.Client.Post].
@@ -53,7 +66,7 @@ func (c *Client) Post(
 	contentType string,
 	body io.Reader,
 ) (resp *http.Response, err error) {
-	return c.do(ctx, http.MethodPost, u, contentType, body)
+	return c.do(ctx, http.MethodPost, u, nil, contentType, body)
 }
 
 // Put is a wrapper around [http.Client.Put].
@@ -66,14 +79,15 @@ func (c *Client) Put(
 	contentType string,
 	body io.Reader,
 ) (resp *http.Response, err error) {
-	return c.do(ctx, http.MethodPut, u, contentType, body)
+	return c.do(ctx, http.MethodPut, u, nil, contentType, body)
 }
 
-// do is a wrapper around [http.Client.Do].
+// do is a wrapper around [http.Client.Do].  h may be nil.
 func (c *Client) do(
 	ctx context.Context,
 	method string,
 	u *url.URL,
+	h http.Header,
 	contentType string,
 	body io.Reader,
 ) (resp *http.Response, err error) {
@@ -82,6 +96,10 @@ func (c *Client) do(
 		return nil, fmt.Errorf("creating %s request to: %w", method, err)
 	}
 
+	for k, vals := range h {
+		req.Header[k] = vals
+	}
+
 	if contentType != "" {
 		req.Header.Set(httphdr.ContentType, contentType)
 	}
@@ -80,5 +80,7 @@ func NewSDEConfig(enabled bool) (c *dnsmsg.StructuredDNSErrorsConfig) {
 
 // NewCloner returns a standard dnsmsg.Cloner for tests.
 func NewCloner() (c *dnsmsg.Cloner) {
-	return dnsmsg.NewCloner(dnsmsg.EmptyClonerStat{})
+	return dnsmsg.NewCloner(&dnsmsg.ClonerConfig{
+		Stat: dnsmsg.EmptyClonerStat{},
+	})
 }
@@ -12,15 +12,22 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdpasswd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/auditlog"
 	"github.com/AdguardTeam/AdGuardDNS/internal/billstat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnscheck"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsdb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/ratelimit"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/popdomains"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnamelimit"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
 	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
 	"github.com/AdguardTeam/AdGuardDNS/internal/remotekv"
 	"github.com/AdguardTeam/AdGuardDNS/internal/rulestat"
@@ -127,6 +134,21 @@ func (c *Clock) Now() (now time.Time) {
 	return c.OnNow()
 }
 
+// Package auditlog
+
+// type check
+var _ auditlog.Interface = (*AuditLog)(nil)
+
+// AuditLog is an [auditlog.Interface] for tests.
+type AuditLog struct {
+	OnRecord func(ctx context.Context, e *auditlog.Event)
+}
+
+// Record implements the [auditlog.Interface] interface for *AuditLog.
+func (l *AuditLog) Record(ctx context.Context, e *auditlog.Event) {
+	l.OnRecord(ctx, e)
+}
+
 // Package billstat
 
 // type check
@@ -169,6 +191,56 @@ func (b *BillStatUploader) Upload(ctx context.Context, records billstat.Records)
 	return b.OnUpload(ctx, records)
 }
 
+// Package devicestat
+
+// type check
+var _ devicestat.Interface = (*DeviceStat)(nil)
+
+// DeviceStat is a [devicestat.Interface] for tests.
+type DeviceStat struct {
+	OnRecord func(
+		ctx context.Context,
+		id agd.DeviceID,
+		t time.Time,
+		fltID filter.ID,
+		blocked bool,
+		learned bool,
+	)
+	OnStats func(ctx context.Context, id agd.DeviceID) (s *devicestat.Stats)
+}
+
+// Record implements the [devicestat.Interface] interface for *DeviceStat.
+func (s *DeviceStat) Record(
+	ctx context.Context,
+	id agd.DeviceID,
+	t time.Time,
+	fltID filter.ID,
+	blocked bool,
+	learned bool,
+) {
+	s.OnRecord(ctx, id, t, fltID, blocked, learned)
+}
+
+// Stats implements the [devicestat.Interface] interface for *DeviceStat.
+func (s *DeviceStat) Stats(ctx context.Context, id agd.DeviceID) (stats *devicestat.Stats) {
+	return s.OnStats(ctx, id)
+}
+
+// Package dhcplease
+
+// type check
+var _ dhcplease.Interface = (*LeaseSource)(nil)
+
+// LeaseSource is a [dhcplease.Interface] for tests.
+type LeaseSource struct {
+	OnDeviceID func(ctx context.Context, ip netip.Addr) (id agd.DeviceID, err error)
+}
+
+// DeviceID implements the [dhcplease.Interface] interface for *LeaseSource.
+func (s *LeaseSource) DeviceID(ctx context.Context, ip netip.Addr) (id agd.DeviceID, err error) {
+	return s.OnDeviceID(ctx, ip)
+}
+
 // Package dnscheck
 
 // type check
@@ -256,6 +328,19 @@ func (f *Filter) FilterResponse(
 	return f.OnFilterResponse(ctx, resp)
 }
 
+// type check
+var _ filter.Classifier = (*Classifier)(nil)
+
+// Classifier is a [filter.Classifier] for tests.
+type Classifier struct {
+	OnClassify func(ctx context.Context, host string) (cats []filter.Category)
+}
+
+// Classify implements the [filter.Classifier] interface for *Classifier.
+func (c *Classifier) Classify(ctx context.Context, host string) (cats []filter.Category) {
+	return c.OnClassify(ctx, host)
+}
+
 // type check
 var _ filter.HashMatcher = (*HashMatcher)(nil)
 
@@ -334,6 +419,22 @@ func NewGeoIP() (c *GeoIP) {
 	}
 }
 
+// Package popdomains
+
+// type check
+var _ popdomains.Uploader = (*PopularDomainsUploader)(nil)
+
+// PopularDomainsUploader is a [popdomains.Uploader] for tests.
+type PopularDomainsUploader struct {
+	OnUpload func(ctx context.Context, s *popdomains.Statistics) (err error)
+}
+
+// Upload implements the [popdomains.Uploader] interface for
+// *PopularDomainsUploader.
+func (u *PopularDomainsUploader) Upload(ctx context.Context, s *popdomains.Statistics) (err error) {
+	return u.OnUpload(ctx, s)
+}
+
 // Package profiledb
 
 // type check
@@ -503,6 +604,45 @@ func (s *ProfileStorage) Profiles(
 	return s.OnProfiles(ctx, req)
 }
 
+// Package qnamelimit
+
+// type check
+var _ qnamelimit.Interface = (*QNAMELimiter)(nil)
+
+// QNAMELimiter is a [qnamelimit.Interface] for tests.
+type QNAMELimiter struct {
+	OnIsLimited func(ctx context.Context, key agd.ClientKey, host string) (limited bool)
+}
+
+// IsLimited implements the [qnamelimit.Interface] interface for
+// *QNAMELimiter.
+func (l *QNAMELimiter) IsLimited(ctx context.Context, key agd.ClientKey, host string) (limited bool) {
+	return l.OnIsLimited(ctx, key, host)
+}
+
+// Package quarantine
+
+// type check
+var _ quarantine.Interface = (*Quarantine)(nil)
+
+// Quarantine is a [quarantine.Interface] for tests.
+type Quarantine struct {
+	OnCheck func(
+		ctx context.Context,
+		d *agd.Device,
+		host string,
+	) (quarantined, allowed bool)
+}
+
+// Check implements the [quarantine.Interface] interface for *Quarantine.
+func (q *Quarantine) Check(
+	ctx context.Context,
+	d *agd.Device,
+	host string,
+) (quarantined, allowed bool) {
+	return q.OnCheck(ctx, d, host)
+}
+
 // Package querylog
 
 // type check
@@ -535,6 +675,29 @@ func (s *RuleStat) Collect(ctx context.Context, id filter.ID, text filter.RuleTe
 
 // Module dnsserver
 
+// Package dnsserver
+
+// type check
+var _ dnsserver.QUICAdvisor = (*QUICAdvisor)(nil)
+
+// QUICAdvisor is a [dnsserver.QUICAdvisor] for tests.
+type QUICAdvisor struct {
+	OnOnQUICHandshake func(ctx context.Context, addr netip.Addr, ok bool)
+	OnIsQUICBlocked   func(ctx context.Context, addr netip.Addr) (blocked bool)
+}
+
+// OnQUICHandshake implements the [dnsserver.QUICAdvisor] interface for
+// *QUICAdvisor.
+func (a *QUICAdvisor) OnQUICHandshake(ctx context.Context, addr netip.Addr, ok bool) {
+	a.OnOnQUICHandshake(ctx, addr, ok)
+}
+
+// IsQUICBlocked implements the [dnsserver.QUICAdvisor] interface for
+// *QUICAdvisor.
+func (a *QUICAdvisor) IsQUICBlocked(ctx context.Context, addr netip.Addr) (blocked bool) {
+	return a.OnIsQUICBlocked(ctx, addr)
+}
+
 // Package netext
 
 var _ netext.ListenConfig = (*ListenConfig)(nil)
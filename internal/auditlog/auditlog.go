@@ -0,0 +1,109 @@
+// Package auditlog contains the append-only log of the events that change the
+// state of AdGuard DNS, such as profile refreshes and certificate changes.
+// Every entry of the log contains the hash of the previous one, which makes it
+// possible to detect removed or modified entries.
+package auditlog
+
+import (
+	"context"
+)
+
+// EventType is the type of an audit-log event.
+type EventType string
+
+// Valid event types.
+const (
+	// EventTypeDebugAPI is the type of the events recorded on invocations of
+	// the debug HTTP API.  The data of such events is [*DebugAPI].
+	EventTypeDebugAPI EventType = "debug_api"
+
+	// EventTypeProfilesRefresh is the type of the events recorded when a
+	// profile refresh is applied.  The data of such events is
+	// [*ProfilesRefresh].
+	EventTypeProfilesRefresh EventType = "profiles_refresh"
+
+	// EventTypeTLSCertificate is the type of the events recorded when a TLS
+	// certificate is changed.  The data of such events is [*TLSCertificate].
+	EventTypeTLSCertificate EventType = "tls_certificate"
+
+	// EventTypeTLSSessionTickets is the type of the events recorded when the
+	// TLS session tickets are rotated.  The data of such events is
+	// [*TLSSessionTickets].
+	EventTypeTLSSessionTickets EventType = "tls_session_tickets"
+)
+
+// Event is an event that should be recorded in the audit log.
+type Event struct {
+	// Data is the data of the event.  It must be serializable to JSON.  See
+	// the documentation of the event types for the types of data.
+	Data any
+
+	// Type is the type of the event.  It must be one of the EventType*
+	// constants.
+	Type EventType
+}
+
+// DebugAPI is the data of an [EventTypeDebugAPI] event.
+type DebugAPI struct {
+	// Method is the HTTP method of the request.
+	Method string `json:"method"`
+
+	// Path is the path of the request URL.
+	Path string `json:"path"`
+
+	// RemoteAddr is the address of the client that sent the request.
+	RemoteAddr string `json:"remote_addr"`
+}
+
+// ProfilesRefresh is the data of an [EventTypeProfilesRefresh] event.
+type ProfilesRefresh struct {
+	// ProfilesNum is the number of profiles received from the storage.
+	ProfilesNum uint `json:"profiles_num"`
+
+	// DevicesNum is the number of devices received from the storage.
+	DevicesNum uint `json:"devices_num"`
+
+	// RemovedDevicesNum is the number of devices that have been removed by
+	// the refresh.
+	RemovedDevicesNum uint `json:"removed_devices_num"`
+
+	// IsFullSync is true if the refresh was a full synchronization.
+	IsFullSync bool `json:"is_full_sync"`
+}
+
+// TLSCertificate is the data of an [EventTypeTLSCertificate] event.
+type TLSCertificate struct {
+	// CertPath is the path to the certificate file.
+	CertPath string `json:"cert_path"`
+
+	// KeyPath is the path to the private-key file.
+	KeyPath string `json:"key_path"`
+}
+
+// TLSSessionTickets is the data of an [EventTypeTLSSessionTickets] event.
+type TLSSessionTickets struct {
+	// KeysNum is the number of the session ticket keys.
+	KeysNum int `json:"keys_num"`
+
+	// KeysHash is the hash of the session ticket keys.
+	KeysHash uint32 `json:"keys_hash"`
+
+	// KeysChanged is true if the keys differ from the previous ones.
+	KeysChanged bool `json:"keys_changed"`
+}
+
+// Interface is the audit log.  All methods must be safe for concurrent use.
+type Interface interface {
+	// Record appends e to the log.  e must not be nil.  The errors are
+	// reported by the implementation.
+	Record(ctx context.Context, e *Event)
+}
+
+// type check
+var _ Interface = Empty{}
+
+// Empty is an [Interface] implementation that does nothing.
+type Empty struct{}
+
+// Record implements the [Interface] interface for Empty.
+func (Empty) Record(_ context.Context, _ *Event) {}
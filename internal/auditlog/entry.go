@@ -0,0 +1,128 @@
+package auditlog
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// maxEntrySize is the maximum size of a single serialized entry.
+const maxEntrySize = 1024 * 1024
+
+// Entry is a single entry of the audit log as it is stored in the log file,
+// one JSON object per line.
+type Entry struct {
+	// Time is the time when the event was recorded, in UTC.
+	Time time.Time `json:"time"`
+
+	// Type is the type of the event.
+	Type EventType `json:"type"`
+
+	// PrevHash is the hash of the previous entry.  It is empty for the first
+	// entry of the log.
+	PrevHash string `json:"prev_hash"`
+
+	// Hash is the hex-encoded SHA-256 hash of the JSON form of the entry
+	// with an empty Hash, which includes PrevHash.
+	Hash string `json:"hash"`
+
+	// Data is the JSON-encoded data of the event.
+	Data json.RawMessage `json:"data"`
+
+	// Seq is the sequence number of the entry, starting with 1.
+	Seq uint64 `json:"seq"`
+}
+
+// sum returns the hash of e that must be stored in its Hash field.
+func (e *Entry) sum() (h string, err error) {
+	c := *e
+	c.Hash = ""
+
+	b, err := json.Marshal(&c)
+	if err != nil {
+		return "", fmt.Errorf("encoding entry: %w", err)
+	}
+
+	sum := sha256.Sum256(b)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Errors returned by [Verify].
+const (
+	errBadHash     errors.Error = "bad hash"
+	errBadPrevHash errors.Error = "bad previous hash"
+	errBadSeq      errors.Error = "bad sequence number"
+)
+
+// Verify reads the audit log from r and checks the hash chain of its entries.
+// last is the last entry that could be decoded, if any.  err is not nil if the
+// chain is broken, in which case it contains the errors about every broken
+// line.
+func Verify(r io.Reader) (last *Entry, err error) {
+	return verify(r, func(_ *Entry) {})
+}
+
+// verify reads the audit log from r, checks the hash chain of its entries, and
+// calls onEntry for every entry that could be decoded.  See [Verify].
+func verify(r io.Reader, onEntry func(e *Entry)) (last *Entry, err error) {
+	var errs []error
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxEntrySize)
+	for lineNum := 1; s.Scan(); lineNum++ {
+		e := &Entry{}
+		err = json.Unmarshal(s.Bytes(), e)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: decoding: %w", lineNum, err))
+
+			continue
+		}
+
+		err = checkEntry(e, last)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", lineNum, err))
+		}
+
+		onEntry(e)
+		last = e
+	}
+
+	err = s.Err()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("reading: %w", err))
+	}
+
+	return last, errors.Join(errs...)
+}
+
+// checkEntry returns an error if e doesn't follow prev in the hash chain.  prev
+// is nil if e is the first entry.
+func checkEntry(e, prev *Entry) (err error) {
+	var wantPrevHash string
+	var wantSeq uint64 = 1
+	if prev != nil {
+		wantPrevHash, wantSeq = prev.Hash, prev.Seq+1
+	}
+
+	if e.Seq != wantSeq {
+		return fmt.Errorf("%w: got %d, want %d", errBadSeq, e.Seq, wantSeq)
+	} else if e.PrevHash != wantPrevHash {
+		return fmt.Errorf("seq %d: %w", e.Seq, errBadPrevHash)
+	}
+
+	h, err := e.sum()
+	if err != nil {
+		return fmt.Errorf("seq %d: %w", e.Seq, err)
+	} else if h != e.Hash {
+		return fmt.Errorf("seq %d: %w", e.Seq, errBadHash)
+	}
+
+	return nil
+}
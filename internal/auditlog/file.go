@@ -0,0 +1,183 @@
+package auditlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// FileConfig is the configuration of the file audit log.
+type FileConfig struct {
+	// Logger is used to log the operation of the audit log.  It must not be
+	// nil.
+	Logger *slog.Logger
+
+	// Clock is used to get the time of the entries.  It must not be nil.
+	Clock agdtime.Clock
+
+	// ErrColl is used to collect the errors of reading and writing the log.
+	// It must not be nil.
+	ErrColl errcoll.Interface
+
+	// Path is the path to the log file.  It must not be empty.
+	Path string
+
+	// RecentCount is the number of the most recent entries kept in memory for
+	// [File.Recent].  It must not be negative.
+	RecentCount int
+}
+
+// File is the audit log that appends the entries to a file, one JSON object
+// per line.
+type File struct {
+	logger  *slog.Logger
+	clock   agdtime.Clock
+	errColl errcoll.Interface
+
+	// mu protects recent, lastHash, and lastSeq and serializes the writes to
+	// the file.
+	mu *sync.Mutex
+
+	// lastHash is the hash of the last entry written to the file.
+	lastHash string
+
+	path string
+
+	// recent are the most recent entries, the oldest first.
+	recent []*Entry
+
+	// lastSeq is the sequence number of the last entry written to the file.
+	lastSeq uint64
+
+	recentCount int
+}
+
+// NewFile returns a new file audit log.  If the file already exists, NewFile
+// reads it to continue the hash chain and reports a broken chain to
+// c.ErrColl.  c must not be nil.
+func NewFile(ctx context.Context, c *FileConfig) (l *File, err error) {
+	l = &File{
+		logger:      c.Logger,
+		clock:       c.Clock,
+		errColl:     c.ErrColl,
+		mu:          &sync.Mutex{},
+		recent:      make([]*Entry, 0, c.RecentCount),
+		path:        c.Path,
+		recentCount: c.RecentCount,
+	}
+
+	f, err := os.Open(c.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("opening audit log file: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+	last, err := verify(f, l.addRecent)
+	if err != nil {
+		errcoll.Collect(ctx, l.errColl, l.logger, "verifying audit log", err)
+	}
+
+	if last != nil {
+		l.lastHash, l.lastSeq = last.Hash, last.Seq
+	}
+
+	l.logger.InfoContext(ctx, "loaded audit log", "last_seq", l.lastSeq)
+
+	return l, nil
+}
+
+// type check
+var _ Interface = (*File)(nil)
+
+// Record implements the [Interface] interface for *File.
+func (l *File) Record(ctx context.Context, e *Event) {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		errcoll.Collect(ctx, l.errColl, l.logger, "encoding audit event", err)
+
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ent := &Entry{
+		Time:     l.clock.Now().UTC(),
+		Data:     data,
+		Type:     e.Type,
+		PrevHash: l.lastHash,
+		Seq:      l.lastSeq + 1,
+	}
+
+	err = l.write(ent)
+	if err != nil {
+		errcoll.Collect(ctx, l.errColl, l.logger, "writing audit log", err)
+
+		return
+	}
+
+	l.lastHash, l.lastSeq = ent.Hash, ent.Seq
+	l.addRecent(ent)
+}
+
+// write sets the hash of ent and appends it to the log file.  l.mu must be
+// locked.
+func (l *File) write(ent *Entry) (err error) {
+	ent.Hash, err = ent.sum()
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	b, err := json.Marshal(ent)
+	if err != nil {
+		return fmt.Errorf("encoding entry: %w", err)
+	}
+
+	f, err := os.OpenFile(l.path, agd.DefaultWOFlags, agd.DefaultPerm)
+	if err != nil {
+		return fmt.Errorf("opening audit log file: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+	_, err = f.Write(append(b, '\n'))
+	if err != nil {
+		return fmt.Errorf("writing entry: %w", err)
+	}
+
+	return nil
+}
+
+// addRecent adds ent to the recent entries, removing the oldest one if there
+// are too many.  l.mu must be locked, unless l is being constructed.
+func (l *File) addRecent(ent *Entry) {
+	if l.recentCount == 0 {
+		return
+	}
+
+	if len(l.recent) == l.recentCount {
+		l.recent = slices.Delete(l.recent, 0, 1)
+	}
+
+	l.recent = append(l.recent, ent)
+}
+
+// Recent returns the most recent entries of the log, the oldest first.  The
+// entries must not be modified.
+func (l *File) Recent() (entries []*Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return slices.Clone(l.recent)
+}
@@ -0,0 +1,153 @@
+package auditlog_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/auditlog"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// newTestFile is a helper that returns a new file audit log writing to path.
+func newTestFile(tb testing.TB, path string) (l *auditlog.File) {
+	tb.Helper()
+
+	ctx := testutil.ContextWithTimeout(tb, testTimeout)
+	l, err := auditlog.NewFile(ctx, &auditlog.FileConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		Clock: &agdtest.Clock{
+			OnNow: func() (now time.Time) {
+				return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			},
+		},
+		ErrColl:     agdtest.NewErrorCollector(),
+		Path:        path,
+		RecentCount: 2,
+	})
+	require.NoError(tb, err)
+
+	return l
+}
+
+// recordTestEvents is a helper that records n events in l.
+func recordTestEvents(ctx context.Context, l *auditlog.File, n int) {
+	for i := range n {
+		l.Record(ctx, &auditlog.Event{
+			Data: &auditlog.ProfilesRefresh{
+				ProfilesNum: uint(i),
+			},
+			Type: auditlog.EventTypeProfilesRefresh,
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	l := newTestFile(t, path)
+	recordTestEvents(ctx, l, 3)
+
+	recent := l.Recent()
+	require.Len(t, recent, 2)
+
+	assert.Equal(t, uint64(2), recent[0].Seq)
+	assert.Equal(t, uint64(3), recent[1].Seq)
+	assert.Equal(t, recent[0].Hash, recent[1].PrevHash)
+	assert.JSONEq(t, `{
+		"profiles_num": 2,
+		"devices_num": 0,
+		"removed_devices_num": 0,
+		"is_full_sync": false
+	}`, string(recent[1].Data))
+
+	// Make sure that a new log continues the chain.
+	l = newTestFile(t, path)
+	require.Equal(t, recent, l.Recent())
+
+	recordTestEvents(ctx, l, 1)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, f.Close)
+
+	last, err := auditlog.Verify(f)
+	require.NoError(t, err)
+	require.NotNil(t, last)
+
+	assert.Equal(t, uint64(4), last.Seq)
+	assert.Equal(t, recent[1].Hash, last.PrevHash)
+}
+
+func TestVerify(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	l := newTestFile(t, path)
+	recordTestEvents(ctx, l, 3)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	require.Len(t, lines, 4)
+
+	testCases := []struct {
+		name       string
+		wantErrMsg string
+		data       []byte
+	}{{
+		name:       "valid",
+		wantErrMsg: "",
+		data:       data,
+	}, {
+		name:       "empty",
+		wantErrMsg: "",
+		data:       nil,
+	}, {
+		name:       "modified",
+		wantErrMsg: "line 2: seq 2: bad hash",
+		data: bytes.Replace(
+			data,
+			[]byte(`"profiles_num":1`),
+			[]byte(`"profiles_num":5`),
+			1,
+		),
+	}, {
+		name:       "removed",
+		wantErrMsg: "line 2: bad sequence number: got 3, want 2",
+		data:       bytes.Join([][]byte{lines[0], lines[2]}, nil),
+	}, {
+		name:       "removed_first",
+		wantErrMsg: "line 1: bad sequence number: got 2, want 1",
+		data:       bytes.Join([][]byte{lines[1], lines[2]}, nil),
+	}, {
+		name:       "bad_json",
+		wantErrMsg: "line 2: decoding: unexpected end of JSON input",
+		data:       bytes.Join([][]byte{lines[0], []byte("{\n"), lines[1]}, nil),
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, vErr := auditlog.Verify(bytes.NewReader(tc.data))
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, vErr)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package backendpb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/access"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/golibs/errors"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// StandardAccessUpdaterConfig is the configuration structure for the business
+// logic backend standard access settings updater.
+type StandardAccessUpdaterConfig struct {
+	// Logger is used for logging the operation of the updater.  It must not be
+	// nil.
+	Logger *slog.Logger
+
+	// GRPCMetrics is used for the collection of the protobuf communication
+	// statistics.
+	GRPCMetrics GRPCMetrics
+
+	// Blocker is the standard access blocker to update.  It must not be nil.
+	Blocker *access.StandardBlocker
+
+	// ErrColl is used to collect errors during refreshes.
+	ErrColl errcoll.Interface
+
+	// Endpoint is the backend API URL.  The scheme should be either "grpc" or
+	// "grpcs".  It must not be nil.
+	Endpoint *url.URL
+
+	// APIKey is the API key used for authentication, if any.  If empty, no
+	// authentication is performed.
+	APIKey string
+}
+
+// StandardAccessUpdater is the implementation of the [agdservice.Refresher]
+// interface that retrieves the standard access rules from the business logic
+// backend.  After the first refresh, it only requests the changes since the
+// previous one.
+type StandardAccessUpdater struct {
+	logger      *slog.Logger
+	grpcMetrics GRPCMetrics
+	blocker     *access.StandardBlocker
+	errColl     errcoll.Interface
+	client      AccessServiceClient
+
+	// mu protects lastUpdate and prevents concurrent refreshes.
+	mu *sync.Mutex
+
+	// lastUpdate is the update time returned by the backend with the previous
+	// successful response.  It is nil if there were no such responses.
+	lastUpdate *timestamppb.Timestamp
+
+	apiKey string
+}
+
+// NewStandardAccessUpdater creates a new properly initialized standard access
+// settings updater.  c must not be nil.
+func NewStandardAccessUpdater(
+	c *StandardAccessUpdaterConfig,
+) (u *StandardAccessUpdater, err error) {
+	client, err := newClient(c.Endpoint)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	return &StandardAccessUpdater{
+		logger:      c.Logger,
+		grpcMetrics: c.GRPCMetrics,
+		blocker:     c.Blocker,
+		errColl:     c.ErrColl,
+		client:      NewAccessServiceClient(client),
+		mu:          &sync.Mutex{},
+		apiKey:      c.APIKey,
+	}, nil
+}
+
+// type check
+var _ agdservice.Refresher = (*StandardAccessUpdater)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for
+// *StandardAccessUpdater.
+func (u *StandardAccessUpdater) Refresh(ctx context.Context) (err error) {
+	u.logger.InfoContext(ctx, "refresh started")
+	defer u.logger.InfoContext(ctx, "refresh finished")
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	ctx = ctxWithAuthentication(ctx, u.apiKey)
+	backendResp, err := u.client.GetStandardAccessSettings(ctx, &StandardAccessSettingsRequest{
+		LastUpdateTime: u.lastUpdate,
+	})
+	if err != nil {
+		return fmt.Errorf(
+			"loading backend standard access settings: %w",
+			fixGRPCError(ctx, u.grpcMetrics, err),
+		)
+	}
+
+	rules := make([]*access.StandardRule, 0, len(backendResp.Rules))
+	for i, r := range backendResp.Rules {
+		rule, convErr := r.toInternal()
+		if convErr != nil {
+			convErr = fmt.Errorf("rule at index %d: %w", i, convErr)
+			errcoll.Collect(ctx, u.errColl, u.logger, "converting standard access rules", convErr)
+
+			continue
+		}
+
+		rules = append(rules, rule)
+	}
+
+	u.blocker.Update(ctx, rules, backendResp.DeletedRuleIds, backendResp.IsFull)
+	u.lastUpdate = backendResp.UpdateTime
+
+	u.logger.InfoContext(
+		ctx,
+		"refresh successful",
+		"num_records", len(rules),
+		"num_deleted", len(backendResp.DeletedRuleIds),
+		"is_full", backendResp.IsFull,
+	)
+
+	return nil
+}
+
+// toInternal converts the protobuf standard access rule to the internal one.
+// x must not be nil.
+func (x *StandardAccessRule) toInternal() (r *access.StandardRule, err error) {
+	if x.Id == "" {
+		return nil, fmt.Errorf("id: %w", errors.ErrEmptyValue)
+	}
+
+	defer func() { err = errors.Annotate(err, "rule %q: %w", x.Id) }()
+
+	if x.Rule == "" {
+		return nil, fmt.Errorf("rule: %w", errors.ErrEmptyValue)
+	}
+
+	var start, end time.Time
+	if st := x.StartTime; st != nil {
+		start = st.AsTime()
+	}
+
+	if et := x.EndTime; et != nil {
+		end = et.AsTime()
+		if !end.After(start) {
+			return nil, fmt.Errorf("end_time: %w: must be after start_time", errors.ErrOutOfRange)
+		}
+	}
+
+	return &access.StandardRule{
+		Start: start,
+		End:   end,
+		ID:    x.Id,
+		Text:  x.Rule,
+	}, nil
+}
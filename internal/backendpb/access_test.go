@@ -0,0 +1,157 @@
+package backendpb_test
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/access"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/backendpb"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// testAccessServiceServer is the [backendpb.AccessServiceServer] for tests.
+type testAccessServiceServer struct {
+	backendpb.UnimplementedAccessServiceServer
+
+	OnGetStandardAccessSettings func(
+		ctx context.Context,
+		req *backendpb.StandardAccessSettingsRequest,
+	) (resp *backendpb.StandardAccessSettingsResponse, err error)
+}
+
+// type check
+var _ backendpb.AccessServiceServer = (*testAccessServiceServer)(nil)
+
+// GetStandardAccessSettings implements the [backendpb.AccessServiceServer]
+// interface for *testAccessServiceServer.
+func (s *testAccessServiceServer) GetStandardAccessSettings(
+	ctx context.Context,
+	req *backendpb.StandardAccessSettingsRequest,
+) (resp *backendpb.StandardAccessSettingsResponse, err error) {
+	return s.OnGetStandardAccessSettings(ctx, req)
+}
+
+func TestStandardAccessUpdater_Refresh(t *testing.T) {
+	const (
+		ruleID      = "rule1"
+		badRuleID   = "bad_rule"
+		badTimesID  = "bad_times"
+		newRuleID   = "rule2"
+		blockedHost = "blocked.example"
+		newHost     = "new.example"
+	)
+
+	now := time.Now()
+	updTime := timestamppb.New(now)
+
+	var reqs []*backendpb.StandardAccessSettingsRequest
+	srv := &testAccessServiceServer{
+		OnGetStandardAccessSettings: func(
+			_ context.Context,
+			req *backendpb.StandardAccessSettingsRequest,
+		) (resp *backendpb.StandardAccessSettingsResponse, err error) {
+			reqs = append(reqs, req)
+			if req.LastUpdateTime != nil {
+				return &backendpb.StandardAccessSettingsResponse{
+					Rules: []*backendpb.StandardAccessRule{{
+						Id:   newRuleID,
+						Rule: "||" + newHost + "^",
+					}},
+					DeletedRuleIds: []string{ruleID},
+					UpdateTime:     updTime,
+				}, nil
+			}
+
+			return &backendpb.StandardAccessSettingsResponse{
+				Rules: []*backendpb.StandardAccessRule{{
+					Id:      ruleID,
+					Rule:    "||" + blockedHost + "^",
+					EndTime: timestamppb.New(now.Add(time.Hour)),
+				}, {
+					Id: badRuleID,
+				}, {
+					Id:        badTimesID,
+					Rule:      "||bad.example^",
+					StartTime: timestamppb.New(now),
+					EndTime:   timestamppb.New(now.Add(-time.Hour)),
+				}},
+				UpdateTime: updTime,
+				IsFull:     true,
+			}, nil
+		},
+	}
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	grpcSrv := grpc.NewServer(
+		grpc.ConnectionTimeout(1*time.Second),
+		grpc.Creds(insecure.NewCredentials()),
+	)
+	backendpb.RegisterAccessServiceServer(grpcSrv, srv)
+
+	go func() {
+		pt := testutil.PanicT{}
+
+		srvErr := grpcSrv.Serve(ln)
+		require.NoError(pt, srvErr)
+	}()
+	t.Cleanup(grpcSrv.GracefulStop)
+
+	var collected []error
+	errColl := agdtest.NewErrorCollector()
+	errColl.OnCollect = func(_ context.Context, err error) {
+		collected = append(collected, err)
+	}
+
+	blocker := access.NewStandardBlocker(&access.StandardBlockerConfig{
+		Logger: backendpb.TestLogger,
+		Clock: &agdtest.Clock{
+			OnNow: func() (n time.Time) { return now },
+		},
+	})
+
+	u, err := backendpb.NewStandardAccessUpdater(&backendpb.StandardAccessUpdaterConfig{
+		Logger:      backendpb.TestLogger,
+		GRPCMetrics: backendpb.EmptyGRPCMetrics{},
+		Blocker:     blocker,
+		ErrColl:     errColl,
+		Endpoint: &url.URL{
+			Scheme: "grpc",
+			Host:   ln.Addr().String(),
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	err = u.Refresh(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, collected, 2)
+	assert.ErrorContains(t, collected[0], badRuleID)
+	assert.ErrorContains(t, collected[1], badTimesID)
+
+	assert.True(t, blocker.IsBlockedHost(blockedHost, dns.TypeA))
+	assert.False(t, blocker.IsBlockedHost("bad.example", dns.TypeA))
+	assert.False(t, blocker.IsBlockedHost(newHost, dns.TypeA))
+
+	err = u.Refresh(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, reqs, 2)
+	assert.Nil(t, reqs[0].LastUpdateTime)
+	assert.True(t, updTime.AsTime().Equal(reqs[1].LastUpdateTime.AsTime()))
+
+	assert.False(t, blocker.IsBlockedHost(blockedHost, dns.TypeA))
+	assert.True(t, blocker.IsBlockedHost(newHost, dns.TypeA))
+}
@@ -27,6 +27,12 @@ const (
 // TestUpdTime is the common update time for tests.
 var TestUpdTime = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
 
+// TestDeviceCreatedAt is the common device creation time for tests.
+var TestDeviceCreatedAt = time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+
+// TestLearningPeriod is the common learning period of devices for tests.
+const TestLearningPeriod = 7 * 24 * time.Hour
+
 // TestBind includes any IPv4 address.
 //
 // TODO(a.garipov):  Add to golibs/netutil.
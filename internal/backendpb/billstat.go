@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"maps"
 	"net/url"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/billstat"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
 	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -35,6 +37,14 @@ type BillStatConfig struct {
 
 	// APIKey is the API key used for authentication, if any.
 	APIKey string
+
+	// ChunkSize is the maximum number of records uploaded over a single stream.
+	// If it is zero, all records are uploaded over a single stream.
+	ChunkSize int
+
+	// MaxRetries is the maximum number of times the upload of a chunk is
+	// retried after a failure.
+	MaxRetries uint
 }
 
 // BillStat is the implementation of the [billstat.Uploader] interface that
@@ -49,6 +59,8 @@ type BillStat struct {
 	grpcMetrics GRPCMetrics
 	client      DNSServiceClient
 	apiKey      string
+	chunkSize   int
+	maxRetries  uint
 }
 
 // NewBillStat creates a new billing statistics uploader.  c must not be nil.
@@ -65,32 +77,102 @@ func NewBillStat(c *BillStatConfig) (b *BillStat, err error) {
 		grpcMetrics: c.GRPCMetrics,
 		client:      NewDNSServiceClient(client),
 		apiKey:      c.APIKey,
+		chunkSize:   c.ChunkSize,
+		maxRetries:  c.MaxRetries,
 	}, nil
 }
 
 // type check
 var _ billstat.Uploader = (*BillStat)(nil)
 
-// Upload implements the [billstat.Uploader] interface for *BillStat.
+// Upload implements the [billstat.Uploader] interface for *BillStat.  The
+// records are uploaded in chunks, each over its own stream, and the upload of
+// each chunk is retried on failure.  If only some of the chunks fail to upload,
+// err is a [*billstat.PartialUploadError].
 func (b *BillStat) Upload(ctx context.Context, records billstat.Records) (err error) {
 	if len(records) == 0 {
 		return nil
 	}
 
 	ctx = ctxWithAuthentication(ctx, b.apiKey)
-	stream, err := b.client.SaveDevicesBillingStat(ctx)
-	if err != nil {
-		return fmt.Errorf("opening stream: %w", fixGRPCError(ctx, b.grpcMetrics, err))
+
+	var errs []error
+	failed := billstat.Records{}
+	for _, chunk := range b.chunks(ctx, records) {
+		err = b.uploadChunkWithRetries(ctx, chunk)
+		if err != nil {
+			errs = append(errs, err)
+			maps.Copy(failed, chunk)
+		}
 	}
 
+	switch {
+	case len(errs) == 0:
+		return nil
+	case len(failed) == len(records):
+		return errors.Join(errs...)
+	default:
+		return &billstat.PartialUploadError{
+			Err:    errors.Join(errs...),
+			Failed: failed,
+		}
+	}
+}
+
+// chunks splits the non-nil records into chunks of at most b.chunkSize
+// records.  The nil records are reported to the error collector.
+func (b *BillStat) chunks(
+	ctx context.Context,
+	records billstat.Records,
+) (chunks []billstat.Records) {
+	chunk := billstat.Records{}
 	for deviceID, record := range records {
 		if record == nil {
-			err = fmt.Errorf("device %q: null record", deviceID)
+			err := fmt.Errorf("device %q: null record", deviceID)
 			errcoll.Collect(ctx, b.errColl, b.logger, "uploading records", err)
 
 			continue
 		}
 
+		chunk[deviceID] = record
+		if b.chunkSize > 0 && len(chunk) == b.chunkSize {
+			chunks = append(chunks, chunk)
+			chunk = billstat.Records{}
+		}
+	}
+
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// uploadChunkWithRetries uploads chunk, retrying it up to b.maxRetries times.
+func (b *BillStat) uploadChunkWithRetries(
+	ctx context.Context,
+	chunk billstat.Records,
+) (err error) {
+	for i := uint(0); ; i++ {
+		err = b.uploadChunk(ctx, chunk)
+		if err == nil || i >= b.maxRetries || ctx.Err() != nil {
+			// Don't wrap the error, because it's informative enough as is.
+			return err
+		}
+
+		b.logger.DebugContext(ctx, "retrying chunk", "attempt", i+1, slogutil.KeyError, err)
+	}
+}
+
+// uploadChunk uploads chunk over a single stream.  All records in chunk must
+// not be nil.
+func (b *BillStat) uploadChunk(ctx context.Context, chunk billstat.Records) (err error) {
+	stream, err := b.client.SaveDevicesBillingStat(ctx)
+	if err != nil {
+		return fmt.Errorf("opening stream: %w", fixGRPCError(ctx, b.grpcMetrics, err))
+	}
+
+	for deviceID, record := range chunk {
 		sendErr := stream.Send(recordToProtobuf(record, deviceID))
 		if sendErr != nil {
 			return fmt.Errorf(
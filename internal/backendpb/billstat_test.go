@@ -5,6 +5,7 @@ import (
 	"io"
 	"net"
 	"net/url"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -18,7 +19,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -121,3 +124,105 @@ func TestBillStat_Upload(t *testing.T) {
 	err = b.Upload(ctx, records)
 	require.NoError(t, err)
 }
+
+func TestBillStat_Upload_partial(t *testing.T) {
+	t.Parallel()
+
+	const (
+		okDeviceID   = "ok"
+		failDeviceID = "fail"
+
+		maxRetries = 2
+	)
+
+	record := &billstat.Record{
+		Country: geoip.CountryCY,
+		ASN:     1221,
+		Queries: 1,
+		Proto:   agd.ProtoDNS,
+	}
+
+	records := billstat.Records{
+		okDeviceID:   record,
+		failDeviceID: record,
+	}
+
+	var failAttempts atomic.Uint32
+	srv := &testDNSServiceServer{
+		OnCreateDeviceByHumanId: func(
+			ctx context.Context,
+			req *backendpb.CreateDeviceRequest,
+		) (resp *backendpb.CreateDeviceResponse, err error) {
+			panic("not implemented")
+		},
+
+		OnGetDNSProfiles: func(
+			req *backendpb.DNSProfilesRequest,
+			srv grpc.ServerStreamingServer[backendpb.DNSProfile],
+		) (err error) {
+			panic("not implemented")
+		},
+
+		OnSaveDevicesBillingStat: func(
+			srv grpc.ClientStreamingServer[backendpb.DeviceBillingStat, emptypb.Empty],
+		) (err error) {
+			for {
+				data, recvErr := srv.Recv()
+				if recvErr != nil {
+					if errors.Is(recvErr, io.EOF) {
+						return srv.SendAndClose(&emptypb.Empty{})
+					}
+
+					return recvErr
+				}
+
+				if data.DeviceId == failDeviceID {
+					failAttempts.Add(1)
+
+					return status.Error(codes.Unavailable, "test error")
+				}
+			}
+		},
+	}
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	grpcSrv := grpc.NewServer(
+		grpc.ConnectionTimeout(1*time.Second),
+		grpc.Creds(insecure.NewCredentials()),
+	)
+	backendpb.RegisterDNSServiceServer(grpcSrv, srv)
+
+	go func() {
+		pt := &testutil.PanicT{}
+
+		srvErr := grpcSrv.Serve(l)
+		require.NoError(pt, srvErr)
+	}()
+	t.Cleanup(grpcSrv.GracefulStop)
+
+	b, err := backendpb.NewBillStat(&backendpb.BillStatConfig{
+		Logger:      backendpb.TestLogger,
+		ErrColl:     agdtest.NewErrorCollector(),
+		GRPCMetrics: backendpb.EmptyGRPCMetrics{},
+		Endpoint: &url.URL{
+			Scheme: "grpc",
+			Host:   l.Addr().String(),
+		},
+		ChunkSize:  1,
+		MaxRetries: maxRetries,
+	})
+	require.NoError(t, err)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	err = b.Upload(ctx, records)
+	require.Error(t, err)
+
+	partErr := &billstat.PartialUploadError{}
+	require.ErrorAs(t, err, &partErr)
+
+	assert.Equal(t, billstat.Records{failDeviceID: record}, partErr.Failed)
+	assert.Equal(t, uint32(maxRetries+1), failAttempts.Load())
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/netip"
+	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdpasswd"
@@ -73,6 +74,11 @@ func (ds *DeviceSettings) toInternal(bindSet netutil.SubnetSet) (dev *agd.Device
 		return nil, fmt.Errorf("dedicated ips: %w", err)
 	}
 
+	dedicatedPrefixes, err := ds.dedicatedPrefixesToInternal(bindSet)
+	if err != nil {
+		return nil, fmt.Errorf("dedicated prefixes: %w", err)
+	}
+
 	auth, err := ds.Authentication.toInternal()
 	if err != nil {
 		return nil, fmt.Errorf("auth: %w", err)
@@ -96,14 +102,22 @@ func (ds *DeviceSettings) toInternal(bindSet netutil.SubnetSet) (dev *agd.Device
 		}
 	}
 
+	var createdAt time.Time
+	if ca := ds.CreatedAt; ca != nil {
+		createdAt = ca.AsTime()
+	}
+
 	return &agd.Device{
-		Auth:             auth,
-		ID:               id,
-		Name:             name,
-		HumanIDLower:     humanID,
-		LinkedIP:         linkedIP,
-		DedicatedIPs:     dedicatedIPs,
-		FilteringEnabled: ds.FilteringEnabled,
+		Auth:              auth,
+		ID:                id,
+		CreatedAt:         createdAt,
+		Name:              name,
+		HumanIDLower:      humanID,
+		LinkedIP:          linkedIP,
+		DedicatedIPs:      dedicatedIPs,
+		DedicatedPrefixes: dedicatedPrefixes,
+		FilteringEnabled:  ds.FilteringEnabled,
+		Quarantined:       ds.Quarantined,
 	}, nil
 }
 
@@ -128,6 +142,71 @@ func (ds *DeviceSettings) dedicatedIPsToInternal(
 	return dedicatedIPs, nil
 }
 
+// dedicatedPrefixesToInternal converts the dedicated IPv6 prefix data while
+// also validating it against the given bindSet.
+func (ds *DeviceSettings) dedicatedPrefixesToInternal(
+	bindSet netutil.SubnetSet,
+) (prefixes []netip.Prefix, err error) {
+	l := len(ds.DedicatedPrefixes)
+	if l == 0 {
+		return nil, nil
+	}
+
+	prefixes = make([]netip.Prefix, 0, l)
+	for i, c := range ds.DedicatedPrefixes {
+		var p netip.Prefix
+		p, err = dedicatedPrefixToInternal(c, bindSet)
+		if err != nil {
+			return nil, fmt.Errorf("at index %d: %w", i, err)
+		}
+
+		prefixes = append(prefixes, p)
+	}
+
+	return prefixes, nil
+}
+
+// dedicatedPrefixToInternal converts and validates a single dedicated prefix.
+func dedicatedPrefixToInternal(
+	c *CidrRange,
+	bindSet netutil.SubnetSet,
+) (p netip.Prefix, err error) {
+	if c == nil {
+		return netip.Prefix{}, fmt.Errorf("cidr is nil")
+	}
+
+	addr, ok := netip.AddrFromSlice(c.Address)
+	if !ok {
+		return netip.Prefix{}, fmt.Errorf("bad address: %v", c.Address)
+	}
+
+	if !addr.Is6() || addr.Is4In6() {
+		return netip.Prefix{}, fmt.Errorf("%s: not an ipv6 address", addr)
+	}
+
+	p = netip.PrefixFrom(addr, int(c.Prefix))
+	switch {
+	case !p.IsValid():
+		return netip.Prefix{}, fmt.Errorf("%s/%d: bad prefix length", addr, c.Prefix)
+	case p.Masked() != p:
+		return netip.Prefix{}, fmt.Errorf("%s: not masked", p)
+	case !bindSet.Contains(p.Addr()) || !bindSet.Contains(lastAddr(p)):
+		return netip.Prefix{}, fmt.Errorf("%q is not in bind data", p)
+	default:
+		return p, nil
+	}
+}
+
+// lastAddr returns the last address within the masked prefix p.
+func lastAddr(p netip.Prefix) (addr netip.Addr) {
+	b := p.Addr().As16()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 1 << (7 - i%8)
+	}
+
+	return netip.AddrFrom16(b)
+}
+
 // toInternal converts a protobuf auth settings structure to an internal one.
 // If x is nil, toInternal returns non-nil settings with enabled field set to
 // false.
@@ -146,6 +225,7 @@ func (x *AuthenticationSettings) toInternal() (s *agd.AuthSettings, err error) {
 
 	return &agd.AuthSettings{
 		PasswordHash: ph,
+		DoHQueryKey:  x.DohQueryKey,
 		Enabled:      true,
 		DoHAuthOnly:  x.DohAuthOnly,
 	}, nil
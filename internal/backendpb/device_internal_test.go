@@ -0,0 +1,86 @@
+package backendpb
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceSettings_DedicatedPrefixesToInternal(t *testing.T) {
+	t.Parallel()
+
+	bindSet := netip.MustParsePrefix("2001:db8::/32")
+
+	newCidr := func(addr string, bits uint32) (c *CidrRange) {
+		return &CidrRange{
+			Address: netip.MustParseAddr(addr).AsSlice(),
+			Prefix:  bits,
+		}
+	}
+
+	testCases := []struct {
+		cidr       *CidrRange
+		name       string
+		wantErrMsg string
+		want       []netip.Prefix
+	}{{
+		cidr:       newCidr("2001:db8:1::", 48),
+		want:       []netip.Prefix{netip.MustParsePrefix("2001:db8:1::/48")},
+		name:       "success",
+		wantErrMsg: "",
+	}, {
+		cidr:       nil,
+		want:       nil,
+		name:       "nil",
+		wantErrMsg: "at index 0: cidr is nil",
+	}, {
+		cidr: &CidrRange{
+			Address: []byte{1},
+			Prefix:  64,
+		},
+		want:       nil,
+		name:       "bad_addr",
+		wantErrMsg: "at index 0: bad address: [1]",
+	}, {
+		cidr:       newCidr("192.0.2.0", 24),
+		want:       nil,
+		name:       "ipv4",
+		wantErrMsg: "at index 0: 192.0.2.0: not an ipv6 address",
+	}, {
+		cidr:       newCidr("2001:db8:1::", 129),
+		want:       nil,
+		name:       "bad_bits",
+		wantErrMsg: "at index 0: 2001:db8:1::/129: bad prefix length",
+	}, {
+		cidr:       newCidr("2001:db8:1::1", 64),
+		want:       nil,
+		name:       "not_masked",
+		wantErrMsg: "at index 0: 2001:db8:1::1/64: not masked",
+	}, {
+		cidr:       newCidr("2001:db9::", 48),
+		want:       nil,
+		name:       "not_in_bind",
+		wantErrMsg: `at index 0: "2001:db9::/48" is not in bind data`,
+	}, {
+		cidr:       newCidr("2001:d00::", 24),
+		want:       nil,
+		name:       "wider_than_bind",
+		wantErrMsg: `at index 0: "2001:d00::/24" is not in bind data`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ds := &DeviceSettings{
+				DedicatedPrefixes: []*CidrRange{tc.cidr},
+			}
+
+			got, err := ds.dedicatedPrefixesToInternal(bindSet)
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
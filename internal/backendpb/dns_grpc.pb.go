@@ -501,3 +501,341 @@ var RemoteKVService_ServiceDesc = grpc.ServiceDesc{
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "dns.proto",
 }
+
+const (
+	ServerGroupService_GetServerGroups_FullMethodName = "/ServerGroupService/getServerGroups"
+)
+
+// ServerGroupServiceClient is the client API for ServerGroupService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ServerGroupServiceClient interface {
+	// Gets the dynamic settings of the server groups, such as the device domains
+	// and the DDR targets of the white-label partners.
+	//
+	// This method may return the following errors:
+	// - AuthenticationFailedError: If the authentication failed.
+	GetServerGroups(ctx context.Context, in *ServerGroupsRequest, opts ...grpc.CallOption) (*ServerGroupsResponse, error)
+}
+
+type serverGroupServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewServerGroupServiceClient(cc grpc.ClientConnInterface) ServerGroupServiceClient {
+	return &serverGroupServiceClient{cc}
+}
+
+func (c *serverGroupServiceClient) GetServerGroups(ctx context.Context, in *ServerGroupsRequest, opts ...grpc.CallOption) (*ServerGroupsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ServerGroupsResponse)
+	err := c.cc.Invoke(ctx, ServerGroupService_GetServerGroups_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ServerGroupServiceServer is the server API for ServerGroupService service.
+// All implementations must embed UnimplementedServerGroupServiceServer
+// for forward compatibility.
+type ServerGroupServiceServer interface {
+	// Gets the dynamic settings of the server groups, such as the device domains
+	// and the DDR targets of the white-label partners.
+	//
+	// This method may return the following errors:
+	// - AuthenticationFailedError: If the authentication failed.
+	GetServerGroups(context.Context, *ServerGroupsRequest) (*ServerGroupsResponse, error)
+	mustEmbedUnimplementedServerGroupServiceServer()
+}
+
+// UnimplementedServerGroupServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedServerGroupServiceServer struct{}
+
+func (UnimplementedServerGroupServiceServer) GetServerGroups(context.Context, *ServerGroupsRequest) (*ServerGroupsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetServerGroups not implemented")
+}
+func (UnimplementedServerGroupServiceServer) mustEmbedUnimplementedServerGroupServiceServer() {}
+func (UnimplementedServerGroupServiceServer) testEmbeddedByValue()                            {}
+
+// UnsafeServerGroupServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ServerGroupServiceServer will
+// result in compilation errors.
+type UnsafeServerGroupServiceServer interface {
+	mustEmbedUnimplementedServerGroupServiceServer()
+}
+
+func RegisterServerGroupServiceServer(s grpc.ServiceRegistrar, srv ServerGroupServiceServer) {
+	// If the following call pancis, it indicates UnimplementedServerGroupServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ServerGroupService_ServiceDesc, srv)
+}
+
+func _ServerGroupService_GetServerGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServerGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ServerGroupServiceServer).GetServerGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ServerGroupService_GetServerGroups_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ServerGroupServiceServer).GetServerGroups(ctx, req.(*ServerGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ServerGroupService_ServiceDesc is the grpc.ServiceDesc for ServerGroupService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ServerGroupService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ServerGroupService",
+	HandlerType: (*ServerGroupServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "getServerGroups",
+			Handler:    _ServerGroupService_GetServerGroups_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "dns.proto",
+}
+
+const (
+	AccessService_GetStandardAccessSettings_FullMethodName = "/AccessService/getStandardAccessSettings"
+)
+
+// AccessServiceClient is the client API for AccessService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AccessServiceClient interface {
+	// Gets the standard access settings, which apply to all requests regardless
+	// of the profile.  If last_update_time is set, the response may only contain
+	// the changes made since that time, see StandardAccessSettingsResponse.is_full.
+	//
+	// This method may return the following errors:
+	// - AuthenticationFailedError: If the authentication failed.
+	GetStandardAccessSettings(ctx context.Context, in *StandardAccessSettingsRequest, opts ...grpc.CallOption) (*StandardAccessSettingsResponse, error)
+}
+
+type accessServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAccessServiceClient(cc grpc.ClientConnInterface) AccessServiceClient {
+	return &accessServiceClient{cc}
+}
+
+func (c *accessServiceClient) GetStandardAccessSettings(ctx context.Context, in *StandardAccessSettingsRequest, opts ...grpc.CallOption) (*StandardAccessSettingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StandardAccessSettingsResponse)
+	err := c.cc.Invoke(ctx, AccessService_GetStandardAccessSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AccessServiceServer is the server API for AccessService service.
+// All implementations must embed UnimplementedAccessServiceServer
+// for forward compatibility.
+type AccessServiceServer interface {
+	// Gets the standard access settings, which apply to all requests regardless
+	// of the profile.  If last_update_time is set, the response may only contain
+	// the changes made since that time, see StandardAccessSettingsResponse.is_full.
+	//
+	// This method may return the following errors:
+	// - AuthenticationFailedError: If the authentication failed.
+	GetStandardAccessSettings(context.Context, *StandardAccessSettingsRequest) (*StandardAccessSettingsResponse, error)
+	mustEmbedUnimplementedAccessServiceServer()
+}
+
+// UnimplementedAccessServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAccessServiceServer struct{}
+
+func (UnimplementedAccessServiceServer) GetStandardAccessSettings(context.Context, *StandardAccessSettingsRequest) (*StandardAccessSettingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStandardAccessSettings not implemented")
+}
+func (UnimplementedAccessServiceServer) mustEmbedUnimplementedAccessServiceServer() {}
+func (UnimplementedAccessServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeAccessServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AccessServiceServer will
+// result in compilation errors.
+type UnsafeAccessServiceServer interface {
+	mustEmbedUnimplementedAccessServiceServer()
+}
+
+func RegisterAccessServiceServer(s grpc.ServiceRegistrar, srv AccessServiceServer) {
+	// If the following call pancis, it indicates UnimplementedAccessServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&AccessService_ServiceDesc, srv)
+}
+
+func _AccessService_GetStandardAccessSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StandardAccessSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AccessServiceServer).GetStandardAccessSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AccessService_GetStandardAccessSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AccessServiceServer).GetStandardAccessSettings(ctx, req.(*StandardAccessSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AccessService_ServiceDesc is the grpc.ServiceDesc for AccessService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AccessService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "AccessService",
+	HandlerType: (*AccessServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "getStandardAccessSettings",
+			Handler:    _AccessService_GetStandardAccessSettings_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "dns.proto",
+}
+
+const (
+	PopularDomainsService_SavePopularDomains_FullMethodName = "/PopularDomainsService/savePopularDomains"
+)
+
+// PopularDomainsServiceClient is the client API for PopularDomainsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PopularDomainsServiceClient interface {
+	// Saves the differentially private statistics of the most popular domains
+	// per country and per ASN collected within a single window.
+	//
+	// This method may return the following errors:
+	// - AuthenticationFailedError: If the authentication failed.
+	SavePopularDomains(ctx context.Context, in *PopularDomainsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+}
+
+type popularDomainsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPopularDomainsServiceClient(cc grpc.ClientConnInterface) PopularDomainsServiceClient {
+	return &popularDomainsServiceClient{cc}
+}
+
+func (c *popularDomainsServiceClient) SavePopularDomains(ctx context.Context, in *PopularDomainsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, PopularDomainsService_SavePopularDomains_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PopularDomainsServiceServer is the server API for PopularDomainsService service.
+// All implementations must embed UnimplementedPopularDomainsServiceServer
+// for forward compatibility.
+type PopularDomainsServiceServer interface {
+	// Saves the differentially private statistics of the most popular domains
+	// per country and per ASN collected within a single window.
+	//
+	// This method may return the following errors:
+	// - AuthenticationFailedError: If the authentication failed.
+	SavePopularDomains(context.Context, *PopularDomainsRequest) (*emptypb.Empty, error)
+	mustEmbedUnimplementedPopularDomainsServiceServer()
+}
+
+// UnimplementedPopularDomainsServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedPopularDomainsServiceServer struct{}
+
+func (UnimplementedPopularDomainsServiceServer) SavePopularDomains(context.Context, *PopularDomainsRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SavePopularDomains not implemented")
+}
+func (UnimplementedPopularDomainsServiceServer) mustEmbedUnimplementedPopularDomainsServiceServer() {}
+func (UnimplementedPopularDomainsServiceServer) testEmbeddedByValue()                               {}
+
+// UnsafePopularDomainsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PopularDomainsServiceServer will
+// result in compilation errors.
+type UnsafePopularDomainsServiceServer interface {
+	mustEmbedUnimplementedPopularDomainsServiceServer()
+}
+
+func RegisterPopularDomainsServiceServer(s grpc.ServiceRegistrar, srv PopularDomainsServiceServer) {
+	// If the following call pancis, it indicates UnimplementedPopularDomainsServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&PopularDomainsService_ServiceDesc, srv)
+}
+
+func _PopularDomainsService_SavePopularDomains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PopularDomainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PopularDomainsServiceServer).SavePopularDomains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PopularDomainsService_SavePopularDomains_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PopularDomainsServiceServer).SavePopularDomains(ctx, req.(*PopularDomainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// PopularDomainsService_ServiceDesc is the grpc.ServiceDesc for PopularDomainsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PopularDomainsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "PopularDomainsService",
+	HandlerType: (*PopularDomainsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "savePopularDomains",
+			Handler:    _PopularDomainsService_SavePopularDomains_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "dns.proto",
+}
@@ -0,0 +1,103 @@
+package backendpb
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/popdomains"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// PopularDomainsUploaderConfig is the configuration structure for the business
+// logic backend popular-domains statistics uploader.
+type PopularDomainsUploaderConfig struct {
+	// GRPCMetrics is used for the collection of the protobuf communication
+	// statistics.
+	GRPCMetrics GRPCMetrics
+
+	// Endpoint is the backend API URL.  The scheme should be either "grpc" or
+	// "grpcs".  It must not be nil.
+	Endpoint *url.URL
+
+	// APIKey is the API key used for authentication, if any.  If empty, no
+	// authentication is performed.
+	APIKey string
+}
+
+// PopularDomainsUploader is the implementation of the [popdomains.Uploader]
+// interface that uploads the statistics to the business logic backend.
+type PopularDomainsUploader struct {
+	grpcMetrics GRPCMetrics
+	client      PopularDomainsServiceClient
+	apiKey      string
+}
+
+// NewPopularDomainsUploader creates a new properly initialized popular-domains
+// statistics uploader.  c must not be nil.
+func NewPopularDomainsUploader(
+	c *PopularDomainsUploaderConfig,
+) (u *PopularDomainsUploader, err error) {
+	client, err := newClient(c.Endpoint)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	return &PopularDomainsUploader{
+		grpcMetrics: c.GRPCMetrics,
+		client:      NewPopularDomainsServiceClient(client),
+		apiKey:      c.APIKey,
+	}, nil
+}
+
+// type check
+var _ popdomains.Uploader = (*PopularDomainsUploader)(nil)
+
+// Upload implements the [popdomains.Uploader] interface for
+// *PopularDomainsUploader.
+func (u *PopularDomainsUploader) Upload(ctx context.Context, s *popdomains.Statistics) (err error) {
+	req := &PopularDomainsRequest{
+		WindowStart: timestamppb.New(s.WindowStart),
+		WindowEnd:   timestamppb.New(s.WindowEnd),
+		Groups:      make([]*PopularDomainsGroup, 0, len(s.Countries)+len(s.ASNs)),
+	}
+
+	for _, ctry := range slices.Sorted(maps.Keys(s.Countries)) {
+		req.Groups = append(req.Groups, &PopularDomainsGroup{
+			Country: string(ctry),
+			Domains: popularDomainsToProtobuf(s.Countries[ctry]),
+		})
+	}
+
+	for _, asn := range slices.Sorted(maps.Keys(s.ASNs)) {
+		req.Groups = append(req.Groups, &PopularDomainsGroup{
+			Asn:     uint32(asn),
+			Domains: popularDomainsToProtobuf(s.ASNs[asn]),
+		})
+	}
+
+	ctx = ctxWithAuthentication(ctx, u.apiKey)
+	_, err = u.client.SavePopularDomains(ctx, req)
+	if err != nil {
+		return fmt.Errorf("saving popular domains: %w", fixGRPCError(ctx, u.grpcMetrics, err))
+	}
+
+	return nil
+}
+
+// popularDomainsToProtobuf converts the domain counts into their protobuf
+// representation.
+func popularDomainsToProtobuf(counts []*popdomains.DomainCount) (pbCounts []*PopularDomain) {
+	pbCounts = make([]*PopularDomain, 0, len(counts))
+	for _, c := range counts {
+		pbCounts = append(pbCounts, &PopularDomain{
+			Domain: c.Domain,
+			Count:  c.Count,
+		})
+	}
+
+	return pbCounts
+}
@@ -0,0 +1,125 @@
+package backendpb_test
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/backendpb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/popdomains"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// testPopularDomainsServiceServer is the
+// [backendpb.PopularDomainsServiceServer] for tests.
+type testPopularDomainsServiceServer struct {
+	backendpb.UnimplementedPopularDomainsServiceServer
+
+	OnSavePopularDomains func(
+		ctx context.Context,
+		req *backendpb.PopularDomainsRequest,
+	) (resp *emptypb.Empty, err error)
+}
+
+// type check
+var _ backendpb.PopularDomainsServiceServer = (*testPopularDomainsServiceServer)(nil)
+
+// SavePopularDomains implements the [backendpb.PopularDomainsServiceServer]
+// interface for *testPopularDomainsServiceServer.
+func (s *testPopularDomainsServiceServer) SavePopularDomains(
+	ctx context.Context,
+	req *backendpb.PopularDomainsRequest,
+) (resp *emptypb.Empty, err error) {
+	return s.OnSavePopularDomains(ctx, req)
+}
+
+func TestPopularDomainsUploader_Upload(t *testing.T) {
+	reqCh := make(chan *backendpb.PopularDomainsRequest, 1)
+	srv := &testPopularDomainsServiceServer{
+		OnSavePopularDomains: func(
+			_ context.Context,
+			req *backendpb.PopularDomainsRequest,
+		) (resp *emptypb.Empty, err error) {
+			reqCh <- req
+
+			return &emptypb.Empty{}, nil
+		},
+	}
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	grpcSrv := grpc.NewServer(
+		grpc.ConnectionTimeout(1*time.Second),
+		grpc.Creds(insecure.NewCredentials()),
+	)
+	backendpb.RegisterPopularDomainsServiceServer(grpcSrv, srv)
+
+	go func() {
+		pt := testutil.PanicT{}
+
+		srvErr := grpcSrv.Serve(ln)
+		require.NoError(pt, srvErr)
+	}()
+	t.Cleanup(grpcSrv.GracefulStop)
+
+	u, err := backendpb.NewPopularDomainsUploader(&backendpb.PopularDomainsUploaderConfig{
+		GRPCMetrics: backendpb.EmptyGRPCMetrics{},
+		Endpoint: &url.URL{
+			Scheme: "grpc",
+			Host:   ln.Addr().String(),
+		},
+	})
+	require.NoError(t, err)
+
+	const asn geoip.ASN = 42
+
+	start := time.Unix(0, 0).UTC()
+	end := start.Add(time.Hour)
+	counts := []*popdomains.DomainCount{{
+		Domain: "example.com",
+		Count:  10,
+	}}
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	err = u.Upload(ctx, &popdomains.Statistics{
+		WindowStart: start,
+		WindowEnd:   end,
+		Countries: map[geoip.Country][]*popdomains.DomainCount{
+			geoip.CountryAD: counts,
+		},
+		ASNs: map[geoip.ASN][]*popdomains.DomainCount{
+			asn: counts,
+		},
+	})
+	require.NoError(t, err)
+
+	req, ok := testutil.RequireReceive(t, reqCh, testTimeout)
+	require.True(t, ok)
+
+	assert.Equal(t, start, req.WindowStart.AsTime())
+	assert.Equal(t, end, req.WindowEnd.AsTime())
+
+	require.Len(t, req.Groups, 2)
+
+	ctryGrp, asnGrp := req.Groups[0], req.Groups[1]
+	assert.Equal(t, string(geoip.CountryAD), ctryGrp.Country)
+	assert.Zero(t, ctryGrp.Asn)
+	assert.Empty(t, asnGrp.Country)
+	assert.Equal(t, uint32(asn), asnGrp.Asn)
+
+	for _, g := range req.Groups {
+		require.Len(t, g.Domains, 1)
+
+		assert.Equal(t, "example.com", g.Domains[0].Domain)
+		assert.Equal(t, uint64(10), g.Domains[0].Count)
+	}
+}
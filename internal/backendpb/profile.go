@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"math"
 	"net/netip"
 	"time"
 
@@ -46,6 +47,11 @@ func (x *DNSProfile) toInternal(
 		return nil, nil, fmt.Errorf("blocking mode: %w", err)
 	}
 
+	blockPage, err := blockPageToInternal(x.BlockPageIpv4, x.BlockPageIpv6)
+	if err != nil {
+		return nil, nil, fmt.Errorf("block page: %w", err)
+	}
+
 	devices, deviceIds := devicesToInternal(ctx, x.Devices, bindSet, errColl, logger, mtrc)
 
 	profID, err := agd.NewProfileID(x.DnsId)
@@ -58,6 +64,11 @@ func (x *DNSProfile) toInternal(
 		fltRespTTL = respTTL.AsDuration()
 	}
 
+	var blockUntil time.Time
+	if bu := x.BlockUntil; bu != nil {
+		blockUntil = bu.AsTime()
+	}
+
 	customRules := rulesToInternal(ctx, x.CustomRules, errColl, logger)
 	custom := &filter.ConfigCustom{
 		ID:         string(x.DnsId),
@@ -69,28 +80,51 @@ func (x *DNSProfile) toInternal(
 
 	return &agd.Profile{
 		FilterConfig: &filter.ConfigClient{
-			Custom:       custom,
-			Parental:     parental,
-			RuleList:     x.RuleLists.toInternal(ctx, errColl, logger),
-			SafeBrowsing: x.SafeBrowsing.toInternal(),
+			BlockPage:     blockPage,
+			Custom:        custom,
+			Parental:      parental,
+			RuleList:      x.RuleLists.toInternal(ctx, errColl, logger),
+			SafeBrowsing:  x.SafeBrowsing.toInternal(),
+			BlockUntil:    blockUntil,
+			AllowlistOnly: x.AllowlistOnly,
 		},
 		Access:              x.Access.toInternal(ctx, errColl, logger),
 		BlockingMode:        m,
 		Ratelimiter:         x.RateLimit.toInternal(ctx, errColl, logger, respSzEst),
+		AccountLimits:       x.accountLimitsToInternal(),
+		QueryTypeActions:    x.queryTypeActionsToInternal(ctx, errColl, logger),
 		ID:                  profID,
+		AccountID:           agd.AccountID(x.AccountId),
 		DeviceIDs:           deviceIds,
 		FilteredResponseTTL: fltRespTTL,
+		ECSMode:             x.EcsMode.toInternal(ctx, errColl, logger),
 		AutoDevicesEnabled:  x.AutoDevicesEnabled,
 		BlockChromePrefetch: x.BlockChromePrefetch,
 		BlockFirefoxCanary:  x.BlockFirefoxCanary,
 		BlockPrivateRelay:   x.BlockPrivateRelay,
 		Deleted:             x.Deleted,
 		FilteringEnabled:    x.FilteringEnabled,
+		ForwardLocalZones:   x.ForwardLocalZones,
 		IPLogEnabled:        x.IpLogEnabled,
 		QueryLogEnabled:     x.QueryLogEnabled,
+		RequireDNSSEC:       x.RequireDnssec,
 	}, devices, nil
 }
 
+// accountLimitsToInternal returns the aggregate limits of the account of the
+// profile.  If the account has no limits, l is nil.
+func (x *DNSProfile) accountLimitsToInternal() (l *agd.AccountLimits) {
+	if x.AccountRps == 0 && x.AccountMaxDevices == 0 {
+		return nil
+	}
+
+	return &agd.AccountLimits{
+		Tier:       agd.AccountTier(x.AccountTier),
+		RPS:        x.AccountRps,
+		MaxDevices: x.AccountMaxDevices,
+	}
+}
+
 // toInternal converts a protobuf parental-protection settings structure to an
 // internal one.  If x is nil, toInternal returns a disabled configuration.
 func (x *ParentalSettings) toInternal(
@@ -109,6 +143,10 @@ func (x *ParentalSettings) toInternal(
 	c.SafeSearchGeneralEnabled = x.GeneralSafeSearch
 	c.SafeSearchYouTubeEnabled = x.YoutubeSafeSearch
 
+	if lp := x.LearningPeriod; lp != nil {
+		c.LearningPeriod = lp.AsDuration()
+	}
+
 	c.PauseSchedule, err = x.Schedule.toInternal()
 	if err != nil {
 		return nil, fmt.Errorf("pause schedule: %w", err)
@@ -304,6 +342,31 @@ func (pbm *BlockingModeCustomIP) toInternal() (m dnsmsg.BlockingMode, err error)
 	return custom, nil
 }
 
+// blockPageToInternal converts the protobuf addresses of the block page of a
+// profile to an internal structure.  If both ipv4 and ipv6 are empty, c is nil.
+func blockPageToInternal(ipv4, ipv6 []byte) (c *filter.ConfigBlockPage, err error) {
+	if len(ipv4) == 0 && len(ipv6) == 0 {
+		return nil, nil
+	}
+
+	c = &filter.ConfigBlockPage{}
+	err = c.IPv4.UnmarshalBinary(ipv4)
+	if err != nil {
+		return nil, fmt.Errorf("bad ipv4: %w", err)
+	} else if c.IPv4.IsValid() && !c.IPv4.Is4() {
+		return nil, fmt.Errorf("bad ipv4: not an ipv4 address: %s", c.IPv4)
+	}
+
+	err = c.IPv6.UnmarshalBinary(ipv6)
+	if err != nil {
+		return nil, fmt.Errorf("bad ipv6: %w", err)
+	} else if c.IPv6.IsValid() && !c.IPv6.Is6() {
+		return nil, fmt.Errorf("bad ipv6: not an ipv6 address: %s", c.IPv6)
+	}
+
+	return c, nil
+}
+
 // blockingModeToInternal converts a protobuf blocking-mode sum-type to an
 // internal one.  If pbm is nil, blockingModeToInternal returns a null-IP
 // blocking mode.
@@ -325,6 +388,70 @@ func blockingModeToInternal(pbm isDNSProfile_BlockingMode) (m dnsmsg.BlockingMod
 	}
 }
 
+// toInternal converts a protobuf ECS mode to an internal one.  Unknown values
+// are reported to errColl and converted into [agd.ECSModeReplace].
+func (x ECSMode) toInternal(
+	ctx context.Context,
+	errColl errcoll.Interface,
+	logger *slog.Logger,
+) (m agd.ECSMode) {
+	switch x {
+	case ECSMode_ECS_MODE_REPLACE:
+		return agd.ECSModeReplace
+	case ECSMode_ECS_MODE_FORWARD:
+		return agd.ECSModeForward
+	case ECSMode_ECS_MODE_STRIP:
+		return agd.ECSModeStrip
+	default:
+		err := fmt.Errorf("bad pb ecs mode %d", x)
+		errcoll.Collect(ctx, errColl, logger, "converting ecs mode", err)
+
+		return agd.ECSModeReplace
+	}
+}
+
+// queryTypeActionsToInternal converts the query types with special actions from
+// the protobuf profile to an internal map.  Invalid query types are reported to
+// errColl and skipped.  If a query type is both refused and NODATA, REFUSED
+// takes precedence.
+func (x *DNSProfile) queryTypeActionsToInternal(
+	ctx context.Context,
+	errColl errcoll.Interface,
+	logger *slog.Logger,
+) (actions map[dnsmsg.RRType]agd.QueryTypeAction) {
+	l := len(x.RefusedQueryTypes) + len(x.NodataQueryTypes)
+	if l == 0 {
+		return nil
+	}
+
+	actions = make(map[dnsmsg.RRType]agd.QueryTypeAction, l)
+	qtActs := []struct {
+		qtypes []uint32
+		act    agd.QueryTypeAction
+	}{{
+		qtypes: x.NodataQueryTypes,
+		act:    agd.QueryTypeActionNODATA,
+	}, {
+		qtypes: x.RefusedQueryTypes,
+		act:    agd.QueryTypeActionRefused,
+	}}
+
+	for _, qa := range qtActs {
+		for i, qt := range qa.qtypes {
+			if qt == 0 || qt > math.MaxUint16 {
+				err := fmt.Errorf("%s query types: at index %d: bad query type %d", qa.act, i, qt)
+				errcoll.Collect(ctx, errColl, logger, "converting query type actions", err)
+
+				continue
+			}
+
+			actions[dnsmsg.RRType(qt)] = qa.act
+		}
+	}
+
+	return actions
+}
+
 // rulesToInternal is a helper that converts the filter rules from the backend
 // response to AdGuard DNS filtering rules.
 func rulesToInternal(
@@ -16,9 +16,11 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
 	"github.com/AdguardTeam/golibs/testutil"
 	"github.com/c2h5oh/datasize"
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestDNSProfile_ToInternal(t *testing.T) {
@@ -325,6 +327,45 @@ func TestDNSProfile_ToInternal(t *testing.T) {
 		assert.Equal(t, got.ID, TestProfileID)
 		assert.IsType(t, access.EmptyProfile{}, got.Access)
 	})
+
+	t.Run("bad_query_types", func(t *testing.T) {
+		t.Parallel()
+
+		var errCollErr error
+		savingErrColl := &agdtest.ErrorCollector{
+			OnCollect: func(_ context.Context, err error) {
+				errCollErr = err
+			},
+		}
+
+		dp := NewTestDNSProfile(t)
+		dp.RefusedQueryTypes = []uint32{uint32(dns.TypeANY), 0x1_0000}
+		dp.NodataQueryTypes = nil
+
+		got, _, err := dp.toInternal(
+			ctx,
+			TestUpdTime,
+			TestBind,
+			savingErrColl,
+			TestLogger,
+			EmptyProfileDBMetrics{},
+			TestRespSzEst,
+		)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+
+		testutil.AssertErrorMsg(
+			t,
+			"converting query type actions: refused query types: at index 1: "+
+				"bad query type 65536",
+			errCollErr,
+		)
+
+		wantActs := map[dnsmsg.RRType]agd.QueryTypeAction{
+			dns.TypeANY: agd.QueryTypeActionRefused,
+		}
+		assert.Equal(t, wantActs, got.QueryTypeActions)
+	})
 }
 
 // newDNSProfileWithBadData returns a new instance of *DNSProfile with bad
@@ -387,11 +428,14 @@ func NewTestDNSProfile(tb testing.TB) (dp *DNSProfile) {
 		FilteringEnabled: true,
 		LinkedIp:         ipToBytes(tb, netip.MustParseAddr("2.2.2.2")),
 		DedicatedIps:     nil,
+		CreatedAt:        timestamppb.New(TestDeviceCreatedAt),
+		Quarantined:      true,
 		Authentication: &AuthenticationSettings{
 			DohAuthOnly: true,
 			DohPasswordHash: &AuthenticationSettings_PasswordHashBcrypt{
 				PasswordHashBcrypt: []byte("test-hash"),
 			},
+			DohQueryKey: []byte("test-key"),
 		},
 	}, {
 		Id:               "3333cccc",
@@ -426,6 +470,7 @@ func NewTestDNSProfile(tb testing.TB) (dp *DNSProfile) {
 			GeneralSafeSearch: false,
 			YoutubeSafeSearch: false,
 			BlockedServices:   []string{"youtube"},
+			LearningPeriod:    durationpb.New(TestLearningPeriod),
 			Schedule: &ScheduleSettings{
 				Tmz: "GMT",
 				WeeklyRange: &WeeklyRange{
@@ -479,6 +524,18 @@ func NewTestDNSProfile(tb testing.TB) (dp *DNSProfile) {
 			}},
 		},
 		BlockChromePrefetch: true,
+		AllowlistOnly:       true,
+		EcsMode:             ECSMode_ECS_MODE_FORWARD,
+		BlockPageIpv4:       ipToBytes(tb, netip.MustParseAddr("192.0.2.1")),
+		ForwardLocalZones:   true,
+		BlockUntil:          timestamppb.New(TestUpdTime.Add(1 * time.Hour)),
+		RefusedQueryTypes:   []uint32{uint32(dns.TypeANY), uint32(dns.TypeHTTPS)},
+		NodataQueryTypes:    []uint32{uint32(dns.TypeAAAA)},
+		RequireDnssec:       true,
+		AccountId:           "acc1234",
+		AccountTier:         "team",
+		AccountRps:          1000,
+		AccountMaxDevices:   20,
 	}
 }
 
@@ -520,6 +577,7 @@ func newProfile(tb testing.TB) (p *agd.Profile) {
 		BlockedServices: []filter.BlockedServiceID{
 			"youtube",
 		},
+		LearningPeriod:           TestLearningPeriod,
 		Enabled:                  false,
 		AdultBlockingEnabled:     false,
 		SafeSearchGeneralEnabled: false,
@@ -553,6 +611,9 @@ func newProfile(tb testing.TB) (p *agd.Profile) {
 
 	return &agd.Profile{
 		FilterConfig: &filter.ConfigClient{
+			BlockPage: &filter.ConfigBlockPage{
+				IPv4: netip.MustParseAddr("192.0.2.1"),
+			},
 			Custom: &filter.ConfigCustom{
 				ID:         TestProfileIDStr,
 				UpdateTime: TestUpdTime,
@@ -564,12 +625,25 @@ func newProfile(tb testing.TB) (p *agd.Profile) {
 				IDs:     []filter.ID{"1"},
 				Enabled: true,
 			},
-			SafeBrowsing: wantSafeBrowsing,
+			SafeBrowsing:  wantSafeBrowsing,
+			BlockUntil:    TestUpdTime.Add(1 * time.Hour),
+			AllowlistOnly: true,
 		},
 		Access:       wantAccess,
 		BlockingMode: wantBlockingMode,
 		Ratelimiter:  wantRateLimiter,
-		ID:           TestProfileID,
+		AccountLimits: &agd.AccountLimits{
+			Tier:       "team",
+			RPS:        1000,
+			MaxDevices: 20,
+		},
+		QueryTypeActions: map[dnsmsg.RRType]agd.QueryTypeAction{
+			dns.TypeAAAA:  agd.QueryTypeActionNODATA,
+			dns.TypeANY:   agd.QueryTypeActionRefused,
+			dns.TypeHTTPS: agd.QueryTypeActionRefused,
+		},
+		ID:        TestProfileID,
+		AccountID: "acc1234",
 		DeviceIDs: []agd.DeviceID{
 			TestDeviceID,
 			"2222bbbb",
@@ -577,14 +651,17 @@ func newProfile(tb testing.TB) (p *agd.Profile) {
 			"4444dddd",
 		},
 		FilteredResponseTTL: 10 * time.Second,
+		ECSMode:             agd.ECSModeForward,
 		AutoDevicesEnabled:  true,
 		BlockChromePrefetch: true,
 		BlockFirefoxCanary:  true,
 		BlockPrivateRelay:   true,
 		Deleted:             false,
 		FilteringEnabled:    true,
+		ForwardLocalZones:   true,
 		IPLogEnabled:        true,
 		QueryLogEnabled:     true,
+		RequireDNSSEC:       true,
 	}
 }
 
@@ -608,12 +685,15 @@ func newDevices(t *testing.T) (d []*agd.Device) {
 			Enabled:      true,
 			DoHAuthOnly:  true,
 			PasswordHash: agdpasswd.NewPasswordHashBcrypt([]byte("test-hash")),
+			DoHQueryKey:  []byte("test-key"),
 		},
 		ID:               "2222bbbb",
+		CreatedAt:        TestDeviceCreatedAt,
 		LinkedIP:         netip.MustParseAddr("2.2.2.2"),
 		Name:             "2222bbbb-name",
 		DedicatedIPs:     nil,
 		FilteringEnabled: true,
+		Quarantined:      true,
 	}, {
 		Auth: &agd.AuthSettings{
 			Enabled:      true,
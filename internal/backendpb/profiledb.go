@@ -205,16 +205,32 @@ func (s *ProfileStorage) Profiles(
 		return nil, fmt.Errorf("retrieving sync_time: %w", err)
 	}
 
+	resp.NextPageToken = nextPageTokenFromTrailer(trailer)
+
 	return resp, nil
 }
 
 // toProtobuf converts a storage request structure into the protobuf structure.
 func toProtobuf(r *profiledb.StorageProfilesRequest) (req *DNSProfilesRequest) {
 	return &DNSProfilesRequest{
-		SyncTime: timestamppb.New(r.SyncTime),
+		SyncTime:  timestamppb.New(r.SyncTime),
+		PageToken: r.PageToken,
+		PageSize:  r.PageSize,
 	}
 }
 
+// nextPageTokenFromTrailer returns the token of the next page of a full
+// synchronization from trailer metadata.  token is empty if there are no more
+// pages.
+func nextPageTokenFromTrailer(trailer metadata.MD) (token string) {
+	vals := trailer.Get("next_page_token")
+	if len(vals) == 0 {
+		return ""
+	}
+
+	return vals[0]
+}
+
 // syncTimeFromTrailer returns sync time from trailer metadata.  Trailer
 // metadata must contain "sync_time" field with milliseconds presentation of
 // sync time.
@@ -50,3 +50,33 @@ func TestSyncTimeFromTrailer(t *testing.T) {
 		})
 	}
 }
+
+func TestNextPageTokenFromTrailer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		in   metadata.MD
+		want string
+		name string
+	}{{
+		in:   metadata.MD{},
+		want: "",
+		name: "no_key",
+	}, {
+		in:   metadata.MD{"next_page_token": []string{}},
+		want: "",
+		name: "empty_key",
+	}, {
+		in:   metadata.MD{"next_page_token": []string{"page_2"}},
+		want: "page_2",
+		name: "success",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, nextPageTokenFromTrailer(tc.in))
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package backendpb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/netutil"
+)
+
+// ServerGroupUpdaterConfig is the configuration structure for the business
+// logic backend server-group settings updater.
+type ServerGroupUpdaterConfig struct {
+	// Logger is used for logging the operation of the updater.  It must not be
+	// nil.
+	Logger *slog.Logger
+
+	// GRPCMetrics is used for the collection of the protobuf communication
+	// statistics.
+	GRPCMetrics GRPCMetrics
+
+	// Settings are the dynamic server-group settings to update.  It must not
+	// be nil.
+	Settings *whitelabel.Dynamic
+
+	// ErrColl is used to collect errors during refreshes.
+	ErrColl errcoll.Interface
+
+	// Endpoint is the backend API URL.  The scheme should be either "grpc" or
+	// "grpcs".  It must not be nil.
+	Endpoint *url.URL
+
+	// APIKey is the API key used for authentication, if any.  If empty, no
+	// authentication is performed.
+	APIKey string
+}
+
+// ServerGroupUpdater is the implementation of the [agdservice.Refresher]
+// interface that retrieves the dynamic server-group settings from the business
+// logic backend.
+type ServerGroupUpdater struct {
+	logger      *slog.Logger
+	grpcMetrics GRPCMetrics
+	settings    *whitelabel.Dynamic
+	errColl     errcoll.Interface
+	client      ServerGroupServiceClient
+	apiKey      string
+}
+
+// NewServerGroupUpdater creates a new properly initialized server-group
+// settings updater.  c must not be nil.
+func NewServerGroupUpdater(c *ServerGroupUpdaterConfig) (u *ServerGroupUpdater, err error) {
+	client, err := newClient(c.Endpoint)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	return &ServerGroupUpdater{
+		logger:      c.Logger,
+		grpcMetrics: c.GRPCMetrics,
+		settings:    c.Settings,
+		errColl:     c.ErrColl,
+		client:      NewServerGroupServiceClient(client),
+		apiKey:      c.APIKey,
+	}, nil
+}
+
+// type check
+var _ agdservice.Refresher = (*ServerGroupUpdater)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for
+// *ServerGroupUpdater.
+func (u *ServerGroupUpdater) Refresh(ctx context.Context) (err error) {
+	u.logger.InfoContext(ctx, "refresh started")
+	defer u.logger.InfoContext(ctx, "refresh finished")
+
+	ctx = ctxWithAuthentication(ctx, u.apiKey)
+	backendResp, err := u.client.GetServerGroups(ctx, &ServerGroupsRequest{})
+	if err != nil {
+		return fmt.Errorf(
+			"loading backend server groups: %w",
+			fixGRPCError(ctx, u.grpcMetrics, err),
+		)
+	}
+
+	settings := make(map[agd.ServerGroupName]*whitelabel.Settings, len(backendResp.ServerGroups))
+	for i, sg := range backendResp.ServerGroups {
+		name, s, convErr := sg.toInternal()
+		if convErr != nil {
+			convErr = fmt.Errorf("server group at index %d: %w", i, convErr)
+			errcoll.Collect(ctx, u.errColl, u.logger, "converting server groups", convErr)
+
+			continue
+		}
+
+		settings[name] = s
+	}
+
+	u.settings.Update(settings)
+
+	u.logger.InfoContext(ctx, "refresh successful", "num_records", len(settings))
+
+	return nil
+}
+
+// toInternal converts the protobuf server-group settings to the internal ones.
+// x must not be nil.
+func (x *ServerGroupSettings) toInternal() (
+	name agd.ServerGroupName,
+	s *whitelabel.Settings,
+	err error,
+) {
+	if x.Name == "" {
+		return "", nil, fmt.Errorf("name: %w", errors.ErrEmptyValue)
+	}
+
+	name = agd.ServerGroupName(x.Name)
+	defer func() { err = errors.Annotate(err, "server group %q: %w", x.Name) }()
+
+	devDomains, err := domainsToInternal(x.DeviceDomains)
+	if err != nil {
+		return "", nil, fmt.Errorf("device_domains: %w", err)
+	}
+
+	pubTargets, err := domainsToInternal(x.DdrPublicTargets)
+	if err != nil {
+		return "", nil, fmt.Errorf("ddr_public_targets: %w", err)
+	}
+
+	devTargets, err := domainsToInternal(x.DdrDeviceTargets)
+	if err != nil {
+		return "", nil, fmt.Errorf("ddr_device_targets: %w", err)
+	}
+
+	tokens, err := validationsToInternal(x.CustomDomainValidations)
+	if err != nil {
+		return "", nil, fmt.Errorf("custom_domain_validations: %w", err)
+	}
+
+	return name, &whitelabel.Settings{
+		DDRDeviceTargets: container.NewMapSet(devTargets...),
+		DDRPublicTargets: container.NewMapSet(pubTargets...),
+		ValidationTokens: tokens,
+		DeviceDomains:    devDomains,
+	}, nil
+}
+
+// validationsToInternal validates and converts the TXT-record validations of
+// the custom domains from the backend response.
+func validationsToInternal(
+	vals []*CustomDomainValidation,
+) (tokens map[string][]string, err error) {
+	if len(vals) == 0 {
+		return nil, nil
+	}
+
+	tokens = make(map[string][]string, len(vals))
+	for i, v := range vals {
+		var domain string
+		var domainTokens []string
+		domain, domainTokens, err = v.toInternal()
+		if err != nil {
+			return nil, fmt.Errorf("at index %d: %w", i, err)
+		}
+
+		tokens[domain] = append(tokens[domain], domainTokens...)
+	}
+
+	return tokens, nil
+}
+
+// toInternal validates and converts the TXT-record validation of a custom
+// domain.  x must not be nil.
+func (x *CustomDomainValidation) toInternal() (domain string, tokens []string, err error) {
+	err = netutil.ValidateDomainName(x.Domain)
+	if err != nil {
+		return "", nil, fmt.Errorf("domain: %w", err)
+	}
+
+	if len(x.TxtTokens) == 0 {
+		return "", nil, fmt.Errorf("txt_tokens: %w", errors.ErrEmptyValue)
+	}
+
+	for i, t := range x.TxtTokens {
+		if t == "" {
+			return "", nil, fmt.Errorf("txt_tokens: at index %d: %w", i, errors.ErrEmptyValue)
+		} else if l := len(t); l > dnsmsg.MaxTXTStringLen {
+			return "", nil, fmt.Errorf(
+				"txt_tokens: at index %d: too long: got %d bytes, max %d",
+				i,
+				l,
+				dnsmsg.MaxTXTStringLen,
+			)
+		}
+	}
+
+	return strings.ToLower(x.Domain), x.TxtTokens, nil
+}
+
+// domainsToInternal validates and normalizes the domain names from the backend
+// response.
+func domainsToInternal(respDomains []string) (domains []string, err error) {
+	if len(respDomains) == 0 {
+		return nil, nil
+	}
+
+	domains = make([]string, 0, len(respDomains))
+	for i, d := range respDomains {
+		err = netutil.ValidateDomainName(d)
+		if err != nil {
+			return nil, fmt.Errorf("at index %d: %w", i, err)
+		}
+
+		domains = append(domains, strings.ToLower(d))
+	}
+
+	return domains, nil
+}
@@ -0,0 +1,141 @@
+package backendpb_test
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/backendpb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// testServerGroupServiceServer is the [backendpb.ServerGroupServiceServer] for
+// tests.
+type testServerGroupServiceServer struct {
+	backendpb.UnimplementedServerGroupServiceServer
+
+	OnGetServerGroups func(
+		ctx context.Context,
+		req *backendpb.ServerGroupsRequest,
+	) (resp *backendpb.ServerGroupsResponse, err error)
+}
+
+// type check
+var _ backendpb.ServerGroupServiceServer = (*testServerGroupServiceServer)(nil)
+
+// GetServerGroups implements the [backendpb.ServerGroupServiceServer]
+// interface for *testServerGroupServiceServer.
+func (s *testServerGroupServiceServer) GetServerGroups(
+	ctx context.Context,
+	req *backendpb.ServerGroupsRequest,
+) (resp *backendpb.ServerGroupsResponse, err error) {
+	return s.OnGetServerGroups(ctx, req)
+}
+
+func TestServerGroupUpdater_Refresh(t *testing.T) {
+	const (
+		grpName           agd.ServerGroupName = "default"
+		badGrpName        agd.ServerGroupName = "bad"
+		badValGrpName     agd.ServerGroupName = "bad_validation"
+		devDomain                             = "d.partner.example"
+		pubTarget                             = "dns.partner.example"
+		devDomainUpper                        = "D.Partner.Example"
+		customDomain                          = "dns.custom.example"
+		customDomainUpper                     = "DNS.Custom.Example"
+		validationToken                       = "token1234"
+	)
+
+	srv := &testServerGroupServiceServer{
+		OnGetServerGroups: func(
+			_ context.Context,
+			_ *backendpb.ServerGroupsRequest,
+		) (resp *backendpb.ServerGroupsResponse, err error) {
+			return &backendpb.ServerGroupsResponse{
+				ServerGroups: []*backendpb.ServerGroupSettings{{
+					Name:             string(grpName),
+					DeviceDomains:    []string{devDomainUpper},
+					DdrPublicTargets: []string{pubTarget},
+					DdrDeviceTargets: []string{devDomain},
+					CustomDomainValidations: []*backendpb.CustomDomainValidation{{
+						Domain:    customDomainUpper,
+						TxtTokens: []string{validationToken},
+					}},
+				}, {
+					Name:          string(badGrpName),
+					DeviceDomains: []string{"!!!"},
+				}, {
+					Name: string(badValGrpName),
+					CustomDomainValidations: []*backendpb.CustomDomainValidation{{
+						Domain: customDomain,
+					}},
+				}},
+			}, nil
+		},
+	}
+
+	ln, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+
+	grpcSrv := grpc.NewServer(
+		grpc.ConnectionTimeout(1*time.Second),
+		grpc.Creds(insecure.NewCredentials()),
+	)
+	backendpb.RegisterServerGroupServiceServer(grpcSrv, srv)
+
+	go func() {
+		pt := testutil.PanicT{}
+
+		srvErr := grpcSrv.Serve(ln)
+		require.NoError(pt, srvErr)
+	}()
+	t.Cleanup(grpcSrv.GracefulStop)
+
+	var collected []error
+	errColl := agdtest.NewErrorCollector()
+	errColl.OnCollect = func(_ context.Context, err error) {
+		collected = append(collected, err)
+	}
+
+	settings := whitelabel.NewDynamic()
+	u, err := backendpb.NewServerGroupUpdater(&backendpb.ServerGroupUpdaterConfig{
+		Logger:      backendpb.TestLogger,
+		GRPCMetrics: backendpb.EmptyGRPCMetrics{},
+		Settings:    settings,
+		ErrColl:     errColl,
+		Endpoint: &url.URL{
+			Scheme: "grpc",
+			Host:   ln.Addr().String(),
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	err = u.Refresh(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, collected, 2)
+	assert.ErrorContains(t, collected[0], string(badGrpName))
+	assert.ErrorContains(t, collected[1], string(badValGrpName))
+
+	assert.Nil(t, settings.ServerGroupSettings(ctx, badGrpName))
+	assert.Nil(t, settings.ServerGroupSettings(ctx, badValGrpName))
+
+	s := settings.ServerGroupSettings(ctx, grpName)
+	require.NotNil(t, s)
+
+	assert.Equal(t, []string{devDomain}, s.DeviceDomains)
+	assert.True(t, s.DDRPublicTargets.Has(pubTarget))
+	assert.True(t, s.DDRDeviceTargets.Has(devDomain))
+	assert.Equal(t, map[string][]string{
+		customDomain: {validationToken},
+	}, s.ValidationTokens)
+}
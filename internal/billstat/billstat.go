@@ -3,10 +3,12 @@ package billstat
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/golibs/errors"
 )
 
 // Recorder is the billing statistics recorder interface.
@@ -41,28 +43,54 @@ func (EmptyRecorder) Record(
 // Uploader is the interface for a backend that accepts the billing statistics
 // records.
 type Uploader interface {
+	// Upload uploads records to the backend.  If only some of the records
+	// failed to upload, err should be a [*PartialUploadError].
 	Upload(ctx context.Context, records Records) (err error)
 }
 
+// PartialUploadError is returned by [Uploader.Upload] when only some of the
+// records failed to upload.
+type PartialUploadError struct {
+	// Err is the error that caused the failure.  It must not be nil.
+	Err error
+
+	// Failed are the records that failed to upload.  It must not be empty.
+	Failed Records
+}
+
+// type check
+var _ error = (*PartialUploadError)(nil)
+
+// Error implements the [error] interface for *PartialUploadError.
+func (err *PartialUploadError) Error() (msg string) {
+	return fmt.Sprintf("uploading %d records: %s", len(err.Failed), err.Err)
+}
+
+// type check
+var _ errors.Wrapper = (*PartialUploadError)(nil)
+
+// Unwrap implements the [errors.Wrapper] interface for *PartialUploadError.
+func (err *PartialUploadError) Unwrap() (unwrapped error) { return err.Err }
+
 // Record is a single billing statistics Record.
 type Record struct {
 	// Time is the time of the most recent query from the device.
-	Time time.Time
+	Time time.Time `json:"time"`
 
 	// Country is the detected country of the client's IP address, if any.
-	Country geoip.Country
+	Country geoip.Country `json:"country"`
 
 	// ASN is the detected ASN of the client's IP address, if any.
-	ASN geoip.ASN
+	ASN geoip.ASN `json:"asn"`
 
 	// Queries is the total number of Queries the device has performed since the
 	// most recent sync.  This value is an int32 to be in sync with the business
 	// logic backend which uses this type.  Change it if it is changed there.
 	// Queries must not be negative.
-	Queries int32
+	Queries int32 `json:"queries"`
 
 	// Proto is the DNS protocol of the most recent query from the device.
-	Proto agd.Protocol
+	Proto agd.Protocol `json:"proto"`
 }
 
 // Records is a helpful alias for a mapping of devices to their billing
@@ -10,34 +10,51 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/golibs/errors"
 )
 
 // RuntimeRecorderConfig is the configuration structure for a runtime billing
-// statistics recorder.  All fields must be non-empty.
+// statistics recorder.
 type RuntimeRecorderConfig struct {
-	// Logger is used for logging the operation of the recorder.
+	// Logger is used for logging the operation of the recorder.  It must not be
+	// nil.
 	Logger *slog.Logger
 
-	// ErrColl is used to collect errors during refreshes.
+	// ErrColl is used to collect errors during refreshes.  It must not be nil.
 	ErrColl errcoll.Interface
 
-	// Uploader is used to upload the billing statistics records to.
+	// Uploader is used to upload the billing statistics records to.  It must
+	// not be nil.
 	Uploader Uploader
 
-	// Metrics is used for the collection of the billing statistics.
+	// Metrics is used for the collection of the billing statistics.  It must
+	// not be nil.
 	Metrics Metrics
+
+	// SpillPath is the optional path to the file to which the records that
+	// failed to upload are saved once the uploads have been failing for
+	// SpillAfter.  The saved records are uploaded along with the new ones
+	// during the next refresh, including the ones after a restart.  If it is
+	// empty, the records that failed to upload are only kept in memory.
+	SpillPath string
+
+	// SpillAfter is the duration for which the uploads must be failing before
+	// the records are saved to SpillPath.  It must not be negative.
+	SpillAfter time.Duration
 }
 
 // NewRuntimeRecorder creates a new runtime billing statistics database.  c must
 // be non-nil.
 func NewRuntimeRecorder(c *RuntimeRecorderConfig) (r *RuntimeRecorder) {
 	return &RuntimeRecorder{
-		logger:   c.Logger,
-		mu:       &sync.Mutex{},
-		records:  Records{},
-		uploader: c.Uploader,
-		errColl:  c.ErrColl,
-		metrics:  c.Metrics,
+		logger:     c.Logger,
+		mu:         &sync.Mutex{},
+		records:    Records{},
+		uploader:   c.Uploader,
+		errColl:    c.ErrColl,
+		metrics:    c.Metrics,
+		spillPath:  c.SpillPath,
+		spillAfter: c.SpillAfter,
 	}
 }
 
@@ -61,6 +78,20 @@ type RuntimeRecorder struct {
 
 	// metrics is used for the collection of the billing statistics.
 	metrics Metrics
+
+	// failingSince is the time of the first failed upload in the current
+	// streak of failed uploads.  It is zero if the most recent upload was
+	// successful.  It is only accessed from Refresh, which must not be called
+	// concurrently.
+	failingSince time.Time
+
+	// spillPath is the optional path to the file with the records that failed
+	// to upload.
+	spillPath string
+
+	// spillAfter is the duration for which the uploads must be failing before
+	// the records are saved to spillPath.
+	spillAfter time.Duration
 }
 
 // type check
@@ -102,21 +133,24 @@ func (r *RuntimeRecorder) Record(
 var _ agdservice.Refresher = (*RuntimeRecorder)(nil)
 
 // Refresh implements the [agdserivce.Refresher] interface for *RuntimeRecorder.
-// It uploads the currently available data and resets it.
+// It uploads the currently available data, including the records saved to the
+// spill file, if any, and resets it.  It must not be called concurrently.
 func (r *RuntimeRecorder) Refresh(ctx context.Context) (err error) {
 	r.logger.DebugContext(ctx, "refresh started")
 	defer r.logger.DebugContext(ctx, "refresh finished")
 
 	records := r.resetRecords(ctx)
+	spilled := r.loadSpill(ctx, records)
 
 	startTime := time.Now()
 	defer func() {
 		dur := time.Since(startTime).Seconds()
 
 		isSuccess := err == nil
-		if !isSuccess {
-			r.remergeRecords(ctx, records)
-			r.logger.WarnContext(ctx, "refresh failed, records remerged")
+		if isSuccess {
+			r.handleUploadSuccess(ctx, spilled)
+		} else {
+			r.handleUploadFailure(ctx, startTime, records, spilled, err)
 		}
 
 		r.metrics.HandleUploadDuration(ctx, dur, isSuccess)
@@ -130,6 +164,46 @@ func (r *RuntimeRecorder) Refresh(ctx context.Context) (err error) {
 	return err
 }
 
+// handleUploadSuccess resets the failure streak and removes the spill file, if
+// its records have been uploaded.
+func (r *RuntimeRecorder) handleUploadSuccess(ctx context.Context, spilled bool) {
+	r.failingSince = time.Time{}
+
+	if spilled {
+		r.removeSpill(ctx)
+	}
+}
+
+// handleUploadFailure remerges the records that failed to upload and saves the
+// pending records to the spill file, if necessary.  now is the time of the
+// failed upload, err is the upload error.
+func (r *RuntimeRecorder) handleUploadFailure(
+	ctx context.Context,
+	now time.Time,
+	records Records,
+	spilled bool,
+	err error,
+) {
+	if r.failingSince.IsZero() {
+		r.failingSince = now
+	}
+
+	failed := records
+	var partErr *PartialUploadError
+	if errors.As(err, &partErr) {
+		failed = partErr.Failed
+	}
+
+	r.remergeRecords(ctx, failed)
+	r.logger.WarnContext(ctx, "refresh failed, records remerged", "failed", len(failed))
+
+	// Save the records to the spill file if they have been loaded from it, so
+	// that they aren't loaded and counted twice.
+	if r.spillPath != "" && (spilled || now.Sub(r.failingSince) >= r.spillAfter) {
+		r.spill(ctx)
+	}
+}
+
 // resetRecords returns the current data and resets the records map to an empty
 // map.
 func (r *RuntimeRecorder) resetRecords(ctx context.Context) (records Records) {
@@ -149,13 +223,19 @@ func (r *RuntimeRecorder) remergeRecords(ctx context.Context, records Records) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	for devID, prev := range records {
-		if curr, ok := r.records[devID]; !ok {
-			r.records[devID] = prev
+	mergeOlder(r.records, records)
+
+	r.metrics.BufferSizeSet(ctx, float64(len(r.records)))
+}
+
+// mergeOlder merges the older records into dst.  If dst already contains a
+// record for a device, only the number of queries is added to it.
+func mergeOlder(dst, older Records) {
+	for devID, prev := range older {
+		if curr, ok := dst[devID]; !ok {
+			dst[devID] = prev
 		} else {
 			curr.Queries += prev.Queries
 		}
 	}
-
-	r.metrics.BufferSizeSet(ctx, float64(len(r.records)))
 }
@@ -2,6 +2,7 @@ package billstat_test
 
 import (
 	"context"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -145,3 +146,107 @@ func TestRuntimeRecorder_fail(t *testing.T) {
 	assert.Equal(t, gotRecord.Queries, int32(2))
 	assert.Equal(t, gotRecord.Proto, proto)
 }
+
+func TestRuntimeRecorder_spill(t *testing.T) {
+	const testError errors.Error = "test error"
+
+	spillPath := filepath.Join(t.TempDir(), "billstat.json")
+
+	var emulateFail bool
+	var gotRecords billstat.Records
+	c := &billstat.RuntimeRecorderConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		ErrColl: &agdtest.ErrorCollector{
+			OnCollect: func(_ context.Context, _ error) {},
+		},
+		Uploader: &agdtest.BillStatUploader{
+			OnUpload: func(_ context.Context, records billstat.Records) (err error) {
+				if emulateFail {
+					return testError
+				}
+
+				gotRecords = records
+
+				return nil
+			},
+		},
+		Metrics:    billstat.EmptyMetrics{},
+		SpillPath:  spillPath,
+		SpillAfter: 0,
+	}
+
+	ctx := context.Background()
+	start := time.Now().Truncate(1 * time.Millisecond)
+
+	r := billstat.NewRuntimeRecorder(c)
+	r.Record(ctx, devID, clientCtry, clientASN, start, proto)
+
+	emulateFail = true
+	err := r.Refresh(ctx)
+	require.ErrorIs(t, err, testError)
+	require.FileExists(t, spillPath)
+
+	// Emulate a restart.
+	r = billstat.NewRuntimeRecorder(c)
+	r.Record(ctx, devID, clientCtry, clientASN, start, proto)
+
+	emulateFail = false
+	err = r.Refresh(ctx)
+	require.NoError(t, err)
+	require.NoFileExists(t, spillPath)
+
+	gotRecord := gotRecords[devID]
+	require.NotNil(t, gotRecord)
+
+	assert.Equal(t, gotRecord.Time.UTC(), start.UTC())
+	assert.Equal(t, gotRecord.Country, clientCtry)
+	assert.Equal(t, gotRecord.ASN, clientASN)
+	assert.Equal(t, gotRecord.Queries, int32(2))
+	assert.Equal(t, gotRecord.Proto, proto)
+}
+
+func TestRuntimeRecorder_partial(t *testing.T) {
+	const otherDevID = "dev5678"
+
+	var partial bool
+	var gotRecords billstat.Records
+	c := &billstat.RuntimeRecorderConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		ErrColl: &agdtest.ErrorCollector{
+			OnCollect: func(_ context.Context, _ error) {},
+		},
+		Uploader: &agdtest.BillStatUploader{
+			OnUpload: func(_ context.Context, records billstat.Records) (err error) {
+				if partial {
+					return &billstat.PartialUploadError{
+						Err:    errors.Error("test error"),
+						Failed: billstat.Records{otherDevID: records[otherDevID]},
+					}
+				}
+
+				gotRecords = records
+
+				return nil
+			},
+		},
+		Metrics: billstat.EmptyMetrics{},
+	}
+
+	ctx := context.Background()
+	start := time.Now().Truncate(1 * time.Millisecond)
+
+	r := billstat.NewRuntimeRecorder(c)
+	r.Record(ctx, devID, clientCtry, clientASN, start, proto)
+	r.Record(ctx, otherDevID, clientCtry, clientASN, start, proto)
+
+	partial = true
+	err := r.Refresh(ctx)
+	require.Error(t, err)
+
+	partial = false
+	err = r.Refresh(ctx)
+	require.NoError(t, err)
+
+	assert.Nil(t, gotRecords[devID])
+	assert.NotNil(t, gotRecords[otherDevID])
+}
@@ -0,0 +1,77 @@
+package billstat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/golibs/errors"
+	renameio "github.com/google/renameio/v2"
+)
+
+// loadSpill merges the records from the spill file, if any, into records.
+// spilled is true if the spill file has been loaded.
+func (r *RuntimeRecorder) loadSpill(ctx context.Context, records Records) (spilled bool) {
+	if r.spillPath == "" {
+		return false
+	}
+
+	b, err := os.ReadFile(r.spillPath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			errcoll.Collect(ctx, r.errColl, r.logger, "reading billstat spill", err)
+		}
+
+		return false
+	}
+
+	older := Records{}
+	err = json.Unmarshal(b, &older)
+	if err != nil {
+		err = fmt.Errorf("decoding %q: %w", r.spillPath, err)
+		errcoll.Collect(ctx, r.errColl, r.logger, "loading billstat spill", err)
+
+		return false
+	}
+
+	mergeOlder(records, older)
+
+	r.logger.InfoContext(ctx, "loaded spilled records", "num", len(older))
+
+	return true
+}
+
+// spill saves the pending records to the spill file and removes them from
+// memory.  If saving fails, the records are kept in memory.
+func (r *RuntimeRecorder) spill(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.Marshal(r.records)
+	if err == nil {
+		err = renameio.WriteFile(r.spillPath, b, agd.DefaultPerm)
+	}
+
+	if err != nil {
+		errcoll.Collect(ctx, r.errColl, r.logger, "saving billstat spill", err)
+
+		return
+	}
+
+	r.logger.WarnContext(ctx, "spilled records", "num", len(r.records), "path", r.spillPath)
+
+	r.records = Records{}
+	r.metrics.BufferSizeSet(ctx, 0)
+}
+
+// removeSpill removes the spill file after its records have been uploaded.
+func (r *RuntimeRecorder) removeSpill(ctx context.Context) {
+	err := os.Remove(r.spillPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		errcoll.Collect(ctx, r.errColl, r.logger, "removing billstat spill", err)
+	}
+}
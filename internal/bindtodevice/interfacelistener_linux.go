@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net"
+	"sync/atomic"
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
@@ -28,6 +29,7 @@ type interfaceListener struct {
 	oobPool            *syncutil.Pool[[]byte]
 	writeRequests      chan *packetConnWriteReq
 	done               chan unit
+	standby            *atomic.Bool
 	errColl            errcoll.Interface
 	writeRequestsGauge prometheus.Gauge
 	writeDurationHist  prometheus.Observer
@@ -75,10 +77,18 @@ func (l *interfaceListener) listenTCP(ctx context.Context, errCh chan<- error) {
 }
 
 // processConn processes a single connection.  If the connection doesn't have a
-// connected channel-listener, it is closed.
+// connected channel-listener or the listener is in the standby mode, it is
+// closed.
 func (l *interfaceListener) processConn(ctx context.Context, logger *slog.Logger, conn net.Conn) {
 	laddr := netutil.NetAddrToAddrPort(conn.LocalAddr())
 	raddr := conn.RemoteAddr()
+	if l.standby.Load() {
+		optslog.Debug2(ctx, logger, "standby, closing", "raddr", raddr, "laddr", laddr)
+		closeConn(ctx, logger, conn)
+
+		return
+	}
+
 	if lsnr := l.conns.listener(laddr.Addr()); lsnr != nil {
 		if !lsnr.send(conn) {
 			optslog.Debug2(ctx, logger, "channel is closed", "raddr", raddr, "laddr", laddr)
@@ -90,10 +100,14 @@ func (l *interfaceListener) processConn(ctx context.Context, logger *slog.Logger
 	metrics.BindToDeviceUnknownTCPRequestsTotal.Inc()
 
 	optslog.Debug2(ctx, logger, "no stream channel", "raddr", raddr, "laddr", laddr)
+	closeConn(ctx, logger, conn)
+}
 
+// closeConn closes conn and logs the error, if any.
+func closeConn(ctx context.Context, logger *slog.Logger, conn net.Conn) {
 	err := conn.Close()
 	if err != nil {
-		optslog.Debug2(ctx, logger, "closing", "raddr", raddr, slogutil.KeyError, err)
+		optslog.Debug2(ctx, logger, "closing", "raddr", conn.RemoteAddr(), slogutil.KeyError, err)
 	}
 }
 
@@ -169,6 +183,15 @@ func (l *interfaceListener) readUDP(
 	}
 
 	laddr := sess.laddr.AddrPort().Addr()
+	if l.standby.Load() {
+		optslog.Debug2(ctx, logger, "standby, dropping", "raddr", sess.raddr, "laddr", laddr)
+
+		// Return the body to the pool, since the session isn't sent anywhere.
+		l.bodyPool.Put(bodyPtr)
+
+		return nil
+	}
+
 	chanPacketConn := l.conns.packetConn(laddr)
 	if chanPacketConn == nil {
 		metrics.BindToDeviceUnknownUDPRequestsTotal.Inc()
@@ -11,6 +11,7 @@ import (
 	"net/netip"
 	"slices"
 	"sync"
+	"sync/atomic"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdnet"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
@@ -30,6 +31,7 @@ type Manager struct {
 	ifaceListeners map[ID]*interfaceListener
 	errColl        errcoll.Interface
 	done           chan unit
+	standby        *atomic.Bool
 	chanBufSize    int
 }
 
@@ -42,6 +44,7 @@ func NewManager(c *ManagerConfig) (m *Manager) {
 		ifaceListeners: map[ID]*interfaceListener{},
 		errColl:        c.ErrColl,
 		done:           make(chan unit),
+		standby:        &atomic.Bool{},
 		chanBufSize:    c.ChannelBufferSize,
 	}
 }
@@ -113,6 +116,7 @@ func (m *Manager) newInterfaceListener(
 		oobPool:            syncutil.NewSlicePool[byte](netext.IPDstOOBSize),
 		writeRequests:      make(chan *packetConnWriteReq, m.chanBufSize),
 		done:               m.done,
+		standby:            m.standby,
 		errColl:            m.errColl,
 		writeRequestsGauge: metrics.BindToDeviceUDPWriteRequestsChanSize.WithLabelValues(ifaceName),
 		writeDurationHist:  metrics.BindToDeviceUDPWriteDurationSeconds.WithLabelValues(ifaceName),
@@ -216,6 +220,20 @@ func (m *Manager) validateIfaceSubnet(ifaceName string, subnet netip.Prefix) (er
 	return fmt.Errorf("interface %s does not contain subnet %s", ifaceName, subnet)
 }
 
+// SetStandby sets the standby mode of the listeners.  In the standby mode, the
+// listeners drop all incoming packets and close all incoming connections, since
+// the other node of the pair is the one that serves them.  If m is nil,
+// SetStandby does nothing, since this feature is optional.
+//
+// SetStandby is safe for concurrent use.
+func (m *Manager) SetStandby(standby bool) {
+	if m == nil {
+		return
+	}
+
+	m.standby.Store(standby)
+}
+
 // type check
 var _ service.Interface = (*Manager)(nil)
 
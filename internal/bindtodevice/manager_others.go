@@ -42,6 +42,11 @@ func (m *Manager) ListenConfig(id ID, subnet netip.Prefix) (c *ListenConfig, err
 	)
 }
 
+// SetStandby sets the standby mode of the listeners.
+//
+// It is only supported on Linux, so it does nothing.
+func (m *Manager) SetStandby(_ bool) {}
+
 // type check
 var _ service.Interface = (*Manager)(nil)
 
@@ -0,0 +1,210 @@
+// Package cachecrypt contains the encryption of the caches stored at rest, such
+// as the profile cache file, with support for key rotation.
+package cachecrypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// KeyLen is the length of a key, in bytes.
+const KeyLen = 32
+
+// Key is a NaCl secretbox key.
+type Key [KeyLen]byte
+
+// ParseKey parses a hex-encoded key.
+func ParseKey(s string) (k *Key, err error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decoding key: %w", err)
+	}
+
+	if len(b) != KeyLen {
+		return nil, fmt.Errorf("key length: got %d bytes, want %d", len(b), KeyLen)
+	}
+
+	k = &Key{}
+	copy(k[:], b)
+
+	return k, nil
+}
+
+// ParseKeys parses a comma-separated list of hex-encoded keys.  The first key
+// is the current one, and the rest are the previous ones.  s must not be
+// empty.
+func ParseKeys(s string) (keys *StaticKeys, err error) {
+	parts := strings.Split(s, ",")
+	parsed := make([]*Key, 0, len(parts))
+	for i, p := range parts {
+		var k *Key
+		k, err = ParseKey(strings.TrimSpace(p))
+		if err != nil {
+			return nil, fmt.Errorf("at index %d: %w", i, err)
+		}
+
+		parsed = append(parsed, k)
+	}
+
+	return NewStaticKeys(parsed[0], parsed[1:]...), nil
+}
+
+// id returns the identifier of the key, which is stored along with the data
+// and allows to find the key during decryption without trying all of them.
+func (k *Key) id() (id [keyIDLen]byte) {
+	sum := sha256.Sum256(k[:])
+	copy(id[:], sum[:])
+
+	return id
+}
+
+// KeySource is the source of the keys.  It allows the keys to be provided by
+// a key-management service.
+//
+// All methods must be safe for concurrent use.
+type KeySource interface {
+	// Keys returns the current key, which is used to encrypt the data, and the
+	// previous keys, which are only used to decrypt the data encrypted before
+	// a rotation.  If err is nil, current must not be nil.
+	Keys(ctx context.Context) (current *Key, previous []*Key, err error)
+}
+
+// StaticKeys is a [KeySource] with fixed keys, for example the ones from the
+// environment.
+type StaticKeys struct {
+	current  *Key
+	previous []*Key
+}
+
+// NewStaticKeys returns a new *StaticKeys with the given keys.  current must
+// not be nil.
+func NewStaticKeys(current *Key, previous ...*Key) (keys *StaticKeys) {
+	return &StaticKeys{
+		current:  current,
+		previous: previous,
+	}
+}
+
+// type check
+var _ KeySource = (*StaticKeys)(nil)
+
+// Keys implements the [KeySource] interface for *StaticKeys.
+func (s *StaticKeys) Keys(_ context.Context) (current *Key, previous []*Key, err error) {
+	return s.current, s.previous, nil
+}
+
+// Format of the encrypted data:
+//
+//	magic (4 bytes) | version (1 byte) | key ID (8 bytes) | nonce (24 bytes) | box
+const (
+	keyIDLen  = 8
+	nonceLen  = 24
+	version   = 1
+	headerLen = len(magic) + 1 + keyIDLen + nonceLen
+)
+
+// magic is the prefix of the encrypted data.
+const magic = "AGDC"
+
+// ErrNoKey is returned by [Cipher.Open] when none of the keys matches the one
+// used to encrypt the data.
+const ErrNoKey errors.Error = "no matching key"
+
+// IsEncrypted returns true if data looks like the data encrypted by a
+// [Cipher].
+func IsEncrypted(data []byte) (ok bool) {
+	return len(data) >= headerLen && bytes.HasPrefix(data, []byte(magic))
+}
+
+// Cipher encrypts and decrypts the data using the keys from a [KeySource].
+type Cipher struct {
+	keys KeySource
+}
+
+// NewCipher returns a new *Cipher.  keys must not be nil.
+func NewCipher(keys KeySource) (c *Cipher) {
+	return &Cipher{
+		keys: keys,
+	}
+}
+
+// Seal encrypts plaintext with the current key.
+func (c *Cipher) Seal(ctx context.Context, plaintext []byte) (data []byte, err error) {
+	current, _, err := c.keys.Keys(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting keys: %w", err)
+	}
+
+	var nonce [nonceLen]byte
+	_, err = rand.Read(nonce[:])
+	if err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	id := current.id()
+
+	data = make([]byte, 0, headerLen+len(plaintext)+secretbox.Overhead)
+	data = append(data, magic...)
+	data = append(data, version)
+	data = append(data, id[:]...)
+	data = append(data, nonce[:]...)
+
+	return secretbox.Seal(data, plaintext, &nonce, (*[KeyLen]byte)(current)), nil
+}
+
+// Open decrypts data encrypted by [Cipher.Seal].  rotated is true if data has
+// been encrypted with one of the previous keys and should be encrypted again
+// with the current one.  data must be encrypted, see [IsEncrypted].
+func (c *Cipher) Open(ctx context.Context, data []byte) (plaintext []byte, rotated bool, err error) {
+	if !IsEncrypted(data) {
+		return nil, false, errors.Error("data is not encrypted")
+	}
+
+	if v := data[len(magic)]; v != version {
+		return nil, false, fmt.Errorf("version: %w: %d", errors.ErrBadEnumValue, v)
+	}
+
+	current, previous, err := c.keys.Keys(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("getting keys: %w", err)
+	}
+
+	idStart := len(magic) + 1
+	id := [keyIDLen]byte(data[idStart : idStart+keyIDLen])
+	key := findKey(id, current, previous)
+	if key == nil {
+		return nil, false, ErrNoKey
+	}
+
+	nonce := [nonceLen]byte(data[idStart+keyIDLen : headerLen])
+	plaintext, ok := secretbox.Open(nil, data[headerLen:], &nonce, (*[KeyLen]byte)(key))
+	if !ok {
+		return nil, false, errors.Error("authentication failed")
+	}
+
+	return plaintext, key != current, nil
+}
+
+// findKey returns the key with the given ID among current and previous or nil
+// if there is none.
+func findKey(id [keyIDLen]byte, current *Key, previous []*Key) (k *Key) {
+	if current.id() == id {
+		return current
+	}
+
+	for _, k = range previous {
+		if k.id() == id {
+			return k
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package cachecrypt_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/cachecrypt"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// Common keys for tests.
+var (
+	testKeyOld = &cachecrypt.Key{1}
+	testKeyNew = &cachecrypt.Key{2}
+)
+
+func TestCipher(t *testing.T) {
+	t.Parallel()
+
+	plaintext := []byte("test data")
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	oldCipher := cachecrypt.NewCipher(cachecrypt.NewStaticKeys(testKeyOld))
+	data, err := oldCipher.Seal(ctx, plaintext)
+	require.NoError(t, err)
+
+	assert.True(t, cachecrypt.IsEncrypted(data))
+	assert.False(t, cachecrypt.IsEncrypted(plaintext))
+
+	testCases := []struct {
+		keys        *cachecrypt.StaticKeys
+		name        string
+		wantErrMsg  string
+		wantRotated bool
+	}{{
+		keys:        cachecrypt.NewStaticKeys(testKeyOld),
+		name:        "current",
+		wantErrMsg:  "",
+		wantRotated: false,
+	}, {
+		keys:        cachecrypt.NewStaticKeys(testKeyNew, testKeyOld),
+		name:        "previous",
+		wantErrMsg:  "",
+		wantRotated: true,
+	}, {
+		keys:        cachecrypt.NewStaticKeys(testKeyNew),
+		name:        "no_key",
+		wantErrMsg:  string(cachecrypt.ErrNoKey),
+		wantRotated: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			c := cachecrypt.NewCipher(tc.keys)
+			got, rotated, openErr := c.Open(testutil.ContextWithTimeout(t, testTimeout), data)
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, openErr)
+			assert.Equal(t, tc.wantRotated, rotated)
+			if tc.wantErrMsg == "" {
+				assert.Equal(t, plaintext, got)
+			}
+		})
+	}
+}
+
+func TestCipher_Open_tampered(t *testing.T) {
+	t.Parallel()
+
+	c := cachecrypt.NewCipher(cachecrypt.NewStaticKeys(testKeyOld))
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	data, err := c.Seal(ctx, []byte("test data"))
+	require.NoError(t, err)
+
+	data[len(data)-1] ^= 0xff
+
+	_, _, err = c.Open(ctx, data)
+	testutil.AssertErrorMsg(t, "authentication failed", err)
+}
+
+func TestParseKeys(t *testing.T) {
+	t.Parallel()
+
+	keyHex := strings.Repeat("01", cachecrypt.KeyLen)
+
+	testCases := []struct {
+		name       string
+		in         string
+		wantErrMsg string
+	}{{
+		name:       "single",
+		in:         keyHex,
+		wantErrMsg: "",
+	}, {
+		name:       "several",
+		in:         keyHex + ", " + strings.Repeat("02", cachecrypt.KeyLen),
+		wantErrMsg: "",
+	}, {
+		name:       "short",
+		in:         keyHex + ",0102",
+		wantErrMsg: "at index 1: key length: got 2 bytes, want 32",
+	}, {
+		name:       "bad_hex",
+		in:         "xyz",
+		wantErrMsg: "at index 0: decoding key: encoding/hex: invalid byte: U+0078 'x'",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			keys, err := cachecrypt.ParseKeys(tc.in)
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, err)
+			if tc.wantErrMsg == "" {
+				assert.NotNil(t, keys)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// auditLogConfig is the configuration of the append-only log of the events
+// that change the state of AdGuard DNS.
+type auditLogConfig struct {
+	// Path is the path to the audit log file.
+	Path string `yaml:"path"`
+
+	// RecentSize is the number of the most recent entries served by the debug
+	// HTTP API.
+	RecentSize int `yaml:"recent_size"`
+
+	// Enabled shows if the audit log is written.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*auditLogConfig)(nil)
+
+// validate implements the [validator] interface for *auditLogConfig.  The
+// audit log configuration is optional.
+func (c *auditLogConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.Path == "":
+		return fmt.Errorf("path: %w", errors.ErrEmptyValue)
+	case c.RecentSize < 0:
+		return newNegativeError("recent_size", c.RecentSize)
+	default:
+		return nil
+	}
+}
@@ -33,9 +33,41 @@ type backendConfig struct {
 	// synchronizations.
 	FullRefreshRetryIvl timeutil.Duration `yaml:"full_refresh_retry_interval"`
 
+	// FullRefreshPageSize is the maximum number of profiles in one page of a
+	// full synchronization.  Zero means that all profiles are received at once.
+	FullRefreshPageSize uint32 `yaml:"full_refresh_page_size"`
+
+	// ProfilesGCRetention is the time after which the deleted profiles and the
+	// devices that don't belong to any profile are removed between full
+	// synchronizations.  Zero means that they are only removed by full
+	// synchronizations.
+	ProfilesGCRetention timeutil.Duration `yaml:"profiles_gc_retention"`
+
+	// HumanIDCacheSize is the maximum number of entries in the cache of the
+	// lookups of devices by their human-readable IDs.  Zero means that the
+	// cache is disabled.
+	HumanIDCacheSize int `yaml:"human_id_cache_size"`
+
+	// HumanIDNegativeTTL is the time for which the failed automatic creations
+	// of devices are cached.  Zero means that they are not cached.
+	HumanIDNegativeTTL timeutil.Duration `yaml:"human_id_negative_ttl"`
+
 	// BillStatIvl defines how often AdGuard DNS sends the billing statistics to
 	// the backend.
 	BillStatIvl timeutil.Duration `yaml:"bill_stat_interval"`
+
+	// BillStatSpillAfter is the duration for which the uploads of the billing
+	// statistics must be failing before the records are saved to the spill
+	// file.  It is only used if the spill file path is set.
+	BillStatSpillAfter timeutil.Duration `yaml:"bill_stat_spill_after"`
+
+	// BillStatChunkSize is the maximum number of billing statistics records
+	// sent over one stream.  Zero means that all records are sent at once.
+	BillStatChunkSize uint32 `yaml:"bill_stat_chunk_size"`
+
+	// BillStatMaxRetries is the maximum number of retries of the upload of a
+	// chunk of billing statistics records.
+	BillStatMaxRetries uint32 `yaml:"bill_stat_max_retries"`
 }
 
 // type check
@@ -54,8 +86,16 @@ func (c *backendConfig) validate() (err error) {
 		return newNotPositiveError("full_refresh_interval", c.FullRefreshIvl)
 	case c.FullRefreshRetryIvl.Duration <= 0:
 		return newNotPositiveError("full_refresh_retry_interval", c.FullRefreshRetryIvl)
+	case c.ProfilesGCRetention.Duration < 0:
+		return newNegativeError("profiles_gc_retention", c.ProfilesGCRetention)
+	case c.HumanIDCacheSize < 0:
+		return newNegativeError("human_id_cache_size", c.HumanIDCacheSize)
+	case c.HumanIDNegativeTTL.Duration < 0:
+		return newNegativeError("human_id_negative_ttl", c.HumanIDNegativeTTL)
 	case c.BillStatIvl.Duration <= 0:
 		return newNotPositiveError("bill_stat_interval", c.BillStatIvl)
+	case c.BillStatSpillAfter.Duration < 0:
+		return newNegativeError("bill_stat_spill_after", c.BillStatSpillAfter)
 	default:
 		return nil
 	}
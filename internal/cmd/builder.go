@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"log/slog"
 	"maps"
@@ -13,35 +14,60 @@ import (
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/access"
+	"github.com/AdguardTeam/AdGuardDNS/internal/accountlimit"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/auditlog"
 	"github.com/AdguardTeam/AdGuardDNS/internal/backendpb"
 	"github.com/AdguardTeam/AdGuardDNS/internal/billstat"
 	"github.com/AdguardTeam/AdGuardDNS/internal/bindtodevice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/cachecrypt"
 	"github.com/AdguardTeam/AdGuardDNS/internal/cmd/plugin"
 	"github.com/AdguardTeam/AdGuardDNS/internal/connlimiter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/consul"
 	"github.com/AdguardTeam/AdGuardDNS/internal/debugsvc"
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnscheck"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsdb"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/forward"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
+	dnssrvprom "github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/prometheus"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/ratelimit"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssign"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/filterstorage"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/hashprefix"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/maintenance"
 	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/AdGuardDNS/internal/noderole"
+	"github.com/AdguardTeam/AdGuardDNS/internal/poisonpill"
+	"github.com/AdguardTeam/AdGuardDNS/internal/popdomains"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnamelimit"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnameredact"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
 	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/remotekv"
+	"github.com/AdguardTeam/AdGuardDNS/internal/remotekv/rediskv"
+	"github.com/AdguardTeam/AdGuardDNS/internal/reqlog"
 	"github.com/AdguardTeam/AdGuardDNS/internal/rulestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/sharedcounter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/snapshot"
+	"github.com/AdguardTeam/AdGuardDNS/internal/staticzone"
 	"github.com/AdguardTeam/AdGuardDNS/internal/tlsconfig"
+	"github.com/AdguardTeam/AdGuardDNS/internal/topprofiles"
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
 	"github.com/AdguardTeam/AdGuardDNS/internal/websvc"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
+	"github.com/AdguardTeam/AdGuardDNS/internal/xdpfilter"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/AdguardTeam/golibs/netutil/urlutil"
@@ -53,16 +79,27 @@ import (
 
 // Constants that define debug identifiers for the debug HTTP service.
 const (
-	debugIDAllowlist     = "allowlist"
-	debugIDBillStat      = "billstat"
-	debugIDGeoIP         = "geoip"
-	debugIDProfileDB     = "profiledb"
-	debugIDRuleStat      = "rulestat"
-	debugIDTicketRotator = "ticket_rotator"
-	debugIDTLSConfig     = "tlsconfig"
-	debugIDWebSvc        = "websvc"
+	debugIDAllowlist      = "allowlist"
+	debugIDBillStat       = "billstat"
+	debugIDDNSSign        = "dnssign"
+	debugIDGeoIP          = "geoip"
+	debugIDNodeRole       = "noderole"
+	debugIDProfileDB      = "profiledb"
+	debugIDRuleStat       = "rulestat"
+	debugIDServerGroups   = "server_groups"
+	debugIDStandardAccess = "standard_access"
+	debugIDStaticZones    = "static_zones"
+	debugIDTicketRotator  = "ticket_rotator"
+	debugIDTicketSync     = "ticket_sync"
+	debugIDTLSConfig      = "tlsconfig"
+	debugIDWebSvc         = "websvc"
+	debugIDXDPFilter      = "xdpfilter"
 )
 
+// snapshotIDRateLimit is the name of the rate limiter in the runtime state
+// snapshots.
+const snapshotIDRateLimit = "ratelimit"
+
 // builder contains the logic of configuring and combining together AdGuard DNS
 // entities.
 //
@@ -88,39 +125,66 @@ type builder struct {
 	plugins        *plugin.Registry
 	promRegisterer prometheus.Registerer
 	sigHdlr        *service.SignalHandler
+	snapshots      snapshot.Manager
 
 	// The fields below are initialized later by calling the builder's methods.
 	// Keep them sorted.
 
 	access              *access.Global
+	accountLimiter      accountlimit.Interface
 	adultBlocking       *hashprefix.Filter
 	adultBlockingHashes *hashprefix.Storage
+	auditLog            auditlog.Interface
 	backendGRPCMtrc     *metrics.BackendGRPC
 	billStat            billstat.Recorder
 	bindSet             netutil.SubnetSet
 	btdManager          *bindtodevice.Manager
+	checkKV             remotekv.Interface
 	connLimit           *connlimiter.Limiter
 	controlConf         *netext.ControlConfig
+	deviceStat          devicestat.Interface
+	dhcpLeases          dhcplease.Interface
 	dnsCheck            dnscheck.Interface
 	dnsDB               dnsdb.Interface
+	dnsSigner           *dnssign.Signer
 	dnsSvc              *dnssvc.Service
+	dnstap              map[agd.ServerGroupName]*dnssvc.DnstapConfig
+	categoryHashes      *hashprefix.CategoryStorage
 	filterMtrc          filter.Metrics
+	filterStatuses      *filter.StatusMetrics
 	filterStorage       *filterstorage.Default
 	filteringGroups     map[agd.FilteringGroupID]*agd.FilteringGroup
 	fwdHandler          *forward.Handler
 	geoIP               *geoip.File
-	hashMatcher         *hashprefix.Matcher
+	hashMatcher         filter.HashMatcher
+	maintenance         *maintenance.Manager
+	nodeRole            *noderole.Manager
 	messages            *dnsmsg.Constructor
 	newRegDomains       *hashprefix.Filter
 	newRegDomainsHashes *hashprefix.Storage
+	poisonPill          *poisonpill.Guard
 	profileDB           profiledb.Interface
+	quarantine          *quarantine.Default
+	quicAdvisor         dnsserver.QUICAdvisor
 	rateLimit           *ratelimit.Backoff
+	reqLog              reqlog.Interface
 	ruleStat            rulestat.Interface
 	safeBrowsing        *hashprefix.Filter
 	safeBrowsingHashes  *hashprefix.Storage
 	sdeConf             *dnsmsg.StructuredDNSErrorsConfig
+	sharedCounter       sharedcounter.Interface
+	standardAccess      *access.StandardBlocker
+	staticZones         staticzone.Interface
 	tlsManager          *tlsconfig.DefaultManager
+	tlsMtrc             *metrics.TLSConfig
+	topProfiles         topprofiles.Interface
+	popDomains          popdomains.Interface
+	qnameLimiters       map[agd.ServerGroupName]qnamelimit.Interface
+	unblockStrg         unblock.Storage
+	unblockWebConf      *websvc.UnblockConfig
+	viewFwdHandlers     map[agd.ViewName]*forward.Handler
 	webSvc              *websvc.Service
+	whiteLabel          whitelabel.Interface
 
 	// The fields below are initialized later, just like with the fields above,
 	// but are placed in this order for alignment optimization.
@@ -153,9 +217,18 @@ type builderConfig struct {
 // shutdownTimeout is the default shutdown timeout for all services.
 const shutdownTimeout = 5 * time.Second
 
+// clonerInternCount is the number of slots in the table of the domain names
+// interned by the cloner.
+//
+// TODO:  Consider making configurable.
+const clonerInternCount = 1 << 16
+
 // newBuilder returns a new properly initialized builder.  c must not be nil.
 func newBuilder(c *builderConfig) (b *builder) {
-	cloner := dnsmsg.NewCloner(metrics.ClonerStat{})
+	cloner := dnsmsg.NewCloner(&dnsmsg.ClonerConfig{
+		Stat:        metrics.ClonerStat{},
+		InternCount: clonerInternCount,
+	})
 
 	return &builder{
 		baseLogger:     c.baseLogger,
@@ -174,9 +247,26 @@ func newBuilder(c *builderConfig) (b *builder) {
 			Logger:          c.baseLogger.With(slogutil.KeyPrefix, service.SignalHandlerPrefix),
 			ShutdownTimeout: shutdownTimeout,
 		}),
+		snapshots: newSnapshotManager(c),
 	}
 }
 
+// newSnapshotManager returns a new manager of the runtime state snapshots.  If
+// the snapshots are disabled, it returns a manager that does nothing.  c must
+// not be nil.
+func newSnapshotManager(c *builderConfig) (m snapshot.Manager) {
+	if c.envs.RuntimeSnapshotPath == "" {
+		return snapshot.EmptyManager{}
+	}
+
+	return snapshot.NewDefaultManager(&snapshot.DefaultManagerConfig{
+		Logger:  c.baseLogger.With(slogutil.KeyPrefix, "snapshot"),
+		ErrColl: c.errColl,
+		Path:    c.envs.RuntimeSnapshotPath,
+		MaxAge:  c.envs.RuntimeSnapshotMaxAge.Duration,
+	})
+}
+
 // startGeoIP starts the concurrent initialization of the GeoIP database.  The
 // GeoIP initialization is started early and concurrently, because it takes
 // time.  Later methods wait for the completion and continue with GeoIP.
@@ -227,13 +317,16 @@ func (b *builder) initHashPrefixFilters(ctx context.Context) (err error) {
 	maxSize := b.conf.Filters.MaxSize
 	cacheDir := b.env.FilterCachePath
 
-	matchers := map[string]*hashprefix.Storage{}
+	matchers := map[string]hashprefix.HashSource{}
 
-	b.filterMtrc, err = metrics.NewFilter(b.mtrcNamespace, b.promRegisterer)
+	fltMtrc, err := metrics.NewFilter(b.mtrcNamespace, b.promRegisterer)
 	if err != nil {
 		return fmt.Errorf("registering filter metrics: %w", err)
 	}
 
+	b.filterStatuses = filter.NewStatusMetrics(fltMtrc)
+	b.filterMtrc = b.filterStatuses
+
 	// TODO(a.garipov):  Merge the three functions below together.
 
 	err = b.initAdultBlocking(ctx, matchers, maxSize, cacheDir)
@@ -253,6 +346,11 @@ func (b *builder) initHashPrefixFilters(ctx context.Context) (err error) {
 
 	b.hashMatcher = hashprefix.NewMatcher(matchers)
 
+	err = b.initCategoryLookup(ctx)
+	if err != nil {
+		return fmt.Errorf("initializing category lookups: %w", err)
+	}
+
 	b.logger.DebugContext(ctx, "initialized hash prefixes")
 
 	return nil
@@ -265,7 +363,7 @@ func (b *builder) initHashPrefixFilters(ctx context.Context) (err error) {
 // It must be called from [builder.initHashPrefixFilters].
 func (b *builder) initAdultBlocking(
 	ctx context.Context,
-	matchers map[string]*hashprefix.Storage,
+	matchers map[string]hashprefix.HashSource,
 	maxSize datasize.ByteSize,
 	cacheDir string,
 ) (err error) {
@@ -273,11 +371,9 @@ func (b *builder) initAdultBlocking(
 		return nil
 	}
 
-	b.adultBlockingHashes, err = hashprefix.NewStorage("")
-	if err != nil {
-		// Don't expect errors here because we pass an empty string.
-		panic(err)
-	}
+	b.adultBlockingHashes = hashprefix.NewFileStorage(
+		filepath.Join(cacheDir, string(filter.IDAdultBlocking)+hashprefix.TableFileExt),
+	)
 
 	c := b.conf.AdultBlocking
 	id := filter.IDAdultBlocking
@@ -348,11 +444,9 @@ func (b *builder) initNewRegDomains(
 		return nil
 	}
 
-	b.newRegDomainsHashes, err = hashprefix.NewStorage("")
-	if err != nil {
-		// Don't expect errors here because we pass an empty string.
-		panic(err)
-	}
+	b.newRegDomainsHashes = hashprefix.NewFileStorage(
+		filepath.Join(cacheDir, string(filter.IDNewRegDomains)+hashprefix.TableFileExt),
+	)
 
 	// Reuse the general safe-browsing filter configuration with a new URL and
 	// ID.
@@ -414,7 +508,7 @@ func (b *builder) initNewRegDomains(
 // It must be called from [builder.initHashPrefixFilters].
 func (b *builder) initSafeBrowsing(
 	ctx context.Context,
-	matchers map[string]*hashprefix.Storage,
+	matchers map[string]hashprefix.HashSource,
 	maxSize datasize.ByteSize,
 	cacheDir string,
 ) (err error) {
@@ -422,11 +516,9 @@ func (b *builder) initSafeBrowsing(
 		return nil
 	}
 
-	b.safeBrowsingHashes, err = hashprefix.NewStorage("")
-	if err != nil {
-		// Don't expect errors here because we pass an empty string.
-		panic(err)
-	}
+	b.safeBrowsingHashes = hashprefix.NewFileStorage(
+		filepath.Join(cacheDir, string(filter.IDSafeBrowsing)+hashprefix.TableFileExt),
+	)
 
 	c := b.conf.SafeBrowsing
 	id := filter.IDSafeBrowsing
@@ -506,10 +598,14 @@ func (b *builder) initFilterStorage(ctx context.Context) (err error) {
 			// TODO(a.garipov):  Consider adding a separate parameter here.
 			ResultCacheEnabled: c.RuleListCache.Enabled,
 			Enabled:            bool(b.env.BlockedServiceEnabled),
+			CategoryHashes:     b.categoryHashes,
 		},
 		Custom: &filterstorage.ConfigCustom{
 			CacheCount: c.CustomFilterCacheSize,
 		},
+		EssentialServices: &filterstorage.ConfigEssentialServices{
+			Domains: c.EssentialServices,
+		},
 		HashPrefix: &filterstorage.ConfigHashPrefix{
 			Adult:           b.adultBlocking,
 			Dangerous:       b.safeBrowsing,
@@ -527,6 +623,7 @@ func (b *builder) initFilterStorage(ctx context.Context) (err error) {
 			// TODO(a.garipov):  Consider adding a separate parameter here.
 			Staleness:          refrIvl,
 			ResultCacheCount:   c.RuleListCache.Size,
+			MaxCombined:        c.MaxCombinedRuleLists,
 			ResultCacheEnabled: c.RuleListCache.Enabled,
 		},
 		SafeSearchGeneral: b.newSafeSearchConfig(
@@ -619,12 +716,22 @@ func (b *builder) initFilteringGroups(ctx context.Context) (err error) {
 	return nil
 }
 
-// initAccess initializes the global access settings.
+// initAccess initializes the global access settings.  If the backend URL for
+// the standard access settings is set, it also initializes the standard access
+// blocker, which is updated later by [builder.initStandardAccess].
 func (b *builder) initAccess(ctx context.Context) (err error) {
+	if b.env.StandardAccessURL != nil {
+		b.standardAccess = access.NewStandardBlocker(&access.StandardBlockerConfig{
+			Logger: b.baseLogger.With(slogutil.KeyPrefix, "standard_access"),
+			Clock:  agdtime.SystemClock{},
+		})
+	}
+
 	c := b.conf.Access
 	b.access, err = access.NewGlobal(
 		c.BlockedQuestionDomains,
 		netutil.UnembedPrefixes(c.BlockedClientSubnets),
+		b.standardAccess,
 	)
 	if err != nil {
 		return fmt.Errorf("initializing global access: %w", err)
@@ -639,8 +746,10 @@ func (b *builder) initAccess(ctx context.Context) (err error) {
 func (b *builder) initBindToDevice(ctx context.Context) (err error) {
 	c := b.conf
 
+	netMtrc := dnssrvprom.NewNetextMetricsListener(b.mtrcNamespace)
+
 	var btdCtrlConf *bindtodevice.ControlConfig
-	btdCtrlConf, b.controlConf = c.Network.toInternal()
+	btdCtrlConf, b.controlConf = c.Network.toInternal(netMtrc)
 	b.btdManager, err = c.InterfaceListeners.toInternal(b.baseLogger, b.errColl, btdCtrlConf)
 	if err != nil {
 		return fmt.Errorf("converting interface listeners: %w", err)
@@ -697,8 +806,36 @@ func (b *builder) initMsgConstructor(ctx context.Context) (err error) {
 	return nil
 }
 
+// initAuditLog initializes the audit log.
+func (b *builder) initAuditLog(ctx context.Context) (err error) {
+	c := b.conf.AuditLog
+	if c == nil || !c.Enabled {
+		b.auditLog = auditlog.Empty{}
+
+		return nil
+	}
+
+	b.auditLog, err = auditlog.NewFile(ctx, &auditlog.FileConfig{
+		Logger:      b.baseLogger.With(slogutil.KeyPrefix, "auditlog"),
+		Clock:       agdtime.SystemClock{},
+		ErrColl:     b.errColl,
+		Path:        c.Path,
+		RecentCount: c.RecentSize,
+	})
+	if err != nil {
+		return fmt.Errorf("initializing audit log: %w", err)
+	}
+
+	b.logger.DebugContext(ctx, "initialized audit log")
+
+	return nil
+}
+
 // initTLSManager initializes the TLS manager and the TLS-related metrics.  It
 // also adds the refresher with ID [debugIDTLSConfig] to the debug refreshers.
+//
+// The following methods must be called before this one:
+//   - [builder.initAuditLog]
 func (b *builder) initTLSManager(ctx context.Context) (err error) {
 	mtrc, err := metrics.NewTLSConfig(b.mtrcNamespace, b.promRegisterer)
 	if err != nil {
@@ -714,6 +851,7 @@ func (b *builder) initTLSManager(ctx context.Context) (err error) {
 
 	mgr, err := tlsconfig.NewDefaultManager(&tlsconfig.DefaultManagerConfig{
 		Logger:             b.baseLogger.With(slogutil.KeyPrefix, "tlsconfig"),
+		AuditLog:           b.auditLog,
 		ErrColl:            b.errColl,
 		Metrics:            mtrc,
 		KeyLogFilename:     logFile,
@@ -724,6 +862,7 @@ func (b *builder) initTLSManager(ctx context.Context) (err error) {
 	}
 
 	b.tlsManager = mgr
+	b.tlsMtrc = mtrc
 	b.debugRefrs[debugIDTLSConfig] = mgr
 
 	b.logger.DebugContext(ctx, "initialized tls manager")
@@ -748,11 +887,14 @@ func (b *builder) initServerGroups(ctx context.Context) (err error) {
 		b.filteringGroups,
 		c.RateLimit,
 		c.DNS,
+		b.controlConf.Metrics,
 	)
 	if err != nil {
 		return fmt.Errorf("initializing server groups: %w", err)
 	}
 
+	b.maintenance = c.ServerGroups.toMaintenanceManager()
+
 	b.setServerGroupProperties(ctx)
 
 	b.logger.DebugContext(ctx, "initialized server groups")
@@ -859,7 +1001,8 @@ func (b *builder) initGRPCMetrics(ctx context.Context) (err error) {
 	case
 		b.profilesEnabled,
 		b.conf.Check.RemoteKV.Type == kvModeBackend,
-		b.conf.RateLimit.Allowlist.Type == rlAllowlistTypeBackend:
+		b.conf.RateLimit.Allowlist.Type == rlAllowlistTypeBackend,
+		b.env.ServerGroupsURL != nil:
 		// Go on.
 	default:
 		// Don't initialize the metrics if no protobuf backend is used.
@@ -896,14 +1039,16 @@ func (b *builder) initBillStat(ctx context.Context) (err error) {
 		return fmt.Errorf("registering billstat metrics: %w", err)
 	}
 
+	c := b.conf.Backend
 	billStat := billstat.NewRuntimeRecorder(&billstat.RuntimeRecorderConfig{
-		Logger:   b.baseLogger.With(slogutil.KeyPrefix, "billstat"),
-		ErrColl:  b.errColl,
-		Uploader: upl,
-		Metrics:  mtrc,
+		Logger:     b.baseLogger.With(slogutil.KeyPrefix, "billstat"),
+		ErrColl:    b.errColl,
+		Uploader:   upl,
+		Metrics:    mtrc,
+		SpillPath:  b.env.BillStatSpillPath,
+		SpillAfter: c.BillStatSpillAfter.Duration,
 	})
 
-	c := b.conf.Backend
 	refrIvl := c.BillStatIvl.Duration
 	timeout := c.Timeout.Duration
 
@@ -945,13 +1090,16 @@ func (b *builder) newBillStatUploader() (s billstat.Uploader, err error) {
 		GRPCMetrics: b.backendGRPCMtrc,
 		Endpoint:    apiURL,
 		APIKey:      b.env.BillStatAPIKey,
+		ChunkSize:   int(b.conf.Backend.BillStatChunkSize),
+		MaxRetries:  uint(b.conf.Backend.BillStatMaxRetries),
 	})
 }
 
 // initProfileDB initializes the profile database if necessary.
 //
-// [builder.initGRPCMetrics] must be called before this method.  It also adds
-// the refresher with ID [debugIDProfileDB] to the debug refreshers.
+// [builder.initAuditLog] and [builder.initGRPCMetrics] must be called before
+// this method.  It also adds the refresher with ID [debugIDProfileDB] to the
+// debug refreshers.
 func (b *builder) initProfileDB(ctx context.Context) (err error) {
 	if !b.profilesEnabled {
 		b.profileDB = &profiledb.Disabled{}
@@ -991,16 +1139,27 @@ func (b *builder) initProfileDB(ctx context.Context) (err error) {
 		return fmt.Errorf("registering profile database metrics: %w", err)
 	}
 
+	cacheKeys, err := b.profilesCacheKeys()
+	if err != nil {
+		return fmt.Errorf("profile cache keys: %w", err)
+	}
+
 	c := b.conf.Backend
 	timeout := c.Timeout.Duration
 	profDB, err := profiledb.New(&profiledb.Config{
 		Logger:               b.baseLogger.With(slogutil.KeyPrefix, "profiledb"),
+		AuditLog:             b.auditLog,
 		Storage:              strg,
 		ErrColl:              b.errColl,
 		Metrics:              profDBMtrc,
 		CacheFilePath:        b.env.ProfilesCachePath,
+		CacheKeys:            cacheKeys,
 		FullSyncIvl:          c.FullRefreshIvl.Duration,
 		FullSyncRetryIvl:     c.FullRefreshRetryIvl.Duration,
+		FullSyncPageSize:     c.FullRefreshPageSize,
+		GCRetention:          c.ProfilesGCRetention.Duration,
+		HumanIDCacheCount:    c.HumanIDCacheSize,
+		HumanIDNegativeTTL:   c.HumanIDNegativeTTL.Duration,
 		ResponseSizeEstimate: respSzEst,
 	})
 	if err != nil {
@@ -1027,6 +1186,9 @@ func (b *builder) initProfileDB(ctx context.Context) (err error) {
 		return fmt.Errorf("starting default profile database refresher: %w", err)
 	}
 
+	// Add the database before its refresher, so that it is shut down after the
+	// refresher is.
+	b.sigHdlr.Add(profDB)
 	b.sigHdlr.Add(refr)
 
 	b.debugRefrs[debugIDProfileDB] = profDB
@@ -1064,254 +1226,1133 @@ func (b *builder) initDNSCheck(ctx context.Context) (err error) {
 	}
 
 	b.dnsCheck = dnscheck.NewRemoteKV(checkConf)
+	b.checkKV = checkConf.RemoteKV
 
 	b.logger.DebugContext(ctx, "initialized dnscheck")
 
 	return nil
 }
 
-// initRuleStat initializes the rule statistics.  It also adds the refresher
-// with ID [debugIDRuleStat] to the debug refreshers.
-func (b *builder) initRuleStat(ctx context.Context) (err error) {
-	u := b.env.RuleStatURL
-	if u == nil {
-		b.logger.WarnContext(ctx, "not collecting rule statistics")
+// keyTicketSyncPrefix is the prefix of the key, under which the hash of the
+// TLS session ticket keys is stored in the DNS check remote KV.
+const keyTicketSyncPrefix = "tls_session_tickets:"
 
-		b.ruleStat = rulestat.Empty{}
+// initTicketSync initializes the detection of the divergence of the TLS
+// session ticket keys between the nodes.  It also adds the refresher with ID
+// [debugIDTicketSync] to the debug refreshers.
+//
+// The following methods must be called before this one:
+//   - [builder.initDNSCheck]
+//   - [builder.initTicketRotator]
+func (b *builder) initTicketSync(ctx context.Context) (err error) {
+	if b.checkKV == nil {
+		b.logger.DebugContext(ctx, "ticket sync disabled: no remote kv")
 
 		return nil
 	}
 
-	ruleStat := rulestat.NewHTTP(&rulestat.HTTPConfig{
-		Logger:  b.baseLogger.With(slogutil.KeyPrefix, "rulestat"),
-		ErrColl: b.errColl,
-		URL:     &u.URL,
+	tickSync := tlsconfig.NewTicketSync(&tlsconfig.TicketSyncConfig{
+		Logger:  b.baseLogger.With(slogutil.KeyPrefix, "ticket_sync"),
+		Clock:   agdtime.SystemClock{},
+		Manager: b.tlsManager,
+		Metrics: b.tlsMtrc,
+		KV:      b.checkKV,
+		Key:     keyTicketSyncPrefix + b.conf.Check.NodeLocation,
 	})
 
-	b.ruleStat = ruleStat
 	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
 		Context:   ctxWithDefaultTimeout,
-		Refresher: ruleStat,
-		Logger:    b.baseLogger.With(slogutil.KeyPrefix, "rulestat_refresh"),
-		// TODO(a.garipov):  Make configurable.
-		Interval:          10 * time.Minute,
-		RefreshOnShutdown: true,
-		RandomizeStart:    false,
+		Refresher: tickSync,
+		Logger:    b.baseLogger.With(slogutil.KeyPrefix, "ticket_sync_refresh"),
+		// TODO:  Make configurable.
+		Interval:          1 * time.Minute,
+		RefreshOnShutdown: false,
+		RandomizeStart:    true,
 	})
 	err = refr.Start(ctx)
 	if err != nil {
-		return fmt.Errorf("starting rulestat refresher: %w", err)
+		return fmt.Errorf("starting ticket sync refresh: %w", err)
 	}
 
 	b.sigHdlr.Add(refr)
 
-	b.debugRefrs[debugIDRuleStat] = ruleStat
+	b.debugRefrs[debugIDTicketSync] = tickSync
 
-	b.logger.DebugContext(ctx, "initialized rulestat")
+	b.logger.DebugContext(ctx, "initialized ticket sync")
 
 	return nil
 }
 
-// initRateLimiter initializes the rate limiter and the connection limiter as
-// well as starts and registers the rate-limiter refresher in the signal
-// handler.  It also adds the refresher with ID [debugIDAllowlist] to the debug
-// refreshers.
-//
-// [builder.initGRPCMetrics] must be called before this method.
-func (b *builder) initRateLimiter(ctx context.Context) (err error) {
-	c := b.conf.RateLimit
-	allowSubnets := netutil.UnembedPrefixes(c.Allowlist.List)
-	allowlist := ratelimit.NewDynamicAllowlist(allowSubnets, nil)
+// initDeviceStat initializes the per-device statistics.
+func (b *builder) initDeviceStat(ctx context.Context) (err error) {
+	c := b.conf.Web
+	if !b.profilesEnabled || c == nil || c.DeviceStats == nil || !c.DeviceStats.Enabled {
+		b.deviceStat = devicestat.Empty{}
 
-	typ := b.conf.RateLimit.Allowlist.Type
-	mtrc, err := metrics.NewAllowlist(b.mtrcNamespace, b.promRegisterer, typ)
-	if err != nil {
-		return fmt.Errorf("ratelimit metrics: %w", err)
+		return nil
 	}
 
-	var updater agdservice.Refresher
-	if typ == rlAllowlistTypeBackend {
-		updater, err = backendpb.NewRateLimiter(&backendpb.RateLimiterConfig{
-			Logger:      b.baseLogger.With(slogutil.KeyPrefix, "backend_ratelimiter"),
-			Metrics:     mtrc,
-			GRPCMetrics: b.backendGRPCMtrc,
-			Allowlist:   allowlist,
-			Endpoint:    &b.env.BackendRateLimitURL.URL,
-			ErrColl:     b.errColl,
-			APIKey:      b.env.BackendRateLimitAPIKey,
-		})
-		if err != nil {
-			return fmt.Errorf("ratelimit: %w", err)
-		}
-	} else {
-		updater = consul.NewAllowlistUpdater(&consul.AllowlistUpdaterConfig{
-			Logger:    b.baseLogger.With(slogutil.KeyPrefix, "ratelimit_allowlist_updater"),
-			Allowlist: allowlist,
-			ConsulURL: &b.env.ConsulAllowlistURL.URL,
-			ErrColl:   b.errColl,
-			Metrics:   mtrc,
-			// TODO(a.garipov):  Make configurable.
-			Timeout: 15 * time.Second,
-		})
+	b.deviceStat = devicestat.NewRuntime(&devicestat.RuntimeConfig{
+		Clock:      agdtime.SystemClock{},
+		MaxDevices: c.DeviceStats.MaxDevices,
+	})
+
+	b.logger.DebugContext(ctx, "initialized device stats")
+
+	return nil
+}
+
+// profilesCacheKeys returns the source of the keys for the encryption of the
+// profile cache.  The keys from the plugins take precedence over the ones from
+// the environment.  keys is nil if the cache should not be encrypted.
+func (b *builder) profilesCacheKeys() (keys cachecrypt.KeySource, err error) {
+	keys = b.plugins.ProfilesCacheKeys()
+	if keys != nil {
+		return keys, nil
 	}
 
-	err = updater.Refresh(ctx)
+	if b.env.ProfilesCacheKeys == "" {
+		return nil, nil
+	}
+
+	staticKeys, err := cachecrypt.ParseKeys(b.env.ProfilesCacheKeys)
 	if err != nil {
-		return fmt.Errorf("allowlist: initial refresh: %w", err)
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	return staticKeys, nil
+}
+
+// initTopProfiles initializes the statistics of the profiles with the most
+// requests, errors, and blocked requests.
+func (b *builder) initTopProfiles(ctx context.Context) (err error) {
+	c := b.conf.TopProfiles
+	if !b.profilesEnabled || c == nil || !c.Enabled {
+		b.topProfiles = topprofiles.Empty{}
+
+		return nil
 	}
 
+	stats := topprofiles.NewDefault(&topprofiles.DefaultConfig{
+		Clock: agdtime.SystemClock{},
+		Size:  c.Size,
+	})
+
 	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
 		Context:           ctxWithDefaultTimeout,
-		Refresher:         updater,
-		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "ratelimit_allowlist_refresh"),
-		Interval:          c.Allowlist.RefreshIvl.Duration,
+		Refresher:         stats,
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "topprofiles_refresh"),
+		Interval:          c.Window.Duration,
 		RefreshOnShutdown: false,
 		RandomizeStart:    false,
 	})
 	err = refr.Start(ctx)
 	if err != nil {
-		return fmt.Errorf("starting allowlist refresher: %w", err)
+		return fmt.Errorf("starting top profiles refresher: %w", err)
 	}
 
 	b.sigHdlr.Add(refr)
 
-	b.connLimit = c.ConnectionLimit.toInternal(b.baseLogger)
-	b.rateLimit = ratelimit.NewBackoff(c.toInternal(allowlist))
+	b.topProfiles = stats
 
-	b.debugRefrs[debugIDAllowlist] = updater
+	b.logger.DebugContext(ctx, "initialized top profiles")
 
-	b.logger.DebugContext(ctx, "initialized ratelimit")
+	return nil
+}
+
+// initRequestLog initializes the structured per-request debug log.
+func (b *builder) initRequestLog(ctx context.Context) {
+	c := b.conf.RequestLog
+	if c == nil || !c.Enabled {
+		b.reqLog = reqlog.Empty{}
+
+		return
+	}
+
+	b.reqLog = reqlog.NewDefault(&reqlog.DefaultConfig{
+		Logger:     b.baseLogger.With(slogutil.KeyPrefix, "reqlog"),
+		Clock:      agdtime.SystemClock{},
+		SampleRate: c.SampleRate,
+	})
+
+	b.logger.DebugContext(ctx, "initialized request log", "sample_rate", c.SampleRate)
+}
+
+// initQuarantine initializes the quarantine of the compromised devices.
+func (b *builder) initQuarantine(ctx context.Context) (err error) {
+	mtrc, err := metrics.NewQuarantine(b.mtrcNamespace, b.promRegisterer)
+	if err != nil {
+		return fmt.Errorf("registering quarantine metrics: %w", err)
+	}
+
+	b.quarantine = b.conf.Quarantine.toInternal(mtrc)
+
+	b.logger.DebugContext(ctx, "initialized quarantine")
 
 	return nil
 }
 
-// initWeb initializes the web service, starts it, and registers it in the
-// signal handler.  [builder.initDNSCheck] must be call before this method.
-func (b *builder) initWeb(ctx context.Context) (err error) {
-	c := b.conf.Web
-	webConf, err := c.toInternal(ctx, b.env, b.dnsCheck, b.errColl, b.tlsManager)
+// initUnblock initializes the storage of the temporary exceptions and the
+// configuration of the temporary-unblocking API.
+func (b *builder) initUnblock(ctx context.Context) (err error) {
+	b.unblockStrg, b.unblockWebConf, err = b.conf.Unblock.toInternal()
 	if err != nil {
-		return fmt.Errorf("converting web configuration: %w", err)
+		return fmt.Errorf("unblock: %w", err)
 	}
 
-	b.webSvc = websvc.New(webConf)
+	b.logger.DebugContext(ctx, "initialized unblock")
 
-	err = b.webSvc.Refresh(ctx)
+	return nil
+}
+
+// initDHCPLeases initializes the source of DHCP leases used to identify the
+// devices sending plain-DNS queries.
+func (b *builder) initDHCPLeases(ctx context.Context) (err error) {
+	c := b.conf.DHCPLeases
+	if !b.profilesEnabled || c == nil || !c.Enabled {
+		b.dhcpLeases = dhcplease.Empty{}
+
+		return nil
+	}
+
+	leases := dhcplease.NewFile(&dhcplease.FileConfig{
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "dhcplease"),
+		Clock:             agdtime.SystemClock{},
+		ErrColl:           b.errColl,
+		DeviceIDs:         c.deviceIDs(),
+		Path:              c.Path,
+		Format:            c.Format,
+		HostnameDeviceIDs: c.HostnameDeviceIDs,
+	})
+
+	err = leases.Refresh(ctx)
 	if err != nil {
-		return fmt.Errorf("web: initial refresh: %w", err)
+		return fmt.Errorf("initial dhcp leases refresh: %w", err)
 	}
 
 	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
-		Context:   ctxWithDefaultTimeout,
-		Refresher: b.webSvc,
-		Logger:    b.baseLogger.With(slogutil.KeyPrefix, "websvc_refresh"),
-		// TODO(a.garipov): Consider making configurable.
-		Interval:          5 * time.Minute,
+		Context:           ctxWithDefaultTimeout,
+		Refresher:         leases,
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "dhcplease_refresh"),
+		Interval:          c.RefreshInterval.Duration,
 		RefreshOnShutdown: false,
 		RandomizeStart:    false,
 	})
 	err = refr.Start(ctx)
 	if err != nil {
-		return fmt.Errorf("starting websvc refresher: %w", err)
+		return fmt.Errorf("starting dhcp leases refresher: %w", err)
 	}
 
 	b.sigHdlr.Add(refr)
 
-	// The web service is considered critical, so its Start method panics
-	// instead of returning an error.
-	_ = b.webSvc.Start(ctx)
-
-	b.sigHdlr.Add(b.webSvc)
-
-	b.debugRefrs[debugIDWebSvc] = b.webSvc
+	b.dhcpLeases = leases
 
-	b.logger.DebugContext(ctx, "initialized web")
+	b.logger.DebugContext(ctx, "initialized dhcp leases")
 
 	return nil
 }
 
-// waitGeoIP waits for the GeoIP initialization and registers its refresher.  It
-// also adds the refresher with ID [debugIDGeoIP] to the debug refreshers.
-func (b *builder) waitGeoIP(ctx context.Context) (err error) {
-	err = <-b.geoIPError
+// initWhiteLabel initializes the dynamic settings of the server groups, such as
+// the device domains and the DDR targets, if the backend URL for them is set.
+// It also adds the refresher with ID [debugIDServerGroups] to the debug
+// refreshers.
+//
+// [builder.initGRPCMetrics] must be called before this method.
+func (b *builder) initWhiteLabel(ctx context.Context) (err error) {
+	if b.env.ServerGroupsURL == nil {
+		b.whiteLabel = whitelabel.Empty{}
+
+		return nil
+	}
+
+	settings := whitelabel.NewDynamic()
+	updater, err := backendpb.NewServerGroupUpdater(&backendpb.ServerGroupUpdaterConfig{
+		Logger:      b.baseLogger.With(slogutil.KeyPrefix, "backend_server_groups"),
+		GRPCMetrics: b.backendGRPCMtrc,
+		Settings:    settings,
+		ErrColl:     b.errColl,
+		Endpoint:    &b.env.ServerGroupsURL.URL,
+		APIKey:      b.env.ServerGroupsAPIKey,
+	})
 	if err != nil {
-		return fmt.Errorf("geoip: %w", err)
+		return fmt.Errorf("server groups updater: %w", err)
+	}
+
+	err = updater.Refresh(ctx)
+	if err != nil {
+		return fmt.Errorf("server groups: initial refresh: %w", err)
 	}
 
-	const prefix = "geoip_refresh"
-	refrLogger := b.baseLogger.With(slogutil.KeyPrefix, prefix)
 	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
-		Context: ctxWithDefaultTimeout,
-		// Do not add errColl to geoip's config, as that would create an import
-		// cycle.
-		//
-		// TODO(a.garipov):  Resolve that.
-		Refresher: agdservice.NewRefresherWithErrColl(
-			b.geoIP,
-			refrLogger,
-			b.errColl,
-			prefix,
-		),
-		Logger:            refrLogger,
-		Interval:          b.conf.GeoIP.RefreshIvl.Duration,
+		Context:           ctxWithDefaultTimeout,
+		Refresher:         updater,
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "server_groups_refresh"),
+		Interval:          b.conf.Backend.RefreshIvl.Duration,
 		RefreshOnShutdown: false,
 		RandomizeStart:    false,
 	})
 	err = refr.Start(ctx)
 	if err != nil {
-		return fmt.Errorf("starting geoip refresher: %w", err)
+		return fmt.Errorf("starting server groups refresher: %w", err)
 	}
 
 	b.sigHdlr.Add(refr)
 
-	b.debugRefrs[debugIDGeoIP] = b.geoIP
+	b.whiteLabel = settings
+	b.debugRefrs[debugIDServerGroups] = updater
+
+	b.logger.DebugContext(ctx, "initialized white-label server groups")
 
 	return nil
 }
 
-// initDNS initializes the DNS service.
+// standardAccessScheduleIvl is the interval between the checks of the validity
+// windows of the standard access rules.
+const standardAccessScheduleIvl = 10 * time.Second
+
+// initPopularDomains initializes the differentially private statistics of the
+// most popular domains.
 //
-// The following methods must be called before this one:
-//   - [builder.initAccess]
-//   - [builder.initBillStat]
-//   - [builder.initBindToDevice]
-//   - [builder.initFilterStorage]
-//   - [builder.initFilteringGroups]
-//   - [builder.initMsgConstructor]
-//   - [builder.initProfileDB]
-//   - [builder.initRateLimiter]
-//   - [builder.initRuleStat]
-//   - [builder.initWeb]
-//   - [builder.waitGeoIP]
-func (b *builder) initDNS(ctx context.Context) (err error) {
-	b.fwdHandler = forward.NewHandler(b.conf.Upstream.toInternal(b.baseLogger))
-	b.dnsDB = b.conf.DNSDB.toInternal(b.baseLogger, b.errColl)
+// [builder.initGRPCMetrics] must be called before this method.
+func (b *builder) initPopularDomains(ctx context.Context) (err error) {
+	c := b.conf.PopularDomains
+	if c == nil || !c.Enabled {
+		b.popDomains = popdomains.Empty{}
 
-	dnsHdlrsConf := &dnssvc.HandlersConfig{
-		BaseLogger:           b.baseLogger,
-		Cache:                b.conf.Cache.toInternal(),
-		Cloner:               b.cloner,
-		HumanIDParser:        agd.NewHumanIDParser(),
-		Messages:             b.messages,
-		PluginRegistry:       b.plugins,
-		StructuredErrors:     b.sdeConf,
-		AccessManager:        b.access,
-		BillStat:             b.billStat,
-		CacheManager:         b.cacheManager,
-		DNSCheck:             b.dnsCheck,
-		DNSDB:                b.dnsDB,
-		ErrColl:              b.errColl,
-		FilterStorage:        b.filterStorage,
-		GeoIP:                b.geoIP,
-		Handler:              b.fwdHandler,
+		return nil
+	}
+
+	uploader, err := backendpb.NewPopularDomainsUploader(&backendpb.PopularDomainsUploaderConfig{
+		GRPCMetrics: b.backendGRPCMtrc,
+		Endpoint:    &b.env.PopularDomainsURL.URL,
+		APIKey:      b.env.PopularDomainsAPIKey,
+	})
+	if err != nil {
+		return fmt.Errorf("popular domains uploader: %w", err)
+	}
+
+	stats, err := popdomains.NewDefault(&popdomains.DefaultConfig{
+		Logger:           b.baseLogger.With(slogutil.KeyPrefix, "popdomains"),
+		Clock:            agdtime.SystemClock{},
+		ErrColl:          b.errColl,
+		Uploader:         uploader,
+		Epsilon:          c.Epsilon,
+		MaxContributions: c.MaxContributions,
+		Threshold:        c.Threshold,
+		MaxASNs:          c.MaxASNs,
+		MaxDomains:       c.MaxDomains,
+		TopSize:          c.TopSize,
+	})
+	if err != nil {
+		return fmt.Errorf("initializing popular domains: %w", err)
+	}
+
+	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context:           ctxWithDefaultTimeout,
+		Refresher:         stats,
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "popdomains_refresh"),
+		Interval:          c.Interval.Duration,
+		RefreshOnShutdown: true,
+		RandomizeStart:    false,
+	})
+	err = refr.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("starting popular domains refresher: %w", err)
+	}
+
+	b.sigHdlr.Add(refr)
+
+	b.popDomains = stats
+
+	b.logger.DebugContext(ctx, "initialized popular domains")
+
+	return nil
+}
+
+// initStandardAccess initializes the updater of the standard access rules from
+// the backend, if the backend URL for them is set.  It also adds the refresher
+// with ID [debugIDStandardAccess] to the debug refreshers.
+//
+// [builder.initAccess] and [builder.initGRPCMetrics] must be called before this
+// method.
+func (b *builder) initStandardAccess(ctx context.Context) (err error) {
+	if b.env.StandardAccessURL == nil {
+		return nil
+	}
+
+	updater, err := backendpb.NewStandardAccessUpdater(&backendpb.StandardAccessUpdaterConfig{
+		Logger:      b.baseLogger.With(slogutil.KeyPrefix, "backend_standard_access"),
+		GRPCMetrics: b.backendGRPCMtrc,
+		Blocker:     b.standardAccess,
+		ErrColl:     b.errColl,
+		Endpoint:    &b.env.StandardAccessURL.URL,
+		APIKey:      b.env.StandardAccessAPIKey,
+	})
+	if err != nil {
+		return fmt.Errorf("standard access updater: %w", err)
+	}
+
+	err = updater.Refresh(ctx)
+	if err != nil {
+		return fmt.Errorf("standard access: initial refresh: %w", err)
+	}
+
+	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context:           ctxWithDefaultTimeout,
+		Refresher:         updater,
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "standard_access_refresh"),
+		Interval:          b.conf.Backend.RefreshIvl.Duration,
+		RefreshOnShutdown: false,
+		RandomizeStart:    false,
+	})
+	err = refr.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("starting standard access refresher: %w", err)
+	}
+
+	b.sigHdlr.Add(refr)
+
+	schedRefr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context:           ctxWithDefaultTimeout,
+		Refresher:         b.standardAccess,
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "standard_access_schedule"),
+		Interval:          standardAccessScheduleIvl,
+		RefreshOnShutdown: false,
+		RandomizeStart:    false,
+	})
+	err = schedRefr.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("starting standard access schedule refresher: %w", err)
+	}
+
+	b.sigHdlr.Add(schedRefr)
+
+	b.debugRefrs[debugIDStandardAccess] = updater
+
+	b.logger.DebugContext(ctx, "initialized standard access updater")
+
+	return nil
+}
+
+// initDNSSigner initializes the optional signer of the synthesized responses.
+// It also adds the refresher of the keys with ID [debugIDDNSSign] to the debug
+// refreshers.
+func (b *builder) initDNSSigner(ctx context.Context) (err error) {
+	c := b.conf.DNSSigning
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	signer := dnssign.New(&dnssign.Config{
+		Logger:   b.baseLogger.With(slogutil.KeyPrefix, "dnssign"),
+		Clock:    agdtime.SystemClock{},
+		ErrColl:  b.errColl,
+		KSK:      c.KSK.toInternal(),
+		ZSK:      c.ZSK.toInternal(),
+		Zone:     c.Zone,
+		Validity: c.SignatureValidity.Duration,
+	})
+
+	err = signer.Refresh(ctx)
+	if err != nil {
+		return fmt.Errorf("initial dnssec keys refresh: %w", err)
+	}
+
+	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context:           ctxWithDefaultTimeout,
+		Refresher:         signer,
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "dnssign_refresh"),
+		Interval:          c.RefreshInterval.Duration,
+		RefreshOnShutdown: false,
+		RandomizeStart:    false,
+	})
+	err = refr.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("starting dnssec keys refresher: %w", err)
+	}
+
+	b.sigHdlr.Add(refr)
+
+	b.dnsSigner = signer
+	b.debugRefrs[debugIDDNSSign] = signer
+
+	b.logger.DebugContext(ctx, "initialized dnssec signer")
+
+	return nil
+}
+
+// initStaticZones initializes the optional static answers loaded from the
+// zone files.  It also adds the refresher with ID [debugIDStaticZones] to the
+// debug refreshers.
+func (b *builder) initStaticZones(ctx context.Context) (err error) {
+	c := b.conf.StaticZones
+	if c == nil || !c.Enabled {
+		b.staticZones = staticzone.Empty{}
+
+		return nil
+	}
+
+	mtrc, err := metrics.NewStaticZone(b.mtrcNamespace, b.promRegisterer)
+	if err != nil {
+		return fmt.Errorf("registering static zone metrics: %w", err)
+	}
+
+	zones := staticzone.NewFile(&staticzone.FileConfig{
+		Logger:        b.baseLogger.With(slogutil.KeyPrefix, "staticzone"),
+		ErrColl:       b.errColl,
+		Metrics:       mtrc,
+		Paths:         c.Files,
+		RequireZONEMD: c.RequireZONEMD,
+	})
+
+	err = zones.Refresh(ctx)
+	if err != nil {
+		return fmt.Errorf("initial static zones refresh: %w", err)
+	}
+
+	b.staticZones = zones
+	b.debugRefrs[debugIDStaticZones] = zones
+
+	b.logger.DebugContext(ctx, "initialized static zones")
+
+	return nil
+}
+
+// initNodeRole initializes the optional coordination of the roles of the
+// paired nodes.  In the Redis mode, it also adds the refresher of the lease with
+// ID [debugIDNodeRole] to the debug refreshers.
+//
+// The following methods must be called before this one:
+//   - [builder.initBindToDevice]
+func (b *builder) initNodeRole(ctx context.Context) (err error) {
+	c := b.conf.NodeRole
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	// Don't check the error, since the configuration has been validated.
+	initRole, _ := noderole.ParseRole(c.InitialRole)
+
+	conf := &noderole.Config{
+		Logger:      b.baseLogger.With(slogutil.KeyPrefix, "noderole"),
+		ErrColl:     b.errColl,
+		InitialRole: initRole,
+	}
+
+	if b.btdManager != nil {
+		conf.Setters = append(conf.Setters, b.btdManager)
+	}
+
+	if !c.usesRedis() {
+		b.nodeRole = noderole.New(conf)
+
+		b.logger.DebugContext(ctx, "initialized static node role", "role", initRole)
+
+		return nil
+	}
+
+	conf.KV = rediskv.NewRedisKV(&rediskv.RedisKVConfig{
+		Metrics: rediskv.EmptyMetrics{},
+		Addr: &netutil.HostPort{
+			Host: b.env.RedisAddr,
+			Port: b.env.RedisPort,
+		},
+		MaxActive:   b.env.RedisMaxActive,
+		MaxIdle:     b.env.RedisMaxIdle,
+		IdleTimeout: b.env.RedisIdleTimeout.Duration,
+		TTL:         c.LeaseTTL.Duration,
+	})
+	conf.LeaseKey = fmt.Sprintf("%s:noderole:%s", b.env.RedisKeyPrefix, c.PairName)
+	conf.NodeName = b.conf.Check.NodeName
+
+	m := noderole.New(conf)
+
+	// Don't return the error, since it has already been collected, the initial
+	// role is kept, and the refresher retries soon.
+	_ = m.Refresh(ctx)
+
+	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context:           ctxWithDefaultTimeout,
+		Refresher:         m,
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "noderole_refresh"),
+		Interval:          c.RefreshInterval.Duration,
+		RefreshOnShutdown: false,
+		RandomizeStart:    false,
+	})
+	err = refr.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("starting node role refresher: %w", err)
+	}
+
+	b.sigHdlr.Add(refr)
+
+	b.nodeRole = m
+	b.debugRefrs[debugIDNodeRole] = m
+
+	b.logger.DebugContext(ctx, "initialized node role", "role", m.Role())
+
+	return nil
+}
+
+// initRuleStat initializes the rule statistics.  It also adds the refresher
+// with ID [debugIDRuleStat] to the debug refreshers.
+func (b *builder) initRuleStat(ctx context.Context) (err error) {
+	u := b.env.RuleStatURL
+	if u == nil {
+		b.logger.WarnContext(ctx, "not collecting rule statistics")
+
+		b.ruleStat = rulestat.Empty{}
+
+		return nil
+	}
+
+	ruleStat := rulestat.NewHTTP(&rulestat.HTTPConfig{
+		Logger:    b.baseLogger.With(slogutil.KeyPrefix, "rulestat"),
+		ErrColl:   b.errColl,
+		URL:       &u.URL,
+		SpoolPath: b.env.RuleStatSpoolPath,
+		// TODO:  Make configurable.  Currently, it's a day's worth
+		// of windows with the default interval.
+		MaxPendingWindows: 144,
+	})
+
+	b.ruleStat = ruleStat
+	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context:   ctxWithDefaultTimeout,
+		Refresher: ruleStat,
+		Logger:    b.baseLogger.With(slogutil.KeyPrefix, "rulestat_refresh"),
+		// TODO(a.garipov):  Make configurable.
+		Interval:          10 * time.Minute,
+		RefreshOnShutdown: true,
+		RandomizeStart:    false,
+	})
+	err = refr.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("starting rulestat refresher: %w", err)
+	}
+
+	b.sigHdlr.Add(refr)
+
+	b.debugRefrs[debugIDRuleStat] = ruleStat
+
+	b.logger.DebugContext(ctx, "initialized rulestat")
+
+	return nil
+}
+
+// initRateLimiter initializes the rate limiter and the connection limiter as
+// well as starts and registers the rate-limiter refresher in the signal
+// handler.  It also adds the refresher with ID [debugIDAllowlist] to the debug
+// refreshers.
+//
+// [builder.initGRPCMetrics] must be called before this method.
+func (b *builder) initRateLimiter(ctx context.Context) (err error) {
+	c := b.conf.RateLimit
+	allowSubnets := netutil.UnembedPrefixes(c.Allowlist.List)
+	allowlist := ratelimit.NewDynamicAllowlist(allowSubnets, nil)
+
+	typ := b.conf.RateLimit.Allowlist.Type
+	mtrc, err := metrics.NewAllowlist(b.mtrcNamespace, b.promRegisterer, typ)
+	if err != nil {
+		return fmt.Errorf("ratelimit metrics: %w", err)
+	}
+
+	var updater agdservice.Refresher
+	if typ == rlAllowlistTypeBackend {
+		updater, err = backendpb.NewRateLimiter(&backendpb.RateLimiterConfig{
+			Logger:      b.baseLogger.With(slogutil.KeyPrefix, "backend_ratelimiter"),
+			Metrics:     mtrc,
+			GRPCMetrics: b.backendGRPCMtrc,
+			Allowlist:   allowlist,
+			Endpoint:    &b.env.BackendRateLimitURL.URL,
+			ErrColl:     b.errColl,
+			APIKey:      b.env.BackendRateLimitAPIKey,
+		})
+		if err != nil {
+			return fmt.Errorf("ratelimit: %w", err)
+		}
+	} else {
+		updater = consul.NewAllowlistUpdater(&consul.AllowlistUpdaterConfig{
+			Logger:    b.baseLogger.With(slogutil.KeyPrefix, "ratelimit_allowlist_updater"),
+			Allowlist: allowlist,
+			ConsulURL: &b.env.ConsulAllowlistURL.URL,
+			ErrColl:   b.errColl,
+			Metrics:   mtrc,
+			// TODO(a.garipov):  Make configurable.
+			Timeout: 15 * time.Second,
+		})
+	}
+
+	err = updater.Refresh(ctx)
+	if err != nil {
+		return fmt.Errorf("allowlist: initial refresh: %w", err)
+	}
+
+	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context:           ctxWithDefaultTimeout,
+		Refresher:         updater,
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "ratelimit_allowlist_refresh"),
+		Interval:          c.Allowlist.RefreshIvl.Duration,
+		RefreshOnShutdown: false,
+		RandomizeStart:    false,
+	})
+	err = refr.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("starting allowlist refresher: %w", err)
+	}
+
+	b.sigHdlr.Add(refr)
+
+	pf, err := b.newXDPFilter(ctx)
+	if err != nil {
+		return fmt.Errorf("xdp filter: %w", err)
+	}
+
+	backoffMtrc, err := metrics.NewDefaultRatelimitBackoff(b.mtrcNamespace, b.promRegisterer)
+	if err != nil {
+		return fmt.Errorf("backoff metrics: %w", err)
+	}
+
+	b.connLimit = c.ConnectionLimit.toInternal(b.baseLogger)
+	b.rateLimit = ratelimit.NewBackoff(c.toInternal(allowlist, pf, backoffMtrc))
+	b.snapshots.Add(snapshotIDRateLimit, b.rateLimit)
+
+	b.debugRefrs[debugIDAllowlist] = updater
+
+	err = b.initSharedCounter(ctx)
+	if err != nil {
+		return fmt.Errorf("shared counter: %w", err)
+	}
+
+	err = b.initAccountLimiter(ctx)
+	if err != nil {
+		return fmt.Errorf("account limiter: %w", err)
+	}
+
+	b.logger.DebugContext(ctx, "initialized ratelimit")
+
+	return nil
+}
+
+// newXDPFilter returns a new XDP filter and starts the refresher of its metrics.
+// pf is nil if the filter is disabled.
+func (b *builder) newXDPFilter(ctx context.Context) (pf netext.PacketFilter, err error) {
+	c := b.conf.RateLimit.XDPFilter
+	if c == nil || !c.Enabled {
+		return nil, nil
+	}
+
+	f, err := xdpfilter.New(&xdpfilter.Config{
+		Logger:    b.baseLogger.With(slogutil.KeyPrefix, "xdpfilter"),
+		ErrColl:   b.errColl,
+		BansPath:  c.BansMapPath,
+		DropsPath: c.DropsMapPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating: %w", err)
+	}
+
+	err = f.Refresh(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("initial refresh: %w", err)
+	}
+
+	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context:           ctxWithDefaultTimeout,
+		Refresher:         f,
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "xdpfilter_refresh"),
+		Interval:          c.RefreshIvl.Duration,
+		RefreshOnShutdown: false,
+		RandomizeStart:    false,
+	})
+	err = refr.Start(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("starting refresher: %w", err)
+	}
+
+	b.sigHdlr.Add(refr)
+
+	b.debugRefrs[debugIDXDPFilter] = f
+
+	return f, nil
+}
+
+// sharedCounterTTL is the expiration time of the keys of the shared counters.
+// Since the counters count requests per second, the keys aren't needed after
+// a few seconds.
+const sharedCounterTTL = 10 * time.Second
+
+// initSharedCounter initializes the shared counters of the profiles' requests
+// as well as starts and registers their refresher in the signal handler.
+func (b *builder) initSharedCounter(ctx context.Context) (err error) {
+	c := b.conf.RateLimit.SharedCounter
+	if !c.isEnabled() {
+		b.sharedCounter = sharedcounter.Empty{}
+
+		return nil
+	}
+
+	mtrc, err := metrics.NewSharedCounterRedisKV(b.mtrcNamespace, b.promRegisterer)
+	if err != nil {
+		return fmt.Errorf("registering redis kv metrics: %w", err)
+	}
+
+	kv := rediskv.NewRedisKV(&rediskv.RedisKVConfig{
+		Metrics: mtrc,
+		Addr: &netutil.HostPort{
+			Host: b.env.RedisAddr,
+			Port: b.env.RedisPort,
+		},
+		MaxActive:   b.env.RedisMaxActive,
+		MaxIdle:     b.env.RedisMaxIdle,
+		IdleTimeout: b.env.RedisIdleTimeout.Duration,
+		TTL:         sharedCounterTTL,
+	})
+
+	sc := sharedcounter.NewDefault(&sharedcounter.DefaultConfig{
+		Logger:    b.baseLogger.With(slogutil.KeyPrefix, "sharedcounter"),
+		Clock:     agdtime.SystemClock{},
+		ErrColl:   b.errColl,
+		Storage:   kv,
+		KeyPrefix: fmt.Sprintf("%s:ratelimit:", b.env.RedisKeyPrefix),
+	})
+
+	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context:           ctxWithDefaultTimeout,
+		Refresher:         sc,
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "sharedcounter_refresh"),
+		Interval:          c.RefreshIvl.Duration,
+		RefreshOnShutdown: false,
+		RandomizeStart:    false,
+	})
+	err = refr.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("starting refresher: %w", err)
+	}
+
+	b.sigHdlr.Add(refr)
+
+	b.sharedCounter = sc
+
+	return nil
+}
+
+// initAccountLimiter initializes the enforcement of the aggregate limits of
+// accounts as well as starts and registers its refresher in the signal handler.
+//
+// [builder.initSharedCounter] must be called before this one.
+func (b *builder) initAccountLimiter(ctx context.Context) (err error) {
+	c := b.conf.RateLimit.AccountLimit
+	if !c.isEnabled() {
+		b.accountLimiter = accountlimit.Empty{}
+
+		return nil
+	}
+
+	mtrc, err := metrics.NewAccountLimit(b.mtrcNamespace, b.promRegisterer)
+	if err != nil {
+		return fmt.Errorf("registering metrics: %w", err)
+	}
+
+	var sc sharedcounter.Interface = sharedcounter.Empty{}
+	if c.Shared {
+		sc = b.sharedCounter
+	}
+
+	l := accountlimit.NewDefault(&accountlimit.DefaultConfig{
+		Metrics:       mtrc,
+		SharedCounter: sc,
+		Clock:         agdtime.SystemClock{},
+		DeviceTTL:     c.DeviceTTL.Duration,
+	})
+
+	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context:           ctxWithDefaultTimeout,
+		Refresher:         l,
+		Logger:            b.baseLogger.With(slogutil.KeyPrefix, "accountlimit_refresh"),
+		Interval:          c.RefreshIvl.Duration,
+		RefreshOnShutdown: false,
+		RandomizeStart:    false,
+	})
+	err = refr.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("starting refresher: %w", err)
+	}
+
+	b.sigHdlr.Add(refr)
+
+	b.accountLimiter = l
+
+	return nil
+}
+
+// initQNAMELimiters initializes the limits of the unique names queried by the
+// clients of the server groups that have them enabled as well as starts and
+// registers their refreshers in the signal handler.
+func (b *builder) initQNAMELimiters(ctx context.Context) (err error) {
+	b.qnameLimiters = map[agd.ServerGroupName]qnamelimit.Interface{}
+
+	var mtrc *metrics.QNAMELimit
+	for _, g := range b.conf.ServerGroups {
+		c := g.QNAMELimit
+		if c == nil || !c.Enabled {
+			continue
+		}
+
+		if mtrc == nil {
+			mtrc, err = metrics.NewQNAMELimit(b.mtrcNamespace, b.promRegisterer)
+			if err != nil {
+				return fmt.Errorf("registering metrics: %w", err)
+			}
+		}
+
+		logger := b.baseLogger.With(slogutil.KeyPrefix, "qnamelimit", "server_group", g.Name)
+		l := qnamelimit.NewDefault(c.toInternal(logger, mtrc.ForServerGroup(g.Name)))
+
+		refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+			Context:           ctxWithDefaultTimeout,
+			Refresher:         l,
+			Logger:            logger.With(slogutil.KeyPrefix, "qnamelimit_refresh"),
+			Interval:          c.Window.Duration,
+			RefreshOnShutdown: false,
+			RandomizeStart:    false,
+		})
+		err = refr.Start(ctx)
+		if err != nil {
+			return fmt.Errorf("starting refresher for server group %q: %w", g.Name, err)
+		}
+
+		b.sigHdlr.Add(refr)
+
+		b.qnameLimiters[agd.ServerGroupName(g.Name)] = l
+	}
+
+	b.logger.DebugContext(ctx, "initialized qname limiters", "num", len(b.qnameLimiters))
+
+	return nil
+}
+
+// initWeb initializes the web service, starts it, and registers it in the
+// signal handler.
+//
+// The following methods must be called before this one:
+//   - [builder.initDeviceStat]
+//   - [builder.initDNSCheck]
+//   - [builder.initProfileDB]
+//   - [builder.initUnblock]
+func (b *builder) initWeb(ctx context.Context) (err error) {
+	c := b.conf.Web
+	webConf, err := c.toInternal(
+		ctx,
+		b.env,
+		b.dnsCheck,
+		b.deviceStat,
+		b.errColl,
+		b.profileDB,
+		b.tlsManager,
+		b.unblockWebConf,
+	)
+	if err != nil {
+		return fmt.Errorf("converting web configuration: %w", err)
+	}
+
+	b.webSvc = websvc.New(webConf)
+
+	err = b.webSvc.Refresh(ctx)
+	if err != nil {
+		return fmt.Errorf("web: initial refresh: %w", err)
+	}
+
+	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context:   ctxWithDefaultTimeout,
+		Refresher: b.webSvc,
+		Logger:    b.baseLogger.With(slogutil.KeyPrefix, "websvc_refresh"),
+		// TODO(a.garipov): Consider making configurable.
+		Interval:          5 * time.Minute,
+		RefreshOnShutdown: false,
+		RandomizeStart:    false,
+	})
+	err = refr.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("starting websvc refresher: %w", err)
+	}
+
+	b.sigHdlr.Add(refr)
+
+	// The web service is considered critical, so its Start method panics
+	// instead of returning an error.
+	_ = b.webSvc.Start(ctx)
+
+	b.sigHdlr.Add(b.webSvc)
+
+	b.debugRefrs[debugIDWebSvc] = b.webSvc
+
+	b.logger.DebugContext(ctx, "initialized web")
+
+	return nil
+}
+
+// waitGeoIP waits for the GeoIP initialization and registers its refresher.  It
+// also adds the refresher with ID [debugIDGeoIP] to the debug refreshers.
+func (b *builder) waitGeoIP(ctx context.Context) (err error) {
+	err = <-b.geoIPError
+	if err != nil {
+		return fmt.Errorf("geoip: %w", err)
+	}
+
+	const prefix = "geoip_refresh"
+	refrLogger := b.baseLogger.With(slogutil.KeyPrefix, prefix)
+	refr := agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context: ctxWithDefaultTimeout,
+		// Do not add errColl to geoip's config, as that would create an import
+		// cycle.
+		//
+		// TODO(a.garipov):  Resolve that.
+		Refresher: agdservice.NewRefresherWithErrColl(
+			b.geoIP,
+			refrLogger,
+			b.errColl,
+			prefix,
+		),
+		Logger:            refrLogger,
+		Interval:          b.conf.GeoIP.RefreshIvl.Duration,
+		RefreshOnShutdown: false,
+		RandomizeStart:    false,
+	})
+	err = refr.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("starting geoip refresher: %w", err)
+	}
+
+	b.sigHdlr.Add(refr)
+
+	b.debugRefrs[debugIDGeoIP] = b.geoIP
+
+	return nil
+}
+
+// newClientKeyHasher returns a new client-key hasher using the salt from the
+// environment.  If there is no salt, a random one is used, so the keys are only
+// stable within the lifetime of the process.
+func (b *builder) newClientKeyHasher(ctx context.Context) (h *agd.ClientKeyHasher, err error) {
+	salt := []byte(b.env.ClientKeySalt)
+	if len(salt) == 0 {
+		b.logger.WarnContext(ctx, "no client key salt; using random salt")
+
+		salt = make([]byte, 32)
+		_, err = rand.Read(salt)
+		if err != nil {
+			return nil, fmt.Errorf("generating client key salt: %w", err)
+		}
+	}
+
+	return agd.NewClientKeyHasher(salt), nil
+}
+
+// initDNS initializes the DNS service.
+//
+// The following methods must be called before this one:
+//   - [builder.initAccess]
+//   - [builder.initBillStat]
+//   - [builder.initBindToDevice]
+//   - [builder.initDHCPLeases]
+//   - [builder.initDNSSigner]
+//   - [builder.initDeviceStat]
+//   - [builder.initDnstap]
+//   - [builder.initFilterStorage]
+//   - [builder.initFilteringGroups]
+//   - [builder.initMsgConstructor]
+//   - [builder.initPoisonPill]
+//   - [builder.initPopularDomains]
+//   - [builder.initProfileDB]
+//   - [builder.initQNAMELimiters]
+//   - [builder.initQUICAdvisor]
+//   - [builder.initQuarantine]
+//   - [builder.initRateLimiter]
+//   - [builder.initRequestLog]
+//   - [builder.initRuleStat]
+//   - [builder.initStaticZones]
+//   - [builder.initTopProfiles]
+//   - [builder.initUnblock]
+//   - [builder.initWeb]
+//   - [builder.waitGeoIP]
+func (b *builder) initDNS(ctx context.Context) (err error) {
+	fwdConf := b.conf.Upstream.toInternal(b.baseLogger)
+	if _, ok := b.reqLog.(*reqlog.Default); ok {
+		fwdConf.MetricsListener = reqlog.NewForwardMetricsListener(fwdConf.MetricsListener)
+	}
+
+	b.fwdHandler = forward.NewHandler(fwdConf)
+	b.viewFwdHandlers = newViewForwardHandlers(b.conf.ServerGroups, fwdConf)
+
+	shadowConf, shadowHdlr := b.conf.Shadow.toInternal(b.baseLogger)
+	if shadowHdlr != nil {
+		// Register the closer before the DNS service so that the handler is
+		// closed after the service is shut down.
+		b.sigHdlr.Add(shadowCloser{handler: shadowHdlr})
+	}
+
+	redactor := b.conf.QueryLog.Redaction.toInternal()
+	b.dnsDB = b.conf.DNSDB.toInternal(b.baseLogger, b.errColl, redactor)
+
+	clientKeys, err := b.newClientKeyHasher(ctx)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	dnsHdlrsConf := &dnssvc.HandlersConfig{
+		BaseLogger:           b.baseLogger,
+		Cache:                b.conf.Cache.toInternal(),
+		Cloner:               b.cloner,
+		ClientKeys:           clientKeys,
+		HumanIDParser:        agd.NewHumanIDParser(),
+		Messages:             b.messages,
+		PluginRegistry:       b.plugins,
+		StructuredErrors:     b.sdeConf,
+		AccessManager:        b.access,
+		AccountLimiter:       b.accountLimiter,
+		BillStat:             b.billStat,
+		CacheManager:         b.cacheManager,
+		Classifier:           b.filterStorage,
+		DeviceStat:           b.deviceStat,
+		DNSCheck:             b.dnsCheck,
+		DNSDB:                b.dnsDB,
+		ErrColl:              b.errColl,
+		Exceptions:           b.unblockStrg,
+		FilterStorage:        b.filterStorage,
+		GeoIP:                b.geoIP,
+		Handler:              b.fwdHandler,
+		ViewHandlers:         b.viewHandlers(),
 		HashMatcher:          b.hashMatcher,
 		ProfileDB:            b.profileDB,
+		PopularDomains:       b.popDomains,
 		PrometheusRegisterer: b.promRegisterer,
-		QueryLog:             b.queryLog(),
+		QNAMELimiters:        b.qnameLimiters,
+		Quarantine:           b.quarantine,
+		QueryLog:             b.queryLog(redactor),
 		RateLimit:            b.rateLimit,
+		RequestLog:           b.reqLog,
 		RuleStat:             b.ruleStat,
+		SharedCounter:        b.sharedCounter,
+		SnapshotManager:      b.snapshots,
+		StaticZones:          b.staticZones,
+		TopProfiles:          b.topProfiles,
+		Maintenance:          b.maintenance,
+		LeaseSource:          b.dhcpLeases,
+		WhiteLabel:           b.whiteLabel,
+		QUICAdvisor:          b.quicAdvisor,
 		MetricsNamespace:     b.mtrcNamespace,
 		FilteringGroups:      b.filteringGroups,
+		Chaos:                b.conf.Chaos.toInternal(b.conf.Check.NodeName),
+		Leak:                 b.conf.SpecialUse.toInternal(),
+		Signer:               b.dnsSigner,
+		PTRZones:             b.conf.PTR.toInternal(),
+		Shadow:               shadowConf,
+		MaxCNAMEChainLen:     b.conf.DNS.MaxCNAMEChainLen,
+		Dnstap:               b.dnstap,
+		PoisonPill:           b.poisonPill,
 		ServerGroups:         b.serverGroups,
 		EDEEnabled:           b.conf.Filters.EDEEnabled,
 	}
@@ -1328,6 +2369,7 @@ func (b *builder) initDNS(ctx context.Context) (err error) {
 		ConnLimiter:      b.connLimit,
 		NonDNS:           b.webSvc,
 		ErrColl:          b.errColl,
+		QUICAdvisor:      b.quicAdvisor,
 		MetricsNamespace: b.mtrcNamespace,
 		ServerGroups:     b.serverGroups,
 		HandleTimeout:    b.conf.DNS.HandleTimeout.Duration,
@@ -1343,20 +2385,30 @@ func (b *builder) initDNS(ctx context.Context) (err error) {
 	return nil
 }
 
+// viewHandlers returns the forwarding handlers of the views as DNS handlers.
+func (b *builder) viewHandlers() (handlers map[agd.ViewName]dnsserver.Handler) {
+	if len(b.viewFwdHandlers) == 0 {
+		return nil
+	}
+
+	handlers = make(map[agd.ViewName]dnsserver.Handler, len(b.viewFwdHandlers))
+	for name, h := range b.viewFwdHandlers {
+		handlers[name] = h
+	}
+
+	return handlers
+}
+
 // queryLog returns the appropriate query log implementation from the
-// configuration and environment data.
-func (b *builder) queryLog() (l querylog.Interface) {
-	fileNeeded := b.conf.QueryLog.File.Enabled
-	if !fileNeeded {
+// configuration and environment data.  r is used to redact the domain names,
+// if not nil.
+func (b *builder) queryLog(r *qnameredact.Redactor) (l querylog.Interface) {
+	c := b.conf.QueryLog.File
+	if !c.Enabled {
 		return querylog.Empty{}
 	}
 
-	return querylog.NewFileSystem(&querylog.FileSystemConfig{
-		Logger: b.baseLogger.With(slogutil.KeyPrefix, "querylog"),
-		Path:   b.env.QueryLogPath,
-		// #nosec G115 -- The Unix epoch time is highly unlikely to be negative.
-		RandSeed: uint64(time.Now().UnixNano()),
-	})
+	return c.toInternal(b.baseLogger, b.env.QueryLogPath, r)
 }
 
 // performConnCheck performs the connectivity check in accordance to the
@@ -1387,11 +2439,66 @@ func (b *builder) initHealthCheck(ctx context.Context) (err error) {
 
 	b.sigHdlr.Add(upd)
 
+	for name, h := range b.viewFwdHandlers {
+		upd = newUpstreamHealthcheck(b.baseLogger, h, b.conf.Upstream, b.errColl)
+		err = upd.Start(ctx)
+		if err != nil {
+			return fmt.Errorf("initializing healthcheck for view %q: %w", name, err)
+		}
+
+		b.sigHdlr.Add(upd)
+	}
+
 	b.logger.DebugContext(ctx, "initialized healthcheck")
 
 	return nil
 }
 
+// initUpstreamDiscovery initializes and registers the upstream discovery
+// worker.  The discovery only affects the main forwarding handler and not the
+// ones of the custom views.
+//
+// [builder.initDNS] must be called before this method.
+func (b *builder) initUpstreamDiscovery(ctx context.Context) (err error) {
+	upd, err := newUpstreamDiscovery(b.baseLogger, b.fwdHandler, b.conf.Upstream, b.errColl)
+	if err != nil {
+		return fmt.Errorf("initializing upstream discovery: %w", err)
+	}
+
+	err = upd.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("starting upstream discovery: %w", err)
+	}
+
+	b.sigHdlr.Add(upd)
+
+	b.logger.DebugContext(ctx, "initialized upstream discovery")
+
+	return nil
+}
+
+// restoreSnapshot restores the runtime state of the entities from the snapshot,
+// if the snapshots are enabled, and registers the snapshot manager in the
+// signal handler so that the state is saved on shutdown.
+//
+// The following methods must be called before this one:
+//   - [builder.initDNS]
+//   - [builder.initRateLimiter]
+func (b *builder) restoreSnapshot(ctx context.Context) {
+	m, ok := b.snapshots.(*snapshot.DefaultManager)
+	if !ok {
+		return
+	}
+
+	m.Restore(ctx)
+
+	// Add the manager before the DNS service, so that the state is saved after
+	// the DNS service is shut down.
+	b.sigHdlr.Add(m)
+
+	b.logger.DebugContext(ctx, "restored snapshot")
+}
+
 // mustStartDNS starts the DNS service and registers it in the signal handler.
 // The DNS service is considered critical, so it panics instead of returning an
 // error.
@@ -1412,19 +2519,54 @@ func (b *builder) mustStartDNS(ctx context.Context) {
 // an error.
 //
 // The following methods must be called before this one:
+//   - [builder.initAuditLog]
 //   - [builder.initBillStat]
 //   - [builder.initDNS]
 //   - [builder.initFilterStorage]
 //   - [builder.initGeoIP]
 //   - [builder.initHashPrefixFilters]
 //   - [builder.initProfileDB]
+//   - [builder.initQuarantine]
 //   - [builder.initRateLimiter]
+//   - [builder.initRequestLog]
 //   - [builder.initRuleStat]
+//   - [builder.initServerGroups]
+//   - [builder.initTLSManager]
+//   - [builder.initTopProfiles]
 //   - [builder.initWeb]
 func (b *builder) mustInitDebugSvc(ctx context.Context) {
 	debugSvcConf := b.env.debugConf(b.dnsDB, b.baseLogger)
 	debugSvcConf.Manager = b.cacheManager
 	debugSvcConf.Refreshers = b.debugRefrs
+	debugSvcConf.TLSManager = b.tlsManager
+	debugSvcConf.Maintenance = b.maintenance
+	debugSvcConf.NodeRole = b.nodeRole
+	debugSvcConf.FilterStatuses = b.filterStatuses
+	debugSvcConf.Quarantine = b.quarantine
+	debugSvcConf.Clock = agdtime.SystemClock{}
+	debugSvcConf.FilterStaleThreshold = b.conf.Filters.StaleThreshold.Duration
+	if b.profilesEnabled {
+		debugSvcConf.ProfileDB = b.profileDB
+	}
+
+	if stats, ok := b.topProfiles.(*topprofiles.Default); ok {
+		debugSvcConf.TopProfiles = stats
+	}
+
+	if l, ok := b.reqLog.(*reqlog.Default); ok {
+		debugSvcConf.RequestLog = l
+	}
+
+	if l, ok := b.auditLog.(*auditlog.File); ok {
+		debugSvcConf.AuditLog = l
+	}
+
+	if b.conf.Upstream.hasGroups() {
+		debugSvcConf.Forward = b.fwdHandler
+	}
+
+	debugSvcConf.PoisonPill = b.poisonPill
+
 	debugSvc := debugsvc.New(debugSvcConf)
 
 	// The debug HTTP service is considered critical, so its Start method panics
@@ -26,6 +26,21 @@ type cacheConfig struct {
 	// ECSSize is the size of the DNS cache for domain names that support ECS,
 	// in entries.
 	ECSSize int `yaml:"ecs_size"`
+
+	// FilteredSize is the size of the DNS cache for domain names that don't
+	// support ECS for the requests that have been blocked or modified, in
+	// entries.
+	FilteredSize int `yaml:"filtered_size"`
+
+	// FilteredECSSize is the size of the DNS cache for domain names that
+	// support ECS for the requests that have been blocked or modified, in
+	// entries.
+	FilteredECSSize int `yaml:"filtered_ecs_size"`
+
+	// NSECSize is the number of zones the NSEC and NSEC3 records of which are
+	// cached for the server groups with aggressive NSEC caching enabled, in
+	// entries.
+	NSECSize int `yaml:"nsec_size"`
 }
 
 // ttlOverride represents TTL override configuration.
@@ -58,11 +73,14 @@ func (c *cacheConfig) toInternal() (cacheConf *dnssvc.CacheConfig) {
 	}
 
 	return &dnssvc.CacheConfig{
-		MinTTL:           c.TTLOverride.Min.Duration,
-		ECSCount:         c.ECSSize,
-		NoECSCount:       c.Size,
-		Type:             typ,
-		OverrideCacheTTL: c.TTLOverride.Enabled,
+		MinTTL:             c.TTLOverride.Min.Duration,
+		ECSCount:           c.ECSSize,
+		NoECSCount:         c.Size,
+		FilteredECSCount:   c.FilteredECSSize,
+		FilteredNoECSCount: c.FilteredSize,
+		NSECCount:          c.NSECSize,
+		Type:               typ,
+		OverrideCacheTTL:   c.TTLOverride.Enabled,
 	}
 }
 
@@ -85,6 +103,12 @@ func (c *cacheConfig) validate() (err error) {
 		return newNegativeError("size", c.Size)
 	case c.Type == cacheTypeECS && c.ECSSize < 0:
 		return newNegativeError("ecs_size", c.ECSSize)
+	case c.Type == cacheTypeECS && c.FilteredSize < 0:
+		return newNegativeError("filtered_size", c.FilteredSize)
+	case c.Type == cacheTypeECS && c.FilteredECSSize < 0:
+		return newNegativeError("filtered_ecs_size", c.FilteredECSSize)
+	case c.NSECSize < 0:
+		return newNegativeError("nsec_size", c.NSECSize)
 	default:
 		// Go on.
 	}
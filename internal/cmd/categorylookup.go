@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/hashprefix"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/netutil"
+)
+
+// categoryLookupConfig is the configuration of the TXT lookups of the content
+// categories of domain names by the prefixes of their hashes.
+type categoryLookupConfig struct {
+	// RPS is the number of lookups per second allowed for a single client
+	// network.
+	RPS int `yaml:"rps"`
+
+	// Burst is the number of lookups a single client network can make at once.
+	Burst int `yaml:"burst"`
+
+	// CacheSize is the maximum number of client networks the rate limiters of
+	// which are kept.
+	CacheSize int `yaml:"cache_size"`
+
+	// MaxPrefixes is the maximum number of hash prefixes in a single lookup.
+	MaxPrefixes int `yaml:"max_prefixes"`
+
+	// IPv4SubnetKeyLen is the length of the subnet prefix used to group the
+	// IPv4 clients.
+	IPv4SubnetKeyLen int `yaml:"ipv4_subnet_key_len"`
+
+	// IPv6SubnetKeyLen is the length of the subnet prefix used to group the
+	// IPv6 clients.
+	IPv6SubnetKeyLen int `yaml:"ipv6_subnet_key_len"`
+
+	// Enabled shows if the category lookups are enabled.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*categoryLookupConfig)(nil)
+
+// validate implements the [validator] interface for *categoryLookupConfig.  The
+// category-lookup configuration is optional.
+func (c *categoryLookupConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.RPS <= 0:
+		return newNotPositiveError("rps", c.RPS)
+	case c.Burst <= 0:
+		return newNotPositiveError("burst", c.Burst)
+	case c.CacheSize <= 0:
+		return newNotPositiveError("cache_size", c.CacheSize)
+	case c.MaxPrefixes <= 0:
+		return newNotPositiveError("max_prefixes", c.MaxPrefixes)
+	case c.IPv4SubnetKeyLen <= 0 || c.IPv4SubnetKeyLen > netutil.IPv4BitLen:
+		return fmt.Errorf("ipv4_subnet_key_len: %w", errors.ErrOutOfRange)
+	case c.IPv6SubnetKeyLen <= 0 || c.IPv6SubnetKeyLen > netutil.IPv6BitLen:
+		return fmt.Errorf("ipv6_subnet_key_len: %w", errors.ErrOutOfRange)
+	default:
+		return nil
+	}
+}
+
+// initCategoryLookup initializes the storage of the content categories and the
+// matcher of the category lookups, if they are enabled.
+//
+// It must be called from [builder.initHashPrefixFilters] after
+// [builder.hashMatcher] is set.
+func (b *builder) initCategoryLookup(ctx context.Context) (err error) {
+	c := b.conf.CategoryLookup
+	if c == nil || !c.Enabled {
+		b.logger.DebugContext(ctx, "category lookups disabled")
+
+		return nil
+	}
+
+	mtrc, err := metrics.NewCategoryLookup(b.mtrcNamespace, b.promRegisterer)
+	if err != nil {
+		return fmt.Errorf("registering category lookup metrics: %w", err)
+	}
+
+	b.categoryHashes = hashprefix.NewCategoryStorage()
+
+	catMatcher := hashprefix.NewCategoryMatcher(&hashprefix.CategoryMatcherConfig{
+		Storage:          b.categoryHashes,
+		Metrics:          mtrc,
+		Suffix:           filter.CategoryTXTSuffix,
+		RPS:              float64(c.RPS),
+		Burst:            c.Burst,
+		CacheSize:        c.CacheSize,
+		MaxPrefixes:      c.MaxPrefixes,
+		IPv4SubnetKeyLen: c.IPv4SubnetKeyLen,
+		IPv6SubnetKeyLen: c.IPv6SubnetKeyLen,
+	})
+
+	b.hashMatcher = hashprefix.MultiMatcher{b.hashMatcher, catMatcher}
+
+	b.logger.DebugContext(ctx, "initialized category lookups")
+
+	return nil
+}
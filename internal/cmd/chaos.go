@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc"
+	"github.com/AdguardTeam/AdGuardDNS/internal/version"
+	"github.com/AdguardTeam/golibs/netutil"
+)
+
+// chaosConfig is the configuration of the responses to the CHAOS-class TXT
+// diagnostic queries.
+type chaosConfig struct {
+	// DebugName is the optional secret domain name of the extended debug query.
+	DebugName string `yaml:"debug_name"`
+
+	// Enabled shows if the CHAOS-class diagnostic queries are answered.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*chaosConfig)(nil)
+
+// validate implements the [validator] interface for *chaosConfig.  The CHAOS
+// configuration is optional.
+func (c *chaosConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled, c.DebugName == "":
+		return nil
+	default:
+		err = netutil.ValidateDomainName(strings.TrimSuffix(c.DebugName, "."))
+		if err != nil {
+			return fmt.Errorf("debug_name: %w", err)
+		}
+
+		return nil
+	}
+}
+
+// toInternal returns the CHAOS configuration for the DNS service.  nodeName is
+// the name of this server node.  c must be valid.
+func (c *chaosConfig) toInternal(nodeName string) (conf *dnssvc.ChaosConfig) {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	return &dnssvc.ChaosConfig{
+		NodeName:  nodeName,
+		Version:   version.Version(),
+		DebugName: strings.ToLower(strings.TrimSuffix(c.DebugName, ".")),
+	}
+}
@@ -94,6 +94,8 @@ func Main(plugins *plugin.Registry) {
 
 	errors.Check(b.initMsgConstructor(ctx))
 
+	errors.Check(b.initAuditLog(ctx))
+
 	errors.Check(b.initTLSManager(ctx))
 
 	errors.Check(b.initServerGroups(ctx))
@@ -110,10 +112,44 @@ func Main(plugins *plugin.Registry) {
 
 	errors.Check(b.initDNSCheck(ctx))
 
+	errors.Check(b.initTicketSync(ctx))
+
 	errors.Check(b.initRuleStat(ctx))
 
 	errors.Check(b.initRateLimiter(ctx))
 
+	errors.Check(b.initDeviceStat(ctx))
+
+	errors.Check(b.initTopProfiles(ctx))
+
+	errors.Check(b.initPopularDomains(ctx))
+
+	errors.Check(b.initQNAMELimiters(ctx))
+
+	b.initRequestLog(ctx)
+
+	errors.Check(b.initQuarantine(ctx))
+
+	errors.Check(b.initUnblock(ctx))
+
+	errors.Check(b.initDHCPLeases(ctx))
+
+	errors.Check(b.initWhiteLabel(ctx))
+
+	errors.Check(b.initStandardAccess(ctx))
+
+	errors.Check(b.initDNSSigner(ctx))
+
+	errors.Check(b.initStaticZones(ctx))
+
+	errors.Check(b.initDnstap(ctx))
+
+	errors.Check(b.initQUICAdvisor(ctx))
+
+	errors.Check(b.initPoisonPill(ctx))
+
+	errors.Check(b.initNodeRole(ctx))
+
 	errors.Check(b.initWeb(ctx))
 
 	errors.Check(b.waitGeoIP(ctx))
@@ -123,6 +159,9 @@ func Main(plugins *plugin.Registry) {
 	errors.Check(b.performConnCheck(ctx))
 
 	errors.Check(b.initHealthCheck(ctx))
+	errors.Check(b.initUpstreamDiscovery(ctx))
+
+	b.restoreSnapshot(ctx)
 
 	b.mustStartDNS(ctx)
 
@@ -24,12 +24,35 @@ type configuration struct {
 	// Upstream is the configuration of upstream servers for the DNS servers.
 	Upstream *upstreamConfig `yaml:"upstream"`
 
+	// Shadow is the optional configuration of the request shadowing.
+	Shadow *shadowConfig `yaml:"shadow"`
+
 	// DNSDB is the configuration of DNSDB buffer.
 	DNSDB *dnsDBConfig `yaml:"dnsdb"`
 
 	// DNSDB is the configuration of common DNS settings.
 	DNS *dnsConfig `yaml:"dns"`
 
+	// PTR is the optional configuration of the synthesized PTR responses.
+	PTR *ptrConfig `yaml:"ptr"`
+
+	// Chaos is the optional configuration of the responses to the CHAOS-class
+	// diagnostic queries.
+	Chaos *chaosConfig `yaml:"chaos"`
+
+	// SpecialUse is the optional configuration of the handling of the queries
+	// for the special-use domain names, such as localhost., .local, and
+	// .onion.
+	SpecialUse *specialUseConfig `yaml:"special_use_domains"`
+
+	// StaticZones is the optional configuration of the static answers loaded
+	// from the operator-defined zone files.
+	StaticZones *staticZonesConfig `yaml:"static_zones"`
+
+	// DNSSigning is the optional configuration of the DNSSEC signing of the
+	// synthesized responses.
+	DNSSigning *dnsSigningConfig `yaml:"dnssec_signing"`
+
 	// Backend is the AdGuard HTTP backend service configuration.  See the
 	// environments type for more backend parameters.
 	Backend *backendConfig `yaml:"backend"`
@@ -38,6 +61,35 @@ type configuration struct {
 	// type for more query log parameters.
 	QueryLog *queryLogConfig `yaml:"query_log"`
 
+	// TopProfiles is the optional configuration of the statistics of the
+	// profiles with the most requests, errors, and blocked requests.
+	TopProfiles *topProfilesConfig `yaml:"top_profiles"`
+
+	// PopularDomains is the optional configuration of the differentially
+	// private statistics of the most popular domains.  See the environments
+	// type for more parameters.
+	PopularDomains *popularDomainsConfig `yaml:"popular_domains"`
+
+	// RequestLog is the optional configuration of the structured per-request
+	// debug log.
+	RequestLog *requestLogConfig `yaml:"request_log"`
+
+	// Quarantine is the optional configuration of the quarantine of the
+	// compromised devices.
+	Quarantine *quarantineConfig `yaml:"quarantine"`
+
+	// Unblock is the optional configuration of the temporary exceptions, which
+	// allow the blocked domain names after a confirmation on the block page.
+	Unblock *unblockConfig `yaml:"unblock"`
+
+	// DHCPLeases is the optional configuration of the identification of the
+	// devices by the leases of a local DHCP server.
+	DHCPLeases *dhcpLeasesConfig `yaml:"dhcp_leases"`
+
+	// AuditLog is the optional configuration of the append-only log of the
+	// events that change the state of AdGuard DNS.
+	AuditLog *auditLogConfig `yaml:"audit_log"`
+
 	// GeoIP is the additional GeoIP database configuration.  See the
 	// environments type for more GeoIP database parameters.
 	GeoIP *geoIPConfig `yaml:"geoip"`
@@ -54,6 +106,10 @@ type configuration struct {
 	// AdultBlocking is the AdGuard adult content blocking filter configuration.
 	AdultBlocking *safeBrowsingConfig `yaml:"adult_blocking"`
 
+	// CategoryLookup is the optional configuration of the TXT lookups of the
+	// content categories of domain names.
+	CategoryLookup *categoryLookupConfig `yaml:"category_lookup"`
+
 	// Filters contains the configuration for the filter lists and filtering
 	// storage to be used.  They are used by filtering groups below.
 	Filters *filtersConfig `yaml:"filters"`
@@ -68,6 +124,18 @@ type configuration struct {
 	// Network is the configuration for network listeners.
 	Network *network `yaml:"network"`
 
+	// NodeRole is the optional configuration of the coordination of the roles
+	// of the paired nodes.
+	NodeRole *nodeRoleConfig `yaml:"node_role"`
+
+	// QUICAdvisor is the optional configuration of the advisor that stops
+	// advertising the QUIC-based protocols to the networks blocking QUIC.
+	QUICAdvisor *quicAdvisorConfig `yaml:"quic_advisor"`
+
+	// PoisonPill is the optional configuration of the isolation of the panics
+	// caused by single queries.
+	PoisonPill *poisonPillConfig `yaml:"poison_pill"`
+
 	// Access is the configuration of the service managing access control.
 	Access *accessConfig `yaml:"access"`
 
@@ -91,13 +159,55 @@ func (c *configuration) validate() (err error) {
 		return errors.ErrNoValue
 	}
 
+	// TODO(a.garipov):  Use errors.Join everywhere.
+	for _, kv := range c.validators() {
+		err = kv.Value.validate()
+		if err != nil {
+			return fmt.Errorf("%s: %w", kv.Key, err)
+		}
+	}
+
+	return nil
+}
+
+// validateAggressiveNSEC returns an error if aggressive NSEC caching is enabled
+// for any server group while the upstream servers aren't trusted to validate
+// DNSSEC.  The records used to synthesize the negative responses are only
+// cached if the upstream has set the AD bit, and their signatures aren't
+// validated, so a spoofed response could otherwise suppress a whole range of
+// names.  Since it is also used by [Lint], which runs all validators, the
+// properties it checks may be invalid.
+func (c *configuration) validateAggressiveNSEC() (err error) {
+	if c.Upstream == nil || c.Upstream.TrustedDNSSEC {
+		return nil
+	}
+
+	for i, g := range c.ServerGroups {
+		if g != nil && g.AggressiveNSECEnabled {
+			return fmt.Errorf(
+				"at index %d: aggressive_nsec_enabled: upstream: trusted_dnssec must be true",
+				i,
+			)
+		}
+	}
+
+	return nil
+}
+
+// validators returns the validators of the top-level properties of the
+// configuration along with their names, including the ones that check several
+// properties at once.  c must not be nil.
+func (c *configuration) validators() (validators container.KeyValues[string, validator]) {
 	// Keep this in the same order as the fields in the config.
-	validators := container.KeyValues[string, validator]{{
+	return container.KeyValues[string, validator]{{
 		Key:   "ratelimit",
 		Value: c.RateLimit,
 	}, {
 		Key:   "upstream",
 		Value: c.Upstream,
+	}, {
+		Key:   "shadow",
+		Value: c.Shadow,
 	}, {
 		Key:   "cache",
 		Value: c.Cache,
@@ -107,12 +217,48 @@ func (c *configuration) validate() (err error) {
 	}, {
 		Key:   "dns",
 		Value: c.DNS,
+	}, {
+		Key:   "ptr",
+		Value: c.PTR,
+	}, {
+		Key:   "chaos",
+		Value: c.Chaos,
+	}, {
+		Key:   "special_use_domains",
+		Value: c.SpecialUse,
+	}, {
+		Key:   "static_zones",
+		Value: c.StaticZones,
+	}, {
+		Key:   "dnssec_signing",
+		Value: c.DNSSigning,
 	}, {
 		Key:   "backend",
 		Value: c.Backend,
 	}, {
 		Key:   "query_log",
 		Value: c.QueryLog,
+	}, {
+		Key:   "top_profiles",
+		Value: c.TopProfiles,
+	}, {
+		Key:   "popular_domains",
+		Value: c.PopularDomains,
+	}, {
+		Key:   "request_log",
+		Value: c.RequestLog,
+	}, {
+		Key:   "quarantine",
+		Value: c.Quarantine,
+	}, {
+		Key:   "unblock",
+		Value: c.Unblock,
+	}, {
+		Key:   "dhcp_leases",
+		Value: c.DHCPLeases,
+	}, {
+		Key:   "audit_log",
+		Value: c.AuditLog,
 	}, {
 		Key:   "geoip",
 		Value: c.GeoIP,
@@ -128,6 +274,9 @@ func (c *configuration) validate() (err error) {
 	}, {
 		Key:   "adult_blocking",
 		Value: c.AdultBlocking,
+	}, {
+		Key:   "category_lookup",
+		Value: c.CategoryLookup,
 	}, {
 		Key:   "filters",
 		Value: c.Filters,
@@ -146,23 +295,27 @@ func (c *configuration) validate() (err error) {
 	}, {
 		Key:   "network",
 		Value: c.Network,
+	}, {
+		Key:   "node_role",
+		Value: c.NodeRole,
+	}, {
+		Key:   "quic_advisor",
+		Value: c.QUICAdvisor,
+	}, {
+		Key:   "poison_pill",
+		Value: c.PoisonPill,
 	}, {
 		Key:   "access",
 		Value: c.Access,
 	}, {
 		Key:   "additional_metrics_info",
 		Value: c.AdditionalMetricsInfo,
+	}, {
+		// Keep the validators that check several properties at once after the
+		// validators of those properties.
+		Key:   "server_groups",
+		Value: validatorFunc(c.validateAggressiveNSEC),
 	}}
-
-	// TODO(a.garipov):  Use errors.Join everywhere.
-	for _, kv := range validators {
-		err = kv.Value.validate()
-		if err != nil {
-			return fmt.Errorf("%s: %w", kv.Key, err)
-		}
-	}
-
-	return nil
 }
 
 // isProfilesEnabled returns true if there is at least one server group with
@@ -16,6 +16,10 @@ type connCheckConfig struct {
 
 	// ProbeIPv6 is a probe v6 address to perform a check to.
 	ProbeIPv6 netip.AddrPort `yaml:"probe_ipv6"`
+
+	// IPv6Only, if true, means that the node has no IPv4 connectivity.  In
+	// that case, only the IPv6 check is performed.
+	IPv6Only bool `yaml:"ipv6_only"`
 }
 
 // type check
@@ -26,7 +30,9 @@ func (c *connCheckConfig) validate() (err error) {
 	switch {
 	case c == nil:
 		return errors.ErrNoValue
-	case c.ProbeIPv4 == netip.AddrPort{}:
+	case c.IPv6Only && c.ProbeIPv6 == netip.AddrPort{}:
+		return fmt.Errorf("probe_ipv6: %w", errors.ErrEmptyValue)
+	case !c.IPv6Only && c.ProbeIPv4 == netip.AddrPort{}:
 		return fmt.Errorf("probe_ipv4: %w", errors.ErrEmptyValue)
 	}
 
@@ -37,8 +43,12 @@ func (c *connCheckConfig) validate() (err error) {
 // provided dialer and probe addresses.  For each server group it reviews each
 // server bind addresses looking up for IPv6 addresses.  If an IPv6 address is
 // found, then additionally to a general probe to IPv4 it will perform a check
-// to IPv6 probe address.
+// to IPv6 probe address.  On IPv6-only nodes, only the IPv6 check is performed.
 func connectivityCheck(srvGrps []*agd.ServerGroup, connCheck *connCheckConfig) (err error) {
+	if connCheck.IPv6Only {
+		return connectivityCheckIPv6(connCheck)
+	}
+
 	probeIPv4 := net.TCPAddrFromAddrPort(connCheck.ProbeIPv4)
 
 	// General check to IPv4 probe address.
@@ -56,6 +66,12 @@ func connectivityCheck(srvGrps []*agd.ServerGroup, connCheck *connCheckConfig) (
 		return nil
 	}
 
+	return connectivityCheckIPv6(connCheck)
+}
+
+// connectivityCheckIPv6 performs the connectivity check to the IPv6 probe
+// address.
+func connectivityCheckIPv6(connCheck *connCheckConfig) (err error) {
 	if (connCheck.ProbeIPv6 == netip.AddrPort{}) {
 		return errors.Error("connectivity check: no ipv6 probe address in config")
 	}
@@ -3,16 +3,17 @@ package cmd
 import (
 	"cmp"
 	"fmt"
+	"maps"
 	"net/netip"
 	"slices"
 	"strings"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
 	"github.com/AdguardTeam/golibs/container"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/netutil"
-	"github.com/miekg/dns"
 )
 
 // ddrConfig is the configuration for a server group's DDR handler.
@@ -25,6 +26,14 @@ type ddrConfig struct {
 	// devices.  The keys of the map are the public domain names.
 	PublicRecords map[string]*ddrRecord `yaml:"public_records"`
 
+	// CountryPriorities are the priorities of the designated resolvers for the
+	// clients from the countries, which override the ones of the records.
+	CountryPriorities map[geoip.Country]*ddrPriorities `yaml:"country_priorities"`
+
+	// CountryRecords are the records used instead of DeviceRecords and
+	// PublicRecords for the clients from the countries.
+	CountryRecords map[geoip.Country]*ddrCountryRecords `yaml:"country_records"`
+
 	// Enabled shows if DDR queries are processed.  If it is false, DDR queries
 	// receive an NXDOMAIN response.
 	Enabled bool `yaml:"enabled"`
@@ -37,9 +46,27 @@ func (c *ddrConfig) toInternal(msgs *dnsmsg.Constructor) (conf *agd.DDR) {
 		Enabled: c.Enabled,
 	}
 
+	if len(c.CountryPriorities) > 0 {
+		conf.CountryPriorities = make(map[geoip.Country]agd.DDRPriorities, len(c.CountryPriorities))
+		for ctry, p := range c.CountryPriorities {
+			conf.CountryPriorities[ctry] = p.toInternal()
+		}
+	}
+
 	conf.DeviceTargets, conf.DeviceRecordTemplates = ddrRecsToSVCBTmpls(msgs, c.DeviceRecords)
 	conf.PublicTargets, conf.PublicRecordTemplates = ddrRecsToSVCBTmpls(msgs, c.PublicRecords)
 
+	if len(c.CountryRecords) > 0 {
+		conf.CountryRecordTemplates = make(
+			map[geoip.Country]*agd.DDRCountryRecordTemplates,
+			len(c.CountryRecords),
+		)
+
+		for ctry, r := range c.CountryRecords {
+			conf.CountryRecordTemplates[ctry] = r.toInternal(msgs, conf)
+		}
+	}
+
 	return conf
 }
 
@@ -48,7 +75,7 @@ func (c *ddrConfig) toInternal(msgs *dnsmsg.Constructor) (conf *agd.DDR) {
 func ddrRecsToSVCBTmpls(
 	msgs *dnsmsg.Constructor,
 	records map[string]*ddrRecord,
-) (targets *container.MapSet[string], tmpls []*dns.SVCB) {
+) (targets *container.MapSet[string], tmpls []*agd.DDRRecordTemplate) {
 	targets = container.NewMapSet[string]()
 	for target, r := range records {
 		target = strings.TrimPrefix(target, "*.")
@@ -56,8 +83,8 @@ func ddrRecsToSVCBTmpls(
 		tmpls = appendDDRSVCBTmpls(tmpls, msgs, r, target)
 	}
 
-	slices.SortStableFunc(tmpls, func(a, b *dns.SVCB) (res int) {
-		return cmp.Compare(a.Priority, b.Priority)
+	slices.SortStableFunc(tmpls, func(a, b *agd.DDRRecordTemplate) (res int) {
+		return cmp.Compare(a.Record.Priority, b.Record.Priority)
 	})
 
 	return targets, tmpls
@@ -66,11 +93,11 @@ func ddrRecsToSVCBTmpls(
 // appendDDRSVCBTmpls creates and appends new SVCB record templates to recs for
 // each protocol port that is not zero.
 func appendDDRSVCBTmpls(
-	recs []*dns.SVCB,
+	recs []*agd.DDRRecordTemplate,
 	msgs *dnsmsg.Constructor,
 	r *ddrRecord,
 	target string,
-) (result []*dns.SVCB) {
+) (result []*agd.DDRRecordTemplate) {
 	protoPorts := container.KeyValues[agd.Protocol, uint16]{{
 		Key:   agd.ProtoDoH,
 		Value: r.HTTPSPort,
@@ -82,17 +109,26 @@ func appendDDRSVCBTmpls(
 		Value: r.QUICPort,
 	}}
 
-	var prio uint16
+	prios := r.Priorities.toInternal()
+
+	var autoPrio uint16
 	for _, kv := range protoPorts {
-		port := kv.Value
+		proto, port := kv.Key, kv.Value
 		if port == 0 {
 			continue
 		}
 
-		prio++
+		autoPrio++
+		prio, ok := prios[proto]
+		if !ok {
+			prio = autoPrio
+		}
 
-		rec := msgs.NewDDRTemplate(kv.Key, target, r.DoHPath, r.IPv4Hints, r.IPv6Hints, port, prio)
-		recs = append(recs, rec)
+		rec := msgs.NewDDRTemplate(proto, target, r.DoHPath, r.IPv4Hints, r.IPv6Hints, port, prio)
+		recs = append(recs, &agd.DDRRecordTemplate{
+			Record: rec,
+			Proto:  proto,
+		})
 	}
 
 	return recs
@@ -107,26 +143,107 @@ func (c *ddrConfig) validate() (err error) {
 		return errors.ErrNoValue
 	}
 
-	for wildcard, r := range c.DeviceRecords {
+	err = validateDDRRecords(c.DeviceRecords, c.PublicRecords)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	for ctry, p := range c.CountryPriorities {
+		err = p.validate()
+		if err != nil {
+			return fmt.Errorf("country_priorities: country %q: %w", ctry, err)
+		}
+	}
+
+	for ctry, r := range c.CountryRecords {
+		err = r.validate()
+		if err != nil {
+			return fmt.Errorf("country_records: country %q: %w", ctry, err)
+		}
+	}
+
+	return nil
+}
+
+// validateDDRRecords returns an error if any of the device records, the keys of
+// which are device ID wildcards, or the public records, the keys of which are
+// public domain names, are invalid.  The error contains all invalid records.
+func validateDDRRecords(devRecs, pubRecs map[string]*ddrRecord) (err error) {
+	var errs []error
+	for _, wildcard := range slices.Sorted(maps.Keys(devRecs)) {
 		if !strings.HasPrefix(wildcard, "*.") {
-			return fmt.Errorf("device_records: record for wildcard %q: not a wildcard", wildcard)
+			err = fmt.Errorf("device_records: record for wildcard %q: not a wildcard", wildcard)
+			errs = append(errs, err)
+
+			continue
 		}
 
 		domainSuf := wildcard[2:]
-		err = errors.Join(netutil.ValidateHostname(domainSuf), r.validate())
+		err = errors.Join(validateWildcardSuffix(domainSuf), devRecs[wildcard].validate())
 		if err != nil {
-			return fmt.Errorf("device_records: wildcard %q: %w", wildcard, err)
+			errs = append(errs, fmt.Errorf("device_records: wildcard %q: %w", wildcard, err))
 		}
 	}
 
-	for domain, r := range c.PublicRecords {
-		err = errors.Join(netutil.ValidateHostname(domain), r.validate())
+	for _, domain := range slices.Sorted(maps.Keys(pubRecs)) {
+		err = errors.Join(netutil.ValidateHostname(domain), pubRecs[domain].validate())
 		if err != nil {
-			return fmt.Errorf("public_records: domain %q: %w", domain, err)
+			errs = append(errs, fmt.Errorf("public_records: domain %q: %w", domain, err))
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
+}
+
+// ddrCountryRecords are the DDR records for the clients from a country.
+type ddrCountryRecords struct {
+	// DeviceRecords are used to respond to DDR queries from recognized devices.
+	// The keys of the map are device ID wildcards.
+	DeviceRecords map[string]*ddrRecord `yaml:"device_records"`
+
+	// PublicRecords are used to respond to DDR queries from unrecognized
+	// devices.  The keys of the map are the public domain names.
+	PublicRecords map[string]*ddrRecord `yaml:"public_records"`
+}
+
+// toInternal returns the record templates for the clients from a country and
+// adds their targets to the targets of conf.  All arguments must not be nil.
+// r must be valid.
+func (r *ddrCountryRecords) toInternal(
+	msgs *dnsmsg.Constructor,
+	conf *agd.DDR,
+) (tmpls *agd.DDRCountryRecordTemplates) {
+	devTargets, devTmpls := ddrRecsToSVCBTmpls(msgs, r.DeviceRecords)
+	pubTargets, pubTmpls := ddrRecsToSVCBTmpls(msgs, r.PublicRecords)
+
+	for t := range devTargets.Range {
+		conf.DeviceTargets.Add(t)
+	}
+
+	for t := range pubTargets.Range {
+		conf.PublicTargets.Add(t)
+	}
+
+	return &agd.DDRCountryRecordTemplates{
+		DeviceRecordTemplates: devTmpls,
+		PublicRecordTemplates: pubTmpls,
+	}
+}
+
+// type check
+var _ validator = (*ddrCountryRecords)(nil)
+
+// validate implements the [validator] interface for *ddrCountryRecords.
+func (r *ddrCountryRecords) validate() (err error) {
+	switch {
+	case r == nil:
+		return errors.ErrNoValue
+	case len(r.DeviceRecords) == 0 && len(r.PublicRecords) == 0:
+		return errors.Error("device_records and public_records: both empty")
+	default:
+		return validateDDRRecords(r.DeviceRecords, r.PublicRecords)
+	}
 }
 
 // ddrRecord is a DDR record template for responses to DDR queries from both
@@ -138,6 +255,10 @@ type ddrRecord struct {
 	// IPv4Hints are the optional hints about the IPv4-addresses of the server.
 	IPv4Hints []netip.Addr `yaml:"ipv4_hints"`
 
+	// Priorities are the optional explicit priorities of the resolvers.  The
+	// resolvers without an explicit priority are ordered as DoH, DoT, and DoQ.
+	Priorities *ddrPriorities `yaml:"priorities"`
+
 	// IPv6Hints are the optional hints about the IPv6-addresses of the server.
 	IPv6Hints []netip.Addr `yaml:"ipv6_hints"`
 
@@ -185,6 +306,13 @@ func (r *ddrRecord) validate() (err error) {
 		}
 	}
 
+	if r.Priorities != nil {
+		err = r.Priorities.validate()
+		if err != nil {
+			return fmt.Errorf("priorities: %w", err)
+		}
+	}
+
 	return r.validatePorts()
 }
 
@@ -200,3 +328,52 @@ func (r *ddrRecord) validatePorts() (err error) {
 		return nil
 	}
 }
+
+// ddrPriorities are the SvcPriority values of the designated resolvers by their
+// protocols.  A zero value means that the priority isn't set.
+type ddrPriorities struct {
+	// HTTPS is the priority of the DoH resolver.
+	HTTPS uint16 `yaml:"https"`
+
+	// QUIC is the priority of the DoQ resolver.
+	QUIC uint16 `yaml:"quic"`
+
+	// TLS is the priority of the DoT resolver.
+	TLS uint16 `yaml:"tls"`
+}
+
+// toInternal converts p to the priorities of the designated resolvers.  If p
+// is nil, prios is nil.  p must be valid.
+func (p *ddrPriorities) toInternal() (prios agd.DDRPriorities) {
+	if p == nil {
+		return nil
+	}
+
+	prios = agd.DDRPriorities{}
+	for proto, prio := range map[agd.Protocol]uint16{
+		agd.ProtoDoH: p.HTTPS,
+		agd.ProtoDoQ: p.QUIC,
+		agd.ProtoDoT: p.TLS,
+	} {
+		if prio != 0 {
+			prios[proto] = prio
+		}
+	}
+
+	return prios
+}
+
+// type check
+var _ validator = (*ddrPriorities)(nil)
+
+// validate implements the [validator] interface for *ddrPriorities.
+func (p *ddrPriorities) validate() (err error) {
+	switch {
+	case p == nil:
+		return errors.ErrNoValue
+	case p.HTTPS == 0 && p.QUIC == 0 && p.TLS == 0:
+		return errors.Error("all priorities are zero")
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// dhcpLeasesConfig is the configuration of the identification of the devices
+// sending plain-DNS queries by the leases of a local DHCP server.
+type dhcpLeasesConfig struct {
+	// DeviceIDs maps the hardware addresses of the clients to the IDs of their
+	// devices.
+	DeviceIDs map[string]string `yaml:"device_ids"`
+
+	// Path is the path to the lease file of the DHCP server.
+	Path string `yaml:"path"`
+
+	// Format is the format of the lease file.
+	Format dhcplease.Format `yaml:"format"`
+
+	// RefreshInterval defines how often the lease file is reread.
+	RefreshInterval timeutil.Duration `yaml:"refresh_interval"`
+
+	// HostnameDeviceIDs shows if the hostnames of the leases that are valid
+	// device IDs are used as such.
+	HostnameDeviceIDs bool `yaml:"hostname_device_ids"`
+
+	// Enabled shows if the DHCP leases are used to identify the devices.
+	Enabled bool `yaml:"enabled"`
+}
+
+// deviceIDs returns the device IDs from c keyed by the normalized hardware
+// addresses.  c must be valid.
+func (c *dhcpLeasesConfig) deviceIDs() (ids map[string]agd.DeviceID) {
+	ids = make(map[string]agd.DeviceID, len(c.DeviceIDs))
+	for hwStr, idStr := range c.DeviceIDs {
+		// The values have already been validated in [dhcpLeasesConfig.validate].
+		hw, _ := net.ParseMAC(hwStr)
+		ids[hw.String()] = agd.DeviceID(idStr)
+	}
+
+	return ids
+}
+
+// type check
+var _ validator = (*dhcpLeasesConfig)(nil)
+
+// validate implements the [validator] interface for *dhcpLeasesConfig.  The
+// DHCP leases configuration is optional.
+func (c *dhcpLeasesConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.Path == "":
+		return fmt.Errorf("path: %w", errors.ErrEmptyValue)
+	}
+
+	err = c.Format.Validate()
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	err = validatePositive("refresh_interval", c.RefreshInterval)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	for hwStr, idStr := range c.DeviceIDs {
+		_, err = net.ParseMAC(hwStr)
+		if err != nil {
+			return fmt.Errorf("device_ids: %w", err)
+		}
+
+		_, err = agd.NewDeviceID(idStr)
+		if err != nil {
+			return fmt.Errorf("device_ids: %q: %w", hwStr, err)
+		}
+	}
+
+	return nil
+}
@@ -31,6 +31,11 @@ type dnsConfig struct {
 
 	// MaxUDPResponseSize is the maximum size of DNS response over UDP protocol.
 	MaxUDPResponseSize datasize.ByteSize `yaml:"max_udp_response_size"`
+
+	// MaxCNAMEChainLen is the maximum number of CNAME records in a chain in
+	// the upstream responses.  Longer chains and chains with loops are
+	// truncated.  If it is zero, the chains aren't checked.
+	MaxCNAMEChainLen int `yaml:"max_cname_chain_length"`
 }
 
 // type check
@@ -64,6 +69,8 @@ func (c *dnsConfig) validate() (err error) {
 			datasize.ByteSize(dns.MaxMsgSize),
 			c.MaxUDPResponseSize,
 		)
+	case c.MaxCNAMEChainLen < 0:
+		return newNegativeError("max_cname_chain_length", c.MaxCNAMEChainLen)
 	default:
 		return nil
 	}
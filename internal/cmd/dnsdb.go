@@ -5,6 +5,7 @@ import (
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsdb"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnameredact"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 )
@@ -40,15 +41,17 @@ func (c *dnsDBConfig) validate() (err error) {
 func (c *dnsDBConfig) toInternal(
 	baseLogger *slog.Logger,
 	errColl errcoll.Interface,
+	r *qnameredact.Redactor,
 ) (d dnsdb.Interface) {
 	if !c.Enabled {
 		return dnsdb.Empty{}
 	}
 
 	db := dnsdb.New(&dnsdb.DefaultConfig{
-		Logger:  baseLogger.With(slogutil.KeyPrefix, "dnsdb"),
-		ErrColl: errColl,
-		MaxSize: c.MaxSize,
+		Logger:   baseLogger.With(slogutil.KeyPrefix, "dnsdb"),
+		ErrColl:  errColl,
+		Redactor: r,
+		MaxSize:  c.MaxSize,
 	})
 
 	return db
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssign"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/netutil"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// dnsSigningConfig is the configuration of the on-node DNSSEC signing of the
+// synthesized responses.
+type dnsSigningConfig struct {
+	// KSK is the configuration of the key-signing key.
+	KSK *dnsSigningKeyConfig `yaml:"ksk"`
+
+	// ZSK is the configuration of the zone-signing key.
+	ZSK *dnsSigningKeyConfig `yaml:"zsk"`
+
+	// Zone is the zone controlled by the operator, within which the
+	// synthesized records are signed.
+	Zone string `yaml:"zone"`
+
+	// SignatureValidity is the validity period of the signatures.
+	SignatureValidity timeutil.Duration `yaml:"signature_validity"`
+
+	// RefreshInterval defines how often the key files are reread.
+	RefreshInterval timeutil.Duration `yaml:"refresh_interval"`
+
+	// Enabled shows if the synthesized responses are signed.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*dnsSigningConfig)(nil)
+
+// validate implements the [validator] interface for *dnsSigningConfig.  The
+// DNSSEC signing configuration is optional.
+func (c *dnsSigningConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.Zone == "":
+		return fmt.Errorf("zone: %w", errors.ErrEmptyValue)
+	}
+
+	err = netutil.ValidateDomainName(strings.TrimSuffix(c.Zone, "."))
+	if err != nil {
+		return fmt.Errorf("zone: %w", err)
+	}
+
+	err = validatePositive("signature_validity", c.SignatureValidity)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	err = validatePositive("refresh_interval", c.RefreshInterval)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	err = c.KSK.validate()
+	if err != nil {
+		return fmt.Errorf("ksk: %w", err)
+	}
+
+	err = c.ZSK.validate()
+	if err != nil {
+		return fmt.Errorf("zsk: %w", err)
+	}
+
+	return nil
+}
+
+// dnsSigningKeyConfig is the configuration of the files of a DNSSEC key.
+type dnsSigningKeyConfig struct {
+	// PublicKey is the path to the file with the DNSKEY record in the BIND
+	// format.
+	PublicKey string `yaml:"public_key"`
+
+	// PrivateKey is the path to the file with the private key in the BIND
+	// format.
+	PrivateKey string `yaml:"private_key"`
+}
+
+// type check
+var _ validator = (*dnsSigningKeyConfig)(nil)
+
+// validate implements the [validator] interface for *dnsSigningKeyConfig.
+func (c *dnsSigningKeyConfig) validate() (err error) {
+	switch {
+	case c == nil:
+		return errors.ErrNoValue
+	case c.PublicKey == "":
+		return fmt.Errorf("public_key: %w", errors.ErrEmptyValue)
+	case c.PrivateKey == "":
+		return fmt.Errorf("private_key: %w", errors.ErrEmptyValue)
+	default:
+		return nil
+	}
+}
+
+// toInternal returns the paths to the key files for the signer.  c must be
+// valid.
+func (c *dnsSigningKeyConfig) toInternal() (paths *dnssign.KeyPaths) {
+	return &dnssign.KeyPaths{
+		Public:  c.PublicKey,
+		Private: c.PrivateKey,
+	}
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnstap"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/AdGuardDNS/internal/version"
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/c2h5oh/datasize"
+)
+
+// Dnstap sink types.
+const (
+	dnstapSinkTypeFile   = "file"
+	dnstapSinkTypeSocket = "socket"
+)
+
+// dnstapConfig is the configuration of the dnstap output of a server group.
+type dnstapConfig struct {
+	// Type is the type of the sink, either [dnstapSinkTypeFile] or
+	// [dnstapSinkTypeSocket].
+	Type string `yaml:"type"`
+
+	// Path is the path to the file or the Unix socket.
+	Path string `yaml:"path"`
+
+	// Percentage is the percentage of the queries that are written.
+	Percentage float64 `yaml:"percentage"`
+
+	// QueueSize is the number of messages that can wait to be written.
+	QueueSize int `yaml:"queue_size"`
+
+	// MaxBackups is the number of the rotated files that are kept.  It is only
+	// used with [dnstapSinkTypeFile].
+	MaxBackups int `yaml:"max_backups"`
+
+	// MaxSize is the size of the file after which it is rotated.  If it is
+	// zero, the file is only rotated on start.  It is only used with
+	// [dnstapSinkTypeFile].
+	MaxSize datasize.ByteSize `yaml:"max_size"`
+
+	// Enabled shows if the dnstap output is enabled.
+	Enabled bool `yaml:"enabled"`
+}
+
+// sinkType returns the internal sink type.  c must be valid.
+func (c *dnstapConfig) sinkType() (t dnstap.SinkType) {
+	if c.Type == dnstapSinkTypeSocket {
+		return dnstap.SinkTypeSocket
+	}
+
+	return dnstap.SinkTypeFile
+}
+
+// type check
+var _ validator = (*dnstapConfig)(nil)
+
+// validate implements the [validator] interface for *dnstapConfig.  The dnstap
+// configuration is optional.
+func (c *dnstapConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.Type != dnstapSinkTypeFile && c.Type != dnstapSinkTypeSocket:
+		return fmt.Errorf("type: %w: %q", errors.ErrBadEnumValue, c.Type)
+	case c.Path == "":
+		return fmt.Errorf("path: %w", errors.ErrEmptyValue)
+	case c.Percentage <= 0 || c.Percentage > 100:
+		return fmt.Errorf(
+			"percentage: %w: must be greater than 0 and not greater than 100; got %v",
+			errors.ErrOutOfRange,
+			c.Percentage,
+		)
+	case c.QueueSize <= 0:
+		return newNotPositiveError("queue_size", c.QueueSize)
+	case c.Type == dnstapSinkTypeFile && c.MaxBackups <= 0:
+		return newNotPositiveError("max_backups", c.MaxBackups)
+	default:
+		return nil
+	}
+}
+
+// validateDnstapPaths returns an error if several server groups write their
+// dnstap output to the same path.
+func (srvGrps serverGroups) validateDnstapPaths() (err error) {
+	paths := container.NewMapSet[string]()
+	for i, g := range srvGrps {
+		c := g.Dnstap
+		if c == nil || !c.Enabled {
+			continue
+		}
+
+		if paths.Has(c.Path) {
+			return fmt.Errorf(
+				"at index %d: dnstap: path: %w: %q",
+				i,
+				errors.ErrDuplicated,
+				c.Path,
+			)
+		}
+
+		paths.Add(c.Path)
+	}
+
+	return nil
+}
+
+// initDnstap initializes and starts the dnstap writers of the server groups
+// for which the dnstap output is enabled.
+func (b *builder) initDnstap(ctx context.Context) (err error) {
+	b.dnstap = map[agd.ServerGroupName]*dnssvc.DnstapConfig{}
+
+	var mtrc dnstap.Metrics
+	for _, g := range b.conf.ServerGroups {
+		c := g.Dnstap
+		if c == nil || !c.Enabled {
+			continue
+		}
+
+		if mtrc == nil {
+			mtrc, err = metrics.NewDefaultDnstap(b.mtrcNamespace, b.promRegisterer)
+			if err != nil {
+				return fmt.Errorf("dnstap metrics: %w", err)
+			}
+		}
+
+		w := dnstap.New(&dnstap.Config{
+			Logger:      b.baseLogger.With(slogutil.KeyPrefix, "dnstap", "server_group", g.Name),
+			ErrColl:     b.errColl,
+			Metrics:     mtrc,
+			Identity:    b.conf.Check.NodeName,
+			Version:     version.Version(),
+			Path:        c.Path,
+			SinkType:    c.sinkType(),
+			QueueSize:   c.QueueSize,
+			MaxBackups:  c.MaxBackups,
+			MaxFileSize: c.MaxSize,
+		})
+
+		err = w.Start(ctx)
+		if err != nil {
+			return fmt.Errorf("starting dnstap for server group %q: %w", g.Name, err)
+		}
+
+		b.sigHdlr.Add(w)
+
+		b.dnstap[agd.ServerGroupName(g.Name)] = &dnssvc.DnstapConfig{
+			Writer: w,
+			Ratio:  c.Percentage / 100,
+		}
+
+		b.logger.InfoContext(ctx, "dnstap enabled", "server_group", g.Name, "path", c.Path)
+	}
+
+	b.logger.DebugContext(ctx, "initialized dnstap")
+
+	return nil
+}
@@ -9,10 +9,14 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/cachecrypt"
 	"github.com/AdguardTeam/AdGuardDNS/internal/debugsvc"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsdb"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
 	"github.com/AdguardTeam/AdGuardDNS/internal/version"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/log"
@@ -23,6 +27,7 @@ import (
 	"github.com/c2h5oh/datasize"
 	"github.com/caarlos0/env/v7"
 	"github.com/getsentry/sentry-go"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // environment represents the configuration that is kept in the environment.
@@ -37,36 +42,49 @@ type environment struct {
 	ConsulDNSCheckKVURL      *urlutil.URL `env:"CONSUL_DNSCHECK_KV_URL"`
 	ConsulDNSCheckSessionURL *urlutil.URL `env:"CONSUL_DNSCHECK_SESSION_URL"`
 	DNSCheckRemoteKVURL      *urlutil.URL `env:"DNSCHECK_REMOTEKV_URL"`
+	ErrCollSyslogURL         *urlutil.URL `env:"ERRCOLL_SYSLOG_URL"`
 	FilterIndexURL           *urlutil.URL `env:"FILTER_INDEX_URL,notEmpty"`
 	GeneralSafeSearchURL     *urlutil.URL `env:"GENERAL_SAFE_SEARCH_URL"`
 	LinkedIPTargetURL        *urlutil.URL `env:"LINKED_IP_TARGET_URL"`
 	NewRegDomainsURL         *urlutil.URL `env:"NEW_REG_DOMAINS_URL"`
+	PopularDomainsURL        *urlutil.URL `env:"POPULAR_DOMAINS_URL"`
 	ProfilesURL              *urlutil.URL `env:"PROFILES_URL"`
 	RuleStatURL              *urlutil.URL `env:"RULESTAT_URL"`
 	SafeBrowsingURL          *urlutil.URL `env:"SAFE_BROWSING_URL"`
+	ServerGroupsURL          *urlutil.URL `env:"SERVER_GROUPS_URL"`
+	StandardAccessURL        *urlutil.URL `env:"STANDARD_ACCESS_URL"`
 	YoutubeSafeSearchURL     *urlutil.URL `env:"YOUTUBE_SAFE_SEARCH_URL"`
 
 	BackendRateLimitAPIKey string `env:"BACKEND_RATELIMIT_API_KEY"`
 	BillStatAPIKey         string `env:"BILLSTAT_API_KEY"`
+	BillStatSpillPath      string `env:"BILLSTAT_SPILL_PATH"`
+	ClientKeySalt          string `env:"CLIENT_KEY_SALT"`
 	ConfPath               string `env:"CONFIG_PATH" envDefault:"./config.yaml"`
 	DNSCheckRemoteKVAPIKey string `env:"DNSCHECK_REMOTEKV_API_KEY"`
 	FilterCachePath        string `env:"FILTER_CACHE_PATH" envDefault:"./filters/"`
 	GeoIPASNPath           string `env:"GEOIP_ASN_PATH" envDefault:"./asn.mmdb"`
 	GeoIPCountryPath       string `env:"GEOIP_COUNTRY_PATH" envDefault:"./country.mmdb"`
+	PopularDomainsAPIKey   string `env:"POPULAR_DOMAINS_API_KEY"`
 	ProfilesAPIKey         string `env:"PROFILES_API_KEY"`
+	ProfilesCacheKeys      string `env:"PROFILES_CACHE_KEYS"`
 	ProfilesCachePath      string `env:"PROFILES_CACHE_PATH" envDefault:"./profilecache.pb"`
 	RedisAddr              string `env:"REDIS_ADDR"`
 	RedisKeyPrefix         string `env:"REDIS_KEY_PREFIX" envDefault:"agdns"`
+	RuleStatSpoolPath      string `env:"RULESTAT_SPOOL_PATH"`
+	RuntimeSnapshotPath    string `env:"RUNTIME_SNAPSHOT_PATH"`
 	QueryLogPath           string `env:"QUERYLOG_PATH" envDefault:"./querylog.jsonl"`
+	ServerGroupsAPIKey     string `env:"SERVER_GROUPS_API_KEY"`
 	SSLKeyLogFile          string `env:"SSL_KEY_LOG_FILE"`
 	SentryDSN              string `env:"SENTRY_DSN" envDefault:"stderr"`
+	StandardAccessAPIKey   string `env:"STANDARD_ACCESS_API_KEY"`
 	WebStaticDir           string `env:"WEB_STATIC_DIR"`
 
 	ListenAddr net.IP `env:"LISTEN_ADDR" envDefault:"127.0.0.1"`
 
 	ProfilesMaxRespSize datasize.ByteSize `env:"PROFILES_MAX_RESP_SIZE" envDefault:"64MB"`
 
-	RedisIdleTimeout timeutil.Duration `env:"REDIS_IDLE_TIMEOUT" envDefault:"30s"`
+	RedisIdleTimeout      timeutil.Duration `env:"REDIS_IDLE_TIMEOUT" envDefault:"30s"`
+	RuntimeSnapshotMaxAge timeutil.Duration `env:"RUNTIME_SNAPSHOT_MAX_AGE" envDefault:"10m"`
 
 	// TODO(a.garipov):  Rename to DNSCHECK_CACHE_KV_COUNT?
 	DNSCheckCacheKVSize int `env:"DNSCHECK_CACHE_KV_SIZE"`
@@ -122,6 +140,23 @@ func (envs *environment) validate() (err error) {
 		errs = append(errs, fmt.Errorf("env WEB_STATIC_DIR: %w", err))
 	}
 
+	if envs.ProfilesCacheKeys != "" {
+		_, err = cachecrypt.ParseKeys(envs.ProfilesCacheKeys)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("env PROFILES_CACHE_KEYS: %w", err))
+		}
+	}
+
+	err = envs.validateErrCollSyslogURL()
+	if err != nil {
+		errs = append(errs, fmt.Errorf("env ERRCOLL_SYSLOG_URL: %w", err))
+	}
+
+	if envs.RuntimeSnapshotPath != "" && envs.RuntimeSnapshotMaxAge.Duration <= 0 {
+		err = newNotPositiveError("env RUNTIME_SNAPSHOT_MAX_AGE", envs.RuntimeSnapshotMaxAge)
+		errs = append(errs, err)
+	}
+
 	_, err = slogutil.VerbosityToLevel(envs.Verbosity)
 	if err != nil {
 		errs = append(errs, fmt.Errorf("env VERBOSE: %w", err))
@@ -204,6 +239,28 @@ func (envs *environment) validateHTTPURLs(errs []error) (res []error) {
 	return res
 }
 
+// validateErrCollSyslogURL returns an error if the ERRCOLL_SYSLOG_URL
+// environment variable contains an invalid value.
+func (envs *environment) validateErrCollSyslogURL() (err error) {
+	u := envs.ErrCollSyslogURL
+	if u == nil {
+		return nil
+	}
+
+	switch s := u.Scheme; s {
+	case "tcp", "udp":
+		// Go on.
+	default:
+		return fmt.Errorf("scheme: %w: %q", errors.ErrBadEnumValue, s)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("host: %w", errors.ErrEmptyValue)
+	}
+
+	return nil
+}
+
 // validateWebStaticDir returns an error if the WEB_STATIC_DIR environment
 // variable contains an invalid value.
 func (envs *environment) validateWebStaticDir() (err error) {
@@ -245,6 +302,11 @@ func (envs *environment) validateFromValidConfig(conf *configuration) (err error
 		// Probably consul.
 	}
 
+	usesRedis := conf.RateLimit.SharedCounter.isEnabled() || conf.NodeRole.usesRedis()
+	if usesRedis && conf.Check.RemoteKV.Type != kvModeRedis {
+		errs = envs.validateRedis(errs)
+	}
+
 	if conf.isProfilesEnabled() {
 		errs = envs.validateProfilesURLs(errs)
 
@@ -260,6 +322,32 @@ func (envs *environment) validateFromValidConfig(conf *configuration) (err error
 
 	errs = envs.validateRateLimitURLs(conf, errs)
 
+	if envs.ServerGroupsURL != nil {
+		err = urlutil.ValidateGRPCURL(&envs.ServerGroupsURL.URL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("env SERVER_GROUPS_URL: %w", err))
+		}
+	}
+
+	if envs.StandardAccessURL != nil {
+		err = urlutil.ValidateGRPCURL(&envs.StandardAccessURL.URL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("env STANDARD_ACCESS_URL: %w", err))
+		}
+	}
+
+	if c := conf.PopularDomains; c != nil && c.Enabled {
+		var u *url.URL
+		if envs.PopularDomainsURL != nil {
+			u = &envs.PopularDomainsURL.URL
+		}
+
+		err = urlutil.ValidateGRPCURL(u)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("env POPULAR_DOMAINS_URL: %w", err))
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -408,8 +496,69 @@ func (envs *environment) configureLogs() (slogLogger *slog.Logger) {
 	})
 }
 
-// buildErrColl builds and returns an error collector from environment.
+// Error collector limits.
+const (
+	// errCollWindow is the window within which the errors are deduplicated and
+	// rate limited.
+	errCollWindow = 1 * time.Minute
+
+	// errCollLimit is the maximum number of errors collected within
+	// [errCollWindow].
+	errCollLimit = 100
+
+	// errCollSyslogTimeout is the timeout for the syslog error collector.
+	errCollSyslogTimeout = 5 * time.Second
+
+	// errCollSyslogQueueSize is the number of errors that can wait to be sent
+	// by the syslog error collector.
+	errCollSyslogQueueSize = 1_000
+)
+
+// buildErrColl builds and returns an error collector from environment.  The
+// syslog error collector is used if ERRCOLL_SYSLOG_URL is set, otherwise the
+// collector depends on SENTRY_DSN.
 func (envs *environment) buildErrColl() (errColl errcoll.Interface, err error) {
+	errColl, err = envs.buildErrCollSink()
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	return errcoll.NewLimitedErrorCollector(&errcoll.LimitedErrorCollectorConfig{
+		Next:   errColl,
+		Clock:  agdtime.SystemClock{},
+		Window: errCollWindow,
+		Limit:  errCollLimit,
+	}), nil
+}
+
+// buildErrCollSink builds and returns the error collector that sends the
+// errors to their final destination.
+func (envs *environment) buildErrCollSink() (errColl errcoll.Interface, err error) {
+	if u := envs.ErrCollSyslogURL; u != nil {
+		// Use the NILVALUE if the hostname is unknown.
+		hostname, _ := os.Hostname()
+
+		mtrc, mtrcErr := metrics.NewDefaultErrCollSyslog(
+			metrics.Namespace(),
+			prometheus.DefaultRegisterer,
+		)
+		if mtrcErr != nil {
+			return nil, fmt.Errorf("syslog error collector metrics: %w", mtrcErr)
+		}
+
+		return errcoll.NewSyslogErrorCollector(&errcoll.SyslogErrorCollectorConfig{
+			Clock:     agdtime.SystemClock{},
+			Metrics:   mtrc,
+			Network:   u.Scheme,
+			Address:   u.Host,
+			Hostname:  hostname,
+			AppName:   "adguard-dns",
+			Timeout:   errCollSyslogTimeout,
+			QueueSize: errCollSyslogQueueSize,
+		}), nil
+	}
+
 	dsn := envs.SentryDSN
 	if dsn == "stderr" {
 		return errcoll.NewWriterErrorCollector(os.Stderr), nil
@@ -19,6 +19,17 @@ type validator interface {
 	validate() (err error)
 }
 
+// validatorFunc is a function that implements the [validator] interface.
+type validatorFunc func() (err error)
+
+// type check
+var _ validator = validatorFunc(nil)
+
+// validate implements the [validator] interface for validatorFunc.
+func (f validatorFunc) validate() (err error) {
+	return f()
+}
+
 // reportPanics reports all panics in Main using the Sentry client, logs them,
 // and repanics.  It should be called in a defer.
 func reportPanics(ctx context.Context, errColl errcoll.Interface, l *slog.Logger) {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/AdguardTeam/golibs/timeutil"
 	"github.com/c2h5oh/datasize"
 )
@@ -17,6 +18,11 @@ type filtersConfig struct {
 	// RuleListCache is the cache settings for the filtering rule-list.
 	RuleListCache *fltRuleListCache `yaml:"rule_list_cache"`
 
+	// EssentialServices are the domain names of the services that are allowed
+	// for profiles in the allowlist-only mode in addition to their custom
+	// allowlist rules.  Subdomains of these domains are allowed as well.
+	EssentialServices []string `yaml:"essential_services"`
+
 	// CustomFilterCacheSize is the size of the LRU cache of compiled filtering
 	// engines for profiles with custom filtering rules.
 	//
@@ -28,6 +34,11 @@ type filtersConfig struct {
 	// TODO(a.garipov):  Rename to "safe_search_cache_count"?
 	SafeSearchCacheSize int `yaml:"safe_search_cache_size"`
 
+	// MaxCombinedRuleLists is the maximum number of combined rule-list
+	// filters, each of which uses a single engine for all rule lists of a
+	// filtering configuration.  Zero disables the combined filters.
+	MaxCombinedRuleLists int `yaml:"max_combined_rule_lists"`
+
 	// ResponseTTL is the TTL to set for DNS responses to requests for filtered
 	// domains.
 	ResponseTTL timeutil.Duration `yaml:"response_ttl"`
@@ -52,6 +63,12 @@ type filtersConfig struct {
 	// RefreshTimeout for the entire filter update operation.
 	RuleListRefreshTimeout timeutil.Duration `yaml:"rule_list_refresh_timeout"`
 
+	// StaleThreshold is the optional duration since the last successful
+	// refresh after which a filter is reported as stale by the filter health
+	// API.  Zero means that filters are only reported as stale if they have
+	// never been refreshed.
+	StaleThreshold timeutil.Duration `yaml:"stale_threshold"`
+
 	// MaxSize is the maximum size of the downloadable filtering rule-list.
 	MaxSize datasize.ByteSize `yaml:"max_size"`
 
@@ -82,6 +99,14 @@ func (c *filtersConfig) validate() (err error) {
 		validatePositive("max_size", c.MaxSize),
 	}
 
+	if c.MaxCombinedRuleLists < 0 {
+		errs = append(errs, newNegativeError("max_combined_rule_lists", c.MaxCombinedRuleLists))
+	}
+
+	if c.StaleThreshold.Duration < 0 {
+		errs = append(errs, newNegativeError("stale_threshold", c.StaleThreshold))
+	}
+
 	if !c.EDEEnabled && c.SDEEnabled {
 		errs = append(errs, errors.Error("ede must be enabled to enable sde"))
 	}
@@ -91,6 +116,13 @@ func (c *filtersConfig) validate() (err error) {
 		errs = append(errs, fmt.Errorf("rule_list_cache: %w", err))
 	}
 
+	for i, d := range c.EssentialServices {
+		err = netutil.ValidateDomainName(d)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("essential_services: at index %d: %w", i, err))
+		}
+	}
+
 	return errors.Join(errs...)
 }
 
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"net/netip"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
@@ -21,6 +22,11 @@ type filteringGroup struct {
 	// group.
 	SafeBrowsing *fltGrpSafeBrowsing `yaml:"safe_browsing"`
 
+	// BlockPage are the addresses of the block page for this filtering group.
+	// If it is nil, the replacement hosts of the safe-browsing filters are
+	// used.
+	BlockPage *fltGrpBlockPage `yaml:"block_page"`
+
 	// ID is a filtering group ID.  Must be unique.
 	ID string `yaml:"id"`
 
@@ -115,6 +121,48 @@ func (c *fltGrpSafeBrowsing) toInternal() (fltConf *filter.ConfigSafeBrowsing) {
 	}
 }
 
+// fltGrpBlockPage contains the addresses of the block page of a filtering
+// group.
+type fltGrpBlockPage struct {
+	// IPv4 is the IPv4 address of the block page.
+	IPv4 netip.Addr `yaml:"ipv4"`
+
+	// IPv6 is the IPv6 address of the block page.
+	IPv6 netip.Addr `yaml:"ipv6"`
+}
+
+// toInternal converts c to the block-page configuration for the filtering
+// group.  If c is nil, fltConf is nil.  c must be valid.
+func (c *fltGrpBlockPage) toInternal() (fltConf *filter.ConfigBlockPage) {
+	if c == nil {
+		return nil
+	}
+
+	return &filter.ConfigBlockPage{
+		IPv4: c.IPv4,
+		IPv6: c.IPv6,
+	}
+}
+
+// type check
+var _ validator = (*fltGrpBlockPage)(nil)
+
+// validate implements the [validator] interface for *fltGrpBlockPage.
+func (c *fltGrpBlockPage) validate() (err error) {
+	switch {
+	case c == nil:
+		return nil
+	case !c.IPv4.IsValid() && !c.IPv6.IsValid():
+		return fmt.Errorf("ipv4 and ipv6: %w", errors.ErrNoValue)
+	case c.IPv4.IsValid() && !c.IPv4.Is4():
+		return fmt.Errorf("ipv4: not an ipv4 address: %s", c.IPv4)
+	case c.IPv6.IsValid() && !c.IPv6.Is6():
+		return fmt.Errorf("ipv6: not an ipv6 address: %s", c.IPv6)
+	default:
+		return nil
+	}
+}
+
 // type check
 var _ validator = (*filteringGroup)(nil)
 
@@ -147,6 +195,11 @@ func (g *filteringGroup) validate() (err error) {
 		fltIDs.Add(fltID)
 	}
 
+	err = g.BlockPage.validate()
+	if err != nil {
+		return fmt.Errorf("block_page: %w", err)
+	}
+
 	return nil
 }
 
@@ -176,6 +229,7 @@ func (groups filteringGroups) toInternal(
 		id := agd.FilteringGroupID(g.ID)
 		fltGrps[id] = &agd.FilteringGroup{
 			FilterConfig: &filter.ConfigGroup{
+				BlockPage:    g.BlockPage.toInternal(),
 				Parental:     g.Parental.toInternal(),
 				RuleList:     g.RuleLists.toInternal(filterIDs),
 				SafeBrowsing: g.SafeBrowsing.toInternal(),
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/filterverdict"
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// filterVerdictConfig is the configuration of the signed filtering verdicts
+// added to the responses of a server group for the trusted downstream
+// resolvers.
+type filterVerdictConfig struct {
+	// KeyPath is the path to the file with the key used to sign the verdicts.
+	KeyPath string `yaml:"key_path"`
+
+	// Enabled shows if the verdicts are added to the responses.
+	Enabled bool `yaml:"enabled"`
+}
+
+// toInternal returns the key used to sign the filtering verdicts.  key is nil
+// if the verdicts are disabled.  c must be valid.
+func (c *filterVerdictConfig) toInternal() (key []byte, err error) {
+	if c == nil || !c.Enabled {
+		return nil, nil
+	}
+
+	key, err = os.ReadFile(c.KeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading key: %w", err)
+	}
+
+	if len(key) < filterverdict.MinKeyLen {
+		return nil, fmt.Errorf(
+			"key in %q: got %d bytes, want at least %d",
+			c.KeyPath,
+			len(key),
+			filterverdict.MinKeyLen,
+		)
+	}
+
+	return key, nil
+}
+
+// type check
+var _ validator = (*filterVerdictConfig)(nil)
+
+// validate implements the [validator] interface for *filterVerdictConfig.  The
+// filtering verdict configuration is optional.
+func (c *filterVerdictConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.KeyPath == "":
+		return fmt.Errorf("key_path: %w", errors.ErrEmptyValue)
+	default:
+		return nil
+	}
+}
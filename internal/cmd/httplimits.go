@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/golibs/timeutil"
+	"github.com/c2h5oh/datasize"
+)
+
+// maxHTTPBodySize is the maximum value of [httpLimitsConfig.MaxBodySize], which
+// is enough for any DNS message.
+const maxHTTPBodySize = 64 * datasize.KB
+
+// httpLimitsConfig is the configuration of the limits of a DoH server.
+type httpLimitsConfig struct {
+	// ReadHeaderTimeout is the amount of time allowed to read the request
+	// headers.  If it is zero, the default is used.
+	ReadHeaderTimeout timeutil.Duration `yaml:"read_header_timeout"`
+
+	// ReadTimeout is the amount of time allowed to read the entire request.
+	// If it is zero, the default is used.
+	ReadTimeout timeutil.Duration `yaml:"read_timeout"`
+
+	// MaxBodySize is the maximum size of the body of a request.  If it is
+	// zero, the default is used.
+	MaxBodySize datasize.ByteSize `yaml:"max_body_size"`
+
+	// ConnRequestsPerSecond is the maximum number of requests per second a
+	// client is allowed to send over a single connection.  If it is zero, the
+	// rate is not limited.
+	ConnRequestsPerSecond uint `yaml:"conn_requests_per_second"`
+
+	// CloseAbusiveConns, if true, makes the server close the connections on
+	// which a limit has been exceeded.
+	CloseAbusiveConns bool `yaml:"close_abusive_conns"`
+}
+
+// toInternal converts c to the DoH limits configuration for a DNS server.  c
+// must be valid.  conf is nil if c is nil.
+func (c *httpLimitsConfig) toInternal() (conf *agd.HTTPLimitsConfig) {
+	if c == nil {
+		return nil
+	}
+
+	return &agd.HTTPLimitsConfig{
+		ReadHeaderTimeout: c.ReadHeaderTimeout.Duration,
+		ReadTimeout:       c.ReadTimeout.Duration,
+		// #nosec G115 -- The value has already been validated in
+		// [httpLimitsConfig.validate].
+		MaxBodySize:           uint(c.MaxBodySize.Bytes()),
+		ConnRequestsPerSecond: c.ConnRequestsPerSecond,
+		CloseAbusiveConns:     c.CloseAbusiveConns,
+	}
+}
+
+// validate returns an error if the DoH limits configuration is invalid for the
+// given protocol.
+func (c *httpLimitsConfig) validate(p serverProto) (err error) {
+	switch {
+	case c == nil:
+		// No DoH limits settings, which is normal.
+		return nil
+	case p != srvProtoHTTPS:
+		return fmt.Errorf("protocol %s does not support http limits", p)
+	case c.ReadHeaderTimeout.Duration < 0:
+		return newNegativeError("read_header_timeout", c.ReadHeaderTimeout)
+	case c.ReadTimeout.Duration < 0:
+		return newNegativeError("read_timeout", c.ReadTimeout)
+	case c.MaxBodySize > maxHTTPBodySize:
+		return fmt.Errorf(
+			"max_body_size: must be less than or equal to %s, got %s",
+			maxHTTPBodySize,
+			c.MaxBodySize,
+		)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/c2h5oh/datasize"
+)
+
+// Limits of the sizes in [httpStreamsConfig].
+const (
+	// maxHTTPWindowSize is the maximum size of a flow-control window allowed
+	// by HTTP/2.
+	maxHTTPWindowSize datasize.ByteSize = math.MaxInt32
+
+	// minHTTPConnWindowSize is the minimum size of the flow-control window of
+	// an HTTP/2 connection, which is the initial window size.
+	minHTTPConnWindowSize datasize.ByteSize = 65_535
+
+	// maxHTTPHeaderTableSize is the maximum size of the HTTP/2 header
+	// compression tables.
+	maxHTTPHeaderTableSize datasize.ByteSize = math.MaxUint32
+)
+
+// httpStreamsConfig is the configuration of the streams of the HTTP/2 and
+// HTTP/3 connections of a DoH server.
+type httpStreamsConfig struct {
+	// WriteScheduler is the scheduler of the HTTP/2 response frames.  If it is
+	// empty, [httpWriteSchedulerRoundRobin] is used.
+	WriteScheduler httpWriteScheduler `yaml:"write_scheduler"`
+
+	// HeaderTableSize is the maximum size of the HTTP/2 header compression
+	// tables.  If it is zero, the default is used.
+	HeaderTableSize datasize.ByteSize `yaml:"header_table_size"`
+
+	// StreamWindowSize is the size of the flow-control window of a single
+	// stream.  If it is zero, the default is used.
+	StreamWindowSize datasize.ByteSize `yaml:"stream_window_size"`
+
+	// ConnWindowSize is the size of the flow-control window of a whole
+	// connection.  If it is zero, the default is used.
+	ConnWindowSize datasize.ByteSize `yaml:"conn_window_size"`
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams a
+	// client is allowed to open over a single connection.  If it is zero, the
+	// default is used.
+	MaxConcurrentStreams uint32 `yaml:"max_concurrent_streams"`
+}
+
+// toInternal converts c to the configuration of the HTTP/2 and HTTP/3 streams
+// for a DNS server.  c must be valid.  conf is nil if c is nil.
+func (c *httpStreamsConfig) toInternal() (conf *agd.HTTPStreamsConfig) {
+	if c == nil {
+		return nil
+	}
+
+	return &agd.HTTPStreamsConfig{
+		WriteScheduler:       c.WriteScheduler.toInternal(),
+		MaxConcurrentStreams: c.MaxConcurrentStreams,
+		// #nosec G115 -- The values have already been validated in
+		// [httpStreamsConfig.validate].
+		HeaderTableSize: uint32(c.HeaderTableSize.Bytes()),
+		// #nosec G115 -- The values have already been validated in
+		// [httpStreamsConfig.validate].
+		StreamWindowSize: uint32(c.StreamWindowSize.Bytes()),
+		// #nosec G115 -- The values have already been validated in
+		// [httpStreamsConfig.validate].
+		ConnWindowSize: uint32(c.ConnWindowSize.Bytes()),
+	}
+}
+
+// validate returns an error if the configuration of the HTTP/2 and HTTP/3
+// streams is invalid for the given protocol.
+func (c *httpStreamsConfig) validate(p serverProto) (err error) {
+	switch {
+	case c == nil:
+		// No DoH stream settings, which is normal.
+		return nil
+	case p != srvProtoHTTPS:
+		return fmt.Errorf("protocol %s does not support http streams", p)
+	case c.HeaderTableSize > maxHTTPHeaderTableSize:
+		return newTooLargeSizeError("header_table_size", c.HeaderTableSize, maxHTTPHeaderTableSize)
+	case c.StreamWindowSize > maxHTTPWindowSize:
+		return newTooLargeSizeError("stream_window_size", c.StreamWindowSize, maxHTTPWindowSize)
+	case c.ConnWindowSize > maxHTTPWindowSize:
+		return newTooLargeSizeError("conn_window_size", c.ConnWindowSize, maxHTTPWindowSize)
+	case c.ConnWindowSize != 0 && c.ConnWindowSize < minHTTPConnWindowSize:
+		return fmt.Errorf(
+			"conn_window_size: must be zero or greater than or equal to %s, got %s",
+			minHTTPConnWindowSize,
+			c.ConnWindowSize,
+		)
+	}
+
+	err = c.WriteScheduler.validate()
+	if err != nil {
+		return fmt.Errorf("write_scheduler: %w", err)
+	}
+
+	return nil
+}
+
+// newTooLargeSizeError returns an error about a size property that is greater
+// than maxSize.
+func newTooLargeSizeError(prop string, size, maxSize datasize.ByteSize) (err error) {
+	return fmt.Errorf("%s: must be less than or equal to %s, got %s", prop, maxSize, size)
+}
+
+// httpWriteScheduler is the type for the HTTP/2 write schedulers in the
+// on-disk configuration.
+type httpWriteScheduler string
+
+// Valid HTTP/2 write scheduler values in the on-disk configuration file.
+const (
+	httpWriteSchedulerPriority   httpWriteScheduler = "priority"
+	httpWriteSchedulerRandom     httpWriteScheduler = "random"
+	httpWriteSchedulerRoundRobin httpWriteScheduler = "round_robin"
+)
+
+// toInternal returns the equivalent dnsserver.HTTPWriteScheduler value.  s
+// must be valid.
+func (s httpWriteScheduler) toInternal() (sched dnsserver.HTTPWriteScheduler) {
+	switch s {
+	case httpWriteSchedulerPriority:
+		return dnsserver.HTTPWriteSchedulerPriority
+	case httpWriteSchedulerRandom:
+		return dnsserver.HTTPWriteSchedulerRandom
+	default:
+		return dnsserver.HTTPWriteSchedulerRoundRobin
+	}
+}
+
+// type check
+var _ validator = httpWriteScheduler("")
+
+// validate implements the [validator] interface for httpWriteScheduler.
+func (s httpWriteScheduler) validate() (err error) {
+	switch s {
+	case
+		"",
+		httpWriteSchedulerPriority,
+		httpWriteSchedulerRandom,
+		httpWriteSchedulerRoundRobin:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", errors.ErrBadEnumValue, s)
+	}
+}
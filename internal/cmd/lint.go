@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+
+	"github.com/caarlos0/env/v7"
+)
+
+// Lint diagnostic sources.
+const (
+	LintSourceConfig      = "config"
+	LintSourceEnvironment = "environment"
+	LintSourceTLS         = "tls"
+)
+
+// LintDiagnostic is a single problem found by [Lint].
+type LintDiagnostic struct {
+	// Source is the source of the problem.  It is one of [LintSourceConfig],
+	// [LintSourceEnvironment], and [LintSourceTLS].
+	Source string `json:"source"`
+
+	// Path is the path to the problematic property, if any.
+	Path string `json:"path,omitempty"`
+
+	// Message is the description of the problem.
+	Message string `json:"message"`
+}
+
+// LintConfig is the configuration for [Lint].
+type LintConfig struct {
+	// Environment are the environment variables to validate.  If it is nil,
+	// the environment of the process is used.
+	Environment map[string]string
+
+	// ConfPath is the path to the configuration file.  If it is empty, the
+	// path from the CONFIG_PATH environment variable is used.
+	ConfPath string
+}
+
+// Lint validates the configuration file, the environment, and the TLS files of
+// the server groups without connecting to any remote services or starting any
+// servers.  c must not be nil.  diags are empty if no problems are found.
+func Lint(c *LintConfig) (diags []*LintDiagnostic) {
+	envs := &environment{}
+	err := env.Parse(envs, env.Options{Environment: c.Environment})
+	if err != nil {
+		diags = appendLintErrs(diags, LintSourceEnvironment, "", err)
+		envs = nil
+	} else {
+		diags = appendLintErrs(diags, LintSourceEnvironment, "", envs.validate())
+	}
+
+	confPath := c.ConfPath
+	if confPath == "" && envs != nil {
+		confPath = envs.ConfPath
+	}
+
+	conf, err := parseConfig(confPath)
+	if err != nil {
+		return appendLintErrs(diags, LintSourceConfig, "", err)
+	}
+
+	diags, ok := lintConfig(diags, conf)
+	if !ok {
+		return diags
+	}
+
+	if envs != nil {
+		diags = appendLintErrs(diags, LintSourceEnvironment, "", envs.validateFromValidConfig(conf))
+	}
+
+	return lintServerGroupsTLS(diags, conf.ServerGroups)
+}
+
+// lintConfig appends the diagnostics about the top-level properties of conf to
+// orig.  ok is true if conf is valid.  conf must not be nil.
+func lintConfig(
+	orig []*LintDiagnostic,
+	conf *configuration,
+) (diags []*LintDiagnostic, ok bool) {
+	diags = orig
+	for _, kv := range conf.validators() {
+		diags = appendLintErrs(diags, LintSourceConfig, kv.Key, kv.Value.validate())
+	}
+
+	return diags, len(diags) == len(orig)
+}
+
+// lintServerGroupsTLS appends the diagnostics about the TLS files of the
+// server groups to diags.  grps must be valid.
+func lintServerGroupsTLS(
+	orig []*LintDiagnostic,
+	grps serverGroups,
+) (diags []*LintDiagnostic) {
+	diags = orig
+	for _, g := range grps {
+		if g.TLS == nil {
+			continue
+		}
+
+		for i, cert := range g.TLS.Certificates {
+			path := fmt.Sprintf("server_groups: %s: tls: certificates: at index %d", g.Name, i)
+			_, err := tls.LoadX509KeyPair(cert.Certificate, cert.Key)
+			diags = appendLintErrs(diags, LintSourceTLS, path, err)
+		}
+
+		for i, keyPath := range g.TLS.SessionKeys {
+			path := fmt.Sprintf("server_groups: %s: tls: session_keys: at index %d", g.Name, i)
+
+			// #nosec G304 -- Trust the paths from the configuration file.
+			_, err := os.ReadFile(keyPath)
+			diags = appendLintErrs(diags, LintSourceTLS, path, err)
+		}
+	}
+
+	return diags
+}
+
+// appendLintErrs appends a diagnostic for each of the errors joined in err to
+// orig.  If err is nil, diags is orig.
+func appendLintErrs(
+	orig []*LintDiagnostic,
+	source string,
+	path string,
+	err error,
+) (diags []*LintDiagnostic) {
+	if err == nil {
+		return orig
+	}
+
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	diags = orig
+	for _, e := range errs {
+		diags = append(diags, &LintDiagnostic{
+			Source:  source,
+			Path:    path,
+			Message: e.Error(),
+		})
+	}
+
+	return diags
+}
@@ -0,0 +1,278 @@
+package cmd_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/cmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+// testDistConfPath is the path to the example configuration file.
+const testDistConfPath = "../../config.dist.yaml"
+
+// testEnvironment is the environment that is valid for the example
+// configuration file.
+var testEnvironment = map[string]string{
+	"ADULT_BLOCKING_URL":        "https://adult.example/",
+	"BILLSTAT_URL":              "grpc://billstat.example:443",
+	"BLOCKED_SERVICE_INDEX_URL": "https://services.example/",
+	"CONSUL_ALLOWLIST_URL":      "https://consul.example/",
+	"DNSCHECK_CACHE_KV_SIZE":    "100",
+	"FILTER_INDEX_URL":          "https://filters.example/",
+	"GENERAL_SAFE_SEARCH_URL":   "https://safesearch.example/",
+	"NEW_REG_DOMAINS_URL":       "https://nrd.example/",
+	"PROFILES_URL":              "grpc://profiles.example:443",
+	"SAFE_BROWSING_URL":         "https://sb.example/",
+	"YOUTUBE_SAFE_SEARCH_URL":   "https://youtube.example/",
+}
+
+// legacySections are the top-level sections of the configuration file that
+// are optional and are absent in the older configuration files.
+var legacySections = []string{
+	"audit_log",
+	"category_lookup",
+	"chaos",
+	"dhcp_leases",
+	"dnssec_signing",
+	"node_role",
+	"poison_pill",
+	"popular_domains",
+	"ptr",
+	"quarantine",
+	"quic_advisor",
+	"request_log",
+	"shadow",
+	"special_use_domains",
+	"static_zones",
+	"top_profiles",
+	"unblock",
+}
+
+// writeTestTLSFiles is a helper that writes the TLS files used by the example
+// configuration file into dir.
+func writeTestTLSFiles(tb testing.TB, dir string) {
+	tb.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(tb, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"dns.example"},
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(tb, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(tb, err)
+
+	files := map[string][]byte{
+		"cert.crt":  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		"cert.key":  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		"tls_key_1": make([]byte, 32),
+		"tls_key_2": make([]byte, 32),
+	}
+
+	for name, data := range files {
+		err = os.WriteFile(filepath.Join(dir, name), data, 0o600)
+		require.NoError(tb, err)
+	}
+}
+
+// writeTestConfig is a helper that writes the example configuration file, with
+// the paths to the test files pointing to dir and modified by modify, if it's
+// not nil, into dir.  It returns the path to the written file.
+func writeTestConfig(
+	tb testing.TB,
+	dir string,
+	modify func(conf map[string]any),
+) (confPath string) {
+	tb.Helper()
+
+	data, err := os.ReadFile(testDistConfPath)
+	require.NoError(tb, err)
+
+	data = []byte(strings.ReplaceAll(string(data), "./test/", dir+"/"))
+
+	if modify != nil {
+		conf := map[string]any{}
+		err = yaml.Unmarshal(data, &conf)
+		require.NoError(tb, err)
+
+		modify(conf)
+
+		data, err = yaml.Marshal(conf)
+		require.NoError(tb, err)
+	}
+
+	confPath = filepath.Join(dir, "config.yaml")
+	err = os.WriteFile(confPath, data, 0o600)
+	require.NoError(tb, err)
+
+	return confPath
+}
+
+// firstServerGroup returns the first server group of conf.
+func firstServerGroup(conf map[string]any) (g map[any]any) {
+	return conf["server_groups"].([]any)[0].(map[any]any)
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		modify    func(conf map[string]any)
+		removeEnv string
+		name      string
+		want      []*cmd.LintDiagnostic
+	}{{
+		modify:    nil,
+		removeEnv: "",
+		name:      "valid",
+		want:      nil,
+	}, {
+		modify: func(conf map[string]any) {
+			for _, s := range legacySections {
+				delete(conf, s)
+			}
+
+			delete(firstServerGroup(conf), "dnstap")
+		},
+		removeEnv: "",
+		name:      "legacy",
+		want:      nil,
+	}, {
+		modify: func(conf map[string]any) {
+			conf["cache"].(map[any]any)["size"] = -1
+		},
+		removeEnv: "",
+		name:      "bad_property",
+		want: []*cmd.LintDiagnostic{{
+			Source:  cmd.LintSourceConfig,
+			Path:    "cache",
+			Message: "size: negative value: got -1",
+		}},
+	}, {
+		modify: func(conf map[string]any) {
+			delete(conf, "cache")
+		},
+		removeEnv: "",
+		name:      "no_section",
+		want: []*cmd.LintDiagnostic{{
+			Source:  cmd.LintSourceConfig,
+			Path:    "cache",
+			Message: "no value",
+		}},
+	}, {
+		modify: func(conf map[string]any) {
+			firstServerGroup(conf)["aggressive_nsec_enabled"] = true
+		},
+		removeEnv: "",
+		name:      "aggressive_nsec_untrusted",
+		want: []*cmd.LintDiagnostic{{
+			Source: cmd.LintSourceConfig,
+			Path:   "server_groups",
+			Message: "at index 0: aggressive_nsec_enabled: upstream: trusted_dnssec " +
+				"must be true",
+		}},
+	}, {
+		modify: func(conf map[string]any) {
+			firstServerGroup(conf)["aggressive_nsec_enabled"] = true
+			conf["upstream"].(map[any]any)["trusted_dnssec"] = true
+		},
+		removeEnv: "",
+		name:      "aggressive_nsec_trusted",
+		want:      nil,
+	}, {
+		modify:    nil,
+		removeEnv: "FILTER_INDEX_URL",
+		name:      "bad_environment",
+		want: []*cmd.LintDiagnostic{{
+			Source: cmd.LintSourceEnvironment,
+			Path:   "",
+			Message: `env: environment variable "FILTER_INDEX_URL" should not ` +
+				`be empty`,
+		}},
+	}, {
+		modify:    nil,
+		removeEnv: "DNSCHECK_CACHE_KV_SIZE",
+		name:      "bad_environment_for_config",
+		want: []*cmd.LintDiagnostic{{
+			Source:  cmd.LintSourceEnvironment,
+			Path:    "",
+			Message: "DNSCHECK_CACHE_KV_SIZE: not positive: got 0",
+		}},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			writeTestTLSFiles(t, dir)
+
+			envs := map[string]string{}
+			for k, v := range testEnvironment {
+				if k != tc.removeEnv {
+					envs[k] = v
+				}
+			}
+
+			diags := cmd.Lint(&cmd.LintConfig{
+				Environment: envs,
+				ConfPath:    writeTestConfig(t, dir, tc.modify),
+			})
+			assert.Equal(t, tc.want, diags)
+		})
+	}
+}
+
+func TestLint_tls(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeTestTLSFiles(t, dir)
+
+	confPath := writeTestConfig(t, dir, nil)
+
+	err := os.Remove(filepath.Join(dir, "tls_key_2"))
+	require.NoError(t, err)
+
+	diags := cmd.Lint(&cmd.LintConfig{
+		Environment: testEnvironment,
+		ConfPath:    confPath,
+	})
+	require.Len(t, diags, 1)
+
+	d := diags[0]
+	assert.Equal(t, cmd.LintSourceTLS, d.Source)
+	assert.Equal(t, "server_groups: adguard_dns_default: tls: session_keys: at index 1", d.Path)
+	assert.Contains(t, d.Message, "no such file or directory")
+}
+
+func TestLint_noConfig(t *testing.T) {
+	t.Parallel()
+
+	diags := cmd.Lint(&cmd.LintConfig{
+		Environment: testEnvironment,
+		ConfPath:    filepath.Join(t.TempDir(), "config.yaml"),
+	})
+	require.Len(t, diags, 1)
+
+	assert.Equal(t, cmd.LintSourceConfig, diags[0].Source)
+}
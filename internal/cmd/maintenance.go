@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/maintenance"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// maintenanceConfig is the maintenance-mode configuration of a server group.
+// The maintenance mode can also be switched at runtime using the debug HTTP
+// API.
+type maintenanceConfig struct {
+	// RetryAfter is the duration after which the clients are advised to retry
+	// their queries.  If it is zero, no advice is given.
+	RetryAfter timeutil.Duration `yaml:"retry_after"`
+
+	// FailHealthCheck, if true, makes the health check fail while the server
+	// group is in the maintenance mode.
+	FailHealthCheck bool `yaml:"fail_health_check"`
+
+	// Enabled is the initial state of the maintenance mode.
+	Enabled bool `yaml:"enabled"`
+}
+
+// toInternal returns the maintenance-mode configuration of a server group.  If
+// c is nil, conf is the default configuration.  c must be valid.
+func (c *maintenanceConfig) toInternal() (conf *maintenance.GroupConfig) {
+	if c == nil {
+		return &maintenance.GroupConfig{}
+	}
+
+	return &maintenance.GroupConfig{
+		RetryAfter:      c.RetryAfter.Duration,
+		FailHealthCheck: c.FailHealthCheck,
+		Enabled:         c.Enabled,
+	}
+}
+
+// type check
+var _ validator = (*maintenanceConfig)(nil)
+
+// validate implements the [validator] interface for *maintenanceConfig.  The
+// maintenance-mode configuration is optional.
+func (c *maintenanceConfig) validate() (err error) {
+	if c == nil {
+		return nil
+	} else if c.RetryAfter.Duration < 0 {
+		return newNegativeError("retry_after", c.RetryAfter)
+	}
+
+	return nil
+}
+
+// toMaintenanceManager returns the maintenance-mode manager for the server
+// groups.  srvGrps must be valid.
+func (srvGrps serverGroups) toMaintenanceManager() (m *maintenance.Manager) {
+	confs := make(map[agd.ServerGroupName]*maintenance.GroupConfig, len(srvGrps))
+	for _, g := range srvGrps {
+		confs[agd.ServerGroupName(g.Name)] = g.Maintenance.toInternal()
+	}
+
+	return maintenance.NewManager(confs)
+}
@@ -3,15 +3,22 @@ package cmd
 import (
 	"fmt"
 	"math"
+	"net"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/bindtodevice"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
 	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/timeutil"
 	"github.com/c2h5oh/datasize"
 )
 
 // network defines the network settings.
 type network struct {
+	// TCPKeepAlive is the optional configuration of the TCP keep-alive probes
+	// of the accepted connections.  If it is nil or disabled, the defaults of
+	// the Go standard library are used.
+	TCPKeepAlive *tcpKeepAliveConfig `yaml:"tcp_keepalive"`
+
 	// SndBufSize defines the size of socket send buffer.  Default is zero (uses
 	// system settings).
 	SndBufSize datasize.ByteSize `yaml:"so_sndbuf"`
@@ -19,10 +26,19 @@ type network struct {
 	// RcvBufSize defines the size of socket receive buffer.  Default is zero
 	// (uses system settings).
 	RcvBufSize datasize.ByteSize `yaml:"so_rcvbuf"`
+
+	// TCPFastOpenQueueLen is the maximum length of the queue of the pending
+	// server-side TCP Fast Open requests.  Default is zero (TCP Fast Open is
+	// disabled).
+	TCPFastOpenQueueLen int `yaml:"tcp_fast_open_queue_len"`
+
+	// DisableTCPNoDelay, if true, makes the accepted TCP connections use the
+	// Nagle's algorithm.
+	DisableTCPNoDelay bool `yaml:"disable_tcp_nodelay"`
 }
 
 // type check
-var _ validator = (*interfaceListener)(nil)
+var _ validator = (*network)(nil)
 
 // validate implements the [validator] interface for *network.
 func (n *network) validate() (err error) {
@@ -42,14 +58,25 @@ func (n *network) validate() (err error) {
 			errors.ErrOutOfRange,
 			maxBufSize,
 		)
+	case n.TCPFastOpenQueueLen < 0:
+		return newNegativeError("tcp_fast_open_queue_len", n.TCPFastOpenQueueLen)
+	case n.TCPFastOpenQueueLen > math.MaxInt32:
+		return fmt.Errorf(
+			"tcp_fast_open_queue_len: %s: must be less than or equal to %d",
+			errors.ErrOutOfRange,
+			math.MaxInt32,
+		)
 	default:
-		return nil
+		return validateProp("tcp_keepalive", n.TCPKeepAlive.validate)
 	}
 }
 
 // toInternal converts n to the bindtodevice control configuration and network
-// extension control configuration.  n must be valid.
-func (n *network) toInternal() (bc *bindtodevice.ControlConfig, nc *netext.ControlConfig) {
+// extension control configuration.  The options of the TCP connections are
+// only used by the latter.  n must be valid.
+func (n *network) toInternal(
+	m netext.Metrics,
+) (bc *bindtodevice.ControlConfig, nc *netext.ControlConfig) {
 	bc = &bindtodevice.ControlConfig{
 		// #nosec G115 -- Validated in [network.validate].
 		SndBufSize: int(n.SndBufSize.Bytes()),
@@ -57,11 +84,68 @@ func (n *network) toInternal() (bc *bindtodevice.ControlConfig, nc *netext.Contr
 		RcvBufSize: int(n.RcvBufSize.Bytes()),
 	}
 	nc = &netext.ControlConfig{
+		Metrics:   m,
+		KeepAlive: n.TCPKeepAlive.toInternal(),
 		// #nosec G115 -- Validated in [network.validate].
 		SndBufSize: int(n.SndBufSize.Bytes()),
 		// #nosec G115 -- Validated in [network.validate].
-		RcvBufSize: int(n.RcvBufSize.Bytes()),
+		RcvBufSize:          int(n.RcvBufSize.Bytes()),
+		TCPFastOpenQueueLen: n.TCPFastOpenQueueLen,
+		DisableTCPNoDelay:   n.DisableTCPNoDelay,
 	}
 
 	return bc, nc
 }
+
+// tcpKeepAliveConfig is the configuration of the TCP keep-alive probes.
+type tcpKeepAliveConfig struct {
+	// Idle is the time that the connection must be idle before the first
+	// keep-alive probe is sent.  If it is zero, the default of 15 seconds is
+	// used.
+	Idle timeutil.Duration `yaml:"idle"`
+
+	// Interval is the time between the keep-alive probes.  If it is zero, the
+	// default of 15 seconds is used.
+	Interval timeutil.Duration `yaml:"interval"`
+
+	// Count is the maximum number of the unanswered keep-alive probes before
+	// the connection is dropped.  If it is zero, the default of 9 is used.
+	Count int `yaml:"count"`
+
+	// Enabled shows if the keep-alive probes are configured explicitly.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*tcpKeepAliveConfig)(nil)
+
+// validate implements the [validator] interface for *tcpKeepAliveConfig.
+func (c *tcpKeepAliveConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.Idle.Duration < 0:
+		return newNegativeError("idle", c.Idle)
+	case c.Interval.Duration < 0:
+		return newNegativeError("interval", c.Interval)
+	case c.Count < 0:
+		return newNegativeError("count", c.Count)
+	default:
+		return nil
+	}
+}
+
+// toInternal converts c to the keep-alive configuration of the listeners.  c
+// must be valid.
+func (c *tcpKeepAliveConfig) toInternal() (conf net.KeepAliveConfig) {
+	if c == nil || !c.Enabled {
+		return net.KeepAliveConfig{}
+	}
+
+	return net.KeepAliveConfig{
+		Enable:   true,
+		Idle:     c.Idle.Duration,
+		Interval: c.Interval.Duration,
+		Count:    c.Count,
+	}
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/noderole"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// Node-role coordination modes.
+const (
+	// nodeRoleModeStatic means that the role is only switched using the debug
+	// HTTP API.
+	nodeRoleModeStatic = "static"
+
+	// nodeRoleModeRedis means that the role is coordinated using a lease in
+	// Redis shared by the nodes of the pair.
+	nodeRoleModeRedis = "redis"
+)
+
+// nodeRoleConfig is the configuration of the coordination of the roles of the
+// paired nodes, one of which is a hot standby.
+type nodeRoleConfig struct {
+	// Mode is the coordination mode.  See the nodeRoleMode constants.
+	Mode string `yaml:"mode"`
+
+	// PairName is the name of the pair of nodes, which is used in the key of
+	// the lease.  It is only used in the Redis mode.
+	PairName string `yaml:"pair_name"`
+
+	// InitialRole is the role of the node at startup.
+	InitialRole string `yaml:"initial_role"`
+
+	// LeaseTTL is the time after which the lease expires unless renewed.  It
+	// is only used in the Redis mode.
+	LeaseTTL timeutil.Duration `yaml:"lease_ttl"`
+
+	// RefreshInterval defines how often the lease is renewed or checked.  It
+	// is only used in the Redis mode.
+	RefreshInterval timeutil.Duration `yaml:"refresh_interval"`
+
+	// Enabled shows if the node is a part of a pair.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*nodeRoleConfig)(nil)
+
+// validate implements the [validator] interface for *nodeRoleConfig.  The
+// node-role configuration is optional.
+func (c *nodeRoleConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.Mode != nodeRoleModeStatic && c.Mode != nodeRoleModeRedis:
+		return fmt.Errorf("mode: %w: %q", errors.ErrBadEnumValue, c.Mode)
+	}
+
+	_, err = noderole.ParseRole(c.InitialRole)
+	if err != nil {
+		return fmt.Errorf("initial_role: %w", err)
+	}
+
+	if c.Mode == nodeRoleModeStatic {
+		return nil
+	}
+
+	return c.validateLease()
+}
+
+// validateLease returns an error if the lease properties of c aren't valid.
+func (c *nodeRoleConfig) validateLease() (err error) {
+	if c.PairName == "" {
+		return fmt.Errorf("pair_name: %w", errors.ErrEmptyValue)
+	}
+
+	err = validatePositive("lease_ttl", c.LeaseTTL)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	err = validatePositive("refresh_interval", c.RefreshInterval)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	if c.RefreshInterval.Duration >= c.LeaseTTL.Duration {
+		return fmt.Errorf(
+			"refresh_interval: %w: must be less than lease_ttl, got %s",
+			errors.ErrOutOfRange,
+			c.RefreshInterval,
+		)
+	}
+
+	return nil
+}
+
+// usesRedis returns true if the node role is coordinated using Redis.
+func (c *nodeRoleConfig) usesRedis() (ok bool) {
+	return c != nil && c.Enabled && c.Mode == nodeRoleModeRedis
+}
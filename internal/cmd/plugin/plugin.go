@@ -3,6 +3,7 @@
 package plugin
 
 import (
+	"github.com/AdguardTeam/AdGuardDNS/internal/cachecrypt"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnscheck"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
@@ -12,9 +13,10 @@ import (
 // DNS entities.  A nil Registry can be used safely: all its methods return zero
 // values.
 type Registry struct {
-	dnscheck   dnscheck.Interface
-	mainMwMtrc metrics.MainMiddleware
-	postInitMw dnsserver.Middleware
+	dnscheck      dnscheck.Interface
+	mainMwMtrc    metrics.MainMiddleware
+	postInitMw    dnsserver.Middleware
+	profCacheKeys cachecrypt.KeySource
 }
 
 // NewRegistry returns a new registry with the given custom implementations.
@@ -22,11 +24,13 @@ func NewRegistry(
 	dnsCk dnscheck.Interface,
 	mainMwMtrc metrics.MainMiddleware,
 	postInitMw dnsserver.Middleware,
+	profCacheKeys cachecrypt.KeySource,
 ) (r *Registry) {
 	return &Registry{
-		dnscheck:   dnsCk,
-		mainMwMtrc: mainMwMtrc,
-		postInitMw: postInitMw,
+		dnscheck:      dnsCk,
+		mainMwMtrc:    mainMwMtrc,
+		postInitMw:    postInitMw,
+		profCacheKeys: profCacheKeys,
 	}
 }
 
@@ -58,3 +62,13 @@ func (r *Registry) PostInitialMiddleware() (postInitMw dnsserver.Middleware) {
 
 	return r.postInitMw
 }
+
+// ProfilesCacheKeys returns a custom source of the keys for the encryption of
+// the profile cache, such as a key-management service, if any.
+func (r *Registry) ProfilesCacheKeys() (keys cachecrypt.KeySource) {
+	if r == nil {
+		return nil
+	}
+
+	return r.profCacheKeys
+}
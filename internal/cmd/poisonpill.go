@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/AdGuardDNS/internal/poisonpill"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// poisonPillConfig is the configuration of the isolation of the panics caused
+// by single queries and of the detection of the queries that repeatedly cause
+// them.
+type poisonPillConfig struct {
+	// Window is the period within which Threshold panics must be caused by the
+	// same query for a drop rule to be added.
+	Window timeutil.Duration `yaml:"window"`
+
+	// DropDuration is the time for which the queries matching a drop rule are
+	// dropped.
+	DropDuration timeutil.Duration `yaml:"drop_duration"`
+
+	// RecentSize is the number of the most recent panics kept in memory for
+	// the debug API.
+	RecentSize uint `yaml:"recent_size"`
+
+	// CacheSize is the maximum number of the queries the panic counters of
+	// which are kept.
+	CacheSize int `yaml:"cache_size"`
+
+	// Threshold is the number of panics caused by the same query after which a
+	// drop rule is added for it.
+	Threshold uint `yaml:"threshold"`
+
+	// Enabled shows if the isolation is enabled.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*poisonPillConfig)(nil)
+
+// validate implements the [validator] interface for *poisonPillConfig.  The
+// poison-pill configuration is optional.
+func (c *poisonPillConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.Window.Duration <= 0:
+		return newNotPositiveError("window", c.Window)
+	case c.DropDuration.Duration <= 0:
+		return newNotPositiveError("drop_duration", c.DropDuration)
+	case c.RecentSize == 0:
+		return newNotPositiveError("recent_size", c.RecentSize)
+	case c.CacheSize <= 0:
+		return newNotPositiveError("cache_size", c.CacheSize)
+	case c.Threshold == 0:
+		return newNotPositiveError("threshold", c.Threshold)
+	default:
+		return nil
+	}
+}
+
+// initPoisonPill initializes the guard that isolates the panics caused by
+// single queries, if it is enabled.
+func (b *builder) initPoisonPill(ctx context.Context) (err error) {
+	c := b.conf.PoisonPill
+	if c == nil || !c.Enabled {
+		b.logger.DebugContext(ctx, "poison-pill guard disabled")
+
+		return nil
+	}
+
+	mtrc, err := metrics.NewPoisonPill(b.mtrcNamespace, b.promRegisterer)
+	if err != nil {
+		return fmt.Errorf("registering poison-pill metrics: %w", err)
+	}
+
+	b.poisonPill = poisonpill.New(&poisonpill.Config{
+		Logger:       b.baseLogger.With(slogutil.KeyPrefix, "poisonpill"),
+		Clock:        agdtime.SystemClock{},
+		ErrColl:      b.errColl,
+		Metrics:      mtrc,
+		Window:       c.Window.Duration,
+		DropDuration: c.DropDuration.Duration,
+		RecentCount:  c.RecentSize,
+		CacheSize:    c.CacheSize,
+		Threshold:    c.Threshold,
+	})
+
+	b.logger.DebugContext(ctx, "initialized poison-pill guard")
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// popularDomainsConfig is the configuration of the differentially private
+// statistics of the most popular domains per country and per ASN.  See the
+// environments type for the backend parameters.
+type popularDomainsConfig struct {
+	// Interval is the duration of the window within which the statistics are
+	// collected before they are uploaded.
+	Interval timeutil.Duration `yaml:"interval"`
+
+	// Epsilon is the privacy budget spent on a single window.
+	Epsilon float64 `yaml:"epsilon"`
+
+	// Threshold is the minimum noisy count of a domain for it to be uploaded.
+	Threshold uint64 `yaml:"threshold"`
+
+	// MaxContributions is the maximum number of requests of a single client
+	// counted within a window.
+	MaxContributions uint `yaml:"max_contributions"`
+
+	// MaxASNs is the maximum number of ASNs tracked within a window.
+	MaxASNs int `yaml:"max_asns"`
+
+	// MaxDomains is the maximum number of domains tracked for each country and
+	// ASN within a window.
+	MaxDomains int `yaml:"max_domains"`
+
+	// TopSize is the maximum number of domains uploaded for each country and
+	// ASN.
+	TopSize int `yaml:"top_size"`
+
+	// Enabled shows if the statistics are collected.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*popularDomainsConfig)(nil)
+
+// validate implements the [validator] interface for *popularDomainsConfig.  The
+// popular-domains configuration is optional.
+func (c *popularDomainsConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.Epsilon <= 0:
+		return fmt.Errorf("epsilon: %w: got %v", errors.ErrNotPositive, c.Epsilon)
+	case c.MaxContributions == 0:
+		return newNotPositiveError("max_contributions", c.MaxContributions)
+	case c.MaxASNs <= 0:
+		return newNotPositiveError("max_asns", c.MaxASNs)
+	case c.MaxDomains <= 0:
+		return newNotPositiveError("max_domains", c.MaxDomains)
+	case c.TopSize <= 0:
+		return newNotPositiveError("top_size", c.TopSize)
+	default:
+		return validatePositive("interval", c.Interval)
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/netutil"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// ptrConfig is the configuration of the synthesized PTR responses for the IP
+// ranges owned by the operator.
+type ptrConfig struct {
+	// Zones are the IP ranges for which PTR responses are synthesized.
+	Zones []*ptrZoneConfig `yaml:"zones"`
+}
+
+// type check
+var _ validator = (*ptrConfig)(nil)
+
+// validate implements the [validator] interface for *ptrConfig.  The PTR
+// configuration is optional.
+func (c *ptrConfig) validate() (err error) {
+	if c == nil {
+		return nil
+	}
+
+	for i, z := range c.Zones {
+		err = z.validate()
+		if err != nil {
+			return fmt.Errorf("zones: at index %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// toInternal returns the PTR zones for the DNS service.  c must be valid.
+func (c *ptrConfig) toInternal() (zones []*dnssvc.PTRZone) {
+	if c == nil {
+		return nil
+	}
+
+	zones = make([]*dnssvc.PTRZone, 0, len(c.Zones))
+	for _, z := range c.Zones {
+		zones = append(zones, &dnssvc.PTRZone{
+			Prefix:         z.Prefix,
+			Template:       z.Template,
+			DeviceTemplate: z.DeviceTemplate,
+			TTL:            z.TTL.Duration,
+		})
+	}
+
+	return zones
+}
+
+// ptrZoneConfig is the configuration of a single PTR zone.
+type ptrZoneConfig struct {
+	// Prefix is the IP range of the zone.
+	Prefix netip.Prefix `yaml:"prefix"`
+
+	// Template is the template of the domain names in the responses.
+	Template string `yaml:"template"`
+
+	// DeviceTemplate is the optional template of the domain names in the
+	// responses for the devices of the profile that makes the query.
+	DeviceTemplate string `yaml:"device_template"`
+
+	// TTL is the TTL of the synthesized records.
+	TTL timeutil.Duration `yaml:"ttl"`
+}
+
+// type check
+var _ validator = (*ptrZoneConfig)(nil)
+
+// validate implements the [validator] interface for *ptrZoneConfig.
+func (c *ptrZoneConfig) validate() (err error) {
+	switch {
+	case c == nil:
+		return errors.ErrNoValue
+	case !c.Prefix.IsValid():
+		return fmt.Errorf("prefix: %w", errors.ErrEmptyValue)
+	case c.Prefix.Masked() != c.Prefix:
+		return fmt.Errorf("prefix: %s has bits set outside of the mask", c.Prefix)
+	case c.TTL.Duration < 0:
+		return newNegativeError("ttl", c.TTL)
+	}
+
+	err = validatePTRTemplate(c.Template)
+	if err != nil {
+		return fmt.Errorf("template: %w", err)
+	}
+
+	if c.DeviceTemplate == "" {
+		return nil
+	}
+
+	if !strings.Contains(c.DeviceTemplate, dnssvc.PTRPlaceholderDeviceName) {
+		return fmt.Errorf(
+			"device_template: no placeholder %q",
+			dnssvc.PTRPlaceholderDeviceName,
+		)
+	}
+
+	err = validatePTRTemplate(c.DeviceTemplate)
+	if err != nil {
+		return fmt.Errorf("device_template: %w", err)
+	}
+
+	return nil
+}
+
+// validatePTRTemplate returns an error if tmpl doesn't produce valid domain
+// names.
+func validatePTRTemplate(tmpl string) (err error) {
+	if tmpl == "" {
+		return errors.ErrEmptyValue
+	}
+
+	// Use the longest possible IP label, the one of an expanded IPv6 address,
+	// and a device label of maximum length.
+	name := strings.ReplaceAll(tmpl, dnssvc.PTRPlaceholderIP, strings.Repeat("0", 39))
+	name = strings.ReplaceAll(
+		name,
+		dnssvc.PTRPlaceholderDeviceName,
+		strings.Repeat("a", netutil.MaxDomainLabelLen),
+	)
+
+	// Don't wrap the error, because it's informative enough as is.
+	return netutil.ValidateDomainName(name)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"log/slog"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnamelimit"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// qnameLimitConfig is the configuration of the limit of the unique names that a
+// single client of a server group may query under a single registrable domain,
+// which protects the upstreams from the random-subdomain attacks.
+type qnameLimitConfig struct {
+	// Window is the duration of the sliding window within which the unique
+	// names are counted.
+	Window timeutil.Duration `yaml:"window"`
+
+	// BlockDuration is the duration for which the requests of a client that
+	// has exceeded the limit for a domain are refused.
+	BlockDuration timeutil.Duration `yaml:"block_duration"`
+
+	// MaxUnique is the maximum number of unique names under a single
+	// registrable domain that a single client may query within the window.
+	MaxUnique uint `yaml:"max_unique"`
+
+	// MaxTracked is the maximum number of pairs of clients and registrable
+	// domains tracked at the same time.
+	MaxTracked int `yaml:"max_tracked"`
+
+	// Enabled shows if the limit is enforced.
+	Enabled bool `yaml:"enabled"`
+}
+
+// toInternal returns the configuration of the limiter.  c must be valid and
+// enabled.
+func (c *qnameLimitConfig) toInternal(
+	logger *slog.Logger,
+	mtrc qnamelimit.Metrics,
+) (conf *qnamelimit.DefaultConfig) {
+	return &qnamelimit.DefaultConfig{
+		Logger:        logger,
+		Clock:         agdtime.SystemClock{},
+		Metrics:       mtrc,
+		Window:        c.Window.Duration,
+		BlockDuration: c.BlockDuration.Duration,
+		MaxUnique:     c.MaxUnique,
+		MaxTracked:    c.MaxTracked,
+	}
+}
+
+// type check
+var _ validator = (*qnameLimitConfig)(nil)
+
+// validate implements the [validator] interface for *qnameLimitConfig.  The
+// unique-name limit configuration is optional.
+func (c *qnameLimitConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.MaxUnique == 0:
+		return newNotPositiveError("max_unique", c.MaxUnique)
+	case c.MaxTracked <= 0:
+		return newNotPositiveError("max_tracked", c.MaxTracked)
+	case c.BlockDuration.Duration <= 0:
+		return newNotPositiveError("block_duration", c.BlockDuration)
+	default:
+		return validatePositive("window", c.Window)
+	}
+}
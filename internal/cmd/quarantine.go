@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
+	"github.com/AdguardTeam/golibs/netutil"
+)
+
+// quarantineConfig is the configuration of the quarantine of the compromised
+// devices.
+type quarantineConfig struct {
+	// RemediationDomains are the domain names that the quarantined devices are
+	// allowed to resolve along with their subdomains.
+	RemediationDomains []string `yaml:"remediation_domains"`
+}
+
+// toInternal returns the quarantine of the devices.  c must be valid.
+func (c *quarantineConfig) toInternal(mtrc quarantine.Metrics) (q *quarantine.Default) {
+	var domains []string
+	if c != nil {
+		domains = make([]string, 0, len(c.RemediationDomains))
+		for _, d := range c.RemediationDomains {
+			domains = append(domains, strings.ToLower(strings.TrimSuffix(d, ".")))
+		}
+	}
+
+	return quarantine.NewDefault(&quarantine.DefaultConfig{
+		Metrics:            mtrc,
+		RemediationDomains: domains,
+	})
+}
+
+// type check
+var _ validator = (*quarantineConfig)(nil)
+
+// validate implements the [validator] interface for *quarantineConfig.  The
+// quarantine configuration is optional.
+func (c *quarantineConfig) validate() (err error) {
+	if c == nil {
+		return nil
+	}
+
+	for i, d := range c.RemediationDomains {
+		err = netutil.ValidateDomainName(strings.TrimSuffix(d, "."))
+		if err != nil {
+			return fmt.Errorf("remediation_domains: at index %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
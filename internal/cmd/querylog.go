@@ -2,14 +2,27 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnameredact"
+	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
 	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/c2h5oh/datasize"
 )
 
 // queryLogConfig is the query log configuration.
 type queryLogConfig struct {
-	// File contains the JSONL file query log configuration.
+	// File contains the file query log configuration.
 	File *queryLogFileConfig `yaml:"file"`
+
+	// Redaction is the optional configuration of the redaction of the domain
+	// names written into the query log and DNSDB.
+	Redaction *queryLogRedactionConfig `yaml:"redaction"`
 }
 
 // type check
@@ -23,11 +36,227 @@ func (c *queryLogConfig) validate() (err error) {
 	case c.File == nil:
 		return fmt.Errorf("file: %w", errors.ErrNoValue)
 	default:
-		return nil
+		// Go on.
 	}
+
+	return errors.Join(
+		validateProp("file", c.File.validate),
+		validateProp("redaction", c.Redaction.validate),
+	)
 }
 
-// queryLogFileConfig is the JSONL file query log configuration.
+// queryLogFileConfig is the file query log configuration.
 type queryLogFileConfig struct {
+	// Rotation is the configuration of the rotation of the protobuf query log
+	// files.  It must be set if Format is [querylog.FileFormatProtobuf].
+	Rotation *queryLogRotationConfig `yaml:"rotation"`
+
+	// Format is the format of the query log file.  If empty,
+	// [querylog.FileFormatJSONL] is used.
+	Format querylog.FileFormat `yaml:"format"`
+
+	// Enabled shows if the file query log is enabled.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*queryLogFileConfig)(nil)
+
+// validate implements the [validator] interface for *queryLogFileConfig.
+func (c *queryLogFileConfig) validate() (err error) {
+	switch {
+	case !c.Enabled, c.Format == "", c.Format == querylog.FileFormatJSONL:
+		return nil
+	case c.Format != querylog.FileFormatProtobuf:
+		// Don't wrap the error, because it's informative enough as is.
+		return c.Format.Validate()
+	default:
+		return validateProp("rotation", c.Rotation.validate)
+	}
+}
+
+// toInternal returns the file query log for the format from c.  c must be
+// enabled and valid.
+func (c *queryLogFileConfig) toInternal(
+	baseLogger *slog.Logger,
+	path string,
+	r *qnameredact.Redactor,
+) (l querylog.Interface) {
+	logger := baseLogger.With(slogutil.KeyPrefix, "querylog")
+
+	// #nosec G115 -- The Unix epoch time is highly unlikely to be negative.
+	seed := uint64(time.Now().UnixNano())
+
+	if c.Format != querylog.FileFormatProtobuf {
+		l = querylog.NewFileSystem(&querylog.FileSystemConfig{
+			Logger:   logger,
+			Path:     path,
+			RandSeed: seed,
+		})
+
+		return querylog.NewQNameRedacting(l, r)
+	}
+
+	rot := c.Rotation
+
+	l = querylog.NewProtobufFileSystem(&querylog.ProtobufFileSystemConfig{
+		Logger:     logger,
+		Clock:      agdtime.SystemClock{},
+		Path:       path,
+		RandSeed:   seed,
+		MaxSize:    rot.MaxSize,
+		MaxBackups: rot.MaxBackups,
+		Compress:   rot.Compress,
+	})
+
+	return querylog.NewQNameRedacting(l, r)
+}
+
+// queryLogRotationConfig is the configuration of the rotation of the protobuf
+// query log files.
+type queryLogRotationConfig struct {
+	// MaxSize is the size of the query log file after which it is rotated.
+	MaxSize datasize.ByteSize `yaml:"max_size"`
+
+	// MaxBackups is the number of the rotated files to keep.  Zero means that
+	// all rotated files are kept.
+	MaxBackups int `yaml:"max_backups"`
+
+	// Compress shows if the rotated files are compressed with gzip.
+	Compress bool `yaml:"compress"`
+}
+
+// type check
+var _ validator = (*queryLogRotationConfig)(nil)
+
+// validate implements the [validator] interface for *queryLogRotationConfig.
+func (c *queryLogRotationConfig) validate() (err error) {
+	switch {
+	case c == nil:
+		return errors.ErrNoValue
+	case c.MaxSize == 0:
+		return newNotPositiveError("max_size", c.MaxSize)
+	case c.MaxBackups < 0:
+		return newNegativeError("max_backups", c.MaxBackups)
+	default:
+		return nil
+	}
+}
+
+// queryLogRedactionConfig is the configuration of the redaction of the domain
+// names written into the query log and DNSDB.
+type queryLogRedactionConfig struct {
+	// Rules are the redaction rules.  The first matching rule is used.
+	Rules []*queryLogRedactionRuleConfig `yaml:"rules"`
+
+	// Enabled shows if the redaction is enabled.
 	Enabled bool `yaml:"enabled"`
 }
+
+// toInternal returns the redactor for the configuration.  r is nil if the
+// redaction is disabled or there are no rules.  c must be valid.
+func (c *queryLogRedactionConfig) toInternal() (r *qnameredact.Redactor) {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	rules := make([]*qnameredact.Rule, 0, len(c.Rules))
+	for _, rc := range c.Rules {
+		rules = append(rules, rc.toInternal())
+	}
+
+	return qnameredact.New(rules)
+}
+
+// type check
+var _ validator = (*queryLogRedactionConfig)(nil)
+
+// validate implements the [validator] interface for *queryLogRedactionConfig.
+// The redaction configuration is optional.
+func (c *queryLogRedactionConfig) validate() (err error) {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	var errs []error
+	for i, r := range c.Rules {
+		err = r.validate()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rules: at index %d: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// queryLogRedactionRuleConfig is the configuration of a single redaction rule.
+type queryLogRedactionRuleConfig struct {
+	// Action is the action performed on the sensitive part of the domain name.
+	Action qnameredact.Action `yaml:"action"`
+
+	// Regexp is the regular expression matching the sensitive part of the
+	// domain name.  It must not be set if Suffix is set.
+	Regexp string `yaml:"regexp"`
+
+	// Suffix is the domain name, the subdomains of which are redacted.  It must
+	// not be set if Regexp is set.
+	Suffix string `yaml:"suffix"`
+}
+
+// toInternal returns the redaction rule for the configuration.  c must be
+// valid.
+func (c *queryLogRedactionRuleConfig) toInternal() (r *qnameredact.Rule) {
+	r = &qnameredact.Rule{
+		Action: c.Action,
+		Suffix: normalizeRedactionSuffix(c.Suffix),
+	}
+
+	if c.Regexp != "" {
+		r.Regexp = regexp.MustCompile(c.Regexp)
+	}
+
+	return r
+}
+
+// normalizeRedactionSuffix returns the lowercased suffix without the leading
+// and trailing dots.
+func normalizeRedactionSuffix(suffix string) (norm string) {
+	return strings.ToLower(strings.Trim(suffix, "."))
+}
+
+// type check
+var _ validator = (*queryLogRedactionRuleConfig)(nil)
+
+// validate implements the [validator] interface for
+// *queryLogRedactionRuleConfig.
+func (c *queryLogRedactionRuleConfig) validate() (err error) {
+	if c == nil {
+		return errors.ErrNoValue
+	}
+
+	err = c.Action.Validate()
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	switch {
+	case c.Regexp == "" && c.Suffix == "":
+		return errors.Error("regexp or suffix must be set")
+	case c.Regexp != "" && c.Suffix != "":
+		return errors.Error("regexp and suffix must not be set together")
+	case c.Suffix != "":
+		if normalizeRedactionSuffix(c.Suffix) == "" {
+			return fmt.Errorf("suffix: %w", errors.ErrEmptyValue)
+		}
+
+		return nil
+	default:
+		_, err = regexp.Compile(c.Regexp)
+		if err != nil {
+			return fmt.Errorf("regexp: %w", err)
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quicadvisor"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/netutil"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// quicAdvisorConfig is the configuration of the advisor that stops advertising
+// the QUIC-based protocols to the client networks where QUIC seems to be
+// blocked.
+type quicAdvisorConfig struct {
+	// FailureWindow is the period within which FailureThreshold failed
+	// handshakes must happen for a network to be considered to block QUIC.
+	FailureWindow timeutil.Duration `yaml:"failure_window"`
+
+	// BlockDuration is the time for which the QUIC-based protocols aren't
+	// advertised to a network that has been considered to block QUIC.
+	BlockDuration timeutil.Duration `yaml:"block_duration"`
+
+	// CacheSize is the maximum number of client networks the state of which
+	// is kept.
+	CacheSize int `yaml:"cache_size"`
+
+	// FailureThreshold is the number of the failed handshakes after which a
+	// network is considered to block QUIC.
+	FailureThreshold uint `yaml:"failure_threshold"`
+
+	// IPv4SubnetKeyLen is the length of the subnet prefix used to group the
+	// IPv4 clients.
+	IPv4SubnetKeyLen int `yaml:"ipv4_subnet_key_len"`
+
+	// IPv6SubnetKeyLen is the length of the subnet prefix used to group the
+	// IPv6 clients.
+	IPv6SubnetKeyLen int `yaml:"ipv6_subnet_key_len"`
+
+	// Enabled shows if the advisor is enabled.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*quicAdvisorConfig)(nil)
+
+// validate implements the [validator] interface for *quicAdvisorConfig.  The
+// QUIC advisor configuration is optional.
+func (c *quicAdvisorConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.FailureWindow.Duration <= 0:
+		return newNotPositiveError("failure_window", c.FailureWindow)
+	case c.BlockDuration.Duration <= 0:
+		return newNotPositiveError("block_duration", c.BlockDuration)
+	case c.CacheSize <= 0:
+		return newNotPositiveError("cache_size", c.CacheSize)
+	case c.FailureThreshold == 0:
+		return newNotPositiveError("failure_threshold", c.FailureThreshold)
+	case c.IPv4SubnetKeyLen <= 0 || c.IPv4SubnetKeyLen > netutil.IPv4BitLen:
+		return fmt.Errorf(
+			"ipv4_subnet_key_len: %w: must be in range [1, %d]; got %d",
+			errors.ErrOutOfRange,
+			netutil.IPv4BitLen,
+			c.IPv4SubnetKeyLen,
+		)
+	case c.IPv6SubnetKeyLen <= 0 || c.IPv6SubnetKeyLen > netutil.IPv6BitLen:
+		return fmt.Errorf(
+			"ipv6_subnet_key_len: %w: must be in range [1, %d]; got %d",
+			errors.ErrOutOfRange,
+			netutil.IPv6BitLen,
+			c.IPv6SubnetKeyLen,
+		)
+	default:
+		return nil
+	}
+}
+
+// initQUICAdvisor initializes the advisor of the QUIC-based protocols.  If it
+// is disabled, the advisor does nothing.
+func (b *builder) initQUICAdvisor(ctx context.Context) (err error) {
+	c := b.conf.QUICAdvisor
+	if c == nil || !c.Enabled {
+		b.quicAdvisor = dnsserver.EmptyQUICAdvisor{}
+
+		b.logger.DebugContext(ctx, "quic advisor disabled")
+
+		return nil
+	}
+
+	mtrc, err := metrics.NewQUICAdvisor(b.mtrcNamespace, b.promRegisterer)
+	if err != nil {
+		return fmt.Errorf("registering quic advisor metrics: %w", err)
+	}
+
+	b.quicAdvisor = quicadvisor.New(&quicadvisor.Config{
+		Logger:           b.baseLogger.With(slogutil.KeyPrefix, "quicadvisor"),
+		Clock:            agdtime.SystemClock{},
+		Metrics:          mtrc,
+		FailureWindow:    c.FailureWindow.Duration,
+		BlockDuration:    c.BlockDuration.Duration,
+		CacheSize:        c.CacheSize,
+		FailureThreshold: c.FailureThreshold,
+		IPv4SubnetKeyLen: c.IPv4SubnetKeyLen,
+		IPv6SubnetKeyLen: c.IPv6SubnetKeyLen,
+	})
+
+	b.logger.DebugContext(ctx, "initialized quic advisor")
+
+	return nil
+}
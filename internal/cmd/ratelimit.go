@@ -4,8 +4,10 @@ import (
 	"cmp"
 	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/connlimiter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/ratelimit"
 	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
 	"github.com/AdguardTeam/golibs/errors"
@@ -23,6 +25,10 @@ const (
 
 // rateLimitConfig is the configuration of the instance's rate limiting.
 type rateLimitConfig struct {
+	// AccountLimit is the configuration of the enforcement of the aggregate
+	// limits of accounts.
+	AccountLimit *accountLimitConfig `yaml:"account_limit"`
+
 	// AllowList is the allowlist of clients.
 	Allowlist *allowListConfig `yaml:"allowlist"`
 
@@ -39,9 +45,17 @@ type rateLimitConfig struct {
 	// QUIC is the configuration of QUIC streams limiting.
 	QUIC *ratelimitQUICConfig `yaml:"quic"`
 
+	// SharedCounter is the configuration of the fleet-wide enforcement of the
+	// custom ratelimits of profiles.
+	SharedCounter *sharedCounterConfig `yaml:"shared_counter"`
+
 	// TCP is the configuration of TCP pipeline limiting.
 	TCP *ratelimitTCPConfig `yaml:"tcp"`
 
+	// XDPFilter is the configuration of dropping the packets from the subnets
+	// in the back off in the kernel.  If it is nil, the filter is disabled.
+	XDPFilter *xdpFilterConfig `yaml:"xdp_filter"`
+
 	// ResponseSizeEstimate is the estimate of the size of one DNS response for
 	// the purposes of rate limiting.  Responses over this estimate are counted
 	// as several responses.
@@ -72,6 +86,10 @@ type rateLimitOptions struct {
 	// Interval is the time during which to count the number of requests.
 	Interval timeutil.Duration `yaml:"interval"`
 
+	// Bandwidth is the maximum number of bytes of responses per second to
+	// a single subnet.  If it is zero, the bandwidth is not limited.
+	Bandwidth datasize.ByteSize `yaml:"bandwidth"`
+
 	// SubnetKeyLen is the length of the subnet prefix used to calculate
 	// rate limiter bucket keys.
 	SubnetKeyLen int `yaml:"subnet_key_len"`
@@ -94,18 +112,26 @@ func (o *rateLimitOptions) validate() (err error) {
 }
 
 // toInternal converts c to the rate limiting configuration for the DNS server.
-// c must be valid.
-func (c *rateLimitConfig) toInternal(al ratelimit.Allowlist) (conf *ratelimit.BackoffConfig) {
+// c must be valid.  pf may be nil.
+func (c *rateLimitConfig) toInternal(
+	al ratelimit.Allowlist,
+	pf netext.PacketFilter,
+	mtrc ratelimit.BackoffMetrics,
+) (conf *ratelimit.BackoffConfig) {
 	return &ratelimit.BackoffConfig{
 		Allowlist:            al,
+		Metrics:              mtrc,
+		PacketFilter:         pf,
 		ResponseSizeEstimate: c.ResponseSizeEstimate,
 		Duration:             c.BackoffDuration.Duration,
 		Period:               c.BackoffPeriod.Duration,
 		IPv4Count:            c.IPv4.Count,
 		IPv4Interval:         c.IPv4.Interval.Duration,
+		IPv4Bandwidth:        c.IPv4.Bandwidth,
 		IPv4SubnetKeyLen:     c.IPv4.SubnetKeyLen,
 		IPv6Count:            c.IPv6.Count,
 		IPv6Interval:         c.IPv6.Interval.Duration,
+		IPv6Bandwidth:        c.IPv6.Bandwidth,
 		IPv6SubnetKeyLen:     c.IPv6.SubnetKeyLen,
 		Count:                c.BackoffCount,
 		RefuseANY:            c.RefuseANY,
@@ -121,18 +147,78 @@ func (c *rateLimitConfig) validate() (err error) {
 		return errors.ErrNoValue
 	}
 
-	return cmp.Or(
+	err = cmp.Or(
+		validateProp("account_limit", c.AccountLimit.validate),
 		validateProp("allowlist", c.Allowlist.validate),
 		validateProp("connection_limit", c.ConnectionLimit.validate),
 		validateProp("ipv4", c.IPv4.validate),
 		validateProp("ipv6", c.IPv6.validate),
 		validateProp("quic", c.QUIC.validate),
+		validateProp("shared_counter", c.SharedCounter.validate),
 		validateProp("tcp", c.TCP.validate),
+		validateProp("xdp_filter", c.XDPFilter.validate),
 		validatePositive("backoff_count", c.BackoffCount),
 		validatePositive("backoff_duration", c.BackoffDuration),
 		validatePositive("backoff_period", c.BackoffPeriod),
 		validatePositive("response_size_estimate", c.ResponseSizeEstimate),
 	)
+	if err != nil {
+		return err
+	}
+
+	if c.AccountLimit.usesSharedCounter() && !c.SharedCounter.isEnabled() {
+		return errors.Error("account_limit: shared requires shared_counter")
+	}
+
+	return nil
+}
+
+// accountLimitConfig is the configuration of the enforcement of the aggregate
+// limits of accounts, which apply to all profiles of an account together.
+type accountLimitConfig struct {
+	// DeviceTTL is the duration after the last request of a device during
+	// which the device is counted as active.
+	DeviceTTL timeutil.Duration `yaml:"device_ttl"`
+
+	// RefreshIvl is the time between two removals of the inactive devices and
+	// accounts.
+	RefreshIvl timeutil.Duration `yaml:"refresh_interval"`
+
+	// Enabled, if true, enables the account limits.
+	Enabled bool `yaml:"enabled"`
+
+	// Shared, if true, makes the requests-per-second limits of accounts use the
+	// counters shared across all nodes.  It requires the shared counters to be
+	// enabled.
+	Shared bool `yaml:"shared"`
+}
+
+// type check
+var _ validator = (*accountLimitConfig)(nil)
+
+// validate implements the [validator] interface for *accountLimitConfig.
+func (c *accountLimitConfig) validate() (err error) {
+	switch {
+	case !c.isEnabled():
+		return nil
+	default:
+		return cmp.Or(
+			validatePositive("device_ttl", c.DeviceTTL),
+			validatePositive("refresh_interval", c.RefreshIvl),
+		)
+	}
+}
+
+// isEnabled returns true if the account limits are enabled.  A nil
+// *accountLimitConfig means that they are disabled.
+func (c *accountLimitConfig) isEnabled() (ok bool) {
+	return c != nil && c.Enabled
+}
+
+// usesSharedCounter returns true if the account limits require the shared
+// counters.  c must be valid.
+func (c *accountLimitConfig) usesSharedCounter() (ok bool) {
+	return c.isEnabled() && c.Shared
 }
 
 // allowListConfig is the consul allow list configuration.
@@ -227,6 +313,41 @@ func (c *connLimitConfig) validate() (err error) {
 	}
 }
 
+// sharedCounterConfig is the configuration of the request counters shared
+// across all nodes, which are used to enforce the custom ratelimits of profiles
+// fleet-wide.  The counters are stored in Redis.
+type sharedCounterConfig struct {
+	// RefreshIvl is the time between two synchronizations of the counters with
+	// Redis.  The longer it is, the more requests over the limit can pass.
+	RefreshIvl timeutil.Duration `yaml:"refresh_interval"`
+
+	// Enabled, if true, enables the shared counters.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*sharedCounterConfig)(nil)
+
+// validate implements the [validator] interface for *sharedCounterConfig.
+func (c *sharedCounterConfig) validate() (err error) {
+	switch {
+	case !c.isEnabled():
+		return nil
+	case c.RefreshIvl.Duration >= time.Second:
+		// The counters count requests per second, so longer intervals make
+		// them pointless.
+		return fmt.Errorf("refresh_interval: %w: must be less than 1s", errors.ErrOutOfRange)
+	default:
+		return validatePositive("refresh_interval", c.RefreshIvl)
+	}
+}
+
+// isEnabled returns true if the shared counters are enabled.  A nil
+// *sharedCounterConfig means that they are disabled.
+func (c *sharedCounterConfig) isEnabled() (ok bool) {
+	return c != nil && c.Enabled
+}
+
 // ratelimitTCPConfig is the configuration of TCP pipeline limiting.
 type ratelimitTCPConfig struct {
 	// MaxPipelineCount is the maximum number of simultaneously processing TCP
@@ -270,3 +391,39 @@ func (c *ratelimitQUICConfig) validate() (err error) {
 
 	return validatePositive("max_streams_per_peer", c.MaxStreamsPerPeer)
 }
+
+// xdpFilterConfig is the configuration of the XDP filter, which drops the
+// packets from the subnets in the back off in the kernel.  The XDP program and
+// its maps must be loaded and pinned beforehand, see scripts/xdpfilter.
+type xdpFilterConfig struct {
+	// BansMapPath is the path to the pinned map with the banned subnets.
+	BansMapPath string `yaml:"bans_map_path"`
+
+	// DropsMapPath is the path to the pinned map with the counter of the
+	// dropped packets.
+	DropsMapPath string `yaml:"drops_map_path"`
+
+	// RefreshIvl is the interval between two updates of the metrics from the
+	// counter of the dropped packets.
+	RefreshIvl timeutil.Duration `yaml:"refresh_interval"`
+
+	// Enabled, if true, enables the XDP filter.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*xdpFilterConfig)(nil)
+
+// validate implements the [validator] interface for *xdpFilterConfig.
+func (c *xdpFilterConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.BansMapPath == "":
+		return fmt.Errorf("bans_map_path: %w", errors.ErrEmptyValue)
+	case c.DropsMapPath == "":
+		return fmt.Errorf("drops_map_path: %w", errors.ErrEmptyValue)
+	default:
+		return validatePositive("refresh_interval", c.RefreshIvl)
+	}
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// requestLogConfig is the configuration of the structured per-request debug
+// log.
+type requestLogConfig struct {
+	// SampleRate is the share of the requests that are logged regardless of
+	// the targets set using the debug HTTP API.
+	SampleRate float64 `yaml:"sample_rate"`
+
+	// Enabled shows if the per-request debug log is enabled.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*requestLogConfig)(nil)
+
+// validate implements the [validator] interface for *requestLogConfig.  The
+// request-log configuration is optional.
+func (c *requestLogConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.SampleRate < 0 || c.SampleRate > 1:
+		return fmt.Errorf(
+			"sample_rate: %w: must be within [0, 1], got %v",
+			errors.ErrOutOfRange,
+			c.SampleRate,
+		)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// responseJitterConfig is the configuration of the randomization of the
+// synthesized responses of a server group, which makes it harder to detect the
+// filtering settings of the clients by probing the caches of shared
+// forwarders.
+type responseJitterConfig struct {
+	// TTL is the maximum duration randomly subtracted from the TTL of the
+	// filtered responses.
+	TTL timeutil.Duration `yaml:"ttl"`
+
+	// SOASerial shows if the SOA records of the synthesized negative responses
+	// have random serial numbers.
+	SOASerial bool `yaml:"soa_serial"`
+
+	// Enabled shows if the responses are randomized.
+	Enabled bool `yaml:"enabled"`
+}
+
+// toInternal returns the randomization configuration of the responses.  conf is
+// nil if the randomization is disabled.  c must be valid.
+func (c *responseJitterConfig) toInternal() (conf *dnsmsg.JitterConfig) {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	return &dnsmsg.JitterConfig{
+		TTL:       c.TTL.Duration,
+		SOASerial: c.SOASerial,
+	}
+}
+
+// type check
+var _ validator = (*responseJitterConfig)(nil)
+
+// validate implements the [validator] interface for *responseJitterConfig.  The
+// response jitter configuration is optional.
+func (c *responseJitterConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.TTL.Duration < 0:
+		return newNegativeError("ttl", c.TTL)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+package cmd
+
+// responsePaddingConfig is the configuration of the EDNS(0) padding of the
+// responses of a server group over DoT and DoH, which makes the traffic
+// analysis of the encrypted DNS harder.  See RFC 7830 and RFC 8467.
+type responsePaddingConfig struct {
+	// BlockSize is the block size to which the responses to the padded queries
+	// are padded.  RFC 8467 recommends 468 bytes.
+	BlockSize uint16 `yaml:"block_size"`
+
+	// Enabled shows if the Block-Length Padding strategy is used.  If it is
+	// false, the responses to the padded queries are padded randomly.
+	Enabled bool `yaml:"enabled"`
+}
+
+// toInternal returns the block size of the padding of the responses.  blockSize
+// is zero if the block-length padding is disabled.  c must be valid.
+func (c *responsePaddingConfig) toInternal() (blockSize uint16) {
+	if c == nil || !c.Enabled {
+		return 0
+	}
+
+	return c.BlockSize
+}
+
+// type check
+var _ validator = (*responsePaddingConfig)(nil)
+
+// validate implements the [validator] interface for *responsePaddingConfig.
+// The response padding configuration is optional.
+func (c *responsePaddingConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.BlockSize == 0:
+		return newNotPositiveError("block_size", c.BlockSize)
+	default:
+		return nil
+	}
+}
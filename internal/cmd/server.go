@@ -9,6 +9,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/bindtodevice"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
 	"github.com/AdguardTeam/AdGuardDNS/internal/tlsconfig"
 	"github.com/AdguardTeam/golibs/container"
 	"github.com/AdguardTeam/golibs/errors"
@@ -21,6 +22,7 @@ func (srvs servers) toInternal(
 	tlsMgr tlsconfig.Manager,
 	ratelimitConf *rateLimitConfig,
 	dnsConf *dnsConfig,
+	netMtrc netext.Metrics,
 	deviceDomains []string,
 ) (dnsSrvs []*agd.Server, err error) {
 	dnsSrvs = make([]*agd.Server, 0, len(srvs))
@@ -37,9 +39,14 @@ func (srvs servers) toInternal(
 			ReadTimeout:     dnsConf.ReadTimeout.Duration,
 			WriteTimeout:    dnsConf.WriteTimeout.Duration,
 			LinkedIPEnabled: srv.LinkedIPEnabled,
+			StrictSNI:       srv.StrictSNI,
 			Protocol:        srv.Protocol.toInternal(),
 		}
 
+		if srv.Network != nil {
+			_, dnsSrv.ControlConf = srv.Network.toInternal(netMtrc)
+		}
+
 		tcpConf := &agd.TCPConfig{
 			IdleTimeout:        dnsConf.TCPIdleTimeout.Duration,
 			MaxPipelineCount:   ratelimitConf.TCP.MaxPipelineCount,
@@ -54,6 +61,9 @@ func (srvs servers) toInternal(
 				// [dnsConfig.validate].
 				MaxRespSize: uint16(dnsConf.MaxUDPResponseSize.Bytes()),
 			}
+
+			udpConf := dnsSrv.UDPConf
+			udpConf.TruncationPolicy, udpConf.TruncateUnknownClients = srv.UDP.toInternal()
 		case agd.ProtoDNSCrypt:
 			var dcConf *agd.DNSCryptConfig
 			dcConf, err = srv.DNSCrypt.toInternal()
@@ -70,6 +80,10 @@ func (srvs servers) toInternal(
 			}
 
 			dnsSrv.TLS = newTLSConfig(dnsSrv, tlsMgr, deviceDomains, srv)
+			dnsSrv.WebSocketConf = srv.WebSocket.toInternal()
+			dnsSrv.TLSFingerprintConf = srv.TLSFingerprint.toInternal()
+			dnsSrv.HTTPLimitsConf = srv.HTTPLimits.toInternal()
+			dnsSrv.HTTPStreamsConf = srv.HTTPStreams.toInternal()
 		}
 
 		dnsSrv.SetBindData(bindData)
@@ -202,6 +216,27 @@ type server struct {
 	// DNSCrypt are the DNSCrypt settings for this server, if any.
 	DNSCrypt *dnsCryptConfig `yaml:"dnscrypt"`
 
+	// WebSocket are the DNS-over-WebSocket settings for this server, if any.
+	WebSocket *webSocketConfig `yaml:"websocket"`
+
+	// HTTPLimits are the DoH limits settings for this server, if any.
+	HTTPLimits *httpLimitsConfig `yaml:"http_limits"`
+
+	// HTTPStreams are the DoH HTTP/2 and HTTP/3 stream settings for this
+	// server, if any.
+	HTTPStreams *httpStreamsConfig `yaml:"http_streams"`
+
+	// TLSFingerprint are the TLS client fingerprinting settings for this
+	// server, if any.
+	TLSFingerprint *tlsFingerprintConfig `yaml:"tls_fingerprint"`
+
+	// UDP are the settings of the responses over UDP for this server, if any.
+	UDP *udpConfig `yaml:"udp"`
+
+	// Network are the network settings for this server, which override the
+	// global ones, if any.  It must not be set if BindInterfaces is set.
+	Network *network `yaml:"network"`
+
 	// Name is the unique name of the server.
 	Name string `yaml:"name"`
 
@@ -219,6 +254,11 @@ type server struct {
 	// LinkedIPEnabled shows if the linked IP addresses should be used to detect
 	// profiles on this server.
 	LinkedIPEnabled bool `yaml:"linked_ip_enabled"`
+
+	// StrictSNI, if true, makes the server reject connections the TLS server
+	// names of which match a device domain but point to no known and
+	// authenticated device.  It must only be set for protocol tls.
+	StrictSNI bool `yaml:"strict_sni"`
 }
 
 // bindData returns the socket binding data for this server.
@@ -292,7 +332,48 @@ func (s *server) validate() (err error) {
 		return fmt.Errorf("dnscrypt: %w", err)
 	}
 
-	return nil
+	err = s.WebSocket.validate(s.Protocol)
+	if err != nil {
+		return fmt.Errorf("websocket: %w", err)
+	}
+
+	err = s.TLSFingerprint.validate(s.Protocol)
+	if err != nil {
+		return fmt.Errorf("tls_fingerprint: %w", err)
+	}
+
+	err = s.HTTPLimits.validate(s.Protocol)
+	if err != nil {
+		return fmt.Errorf("http_limits: %w", err)
+	}
+
+	err = s.HTTPStreams.validate(s.Protocol)
+	if err != nil {
+		return fmt.Errorf("http_streams: %w", err)
+	}
+
+	err = s.UDP.validate(s.Protocol)
+	if err != nil {
+		return fmt.Errorf("udp: %w", err)
+	}
+
+	if s.StrictSNI && s.Protocol != srvProtoTLS {
+		return fmt.Errorf("strict_sni: protocol %s does not support strict sni", s.Protocol)
+	}
+
+	return s.validateNetwork()
+}
+
+// validateNetwork returns an error if the server's network settings aren't
+// valid.
+func (s *server) validateNetwork() (err error) {
+	if s.Network == nil {
+		return nil
+	} else if len(s.BindInterfaces) > 0 {
+		return errors.Error("network: not supported with bind_interfaces")
+	}
+
+	return validateProp("network", s.Network.validate)
 }
 
 // validateBindData returns an error if the server's binding data aren't valid.
@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/bindtodevice"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
 	"github.com/AdguardTeam/AdGuardDNS/internal/tlsconfig"
 	"github.com/AdguardTeam/golibs/container"
 	"github.com/AdguardTeam/golibs/errors"
@@ -26,6 +28,7 @@ func (srvGrps serverGroups) toInternal(
 	fltGrps map[agd.FilteringGroupID]*agd.FilteringGroup,
 	ratelimitConf *rateLimitConfig,
 	dnsConf *dnsConfig,
+	netMtrc netext.Metrics,
 ) (svcSrvGrps []*agd.ServerGroup, err error) {
 	svcSrvGrps = make([]*agd.ServerGroup, len(srvGrps))
 	for i, g := range srvGrps {
@@ -41,12 +44,29 @@ func (srvGrps serverGroups) toInternal(
 			return nil, fmt.Errorf("tls %q: %w", g.Name, err)
 		}
 
+		var verdictKey []byte
+		verdictKey, err = g.FilterVerdict.toInternal()
+		if err != nil {
+			return nil, fmt.Errorf("server group %q: filter_verdict: %w", g.Name, err)
+		}
+
 		svcSrvGrps[i] = &agd.ServerGroup{
-			DDR:             g.DDR.toInternal(messages),
-			DeviceDomains:   deviceDomains,
-			Name:            agd.ServerGroupName(g.Name),
-			FilteringGroup:  fltGrpID,
-			ProfilesEnabled: g.ProfilesEnabled,
+			DDR:                   g.DDR.toInternal(messages),
+			DeviceDomains:         deviceDomains,
+			Name:                  agd.ServerGroupName(g.Name),
+			FilteringGroup:        fltGrpID,
+			FilterVerdictKey:      verdictKey,
+			ResponseJitter:        g.ResponseJitter.toInternal(),
+			AggressiveNSECEnabled: g.AggressiveNSECEnabled,
+			LocalZonesEnabled:     g.LocalZonesEnabled,
+			ProfilesEnabled:       g.ProfilesEnabled,
+
+			ResponsePaddingBlockSize: g.ResponsePadding.toInternal(),
+		}
+
+		svcSrvGrps[i].Views, err = g.Views.toInternal(fltGrps)
+		if err != nil {
+			return nil, fmt.Errorf("server group %q: %w", g.Name, err)
 		}
 
 		svcSrvGrps[i].Servers, err = g.Servers.toInternal(
@@ -54,6 +74,7 @@ func (srvGrps serverGroups) toInternal(
 			tlsMgr,
 			ratelimitConf,
 			dnsConf,
+			netMtrc,
 			deviceDomains,
 		)
 		if err != nil {
@@ -74,6 +95,7 @@ func (srvGrps serverGroups) validate() (err error) {
 	}
 
 	names := container.NewMapSet[string]()
+	viewNames := container.NewMapSet[string]()
 	for i, g := range srvGrps {
 		err = g.validate()
 		if err != nil {
@@ -85,9 +107,27 @@ func (srvGrps serverGroups) validate() (err error) {
 		}
 
 		names.Add(g.Name)
+
+		// The names of the views must be unique across all server groups,
+		// since they are used to partition the cache and to select the
+		// upstreams.
+		for j, v := range g.Views {
+			if viewNames.Has(v.Name) {
+				return fmt.Errorf(
+					"at index %d: views: at index %d: name: %w: %q",
+					i,
+					j,
+					errors.ErrDuplicated,
+					v.Name,
+				)
+			}
+
+			viewNames.Add(v.Name)
+		}
 	}
 
-	return nil
+	// Don't wrap the error, because it's informative enough as is.
+	return srvGrps.validateDnstapPaths()
 }
 
 // serverGroup defines a group of DNS servers all of which use the same
@@ -103,6 +143,30 @@ type serverGroup struct {
 	// TLS are the TLS settings for this server, if any.
 	TLS *tlsConfig `yaml:"tls"`
 
+	// Maintenance is the optional maintenance-mode configuration of this
+	// server group.
+	Maintenance *maintenanceConfig `yaml:"maintenance"`
+
+	// FilterVerdict is the optional configuration of the signed filtering
+	// verdicts added to the responses for the trusted downstream resolvers.
+	FilterVerdict *filterVerdictConfig `yaml:"filter_verdict"`
+
+	// ResponseJitter is the optional configuration of the randomization of the
+	// synthesized responses.
+	ResponseJitter *responseJitterConfig `yaml:"response_jitter"`
+
+	// ResponsePadding is the optional configuration of the padding of the
+	// responses over the encrypted protocols.
+	ResponsePadding *responsePaddingConfig `yaml:"response_padding"`
+
+	// Dnstap is the optional configuration of the dnstap output of the queries
+	// and responses of this server group.
+	Dnstap *dnstapConfig `yaml:"dnstap"`
+
+	// QNAMELimit is the optional configuration of the limit of the unique names
+	// queried by a single client under a single registrable domain.
+	QNAMELimit *qnameLimitConfig `yaml:"qname_limit"`
+
 	// Name is the unique name of the server group.
 	Name string `yaml:"name"`
 
@@ -112,6 +176,19 @@ type serverGroup struct {
 	// Servers are the settings for servers.
 	Servers servers `yaml:"servers"`
 
+	// Views are the optional views of this server group.  The first view
+	// matching the client is used.
+	Views views `yaml:"views"`
+
+	// AggressiveNSECEnabled, if true, enables the synthesis of negative
+	// responses from the cached NSEC and NSEC3 records for this server group.
+	AggressiveNSECEnabled bool `yaml:"aggressive_nsec_enabled"`
+
+	// LocalZonesEnabled, if true, makes the queries for the locally-served
+	// zones be answered locally instead of being forwarded upstream for this
+	// server group.
+	LocalZonesEnabled bool `yaml:"local_zones_enabled"`
+
 	// ProfilesEnabled, if true, enables recognition of user devices and
 	// profiles for this server group.
 	ProfilesEnabled bool `yaml:"profiles_enabled"`
@@ -146,7 +223,20 @@ func (g *serverGroup) validate() (err error) {
 		return fmt.Errorf("tls: %w", err)
 	}
 
-	return nil
+	err = validateProp("maintenance", g.Maintenance.validate)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	return cmp.Or(
+		validateProp("dnstap", g.Dnstap.validate),
+		validateProp("filter_verdict", g.FilterVerdict.validate),
+		validateProp("qname_limit", g.QNAMELimit.validate),
+		validateProp("response_jitter", g.ResponseJitter.validate),
+		validateProp("response_padding", g.ResponsePadding.validate),
+		validateProp("views", g.Views.validate),
+	)
 }
 
 // collectSessTicketPaths returns the list of unique session ticket file paths
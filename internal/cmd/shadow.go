@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/forward"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/service"
+)
+
+// shadowConfig is the configuration of the request shadowing, which mirrors a
+// share of the queries sent upstream to the secondary upstream servers without
+// serving their responses.
+type shadowConfig struct {
+	// Servers are the secondary upstream servers to which the queries are
+	// mirrored.
+	Servers []*upstreamServerConfig `yaml:"servers"`
+
+	// Percentage is the percentage of the queries sent upstream that are
+	// mirrored.
+	Percentage float64 `yaml:"percentage"`
+
+	// MaxInFlight is the maximum number of mirrored queries processed at the
+	// same time.
+	MaxInFlight int `yaml:"max_in_flight"`
+
+	// Enabled shows if the request shadowing is enabled.
+	Enabled bool `yaml:"enabled"`
+}
+
+// toInternal converts c to the request-shadowing configuration for the DNS
+// service.  h is the forwarding handler used in shadowConf; it must be closed
+// on shutdown.  If c is nil or disabled, shadowConf and h are nil.  c must be
+// valid.
+func (c *shadowConfig) toInternal(
+	logger *slog.Logger,
+) (shadowConf *dnssvc.ShadowConfig, h *forward.Handler) {
+	if c == nil || !c.Enabled {
+		return nil, nil
+	}
+
+	h = forward.NewHandler(&forward.HandlerConfig{
+		Logger:             logger.With(slogutil.KeyPrefix, "shadow_forward"),
+		UpstreamsAddresses: toUpstreamConfigs(c.Servers),
+	})
+
+	return &dnssvc.ShadowConfig{
+		Handler:     h,
+		Ratio:       c.Percentage / 100,
+		MaxInFlight: c.MaxInFlight,
+	}, h
+}
+
+// shadowCloser is a [service.Interface] that closes the shadow forwarding
+// handler on shutdown.
+type shadowCloser struct {
+	handler *forward.Handler
+}
+
+// type check
+var _ service.Interface = shadowCloser{}
+
+// Start implements the [service.Interface] interface for shadowCloser.
+func (shadowCloser) Start(_ context.Context) (err error) { return nil }
+
+// Shutdown implements the [service.Interface] interface for shadowCloser.
+func (c shadowCloser) Shutdown(_ context.Context) (err error) {
+	err = c.handler.Close()
+	if err != nil {
+		return fmt.Errorf("closing shadow forward handler: %w", err)
+	}
+
+	return nil
+}
+
+// type check
+var _ validator = (*shadowConfig)(nil)
+
+// validate implements the [validator] interface for *shadowConfig.  The request
+// shadowing configuration is optional.
+func (c *shadowConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case len(c.Servers) == 0:
+		return fmt.Errorf("servers: %w", errors.ErrEmptyValue)
+	case c.Percentage <= 0 || c.Percentage > 100:
+		return fmt.Errorf(
+			"percentage: %w: must be greater than 0 and not greater than 100; got %v",
+			errors.ErrOutOfRange,
+			c.Percentage,
+		)
+	case c.MaxInFlight <= 0:
+		return newNotPositiveError("max_in_flight", c.MaxInFlight)
+	}
+
+	for i, s := range c.Servers {
+		if err = s.validate(); err != nil {
+			return fmt.Errorf("servers: at index %d: %w", i, err)
+		} else if s.hasHostname() {
+			return fmt.Errorf("servers: at index %d: hostnames are not supported", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc"
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// specialUseConfig is the configuration of the handling of the queries for the
+// special-use domain names, such as localhost., that must not leak to the
+// upstreams.
+type specialUseConfig struct {
+	// Local is the action for the queries for .local and its subdomains.
+	Local leakAction `yaml:"local"`
+
+	// Onion is the action for the queries for .onion and its subdomains.
+	Onion leakAction `yaml:"onion"`
+
+	// Localhost, if true, makes AdGuard DNS answer the queries for localhost.
+	// and its subdomains with the loopback addresses.
+	Localhost bool `yaml:"localhost"`
+
+	// Enabled shows if the queries for the special-use domain names are
+	// handled by AdGuard DNS.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*specialUseConfig)(nil)
+
+// validate implements the [validator] interface for *specialUseConfig.  The
+// special-use domain names configuration is optional.
+func (c *specialUseConfig) validate() (err error) {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	err = c.Local.validate()
+	if err != nil {
+		return fmt.Errorf("local: %w", err)
+	}
+
+	err = c.Onion.validate()
+	if err != nil {
+		return fmt.Errorf("onion: %w", err)
+	}
+
+	return nil
+}
+
+// toInternal returns the configuration of the handling of the queries for the
+// special-use domain names for the DNS service.  c must be valid.
+func (c *specialUseConfig) toInternal() (conf *dnssvc.LeakConfig) {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	return &dnssvc.LeakConfig{
+		Local:     c.Local.toInternal(),
+		Onion:     c.Onion.toInternal(),
+		Localhost: c.Localhost,
+	}
+}
+
+// leakAction is the type for the actions on the queries for the special-use
+// domain names in the on-disk configuration.
+type leakAction string
+
+// Valid leak action values in the on-disk configuration file.
+const (
+	leakActionForward  leakAction = "forward"
+	leakActionNXDomain leakAction = "nxdomain"
+	leakActionRefused  leakAction = "refused"
+)
+
+// toInternal returns the equivalent dnssvc.LeakAction value.  a must be valid.
+func (a leakAction) toInternal() (act dnssvc.LeakAction) {
+	switch a {
+	case leakActionNXDomain:
+		return dnssvc.LeakActionNXDomain
+	case leakActionRefused:
+		return dnssvc.LeakActionRefused
+	default:
+		return dnssvc.LeakActionForward
+	}
+}
+
+// type check
+var _ validator = leakAction("")
+
+// validate implements the [validator] interface for leakAction.
+func (a leakAction) validate() (err error) {
+	switch a {
+	case
+		"",
+		leakActionForward,
+		leakActionNXDomain,
+		leakActionRefused:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", errors.ErrBadEnumValue, a)
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// staticZonesConfig is the configuration of the static answers loaded from the
+// operator-defined zone files.
+type staticZonesConfig struct {
+	// Files are the paths to the zone files in the RFC 1035 format.
+	Files []string `yaml:"files"`
+
+	// Enabled shows if the static answers are served.
+	Enabled bool `yaml:"enabled"`
+
+	// RequireZONEMD, if true, makes the zone files without verifiable ZONEMD
+	// records to be refused.
+	RequireZONEMD bool `yaml:"require_zonemd"`
+}
+
+// type check
+var _ validator = (*staticZonesConfig)(nil)
+
+// validate implements the [validator] interface for *staticZonesConfig.  The
+// static zones configuration is optional.
+func (c *staticZonesConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case len(c.Files) == 0:
+		return fmt.Errorf("files: %w", errors.ErrEmptyValue)
+	}
+
+	for i, f := range c.Files {
+		if f == "" {
+			return fmt.Errorf("files: at index %d: %w", i, errors.ErrEmptyValue)
+		}
+	}
+
+	return nil
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/tlsconfig"
 	"github.com/AdguardTeam/golibs/container"
 	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/netutil"
+	"golang.org/x/net/publicsuffix"
 )
 
 // tlsConfig are the TLS settings of a DNS server, if any.
@@ -86,18 +88,47 @@ func (c *tlsConfig) validate(needsTLS bool) (err error) {
 }
 
 // validateDeviceIDWildcards returns an error if the device ID domain wildcards
-// are invalid.
+// are invalid.  The error contains all invalid wildcards.
 func validateDeviceIDWildcards(wildcards []string) (err error) {
+	var errs []error
 	s := container.NewMapSet[string]()
 	for i, w := range wildcards {
 		// TODO(e.burkov):  Consider removing this requirement.
 		if !strings.HasPrefix(w, "*.") {
-			return fmt.Errorf("at index %d: not a wildcard: %q", i, w)
+			errs = append(errs, fmt.Errorf("at index %d: not a wildcard: %q", i, w))
+
+			continue
 		} else if s.Has(w) {
-			return fmt.Errorf("at index %d: wildcard: %w: %q", i, errors.ErrDuplicated, w)
+			err = fmt.Errorf("at index %d: wildcard: %w: %q", i, errors.ErrDuplicated, w)
+			errs = append(errs, err)
+
+			continue
 		}
 
 		s.Add(w)
+
+		err = validateWildcardSuffix(w[2:])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("at index %d: wildcard %q: %w", i, w, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateWildcardSuffix returns an error if the domain of a wildcard, that is
+// the part after "*.", is not a valid hostname or is a public suffix, such as
+// "co.uk", since such a wildcard would cover the domains of unrelated parties.
+func validateWildcardSuffix(domain string) (err error) {
+	err = netutil.ValidateHostname(domain)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	pubSuf, _ := publicsuffix.PublicSuffix(strings.ToLower(domain))
+	if pubSuf == strings.ToLower(domain) {
+		return fmt.Errorf("%q is a public suffix", domain)
 	}
 
 	return nil
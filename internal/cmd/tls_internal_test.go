@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/AdguardTeam/golibs/testutil"
+)
+
+func TestValidateWildcardSuffix(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		domain     string
+		wantErrMsg string
+	}{{
+		name:       "valid",
+		domain:     "dns.example.com",
+		wantErrMsg: "",
+	}, {
+		name:       "valid_registrable",
+		domain:     "example.co.uk",
+		wantErrMsg: "",
+	}, {
+		name:       "tld",
+		domain:     "com",
+		wantErrMsg: `"com" is a public suffix`,
+	}, {
+		name:       "public_suffix",
+		domain:     "co.uk",
+		wantErrMsg: `"co.uk" is a public suffix`,
+	}, {
+		name:       "public_suffix_upper",
+		domain:     "CO.UK",
+		wantErrMsg: `"CO.UK" is a public suffix`,
+	}, {
+		name:       "empty",
+		domain:     "",
+		wantErrMsg: `bad hostname "": hostname is empty`,
+	}, {
+		name:   "bad_hostname",
+		domain: "bad..example",
+		wantErrMsg: `bad hostname "bad..example": bad hostname label "": ` +
+			`hostname label is empty`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateWildcardSuffix(tc.domain)
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, err)
+		})
+	}
+}
+
+func TestValidateDeviceIDWildcards(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		wantErrMsg string
+		wildcards  []string
+	}{{
+		name:       "valid",
+		wantErrMsg: "",
+		wildcards:  []string{"*.d.dns.example", "*.d.dns.example.co.uk"},
+	}, {
+		name:       "empty",
+		wantErrMsg: "",
+		wildcards:  nil,
+	}, {
+		name: "all_errors",
+		wantErrMsg: `at index 0: not a wildcard: "d.dns.example"` + "\n" +
+			`at index 1: wildcard "*.co.uk": "co.uk" is a public suffix` + "\n" +
+			`at index 3: wildcard: duplicated value: "*.dns.example"` + "\n" +
+			`at index 4: wildcard "*.com": "com" is a public suffix`,
+		wildcards: []string{
+			"d.dns.example",
+			"*.co.uk",
+			"*.dns.example",
+			"*.dns.example",
+			"*.com",
+		},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validateDeviceIDWildcards(tc.wildcards)
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, err)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// tlsFingerprintConfig is the configuration of the TLS client fingerprinting of
+// a DoT or DoH server.
+type tlsFingerprintConfig struct {
+	// Enabled, if true, enables the calculation of the JA3 and JA4
+	// fingerprints of the TLS clients.
+	Enabled bool `yaml:"enabled"`
+
+	// KeepRaw, if true, makes the server keep the raw fingerprints along with
+	// the hashed ones.  It should only be enabled for debugging.
+	KeepRaw bool `yaml:"keep_raw"`
+}
+
+// toInternal converts c to the TLS fingerprinting configuration for a DNS
+// server.  c must be valid.  conf is nil if the fingerprinting is disabled.
+func (c *tlsFingerprintConfig) toInternal() (conf *agd.TLSFingerprintConfig) {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	return &agd.TLSFingerprintConfig{
+		KeepRaw: c.KeepRaw,
+	}
+}
+
+// validate returns an error if the TLS fingerprinting configuration is invalid
+// for the given protocol.
+func (c *tlsFingerprintConfig) validate(p serverProto) (err error) {
+	if c == nil {
+		// No TLS fingerprinting settings, which is normal.
+		return nil
+	} else if p != srvProtoHTTPS && p != srvProtoTLS {
+		return fmt.Errorf("protocol %s does not support tls fingerprinting", p)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import "github.com/AdguardTeam/golibs/timeutil"
+
+// topProfilesConfig is the configuration of the statistics of the profiles
+// with the most requests, errors, and blocked requests served by the debug
+// HTTP API.
+type topProfilesConfig struct {
+	// Window is the duration of the window within which the statistics are
+	// collected.
+	Window timeutil.Duration `yaml:"window"`
+
+	// Size is the maximum number of profiles in each of the top lists.
+	Size int `yaml:"size"`
+
+	// Enabled shows if the statistics are collected.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*topProfilesConfig)(nil)
+
+// validate implements the [validator] interface for *topProfilesConfig.  The
+// top-profiles configuration is optional.
+func (c *topProfilesConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.Size <= 0:
+		return newNotPositiveError("size", c.Size)
+	default:
+		return validatePositive("window", c.Window)
+	}
+}
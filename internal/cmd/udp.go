@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// udpConfig is the configuration of the responses over UDP of a plain DNS
+// server.
+type udpConfig struct {
+	// TruncationPolicy is the policy of truncating DNS responses over UDP.  If
+	// it is empty, [udpTruncationPolicyEDNS] is used.
+	TruncationPolicy udpTruncationPolicy `yaml:"truncation_policy"`
+
+	// TruncateUnknownClients, if true, makes the server truncate responses
+	// larger than 512 bytes to the clients, which aren't associated with any
+	// profile, to make them retry over TCP.
+	TruncateUnknownClients bool `yaml:"truncate_unknown_clients"`
+}
+
+// toInternal converts c to the truncation settings of a DNS server.  c must be
+// valid.
+func (c *udpConfig) toInternal() (
+	policy dnsserver.UDPTruncationPolicy,
+	truncateUnknown bool,
+) {
+	if c == nil {
+		return dnsserver.UDPTruncationEDNS, false
+	}
+
+	return c.TruncationPolicy.toInternal(), c.TruncateUnknownClients
+}
+
+// validate returns an error if the UDP configuration is invalid for the given
+// protocol.
+func (c *udpConfig) validate(p serverProto) (err error) {
+	if c == nil {
+		// No UDP settings, which is normal.
+		return nil
+	} else if p != srvProtoDNS {
+		return fmt.Errorf("protocol %s does not support udp settings", p)
+	}
+
+	err = c.TruncationPolicy.validate()
+	if err != nil {
+		return fmt.Errorf("truncation_policy: %w", err)
+	}
+
+	return nil
+}
+
+// udpTruncationPolicy is the type for the UDP truncation policies in the
+// on-disk configuration.
+type udpTruncationPolicy string
+
+// Valid UDP truncation policy values in the on-disk configuration file.
+const (
+	udpTruncationPolicyClamp   udpTruncationPolicy = "clamp"
+	udpTruncationPolicyEDNS    udpTruncationPolicy = "edns"
+	udpTruncationPolicyMinimal udpTruncationPolicy = "minimal"
+)
+
+// toInternal returns the equivalent dnsserver.UDPTruncationPolicy value.  p
+// must be valid.
+func (p udpTruncationPolicy) toInternal() (policy dnsserver.UDPTruncationPolicy) {
+	switch p {
+	case udpTruncationPolicyClamp:
+		return dnsserver.UDPTruncationClamp
+	case udpTruncationPolicyMinimal:
+		return dnsserver.UDPTruncationMinimal
+	default:
+		return dnsserver.UDPTruncationEDNS
+	}
+}
+
+// type check
+var _ validator = udpTruncationPolicy("")
+
+// validate implements the [validator] interface for udpTruncationPolicy.
+func (p udpTruncationPolicy) validate() (err error) {
+	switch p {
+	case
+		"",
+		udpTruncationPolicyClamp,
+		udpTruncationPolicyEDNS,
+		udpTruncationPolicyMinimal:
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", errors.ErrBadEnumValue, p)
+	}
+}
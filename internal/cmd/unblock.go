@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
+	"github.com/AdguardTeam/AdGuardDNS/internal/websvc"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// unblockConfig is the configuration of the temporary exceptions, which allow
+// the domain names blocked for a profile or a device after a confirmation on
+// the block page.
+type unblockConfig struct {
+	// KeyPath is the path to the file with the key used to verify the unblock
+	// tokens.
+	KeyPath string `yaml:"key_path"`
+
+	// MaxDuration is the maximum duration of a temporary exception.
+	MaxDuration timeutil.Duration `yaml:"max_duration"`
+
+	// MaxPerProfile is the maximum number of temporary exceptions kept for a
+	// single profile.
+	MaxPerProfile int `yaml:"max_per_profile"`
+
+	// Enabled shows if the temporary exceptions are enabled.
+	Enabled bool `yaml:"enabled"`
+}
+
+// toInternal returns the storage of the temporary exceptions as well as the
+// configuration of the temporary-unblocking API of the web service.  webConf is
+// nil if the exceptions are disabled.  c must be valid.
+func (c *unblockConfig) toInternal() (
+	strg unblock.Storage,
+	webConf *websvc.UnblockConfig,
+	err error,
+) {
+	if c == nil || !c.Enabled {
+		return unblock.EmptyStorage{}, nil, nil
+	}
+
+	key, err := os.ReadFile(c.KeyPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading key: %w", err)
+	}
+
+	if len(key) < unblock.MinKeyLen {
+		return nil, nil, fmt.Errorf(
+			"key in %q: got %d bytes, want at least %d",
+			c.KeyPath,
+			len(key),
+			unblock.MinKeyLen,
+		)
+	}
+
+	clock := agdtime.SystemClock{}
+	strg = unblock.NewDefaultStorage(&unblock.DefaultStorageConfig{
+		Clock:         clock,
+		MaxPerProfile: c.MaxPerProfile,
+	})
+
+	return strg, &websvc.UnblockConfig{
+		Clock:       clock,
+		Storage:     strg,
+		Redeemed:    unblock.NewRedeemedTokens(),
+		Key:         key,
+		MaxDuration: c.MaxDuration.Duration,
+	}, nil
+}
+
+// type check
+var _ validator = (*unblockConfig)(nil)
+
+// validate implements the [validator] interface for *unblockConfig.  The
+// temporary-exception configuration is optional.
+func (c *unblockConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.KeyPath == "":
+		return fmt.Errorf("key_path: %w", errors.ErrEmptyValue)
+	case c.MaxDuration.Duration <= 0:
+		return newNotPositiveError("max_duration", c.MaxDuration)
+	case c.MaxPerProfile <= 0:
+		return newNotPositiveError("max_per_profile", c.MaxPerProfile)
+	default:
+		return nil
+	}
+}
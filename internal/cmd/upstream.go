@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"net/netip"
 	"net/url"
+	"slices"
 	"strings"
 	"time"
 
@@ -16,8 +17,10 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/AdguardTeam/golibs/service"
 	"github.com/AdguardTeam/golibs/timeutil"
+	"github.com/miekg/dns"
 )
 
 // upstreamConfig is the upstream module configuration.
@@ -28,9 +31,50 @@ type upstreamConfig struct {
 	// Fallback is the configuration for the upstream fallback servers.
 	Fallback *upstreamFallbackConfig `yaml:"fallback"`
 
+	// Bootstrap is the optional configuration of the resolvers used to resolve
+	// the hostnames of the upstream servers.  It must be set if any of the
+	// servers has a hostname.
+	Bootstrap *upstreamBootstrapConfig `yaml:"bootstrap"`
+
+	// HappyEyeballs is the optional configuration of the Happy Eyeballs
+	// algorithm for the upstream servers with additional addresses.
+	HappyEyeballs *upstreamHappyEyeballsConfig `yaml:"happy_eyeballs"`
+
+	// CircuitBreaker is the optional configuration of the circuit breaker of
+	// the main upstream servers.
+	CircuitBreaker *upstreamCircuitBreakerConfig `yaml:"circuit_breaker"`
+
+	// Discovery is the optional configuration of the discovery of the main
+	// upstream servers from a DNS zone.  If enabled, Servers are only used
+	// until the first successful discovery.
+	Discovery *upstreamDiscoveryConfig `yaml:"discovery"`
+
+	// NAT64Prefix is the optional NAT64 prefix used to reach the upstreams
+	// with IPv4 addresses from IPv6-only nodes.
+	NAT64Prefix netip.Prefix `yaml:"nat64_prefix"`
+
 	// Servers is a list of the upstream servers configurations we use to
 	// forward DNS queries.
 	Servers []*upstreamServerConfig `yaml:"servers"`
+
+	// Groups are the optional named groups of upstream servers tried in order
+	// when the main upstream servers fail and before the fallback ones.
+	Groups upstreamGroups `yaml:"groups"`
+
+	// PreferIPv6, if true, makes AdGuard DNS use the main upstreams with IPv6
+	// addresses as long as any of them are up.
+	PreferIPv6 bool `yaml:"prefer_ipv6"`
+
+	// LoopDetection, if true, makes AdGuard DNS mark the forwarded queries and
+	// refuse the ones that the upstreams send back to it.
+	LoopDetection bool `yaml:"loop_detection"`
+
+	// TrustedDNSSEC, if true, means that all upstream servers are
+	// DNSSEC-validating resolvers and the network paths to them can't be
+	// tampered with, so that the AD bit in their responses can be trusted.  It
+	// must be true if any server group has aggressive NSEC caching enabled,
+	// since the signatures of the cached records aren't validated.
+	TrustedDNSSEC bool `yaml:"trusted_dnssec"`
 }
 
 // toInternal converts c to the data storage configuration for the DNS server.
@@ -43,22 +87,35 @@ func (c *upstreamConfig) toInternal(logger *slog.Logger) (fwdConf *forward.Handl
 	fallbackConfs := toUpstreamConfigs(fallbacks)
 	metricsListener := prometheus.NewForwardMetricsListener(
 		metrics.Namespace(),
-		len(upstreams)+len(fallbacks),
+		len(upstreams)+len(fallbacks)+c.Groups.serversNum(),
 	)
 
+	hc := c.Healthcheck
+
 	var hcInit time.Duration
-	if c.Healthcheck.Enabled {
-		hcInit = c.Healthcheck.Timeout.Duration
+	if hc.Enabled {
+		hcInit = hc.refreshTimeout()
 	}
 
 	fwdConf = &forward.HandlerConfig{
 		Logger:                     logger.With(slogutil.KeyPrefix, "forward"),
 		MetricsListener:            metricsListener,
-		HealthcheckDomainTmpl:      c.Healthcheck.DomainTmpl,
+		Bootstrap:                  c.Bootstrap.toInternal(),
+		HealthcheckDomainTmpl:      hc.DomainTmpl,
+		HealthcheckProbes:          hc.toInternalProbes(),
 		UpstreamsAddresses:         upsConfs,
 		FallbackAddresses:          fallbackConfs,
-		HealthcheckBackoffDuration: c.Healthcheck.BackoffDuration.Duration,
+		UpstreamGroups:             c.Groups.toInternal(),
+		CircuitBreaker:             c.CircuitBreaker.toInternal(),
+		HealthcheckBackoffDuration: hc.BackoffDuration.Duration,
 		HealthcheckInitDuration:    hcInit,
+		HealthcheckRecheckInterval: hc.RecheckInterval.Duration,
+		HealthcheckFallThreshold:   hc.FallThreshold,
+		HealthcheckRiseThreshold:   hc.RiseThreshold,
+		HappyEyeballs:              c.HappyEyeballs.toInternal(),
+		NAT64Prefix:                c.NAT64Prefix,
+		PreferIPv6:                 c.PreferIPv6,
+		LoopDetection:              c.LoopDetection,
 	}
 
 	return fwdConf
@@ -82,14 +139,153 @@ func (c *upstreamConfig) validate() (err error) {
 		}
 	}
 
-	return cmp.Or(
+	if c.NAT64Prefix != (netip.Prefix{}) {
+		err = forward.ValidateNAT64Prefix(c.NAT64Prefix)
+		if err != nil {
+			return fmt.Errorf("nat64_prefix: %w", err)
+		}
+	}
+
+	err = cmp.Or(
+		validateProp("bootstrap", c.Bootstrap.validate),
+		validateProp("circuit_breaker", c.CircuitBreaker.validate),
+		validateProp("discovery", c.Discovery.validate),
 		validateProp("fallback", c.Fallback.validate),
+		validateProp("groups", c.Groups.validate),
+		validateProp("happy_eyeballs", c.HappyEyeballs.validate),
 		validateProp("healthcheck", c.Healthcheck.validate),
 	)
+	if err != nil {
+		return err
+	}
+
+	return c.validateBootstrapNeeded()
+}
+
+// validateBootstrapNeeded returns an error if any of the servers, including the
+// fallback ones, has a hostname or if the discovery is enabled while the
+// bootstrap is not configured.  c and its servers must be valid.
+func (c *upstreamConfig) validateBootstrapNeeded() (err error) {
+	if c.Bootstrap != nil {
+		return nil
+	}
+
+	if c.Discovery != nil && c.Discovery.Enabled {
+		return fmt.Errorf("discovery: bootstrap: %w", errors.ErrNoValue)
+	}
+
+	if i := slices.IndexFunc(c.Servers, (*upstreamServerConfig).hasHostname); i >= 0 {
+		return fmt.Errorf("servers: at index %d: bootstrap: %w", i, errors.ErrNoValue)
+	}
+
+	i := slices.IndexFunc(c.Fallback.Servers, (*upstreamServerConfig).hasHostname)
+	if i >= 0 {
+		return fmt.Errorf("fallback: servers: at index %d: bootstrap: %w", i, errors.ErrNoValue)
+	}
+
+	return c.Groups.validateBootstrapNeeded()
+}
+
+// upstreamBootstrapConfig is the configuration of the resolvers used to resolve
+// the hostnames of the upstream servers.
+type upstreamBootstrapConfig struct {
+	// Servers are the addresses of the plain-DNS resolvers.  They are tried in
+	// order.
+	Servers []netip.AddrPort `yaml:"servers"`
+
+	// Timeout is the timeout of a single query to a resolver.
+	Timeout timeutil.Duration `yaml:"timeout"`
+}
+
+// type check
+var _ validator = (*upstreamBootstrapConfig)(nil)
+
+// validate implements the [validator] interface for *upstreamBootstrapConfig.
+func (c *upstreamBootstrapConfig) validate() (err error) {
+	switch {
+	case c == nil:
+		return nil
+	case len(c.Servers) == 0:
+		return fmt.Errorf("servers: %w", errors.ErrEmptyValue)
+	case c.Timeout.Duration <= 0:
+		return newNotPositiveError("timeout", c.Timeout)
+	}
+
+	for i, s := range c.Servers {
+		if !s.IsValid() {
+			return fmt.Errorf("servers: at index %d: %w", i, errors.ErrNoValue)
+		}
+	}
+
+	return nil
+}
+
+// toInternal returns the bootstrap configuration for the forwarding handler.  c
+// must be valid.
+func (c *upstreamBootstrapConfig) toInternal() (conf *forward.BootstrapConfig) {
+	if c == nil {
+		return nil
+	}
+
+	return &forward.BootstrapConfig{
+		Resolvers: c.Servers,
+		Timeout:   c.Timeout.Duration,
+	}
+}
+
+// upstreamHappyEyeballsConfig is the configuration of the Happy Eyeballs
+// algorithm used to connect to the upstream servers with several addresses.
+type upstreamHappyEyeballsConfig struct {
+	// ConnectionAttemptDelay is the delay after which the connection attempt
+	// to the next address is started.  Zero means the default value.
+	ConnectionAttemptDelay timeutil.Duration `yaml:"connection_attempt_delay"`
+
+	// FailureCacheDuration is the duration for which a failed address is
+	// tried after the other ones.  Zero means the default value.
+	FailureCacheDuration timeutil.Duration `yaml:"failure_cache_duration"`
+
+	// PreferIPv4, if true, makes AdGuard DNS try the IPv4 addresses of the
+	// upstream servers before the IPv6 ones.
+	PreferIPv4 bool `yaml:"prefer_ipv4"`
+}
+
+// type check
+var _ validator = (*upstreamHappyEyeballsConfig)(nil)
+
+// validate implements the [validator] interface for
+// *upstreamHappyEyeballsConfig.
+func (c *upstreamHappyEyeballsConfig) validate() (err error) {
+	switch {
+	case c == nil:
+		return nil
+	case c.ConnectionAttemptDelay.Duration < 0:
+		return newNegativeError("connection_attempt_delay", c.ConnectionAttemptDelay)
+	case c.FailureCacheDuration.Duration < 0:
+		return newNegativeError("failure_cache_duration", c.FailureCacheDuration)
+	default:
+		return nil
+	}
+}
+
+// toInternal returns the Happy Eyeballs configuration for the forwarding
+// handler.  c must be valid.
+func (c *upstreamHappyEyeballsConfig) toInternal() (conf *forward.HappyEyeballsConfig) {
+	if c == nil {
+		return nil
+	}
+
+	return &forward.HappyEyeballsConfig{
+		ConnectionAttemptDelay: c.ConnectionAttemptDelay.Duration,
+		FailureCacheDuration:   c.FailureCacheDuration.Duration,
+		PreferIPv4:             c.PreferIPv4,
+	}
 }
 
-// splitUpstreamURL separates server url to net protocol and port address.
-func splitUpstreamURL(raw string) (upsNet forward.Network, addrPort netip.AddrPort, err error) {
+// splitUpstreamURL separates server url to net protocol and port address.  If
+// the host of the url is a hostname, addrPort only contains the port.
+func splitUpstreamURL(
+	raw string,
+) (upsNet forward.Network, addrPort netip.AddrPort, hostname string, err error) {
 	addr := raw
 	upsNet = forward.NetworkAny
 
@@ -97,7 +293,7 @@ func splitUpstreamURL(raw string) (upsNet forward.Network, addrPort netip.AddrPo
 		var u *url.URL
 		u, err = url.Parse(raw)
 		if err != nil {
-			return upsNet, addrPort, fmt.Errorf("bad server url: %q: %w", raw, err)
+			return upsNet, addrPort, "", fmt.Errorf("bad server url: %q: %w", raw, err)
 		}
 
 		addr = u.Host
@@ -108,23 +304,33 @@ func splitUpstreamURL(raw string) (upsNet forward.Network, addrPort netip.AddrPo
 			// Go on.
 			break
 		default:
-			return upsNet, addrPort, fmt.Errorf("bad server protocol: %q", u.Scheme)
+			return upsNet, addrPort, "", fmt.Errorf("bad server protocol: %q", u.Scheme)
 		}
 	}
 
-	if addrPort, err = netip.ParseAddrPort(addr); err != nil {
-		return upsNet, addrPort, fmt.Errorf("bad server address: %q", addr)
+	if addrPort, err = netip.ParseAddrPort(addr); err == nil {
+		return upsNet, addrPort, "", nil
 	}
 
-	return upsNet, addrPort, nil
+	hostname, port, err := netutil.SplitHostPort(addr)
+	if err != nil || netutil.ValidateHostname(hostname) != nil {
+		return upsNet, addrPort, "", fmt.Errorf("bad server address: %q", addr)
+	}
+
+	return upsNet, netip.AddrPortFrom(netip.Addr{}, port), hostname, nil
 }
 
 // upstreamHealthcheckConfig is the configuration for the upstream healthcheck
 // feature.
 type upstreamHealthcheckConfig struct {
-	// DomainTmpl is the interval of upstream healthcheck probes.
+	// DomainTmpl is the template of the domain name of the default
+	// healthcheck probe.  It is only used when Probes is empty.
 	DomainTmpl string `yaml:"domain_template"`
 
+	// Probes are the optional healthcheck queries.  An upstream passes a
+	// healthcheck if all of them succeed.
+	Probes []*upstreamHealthcheckProbeConfig `yaml:"probes"`
+
 	// Interval is the interval of upstream healthcheck probes.
 	Interval timeutil.Duration `yaml:"interval"`
 
@@ -137,8 +343,24 @@ type upstreamHealthcheckConfig struct {
 	// failed check advances the backoff.
 	BackoffDuration timeutil.Duration `yaml:"backoff_duration"`
 
+	// RecheckInterval is the optional delay before rechecking an upstream that
+	// has failed a healthcheck but isn't considered down yet.
+	RecheckInterval timeutil.Duration `yaml:"recheck_interval"`
+
+	// FallThreshold is the number of consecutive failed healthchecks after
+	// which an upstream is considered down.  Zero means one.
+	FallThreshold uint `yaml:"fall_threshold"`
+
+	// RiseThreshold is the number of consecutive successful healthchecks after
+	// which an upstream that is down is considered up again.  Zero means one.
+	RiseThreshold uint `yaml:"rise_threshold"`
+
 	// Enabled shows if upstream healthcheck is enabled.
 	Enabled bool `yaml:"enabled"`
+
+	// Jitter, if true, makes AdGuard DNS delay each healthcheck by a random
+	// duration of up to 10 % of Interval.
+	Jitter bool `yaml:"jitter"`
 }
 
 // type check
@@ -151,7 +373,7 @@ func (c *upstreamHealthcheckConfig) validate() (err error) {
 		return errors.ErrNoValue
 	case !c.Enabled:
 		return nil
-	case c.DomainTmpl == "":
+	case c.DomainTmpl == "" && len(c.Probes) == 0:
 		return fmt.Errorf("domain_template: %w", errors.ErrEmptyValue)
 	case c.Interval.Duration <= 0:
 		return newNotPositiveError("interval", c.Interval)
@@ -159,11 +381,91 @@ func (c *upstreamHealthcheckConfig) validate() (err error) {
 		return newNotPositiveError("timeout", c.Timeout)
 	case c.BackoffDuration.Duration <= 0:
 		return newNotPositiveError("backoff_duration", c.BackoffDuration)
+	case c.RecheckInterval.Duration < 0:
+		return newNegativeError("recheck_interval", c.RecheckInterval)
+	}
+
+	for i, p := range c.Probes {
+		err = p.validate()
+		if err != nil {
+			return fmt.Errorf("probes: at index %d: %w", i, err)
+		}
 	}
 
 	return nil
 }
 
+// toInternalProbes returns the healthcheck probes for the forwarding handler.
+// c must be valid.
+func (c *upstreamHealthcheckConfig) toInternalProbes() (probes []*forward.HealthcheckProbe) {
+	if len(c.Probes) == 0 {
+		return nil
+	}
+
+	probes = make([]*forward.HealthcheckProbe, 0, len(c.Probes))
+	for _, p := range c.Probes {
+		probes = append(probes, &forward.HealthcheckProbe{
+			DomainTmpl: p.DomainTmpl,
+			Network:    p.Network,
+			QType:      dns.StringToType[strings.ToUpper(p.Type)],
+		})
+	}
+
+	return probes
+}
+
+// refreshTimeout returns the timeout of a single healthcheck refresh including
+// the rechecks, if any.  c must be valid.
+func (c *upstreamHealthcheckConfig) refreshTimeout() (d time.Duration) {
+	d = c.Timeout.Duration
+	if c.RecheckInterval.Duration > 0 && c.FallThreshold > 1 {
+		rechecks := time.Duration(c.FallThreshold - 1)
+		d += rechecks * (c.Timeout.Duration + c.RecheckInterval.Duration)
+	}
+
+	return d
+}
+
+// upstreamHealthcheckProbeConfig is the configuration of a single upstream
+// healthcheck query.
+type upstreamHealthcheckProbeConfig struct {
+	// DomainTmpl is the template of the domain name to query.
+	DomainTmpl string `yaml:"domain_template"`
+
+	// Type is the type of the query, for example "A" or "AAAA".
+	Type string `yaml:"type"`
+
+	// Network is the optional network over which the query is sent.
+	Network forward.Network `yaml:"network"`
+}
+
+// type check
+var _ validator = (*upstreamHealthcheckProbeConfig)(nil)
+
+// validate implements the [validator] interface for
+// *upstreamHealthcheckProbeConfig.
+func (c *upstreamHealthcheckProbeConfig) validate() (err error) {
+	switch {
+	case c == nil:
+		return errors.ErrNoValue
+	case c.DomainTmpl == "":
+		return fmt.Errorf("domain_template: %w", errors.ErrEmptyValue)
+	case c.Type == "":
+		return fmt.Errorf("type: %w", errors.ErrEmptyValue)
+	}
+
+	if _, ok := dns.StringToType[strings.ToUpper(c.Type)]; !ok {
+		return fmt.Errorf("type: %w: %q", errors.ErrBadEnumValue, c.Type)
+	}
+
+	switch c.Network {
+	case forward.NetworkAny, forward.NetworkTCP, forward.NetworkUDP:
+		return nil
+	default:
+		return fmt.Errorf("network: %w: %q", errors.ErrBadEnumValue, c.Network)
+	}
+}
+
 // newUpstreamHealthcheck returns refresher worker service that performs
 // upstream healthchecks.  conf must be valid.
 func newUpstreamHealthcheck(
@@ -179,12 +481,12 @@ func newUpstreamHealthcheck(
 	const prefix = "upstream_healthcheck_refresh"
 	refrLogger := logger.With(slogutil.KeyPrefix, prefix)
 	return agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
-		Context:           newCtxWithTimeoutCons(conf.Healthcheck.Timeout.Duration),
+		Context:           newCtxWithTimeoutCons(conf.Healthcheck.refreshTimeout()),
 		Refresher:         agdservice.NewRefresherWithErrColl(handler, refrLogger, errColl, prefix),
 		Logger:            refrLogger,
 		Interval:          conf.Healthcheck.Interval.Duration,
 		RefreshOnShutdown: false,
-		RandomizeStart:    false,
+		RandomizeStart:    conf.Healthcheck.Jitter,
 	})
 }
 
@@ -219,10 +521,16 @@ func (c *upstreamFallbackConfig) validate() (err error) {
 
 // upstreamServerConfig is the configuration for the upstream server.
 type upstreamServerConfig struct {
-	// Address is the url of the DNS server in the `[scheme://]ip:port`
-	// format.
+	// Address is the url of the DNS server in the `[scheme://]ip:port` or
+	// `[scheme://]hostname:port` format.  Hostnames are resolved using the
+	// bootstrap resolvers.
 	Address string `yaml:"address"`
 
+	// AdditionalAddresses are the optional other addresses of the same server,
+	// typically of the other IP family, in the `ip:port` format.  If set,
+	// AdGuard DNS uses the Happy Eyeballs algorithm to connect to the server.
+	AdditionalAddresses []string `yaml:"additional_addresses"`
+
 	// Timeout is the timeout for DNS requests.
 	Timeout timeutil.Duration `yaml:"timeout"`
 }
@@ -239,27 +547,57 @@ func (c *upstreamServerConfig) validate() (err error) {
 		return newNotPositiveError("timeout", c.Timeout)
 	}
 
-	_, _, err = splitUpstreamURL(c.Address)
+	_, _, hostname, err := splitUpstreamURL(c.Address)
 	if err != nil {
 		return fmt.Errorf("invalid addr: %s", c.Address)
+	} else if hostname != "" && len(c.AdditionalAddresses) > 0 {
+		return errors.Error("additional_addresses: not supported for hostnames")
+	}
+
+	for i, a := range c.AdditionalAddresses {
+		_, err = netip.ParseAddrPort(a)
+		if err != nil {
+			return fmt.Errorf("additional_addresses: at index %d: %w", i, err)
+		}
 	}
 
 	return nil
 }
 
+// hasHostname returns true if the address of the server is a hostname.  c must
+// be valid.
+func (c *upstreamServerConfig) hasHostname() (ok bool) {
+	_, _, hostname, _ := splitUpstreamURL(c.Address)
+
+	return hostname != ""
+}
+
 // toUpstreamConfigs converts confs to the list of upstream configurations.
 // confs must be valid.
 func toUpstreamConfigs(confs []*upstreamServerConfig) (upsConfs []*forward.UpstreamPlainConfig) {
 	upsConfs = make([]*forward.UpstreamPlainConfig, 0, len(confs))
 	for _, c := range confs {
-		net, addrPort, _ := splitUpstreamURL(c.Address)
+		net, addrPort, hostname, _ := splitUpstreamURL(c.Address)
+
+		var addAddrs []netip.AddrPort
+		for _, a := range c.AdditionalAddresses {
+			addAddrs = append(addAddrs, netip.MustParseAddrPort(a))
+		}
 
 		upsConfs = append(upsConfs, &forward.UpstreamPlainConfig{
-			Network: net,
-			Address: addrPort,
-			Timeout: c.Timeout.Duration,
+			Network:             net,
+			Address:             addrPort,
+			Hostname:            hostname,
+			AdditionalAddresses: addAddrs,
+			Timeout:             c.Timeout.Duration,
 		})
 	}
 
 	return upsConfs
 }
+
+// hasGroups returns true if c has a circuit breaker for the main upstream
+// servers or any upstream groups.  c must be valid.
+func (c *upstreamConfig) hasGroups() (ok bool) {
+	return len(c.Groups) > 0 || (c.CircuitBreaker != nil && c.CircuitBreaker.Enabled)
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/forward"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/netutil"
+	"github.com/AdguardTeam/golibs/service"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// upstreamDiscoveryConfig is the configuration of the discovery of the main
+// upstream servers using the SRV or SVCB records of a DNS zone.
+type upstreamDiscoveryConfig struct {
+	// Type is the type of the records used for discovery.
+	Type forward.DiscoveryType `yaml:"type"`
+
+	// Zone is the domain name of the zone containing the records.
+	Zone string `yaml:"zone"`
+
+	// Interval is the interval between the discovery refreshes.
+	Interval timeutil.Duration `yaml:"interval"`
+
+	// Timeout is the timeout of a single discovery refresh.
+	Timeout timeutil.Duration `yaml:"timeout"`
+
+	// ServerTimeout is the timeout for DNS requests to the discovered servers.
+	ServerTimeout timeutil.Duration `yaml:"server_timeout"`
+
+	// Enabled shows if the discovery is used.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*upstreamDiscoveryConfig)(nil)
+
+// validate implements the [validator] interface for *upstreamDiscoveryConfig.
+// The discovery configuration is optional.
+func (c *upstreamDiscoveryConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.Interval.Duration <= 0:
+		return newNotPositiveError("interval", c.Interval)
+	case c.Timeout.Duration <= 0:
+		return newNotPositiveError("timeout", c.Timeout)
+	case c.ServerTimeout.Duration <= 0:
+		return newNotPositiveError("server_timeout", c.ServerTimeout)
+	}
+
+	err = c.Type.Validate()
+	if err != nil {
+		// Don't wrap the error, since it's informative enough as is.
+		return err
+	}
+
+	err = netutil.ValidateDomainName(strings.TrimSuffix(c.Zone, "."))
+	if err != nil {
+		return fmt.Errorf("zone: %w", err)
+	}
+
+	return nil
+}
+
+// newUpstreamDiscovery returns refresher worker service that discovers the
+// main upstreams of handler.  conf must be valid.
+func newUpstreamDiscovery(
+	logger *slog.Logger,
+	handler *forward.Handler,
+	conf *upstreamConfig,
+	errColl errcoll.Interface,
+) (refr service.Interface, err error) {
+	c := conf.Discovery
+	if c == nil || !c.Enabled {
+		return service.Empty{}, nil
+	}
+
+	const prefix = "upstream_discovery_refresh"
+	refrLogger := logger.With(slogutil.KeyPrefix, prefix)
+
+	d, err := forward.NewDiscovery(&forward.DiscoveryConfig{
+		Logger:          refrLogger,
+		Handler:         handler,
+		Type:            c.Type,
+		Zone:            c.Zone,
+		Network:         forward.NetworkAny,
+		UpstreamTimeout: c.ServerTimeout.Duration,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating upstream discovery: %w", err)
+	}
+
+	return agdservice.NewRefreshWorker(&agdservice.RefreshWorkerConfig{
+		Context:           newCtxWithTimeoutCons(c.Timeout.Duration),
+		Refresher:         agdservice.NewRefresherWithErrColl(d, refrLogger, errColl, prefix),
+		Logger:            refrLogger,
+		Interval:          c.Interval.Duration,
+		RefreshOnShutdown: false,
+		RandomizeStart:    false,
+	}), nil
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/forward"
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// upstreamCircuitBreakerConfig is the configuration of the circuit breaker of
+// a group of upstream servers.
+type upstreamCircuitBreakerConfig struct {
+	// FailureWindow is the duration within which the failures are counted.
+	FailureWindow timeutil.Duration `yaml:"failure_window"`
+
+	// OpenDuration is the duration for which the group is skipped before a
+	// probe query is sent to it.
+	OpenDuration timeutil.Duration `yaml:"open_duration"`
+
+	// FailureThreshold is the number of failures within FailureWindow after
+	// which the group is skipped.
+	FailureThreshold uint `yaml:"failure_threshold"`
+
+	// Enabled shows if the circuit breaker is used.
+	Enabled bool `yaml:"enabled"`
+}
+
+// toInternal returns the circuit breaker configuration for the forwarding
+// handler.  conf is nil if the circuit breaker is disabled.  c must be valid.
+func (c *upstreamCircuitBreakerConfig) toInternal() (conf *forward.CircuitBreakerConfig) {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	return &forward.CircuitBreakerConfig{
+		FailureWindow:    c.FailureWindow.Duration,
+		OpenDuration:     c.OpenDuration.Duration,
+		FailureThreshold: c.FailureThreshold,
+	}
+}
+
+// type check
+var _ validator = (*upstreamCircuitBreakerConfig)(nil)
+
+// validate implements the [validator] interface for
+// *upstreamCircuitBreakerConfig.  The circuit breaker configuration is
+// optional.
+func (c *upstreamCircuitBreakerConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.FailureWindow.Duration <= 0:
+		return newNotPositiveError("failure_window", c.FailureWindow)
+	case c.OpenDuration.Duration <= 0:
+		return newNotPositiveError("open_duration", c.OpenDuration)
+	case c.FailureThreshold == 0:
+		return newNotPositiveError("failure_threshold", c.FailureThreshold)
+	default:
+		return nil
+	}
+}
+
+// upstreamGroupConfig is the configuration of a named group of upstream
+// servers.
+type upstreamGroupConfig struct {
+	// CircuitBreaker is the optional configuration of the circuit breaker of
+	// the group.
+	CircuitBreaker *upstreamCircuitBreakerConfig `yaml:"circuit_breaker"`
+
+	// Name is the unique name of the group.
+	Name string `yaml:"name"`
+
+	// Servers are the upstream servers of the group.
+	Servers []*upstreamServerConfig `yaml:"servers"`
+}
+
+// type check
+var _ validator = (*upstreamGroupConfig)(nil)
+
+// validate implements the [validator] interface for *upstreamGroupConfig.
+func (c *upstreamGroupConfig) validate() (err error) {
+	switch {
+	case c == nil:
+		return errors.ErrNoValue
+	case c.Name == "":
+		return fmt.Errorf("name: %w", errors.ErrEmptyValue)
+	case c.Name == forward.UpstreamGroupNameMain:
+		return fmt.Errorf("name: %q is reserved", c.Name)
+	case len(c.Servers) == 0:
+		return fmt.Errorf("servers: %w", errors.ErrEmptyValue)
+	}
+
+	for i, s := range c.Servers {
+		if err = s.validate(); err != nil {
+			return fmt.Errorf("servers: at index %d: %w", i, err)
+		}
+	}
+
+	return validateProp("circuit_breaker", c.CircuitBreaker.validate)
+}
+
+// upstreamGroups are the named groups of upstream servers.  A valid instance of
+// upstreamGroups has no nil items.
+type upstreamGroups []*upstreamGroupConfig
+
+// toInternal returns the configurations of the upstream groups for the
+// forwarding handler.  groups must be valid.
+func (groups upstreamGroups) toInternal() (confs []*forward.UpstreamGroupConfig) {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	confs = make([]*forward.UpstreamGroupConfig, 0, len(groups))
+	for _, g := range groups {
+		confs = append(confs, &forward.UpstreamGroupConfig{
+			CircuitBreaker: g.CircuitBreaker.toInternal(),
+			Name:           g.Name,
+			Upstreams:      toUpstreamConfigs(g.Servers),
+		})
+	}
+
+	return confs
+}
+
+// serversNum returns the total number of the servers in groups.
+func (groups upstreamGroups) serversNum() (n int) {
+	for _, g := range groups {
+		n += len(g.Servers)
+	}
+
+	return n
+}
+
+// type check
+var _ validator = upstreamGroups(nil)
+
+// validate implements the [validator] interface for upstreamGroups.  The
+// upstream groups are optional.
+func (groups upstreamGroups) validate() (err error) {
+	names := container.NewMapSet[string]()
+	for i, g := range groups {
+		err = g.validate()
+		if err != nil {
+			return fmt.Errorf("at index %d: %w", i, err)
+		}
+
+		if names.Has(g.Name) {
+			return fmt.Errorf("at index %d: name: %w: %q", i, errors.ErrDuplicated, g.Name)
+		}
+
+		names.Add(g.Name)
+	}
+
+	return nil
+}
+
+// validateBootstrapNeeded returns an error if any of the servers of groups has
+// a hostname.  groups must be valid.
+func (groups upstreamGroups) validateBootstrapNeeded() (err error) {
+	for i, g := range groups {
+		j := slices.IndexFunc(g.Servers, (*upstreamServerConfig).hasHostname)
+		if j >= 0 {
+			return fmt.Errorf(
+				"groups: at index %d: servers: at index %d: bootstrap: %w",
+				i,
+				j,
+				errors.ErrNoValue,
+			)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/forward"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// views are the views of a server group.  A valid instance of views has no nil
+// items.
+type views []*viewConfig
+
+// toInternal returns the views of a server group.  vs must be valid, and fltGrps
+// must contain all filtering groups of vs.
+func (vs views) toInternal(
+	fltGrps map[agd.FilteringGroupID]*agd.FilteringGroup,
+) (res []*agd.View, err error) {
+	if len(vs) == 0 {
+		return nil, nil
+	}
+
+	res = make([]*agd.View, 0, len(vs))
+	for _, v := range vs {
+		fltGrpID := agd.FilteringGroupID(v.FilteringGroup)
+		fltGrp, ok := fltGrps[fltGrpID]
+		if !ok {
+			return nil, fmt.Errorf("view %q: unknown filtering group %q", v.Name, fltGrpID)
+		}
+
+		res = append(res, &agd.View{
+			FilteringGroup: fltGrp,
+			Name:           agd.ViewName(v.Name),
+			Countries:      v.Countries,
+			Subnets:        v.Subnets,
+		})
+	}
+
+	return res, nil
+}
+
+// type check
+var _ validator = views(nil)
+
+// validate implements the [validator] interface for views.
+func (vs views) validate() (err error) {
+	for i, v := range vs {
+		err = v.validate()
+		if err != nil {
+			return fmt.Errorf("at index %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// viewConfig is the configuration of a logical configuration of a server group
+// applied to the clients from certain subnets or countries.
+type viewConfig struct {
+	// Name is the unique name of the view.
+	Name string `yaml:"name"`
+
+	// FilteringGroup is the name of the filtering group used for the clients
+	// matching the view.
+	FilteringGroup string `yaml:"filtering_group"`
+
+	// Countries are the countries of the clients matching the view.
+	Countries []geoip.Country `yaml:"countries"`
+
+	// Subnets are the subnets of the clients matching the view.
+	Subnets []netip.Prefix `yaml:"subnets"`
+
+	// Upstreams are the optional upstream servers used for the clients
+	// matching the view.  If empty, the main upstream servers are used.
+	Upstreams []*upstreamServerConfig `yaml:"upstreams"`
+}
+
+// type check
+var _ validator = (*viewConfig)(nil)
+
+// validate implements the [validator] interface for *viewConfig.
+func (c *viewConfig) validate() (err error) {
+	switch {
+	case c == nil:
+		return errors.ErrNoValue
+	case c.Name == "":
+		return fmt.Errorf("name: %w", errors.ErrEmptyValue)
+	case c.FilteringGroup == "":
+		return fmt.Errorf("filtering_group: %w", errors.ErrEmptyValue)
+	case len(c.Countries) == 0 && len(c.Subnets) == 0:
+		return fmt.Errorf("countries and subnets: %w", errors.ErrEmptyValue)
+	}
+
+	for i, ctry := range c.Countries {
+		if ctry == geoip.CountryNone {
+			return fmt.Errorf("countries: at index %d: %w", i, errors.ErrEmptyValue)
+		}
+	}
+
+	for i, subnet := range c.Subnets {
+		if !subnet.IsValid() {
+			return fmt.Errorf("subnets: at index %d: bad subnet", i)
+		}
+	}
+
+	for i, s := range c.Upstreams {
+		err = s.validate()
+		if err != nil {
+			return fmt.Errorf("upstreams: at index %d: %w", i, err)
+		} else if s.hasHostname() {
+			return fmt.Errorf("upstreams: at index %d: hostnames are not supported", i)
+		}
+	}
+
+	return nil
+}
+
+// newViewForwardHandlers returns the forwarding handlers for the views of
+// srvGrps with their own upstreams.  fwdConf is the configuration of the main
+// forwarding handler, the settings of which, except for the upstreams, are
+// reused.  srvGrps and fwdConf must be valid.
+func newViewForwardHandlers(
+	srvGrps serverGroups,
+	fwdConf *forward.HandlerConfig,
+) (handlers map[agd.ViewName]*forward.Handler) {
+	for _, g := range srvGrps {
+		for _, v := range g.Views {
+			if len(v.Upstreams) == 0 {
+				continue
+			}
+
+			if handlers == nil {
+				handlers = map[agd.ViewName]*forward.Handler{}
+			}
+
+			conf := &forward.HandlerConfig{}
+			*conf = *fwdConf
+			conf.Logger = fwdConf.Logger.With("view", v.Name)
+			conf.UpstreamsAddresses = toUpstreamConfigs(v.Upstreams)
+
+			handlers[agd.ViewName(v.Name)] = forward.NewHandler(conf)
+		}
+	}
+
+	return handlers
+}
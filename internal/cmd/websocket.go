@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// webSocketConfig is the DNS-over-WebSocket configuration of a DoH server.
+type webSocketConfig struct {
+	// MaxQueries is the maximum number of queries a client is allowed to send
+	// over a single WebSocket connection.  If it is zero, the number of queries
+	// is not limited.
+	MaxQueries uint `yaml:"max_queries"`
+
+	// Enabled, if true, enables the DNS-over-WebSocket endpoint.
+	Enabled bool `yaml:"enabled"`
+}
+
+// toInternal converts c to the DNS-over-WebSocket configuration for a DNS
+// server.  c must be valid.
+func (c *webSocketConfig) toInternal() (conf *agd.WebSocketConfig) {
+	if c == nil {
+		return nil
+	}
+
+	return &agd.WebSocketConfig{
+		MaxQueries: c.MaxQueries,
+		Enabled:    c.Enabled,
+	}
+}
+
+// validate returns an error if the DNS-over-WebSocket configuration is invalid
+// for the given protocol.
+func (c *webSocketConfig) validate(p serverProto) (err error) {
+	if c == nil {
+		// No WebSocket settings, which is normal.
+		return nil
+	} else if p != srvProtoHTTPS {
+		return fmt.Errorf("protocol %s does not support websocket", p)
+	}
+
+	return nil
+}
@@ -11,11 +11,15 @@ import (
 	"os"
 	"path"
 	"slices"
+	"strings"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnscheck"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
 	"github.com/AdguardTeam/AdGuardDNS/internal/tlsconfig"
 	"github.com/AdguardTeam/AdGuardDNS/internal/websvc"
+	"github.com/AdguardTeam/golibs/container"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/httphdr"
 	"github.com/AdguardTeam/golibs/netutil"
@@ -37,6 +41,14 @@ type webConfig struct {
 	// SafeBrowsing is the optional safe browsing block page web server.
 	SafeBrowsing *blockPageServer `yaml:"safe_browsing"`
 
+	// DeviceStats is the optional configuration of the per-device statistics
+	// API.
+	DeviceStats *deviceStatsConfig `yaml:"device_stats"`
+
+	// DoHTest is the optional configuration of the built-in DNS-over-HTTPS
+	// test page.
+	DoHTest *dohTestConfig `yaml:"doh_test"`
+
 	// RootRedirectURL is the URL to which non-DNS and non-Debug HTTP requests
 	// are redirected.  If not set, a 404 page is shown.
 	RootRedirectURL *urlutil.URL `yaml:"root_redirect_url"`
@@ -45,6 +57,10 @@ type webConfig struct {
 	// paths.  If not set, no static content is shown.
 	StaticContent staticContent `yaml:"static_content"`
 
+	// DomainPolicies are the HTTP security policies of the domains served by
+	// the web service.
+	DomainPolicies []*domainPolicyConfig `yaml:"domain_policies"`
+
 	// Error404 is the path to the file with the HTML page for the 404 status.
 	// If not set, a simple plain text 404 response is served.
 	Error404 string `yaml:"error_404"`
@@ -62,21 +78,40 @@ type webConfig struct {
 }
 
 // toInternal converts c to the AdGuardDNS web service configuration.  c must be
-// valid.
+// valid.  unblockConf may be nil.
 func (c *webConfig) toInternal(
 	ctx context.Context,
 	envs *environment,
 	dnsCk dnscheck.Interface,
+	devStat devicestat.Interface,
 	errColl errcoll.Interface,
+	profDB profiledb.Interface,
 	tlsMgr tlsconfig.Manager,
+	unblockConf *websvc.UnblockConfig,
 ) (conf *websvc.Config, err error) {
 	if c == nil {
 		return nil, nil
 	}
 
 	conf = &websvc.Config{
-		ErrColl: errColl,
-		Timeout: c.Timeout.Duration,
+		Unblock:        unblockConf,
+		DomainPolicies: domainPoliciesToInternal(c.DomainPolicies),
+		ErrColl:        errColl,
+		Timeout:        c.Timeout.Duration,
+	}
+
+	if c.DeviceStats != nil && c.DeviceStats.Enabled {
+		conf.DeviceStats = &websvc.DeviceStatsConfig{
+			ProfileDB: profDB,
+			Stats:     devStat,
+		}
+	}
+
+	if c.DoHTest != nil && c.DoHTest.Enabled {
+		conf.DoHTest = &websvc.DoHTestConfig{
+			CheckDomain: strings.ToLower(c.DoHTest.CheckDomain),
+			DoHPath:     c.DoHTest.DoHPath,
+		}
 	}
 
 	if dnsCkHdlr, ok := dnsCk.(http.Handler); ok {
@@ -207,6 +242,16 @@ func (c *webConfig) validate() (err error) {
 		return fmt.Errorf("safe_browsing: %w", err)
 	}
 
+	err = c.DeviceStats.validate()
+	if err != nil {
+		return fmt.Errorf("device_stats: %w", err)
+	}
+
+	err = c.DoHTest.validate()
+	if err != nil {
+		return fmt.Errorf("doh_test: %w", err)
+	}
+
 	err = c.StaticContent.validate()
 	if err != nil {
 		return fmt.Errorf("static_content: %w", err)
@@ -217,6 +262,67 @@ func (c *webConfig) validate() (err error) {
 		return fmt.Errorf("non_doh_bind: %w", err)
 	}
 
+	return validateDomainPolicies(c.DomainPolicies)
+}
+
+// deviceStatsConfig is the configuration of the per-device statistics API.
+type deviceStatsConfig struct {
+	// MaxDevices is the maximum number of devices for which the statistics
+	// are kept on the node.
+	MaxDevices int `yaml:"max_devices"`
+
+	// Enabled shows if the per-device statistics are collected and served.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*deviceStatsConfig)(nil)
+
+// validate implements the [validator] interface for *deviceStatsConfig.  The
+// per-device statistics configuration is optional.
+func (c *deviceStatsConfig) validate() (err error) {
+	if c == nil || !c.Enabled {
+		return nil
+	}
+
+	return validatePositive("max_devices", c.MaxDevices)
+}
+
+// dohTestConfig is the configuration of the built-in DNS-over-HTTPS test page.
+type dohTestConfig struct {
+	// CheckDomain is the DNS check domain used for the test queries.  It
+	// should be one of the domains in the check configuration.
+	CheckDomain string `yaml:"check_domain"`
+
+	// DoHPath is the default path of the DNS-over-HTTPS endpoint to test.
+	DoHPath string `yaml:"doh_path"`
+
+	// Enabled shows if the DoH test page is served.
+	Enabled bool `yaml:"enabled"`
+}
+
+// type check
+var _ validator = (*dohTestConfig)(nil)
+
+// validate implements the [validator] interface for *dohTestConfig.  The DoH
+// test page configuration is optional.
+func (c *dohTestConfig) validate() (err error) {
+	switch {
+	case c == nil, !c.Enabled:
+		return nil
+	case c.DoHPath == "":
+		return fmt.Errorf("doh_path: %w", errors.ErrEmptyValue)
+	case !path.IsAbs(c.DoHPath):
+		return fmt.Errorf("doh_path %q: not absolute", c.DoHPath)
+	default:
+		// Go on.
+	}
+
+	err = netutil.ValidateDomainName(c.CheckDomain)
+	if err != nil {
+		return fmt.Errorf("check_domain: %w", err)
+	}
+
 	return nil
 }
 
@@ -509,3 +615,154 @@ func (f *staticFile) validate() (err error) {
 
 	return nil
 }
+
+// domainPolicyConfig is the HTTP security policy of one or more domains.
+type domainPolicyConfig struct {
+	// HSTS is the optional configuration of the Strict-Transport-Security
+	// header.
+	HSTS *hstsConfig `yaml:"hsts"`
+
+	// Domains are the domain names to which the policy applies.  A domain name
+	// starting with "*." matches the direct subdomains of the domain.
+	Domains []string `yaml:"domains"`
+
+	// RedirectHTTPS, if true, makes the web service redirect plain-HTTP
+	// requests to HTTPS.
+	RedirectHTTPS bool `yaml:"redirect_https"`
+
+	// SecurityHeaders, if true, makes the web service add the common security
+	// headers to the responses.
+	SecurityHeaders bool `yaml:"security_headers"`
+}
+
+// domainPoliciesToInternal converts the domain policies to the web service
+// policy storage.  policies must be valid.
+func domainPoliciesToInternal(
+	policies []*domainPolicyConfig,
+) (s *websvc.DefaultDomainPolicyStorage) {
+	static := map[string]*websvc.DomainPolicy{}
+	for _, c := range policies {
+		p := c.toInternal()
+		for _, d := range c.Domains {
+			static[strings.ToLower(d)] = p
+		}
+	}
+
+	return websvc.NewDefaultDomainPolicyStorage(static)
+}
+
+// toInternal converts c to the web service domain policy.  c must be valid.
+func (c *domainPolicyConfig) toInternal() (p *websvc.DomainPolicy) {
+	p = &websvc.DomainPolicy{
+		RedirectHTTPS:   c.RedirectHTTPS,
+		SecurityHeaders: c.SecurityHeaders,
+	}
+
+	if c.HSTS != nil {
+		p.HSTS = &websvc.HSTSConfig{
+			MaxAge:            c.HSTS.MaxAge.Duration,
+			IncludeSubdomains: c.HSTS.IncludeSubdomains,
+			Preload:           c.HSTS.Preload,
+		}
+	}
+
+	return p
+}
+
+// validateDomainPolicies returns an error if policies contain invalid policies
+// or duplicated domains.
+func validateDomainPolicies(policies []*domainPolicyConfig) (err error) {
+	domains := container.NewMapSet[string]()
+	for i, c := range policies {
+		err = c.validate()
+		if err != nil {
+			return fmt.Errorf("domain_policies: at index %d: %w", i, err)
+		}
+
+		for _, d := range c.Domains {
+			d = strings.ToLower(d)
+			if domains.Has(d) {
+				return fmt.Errorf(
+					"domain_policies: at index %d: domain %q: %w",
+					i,
+					d,
+					errors.ErrDuplicated,
+				)
+			}
+
+			domains.Add(d)
+		}
+	}
+
+	return nil
+}
+
+// type check
+var _ validator = (*domainPolicyConfig)(nil)
+
+// validate implements the [validator] interface for *domainPolicyConfig.
+func (c *domainPolicyConfig) validate() (err error) {
+	switch {
+	case c == nil:
+		return errors.ErrNoValue
+	case len(c.Domains) == 0:
+		return fmt.Errorf("domains: %w", errors.ErrEmptyValue)
+	default:
+		// Go on.
+	}
+
+	for i, d := range c.Domains {
+		err = netutil.ValidateHostname(strings.TrimPrefix(d, "*."))
+		if err != nil {
+			return fmt.Errorf("domains: at index %d: %w", i, err)
+		}
+	}
+
+	err = c.HSTS.validate()
+	if err != nil {
+		return fmt.Errorf("hsts: %w", err)
+	}
+
+	return nil
+}
+
+// hstsConfig is the configuration of the Strict-Transport-Security header.
+type hstsConfig struct {
+	// MaxAge is the time during which the browsers must only use HTTPS to
+	// access the domain.
+	MaxAge timeutil.Duration `yaml:"max_age"`
+
+	// IncludeSubdomains, if true, makes the policy apply to the subdomains as
+	// well.
+	IncludeSubdomains bool `yaml:"include_subdomains"`
+
+	// Preload, if true, allows the domain to be included into the preload
+	// lists of the browsers.
+	Preload bool `yaml:"preload"`
+}
+
+// type check
+var _ validator = (*hstsConfig)(nil)
+
+// validate implements the [validator] interface for *hstsConfig.  The HSTS
+// configuration is optional.
+func (c *hstsConfig) validate() (err error) {
+	switch {
+	case c == nil:
+		return nil
+	case c.MaxAge.Duration <= 0:
+		return newNotPositiveError("max_age", c.MaxAge)
+	case !c.Preload:
+		return nil
+	case !c.IncludeSubdomains:
+		return errors.Error("preload: include_subdomains must be true")
+	case c.MaxAge.Duration < websvc.HSTSPreloadMinMaxAge:
+		return fmt.Errorf(
+			"preload: max_age must be at least %s, got %s",
+			timeutil.Duration{Duration: websvc.HSTSPreloadMinMaxAge},
+			c.MaxAge,
+		)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+package debugsvc
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/auditlog"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/netutil/httputil"
+)
+
+// auditLogHandler serves the most recent entries of the audit log.
+type auditLogHandler struct {
+	log *auditlog.File
+}
+
+// auditLogResponse describes the response to the GET /debug/api/audit HTTP
+// API.
+type auditLogResponse struct {
+	// Entries are the most recent entries of the audit log, the oldest first.
+	Entries []*auditlog.Entry `json:"entries"`
+}
+
+// type check
+var _ http.Handler = (*auditLogHandler)(nil)
+
+// ServeHTTP implements the [http.Handler] interface for *auditLogHandler.
+func (h *auditLogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := slogutil.MustLoggerFromContext(ctx)
+
+	resp := &auditLogResponse{
+		Entries: h.log.Recent(),
+	}
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		l.ErrorContext(ctx, "writing response", slogutil.KeyError, err)
+	}
+}
+
+// auditMiddleware records the invocations of the debug API in the audit log.
+type auditMiddleware struct {
+	log auditlog.Interface
+}
+
+// type check
+var _ httputil.Middleware = (*auditMiddleware)(nil)
+
+// Wrap implements the [httputil.Middleware] interface for *auditMiddleware.
+func (mw *auditMiddleware) Wrap(h http.Handler) (wrapped http.Handler) {
+	f := func(w http.ResponseWriter, r *http.Request) {
+		mw.log.Record(r.Context(), &auditlog.Event{
+			Data: &auditlog.DebugAPI{
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				RemoteAddr: r.RemoteAddr,
+			},
+			Type: auditlog.EventTypeDebugAPI,
+		})
+
+		h.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(f)
+}
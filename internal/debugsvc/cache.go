@@ -2,12 +2,18 @@ package debugsvc
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdnet"
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/httphdr"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/netutil"
 )
 
 // cacheHandler performs debug cache purges.
@@ -45,7 +51,7 @@ func (h *cacheHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	reqIDs, err := h.idsFromReq(req.Patterns)
+	reqIDs, err := idsFromReq(h.manager, req.Patterns)
 	if err != nil {
 		l.ErrorContext(ctx, "validating request", slogutil.KeyError, err)
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -69,18 +75,139 @@ func (h *cacheHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// idsFromReq returns the IDs of matching caches to purge.
-func (h *cacheHandler) idsFromReq(patterns []string) (ids []string, err error) {
+// idsFromReq returns the IDs of the caches of m matching patterns.
+func idsFromReq(m *agdcache.DefaultManager, patterns []string) (ids []string, err error) {
 	ok, err := isWildcard(patterns)
 	if err != nil {
 		// Don't wrap the error, because it's informative enough as is.
 		return nil, err
 	}
 
-	cacheIDs := h.manager.IDs()
+	cacheIDs := m.IDs()
 	if ok {
 		return cacheIDs, nil
 	}
 
 	return matchPatterns(cacheIDs, patterns), nil
 }
+
+// cacheFlushHandler performs debug removals of the cache items related to
+// particular hostnames.
+type cacheFlushHandler struct {
+	manager *agdcache.DefaultManager
+}
+
+// type check
+var _ http.Handler = (*cacheFlushHandler)(nil)
+
+// cacheFlushRequest describes the request to the POST /debug/api/cache/flush
+// HTTP API.
+type cacheFlushRequest struct {
+	// Patterns is the slice of path patterns to match the cache IDs.
+	Patterns []string `json:"ids"`
+
+	// Domains are the domain names the items of which should be removed.  A
+	// domain name starting with "*." matches all its subdomains, but not the
+	// domain name itself.
+	Domains []string `json:"domains"`
+}
+
+// cacheFlushResponse describes the response to the POST /debug/api/cache/flush
+// HTTP API.
+type cacheFlushResponse struct {
+	// Results maps the IDs of the caches supporting the removal of items by
+	// hostnames to the number of removed items.
+	Results map[string]int `json:"results"`
+}
+
+// ServeHTTP implements the [http.Handler] interface for *cacheFlushHandler.
+func (h *cacheFlushHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := slogutil.MustLoggerFromContext(ctx)
+
+	req := &cacheFlushRequest{}
+	err := json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		l.ErrorContext(ctx, "decoding request", slogutil.KeyError, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	match, err := newHostMatcher(req.Domains)
+	if err != nil {
+		l.ErrorContext(ctx, "validating domains", slogutil.KeyError, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	reqIDs, err := idsFromReq(h.manager, req.Patterns)
+	if err != nil {
+		l.ErrorContext(ctx, "validating request", slogutil.KeyError, err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	resp := &cacheFlushResponse{
+		Results: make(map[string]int, len(reqIDs)),
+	}
+
+	for _, id := range reqIDs {
+		n, ok := h.manager.FlushHostsByID(id, match)
+		if ok {
+			resp.Results[id] = n
+		}
+	}
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		l.ErrorContext(ctx, "writing response", slogutil.KeyError, err)
+	}
+}
+
+// subdomainsPrefix is the prefix of the domain names in the cache flush
+// requests that match the subdomains of the domain name.
+const subdomainsPrefix = "*."
+
+// newHostMatcher returns a function that matches the hostnames against the
+// domains from a cache flush request.  See [cacheFlushRequest.Domains].
+func newHostMatcher(domains []string) (match func(host string) (ok bool), err error) {
+	if len(domains) == 0 {
+		return nil, errors.Error("no domains")
+	}
+
+	exact := container.NewMapSet[string]()
+	var tops []string
+	for i, d := range domains {
+		d = agdnet.NormalizeDomain(d)
+
+		top, isSub := strings.CutPrefix(d, subdomainsPrefix)
+		err = netutil.ValidateDomainName(top)
+		if err != nil {
+			return nil, fmt.Errorf("domains: at index %d: %w", i, err)
+		}
+
+		if isSub {
+			tops = append(tops, top)
+		} else {
+			exact.Add(d)
+		}
+	}
+
+	return func(host string) (ok bool) {
+		if exact.Has(host) {
+			return true
+		}
+
+		for _, top := range tops {
+			if netutil.IsSubdomain(host, top) {
+				return true
+			}
+		}
+
+		return false
+	}, nil
+}
@@ -6,8 +6,21 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/auditlog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/forward"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/maintenance"
+	"github.com/AdguardTeam/AdGuardDNS/internal/noderole"
+	"github.com/AdguardTeam/AdGuardDNS/internal/poisonpill"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
+	"github.com/AdguardTeam/AdGuardDNS/internal/reqlog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/tlsconfig"
+	"github.com/AdguardTeam/AdGuardDNS/internal/topprofiles"
 	"github.com/AdguardTeam/golibs/container"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
@@ -18,10 +31,24 @@ import (
 // Service is the HTTP service of AdGuard DNS.  It serves prometheus metrics,
 // pprof, health check, DNSDB, and other endpoints.
 type Service struct {
-	logger    *slog.Logger
-	refrHdlr  *refreshHandler
-	cacheHdlr *cacheHandler
-	dnsDB     http.Handler
+	logger          *slog.Logger
+	refrHdlr        *refreshHandler
+	cacheHdlr       *cacheHandler
+	cacheFlushHdlr  *cacheFlushHandler
+	healthHdlr      *healthCheckHandler
+	fltHealthHdlr   *filterHealthHandler
+	maintHdlr       *maintenanceHandler
+	roleHdlr        *nodeRoleHandler
+	sessTicketsHdlr *sessionTicketsHandler
+	topProfilesHdlr *topProfilesHandler
+	effPolicyHdlr   *effectivePolicyHandler
+	reqLogHdlr      *requestLogHandler
+	quarHdlr        *quarantineHandler
+	upsGroupsHdlr   *upstreamGroupsHandler
+	poisonPillHdlr  *poisonPillHandler
+	auditLogHdlr    *auditLogHandler
+	auditMw         *auditMiddleware
+	dnsDB           http.Handler
 
 	// servers are the servers of this service by their address.  Map entries
 	// must not be nil.
@@ -37,14 +64,70 @@ type server struct {
 
 // Config is the AdGuard DNS HTTP service configuration structure.
 type Config struct {
-	DNSDBHandler   http.Handler
-	Logger         *slog.Logger
-	Manager        *agdcache.DefaultManager
+	DNSDBHandler http.Handler
+	Logger       *slog.Logger
+	Manager      *agdcache.DefaultManager
+
+	// TLSManager, if not nil, is used to serve the information about the TLS
+	// session ticket keys.
+	TLSManager *tlsconfig.DefaultManager
+
+	// Maintenance, if not nil, is used to serve and switch the maintenance
+	// mode of the server groups as well as to fail the health check while the
+	// node is being drained.
+	Maintenance *maintenance.Manager
+
+	// NodeRole, if not nil, is used to serve and switch the role of the node
+	// within a pair as well as to fail the health check while the node is a
+	// standby one.
+	NodeRole *noderole.Manager
+
+	// TopProfiles, if not nil, is used to serve the statistics of the profiles
+	// with the most requests, errors, and blocked requests.
+	TopProfiles *topprofiles.Default
+
+	// RequestLog, if not nil, is used to serve and set the targets of the
+	// structured per-request debug log.
+	RequestLog *reqlog.Default
+
+	// Quarantine, if not nil, is used to serve and switch the quarantine of
+	// the devices.
+	Quarantine *quarantine.Default
+
+	// ProfileDB, if not nil, is used to serve the effective policy of the
+	// devices.  If it is not nil, Clock must not be nil.
+	ProfileDB profiledb.Interface
+
+	// Clock is used to compute the parts of the effective policy that depend
+	// on time, such as schedules.
+	Clock agdtime.Clock
+
+	// AuditLog, if not nil, is used to record the invocations of the debug API
+	// and to serve the most recent entries of the audit log.
+	AuditLog *auditlog.File
+
+	// FilterStatuses, if not nil, is used to serve the refresh statuses of the
+	// filters.
+	FilterStatuses *filter.StatusMetrics
+
+	// Forward, if not nil, is used to serve the states of the circuit breakers
+	// of the upstream groups.
+	Forward *forward.Handler
+
+	// PoisonPill, if not nil, is used to serve the most recent queries that
+	// have caused panics and the active drop rules.
+	PoisonPill *poisonpill.Guard
+
 	Refreshers     Refreshers
 	DNSDBAddr      string
 	APIAddr        string
 	PprofAddr      string
 	PrometheusAddr string
+
+	// FilterStaleThreshold is the duration since the last successful refresh
+	// after which a filter is considered stale.  If it is zero, filters are
+	// only considered stale if they have never been refreshed.
+	FilterStaleThreshold time.Duration
 }
 
 // handlerGroup is a semantic alias for names of handler groups.
@@ -68,10 +151,90 @@ func New(c *Config) (svc *Service) {
 		cacheHdlr: &cacheHandler{
 			manager: c.Manager,
 		},
+		cacheFlushHdlr: &cacheFlushHandler{
+			manager: c.Manager,
+		},
+		healthHdlr: &healthCheckHandler{
+			manager: c.Maintenance,
+			role:    c.NodeRole,
+		},
+		auditMw: &auditMiddleware{
+			log: auditlog.Empty{},
+		},
 		servers: map[string]*server{},
 		dnsDB:   c.DNSDBHandler,
 	}
 
+	if c.AuditLog != nil {
+		svc.auditLogHdlr = &auditLogHandler{
+			log: c.AuditLog,
+		}
+		svc.auditMw.log = c.AuditLog
+	}
+
+	if c.FilterStatuses != nil {
+		svc.fltHealthHdlr = &filterHealthHandler{
+			statuses:       c.FilterStatuses,
+			staleThreshold: c.FilterStaleThreshold,
+		}
+	}
+
+	if c.Maintenance != nil {
+		svc.maintHdlr = &maintenanceHandler{
+			manager: c.Maintenance,
+		}
+	}
+
+	if c.NodeRole != nil {
+		svc.roleHdlr = &nodeRoleHandler{
+			manager: c.NodeRole,
+		}
+	}
+
+	if c.TLSManager != nil {
+		svc.sessTicketsHdlr = &sessionTicketsHandler{
+			manager: c.TLSManager,
+		}
+	}
+
+	if c.TopProfiles != nil {
+		svc.topProfilesHdlr = &topProfilesHandler{
+			stats: c.TopProfiles,
+		}
+	}
+
+	if c.RequestLog != nil {
+		svc.reqLogHdlr = &requestLogHandler{
+			log: c.RequestLog,
+		}
+	}
+
+	if c.Quarantine != nil {
+		svc.quarHdlr = &quarantineHandler{
+			quarantine: c.Quarantine,
+		}
+	}
+
+	if c.ProfileDB != nil {
+		svc.effPolicyHdlr = &effectivePolicyHandler{
+			clock:      c.Clock,
+			profiles:   c.ProfileDB,
+			quarantine: c.Quarantine,
+		}
+	}
+
+	if c.Forward != nil {
+		svc.upsGroupsHdlr = &upstreamGroupsHandler{
+			forward: c.Forward,
+		}
+	}
+
+	if c.PoisonPill != nil {
+		svc.poisonPillHdlr = &poisonPillHandler{
+			guard: c.PoisonPill,
+		}
+	}
+
 	svc.initServers(c)
 	svc.route(c)
 
@@ -2,17 +2,33 @@ package debugsvc_test
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
+	"net/netip"
 	"net/url"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/access"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/auditlog"
 	"github.com/AdguardTeam/AdGuardDNS/internal/debugsvc"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/maintenance"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
+	"github.com/AdguardTeam/AdGuardDNS/internal/reqlog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/tlsconfig"
+	"github.com/AdguardTeam/AdGuardDNS/internal/topprofiles"
+	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/netutil/httputil"
 	"github.com/AdguardTeam/golibs/netutil/urlutil"
@@ -65,21 +81,77 @@ func TestService_Start(t *testing.T) {
 	cacheManager := agdcache.NewDefaultManager()
 	cacheManager.Add("test", agdcache.Empty[any, any]{})
 
+	hostCache := agdcache.NewLRU[int, agdcache.HostItem](&agdcache.LRUConfig{
+		Count: 10,
+	})
+	hostCache.Set(1, testHostItem("example.com"))
+	hostCache.Set(2, testHostItem("www.example.com"))
+	cacheManager.Add("hosts", hostCache)
+
+	tlsManager, err := tlsconfig.NewDefaultManager(&tlsconfig.DefaultManagerConfig{
+		Logger:   slogutil.NewDiscardLogger(),
+		AuditLog: auditlog.Empty{},
+		ErrColl:  agdtest.NewErrorCollector(),
+		Metrics:  tlsconfig.EmptyMetrics{},
+	})
+	require.NoError(t, err)
+
+	maintManager := maintenance.NewManager(map[agd.ServerGroupName]*maintenance.GroupConfig{
+		"test_server_group": {
+			RetryAfter:      30 * time.Second,
+			FailHealthCheck: true,
+			Enabled:         false,
+		},
+	})
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	auditLog, err := auditlog.NewFile(ctx, &auditlog.FileConfig{
+		Logger:      slogutil.NewDiscardLogger(),
+		Clock:       agdtime.SystemClock{},
+		ErrColl:     agdtest.NewErrorCollector(),
+		Path:        filepath.Join(t.TempDir(), "audit.jsonl"),
+		RecentCount: 100,
+	})
+	require.NoError(t, err)
+
+	fltStatuses := filter.NewStatusMetrics(filter.EmptyMetrics{})
+	fltStatuses.SetFilterStatus(ctx, "rulelist_ok", time.Now(), 10, nil)
+	fltStatuses.SetFilterStatus(ctx, "hashprefix_fail", time.Time{}, 0, errors.Error("test error"))
+
+	profDB := newTestProfileDB()
+
 	c := &debugsvc.Config{
-		Logger:         slogutil.NewDiscardLogger(),
-		DNSDBAddr:      addr,
-		DNSDBHandler:   h,
-		Manager:        cacheManager,
-		Refreshers:     refreshers,
-		APIAddr:        addr,
-		PprofAddr:      addr,
-		PrometheusAddr: addr,
+		Logger:       slogutil.NewDiscardLogger(),
+		DNSDBAddr:    addr,
+		DNSDBHandler: h,
+		Manager:      cacheManager,
+		TLSManager:   tlsManager,
+		Maintenance:  maintManager,
+		TopProfiles: topprofiles.NewDefault(&topprofiles.DefaultConfig{
+			Clock: agdtime.SystemClock{},
+			Size:  10,
+		}),
+		RequestLog: reqlog.NewDefault(&reqlog.DefaultConfig{
+			Logger: slogutil.NewDiscardLogger(),
+			Clock:  agdtime.SystemClock{},
+		}),
+		Quarantine: quarantine.NewDefault(&quarantine.DefaultConfig{
+			Metrics: quarantine.EmptyMetrics{},
+		}),
+		ProfileDB:            profDB,
+		Clock:                agdtime.SystemClock{},
+		AuditLog:             auditLog,
+		FilterStatuses:       fltStatuses,
+		FilterStaleThreshold: time.Hour,
+		Refreshers:           refreshers,
+		APIAddr:              addr,
+		PprofAddr:            addr,
+		PrometheusAddr:       addr,
 	}
 
 	svc := debugsvc.New(c)
 	require.NotNil(t, svc)
 
-	var err error
 	require.NotPanics(t, func() {
 		err = svc.Start(testutil.ContextWithTimeout(t, testTimeout))
 	})
@@ -99,7 +171,7 @@ func TestService_Start(t *testing.T) {
 
 	// Use a context without a timeout, since it is used with agdhttp.Client,
 	// which already has a timeout.
-	ctx := context.Background()
+	ctx = context.Background()
 
 	// First check health-check service URL.  As the service could not be ready
 	// yet, check for it in periodically.
@@ -182,6 +254,322 @@ func TestService_Start(t *testing.T) {
 
 	respBody = readRespBody(t, resp)
 	assert.JSONEq(t, clearResp, respBody)
+
+	// Check cache flush API.
+
+	flushURL := srvURL.JoinPath(debugsvc.PathPatternDebugAPICacheFlush)
+
+	reqBody = strings.NewReader(`{"ids":["*"],"domains":["*.EXAMPLE.com"]}`)
+	resp, err = client.Post(ctx, flushURL, agdhttp.HdrValApplicationJSON, reqBody)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	respBody = readRespBody(t, resp)
+	assert.JSONEq(t, `{"results":{"hosts":1}}`, respBody)
+	assert.Equal(t, 1, hostCache.Len())
+
+	reqBody = strings.NewReader(`{"ids":["*"],"domains":["bad..domain"]}`)
+	resp, err = client.Post(ctx, flushURL, agdhttp.HdrValApplicationJSON, reqBody)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// Check session tickets API.
+
+	ticketsURL := srvURL.JoinPath(debugsvc.PathPatternDebugAPITLSSessionTickets)
+	resp, err = client.Get(ctx, ticketsURL)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	respBody = readRespBody(t, resp)
+	assert.JSONEq(t, `{"rotated_at":null,"keys_hash":"","num_keys":0}`, respBody)
+
+	// Check top profiles API.
+
+	topURL := srvURL.JoinPath(debugsvc.PathPatternDebugAPIProfilesTop)
+	resp, err = client.Get(ctx, topURL)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	respBody = readRespBody(t, resp)
+	assert.JSONEq(
+		t,
+		`{"start":null,"end":null,"requests":[],"errors":[],"blocked":[]}`,
+		respBody,
+	)
+
+	// Check filter health API.
+
+	resp, err = client.Get(ctx, srvURL.JoinPath(debugsvc.PathPatternHealthFilters))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	fltHealth := &struct {
+		Filters []struct {
+			ID        string `json:"id"`
+			LastError string `json:"last_error"`
+			RuleCount int    `json:"rule_count"`
+			Stale     bool   `json:"stale"`
+		} `json:"filters"`
+		Healthy bool `json:"healthy"`
+	}{}
+	err = json.Unmarshal([]byte(readRespBody(t, resp)), fltHealth)
+	require.NoError(t, err)
+	require.Len(t, fltHealth.Filters, 2)
+
+	assert.False(t, fltHealth.Healthy)
+
+	failFlt, okFlt := fltHealth.Filters[0], fltHealth.Filters[1]
+	assert.Equal(t, "hashprefix_fail", failFlt.ID)
+	assert.Equal(t, "test error", failFlt.LastError)
+	assert.True(t, failFlt.Stale)
+
+	assert.Equal(t, "rulelist_ok", okFlt.ID)
+	assert.Empty(t, okFlt.LastError)
+	assert.Equal(t, 10, okFlt.RuleCount)
+	assert.False(t, okFlt.Stale)
+
+	// Check maintenance API.
+
+	maintURL := srvURL.JoinPath(debugsvc.PathPatternDebugAPIMaintenance)
+	resp, err = client.Get(ctx, maintURL)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	respBody = readRespBody(t, resp)
+	assert.JSONEq(t, `{"server_groups":[{
+		"name":"test_server_group",
+		"retry_after":"30s",
+		"fail_health_check":true,
+		"enabled":false
+	}]}`, respBody)
+
+	reqBody = strings.NewReader(`{"server_groups":{"unknown":true}}`)
+	resp, err = client.Post(ctx, maintURL, agdhttp.HdrValApplicationJSON, reqBody)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	reqBody = strings.NewReader(`{"server_groups":{"test_server_group":true}}`)
+	resp, err = client.Post(ctx, maintURL, agdhttp.HdrValApplicationJSON, reqBody)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	respBody = readRespBody(t, resp)
+	assert.JSONEq(t, `{"server_groups":[{
+		"name":"test_server_group",
+		"retry_after":"30s",
+		"fail_health_check":true,
+		"enabled":true
+	}]}`, respBody)
+
+	resp, err = client.Get(ctx, healthCheckURL)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	// Check request log API.
+
+	reqLogURL := srvURL.JoinPath(debugsvc.PathPatternDebugAPIRequestLog)
+	resp, err = client.Get(ctx, reqLogURL)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	respBody = readRespBody(t, resp)
+	assert.JSONEq(t, `{"expires":null,"profile_ids":[],"device_ids":[]}`, respBody)
+
+	reqBody = strings.NewReader(`{"profile_ids":["prof1234"],"duration":"0s"}`)
+	resp, err = client.Post(ctx, reqLogURL, agdhttp.HdrValApplicationJSON, reqBody)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	reqBody = strings.NewReader(`{"device_ids":["dev1234"],"duration":"1h"}`)
+	resp, err = client.Post(ctx, reqLogURL, agdhttp.HdrValApplicationJSON, reqBody)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	reqLogResp := &struct {
+		Expires    *time.Time      `json:"expires"`
+		ProfileIDs []agd.ProfileID `json:"profile_ids"`
+		DeviceIDs  []agd.DeviceID  `json:"device_ids"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(reqLogResp)
+	require.NoError(t, err)
+
+	assert.NotNil(t, reqLogResp.Expires)
+	assert.Empty(t, reqLogResp.ProfileIDs)
+	assert.Equal(t, []agd.DeviceID{"dev1234"}, reqLogResp.DeviceIDs)
+
+	// Check quarantine API.
+
+	quarURL := srvURL.JoinPath(debugsvc.PathPatternDebugAPIQuarantine)
+	resp, err = client.Get(ctx, quarURL)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	respBody = readRespBody(t, resp)
+	assert.JSONEq(t, `{"device_ids":[]}`, respBody)
+
+	reqBody = strings.NewReader(`{"device_ids":{"bad device":true}}`)
+	resp, err = client.Post(ctx, quarURL, agdhttp.HdrValApplicationJSON, reqBody)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	reqBody = strings.NewReader(`{"device_ids":{"dev1234":true,"dev5678":false}}`)
+	resp, err = client.Post(ctx, quarURL, agdhttp.HdrValApplicationJSON, reqBody)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	respBody = readRespBody(t, resp)
+	assert.JSONEq(t, `{"device_ids":["dev1234"]}`, respBody)
+
+	// Check effective policy API.
+
+	effURL := srvURL.JoinPath(debugsvc.PathPatternDebugAPIProfilesEffective)
+	resp, err = client.Get(ctx, effURL)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	effURL.RawQuery = url.Values{"client_ip": []string{"192.0.2.1"}}.Encode()
+	resp, err = client.Get(ctx, effURL)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	effURL.RawQuery = url.Values{"device_id": []string{"dev1234"}}.Encode()
+	resp, err = client.Get(ctx, effURL)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	effResp := &struct {
+		Filter *struct {
+			Parental *struct {
+				BlockedServices []filter.BlockedServiceID `json:"blocked_services"`
+				AdultBlocking   bool                      `json:"adult_blocking"`
+			} `json:"parental"`
+			RuleLists        []filter.ID `json:"rule_lists"`
+			DangerousDomains bool        `json:"dangerous_domains"`
+		} `json:"filter"`
+		BlockingMode *struct {
+			Type string `json:"type"`
+		} `json:"blocking_mode"`
+		ProfileID        agd.ProfileID `json:"profile_id"`
+		DeviceID         agd.DeviceID  `json:"device_id"`
+		FilteringEnabled bool          `json:"filtering_enabled"`
+		Quarantined      bool          `json:"quarantined"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(effResp)
+	require.NoError(t, err)
+
+	assert.Equal(t, agd.ProfileID("prof1234"), effResp.ProfileID)
+	assert.Equal(t, agd.DeviceID("dev1234"), effResp.DeviceID)
+	assert.True(t, effResp.FilteringEnabled)
+	assert.True(t, effResp.Quarantined)
+	assert.Equal(t, "null_ip", effResp.BlockingMode.Type)
+
+	require.NotNil(t, effResp.Filter)
+
+	assert.Equal(t, []filter.ID{"adguard_dns_filter"}, effResp.Filter.RuleLists)
+	assert.False(t, effResp.Filter.DangerousDomains)
+
+	require.NotNil(t, effResp.Filter.Parental)
+
+	assert.True(t, effResp.Filter.Parental.AdultBlocking)
+	assert.Equal(t, []filter.BlockedServiceID{"youtube"}, effResp.Filter.Parental.BlockedServices)
+
+	// Check audit log API.
+
+	resp, err = client.Get(ctx, srvURL.JoinPath(debugsvc.PathPatternDebugAPIAudit))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	auditResp := &struct {
+		Entries []*auditlog.Entry `json:"entries"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(auditResp)
+	require.NoError(t, err)
+
+	// All API requests above except the health checks are recorded.
+	require.Len(t, auditResp.Entries, 20)
+
+	first := auditResp.Entries[0]
+	assert.Equal(t, auditlog.EventTypeDebugAPI, first.Type)
+
+	apiData := &auditlog.DebugAPI{}
+	err = json.Unmarshal(first.Data, apiData)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.MethodPost, apiData.Method)
+	assert.Equal(t, debugsvc.PathPatternDebugAPIRefresh, apiData.Path)
+}
+
+// newTestProfileDB returns a profile database that only contains a single
+// profile with the device "dev1234".
+func newTestProfileDB() (db *agdtest.ProfileDB) {
+	dev := &agd.Device{
+		ID:               "dev1234",
+		FilteringEnabled: true,
+	}
+
+	prof := &agd.Profile{
+		FilterConfig: &filter.ConfigClient{
+			Custom: &filter.ConfigCustom{},
+			Parental: &filter.ConfigParental{
+				BlockedServices:      []filter.BlockedServiceID{"youtube"},
+				Enabled:              true,
+				AdultBlockingEnabled: true,
+			},
+			RuleList: &filter.ConfigRuleList{
+				IDs:     []filter.ID{"adguard_dns_filter"},
+				Enabled: true,
+			},
+			SafeBrowsing: &filter.ConfigSafeBrowsing{
+				DangerousDomainsEnabled: true,
+			},
+		},
+		Access:              access.EmptyProfile{},
+		BlockingMode:        &dnsmsg.BlockingModeNullIP{},
+		Ratelimiter:         agd.GlobalRatelimiter{},
+		ID:                  "prof1234",
+		DeviceIDs:           []agd.DeviceID{dev.ID},
+		FilteredResponseTTL: agdtest.FilteredResponseTTL,
+		FilteringEnabled:    true,
+	}
+
+	db = agdtest.NewProfileDB()
+	db.OnProfileByDeviceID = func(
+		_ context.Context,
+		id agd.DeviceID,
+	) (p *agd.Profile, d *agd.Device, err error) {
+		if id != dev.ID {
+			return nil, nil, profiledb.ErrDeviceNotFound
+		}
+
+		return prof, dev, nil
+	}
+	db.OnProfileByLinkedIP = func(
+		_ context.Context,
+		_ netip.Addr,
+	) (p *agd.Profile, d *agd.Device, err error) {
+		return nil, nil, profiledb.ErrDeviceNotFound
+	}
+
+	return db
 }
 
 // readRespBody is a helper function that reads and returns body from response.
@@ -193,3 +581,14 @@ func readRespBody(t testing.TB, resp *http.Response) (body string) {
 
 	return string(buf)
 }
+
+// testHostItem is a [agdcache.HostItem] for tests.
+type testHostItem string
+
+// type check
+var _ agdcache.HostItem = testHostItem("")
+
+// CachedHost implements the [agdcache.HostItem] interface for testHostItem.
+func (i testHostItem) CachedHost() (host string) {
+	return string(i)
+}
@@ -0,0 +1,412 @@
+package debugsvc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/miekg/dns"
+)
+
+// Query parameters of the GET /debug/api/profiles/effective HTTP API.
+const (
+	queryParamClientIP = "client_ip"
+	queryParamDeviceID = "device_id"
+)
+
+// effectivePolicyHandler serves the effective policy of a device, computed in
+// the same way as the DNS service does it for the requests of the device.
+type effectivePolicyHandler struct {
+	clock      agdtime.Clock
+	profiles   profiledb.Interface
+	quarantine *quarantine.Default
+}
+
+// effectivePolicyResponse describes the response to the GET
+// /debug/api/profiles/effective HTTP API.
+type effectivePolicyResponse struct {
+	// Now is the time at which the policy has been computed.
+	Now time.Time `json:"now"`
+
+	// Filter is the effective filtering configuration.  It is nil if the
+	// requests of the device aren't filtered.
+	Filter *effectiveFilter `json:"filter"`
+
+	// Access is the access configuration of the profile.  It is nil if the
+	// profile has no access settings.
+	Access *effectiveAccess `json:"access"`
+
+	// Ratelimit is the custom rate limit of the profile.
+	Ratelimit *effectiveRatelimit `json:"ratelimit"`
+
+	// AccountLimits are the aggregate limits of the account of the profile.
+	// It is nil if the account has no limits.
+	AccountLimits *effectiveAccountLimits `json:"account_limits"`
+
+	// BlockingMode is the way the blocked responses are constructed.
+	BlockingMode *effectiveBlockingMode `json:"blocking_mode"`
+
+	// QueryTypeActions are the actions taken on the requests with the
+	// corresponding query types.
+	QueryTypeActions map[string]string `json:"query_type_actions"`
+
+	// ProfileID is the ID of the profile of the device.
+	ProfileID agd.ProfileID `json:"profile_id"`
+
+	// AccountID is the ID of the account of the profile, if any.
+	AccountID agd.AccountID `json:"account_id"`
+
+	// DeviceID is the ID of the device.
+	DeviceID agd.DeviceID `json:"device_id"`
+
+	// ECSMode is the EDNS Client Subnet mode of the profile.
+	ECSMode string `json:"ecs_mode"`
+
+	// FilteredResponseTTL is the human-readable TTL of the filtered responses.
+	FilteredResponseTTL string `json:"filtered_response_ttl"`
+
+	// FilteringEnabled is true if the requests of the device are filtered.
+	FilteringEnabled bool `json:"filtering_enabled"`
+
+	// Quarantined is true if the device is quarantined.
+	Quarantined bool `json:"quarantined"`
+
+	// QueryLogEnabled is true if the query log is written for the device.
+	QueryLogEnabled bool `json:"query_log_enabled"`
+
+	// IPLogEnabled is true if the client IP addresses are logged.
+	IPLogEnabled bool `json:"ip_log_enabled"`
+
+	// RequireDNSSEC is true if only the DNSSEC-validated upstream responses
+	// are sent to the device.
+	RequireDNSSEC bool `json:"require_dnssec"`
+}
+
+// effectiveFilter is the effective filtering configuration in an
+// [effectivePolicyResponse].
+type effectiveFilter struct {
+	// BlockUntil is the time until which all requests are blocked.  It is nil
+	// if there is no such override in effect.
+	BlockUntil *time.Time `json:"block_until"`
+
+	// Parental is the effective parental-control configuration.
+	Parental *effectiveParental `json:"parental"`
+
+	// RuleLists are the IDs of the rule lists in effect.
+	RuleLists []filter.ID `json:"rule_lists"`
+
+	// CustomRulesCount is the number of the custom rules in effect.
+	CustomRulesCount int `json:"custom_rules_count"`
+
+	// AllowlistOnly is true if only the explicitly allowed requests are
+	// resolved.
+	AllowlistOnly bool `json:"allowlist_only"`
+
+	// DangerousDomains is true if the dangerous-domains filter is in effect.
+	DangerousDomains bool `json:"dangerous_domains"`
+
+	// NewlyRegisteredDomains is true if the newly-registered domains filter
+	// is in effect.
+	NewlyRegisteredDomains bool `json:"newly_registered_domains"`
+}
+
+// effectiveParental is the effective parental-control configuration in an
+// [effectiveFilter].
+type effectiveParental struct {
+	// BlockedServices are the IDs of the blocked services in effect.
+	BlockedServices []filter.BlockedServiceID `json:"blocked_services"`
+
+	// Enabled is true if the parental control is enabled in the profile.
+	Enabled bool `json:"enabled"`
+
+	// Paused is true if the parental control is paused by its schedule right
+	// now.
+	Paused bool `json:"paused"`
+
+	// Learning is true if the device is in the learning mode, so the
+	// adult-content and blocked-service matches are only recorded.
+	Learning bool `json:"learning"`
+
+	// AdultBlocking is true if the adult-content filter is in effect.
+	AdultBlocking bool `json:"adult_blocking"`
+
+	// SafeSearchGeneral is true if the general safe search is in effect.
+	SafeSearchGeneral bool `json:"safe_search_general"`
+
+	// SafeSearchYouTube is true if the YouTube safe search is in effect.
+	SafeSearchYouTube bool `json:"safe_search_youtube"`
+}
+
+// effectiveAccess is the access configuration in an
+// [effectivePolicyResponse].
+type effectiveAccess struct {
+	AllowedNets          []netip.Prefix `json:"allowed_nets"`
+	BlockedNets          []netip.Prefix `json:"blocked_nets"`
+	AllowedASN           []geoip.ASN    `json:"allowed_asn"`
+	BlockedASN           []geoip.ASN    `json:"blocked_asn"`
+	BlocklistDomainRules []string       `json:"blocklist_domain_rules"`
+}
+
+// effectiveRatelimit is the custom rate limit in an
+// [effectivePolicyResponse].
+type effectiveRatelimit struct {
+	ClientSubnets []netip.Prefix `json:"client_subnets"`
+	RPS           uint32         `json:"rps"`
+	Enabled       bool           `json:"enabled"`
+}
+
+// effectiveAccountLimits are the aggregate limits of an account in an
+// [effectivePolicyResponse].
+type effectiveAccountLimits struct {
+	Tier       agd.AccountTier `json:"tier"`
+	RPS        uint32          `json:"rps"`
+	MaxDevices uint32          `json:"max_devices"`
+}
+
+// effectiveBlockingMode is the blocking mode in an [effectivePolicyResponse].
+type effectiveBlockingMode struct {
+	// Type is the type of the blocking mode.
+	Type string `json:"type"`
+
+	// IPv4 are the custom IPv4 addresses, if any.
+	IPv4 []netip.Addr `json:"ipv4,omitempty"`
+
+	// IPv6 are the custom IPv6 addresses, if any.
+	IPv6 []netip.Addr `json:"ipv6,omitempty"`
+}
+
+// type check
+var _ http.Handler = (*effectivePolicyHandler)(nil)
+
+// ServeHTTP implements the [http.Handler] interface for
+// *effectivePolicyHandler.
+func (h *effectivePolicyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := slogutil.MustLoggerFromContext(ctx)
+
+	p, d, err := h.find(ctx, r)
+	if err != nil {
+		l.DebugContext(ctx, "finding device", slogutil.KeyError, err)
+
+		code := http.StatusBadRequest
+		if errors.Is(err, profiledb.ErrDeviceNotFound) ||
+			errors.Is(err, profiledb.ErrProfileNotFound) {
+			code = http.StatusNotFound
+		}
+
+		http.Error(w, err.Error(), code)
+
+		return
+	}
+
+	resp := h.newResponse(p, d)
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err = json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		l.ErrorContext(ctx, "writing response", slogutil.KeyError, err)
+	}
+}
+
+// find returns the profile and the device identified by the query parameters
+// of r.
+func (h *effectivePolicyHandler) find(
+	ctx context.Context,
+	r *http.Request,
+) (p *agd.Profile, d *agd.Device, err error) {
+	q := r.URL.Query()
+	devIDStr, ipStr := q.Get(queryParamDeviceID), q.Get(queryParamClientIP)
+	switch {
+	case devIDStr != "" && ipStr != "":
+		return nil, nil, fmt.Errorf(
+			"only one of %q and %q must be set",
+			queryParamDeviceID,
+			queryParamClientIP,
+		)
+	case devIDStr != "":
+		var id agd.DeviceID
+		id, err = agd.NewDeviceID(devIDStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", queryParamDeviceID, err)
+		}
+
+		return h.profiles.ProfileByDeviceID(ctx, id)
+	case ipStr != "":
+		var ip netip.Addr
+		ip, err = netip.ParseAddr(ipStr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", queryParamClientIP, err)
+		}
+
+		return h.profiles.ProfileByLinkedIP(ctx, ip)
+	default:
+		return nil, nil, fmt.Errorf(
+			"one of %q and %q must be set",
+			queryParamDeviceID,
+			queryParamClientIP,
+		)
+	}
+}
+
+// newResponse returns the effective policy of d from p.  p and d must not be
+// nil.
+func (h *effectivePolicyHandler) newResponse(
+	p *agd.Profile,
+	d *agd.Device,
+) (resp *effectivePolicyResponse) {
+	now := h.clock.Now()
+
+	resp = &effectivePolicyResponse{
+		Now:                 now,
+		BlockingMode:        newEffectiveBlockingMode(p.BlockingMode),
+		QueryTypeActions:    map[string]string{},
+		ProfileID:           p.ID,
+		AccountID:           p.AccountID,
+		DeviceID:            d.ID,
+		ECSMode:             p.ECSMode.String(),
+		FilteredResponseTTL: p.FilteredResponseTTL.String(),
+		FilteringEnabled:    p.FilteringEnabled && d.FilteringEnabled,
+		Quarantined:         d.Quarantined,
+		QueryLogEnabled:     p.QueryLogEnabled,
+		IPLogEnabled:        p.IPLogEnabled,
+		RequireDNSSEC:       p.RequireDNSSEC,
+	}
+
+	if resp.FilteringEnabled {
+		resp.Filter = newEffectiveFilter(p.FilterConfig, d, now)
+	}
+
+	if h.quarantine != nil && slices.Contains(h.quarantine.DeviceIDs(), d.ID) {
+		resp.Quarantined = true
+	}
+
+	if c := p.Access.Config(); c != nil {
+		resp.Access = &effectiveAccess{
+			AllowedNets:          c.AllowedNets,
+			BlockedNets:          c.BlockedNets,
+			AllowedASN:           c.AllowedASN,
+			BlockedASN:           c.BlockedASN,
+			BlocklistDomainRules: c.BlocklistDomainRules,
+		}
+	}
+
+	if l := p.AccountLimits; l != nil {
+		resp.AccountLimits = &effectiveAccountLimits{
+			Tier:       l.Tier,
+			RPS:        l.RPS,
+			MaxDevices: l.MaxDevices,
+		}
+	}
+
+	rlConf := p.Ratelimiter.Config()
+	resp.Ratelimit = &effectiveRatelimit{
+		ClientSubnets: rlConf.ClientSubnets,
+		RPS:           rlConf.RPS,
+		Enabled:       rlConf.Enabled,
+	}
+
+	for _, qt := range slices.Sorted(maps.Keys(p.QueryTypeActions)) {
+		resp.QueryTypeActions[dns.Type(qt).String()] = p.QueryTypeActions[qt].String()
+	}
+
+	return resp
+}
+
+// newEffectiveFilter returns the filtering configuration from c, which is in
+// effect for d at now, computed in the same way as the filter storage does it.
+// c and d must not be nil.
+func newEffectiveFilter(
+	c *filter.ConfigClient,
+	d *agd.Device,
+	now time.Time,
+) (f *effectiveFilter) {
+	f = &effectiveFilter{
+		Parental:      newEffectiveParental(c.Parental, d, now),
+		RuleLists:     []filter.ID{},
+		AllowlistOnly: c.AllowlistOnly,
+	}
+
+	if now.Before(c.BlockUntil) {
+		f.BlockUntil = &c.BlockUntil
+	}
+
+	if c.RuleList.Enabled {
+		f.RuleLists = append(f.RuleLists, c.RuleList.IDs...)
+	}
+
+	if c.Custom.Enabled {
+		f.CustomRulesCount = len(c.Custom.Rules)
+	}
+
+	sb := c.SafeBrowsing
+	f.DangerousDomains = sb.Enabled && sb.DangerousDomainsEnabled
+	f.NewlyRegisteredDomains = sb.Enabled && sb.NewlyRegisteredDomainsEnabled
+
+	return f
+}
+
+// newEffectiveParental returns the parental-control configuration from c,
+// which is in effect for d at now.  c and d must not be nil.
+func newEffectiveParental(
+	c *filter.ConfigParental,
+	d *agd.Device,
+	now time.Time,
+) (p *effectiveParental) {
+	p = &effectiveParental{
+		BlockedServices: []filter.BlockedServiceID{},
+		Enabled:         c.Enabled,
+	}
+
+	if !c.Enabled {
+		return p
+	}
+
+	p.Paused = c.PauseSchedule != nil && c.PauseSchedule.Contains(now)
+	if p.Paused {
+		return p
+	}
+
+	p.Learning = d.IsLearning(c.LearningPeriod, now)
+	p.AdultBlocking = c.AdultBlockingEnabled
+	p.SafeSearchGeneral = c.SafeSearchGeneralEnabled
+	p.SafeSearchYouTube = c.SafeSearchYouTubeEnabled
+	p.BlockedServices = append(p.BlockedServices, c.BlockedServices...)
+
+	return p
+}
+
+// newEffectiveBlockingMode returns the blocking mode data for m.
+func newEffectiveBlockingMode(m dnsmsg.BlockingMode) (bm *effectiveBlockingMode) {
+	switch m := m.(type) {
+	case *dnsmsg.BlockingModeCustomIP:
+		return &effectiveBlockingMode{
+			Type: "custom_ip",
+			IPv4: m.IPv4,
+			IPv6: m.IPv6,
+		}
+	case *dnsmsg.BlockingModeNXDOMAIN:
+		return &effectiveBlockingMode{Type: "nxdomain"}
+	case *dnsmsg.BlockingModeNullIP:
+		return &effectiveBlockingMode{Type: "null_ip"}
+	case *dnsmsg.BlockingModeREFUSED:
+		return &effectiveBlockingMode{Type: "refused"}
+	default:
+		return &effectiveBlockingMode{Type: fmt.Sprintf("!bad_blocking_mode_%T", m)}
+	}
+}
@@ -0,0 +1,123 @@
+package debugsvc
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// filterHealthHandler serves the refresh statuses of the filters.
+type filterHealthHandler struct {
+	// statuses is the source of the refresh statuses of the filters.
+	statuses *filter.StatusMetrics
+
+	// staleThreshold is the duration since the last successful refresh after
+	// which a filter is considered stale.  If it is zero, filters are never
+	// considered stale.
+	staleThreshold time.Duration
+}
+
+// filterHealthResponse describes the response to the GET /health/filters HTTP
+// API.
+type filterHealthResponse struct {
+	// Filters are the statuses of the filters sorted by their IDs.
+	Filters []*filterHealth `json:"filters"`
+
+	// StaleThreshold is the duration since the last successful refresh after
+	// which a filter is considered stale.
+	StaleThreshold timeutil.Duration `json:"stale_threshold"`
+
+	// Healthy is true if none of the filters are stale.
+	Healthy bool `json:"healthy"`
+}
+
+// filterHealth is the status of a single filter in a [filterHealthResponse].
+type filterHealth struct {
+	// LastRefresh is the time of the last successful refresh.  It is nil if
+	// there were none.
+	LastRefresh *time.Time `json:"last_refresh"`
+
+	// ID is the ID of the filter.
+	ID string `json:"id"`
+
+	// LastError is the error of the last refresh.  It is empty if the last
+	// refresh was successful.
+	LastError string `json:"last_error,omitempty"`
+
+	// Staleness is the duration since the last successful refresh.  It is zero
+	// if there were none.
+	Staleness timeutil.Duration `json:"staleness"`
+
+	// RuleCount is the number of rules after the last successful refresh.
+	RuleCount int `json:"rule_count"`
+
+	// Stale is true if there were no successful refreshes or if Staleness is
+	// greater than the threshold.
+	Stale bool `json:"stale"`
+}
+
+// type check
+var _ http.Handler = (*filterHealthHandler)(nil)
+
+// ServeHTTP implements the [http.Handler] interface for *filterHealthHandler.
+func (h *filterHealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := slogutil.MustLoggerFromContext(ctx)
+
+	resp := h.response(time.Now())
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	if !resp.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		l.ErrorContext(ctx, "writing response", slogutil.KeyError, err)
+	}
+}
+
+// response returns the response with the statuses of the filters at now.
+func (h *filterHealthHandler) response(now time.Time) (resp *filterHealthResponse) {
+	statuses := h.statuses.Statuses()
+	resp = &filterHealthResponse{
+		Filters:        make([]*filterHealth, 0, len(statuses)),
+		StaleThreshold: timeutil.Duration{Duration: h.staleThreshold},
+		Healthy:        true,
+	}
+
+	for id, s := range statuses {
+		fh := &filterHealth{
+			ID:        id,
+			RuleCount: s.RuleCount,
+			Stale:     s.LastRefresh.IsZero(),
+		}
+
+		if s.LastError != nil {
+			fh.LastError = s.LastError.Error()
+		}
+
+		if !fh.Stale {
+			fh.LastRefresh = &s.LastRefresh
+			fh.Staleness = timeutil.Duration{Duration: now.Sub(s.LastRefresh)}
+			fh.Stale = h.staleThreshold > 0 && fh.Staleness.Duration > h.staleThreshold
+		}
+
+		resp.Healthy = resp.Healthy && !fh.Stale
+		resp.Filters = append(resp.Filters, fh)
+	}
+
+	slices.SortFunc(resp.Filters, func(a, b *filterHealth) (res int) {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return resp
+}
@@ -0,0 +1,159 @@
+package debugsvc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/maintenance"
+	"github.com/AdguardTeam/AdGuardDNS/internal/noderole"
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/netutil/httputil"
+)
+
+// healthCheckHandler is the health-check handler that takes the maintenance
+// mode of the server groups and the role of the node into account.
+type healthCheckHandler struct {
+	// manager, if not nil, is used to check if the node is being drained.
+	manager *maintenance.Manager
+
+	// role, if not nil, is used to check if the node is a standby one.
+	role *noderole.Manager
+}
+
+// type check
+var _ http.Handler = (*healthCheckHandler)(nil)
+
+// ServeHTTP implements the [http.Handler] interface for *healthCheckHandler.
+func (h *healthCheckHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.manager != nil && !h.manager.IsHealthy() {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+
+		return
+	}
+
+	if h.role != nil && !h.role.IsHealthy() {
+		http.Error(w, "standby", http.StatusServiceUnavailable)
+
+		return
+	}
+
+	httputil.HealthCheckHandler.ServeHTTP(w, r)
+}
+
+// maintenanceHandler serves and switches the maintenance mode of the server
+// groups.
+type maintenanceHandler struct {
+	manager *maintenance.Manager
+}
+
+// maintenanceRequest describes the request to the POST /debug/api/maintenance
+// HTTP API.
+type maintenanceRequest struct {
+	// ServerGroups maps the names of the server groups to the new states of
+	// their maintenance mode.
+	ServerGroups map[agd.ServerGroupName]bool `json:"server_groups"`
+}
+
+// maintenanceResponse describes the response to the GET and POST
+// /debug/api/maintenance HTTP APIs.
+type maintenanceResponse struct {
+	// ServerGroups are the states of all server groups.
+	ServerGroups []*maintenanceState `json:"server_groups"`
+}
+
+// maintenanceState is the maintenance-mode state of a single server group in a
+// [maintenanceResponse].
+type maintenanceState struct {
+	// Name is the name of the server group.
+	Name agd.ServerGroupName `json:"name"`
+
+	// RetryAfter is the human-readable duration after which the clients are
+	// advised to retry.
+	RetryAfter string `json:"retry_after"`
+
+	// FailHealthCheck is true if the maintenance mode of the server group
+	// fails the health check.
+	FailHealthCheck bool `json:"fail_health_check"`
+
+	// Enabled is true if the server group is in the maintenance mode.
+	Enabled bool `json:"enabled"`
+}
+
+// type check
+var _ http.Handler = (*maintenanceHandler)(nil)
+
+// ServeHTTP implements the [http.Handler] interface for *maintenanceHandler.
+func (h *maintenanceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := slogutil.MustLoggerFromContext(ctx)
+
+	if r.Method == http.MethodPost {
+		err := h.update(ctx, l, r)
+		if err != nil {
+			l.ErrorContext(ctx, "updating maintenance mode", slogutil.KeyError, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	resp := &maintenanceResponse{
+		ServerGroups: []*maintenanceState{},
+	}
+
+	for _, s := range h.manager.States() {
+		resp.ServerGroups = append(resp.ServerGroups, &maintenanceState{
+			Name:            s.Name,
+			RetryAfter:      s.RetryAfter.String(),
+			FailHealthCheck: s.FailHealthCheck,
+			Enabled:         s.Enabled,
+		})
+	}
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		l.ErrorContext(ctx, "writing response", slogutil.KeyError, err)
+	}
+}
+
+// update decodes the request and switches the maintenance mode of the server
+// groups.  No server groups are switched if the request contains unknown ones.
+func (h *maintenanceHandler) update(
+	ctx context.Context,
+	l *slog.Logger,
+	r *http.Request,
+) (err error) {
+	req := &maintenanceRequest{}
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return fmt.Errorf("decoding request: %w", err)
+	}
+
+	known := container.NewMapSet[agd.ServerGroupName]()
+	for _, s := range h.manager.States() {
+		known.Add(s.Name)
+	}
+
+	for name := range req.ServerGroups {
+		if !known.Has(name) {
+			return fmt.Errorf("server_groups: %w: %q", maintenance.ErrNoServerGroup, name)
+		}
+	}
+
+	for name, enabled := range req.ServerGroups {
+		// Don't check the error, since the names have been validated above.
+		_ = h.manager.SetEnabled(name, enabled)
+
+		l.InfoContext(ctx, "switched maintenance mode", "server_group", name, "enabled", enabled)
+	}
+
+	return nil
+}
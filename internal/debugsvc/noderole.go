@@ -0,0 +1,76 @@
+package debugsvc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/noderole"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+)
+
+// nodeRoleHandler serves and switches the role of the node within a pair.
+type nodeRoleHandler struct {
+	manager *noderole.Manager
+}
+
+// nodeRoleRequest describes the request to the POST /debug/api/role HTTP API.
+type nodeRoleRequest struct {
+	// Role is the new role of the node.  See [noderole.ParseRole].
+	Role string `json:"role"`
+}
+
+// nodeRoleResponse describes the response to the GET and POST /debug/api/role
+// HTTP APIs.
+type nodeRoleResponse struct {
+	// Role is the current role of the node.
+	Role string `json:"role"`
+}
+
+// type check
+var _ http.Handler = (*nodeRoleHandler)(nil)
+
+// ServeHTTP implements the [http.Handler] interface for *nodeRoleHandler.
+func (h *nodeRoleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := slogutil.MustLoggerFromContext(ctx)
+
+	if r.Method == http.MethodPost {
+		err := h.update(r)
+		if err != nil {
+			l.ErrorContext(ctx, "updating node role", slogutil.KeyError, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	resp := &nodeRoleResponse{
+		Role: h.manager.Role().String(),
+	}
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		l.ErrorContext(ctx, "writing response", slogutil.KeyError, err)
+	}
+}
+
+// update decodes the request and switches the role of the node.
+func (h *nodeRoleHandler) update(r *http.Request) (err error) {
+	req := &nodeRoleRequest{}
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return fmt.Errorf("decoding request: %w", err)
+	}
+
+	role, err := noderole.ParseRole(req.Role)
+	if err != nil {
+		return fmt.Errorf("role: %w", err)
+	}
+
+	// Don't wrap the error, because it's informative enough as is.
+	return h.manager.SetRole(r.Context(), role)
+}
@@ -0,0 +1,47 @@
+package debugsvc
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/poisonpill"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+)
+
+// poisonPillHandler serves the most recent queries that have caused panics and
+// the active drop rules.
+type poisonPillHandler struct {
+	guard *poisonpill.Guard
+}
+
+// poisonPillResponse describes the response to the GET /debug/api/poisonpill
+// HTTP API.
+type poisonPillResponse struct {
+	// Records are the records of the most recent panics, the oldest first.
+	Records []*poisonpill.Record `json:"records"`
+
+	// DropRules are the active drop rules, the earliest to expire first.
+	DropRules []*poisonpill.DropRule `json:"drop_rules"`
+}
+
+// type check
+var _ http.Handler = (*poisonPillHandler)(nil)
+
+// ServeHTTP implements the [http.Handler] interface for *poisonPillHandler.
+func (h *poisonPillHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := slogutil.MustLoggerFromContext(ctx)
+
+	resp := &poisonPillResponse{
+		Records:   h.guard.Recent(),
+		DropRules: h.guard.DropRules(),
+	}
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		l.ErrorContext(ctx, "writing response", slogutil.KeyError, err)
+	}
+}
@@ -0,0 +1,92 @@
+package debugsvc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+)
+
+// quarantineHandler serves and switches the quarantine of the devices.
+type quarantineHandler struct {
+	quarantine *quarantine.Default
+}
+
+// quarantineRequest describes the request to the POST /debug/api/quarantine
+// HTTP API.
+type quarantineRequest struct {
+	// DeviceIDs maps the IDs of the devices to their new quarantine states.
+	DeviceIDs map[agd.DeviceID]bool `json:"device_ids"`
+}
+
+// quarantineResponse describes the response to the GET and POST
+// /debug/api/quarantine HTTP APIs.
+type quarantineResponse struct {
+	// DeviceIDs are the IDs of the devices quarantined using this API.
+	DeviceIDs []agd.DeviceID `json:"device_ids"`
+}
+
+// type check
+var _ http.Handler = (*quarantineHandler)(nil)
+
+// ServeHTTP implements the [http.Handler] interface for *quarantineHandler.
+func (h *quarantineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := slogutil.MustLoggerFromContext(ctx)
+
+	if r.Method == http.MethodPost {
+		err := h.update(ctx, l, r)
+		if err != nil {
+			l.ErrorContext(ctx, "updating quarantine", slogutil.KeyError, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	resp := &quarantineResponse{
+		DeviceIDs: h.quarantine.DeviceIDs(),
+	}
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		l.ErrorContext(ctx, "writing response", slogutil.KeyError, err)
+	}
+}
+
+// update decodes the request and switches the quarantine of the devices.  No
+// devices are switched if the request contains invalid IDs.
+func (h *quarantineHandler) update(
+	ctx context.Context,
+	l *slog.Logger,
+	r *http.Request,
+) (err error) {
+	req := &quarantineRequest{}
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return fmt.Errorf("decoding request: %w", err)
+	}
+
+	for id := range req.DeviceIDs {
+		_, err = agd.NewDeviceID(string(id))
+		if err != nil {
+			return fmt.Errorf("device_ids: %w", err)
+		}
+	}
+
+	for id, quarantined := range req.DeviceIDs {
+		h.quarantine.SetQuarantined(ctx, id, quarantined)
+
+		l.InfoContext(ctx, "switched quarantine", "device_id", id, "quarantined", quarantined)
+	}
+
+	return nil
+}
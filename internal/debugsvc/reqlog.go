@@ -0,0 +1,131 @@
+package debugsvc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/reqlog"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/timeutil"
+)
+
+// requestLogHandler serves and sets the targets of the structured per-request
+// debug log.
+type requestLogHandler struct {
+	log *reqlog.Default
+}
+
+// requestLogRequest describes the request to the POST /debug/api/reqlog HTTP
+// API.
+type requestLogRequest struct {
+	// ProfileIDs are the IDs of the profiles, all requests of which are
+	// logged.
+	ProfileIDs []agd.ProfileID `json:"profile_ids"`
+
+	// DeviceIDs are the IDs of the devices, all requests of which are logged.
+	DeviceIDs []agd.DeviceID `json:"device_ids"`
+
+	// Duration is the human-readable duration for which the targets are used.
+	// It is ignored if there are no targets.
+	Duration timeutil.Duration `json:"duration"`
+}
+
+// requestLogResponse describes the response to the GET and POST
+// /debug/api/reqlog HTTP APIs.
+type requestLogResponse struct {
+	// Expires is the time after which the targets are no longer used.  It is
+	// nil if there are no targets.
+	Expires *time.Time `json:"expires"`
+
+	// ProfileIDs are the IDs of the targeted profiles.
+	ProfileIDs []agd.ProfileID `json:"profile_ids"`
+
+	// DeviceIDs are the IDs of the targeted devices.
+	DeviceIDs []agd.DeviceID `json:"device_ids"`
+}
+
+// type check
+var _ http.Handler = (*requestLogHandler)(nil)
+
+// ServeHTTP implements the [http.Handler] interface for *requestLogHandler.
+func (h *requestLogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := slogutil.MustLoggerFromContext(ctx)
+
+	if r.Method == http.MethodPost {
+		err := h.update(ctx, l, r)
+		if err != nil {
+			l.ErrorContext(ctx, "updating request log targets", slogutil.KeyError, err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	resp := &requestLogResponse{
+		ProfileIDs: []agd.ProfileID{},
+		DeviceIDs:  []agd.DeviceID{},
+	}
+
+	if t := h.log.Targets(); t != nil {
+		resp.Expires = &t.Expires
+		resp.ProfileIDs = append(resp.ProfileIDs, t.ProfileIDs...)
+		resp.DeviceIDs = append(resp.DeviceIDs, t.DeviceIDs...)
+	}
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		l.ErrorContext(ctx, "writing response", slogutil.KeyError, err)
+	}
+}
+
+// update decodes the request and sets the targets of the log.  The targets are
+// removed if the request contains none.
+func (h *requestLogHandler) update(
+	ctx context.Context,
+	l *slog.Logger,
+	r *http.Request,
+) (err error) {
+	req := &requestLogRequest{}
+	err = json.NewDecoder(r.Body).Decode(req)
+	if err != nil {
+		return fmt.Errorf("decoding request: %w", err)
+	}
+
+	if len(req.ProfileIDs) == 0 && len(req.DeviceIDs) == 0 {
+		h.log.SetTargets(nil)
+		l.InfoContext(ctx, "removed request log targets")
+
+		return nil
+	}
+
+	if req.Duration.Duration <= 0 {
+		return fmt.Errorf("duration: %w: %s", errors.ErrNotPositive, req.Duration)
+	}
+
+	expires := time.Now().Add(req.Duration.Duration)
+	h.log.SetTargets(&reqlog.Targets{
+		Expires:    expires,
+		ProfileIDs: req.ProfileIDs,
+		DeviceIDs:  req.DeviceIDs,
+	})
+
+	l.InfoContext(
+		ctx,
+		"set request log targets",
+		"profile_ids", req.ProfileIDs,
+		"device_ids", req.DeviceIDs,
+		"expires", expires,
+	)
+
+	return nil
+}
@@ -12,20 +12,48 @@ import (
 
 // Path pattern constants.
 const (
-	PathPatternDNSDBCSV        = "/dnsdb/csv"
-	PathPatternDebugAPICache   = "/debug/api/cache/clear"
-	PathPatternDebugAPIRefresh = "/debug/api/refresh"
-	PathPatternHealthCheck     = "/health-check"
-	PathPatternMetrics         = "/metrics"
+	PathPatternDNSDBCSV                  = "/dnsdb/csv"
+	PathPatternDebugAPIAudit             = "/debug/api/audit"
+	PathPatternDebugAPICache             = "/debug/api/cache/clear"
+	PathPatternDebugAPICacheFlush        = "/debug/api/cache/flush"
+	PathPatternDebugAPIMaintenance       = "/debug/api/maintenance"
+	PathPatternDebugAPIPoisonPill        = "/debug/api/poisonpill"
+	PathPatternDebugAPIProfilesEffective = "/debug/api/profiles/effective"
+	PathPatternDebugAPIProfilesTop       = "/debug/api/profiles/top"
+	PathPatternDebugAPIQuarantine        = "/debug/api/quarantine"
+	PathPatternDebugAPIRefresh           = "/debug/api/refresh"
+	PathPatternDebugAPIRequestLog        = "/debug/api/reqlog"
+	PathPatternDebugAPIRole              = "/debug/api/role"
+	PathPatternDebugAPITLSSessionTickets = "/debug/api/tls/session_tickets"
+	PathPatternDebugAPIUpstreamGroups    = "/debug/api/upstream/groups"
+	PathPatternHealthCheck               = "/health-check"
+	PathPatternHealthFilters             = "/health/filters"
+	PathPatternMetrics                   = "/metrics"
 )
 
 // Route pattern constants.
 const (
-	routePatternDNSDBCSV        = http.MethodPost + " " + PathPatternDNSDBCSV
-	routePatternDebugAPICache   = http.MethodPost + " " + PathPatternDebugAPICache
-	routePatternDebugAPIRefresh = http.MethodPost + " " + PathPatternDebugAPIRefresh
-	routePatternHealthCheck     = http.MethodGet + " " + PathPatternHealthCheck
-	routePatternMetrics         = http.MethodGet + " " + PathPatternMetrics
+	routePatternDNSDBCSV                  = http.MethodPost + " " + PathPatternDNSDBCSV
+	routePatternDebugAPIAudit             = http.MethodGet + " " + PathPatternDebugAPIAudit
+	routePatternDebugAPICache             = http.MethodPost + " " + PathPatternDebugAPICache
+	routePatternDebugAPICacheFlush        = http.MethodPost + " " + PathPatternDebugAPICacheFlush
+	routePatternDebugAPIMaintenanceGet    = http.MethodGet + " " + PathPatternDebugAPIMaintenance
+	routePatternDebugAPIMaintenancePost   = http.MethodPost + " " + PathPatternDebugAPIMaintenance
+	routePatternDebugAPIPoisonPill        = http.MethodGet + " " + PathPatternDebugAPIPoisonPill
+	routePatternDebugAPIProfilesEffective = http.MethodGet + " " + PathPatternDebugAPIProfilesEffective
+	routePatternDebugAPIProfilesTop       = http.MethodGet + " " + PathPatternDebugAPIProfilesTop
+	routePatternDebugAPIQuarantineGet     = http.MethodGet + " " + PathPatternDebugAPIQuarantine
+	routePatternDebugAPIQuarantinePost    = http.MethodPost + " " + PathPatternDebugAPIQuarantine
+	routePatternDebugAPIRefresh           = http.MethodPost + " " + PathPatternDebugAPIRefresh
+	routePatternDebugAPIRequestLogGet     = http.MethodGet + " " + PathPatternDebugAPIRequestLog
+	routePatternDebugAPIRequestLogPost    = http.MethodPost + " " + PathPatternDebugAPIRequestLog
+	routePatternDebugAPIRoleGet           = http.MethodGet + " " + PathPatternDebugAPIRole
+	routePatternDebugAPIRolePost          = http.MethodPost + " " + PathPatternDebugAPIRole
+	routePatternDebugAPITLSSessionTickets = http.MethodGet + " " + PathPatternDebugAPITLSSessionTickets
+	routePatternDebugAPIUpstreamGroups    = http.MethodGet + " " + PathPatternDebugAPIUpstreamGroups
+	routePatternHealthCheck               = http.MethodGet + " " + PathPatternHealthCheck
+	routePatternHealthFilters             = http.MethodGet + " " + PathPatternHealthFilters
+	routePatternMetrics                   = http.MethodGet + " " + PathPatternMetrics
 )
 
 // route further initializes the svc.servers field by adding handlers and
@@ -39,14 +67,69 @@ func (svc *Service) route(c *Config) {
 		router := srv.http.Handler.(httputil.Router)
 		l := svc.logger.With(hdlrGrpKey, handlerGroupAPI)
 
-		router.Handle(
-			routePatternHealthCheck,
-			httputil.NewLogMiddleware(l, slogutil.LevelTrace).Wrap(httputil.HealthCheckHandler),
-		)
+		traceLogMw := httputil.NewLogMiddleware(l, slogutil.LevelTrace)
+		router.Handle(routePatternHealthCheck, traceLogMw.Wrap(svc.healthHdlr))
+
+		if svc.fltHealthHdlr != nil {
+			router.Handle(routePatternHealthFilters, traceLogMw.Wrap(svc.fltHealthHdlr))
+		}
 
 		infoLogMw := httputil.NewLogMiddleware(l, slog.LevelInfo)
-		router.Handle(routePatternDebugAPIRefresh, infoLogMw.Wrap(svc.refrHdlr))
-		router.Handle(routePatternDebugAPICache, infoLogMw.Wrap(svc.cacheHdlr))
+		debugLogMw := httputil.NewLogMiddleware(l, slog.LevelDebug)
+
+		// Record the invocations of all API handlers except the audit-log
+		// viewer itself in the audit log.
+		handle := func(pattern string, logMw httputil.Middleware, h http.Handler) {
+			router.Handle(pattern, httputil.Wrap(h, logMw, svc.auditMw))
+		}
+
+		handle(routePatternDebugAPIRefresh, infoLogMw, svc.refrHdlr)
+		handle(routePatternDebugAPICache, infoLogMw, svc.cacheHdlr)
+		handle(routePatternDebugAPICacheFlush, infoLogMw, svc.cacheFlushHdlr)
+
+		if svc.sessTicketsHdlr != nil {
+			handle(routePatternDebugAPITLSSessionTickets, debugLogMw, svc.sessTicketsHdlr)
+		}
+
+		if svc.maintHdlr != nil {
+			handle(routePatternDebugAPIMaintenanceGet, debugLogMw, svc.maintHdlr)
+			handle(routePatternDebugAPIMaintenancePost, infoLogMw, svc.maintHdlr)
+		}
+
+		if svc.roleHdlr != nil {
+			handle(routePatternDebugAPIRoleGet, debugLogMw, svc.roleHdlr)
+			handle(routePatternDebugAPIRolePost, infoLogMw, svc.roleHdlr)
+		}
+
+		if svc.topProfilesHdlr != nil {
+			handle(routePatternDebugAPIProfilesTop, debugLogMw, svc.topProfilesHdlr)
+		}
+
+		if svc.effPolicyHdlr != nil {
+			handle(routePatternDebugAPIProfilesEffective, debugLogMw, svc.effPolicyHdlr)
+		}
+
+		if svc.reqLogHdlr != nil {
+			handle(routePatternDebugAPIRequestLogGet, debugLogMw, svc.reqLogHdlr)
+			handle(routePatternDebugAPIRequestLogPost, infoLogMw, svc.reqLogHdlr)
+		}
+
+		if svc.quarHdlr != nil {
+			handle(routePatternDebugAPIQuarantineGet, debugLogMw, svc.quarHdlr)
+			handle(routePatternDebugAPIQuarantinePost, infoLogMw, svc.quarHdlr)
+		}
+
+		if svc.upsGroupsHdlr != nil {
+			handle(routePatternDebugAPIUpstreamGroups, debugLogMw, svc.upsGroupsHdlr)
+		}
+
+		if svc.poisonPillHdlr != nil {
+			handle(routePatternDebugAPIPoisonPill, debugLogMw, svc.poisonPillHdlr)
+		}
+
+		if svc.auditLogHdlr != nil {
+			router.Handle(routePatternDebugAPIAudit, debugLogMw.Wrap(svc.auditLogHdlr))
+		}
 	}
 
 	if srv := svc.servers[c.DNSDBAddr]; srv != nil {
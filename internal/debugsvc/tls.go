@@ -0,0 +1,59 @@
+package debugsvc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/tlsconfig"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+)
+
+// sessionTicketsHandler serves the information about the TLS session ticket
+// keys in use.
+type sessionTicketsHandler struct {
+	manager *tlsconfig.DefaultManager
+}
+
+// sessionTicketsResponse describes the response to the GET
+// /debug/api/tls/session_tickets HTTP API.
+type sessionTicketsResponse struct {
+	// RotatedAt is the time of the last successful rotation.  It is nil if
+	// there were none.
+	RotatedAt *time.Time `json:"rotated_at"`
+
+	// KeysHash is the hexadecimal hash of the keys in use.  It is empty if
+	// there were no successful rotations.
+	KeysHash string `json:"keys_hash"`
+
+	// NumKeys is the number of the keys in use.
+	NumKeys int `json:"num_keys"`
+}
+
+// type check
+var _ http.Handler = (*sessionTicketsHandler)(nil)
+
+// ServeHTTP implements the [http.Handler] interface for *sessionTicketsHandler.
+func (h *sessionTicketsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := slogutil.MustLoggerFromContext(ctx)
+
+	info := h.manager.SessionTicketsInfo()
+	resp := &sessionTicketsResponse{
+		NumKeys: info.NumKeys,
+	}
+
+	if !info.RotatedAt.IsZero() {
+		resp.RotatedAt = &info.RotatedAt
+		resp.KeysHash = fmt.Sprintf("%08x", info.KeysHash)
+	}
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		l.ErrorContext(ctx, "writing response", slogutil.KeyError, err)
+	}
+}
@@ -0,0 +1,97 @@
+package debugsvc
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/topprofiles"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+)
+
+// topProfilesHandler serves the statistics of the profiles with the most
+// requests, errors, and blocked requests.
+type topProfilesHandler struct {
+	stats *topprofiles.Default
+}
+
+// topProfilesResponse describes the response to the GET
+// /debug/api/profiles/top HTTP API.
+type topProfilesResponse struct {
+	// Start is the start of the window.  It is nil if there were no closed
+	// windows yet.
+	Start *time.Time `json:"start"`
+
+	// End is the end of the window.  It is nil if there were no closed
+	// windows yet.
+	End *time.Time `json:"end"`
+
+	// Requests are the profiles with the most requests.
+	Requests []*topProfile `json:"requests"`
+
+	// Errors are the profiles with the most failed requests.
+	Errors []*topProfile `json:"errors"`
+
+	// Blocked are the profiles with the most blocked requests.
+	Blocked []*topProfile `json:"blocked"`
+}
+
+// topProfile is the statistics of a single profile in a
+// [topProfilesResponse].
+type topProfile struct {
+	// ProfileID is the ID of the profile.
+	ProfileID agd.ProfileID `json:"profile_id"`
+
+	// Count is the estimated number of requests.
+	Count uint64 `json:"count"`
+
+	// Rate is the number of requests per second for the requests list and the
+	// ratio to all requests of the profile for the other lists.
+	Rate float64 `json:"rate"`
+}
+
+// type check
+var _ http.Handler = (*topProfilesHandler)(nil)
+
+// ServeHTTP implements the [http.Handler] interface for *topProfilesHandler.
+func (h *topProfilesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := slogutil.MustLoggerFromContext(ctx)
+
+	resp := &topProfilesResponse{
+		Requests: []*topProfile{},
+		Errors:   []*topProfile{},
+		Blocked:  []*topProfile{},
+	}
+
+	if s := h.stats.Snapshot(); s != nil {
+		resp.Start, resp.End = &s.Start, &s.End
+		resp.Requests = appendTopProfiles(resp.Requests, s.Requests)
+		resp.Errors = appendTopProfiles(resp.Errors, s.Errors)
+		resp.Blocked = appendTopProfiles(resp.Blocked, s.Blocked)
+	}
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		l.ErrorContext(ctx, "writing response", slogutil.KeyError, err)
+	}
+}
+
+// appendTopProfiles appends the response data for counts to orig and returns
+// the result.
+func appendTopProfiles(orig []*topProfile, counts []*topprofiles.ProfileCount) (res []*topProfile) {
+	res = orig
+	for _, c := range counts {
+		res = append(res, &topProfile{
+			ProfileID: c.ProfileID,
+			Count:     c.Count,
+			Rate:      c.Rate,
+		})
+	}
+
+	return res
+}
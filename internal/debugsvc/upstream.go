@@ -0,0 +1,61 @@
+package debugsvc
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/forward"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+)
+
+// upstreamGroupsHandler serves the states of the circuit breakers of the
+// upstream groups.
+type upstreamGroupsHandler struct {
+	forward *forward.Handler
+}
+
+// upstreamGroupsResponse describes the response to the GET
+// /debug/api/upstream/groups HTTP API.
+type upstreamGroupsResponse struct {
+	// Groups are the upstream groups in the order in which they are tried.
+	Groups []*upstreamGroup `json:"groups"`
+}
+
+// upstreamGroup is the status of a single group in an
+// [upstreamGroupsResponse].
+type upstreamGroup struct {
+	// Name is the name of the group.
+	Name string `json:"name"`
+
+	// State is the state of the circuit breaker of the group.
+	State string `json:"state"`
+}
+
+// type check
+var _ http.Handler = (*upstreamGroupsHandler)(nil)
+
+// ServeHTTP implements the [http.Handler] interface for *upstreamGroupsHandler.
+func (h *upstreamGroupsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := slogutil.MustLoggerFromContext(ctx)
+
+	statuses := h.forward.UpstreamGroups()
+	resp := &upstreamGroupsResponse{
+		Groups: make([]*upstreamGroup, 0, len(statuses)),
+	}
+
+	for _, s := range statuses {
+		resp.Groups = append(resp.Groups, &upstreamGroup{
+			Name:  s.Name,
+			State: s.State.String(),
+		})
+	}
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err := json.NewEncoder(w).Encode(resp)
+	if err != nil {
+		l.ErrorContext(ctx, "writing response", slogutil.KeyError, err)
+	}
+}
@@ -0,0 +1,80 @@
+// Package devicestat contains the rolled-up per-device query statistics, which
+// are computed on the node itself and are therefore available without waiting
+// for the backend aggregation.
+package devicestat
+
+import (
+	"context"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+)
+
+// Period is the period of time for which the statistics are kept.
+const Period = 24 * time.Hour
+
+// Interface is the per-device statistics interface.  All methods must be safe
+// for concurrent use.
+type Interface interface {
+	// Record records a single query from the device with the given ID made at
+	// t.  fltID is the ID of the filter the rules of which matched the query,
+	// if any.  blocked shows if the request or the response were blocked.
+	// learned shows if the query would have been blocked by fltID, if the
+	// device weren't in the learning mode.
+	Record(
+		ctx context.Context,
+		id agd.DeviceID,
+		t time.Time,
+		fltID filter.ID,
+		blocked bool,
+		learned bool,
+	)
+
+	// Stats returns the statistics of the device with the given ID for the
+	// last [Period].  s must not be nil.
+	Stats(ctx context.Context, id agd.DeviceID) (s *Stats)
+}
+
+// Stats are the rolled-up query statistics of a single device.
+type Stats struct {
+	// Categories maps the IDs of filters to the numbers of queries blocked by
+	// them.  It is never nil.
+	Categories map[filter.ID]uint64
+
+	// Learned maps the IDs of parental-control filters to the numbers of
+	// queries that would have been blocked by them, if the device weren't in
+	// the learning mode.  It is never nil.
+	Learned map[filter.ID]uint64
+
+	// Total is the total number of queries.
+	Total uint64
+
+	// Blocked is the number of blocked queries.
+	Blocked uint64
+}
+
+// type check
+var _ Interface = Empty{}
+
+// Empty is an [Interface] implementation that does nothing.
+type Empty struct{}
+
+// Record implements the [Interface] interface for Empty.
+func (Empty) Record(
+	_ context.Context,
+	_ agd.DeviceID,
+	_ time.Time,
+	_ filter.ID,
+	_ bool,
+	_ bool,
+) {
+}
+
+// Stats implements the [Interface] interface for Empty.  s is always empty.
+func (Empty) Stats(_ context.Context, _ agd.DeviceID) (s *Stats) {
+	return &Stats{
+		Categories: map[filter.ID]uint64{},
+		Learned:    map[filter.ID]uint64{},
+	}
+}
@@ -0,0 +1,212 @@
+package devicestat
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+)
+
+// bucketDuration is the duration of a single statistics bucket.
+const bucketDuration = time.Hour
+
+// bucketsNum is the number of buckets kept for each device.
+const bucketsNum = int64(Period / bucketDuration)
+
+// RuntimeConfig is the configuration structure for a runtime per-device
+// statistics storage.
+type RuntimeConfig struct {
+	// Clock is used to get the current time.  It must not be nil.
+	Clock agdtime.Clock
+
+	// MaxDevices is the maximum number of devices for which the statistics are
+	// kept.  Queries of new devices are not recorded once this number is
+	// reached until the statistics of some devices become stale.  It must be
+	// positive.
+	MaxDevices int
+}
+
+// Runtime is the per-device statistics storage that keeps the statistics in
+// memory.  The statistics are not persistent.
+type Runtime struct {
+	clock agdtime.Clock
+
+	// mu protects devices and lastPurge.
+	mu *sync.Mutex
+
+	// devices are the statistics of the devices that made queries during the
+	// last [Period].
+	devices map[agd.DeviceID]*deviceCounters
+
+	// lastPurge is the number of the bucket in which the stale devices were
+	// last removed.
+	lastPurge int64
+
+	maxDevices int
+}
+
+// NewRuntime returns a new properly initialized *Runtime.  c must not be nil
+// and must be valid.
+func NewRuntime(c *RuntimeConfig) (r *Runtime) {
+	return &Runtime{
+		clock:      c.Clock,
+		mu:         &sync.Mutex{},
+		devices:    map[agd.DeviceID]*deviceCounters{},
+		maxDevices: c.MaxDevices,
+	}
+}
+
+// deviceCounters are the query counters of a single device for the last
+// [Period].
+type deviceCounters struct {
+	// buckets are the counters for each [bucketDuration] of the [Period].  The
+	// bucket with number n is stored at index n % [bucketsNum].
+	buckets [bucketsNum]bucket
+
+	// last is the number of the most recent bucket with queries.
+	last int64
+}
+
+// bucket contains the counters for a single [bucketDuration].
+type bucket struct {
+	// categories maps the IDs of filters to the numbers of queries blocked by
+	// them.  It is nil if there were no such queries.
+	categories map[filter.ID]uint64
+
+	// learned maps the IDs of filters to the numbers of queries that would
+	// have been blocked by them, if the device weren't in the learning mode.
+	// It is nil if there were no such queries.
+	learned map[filter.ID]uint64
+
+	// num is the number of the bucket since the Unix epoch.
+	num int64
+
+	// total is the total number of queries.
+	total uint64
+
+	// blocked is the number of blocked queries.
+	blocked uint64
+}
+
+// bucketNum returns the number of the bucket for t.
+func bucketNum(t time.Time) (n int64) {
+	return t.Unix() / int64(bucketDuration/time.Second)
+}
+
+// type check
+var _ Interface = (*Runtime)(nil)
+
+// Record implements the [Interface] interface for *Runtime.
+func (r *Runtime) Record(
+	_ context.Context,
+	id agd.DeviceID,
+	t time.Time,
+	fltID filter.ID,
+	blocked bool,
+	learned bool,
+) {
+	if id == "" {
+		return
+	}
+
+	n := bucketNum(t)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.purgeStale(bucketNum(r.clock.Now()))
+
+	dc := r.devices[id]
+	if dc == nil {
+		if len(r.devices) >= r.maxDevices {
+			return
+		}
+
+		dc = &deviceCounters{}
+		r.devices[id] = dc
+	}
+
+	b := &dc.buckets[n%bucketsNum]
+	if b.num > n {
+		// A very late record from a bucket that has already been reused.
+		return
+	} else if b.num < n {
+		*b = bucket{
+			num: n,
+		}
+	}
+
+	b.total++
+	if blocked {
+		b.blocked++
+		if fltID != filter.IDNone {
+			if b.categories == nil {
+				b.categories = map[filter.ID]uint64{}
+			}
+
+			b.categories[fltID]++
+		}
+	} else if learned && fltID != filter.IDNone {
+		if b.learned == nil {
+			b.learned = map[filter.ID]uint64{}
+		}
+
+		b.learned[fltID]++
+	}
+
+	dc.last = max(dc.last, n)
+}
+
+// purgeStale removes the devices that haven't made any queries during the last
+// [Period].  It only does that once per bucket.  r.mu must be locked.
+func (r *Runtime) purgeStale(now int64) {
+	if r.lastPurge == now {
+		return
+	}
+
+	r.lastPurge = now
+	for id, dc := range r.devices {
+		if dc.last <= now-bucketsNum {
+			delete(r.devices, id)
+		}
+	}
+}
+
+// Stats implements the [Interface] interface for *Runtime.
+func (r *Runtime) Stats(_ context.Context, id agd.DeviceID) (s *Stats) {
+	s = &Stats{
+		Categories: map[filter.ID]uint64{},
+		Learned:    map[filter.ID]uint64{},
+	}
+
+	now := bucketNum(r.clock.Now())
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	dc := r.devices[id]
+	if dc == nil {
+		return s
+	}
+
+	for _, b := range dc.buckets {
+		if b.num <= now-bucketsNum || b.num > now {
+			continue
+		}
+
+		s.Total += b.total
+		s.Blocked += b.blocked
+		for fltID, cnt := range b.categories {
+			s.Categories[fltID] += cnt
+		}
+
+		for fltID, cnt := range b.learned {
+			s.Learned[fltID] += cnt
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,94 @@
+package devicestat_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/stretchr/testify/assert"
+)
+
+// Common constants for tests.
+const (
+	devID      agd.DeviceID = "dev1234"
+	otherDevID agd.DeviceID = "dev5678"
+)
+
+func TestRuntime(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	clock := &agdtest.Clock{
+		OnNow: func() (n time.Time) { return now },
+	}
+
+	r := devicestat.NewRuntime(&devicestat.RuntimeConfig{
+		Clock:      clock,
+		MaxDevices: 1,
+	})
+
+	ctx := context.Background()
+	r.Record(ctx, devID, now, filter.IDNone, false, false)
+	r.Record(ctx, devID, now, filter.IDAdultBlocking, true, false)
+	r.Record(ctx, devID, now, filter.IDAdultBlocking, true, false)
+
+	// Should not be recorded, since the maximum number of devices is reached.
+	r.Record(ctx, otherDevID, now, filter.IDNone, false, false)
+
+	now = now.Add(time.Hour)
+	r.Record(ctx, devID, now, filter.IDSafeBrowsing, true, false)
+	r.Record(ctx, devID, now, filter.IDCustom, false, false)
+	r.Record(ctx, devID, now, filter.IDBlockedService, false, true)
+
+	assert.Equal(t, &devicestat.Stats{
+		Categories: map[filter.ID]uint64{
+			filter.IDAdultBlocking: 2,
+			filter.IDSafeBrowsing:  1,
+		},
+		Learned: map[filter.ID]uint64{
+			filter.IDBlockedService: 1,
+		},
+		Total:   6,
+		Blocked: 3,
+	}, r.Stats(ctx, devID))
+
+	assert.Equal(t, &devicestat.Stats{
+		Categories: map[filter.ID]uint64{},
+		Learned:    map[filter.ID]uint64{},
+	}, r.Stats(ctx, otherDevID))
+
+	// The first bucket becomes stale.
+	now = start.Add(devicestat.Period)
+
+	assert.Equal(t, &devicestat.Stats{
+		Categories: map[filter.ID]uint64{
+			filter.IDSafeBrowsing: 1,
+		},
+		Learned: map[filter.ID]uint64{
+			filter.IDBlockedService: 1,
+		},
+		Total:   3,
+		Blocked: 1,
+	}, r.Stats(ctx, devID))
+
+	// The device becomes stale and is removed, so that the other device can
+	// be recorded.
+	now = start.Add(devicestat.Period + time.Hour)
+	r.Record(ctx, otherDevID, now, filter.IDNone, false, false)
+
+	assert.Equal(t, &devicestat.Stats{
+		Categories: map[filter.ID]uint64{},
+		Learned:    map[filter.ID]uint64{},
+	}, r.Stats(ctx, devID))
+
+	assert.Equal(t, &devicestat.Stats{
+		Categories: map[filter.ID]uint64{},
+		Learned:    map[filter.ID]uint64{},
+		Total:      1,
+	}, r.Stats(ctx, otherDevID))
+}
@@ -0,0 +1,31 @@
+// Package dhcplease contains the sources of DHCP leases that are used to
+// identify the devices sending plain-DNS queries from local networks, for
+// example when AdGuard DNS is installed on a router.
+package dhcplease
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// Interface is the interface for the sources of DHCP leases.
+type Interface interface {
+	// DeviceID returns the ID of the device to which the DHCP server has leased
+	// ip.  id is empty if there is no such active lease or if there is no
+	// device associated with it.
+	DeviceID(ctx context.Context, ip netip.Addr) (id agd.DeviceID, err error)
+}
+
+// type check
+var _ Interface = Empty{}
+
+// Empty is an [Interface] implementation that never finds any devices.
+type Empty struct{}
+
+// DeviceID implements the [Interface] interface for Empty.  It always returns
+// an empty ID and nil.
+func (Empty) DeviceID(_ context.Context, _ netip.Addr) (id agd.DeviceID, err error) {
+	return "", nil
+}
@@ -0,0 +1,174 @@
+package dhcplease
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"sync"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// FileConfig is the configuration structure for a *File.
+type FileConfig struct {
+	// Logger is used to log the operation of the lease source.  It must not be
+	// nil.
+	Logger *slog.Logger
+
+	// Clock is used to check the expiry of the leases.  It must not be nil.
+	Clock agdtime.Clock
+
+	// ErrColl is used to collect the refresh errors.  It must not be nil.
+	ErrColl errcoll.Interface
+
+	// DeviceIDs maps the hardware addresses of the clients, in the format
+	// returned by [net.HardwareAddr.String], to the IDs of their devices.
+	DeviceIDs map[string]agd.DeviceID
+
+	// Path is the path to the lease file.  It must not be empty.
+	Path string
+
+	// Format is the format of the lease file.  It must be valid.
+	Format Format
+
+	// HostnameDeviceIDs, if true, means that the hostnames of the leases that
+	// are valid device IDs are used as such when there is no device ID for the
+	// hardware address of the client in DeviceIDs.
+	HostnameDeviceIDs bool
+}
+
+// File is an [Interface] implementation that reads the leases from the lease
+// file of a DHCP server.  It should be initially refreshed before use.
+type File struct {
+	logger    *slog.Logger
+	clock     agdtime.Clock
+	errColl   errcoll.Interface
+	deviceIDs map[string]agd.DeviceID
+	path      string
+
+	// mu protects leases.
+	mu *sync.RWMutex
+
+	leases map[netip.Addr]*lease
+
+	format            Format
+	hostnameDeviceIDs bool
+}
+
+// NewFile returns a new properly initialized *File.  c must be valid.
+func NewFile(c *FileConfig) (f *File) {
+	return &File{
+		logger:            c.Logger,
+		clock:             c.Clock,
+		errColl:           c.ErrColl,
+		deviceIDs:         c.DeviceIDs,
+		path:              c.Path,
+		format:            c.Format,
+		hostnameDeviceIDs: c.HostnameDeviceIDs,
+		mu:                &sync.RWMutex{},
+		leases:            map[netip.Addr]*lease{},
+	}
+}
+
+// type check
+var _ Interface = (*File)(nil)
+
+// DeviceID implements the [Interface] interface for *File.
+func (f *File) DeviceID(ctx context.Context, ip netip.Addr) (id agd.DeviceID, err error) {
+	l := f.lease(ip)
+	if l == nil || !l.isActive(f.clock.Now()) {
+		return "", nil
+	}
+
+	if l.hwAddr != nil {
+		var ok bool
+		id, ok = f.deviceIDs[l.hwAddr.String()]
+		if ok {
+			return id, nil
+		}
+	}
+
+	if !f.hostnameDeviceIDs || l.hostname == "" {
+		return "", nil
+	}
+
+	id, err = agd.NewDeviceID(l.hostname)
+	if err != nil {
+		// Most hostnames aren't device IDs, so don't return the error.
+		optslog.Debug2(
+			ctx,
+			f.logger,
+			"hostname is not a device id",
+			"ip", ip,
+			"hostname", l.hostname,
+		)
+
+		return "", nil
+	}
+
+	return id, nil
+}
+
+// lease returns the lease for ip, if any.
+func (f *File) lease(ip netip.Addr) (l *lease) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.leases[ip.Unmap()]
+}
+
+// type check
+var _ agdservice.Refresher = (*File)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for *File.  It
+// rereads the lease file.
+func (f *File) Refresh(ctx context.Context) (err error) {
+	f.logger.DebugContext(ctx, "refresh started")
+	defer f.logger.DebugContext(ctx, "refresh finished")
+
+	leases, err := f.readLeases()
+	if err != nil {
+		errcoll.Collect(ctx, f.errColl, f.logger, "refreshing dhcp leases", err)
+
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	byIP := make(map[netip.Addr]*lease, len(leases))
+	for _, l := range leases {
+		byIP[l.ip.Unmap()] = l
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.leases = byIP
+
+	f.logger.InfoContext(ctx, "refresh successful", "num_leases", len(byIP))
+
+	return nil
+}
+
+// readLeases reads and parses the lease file.
+func (f *File) readLeases() (leases []*lease, err error) {
+	// #nosec G304 -- Trust the path, since it's given by the operator.
+	file, err := os.Open(f.path)
+	if err != nil {
+		return nil, fmt.Errorf("opening lease file: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, file.Close()) }()
+
+	leases, err = f.format.parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s lease file: %w", f.format, err)
+	}
+
+	return leases, nil
+}
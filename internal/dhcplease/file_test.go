@@ -0,0 +1,120 @@
+package dhcplease_test
+
+import (
+	"context"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// testLeases is the common content of the Kea lease file for tests.
+const testLeases = `address,hwaddr,expire,hostname,state
+192.168.1.10,00:11:22:33:44:55,1700000000,laptop,0
+192.168.1.11,00:11:22:33:44:66,1700000000,phone123,0
+192.168.1.12,00:11:22:33:44:77,1700000000,Bad_Device,0
+192.168.1.13,00:11:22:33:44:88,1600000000,expired,0
+`
+
+func TestFile_DeviceID(t *testing.T) {
+	t.Parallel()
+
+	leasesPath := filepath.Join(t.TempDir(), "kea-leases4.csv")
+	err := os.WriteFile(leasesPath, []byte(testLeases), 0o600)
+	require.NoError(t, err)
+
+	f := dhcplease.NewFile(&dhcplease.FileConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		Clock: &agdtest.Clock{
+			OnNow: func() (now time.Time) { return time.Unix(1_650_000_000, 0) },
+		},
+		ErrColl: agdtest.NewErrorCollector(),
+		DeviceIDs: map[string]agd.DeviceID{
+			"00:11:22:33:44:55": "dev1234",
+			"00:11:22:33:44:88": "dev5678",
+		},
+		Path:              leasesPath,
+		Format:            dhcplease.FormatKea,
+		HostnameDeviceIDs: true,
+	})
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, f.Refresh(ctx))
+
+	testCases := []struct {
+		ip   netip.Addr
+		name string
+		want agd.DeviceID
+	}{{
+		ip:   netip.MustParseAddr("192.168.1.10"),
+		name: "hwaddr",
+		want: "dev1234",
+	}, {
+		ip:   netip.MustParseAddr("::ffff:192.168.1.10"),
+		name: "mapped",
+		want: "dev1234",
+	}, {
+		ip:   netip.MustParseAddr("192.168.1.11"),
+		name: "hostname",
+		want: "phone123",
+	}, {
+		ip:   netip.MustParseAddr("192.168.1.12"),
+		name: "bad_hostname",
+		want: "",
+	}, {
+		ip:   netip.MustParseAddr("192.168.1.13"),
+		name: "expired",
+		want: "",
+	}, {
+		ip:   netip.MustParseAddr("192.168.1.14"),
+		name: "no_lease",
+		want: "",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			id, idErr := f.DeviceID(testutil.ContextWithTimeout(t, testTimeout), tc.ip)
+			require.NoError(t, idErr)
+
+			assert.Equal(t, tc.want, id)
+		})
+	}
+}
+
+func TestFile_Refresh_error(t *testing.T) {
+	t.Parallel()
+
+	var collected error
+	errColl := &agdtest.ErrorCollector{
+		OnCollect: func(_ context.Context, err error) { collected = err },
+	}
+
+	f := dhcplease.NewFile(&dhcplease.FileConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		Clock: &agdtest.Clock{
+			OnNow: time.Now,
+		},
+		ErrColl: errColl,
+		Path:    filepath.Join(t.TempDir(), "none"),
+		Format:  dhcplease.FormatISC,
+	})
+
+	err := f.Refresh(testutil.ContextWithTimeout(t, testTimeout))
+	require.Error(t, err)
+
+	assert.ErrorIs(t, collected, os.ErrNotExist)
+}
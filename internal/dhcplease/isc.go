@@ -0,0 +1,163 @@
+package dhcplease
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iscTimeLayout is the layout of the UTC time values in the ISC DHCP lease
+// files without the day of the week.
+const iscTimeLayout = "2006/01/02 15:04:05"
+
+// iscLease is a lease being parsed from an ISC DHCP lease file.
+type iscLease struct {
+	*lease
+
+	// active is false if the binding state of the lease isn't active.
+	active bool
+}
+
+// parseISC parses the leases from the ISC DHCP dhcpd.leases file read from r.
+// The file is a log of the lease updates, so only the last declaration for
+// each address is used.  The declarations with a binding state other than
+// active remove the lease.
+func parseISC(r io.Reader) (leases []*lease, err error) {
+	byIP := map[netip.Addr]*lease{}
+
+	var cur *iscLease
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := strings.TrimSpace(stripISCComment(s.Text()))
+		switch {
+		case text == "":
+			continue
+		case cur == nil:
+			cur, err = parseISCLeaseStart(text)
+		case text == "}":
+			if cur.active {
+				byIP[cur.ip] = cur.lease
+			} else {
+				delete(byIP, cur.ip)
+			}
+
+			cur = nil
+		default:
+			err = cur.parseStatement(text)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	err = s.Err()
+	if err != nil {
+		return nil, fmt.Errorf("reading: %w", err)
+	}
+
+	leases = make([]*lease, 0, len(byIP))
+	for _, l := range byIP {
+		leases = append(leases, l)
+	}
+
+	return leases, nil
+}
+
+// stripISCComment removes the comment from line, unless the comment character
+// is within a quoted string.
+func stripISCComment(line string) (stripped string) {
+	i := strings.IndexByte(line, '#')
+	if i < 0 || strings.Contains(line[:i], `"`) {
+		return line
+	}
+
+	return line[:i]
+}
+
+// parseISCLeaseStart parses the beginning of a lease declaration from text.  l
+// is nil if text is a declaration of something other than a lease, in which
+// case it is ignored.
+func parseISCLeaseStart(text string) (l *iscLease, err error) {
+	fields := strings.Fields(text)
+	if len(fields) != 3 || fields[0] != "lease" || fields[2] != "{" {
+		return nil, nil
+	}
+
+	ip, err := netip.ParseAddr(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("lease address: %w", err)
+	}
+
+	return &iscLease{
+		lease: &lease{
+			ip: ip,
+		},
+		active: true,
+	}, nil
+}
+
+// parseStatement parses a single statement within a lease declaration.  The
+// unknown statements are ignored.
+func (l *iscLease) parseStatement(text string) (err error) {
+	fields := strings.Fields(strings.TrimSuffix(text, ";"))
+	if len(fields) < 2 {
+		return nil
+	}
+
+	switch fields[0] {
+	case "binding":
+		// "binding state active", but not "next binding state free".
+		l.active = len(fields) == 3 && fields[1] == "state" && fields[2] == "active"
+	case "client-hostname":
+		l.hostname, err = strconv.Unquote(strings.Join(fields[1:], " "))
+		if err != nil {
+			return fmt.Errorf("client-hostname: %w", err)
+		}
+	case "ends":
+		l.expiry, err = parseISCTime(fields[1:])
+		if err != nil {
+			return fmt.Errorf("ends: %w", err)
+		}
+	case "hardware":
+		if len(fields) != 3 {
+			return fmt.Errorf("hardware: bad number of fields: %d", len(fields))
+		}
+
+		l.hwAddr, err = net.ParseMAC(fields[2])
+		if err != nil {
+			return fmt.Errorf("hardware: %w", err)
+		}
+	default:
+		// Ignore the other statements.
+	}
+
+	return nil
+}
+
+// parseISCTime parses the time value from the fields of a statement.  The
+// supported formats are "never", "epoch <seconds>", and
+// "<weekday> <yyyy/mm/dd> <hh:mm:ss>" in UTC.  t is zero for "never".
+func parseISCTime(fields []string) (t time.Time, err error) {
+	switch {
+	case len(fields) == 1 && fields[0] == "never":
+		return time.Time{}, nil
+	case len(fields) == 2 && fields[0] == "epoch":
+		var sec int64
+		sec, err = strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("epoch: %w", err)
+		}
+
+		return time.Unix(sec, 0), nil
+	case len(fields) == 3:
+		return time.Parse(iscTimeLayout, fields[1]+" "+fields[2])
+	default:
+		return time.Time{}, fmt.Errorf("bad time value %q", strings.Join(fields, " "))
+	}
+}
@@ -0,0 +1,159 @@
+package dhcplease
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// Column names of the Kea lease files.
+const (
+	keaColAddress  = "address"
+	keaColExpire   = "expire"
+	keaColHWAddr   = "hwaddr"
+	keaColHostname = "hostname"
+	keaColState    = "state"
+)
+
+// keaStateDefault is the value of the state column of the Kea lease files for
+// the leases that are in use.
+const keaStateDefault = "0"
+
+// keaCommaEscape is the escape sequence that Kea uses for commas within the
+// values of the lease files.
+const keaCommaEscape = "&#x2c"
+
+// parseKea parses the leases from the Kea memfile lease file read from r.  The
+// file is a log of the lease updates, so only the last record for each address
+// is used.  The records with a non-default state, such as declined or
+// reclaimed leases, remove the lease.
+func parseKea(r io.Reader) (leases []*lease, err error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+
+	cols, err := newKeaColumns(header)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	byIP := map[netip.Addr]*lease{}
+	for line := 2; ; line++ {
+		var rec []string
+		rec, err = cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		var ip netip.Addr
+		var l *lease
+		ip, l, err = cols.parse(rec)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		} else if l == nil {
+			delete(byIP, ip)
+
+			continue
+		}
+
+		byIP[ip] = l
+	}
+
+	leases = make([]*lease, 0, len(byIP))
+	for _, l := range byIP {
+		leases = append(leases, l)
+	}
+
+	return leases, nil
+}
+
+// keaColumns are the indexes of the columns of a Kea lease file.  Optional
+// columns have the index of -1 if they are absent.
+type keaColumns struct {
+	address  int
+	expire   int
+	hwAddr   int
+	hostname int
+	state    int
+}
+
+// newKeaColumns returns the indexes of the columns from the header of a Kea
+// lease file.
+func newKeaColumns(header []string) (cols *keaColumns, err error) {
+	cols = &keaColumns{
+		address:  slices.Index(header, keaColAddress),
+		expire:   slices.Index(header, keaColExpire),
+		hwAddr:   slices.Index(header, keaColHWAddr),
+		hostname: slices.Index(header, keaColHostname),
+		state:    slices.Index(header, keaColState),
+	}
+
+	if cols.address < 0 {
+		return nil, fmt.Errorf("header: no column %q", keaColAddress)
+	}
+
+	return cols, nil
+}
+
+// field returns the value of the column with index i from rec or an empty
+// string if there is no such column.
+func field(rec []string, i int) (v string) {
+	if i < 0 || i >= len(rec) {
+		return ""
+	}
+
+	return strings.ReplaceAll(rec[i], keaCommaEscape, ",")
+}
+
+// parse parses the address and the lease from rec.  l is nil if the lease
+// isn't in use.
+func (cols *keaColumns) parse(rec []string) (ip netip.Addr, l *lease, err error) {
+	ip, err = netip.ParseAddr(field(rec, cols.address))
+	if err != nil {
+		return netip.Addr{}, nil, fmt.Errorf("%s: %w", keaColAddress, err)
+	}
+
+	if st := field(rec, cols.state); st != "" && st != keaStateDefault {
+		return ip, nil, nil
+	}
+
+	l = &lease{
+		hostname: strings.TrimSuffix(field(rec, cols.hostname), "."),
+		ip:       ip,
+	}
+
+	if expStr := field(rec, cols.expire); expStr != "" {
+		var exp int64
+		exp, err = strconv.ParseInt(expStr, 10, 64)
+		if err != nil {
+			return ip, nil, fmt.Errorf("%s: %w", keaColExpire, err)
+		}
+
+		l.expiry = time.Unix(exp, 0)
+	}
+
+	if hwStr := field(rec, cols.hwAddr); hwStr != "" {
+		l.hwAddr, err = net.ParseMAC(hwStr)
+		if err != nil {
+			return ip, nil, fmt.Errorf("%s: %w", keaColHWAddr, err)
+		}
+	}
+
+	return ip, l, nil
+}
@@ -0,0 +1,64 @@
+package dhcplease
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// Format is the format of a DHCP lease file.
+type Format string
+
+// Valid [Format] values.
+const (
+	// FormatISC is the format of the dhcpd.leases file of ISC DHCP.
+	FormatISC Format = "isc"
+
+	// FormatKea is the CSV format of the lease files of the memfile backend of
+	// Kea DHCP.
+	FormatKea Format = "kea"
+)
+
+// Validate returns an error if f is not a valid format.
+func (f Format) Validate() (err error) {
+	switch f {
+	case FormatISC, FormatKea:
+		return nil
+	default:
+		return fmt.Errorf("format: %w: %q", errors.ErrBadEnumValue, f)
+	}
+}
+
+// parse parses the leases from r according to f.  f must be valid.
+func (f Format) parse(r io.Reader) (leases []*lease, err error) {
+	if f == FormatISC {
+		return parseISC(r)
+	}
+
+	return parseKea(r)
+}
+
+// lease is a single DHCP lease.
+type lease struct {
+	// expiry is the time after which the lease isn't valid anymore.  If it is
+	// zero, the lease never expires.
+	expiry time.Time
+
+	// hostname is the hostname of the client, if known.
+	hostname string
+
+	// ip is the leased IP address.
+	ip netip.Addr
+
+	// hwAddr is the hardware address of the client, if known.
+	hwAddr net.HardwareAddr
+}
+
+// isActive returns true if l is not expired at the moment now.
+func (l *lease) isActive(now time.Time) (ok bool) {
+	return l.expiry.IsZero() || now.Before(l.expiry)
+}
@@ -0,0 +1,163 @@
+package dhcplease
+
+import (
+	"cmp"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Common lease data for tests.
+var (
+	testIP      = netip.MustParseAddr("192.168.1.10")
+	testIPOther = netip.MustParseAddr("192.168.1.11")
+	testHWAddr  = net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}
+	testExpiry  = time.Unix(1_700_000_000, 0)
+)
+
+// testHostname is the common hostname for tests.
+const testHostname = "laptop"
+
+// sortLeases sorts leases by address for comparison.
+func sortLeases(leases []*lease) {
+	slices.SortFunc(leases, func(a, b *lease) (res int) {
+		return cmp.Compare(a.ip.String(), b.ip.String())
+	})
+}
+
+func TestFormat_parse(t *testing.T) {
+	t.Parallel()
+
+	wantLease := &lease{
+		expiry:   testExpiry,
+		hwAddr:   testHWAddr,
+		hostname: testHostname,
+		ip:       testIP,
+	}
+
+	testCases := []struct {
+		name       string
+		format     Format
+		data       string
+		wantErrMsg string
+		want       []*lease
+	}{{
+		name:   "kea",
+		format: FormatKea,
+		data: "address,hwaddr,client_id,valid_lifetime,expire,subnet_id," +
+			"fqdn_fwd,fqdn_rev,hostname,state,user_context,pool_id\n" +
+			"192.168.1.10,00:11:22:33:44:55,,3600,1700000000,1,0,0,laptop.,0,,0\n",
+		want:       []*lease{wantLease},
+		wantErrMsg: "",
+	}, {
+		name:   "kea_reclaimed",
+		format: FormatKea,
+		data: "address,hwaddr,expire,hostname,state\n" +
+			"192.168.1.10,00:11:22:33:44:55,1700000000,laptop,0\n" +
+			"192.168.1.11,00:11:22:33:44:66,1700000000,phone,0\n" +
+			"192.168.1.11,00:11:22:33:44:66,1700000000,phone,2\n",
+		want:       []*lease{wantLease},
+		wantErrMsg: "",
+	}, {
+		name:   "kea_update",
+		format: FormatKea,
+		data: "address,hwaddr,expire,hostname,state\n" +
+			"192.168.1.10,00:11:22:33:44:55,1600000000,old,0\n" +
+			"192.168.1.10,00:11:22:33:44:55,1700000000,laptop,0\n",
+		want:       []*lease{wantLease},
+		wantErrMsg: "",
+	}, {
+		name:       "kea_no_address",
+		format:     FormatKea,
+		data:       "hwaddr,expire\n",
+		want:       nil,
+		wantErrMsg: `header: no column "address"`,
+	}, {
+		name:   "kea_bad_hwaddr",
+		format: FormatKea,
+		data: "address,hwaddr\n" +
+			"192.168.1.10,bad\n",
+		want:       nil,
+		wantErrMsg: "line 2: hwaddr: address bad: invalid MAC address",
+	}, {
+		name:   "isc",
+		format: FormatISC,
+		data: "# The format of this file is documented in the dhcpd.leases(5).\n" +
+			"lease 192.168.1.10 {\n" +
+			"  starts 2 2023/11/14 21:13:20;\n" +
+			"  ends 2 2023/11/14 22:13:20;\n" +
+			"  binding state active;\n" +
+			"  next binding state free;\n" +
+			"  hardware ethernet 00:11:22:33:44:55;\n" +
+			"  client-hostname \"laptop\";\n" +
+			"}\n",
+		want:       []*lease{wantLease},
+		wantErrMsg: "",
+	}, {
+		name:   "isc_epoch_and_free",
+		format: FormatISC,
+		data: "server-duid \"\\000\\001\";\n" +
+			"lease 192.168.1.10 {\n" +
+			"  ends epoch 1700000000; # Tue Nov 14 22:13:20 2023\n" +
+			"  hardware ethernet 00:11:22:33:44:55;\n" +
+			"  client-hostname \"laptop\";\n" +
+			"}\n" +
+			"lease 192.168.1.11 {\n" +
+			"  ends never;\n" +
+			"  binding state active;\n" +
+			"}\n" +
+			"lease 192.168.1.11 {\n" +
+			"  binding state free;\n" +
+			"}\n",
+		want:       []*lease{wantLease},
+		wantErrMsg: "",
+	}, {
+		name:   "isc_never",
+		format: FormatISC,
+		data: "lease 192.168.1.11 {\n" +
+			"  ends never;\n" +
+			"}\n",
+		want: []*lease{{
+			ip: testIPOther,
+		}},
+		wantErrMsg: "",
+	}, {
+		name:   "isc_bad_ends",
+		format: FormatISC,
+		data: "lease 192.168.1.10 {\n" +
+			"  ends soon;\n" +
+			"}\n",
+		want:       nil,
+		wantErrMsg: `line 2: ends: bad time value "soon"`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			leases, err := tc.format.parse(strings.NewReader(tc.data))
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, err)
+			if tc.wantErrMsg != "" {
+				return
+			}
+
+			require.Len(t, leases, len(tc.want))
+
+			sortLeases(leases)
+			for i, l := range leases {
+				want := tc.want[i]
+				assert.Equal(t, want.ip, l.ip)
+				assert.Equal(t, want.hwAddr, l.hwAddr)
+				assert.Equal(t, want.hostname, l.hostname)
+				assert.True(t, want.expiry.Equal(l.expiry), "expiry: %s", l.expiry)
+			}
+		})
+	}
+}
@@ -25,6 +25,18 @@ type Interface interface {
 	Check(ctx context.Context, req *dns.Msg, ri *agd.RequestInfo) (resp *dns.Msg, err error)
 }
 
+// type check
+var _ Interface = Empty{}
+
+// Empty is an [Interface] implementation that never responds to any requests.
+type Empty struct{}
+
+// Check implements the [Interface] interface for Empty.  resp and err are
+// always nil.
+func (Empty) Check(_ context.Context, _ *dns.Msg, _ *agd.RequestInfo) (resp *dns.Msg, err error) {
+	return nil, nil
+}
+
 // randomIDFromDomain returns a random ID from name using one of the suf as
 // the domain name suffix.  If matched is false, this is not a DNS check request.
 func randomIDFromDomain(name string, suf []string) (id string, matched bool, err error) {
@@ -16,6 +16,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
 	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnameredact"
 	"github.com/miekg/dns"
 )
 
@@ -36,10 +37,11 @@ func (Empty) Record(_ context.Context, _ *dns.Msg, _ *agd.RequestInfo) {}
 
 // Default is the default DNSDB implementation.
 type Default struct {
-	logger  *slog.Logger
-	buffer  *atomic.Pointer[buffer]
-	errColl errcoll.Interface
-	maxSize int
+	logger   *slog.Logger
+	buffer   *atomic.Pointer[buffer]
+	errColl  errcoll.Interface
+	redactor *qnameredact.Redactor
+	maxSize  int
 }
 
 // DefaultConfig is the default DNS database configuration structure.
@@ -50,6 +52,10 @@ type DefaultConfig struct {
 	// ErrColl is used to collect HTTP errors.
 	ErrColl errcoll.Interface
 
+	// Redactor, if not nil, is used to redact the target hostnames before
+	// recording them.
+	Redactor *qnameredact.Redactor
+
 	// MaxSize is the maximum amount of records in the memory buffer.
 	MaxSize int
 }
@@ -57,10 +63,11 @@ type DefaultConfig struct {
 // New creates a new default DNS database.  c must not be nil.
 func New(c *DefaultConfig) (db *Default) {
 	db = &Default{
-		logger:  c.Logger,
-		buffer:  &atomic.Pointer[buffer]{},
-		errColl: c.ErrColl,
-		maxSize: c.MaxSize,
+		logger:   c.Logger,
+		buffer:   &atomic.Pointer[buffer]{},
+		errColl:  c.ErrColl,
+		redactor: c.Redactor,
+		maxSize:  c.MaxSize,
 	}
 
 	db.buffer.Store(&buffer{
@@ -87,8 +94,10 @@ func (db *Default) Record(ctx context.Context, m *dns.Msg, ri *agd.RequestInfo)
 		return
 	}
 
+	target := db.redactor.Redact(ri.Host)
+
 	// #nosec G115 -- RCODE is currently defined to be 16 bit or less.
-	db.buffer.Load().add(ri.Host, m.Answer, q.Qtype, dnsmsg.RCode(m.Rcode))
+	db.buffer.Load().add(target, m.Answer, q.Qtype, dnsmsg.RCode(m.Rcode))
 }
 
 // reset returns buffered records and resets the database.
@@ -16,9 +16,11 @@ type BlockingMode interface {
 }
 
 // BlockingModeCustomIP makes the [dnsmsg.Constructor] return responses with
-// custom IP addresses to A and AAAA requests.  For all other types of requests,
-// as well as in case the address corresponding to IP version is not set, it
-// returns a response with no answers (aka NODATA).
+// custom IP addresses to A and AAAA requests.  For HTTPS and SVCB requests, it
+// returns a record pointing to the requested name with the custom IP addresses
+// as hints.  For all other types of requests, as well as in case the addresses
+// corresponding to the request are not set, it returns a response with no
+// answers (aka NODATA).
 type BlockingModeCustomIP struct {
 	// IPv4 is a slice of valid IPv4 addresses used in responses to A requests.
 	IPv4 []netip.Addr
@@ -33,8 +35,8 @@ type BlockingModeCustomIP struct {
 func (*BlockingModeCustomIP) isBlockingMode() {}
 
 // BlockingModeNullIP makes the [dnsmsg.Constructor] return a null-IP response
-// to A and AAAA requests.  For all other types of requests, it returns a
-// response with no answers (aka NODATA).
+// to A and AAAA requests.  For all other types of requests, including HTTPS and
+// SVCB ones, it returns a response with no answers (aka NODATA).
 type BlockingModeNullIP struct{}
 
 // isBlockingMode implements the BlockingMode interface for *BlockingModeNullIP.
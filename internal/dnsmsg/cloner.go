@@ -6,8 +6,20 @@ import (
 	"github.com/miekg/dns"
 )
 
+// ClonerConfig is the configuration structure for [Cloner].
+type ClonerConfig struct {
+	// Stat is used to collect the statistics of the cloner.  It must not be
+	// nil.
+	Stat ClonerStat
+
+	// InternCount is the number of slots in the table of interned domain names.
+	// If it is zero, the domain names are not interned.
+	InternCount uint
+}
+
 // Cloner is a pool that can clone common parts of DNS messages with fewer
-// allocations.
+// allocations.  The resource-record slices are kept in size-classed pools, and
+// the domain names of the clones can be interned.
 //
 // TODO(a.garipov): Use in filtering when cloning a
 // [filter.ResultModifiedResponse] message.
@@ -22,6 +34,11 @@ type Cloner struct {
 
 	msg *syncutil.Pool[dns.Msg]
 
+	// Section slices and names.
+
+	rrSlices *rrSlicePool
+	names    *nameInterner
+
 	// Mostly-answer structures.
 
 	a     *syncutil.Pool[dns.A]
@@ -45,10 +62,10 @@ type Cloner struct {
 	opt *optCloner
 }
 
-// NewCloner returns a new properly initialized *Cloner.
-func NewCloner(stat ClonerStat) (c *Cloner) {
+// NewCloner returns a new properly initialized *Cloner.  c must be valid.
+func NewCloner(c *ClonerConfig) (cl *Cloner) {
 	return &Cloner{
-		stat: stat,
+		stat: c.Stat,
 
 		msg: syncutil.NewPool(func() (v *dns.Msg) {
 			return &dns.Msg{
@@ -58,6 +75,9 @@ func NewCloner(stat ClonerStat) (c *Cloner) {
 			}
 		}),
 
+		rrSlices: newRRSlicePool(c.Stat),
+		names:    newNameInterner(c.Stat, c.InternCount),
+
 		a: syncutil.NewPool(func() (v *dns.A) {
 			return &dns.A{}
 		}),
@@ -102,6 +122,11 @@ func (c *Cloner) Clone(msg *dns.Msg) (clone *dns.Msg) {
 	clone.Compress = msg.Compress
 
 	clone.Question = appendIfNotNil(clone.Question[:0], msg.Question)
+	if c.names != nil {
+		for i := range clone.Question {
+			clone.Question[i].Name = c.names.intern(clone.Question[i].Name)
+		}
+	}
 
 	var ansFull, nsFull, exFull bool
 	clone.Answer, ansFull = c.appendAnswer(clone.Answer[:0], msg.Answer)
@@ -114,15 +139,18 @@ func (c *Cloner) Clone(msg *dns.Msg) (clone *dns.Msg) {
 }
 
 // appendAnswer appends deep clones of all resource records from original to
-// clones and returns it.
+// clones and returns it.  clones must be empty.  If it cannot hold all clones,
+// it is replaced with a slice from the pools.
 //
 // TODO(a.garipov): Consider ways of DRY'ing and merging with [Cloner.appendNS]
 // and [Cloner.appendExtra].
 func (c *Cloner) appendAnswer(clones, original []dns.RR) (res []dns.RR, full bool) {
 	if original == nil {
-		// TODO(a.garipov): This loses the RR slice in the message from the
-		// pool.  Consider ways of mitigating that.
+		c.rrSlices.release(clones)
+
 		return nil, true
+	} else if clones == nil || cap(clones) < len(original) {
+		clones = c.rrSlices.replace(clones, len(original))
 	}
 
 	full = true
@@ -144,33 +172,37 @@ func (c *Cloner) cloneAnswerRR(orig dns.RR) (clone dns.RR, full bool) {
 	case *dns.AAAA:
 		clone = newAAAANetIP(c, orig.AAAA)
 	case *dns.CNAME:
-		clone = newCNAME(c, orig.Target)
+		clone = newCNAME(c, c.names.intern(orig.Target))
 	case *dns.HTTPS:
 		return c.https.clone(orig)
 	case *dns.MX:
-		clone = newMX(c, orig.Mx, orig.Preference)
+		clone = newMX(c, c.names.intern(orig.Mx), orig.Preference)
 	case *dns.PTR:
-		clone = newPTR(c, orig.Ptr)
+		clone = newPTR(c, c.names.intern(orig.Ptr))
 	case *dns.SRV:
-		clone = newSRV(c, orig.Target, orig.Priority, orig.Weight, orig.Port)
+		clone = newSRV(c, c.names.intern(orig.Target), orig.Priority, orig.Weight, orig.Port)
 	case *dns.TXT:
 		clone = newTXT(c, orig.Txt)
 	default:
 		return dns.Copy(orig), false
 	}
 
-	*clone.Header() = *orig.Header()
+	hdr := clone.Header()
+	*hdr = *orig.Header()
+	hdr.Name = c.names.intern(hdr.Name)
 
 	return clone, true
 }
 
 // appendNS appends deep clones of all resource records from original to
-// clones and returns it.
+// clones and returns it.  See [Cloner.appendAnswer].
 func (c *Cloner) appendNS(clones, original []dns.RR) (res []dns.RR, full bool) {
 	if original == nil {
-		// TODO(a.garipov): This loses the RR slice in the message from the
-		// pool.  Consider ways of mitigating that.
+		c.rrSlices.release(clones)
+
 		return nil, true
+	} else if clones == nil || cap(clones) < len(original) {
+		clones = c.rrSlices.replace(clones, len(original))
 	}
 
 	full = true
@@ -180,6 +212,9 @@ func (c *Cloner) appendNS(clones, original []dns.RR) (res []dns.RR, full bool) {
 		case *dns.SOA:
 			ns := c.soa.Get()
 			*ns = *orig
+			ns.Hdr.Name = c.names.intern(ns.Hdr.Name)
+			ns.Ns = c.names.intern(ns.Ns)
+			ns.Mbox = c.names.intern(ns.Mbox)
 
 			nsClone = ns
 		// TODO(a.garipov): Add more if necessary.
@@ -195,12 +230,14 @@ func (c *Cloner) appendNS(clones, original []dns.RR) (res []dns.RR, full bool) {
 }
 
 // appendExtra appends deep clones of all resource records from original to
-// clones and returns it.
+// clones and returns it.  See [Cloner.appendAnswer].
 func (c *Cloner) appendExtra(clones, original []dns.RR) (res []dns.RR, full bool) {
 	if original == nil {
-		// TODO(a.garipov): This loses the RR slice in the message from the
-		// pool.  Consider ways of mitigating that.
+		c.rrSlices.release(clones)
+
 		return nil, true
+	} else if clones == nil || cap(clones) < len(original) {
+		clones = c.rrSlices.replace(clones, len(original))
 	}
 
 	full = true
@@ -235,6 +272,7 @@ func (c *Cloner) Dispose(resp *dns.Msg) {
 	}
 
 	c.putAnswers(resp.Answer)
+	resp.Answer = c.rrSlices.detachLarge(resp.Answer)
 
 	for _, ns := range resp.Ns {
 		switch ns := ns.(type) {
@@ -245,6 +283,8 @@ func (c *Cloner) Dispose(resp *dns.Msg) {
 		}
 	}
 
+	resp.Ns = c.rrSlices.detachLarge(resp.Ns)
+
 	for _, ex := range resp.Extra {
 		switch ex := ex.(type) {
 		case *dns.OPT:
@@ -254,6 +294,8 @@ func (c *Cloner) Dispose(resp *dns.Msg) {
 		}
 	}
 
+	resp.Extra = c.rrSlices.detachLarge(resp.Extra)
+
 	c.msg.Put(resp)
 }
 
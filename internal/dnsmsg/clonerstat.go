@@ -8,6 +8,14 @@ type ClonerStat interface {
 	// OnClone is called on [Cloner.Clone] calls.  isFull is true if the clone
 	// was full.
 	OnClone(isFull bool)
+
+	// OnRRSliceGet is called when a [Cloner] takes a resource-record slice from
+	// its size-classed pools.  isHit is true if a pooled slice was reused.
+	OnRRSliceGet(isHit bool)
+
+	// OnIntern is called when a [Cloner] interns a domain name.  isHit is true
+	// if the name has already been interned.
+	OnIntern(isHit bool)
 }
 
 // EmptyClonerStat is a [ClonerStat] implementation that does nothing.
@@ -18,3 +26,9 @@ var _ ClonerStat = EmptyClonerStat{}
 
 // OnClone implements the [ClonerStat] interface for EmptyClonerStat.
 func (EmptyClonerStat) OnClone(_ bool) {}
+
+// OnRRSliceGet implements the [ClonerStat] interface for EmptyClonerStat.
+func (EmptyClonerStat) OnRRSliceGet(_ bool) {}
+
+// OnIntern implements the [ClonerStat] interface for EmptyClonerStat.
+func (EmptyClonerStat) OnIntern(_ bool) {}
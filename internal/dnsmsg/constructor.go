@@ -63,11 +63,12 @@ func (conf *ConstructorConfig) validate() (err error) {
 // Constructor creates DNS messages for blocked or modified responses.  It must
 // be created using [NewConstructor].
 type Constructor struct {
-	cloner       *Cloner
-	blockingMode BlockingMode
-	sde          string
-	fltRespTTL   time.Duration
-	edeEnabled   bool
+	cloner        *Cloner
+	blockingMode  BlockingMode
+	sde           string
+	fltRespTTL    time.Duration
+	edeEnabled    bool
+	randSOASerial bool
 }
 
 // NewConstructor returns a properly initialized constructor using conf.
@@ -251,6 +252,10 @@ func (c *Constructor) NewAnswerTXT(req *dns.Msg, strs []string) (rr *dns.TXT, er
 	return rr, nil
 }
 
+// defaultSOASerial is the serial number of the SOA records of the synthesized
+// negative responses, unless it is randomized.
+const defaultSOASerial = 100500
+
 // newSOARecords generates the Start Of Authority record for AdGuardDNS.  It
 // must be used with all blocked responses.
 func (c *Constructor) newSOARecords(req *dns.Msg) (soaRecs []dns.RR) {
@@ -271,7 +276,7 @@ func (c *Constructor) newSOARecords(req *dns.Msg) (soaRecs []dns.RR) {
 		Minttl:  86400,
 		// Copied from AdGuard DNS.
 		Ns:     "fake-for-negative-caching.adguard.com.",
-		Serial: 100500,
+		Serial: c.soaSerial(),
 		// Rest is request-specific.
 		Hdr: c.newHdrWithClass(zone, dns.TypeSOA, dns.ClassINET),
 		// Zone will be appended later if it's not empty or ".".
@@ -318,3 +323,48 @@ func (c *Constructor) newMsgAAAA(req *dns.Msg, ips ...netip.Addr) (msg *dns.Msg,
 
 	return msg, nil
 }
+
+// newMsgSVCBHints returns a new HTTPS or SVCB DNS response, depending on the
+// question type of req, with a single service-mode record pointing to the
+// requested name with the given IP addresses as hints.  At least one of ipv4
+// and ipv6 must not be empty.
+func (c *Constructor) newMsgSVCBHints(req *dns.Msg, ipv4, ipv6 []netip.Addr) (msg *dns.Msg) {
+	qt := req.Question[0].Qtype
+	svcb := &dns.SVCB{
+		Hdr:      c.newHdr(req, qt),
+		Priority: 1,
+		// Target "." means the owner name in the service mode.  See RFC 9460,
+		// Section 2.5.2.
+		Target: ".",
+	}
+
+	if len(ipv4) > 0 {
+		hint := &dns.SVCBIPv4Hint{}
+		for _, ip := range ipv4 {
+			hint.Hint = append(hint.Hint, ip.AsSlice())
+		}
+
+		svcb.Value = append(svcb.Value, hint)
+	}
+
+	if len(ipv6) > 0 {
+		hint := &dns.SVCBIPv6Hint{}
+		for _, ip := range ipv6 {
+			hint.Hint = append(hint.Hint, ip.AsSlice())
+		}
+
+		svcb.Value = append(svcb.Value, hint)
+	}
+
+	var ans dns.RR = svcb
+	if qt == dns.TypeHTTPS {
+		ans = &dns.HTTPS{
+			SVCB: *svcb,
+		}
+	}
+
+	msg = c.NewResp(req)
+	msg.Answer = append(msg.Answer, ans)
+
+	return msg
+}
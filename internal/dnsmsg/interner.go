@@ -0,0 +1,71 @@
+package dnsmsg
+
+import (
+	"hash/maphash"
+	"math/bits"
+	"sync/atomic"
+)
+
+// nameInterner is a fixed-size table of interned domain names.  It makes the
+// clones of messages, for example cached answers, share the memory for the
+// common names instead of keeping the ones from the original messages.  A nil
+// *nameInterner doesn't intern anything.  It is safe for concurrent use.
+type nameInterner struct {
+	stat ClonerStat
+
+	// slots are the interned names.  A new name replaces the one in its slot,
+	// so the table never grows.
+	slots []atomic.Pointer[string]
+
+	seed maphash.Seed
+	mask uint64
+}
+
+// newNameInterner returns a new properly initialized *nameInterner with at
+// least count slots.  If count is zero, ni is nil.
+func newNameInterner(stat ClonerStat, count uint) (ni *nameInterner) {
+	if count == 0 {
+		return nil
+	}
+
+	// Round up to a power of two to use a mask instead of a modulo.
+	n := uint64(1) << bits.Len64(uint64(count-1))
+
+	return &nameInterner{
+		stat:  stat,
+		slots: make([]atomic.Pointer[string], n),
+		seed:  maphash.MakeSeed(),
+		mask:  n - 1,
+	}
+}
+
+// intern returns the interned version of name.  It is kept small enough to be
+// inlined, since it is called for most names of every clone.
+func (ni *nameInterner) intern(name string) (interned string) {
+	if ni == nil || name == "" {
+		return name
+	}
+
+	return ni.lookup(name)
+}
+
+// lookup returns the interned version of name, interning it if necessary.
+func (ni *nameInterner) lookup(name string) (interned string) {
+	slot := &ni.slots[maphash.String(ni.seed, name)&ni.mask]
+	if p := slot.Load(); p != nil && *p == name {
+		ni.stat.OnIntern(true)
+
+		return *p
+	}
+
+	storeName(slot, name)
+	ni.stat.OnIntern(false)
+
+	return name
+}
+
+// storeName stores name in slot.  It is a separate function to make sure that
+// only the names that are actually stored are moved to the heap.
+func storeName(slot *atomic.Pointer[string], name string) {
+	slot.Store(&name)
+}
@@ -0,0 +1,50 @@
+package dnsmsg
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// JitterConfig is the configuration of the randomization of the synthesized
+// responses, which makes it harder for third parties to find out whether a
+// client has specific filters enabled by probing the caches of shared
+// forwarders.
+type JitterConfig struct {
+	// TTL is the maximum duration randomly subtracted from the filtered
+	// response TTL.  It must be non-negative.  If it is zero, the TTLs aren't
+	// randomized.
+	TTL time.Duration
+
+	// SOASerial, if true, makes the SOA records in the synthesized negative
+	// responses have random serial numbers.
+	SOASerial bool
+}
+
+// WithJitter returns a copy of c that randomizes the responses as set in conf.
+// The TTL is randomized once for the returned constructor, so that all records
+// in the responses created by it, and thus all records in an RRset, have the
+// same TTL.  Because of that, a new constructor should be created for each
+// request.  conf must not be nil.
+func (c *Constructor) WithJitter(conf *JitterConfig) (jc *Constructor) {
+	cp := *c
+	jc = &cp
+
+	jc.randSOASerial = conf.SOASerial
+
+	maxJitter := min(conf.TTL, c.fltRespTTL)
+	if maxJitter > 0 {
+		jc.fltRespTTL -= rand.N(maxJitter + 1)
+	}
+
+	return jc
+}
+
+// soaSerial returns the serial number for the SOA records of the synthesized
+// negative responses.
+func (c *Constructor) soaSerial() (serial uint32) {
+	if c.randSOASerial {
+		return rand.Uint32()
+	}
+
+	return defaultSOASerial
+}
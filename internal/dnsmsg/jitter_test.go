@@ -0,0 +1,87 @@
+package dnsmsg_test
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstructor_WithJitter(t *testing.T) {
+	t.Parallel()
+
+	// iterNum is the number of responses to check, which is enough to make
+	// the probability of a false failure negligible.
+	const iterNum = 100
+
+	const (
+		ttlSec    = agdtest.FilteredResponseTTLSec
+		jitterSec = ttlSec / 2
+	)
+
+	msgs := agdtest.NewConstructor(t)
+	conf := &dnsmsg.JitterConfig{
+		TTL:       jitterSec * time.Second,
+		SOASerial: true,
+	}
+
+	ips := []netip.Addr{
+		netip.MustParseAddr("192.0.2.1"),
+		netip.MustParseAddr("192.0.2.2"),
+	}
+
+	t.Run("ttl", func(t *testing.T) {
+		t.Parallel()
+
+		req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+		ttls := map[uint32]struct{}{}
+		for range iterNum {
+			resp, err := msgs.WithJitter(conf).NewRespIP(req, ips...)
+			require.NoError(t, err)
+			require.Len(t, resp.Answer, len(ips))
+
+			ttl := resp.Answer[0].Header().Ttl
+			assert.Equal(t, ttl, resp.Answer[1].Header().Ttl)
+			assert.GreaterOrEqual(t, ttl, uint32(ttlSec-jitterSec))
+			assert.LessOrEqual(t, ttl, uint32(ttlSec))
+
+			ttls[ttl] = struct{}{}
+		}
+
+		assert.Greater(t, len(ttls), 1)
+	})
+
+	t.Run("soa_serial", func(t *testing.T) {
+		t.Parallel()
+
+		req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+		serials := map[uint32]struct{}{}
+		for range iterNum {
+			resp := msgs.WithJitter(conf).NewRespRCode(req, dns.RcodeNameError)
+			require.Len(t, resp.Ns, 1)
+
+			soa := testutil.RequireTypeAssert[*dns.SOA](t, resp.Ns[0])
+			serials[soa.Serial] = struct{}{}
+		}
+
+		assert.Greater(t, len(serials), 1)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+		resp := msgs.WithJitter(&dnsmsg.JitterConfig{}).NewRespRCode(req, dns.RcodeNameError)
+		require.Len(t, resp.Ns, 1)
+
+		assert.Equal(t, uint32(ttlSec), resp.Ns[0].Header().Ttl)
+		assert.Equal(t, msgs.NewRespRCode(req, dns.RcodeNameError).Ns, resp.Ns)
+	})
+}
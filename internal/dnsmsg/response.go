@@ -75,6 +75,51 @@ func (c *Constructor) NewBlockedRespRCode(req *dns.Msg, rc RCode) (resp *dns.Msg
 	return resp
 }
 
+// NewRespNotReady returns a REFUSED response DNS message with the Not Ready
+// Extended DNS Error (EDE) code and extraText, if the request indicates EDNS
+// support.  Unlike [Constructor.AddEDE], it adds the EDE regardless of whether
+// the feature is enabled, since the EDE is the only explanation of the refusal.
+func (c *Constructor) NewRespNotReady(req *dns.Msg, extraText string) (resp *dns.Msg) {
+	return c.newRespEDE(req, dns.RcodeRefused, dns.ExtendedErrorCodeNotReady, extraText)
+}
+
+// NewRespDNSSECRequired returns a SERVFAIL response DNS message with the DNSSEC
+// Indeterminate Extended DNS Error (EDE) code and extraText, if the request
+// indicates EDNS support.  Like [Constructor.NewRespNotReady], it adds the EDE
+// regardless of whether the feature is enabled.
+func (c *Constructor) NewRespDNSSECRequired(req *dns.Msg, extraText string) (resp *dns.Msg) {
+	return c.newRespEDE(
+		req,
+		dns.RcodeServerFailure,
+		dns.ExtendedErrorCodeDNSSECIndeterminate,
+		extraText,
+	)
+}
+
+// newRespEDE returns a response DNS message with the response code rc and, if
+// the request indicates EDNS support, the Extended DNS Error (EDE) code with
+// extraText.
+func (c *Constructor) newRespEDE(
+	req *dns.Msg,
+	rc int,
+	code uint16,
+	extraText string,
+) (resp *dns.Msg) {
+	resp = c.NewResp(req)
+	resp.Rcode = rc
+
+	reqOpt := req.IsEdns0()
+	if reqOpt == nil {
+		return resp
+	}
+
+	respOpt := newOPT(c.cloner, reqOpt.UDPSize(), reqOpt.Do())
+	respOpt.Option = append(respOpt.Option, newEDNS0EDE(c.cloner, code, extraText))
+	resp.Extra = append(resp.Extra, respOpt)
+
+	return resp
+}
+
 // NewRespTXT returns a DNS TXT response message with the given strings as
 // content.  The TTL of the TXT answer is set to c.FilteredResponseTTL.
 func (c *Constructor) NewRespTXT(req *dns.Msg, strs ...string) (msg *dns.Msg, err error) {
@@ -203,7 +248,8 @@ func (c *Constructor) sdeForReqOpt(reqOpt *dns.OPT) (sde string) {
 }
 
 // newBlockedCustomIPResp returns a blocked DNS response message with either the
-// custom IPs from the blocking mode options or a NODATA one.
+// custom IPs from the blocking mode options or a NODATA one.  For HTTPS and
+// SVCB queries, the custom IPs are put into the hints of a synthesized record.
 func (c *Constructor) newBlockedCustomIPResp(
 	req *dns.Msg,
 	m *BlockingModeCustomIP,
@@ -217,6 +263,10 @@ func (c *Constructor) newBlockedCustomIPResp(
 		if len(m.IPv6) > 0 {
 			return c.NewBlockedRespIP(req, m.IPv6...)
 		}
+	case dns.TypeHTTPS, dns.TypeSVCB:
+		if len(m.IPv4) > 0 || len(m.IPv6) > 0 {
+			return c.newMsgSVCBHints(req, m.IPv4, m.IPv6), nil
+		}
 	default:
 		// Go on.
 	}
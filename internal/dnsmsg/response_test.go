@@ -1,6 +1,7 @@
 package dnsmsg_test
 
 import (
+	"net"
 	"net/netip"
 	"strings"
 	"testing"
@@ -51,6 +52,16 @@ func TestConstructor_NewBlockedResp_nullIP(t *testing.T) {
 		wantAns:   nil,
 		wantExtra: []dns.RR{filteredSDE},
 		qt:        dns.TypeTXT,
+	}, {
+		name:      "https",
+		wantAns:   nil,
+		wantExtra: []dns.RR{filteredSDE},
+		qt:        dns.TypeHTTPS,
+	}, {
+		name:      "svcb",
+		wantAns:   nil,
+		wantExtra: []dns.RR{filteredSDE},
+		qt:        dns.TypeSVCB,
 	}}
 
 	for _, tc := range testCases {
@@ -177,6 +188,97 @@ func TestConstructor_NewBlockedResp_customIP(t *testing.T) {
 	}
 }
 
+func TestConstructor_NewBlockedResp_customIPSVCB(t *testing.T) {
+	t.Parallel()
+
+	newSVCB := func(qt dnsmsg.RRType, hints ...dns.SVCBKeyValue) (rr dns.RR) {
+		svcb := &dns.SVCB{
+			Hdr: dns.RR_Header{
+				Name:   testFQDN,
+				Rrtype: qt,
+				Class:  dns.ClassINET,
+				Ttl:    agdtest.FilteredResponseTTLSec,
+			},
+			Priority: 1,
+			Target:   ".",
+			Value:    hints,
+		}
+
+		if qt == dns.TypeHTTPS {
+			return &dns.HTTPS{
+				SVCB: *svcb,
+			}
+		}
+
+		return svcb
+	}
+
+	v4Hint := &dns.SVCBIPv4Hint{Hint: []net.IP{testIPv4.AsSlice()}}
+	v6Hint := &dns.SVCBIPv6Hint{Hint: []net.IP{testIPv6.AsSlice()}}
+
+	testCases := []struct {
+		blockingMode dnsmsg.BlockingMode
+		name         string
+		wantHints    []dns.SVCBKeyValue
+	}{{
+		blockingMode: &dnsmsg.BlockingModeCustomIP{
+			IPv4: []netip.Addr{testIPv4},
+			IPv6: []netip.Addr{testIPv6},
+		},
+		name:      "both",
+		wantHints: []dns.SVCBKeyValue{v4Hint, v6Hint},
+	}, {
+		blockingMode: &dnsmsg.BlockingModeCustomIP{
+			IPv4: []netip.Addr{testIPv4},
+		},
+		name:      "ipv4_only",
+		wantHints: []dns.SVCBKeyValue{v4Hint},
+	}, {
+		blockingMode: &dnsmsg.BlockingModeCustomIP{
+			IPv6: []netip.Addr{testIPv6},
+		},
+		name:      "ipv6_only",
+		wantHints: []dns.SVCBKeyValue{v6Hint},
+	}, {
+		blockingMode: &dnsmsg.BlockingModeCustomIP{},
+		name:         "empty",
+		wantHints:    nil,
+	}}
+
+	for _, tc := range testCases {
+		msgs, err := dnsmsg.NewConstructor(&dnsmsg.ConstructorConfig{
+			Cloner:              agdtest.NewCloner(),
+			BlockingMode:        tc.blockingMode,
+			StructuredErrors:    agdtest.NewSDEConfig(true),
+			FilteredResponseTTL: agdtest.FilteredResponseTTL,
+			EDEEnabled:          true,
+		})
+		require.NoError(t, err)
+
+		for _, qt := range []dnsmsg.RRType{dns.TypeHTTPS, dns.TypeSVCB} {
+			t.Run(tc.name+"_"+dns.Type(qt).String(), func(t *testing.T) {
+				t.Parallel()
+
+				req := dnsservertest.NewReq(testFQDN, qt, dns.ClassINET)
+				resp, respErr := msgs.NewBlockedResp(req)
+				require.NoError(t, respErr)
+				require.NotNil(t, resp)
+
+				assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+
+				if tc.wantHints == nil {
+					assert.Empty(t, resp.Answer)
+					assert.Len(t, resp.Ns, 1)
+
+					return
+				}
+
+				assert.Equal(t, []dns.RR{newSVCB(qt, tc.wantHints...)}, resp.Answer)
+			})
+		}
+	}
+}
+
 func TestConstructor_NewBlockedResp_nodata(t *testing.T) {
 	t.Parallel()
 
@@ -351,6 +453,70 @@ func TestConstructor_NewRespRCode(t *testing.T) {
 	}
 }
 
+func TestConstructor_NewRespNotReady(t *testing.T) {
+	t.Parallel()
+
+	const extraText = "retry after 30 seconds"
+
+	msgs := agdtest.NewConstructor(t)
+
+	testCases := []struct {
+		req       *dns.Msg
+		name      string
+		wantExtra []dns.RR
+	}{{
+		req:       dnsservertest.NewReq(testFQDN, dns.TypeA, dns.ClassINET),
+		name:      "no_edns",
+		wantExtra: nil,
+	}, {
+		req: dnsservertest.NewReq(testFQDN, dns.TypeA, dns.ClassINET, dnsservertest.SectionExtra{
+			dnsservertest.NewOPT(true, dns.MaxMsgSize),
+		}),
+		name: "edns",
+		wantExtra: []dns.RR{dnsservertest.NewOPT(true, dns.MaxMsgSize, &dns.EDNS0_EDE{
+			InfoCode:  dns.ExtendedErrorCodeNotReady,
+			ExtraText: extraText,
+		})},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp := msgs.NewRespNotReady(tc.req, extraText)
+			require.NotNil(t, resp)
+
+			assert.Equal(t, dns.RcodeRefused, resp.Rcode)
+			assert.Empty(t, resp.Answer)
+			assert.Equal(t, tc.wantExtra, resp.Extra)
+		})
+	}
+}
+
+func TestConstructor_NewRespDNSSECRequired(t *testing.T) {
+	t.Parallel()
+
+	const extraText = "dnssec validation required"
+
+	msgs := agdtest.NewConstructor(t)
+
+	req := dnsservertest.NewReq(testFQDN, dns.TypeA, dns.ClassINET, dnsservertest.SectionExtra{
+		dnsservertest.NewOPT(false, dns.MaxMsgSize),
+	})
+
+	resp := msgs.NewRespDNSSECRequired(req, extraText)
+	require.NotNil(t, resp)
+
+	assert.Equal(t, dns.RcodeServerFailure, resp.Rcode)
+	assert.Empty(t, resp.Answer)
+
+	wantExtra := []dns.RR{dnsservertest.NewOPT(false, dns.MaxMsgSize, &dns.EDNS0_EDE{
+		InfoCode:  dns.ExtendedErrorCodeDNSSECIndeterminate,
+		ExtraText: extraText,
+	})}
+	assert.Equal(t, wantExtra, resp.Extra)
+}
+
 func TestConstructor_NewRespTXT(t *testing.T) {
 	t.Parallel()
 
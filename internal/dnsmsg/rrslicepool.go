@@ -0,0 +1,140 @@
+package dnsmsg
+
+import (
+	"github.com/AdguardTeam/golibs/syncutil"
+	"github.com/miekg/dns"
+)
+
+// rrSliceClasses are the capacities of the resource-record slices kept in the
+// size-classed pools.  The slices for larger sections are allocated directly
+// and are never pooled.
+var rrSliceClasses = [...]int{1, 4, 16, 64, 256}
+
+// maxAttachedRRs is the maximum capacity of a resource-record slice that is
+// kept in a disposed message.  Larger slices are returned into the size-classed
+// pools.
+const maxAttachedRRs = 4
+
+// rrSlicePool is a set of size-classed pools of resource-record slices.
+// Pooling the large slices separately from the messages makes sure that a
+// message with a large answer doesn't pin a large slice in the message pool
+// and that the messages with empty sections don't lose their slices.
+type rrSlicePool struct {
+	stat ClonerStat
+
+	// holders are the empty pointers used to put the slices into classes
+	// without allocating.
+	holders *syncutil.Pool[[]dns.RR]
+
+	// classes are the pools of the slices with the capacities from
+	// [rrSliceClasses].
+	classes [len(rrSliceClasses)]*syncutil.Pool[[]dns.RR]
+}
+
+// newRRSlicePool returns a new properly initialized *rrSlicePool.
+func newRRSlicePool(stat ClonerStat) (p *rrSlicePool) {
+	p = &rrSlicePool{
+		stat:    stat,
+		holders: syncutil.NewPool(newRRSliceHolder),
+	}
+
+	for i := range p.classes {
+		p.classes[i] = syncutil.NewPool(newRRSliceHolder)
+	}
+
+	return p
+}
+
+// newRRSliceHolder returns a pointer to a nil resource-record slice.
+func newRRSliceHolder() (v *[]dns.RR) {
+	return new([]dns.RR)
+}
+
+// release returns rrs, if any, into the pools.  rrs must not be used after
+// this.
+func (p *rrSlicePool) release(rrs []dns.RR) {
+	if rrs != nil {
+		p.put(rrs)
+	}
+}
+
+// replace returns rrs, if any, into the pools and returns an empty slice able
+// to hold n records.  rrs must not be used after this.
+func (p *rrSlicePool) replace(rrs []dns.RR, n int) (res []dns.RR) {
+	p.release(rrs)
+
+	return p.get(n)
+}
+
+// get returns an empty non-nil slice with the capacity of at least n.
+func (p *rrSlicePool) get(n int) (rrs []dns.RR) {
+	if n == 0 {
+		return []dns.RR{}
+	}
+
+	i := rrSliceClassIdx(n)
+	if i < 0 {
+		p.stat.OnRRSliceGet(false)
+
+		return make([]dns.RR, 0, n)
+	}
+
+	h := p.classes[i].Get()
+	rrs = *h
+	*h = nil
+	p.holders.Put(h)
+
+	isHit := rrs != nil
+	p.stat.OnRRSliceGet(isHit)
+	if !isHit {
+		return make([]dns.RR, 0, rrSliceClasses[i])
+	}
+
+	return rrs
+}
+
+// detachLarge returns rrs into the pools and nil if its capacity is larger
+// than [maxAttachedRRs].  Otherwise, it returns rrs.
+func (p *rrSlicePool) detachLarge(rrs []dns.RR) (res []dns.RR) {
+	if cap(rrs) <= maxAttachedRRs {
+		return rrs
+	}
+
+	p.put(rrs)
+
+	return nil
+}
+
+// put returns rrs into the pool of its class.  rrs are only pooled if their
+// capacity exactly matches one of [rrSliceClasses].  rrs must not be used after
+// this.
+func (p *rrSlicePool) put(rrs []dns.RR) {
+	c := cap(rrs)
+	if c == 0 {
+		return
+	}
+
+	i := rrSliceClassIdx(c)
+	if i < 0 || rrSliceClasses[i] != c {
+		return
+	}
+
+	// Clear the slice to make sure that the pool doesn't keep the records.
+	clear(rrs[:c])
+
+	h := p.holders.Get()
+	*h = rrs[:0]
+	p.classes[i].Put(h)
+}
+
+// rrSliceClassIdx returns the index of the smallest class in [rrSliceClasses]
+// that can hold n records or -1 if there is none.
+func rrSliceClassIdx(n int) (i int) {
+	for i, c := range rrSliceClasses {
+		if n <= c {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,44 @@
+package dnsserver
+
+import (
+	"context"
+	"net"
+	"net/netip"
+
+	"github.com/AdguardTeam/golibs/netutil"
+)
+
+// TLSConnInspector inspects DNS-over-TLS connections right after the TLS
+// handshake, before any queries are read from them.
+//
+// NOTE:  Implementations must be thread-safe.
+type TLSConnInspector interface {
+	// InspectTLSConn returns the connection-scoped hint for the queries sent
+	// over the connection.  serverName is the original, non-lowercased server
+	// name field of the client's TLS hello request, raddr and laddr are the
+	// remote and the local addresses of the connection.  hint is set as
+	// [RequestInfo.ConnHint] of every request received over the connection.  If
+	// err is not nil, the connection is closed.
+	InspectTLSConn(
+		ctx context.Context,
+		serverName string,
+		raddr netip.AddrPort,
+		laddr netip.AddrPort,
+	) (hint any, err error)
+}
+
+// inspectTLSConn returns the connection hint for conn using the TLS connection
+// inspector of s, if any.  The handshake must have already been performed.
+func (s *ServerDNS) inspectTLSConn(ctx context.Context, conn net.Conn) (hint any, err error) {
+	cs, ok := conn.(tlsConnectionStater)
+	if s.tlsConnInspector == nil || !ok {
+		return nil, nil
+	}
+
+	return s.tlsConnInspector.InspectTLSConn(
+		ctx,
+		cs.ConnectionState().ServerName,
+		netutil.NetAddrToAddrPort(conn.RemoteAddr()),
+		netutil.NetAddrToAddrPort(conn.LocalAddr()),
+	)
+}
@@ -3,8 +3,15 @@ package dnsserver
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"net/netip"
 	"net/url"
 	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/tlsfingerprint"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/quic-go/quic-go"
+	"golang.org/x/time/rate"
 )
 
 // ContextConstructor is an interface for constructing interfaces with
@@ -57,6 +64,7 @@ type ctxKey int
 const (
 	ctxKeyServerInfo ctxKey = iota
 	ctxKeyRequestInfo
+	ctxKeyConnInfo
 )
 
 // type check
@@ -69,6 +77,8 @@ func (k ctxKey) String() (s string) {
 		return "dnsserver.ctxKeyServerInfo"
 	case ctxKeyRequestInfo:
 		return "dnsserver.ctxKeyRequestInfo"
+	case ctxKeyConnInfo:
+		return "dnsserver.ctxKeyConnInfo"
 	default:
 		panic(fmt.Errorf("bad ctx key value %d", k))
 	}
@@ -119,8 +129,11 @@ func MustServerInfoFromContext(ctx context.Context) (si *ServerInfo) {
 	return si
 }
 
-// RequestInfo is a structure that contains basic request information.  It is
-// attached to every context.Context linked to processing a DNS request.
+// RequestInfo is the request-scoped metadata about the transport of a DNS
+// request.  It is attached to every context.Context linked to processing a DNS
+// request, so that all middlewares can access it using [RequestInfoFromContext]
+// or [MustRequestInfoFromContext].  Fields that don't apply to the protocol of
+// the server have zero values.
 type RequestInfo struct {
 	// URL is the request URL.  It is set only if the protocol of the server is
 	// DoH.
@@ -130,6 +143,22 @@ type RequestInfo struct {
 	// only if the protocol of the server is DoH.
 	Userinfo *url.Userinfo
 
+	// HTTPHeaders is the subset of the headers of the HTTP request, which
+	// contains only the Accept, Origin, and User-Agent headers, if any.  It is
+	// set only if the protocol of the server is DoH.
+	HTTPHeaders http.Header
+
+	// TLSFingerprint are the fingerprints of the client's TLS hello request.
+	// It is set only if the protocol of the server is either DoT or DoH over
+	// TCP and the fingerprinting is enabled.  It is nil if the fingerprints
+	// could not be calculated.
+	TLSFingerprint *tlsfingerprint.Fingerprint
+
+	// ConnHint is the connection-scoped hint returned by the
+	// [TLSConnInspector] of the server for the connection of the request, if
+	// any.  It is set only if the protocol of the server is DoT.
+	ConnHint any
+
 	// StartTime is the request's start time.  It's never zero value.
 	StartTime time.Time
 
@@ -139,6 +168,49 @@ type RequestInfo struct {
 	//
 	// TODO(ameshkov): use r.TLS with DoH3 (see addRequestInfo).
 	TLSServerName string
+
+	// ALPN is the application protocol negotiated during the TLS handshake, if
+	// any.  It is set only if the protocol of the server is either DoQ, DoT or
+	// DoH.
+	ALPN string
+
+	// Network is the network over which the request has been received.  It is
+	// [NetworkUDP] for DoQ and DoH over HTTP/3.  It is [NetworkAny] if the
+	// network is unknown.
+	Network Network
+
+	// LocalAddr is the local address on which the request has been received.
+	// It may be invalid if the address is unknown.
+	LocalAddr netip.AddrPort
+
+	// Used0RTT is true if the request has been received on a QUIC connection
+	// that has used 0-RTT.  It is set only if the protocol of the server is
+	// either DoQ or DoH over HTTP/3.
+	Used0RTT bool
+}
+
+// requestInfoHTTPHeaders are the canonical names of the headers of the DoH
+// requests that are kept in [RequestInfo.HTTPHeaders].
+var requestInfoHTTPHeaders = []string{
+	httphdr.Accept,
+	httphdr.Origin,
+	httphdr.UserAgent,
+}
+
+// Elapsed returns the duration since the start of the request.  ri must not
+// be nil.
+func (ri *RequestInfo) Elapsed() (d time.Duration) {
+	return time.Since(ri.StartTime)
+}
+
+// HTTPPath returns the path of the URL of the request, if any.  ri must not be
+// nil.
+func (ri *RequestInfo) HTTPPath() (p string) {
+	if ri.URL == nil {
+		return ""
+	}
+
+	return ri.URL.Path
 }
 
 // ContextWithRequestInfo attaches RequestInfo to the specified context.  ri
@@ -173,6 +245,43 @@ func MustRequestInfoFromContext(ctx context.Context) (ri *RequestInfo) {
 	return ri
 }
 
+// connInfo is the connection-scoped data attached to the contexts of the
+// connections of the servers, from which the per-request data are taken.
+type connInfo struct {
+	// tlsFingerprintConn is the fingerprinting connection underlying the TLS
+	// connection, if any.
+	tlsFingerprintConn *tlsfingerprint.Conn
+
+	// httpLimiter is the per-connection rate limiter of the DoH requests, if
+	// any.
+	httpLimiter *rate.Limiter
+
+	// quicConn is the QUIC connection of the DoH requests over HTTP/3, if any.
+	quicConn quic.Connection
+}
+
+// contextWithConnInfo attaches ci to the specified context.  ci must not be
+// nil.
+func contextWithConnInfo(parent context.Context, ci *connInfo) (ctx context.Context) {
+	return context.WithValue(parent, ctxKeyConnInfo, ci)
+}
+
+// connInfoFromContext returns the connection data attached to the context, if
+// any.
+func connInfoFromContext(ctx context.Context) (ci *connInfo, found bool) {
+	v := ctx.Value(ctxKeyConnInfo)
+	if v == nil {
+		return nil, false
+	}
+
+	ci, ok := v.(*connInfo)
+	if !ok {
+		panicBadType(ctxKeyConnInfo, v)
+	}
+
+	return ci, true
+}
+
 // panicBadType is a helper that panics with a message about the context key and
 // the expected type.
 func panicBadType(key ctxKey, v any) {
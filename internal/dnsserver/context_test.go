@@ -2,6 +2,7 @@ package dnsserver_test
 
 import (
 	"context"
+	"net/url"
 	"testing"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
@@ -31,3 +32,11 @@ func TestMustServerInfoFromContext(t *testing.T) {
 		_ = dnsserver.MustServerInfoFromContext(ctx)
 	})
 }
+
+func TestRequestInfo_HTTPPath(t *testing.T) {
+	ri := &dnsserver.RequestInfo{}
+	require.Empty(t, ri.HTTPPath())
+
+	ri.URL = &url.URL{Path: "/dns-query/dev1234"}
+	require.Equal(t, "/dns-query/dev1234", ri.HTTPPath())
+}
@@ -0,0 +1,66 @@
+package dnsservertest
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+)
+
+// ServerName is the TLS server name of the requests in the contexts returned by
+// [ContextWithRequest] for the standard encrypted protocols.
+const ServerName = "dns.example"
+
+// ServerInfoName is the name of the server in the contexts returned by
+// [ContextWithRequest].
+const ServerInfoName = "test_server"
+
+// ContextWithRequest returns a copy of parent with the [dnsserver.ServerInfo]
+// and the [dnsserver.RequestInfo] typical for a request received by a server of
+// proto over network, consistent with the addresses of the [ResponseWriter]
+// returned by [NewResponseWriter] for the same arguments.  If network is
+// [dnsserver.NetworkAny], the network most commonly used by proto is used.
+func ContextWithRequest(
+	parent context.Context,
+	proto dnsserver.Protocol,
+	network dnsserver.Network,
+) (ctx context.Context) {
+	network = protoNetwork(proto, network)
+	localAddr := protoLocalAddr(proto)
+
+	ctx = dnsserver.ContextWithServerInfo(parent, &dnsserver.ServerInfo{
+		Name:  ServerInfoName,
+		Addr:  localAddr.String(),
+		Proto: proto,
+	})
+
+	ri := &dnsserver.RequestInfo{
+		StartTime: time.Now(),
+		Network:   network,
+		LocalAddr: localAddr,
+	}
+
+	switch proto {
+	case dnsserver.ProtoDoT, dnsserver.ProtoDoQ:
+		ri.TLSServerName = ServerName
+		ri.ALPN = proto.ALPN()[0]
+	case dnsserver.ProtoDoH:
+		ri.TLSServerName = ServerName
+		ri.URL = &url.URL{
+			Scheme: "https",
+			Host:   ServerName,
+			Path:   dnsserver.PathDoH,
+		}
+
+		if network == dnsserver.NetworkUDP {
+			ri.ALPN = "h3"
+		} else {
+			ri.ALPN = "h2"
+		}
+	default:
+		// Go on.
+	}
+
+	return dnsserver.ContextWithRequestInfo(ctx, ri)
+}
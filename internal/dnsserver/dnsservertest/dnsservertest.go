@@ -1,3 +1,12 @@
 // Package dnsservertest provides convenient helper functions for unit-tests
-// in packages related to dnsserver.
+// in packages related to dnsserver.  Besides the test servers, it contains a
+// toolkit for the authors of middlewares:
+//
+//   - [ResponseWriter] and [ContextWithRequest], which fake the response writer
+//     and the request context of a server of each protocol;
+//   - [NewReq], [NewEDNSReq], and [NewResp], which build DNS messages;
+//   - [RunMiddlewareConformance], which checks that a middleware follows the
+//     contract of [dnsserver.Middleware];
+//   - [RequireGolden] and [RequireGoldenMsg], which compare the data with
+//     golden files.
 package dnsservertest
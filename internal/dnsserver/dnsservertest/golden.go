@@ -0,0 +1,53 @@
+package dnsservertest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// EnvUpdateGolden is the name of the environment variable, which, if set to
+// "1", makes the golden-file helpers rewrite the golden files with the actual
+// data instead of comparing them.
+const EnvUpdateGolden = "ADGUARD_DNS_TEST_UPDATE_GOLDEN"
+
+// RequireGolden checks that got is equal to the content of the golden file at
+// path.  If the environment variable [EnvUpdateGolden] is set to "1", the file
+// is rewritten with got instead.
+func RequireGolden(tb testing.TB, got []byte, path string) {
+	tb.Helper()
+
+	if os.Getenv(EnvUpdateGolden) == "1" {
+		err := os.MkdirAll(filepath.Dir(path), 0o755)
+		require.NoError(tb, err)
+
+		err = os.WriteFile(path, got, 0o644)
+		require.NoError(tb, err)
+
+		return
+	}
+
+	// #nosec G304 -- Trust the path, since it's given by the test.
+	want, err := os.ReadFile(path)
+	require.NoError(tb, err)
+
+	assert.Equal(tb, string(want), string(got))
+}
+
+// RequireGoldenMsg checks that the text representation of msg is equal to the
+// content of the golden file at path.  The ID of the message is replaced with
+// zero, since it's usually random.  See [RequireGolden].
+func RequireGoldenMsg(tb testing.TB, msg *dns.Msg, path string) {
+	tb.Helper()
+
+	require.NotNil(tb, msg)
+
+	msg = msg.Copy()
+	msg.Id = 0
+
+	RequireGolden(tb, []byte(msg.String()), path)
+}
@@ -0,0 +1,33 @@
+package dnsservertest_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireGoldenMsg(t *testing.T) {
+	t.Parallel()
+
+	ctx := dnsservertest.ContextWithRequest(
+		context.Background(),
+		dnsserver.ProtoDoT,
+		dnsserver.NetworkAny,
+	)
+	rw := dnsservertest.NewResponseWriter(dnsserver.ProtoDoT, dnsserver.NetworkAny)
+	req := dnsservertest.NewEDNSReq("example.org", dns.TypeA, true, dns.DefaultMsgSize)
+
+	err := dnsservertest.NewDefaultHandlerWithCount(2).ServeDNS(ctx, rw, req)
+	require.NoError(t, err)
+
+	assert.Equal(t, "tcp", rw.LocalAddr().Network())
+	assert.Len(t, rw.Msgs(), 1)
+
+	dnsservertest.RequireGoldenMsg(t, rw.Msg(), filepath.Join("testdata", "default_handler.golden"))
+}
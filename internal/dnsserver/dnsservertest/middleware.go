@@ -0,0 +1,307 @@
+package dnsservertest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DefaultMiddlewareTimeout is the default timeout of the requests in the
+// checks of [RunMiddlewareConformance].
+const DefaultMiddlewareTimeout = 1 * time.Second
+
+// MiddlewareConformanceConfig is the configuration for
+// [RunMiddlewareConformance].
+type MiddlewareConformanceConfig struct {
+	// Middleware is the middleware being checked.  It must not be nil.
+	Middleware dnsserver.Middleware
+
+	// Protocols are the protocols of the servers, for which the middleware is
+	// checked.  If empty, all protocols are used.
+	Protocols []dnsserver.Protocol
+
+	// Timeout is the timeout of the requests in the timeout check.  The
+	// middleware must return within twice this duration.  If zero,
+	// [DefaultMiddlewareTimeout] is used.
+	Timeout time.Duration
+}
+
+// allProtocols are the protocols used by [RunMiddlewareConformance] by default.
+var allProtocols = []dnsserver.Protocol{
+	dnsserver.ProtoDNS,
+	dnsserver.ProtoDoT,
+	dnsserver.ProtoDoH,
+	dnsserver.ProtoDoQ,
+	dnsserver.ProtoDNSCrypt,
+}
+
+// errNextHandler is the error returned by the next handler in the handler
+// error check of [RunMiddlewareConformance].
+const errNextHandler errors.Error = "next handler error"
+
+// RunMiddlewareConformance checks that the middleware from c follows the
+// contract of [dnsserver.Middleware] and [dnsserver.Handler] for the requests
+// received over each protocol.  The checks, each of which uses a separate
+// question name so that caching middlewares don't skip the next handler, are:
+//
+//   - response:  at most one response is written, and it matches the request;
+//   - context:  the server and request information are kept in the context
+//     passed to the next handler;
+//   - handler_error:  the error of the next handler is not swallowed;
+//   - panic:  the panic of the next handler is either propagated or turned
+//     into an error;
+//   - timeout:  the middleware returns soon after the deadline of the request;
+//   - cancel:  the context passed to the next handler is canceled along with
+//     the request context.
+//
+// The conditions on the next handler are only checked if the middleware calls
+// it.  c must not be nil.
+func RunMiddlewareConformance(t *testing.T, c *MiddlewareConformanceConfig) {
+	t.Helper()
+
+	protos := c.Protocols
+	if len(protos) == 0 {
+		protos = allProtocols
+	}
+
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultMiddlewareTimeout
+	}
+
+	for _, proto := range protos {
+		t.Run(proto.String(), func(t *testing.T) {
+			mc := &middlewareChecker{
+				mw:      c.Middleware,
+				proto:   proto,
+				timeout: timeout,
+			}
+
+			t.Run("response", mc.checkResponse)
+			t.Run("context", mc.checkContext)
+			t.Run("handler_error", mc.checkHandlerError)
+			t.Run("panic", mc.checkPanic)
+			t.Run("timeout", mc.checkTimeout)
+			t.Run("cancel", mc.checkCancel)
+		})
+	}
+}
+
+// middlewareChecker contains the common data of the checks of
+// [RunMiddlewareConformance].
+type middlewareChecker struct {
+	mw      dnsserver.Middleware
+	proto   dnsserver.Protocol
+	timeout time.Duration
+}
+
+// newRequest is a helper that returns the context, response writer, and
+// request for a check with the given name.
+func (mc *middlewareChecker) newRequest(
+	parent context.Context,
+	check string,
+) (ctx context.Context, rw *ResponseWriter, req *dns.Msg) {
+	ctx = ContextWithRequest(parent, mc.proto, dnsserver.NetworkAny)
+	rw = NewResponseWriter(mc.proto, dnsserver.NetworkAny)
+	req = CreateMessage(check+".conformance.example", dns.TypeA)
+
+	return ctx, rw, req
+}
+
+// serve calls the middleware wrapping next in a separate goroutine and waits
+// for it to return for twice the timeout.  recovered is the value of the
+// panic, if any.
+func (mc *middlewareChecker) serve(
+	tb testing.TB,
+	ctx context.Context,
+	next dnsserver.Handler,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+) (recovered any, err error) {
+	tb.Helper()
+
+	h := mc.mw.Wrap(next)
+
+	type result struct {
+		recovered any
+		err       error
+	}
+
+	resCh := make(chan result, 1)
+	go func() {
+		res := result{}
+		defer func() {
+			res.recovered = recover()
+			resCh <- res
+		}()
+
+		res.err = h.ServeDNS(ctx, rw, req)
+	}()
+
+	select {
+	case res := <-resCh:
+		return res.recovered, res.err
+	case <-time.After(2 * mc.timeout):
+		require.FailNow(tb, "middleware did not return in time", "timeout: %s", mc.timeout)
+
+		// Never reached.
+		return nil, nil
+	}
+}
+
+// checkResponse checks that the middleware writes at most one response, which
+// matches the request.
+func (mc *middlewareChecker) checkResponse(t *testing.T) {
+	ctx, rw, req := mc.newRequest(context.Background(), "response")
+
+	recovered, err := mc.serve(t, ctx, NewDefaultHandler(), rw, req)
+	require.Nil(t, recovered)
+	require.NoError(t, err)
+
+	resps := rw.Msgs()
+	require.LessOrEqual(t, len(resps), 1)
+
+	for _, resp := range resps {
+		assert.Equal(t, req.Id, resp.Id)
+		assert.True(t, resp.Response)
+		assert.Equal(t, req.Question, resp.Question)
+	}
+}
+
+// checkContext checks that the middleware keeps the server and request
+// information in the context passed to the next handler.
+func (mc *middlewareChecker) checkContext(t *testing.T) {
+	ctx, rw, req := mc.newRequest(context.Background(), "context")
+
+	var called, hasSrvInfo, hasReqInfo bool
+	next := dnsserver.HandlerFunc(func(
+		nextCtx context.Context,
+		nextRW dnsserver.ResponseWriter,
+		nextReq *dns.Msg,
+	) (err error) {
+		called = true
+		_, hasSrvInfo = dnsserver.ServerInfoFromContext(nextCtx)
+		_, hasReqInfo = dnsserver.RequestInfoFromContext(nextCtx)
+
+		return nextRW.WriteMsg(nextCtx, nextReq, NewResp(dns.RcodeSuccess, nextReq))
+	})
+
+	recovered, err := mc.serve(t, ctx, next, rw, req)
+	require.Nil(t, recovered)
+	require.NoError(t, err)
+
+	if called {
+		assert.True(t, hasSrvInfo, "server info")
+		assert.True(t, hasReqInfo, "request info")
+	}
+}
+
+// checkHandlerError checks that the middleware doesn't swallow the error of the
+// next handler.
+func (mc *middlewareChecker) checkHandlerError(t *testing.T) {
+	ctx, rw, req := mc.newRequest(context.Background(), "handler-error")
+
+	called := false
+	next := dnsserver.HandlerFunc(func(
+		_ context.Context,
+		_ dnsserver.ResponseWriter,
+		_ *dns.Msg,
+	) (err error) {
+		called = true
+
+		return errNextHandler
+	})
+
+	recovered, err := mc.serve(t, ctx, next, rw, req)
+	require.Nil(t, recovered)
+
+	if called {
+		assert.ErrorIs(t, err, errNextHandler)
+	}
+}
+
+// checkPanic checks that the middleware either propagates the panic of the
+// next handler or turns it into an error.
+func (mc *middlewareChecker) checkPanic(t *testing.T) {
+	ctx, rw, req := mc.newRequest(context.Background(), "panic")
+
+	called := false
+	panicHdlr := NewPanicHandler()
+	next := dnsserver.HandlerFunc(func(
+		nextCtx context.Context,
+		nextRW dnsserver.ResponseWriter,
+		nextReq *dns.Msg,
+	) (err error) {
+		called = true
+
+		return panicHdlr.ServeDNS(nextCtx, nextRW, nextReq)
+	})
+
+	recovered, err := mc.serve(t, ctx, next, rw, req)
+	if called {
+		assert.True(t, recovered != nil || err != nil, "panic swallowed")
+	}
+}
+
+// checkTimeout checks that the middleware returns soon after the deadline of
+// the request, if the next handler respects it.
+func (mc *middlewareChecker) checkTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), mc.timeout)
+	t.Cleanup(cancel)
+
+	ctx, rw, req := mc.newRequest(ctx, "timeout")
+
+	called := false
+	next := dnsserver.HandlerFunc(func(
+		nextCtx context.Context,
+		_ dnsserver.ResponseWriter,
+		_ *dns.Msg,
+	) (err error) {
+		called = true
+
+		<-nextCtx.Done()
+
+		return nextCtx.Err()
+	})
+
+	recovered, err := mc.serve(t, ctx, next, rw, req)
+	require.Nil(t, recovered)
+
+	if called {
+		assert.Error(t, err)
+	}
+}
+
+// checkCancel checks that the context passed to the next handler is canceled
+// along with the request context.
+func (mc *middlewareChecker) checkCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	ctx, rw, req := mc.newRequest(ctx, "cancel")
+
+	var called, isCanceled bool
+	next := dnsserver.HandlerFunc(func(
+		nextCtx context.Context,
+		_ dnsserver.ResponseWriter,
+		_ *dns.Msg,
+	) (err error) {
+		called = true
+		isCanceled = nextCtx.Err() != nil
+
+		return nextCtx.Err()
+	})
+
+	recovered, _ := mc.serve(t, ctx, next, rw, req)
+	require.Nil(t, recovered)
+
+	if called {
+		assert.True(t, isCanceled, "next handler context not canceled")
+	}
+}
@@ -0,0 +1,53 @@
+package dnsservertest_test
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/cache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/querylog"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 100 * time.Millisecond
+
+// passMiddleware is a [dnsserver.Middleware] that returns the handler as is.
+type passMiddleware struct{}
+
+// type check
+var _ dnsserver.Middleware = passMiddleware{}
+
+// Wrap implements the [dnsserver.Middleware] interface for passMiddleware.
+func (passMiddleware) Wrap(h dnsserver.Handler) (wrapped dnsserver.Handler) {
+	return h
+}
+
+func TestRunMiddlewareConformance(t *testing.T) {
+	testCases := []struct {
+		mw   dnsserver.Middleware
+		name string
+	}{{
+		mw:   passMiddleware{},
+		name: "pass",
+	}, {
+		mw:   querylog.NewLogMiddleware(io.Discard),
+		name: "querylog",
+	}, {
+		mw: cache.NewMiddleware(&cache.MiddlewareConfig{
+			Count: 100,
+		}),
+		name: "cache",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dnsservertest.RunMiddlewareConformance(t, &dnsservertest.MiddlewareConformanceConfig{
+				Middleware: tc.mw,
+				Timeout:    testTimeout,
+			})
+		})
+	}
+}
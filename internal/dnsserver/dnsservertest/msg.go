@@ -18,6 +18,16 @@ func CreateMessage(hostname string, qtype uint16) (m *dns.Msg) {
 	return m
 }
 
+// NewEDNSReq returns the new DNS request with a single question for hostname
+// and qtype of class IN with the recursion desired and an OPT record with the
+// DO bit and the UDP payload size set to do and udpSize.
+func NewEDNSReq(hostname string, qtype uint16, do bool, udpSize uint16) (m *dns.Msg) {
+	m = NewReq(hostname, qtype, dns.ClassINET, SectionExtra{NewOPT(do, udpSize)})
+	m.RecursionDesired = true
+
+	return m
+}
+
 // RequireResponse checks that the DNS response we received is what was
 // expected.
 func RequireResponse(
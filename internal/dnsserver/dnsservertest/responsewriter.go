@@ -0,0 +1,135 @@
+package dnsservertest
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// RemoteAddr is the address of the client used by [NewResponseWriter] and
+// [ContextWithRequest].
+var RemoteAddr = netip.MustParseAddrPort("192.0.2.1:12345")
+
+// protoLocalAddr returns the local address of a server of proto typically used
+// for it.
+func protoLocalAddr(proto dnsserver.Protocol) (addr netip.AddrPort) {
+	var port uint16
+	switch proto {
+	case dnsserver.ProtoDoT, dnsserver.ProtoDoQ:
+		port = 853
+	case dnsserver.ProtoDoH:
+		port = 443
+	case dnsserver.ProtoDNSCrypt:
+		port = 5443
+	default:
+		port = 53
+	}
+
+	return netip.AddrPortFrom(netip.MustParseAddr("127.0.0.1"), port)
+}
+
+// protoNetwork returns network, if it is not [dnsserver.NetworkAny], or the
+// network most commonly used by proto otherwise.
+func protoNetwork(proto dnsserver.Protocol, network dnsserver.Network) (n dnsserver.Network) {
+	if network != dnsserver.NetworkAny {
+		return network
+	}
+
+	switch proto {
+	case dnsserver.ProtoDoT, dnsserver.ProtoDoH:
+		return dnsserver.NetworkTCP
+	default:
+		return dnsserver.NetworkUDP
+	}
+}
+
+// newNetAddr returns addr as a [net.Addr] of network, which must not be
+// [dnsserver.NetworkAny].
+func newNetAddr(network dnsserver.Network, addr netip.AddrPort) (a net.Addr) {
+	if network == dnsserver.NetworkTCP {
+		return net.TCPAddrFromAddrPort(addr)
+	}
+
+	return net.UDPAddrFromAddrPort(addr)
+}
+
+// ResponseWriter is a [dnsserver.ResponseWriter] for tests that records the
+// written responses instead of sending them.  It is safe for concurrent use.
+type ResponseWriter struct {
+	localAddr  net.Addr
+	remoteAddr net.Addr
+
+	// mu protects resps.
+	mu    *sync.Mutex
+	resps []*dns.Msg
+}
+
+// NewResponseWriter returns a new *ResponseWriter with the addresses typical
+// for a request received by a server of proto over network.  If network is
+// [dnsserver.NetworkAny], the network most commonly used by proto is used.
+func NewResponseWriter(proto dnsserver.Protocol, network dnsserver.Network) (rw *ResponseWriter) {
+	network = protoNetwork(proto, network)
+
+	return &ResponseWriter{
+		localAddr:  newNetAddr(network, protoLocalAddr(proto)),
+		remoteAddr: newNetAddr(network, RemoteAddr),
+		mu:         &sync.Mutex{},
+	}
+}
+
+// type check
+var _ dnsserver.ResponseWriter = (*ResponseWriter)(nil)
+
+// LocalAddr implements the [dnsserver.ResponseWriter] interface for
+// *ResponseWriter.
+func (rw *ResponseWriter) LocalAddr() (addr net.Addr) {
+	return rw.localAddr
+}
+
+// RemoteAddr implements the [dnsserver.ResponseWriter] interface for
+// *ResponseWriter.
+func (rw *ResponseWriter) RemoteAddr() (addr net.Addr) {
+	return rw.remoteAddr
+}
+
+// WriteMsg implements the [dnsserver.ResponseWriter] interface for
+// *ResponseWriter.  It returns an error if resp is nil.
+func (rw *ResponseWriter) WriteMsg(_ context.Context, _, resp *dns.Msg) (err error) {
+	if resp == nil {
+		return errors.Error("nil response")
+	}
+
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.resps = append(rw.resps, resp)
+
+	return nil
+}
+
+// Msg returns the last written response or nil if there were none.
+func (rw *ResponseWriter) Msg() (resp *dns.Msg) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if len(rw.resps) == 0 {
+		return nil
+	}
+
+	return rw.resps[len(rw.resps)-1]
+}
+
+// Msgs returns all written responses in the order in which they have been
+// written.
+func (rw *ResponseWriter) Msgs() (resps []*dns.Msg) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	return slices.Clone(rw.resps)
+}
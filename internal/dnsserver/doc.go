@@ -162,6 +162,11 @@ you need to do is implement dnsserver.Middleware interface and use it this way:
 
 After that you can use the resulting handler when creating server instances.
 
+Package dnsservertest contains the helpers for testing middlewares, including
+the fake response writers and request contexts for each protocol and
+RunMiddlewareConformance, which checks that a middleware follows the contract
+of the interface.
+
 # Metrics And Error Reporting
 
 Package dnsserver allows you to register custom listeners which would be called
@@ -0,0 +1,252 @@
+package forward
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// BootstrapConfig is the configuration structure for [NewBootstrap].
+type BootstrapConfig struct {
+	// Logger is used for logging the resolution of hostnames.  If Logger is
+	// nil, [slog.Default] is used.
+	Logger *slog.Logger
+
+	// Resolvers are the addresses of the plain-DNS servers used to resolve the
+	// hostnames of upstreams.  They are tried in order until one of them
+	// responds.  It must not be empty.
+	Resolvers []netip.AddrPort
+
+	// Timeout is the optional query timeout for the resolvers.  If not set, the
+	// context timeout or [defaultUDPTimeout] is used.
+	Timeout time.Duration
+}
+
+// Bootstrap resolves the hostnames of upstreams using plain-DNS resolvers.  The
+// results are cached for the lowest TTL of the answer records.
+type Bootstrap struct {
+	// logger is used for logging the resolution of hostnames.
+	logger *slog.Logger
+
+	// cacheMu protects cache.
+	cacheMu *sync.Mutex
+
+	// cache maps the lowercased FQDNs to the resolution results.
+	cache map[string]*bootstrapEntry
+
+	// resolvers are the upstreams used to resolve hostnames.  It is never
+	// empty.
+	resolvers []*UpstreamPlain
+}
+
+// bootstrapEntry is a cached result of a hostname resolution.
+type bootstrapEntry struct {
+	// expire is the time after which the entry must be resolved again.
+	expire time.Time
+
+	// addrs are the sorted unique IP addresses of the hostname.
+	addrs []netip.Addr
+}
+
+// NewBootstrap returns a new properly initialized *Bootstrap.  c must not be
+// nil and must be valid.
+func NewBootstrap(c *BootstrapConfig) (b *Bootstrap) {
+	b = &Bootstrap{
+		logger:    cmp.Or(c.Logger, slog.Default()),
+		cacheMu:   &sync.Mutex{},
+		cache:     map[string]*bootstrapEntry{},
+		resolvers: make([]*UpstreamPlain, 0, len(c.Resolvers)),
+	}
+
+	for _, addr := range c.Resolvers {
+		b.resolvers = append(b.resolvers, NewUpstreamPlain(&UpstreamPlainConfig{
+			Network: NetworkAny,
+			Address: addr,
+			Timeout: c.Timeout,
+		}))
+	}
+
+	return b
+}
+
+// Resolve returns the sorted unique IP addresses of host.  The results are
+// cached for the lowest TTL of the answer records.  If none of the resolvers
+// respond with addresses, err is not nil and contains a [net.Error].
+func (b *Bootstrap) Resolve(ctx context.Context, host string) (addrs []netip.Addr, err error) {
+	fqdn := strings.ToLower(dns.Fqdn(host))
+
+	now := time.Now()
+	if addrs = b.cached(fqdn, now); addrs != nil {
+		return addrs, nil
+	}
+
+	var errs []error
+	for _, r := range b.resolvers {
+		var ttl time.Duration
+		addrs, ttl, err = resolve(ctx, r, fqdn)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("bootstrap %s: %w", r, err))
+
+			continue
+		}
+
+		b.logger.DebugContext(ctx, "resolved", "host", fqdn, "addrs", addrs, "ttl", ttl)
+
+		b.cacheMu.Lock()
+		defer b.cacheMu.Unlock()
+
+		b.cache[fqdn] = &bootstrapEntry{
+			expire: now.Add(ttl),
+			addrs:  addrs,
+		}
+
+		return addrs, nil
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// cached returns the cached addresses of fqdn, if there are any and they
+// haven't expired by now.
+func (b *Bootstrap) cached(fqdn string, now time.Time) (addrs []netip.Addr) {
+	b.cacheMu.Lock()
+	defer b.cacheMu.Unlock()
+
+	e, ok := b.cache[fqdn]
+	if !ok || !now.Before(e.expire) {
+		return nil
+	}
+
+	return e.addrs
+}
+
+// Invalidate removes the cached addresses of host, so that the next call to
+// [Bootstrap.Resolve] resolves it again.  It is used when the upstream at the
+// resolved addresses fails.
+func (b *Bootstrap) Invalidate(host string) {
+	fqdn := strings.ToLower(dns.Fqdn(host))
+
+	b.cacheMu.Lock()
+	defer b.cacheMu.Unlock()
+
+	delete(b.cache, fqdn)
+}
+
+// exchange sends req to the resolvers in order and returns the first
+// response.
+func (b *Bootstrap) exchange(ctx context.Context, req *dns.Msg) (resp *dns.Msg, err error) {
+	var errs []error
+	for _, r := range b.resolvers {
+		resp, _, err = r.Exchange(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		errs = append(errs, fmt.Errorf("bootstrap %s: %w", r, err))
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// type check
+var _ io.Closer = (*Bootstrap)(nil)
+
+// Close implements the [io.Closer] interface for *Bootstrap.
+func (b *Bootstrap) Close() (err error) {
+	errs := make([]error, 0, len(b.resolvers))
+	for _, r := range b.resolvers {
+		errs = append(errs, r.Close())
+	}
+
+	return errors.Annotate(errors.Join(errs...), "closing bootstrap: %w")
+}
+
+// resolve queries ups for the A and AAAA records of fqdn.  ttl is the lowest
+// TTL of the answer records.  All errors returned for the responses are
+// [*net.DNSError].
+func resolve(
+	ctx context.Context,
+	ups *UpstreamPlain,
+	fqdn string,
+) (addrs []netip.Addr, ttl time.Duration, err error) {
+	minTTL := uint32(math.MaxUint32)
+	for _, qt := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		req := &dns.Msg{}
+		req.SetQuestion(fqdn, qt)
+
+		var resp *dns.Msg
+		resp, _, err = ups.Exchange(ctx, req)
+		if err != nil {
+			// Don't wrap the error, because it's informative enough as is.
+			return nil, 0, err
+		}
+
+		rc := resp.Rcode
+		if rc != dns.RcodeSuccess && rc != dns.RcodeNameError {
+			return nil, 0, &net.DNSError{
+				Err:         fmt.Sprintf("rcode %s", dns.RcodeToString[rc]),
+				Name:        fqdn,
+				Server:      ups.String(),
+				IsTemporary: rc == dns.RcodeServerFailure,
+			}
+		}
+
+		addrs, minTTL = appendAnswerAddrs(addrs, minTTL, resp.Answer)
+	}
+
+	if len(addrs) == 0 {
+		return nil, 0, &net.DNSError{
+			Err:        "no addresses",
+			Name:       fqdn,
+			Server:     ups.String(),
+			IsNotFound: true,
+		}
+	}
+
+	slices.SortFunc(addrs, netip.Addr.Compare)
+
+	return slices.Compact(addrs), time.Duration(minTTL) * time.Second, nil
+}
+
+// appendAnswerAddrs appends the addresses from the A and AAAA records of ans to
+// orig and returns the lowest of their TTLs and prevTTL.
+func appendAnswerAddrs(
+	orig []netip.Addr,
+	prevTTL uint32,
+	ans []dns.RR,
+) (addrs []netip.Addr, ttl uint32) {
+	addrs, ttl = orig, prevTTL
+	for _, rr := range ans {
+		var ip net.IP
+		switch rr := rr.(type) {
+		case *dns.A:
+			ip = rr.A
+		case *dns.AAAA:
+			ip = rr.AAAA
+		default:
+			continue
+		}
+
+		addr, ok := netip.AddrFromSlice(ip)
+		if !ok {
+			continue
+		}
+
+		addrs = append(addrs, addr.Unmap())
+		ttl = min(ttl, rr.Header().Ttl)
+	}
+
+	return addrs, ttl
+}
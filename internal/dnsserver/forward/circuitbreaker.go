@@ -0,0 +1,215 @@
+package forward
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// CircuitBreakerConfig is the configuration of the circuit breaker of a group
+// of upstreams.  The circuit opens when the group fails FailureThreshold times
+// within FailureWindow.  While the circuit is open, the group is skipped.  After
+// OpenDuration, the circuit becomes half-open, and a single query is sent to
+// the group as a probe.  If it succeeds, the circuit closes, otherwise it opens
+// again.
+type CircuitBreakerConfig struct {
+	// FailureWindow is the duration within which the failures are counted.  It
+	// must be positive.
+	FailureWindow time.Duration
+
+	// OpenDuration is the duration for which the circuit stays open before a
+	// probe is sent.  It must be positive.
+	OpenDuration time.Duration
+
+	// FailureThreshold is the number of failures within FailureWindow after
+	// which the circuit opens.  It must be positive.
+	FailureThreshold uint
+}
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState uint8
+
+// CircuitState values.
+const (
+	// CircuitClosed means that the queries are sent to the group.
+	CircuitClosed CircuitState = iota
+
+	// CircuitHalfOpen means that a probe query is sent to the group to find
+	// out whether it has recovered.
+	CircuitHalfOpen
+
+	// CircuitOpen means that the group is skipped.
+	CircuitOpen
+)
+
+// type check
+var _ fmt.Stringer = CircuitClosed
+
+// String implements the [fmt.Stringer] interface for CircuitState.
+func (s CircuitState) String() (str string) {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half_open"
+	case CircuitOpen:
+		return "open"
+	default:
+		return fmt.Sprintf("!bad_circuit_state_%d", uint8(s))
+	}
+}
+
+// circuitBreaker is the circuit breaker of a group of upstreams.  A nil
+// *circuitBreaker always allows the queries.
+type circuitBreaker struct {
+	// onChange is called with the mutex locked when the state changes.
+	onChange func(ctx context.Context, from, to CircuitState)
+
+	// mu protects failures, openedAt, probing, and state.
+	mu *sync.Mutex
+
+	// openedAt is the time when the circuit has been opened last.
+	openedAt time.Time
+
+	// failures are the times of the recent failures within the failure window.
+	failures []time.Time
+
+	window    time.Duration
+	openDur   time.Duration
+	threshold uint
+
+	// probing is true if the probe query is in flight in the half-open state.
+	probing bool
+
+	state CircuitState
+}
+
+// newCircuitBreaker returns a new properly initialized *circuitBreaker.  If c
+// is nil, b is nil.  onChange must not be nil.
+func newCircuitBreaker(
+	c *CircuitBreakerConfig,
+	onChange func(ctx context.Context, from, to CircuitState),
+) (b *circuitBreaker) {
+	if c == nil {
+		return nil
+	}
+
+	return &circuitBreaker{
+		onChange:  onChange,
+		mu:        &sync.Mutex{},
+		failures:  make([]time.Time, 0, c.FailureThreshold),
+		window:    c.FailureWindow,
+		openDur:   c.OpenDuration,
+		threshold: max(c.FailureThreshold, 1),
+		state:     CircuitClosed,
+	}
+}
+
+// allow returns true if a query may be sent to the group at now.  If it returns
+// true, the result of the query must be reported using [circuitBreaker.report].
+func (b *circuitBreaker) allow(ctx context.Context, now time.Time) (ok bool) {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitClosed:
+		return true
+	case CircuitOpen:
+		if now.Sub(b.openedAt) < b.openDur {
+			return false
+		}
+
+		b.setState(ctx, CircuitHalfOpen)
+		b.probing = true
+
+		return true
+	default:
+		if b.probing {
+			return false
+		}
+
+		b.probing = true
+
+		return true
+	}
+}
+
+// report records the result of a query sent to the group at now.
+func (b *circuitBreaker) report(ctx context.Context, now time.Time, success bool) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitHalfOpen {
+		b.probing = false
+		if success {
+			b.failures = b.failures[:0]
+			b.setState(ctx, CircuitClosed)
+		} else {
+			b.open(ctx, now)
+		}
+
+		return
+	}
+
+	if success || b.state == CircuitOpen {
+		return
+	}
+
+	// Remove the failures that are outside of the window.
+	i := slices.IndexFunc(b.failures, func(t time.Time) (ok bool) {
+		return now.Sub(t) <= b.window
+	})
+	if i < 0 {
+		b.failures = b.failures[:0]
+	} else {
+		b.failures = slices.Delete(b.failures, 0, i)
+	}
+
+	b.failures = append(b.failures, now)
+
+	if uint(len(b.failures)) >= b.threshold {
+		b.failures = b.failures[:0]
+		b.open(ctx, now)
+	}
+}
+
+// open opens the circuit at now.  b.mu must be locked.
+func (b *circuitBreaker) open(ctx context.Context, now time.Time) {
+	b.openedAt = now
+	b.setState(ctx, CircuitOpen)
+}
+
+// setState sets the state of the circuit and calls b.onChange if it has
+// changed.  b.mu must be locked.
+func (b *circuitBreaker) setState(ctx context.Context, st CircuitState) {
+	if b.state == st {
+		return
+	}
+
+	prev := b.state
+	b.state = st
+	b.onChange(ctx, prev, st)
+}
+
+// currentState returns the current state of the circuit.  If b is nil, state
+// is [CircuitClosed].
+func (b *circuitBreaker) currentState() (state CircuitState) {
+	if b == nil {
+		return CircuitClosed
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
@@ -0,0 +1,90 @@
+package forward
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	const (
+		window  = 10 * time.Second
+		openDur = 30 * time.Second
+	)
+
+	var changes []CircuitState
+	b := newCircuitBreaker(&CircuitBreakerConfig{
+		FailureWindow:    window,
+		OpenDuration:     openDur,
+		FailureThreshold: 2,
+	}, func(_ context.Context, _, to CircuitState) {
+		changes = append(changes, to)
+	})
+	require.NotNil(t, b)
+
+	ctx := context.Background()
+	start := time.Now()
+
+	// Failures outside of the window don't open the circuit.
+	require.True(t, b.allow(ctx, start))
+	b.report(ctx, start, false)
+
+	now := start.Add(window + time.Second)
+	require.True(t, b.allow(ctx, now))
+	b.report(ctx, now, false)
+	assert.Equal(t, CircuitClosed, b.currentState())
+
+	// The second failure within the window opens the circuit.
+	now = now.Add(time.Second)
+	require.True(t, b.allow(ctx, now))
+	b.report(ctx, now, false)
+	assert.Equal(t, CircuitOpen, b.currentState())
+
+	assert.False(t, b.allow(ctx, now.Add(openDur/2)))
+
+	// After the open duration, only a single probe is allowed.
+	now = now.Add(openDur)
+	require.True(t, b.allow(ctx, now))
+	assert.Equal(t, CircuitHalfOpen, b.currentState())
+	assert.False(t, b.allow(ctx, now))
+
+	// A failed probe opens the circuit again.
+	b.report(ctx, now, false)
+	assert.Equal(t, CircuitOpen, b.currentState())
+
+	// A successful probe closes it.
+	now = now.Add(openDur)
+	require.True(t, b.allow(ctx, now))
+	b.report(ctx, now, true)
+	assert.Equal(t, CircuitClosed, b.currentState())
+
+	wantChanges := []CircuitState{
+		CircuitOpen,
+		CircuitHalfOpen,
+		CircuitOpen,
+		CircuitHalfOpen,
+		CircuitClosed,
+	}
+	assert.Equal(t, wantChanges, changes)
+}
+
+func TestCircuitBreaker_nil(t *testing.T) {
+	t.Parallel()
+
+	b := newCircuitBreaker(nil, nil)
+	require.Nil(t, b)
+
+	ctx := context.Background()
+	now := time.Now()
+	for range 10 {
+		assert.True(t, b.allow(ctx, now))
+		b.report(ctx, now, false)
+	}
+
+	assert.Equal(t, CircuitClosed, b.currentState())
+}
@@ -0,0 +1,367 @@
+package forward
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/miekg/dns"
+)
+
+// DiscoveryType is the type of DNS records used to discover the main upstreams.
+type DiscoveryType string
+
+// Valid [DiscoveryType] values.
+const (
+	// DiscoveryTypeSRV means that the upstreams are discovered using the SRV
+	// records of the _dns._udp subdomain of the zone, see RFC 2782.
+	DiscoveryTypeSRV DiscoveryType = "srv"
+
+	// DiscoveryTypeSVCB means that the upstreams are discovered using the
+	// service-mode SVCB records of the _dns subdomain of the zone, see RFC
+	// 9460 and RFC 9461.  The records with the alpn key are ignored, since
+	// only plain DNS upstreams are supported.
+	DiscoveryTypeSVCB DiscoveryType = "svcb"
+)
+
+// Validate returns an error if t is not a valid discovery type.
+func (t DiscoveryType) Validate() (err error) {
+	switch t {
+	case DiscoveryTypeSRV, DiscoveryTypeSVCB:
+		return nil
+	default:
+		return fmt.Errorf("discovery type: %w: %q", errors.ErrBadEnumValue, t)
+	}
+}
+
+// defaultDiscoveryPort is the port of the discovered upstreams used when the
+// SVCB record doesn't have the port key.
+const defaultDiscoveryPort uint16 = 53
+
+// DiscoveryConfig is the configuration structure for [NewDiscovery].
+type DiscoveryConfig struct {
+	// Logger is used for logging the discovery.  If Logger is nil,
+	// [slog.Default] is used.
+	Logger *slog.Logger
+
+	// Handler is the handler the main upstreams of which are replaced with the
+	// discovered ones.  It must not be nil and must have been created with a
+	// non-nil [HandlerConfig.Bootstrap], the resolvers of which are used to
+	// query the records.
+	Handler *Handler
+
+	// Type is the type of records used for discovery.  It must be valid.
+	Type DiscoveryType
+
+	// Zone is the domain name of the zone containing the records.  It must not
+	// be empty.
+	Zone string
+
+	// Network is the network of the discovered upstreams.
+	Network Network
+
+	// UpstreamTimeout is the optional query timeout of the discovered
+	// upstreams, see [UpstreamPlainConfig.Timeout].
+	UpstreamTimeout time.Duration
+}
+
+// Discovery periodically discovers the main upstreams of a [Handler] using the
+// SRV or SVCB records in a DNS zone, so that the upstreams of many nodes can be
+// changed by editing that zone.
+type Discovery struct {
+	logger    *slog.Logger
+	handler   *Handler
+	bootstrap *Bootstrap
+	typ       DiscoveryType
+	qname     string
+	network   Network
+	timeout   time.Duration
+}
+
+// NewDiscovery returns a new properly initialized *Discovery.  c must not be
+// nil and must be valid.
+func NewDiscovery(c *DiscoveryConfig) (d *Discovery, err error) {
+	if c.Handler.bootstrap == nil {
+		return nil, errors.Error("handler has no bootstrap")
+	}
+
+	zone := dns.Fqdn(strings.ToLower(c.Zone))
+	qname := "_dns." + zone
+	if c.Type == DiscoveryTypeSRV {
+		qname = "_dns._udp." + zone
+	}
+
+	return &Discovery{
+		logger:    cmp.Or(c.Logger, slog.Default()),
+		handler:   c.Handler,
+		bootstrap: c.Handler.bootstrap,
+		typ:       c.Type,
+		qname:     qname,
+		network:   c.Network,
+		timeout:   c.UpstreamTimeout,
+	}, nil
+}
+
+// Refresh implements the [agdservice.Refresher] interface for *Discovery.  It
+// queries the records and sets the discovered upstreams as the main upstreams
+// of the handler.  If no upstreams are discovered, the current ones are kept
+// and err is not nil.
+func (d *Discovery) Refresh(ctx context.Context) (err error) {
+	defer func() { err = errors.Annotate(err, "discovering upstreams from %q: %w", d.qname) }()
+
+	qt := dns.TypeSVCB
+	if d.typ == DiscoveryTypeSRV {
+		qt = dns.TypeSRV
+	}
+
+	req := &dns.Msg{}
+	req.SetQuestion(d.qname, qt)
+
+	resp, err := d.bootstrap.exchange(ctx, req)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	} else if rc := resp.Rcode; rc != dns.RcodeSuccess {
+		return fmt.Errorf("rcode %s", dns.RcodeToString[rc])
+	}
+
+	var confs []*UpstreamPlainConfig
+	if d.typ == DiscoveryTypeSRV {
+		confs = d.upstreamsFromSRV(resp.Answer)
+	} else {
+		confs = d.upstreamsFromSVCB(resp.Answer)
+	}
+
+	if len(confs) == 0 {
+		return errors.Error("no upstreams discovered")
+	}
+
+	if d.handler.SetUpstreams(ctx, confs) {
+		d.logger.InfoContext(ctx, "upstreams changed", "num", len(confs))
+	}
+
+	return nil
+}
+
+// upstreamsFromSRV returns the configurations of the upstreams from the SRV
+// records with the lowest priority in ans.
+func (d *Discovery) upstreamsFromSRV(ans []dns.RR) (confs []*UpstreamPlainConfig) {
+	var srvs []*dns.SRV
+	for _, rr := range ans {
+		if srv, ok := rr.(*dns.SRV); ok && srv.Target != "." {
+			srvs = append(srvs, srv)
+		}
+	}
+
+	if len(srvs) == 0 {
+		return nil
+	}
+
+	minPrio := slices.MinFunc(srvs, func(a, b *dns.SRV) (res int) {
+		return cmp.Compare(a.Priority, b.Priority)
+	}).Priority
+
+	for _, srv := range srvs {
+		if srv.Priority != minPrio {
+			continue
+		}
+
+		confs = append(confs, &UpstreamPlainConfig{
+			Network:  d.network,
+			Address:  netip.AddrPortFrom(netip.Addr{}, srv.Port),
+			Hostname: strings.ToLower(srv.Target),
+			Timeout:  d.timeout,
+		})
+	}
+
+	return sortUpstreamConfigs(confs)
+}
+
+// upstreamsFromSVCB returns the configurations of the upstreams from the
+// service-mode SVCB records with the lowest priority in ans.
+func (d *Discovery) upstreamsFromSVCB(ans []dns.RR) (confs []*UpstreamPlainConfig) {
+	var svcbs []*dns.SVCB
+	for _, rr := range ans {
+		svcb, ok := rr.(*dns.SVCB)
+		if ok && svcb.Priority > 0 && !hasSVCBKey[*dns.SVCBAlpn](svcb) {
+			svcbs = append(svcbs, svcb)
+		}
+	}
+
+	if len(svcbs) == 0 {
+		return nil
+	}
+
+	minPrio := slices.MinFunc(svcbs, func(a, b *dns.SVCB) (res int) {
+		return cmp.Compare(a.Priority, b.Priority)
+	}).Priority
+
+	for _, svcb := range svcbs {
+		if svcb.Priority == minPrio {
+			confs = append(confs, d.upstreamFromSVCB(svcb))
+		}
+	}
+
+	return sortUpstreamConfigs(confs)
+}
+
+// upstreamFromSVCB returns the configuration of the upstream from svcb.  If
+// svcb has address hints, they are used as the addresses of the upstream.
+// Otherwise, the target of svcb or, if it's ".", its owner name is used as the
+// hostname.
+func (d *Discovery) upstreamFromSVCB(svcb *dns.SVCB) (c *UpstreamPlainConfig) {
+	port := defaultDiscoveryPort
+	var addrs []netip.AddrPort
+	for _, kv := range svcb.Value {
+		var hint []net.IP
+		switch kv := kv.(type) {
+		case *dns.SVCBPort:
+			port = kv.Port
+		case *dns.SVCBIPv4Hint:
+			hint = kv.Hint
+		case *dns.SVCBIPv6Hint:
+			hint = kv.Hint
+		}
+
+		for _, ip := range hint {
+			if addr, ok := netip.AddrFromSlice(ip); ok {
+				addrs = append(addrs, netip.AddrPortFrom(addr.Unmap(), 0))
+			}
+		}
+	}
+
+	c = &UpstreamPlainConfig{
+		Network: d.network,
+		Timeout: d.timeout,
+	}
+
+	if len(addrs) == 0 {
+		c.Address = netip.AddrPortFrom(netip.Addr{}, port)
+		c.Hostname = strings.ToLower(svcb.Target)
+		if c.Hostname == "." {
+			c.Hostname = strings.ToLower(svcb.Hdr.Name)
+		}
+
+		return c
+	}
+
+	for i, a := range addrs {
+		addrs[i] = netip.AddrPortFrom(a.Addr(), port)
+	}
+
+	c.Address = addrs[0]
+	if len(addrs) > 1 {
+		c.AdditionalAddresses = addrs[1:]
+	}
+
+	return c
+}
+
+// hasSVCBKey returns true if svcb has a key-value pair of type T.
+func hasSVCBKey[T dns.SVCBKeyValue](svcb *dns.SVCB) (ok bool) {
+	return slices.ContainsFunc(svcb.Value, func(kv dns.SVCBKeyValue) (isT bool) {
+		_, isT = kv.(T)
+
+		return isT
+	})
+}
+
+// sortUpstreamConfigs sorts confs by their hostnames and addresses and returns
+// them, so that the same set of records always produces the same upstreams.
+func sortUpstreamConfigs(confs []*UpstreamPlainConfig) (sorted []*UpstreamPlainConfig) {
+	slices.SortFunc(confs, func(a, b *UpstreamPlainConfig) (res int) {
+		return cmp.Or(
+			strings.Compare(a.Hostname, b.Hostname),
+			a.Address.Compare(b.Address),
+		)
+	})
+
+	return confs
+}
+
+// SetUpstreams replaces the main upstreams of the handler with the ones
+// described by confs, which must not be empty and must not contain nil items.
+// The upstreams with the same configuration as the current ones keep their
+// healthcheck statuses, the new ones are considered up, and the removed ones
+// are closed.  changed is true if the set of the upstreams has changed.
+func (h *Handler) SetUpstreams(ctx context.Context, confs []*UpstreamPlainConfig) (changed bool) {
+	h.upstreamsMu.Lock()
+	defer h.upstreamsMu.Unlock()
+
+	statuses := make([]*upstreamStatus, 0, len(confs))
+	kept := make(map[*upstreamStatus]struct{}, len(h.upstreams))
+	for _, c := range confs {
+		i := slices.IndexFunc(h.upstreams, func(s *upstreamStatus) (ok bool) {
+			return upstreamConfigEqual(s.conf, c)
+		})
+		if i < 0 {
+			statuses = append(statuses, h.newUpstreamStatus(c))
+
+			continue
+		}
+
+		kept[h.upstreams[i]] = struct{}{}
+		statuses = append(statuses, h.upstreams[i])
+	}
+
+	changed = len(kept) != len(h.upstreams) || len(statuses) != len(h.upstreams)
+	if !changed {
+		return false
+	}
+
+	var active []*upstreamStatus
+	for _, s := range statuses {
+		if s.isUp {
+			active = append(active, s)
+		}
+	}
+
+	activeUps := h.preferredUpstreams(active)
+
+	func() {
+		h.activeUpstreamsMu.Lock()
+		defer h.activeUpstreamsMu.Unlock()
+
+		h.activeUpstreams = activeUps
+	}()
+
+	prev := h.upstreams
+	h.upstreams = statuses
+
+	for _, s := range prev {
+		if _, ok := kept[s]; ok {
+			continue
+		}
+
+		// TODO:  Consider waiting for the queries in flight before
+		// closing the removed upstreams.
+		if err := s.upstream.Close(); err != nil {
+			h.logger.DebugContext(
+				ctx,
+				"closing removed upstream",
+				"addr", s.upstream.String(),
+				slogutil.KeyError, err,
+			)
+		}
+	}
+
+	return true
+}
+
+// upstreamConfigEqual returns true if a and b describe the same upstream.
+func upstreamConfigEqual(a, b *UpstreamPlainConfig) (ok bool) {
+	return a.Network == b.Network &&
+		a.Address == b.Address &&
+		a.Hostname == b.Hostname &&
+		a.Timeout == b.Timeout &&
+		a.HappyEyeballs == b.HappyEyeballs &&
+		slices.Equal(a.AdditionalAddresses, b.AdditionalAddresses)
+}
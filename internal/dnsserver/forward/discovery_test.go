@@ -0,0 +1,166 @@
+package forward_test
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/forward"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDiscoveryZone is the zone used in the upstream discovery tests.
+const testDiscoveryZone = "corp.example"
+
+// newDiscoveryHandler returns a handler that responds to the SRV and SVCB
+// discovery queries for [testDiscoveryZone] with the records pointing to the
+// upstream on port and to the A queries for [testUpsHostname] with 127.0.0.1.
+func newDiscoveryHandler(port uint16) (h dnsserver.Handler) {
+	return dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		q := req.Question[0]
+		hdr := dns.RR_Header{
+			Name:   q.Name,
+			Rrtype: q.Qtype,
+			Class:  dns.ClassINET,
+			Ttl:    60,
+		}
+
+		var ans dnsservertest.SectionAnswer
+		switch {
+		case q.Qtype == dns.TypeSRV && q.Name == "_dns._udp."+testDiscoveryZone+".":
+			ans = dnsservertest.SectionAnswer{&dns.SRV{
+				Hdr:      hdr,
+				Priority: 10,
+				Port:     port,
+				Target:   testUpsHostname + ".",
+			}, &dns.SRV{
+				Hdr:      hdr,
+				Priority: 20,
+				Port:     1,
+				Target:   "backup." + testUpsHostname + ".",
+			}}
+		case q.Qtype == dns.TypeSVCB && q.Name == "_dns."+testDiscoveryZone+".":
+			ans = dnsservertest.SectionAnswer{&dns.SVCB{
+				Hdr:      hdr,
+				Priority: 1,
+				Target:   ".",
+				Value: []dns.SVCBKeyValue{
+					&dns.SVCBPort{Port: port},
+					&dns.SVCBIPv4Hint{Hint: []net.IP{net.IPv4(127, 0, 0, 1)}},
+				},
+			}, &dns.SVCB{
+				Hdr:      hdr,
+				Priority: 1,
+				Target:   "doh." + testUpsHostname + ".",
+				Value: []dns.SVCBKeyValue{
+					&dns.SVCBAlpn{Alpn: []string{"h2"}},
+				},
+			}}
+		case q.Qtype == dns.TypeA && q.Name == testUpsHostname+".":
+			ans = dnsservertest.SectionAnswer{
+				dnsservertest.NewA(q.Name, 60, netip.MustParseAddr("127.0.0.1")),
+			}
+		}
+
+		return rw.WriteMsg(ctx, req, dnsservertest.NewResp(dns.RcodeSuccess, req, ans))
+	})
+}
+
+func TestDiscovery_Refresh(t *testing.T) {
+	srv, upsAddrStr := dnsservertest.RunDNSServer(t, dnsservertest.NewDefaultHandler())
+	upsAddr := netip.MustParseAddrPort(upsAddrStr)
+
+	_, bsAddr := dnsservertest.RunDNSServer(t, newDiscoveryHandler(upsAddr.Port()))
+
+	testCases := []struct {
+		name    string
+		typ     forward.DiscoveryType
+		zone    string
+		wantErr bool
+	}{{
+		name:    "srv",
+		typ:     forward.DiscoveryTypeSRV,
+		zone:    testDiscoveryZone,
+		wantErr: false,
+	}, {
+		name:    "svcb",
+		typ:     forward.DiscoveryTypeSVCB,
+		zone:    testDiscoveryZone,
+		wantErr: false,
+	}, {
+		name:    "no_records",
+		typ:     forward.DiscoveryTypeSRV,
+		zone:    "other.example",
+		wantErr: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := forward.NewHandler(&forward.HandlerConfig{
+				Bootstrap: &forward.BootstrapConfig{
+					Resolvers: []netip.AddrPort{netip.MustParseAddrPort(bsAddr)},
+					Timeout:   testTimeout,
+				},
+				UpstreamsAddresses: []*forward.UpstreamPlainConfig{{
+					Network: forward.NetworkAny,
+					Address: netip.MustParseAddrPort("127.0.0.1:0"),
+					Timeout: testTimeout,
+				}},
+			})
+			testutil.CleanupAndRequireSuccess(t, handler.Close)
+
+			d, err := forward.NewDiscovery(&forward.DiscoveryConfig{
+				Handler:         handler,
+				Type:            tc.typ,
+				Zone:            tc.zone,
+				Network:         forward.NetworkAny,
+				UpstreamTimeout: testTimeout,
+			})
+			require.NoError(t, err)
+
+			err = d.Refresh(testutil.ContextWithTimeout(t, testTimeout))
+			req := dnsservertest.CreateMessage("example.org.", dns.TypeA)
+			rw := dnsserver.NewNonWriterResponseWriter(srv.LocalUDPAddr(), srv.LocalUDPAddr())
+			serveErr := handler.ServeDNS(testutil.ContextWithTimeout(t, testTimeout), rw, req)
+			if tc.wantErr {
+				assert.Error(t, err)
+				assert.Error(t, serveErr)
+
+				return
+			}
+
+			require.NoError(t, err)
+			require.NoError(t, serveErr)
+
+			dnsservertest.RequireResponse(t, req, rw.Msg(), 1, dns.RcodeSuccess, false)
+		})
+	}
+}
+
+func TestNewDiscovery_noBootstrap(t *testing.T) {
+	handler := forward.NewHandler(&forward.HandlerConfig{
+		UpstreamsAddresses: []*forward.UpstreamPlainConfig{{
+			Network: forward.NetworkAny,
+			Address: netip.MustParseAddrPort("127.0.0.1:0"),
+			Timeout: testTimeout,
+		}},
+	})
+	testutil.CleanupAndRequireSuccess(t, handler.Close)
+
+	_, err := forward.NewDiscovery(&forward.DiscoveryConfig{
+		Handler: handler,
+		Type:    forward.DiscoveryTypeSRV,
+		Zone:    testDiscoveryZone,
+	})
+	assert.Error(t, err)
+}
@@ -30,7 +30,7 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
-	"net"
+	"net/netip"
 	"sync"
 	"time"
 
@@ -50,6 +50,10 @@ type Handler struct {
 	// metrics is a listener for the handler events.
 	metrics MetricsListener
 
+	// bootstrap resolves the hostnames of upstreams.  It is nil if
+	// [HandlerConfig.Bootstrap] is nil.
+	bootstrap *Bootstrap
+
 	// rand is a random-number generator that is not cryptographically secure
 	// and is used for randomized upstream selection and other non-sensitive
 	// tasks.
@@ -58,9 +62,18 @@ type Handler struct {
 	// activeUpstreamsMu protects activeUpstreams.
 	activeUpstreamsMu *sync.RWMutex
 
-	// hcDomainTmpl is the template for domains used to perform healthcheck
-	// requests.
-	hcDomainTmpl string
+	// upstreamsMu protects upstreams and their statuses.  It is held during
+	// the whole healthcheck, so that the upstreams aren't replaced by
+	// [Handler.SetUpstreams] in the middle of it.
+	upstreamsMu *sync.Mutex
+
+	// conf is the configuration of the handler used to create the main
+	// upstreams set by [Handler.SetUpstreams].
+	conf *HandlerConfig
+
+	// hcProbes are the queries used to perform healthchecks.  It is never
+	// empty.
+	hcProbes []*HealthcheckProbe
 
 	// upstreams is a list of all upstreams where this handler can forward DNS
 	// queries with its last failed healthcheck timestamps.
@@ -70,22 +83,71 @@ type Handler struct {
 	// DNS queries.  This slice is updated by healthcheck mechanics.
 	activeUpstreams []Upstream
 
+	// mainBreaker is the circuit breaker of the main upstreams.  It is nil if
+	// [HandlerConfig.CircuitBreaker] is nil.
+	mainBreaker *circuitBreaker
+
+	// groups are the named groups of upstreams tried after the main upstreams
+	// and before the fallbacks.  Items are never nil.
+	groups []*upstreamGroup
+
 	// fallbacks is a list of fallback DNS servers.
 	fallbacks []Upstream
 
+	// loopID is the random identifier added to the forwarded queries to detect
+	// forwarding loops.  It is nil if the loop detection is disabled.
+	loopID []byte
+
 	// hcBackoffTime specifies the delay before returning to the main upstream
 	// after failed healthcheck probe.
 	hcBackoff time.Duration
+
+	// hcRecheckInterval is the delay before rechecking an upstream that has
+	// failed a healthcheck but isn't considered down yet.  If it is zero, such
+	// upstreams are only rechecked on the next refresh.
+	hcRecheckInterval time.Duration
+
+	// hcFallThreshold is the number of consecutive failed healthchecks after
+	// which an upstream is considered down.  It is always positive.
+	hcFallThreshold uint
+
+	// hcRiseThreshold is the number of consecutive successful healthchecks
+	// after which an upstream that is down is considered up again.  It is
+	// always positive.
+	hcRiseThreshold uint
+
+	// preferIPv6, if true, makes the handler only use the active main
+	// upstreams with IPv6 addresses as long as there are any.
+	preferIPv6 bool
 }
 
-// upstreamStatus contains upstream with its last failed healthcheck time.
+// upstreamStatus contains upstream with the state of its healthchecks.  The
+// selection of the active upstreams is based on it.
 type upstreamStatus struct {
 	// upstream is an upstream where the handler can forward DNS queries.
 	upstream Upstream
 
+	// conf is the original configuration of the upstream.  It is used to
+	// find the upstreams that haven't changed in [Handler.SetUpstreams].
+	conf *UpstreamPlainConfig
+
 	// lastFailedHealthcheck contains the time of the last failed healthcheck
-	// or zero if the last healthcheck succeeded.
+	// or zero if the upstream is up.
 	lastFailedHealthcheck time.Time
+
+	// failures is the number of consecutive failed healthchecks.
+	failures uint
+
+	// successes is the number of consecutive successful healthchecks.
+	successes uint
+
+	// isIPv6 is true if the upstream has an IPv6 address, including the
+	// addresses mapped using a NAT64 prefix.
+	isIPv6 bool
+
+	// isUp is true if the upstream is considered up and can be used to
+	// forward queries.
+	isUp bool
 }
 
 // ErrNoResponse is returned from Handler's methods when the desired response
@@ -95,6 +157,12 @@ const ErrNoResponse = errors.Error("no response")
 
 // HandlerConfig is the configuration structure for [NewHandler].
 type HandlerConfig struct {
+	// Bootstrap is the configuration of the resolvers used to resolve the
+	// hostnames of upstreams.  It must not be nil if any of the upstream
+	// configurations has a hostname.  If Bootstrap.Logger is nil, Logger is
+	// used.
+	Bootstrap *BootstrapConfig
+
 	// Logger is used for logging the operation of the forwarding handler.  If
 	// Logger is nil, [slog.Default] is used.
 	Logger *slog.Logger
@@ -108,19 +176,47 @@ type HandlerConfig struct {
 	// healthcheck queries.  If the HealthcheckDomainTmpl contains the string
 	// "${RANDOM}", all occurrences of this string are replaced with a random
 	// string on every healthcheck query.  Queries to the resulting domains must
-	// return a NOERROR response.
+	// return a NOERROR response.  It is only used when HealthcheckProbes is
+	// empty, in which case the probe is an A query over any network.
 	HealthcheckDomainTmpl string
 
+	// HealthcheckProbes are the optional queries used to perform healthchecks.
+	// An upstream passes a healthcheck if all of the probes succeed.  Items
+	// must not be nil.
+	HealthcheckProbes []*HealthcheckProbe
+
 	// UpstreamsAddresses is a list of upstream configurations of the main
 	// upstreams where the handler forwards all DNS queries.  Items must no be
 	// nil.
 	UpstreamsAddresses []*UpstreamPlainConfig
 
+	// NAT64Prefix is the optional NAT64 prefix.  If set, the IPv4 addresses of
+	// all upstreams are mapped into it as described in RFC 6052, which allows
+	// using IPv4-only upstreams on IPv6-only nodes.  If set, it must be valid
+	// according to [ValidateNAT64Prefix].
+	NAT64Prefix netip.Prefix
+
+	// HappyEyeballs is the optional configuration of the Happy Eyeballs
+	// algorithm for the upstreams that have additional addresses.  It is only
+	// used for the upstream configurations that don't have their own.
+	HappyEyeballs *HappyEyeballsConfig
+
 	// FallbackAddresses are the optional fallback upstream configurations.  A
 	// fallback server is used either the main upstream returns an error or when
 	// the main upstream returns a SERVFAIL response.
 	FallbackAddresses []*UpstreamPlainConfig
 
+	// CircuitBreaker is the optional configuration of the circuit breaker of
+	// the main upstreams.  If it is nil, the main upstreams are only skipped
+	// when they fail the healthchecks.
+	CircuitBreaker *CircuitBreakerConfig
+
+	// UpstreamGroups are the optional named groups of upstreams.  When the main
+	// upstreams fail with a network error or their circuit is open, the groups
+	// are tried in order before the fallbacks.  Items must not be nil, and
+	// their names must be unique.
+	UpstreamGroups []*UpstreamGroupConfig
+
 	// HealthcheckBackoffDuration is the healthcheck query backoff duration.  If
 	// the main upstream is down, queries will not be routed back to the main
 	// upstream until this time has passed.  If the healthcheck is still
@@ -130,6 +226,35 @@ type HandlerConfig struct {
 	// HealthcheckInitDuration is the time duration for initial upstream
 	// healthcheck.
 	HealthcheckInitDuration time.Duration
+
+	// HealthcheckRecheckInterval is the optional delay before rechecking an
+	// upstream that has failed a healthcheck but isn't considered down yet,
+	// see HealthcheckFallThreshold.  If it is zero, such upstreams are only
+	// rechecked on the next refresh.
+	HealthcheckRecheckInterval time.Duration
+
+	// HealthcheckFallThreshold is the number of consecutive failed
+	// healthchecks after which a main upstream is considered down.  If it is
+	// zero, one is used.
+	HealthcheckFallThreshold uint
+
+	// HealthcheckRiseThreshold is the number of consecutive successful
+	// healthchecks after which a main upstream that is down is considered up
+	// again.  If it is zero, one is used.
+	HealthcheckRiseThreshold uint
+
+	// PreferIPv6, if true, makes the handler only forward queries to the main
+	// upstreams with IPv6 addresses as long as at least one of them is
+	// active.  The upstreams with IPv4 addresses and the ones with hostnames
+	// are only used when all IPv6 ones are down.
+	PreferIPv6 bool
+
+	// LoopDetection, if true, makes the handler add a local EDNS option with
+	// the code [EDNSCodeLoopDetection] and a random identifier to the
+	// forwarded queries.  Queries that already contain the option with the
+	// same identifier, which means that the upstream has sent them back to the
+	// handler, are refused and reported to the metrics listener.
+	LoopDetection bool
 }
 
 // NewHandler initializes a new instance of Handler.  It also performs a health
@@ -140,33 +265,64 @@ func NewHandler(c *HandlerConfig) (h *Handler) {
 		logger:            cmp.Or(c.Logger, slog.Default()),
 		rand:              rand.New(&rand.LockedSource{}),
 		activeUpstreamsMu: &sync.RWMutex{},
-		hcDomainTmpl:      c.HealthcheckDomainTmpl,
+		upstreamsMu:       &sync.Mutex{},
+		conf:              c,
+		hcProbes:          c.HealthcheckProbes,
 		hcBackoff:         c.HealthcheckBackoffDuration,
+		hcRecheckInterval: c.HealthcheckRecheckInterval,
+		hcFallThreshold:   max(c.HealthcheckFallThreshold, 1),
+		hcRiseThreshold:   max(c.HealthcheckRiseThreshold, 1),
+		preferIPv6:        c.PreferIPv6,
+	}
+
+	if len(h.hcProbes) == 0 {
+		h.hcProbes = []*HealthcheckProbe{{
+			DomainTmpl: c.HealthcheckDomainTmpl,
+			Network:    NetworkAny,
+			QType:      dns.TypeA,
+		}}
 	}
 
 	// #nosec G115 -- The Unix epoch time is highly unlikely to be negative.
 	h.rand.Seed(uint64(time.Now().UnixNano()))
 
+	if c.LoopDetection {
+		h.loopID = h.newLoopID()
+	}
+
 	if l := c.MetricsListener; l != nil {
 		h.metrics = l
 	} else {
 		h.metrics = &EmptyMetricsListener{}
 	}
 
+	if c.Bootstrap != nil {
+		bsConf := &BootstrapConfig{}
+		*bsConf = *c.Bootstrap
+		bsConf.Logger = cmp.Or(bsConf.Logger, h.logger)
+		h.bootstrap = NewBootstrap(bsConf)
+	}
+
 	h.upstreams = make([]*upstreamStatus, 0, len(c.UpstreamsAddresses))
-	h.activeUpstreams = make([]Upstream, 0, len(c.UpstreamsAddresses))
 	for _, upsConf := range c.UpstreamsAddresses {
-		u := NewUpstreamPlain(upsConf)
-		h.activeUpstreams = append(h.activeUpstreams, u)
-		h.upstreams = append(h.upstreams, &upstreamStatus{
-			upstream:              u,
-			lastFailedHealthcheck: time.Time{},
-		})
+		h.upstreams = append(h.upstreams, h.newUpstreamStatus(upsConf))
+	}
+
+	h.activeUpstreams = h.preferredUpstreams(h.upstreams)
+	h.mainBreaker = newCircuitBreaker(
+		c.CircuitBreaker,
+		h.newCircuitChangeFunc(UpstreamGroupNameMain),
+	)
+
+	h.groups = make([]*upstreamGroup, 0, len(c.UpstreamGroups))
+	for _, grpConf := range c.UpstreamGroups {
+		h.groups = append(h.groups, h.newUpstreamGroup(grpConf, c))
 	}
 
 	h.fallbacks = make([]Upstream, 0, len(c.FallbackAddresses))
 	for _, upsConf := range c.FallbackAddresses {
-		h.fallbacks = append(h.fallbacks, NewUpstreamPlain(upsConf))
+		upsConf = withHandlerConf(upsConf, c)
+		h.fallbacks = append(h.fallbacks, h.newUpstream(upsConf, c))
 	}
 
 	if c.HealthcheckInitDuration > 0 {
@@ -181,21 +337,138 @@ func NewHandler(c *HandlerConfig) (h *Handler) {
 	return h
 }
 
+// newUpstreamStatus returns a new status of the main upstream for c, which is
+// considered up.
+func (h *Handler) newUpstreamStatus(c *UpstreamPlainConfig) (s *upstreamStatus) {
+	upsConf := withHandlerConf(c, h.conf)
+
+	return &upstreamStatus{
+		upstream:              h.newUpstream(upsConf, h.conf),
+		conf:                  c,
+		lastFailedHealthcheck: time.Time{},
+		isIPv6:                upsConf.Hostname == "" && upsConf.Address.Addr().Is6(),
+		isUp:                  true,
+	}
+}
+
+// newUpstream returns a new upstream for c.  If c has a hostname, the upstream
+// resolves it using the bootstrap of the handler, which must not be nil.
+func (h *Handler) newUpstream(c *UpstreamPlainConfig, hc *HandlerConfig) (ups Upstream) {
+	if c.Hostname == "" {
+		return NewUpstreamPlain(c)
+	}
+
+	return NewUpstreamHostname(&UpstreamHostnameConfig{
+		Bootstrap:   h.bootstrap,
+		Upstream:    c,
+		NAT64Prefix: hc.NAT64Prefix,
+	})
+}
+
+// withHandlerConf returns a copy of c with the addresses mapped into the NAT64
+// prefix and the Happy Eyeballs configuration from hc, if necessary.
+// Otherwise, it returns c.
+func withHandlerConf(c *UpstreamPlainConfig, hc *HandlerConfig) (res *UpstreamPlainConfig) {
+	if c.Hostname != "" {
+		return withHostnameHandlerConf(c, hc)
+	}
+
+	prefix := hc.NAT64Prefix
+	mapped := mapUpstreamAddr(prefix, c.Address)
+
+	needsHE := c.HappyEyeballs == nil && hc.HappyEyeballs != nil && len(c.AdditionalAddresses) > 0
+	needsNAT64 := mapped != c.Address || (prefix.IsValid() && len(c.AdditionalAddresses) > 0)
+	if !needsHE && !needsNAT64 {
+		return c
+	}
+
+	res = &UpstreamPlainConfig{}
+	*res = *c
+	res.Address = mapped
+
+	if needsHE {
+		res.HappyEyeballs = hc.HappyEyeballs
+	}
+
+	if len(c.AdditionalAddresses) > 0 {
+		res.AdditionalAddresses = make([]netip.AddrPort, 0, len(c.AdditionalAddresses))
+		for _, a := range c.AdditionalAddresses {
+			res.AdditionalAddresses = append(res.AdditionalAddresses, mapUpstreamAddr(prefix, a))
+		}
+	}
+
+	return res
+}
+
+// withHostnameHandlerConf is like [withHandlerConf] but for the upstream
+// configurations with hostnames, the addresses of which are mapped into the
+// NAT64 prefix after resolution.
+func withHostnameHandlerConf(
+	c *UpstreamPlainConfig,
+	hc *HandlerConfig,
+) (res *UpstreamPlainConfig) {
+	if c.HappyEyeballs != nil || hc.HappyEyeballs == nil {
+		return c
+	}
+
+	res = &UpstreamPlainConfig{}
+	*res = *c
+	res.HappyEyeballs = hc.HappyEyeballs
+
+	return res
+}
+
+// preferredUpstreams returns the upstreams from statuses that should be used
+// according to the IP preferences of the handler.
+func (h *Handler) preferredUpstreams(statuses []*upstreamStatus) (ups []Upstream) {
+	ups = make([]Upstream, 0, len(statuses))
+	if h.preferIPv6 {
+		for _, s := range statuses {
+			if s.isIPv6 {
+				ups = append(ups, s.upstream)
+			}
+		}
+
+		if len(ups) > 0 {
+			return ups
+		}
+	}
+
+	for _, s := range statuses {
+		ups = append(ups, s.upstream)
+	}
+
+	return ups
+}
+
 // type check
 var _ io.Closer = &Handler{}
 
 // Close implements the [io.Closer] interface for *Handler.
 func (h *Handler) Close() (err error) {
-	errs := make([]error, 0, len(h.upstreams)+len(h.fallbacks))
+	h.upstreamsMu.Lock()
+	defer h.upstreamsMu.Unlock()
+
+	errs := make([]error, 0, len(h.upstreams)+len(h.fallbacks)+1)
 
 	for _, u := range h.upstreams {
 		errs = append(errs, u.upstream.Close())
 	}
 
+	for _, g := range h.groups {
+		for _, u := range g.upstreams {
+			errs = append(errs, u.Close())
+		}
+	}
+
 	for _, f := range h.fallbacks {
 		errs = append(errs, f.Close())
 	}
 
+	if h.bootstrap != nil {
+		errs = append(errs, h.bootstrap.Close())
+	}
+
 	err = errors.Join(errs...)
 	if err != nil {
 		return fmt.Errorf("closing forward handler: %w", err)
@@ -213,20 +486,39 @@ func (h *Handler) ServeDNS(
 	rw dnsserver.ResponseWriter,
 	req *dns.Msg,
 ) (err error) {
+	var restoreReq func()
+	if h.loopID != nil {
+		if h.isLoop(req) {
+			return h.refuseLoop(ctx, rw, req)
+		}
+
+		restoreReq = h.addLoopOption(req)
+	}
+
 	var ups, fallbackUps Upstream
 	defer func() { err = annotate(err, ups, fallbackUps) }()
 
 	ups = h.pickActiveUpstream()
-	useFallbacks := ups == nil
+	useFallbacks := ups == nil || !h.mainBreaker.allow(ctx, time.Now())
 
 	var resp *dns.Msg
 	if !useFallbacks {
 		resp, err = h.exchange(ctx, ups, req)
 
-		var netErr net.Error
 		// Network error means that something is wrong with the upstream, we
 		// definitely should use the fallback.
-		useFallbacks = err != nil && errors.As(err, &netErr)
+		useFallbacks = isNetError(err)
+		h.mainBreaker.report(ctx, time.Now(), !useFallbacks)
+	}
+
+	if useFallbacks && len(h.groups) > 0 {
+		var groupResp *dns.Msg
+		var groupUps Upstream
+		var groupErr error
+		groupResp, groupUps, useFallbacks, groupErr = h.exchangeGroups(ctx, req)
+		if groupUps != nil {
+			resp, ups, err = groupResp, groupUps, groupErr
+		}
 	}
 
 	if useFallbacks && len(h.fallbacks) > 0 {
@@ -235,6 +527,12 @@ func (h *Handler) ServeDNS(
 		resp, err = h.exchange(ctx, fallbackUps, req)
 	}
 
+	if restoreReq != nil {
+		// Restore the request before writing the response, since the response
+		// is normalized according to the request options.
+		restoreReq()
+	}
+
 	if err != nil {
 		return fmt.Errorf("forwarding: %w", err)
 	}
@@ -3,6 +3,7 @@ package forward_test
 import (
 	"context"
 	"net/netip"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/forward"
 	"github.com/AdguardTeam/golibs/testutil"
 	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -72,3 +74,116 @@ func TestHandler_ServeDNS_fallbackNetError(t *testing.T) {
 	require.NotNil(t, res)
 	dnsservertest.RequireResponse(t, req, res, 1, dns.RcodeSuccess, false)
 }
+
+func TestHandler_ServeDNS_upstreamGroups(t *testing.T) {
+	srv, addr := dnsservertest.RunDNSServer(t, dnsservertest.NewDefaultHandler())
+
+	handler := forward.NewHandler(&forward.HandlerConfig{
+		UpstreamsAddresses: []*forward.UpstreamPlainConfig{{
+			Network: forward.NetworkAny,
+			Address: netip.MustParseAddrPort("127.0.0.1:0"),
+			Timeout: testTimeout,
+		}},
+		CircuitBreaker: &forward.CircuitBreakerConfig{
+			FailureWindow:    time.Minute,
+			OpenDuration:     time.Minute,
+			FailureThreshold: 1,
+		},
+		UpstreamGroups: []*forward.UpstreamGroupConfig{{
+			Name: "secondary",
+			Upstreams: []*forward.UpstreamPlainConfig{{
+				Network: forward.NetworkAny,
+				Address: netip.MustParseAddrPort(addr),
+				Timeout: testTimeout,
+			}},
+		}},
+	})
+
+	wantClosed := []*forward.UpstreamGroupStatus{{
+		Name:  forward.UpstreamGroupNameMain,
+		State: forward.CircuitClosed,
+	}, {
+		Name:  "secondary",
+		State: forward.CircuitClosed,
+	}}
+	assert.Equal(t, wantClosed, handler.UpstreamGroups())
+
+	wantOpen := []*forward.UpstreamGroupStatus{{
+		Name:  forward.UpstreamGroupNameMain,
+		State: forward.CircuitOpen,
+	}, {
+		Name:  "secondary",
+		State: forward.CircuitClosed,
+	}}
+
+	// The first query fails over to the group and opens the circuit of the
+	// main upstreams, and the second one is sent to the group right away.
+	for range 2 {
+		req := dnsservertest.CreateMessage("example.org.", dns.TypeA)
+		rw := dnsserver.NewNonWriterResponseWriter(srv.LocalUDPAddr(), srv.LocalUDPAddr())
+
+		err := handler.ServeDNS(testutil.ContextWithTimeout(t, testTimeout), rw, req)
+		require.NoError(t, err)
+
+		res := rw.Msg()
+		require.NotNil(t, res)
+		dnsservertest.RequireResponse(t, req, res, 1, dns.RcodeSuccess, false)
+
+		assert.Equal(t, wantOpen, handler.UpstreamGroups())
+	}
+}
+
+// loopMetricsListener is a [forward.MetricsListener] that counts the detected
+// forwarding loops.
+type loopMetricsListener struct {
+	forward.EmptyMetricsListener
+
+	loops *atomic.Uint32
+}
+
+// OnLoopDetected implements the [forward.MetricsListener] interface for
+// *loopMetricsListener.
+func (l *loopMetricsListener) OnLoopDetected(_ context.Context, _ *dns.Msg) {
+	l.loops.Add(1)
+}
+
+func TestHandler_ServeDNS_loop(t *testing.T) {
+	metrics := &loopMetricsListener{
+		loops: &atomic.Uint32{},
+	}
+
+	// The upstream server sends all queries back to the handler.
+	var handler *forward.Handler
+	srv, addr := dnsservertest.RunDNSServer(t, dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		return handler.ServeDNS(ctx, rw, req)
+	}))
+
+	handler = forward.NewHandler(&forward.HandlerConfig{
+		MetricsListener: metrics,
+		UpstreamsAddresses: []*forward.UpstreamPlainConfig{{
+			Network: forward.NetworkAny,
+			Address: netip.MustParseAddrPort(addr),
+			Timeout: testTimeout,
+		}},
+		LoopDetection: true,
+	})
+
+	req := dnsservertest.CreateMessage("example.org.", dns.TypeA)
+	rw := dnsserver.NewNonWriterResponseWriter(srv.LocalUDPAddr(), srv.LocalUDPAddr())
+
+	err := handler.ServeDNS(testutil.ContextWithTimeout(t, testTimeout), rw, req)
+	require.NoError(t, err)
+
+	res := rw.Msg()
+	require.NotNil(t, res)
+
+	assert.Equal(t, dns.RcodeRefused, res.Rcode)
+	assert.Equal(t, uint32(1), metrics.loops.Load())
+
+	// Make sure that the request is restored.
+	assert.Nil(t, req.IsEdns0())
+}
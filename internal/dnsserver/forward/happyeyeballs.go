@@ -0,0 +1,244 @@
+package forward
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+const (
+	// DefaultConnectionAttemptDelay is the default delay between the
+	// connection attempts, as recommended by RFC 8305, Section 5.
+	DefaultConnectionAttemptDelay = 250 * time.Millisecond
+
+	// DefaultFailureCacheDuration is the default duration for which a failed
+	// address of an upstream is only tried after the other ones.
+	DefaultFailureCacheDuration = 10 * time.Minute
+)
+
+// HappyEyeballsConfig is the configuration of the Happy Eyeballs algorithm, as
+// described in RFC 8305, which is used to connect to upstreams with several
+// addresses.
+type HappyEyeballsConfig struct {
+	// ConnectionAttemptDelay is the delay after which the connection attempt
+	// to the next address is started, if the previous one hasn't finished yet.
+	// If it is zero, [DefaultConnectionAttemptDelay] is used.
+	ConnectionAttemptDelay time.Duration
+
+	// FailureCacheDuration is the duration for which an address, the
+	// connection to which has failed, is only tried after the other ones.  If
+	// it is zero, [DefaultFailureCacheDuration] is used.
+	FailureCacheDuration time.Duration
+
+	// PreferIPv4, if true, makes the IPv4 addresses tried before the IPv6
+	// ones.  By default, the IPv6 addresses are tried first, as recommended by
+	// RFC 8305.
+	PreferIPv4 bool
+}
+
+// happyEyeballsDialer connects to one of the addresses of an upstream using
+// the Happy Eyeballs algorithm.  It also remembers the addresses that have
+// recently failed and tries them last.
+type happyEyeballsDialer struct {
+	// failuresMu protects failures.
+	failuresMu *sync.Mutex
+
+	// failures maps the addresses that have recently failed to the time of
+	// the last failure.
+	failures map[netip.AddrPort]time.Time
+
+	// addrs are the addresses of the upstream in the order of preference, with
+	// the address families interleaved, see RFC 8305, Section 4.
+	addrs []netip.AddrPort
+
+	// attemptDelay is the delay between the connection attempts.  It is
+	// always positive.
+	attemptDelay time.Duration
+
+	// failureTTL is the duration for which a failed address is tried last.  It
+	// is always positive.
+	failureTTL time.Duration
+}
+
+// newHappyEyeballsDialer returns a new properly initialized
+// *happyEyeballsDialer.  c may be nil, in which case the defaults are used.
+// addrs must not be empty.
+func newHappyEyeballsDialer(
+	c *HappyEyeballsConfig,
+	addrs []netip.AddrPort,
+) (d *happyEyeballsDialer) {
+	if c == nil {
+		c = &HappyEyeballsConfig{}
+	}
+
+	return &happyEyeballsDialer{
+		failuresMu:   &sync.Mutex{},
+		failures:     map[netip.AddrPort]time.Time{},
+		addrs:        interleaveAddrs(addrs, c.PreferIPv4),
+		attemptDelay: durationOr(c.ConnectionAttemptDelay, DefaultConnectionAttemptDelay),
+		failureTTL:   durationOr(c.FailureCacheDuration, DefaultFailureCacheDuration),
+	}
+}
+
+// durationOr returns d if it is positive and def otherwise.
+func durationOr(d, def time.Duration) (res time.Duration) {
+	if d > 0 {
+		return d
+	}
+
+	return def
+}
+
+// interleaveAddrs returns the addresses from addrs ordered so that the
+// addresses of the preferred family go first and the families alternate, as
+// described in RFC 8305, Section 4.  The relative order of addresses within a
+// family is kept.
+func interleaveAddrs(addrs []netip.AddrPort, preferIPv4 bool) (res []netip.AddrPort) {
+	var preferred, other []netip.AddrPort
+	for _, a := range addrs {
+		if a.Addr().Unmap().Is4() == preferIPv4 {
+			preferred = append(preferred, a)
+		} else {
+			other = append(other, a)
+		}
+	}
+
+	res = make([]netip.AddrPort, 0, len(addrs))
+	for i := range max(len(preferred), len(other)) {
+		if i < len(preferred) {
+			res = append(res, preferred[i])
+		}
+
+		if i < len(other) {
+			res = append(res, other[i])
+		}
+	}
+
+	return res
+}
+
+// orderedAddrs returns the addresses of the upstream in the order in which
+// they should be tried at now.  The addresses that have failed recently go
+// last.
+func (d *happyEyeballsDialer) orderedAddrs(now time.Time) (addrs []netip.AddrPort) {
+	d.failuresMu.Lock()
+	defer d.failuresMu.Unlock()
+
+	addrs = make([]netip.AddrPort, 0, len(d.addrs))
+	var failed []netip.AddrPort
+	for _, a := range d.addrs {
+		failedAt, ok := d.failures[a]
+		if !ok {
+			addrs = append(addrs, a)
+		} else if now.Sub(failedAt) >= d.failureTTL {
+			delete(d.failures, a)
+			addrs = append(addrs, a)
+		} else {
+			failed = append(failed, a)
+		}
+	}
+
+	return append(addrs, failed...)
+}
+
+// reportFailure remembers that the connection to addr has failed at now.
+// Addresses that don't belong to the upstream are ignored.
+func (d *happyEyeballsDialer) reportFailure(addr netip.AddrPort, now time.Time) {
+	addr = netip.AddrPortFrom(addr.Addr().Unmap(), addr.Port())
+
+	d.failuresMu.Lock()
+	defer d.failuresMu.Unlock()
+
+	for _, a := range d.addrs {
+		if netip.AddrPortFrom(a.Addr().Unmap(), a.Port()) == addr {
+			d.failures[a] = now
+
+			return
+		}
+	}
+}
+
+// dialResult is the result of a single connection attempt.
+type dialResult struct {
+	conn net.Conn
+	err  error
+	addr netip.AddrPort
+}
+
+// dial connects to the first address of the upstream that responds using
+// network.  A connection attempt to the next address is started if the
+// previous one fails or doesn't finish within the attempt delay.  The
+// connections established after the first one are closed.
+func (d *happyEyeballsDialer) dial(ctx context.Context, network string) (conn net.Conn, err error) {
+	addrs := d.orderedAddrs(time.Now())
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan *dialResult, len(addrs))
+	delay := time.NewTimer(d.attemptDelay)
+	defer delay.Stop()
+
+	go dialAddr(ctx, network, addrs[0], results)
+	next, pending := 1, 1
+
+	var errs []error
+	for pending > 0 {
+		select {
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				go closeLateConns(results, pending)
+
+				return res.conn, nil
+			}
+
+			d.reportFailure(res.addr, time.Now())
+			errs = append(errs, res.err)
+		case <-delay.C:
+			// Go on and start the next attempt.
+		}
+
+		if next < len(addrs) {
+			go dialAddr(ctx, network, addrs[next], results)
+			next++
+			pending++
+			delay.Reset(d.attemptDelay)
+		}
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// dialAddr connects to addr using network and sends the result to results.
+func dialAddr(
+	ctx context.Context,
+	network string,
+	addr netip.AddrPort,
+	results chan<- *dialResult,
+) {
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, network, addr.String())
+	results <- &dialResult{
+		conn: conn,
+		err:  err,
+		addr: addr,
+	}
+}
+
+// closeLateConns receives n results from results and closes the connections
+// that have been established after the winning one.
+func closeLateConns(results <-chan *dialResult, n int) {
+	for range n {
+		res := <-results
+		if res.conn != nil {
+			// Don't report the error, since the connection has never been
+			// used.
+			_ = res.conn.Close()
+		}
+	}
+}
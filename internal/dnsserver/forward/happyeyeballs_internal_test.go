@@ -0,0 +1,85 @@
+package forward
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterleaveAddrs(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ip4a = netip.MustParseAddrPort("192.0.2.1:53")
+		ip4b = netip.MustParseAddrPort("192.0.2.2:53")
+		ip6a = netip.MustParseAddrPort("[2001:db8::1]:53")
+		ip6b = netip.MustParseAddrPort("[2001:db8::2]:53")
+		ip6c = netip.MustParseAddrPort("[2001:db8::3]:53")
+	)
+
+	testCases := []struct {
+		name       string
+		addrs      []netip.AddrPort
+		want       []netip.AddrPort
+		preferIPv4 bool
+	}{{
+		name:       "prefer_ipv6",
+		addrs:      []netip.AddrPort{ip4a, ip4b, ip6a, ip6b, ip6c},
+		want:       []netip.AddrPort{ip6a, ip4a, ip6b, ip4b, ip6c},
+		preferIPv4: false,
+	}, {
+		name:       "prefer_ipv4",
+		addrs:      []netip.AddrPort{ip6a, ip6b, ip6c, ip4a, ip4b},
+		want:       []netip.AddrPort{ip4a, ip6a, ip4b, ip6b, ip6c},
+		preferIPv4: true,
+	}, {
+		name:       "single_family",
+		addrs:      []netip.AddrPort{ip4a, ip4b},
+		want:       []netip.AddrPort{ip4a, ip4b},
+		preferIPv4: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, interleaveAddrs(tc.addrs, tc.preferIPv4))
+		})
+	}
+}
+
+func TestHappyEyeballsDialer_orderedAddrs(t *testing.T) {
+	t.Parallel()
+
+	const failureTTL = 1 * time.Minute
+
+	var (
+		ip4 = netip.MustParseAddrPort("192.0.2.1:53")
+		ip6 = netip.MustParseAddrPort("[2001:db8::1]:53")
+	)
+
+	d := newHappyEyeballsDialer(&HappyEyeballsConfig{
+		FailureCacheDuration: failureTTL,
+	}, []netip.AddrPort{ip4, ip6})
+
+	now := time.Now()
+	assert.Equal(t, []netip.AddrPort{ip6, ip4}, d.orderedAddrs(now))
+
+	d.reportFailure(ip6, now)
+	assert.Equal(t, []netip.AddrPort{ip4, ip6}, d.orderedAddrs(now))
+	assert.Equal(t, []netip.AddrPort{ip4, ip6}, d.orderedAddrs(now.Add(failureTTL/2)))
+
+	assert.Equal(t, []netip.AddrPort{ip6, ip4}, d.orderedAddrs(now.Add(failureTTL)))
+	assert.Empty(t, d.failures)
+
+	// Make sure that the unrelated and IPv4-mapped addresses are handled
+	// correctly.
+	d.reportFailure(netip.MustParseAddrPort("192.0.2.2:53"), now)
+	assert.Empty(t, d.failures)
+
+	d.reportFailure(netip.MustParseAddrPort("[::ffff:192.0.2.1]:53"), now)
+	assert.Equal(t, []netip.AddrPort{ip6, ip4}, d.orderedAddrs(now))
+	assert.Contains(t, d.failures, ip4)
+}
@@ -41,32 +41,45 @@ func (h *Handler) refresh(ctx context.Context, mustReport bool) (err error) {
 // healthcheck domain names.
 const randomPlaceholder = "${RANDOM}"
 
+// HealthcheckProbe is the configuration of a single healthcheck query.
+type HealthcheckProbe struct {
+	// DomainTmpl is the template of the domain name to query.  All occurrences
+	// of "${RANDOM}" are replaced with a random string on every query.  Queries
+	// to the resulting domains must return a NOERROR response.
+	DomainTmpl string
+
+	// Network is the network over which the probe is sent.  If it is
+	// [NetworkAny], the probe is sent the same way as the forwarded queries.
+	// Probes over a network that the upstream doesn't use are skipped.
+	Network Network
+
+	// QType is the type of the query.
+	QType uint16
+}
+
 // healthcheck returns an error if all of handler's main upstreams are down.
 // Updates handler's activeUpstreams slice.
 func (h *Handler) healthcheck(ctx context.Context, mustReport bool) (err error) {
-	domain := h.hcDomainTmpl
-	if strings.Contains(domain, randomPlaceholder) {
-		randStr := strconv.FormatUint(h.rand.Uint64(), 16)
-		domain = strings.ReplaceAll(domain, randomPlaceholder, randStr)
-	}
-
-	defer func() { err = errors.Annotate(err, "healthcheck: querying %q: %w", domain) }()
+	defer func() { err = errors.Annotate(err, "healthcheck: %w") }()
 
-	req := newProbeReq(domain)
+	h.upstreamsMu.Lock()
+	defer h.upstreamsMu.Unlock()
 
-	var activeUps []Upstream
+	var active []*upstreamStatus
 	var errs []error
 	for _, status := range h.upstreams {
-		inBackoff, ckErr := h.healthcheckUpstream(ctx, status, req, mustReport)
-		if inBackoff {
-			continue
-		} else if ckErr != nil {
+		ckErr := h.healthcheckUpstream(ctx, status, mustReport)
+		if ckErr != nil {
 			errs = append(errs, ckErr)
-		} else {
-			activeUps = append(activeUps, status.upstream)
+		}
+
+		if status.isUp {
+			active = append(active, status)
 		}
 	}
 
+	activeUps := h.preferredUpstreams(active)
+
 	h.activeUpstreamsMu.Lock()
 	defer h.activeUpstreamsMu.Unlock()
 
@@ -81,8 +94,14 @@ func (h *Handler) healthcheck(ctx context.Context, mustReport bool) (err error)
 	return nil
 }
 
-// newProbeReq returns a new request message for given domain.
-func newProbeReq(domain string) (req *dns.Msg) {
+// newProbeReq returns a new request message for p.
+func (h *Handler) newProbeReq(p *HealthcheckProbe) (req *dns.Msg) {
+	domain := p.DomainTmpl
+	if strings.Contains(domain, randomPlaceholder) {
+		randStr := strconv.FormatUint(h.rand.Uint64(), 16)
+		domain = strings.ReplaceAll(domain, randomPlaceholder, randStr)
+	}
+
 	return &dns.Msg{
 		MsgHdr: dns.MsgHdr{
 			Id:               dns.Id(),
@@ -90,43 +109,103 @@ func newProbeReq(domain string) (req *dns.Msg) {
 		},
 		Question: []dns.Question{{
 			Name:   dns.Fqdn(domain),
-			Qtype:  dns.TypeA,
+			Qtype:  p.QType,
 			Qclass: dns.ClassINET,
 		}},
 	}
 }
 
-// healthcheckUpstream returns an error if the upstream is down, sets last
-// failed healthcheck timestamp and updates metrics for given upstream.
+// healthcheckUpstream checks the upstream, updates its status and the metrics,
+// and returns an error if the upstream is down.  If the upstream fails the
+// check but isn't considered down yet, it is rechecked after the recheck
+// interval, if there is one.
 func (h *Handler) healthcheckUpstream(
 	ctx context.Context,
 	upsStatus *upstreamStatus,
-	req *dns.Msg,
 	mustReport bool,
-) (inBackoff bool, err error) {
-	lastFailed := upsStatus.lastFailedHealthcheck
+) (err error) {
 	ups := upsStatus.upstream
+	wasUp := upsStatus.isUp
 
 	// TODO(a.garipov):  Augment our JSON log handler to use fmt.Stringer
 	// automatically?
 	upsLogger := h.logger.With("addr", ups.String())
-	if time.Since(lastFailed) < h.hcBackoff {
+	if !wasUp && time.Since(upsStatus.lastFailedHealthcheck) < h.hcBackoff {
 		// Make sure that this main upstream is not in the backoff mode.
 		upsLogger.DebugContext(ctx, "healthcheck: upstream in backoff")
 
-		return true, nil
+		return nil
 	}
 
-	err = checkUpstream(ctx, ups, req)
-	if err != nil {
-		upsStatus.lastFailedHealthcheck = time.Now()
-	} else {
-		upsStatus.lastFailedHealthcheck = time.Time{}
+	err = h.checkUpstream(ctx, ups)
+	upsStatus.update(err, h.hcFallThreshold, h.hcRiseThreshold)
+	for err != nil && upsStatus.isUp && h.hcRecheckInterval > 0 {
+		upsLogger.DebugContext(
+			ctx,
+			"healthcheck: rechecking upstream",
+			"failures", upsStatus.failures,
+			slogutil.KeyError, err,
+		)
+
+		if sleepErr := sleep(ctx, h.hcRecheckInterval); sleepErr != nil {
+			break
+		}
+
+		err = h.checkUpstream(ctx, ups)
+		upsStatus.update(err, h.hcFallThreshold, h.hcRiseThreshold)
+	}
+
+	h.reportChange(ctx, upsLogger, ups, err, wasUp, upsStatus.isUp, mustReport)
+
+	if upsStatus.isUp {
+		return nil
+	} else if err == nil {
+		return fmt.Errorf(
+			"%s: upstream is down: %d of %d successful checks",
+			ups,
+			upsStatus.successes,
+			h.hcRiseThreshold,
+		)
 	}
 
-	h.reportChange(ctx, upsLogger, ups, err, lastFailed.IsZero(), mustReport)
+	return fmt.Errorf("%s: upstream is down: %w", ups, err)
+}
 
-	return false, errors.Annotate(err, "%s: upstream is down: %w", ups)
+// update updates the counters and the state of s using the result of a
+// healthcheck.  fall and rise are the thresholds of consecutive failed and
+// successful checks respectively.
+func (s *upstreamStatus) update(ckErr error, fall, rise uint) {
+	if ckErr != nil {
+		s.successes = 0
+		s.failures++
+		if !s.isUp || s.failures >= fall {
+			// Each failed check advances the backoff.
+			s.isUp = false
+			s.lastFailedHealthcheck = time.Now()
+		}
+
+		return
+	}
+
+	s.failures = 0
+	s.successes++
+	if s.isUp || s.successes >= rise {
+		s.isUp = true
+		s.lastFailedHealthcheck = time.Time{}
+	}
+}
+
+// sleep waits for d or until ctx is done, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) (err error) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
 
 // reportChange updates the metrics if the status of upstream has changed or an
@@ -137,9 +216,9 @@ func (h *Handler) reportChange(
 	ups Upstream,
 	err error,
 	wasUp bool,
+	isUp bool,
 	mustReport bool,
 ) {
-	isUp := err == nil
 	if wasUp != isUp || mustReport {
 		h.metrics.OnUpstreamStatusChanged(ups, true, isUp)
 	}
@@ -155,9 +234,37 @@ func (h *Handler) reportChange(
 	}
 }
 
-// checkUpstream returns an error if the given upstream is not up.
-func checkUpstream(ctx context.Context, ups Upstream, req *dns.Msg) (err error) {
-	resp, _, err := ups.Exchange(ctx, req)
+// checkUpstream returns an error if any of the probes to the given upstream
+// fails.
+func (h *Handler) checkUpstream(ctx context.Context, ups Upstream) (err error) {
+	for _, p := range h.hcProbes {
+		req := h.newProbeReq(p)
+		err = checkProbe(ctx, ups, req, p.Network)
+		if err != nil {
+			q := req.Question[0]
+
+			return fmt.Errorf("querying %s %q: %w", dns.Type(q.Qtype), q.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// checkProbe returns an error if the response of the upstream to req sent over
+// nw is not successful.
+func checkProbe(ctx context.Context, ups Upstream, req *dns.Msg, nw Network) (err error) {
+	var resp *dns.Msg
+	if ne, ok := ups.(overNetworkExchanger); ok && nw != NetworkAny {
+		if !ne.usesNetwork(nw) {
+			// The upstream doesn't use this network, so skip the probe.
+			return nil
+		}
+
+		resp, err = ne.exchangeOver(ctx, req, nw)
+	} else {
+		resp, _, err = ups.Exchange(ctx, req)
+	}
+
 	if err != nil {
 		return err
 	} else if resp == nil {
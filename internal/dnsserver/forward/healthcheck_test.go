@@ -2,9 +2,11 @@ package forward_test
 
 import (
 	"context"
+	"net"
 	"net/netip"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
@@ -93,3 +95,177 @@ func TestHandler_Refresh(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, int64(6), upstreamRequestsCount.Load())
 }
+
+// newFailingHandler is a helper that returns a DNS handler that responds with
+// SERVFAIL when isUp returns false.
+func newFailingHandler(isUp func(rw dnsserver.ResponseWriter) (ok bool)) (h dnsserver.Handler) {
+	defaultHandler := dnsservertest.NewDefaultHandler()
+
+	return dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		if !isUp(rw) {
+			resp := (&dns.Msg{}).SetRcode(req, dns.RcodeServerFailure)
+
+			return rw.WriteMsg(ctx, req, resp)
+		}
+
+		return defaultHandler.ServeDNS(ctx, rw, req)
+	})
+}
+
+func TestHandler_Refresh_thresholds(t *testing.T) {
+	var upstreamIsUp atomic.Bool
+	upstream, _ := dnsservertest.RunDNSServer(t, newFailingHandler(
+		func(_ dnsserver.ResponseWriter) (ok bool) { return upstreamIsUp.Load() },
+	))
+	fallback, _ := dnsservertest.RunDNSServer(t, dnsservertest.NewDefaultHandler())
+
+	handler := forward.NewHandler(&forward.HandlerConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		UpstreamsAddresses: []*forward.UpstreamPlainConfig{{
+			Network: forward.NetworkAny,
+			Address: netip.MustParseAddrPort(upstream.LocalUDPAddr().String()),
+			Timeout: testTimeout,
+		}},
+		HealthcheckDomainTmpl: "${RANDOM}.upstream-check.example",
+		FallbackAddresses: []*forward.UpstreamPlainConfig{{
+			Network: forward.NetworkAny,
+			Address: netip.MustParseAddrPort(fallback.LocalUDPAddr().String()),
+			Timeout: testTimeout,
+		}},
+		HealthcheckBackoffDuration: 0,
+		HealthcheckFallThreshold:   2,
+		HealthcheckRiseThreshold:   2,
+	})
+
+	refresh := func() (err error) {
+		return handler.Refresh(testutil.ContextWithTimeout(t, testTimeout))
+	}
+
+	// The first failure doesn't make the upstream down.
+	require.NoError(t, refresh())
+	require.Error(t, refresh())
+
+	upstreamIsUp.Store(true)
+
+	// The first success doesn't make the upstream up.
+	require.Error(t, refresh())
+	require.NoError(t, refresh())
+
+	upstreamIsUp.Store(false)
+
+	require.NoError(t, refresh())
+
+	// A success resets the number of failures.
+	upstreamIsUp.Store(true)
+	require.NoError(t, refresh())
+
+	upstreamIsUp.Store(false)
+	require.NoError(t, refresh())
+}
+
+func TestHandler_Refresh_recheck(t *testing.T) {
+	var upstreamRequestsCount atomic.Int64
+	upstream, _ := dnsservertest.RunDNSServer(t, newFailingHandler(
+		func(_ dnsserver.ResponseWriter) (ok bool) {
+			upstreamRequestsCount.Add(1)
+
+			return false
+		},
+	))
+	fallback, _ := dnsservertest.RunDNSServer(t, dnsservertest.NewDefaultHandler())
+
+	handler := forward.NewHandler(&forward.HandlerConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		UpstreamsAddresses: []*forward.UpstreamPlainConfig{{
+			Network: forward.NetworkUDP,
+			Address: netip.MustParseAddrPort(upstream.LocalUDPAddr().String()),
+			Timeout: testTimeout,
+		}},
+		HealthcheckDomainTmpl: "${RANDOM}.upstream-check.example",
+		FallbackAddresses: []*forward.UpstreamPlainConfig{{
+			Network: forward.NetworkAny,
+			Address: netip.MustParseAddrPort(fallback.LocalUDPAddr().String()),
+			Timeout: testTimeout,
+		}},
+		HealthcheckBackoffDuration: testTimeout,
+		HealthcheckRecheckInterval: 1 * time.Millisecond,
+		HealthcheckFallThreshold:   3,
+	})
+
+	err := handler.Refresh(testutil.ContextWithTimeout(t, testTimeout))
+	require.Error(t, err)
+
+	assert.Equal(t, int64(3), upstreamRequestsCount.Load())
+}
+
+func TestHandler_Refresh_probes(t *testing.T) {
+	upstream, _ := dnsservertest.RunDNSServer(t, newFailingHandler(
+		func(rw dnsserver.ResponseWriter) (ok bool) {
+			_, isTCP := rw.RemoteAddr().(*net.TCPAddr)
+
+			return !isTCP
+		},
+	))
+	fallback, _ := dnsservertest.RunDNSServer(t, dnsservertest.NewDefaultHandler())
+
+	testCases := []struct {
+		name    string
+		probes  []*forward.HealthcheckProbe
+		wantErr bool
+	}{{
+		name: "udp",
+		probes: []*forward.HealthcheckProbe{{
+			DomainTmpl: "${RANDOM}.upstream-check.example",
+			Network:    forward.NetworkUDP,
+			QType:      dns.TypeA,
+		}, {
+			DomainTmpl: "upstream-check.example",
+			Network:    forward.NetworkUDP,
+			QType:      dns.TypeAAAA,
+		}},
+		wantErr: false,
+	}, {
+		name: "tcp",
+		probes: []*forward.HealthcheckProbe{{
+			DomainTmpl: "${RANDOM}.upstream-check.example",
+			Network:    forward.NetworkUDP,
+			QType:      dns.TypeA,
+		}, {
+			DomainTmpl: "upstream-check.example",
+			Network:    forward.NetworkTCP,
+			QType:      dns.TypeA,
+		}},
+		wantErr: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := forward.NewHandler(&forward.HandlerConfig{
+				Logger: slogutil.NewDiscardLogger(),
+				UpstreamsAddresses: []*forward.UpstreamPlainConfig{{
+					Network: forward.NetworkAny,
+					Address: netip.MustParseAddrPort(upstream.LocalUDPAddr().String()),
+					Timeout: testTimeout,
+				}},
+				HealthcheckProbes: tc.probes,
+				FallbackAddresses: []*forward.UpstreamPlainConfig{{
+					Network: forward.NetworkAny,
+					Address: netip.MustParseAddrPort(fallback.LocalUDPAddr().String()),
+					Timeout: testTimeout,
+				}},
+				HealthcheckBackoffDuration: 0,
+			})
+
+			err := handler.Refresh(testutil.ContextWithTimeout(t, testTimeout))
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package forward
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/miekg/dns"
+)
+
+// EDNSCodeLoopDetection is the code of the local EDNS option that the handler
+// adds to the forwarded queries if the loop detection is enabled.  The data of
+// the option is the random identifier of the handler.  See RFC 6891, Section
+// 9.
+const EDNSCodeLoopDetection uint16 = 65100
+
+// loopIDLen is the length of the loop-detection identifier of a handler.
+const loopIDLen = 8
+
+// newLoopID returns a new random loop-detection identifier.
+func (h *Handler) newLoopID() (id []byte) {
+	return binary.BigEndian.AppendUint64(make([]byte, 0, loopIDLen), h.rand.Uint64())
+}
+
+// isLoop returns true if req contains the loop-detection option with the
+// identifier of h, which means that the query has already been forwarded by
+// this handler.
+func (h *Handler) isLoop(req *dns.Msg) (ok bool) {
+	opt := req.IsEdns0()
+	if opt == nil {
+		return false
+	}
+
+	for _, o := range opt.Option {
+		local, isLocal := o.(*dns.EDNS0_LOCAL)
+		if isLocal && local.Code == EDNSCodeLoopDetection && bytes.Equal(local.Data, h.loopID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addLoopOption adds the loop-detection option with the identifier of h to
+// req.  restore must be called to return req into its original state before
+// the request is used further, for example, to write the response.
+func (h *Handler) addLoopOption(req *dns.Msg) (restore func()) {
+	local := &dns.EDNS0_LOCAL{
+		Code: EDNSCodeLoopDetection,
+		Data: h.loopID,
+	}
+
+	opt := req.IsEdns0()
+	if opt != nil {
+		prevLen := len(opt.Option)
+		opt.Option = append(opt.Option, local)
+
+		return func() { opt.Option = opt.Option[:prevLen] }
+	}
+
+	prevLen := len(req.Extra)
+	opt = &dns.OPT{
+		Hdr: dns.RR_Header{
+			Name:   ".",
+			Rrtype: dns.TypeOPT,
+			Class:  dns.DefaultMsgSize,
+		},
+		Option: []dns.EDNS0{local},
+	}
+	req.Extra = append(req.Extra, opt)
+
+	return func() { req.Extra = req.Extra[:prevLen] }
+}
+
+// refuseLoop reports the forwarding loop detected for req and writes a REFUSED
+// response to rw, so that the loop is broken.
+func (h *Handler) refuseLoop(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+) (err error) {
+	h.metrics.OnLoopDetected(ctx, req)
+	h.logger.DebugContext(ctx, "forwarding loop detected", "qname", req.Question[0].Name)
+
+	resp := (&dns.Msg{}).SetRcode(req, dns.RcodeRefused)
+	resp.RecursionAvailable = true
+
+	err = rw.WriteMsg(ctx, req, resp)
+	if err != nil {
+		return fmt.Errorf("writing loop response: %w", err)
+	}
+
+	return nil
+}
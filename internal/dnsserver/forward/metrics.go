@@ -32,6 +32,16 @@ type MetricsListener interface {
 	// after a healthcheck probe.  True means the upstream is up, and false
 	// means the upstream is backed off.
 	OnUpstreamStatusChanged(ups Upstream, isMain, isUp bool)
+
+	// OnLoopDetected is called when the handler receives a request that it has
+	// already forwarded, which means that one of the upstreams sends the
+	// queries back to the handler.  req is the looped request.
+	OnLoopDetected(ctx context.Context, req *dns.Msg)
+
+	// OnCircuitStateChanged is called when the state of the circuit breaker of
+	// a group of upstreams changes.  group is the name of the group, see
+	// [UpstreamGroupNameMain].
+	OnCircuitStateChanged(group string, state CircuitState)
 }
 
 // EmptyMetricsListener implements MetricsListener with empty functions.
@@ -58,5 +68,17 @@ func (e *EmptyMetricsListener) OnUpstreamStatusChanged(_ Upstream, _, _ bool) {
 	// do nothing
 }
 
+// OnLoopDetected implements the MetricsListener interface for
+// *EmptyMetricsListener.
+func (e *EmptyMetricsListener) OnLoopDetected(_ context.Context, _ *dns.Msg) {
+	// do nothing
+}
+
+// OnCircuitStateChanged implements the MetricsListener interface for
+// *EmptyMetricsListener.
+func (e *EmptyMetricsListener) OnCircuitStateChanged(_ string, _ CircuitState) {
+	// do nothing
+}
+
 // type check
 var _ MetricsListener = (*EmptyMetricsListener)(nil)
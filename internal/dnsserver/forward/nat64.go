@@ -0,0 +1,63 @@
+package forward
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// ValidateNAT64Prefix returns an error if p is not a valid NAT64 prefix as
+// defined by RFC 6052, Section 2.2.
+func ValidateNAT64Prefix(p netip.Prefix) (err error) {
+	if !p.IsValid() {
+		return errors.Error("invalid prefix")
+	} else if !p.Addr().Is6() || p.Addr().Is4In6() {
+		return fmt.Errorf("prefix %s is not ipv6", p)
+	} else if p.Masked() != p {
+		return fmt.Errorf("prefix %s has bits set outside of the mask", p)
+	}
+
+	switch p.Bits() {
+	case 32, 40, 48, 56, 64, 96:
+		return nil
+	default:
+		return fmt.Errorf("prefix %s: length must be one of 32, 40, 48, 56, 64, or 96", p)
+	}
+}
+
+// nat64ReservedOctet is the offset of the reserved octet, the "u" octet in RFC
+// 6052, within an IPv6 address.
+const nat64ReservedOctet = 8
+
+// mapToNAT64 returns an IPv6 address with ip embedded into prefix as described
+// in RFC 6052, Section 2.2.  prefix must be valid according to
+// [ValidateNAT64Prefix], and ip must be an IPv4 address.
+func mapToNAT64(prefix netip.Prefix, ip netip.Addr) (mapped netip.Addr) {
+	data := prefix.Addr().As16()
+	ip4 := ip.As4()
+
+	octet := prefix.Bits() / 8
+	for _, b := range ip4 {
+		if octet == nat64ReservedOctet {
+			// Bits 64 to 71 must be set to zero.
+			data[octet] = 0
+			octet++
+		}
+
+		data[octet] = b
+		octet++
+	}
+
+	return netip.AddrFrom16(data)
+}
+
+// mapUpstreamAddr returns the address of the upstream mapped into prefix, if
+// prefix is valid and addr is an IPv4 address.  Otherwise, it returns addr.
+func mapUpstreamAddr(prefix netip.Prefix, addr netip.AddrPort) (mapped netip.AddrPort) {
+	if !prefix.IsValid() || !addr.Addr().Unmap().Is4() {
+		return addr
+	}
+
+	return netip.AddrPortFrom(mapToNAT64(prefix, addr.Addr().Unmap()), addr.Port())
+}
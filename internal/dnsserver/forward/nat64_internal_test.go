@@ -0,0 +1,87 @@
+package forward
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapToNAT64(t *testing.T) {
+	t.Parallel()
+
+	// See RFC 6052, Section 2.4.
+	ip := netip.MustParseAddr("192.0.2.33")
+
+	testCases := []struct {
+		want   netip.Addr
+		prefix netip.Prefix
+	}{{
+		prefix: netip.MustParsePrefix("2001:db8::/32"),
+		want:   netip.MustParseAddr("2001:db8:c000:221::"),
+	}, {
+		prefix: netip.MustParsePrefix("2001:db8:100::/40"),
+		want:   netip.MustParseAddr("2001:db8:1c0:2:21::"),
+	}, {
+		prefix: netip.MustParsePrefix("2001:db8:122::/48"),
+		want:   netip.MustParseAddr("2001:db8:122:c000:2:2100::"),
+	}, {
+		prefix: netip.MustParsePrefix("2001:db8:122:300::/56"),
+		want:   netip.MustParseAddr("2001:db8:122:3c0:0:221::"),
+	}, {
+		prefix: netip.MustParsePrefix("2001:db8:122:344::/64"),
+		want:   netip.MustParseAddr("2001:db8:122:344:c0:2:2100:0"),
+	}, {
+		prefix: netip.MustParsePrefix("64:ff9b::/96"),
+		want:   netip.MustParseAddr("64:ff9b::c000:221"),
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.prefix.String(), func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, mapToNAT64(tc.prefix, ip))
+		})
+	}
+}
+
+func TestValidateNAT64Prefix(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		prefix     netip.Prefix
+		name       string
+		wantErrMsg string
+	}{{
+		prefix:     netip.MustParsePrefix("64:ff9b::/96"),
+		name:       "well_known",
+		wantErrMsg: "",
+	}, {
+		prefix:     netip.Prefix{},
+		name:       "empty",
+		wantErrMsg: "invalid prefix",
+	}, {
+		prefix:     netip.MustParsePrefix("192.0.2.0/24"),
+		name:       "ipv4",
+		wantErrMsg: "prefix 192.0.2.0/24 is not ipv6",
+	}, {
+		prefix:     netip.MustParsePrefix("64:ff9b::1/96"),
+		name:       "not_masked",
+		wantErrMsg: "prefix 64:ff9b::1/96 has bits set outside of the mask",
+	}, {
+		prefix: netip.MustParsePrefix("64:ff9b::/80"),
+		name:   "bad_length",
+		wantErrMsg: "prefix 64:ff9b::/80: " +
+			"length must be one of 32, 40, 48, 56, 64, or 96",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := ValidateNAT64Prefix(tc.prefix)
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, err)
+		})
+	}
+}
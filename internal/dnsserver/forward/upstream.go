@@ -20,3 +20,14 @@ type Upstream interface {
 	io.Closer
 	fmt.Stringer
 }
+
+// overNetworkExchanger is an upstream that can send queries over a particular
+// network.  It is used to perform healthchecks over each network.
+type overNetworkExchanger interface {
+	// usesNetwork returns true if the upstream sends queries over nw.
+	usesNetwork(nw Network) (ok bool)
+
+	// exchangeOver is like [Upstream.Exchange] but only uses nw to send req.
+	// nw must be either [NetworkUDP] or [NetworkTCP].
+	exchangeOver(ctx context.Context, req *dns.Msg, nw Network) (resp *dns.Msg, err error)
+}
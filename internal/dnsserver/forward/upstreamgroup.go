@@ -0,0 +1,142 @@
+package forward
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// UpstreamGroupConfig is the configuration of a named group of upstreams.
+type UpstreamGroupConfig struct {
+	// CircuitBreaker is the optional configuration of the circuit breaker of
+	// the group.  If it is nil, the group is never skipped.
+	CircuitBreaker *CircuitBreakerConfig
+
+	// Name is the unique name of the group.  It must not be empty or equal to
+	// [UpstreamGroupNameMain].
+	Name string
+
+	// Upstreams are the configurations of the upstreams of the group.  It must
+	// not be empty, and items must not be nil.
+	Upstreams []*UpstreamPlainConfig
+}
+
+// UpstreamGroupNameMain is the name of the group of the main upstreams, see
+// [HandlerConfig.UpstreamsAddresses].
+const UpstreamGroupNameMain = "main"
+
+// UpstreamGroupStatus is the status of a group of upstreams.
+type UpstreamGroupStatus struct {
+	// Name is the name of the group.
+	Name string
+
+	// State is the state of the circuit breaker of the group.
+	State CircuitState
+}
+
+// upstreamGroup is a named group of upstreams with an optional circuit
+// breaker.
+type upstreamGroup struct {
+	// breaker is the circuit breaker of the group.  It is nil if the group has
+	// no circuit breaker.
+	breaker *circuitBreaker
+
+	// name is the name of the group.
+	name string
+
+	// upstreams are the upstreams of the group.  It is never empty.
+	upstreams []Upstream
+}
+
+// newUpstreamGroup returns a new properly initialized *upstreamGroup.  c and
+// hc must be valid.
+func (h *Handler) newUpstreamGroup(c *UpstreamGroupConfig, hc *HandlerConfig) (g *upstreamGroup) {
+	g = &upstreamGroup{
+		name:      c.Name,
+		upstreams: make([]Upstream, 0, len(c.Upstreams)),
+	}
+
+	for _, upsConf := range c.Upstreams {
+		upsConf = withHandlerConf(upsConf, hc)
+		g.upstreams = append(g.upstreams, h.newUpstream(upsConf, hc))
+	}
+
+	g.breaker = newCircuitBreaker(c.CircuitBreaker, h.newCircuitChangeFunc(c.Name))
+
+	return g
+}
+
+// newCircuitChangeFunc returns a function that logs and reports the changes of
+// the state of the circuit breaker of the group with the given name.
+func (h *Handler) newCircuitChangeFunc(
+	name string,
+) (f func(ctx context.Context, from, to CircuitState)) {
+	return func(ctx context.Context, from, to CircuitState) {
+		lvl := slog.LevelInfo
+		if to == CircuitOpen {
+			lvl = slog.LevelWarn
+		}
+
+		h.logger.Log(ctx, lvl, "circuit state changed", "group", name, "from", from, "to", to)
+		h.metrics.OnCircuitStateChanged(name, to)
+	}
+}
+
+// exchangeGroups forwards req to the first group the circuit breaker of which
+// allows it.  If the group fails with a network error, the next one is tried.
+// ups is the last upstream used, if any.  useFallbacks is true if all groups
+// have been skipped or have failed with a network error.
+func (h *Handler) exchangeGroups(
+	ctx context.Context,
+	req *dns.Msg,
+) (resp *dns.Msg, ups Upstream, useFallbacks bool, err error) {
+	for _, g := range h.groups {
+		if !g.breaker.allow(ctx, time.Now()) {
+			continue
+		}
+
+		ups = g.upstreams[h.rand.Intn(len(g.upstreams))]
+		resp, err = h.exchange(ctx, ups, req)
+		useFallbacks = isNetError(err)
+		g.breaker.report(ctx, time.Now(), !useFallbacks)
+		if !useFallbacks {
+			return resp, ups, false, err
+		}
+	}
+
+	return resp, ups, true, err
+}
+
+// isNetError returns true if err is a network error, which means that something
+// is wrong with the upstream.
+func isNetError(err error) (ok bool) {
+	var netErr net.Error
+
+	return err != nil && errors.As(err, &netErr)
+}
+
+// UpstreamGroups returns the statuses of the main upstreams, if they have a
+// circuit breaker, and of the upstream groups, in the order in which they are
+// tried.
+func (h *Handler) UpstreamGroups() (statuses []*UpstreamGroupStatus) {
+	statuses = make([]*UpstreamGroupStatus, 0, len(h.groups)+1)
+	if h.mainBreaker != nil {
+		statuses = append(statuses, &UpstreamGroupStatus{
+			Name:  UpstreamGroupNameMain,
+			State: h.mainBreaker.currentState(),
+		})
+	}
+
+	for _, g := range h.groups {
+		statuses = append(statuses, &UpstreamGroupStatus{
+			Name:  g.name,
+			State: g.breaker.currentState(),
+		})
+	}
+
+	return statuses
+}
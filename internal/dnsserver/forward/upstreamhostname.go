@@ -0,0 +1,200 @@
+package forward
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"sync"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/netutil"
+	"github.com/miekg/dns"
+)
+
+// UpstreamHostnameConfig is the configuration structure for
+// [NewUpstreamHostname].
+type UpstreamHostnameConfig struct {
+	// Bootstrap is used to resolve the hostname of the upstream.  It must not
+	// be nil.
+	Bootstrap *Bootstrap
+
+	// Upstream is the configuration of the upstream.  Its Hostname must not be
+	// empty, and only the port of its Address is used.  AdditionalAddresses
+	// are ignored.  It must not be nil.
+	Upstream *UpstreamPlainConfig
+
+	// NAT64Prefix is the optional NAT64 prefix.  If set, the resolved IPv4
+	// addresses are mapped into it.  If set, it must be valid according to
+	// [ValidateNAT64Prefix].
+	NAT64Prefix netip.Prefix
+}
+
+// UpstreamHostname is a plain-DNS upstream, the addresses of which are resolved
+// from its hostname using a [Bootstrap].  The addresses are re-resolved when
+// the cached ones expire or when the upstream fails with a network error.
+type UpstreamHostname struct {
+	// bootstrap is used to resolve hostname.
+	bootstrap *Bootstrap
+
+	// conf is the configuration of the underlying plain-DNS upstreams.
+	conf *UpstreamPlainConfig
+
+	// upsMu protects ups and addrs.
+	upsMu *sync.Mutex
+
+	// ups is the upstream for the current addresses.  It is nil until the
+	// first successful resolution.
+	ups *UpstreamPlain
+
+	// nat64Prefix is the optional NAT64 prefix for the resolved addresses.
+	nat64Prefix netip.Prefix
+
+	// hostname is the hostname of the upstream.
+	hostname string
+
+	// addrs are the addresses of ups.
+	addrs []netip.AddrPort
+
+	// port is the port of the upstream.
+	port uint16
+}
+
+// NewUpstreamHostname returns a new properly initialized *UpstreamHostname.  c
+// must not be nil and must be valid.
+func NewUpstreamHostname(c *UpstreamHostnameConfig) (ups *UpstreamHostname) {
+	return &UpstreamHostname{
+		bootstrap:   c.Bootstrap,
+		conf:        c.Upstream,
+		upsMu:       &sync.Mutex{},
+		nat64Prefix: c.NAT64Prefix,
+		hostname:    c.Upstream.Hostname,
+		port:        c.Upstream.Address.Port(),
+	}
+}
+
+// type check
+var _ Upstream = (*UpstreamHostname)(nil)
+
+// Exchange implements the [Upstream] interface for *UpstreamHostname.
+func (u *UpstreamHostname) Exchange(
+	ctx context.Context,
+	req *dns.Msg,
+) (resp *dns.Msg, nw Network, err error) {
+	ups, err := u.current(ctx)
+	if err != nil {
+		return nil, u.conf.Network, err
+	}
+
+	resp, nw, err = ups.Exchange(ctx, req)
+	u.invalidateOnNetErr(err)
+
+	return resp, nw, err
+}
+
+// type check
+var _ overNetworkExchanger = (*UpstreamHostname)(nil)
+
+// usesNetwork implements the [overNetworkExchanger] interface for
+// *UpstreamHostname.
+func (u *UpstreamHostname) usesNetwork(nw Network) (ok bool) {
+	return u.conf.Network == NetworkAny || u.conf.Network == nw
+}
+
+// exchangeOver implements the [overNetworkExchanger] interface for
+// *UpstreamHostname.
+func (u *UpstreamHostname) exchangeOver(
+	ctx context.Context,
+	req *dns.Msg,
+	nw Network,
+) (resp *dns.Msg, err error) {
+	ups, err := u.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err = ups.exchangeOver(ctx, req, nw)
+	u.invalidateOnNetErr(err)
+
+	return resp, err
+}
+
+// Close implements the [Upstream] interface for *UpstreamHostname.
+func (u *UpstreamHostname) Close() (err error) {
+	u.upsMu.Lock()
+	defer u.upsMu.Unlock()
+
+	if u.ups == nil {
+		return nil
+	}
+
+	return u.ups.Close()
+}
+
+// String implements the [Upstream] interface for *UpstreamHostname.  The
+// format is the same as the one of [UpstreamPlain.String], but with the
+// hostname instead of the IP address.
+func (u *UpstreamHostname) String() (str string) {
+	hostport := netutil.JoinHostPort(u.hostname, u.port)
+	if u.conf.Network == NetworkAny {
+		return hostport
+	}
+
+	return fmt.Sprintf("%s://%s", u.conf.Network, hostport)
+}
+
+// current returns the upstream for the current addresses of the hostname,
+// creating a new one if the addresses have changed.
+func (u *UpstreamHostname) current(ctx context.Context) (ups *UpstreamPlain, err error) {
+	ips, err := u.bootstrap.Resolve(ctx, u.hostname)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %q: %w", u.hostname, err)
+	}
+
+	addrs := make([]netip.AddrPort, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, mapUpstreamAddr(u.nat64Prefix, netip.AddrPortFrom(ip, u.port)))
+	}
+
+	u.upsMu.Lock()
+	defer u.upsMu.Unlock()
+
+	if u.ups != nil && slices.Equal(addrs, u.addrs) {
+		return u.ups, nil
+	}
+
+	if u.ups != nil {
+		// The connections currently in use are closed when they are returned
+		// to the closed pools.
+		err = u.ups.Close()
+		if err != nil {
+			u.bootstrap.logger.WarnContext(
+				ctx,
+				"closing previous upstream",
+				"upstream", u,
+				slogutil.KeyError, err,
+			)
+		}
+	}
+
+	conf := &UpstreamPlainConfig{}
+	*conf = *u.conf
+	conf.Address = addrs[0]
+	conf.AdditionalAddresses = addrs[1:]
+
+	u.ups = NewUpstreamPlain(conf)
+	u.addrs = addrs
+
+	return u.ups, nil
+}
+
+// invalidateOnNetErr makes the bootstrap re-resolve the hostname on the next
+// exchange if err is a network error.
+func (u *UpstreamHostname) invalidateOnNetErr(err error) {
+	var netErr net.Error
+	if err != nil && errors.As(err, &netErr) {
+		u.bootstrap.Invalidate(u.hostname)
+	}
+}
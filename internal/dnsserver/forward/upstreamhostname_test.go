@@ -0,0 +1,141 @@
+package forward_test
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/forward"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testUpsHostname is the hostname of the upstream used in tests.
+const testUpsHostname = "upstream.example"
+
+// newBootstrapHandler returns a handler that responds to the A queries for
+// [testUpsHostname] with 127.0.0.1 and ttl, to the other ones with an empty
+// response, and counts the former in queries.
+func newBootstrapHandler(ttl uint32, queries *atomic.Uint32) (h dnsserver.Handler) {
+	return dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		q := req.Question[0]
+		if q.Qtype != dns.TypeA || q.Name != dns.Fqdn(testUpsHostname) {
+			return rw.WriteMsg(ctx, req, dnsservertest.NewResp(dns.RcodeSuccess, req))
+		}
+
+		queries.Add(1)
+		ans := dnsservertest.NewA(q.Name, ttl, netip.MustParseAddr("127.0.0.1"))
+
+		return rw.WriteMsg(ctx, req, dnsservertest.NewResp(
+			dns.RcodeSuccess,
+			req,
+			dnsservertest.SectionAnswer{ans},
+		))
+	})
+}
+
+func TestUpstreamHostname_Exchange(t *testing.T) {
+	_, upsAddrStr := dnsservertest.RunDNSServer(t, dnsservertest.NewDefaultHandler())
+	upsAddr := netip.MustParseAddrPort(upsAddrStr)
+
+	testCases := []struct {
+		name        string
+		ttl         uint32
+		wantQueries uint32
+	}{{
+		name:        "cached",
+		ttl:         3600,
+		wantQueries: 1,
+	}, {
+		name:        "zero_ttl",
+		ttl:         0,
+		wantQueries: 2,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			queries := &atomic.Uint32{}
+			_, bsAddr := dnsservertest.RunDNSServer(t, newBootstrapHandler(tc.ttl, queries))
+
+			bs := forward.NewBootstrap(&forward.BootstrapConfig{
+				Resolvers: []netip.AddrPort{netip.MustParseAddrPort(bsAddr)},
+				Timeout:   testTimeout,
+			})
+			testutil.CleanupAndRequireSuccess(t, bs.Close)
+
+			ups := forward.NewUpstreamHostname(&forward.UpstreamHostnameConfig{
+				Bootstrap: bs,
+				Upstream: &forward.UpstreamPlainConfig{
+					Network:  forward.NetworkAny,
+					Address:  netip.AddrPortFrom(netip.Addr{}, upsAddr.Port()),
+					Hostname: testUpsHostname,
+					Timeout:  testTimeout,
+				},
+			})
+			testutil.CleanupAndRequireSuccess(t, ups.Close)
+
+			for range 2 {
+				req := dnsservertest.CreateMessage("example.org.", dns.TypeA)
+				ctx := testutil.ContextWithTimeout(t, testTimeout)
+				resp, _, err := ups.Exchange(ctx, req)
+				require.NoError(t, err)
+
+				dnsservertest.RequireResponse(t, req, resp, 1, dns.RcodeSuccess, false)
+			}
+
+			assert.Equal(t, tc.wantQueries, queries.Load())
+		})
+	}
+}
+
+func TestBootstrap_Resolve(t *testing.T) {
+	queries := &atomic.Uint32{}
+	_, bsAddr := dnsservertest.RunDNSServer(t, newBootstrapHandler(3600, queries))
+
+	bs := forward.NewBootstrap(&forward.BootstrapConfig{
+		Resolvers: []netip.AddrPort{netip.MustParseAddrPort(bsAddr)},
+		Timeout:   testTimeout,
+	})
+	testutil.CleanupAndRequireSuccess(t, bs.Close)
+
+	wantAddrs := []netip.Addr{netip.MustParseAddr("127.0.0.1")}
+
+	t.Run("invalidate", func(t *testing.T) {
+		ctx := testutil.ContextWithTimeout(t, testTimeout)
+		addrs, err := bs.Resolve(ctx, testUpsHostname)
+		require.NoError(t, err)
+
+		assert.Equal(t, wantAddrs, addrs)
+
+		bs.Invalidate(testUpsHostname)
+
+		addrs, err = bs.Resolve(ctx, testUpsHostname)
+		require.NoError(t, err)
+
+		assert.Equal(t, wantAddrs, addrs)
+		assert.Equal(t, uint32(2), queries.Load())
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		ctx := testutil.ContextWithTimeout(t, testTimeout)
+		addrs, err := bs.Resolve(ctx, "other.example")
+		require.Error(t, err)
+
+		assert.Empty(t, addrs)
+
+		var dnsErr *net.DNSError
+		require.ErrorAs(t, err, &dnsErr)
+
+		assert.True(t, dnsErr.IsNotFound)
+	})
+}
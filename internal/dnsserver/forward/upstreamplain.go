@@ -12,6 +12,7 @@ import (
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/pool"
 	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/AdguardTeam/golibs/syncutil"
 	"github.com/miekg/dns"
 )
@@ -66,6 +67,10 @@ type UpstreamPlain struct {
 	udpBufs *syncutil.Pool[[]byte]
 	tcpBufs *syncutil.Pool[[]byte]
 
+	// dialer connects to the upstream using the Happy Eyeballs algorithm.  It
+	// is nil if the upstream has only one address.
+	dialer *happyEyeballsDialer
+
 	addr    netip.AddrPort
 	network Network
 
@@ -81,9 +86,27 @@ type UpstreamPlainConfig struct {
 	// Network is the network to use for this upstream.
 	Network Network
 
-	// Address is the address of the upstream DNS server.
+	// HappyEyeballs is the optional configuration of the Happy Eyeballs
+	// algorithm used when AdditionalAddresses is not empty.  If it is nil, the
+	// defaults are used.
+	HappyEyeballs *HappyEyeballsConfig
+
+	// Address is the address of the upstream DNS server.  If Hostname is set,
+	// only the port of Address is used.
 	Address netip.AddrPort
 
+	// Hostname is the optional hostname of the upstream DNS server.  If set,
+	// the addresses of the server are resolved using the bootstrap resolvers,
+	// see [HandlerConfig.Bootstrap] and [UpstreamHostname].  It is ignored by
+	// [NewUpstreamPlain].
+	Hostname string
+
+	// AdditionalAddresses are the optional other addresses of the same
+	// upstream DNS server, typically of the other IP family.  If set, the
+	// connections are established using the Happy Eyeballs algorithm, see
+	// [HappyEyeballsConfig].
+	AdditionalAddresses []netip.AddrPort
+
 	// Timeout is the optional query timeout for upstreams.  If not set, the
 	// context timeout or [defaultUDPTimeout] is used in case of UDP network.
 	Timeout time.Duration
@@ -102,6 +125,11 @@ func NewUpstreamPlain(c *UpstreamPlainConfig) (ups *UpstreamPlain) {
 		timeout: c.Timeout,
 	}
 
+	if len(c.AdditionalAddresses) > 0 {
+		addrs := append([]netip.AddrPort{c.Address}, c.AdditionalAddresses...)
+		ups.dialer = newHappyEyeballsDialer(c.HappyEyeballs, addrs)
+	}
+
 	ups.connsPoolUDP = pool.NewPool(poolMaxCapacity, makeConnsPoolFactory(ups, NetworkUDP))
 	ups.connsPoolUDP.IdleTimeout = poolIdleTimeout
 	ups.connsPoolTCP = pool.NewPool(poolMaxCapacity, makeConnsPoolFactory(ups, NetworkTCP))
@@ -140,6 +168,33 @@ func (u *UpstreamPlain) Exchange(
 	return resp, NetworkTCP, err
 }
 
+// type check
+var _ overNetworkExchanger = (*UpstreamPlain)(nil)
+
+// usesNetwork implements the [overNetworkExchanger] interface for
+// *UpstreamPlain.
+func (u *UpstreamPlain) usesNetwork(nw Network) (ok bool) {
+	return u.network == NetworkAny || u.network == nw
+}
+
+// exchangeOver implements the [overNetworkExchanger] interface for
+// *UpstreamPlain.
+func (u *UpstreamPlain) exchangeOver(
+	ctx context.Context,
+	req *dns.Msg,
+	nw Network,
+) (resp *dns.Msg, err error) {
+	defer func() { err = errors.Annotate(err, "upstreamplain: %w") }()
+
+	if u.timeout > 0 {
+		var cancel func()
+		ctx, cancel = context.WithTimeout(ctx, u.timeout)
+		defer cancel()
+	}
+
+	return u.exchangeNet(ctx, req, nw)
+}
+
 // Close implements the io.Closer interface for *UpstreamPlain.
 func (u *UpstreamPlain) Close() (err error) {
 	udpErr := u.connsPoolUDP.Close()
@@ -231,6 +286,12 @@ func (u *UpstreamPlain) exchangeNet(
 	// err is already wrapped inside processConn.
 	resp, err = u.processConn(ctx, conn, connsPool, network, req, buf, bufReqLen)
 	if isExpectedConnErr(err) {
+		// Only consider timeouts a failure of the address here, since other
+		// errors may be caused by a stale idle connection.
+		if isTimeout(err) {
+			u.reportAddrFailure(conn)
+		}
+
 		conn, err = connsPool.Create(ctx)
 		if err != nil {
 			return nil, fmt.Errorf("creating connection: %w", err)
@@ -238,11 +299,24 @@ func (u *UpstreamPlain) exchangeNet(
 
 		// err is already wrapped inside processConn.
 		resp, err = u.processConn(ctx, conn, connsPool, network, req, buf, bufReqLen)
+		if isExpectedConnErr(err) {
+			u.reportAddrFailure(conn)
+		}
 	}
 
 	return resp, err
 }
 
+// reportAddrFailure remembers that the remote address of conn has failed, so
+// that the other addresses of the upstream are tried first next time.
+func (u *UpstreamPlain) reportAddrFailure(conn net.Conn) {
+	if u.dialer == nil {
+		return
+	}
+
+	u.dialer.reportFailure(netutil.NetAddrToAddrPort(conn.RemoteAddr()), time.Now())
+}
+
 // validatePlainResponse returns an error if the response is not valid for the
 // original request.  This is required because we might receive a response to a
 // different query, e.g. when the server is under heavy load.
@@ -445,6 +519,10 @@ func makeConnsPoolFactory(u *UpstreamPlain, network Network) (f pool.Factory) {
 	}
 
 	return func(ctx context.Context) (conn net.Conn, err error) {
+		if u.dialer != nil {
+			return u.dialer.dial(ctx, dialNetwork)
+		}
+
 		deadline, ok := ctx.Deadline()
 		var timeout time.Duration
 		if ok {
@@ -462,3 +540,10 @@ func isExpectedConnErr(err error) (is bool) {
 
 	return err != nil && (errors.As(err, &netErr) || errors.Is(err, io.EOF))
 }
+
+// isTimeout returns true if err is a network timeout error.
+func isTimeout(err error) (ok bool) {
+	var netErr net.Error
+
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
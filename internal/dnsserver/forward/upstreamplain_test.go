@@ -2,6 +2,7 @@ package forward_test
 
 import (
 	"context"
+	"net"
 	"net/netip"
 	"testing"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/forward"
 	"github.com/AdguardTeam/golibs/log"
+	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/AdguardTeam/golibs/testutil"
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
@@ -54,6 +56,38 @@ func TestUpstreamPlain_Exchange(t *testing.T) {
 	}
 }
 
+func TestUpstreamPlain_Exchange_happyEyeballs(t *testing.T) {
+	_, addr := dnsservertest.RunDNSServer(t, dnsservertest.NewDefaultHandler())
+
+	// Get an address on which nothing listens.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	deadAddr := netutil.NetAddrToAddrPort(l.Addr())
+	require.NoError(t, l.Close())
+
+	ups := forward.NewUpstreamPlain(&forward.UpstreamPlainConfig{
+		HappyEyeballs: &forward.HappyEyeballsConfig{
+			ConnectionAttemptDelay: testTimeout,
+			PreferIPv4:             true,
+		},
+		Network:             forward.NetworkTCP,
+		Address:             deadAddr,
+		AdditionalAddresses: []netip.AddrPort{netip.MustParseAddrPort(addr)},
+	})
+	testutil.CleanupAndRequireSuccess(t, ups.Close)
+
+	for range 2 {
+		req := dnsservertest.CreateMessage("example.org.", dns.TypeA)
+		res, nw, exchErr := ups.Exchange(testutil.ContextWithTimeout(t, testTimeout), req)
+		require.NoError(t, exchErr)
+		require.NotNil(t, res)
+		dnsservertest.RequireResponse(t, req, res, 1, dns.RcodeSuccess, false)
+
+		assert.Equal(t, forward.NetworkTCP, nw)
+	}
+}
+
 func TestUpstreamPlain_Exchange_truncated(t *testing.T) {
 	// this handler always truncates responses if they're received over UDP.
 	handlerFunc := dnsserver.HandlerFunc(func(
@@ -51,6 +51,22 @@ type MetricsListener interface {
 	// allows to keep an eye on how the addresses cache performs.
 	// TODO(ameshkov): find a way to attach this info to ctx and remove this.
 	OnQUICAddressValidation(hit bool)
+
+	// OnWebSocketConnClosed called when a DNS-over-WebSocket connection is
+	// closed.  ctx is the context of the connection.  queries is the number of
+	// queries received over the connection.  limited is true if the server has
+	// closed the connection, because the client has reached the query limit.
+	OnWebSocketConnClosed(ctx context.Context, queries uint, limited bool)
+
+	// OnHTTPLimitExceeded called when a DoH request exceeds a limit of the
+	// server.  ctx is the context of the request.  closed is true if the
+	// server closes the connection after the response.
+	OnHTTPLimitExceeded(ctx context.Context, limit HTTPLimit, closed bool)
+
+	// OnHTTPStreamReset is called when the client resets the HTTP/2 or HTTP/3
+	// stream of a DoH request before the response is written.  ctx is the
+	// context of the request.
+	OnHTTPStreamReset(ctx context.Context)
 }
 
 // QueryInfo contains the request with its size, and the response with its size.
@@ -93,3 +109,15 @@ func (e EmptyMetricsListener) OnPanic(_ context.Context, _ any) {}
 // OnQUICAddressValidation implements the [MetricsListener] interface for
 // EmptyMetricsListener.
 func (e EmptyMetricsListener) OnQUICAddressValidation(_ bool) {}
+
+// OnWebSocketConnClosed implements the [MetricsListener] interface for
+// EmptyMetricsListener.
+func (e EmptyMetricsListener) OnWebSocketConnClosed(_ context.Context, _ uint, _ bool) {}
+
+// OnHTTPLimitExceeded implements the [MetricsListener] interface for
+// EmptyMetricsListener.
+func (e EmptyMetricsListener) OnHTTPLimitExceeded(_ context.Context, _ HTTPLimit, _ bool) {}
+
+// OnHTTPStreamReset implements the [MetricsListener] interface for
+// EmptyMetricsListener.
+func (e EmptyMetricsListener) OnHTTPStreamReset(_ context.Context) {}
@@ -35,10 +35,9 @@ func DefaultListenConfig(conf *ControlConfig) (lc ListenConfig) {
 		conf = defaultCtrlConf
 	}
 
-	return &net.ListenConfig{
-		Control: func(_, _ string, c syscall.RawConn) (err error) {
-			return listenControlWithSO(conf, c)
-		},
+	return &listenConfig{
+		ListenConfig: newNetListenConfig(conf),
+		conf:         conf,
 	}
 }
 
@@ -52,21 +51,67 @@ func DefaultListenConfigWithOOB(conf *ControlConfig) (lc ListenConfig) {
 	}
 
 	return &listenConfigOOB{
-		ListenConfig: net.ListenConfig{
-			Control: func(_, _ string, c syscall.RawConn) (err error) {
-				return listenControlWithSO(conf, c)
-			},
+		listenConfig: listenConfig{
+			ListenConfig: newNetListenConfig(conf),
+			conf:         conf,
+		},
+	}
+}
+
+// newNetListenConfig returns a [net.ListenConfig] that sets the socket options
+// from conf.  conf must not be nil.
+func newNetListenConfig(conf *ControlConfig) (lc net.ListenConfig) {
+	return net.ListenConfig{
+		Control: func(network, _ string, c syscall.RawConn) (err error) {
+			return listenControlWithSO(conf, network, c)
 		},
+		KeepAliveConfig: conf.KeepAlive,
 	}
 }
 
+// type check
+var _ ListenConfig = (*listenConfig)(nil)
+
+// listenConfig is a wrapper around [net.ListenConfig] that applies the options
+// of the accepted stream-connections.
+type listenConfig struct {
+	// conf is the configuration of the socket options.  It must not be nil.
+	conf *ControlConfig
+
+	net.ListenConfig
+}
+
+// Listen implements the [ListenConfig] interface for *listenConfig.  The
+// returned listener sets the TCP_NODELAY option of the accepted connections and
+// reports the ones accepted using TCP Fast Open, if necessary.
+func (lc *listenConfig) Listen(
+	ctx context.Context,
+	network string,
+	address string,
+) (l net.Listener, err error) {
+	l, err = lc.ListenConfig.Listen(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if !lc.conf.DisableTCPNoDelay && lc.conf.TCPFastOpenQueueLen == 0 {
+		return l, nil
+	}
+
+	return &tcpListener{
+		Listener: l,
+		ctx:      ctx,
+		conf:     lc.conf,
+	}, nil
+}
+
 // type check
 var _ ListenConfig = (*listenConfigOOB)(nil)
 
-// listenConfigOOB is a wrapper around [net.ListenConfig] with modifications
-// that set the control-message options on packet conns.
+// listenConfigOOB is a wrapper around [listenConfig] with modifications that
+// set the control-message options on packet conns.
 type listenConfigOOB struct {
-	net.ListenConfig
+	listenConfig
 }
 
 // ListenPacket implements the [ListenConfig] interface for *listenConfigOOB.
@@ -95,6 +140,15 @@ func (lc *listenConfigOOB) ListenPacket(
 
 // ControlConfig is the configuration of socket options.
 type ControlConfig struct {
+	// Metrics is used to collect the statistics of the accepted connections.
+	// If nil, [EmptyMetrics] is used.
+	Metrics Metrics
+
+	// KeepAlive is the configuration of the TCP keep-alive probes of the
+	// accepted connections.  If KeepAlive.Enable is false, the defaults of
+	// [net.ListenConfig] are used.
+	KeepAlive net.KeepAliveConfig
+
 	// RcvBufSize defines the size of socket receive buffer in bytes.  Default
 	// is zero (uses system settings).
 	RcvBufSize int
@@ -102,4 +156,22 @@ type ControlConfig struct {
 	// SndBufSize defines the size of socket send buffer in bytes.  Default is
 	// zero (uses system settings).
 	SndBufSize int
+
+	// TCPFastOpenQueueLen is the maximum length of the queue of the pending
+	// server-side TCP Fast Open requests of the TCP listeners.  Default is zero
+	// (TCP Fast Open is disabled).  It is currently only supported on Linux.
+	TCPFastOpenQueueLen int
+
+	// DisableTCPNoDelay, if true, makes the accepted TCP connections use the
+	// Nagle's algorithm, which is disabled by default.
+	DisableTCPNoDelay bool
+}
+
+// metrics returns the metrics of c or [EmptyMetrics] if there are none.
+func (c *ControlConfig) metrics() (m Metrics) {
+	if c.Metrics == nil {
+		return EmptyMetrics{}
+	}
+
+	return c.Metrics
 }
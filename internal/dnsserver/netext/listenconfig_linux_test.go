@@ -0,0 +1,66 @@
+//go:build linux
+
+package netext_test
+
+import (
+	"context"
+	"net"
+	"syscall"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// getSockOpt is a helper that returns the value of the integer socket option
+// of c.
+func getSockOpt(tb testing.TB, c syscall.Conn, lvl, opt int) (val int) {
+	tb.Helper()
+
+	sc, err := c.SyscallConn()
+	require.NoError(tb, err)
+
+	var opErr error
+	err = sc.Control(func(fd uintptr) {
+		val, opErr = unix.GetsockoptInt(int(fd), lvl, opt)
+	})
+	require.NoError(tb, err)
+	require.NoError(tb, opErr)
+
+	return val
+}
+
+func TestDefaultListenConfig_tcp(t *testing.T) {
+	t.Parallel()
+
+	const qLen = 16
+
+	lc := netext.DefaultListenConfig(&netext.ControlConfig{
+		TCPFastOpenQueueLen: qLen,
+		DisableTCPNoDelay:   true,
+	})
+
+	l, err := lc.Listen(context.Background(), "tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, l.Close)
+
+	addr := l.Addr()
+	require.Implements(t, (*syscall.Conn)(nil), l)
+
+	assert.Equal(t, qLen, getSockOpt(t, l.(syscall.Conn), unix.IPPROTO_TCP, unix.TCP_FASTOPEN))
+
+	client, err := net.Dial(addr.Network(), addr.String())
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, client.Close)
+
+	c, err := l.Accept()
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, c.Close)
+
+	require.Implements(t, (*syscall.Conn)(nil), c)
+
+	assert.Zero(t, getSockOpt(t, c.(syscall.Conn), unix.IPPROTO_TCP, unix.TCP_NODELAY))
+}
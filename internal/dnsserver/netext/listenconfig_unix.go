@@ -26,9 +26,10 @@ func newSetSockOptFunc(name string, lvl, opt, val int) (o setSockOptFunc) {
 }
 
 // listenControlWithSO is used as a [net.ListenConfig.Control] function to set
-// the SO_REUSEPORT, SO_SNDBUF, and SO_RCVBUF socket options on all sockets
-// used by the DNS servers in this package.  conf must not be nil.
-func listenControlWithSO(conf *ControlConfig, c syscall.RawConn) (err error) {
+// the SO_REUSEPORT, SO_SNDBUF, and SO_RCVBUF socket options on all sockets used
+// by the DNS servers in this package as well as the TCP_FASTOPEN option on the
+// TCP ones.  conf must not be nil.
+func listenControlWithSO(conf *ControlConfig, network string, c syscall.RawConn) (err error) {
 	opts := []setSockOptFunc{
 		newSetSockOptFunc("SO_REUSEPORT", unix.SOL_SOCKET, unix.SO_REUSEPORT, 1),
 	}
@@ -47,6 +48,10 @@ func listenControlWithSO(conf *ControlConfig, c syscall.RawConn) (err error) {
 		)
 	}
 
+	if conf.TCPFastOpenQueueLen > 0 && isTCPNetwork(network) {
+		opts = append(opts, newTFOSockOptFunc(conf.TCPFastOpenQueueLen))
+	}
+
 	var opErr error
 	err = c.Control(func(fd uintptr) {
 		fdInt := int(fd)
@@ -61,6 +66,16 @@ func listenControlWithSO(conf *ControlConfig, c syscall.RawConn) (err error) {
 	return errors.WithDeferred(opErr, err)
 }
 
+// isTCPNetwork returns true if network is one of the TCP networks.
+func isTCPNetwork(network string) (ok bool) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+		return true
+	default:
+		return false
+	}
+}
+
 // setIPOpts sets the IPv4 and IPv6 options on a packet connection.
 func setIPOpts(c net.PacketConn) (err error) {
 	// TODO(a.garipov): Returning an error only if both functions return one
@@ -9,9 +9,15 @@ import (
 
 // listenControlWithSO is nil on Windows, because it doesn't support socket
 // options.
-var listenControlWithSO func(_ *ControlConfig, _ syscall.RawConn) (_ error)
+var listenControlWithSO func(_ *ControlConfig, _ string, _ syscall.RawConn) (_ error)
 
 // setIPOpts sets the IPv4 and IPv6 options on a packet connection.
 func setIPOpts(c net.PacketConn) (err error) {
 	return nil
 }
+
+// isSYNData always returns false, since TCP Fast Open is currently only
+// supported on Linux.
+func isSYNData(_ *net.TCPConn) (ok bool) {
+	return false
+}
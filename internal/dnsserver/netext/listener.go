@@ -0,0 +1,86 @@
+package netext
+
+import (
+	"context"
+	"net"
+	"syscall"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// Metrics is the interface for the statistics of the connections accepted by
+// the listeners created by the [ListenConfig] implementations in this package.
+type Metrics interface {
+	// IncrementTFOAccepted is called when a TCP connection, the SYN segment of
+	// which carried data accepted using TCP Fast Open, is accepted.  ctx is the
+	// context passed to [ListenConfig.Listen].
+	IncrementTFOAccepted(ctx context.Context)
+}
+
+// EmptyMetrics is the implementation of the [Metrics] interface that does
+// nothing.
+type EmptyMetrics struct{}
+
+// type check
+var _ Metrics = EmptyMetrics{}
+
+// IncrementTFOAccepted implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) IncrementTFOAccepted(_ context.Context) {}
+
+// tcpListener is a wrapper around a TCP listener that applies the options from
+// the control configuration to the accepted connections.
+type tcpListener struct {
+	net.Listener
+
+	// ctx is the context passed to Listen.  It is only used for metrics.
+	ctx context.Context
+
+	// conf is the configuration of the socket options.  It must not be nil.
+	conf *ControlConfig
+}
+
+// type check
+var _ net.Listener = (*tcpListener)(nil)
+
+// Accept implements the [net.Listener] interface for *tcpListener.
+func (l *tcpListener) Accept() (c net.Conn, err error) {
+	c, err = l.Listener.Accept()
+	if err != nil {
+		// Don't wrap the error, because callers may check for
+		// [net.ErrClosed].
+		return nil, err
+	}
+
+	tc, ok := c.(*net.TCPConn)
+	if !ok {
+		return c, nil
+	}
+
+	if l.conf.DisableTCPNoDelay {
+		err = tc.SetNoDelay(false)
+		if err != nil {
+			// Don't return the error, since the connection is still usable.
+			return c, nil
+		}
+	}
+
+	if l.conf.TCPFastOpenQueueLen > 0 && isSYNData(tc) {
+		l.conf.metrics().IncrementTFOAccepted(l.ctx)
+	}
+
+	return c, nil
+}
+
+// type check
+var _ syscall.Conn = (*tcpListener)(nil)
+
+// SyscallConn implements the [syscall.Conn] interface for *tcpListener.  It
+// returns the raw connection of the underlying listener, if it has one.
+func (l *tcpListener) SyscallConn() (c syscall.RawConn, err error) {
+	sc, ok := l.Listener.(syscall.Conn)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+
+	return sc.SyscallConn()
+}
@@ -0,0 +1,33 @@
+package netext
+
+import (
+	"context"
+	"net/netip"
+)
+
+// PacketFilter is a kernel-level filter of the incoming packets, such as an XDP
+// program, which drops the packets from the banned subnets before they reach
+// the listeners.  All methods must be safe for concurrent use.  The
+// implementations must handle the errors, for example by logging and
+// collecting them, on their own.
+type PacketFilter interface {
+	// Ban makes the filter drop the packets from subnet.  subnet must be
+	// valid.
+	Ban(ctx context.Context, subnet netip.Prefix)
+
+	// Unban makes the filter stop dropping the packets from subnet.  subnet
+	// must be valid.
+	Unban(ctx context.Context, subnet netip.Prefix)
+}
+
+// EmptyPacketFilter is a [PacketFilter] that does nothing.
+type EmptyPacketFilter struct{}
+
+// type check
+var _ PacketFilter = EmptyPacketFilter{}
+
+// Ban implements the [PacketFilter] interface for EmptyPacketFilter.
+func (EmptyPacketFilter) Ban(_ context.Context, _ netip.Prefix) {}
+
+// Unban implements the [PacketFilter] interface for EmptyPacketFilter.
+func (EmptyPacketFilter) Unban(_ context.Context, _ netip.Prefix) {}
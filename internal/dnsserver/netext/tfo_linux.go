@@ -0,0 +1,36 @@
+//go:build linux
+
+package netext
+
+import (
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// tcpiOptSYNData is the TCPI_OPT_SYN_DATA flag of the tcpi_options field of
+// struct tcp_info, which is set when the data in the SYN segment has been
+// accepted using TCP Fast Open.
+const tcpiOptSYNData = 0x20
+
+// newTFOSockOptFunc returns a socket-option function that enables server-side
+// TCP Fast Open with the queue length qLen.
+func newTFOSockOptFunc(qLen int) (o setSockOptFunc) {
+	return newSetSockOptFunc("TCP_FASTOPEN", unix.IPPROTO_TCP, unix.TCP_FASTOPEN, qLen)
+}
+
+// isSYNData returns true if the SYN segment of c carried data that has been
+// accepted using TCP Fast Open.
+func isSYNData(c *net.TCPConn) (ok bool) {
+	sc, err := c.SyscallConn()
+	if err != nil {
+		return false
+	}
+
+	var info *unix.TCPInfo
+	err = sc.Control(func(fd uintptr) {
+		info, err = unix.GetsockoptTCPInfo(int(fd), unix.IPPROTO_TCP, unix.TCP_INFO)
+	})
+
+	return err == nil && info != nil && info.Options&tcpiOptSYNData != 0
+}
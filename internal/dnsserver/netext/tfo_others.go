@@ -0,0 +1,23 @@
+//go:build unix && !linux
+
+package netext
+
+import (
+	"net"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// newTFOSockOptFunc returns a socket-option function that returns an error,
+// since server-side TCP Fast Open is currently only supported on Linux.
+func newTFOSockOptFunc(_ int) (o setSockOptFunc) {
+	return func(_ int) (err error) {
+		return errors.Error("setting TCP_FASTOPEN: only supported on linux")
+	}
+}
+
+// isSYNData always returns false, since TCP Fast Open is currently only
+// supported on Linux.
+func isSYNData(_ *net.TCPConn) (ok bool) {
+	return false
+}
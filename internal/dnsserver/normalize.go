@@ -2,6 +2,7 @@ package dnsserver
 
 import (
 	"math/rand"
+	"slices"
 
 	"github.com/miekg/dns"
 )
@@ -17,15 +18,27 @@ var respPadBuf [responsePaddingMaxSize]byte
 // normalizeTCP adds an OPT record that reflects the intent from request over
 // TCP.  It also truncates and pads the response if needed.  When the request
 // was over TCP, we set the maximum allowed response size at 64K.
-func normalizeTCP(proto Protocol, req, resp *dns.Msg) {
-	normalize(NetworkTCP, proto, req, resp, dns.MaxMsgSize)
+func normalizeTCP(proto Protocol, req, resp *dns.Msg, padBlockSize uint16) {
+	normalize(NetworkTCP, proto, req, resp, dns.MaxMsgSize, padBlockSize)
 }
 
 // normalize adds an OPT record that reflects the intent from request.  It also
-// truncates and pads the response if needed.
+// truncates and pads the response if needed.  Following RFC 6891, the response
+// only contains an OPT record if the request does, and never more than one.
+// If padBlockSize is not zero, the response is padded to a multiple of it,
+// otherwise it is padded randomly.
 //
 // TODO(ameshkov): Consider adding EDNS0COOKIE support.
-func normalize(network Network, proto Protocol, req, resp *dns.Msg, maxMsgSize uint16) {
+func normalize(
+	network Network,
+	proto Protocol,
+	req *dns.Msg,
+	resp *dns.Msg,
+	maxMsgSize uint16,
+	padBlockSize uint16,
+) {
+	respOpt := removeOPT(resp)
+
 	reqOpt := req.IsEdns0()
 	if reqOpt == nil {
 		truncate(resp, maxDNSSize(network, 0, maxMsgSize))
@@ -34,9 +47,8 @@ func normalize(network Network, proto Protocol, req, resp *dns.Msg, maxMsgSize u
 		return
 	}
 
-	var respOpt *dns.OPT
 	ednsUDPSize := reqOpt.UDPSize()
-	if respOpt = resp.IsEdns0(); respOpt != nil {
+	if respOpt != nil {
 		respOpt.Hdr.Name = "."
 		respOpt.Hdr.Rrtype = dns.TypeOPT
 		respOpt.SetVersion(0)
@@ -61,9 +73,10 @@ func normalize(network Network, proto Protocol, req, resp *dns.Msg, maxMsgSize u
 			},
 			Option: filterUnsupportedOptions(reqOpt.Option),
 		}
-		resp.Extra = append(resp.Extra, respOpt)
 	}
 
+	resp.Extra = append(resp.Extra, respOpt)
+
 	// Make sure that we don't send messages larger than the protocol supports.
 	truncate(resp, maxDNSSize(network, ednsUDPSize, maxMsgSize))
 
@@ -72,10 +85,25 @@ func normalize(network Network, proto Protocol, req, resp *dns.Msg, maxMsgSize u
 
 	// In the case of encrypted protocols we should pad responses.
 	if proto.HasPaddingSupport() {
-		padAnswer(reqOpt, respOpt)
+		padAnswer(resp, reqOpt, respOpt, padBlockSize)
 	}
 }
 
+// removeOPT removes all OPT records from the additional section of resp and
+// returns the last one, which is the one [dns.Msg.IsEdns0] returns, if any.
+func removeOPT(resp *dns.Msg) (opt *dns.OPT) {
+	resp.Extra = slices.DeleteFunc(resp.Extra, func(rr dns.RR) (ok bool) {
+		o, isOPT := rr.(*dns.OPT)
+		if isOPT {
+			opt = o
+		}
+
+		return isOPT
+	})
+
+	return opt
+}
+
 // truncate makes sure the response is not larger than the specified size.  If
 // it is, the Truncate flag is set to true and answer records are removed.
 func truncate(resp *dns.Msg, size int) {
@@ -121,8 +149,9 @@ func filterUnsupportedOptions(o []dns.EDNS0) (supported []dns.EDNS0) {
 
 // padAnswer adds padding to a DNS response before it's sent back over an
 // encrypted DNS protocol according to RFC 8467.  Unencrypted responses should
-// not be padded.  Inspired by github.com/folbricht/routedns padding.
-func padAnswer(reqOpt, respOpt *dns.OPT) {
+// not be padded.  If blockSize is not zero, the Block-Length Padding strategy
+// is used.  Inspired by github.com/folbricht/routedns padding.
+func padAnswer(resp *dns.Msg, reqOpt, respOpt *dns.OPT, blockSize uint16) {
 	if findOption[*dns.EDNS0_PADDING](reqOpt) == nil {
 		// According to the RFC, responders MAY (or may not) pad responses when
 		// the padding option is not included in the request.  In our case, we
@@ -142,6 +171,12 @@ func padAnswer(reqOpt, respOpt *dns.OPT) {
 		respOpt.Option = append(respOpt.Option, paddingOpt)
 	}
 
+	if blockSize > 0 {
+		paddingOpt.Padding = blockPadding(resp.Len(), int(blockSize))
+
+		return
+	}
+
 	// TODO(ameshkov): Consider changing to crypto/rand, need to hold a vote.
 	// #nosec G404 -- We don't need a real random for a simple padding
 	// randomization, pseudo-random is enough.
@@ -157,6 +192,19 @@ func padAnswer(reqOpt, respOpt *dns.OPT) {
 	paddingOpt.Padding = respPadBuf[:padLen:padLen]
 }
 
+// blockPadding returns the padding for a message of msgLen bytes, which already
+// includes an empty padding option, so that its length is a multiple of
+// blockSize.  The padding is empty if the padded message would exceed
+// [dns.MaxMsgSize].  blockSize must be positive.
+func blockPadding(msgLen, blockSize int) (padding []byte) {
+	padLen := (blockSize - msgLen%blockSize) % blockSize
+	if padLen == 0 || msgLen+padLen > dns.MaxMsgSize {
+		return nil
+	}
+
+	return make([]byte, padLen)
+}
+
 // findOption searches for the specified EDNS0 option in the OPT resource record
 // and returns it or nil if it's not present.
 //
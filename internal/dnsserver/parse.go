@@ -0,0 +1,49 @@
+package dnsserver
+
+import (
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// ErrIgnoredMsg is returned by [ParseRequest] when the servers ignore the
+// message instead of responding to it.
+const ErrIgnoredMsg errors.Error = "message ignored"
+
+// ParseRequest parses b and checks the result in the same way the servers do
+// before passing the request to the handler.  err is not nil if b is not a
+// valid DNS message or if the servers ignore it.  errResp is not nil if the
+// servers respond to req with an error response without calling the handler.
+//
+// ParseRequest is deterministic, which makes it suitable for fuzzing together
+// with [NormalizeResponse].
+func ParseRequest(b []byte) (req, errResp *dns.Msg, err error) {
+	req = &dns.Msg{}
+	err = req.Unpack(b)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unpacking: %w", err)
+	}
+
+	switch action := acceptMsg(req); action {
+	case dns.MsgAccept:
+		return req, nil, nil
+	case dns.MsgReject:
+		return req, genErrorResponse(req, dns.RcodeFormatError), nil
+	case dns.MsgRejectNotImplemented:
+		return req, genErrorResponse(req, dns.RcodeNotImplemented), nil
+	default:
+		return nil, nil, ErrIgnoredMsg
+	}
+}
+
+// NormalizeResponse normalizes resp for req the same way the servers do
+// before writing it to a client over network using proto.  maxUDPSize is the
+// maximum size of a response over UDP, it is ignored for other networks.
+//
+// resp is modified: the OPT record is added or adjusted according to the one in
+// req, and the response is truncated and padded, if necessary.  All changes
+// are deterministic except for the length of the padding, which is random.
+func NormalizeResponse(network Network, proto Protocol, req, resp *dns.Msg, maxUDPSize uint16) {
+	normalize(network, proto, req, resp, maxUDPSize, 0)
+}
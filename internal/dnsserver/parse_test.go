@@ -0,0 +1,140 @@
+package dnsserver_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func FuzzParseRequest(f *testing.F) {
+	for _, req := range []*dns.Msg{
+		dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET),
+		dnsservertest.NewReq("example.org.", dns.TypeAAAA, dns.ClassINET, dnsservertest.SectionExtra{
+			dnsservertest.NewOPT(true, dns.MaxMsgSize, &dns.EDNS0_PADDING{}),
+		}),
+		{Question: []dns.Question{}},
+	} {
+		b, err := req.Pack()
+		require.NoError(f, err)
+
+		f.Add(b)
+	}
+
+	f.Fuzz(func(t *testing.T, input []byte) {
+		req, errResp, err := dnsserver.ParseRequest(input)
+		if err != nil {
+			assert.Nil(t, req)
+			assert.Nil(t, errResp)
+
+			return
+		}
+
+		require.NotNil(t, req)
+
+		if errResp != nil {
+			assert.True(t, errResp.Response)
+			assert.NotEqual(t, dns.RcodeSuccess, errResp.Rcode)
+
+			return
+		}
+
+		assert.Len(t, req.Question, 1)
+		assert.False(t, req.Response)
+	})
+}
+
+func FuzzNormalizeResponse(f *testing.F) {
+	const fqdn = "example.org."
+
+	req := dnsservertest.NewReq(fqdn, dns.TypeA, dns.ClassINET)
+	reqEDNS := dnsservertest.NewReq(fqdn, dns.TypeA, dns.ClassINET, dnsservertest.SectionExtra{
+		dnsservertest.NewOPT(true, dns.MaxMsgSize, &dns.EDNS0_PADDING{}),
+	})
+
+	var ans dnsservertest.SectionAnswer
+	for i := range 64 {
+		ans = append(ans, dnsservertest.NewA(fqdn, 60, netip.AddrFrom4([4]byte{192, 0, 2, byte(i)})))
+	}
+
+	respOPT := dnsservertest.NewResp(dns.RcodeSuccess, req, ans, dnsservertest.SectionExtra{
+		dnsservertest.NewOPT(true, dns.MaxMsgSize),
+		dnsservertest.NewOPT(false, dns.MinMsgSize),
+	})
+
+	for _, tc := range []struct {
+		req  *dns.Msg
+		resp *dns.Msg
+	}{{
+		req:  req,
+		resp: dnsservertest.NewResp(dns.RcodeSuccess, req, ans),
+	}, {
+		req:  req,
+		resp: respOPT,
+	}, {
+		req:  reqEDNS,
+		resp: respOPT,
+	}} {
+		reqData, err := tc.req.Pack()
+		require.NoError(f, err)
+
+		respData, err := tc.resp.Pack()
+		require.NoError(f, err)
+
+		f.Add(reqData, respData, true, uint16(dns.MinMsgSize))
+		f.Add(reqData, respData, false, uint16(dns.MaxMsgSize))
+	}
+
+	f.Fuzz(func(t *testing.T, reqData, respData []byte, isUDP bool, maxUDPSize uint16) {
+		req, errResp, err := dnsserver.ParseRequest(reqData)
+		if err != nil || errResp != nil {
+			return
+		}
+
+		resp := &dns.Msg{}
+		err = resp.Unpack(respData)
+		if err != nil {
+			return
+		}
+
+		network, proto := dnsserver.NetworkTCP, dnsserver.ProtoDoT
+		if isUDP {
+			network, proto = dnsserver.NetworkUDP, dnsserver.ProtoDNS
+		}
+
+		dnsserver.NormalizeResponse(network, proto, req, resp, maxUDPSize)
+
+		b, err := resp.Pack()
+		require.NoError(t, err)
+
+		reqOpt := req.IsEdns0()
+		if isUDP {
+			limit := dns.MinMsgSize
+			if reqOpt != nil {
+				limit = max(int(min(reqOpt.UDPSize(), maxUDPSize)), dns.MinMsgSize)
+			}
+
+			assert.LessOrEqual(t, len(b), limit)
+		}
+
+		unpacked := &dns.Msg{}
+		require.NoError(t, unpacked.Unpack(b))
+
+		var numOPT int
+		for _, rr := range unpacked.Extra {
+			if _, ok := rr.(*dns.OPT); ok {
+				numOPT++
+			}
+		}
+
+		if reqOpt == nil {
+			assert.Zero(t, numOPT)
+		} else {
+			assert.Equal(t, 1, numOPT)
+		}
+	})
+}
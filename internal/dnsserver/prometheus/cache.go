@@ -2,11 +2,13 @@ package prometheus
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/cache"
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
 	"github.com/miekg/dns"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 // cacheTypeDefault is a "type" label value for the default LRU cache.  In the
@@ -22,33 +24,65 @@ type CacheMetricsListener struct {
 }
 
 // NewCacheMetricsListener returns a new properly initialized
-// *CacheMetricsListener.  As long as this function registers prometheus
-// counters it must be called only once.
-//
-// TODO(a.garipov): Do not use promauto.
-func NewCacheMetricsListener(namespace string) *CacheMetricsListener {
-	return &CacheMetricsListener{
-		cacheSize: promauto.NewGaugeVec(prometheus.GaugeOpts{
-			Name:      "size",
+// *CacheMetricsListener with the metrics registered in reg, which must not be
+// nil.
+func NewCacheMetricsListener(
+	namespace string,
+	reg prometheus.Registerer,
+) (l *CacheMetricsListener, err error) {
+	const (
+		cacheSize   = "size"
+		hitsTotal   = "hits_total"
+		missesTotal = "misses_total"
+	)
+
+	l = &CacheMetricsListener{
+		cacheSize: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:      cacheSize,
 			Namespace: namespace,
 			Subsystem: subsystemCache,
 			Help:      "The total number items in the cache.",
 		}, []string{"type"}),
 
-		hitsTotal: promauto.NewCounterVec(prometheus.CounterOpts{
-			Name:      "hits_total",
+		hitsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      hitsTotal,
 			Namespace: namespace,
 			Subsystem: subsystemCache,
 			Help:      "The total number of cache hits.",
 		}, []string{"type"}),
 
-		missesTotal: promauto.NewCounterVec(prometheus.CounterOpts{
-			Name:      "misses_total",
+		missesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      missesTotal,
 			Namespace: namespace,
 			Subsystem: subsystemCache,
 			Help:      "The total number of cache misses.",
 		}, []string{"type"}),
 	}
+
+	var errs []error
+	collectors := container.KeyValues[string, prometheus.Collector]{{
+		Key:   cacheSize,
+		Value: l.cacheSize,
+	}, {
+		Key:   hitsTotal,
+		Value: l.hitsTotal,
+	}, {
+		Key:   missesTotal,
+		Value: l.missesTotal,
+	}}
+
+	for _, c := range collectors {
+		err = reg.Register(c.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("registering metrics %q: %w", c.Key, err))
+		}
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return l, nil
 }
 
 // type check
@@ -10,6 +10,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/prometheus"
 	"github.com/miekg/dns"
+	promclient "github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,8 +18,14 @@ import (
 // normal unit test, we create a cache middleware, emulate a query and then
 // check if prom metrics were incremented.
 func TestCacheMetricsListener_integration_cache(t *testing.T) {
+	mtrcListener, err := prometheus.NewCacheMetricsListener(
+		testNamespace,
+		promclient.DefaultRegisterer,
+	)
+	require.NoError(t, err)
+
 	cacheMiddleware := cache.NewMiddleware(&cache.MiddlewareConfig{
-		MetricsListener: prometheus.NewCacheMetricsListener(testNamespace),
+		MetricsListener: mtrcListener,
 		Count:           100,
 	})
 
@@ -39,7 +46,7 @@ func TestCacheMetricsListener_integration_cache(t *testing.T) {
 
 		req := dnsservertest.CreateMessage(testReqDomain, dns.TypeA)
 
-		err := handlerWithMiddleware.ServeDNS(ctx, nrw, req)
+		err = handlerWithMiddleware.ServeDNS(ctx, nrw, req)
 		require.NoError(t, err)
 		dnsservertest.RequireResponse(t, req, nrw.Msg(), 1, dns.RcodeSuccess, false)
 	}
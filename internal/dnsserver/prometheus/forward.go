@@ -21,6 +21,8 @@ type ForwardMetricsListener struct {
 	requestDuration *prometheus.HistogramVec
 	errorsTotal     *prometheus.CounterVec
 	upstreamStatus  *prometheus.GaugeVec
+	circuitState    *prometheus.GaugeVec
+	loopsTotal      prometheus.Counter
 
 	// mu protects statusGauges.
 	mu *sync.Mutex
@@ -72,6 +74,21 @@ func NewForwardMetricsListener(namespace string, upsNumHint int) (f *ForwardMetr
 			Help:      "Status of the main upstream. 1 is okay, 0 the upstream is backed off",
 		}, []string{"to", "type"}),
 
+		circuitState: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name:      "upstream_group_circuit_state",
+			Namespace: namespace,
+			Subsystem: subsystemForward,
+			Help: "State of the circuit breaker of the upstream group. " +
+				"0 is closed, 1 is half-open, 2 is open.",
+		}, []string{"group"}),
+
+		loopsTotal: promauto.NewCounter(prometheus.CounterOpts{
+			Name:      "loop_detected_total",
+			Namespace: namespace,
+			Subsystem: subsystemForward,
+			Help:      "The number of DNS requests that were forwarded back to this server by the upstreams.",
+		}),
+
 		mu: &sync.Mutex{},
 
 		statusGauges: make(map[forward.Upstream]prometheus.Gauge, upsNumHint),
@@ -140,6 +157,18 @@ func (f *ForwardMetricsListener) OnUpstreamStatusChanged(ups forward.Upstream, i
 	setBoolGauge(gauge, isUp)
 }
 
+// OnLoopDetected implements the [forward.MetricsListener] interface for
+// *ForwardMetricsListener.
+func (f *ForwardMetricsListener) OnLoopDetected(_ context.Context, _ *dns.Msg) {
+	f.loopsTotal.Inc()
+}
+
+// OnCircuitStateChanged implements the [forward.MetricsListener] interface for
+// *ForwardMetricsListener.
+func (f *ForwardMetricsListener) OnCircuitStateChanged(group string, state forward.CircuitState) {
+	f.circuitState.WithLabelValues(group).Set(float64(state))
+}
+
 // errorType returns the human-readable type of error for the metrics.
 func errorType(err error) (typ string) {
 	var netErr net.Error
@@ -100,3 +100,12 @@ func setBoolGauge(gauge prometheus.Gauge, val bool) {
 		gauge.Set(0)
 	}
 }
+
+// boolString returns "1" if cond is true and "0" otherwise.
+func boolString(cond bool) (s string) {
+	if cond {
+		return "1"
+	}
+
+	return "0"
+}
@@ -0,0 +1,48 @@
+package prometheus
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
+	"github.com/AdguardTeam/golibs/syncutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// NetextMetricsListener implements the [netext.Metrics] interface and
+// increments prom counters.
+type NetextMetricsListener struct {
+	tfoAcceptedCounters *syncutil.OnceConstructor[dnsserver.ServerInfo, prometheus.Counter]
+}
+
+// NewNetextMetricsListener returns a new properly initialized
+// *NetextMetricsListener.  As long as this function registers prometheus
+// counters it must be called only once.
+//
+// TODO: Do not use promauto.
+func NewNetextMetricsListener(namespace string) (l *NetextMetricsListener) {
+	tfoAcceptedTotal := promauto.NewCounterVec(prometheus.CounterOpts{
+		Name:      "tfo_accepted_total",
+		Namespace: namespace,
+		Subsystem: subsystemServer,
+		Help:      "The total number of TCP connections accepted using TCP Fast Open.",
+	}, []string{"name", "proto", "addr"})
+
+	return &NetextMetricsListener{
+		tfoAcceptedCounters: syncutil.NewOnceConstructor(
+			func(k dnsserver.ServerInfo) (c prometheus.Counter) {
+				return tfoAcceptedTotal.WithLabelValues(k.Name, k.Proto.String(), k.Addr)
+			},
+		),
+	}
+}
+
+// type check
+var _ netext.Metrics = (*NetextMetricsListener)(nil)
+
+// IncrementTFOAccepted implements the [netext.Metrics] interface for
+// *NetextMetricsListener.  ctx must contain a [dnsserver.ServerInfo].
+func (l *NetextMetricsListener) IncrementTFOAccepted(ctx context.Context) {
+	l.tfoAcceptedCounters.Get(*dnsserver.MustServerInfoFromContext(ctx)).Inc()
+}
@@ -43,6 +43,9 @@ forward.MetricsListener metrics:
   - "dns_forward_request_duration_seconds" is a histogram with request
     durations.
   - "dns_forward_error_total" is the number of errors occurred.
+  - "dns_forward_upstream_group_circuit_state" is the state of the circuit
+    breaker of an upstream group: 0 is closed, 1 is half-open, 2 is open.
+    There's a single label: the group name.
 
 cache.MetricsListener metrics:
 
@@ -55,6 +58,12 @@ ratelimit.MetricsListener metrics:
   - "dns_ratelimit_dropped_total" is the total number of rate-limited DNS
     queries.
 
+netext.Metrics metrics:
+
+  - "dns_server_tfo_accepted_total" is the total number of TCP connections
+    accepted using TCP Fast Open.  It has the same basic labels as the
+    dnsserver.MetricsListener metrics.
+
 TODO(a.garipov):  Update the docs.
 */
 package prometheus
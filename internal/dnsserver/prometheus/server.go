@@ -2,10 +2,10 @@ package prometheus
 
 import (
 	"context"
-	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/golibs/syncutil"
+	"github.com/miekg/dns"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -20,6 +20,8 @@ type ServerMetricsListener struct {
 
 	respRCodeCounters *syncutil.OnceConstructor[srvInfoRCode, prometheus.Counter]
 
+	respTruncatedCounters *syncutil.OnceConstructor[dnsserver.ServerInfo, prometheus.Counter]
+
 	invalidMsgCounters *syncutil.OnceConstructor[dnsserver.ServerInfo, prometheus.Counter]
 	errorCounters      *syncutil.OnceConstructor[dnsserver.ServerInfo, prometheus.Counter]
 	panicCounters      *syncutil.OnceConstructor[dnsserver.ServerInfo, prometheus.Counter]
@@ -27,6 +29,13 @@ type ServerMetricsListener struct {
 	reqDurationHistograms *syncutil.OnceConstructor[dnsserver.ServerInfo, prometheus.Observer]
 	reqSizeHistograms     *syncutil.OnceConstructor[dnsserver.ServerInfo, prometheus.Observer]
 	respSizeHistograms    *syncutil.OnceConstructor[dnsserver.ServerInfo, prometheus.Observer]
+	respPadHistograms     *syncutil.OnceConstructor[dnsserver.ServerInfo, prometheus.Observer]
+
+	wsConnQueriesHistograms *syncutil.OnceConstructor[dnsserver.ServerInfo, prometheus.Observer]
+	wsConnLimitedCounters   *syncutil.OnceConstructor[dnsserver.ServerInfo, prometheus.Counter]
+
+	httpLimitCounters       *syncutil.OnceConstructor[srvInfoHTTPLimit, prometheus.Counter]
+	httpStreamResetCounters *syncutil.OnceConstructor[dnsserver.ServerInfo, prometheus.Counter]
 }
 
 // srvInfoRCode is a struct containing the server information along with a
@@ -52,6 +61,32 @@ func (i srvInfoRCode) withLabelValues(vec *prometheus.CounterVec) (c prometheus.
 	)
 }
 
+// srvInfoHTTPLimit is a struct containing the server information along with the
+// exceeded HTTP limit.
+type srvInfoHTTPLimit struct {
+	limit dnsserver.HTTPLimit
+	dnsserver.ServerInfo
+	closed bool
+}
+
+// withLabelValues returns a counter with the server info and limit data in the
+// correct order.
+func (i srvInfoHTTPLimit) withLabelValues(vec *prometheus.CounterVec) (c prometheus.Counter) {
+	// The labels must be in the following order:
+	//   1. server name;
+	//   2. server protocol;
+	//   3. server addr;
+	//   4. limit;
+	//   5. closed.
+	return vec.WithLabelValues(
+		i.Name,
+		i.Proto.String(),
+		i.Addr,
+		string(i.limit),
+		boolString(i.closed),
+	)
+}
+
 // NewServerMetricsListener returns a new properly initialized
 // *ServerMetricsListener.  As long as this function registers prometheus
 // counters it must be called only once.
@@ -93,6 +128,16 @@ func NewServerMetricsListener(namespace string) (l *ServerMetricsListener) {
 			},
 		}, []string{"name", "proto", "addr"})
 
+		responsePadding = promauto.NewHistogramVec(prometheus.HistogramOpts{
+			Name:      "response_padding_bytes",
+			Namespace: namespace,
+			Subsystem: subsystemServer,
+			Help:      "The size of the EDNS(0) padding of DNS responses.",
+			Buckets: []float64{
+				0, 8, 16, 32, 64, 128, 256, 468, 1024,
+			},
+		}, []string{"name", "proto", "addr"})
+
 		responseRCode = promauto.NewCounterVec(prometheus.CounterOpts{
 			Name:      "response_rcode_total",
 			Namespace: namespace,
@@ -100,6 +145,13 @@ func NewServerMetricsListener(namespace string) (l *ServerMetricsListener) {
 			Help:      "The counter for DNS response codes.",
 		}, []string{"name", "proto", "addr", "rcode"})
 
+		responseTruncatedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+			Name:      "response_truncated_total",
+			Namespace: namespace,
+			Subsystem: subsystemServer,
+			Help:      "The number of DNS responses with the TC flag set.",
+		}, []string{"name", "proto", "addr"})
+
 		errorTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 			Name:      "error_total",
 			Namespace: namespace,
@@ -122,6 +174,39 @@ func NewServerMetricsListener(namespace string) (l *ServerMetricsListener) {
 		}, []string{"name", "proto", "addr"})
 	)
 
+	wsConnQueries := promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:      "websocket_conn_queries",
+		Namespace: namespace,
+		Subsystem: subsystemServer,
+		Help:      "The number of DNS queries received over a closed DNS-over-WebSocket connection.",
+		Buckets: []float64{
+			0, 1, 2, 5, 10, 20, 50, 100, 200, 500, 1000,
+		},
+	}, []string{"name", "proto", "addr"})
+
+	wsConnLimitedTotal := promauto.NewCounterVec(prometheus.CounterOpts{
+		Name:      "websocket_conn_limited_total",
+		Namespace: namespace,
+		Subsystem: subsystemServer,
+		Help:      "The number of DNS-over-WebSocket connections closed due to the query limit.",
+	}, []string{"name", "proto", "addr"})
+
+	httpLimitExceededTotal := promauto.NewCounterVec(prometheus.CounterOpts{
+		Name:      "http_limit_exceeded_total",
+		Namespace: namespace,
+		Subsystem: subsystemServer,
+		Help: "The number of DoH requests that exceeded a limit of the server. " +
+			"closed=1 means that the connection was closed.",
+	}, []string{"name", "proto", "addr", "limit", "closed"})
+
+	httpStreamResetsTotal := promauto.NewCounterVec(prometheus.CounterOpts{
+		Name:      "http_stream_resets_total",
+		Namespace: namespace,
+		Subsystem: subsystemServer,
+		Help: "The number of HTTP/2 and HTTP/3 streams of DoH requests reset by " +
+			"the client before the response was written.",
+	}, []string{"name", "proto", "addr"})
+
 	quicAddrValidationCacheLookups := promauto.NewCounterVec(prometheus.CounterOpts{
 		Name:      "quic_addr_validation_lookups",
 		Namespace: namespace,
@@ -146,6 +231,12 @@ func NewServerMetricsListener(namespace string) (l *ServerMetricsListener) {
 			},
 		),
 
+		respTruncatedCounters: syncutil.NewOnceConstructor(
+			func(k dnsserver.ServerInfo) (c prometheus.Counter) {
+				return withSrvInfoLabelValues(responseTruncatedTotal, k)
+			},
+		),
+
 		invalidMsgCounters: syncutil.NewOnceConstructor(
 			func(k dnsserver.ServerInfo) (c prometheus.Counter) {
 				return withSrvInfoLabelValues(invalidMsgTotal, k)
@@ -177,6 +268,34 @@ func NewServerMetricsListener(namespace string) (l *ServerMetricsListener) {
 				return withSrvInfoLabelValues(responseSize, k)
 			},
 		),
+		respPadHistograms: syncutil.NewOnceConstructor(
+			func(k dnsserver.ServerInfo) (o prometheus.Observer) {
+				return withSrvInfoLabelValues(responsePadding, k)
+			},
+		),
+
+		wsConnQueriesHistograms: syncutil.NewOnceConstructor(
+			func(k dnsserver.ServerInfo) (o prometheus.Observer) {
+				return withSrvInfoLabelValues(wsConnQueries, k)
+			},
+		),
+		wsConnLimitedCounters: syncutil.NewOnceConstructor(
+			func(k dnsserver.ServerInfo) (c prometheus.Counter) {
+				return withSrvInfoLabelValues(wsConnLimitedTotal, k)
+			},
+		),
+
+		httpLimitCounters: syncutil.NewOnceConstructor(
+			func(k srvInfoHTTPLimit) (c prometheus.Counter) {
+				return k.withLabelValues(httpLimitExceededTotal)
+			},
+		),
+
+		httpStreamResetCounters: syncutil.NewOnceConstructor(
+			func(k dnsserver.ServerInfo) (c prometheus.Counter) {
+				return withSrvInfoLabelValues(httpStreamResetsTotal, k)
+			},
+		),
 	}
 }
 
@@ -200,7 +319,7 @@ func (l *ServerMetricsListener) OnRequest(
 	l.reqSizeHistograms.Get(serverInfo).Observe(float64(info.RequestSize))
 
 	// Increment request duration histogram.
-	elapsed := time.Since(ri.StartTime).Seconds()
+	elapsed := ri.Elapsed().Seconds()
 	l.reqDurationHistograms.Get(serverInfo).Observe(elapsed)
 
 	// If resp is not nil, increment response-related metrics.
@@ -210,6 +329,14 @@ func (l *ServerMetricsListener) OnRequest(
 			ServerInfo: serverInfo,
 			rCode:      rCodeToString(resp.Rcode),
 		}).Inc()
+
+		if resp.Truncated {
+			l.respTruncatedCounters.Get(serverInfo).Inc()
+		}
+
+		if padLen, ok := paddingLen(resp); ok {
+			l.respPadHistograms.Get(serverInfo).Observe(float64(padLen))
+		}
 	} else {
 		// If resp is nil, increment responseRCode with a special "rcode" label
 		// value ("DROPPED").
@@ -247,3 +374,54 @@ func (l *ServerMetricsListener) OnQUICAddressValidation(hit bool) {
 		l.quicAddrValidationCacheLookupsMisses.Inc()
 	}
 }
+
+// OnWebSocketConnClosed implements the [dnsserver.MetricsListener] interface
+// for [*ServerMetricsListener].
+func (l *ServerMetricsListener) OnWebSocketConnClosed(
+	ctx context.Context,
+	queries uint,
+	limited bool,
+) {
+	serverInfo := *dnsserver.MustServerInfoFromContext(ctx)
+	l.wsConnQueriesHistograms.Get(serverInfo).Observe(float64(queries))
+	if limited {
+		l.wsConnLimitedCounters.Get(serverInfo).Inc()
+	}
+}
+
+// OnHTTPLimitExceeded implements the [dnsserver.MetricsListener] interface for
+// [*ServerMetricsListener].
+func (l *ServerMetricsListener) OnHTTPLimitExceeded(
+	ctx context.Context,
+	limit dnsserver.HTTPLimit,
+	closed bool,
+) {
+	l.httpLimitCounters.Get(srvInfoHTTPLimit{
+		ServerInfo: *dnsserver.MustServerInfoFromContext(ctx),
+		limit:      limit,
+		closed:     closed,
+	}).Inc()
+}
+
+// OnHTTPStreamReset implements the [dnsserver.MetricsListener] interface for
+// [*ServerMetricsListener].
+func (l *ServerMetricsListener) OnHTTPStreamReset(ctx context.Context) {
+	l.httpStreamResetCounters.Get(*dnsserver.MustServerInfoFromContext(ctx)).Inc()
+}
+
+// paddingLen returns the length of the EDNS(0) padding of msg.  ok is false if
+// msg has no padding option.
+func paddingLen(msg *dns.Msg) (n int, ok bool) {
+	opt := msg.IsEdns0()
+	if opt == nil {
+		return 0, false
+	}
+
+	for _, o := range opt.Option {
+		if p, isPad := o.(*dns.EDNS0_PADDING); isPad {
+			return len(p.Padding), true
+		}
+	}
+
+	return 0, false
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"io"
 	"strings"
-	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/golibs/log"
@@ -87,7 +86,7 @@ func (l *LogMiddleware) Wrap(h dnsserver.Handler) (wrapped dnsserver.Handler) {
 		sb.WriteString(fmt.Sprintf("%d %d ", rcode, rsize))
 
 		// Duration
-		elapsed := time.Since(requestInfo.StartTime)
+		elapsed := requestInfo.Elapsed()
 		sb.WriteString(fmt.Sprintf("%s\n", elapsed))
 
 		// Suppress errors, it's not that important for a query log
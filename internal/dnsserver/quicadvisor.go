@@ -0,0 +1,96 @@
+package dnsserver
+
+import (
+	"context"
+	"net"
+	"net/netip"
+
+	"github.com/AdguardTeam/golibs/netutil"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/logging"
+)
+
+// QUICAdvisor observes the outcomes of the QUIC handshakes and decides whether
+// the QUIC-based protocols should be advertised to the clients.  It is used to
+// steer the clients from the networks where QUIC is blocked to the TCP-based
+// protocols.
+type QUICAdvisor interface {
+	// OnQUICHandshake is called when a QUIC connection from addr either
+	// completes its handshake or is closed before that.  ok is true if the
+	// handshake has been completed.
+	OnQUICHandshake(ctx context.Context, addr netip.Addr, ok bool)
+
+	// IsQUICBlocked returns true if the QUIC-based protocols should not be
+	// advertised to the client with the address addr.
+	IsQUICBlocked(ctx context.Context, addr netip.Addr) (blocked bool)
+}
+
+// EmptyQUICAdvisor is the [QUICAdvisor] implementation that does nothing.
+type EmptyQUICAdvisor struct{}
+
+// type check
+var _ QUICAdvisor = EmptyQUICAdvisor{}
+
+// OnQUICHandshake implements the [QUICAdvisor] interface for EmptyQUICAdvisor.
+func (EmptyQUICAdvisor) OnQUICHandshake(_ context.Context, _ netip.Addr, _ bool) {}
+
+// IsQUICBlocked implements the [QUICAdvisor] interface for EmptyQUICAdvisor.
+// It always returns false.
+func (EmptyQUICAdvisor) IsQUICBlocked(_ context.Context, _ netip.Addr) (blocked bool) {
+	return false
+}
+
+// setQUICAdvisorTracer sets the connection tracer reporting the outcomes of the
+// handshakes to a.  If a is nil, conf is not changed.  conf must not be nil.
+func setQUICAdvisorTracer(conf *quic.Config, a QUICAdvisor) {
+	if a == nil {
+		return
+	}
+
+	conf.Tracer = func(
+		ctx context.Context,
+		p logging.Perspective,
+		_ quic.ConnectionID,
+	) (t *logging.ConnectionTracer) {
+		if p != logging.PerspectiveServer {
+			return nil
+		}
+
+		return newQUICAdvisorTracer(ctx, a)
+	}
+}
+
+// newQUICAdvisorTracer returns a new connection tracer that reports the outcome
+// of the handshake of the connection to a.  a must not be nil.
+//
+// NOTE:  The callbacks of a connection tracer are called from the goroutine of
+// the connection, so the state is not protected.
+func newQUICAdvisorTracer(ctx context.Context, a QUICAdvisor) (t *logging.ConnectionTracer) {
+	var (
+		addr       netip.Addr
+		isComplete bool
+	)
+
+	return &logging.ConnectionTracer{
+		StartedConnection: func(_, remote net.Addr, _, _ logging.ConnectionID) {
+			addr = netutil.NetAddrToAddrPort(remote).Addr().Unmap()
+		},
+		DroppedEncryptionLevel: func(l logging.EncryptionLevel) {
+			// A server drops the keys of the handshake encryption level once
+			// the handshake is complete.
+			if l != logging.EncryptionHandshake || isComplete || !addr.IsValid() {
+				return
+			}
+
+			isComplete = true
+			a.OnQUICHandshake(ctx, addr, true)
+		},
+		ClosedConnection: func(_ error) {
+			if isComplete || !addr.IsValid() {
+				return
+			}
+
+			a.OnQUICHandshake(ctx, addr, false)
+		},
+	}
+}
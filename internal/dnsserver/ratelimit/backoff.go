@@ -1,12 +1,15 @@
 package ratelimit
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/netip"
 	"sync/atomic"
 	"time"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
 	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/c2h5oh/datasize"
 	"github.com/miekg/dns"
@@ -18,6 +21,15 @@ type BackoffConfig struct {
 	// Allowlist defines which IP networks are excluded from rate limiting.
 	Allowlist Allowlist
 
+	// Metrics is a listener for the rate limiter events.  If nil,
+	// [EmptyBackoffMetrics] is used.
+	Metrics BackoffMetrics
+
+	// PacketFilter, if not nil, is used to drop the packets from the subnets
+	// in the backoff state in the kernel.  The subnets are banned once they
+	// enter the backoff state and unbanned once their backoff counters expire.
+	PacketFilter netext.PacketFilter
+
 	// Period is the time during which the rate limiter counts the number of
 	// times a client make more requests than RPS allows to increment the
 	// backoff count for the client.
@@ -46,6 +58,12 @@ type BackoffConfig struct {
 	// for IPv4 addresses.
 	IPv4Interval time.Duration
 
+	// IPv4Bandwidth is the maximum number of bytes of responses per second
+	// allowed to a single subnet for IPv4 addresses.  Any requests above this
+	// rate are dropped and counted as the client's backoff count.  If it is
+	// zero, the bandwidth is not limited.
+	IPv4Bandwidth datasize.ByteSize
+
 	// IPv4SubnetKeyLen is the length of the subnet prefix used to calculate
 	// rate limiter bucket keys for IPv4 addresses.  Must be greater than zero.
 	IPv4SubnetKeyLen int
@@ -60,6 +78,12 @@ type BackoffConfig struct {
 	// for IPv6 addresses.
 	IPv6Interval time.Duration
 
+	// IPv6Bandwidth is the maximum number of bytes of responses per second
+	// allowed to a single subnet for IPv6 addresses.  Any requests above this
+	// rate are dropped and counted as the client's backoff count.  If it is
+	// zero, the bandwidth is not limited.
+	IPv6Bandwidth datasize.ByteSize
+
 	// IPv6SubnetKeyLen is the length of the subnet prefix used to calculate
 	// rate limiter bucket keys for IPv6 addresses.  Must be greater than zero.
 	IPv6SubnetKeyLen int
@@ -82,14 +106,19 @@ type BackoffConfig struct {
 type Backoff struct {
 	reqCounters      *cache.Cache
 	hitCounters      *cache.Cache
+	bwCounters       *cache.Cache
 	allowlist        Allowlist
+	metrics          BackoffMetrics
+	pktFilter        netext.PacketFilter
 	respSzEst        datasize.ByteSize
 	count            uint
 	ipv4Count        uint
 	ipv4Interval     time.Duration
+	ipv4Bandwidth    datasize.ByteSize
 	ipv4SubnetKeyLen int
 	ipv6Count        uint
 	ipv6Interval     time.Duration
+	ipv6Bandwidth    datasize.ByteSize
 	ipv6SubnetKeyLen int
 	refuseANY        bool
 }
@@ -98,21 +127,34 @@ type Backoff struct {
 func NewBackoff(c *BackoffConfig) (l *Backoff) {
 	// TODO(ameshkov, a.garipov): Consider adding a job or an endpoint for
 	// purging the caches to free the map bucket space in the caches.
-	return &Backoff{
+	l = &Backoff{
 		// TODO(ameshkov): Consider running the janitor more often.
 		reqCounters:      cache.New(c.Period, c.Period),
 		hitCounters:      cache.New(c.Duration, c.Duration),
+		bwCounters:       cache.New(c.Period, c.Period),
 		allowlist:        c.Allowlist,
+		metrics:          cmp.Or[BackoffMetrics](c.Metrics, EmptyBackoffMetrics{}),
+		pktFilter:        c.PacketFilter,
 		respSzEst:        c.ResponseSizeEstimate,
 		count:            c.Count,
 		ipv4Count:        c.IPv4Count,
 		ipv4Interval:     c.IPv4Interval,
+		ipv4Bandwidth:    c.IPv4Bandwidth,
 		ipv4SubnetKeyLen: c.IPv4SubnetKeyLen,
 		ipv6Count:        c.IPv6Count,
 		ipv6Interval:     c.IPv6Interval,
+		ipv6Bandwidth:    c.IPv6Bandwidth,
 		ipv6SubnetKeyLen: c.IPv6SubnetKeyLen,
 		refuseANY:        c.RefuseANY,
 	}
+
+	if l.pktFilter == nil {
+		l.pktFilter = netext.EmptyPacketFilter{}
+	} else {
+		l.hitCounters.OnEvicted(l.onHitCounterEvicted)
+	}
+
+	return l
 }
 
 // type check
@@ -147,12 +189,24 @@ func (l *Backoff) IsRateLimited(
 		return true, false, nil
 	}
 
+	if l.isAboveBandwidth(key, ip) {
+		l.incBackoff(ctx, key)
+		l.metrics.OnLimited(ctx, LimitReasonBandwidth)
+
+		return true, false, nil
+	}
+
 	count, ivl := l.ipv4Count, l.ipv4Interval
 	if ip.Is6() {
 		count, ivl = l.ipv6Count, l.ipv6Interval
 	}
 
-	return l.hasHitRateLimit(key, count, ivl), false, nil
+	drop = l.hasHitRateLimit(ctx, key, count, ivl)
+	if drop {
+		l.metrics.OnLimited(ctx, LimitReasonQPS)
+	}
+
+	return drop, false, nil
 }
 
 // validateAddr returns an error if addr is not a valid IPv4 or IPv6 address.
@@ -169,10 +223,17 @@ func validateAddr(addr netip.Addr) (err error) {
 	return nil
 }
 
-// CountResponses implements the Interface interface for *Backoff.
+// CountResponses implements the Interface interface for *Backoff.  It also
+// counts the size of resp for the bandwidth limit of the subnet of ip.
 func (l *Backoff) CountResponses(ctx context.Context, resp *dns.Msg, ip netip.Addr) {
+	respLen := resp.Len()
+	if ip.IsValid() {
+		// #nosec G115 -- Assume that resp.Len is always non-negative.
+		l.addBandwidth(ip, uint64(respLen))
+	}
+
 	// #nosec G115 -- Assume that resp.Len is always non-negative.
-	estRespNum := datasize.ByteSize(resp.Len()) / l.respSzEst
+	estRespNum := datasize.ByteSize(respLen) / l.respSzEst
 	for range estRespNum {
 		_, _, _ = l.IsRateLimited(ctx, resp, ip)
 	}
@@ -198,23 +259,44 @@ func (l *Backoff) subnetKey(ip netip.Addr) (key string) {
 	return subnet.String()
 }
 
-// incBackoff increments the number of requests above the RPS for a client.
-func (l *Backoff) incBackoff(key string) {
+// incBackoff increments the number of requests above the RPS for a client and
+// bans its subnet in the packet filter once it enters the backoff state.
+func (l *Backoff) incBackoff(ctx context.Context, key string) {
+	var n uint64
 	counterVal, ok := l.hitCounters.Get(key)
 	if ok {
-		counterVal.(*atomic.Uint64).Add(1)
+		n = counterVal.(*atomic.Uint64).Add(1)
+	} else {
+		counter := &atomic.Uint64{}
+		n = counter.Add(1)
+		l.hitCounters.SetDefault(key, counter)
+	}
 
+	if n == uint64(l.count) {
+		l.pktFilter.Ban(ctx, netip.MustParsePrefix(key))
+	}
+}
+
+// onHitCounterEvicted unbans the subnet of an evicted backoff counter in the
+// packet filter, if the subnet has been banned.  It is used as the eviction
+// callback of l.hitCounters.
+func (l *Backoff) onHitCounterEvicted(key string, val any) {
+	if val.(*atomic.Uint64).Load() < uint64(l.count) {
 		return
 	}
 
-	counter := &atomic.Uint64{}
-	counter.Add(1)
-	l.hitCounters.SetDefault(key, counter)
+	// TODO:  Use a proper context once the cache supports it.
+	l.pktFilter.Unban(context.Background(), netip.MustParsePrefix(key))
 }
 
 // hasHitRateLimit checks if the value of requests for given subnet hit the
 // maximum count of requests per given interval.
-func (l *Backoff) hasHitRateLimit(subnetIPStr string, count uint, ivl time.Duration) (ok bool) {
+func (l *Backoff) hasHitRateLimit(
+	ctx context.Context,
+	subnetIPStr string,
+	count uint,
+	ivl time.Duration,
+) (ok bool) {
 	var r *RequestCounter
 	rVal, ok := l.reqCounters.Get(subnetIPStr)
 	if ok {
@@ -226,12 +308,58 @@ func (l *Backoff) hasHitRateLimit(subnetIPStr string, count uint, ivl time.Durat
 
 	above := r.Add(time.Now())
 	if above {
-		l.incBackoff(subnetIPStr)
+		l.incBackoff(ctx, subnetIPStr)
 	}
 
 	return above
 }
 
+// bandwidth returns the bandwidth limit for the address family of ip.
+func (l *Backoff) bandwidth(ip netip.Addr) (bw datasize.ByteSize) {
+	if ip.Is6() {
+		return l.ipv6Bandwidth
+	}
+
+	return l.ipv4Bandwidth
+}
+
+// addBandwidth adds n bytes of a response to the bandwidth counter of the
+// subnet of ip, if the bandwidth is limited.  ip must be valid.
+func (l *Backoff) addBandwidth(ip netip.Addr, n uint64) {
+	bw := l.bandwidth(ip)
+	if bw == 0 {
+		return
+	}
+
+	key := l.subnetKey(ip)
+
+	var c *BandwidthCounter
+	cVal, ok := l.bwCounters.Get(key)
+	if ok {
+		c = cVal.(*BandwidthCounter)
+	} else {
+		c = NewBandwidthCounter(bw)
+		l.bwCounters.SetDefault(key, c)
+	}
+
+	c.Add(time.Now(), n)
+}
+
+// isAboveBandwidth returns true if the subnet with the given key has received
+// more bytes of responses within the current second than allowed.
+func (l *Backoff) isAboveBandwidth(key string, ip netip.Addr) (ok bool) {
+	if l.bandwidth(ip) == 0 {
+		return false
+	}
+
+	cVal, ok := l.bwCounters.Get(key)
+	if !ok {
+		return false
+	}
+
+	return cVal.(*BandwidthCounter).IsAbove(time.Now())
+}
+
 // isBackoff returns true if the specified client has hit the RPS too often.
 func (l *Backoff) isBackoff(key string) (ok bool) {
 	counterVal, ok := l.hitCounters.Get(key)
@@ -241,3 +369,64 @@ func (l *Backoff) isBackoff(key string) (ok bool) {
 
 	return counterVal.(*atomic.Uint64).Load() >= uint64(l.count)
 }
+
+// backoffSnapshotItem is the representation of a subnet in the backoff state in
+// a runtime state snapshot.
+type backoffSnapshotItem struct {
+	// Expiration is the time when the subnet leaves the backoff state.
+	Expiration time.Time `json:"expiration"`
+
+	// Subnet is the subnet in the backoff state.
+	Subnet netip.Prefix `json:"subnet"`
+}
+
+// Snapshot returns the list of the subnets in the backoff state.  state is
+// encodable as JSON.
+func (l *Backoff) Snapshot(_ context.Context) (state any, err error) {
+	var items []*backoffSnapshotItem
+	for key, item := range l.hitCounters.Items() {
+		if item.Expiration == 0 || item.Object.(*atomic.Uint64).Load() < uint64(l.count) {
+			continue
+		}
+
+		items = append(items, &backoffSnapshotItem{
+			Expiration: time.Unix(0, item.Expiration),
+			Subnet:     netip.MustParsePrefix(key),
+		})
+	}
+
+	return items, nil
+}
+
+// Restore puts the subnets from data, which is the JSON encoding of a state
+// previously returned by [Backoff.Snapshot], back into the backoff state and
+// bans them in the packet filter.  The subnets the backoff state of which has
+// expired as well as the ones that don't match the current subnet key lengths
+// are skipped.
+func (l *Backoff) Restore(ctx context.Context, data []byte) (err error) {
+	var items []*backoffSnapshotItem
+	err = json.Unmarshal(data, &items)
+	if err != nil {
+		return fmt.Errorf("decoding items: %w", err)
+	}
+
+	for _, item := range items {
+		subnet := item.Subnet
+		if !subnet.IsValid() || l.subnetKey(subnet.Addr()) != subnet.String() {
+			continue
+		}
+
+		exp := time.Until(item.Expiration)
+		if exp <= 0 {
+			continue
+		}
+
+		counter := &atomic.Uint64{}
+		counter.Store(uint64(l.count))
+		l.hitCounters.Set(subnet.String(), counter, exp)
+
+		l.pktFilter.Ban(ctx, subnet)
+	}
+
+	return nil
+}
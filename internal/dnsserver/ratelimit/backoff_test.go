@@ -0,0 +1,242 @@
+package ratelimit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/ratelimit"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/c2h5oh/datasize"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPacketFilter is a [netext.PacketFilter] for tests.
+type testPacketFilter struct {
+	bans   chan netip.Prefix
+	unbans chan netip.Prefix
+}
+
+// type check
+var _ netext.PacketFilter = (*testPacketFilter)(nil)
+
+// Ban implements the [netext.PacketFilter] interface for *testPacketFilter.
+func (f *testPacketFilter) Ban(_ context.Context, subnet netip.Prefix) {
+	f.bans <- subnet
+}
+
+// Unban implements the [netext.PacketFilter] interface for *testPacketFilter.
+func (f *testPacketFilter) Unban(_ context.Context, subnet netip.Prefix) {
+	f.unbans <- subnet
+}
+
+func TestBackoff_packetFilter(t *testing.T) {
+	t.Parallel()
+
+	const (
+		backoffCount = 2
+		backoffDur   = 100 * time.Millisecond
+	)
+
+	pf := &testPacketFilter{
+		bans:   make(chan netip.Prefix, backoffCount),
+		unbans: make(chan netip.Prefix, backoffCount),
+	}
+
+	rl := ratelimit.NewBackoff(&ratelimit.BackoffConfig{
+		Allowlist:            ratelimit.NewDynamicAllowlist(nil, nil),
+		PacketFilter:         pf,
+		Period:               time.Minute,
+		Duration:             backoffDur,
+		Count:                backoffCount,
+		ResponseSizeEstimate: 128 * datasize.B,
+		IPv4Count:            1,
+		IPv4Interval:         time.Minute,
+		IPv4SubnetKeyLen:     24,
+		IPv6Count:            1,
+		IPv6Interval:         time.Minute,
+		IPv6SubnetKeyLen:     48,
+	})
+
+	ctx := testutil.ContextWithTimeout(t, time.Second)
+	req := dnsservertest.CreateMessage("example.org.", dns.TypeA)
+	ip := netip.MustParseAddr("192.0.2.1")
+	wantSubnet := netip.MustParsePrefix("192.0.2.0/24")
+
+	// The first request passes, and the following ones hit the rate limit.
+	for range backoffCount + 1 {
+		_, _, err := rl.IsRateLimited(ctx, req, ip)
+		require.NoError(t, err)
+	}
+
+	banned, ok := testutil.RequireReceive(t, pf.bans, time.Second)
+	require.True(t, ok)
+
+	assert.Equal(t, wantSubnet, banned)
+
+	// Further hits must not ban the subnet again.
+	_, _, err := rl.IsRateLimited(ctx, req, ip)
+	require.NoError(t, err)
+
+	unbanned, ok := testutil.RequireReceive(t, pf.unbans, 10*backoffDur)
+	require.True(t, ok)
+
+	assert.Equal(t, wantSubnet, unbanned)
+	assert.Empty(t, pf.bans)
+}
+
+// testBackoffMetrics is a [ratelimit.BackoffMetrics] for tests.
+type testBackoffMetrics struct {
+	reasons chan ratelimit.LimitReason
+}
+
+// type check
+var _ ratelimit.BackoffMetrics = (*testBackoffMetrics)(nil)
+
+// OnLimited implements the [ratelimit.BackoffMetrics] interface for
+// *testBackoffMetrics.
+func (m *testBackoffMetrics) OnLimited(_ context.Context, reason ratelimit.LimitReason) {
+	m.reasons <- reason
+}
+
+func TestBackoff_bandwidth(t *testing.T) {
+	t.Parallel()
+
+	mtrc := &testBackoffMetrics{
+		reasons: make(chan ratelimit.LimitReason, 1),
+	}
+
+	rl := ratelimit.NewBackoff(&ratelimit.BackoffConfig{
+		Allowlist:            ratelimit.NewDynamicAllowlist(nil, nil),
+		Metrics:              mtrc,
+		Period:               time.Minute,
+		Duration:             time.Minute,
+		Count:                10,
+		ResponseSizeEstimate: 1 * datasize.KB,
+		IPv4Count:            100,
+		IPv4Interval:         time.Minute,
+		IPv4Bandwidth:        64 * datasize.B,
+		IPv4SubnetKeyLen:     24,
+		IPv6Count:            100,
+		IPv6Interval:         time.Minute,
+		IPv6SubnetKeyLen:     48,
+	})
+
+	ctx := testutil.ContextWithTimeout(t, time.Second)
+	req := dnsservertest.CreateMessage("example.org.", dns.TypeA)
+	resp := dnsservertest.NewResp(dns.RcodeSuccess, req, dnsservertest.SectionAnswer{
+		dnsservertest.NewA("example.org.", 60, netip.MustParseAddr("192.0.2.10")),
+		dnsservertest.NewA("example.org.", 60, netip.MustParseAddr("192.0.2.11")),
+		dnsservertest.NewA("example.org.", 60, netip.MustParseAddr("192.0.2.12")),
+	})
+	require.Greater(t, resp.Len(), 64)
+
+	ip := netip.MustParseAddr("192.0.2.1")
+
+	drop, _, err := rl.IsRateLimited(ctx, req, ip)
+	require.NoError(t, err)
+
+	assert.False(t, drop)
+
+	rl.CountResponses(ctx, resp, ip)
+
+	drop, _, err = rl.IsRateLimited(ctx, req, ip)
+	require.NoError(t, err)
+
+	assert.True(t, drop)
+
+	reason, ok := testutil.RequireReceive(t, mtrc.reasons, time.Second)
+	require.True(t, ok)
+
+	assert.Equal(t, ratelimit.LimitReasonBandwidth, reason)
+
+	// Other subnets and address families are not affected.
+	drop, _, err = rl.IsRateLimited(ctx, req, netip.MustParseAddr("198.51.100.1"))
+	require.NoError(t, err)
+
+	assert.False(t, drop)
+
+	rl.CountResponses(ctx, resp, netip.MustParseAddr("2001:db8::1"))
+	drop, _, err = rl.IsRateLimited(ctx, req, netip.MustParseAddr("2001:db8::1"))
+	require.NoError(t, err)
+
+	assert.False(t, drop)
+}
+
+func TestBackoff_Restore(t *testing.T) {
+	t.Parallel()
+
+	const backoffCount = 2
+
+	newBackoff := func(pf netext.PacketFilter) (rl *ratelimit.Backoff) {
+		return ratelimit.NewBackoff(&ratelimit.BackoffConfig{
+			Allowlist:            ratelimit.NewDynamicAllowlist(nil, nil),
+			PacketFilter:         pf,
+			Period:               time.Minute,
+			Duration:             time.Minute,
+			Count:                backoffCount,
+			ResponseSizeEstimate: 128 * datasize.B,
+			IPv4Count:            1,
+			IPv4Interval:         time.Minute,
+			IPv4SubnetKeyLen:     24,
+			IPv6Count:            1,
+			IPv6Interval:         time.Minute,
+			IPv6SubnetKeyLen:     48,
+		})
+	}
+
+	ctx := testutil.ContextWithTimeout(t, time.Second)
+	req := dnsservertest.CreateMessage("example.org.", dns.TypeA)
+	ip := netip.MustParseAddr("192.0.2.1")
+	otherIP := netip.MustParseAddr("198.51.100.1")
+	wantSubnet := netip.MustParsePrefix("192.0.2.0/24")
+
+	saved := newBackoff(nil)
+	for range backoffCount + 1 {
+		_, _, err := saved.IsRateLimited(ctx, req, ip)
+		require.NoError(t, err)
+	}
+
+	// Only hit the rate limit once for the other subnet, so that it doesn't
+	// enter the backoff state.
+	for range 2 {
+		_, _, err := saved.IsRateLimited(ctx, req, otherIP)
+		require.NoError(t, err)
+	}
+
+	state, err := saved.Snapshot(ctx)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+
+	pf := &testPacketFilter{
+		bans:   make(chan netip.Prefix, 1),
+		unbans: make(chan netip.Prefix, 1),
+	}
+
+	restored := newBackoff(pf)
+	err = restored.Restore(ctx, data)
+	require.NoError(t, err)
+
+	banned, ok := testutil.RequireReceive(t, pf.bans, time.Second)
+	require.True(t, ok)
+
+	assert.Equal(t, wantSubnet, banned)
+
+	drop, _, err := restored.IsRateLimited(ctx, req, ip)
+	require.NoError(t, err)
+
+	assert.True(t, drop)
+
+	drop, _, err = restored.IsRateLimited(ctx, req, otherIP)
+	require.NoError(t, err)
+
+	assert.False(t, drop)
+}
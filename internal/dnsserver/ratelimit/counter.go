@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/AdguardTeam/golibs/container"
+	"github.com/c2h5oh/datasize"
 )
 
 // RequestCounter is a single request-per-interval counter.
@@ -45,3 +46,58 @@ func (r *RequestCounter) Add(t time.Time) (isAbove bool) {
 
 	return tail > 0 && ts-tail <= int64(1*r.ivl)
 }
+
+// BandwidthCounter is a single bytes-per-second counter.  It counts the bytes
+// within fixed one-second windows.
+type BandwidthCounter struct {
+	// mu protects sec and bytes.
+	mu *sync.Mutex
+
+	// limit is the maximum number of bytes per second.
+	limit uint64
+
+	// sec is the Unix time in seconds of the current window.
+	sec int64
+
+	// bytes is the number of bytes counted within the current window.
+	bytes uint64
+}
+
+// NewBandwidthCounter returns a new bytes-per-second counter with the given
+// limit.  limit must be positive.
+func NewBandwidthCounter(limit datasize.ByteSize) (c *BandwidthCounter) {
+	return &BandwidthCounter{
+		mu:    &sync.Mutex{},
+		limit: uint64(limit),
+	}
+}
+
+// Add adds n bytes sent at t to c.  It is safe for concurrent use.
+func (c *BandwidthCounter) Add(t time.Time, n uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.resetWindow(t)
+	c.bytes += n
+}
+
+// IsAbove returns true if the bytes counted within the window of t exceed the
+// limit.  It is safe for concurrent use.
+func (c *BandwidthCounter) IsAbove(t time.Time) (isAbove bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.resetWindow(t)
+
+	return c.bytes > c.limit
+}
+
+// resetWindow starts a new window if t is not within the current one.  c.mu
+// must be locked.
+func (c *BandwidthCounter) resetWindow(t time.Time) {
+	sec := t.Unix()
+	if sec != c.sec {
+		c.sec = sec
+		c.bytes = 0
+	}
+}
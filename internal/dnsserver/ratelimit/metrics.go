@@ -31,3 +31,36 @@ func (EmptyMetrics) OnRateLimited(context.Context, *dns.Msg, dnsserver.ResponseW
 
 // OnAllowlisted implements the [Metrics] interface for EmptyMetrics.
 func (EmptyMetrics) OnAllowlisted(context.Context, *dns.Msg, dnsserver.ResponseWriter) {}
+
+// LimitReason is the reason for which the backoff rate limiter has dropped a
+// request.
+type LimitReason string
+
+// LimitReason values.
+const (
+	// LimitReasonBandwidth means that the subnet has received more bytes of
+	// responses per second than allowed.
+	LimitReasonBandwidth LimitReason = "bandwidth"
+
+	// LimitReasonQPS means that the subnet has sent more requests per interval
+	// than allowed.
+	LimitReasonQPS LimitReason = "qps"
+)
+
+// BackoffMetrics is an interface for monitoring the [Backoff] state.
+type BackoffMetrics interface {
+	// OnLimited is called when a request goes above a limit for the given
+	// reason.  It is not called for the requests dropped because the subnet is
+	// already in the backoff state.
+	OnLimited(ctx context.Context, reason LimitReason)
+}
+
+// EmptyBackoffMetrics implements [BackoffMetrics] with empty functions.  This
+// implementation is used by default if the user does not supply a custom one.
+type EmptyBackoffMetrics struct{}
+
+// type check
+var _ BackoffMetrics = EmptyBackoffMetrics{}
+
+// OnLimited implements the [BackoffMetrics] interface for EmptyBackoffMetrics.
+func (EmptyBackoffMetrics) OnLimited(_ context.Context, _ LimitReason) {}
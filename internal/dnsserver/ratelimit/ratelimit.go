@@ -24,6 +24,25 @@ type Interface interface {
 	CountResponses(ctx context.Context, resp *dns.Msg, ip netip.Addr)
 }
 
+// type check
+var _ Interface = Empty{}
+
+// Empty is an [Interface] implementation that never limits any queries.
+type Empty struct{}
+
+// IsRateLimited implements the [Interface] interface for Empty.  shouldDrop
+// and isAllowlisted are always false.
+func (Empty) IsRateLimited(
+	_ context.Context,
+	_ *dns.Msg,
+	_ netip.Addr,
+) (shouldDrop, isAllowlisted bool, err error) {
+	return false, false, nil
+}
+
+// CountResponses implements the [Interface] interface for Empty.
+func (Empty) CountResponses(_ context.Context, _ *dns.Msg, _ netip.Addr) {}
+
 // Middleware applies rate limiting to DNS queries.
 type Middleware struct {
 	// metrics is a listener for the middleware events.  Set it if you want to
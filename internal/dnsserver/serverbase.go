@@ -27,6 +27,12 @@ type ConfigBase struct {
 	// not set, EmptyDisposer is used.
 	Disposer Disposer
 
+	// QUICAdvisor, if not nil, is notified about the outcomes of the QUIC
+	// handshakes and is used to decide whether HTTP/3 should be advertised to
+	// the clients.  Note, that it only makes sense for [ServerQUIC] and
+	// [ServerHTTPS].
+	QUICAdvisor QUICAdvisor
+
 	// RequestContext is a ContextConstructor that returns contexts for
 	// requests.  If not set, the server uses [DefaultContextConstructor].
 	RequestContext ContextConstructor
@@ -47,6 +53,12 @@ type ConfigBase struct {
 	// Addr is the address the server listens to.  See [net.Dial] for the
 	// documentation on the address format.
 	Addr string
+
+	// PaddingBlockSize, if not zero, is the block size to which the responses
+	// to the padded queries over the encrypted protocols are padded, as per
+	// the Block-Length Padding strategy from RFC 8467.  If it is zero, the
+	// Random-Length Padding strategy is used.
+	PaddingBlockSize uint16
 }
 
 // ServerBase implements base methods that every Server implementation uses.
@@ -63,6 +75,10 @@ type ServerBase struct {
 	// disposer is used to help module users reuse parts of DNS responses.
 	disposer Disposer
 
+	// quicAdvisor, if not nil, is notified about the outcomes of the QUIC
+	// handshakes.
+	quicAdvisor QUICAdvisor
+
 	// listenConfig is used to set tcpListener and udpListener.
 	listenConfig netext.ListenConfig
 
@@ -94,6 +110,10 @@ type ServerBase struct {
 	// proto is the server protocol.
 	proto Protocol
 
+	// paddingBlockSize is the block size for the padding of responses.  If it
+	// is zero, the responses are padded randomly.
+	paddingBlockSize uint16
+
 	started bool
 }
 
@@ -108,6 +128,7 @@ func newServerBase(proto Protocol, conf ConfigBase) (s *ServerBase) {
 		reqCtx:       conf.RequestContext,
 		metrics:      conf.Metrics,
 		disposer:     conf.Disposer,
+		quicAdvisor:  conf.QUICAdvisor,
 		listenConfig: conf.ListenConfig,
 		mu:           &sync.RWMutex{},
 		wg:           &sync.WaitGroup{},
@@ -115,6 +136,8 @@ func newServerBase(proto Protocol, conf ConfigBase) (s *ServerBase) {
 		addr:         conf.Addr,
 		network:      conf.Network,
 		proto:        proto,
+
+		paddingBlockSize: conf.PaddingBlockSize,
 	}
 
 	if s.reqCtx == nil {
@@ -276,7 +299,7 @@ func (s *ServerBase) serveDNSMsgInternal(
 	var resp *dns.Msg
 
 	// Check if we can accept this message
-	switch action := s.acceptMsg(req); action {
+	switch action := acceptMsg(req); action {
 	case dns.MsgReject:
 		log.Debug("[%d] Query format is invalid", req.Id)
 		resp = genErrorResponse(req, dns.RcodeFormatError)
@@ -343,7 +366,7 @@ func addEDE(req, resp *dns.Msg, code uint16, text string) {
 }
 
 // acceptMsg checks if we should process the incoming DNS query.
-func (s *ServerBase) acceptMsg(m *dns.Msg) (action dns.MsgAcceptAction) {
+func acceptMsg(m *dns.Msg) (action dns.MsgAcceptAction) {
 	if m.Response {
 		log.Debug("[%d]: message rejected since this is a response", m.Id)
 
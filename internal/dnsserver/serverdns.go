@@ -39,6 +39,11 @@ const (
 // ConfigDNS is a struct that needs to be passed to NewServerDNS to
 // initialize a new ServerDNS instance.
 type ConfigDNS struct {
+	// KnownClients, if not nil, is used to check if the clients of the UDP
+	// requests are known.  Responses larger than [dns.MinMsgSize] to the
+	// unknown clients are truncated preemptively to make them retry over TCP.
+	KnownClients KnownClientChecker
+
 	ConfigBase
 
 	// ReadTimeout is the net.Conn.SetReadTimeout value for new connections.
@@ -70,6 +75,10 @@ type ConfigDNS struct {
 	// MaxUDPRespSize is the maximum size of DNS response over UDP protocol.
 	MaxUDPRespSize uint16
 
+	// UDPTruncation is the policy of truncating DNS responses over UDP.  If
+	// not set, [UDPTruncationEDNS] is used.
+	UDPTruncation UDPTruncationPolicy
+
 	// MaxPipelineEnabled, if true, enables TCP pipeline limiting.
 	MaxPipelineEnabled bool
 }
@@ -97,6 +106,10 @@ type ServerDNS struct {
 	tcpConns   map[net.Conn]struct{}
 	tcpConnsMu *sync.Mutex
 
+	// tlsConnInspector, if not nil, is used to inspect the TLS connections
+	// after the handshake.  It is only set for DoT servers.
+	tlsConnInspector TLSConnInspector
+
 	// TODO(ameshkov, a.garipov):  Only save the parameters a server actually
 	// needs.
 	conf ConfigDNS
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -371,6 +372,274 @@ func TestServerDNS_integration_tcpQueriesPipelining(t *testing.T) {
 	assert.Equal(t, sentIDs, receivedIDs)
 }
 
+func TestServerDNS_integration_tcpOutOfOrder(t *testing.T) {
+	t.Parallel()
+
+	const (
+		slowName = "slow.example."
+		fastName = "fast.example."
+	)
+
+	unblock := make(chan struct{})
+	h := dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		if req.Question[0].Name == slowName {
+			select {
+			case <-unblock:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		return rw.WriteMsg(ctx, req, dnsservertest.NewResp(dns.RcodeSuccess, req))
+	})
+
+	_, addr := dnsservertest.RunDNSServer(t, h)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, conn.Close)
+
+	slowReq := dnsservertest.NewReq(slowName, dns.TypeA, dns.ClassINET)
+	slowReq.Id = 1
+	writeTCPMsg(t, conn, slowReq)
+
+	fastReq := dnsservertest.NewReq(fastName, dns.TypeA, dns.ClassINET)
+	fastReq.Id = 2
+	writeTCPMsg(t, conn, fastReq)
+
+	// The response to the second query must not wait for the first one.
+	resp := readTCPMsg(t, conn)
+	assert.Equal(t, fastReq.Id, resp.Id)
+	assert.Equal(t, fastName, resp.Question[0].Name)
+
+	close(unblock)
+
+	resp = readTCPMsg(t, conn)
+	assert.Equal(t, slowReq.Id, resp.Id)
+	assert.Equal(t, slowName, resp.Question[0].Name)
+}
+
+func TestServerDNS_integration_tcpPipelineLimit(t *testing.T) {
+	t.Parallel()
+
+	const (
+		maxPipelineCount = 2
+		queriesNum       = 10
+	)
+
+	inFlight, maxInFlight := &atomic.Int32{}, &atomic.Int32{}
+	h := dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+
+		// Give the other queries a chance to be processed concurrently.
+		time.Sleep(10 * time.Millisecond)
+
+		return rw.WriteMsg(ctx, req, dnsservertest.NewResp(dns.RcodeSuccess, req))
+	})
+
+	srv := dnsserver.NewServerDNS(dnsserver.ConfigDNS{
+		ConfigBase: dnsserver.ConfigBase{
+			Name:    "test",
+			Addr:    "127.0.0.1:0",
+			Handler: h,
+		},
+		MaxUDPRespSize:     dns.MaxMsgSize,
+		MaxPipelineCount:   maxPipelineCount,
+		MaxPipelineEnabled: true,
+	})
+
+	err := srv.Start(context.Background())
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, func() (err error) {
+		return srv.Shutdown(context.Background())
+	})
+
+	conn, err := net.Dial("tcp", srv.LocalTCPAddr().String())
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, conn.Close)
+
+	sentIDs := make(map[uint16]string, queriesNum)
+	for i := range queriesNum {
+		name := fmt.Sprintf("host%d.example.", i)
+		req := dnsservertest.NewReq(name, dns.TypeA, dns.ClassINET)
+		req.Id = uint16(i + 1)
+
+		writeTCPMsg(t, conn, req)
+		sentIDs[req.Id] = name
+	}
+
+	receivedIDs := make(map[uint16]string, queriesNum)
+	for range queriesNum {
+		resp := readTCPMsg(t, conn)
+		require.NotEmpty(t, resp.Question)
+
+		receivedIDs[resp.Id] = resp.Question[0].Name
+	}
+
+	assert.Equal(t, sentIDs, receivedIDs)
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(maxPipelineCount))
+}
+
+// testKnownClientChecker is a [dnsserver.KnownClientChecker] for tests that
+// always returns its value.
+type testKnownClientChecker bool
+
+// type check
+var _ dnsserver.KnownClientChecker = testKnownClientChecker(false)
+
+// IsKnownClient implements the [dnsserver.KnownClientChecker] interface for
+// testKnownClientChecker.
+func (c testKnownClientChecker) IsKnownClient(_ context.Context) (ok bool) {
+	return bool(c)
+}
+
+func TestServerDNS_integration_udpTruncation(t *testing.T) {
+	t.Parallel()
+
+	const (
+		// smallCount is the number of answers which make a response larger
+		// than [dns.MinMsgSize] but smaller than
+		// [dnsserver.ClampedUDPRespSize].
+		smallCount = 40
+
+		// largeCount is the number of answers which make a response larger
+		// than [dnsserver.ClampedUDPRespSize].
+		largeCount = 100
+	)
+
+	testCases := []struct {
+		knownClients  dnsserver.KnownClientChecker
+		name          string
+		count         int
+		policy        dnsserver.UDPTruncationPolicy
+		wantTruncated bool
+	}{{
+		knownClients:  nil,
+		name:          "edns",
+		count:         largeCount,
+		policy:        dnsserver.UDPTruncationEDNS,
+		wantTruncated: false,
+	}, {
+		knownClients:  nil,
+		name:          "clamp_large",
+		count:         largeCount,
+		policy:        dnsserver.UDPTruncationClamp,
+		wantTruncated: true,
+	}, {
+		knownClients:  nil,
+		name:          "clamp_small",
+		count:         smallCount,
+		policy:        dnsserver.UDPTruncationClamp,
+		wantTruncated: false,
+	}, {
+		knownClients:  nil,
+		name:          "minimal",
+		count:         smallCount,
+		policy:        dnsserver.UDPTruncationMinimal,
+		wantTruncated: true,
+	}, {
+		knownClients:  testKnownClientChecker(true),
+		name:          "known_client",
+		count:         smallCount,
+		policy:        dnsserver.UDPTruncationEDNS,
+		wantTruncated: false,
+	}, {
+		knownClients:  testKnownClientChecker(false),
+		name:          "unknown_client",
+		count:         smallCount,
+		policy:        dnsserver.UDPTruncationEDNS,
+		wantTruncated: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			srv := dnsserver.NewServerDNS(dnsserver.ConfigDNS{
+				ConfigBase: dnsserver.ConfigBase{
+					Name:    "test",
+					Addr:    "127.0.0.1:0",
+					Handler: dnsservertest.NewDefaultHandlerWithCount(tc.count),
+					Network: dnsserver.NetworkUDP,
+				},
+				KnownClients:   tc.knownClients,
+				MaxUDPRespSize: dns.MaxMsgSize,
+				UDPTruncation:  tc.policy,
+			})
+
+			err := srv.Start(context.Background())
+			require.NoError(t, err)
+			testutil.CleanupAndRequireSuccess(t, func() (err error) {
+				return srv.Shutdown(context.Background())
+			})
+
+			req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+			req.SetEdns0(dns.DefaultMsgSize, false)
+
+			c := &dns.Client{
+				Net:     string(dnsserver.NetworkUDP),
+				UDPSize: dns.DefaultMsgSize,
+			}
+
+			resp, _, err := c.Exchange(req, srv.LocalUDPAddr().String())
+			require.NoError(t, err)
+			require.NotNil(t, resp)
+
+			assert.Equal(t, tc.wantTruncated, resp.Truncated)
+		})
+	}
+}
+
+// writeTCPMsg is a helper that writes msg into conn with the length prefix.
+func writeTCPMsg(tb testing.TB, conn net.Conn, msg *dns.Msg) {
+	tb.Helper()
+
+	b, err := msg.Pack()
+	require.NoError(tb, err)
+
+	buf := binary.BigEndian.AppendUint16(nil, uint16(len(b)))
+	_, err = conn.Write(append(buf, b...))
+	require.NoError(tb, err)
+}
+
+// readTCPMsg is a helper that reads a length-prefixed message from conn.
+func readTCPMsg(tb testing.TB, conn net.Conn) (msg *dns.Msg) {
+	tb.Helper()
+
+	err := conn.SetReadDeadline(time.Now().Add(time.Second))
+	require.NoError(tb, err)
+
+	var length uint16
+	err = binary.Read(conn, binary.BigEndian, &length)
+	require.NoError(tb, err)
+
+	buf := make([]byte, length)
+	_, err = io.ReadFull(conn, buf)
+	require.NoError(tb, err)
+
+	msg = &dns.Msg{}
+	err = msg.Unpack(buf)
+	require.NoError(tb, err)
+
+	return msg
+}
+
 func TestServerDNS_integration_udpMsgIgnore(t *testing.T) {
 	_, addr := dnsservertest.RunDNSServer(t, dnsservertest.NewDefaultHandler())
 	conn, err := net.Dial("udp", addr)
@@ -9,6 +9,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/log"
+	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/ameshkov/dnscrypt/v2"
 	"github.com/miekg/dns"
 )
@@ -16,13 +17,13 @@ import (
 // ConfigDNSCrypt is a struct that needs to be passed to NewServerDNSCrypt to
 // initialize a new ServerDNSCrypt instance.
 type ConfigDNSCrypt struct {
-	ConfigBase
-
 	// DNSCryptResolverCert is a DNSCrypt server certificate.
 	DNSCryptResolverCert *dnscrypt.Cert
 
 	// DNSCryptProviderName is a DNSCrypt provider name (see DNSCrypt spec).
 	DNSCryptProviderName string
+
+	ConfigBase
 }
 
 // ServerDNSCrypt is a DNSCrypt server implementation.
@@ -215,7 +216,11 @@ func (h *dnsCryptHandler) ServeDNS(rw dnscrypt.ResponseWriter, r *dns.Msg) (err
 	ctx, cancel := h.srv.requestContext()
 	defer cancel()
 
-	ctx = ContextWithRequestInfo(ctx, &RequestInfo{StartTime: time.Now()})
+	ctx = ContextWithRequestInfo(ctx, &RequestInfo{
+		StartTime: time.Now(),
+		Network:   NetworkFromAddr(rw.LocalAddr()),
+		LocalAddr: netutil.NetAddrToAddrPort(rw.LocalAddr()),
+	})
 
 	nrw := NewNonWriterResponseWriter(rw.LocalAddr(), rw.RemoteAddr())
 	written := h.srv.serveDNSMsg(ctx, r, nrw)
@@ -226,7 +231,7 @@ func (h *dnsCryptHandler) ServeDNS(rw dnscrypt.ResponseWriter, r *dns.Msg) (err
 
 	network := NetworkFromAddr(rw.LocalAddr())
 	msg := nrw.Msg()
-	normalize(network, ProtoDNSCrypt, r, msg, dns.MaxMsgSize)
+	normalize(network, ProtoDNSCrypt, r, msg, dns.MaxMsgSize, 0)
 
 	return rw.WriteMsg(msg)
 }
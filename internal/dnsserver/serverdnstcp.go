@@ -13,6 +13,7 @@ import (
 
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/log"
+	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/AdguardTeam/golibs/syncutil"
 	"github.com/miekg/dns"
 )
@@ -127,8 +128,15 @@ func (s *ServerDNS) serveTCPConn(ctx context.Context, conn net.Conn) {
 		return
 	}
 
+	hint, err := s.inspectTLSConn(ctx, conn)
+	if err != nil {
+		s.logReadErr("inspecting tls conn", err)
+
+		return
+	}
+
 	for s.isStarted() {
-		err = s.acceptTCPMsg(conn, wg, writeMu, timeout, msgSema)
+		err = s.acceptTCPMsg(conn, wg, writeMu, timeout, msgSema, hint)
 		if err != nil {
 			s.logReadErr("reading from conn", err)
 
@@ -151,13 +159,15 @@ func (s *ServerDNS) logReadErr(msg string, err error) {
 }
 
 // acceptTCPMsg reads and starts processing a single TCP message.  If conn is a
-// TLS connection, the handshake must have already been performed.
+// TLS connection, the handshake must have already been performed.  hint is the
+// connection hint from [ServerDNS.inspectTLSConn], if any.
 func (s *ServerDNS) acceptTCPMsg(
 	conn net.Conn,
 	wg *sync.WaitGroup,
 	writeMu *sync.Mutex,
 	timeout time.Duration,
 	msgSema syncutil.Semaphore,
+	hint any,
 ) (err error) {
 	bufPtr, err := s.readTCPMsg(conn, timeout)
 	if err != nil {
@@ -165,10 +175,19 @@ func (s *ServerDNS) acceptTCPMsg(
 	}
 
 	ri := &RequestInfo{
+		ConnHint:  hint,
 		StartTime: time.Now(),
+		Network:   NetworkTCP,
+		LocalAddr: netutil.NetAddrToAddrPort(conn.LocalAddr()),
 	}
 	if cs, ok := conn.(tlsConnectionStater); ok {
-		ri.TLSServerName = cs.ConnectionState().ServerName
+		state := cs.ConnectionState()
+		ri.TLSServerName = state.ServerName
+		ri.ALPN = state.NegotiatedProtocol
+	}
+
+	if fc, ok := conn.(tlsFingerprinter); ok {
+		ri.TLSFingerprint = fc.TLSFingerprint()
 	}
 
 	reqCtx, reqCancel := s.requestContext()
@@ -215,6 +234,8 @@ func (s *ServerDNS) serveTCPMessage(
 		conn:         conn,
 		writeTimeout: s.conf.WriteTimeout,
 		idleTimeout:  s.conf.TCPIdleTimeout,
+
+		paddingBlockSize: s.paddingBlockSize,
 	}
 	written := s.serveDNS(ctx, buf, rw)
 
@@ -280,6 +301,10 @@ type tcpResponseWriter struct {
 	conn         net.Conn
 	writeTimeout time.Duration
 	idleTimeout  time.Duration
+
+	// paddingBlockSize is the block size for the padding of responses.  If it
+	// is zero, the responses are padded randomly.
+	paddingBlockSize uint16
 }
 
 // type check
@@ -298,7 +323,7 @@ func (r *tcpResponseWriter) RemoteAddr() (addr net.Addr) {
 // WriteMsg implements the ResponseWriter interface for *tcpResponseWriter.
 func (r *tcpResponseWriter) WriteMsg(ctx context.Context, req, resp *dns.Msg) (err error) {
 	si := MustServerInfoFromContext(ctx)
-	normalizeTCP(si.Proto, req, resp)
+	normalizeTCP(si.Proto, req, resp, r.paddingBlockSize)
 	r.addTCPKeepAlive(req, resp)
 
 	bufPtr := r.respPool.Get()
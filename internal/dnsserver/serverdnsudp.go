@@ -9,6 +9,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/log"
+	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/AdguardTeam/golibs/syncutil"
 	"github.com/miekg/dns"
 )
@@ -65,6 +66,8 @@ func (s *ServerDNS) acceptUDPMsg(ctx context.Context, conn net.PacketConn) (err
 
 		reqCtx = ContextWithRequestInfo(reqCtx, &RequestInfo{
 			StartTime: startTime,
+			Network:   NetworkUDP,
+			LocalAddr: netutil.NetAddrToAddrPort(sess.LocalAddr()),
 		})
 
 		s.serveUDPPacket(reqCtx, (*bufPtr)[:n], conn, sess)
@@ -87,7 +90,9 @@ func (s *ServerDNS) serveUDPPacket(
 		udpSession:   sess,
 		conn:         conn,
 		writeTimeout: s.conf.WriteTimeout,
+		knownClients: s.conf.KnownClients,
 		maxRespSize:  s.conf.MaxUDPRespSize,
+		truncation:   s.conf.UDPTruncation,
 	}
 	s.serveDNS(ctx, buf, rw)
 }
@@ -122,8 +127,10 @@ type udpResponseWriter struct {
 	respPool     *syncutil.Pool[[]byte]
 	udpSession   netext.PacketSession
 	conn         net.PacketConn
+	knownClients KnownClientChecker
 	writeTimeout time.Duration
 	maxRespSize  uint16
+	truncation   UDPTruncationPolicy
 }
 
 // type check
@@ -145,7 +152,8 @@ func (r *udpResponseWriter) RemoteAddr() (addr net.Addr) {
 
 // WriteMsg implements the ResponseWriter interface for *udpResponseWriter.
 func (r *udpResponseWriter) WriteMsg(ctx context.Context, req, resp *dns.Msg) (err error) {
-	normalize(NetworkUDP, ProtoDNS, req, resp, r.maxRespSize)
+	maxSize := maxUDPRespSize(ctx, r.truncation, r.knownClients, r.maxRespSize)
+	normalize(NetworkUDP, ProtoDNS, req, resp, maxSize, 0)
 
 	bufPtr := r.respPool.Get()
 	defer func() {
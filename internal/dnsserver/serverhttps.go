@@ -10,11 +10,14 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/tlsfingerprint"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/httphdr"
 	"github.com/AdguardTeam/golibs/log"
@@ -24,6 +27,7 @@ import (
 	"github.com/quic-go/quic-go"
 	"github.com/quic-go/quic-go/http3"
 	"golang.org/x/net/http2"
+	"golang.org/x/net/websocket"
 )
 
 const (
@@ -37,8 +41,10 @@ const (
 	PathDoH = "/dns-query"
 	// PathJSON is a relative path we use to accept DoH JSON requests.
 	PathJSON = "/resolve"
+	// PathWebSocket is a relative path we use to accept DNS-over-WebSocket
+	// connections.  See [ConfigHTTPS.WebSocketEnabled].
+	PathWebSocket = "/dns-ws"
 
-	httpReadTimeout  = 5 * time.Second
 	httpWriteTimeout = 5 * time.Second
 	httpIdleTimeout  = 120 * time.Second
 )
@@ -72,20 +78,49 @@ type ConfigHTTPS struct {
 	// If it is empty, the server will return 404 for requests like that.
 	NonDNSHandler http.Handler
 
+	// Limits is the configuration of the limits of the server.  If it is nil,
+	// the defaults are used.
+	Limits *HTTPLimitsConfig
+
+	// Streams is the configuration of the streams of the HTTP/2 and HTTP/3
+	// connections.  If it is nil, the defaults are used.
+	Streams *HTTPStreamsConfig
+
+	// TLSFingerprint is the configuration of the TLS client fingerprinting.  If
+	// it is nil, the fingerprinting is disabled.  Only the connections over
+	// TCP are fingerprinted, since the raw ClientHello messages of the QUIC
+	// connections are not available.
+	TLSFingerprint *TLSFingerprintConfig
+
 	ConfigBase
 
 	// MaxStreamsPerPeer is the maximum number of concurrent streams that a peer
 	// is allowed to open.
 	MaxStreamsPerPeer int
 
+	// WebSocketMaxQueries is the maximum number of queries a client is allowed
+	// to send over a single WebSocket connection.  Once it is reached, the
+	// server closes the connection.  If it is zero, the number of queries is
+	// not limited.
+	WebSocketMaxQueries uint
+
 	// QUICLimitsEnabled, if true, enables QUIC limiting.
 	QUICLimitsEnabled bool
+
+	// WebSocketEnabled, if true, enables the DNS-over-WebSocket endpoint at
+	// [PathWebSocket].  Each binary WebSocket message sent by the client must
+	// contain a single DNS query in the wire format, and the server responds
+	// with a binary message containing the response.  Only HTTP/1.1 is
+	// supported for WebSocket connections.  Handshakes with an Origin header
+	// that doesn't match the requested host are rejected.
+	WebSocketEnabled bool
 }
 
 // ServerHTTPS is a DoH server implementation.  It supports both DNS Wireformat
 // and DNS JSON format.  Regular DoH (wireformat) will be available at the
 // /dns-query location.  JSON format will be available at the "/resolve"
-// location.
+// location.  DNS over WebSocket, if enabled, will be available at the
+// "/dns-ws" location.
 type ServerHTTPS struct {
 	*ServerBase
 
@@ -103,6 +138,15 @@ type ServerHTTPS struct {
 	// quicTransport is saved here to close it later.
 	quicTransport *quic.Transport
 
+	// wsConns is a set that is used to track active WebSocket connections,
+	// which are not closed by the HTTP server on shutdown, since they are
+	// hijacked.
+	wsConns   map[*websocket.Conn]struct{}
+	wsConnsMu *sync.Mutex
+
+	// limits are the limits of the server with the defaults applied.
+	limits *httpLimits
+
 	conf ConfigHTTPS
 }
 
@@ -119,6 +163,9 @@ func NewServerHTTPS(conf ConfigHTTPS) (s *ServerHTTPS) {
 
 	s = &ServerHTTPS{
 		ServerBase: newServerBase(ProtoDoH, conf.ConfigBase),
+		wsConns:    map[*websocket.Conn]struct{}{},
+		wsConnsMu:  &sync.Mutex{},
+		limits:     newHTTPLimits(conf.Limits),
 		conf:       conf,
 	}
 
@@ -204,13 +251,19 @@ func (s *ServerHTTPS) startHTTPSServer(ctx context.Context) (err error) {
 	// Create an instance of the HTTP server.
 	s.httpServer = &http.Server{
 		Handler:           handler,
-		ReadTimeout:       httpReadTimeout,
-		ReadHeaderTimeout: httpReadTimeout,
+		ConnContext:       s.connContext,
+		ReadTimeout:       s.limits.readTimeout,
+		ReadHeaderTimeout: s.limits.readHeaderTimeout,
 		WriteTimeout:      httpWriteTimeout,
 		IdleTimeout:       httpIdleTimeout,
 		ErrorLog:          log.StdLog("dnsserver/serverhttps: "+s.name, log.DEBUG),
 	}
 
+	err = http2.ConfigureServer(s.httpServer, newHTTP2Server(s.conf.Streams))
+	if err != nil {
+		return fmt.Errorf("configuring http2: %w", err)
+	}
+
 	// Start the server worker goroutine.
 	s.wg.Add(1)
 	go s.serveHTTPS(ctx, s.httpServer, s.tcpListener)
@@ -234,7 +287,8 @@ func (s *ServerHTTPS) startH3Server(ctx context.Context) (err error) {
 
 	// Create an instance of the HTTP/3 server.
 	s.h3Server = &http3.Server{
-		Handler: handler,
+		Handler:     handler,
+		ConnContext: s.quicConnContext,
 	}
 
 	// Start the server worker goroutine.
@@ -272,9 +326,13 @@ func (s *ServerHTTPS) shutdown(ctx context.Context) (err error) {
 		log.Debug("[%s]: http server shutdown: %v", s.Name(), err)
 	}
 
-	// Finally, shutdown the HTTP/3 server.
+	// Then, shutdown the HTTP/3 server.
 	s.shutdownH3()
 
+	// Finally, unblock the hijacked WebSocket connections so that their
+	// workers exit.
+	s.unblockWebSocketConns()
+
 	return nil
 }
 
@@ -388,6 +446,12 @@ func (h *httpHandler) remoteAddr(r *http.Request) (addr net.Addr) {
 // NOTE: r.Context() is only used to control cancelation.  To add values to the
 // context, use the BaseContext of this handler's ServerHTTPS.
 func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.srv.conf.WebSocketEnabled && isWebSocket(r) {
+		h.serveWebSocket(w, r)
+
+		return
+	}
+
 	ctx, cancel := h.srv.requestContext()
 	defer cancel()
 
@@ -420,8 +484,29 @@ func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // serveDoH processes the incoming DNS message and writes the response back to
 // the client.
 func (h *httpHandler) serveDoH(ctx context.Context, w http.ResponseWriter, r *http.Request) {
-	m, err := httpRequestToMsg(r)
-	if err != nil {
+	if _, isJSON, _ := isDoH(r); !isJSON {
+		code, err := validateAccept(r, MimeTypeDoH)
+		if err != nil {
+			log.Debug("Bad accept header: %v", err)
+			h.srv.metrics.OnInvalidMsg(ctx)
+			http.Error(w, err.Error(), code)
+
+			return
+		}
+	}
+
+	if !allowRequest(r) {
+		h.onLimitExceeded(ctx, w, HTTPLimitConnRate, http.StatusTooManyRequests)
+
+		return
+	}
+
+	m, err := httpRequestToMsg(w, r, h.srv.limits.maxBodySize)
+	if isBodyTooLarge(err) {
+		h.onLimitExceeded(ctx, w, HTTPLimitBodySize, http.StatusRequestEntityTooLarge)
+
+		return
+	} else if err != nil {
 		log.Debug("Failed to convert request to a DNS message: %v", err)
 		h.srv.metrics.OnInvalidMsg(ctx)
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -437,6 +522,14 @@ func (h *httpHandler) serveDoH(ctx context.Context, w http.ResponseWriter, r *ht
 	// Serve the query
 	written := h.srv.serveDNS(ctx, m, rw)
 
+	// The context of an HTTP/2 or HTTP/3 request is canceled when the client
+	// resets the stream, so there is no one to write the response to.
+	if r.ProtoMajor >= 2 && r.Context().Err() != nil {
+		h.srv.metrics.OnHTTPStreamReset(ctx)
+
+		return
+	}
+
 	// If no response were written, indicate it via an internal server error.
 	if !written {
 		log.Debug("No response has been written by the handler")
@@ -473,7 +566,7 @@ func (h *httpHandler) writeResponse(
 	w http.ResponseWriter,
 ) (err error) {
 	// normalize the response
-	normalizeTCP(ProtoDoH, req, resp)
+	normalizeTCP(ProtoDoH, req, resp, h.srv.paddingBlockSize)
 
 	isDNS, _, ct := isDoH(r)
 	if !isDNS {
@@ -495,12 +588,8 @@ func (h *httpHandler) writeResponse(
 		return err
 	}
 
-	// From RFC8484, Section 5.1:
-	// DoH servers SHOULD assign an explicit HTTP freshness
-	// lifetime (see Section 4.2 of [RFC7234]) so that the DoH client is
-	// more likely to use fresh DNS data.
-	maxAge := minimalTTL(resp)
-	w.Header().Set(httphdr.CacheControl, fmt.Sprintf("max-age=%f", maxAge.Seconds()))
+	setCacheHeaders(w.Header(), resp)
+	h.setAltSvc(w.Header(), r)
 	w.Header().Set(httphdr.ContentLength, strconv.Itoa(len(buf)))
 	w.WriteHeader(http.StatusOK)
 
@@ -525,6 +614,10 @@ func (s *ServerHTTPS) listenTLS(ctx context.Context) (err error) {
 		return nil
 	}
 
+	if fpConf := s.conf.TLSFingerprint; fpConf != nil {
+		s.tcpListener = tlsfingerprint.NewListener(s.tcpListener, fpConf.KeepRaw)
+	}
+
 	s.tcpListener = tls.NewListener(s.tcpListener, tlsConf)
 
 	return nil
@@ -547,6 +640,9 @@ func (s *ServerHTTPS) listenQUIC(ctx context.Context) (err error) {
 	}
 
 	qConf := newServerQUICConfig(s.conf.QUICLimitsEnabled, s.conf.MaxStreamsPerPeer)
+	setQUICStreams(qConf, s.conf.Streams)
+	setQUICAdvisorTracer(qConf, s.quicAdvisor)
+
 	ql, err := transport.ListenEarly(tlsConf, qConf)
 	if err != nil {
 		return fmt.Errorf("listening quic: %w", err)
@@ -564,12 +660,25 @@ func addRequestInfo(parent context.Context, r *http.Request) (ctx context.Contex
 	ctx = parent
 
 	ri := &RequestInfo{
-		StartTime: time.Now(),
-		URL:       netutil.CloneURL(r.URL),
+		StartTime:   time.Now(),
+		URL:         netutil.CloneURL(r.URL),
+		HTTPHeaders: requestInfoHeaders(r.Header),
+		Network:     NetworkTCP,
+	}
+
+	if laddr, ok := r.Context().Value(http.LocalAddrContextKey).(net.Addr); ok {
+		ri.LocalAddr = netutil.NetAddrToAddrPort(laddr)
 	}
 
 	if r.TLS != nil {
 		ri.TLSServerName = r.TLS.ServerName
+		ri.ALPN = r.TLS.NegotiatedProtocol
+		ri.TLSFingerprint = tlsFingerprintFromContext(r.Context())
+	}
+
+	if ci, ok := connInfoFromContext(r.Context()); ok && ci.quicConn != nil {
+		ri.Network = NetworkUDP
+		ri.Used0RTT = ci.quicConn.ConnectionState().Used0RTT
 	}
 
 	if username, pass, ok := r.BasicAuth(); ok {
@@ -579,8 +688,32 @@ func addRequestInfo(parent context.Context, r *http.Request) (ctx context.Contex
 	return ContextWithRequestInfo(ctx, ri)
 }
 
-// httpRequestToMsg reads the DNS message from http.Request.
-func httpRequestToMsg(req *http.Request) (b []byte, err error) {
+// requestInfoHeaders returns the subset of h that contains only the headers
+// from [requestInfoHTTPHeaders].  hdrs is nil if there are no such headers.
+func requestInfoHeaders(h http.Header) (hdrs http.Header) {
+	for _, name := range requestInfoHTTPHeaders {
+		vals := h.Values(name)
+		if len(vals) == 0 {
+			continue
+		}
+
+		if hdrs == nil {
+			hdrs = http.Header{}
+		}
+
+		hdrs[name] = slices.Clone(vals)
+	}
+
+	return hdrs
+}
+
+// httpRequestToMsg reads the DNS message from http.Request.  maxBodySize is the
+// maximum size of the body of a POST request, see [isBodyTooLarge].
+func httpRequestToMsg(
+	w http.ResponseWriter,
+	req *http.Request,
+	maxBodySize int64,
+) (b []byte, err error) {
 	_, isJSON, _ := isDoH(req)
 	if isJSON {
 		return httpRequestToMsgJSON(req)
@@ -590,17 +723,35 @@ func httpRequestToMsg(req *http.Request) (b []byte, err error) {
 	case http.MethodGet:
 		return httpRequestToMsgGet(req)
 	case http.MethodPost:
-		return httpRequestToMsgPost(req)
+		return httpRequestToMsgPost(w, req, maxBodySize)
 	default:
 		return nil, fmt.Errorf("method not allowed: %s", req.Method)
 	}
 }
 
-// httpRequestToMsgPost extracts the DNS message from a request body.
-func httpRequestToMsgPost(req *http.Request) (b []byte, err error) {
-	buf, err := io.ReadAll(req.Body)
+// httpRequestToMsgPost extracts the DNS message from a request body.  If the
+// body is larger than maxBodySize, err is an [*http.MaxBytesError].
+func httpRequestToMsgPost(
+	w http.ResponseWriter,
+	req *http.Request,
+	maxBodySize int64,
+) (b []byte, err error) {
 	defer log.OnCloserError(req.Body, log.DEBUG)
-	return buf, err
+
+	if req.ContentLength > maxBodySize {
+		// Don't read the body at all, since it's known to be too large.
+		return nil, &http.MaxBytesError{Limit: maxBodySize}
+	}
+
+	return io.ReadAll(http.MaxBytesReader(w, req.Body, maxBodySize))
+}
+
+// isBodyTooLarge returns true if err means that the request body is larger than
+// the limit.
+func isBodyTooLarge(err error) (ok bool) {
+	var maxBytesErr *http.MaxBytesError
+
+	return errors.As(err, &maxBytesErr)
 }
 
 // httpRequestToMsgGet extracts the DNS message from a GET request.
@@ -642,3 +793,15 @@ func isDoH(r *http.Request) (ok, isJSON bool, ct string) {
 		return false, false, ""
 	}
 }
+
+// isWebSocket returns true if r is a request to the DNS-over-WebSocket
+// endpoint, either at [PathWebSocket] or at a path with an additional element,
+// such as the device ID.
+func isWebSocket(r *http.Request) (ok bool) {
+	parts := strings.Split(path.Clean(r.URL.Path), "/")
+	if parts[0] == "" {
+		parts = parts[1:]
+	}
+
+	return len(parts) <= 2 && parts[0] != "" && strings.HasSuffix(PathWebSocket, parts[0])
+}
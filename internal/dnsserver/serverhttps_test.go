@@ -10,8 +10,11 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -19,12 +22,15 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
 	"github.com/AdguardTeam/golibs/httphdr"
 	"github.com/AdguardTeam/golibs/log"
+	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/AdguardTeam/golibs/testutil"
 	"github.com/miekg/dns"
 	"github.com/quic-go/quic-go"
 	"github.com/quic-go/quic-go/http3"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/net/http2"
+	"golang.org/x/net/websocket"
 )
 
 func TestServerHTTPS_integration_serveRequests(t *testing.T) {
@@ -163,6 +169,155 @@ func TestServerHTTPS_integration_nonDNSHandler(t *testing.T) {
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 }
 
+func TestServerHTTPS_integration_webSocketDisabled(t *testing.T) {
+	srv, err := dnsservertest.RunLocalHTTPSServer(dnsservertest.NewDefaultHandler(), nil, nil)
+	require.NoError(t, err)
+
+	testutil.CleanupAndRequireSuccess(t, func() (err error) {
+		return srv.Shutdown(context.Background())
+	})
+
+	wsURL := fmt.Sprintf("ws://%s%s", srv.LocalTCPAddr(), dnsserver.PathWebSocket)
+	_, err = websocket.Dial(wsURL, "", "http://localhost/")
+	require.Error(t, err)
+}
+
+func TestServerHTTPS_integration_limits(t *testing.T) {
+	t.Parallel()
+
+	const maxBodySize = 64
+
+	srv := dnsserver.NewServerHTTPS(dnsserver.ConfigHTTPS{
+		ConfigBase: dnsserver.ConfigBase{
+			Name:    "test",
+			Addr:    "127.0.0.1:0",
+			Handler: dnsservertest.NewDefaultHandler(),
+			Network: dnsserver.NetworkTCP,
+		},
+		Limits: &dnsserver.HTTPLimitsConfig{
+			MaxBodySize:           maxBodySize,
+			ConnRequestsPerSecond: 1,
+			CloseAbusiveConns:     true,
+		},
+	})
+
+	err := srv.Start(context.Background())
+	require.NoError(t, err)
+
+	testutil.CleanupAndRequireSuccess(t, func() (err error) {
+		return srv.Shutdown(context.Background())
+	})
+
+	u := fmt.Sprintf("http://%s%s", srv.LocalTCPAddr(), dnsserver.PathDoH)
+	req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+
+	t.Run("body_size", func(t *testing.T) {
+		t.Parallel()
+
+		body := bytes.Repeat([]byte{0}, maxBodySize+1)
+		resp, postErr := http.Post(u, dnsserver.MimeTypeDoH, bytes.NewReader(body))
+		require.NoError(t, postErr)
+		defer log.OnCloserError(resp.Body, log.DEBUG)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+		assert.True(t, resp.Close)
+	})
+
+	t.Run("conn_rate", func(t *testing.T) {
+		t.Parallel()
+
+		client, clientErr := newDoHClient(srv.LocalTCPAddr(), nil)
+		require.NoError(t, clientErr)
+
+		httpReq, reqErr := newDoHRequest(http.MethodGet, req, false)
+		require.NoError(t, reqErr)
+
+		wantCodes := []int{http.StatusOK, http.StatusTooManyRequests}
+		for _, want := range wantCodes {
+			resp, doErr := client.Do(httpReq.Clone(context.Background()))
+			require.NoError(t, doErr)
+
+			_, _ = io.Copy(io.Discard, resp.Body)
+			require.NoError(t, resp.Body.Close())
+
+			assert.Equal(t, want, resp.StatusCode)
+		}
+	})
+}
+
+func TestServerHTTPS_integration_streams(t *testing.T) {
+	t.Parallel()
+
+	const (
+		maxStreams      = 10
+		headerTableSize = 1024
+		streamWindow    = 16384
+	)
+
+	tlsConfig := dnsservertest.CreateServerTLSConfig("example.org")
+	tlsConfig.NextProtos = dnsserver.NextProtoDoH
+
+	srv := dnsserver.NewServerHTTPS(dnsserver.ConfigHTTPS{
+		ConfigBase: dnsserver.ConfigBase{
+			Name:    "test",
+			Addr:    "127.0.0.1:0",
+			Handler: dnsservertest.NewDefaultHandler(),
+			Network: dnsserver.NetworkTCP,
+		},
+		TLSConfDefault: tlsConfig,
+		Streams: &dnsserver.HTTPStreamsConfig{
+			WriteScheduler:       dnsserver.HTTPWriteSchedulerPriority,
+			MaxConcurrentStreams: maxStreams,
+			HeaderTableSize:      headerTableSize,
+			StreamWindowSize:     streamWindow,
+		},
+	})
+
+	err := srv.Start(context.Background())
+	require.NoError(t, err)
+
+	testutil.CleanupAndRequireSuccess(t, func() (err error) {
+		return srv.Shutdown(context.Background())
+	})
+
+	conn, err := tls.Dial("tcp", srv.LocalTCPAddr().String(), &tls.Config{
+		ServerName:         "example.org",
+		NextProtos:         []string{http2.NextProtoTLS},
+		InsecureSkipVerify: true,
+	})
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, conn.Close)
+
+	require.Equal(t, http2.NextProtoTLS, conn.ConnectionState().NegotiatedProtocol)
+
+	_, err = io.WriteString(conn, http2.ClientPreface)
+	require.NoError(t, err)
+
+	framer := http2.NewFramer(conn, conn)
+	err = framer.WriteSettings()
+	require.NoError(t, err)
+
+	err = conn.SetReadDeadline(time.Now().Add(testTimeout))
+	require.NoError(t, err)
+
+	f, err := framer.ReadFrame()
+	require.NoError(t, err)
+
+	settings := testutil.RequireTypeAssert[*http2.SettingsFrame](t, f)
+
+	got := map[http2.SettingID]uint32{}
+	err = settings.ForeachSetting(func(s http2.Setting) (err error) {
+		got[s.ID] = s.Val
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, uint32(maxStreams), got[http2.SettingMaxConcurrentStreams])
+	assert.Equal(t, uint32(headerTableSize), got[http2.SettingHeaderTableSize])
+	assert.Equal(t, uint32(streamWindow), got[http2.SettingInitialWindowSize])
+}
+
 func TestDNSMsgToJSONMsg(t *testing.T) {
 	m := &dns.Msg{
 		MsgHdr: dns.MsgHdr{
@@ -344,6 +499,352 @@ func TestServerHTTPS_integration_ENDS0Padding(t *testing.T) {
 	require.NotEmpty(t, paddingOpt.Padding)
 }
 
+func TestServerHTTPS_integration_cacheHeaders(t *testing.T) {
+	t.Parallel()
+
+	const (
+		initTTL = 10
+		soaTTL  = 300
+		soaMin  = 60
+	)
+
+	// ttl emulates the TTL of a cached response, which is decremented by the
+	// DNS cache on each hit.
+	ttl := &atomic.Uint32{}
+	ttl.Store(initTTL)
+
+	handler := dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		name := req.Question[0].Name
+		if req.Question[0].Qtype != dns.TypeA {
+			resp := dnsservertest.NewResp(dns.RcodeNameError, req, dnsservertest.SectionNs{
+				&dns.SOA{
+					Hdr: dns.RR_Header{
+						Name:   name,
+						Rrtype: dns.TypeSOA,
+						Class:  dns.ClassINET,
+						Ttl:    soaTTL,
+					},
+					Ns:     "ns." + name,
+					Mbox:   "hostmaster." + name,
+					Minttl: soaMin,
+				},
+			})
+
+			return rw.WriteMsg(ctx, req, resp)
+		}
+
+		rr := dnsservertest.NewA(name, ttl.Add(^uint32(0))+1, netip.MustParseAddr("192.0.2.1"))
+		resp := dnsservertest.NewResp(dns.RcodeSuccess, req, dnsservertest.SectionAnswer{rr})
+
+		return rw.WriteMsg(ctx, req, resp)
+	})
+
+	srv, err := dnsservertest.RunLocalHTTPSServer(handler, nil, nil)
+	require.NoError(t, err)
+
+	testutil.CleanupAndRequireSuccess(t, func() (err error) {
+		return srv.Shutdown(context.Background())
+	})
+
+	client, err := newDoHClient(srv.LocalTCPAddr(), nil)
+	require.NoError(t, err)
+
+	t.Run("ttl_decrement", func(t *testing.T) {
+		for i := range 3 {
+			req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+			hdr := mustDoHGetHeader(t, client, req, dnsserver.MimeTypeDoH, http.StatusOK)
+
+			wantMaxAge := "max-age=" + strconv.Itoa(initTTL-i)
+			assert.Equal(t, wantMaxAge, hdr.Get(httphdr.CacheControl))
+			assert.Equal(t, "0", hdr.Get("Age"))
+		}
+	})
+
+	t.Run("soa_minimum", func(t *testing.T) {
+		req := dnsservertest.NewReq("example.org.", dns.TypeAAAA, dns.ClassINET)
+		hdr := mustDoHGetHeader(t, client, req, dnsserver.MimeTypeDoH, http.StatusOK)
+
+		assert.Equal(t, "max-age="+strconv.Itoa(soaMin), hdr.Get(httphdr.CacheControl))
+		assert.Equal(t, "0", hdr.Get("Age"))
+	})
+}
+
+func TestServerHTTPS_integration_accept(t *testing.T) {
+	t.Parallel()
+
+	srv, err := dnsservertest.RunLocalHTTPSServer(dnsservertest.NewDefaultHandler(), nil, nil)
+	require.NoError(t, err)
+
+	testutil.CleanupAndRequireSuccess(t, func() (err error) {
+		return srv.Shutdown(context.Background())
+	})
+
+	client, err := newDoHClient(srv.LocalTCPAddr(), nil)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		accept   string
+		wantCode int
+	}{{
+		name:     "empty",
+		accept:   "",
+		wantCode: http.StatusOK,
+	}, {
+		name:     "exact",
+		accept:   dnsserver.MimeTypeDoH,
+		wantCode: http.StatusOK,
+	}, {
+		name:     "any",
+		accept:   "*/*",
+		wantCode: http.StatusOK,
+	}, {
+		name:     "any_subtype",
+		accept:   "application/*",
+		wantCode: http.StatusOK,
+	}, {
+		name:     "several",
+		accept:   "text/html, " + dnsserver.MimeTypeDoH + ";q=0.9",
+		wantCode: http.StatusOK,
+	}, {
+		name:     "other",
+		accept:   "text/html",
+		wantCode: http.StatusNotAcceptable,
+	}, {
+		name:     "zero_quality",
+		accept:   dnsserver.MimeTypeDoH + ";q=0",
+		wantCode: http.StatusNotAcceptable,
+	}, {
+		name:     "zero_quality_specific",
+		accept:   dnsserver.MimeTypeDoH + ";q=0, */*",
+		wantCode: http.StatusNotAcceptable,
+	}, {
+		name:     "too_long",
+		accept:   strings.Repeat("text/html, ", 100) + dnsserver.MimeTypeDoH,
+		wantCode: http.StatusRequestHeaderFieldsTooLarge,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+			_ = mustDoHGetHeader(t, client, req, tc.accept, tc.wantCode)
+		})
+	}
+}
+
+func TestServerHTTPS_integration_tlsFingerprint(t *testing.T) {
+	t.Parallel()
+
+	h, fpCh := newFingerprintHandler()
+	tlsConfig := dnsservertest.CreateServerTLSConfig("example.org")
+	tlsConfig.NextProtos = dnsserver.NextProtoDoH
+
+	srv := dnsserver.NewServerHTTPS(dnsserver.ConfigHTTPS{
+		ConfigBase: dnsserver.ConfigBase{
+			Name:    "test",
+			Addr:    "127.0.0.1:0",
+			Handler: h,
+			Network: dnsserver.NetworkTCP,
+		},
+		TLSConfDefault: tlsConfig,
+		TLSFingerprint: &dnsserver.TLSFingerprintConfig{
+			KeepRaw: false,
+		},
+	})
+
+	err := srv.Start(context.Background())
+	require.NoError(t, err)
+
+	testutil.CleanupAndRequireSuccess(t, func() (err error) {
+		return srv.Shutdown(context.Background())
+	})
+
+	req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+	resp := mustDoHReq(t, srv.LocalTCPAddr(), tlsConfig, http.MethodGet, false, false, req)
+	require.Equal(t, dns.RcodeSuccess, resp.Rcode)
+
+	fp, _ := testutil.RequireReceive(t, fpCh, testTimeout)
+	require.NotNil(t, fp)
+
+	assert.Regexp(t, `^t13d[0-9]{4}h2_[0-9a-f]{12}_[0-9a-f]{12}$`, fp.JA4)
+	assert.Empty(t, fp.JA3Raw)
+	assert.Empty(t, fp.JA4Raw)
+}
+
+func TestServerHTTPS_integration_requestInfo(t *testing.T) {
+	t.Parallel()
+
+	riCh := make(chan *dnsserver.RequestInfo, 1)
+	h := dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		select {
+		case riCh <- dnsserver.MustRequestInfoFromContext(ctx):
+		default:
+		}
+
+		return rw.WriteMsg(ctx, req, dnsservertest.NewResp(dns.RcodeSuccess, req))
+	})
+
+	tlsConfig := dnsservertest.CreateServerTLSConfig("example.org")
+	tlsConfig.NextProtos = dnsserver.NextProtoDoH
+
+	srv := dnsserver.NewServerHTTPS(dnsserver.ConfigHTTPS{
+		ConfigBase: dnsserver.ConfigBase{
+			Name:    "test",
+			Addr:    "127.0.0.1:0",
+			Handler: h,
+			Network: dnsserver.NetworkTCP,
+		},
+		TLSConfDefault: tlsConfig,
+	})
+
+	err := srv.Start(context.Background())
+	require.NoError(t, err)
+
+	testutil.CleanupAndRequireSuccess(t, func() (err error) {
+		return srv.Shutdown(context.Background())
+	})
+
+	req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+	resp := mustDoHReq(t, srv.LocalTCPAddr(), tlsConfig, http.MethodGet, false, false, req)
+	require.Equal(t, dns.RcodeSuccess, resp.Rcode)
+
+	ri, _ := testutil.RequireReceive(t, riCh, testTimeout)
+	require.NotNil(t, ri)
+
+	assert.Equal(t, dnsserver.NetworkTCP, ri.Network)
+	assert.Equal(t, netutil.NetAddrToAddrPort(srv.LocalTCPAddr()), ri.LocalAddr)
+	assert.Equal(t, "h2", ri.ALPN)
+	assert.Equal(t, dnsserver.PathDoH, ri.HTTPPath())
+	assert.Equal(t, dnsserver.MimeTypeDoH, ri.HTTPHeaders.Get(httphdr.Accept))
+	assert.Empty(t, ri.HTTPHeaders.Get(httphdr.ContentType))
+	assert.False(t, ri.Used0RTT)
+}
+
+// testQUICAdvisor is a [dnsserver.QUICAdvisor] for tests.
+type testQUICAdvisor struct {
+	onOnQUICHandshake func(ctx context.Context, addr netip.Addr, ok bool)
+	onIsQUICBlocked   func(ctx context.Context, addr netip.Addr) (blocked bool)
+}
+
+// type check
+var _ dnsserver.QUICAdvisor = (*testQUICAdvisor)(nil)
+
+// OnQUICHandshake implements the [dnsserver.QUICAdvisor] interface for
+// *testQUICAdvisor.
+func (a *testQUICAdvisor) OnQUICHandshake(ctx context.Context, addr netip.Addr, ok bool) {
+	a.onOnQUICHandshake(ctx, addr, ok)
+}
+
+// IsQUICBlocked implements the [dnsserver.QUICAdvisor] interface for
+// *testQUICAdvisor.
+func (a *testQUICAdvisor) IsQUICBlocked(ctx context.Context, addr netip.Addr) (blocked bool) {
+	return a.onIsQUICBlocked(ctx, addr)
+}
+
+func TestServerHTTPS_integration_quicAdvisor(t *testing.T) {
+	t.Parallel()
+
+	handshakeCh := make(chan bool, 1)
+	advisor := &testQUICAdvisor{
+		onOnQUICHandshake: func(_ context.Context, addr netip.Addr, ok bool) {
+			assert.Equal(t, netutil.IPv4Localhost(), addr)
+
+			testutil.RequireSend(testutil.PanicT{}, handshakeCh, ok, testTimeout)
+		},
+		onIsQUICBlocked: func(_ context.Context, addr netip.Addr) (blocked bool) {
+			return addr == netutil.IPv4Localhost()
+		},
+	}
+
+	tlsConfig := dnsservertest.CreateServerTLSConfig("example.org")
+	tlsConfigH3 := tlsConfig.Clone()
+	tlsConfig.NextProtos = dnsserver.NextProtoDoH
+	tlsConfigH3.NextProtos = dnsserver.NextProtoDoH3
+
+	srv := dnsserver.NewServerHTTPS(dnsserver.ConfigHTTPS{
+		ConfigBase: dnsserver.ConfigBase{
+			Name:        "test",
+			Addr:        "127.0.0.1:0",
+			Handler:     dnsservertest.NewDefaultHandler(),
+			Network:     dnsserver.NetworkAny,
+			QUICAdvisor: advisor,
+		},
+		TLSConfDefault: tlsConfig,
+		TLSConfH3:      tlsConfigH3,
+	})
+
+	err := srv.Start(context.Background())
+	require.NoError(t, err)
+
+	testutil.CleanupAndRequireSuccess(t, func() (err error) {
+		return srv.Shutdown(context.Background())
+	})
+
+	t.Run("handshake", func(t *testing.T) {
+		testDoH3Exchange(t, srv.LocalUDPAddr(), tlsConfig, nil)
+
+		ok, _ := testutil.RequireReceive(t, handshakeCh, testTimeout)
+		assert.True(t, ok)
+	})
+
+	t.Run("alt_svc", func(t *testing.T) {
+		client, cliErr := createDoH2Client(srv.LocalTCPAddr(), tlsConfig)
+		require.NoError(t, cliErr)
+
+		req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+		httpReq, reqErr := newDoHRequest(http.MethodGet, req, true)
+		require.NoError(t, reqErr)
+
+		httpResp, respErr := client.Do(httpReq)
+		require.NoError(t, respErr)
+
+		testutil.CleanupAndRequireSuccess(t, httpResp.Body.Close)
+
+		assert.Equal(t, http.StatusOK, httpResp.StatusCode)
+		assert.Equal(t, "clear", httpResp.Header.Get(httphdr.AltSvc))
+	})
+}
+
+// mustDoHGetHeader is a helper that sends req as a DoH GET request with the
+// given Accept header using client, checks the status code of the response,
+// and returns its header.
+func mustDoHGetHeader(
+	tb testing.TB,
+	client *http.Client,
+	req *dns.Msg,
+	accept string,
+	wantCode int,
+) (hdr http.Header) {
+	tb.Helper()
+
+	httpReq, err := newDoHRequest(http.MethodGet, req, false)
+	require.NoError(tb, err)
+
+	httpReq.Header.Del(httphdr.Accept)
+	if accept != "" {
+		httpReq.Header.Set(httphdr.Accept, accept)
+	}
+
+	httpResp, err := client.Do(httpReq)
+	require.NoError(tb, err)
+
+	testutil.CleanupAndRequireSuccess(tb, httpResp.Body.Close)
+
+	_, err = io.Copy(io.Discard, httpResp.Body)
+	require.NoError(tb, err)
+	require.Equal(tb, wantCode, httpResp.StatusCode)
+
+	return httpResp.Header
+}
+
 func TestServerHTTPS_0RTT(t *testing.T) {
 	tlsConfig := dnsservertest.CreateServerTLSConfig("example.org")
 	srv, err := dnsservertest.RunLocalHTTPSServer(
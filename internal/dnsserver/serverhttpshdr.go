@@ -0,0 +1,138 @@
+package dnsserver
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/netutil"
+	"github.com/miekg/dns"
+)
+
+const (
+	// httpHdrAge is the Age HTTP header, which is missing from package
+	// httphdr.  See RFC 9111, Section 5.1.
+	httpHdrAge = "Age"
+
+	// maxAcceptLen is the maximum total length of the values of the Accept
+	// headers of a DoH request.  It is large enough for any real-world client,
+	// including browsers.
+	maxAcceptLen = 1024
+)
+
+// altSvcClear is the value of the Alt-Svc header that invalidates all
+// alternative services previously advertised to the client.  See RFC 7838,
+// Section 3.
+const altSvcClear = "clear"
+
+// errAcceptTooLong is returned by [validateAccept] when the Accept headers of
+// the request are too long.
+const errAcceptTooLong errors.Error = "accept header too long"
+
+// validateAccept returns an HTTP status code and a non-nil error if the
+// Accept headers of r don't allow responses of mimeType.  An absent Accept
+// header allows any type.
+func validateAccept(r *http.Request, mimeType string) (code int, err error) {
+	accept := strings.Join(r.Header.Values(httphdr.Accept), ",")
+	if len(accept) > maxAcceptLen {
+		return http.StatusRequestHeaderFieldsTooLarge, errAcceptTooLong
+	}
+
+	if !acceptsMimeType(accept, mimeType) {
+		return http.StatusNotAcceptable, fmt.Errorf("%s is not accepted", mimeType)
+	}
+
+	return http.StatusOK, nil
+}
+
+// acceptsMimeType returns true if the value of the Accept header accept allows
+// responses of mimeType, which must be in the "type/subtype" form.  The most
+// specific media range matching mimeType is used, as defined by RFC 9110,
+// Section 12.5.1.  An empty accept allows any type.
+func acceptsMimeType(accept, mimeType string) (ok bool) {
+	if strings.TrimSpace(accept) == "" {
+		return true
+	}
+
+	typ, _, _ := strings.Cut(mimeType, "/")
+
+	// specificity is the specificity of the matched media range: 0 for "*/*",
+	// 1 for "type/*", and 2 for "type/subtype".  -1 means no match.
+	specificity := -1
+	for _, mediaRange := range strings.Split(accept, ",") {
+		rng, params, _ := strings.Cut(mediaRange, ";")
+		rng = strings.TrimSpace(rng)
+
+		var s int
+		switch {
+		case strings.EqualFold(rng, mimeType):
+			s = 2
+		case strings.EqualFold(rng, typ+"/*"):
+			s = 1
+		case rng == "*/*":
+			s = 0
+		default:
+			continue
+		}
+
+		if s > specificity {
+			specificity = s
+			ok = !isZeroQuality(params)
+		}
+	}
+
+	return ok
+}
+
+// isZeroQuality returns true if the media-range parameters params contain a
+// quality value of zero, which means "not acceptable".
+func isZeroQuality(params string) (ok bool) {
+	for _, p := range strings.Split(params, ";") {
+		k, v, _ := strings.Cut(p, "=")
+		if !strings.EqualFold(strings.TrimSpace(k), "q") {
+			continue
+		}
+
+		q, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+
+		return err == nil && q == 0
+	}
+
+	return false
+}
+
+// setCacheHeaders sets the HTTP caching headers of a DoH response for resp.
+//
+// From RFC 8484, Section 5.1:
+//
+//	DoH servers SHOULD assign an explicit HTTP freshness lifetime (see
+//	Section 4.2 of [RFC7234]) so that the DoH client is more likely to use
+//	fresh DNS data.
+//
+// The TTLs of resp are expected to already be decremented by the DNS caches,
+// if any, so the freshness lifetime is counted from now, and the age of the
+// response is zero.
+func setCacheHeaders(h http.Header, resp *dns.Msg) {
+	maxAge := int64(minimalTTL(resp).Seconds())
+	h.Set(httphdr.CacheControl, "max-age="+strconv.FormatInt(maxAge, 10))
+	h.Set(httpHdrAge, "0")
+}
+
+// setAltSvc sets the Alt-Svc header in hdr to invalidate HTTP/3 for the clients
+// that shouldn't use QUIC according to the QUIC advisor of the server.  It does
+// nothing if the advisor or HTTP/3 is not configured or if r has been received
+// over HTTP/3.
+func (h *httpHandler) setAltSvc(hdr http.Header, r *http.Request) {
+	a := h.srv.quicAdvisor
+	if a == nil || h.srv.conf.TLSConfH3 == nil || r.ProtoMajor >= 3 {
+		return
+	}
+
+	addr := netutil.NetAddrToAddrPort(h.remoteAddr(r)).Addr().Unmap()
+	if a.IsQUICBlocked(r.Context(), addr) {
+		hdr.Set(httphdr.AltSvc, altSvcClear)
+	}
+}
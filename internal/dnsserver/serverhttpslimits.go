@@ -0,0 +1,158 @@
+package dnsserver
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go"
+	"golang.org/x/time/rate"
+)
+
+// HTTPLimitsConfig is the configuration of the limits of the DoH server, which
+// protect it from the slow-loris style resource exhaustion.
+type HTTPLimitsConfig struct {
+	// ReadHeaderTimeout is the amount of time allowed to read the request
+	// headers.  If it is zero, [DefaultHTTPReadTimeout] is used.
+	ReadHeaderTimeout time.Duration
+
+	// ReadTimeout is the amount of time allowed to read the entire request,
+	// including the body.  If it is zero, [DefaultHTTPReadTimeout] is used.
+	ReadTimeout time.Duration
+
+	// MaxBodySize is the maximum size of the body of a request, in bytes.
+	// Requests with larger bodies are answered with the 413 status.  If it is
+	// zero, [dns.MaxMsgSize] is used.
+	MaxBodySize uint
+
+	// ConnRequestsPerSecond is the maximum number of requests per second a
+	// client is allowed to send over a single connection.  Requests above the
+	// limit are answered with the 429 status.  If it is zero, the rate is not
+	// limited.
+	ConnRequestsPerSecond uint
+
+	// CloseAbusiveConns, if true, makes the server close the HTTP/1.1 and
+	// HTTP/2 connections on which a limit has been exceeded after the response
+	// is written.
+	CloseAbusiveConns bool
+}
+
+// DefaultHTTPReadTimeout is the default value of [HTTPLimitsConfig.ReadTimeout]
+// and [HTTPLimitsConfig.ReadHeaderTimeout].
+const DefaultHTTPReadTimeout = 5 * time.Second
+
+// HTTPLimit is the limit of the DoH server that a request has exceeded.
+type HTTPLimit string
+
+// Valid [HTTPLimit] values.
+const (
+	// HTTPLimitBodySize means that the request body is larger than
+	// [HTTPLimitsConfig.MaxBodySize].
+	HTTPLimitBodySize HTTPLimit = "body_size"
+
+	// HTTPLimitConnRate means that the client has sent more requests over the
+	// connection than [HTTPLimitsConfig.ConnRequestsPerSecond] allows.
+	HTTPLimitConnRate HTTPLimit = "conn_rate"
+)
+
+// httpLimits are the normalized limits of the DoH server.
+type httpLimits struct {
+	readHeaderTimeout time.Duration
+	readTimeout       time.Duration
+	maxBodySize       int64
+	connRate          rate.Limit
+	connBurst         int
+	closeAbusiveConns bool
+}
+
+// newHTTPLimits returns the limits from c with the defaults applied.  c may be
+// nil.
+func newHTTPLimits(c *HTTPLimitsConfig) (l *httpLimits) {
+	if c == nil {
+		c = &HTTPLimitsConfig{}
+	}
+
+	l = &httpLimits{
+		readHeaderTimeout: cmp.Or(c.ReadHeaderTimeout, DefaultHTTPReadTimeout),
+		readTimeout:       cmp.Or(c.ReadTimeout, DefaultHTTPReadTimeout),
+		maxBodySize:       int64(cmp.Or(c.MaxBodySize, dns.MaxMsgSize)),
+		closeAbusiveConns: c.CloseAbusiveConns,
+	}
+
+	if c.ConnRequestsPerSecond > 0 {
+		l.connRate = rate.Limit(c.ConnRequestsPerSecond)
+
+		// #nosec G115 -- The value is a reasonable configuration value.
+		l.connBurst = int(c.ConnRequestsPerSecond)
+	}
+
+	return l
+}
+
+// connLimiter returns a new per-connection rate limiter.  It returns nil if the
+// rate is not limited.
+func (l *httpLimits) connLimiter() (lim *rate.Limiter) {
+	if l.connRate == 0 {
+		return nil
+	}
+
+	return rate.NewLimiter(l.connRate, l.connBurst)
+}
+
+// connContext is the [http.Server.ConnContext] function of the DoH server.  It
+// adds the connection data, such as the per-connection rate limiter, to ctx.
+func (s *ServerHTTPS) connContext(ctx context.Context, c net.Conn) (connCtx context.Context) {
+	ci := &connInfo{
+		httpLimiter: s.limits.connLimiter(),
+	}
+
+	if s.conf.TLSFingerprint != nil {
+		ci.tlsFingerprintConn = tlsFingerprintConn(c)
+	}
+
+	return contextWithConnInfo(ctx, ci)
+}
+
+// quicConnContext is the [http3.Server.ConnContext] function of the DoH
+// server.  It adds the connection data, such as the per-connection rate
+// limiter, to ctx.
+func (s *ServerHTTPS) quicConnContext(
+	ctx context.Context,
+	conn quic.Connection,
+) (connCtx context.Context) {
+	return contextWithConnInfo(ctx, &connInfo{
+		httpLimiter: s.limits.connLimiter(),
+		quicConn:    conn,
+	})
+}
+
+// allowRequest returns true if the rate limiter of the connection of r, if
+// any, allows the request.
+func allowRequest(r *http.Request) (ok bool) {
+	ci, ok := connInfoFromContext(r.Context())
+
+	return !ok || ci.httpLimiter == nil || ci.httpLimiter.Allow()
+}
+
+// onLimitExceeded writes the error response with the given status code for the
+// exceeded limit, closes the connection if necessary, and reports the metrics.
+func (h *httpHandler) onLimitExceeded(
+	ctx context.Context,
+	w http.ResponseWriter,
+	limit HTTPLimit,
+	code int,
+) {
+	closed := h.srv.limits.closeAbusiveConns
+	if closed {
+		// Both the HTTP/1.1 and HTTP/2 servers close the connection after the
+		// response if this header is set.  The HTTP/3 server ignores it.
+		w.Header().Set("Connection", "close")
+	}
+
+	h.srv.metrics.OnHTTPLimitExceeded(ctx, limit, closed)
+	http.Error(w, fmt.Sprintf("%s limit exceeded", limit), code)
+}
@@ -0,0 +1,134 @@
+package dnsserver
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go"
+	"golang.org/x/net/http2"
+)
+
+// HTTPStreamsConfig is the configuration of the streams of the HTTP/2 and
+// HTTP/3 connections of the DoH server, which prevents a single client from
+// monopolizing a worker.  The defaults are tuned for the pattern of many small
+// requests and responses, which is typical for DNS.
+type HTTPStreamsConfig struct {
+	// WriteScheduler is the scheduler of the HTTP/2 response frames.  The
+	// HTTP/3 server doesn't support prioritization, so it is only used for
+	// HTTP/2.
+	WriteScheduler HTTPWriteScheduler
+
+	// MaxConcurrentStreams is the maximum number of concurrent streams a
+	// client is allowed to open over a single connection.  If it is zero,
+	// [DefaultHTTPMaxConcurrentStreams] is used for HTTP/2, and the QUIC
+	// settings of the server are used for HTTP/3.
+	MaxConcurrentStreams uint32
+
+	// HeaderTableSize is the maximum size of the HPACK header compression
+	// tables of an HTTP/2 connection, in bytes.  If it is zero,
+	// [DefaultHTTPHeaderTableSize] is used.  The HTTP/3 server doesn't use the
+	// dynamic QPACK table, so it is only used for HTTP/2.
+	HeaderTableSize uint32
+
+	// StreamWindowSize is the size of the flow-control window of a single
+	// stream, in bytes.  If it is zero, [DefaultHTTPStreamWindowSize] is used
+	// for HTTP/2, and the QUIC default is used for HTTP/3.
+	StreamWindowSize uint32
+
+	// ConnWindowSize is the size of the flow-control window of a whole
+	// connection, in bytes.  If it is zero, [DefaultHTTPConnWindowSize] is used
+	// for HTTP/2, and the QUIC default is used for HTTP/3.  For HTTP/2, it
+	// must not be less than 65535, which is the initial window size.
+	ConnWindowSize uint32
+}
+
+// Default values of the [HTTPStreamsConfig] fields.
+const (
+	DefaultHTTPMaxConcurrentStreams uint32 = 100
+	DefaultHTTPHeaderTableSize      uint32 = 4096
+	DefaultHTTPStreamWindowSize     uint32 = dns.MaxMsgSize
+	DefaultHTTPConnWindowSize       uint32 = 1 << 20
+)
+
+// HTTPWriteScheduler defines the order in which the responses to the
+// concurrent requests of an HTTP/2 connection are written.
+type HTTPWriteScheduler uint8
+
+// HTTPWriteScheduler values.
+const (
+	// HTTPWriteSchedulerRoundRobin makes the server write the responses of
+	// all streams in turn, ignoring the priorities set by the client.  This is
+	// the default scheduler.
+	HTTPWriteSchedulerRoundRobin HTTPWriteScheduler = iota
+
+	// HTTPWriteSchedulerPriority makes the server respect the priorities set
+	// by the client, as described in RFC 7540.
+	HTTPWriteSchedulerPriority
+
+	// HTTPWriteSchedulerRandom makes the server write the responses of the
+	// streams in a random order, ignoring the priorities set by the client.
+	HTTPWriteSchedulerRandom
+)
+
+// newWriteScheduler returns the constructor of the HTTP/2 write scheduler for
+// s.  It returns nil for [HTTPWriteSchedulerRoundRobin], since that is the
+// default scheduler of [http2.Server].
+func (s HTTPWriteScheduler) newWriteScheduler() (f func() (ws http2.WriteScheduler)) {
+	switch s {
+	case HTTPWriteSchedulerRoundRobin:
+		return nil
+	case HTTPWriteSchedulerPriority:
+		return func() (ws http2.WriteScheduler) {
+			return http2.NewPriorityWriteScheduler(nil)
+		}
+	case HTTPWriteSchedulerRandom:
+		return http2.NewRandomWriteScheduler
+	default:
+		panic(fmt.Errorf("http write scheduler: %w: %d", errors.ErrBadEnumValue, s))
+	}
+}
+
+// newHTTP2Server returns the configuration of the HTTP/2 server from c with
+// the defaults applied.  c may be nil.
+func newHTTP2Server(c *HTTPStreamsConfig) (conf *http2.Server) {
+	if c == nil {
+		c = &HTTPStreamsConfig{}
+	}
+
+	headerTableSize := cmp.Or(c.HeaderTableSize, DefaultHTTPHeaderTableSize)
+
+	return &http2.Server{
+		MaxConcurrentStreams:      cmp.Or(c.MaxConcurrentStreams, DefaultHTTPMaxConcurrentStreams),
+		MaxDecoderHeaderTableSize: headerTableSize,
+		MaxEncoderHeaderTableSize: headerTableSize,
+		// #nosec G115 -- The values are reasonable configuration values.
+		MaxUploadBufferPerConnection: int32(cmp.Or(c.ConnWindowSize, DefaultHTTPConnWindowSize)),
+		// #nosec G115 -- The values are reasonable configuration values.
+		MaxUploadBufferPerStream: int32(cmp.Or(c.StreamWindowSize, DefaultHTTPStreamWindowSize)),
+		NewWriteScheduler:        c.WriteScheduler.newWriteScheduler(),
+	}
+}
+
+// setQUICStreams sets the stream settings of the HTTP/3 connections from c in
+// conf.  Only the explicitly set values are used.  c may be nil.
+func setQUICStreams(conf *quic.Config, c *HTTPStreamsConfig) {
+	if c == nil {
+		return
+	}
+
+	if c.MaxConcurrentStreams > 0 {
+		conf.MaxIncomingStreams = int64(c.MaxConcurrentStreams)
+	}
+
+	if c.StreamWindowSize > 0 {
+		conf.InitialStreamReceiveWindow = uint64(c.StreamWindowSize)
+		conf.MaxStreamReceiveWindow = uint64(c.StreamWindowSize)
+	}
+
+	if c.ConnWindowSize > 0 {
+		conf.InitialConnectionReceiveWindow = uint64(c.ConnWindowSize)
+		conf.MaxConnectionReceiveWindow = uint64(c.ConnWindowSize)
+	}
+}
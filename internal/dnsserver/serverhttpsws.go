@@ -0,0 +1,207 @@
+package dnsserver
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/golibs/log"
+	"github.com/miekg/dns"
+	"golang.org/x/net/websocket"
+)
+
+// serveWebSocket upgrades the connection to WebSocket and serves DNS queries
+// over it.  See [ConfigHTTPS.WebSocketEnabled].
+func (h *httpHandler) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	// WebSocket over HTTP/2 and HTTP/3, as defined in RFC 8441 and RFC 9220, is
+	// not supported, and neither HTTP/2 nor HTTP/3 connections can be
+	// hijacked.
+	if _, ok := w.(http.Hijacker); !ok || r.ProtoMajor != 1 {
+		ctx, cancel := h.srv.requestContext()
+		defer cancel()
+
+		h.srv.metrics.OnInvalidMsg(ctx)
+		http.Error(w, "websocket requires http/1.1", http.StatusHTTPVersionNotSupported)
+
+		return
+	}
+
+	wsSrv := websocket.Server{
+		Handshake: checkWebSocketOrigin,
+		Handler: func(ws *websocket.Conn) {
+			h.serveWebSocketConn(ws, r)
+		},
+	}
+
+	wsSrv.ServeHTTP(w, r)
+}
+
+// checkWebSocketOrigin is a [websocket.Server.Handshake] function that accepts
+// handshakes without the Origin header, since most of the clients are not
+// browsers, as well as the handshakes with the Origin header that matches the
+// requested host.  Cross-origin handshakes are rejected, so that pages on other
+// sites cannot use the browsers of the users to send queries on their behalf.
+func checkWebSocketOrigin(conf *websocket.Config, r *http.Request) (err error) {
+	origin, err := websocket.Origin(conf, r)
+	if err != nil {
+		return fmt.Errorf("parsing origin: %w", err)
+	} else if origin == nil {
+		return nil
+	}
+
+	if !strings.EqualFold(origin.Host, r.Host) {
+		return fmt.Errorf("origin host %q does not match host %q", origin.Host, r.Host)
+	}
+
+	conf.Origin = origin
+
+	return nil
+}
+
+// serveWebSocketConn serves DNS queries from a single WebSocket connection
+// until the client closes it, the connection becomes idle, or the client
+// reaches the query limit.  The queries are processed sequentially.  ws is
+// closed by the caller.
+func (h *httpHandler) serveWebSocketConn(ws *websocket.Conn, r *http.Request) {
+	s := h.srv
+	if !s.trackWebSocketConn(ws) {
+		return
+	}
+
+	ctx, cancel := s.requestContext()
+	defer cancel()
+
+	var queries uint
+	var limited bool
+	defer func() {
+		s.untrackWebSocketConn(ws)
+		s.metrics.OnWebSocketConnClosed(ctx, queries, limited)
+	}()
+
+	defer s.handlePanicAndRecover(ctx)
+
+	ws.PayloadType = websocket.BinaryFrame
+	ws.MaxPayloadBytes = dns.MaxMsgSize
+
+	maxQueries := s.conf.WebSocketMaxQueries
+	for maxQueries == 0 || queries < maxQueries {
+		var buf []byte
+		err := h.readWebSocketMsg(ws, &buf)
+		if err != nil {
+			log.Debug("[%s]: reading websocket message: %s", s.name, err)
+
+			return
+		}
+
+		queries++
+
+		err = h.serveWebSocketMsg(ws, r, buf)
+		if err != nil {
+			log.Debug("[%s]: writing websocket message: %s", s.name, err)
+
+			return
+		}
+	}
+
+	limited = true
+	log.Debug("[%s]: websocket query limit of %d reached", s.name, maxQueries)
+}
+
+// readWebSocketMsg reads a single message from ws into buf.
+func (h *httpHandler) readWebSocketMsg(ws *websocket.Conn, buf *[]byte) (err error) {
+	// The deadlines set by the HTTP server before the connection has been
+	// hijacked are still in effect, so always override them.
+	err = ws.SetReadDeadline(time.Now().Add(httpIdleTimeout))
+	if err != nil {
+		return fmt.Errorf("setting read deadline: %w", err)
+	}
+
+	return websocket.Message.Receive(ws, buf)
+}
+
+// serveWebSocketMsg processes the DNS message in buf and writes the response,
+// if any, to ws.  err is only returned if the response cannot be written.
+func (h *httpHandler) serveWebSocketMsg(
+	ws *websocket.Conn,
+	r *http.Request,
+	buf []byte,
+) (err error) {
+	ctx, cancel := h.srv.requestContext()
+	defer cancel()
+
+	ctx = addRequestInfo(ctx, r)
+	rw := NewNonWriterResponseWriter(h.localAddr, h.remoteAddr(r))
+
+	written := h.srv.serveDNS(ctx, buf, rw)
+	if !written {
+		// Do not close the connection on invalid messages, since the next ones
+		// may be valid.
+		log.Debug("No response has been written by the handler")
+
+		return nil
+	}
+
+	resp := rw.Msg()
+	defer h.srv.disposer.Dispose(resp)
+
+	normalizeTCP(ProtoDoH, rw.req, resp, h.srv.paddingBlockSize)
+	b, err := resp.Pack()
+	if err != nil {
+		// Don't close the connection, since it's not the client's fault.
+		h.srv.metrics.OnError(ctx, fmt.Errorf("packing websocket response: %w", err))
+
+		return nil
+	}
+
+	err = ws.SetWriteDeadline(time.Now().Add(httpWriteTimeout))
+	if err != nil {
+		return fmt.Errorf("setting write deadline: %w", err)
+	}
+
+	return websocket.Message.Send(ws, b)
+}
+
+// trackWebSocketConn adds ws to the set of active WebSocket connections and
+// increments the worker counter of s.  ok is false if the server is not
+// running, in which case ws must not be served.
+func (s *ServerHTTPS) trackWebSocketConn(ws *websocket.Conn) (ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if !s.started {
+		return false
+	}
+
+	s.wsConnsMu.Lock()
+	defer s.wsConnsMu.Unlock()
+
+	s.wsConns[ws] = struct{}{}
+	s.wg.Add(1)
+
+	return true
+}
+
+// untrackWebSocketConn removes ws from the set of active WebSocket connections
+// and decrements the worker counter of s.
+func (s *ServerHTTPS) untrackWebSocketConn(ws *websocket.Conn) {
+	defer s.wg.Done()
+
+	s.wsConnsMu.Lock()
+	defer s.wsConnsMu.Unlock()
+
+	delete(s.wsConns, ws)
+}
+
+// unblockWebSocketConns unblocks reads for all active WebSocket connections.
+func (s *ServerHTTPS) unblockWebSocketConns() {
+	s.wsConnsMu.Lock()
+	defer s.wsConnsMu.Unlock()
+
+	for ws := range s.wsConns {
+		err := ws.SetReadDeadline(time.Unix(1, 0))
+		if err != nil {
+			log.Debug("[%s]: Failed to set read deadline: %v", s.Name(), err)
+		}
+	}
+}
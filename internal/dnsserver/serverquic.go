@@ -13,6 +13,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/log"
+	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/AdguardTeam/golibs/syncutil"
 	"github.com/bluele/gcache"
 	"github.com/miekg/dns"
@@ -367,9 +368,14 @@ func (s *ServerQUIC) serveQUICConn(ctx context.Context, conn quic.Connection) (e
 			return err
 		}
 
+		state := conn.ConnectionState()
 		ri := &RequestInfo{
 			StartTime:     time.Now(),
-			TLSServerName: conn.ConnectionState().TLS.ServerName,
+			TLSServerName: state.TLS.ServerName,
+			ALPN:          state.TLS.NegotiatedProtocol,
+			Network:       NetworkUDP,
+			LocalAddr:     netutil.NetAddrToAddrPort(conn.LocalAddr()),
+			Used0RTT:      state.Used0RTT,
 		}
 
 		reqCtx, reqCancel := s.requestContext()
@@ -457,7 +463,7 @@ func (s *ServerQUIC) serveQUICStream(
 
 	// Normalize before writing the response.  Note that for QUIC we can
 	// normalize as if it was TCP.
-	normalizeTCP(ProtoDoQ, msg, resp)
+	normalizeTCP(ProtoDoQ, msg, resp, s.paddingBlockSize)
 
 	bufPtr := s.respPool.Get()
 	defer s.respPool.Put(bufPtr)
@@ -571,6 +577,8 @@ func (s *ServerQUIC) listenQUIC(ctx context.Context) (err error) {
 	}
 
 	qConf := newServerQUICConfig(s.conf.QUICLimitsEnabled, s.conf.MaxStreamsPerPeer)
+	setQUICAdvisorTracer(qConf, s.quicAdvisor)
+
 	ql, err := transport.Listen(s.conf.TLSConfig, qConf)
 	if err != nil {
 		return fmt.Errorf("listening quic: %w", err)
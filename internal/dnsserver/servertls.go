@@ -14,6 +14,14 @@ type ConfigTLS struct {
 	// TLSConfig is the TLS configuration for TLS.
 	TLSConfig *tls.Config
 
+	// TLSFingerprint is the configuration of the TLS client fingerprinting.
+	// If it is nil, the fingerprinting is disabled.
+	TLSFingerprint *TLSFingerprintConfig
+
+	// ConnInspector, if not nil, is used to inspect the connections after the
+	// TLS handshake and to set [RequestInfo.ConnHint] for their requests.
+	ConnInspector TLSConnInspector
+
 	ConfigDNS
 }
 
@@ -31,6 +39,8 @@ var _ Server = (*ServerTLS)(nil)
 // NewServerTLS creates a new ServerTLS instance.
 func NewServerTLS(conf ConfigTLS) (s *ServerTLS) {
 	srv := newServerDNS(ProtoDoT, conf.ConfigDNS)
+	srv.tlsConnInspector = conf.ConnInspector
+
 	s = &ServerTLS{
 		ServerDNS: srv,
 		conf:      conf,
@@ -109,7 +119,7 @@ func (s *ServerTLS) listenTLS(ctx context.Context) (err error) {
 		return err
 	}
 
-	s.tcpListener = newTLSListener(l, s.conf.TLSConfig)
+	s.tcpListener = newTLSListener(l, s.conf.TLSConfig, s.conf.TLSFingerprint)
 
 	return nil
 }
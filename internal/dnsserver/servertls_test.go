@@ -1,17 +1,22 @@
 package dnsserver_test
 
 import (
+	"context"
 	"crypto/tls"
 	"encoding/binary"
 	"io"
 	"net"
+	"net/netip"
 	"testing"
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/tlsfingerprint"
 	"github.com/AdguardTeam/golibs/log"
+	"github.com/AdguardTeam/golibs/testutil"
 	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -239,3 +244,228 @@ func TestServerTLS_integration_ENDS0Padding(t *testing.T) {
 	require.NotNil(t, paddingOpt)
 	require.NotEmpty(t, paddingOpt.Padding)
 }
+
+func TestServerTLS_integration_ENDS0PaddingBlock(t *testing.T) {
+	t.Parallel()
+
+	const blockSize = 468
+
+	tlsConfig := dnsservertest.CreateServerTLSConfig("example.org")
+	s := dnsserver.NewServerTLS(dnsserver.ConfigTLS{
+		ConfigDNS: dnsserver.ConfigDNS{
+			ConfigBase: dnsserver.ConfigBase{
+				Name:             "test",
+				Addr:             "127.0.0.1:0",
+				Handler:          dnsservertest.NewDefaultHandler(),
+				PaddingBlockSize: blockSize,
+			},
+		},
+		TLSConfig: tlsConfig,
+	})
+
+	err := s.Start(context.Background())
+	require.NoError(t, err)
+
+	testutil.CleanupAndRequireSuccess(t, func() (err error) {
+		return s.Shutdown(context.Background())
+	})
+
+	c := &dns.Client{Net: "tcp-tls", TLSConfig: tlsConfig}
+	addr := s.LocalTCPAddr().String()
+
+	t.Run("padded", func(t *testing.T) {
+		req := dnsservertest.CreateMessage("example.org.", dns.TypeA)
+		req.Extra = []dns.RR{dnsservertest.NewEDNS0Padding(req.Len(), dns.DefaultMsgSize)}
+
+		resp, _, exchErr := c.Exchange(req, addr)
+		require.NoError(t, exchErr)
+		require.NotNil(t, resp)
+
+		paddingOpt := dnsservertest.FindEDNS0Option[*dns.EDNS0_PADDING](resp)
+		require.NotNil(t, paddingOpt)
+
+		// The server always compresses the responses.
+		resp.Compress = true
+
+		assert.NotEmpty(t, paddingOpt.Padding)
+		assert.Zero(t, resp.Len()%blockSize)
+	})
+
+	t.Run("not_padded", func(t *testing.T) {
+		req := dnsservertest.CreateMessage("example.org.", dns.TypeA)
+		req.SetEdns0(dns.DefaultMsgSize, false)
+
+		resp, _, exchErr := c.Exchange(req, addr)
+		require.NoError(t, exchErr)
+		require.NotNil(t, resp)
+
+		paddingOpt := dnsservertest.FindEDNS0Option[*dns.EDNS0_PADDING](resp)
+		assert.Nil(t, paddingOpt)
+	})
+}
+
+func TestServerTLS_integration_tlsFingerprint(t *testing.T) {
+	t.Parallel()
+
+	h, fpCh := newFingerprintHandler()
+	tlsConfig := dnsservertest.CreateServerTLSConfig("example.org")
+
+	s := dnsserver.NewServerTLS(dnsserver.ConfigTLS{
+		ConfigDNS: dnsserver.ConfigDNS{
+			ConfigBase: dnsserver.ConfigBase{
+				Name:    "test",
+				Addr:    "127.0.0.1:0",
+				Handler: h,
+			},
+		},
+		TLSConfig: tlsConfig,
+		TLSFingerprint: &dnsserver.TLSFingerprintConfig{
+			KeepRaw: true,
+		},
+	})
+
+	err := s.Start(context.Background())
+	require.NoError(t, err)
+
+	testutil.CleanupAndRequireSuccess(t, func() (err error) {
+		return s.Shutdown(context.Background())
+	})
+
+	c := &dns.Client{
+		TLSConfig: tlsConfig,
+		Net:       "tcp-tls",
+	}
+
+	req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+	resp, _, err := c.Exchange(req, s.LocalTCPAddr().String())
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+
+	fp, _ := testutil.RequireReceive(t, fpCh, testTimeout)
+	require.NotNil(t, fp)
+
+	assert.Regexp(t, `^t13d[0-9]{4}00_[0-9a-f]{12}_[0-9a-f]{12}$`, fp.JA4)
+	assert.NotEmpty(t, fp.JA3Raw)
+	assert.NotEmpty(t, fp.JA4Raw)
+}
+
+// newFingerprintHandler returns a handler that sends the TLS fingerprints from
+// the request info to fpCh and responds with NOERROR.
+func newFingerprintHandler() (h dnsserver.Handler, fpCh chan *tlsfingerprint.Fingerprint) {
+	fpCh = make(chan *tlsfingerprint.Fingerprint, 1)
+	h = dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		select {
+		case fpCh <- dnsserver.MustRequestInfoFromContext(ctx).TLSFingerprint:
+		default:
+		}
+
+		return rw.WriteMsg(ctx, req, dnsservertest.NewResp(dns.RcodeSuccess, req))
+	})
+
+	return h, fpCh
+}
+
+// testTLSConnInspector is a [dnsserver.TLSConnInspector] for tests that returns
+// the server name as the hint and rejects the connections with the server name
+// equal to its value.
+type testTLSConnInspector string
+
+// type check
+var _ dnsserver.TLSConnInspector = testTLSConnInspector("")
+
+// InspectTLSConn implements the [dnsserver.TLSConnInspector] interface for
+// testTLSConnInspector.
+func (rejected testTLSConnInspector) InspectTLSConn(
+	_ context.Context,
+	serverName string,
+	_ netip.AddrPort,
+	_ netip.AddrPort,
+) (hint any, err error) {
+	if serverName == string(rejected) {
+		return nil, assert.AnError
+	}
+
+	return serverName, nil
+}
+
+func TestServerTLS_integration_connInspector(t *testing.T) {
+	t.Parallel()
+
+	const (
+		allowedName  = "example.org"
+		rejectedName = "rejected.example"
+	)
+
+	h := dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		rcode := dns.RcodeRefused
+		if dnsserver.MustRequestInfoFromContext(ctx).ConnHint == allowedName {
+			rcode = dns.RcodeSuccess
+		}
+
+		return rw.WriteMsg(ctx, req, dnsservertest.NewResp(rcode, req))
+	})
+
+	tlsConfig := dnsservertest.CreateServerTLSConfig(allowedName)
+
+	s := dnsserver.NewServerTLS(dnsserver.ConfigTLS{
+		ConfigDNS: dnsserver.ConfigDNS{
+			ConfigBase: dnsserver.ConfigBase{
+				Name:    "test",
+				Addr:    "127.0.0.1:0",
+				Handler: h,
+			},
+		},
+		TLSConfig:     tlsConfig,
+		ConnInspector: testTLSConnInspector(rejectedName),
+	})
+
+	err := s.Start(context.Background())
+	require.NoError(t, err)
+
+	testutil.CleanupAndRequireSuccess(t, func() (err error) {
+		return s.Shutdown(context.Background())
+	})
+
+	addr := s.LocalTCPAddr().String()
+
+	t.Run("allowed", func(t *testing.T) {
+		t.Parallel()
+
+		c := &dns.Client{
+			TLSConfig: tlsConfig,
+			Net:       "tcp-tls",
+		}
+
+		req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+		resp, _, exchErr := c.Exchange(req, addr)
+		require.NoError(t, exchErr)
+		require.NotNil(t, resp)
+
+		assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		t.Parallel()
+
+		cliConf := tlsConfig.Clone()
+		cliConf.ServerName = rejectedName
+		cliConf.InsecureSkipVerify = true
+
+		c := &dns.Client{
+			TLSConfig: cliConf,
+			Net:       "tcp-tls",
+		}
+
+		req := dnsservertest.NewReq("example.org.", dns.TypeA, dns.ClassINET)
+		_, _, exchErr := c.Exchange(req, addr)
+		assert.Error(t, exchErr)
+	})
+}
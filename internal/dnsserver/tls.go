@@ -1,10 +1,21 @@
 package dnsserver
 
 import (
+	"context"
 	"crypto/tls"
 	"net"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/tlsfingerprint"
 )
 
+// TLSFingerprintConfig is the configuration of the JA3 and JA4 fingerprinting
+// of the TLS clients.
+type TLSFingerprintConfig struct {
+	// KeepRaw, if true, makes the server keep the raw fingerprints along with
+	// the hashed ones.  Otherwise, only the hashed fingerprints are kept.
+	KeepRaw bool
+}
+
 // tlsListener is the implementation of net.Listener that accepts tls.Conn.
 // The only point of using our own implementation is to close underlying TCP
 // connections gracefully.
@@ -14,8 +25,17 @@ type tlsListener struct {
 	tlsConfig *tls.Config
 }
 
-// newTLSListener creates a new instance of tlsListener.
-func newTLSListener(l net.Listener, tlsConfig *tls.Config) (tlsListen *tlsListener) {
+// newTLSListener creates a new instance of tlsListener.  If fpConf is not nil,
+// the fingerprints of the accepted connections are calculated.
+func newTLSListener(
+	l net.Listener,
+	tlsConfig *tls.Config,
+	fpConf *TLSFingerprintConfig,
+) (tlsListen *tlsListener) {
+	if fpConf != nil {
+		l = tlsfingerprint.NewListener(l, fpConf.KeepRaw)
+	}
+
 	return &tlsListener{
 		tcp:       l,
 		tlsConfig: tlsConfig,
@@ -57,6 +77,21 @@ type tlsConn struct {
 	baseConn net.Conn // underlying TCP connection
 }
 
+// tlsFingerprinter is an interface for connections that can return the
+// fingerprints of the TLS client.
+type tlsFingerprinter interface {
+	// TLSFingerprint returns the fingerprints of the TLS client, if any.
+	TLSFingerprint() (fp *tlsfingerprint.Fingerprint)
+}
+
+// type check
+var _ tlsFingerprinter = (*tlsConn)(nil)
+
+// TLSFingerprint implements the [tlsFingerprinter] interface for *tlsConn.
+func (c *tlsConn) TLSFingerprint() (fp *tlsfingerprint.Fingerprint) {
+	return fingerprintFromNetConn(c.baseConn)
+}
+
 // type check
 var _ net.Conn = (*tlsConn)(nil)
 
@@ -66,3 +101,37 @@ var _ net.Conn = (*tlsConn)(nil)
 func (c *tlsConn) Close() (err error) {
 	return c.baseConn.Close()
 }
+
+// fingerprintFromNetConn returns the fingerprints of the TLS client if c is a
+// fingerprinting connection.  Otherwise, it returns nil.
+func fingerprintFromNetConn(c net.Conn) (fp *tlsfingerprint.Fingerprint) {
+	if fc, ok := c.(*tlsfingerprint.Conn); ok {
+		return fc.Fingerprint()
+	}
+
+	return nil
+}
+
+// tlsFingerprintConn returns the fingerprinting connection underlying c, if
+// any.
+func tlsFingerprintConn(c net.Conn) (fc *tlsfingerprint.Conn) {
+	tc, ok := c.(*tls.Conn)
+	if !ok {
+		return nil
+	}
+
+	fc, _ = tc.NetConn().(*tlsfingerprint.Conn)
+
+	return fc
+}
+
+// tlsFingerprintFromContext returns the fingerprints of the TLS client from
+// the connection data added to ctx by [ServerHTTPS.connContext], if any.
+func tlsFingerprintFromContext(ctx context.Context) (fp *tlsfingerprint.Fingerprint) {
+	ci, ok := connInfoFromContext(ctx)
+	if !ok || ci.tlsFingerprintConn == nil {
+		return nil
+	}
+
+	return ci.tlsFingerprintConn.Fingerprint()
+}
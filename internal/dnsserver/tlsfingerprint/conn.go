@@ -0,0 +1,128 @@
+package tlsfingerprint
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// MaxHelloLen is the maximum number of bytes recorded by [Conn] before it
+// gives up looking for a ClientHello message.
+const MaxHelloLen = 64 * 1024
+
+// Conn is a [net.Conn] that records the TLS ClientHello message read from the
+// underlying connection and calculates the fingerprints of the client.  The
+// recorded data is discarded as soon as the fingerprints are calculated.
+type Conn struct {
+	net.Conn
+
+	// mu protects buf and fp.
+	mu *sync.Mutex
+
+	// recording is true until the ClientHello message is complete or the
+	// recording is given up.
+	recording *atomic.Bool
+
+	// fp are the fingerprints of the client, if calculated.
+	fp *Fingerprint
+
+	// buf contains the data read from the connection so far.
+	buf []byte
+
+	// keepRaw defines if the raw fingerprints should be kept.
+	keepRaw bool
+}
+
+// NewConn returns a new properly initialized *Conn wrapping c.  If keepRaw is
+// true, the raw fingerprints are also kept.
+func NewConn(c net.Conn, keepRaw bool) (fc *Conn) {
+	recording := &atomic.Bool{}
+	recording.Store(true)
+
+	return &Conn{
+		Conn:      c,
+		mu:        &sync.Mutex{},
+		recording: recording,
+		keepRaw:   keepRaw,
+	}
+}
+
+// type check
+var _ net.Conn = (*Conn)(nil)
+
+// Read implements the [net.Conn] interface for *Conn.
+func (c *Conn) Read(b []byte) (n int, err error) {
+	n, err = c.Conn.Read(b)
+	if n > 0 && c.recording.Load() {
+		c.record(b[:n])
+	}
+
+	return n, err
+}
+
+// record appends b to the recorded data and calculates the fingerprints once
+// the ClientHello message is complete.
+func (c *Conn) record(b []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.recording.Load() {
+		return
+	}
+
+	c.buf = append(c.buf, b...)
+
+	fp, err := New(c.buf, c.keepRaw)
+	if errors.Is(err, errIncomplete) && len(c.buf) < MaxHelloLen {
+		return
+	}
+
+	// Any other error means that the client hasn't sent a valid ClientHello,
+	// which is reported by the TLS server anyway.
+	c.fp = fp
+	c.buf = nil
+	c.recording.Store(false)
+}
+
+// Fingerprint returns the fingerprints of the client.  fp is nil if the
+// ClientHello message hasn't been read yet or is invalid.
+func (c *Conn) Fingerprint() (fp *Fingerprint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.fp
+}
+
+// Listener is a [net.Listener] that wraps the accepted connections into
+// [*Conn].
+type Listener struct {
+	net.Listener
+
+	// keepRaw defines if the raw fingerprints should be kept.
+	keepRaw bool
+}
+
+// NewListener returns a new properly initialized *Listener wrapping l.  If
+// keepRaw is true, the raw fingerprints are also kept.
+func NewListener(l net.Listener, keepRaw bool) (fl *Listener) {
+	return &Listener{
+		Listener: l,
+		keepRaw:  keepRaw,
+	}
+}
+
+// type check
+var _ net.Listener = (*Listener)(nil)
+
+// Accept implements the [net.Listener] interface for *Listener.
+func (l *Listener) Accept() (c net.Conn, err error) {
+	c, err = l.Listener.Accept()
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	return NewConn(c, l.keepRaw), nil
+}
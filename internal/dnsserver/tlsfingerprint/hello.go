@@ -0,0 +1,230 @@
+package tlsfingerprint
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// errIncomplete is returned when the data doesn't contain the complete
+// ClientHello message yet.
+const errIncomplete errors.Error = "incomplete client hello"
+
+// errBadClientHello is returned when the ClientHello message is malformed.
+const errBadClientHello errors.Error = "bad client hello"
+
+// TLS protocol constants.  See RFC 8446.
+const (
+	recordHeaderLen    = 5
+	handshakeHeaderLen = 4
+
+	recordTypeHandshake      uint8 = 22
+	handshakeTypeClientHello uint8 = 1
+)
+
+// TLS extension types.  See RFC 8446, RFC 7301, and RFC 8422.
+const (
+	extServerName          uint16 = 0x0000
+	extSupportedGroups     uint16 = 0x000a
+	extECPointFormats      uint16 = 0x000b
+	extSignatureAlgorithms uint16 = 0x000d
+	extALPN                uint16 = 0x0010
+	extSupportedVersions   uint16 = 0x002b
+)
+
+// clientHello contains the data of a ClientHello message required for the
+// fingerprints.  The GREASE values are not included.
+type clientHello struct {
+	// alpn is the first protocol of the ALPN extension, if any.
+	alpn string
+
+	// ciphers are the cipher suites in the order sent by the client.
+	ciphers []uint16
+
+	// extensions are the types of the extensions in the order sent by the
+	// client.
+	extensions []uint16
+
+	// curves are the supported groups in the order sent by the client.
+	curves []uint16
+
+	// sigAlgs are the signature algorithms in the order sent by the client.
+	sigAlgs []uint16
+
+	// versions are the supported versions in the order sent by the client.
+	versions []uint16
+
+	// points are the EC point formats in the order sent by the client.
+	points []uint8
+
+	// version is the legacy version of the ClientHello message.
+	version uint16
+
+	// hasSNI is true if the client has sent the server name extension.
+	hasSNI bool
+}
+
+// handshakeMsg returns the first handshake message contained in the TLS
+// records in b.  err is [errIncomplete] if b doesn't contain the complete
+// message yet.
+func handshakeMsg(b []byte) (msg []byte, err error) {
+	var hs []byte
+	for len(b) > 0 {
+		if len(b) < recordHeaderLen {
+			return nil, errIncomplete
+		}
+
+		if typ := b[0]; typ != recordTypeHandshake {
+			return nil, fmt.Errorf("record type: %w: %d", errors.ErrBadEnumValue, typ)
+		}
+
+		n := recordHeaderLen + int(binary.BigEndian.Uint16(b[3:recordHeaderLen]))
+		if len(b) < n {
+			return nil, errIncomplete
+		}
+
+		hs = append(hs, b[recordHeaderLen:n]...)
+		b = b[n:]
+
+		if len(hs) < handshakeHeaderLen {
+			continue
+		}
+
+		msgLen := handshakeHeaderLen + (int(hs[1])<<16 | int(hs[2])<<8 | int(hs[3]))
+		if len(hs) >= msgLen {
+			return hs[:msgLen], nil
+		}
+	}
+
+	return nil, errIncomplete
+}
+
+// parseClientHello parses the ClientHello handshake message msg.
+func parseClientHello(msg []byte) (h *clientHello, err error) {
+	s := cryptobyte.String(msg)
+
+	var typ uint8
+	var body cryptobyte.String
+	if !s.ReadUint8(&typ) || !s.ReadUint24LengthPrefixed(&body) {
+		return nil, errBadClientHello
+	} else if typ != handshakeTypeClientHello {
+		return nil, fmt.Errorf("handshake type: %w: %d", errors.ErrBadEnumValue, typ)
+	}
+
+	h = &clientHello{}
+
+	var sessionID, ciphers, compression cryptobyte.String
+	if !body.ReadUint16(&h.version) ||
+		!body.Skip(32) ||
+		!body.ReadUint8LengthPrefixed(&sessionID) ||
+		!body.ReadUint16LengthPrefixed(&ciphers) ||
+		!body.ReadUint8LengthPrefixed(&compression) {
+		return nil, errBadClientHello
+	}
+
+	h.ciphers, err = readUint16s(ciphers)
+	if err != nil {
+		return nil, fmt.Errorf("cipher suites: %w", err)
+	}
+
+	if body.Empty() {
+		// No extensions.
+		return h, nil
+	}
+
+	var exts cryptobyte.String
+	if !body.ReadUint16LengthPrefixed(&exts) {
+		return nil, errBadClientHello
+	}
+
+	for !exts.Empty() {
+		var extType uint16
+		var data cryptobyte.String
+		if !exts.ReadUint16(&extType) || !exts.ReadUint16LengthPrefixed(&data) {
+			return nil, errBadClientHello
+		}
+
+		if isGREASE(extType) {
+			continue
+		}
+
+		h.extensions = append(h.extensions, extType)
+
+		err = h.parseExtension(extType, data)
+		if err != nil {
+			return nil, fmt.Errorf("extension %d: %w", extType, err)
+		}
+	}
+
+	return h, nil
+}
+
+// parseExtension sets the fields of h from the data of the extension with the
+// given type, if the extension is required for the fingerprints.
+func (h *clientHello) parseExtension(extType uint16, data cryptobyte.String) (err error) {
+	var list cryptobyte.String
+	switch extType {
+	case extServerName:
+		h.hasSNI = true
+	case extSupportedGroups:
+		if !data.ReadUint16LengthPrefixed(&list) {
+			return errBadClientHello
+		}
+
+		h.curves, err = readUint16s(list)
+	case extECPointFormats:
+		if !data.ReadUint8LengthPrefixed(&list) {
+			return errBadClientHello
+		}
+
+		h.points = list
+	case extSignatureAlgorithms:
+		if !data.ReadUint16LengthPrefixed(&list) {
+			return errBadClientHello
+		}
+
+		h.sigAlgs, err = readUint16s(list)
+	case extALPN:
+		var proto cryptobyte.String
+		if !data.ReadUint16LengthPrefixed(&list) || !list.ReadUint8LengthPrefixed(&proto) {
+			return errBadClientHello
+		}
+
+		h.alpn = string(proto)
+	case extSupportedVersions:
+		if !data.ReadUint8LengthPrefixed(&list) {
+			return errBadClientHello
+		}
+
+		h.versions, err = readUint16s(list)
+	default:
+		// Not required for the fingerprints.
+	}
+
+	return err
+}
+
+// readUint16s reads all big-endian uint16 values from s skipping the GREASE
+// ones.
+func readUint16s(s cryptobyte.String) (vals []uint16, err error) {
+	for !s.Empty() {
+		var v uint16
+		if !s.ReadUint16(&v) {
+			return nil, errBadClientHello
+		}
+
+		if !isGREASE(v) {
+			vals = append(vals, v)
+		}
+	}
+
+	return vals, nil
+}
+
+// isGREASE returns true if v is one of the reserved GREASE values.  See RFC
+// 8701.
+func isGREASE(v uint16) (ok bool) {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
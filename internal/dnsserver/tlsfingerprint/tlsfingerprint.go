@@ -0,0 +1,202 @@
+// Package tlsfingerprint contains the JA3 and JA4 fingerprinting of the TLS
+// clients using their ClientHello messages.
+//
+// See https://github.com/salesforce/ja3 and
+// https://github.com/FoxIO-LLC/ja4/blob/main/technical_details/JA4.md.
+package tlsfingerprint
+
+import (
+	// #nosec G501 -- JA3 is defined using MD5, and it's not used for security
+	// purposes here.
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Fingerprint contains the fingerprints of the ClientHello message of a TLS
+// client.
+type Fingerprint struct {
+	// JA3 is the hex-encoded MD5 hash of the JA3 string.
+	JA3 string
+
+	// JA4 is the JA4 fingerprint with the hashed lists of the cipher suites and
+	// the extensions.
+	JA4 string
+
+	// JA3Raw is the JA3 string.  It is only set if the raw fingerprints are
+	// kept.
+	JA3Raw string
+
+	// JA4Raw is the raw JA4 fingerprint, also known as JA4_r.  It is only set
+	// if the raw fingerprints are kept.
+	JA4Raw string
+}
+
+// New returns the fingerprints of the ClientHello message contained in the TLS
+// records in b.  If keepRaw is true, the raw fingerprints are also set.
+func New(b []byte, keepRaw bool) (fp *Fingerprint, err error) {
+	msg, err := handshakeMsg(b)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	h, err := parseClientHello(msg)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	return newFingerprint(h, keepRaw), nil
+}
+
+// newFingerprint returns the fingerprints of h.
+func newFingerprint(h *clientHello, keepRaw bool) (fp *Fingerprint) {
+	ja3Raw := ja3String(h)
+	// #nosec G401 -- See the comment on the import.
+	ja3Sum := md5.Sum([]byte(ja3Raw))
+
+	ja4a := ja4Prefix(h)
+	ciphers := sortedHex(h.ciphers)
+	exts := sortedHex(slices.DeleteFunc(slices.Clone(h.extensions), func(t uint16) (ok bool) {
+		return t == extServerName || t == extALPN
+	}))
+
+	extsAndAlgs := exts
+	if len(h.sigAlgs) > 0 {
+		extsAndAlgs += "_" + hexList(h.sigAlgs)
+	}
+
+	fp = &Fingerprint{
+		JA3: hex.EncodeToString(ja3Sum[:]),
+		JA4: ja4a + "_" + hash12(ciphers) + "_" + hash12(extsAndAlgs),
+	}
+
+	if keepRaw {
+		fp.JA3Raw = ja3Raw
+		fp.JA4Raw = ja4a + "_" + ciphers + "_" + extsAndAlgs
+	}
+
+	return fp
+}
+
+// ja3String returns the JA3 string of h.
+func ja3String(h *clientHello) (s string) {
+	points := make([]uint16, 0, len(h.points))
+	for _, p := range h.points {
+		points = append(points, uint16(p))
+	}
+
+	return strings.Join([]string{
+		strconv.FormatUint(uint64(h.version), 10),
+		decList(h.ciphers),
+		decList(h.extensions),
+		decList(h.curves),
+		decList(points),
+	}, ",")
+}
+
+// decList returns the dash-separated decimal representation of vals.
+func decList(vals []uint16) (s string) {
+	strs := make([]string, 0, len(vals))
+	for _, v := range vals {
+		strs = append(strs, strconv.FormatUint(uint64(v), 10))
+	}
+
+	return strings.Join(strs, "-")
+}
+
+// ja4Prefix returns the first, unhashed, part of the JA4 fingerprint of h.
+func ja4Prefix(h *clientHello) (s string) {
+	sni := "i"
+	if h.hasSNI {
+		sni = "d"
+	}
+
+	return fmt.Sprintf(
+		"t%s%s%02d%02d%s",
+		ja4Version(h),
+		sni,
+		min(len(h.ciphers), 99),
+		min(len(h.extensions), 99),
+		ja4ALPN(h.alpn),
+	)
+}
+
+// ja4Version returns the JA4 representation of the highest TLS version
+// supported by the client.
+func ja4Version(h *clientHello) (s string) {
+	v := h.version
+	if len(h.versions) > 0 {
+		v = slices.Max(h.versions)
+	}
+
+	switch v {
+	case 0x0304:
+		return "13"
+	case 0x0303:
+		return "12"
+	case 0x0302:
+		return "11"
+	case 0x0301:
+		return "10"
+	case 0x0300:
+		return "s3"
+	default:
+		return "00"
+	}
+}
+
+// ja4ALPN returns the JA4 representation of the first ALPN protocol.
+func ja4ALPN(alpn string) (s string) {
+	if alpn == "" {
+		return "00"
+	}
+
+	first, last := alpn[0], alpn[len(alpn)-1]
+	if isAlnum(first) && isAlnum(last) {
+		return string([]byte{first, last})
+	}
+
+	h := hex.EncodeToString([]byte(alpn))
+
+	return string([]byte{h[0], h[len(h)-1]})
+}
+
+// isAlnum returns true if c is an ASCII letter or digit.
+func isAlnum(c byte) (ok bool) {
+	return (c >= '0' && c <= '9') || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+// sortedHex returns the comma-separated, sorted hexadecimal representation of
+// vals.
+func sortedHex(vals []uint16) (s string) {
+	return hexList(slices.Sorted(slices.Values(vals)))
+}
+
+// hexList returns the comma-separated four-digit hexadecimal representation of
+// vals.
+func hexList(vals []uint16) (s string) {
+	strs := make([]string, 0, len(vals))
+	for _, v := range vals {
+		strs = append(strs, fmt.Sprintf("%04x", v))
+	}
+
+	return strings.Join(strs, ",")
+}
+
+// hash12 returns the first 12 characters of the hex-encoded SHA-256 hash of s
+// or zeroes if s is empty.
+func hash12(s string) (h string) {
+	if s == "" {
+		return "000000000000"
+	}
+
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:6])
+}
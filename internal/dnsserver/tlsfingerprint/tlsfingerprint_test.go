@@ -0,0 +1,246 @@
+package tlsfingerprint_test
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/tlsfingerprint"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/cryptobyte"
+)
+
+// testGREASE is a GREASE value for tests.
+const testGREASE uint16 = 0x0a0a
+
+// newClientHello returns a ClientHello handshake message with GREASE values,
+// SNI, and ALPN.
+func newClientHello(tb testing.TB) (msg []byte) {
+	tb.Helper()
+
+	b := cryptobyte.NewBuilder(nil)
+	b.AddUint8(1)
+	b.AddUint24LengthPrefixed(func(b *cryptobyte.Builder) {
+		b.AddUint16(tls.VersionTLS12)
+		b.AddBytes(make([]byte, 32))
+		b.AddUint8LengthPrefixed(func(_ *cryptobyte.Builder) {})
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			b.AddUint16(testGREASE)
+			b.AddUint16(tls.TLS_AES_128_GCM_SHA256)
+			b.AddUint16(tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256)
+		})
+		b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddUint8(0) })
+		b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+			addExt(b, testGREASE, func(_ *cryptobyte.Builder) {})
+			addExt(b, 0x0000, func(b *cryptobyte.Builder) {
+				b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+					b.AddUint8(0)
+					b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+						b.AddBytes([]byte("dns.example"))
+					})
+				})
+			})
+			addExt(b, 0x000a, func(b *cryptobyte.Builder) {
+				b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+					b.AddUint16(testGREASE)
+					b.AddUint16(uint16(tls.X25519))
+					b.AddUint16(uint16(tls.CurveP256))
+				})
+			})
+			addExt(b, 0x000b, func(b *cryptobyte.Builder) {
+				b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) { b.AddUint8(0) })
+			})
+			addExt(b, 0x000d, func(b *cryptobyte.Builder) {
+				b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+					b.AddUint16(uint16(tls.ECDSAWithP256AndSHA256))
+					b.AddUint16(uint16(tls.PSSWithSHA256))
+				})
+			})
+			addExt(b, 0x0010, func(b *cryptobyte.Builder) {
+				b.AddUint16LengthPrefixed(func(b *cryptobyte.Builder) {
+					b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+						b.AddBytes([]byte("h2"))
+					})
+				})
+			})
+			addExt(b, 0x002b, func(b *cryptobyte.Builder) {
+				b.AddUint8LengthPrefixed(func(b *cryptobyte.Builder) {
+					b.AddUint16(testGREASE)
+					b.AddUint16(tls.VersionTLS13)
+					b.AddUint16(tls.VersionTLS12)
+				})
+			})
+		})
+	})
+
+	return b.BytesOrPanic()
+}
+
+// addExt adds a TLS extension with the given type and data to b.
+func addExt(b *cryptobyte.Builder, typ uint16, data cryptobyte.BuilderContinuation) {
+	b.AddUint16(typ)
+	b.AddUint16LengthPrefixed(data)
+}
+
+// newRecords splits msg into handshake TLS records of at most n bytes each.
+func newRecords(msg []byte, n int) (records []byte) {
+	for len(msg) > 0 {
+		l := min(n, len(msg))
+		records = append(records, 22, 3, 1, byte(l>>8), byte(l))
+		records = append(records, msg[:l]...)
+		msg = msg[l:]
+	}
+
+	return records
+}
+
+// hash12 returns the truncated SHA-256 hash as used in JA4.
+func hash12(s string) (h string) {
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:6])
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	const (
+		wantJA3Raw = "771,4865-49195,0-10-11-13-16-43,29-23,0"
+		wantJA4a   = "t13d0206h2"
+		wantCiph   = "1301,c02b"
+		wantExts   = "000a,000b,000d,002b_0403,0804"
+	)
+
+	ja3Sum := md5.Sum([]byte(wantJA3Raw))
+	wantJA3 := hex.EncodeToString(ja3Sum[:])
+	wantJA4 := wantJA4a + "_" + hash12(wantCiph) + "_" + hash12(wantExts)
+
+	msg := newClientHello(t)
+
+	testCases := []struct {
+		want    *tlsfingerprint.Fingerprint
+		name    string
+		recLen  int
+		keepRaw bool
+	}{{
+		want: &tlsfingerprint.Fingerprint{
+			JA3: wantJA3,
+			JA4: wantJA4,
+		},
+		name:    "hashed",
+		recLen:  len(msg),
+		keepRaw: false,
+	}, {
+		want: &tlsfingerprint.Fingerprint{
+			JA3:    wantJA3,
+			JA4:    wantJA4,
+			JA3Raw: wantJA3Raw,
+			JA4Raw: wantJA4a + "_" + wantCiph + "_" + wantExts,
+		},
+		name:    "raw",
+		recLen:  len(msg),
+		keepRaw: true,
+	}, {
+		want: &tlsfingerprint.Fingerprint{
+			JA3: wantJA3,
+			JA4: wantJA4,
+		},
+		name:    "fragmented",
+		recLen:  3,
+		keepRaw: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fp, err := tlsfingerprint.New(newRecords(msg, tc.recLen), tc.keepRaw)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, fp)
+		})
+	}
+}
+
+func TestNew_errors(t *testing.T) {
+	t.Parallel()
+
+	msg := newClientHello(t)
+	records := newRecords(msg, len(msg))
+
+	testCases := []struct {
+		name       string
+		wantErrMsg string
+		data       []byte
+	}{{
+		name:       "incomplete",
+		wantErrMsg: "incomplete client hello",
+		data:       records[:len(records)-1],
+	}, {
+		name:       "not_handshake",
+		wantErrMsg: "record type: bad enum value: 23",
+		data:       []byte{23, 3, 3, 0, 0},
+	}, {
+		name:       "not_client_hello",
+		wantErrMsg: "handshake type: bad enum value: 2",
+		data:       newRecords([]byte{2, 0, 0, 0}, 4),
+	}, {
+		name:       "bad_client_hello",
+		wantErrMsg: "bad client hello",
+		data:       newRecords([]byte{1, 0, 0, 1, 0}, 5),
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			fp, err := tlsfingerprint.New(tc.data, false)
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, err)
+			assert.Nil(t, fp)
+		})
+	}
+}
+
+func TestConn(t *testing.T) {
+	t.Parallel()
+
+	tlsConf := dnsservertest.CreateServerTLSConfig("dns.example")
+	tlsConf.NextProtos = []string{"dot"}
+
+	clientConn, serverConn := net.Pipe()
+	testutil.CleanupAndRequireSuccess(t, clientConn.Close)
+	testutil.CleanupAndRequireSuccess(t, serverConn.Close)
+
+	fpConn := tlsfingerprint.NewConn(serverConn, false)
+	assert.Nil(t, fpConn.Fingerprint())
+
+	srv := tls.Server(fpConn, tlsConf)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- srv.Handshake()
+	}()
+
+	cli := tls.Client(clientConn, &tls.Config{
+		ServerName:         "dns.example",
+		NextProtos:         []string{"dot"},
+		InsecureSkipVerify: true,
+	})
+
+	require.NoError(t, cli.Handshake())
+	require.NoError(t, <-errCh)
+
+	fp := fpConn.Fingerprint()
+	require.NotNil(t, fp)
+
+	assert.Regexp(t, `^[0-9a-f]{32}$`, fp.JA3)
+	assert.Regexp(t, `^t13d[0-9]{4}dt_[0-9a-f]{12}_[0-9a-f]{12}$`, fp.JA4)
+	assert.Empty(t, fp.JA3Raw)
+	assert.Empty(t, fp.JA4Raw)
+}
@@ -0,0 +1,70 @@
+package dnsserver
+
+import (
+	"context"
+
+	"github.com/miekg/dns"
+)
+
+// ClampedUDPRespSize is the maximum size of DNS responses over UDP used with
+// [UDPTruncationClamp].  It is the value recommended by the DNS Flag Day 2020.
+//
+// See https://www.dnsflagday.net/2020.
+const ClampedUDPRespSize uint16 = 1232
+
+// UDPTruncationPolicy defines how the maximum size of DNS responses over UDP
+// is calculated.
+type UDPTruncationPolicy uint8
+
+// UDPTruncationPolicy values.
+const (
+	// UDPTruncationEDNS makes the server respect the UDP payload size
+	// advertised by the client in its EDNS(0) OPT record, limited by
+	// [ConfigDNS.MaxUDPRespSize].  This is the default policy.
+	UDPTruncationEDNS UDPTruncationPolicy = iota
+
+	// UDPTruncationClamp is like [UDPTruncationEDNS], but the size is also
+	// limited by [ClampedUDPRespSize].
+	UDPTruncationClamp
+
+	// UDPTruncationMinimal makes the server always truncate responses larger
+	// than [dns.MinMsgSize], regardless of the size advertised by the client.
+	UDPTruncationMinimal
+)
+
+// KnownClientChecker checks if the client of a DNS request is known to the
+// server's user.  Responses to the unknown clients over UDP may be truncated
+// preemptively to make them retry over TCP.
+//
+// NOTE:  Implementations must be thread-safe.
+type KnownClientChecker interface {
+	// IsKnownClient returns true if the client of the request is known.  ctx is
+	// the context with which the response is written.
+	IsKnownClient(ctx context.Context) (ok bool)
+}
+
+// maxUDPRespSize returns the maximum size of a response over UDP for the given
+// truncation policy.  maxSize is the configured maximum size of UDP responses.
+// If checker is not nil and the client isn't known, the size is limited to
+// [dns.MinMsgSize].
+func maxUDPRespSize(
+	ctx context.Context,
+	policy UDPTruncationPolicy,
+	checker KnownClientChecker,
+	maxSize uint16,
+) (n uint16) {
+	switch policy {
+	case UDPTruncationClamp:
+		maxSize = min(maxSize, ClampedUDPRespSize)
+	case UDPTruncationMinimal:
+		maxSize = dns.MinMsgSize
+	default:
+		// Go on.
+	}
+
+	if checker != nil && !checker.IsKnownClient(ctx) {
+		maxSize = min(maxSize, dns.MinMsgSize)
+	}
+
+	return maxSize
+}
@@ -47,6 +47,13 @@ func minimalTTLMsgRRs(m *dns.Msg) (d time.Duration) {
 
 	for _, r := range m.Ns {
 		minTTL32 = min(minTTL32, r.Header().Ttl)
+
+		// As per RFC 8484, Section 5.1, the freshness lifetime of a response
+		// without answers must not be greater than the MINIMUM field of the
+		// SOA record.
+		if soa, ok := r.(*dns.SOA); ok && len(m.Answer) == 0 {
+			minTTL32 = min(minTTL32, soa.Minttl)
+		}
 	}
 
 	for _, r := range m.Extra {
@@ -0,0 +1,304 @@
+// Package dnssign contains the on-node DNSSEC signer of the synthesized
+// responses, such as the blocked responses and the answers to the DDR queries,
+// within a zone controlled by the operator.
+package dnssign
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/miekg/dns"
+)
+
+// inceptionOffset is the offset of the inception time of the signatures into
+// the past, which accounts for the clock skew of the validators.
+const inceptionOffset = 1 * time.Hour
+
+// KeyPaths are the paths to the files of a DNSSEC key in the BIND format, as
+// produced by dnssec-keygen.
+type KeyPaths struct {
+	// Public is the path to the file with the DNSKEY record.  It must not be
+	// empty.
+	Public string
+
+	// Private is the path to the file with the private key.  It must not be
+	// empty.
+	Private string
+}
+
+// Config is the configuration structure for a *Signer.
+type Config struct {
+	// Logger is used to log the operation of the signer.  It must not be nil.
+	Logger *slog.Logger
+
+	// Clock is used to set the validity period of the signatures.  It must not
+	// be nil.
+	Clock agdtime.Clock
+
+	// ErrColl is used to collect the refresh errors.  It must not be nil.
+	ErrColl errcoll.Interface
+
+	// KSK are the paths to the key-signing key, which is used to sign the
+	// DNSKEY records of the zone.  It must not be nil, and the key must have
+	// the SEP flag set.
+	KSK *KeyPaths
+
+	// ZSK are the paths to the zone-signing key, which is used to sign all
+	// other records.  It must not be nil, and the key must not have the SEP
+	// flag set.
+	ZSK *KeyPaths
+
+	// Zone is the zone within which the records are signed.  It must be a
+	// valid domain name.  The owner names of the keys must be the same as
+	// Zone.
+	Zone string
+
+	// Validity is the validity period of the signatures.  It must be
+	// positive.
+	Validity time.Duration
+}
+
+// Signer signs the synthesized records within a zone.  It should be initially
+// refreshed before use.
+type Signer struct {
+	logger   *slog.Logger
+	clock    agdtime.Clock
+	errColl  errcoll.Interface
+	kskPaths *KeyPaths
+	zskPaths *KeyPaths
+
+	// mu protects ksk and zsk.
+	mu *sync.RWMutex
+
+	ksk *key
+
+	zsk      *key
+	zone     string
+	validity time.Duration
+}
+
+// New returns a new properly initialized *Signer.  c must be valid.
+func New(c *Config) (s *Signer) {
+	return &Signer{
+		logger:   c.Logger,
+		clock:    c.Clock,
+		errColl:  c.ErrColl,
+		kskPaths: c.KSK,
+		zskPaths: c.ZSK,
+		zone:     dns.CanonicalName(c.Zone),
+		validity: c.Validity,
+		mu:       &sync.RWMutex{},
+	}
+}
+
+// Zone returns the lowercased FQDN of the zone of the signer.
+func (s *Signer) Zone() (zone string) {
+	return s.zone
+}
+
+// type check
+var _ agdservice.Refresher = (*Signer)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for *Signer.  It
+// rereads the key files.  If any of the keys fails to load, the previous keys
+// are kept.
+func (s *Signer) Refresh(ctx context.Context) (err error) {
+	s.logger.DebugContext(ctx, "refresh started")
+	defer s.logger.DebugContext(ctx, "refresh finished")
+
+	ksk, zsk, err := s.loadKeys()
+	if err != nil {
+		errcoll.Collect(ctx, s.errColl, s.logger, "refreshing dnssec keys", err)
+
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.ksk, s.zsk = ksk, zsk
+
+	s.logger.InfoContext(
+		ctx,
+		"refresh successful",
+		"ksk_tag", ksk.pub.KeyTag(),
+		"zsk_tag", zsk.pub.KeyTag(),
+	)
+
+	return nil
+}
+
+// loadKeys loads and checks both keys of the signer.
+func (s *Signer) loadKeys() (ksk, zsk *key, err error) {
+	ksk, err = loadKey(s.kskPaths, s.zone, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ksk: %w", err)
+	}
+
+	zsk, err = loadKey(s.zskPaths, s.zone, false)
+	if err != nil {
+		return nil, nil, fmt.Errorf("zsk: %w", err)
+	}
+
+	return ksk, zsk, nil
+}
+
+// keys returns the current keys.  Both are nil if the signer hasn't been
+// refreshed successfully yet.
+func (s *Signer) keys() (ksk, zsk *key) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.ksk, s.zsk
+}
+
+// InZone returns true if name is within the zone of the signer.  name must be
+// an FQDN.
+func (s *Signer) InZone(name string) (ok bool) {
+	return dns.IsSubDomain(s.zone, strings.ToLower(name))
+}
+
+// Sign adds the RRSIG records for the RRsets within the zone in the answer and
+// authority sections of resp, which don't have them already.  It does nothing
+// if the keys haven't been loaded yet.
+func (s *Signer) Sign(resp *dns.Msg) (err error) {
+	_, zsk := s.keys()
+	if zsk == nil {
+		return nil
+	}
+
+	resp.Answer, err = s.signSection(resp.Answer, zsk)
+	if err != nil {
+		return fmt.Errorf("answer: %w", err)
+	}
+
+	resp.Ns, err = s.signSection(resp.Ns, zsk)
+	if err != nil {
+		return fmt.Errorf("authority: %w", err)
+	}
+
+	return nil
+}
+
+// DNSKEYs returns the DNSKEY records of the zone with the signature made by the
+// key-signing key.  rrs is nil if the keys haven't been loaded yet.
+func (s *Signer) DNSKEYs() (rrs []dns.RR, err error) {
+	ksk, zsk := s.keys()
+	if ksk == nil {
+		return nil, nil
+	}
+
+	rrs = []dns.RR{dns.Copy(ksk.pub), dns.Copy(zsk.pub)}
+	sig, err := s.sign(rrs, ksk)
+	if err != nil {
+		return nil, fmt.Errorf("signing dnskey: %w", err)
+	}
+
+	return append(rrs, sig), nil
+}
+
+// signSection returns rrs with the signatures for the RRsets within the zone
+// appended.
+func (s *Signer) signSection(rrs []dns.RR, k *key) (res []dns.RR, err error) {
+	res = rrs
+	for _, set := range s.unsignedSets(rrs) {
+		var sig *dns.RRSIG
+		sig, err = s.sign(set, k)
+		if err != nil {
+			name := set[0].Header().Name
+
+			return nil, fmt.Errorf("signing %s %s: %w", name, typeOf(set), err)
+		}
+
+		res = append(res, sig)
+	}
+
+	return res, nil
+}
+
+// rrSetKey is the key of an RRset.
+type rrSetKey struct {
+	name  string
+	rrTyp uint16
+}
+
+// unsignedSets returns the RRsets within the zone from rrs, which aren't
+// covered by any RRSIG record in rrs, in the order of their first appearance.
+func (s *Signer) unsignedSets(rrs []dns.RR) (sets [][]dns.RR) {
+	signed := map[rrSetKey]struct{}{}
+	for _, rr := range rrs {
+		sig, ok := rr.(*dns.RRSIG)
+		if ok {
+			k := rrSetKey{name: strings.ToLower(sig.Hdr.Name), rrTyp: sig.TypeCovered}
+			signed[k] = struct{}{}
+		}
+	}
+
+	idx := map[rrSetKey]int{}
+	for _, rr := range rrs {
+		hdr := rr.Header()
+		if hdr.Rrtype == dns.TypeRRSIG || !s.InZone(hdr.Name) {
+			continue
+		}
+
+		k := rrSetKey{name: strings.ToLower(hdr.Name), rrTyp: hdr.Rrtype}
+		if _, ok := signed[k]; ok {
+			continue
+		}
+
+		i, ok := idx[k]
+		if !ok {
+			i = len(sets)
+			idx[k] = i
+			sets = append(sets, nil)
+		}
+
+		sets[i] = append(sets[i], rr)
+	}
+
+	return sets
+}
+
+// sign returns the signature of set made with k.  set must not be empty.
+func (s *Signer) sign(set []dns.RR, k *key) (sig *dns.RRSIG, err error) {
+	now := s.clock.Now()
+	hdr := set[0].Header()
+	sig = &dns.RRSIG{
+		Hdr: dns.RR_Header{
+			Name:   hdr.Name,
+			Rrtype: dns.TypeRRSIG,
+			Class:  hdr.Class,
+			Ttl:    hdr.Ttl,
+		},
+		Algorithm:  k.pub.Algorithm,
+		KeyTag:     k.pub.KeyTag(),
+		SignerName: k.pub.Hdr.Name,
+		// #nosec G115 -- The signature timestamps use serial number
+		// arithmetic, so the overflow is expected.
+		Inception: uint32(now.Add(-inceptionOffset).Unix()),
+		// #nosec G115 -- See above.
+		Expiration: uint32(now.Add(s.validity).Unix()),
+	}
+
+	err = sig.Sign(k.priv, set)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	return sig, nil
+}
+
+// typeOf returns the string representation of the type of set.  set must not
+// be empty.
+func typeOf(set []dns.RR) (s string) {
+	return dns.Type(set[0].Header().Rrtype).String()
+}
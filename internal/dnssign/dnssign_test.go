@@ -0,0 +1,192 @@
+package dnssign_test
+
+import (
+	"context"
+	"crypto"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssign"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// testZone is the common signed zone for tests.
+const testZone = "block.example."
+
+// testNow is the common current time for tests.
+var testNow = time.Unix(1_700_000_000, 0)
+
+// newKeyFiles is a helper that generates a new ECDSA key for testZone with
+// flags and writes it into files within dir.
+func newKeyFiles(tb testing.TB, dir string, flags uint16) (paths *dnssign.KeyPaths) {
+	tb.Helper()
+
+	pub := &dns.DNSKEY{
+		Hdr: dns.RR_Header{
+			Name:   testZone,
+			Rrtype: dns.TypeDNSKEY,
+			Class:  dns.ClassINET,
+			Ttl:    3600,
+		},
+		Flags:     flags,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+
+	priv, err := pub.Generate(256)
+	require.NoError(tb, err)
+
+	paths = &dnssign.KeyPaths{
+		Public:  filepath.Join(dir, "pub"),
+		Private: filepath.Join(dir, "priv"),
+	}
+
+	err = os.WriteFile(paths.Public, []byte(pub.String()+"\n"), 0o600)
+	require.NoError(tb, err)
+
+	err = os.WriteFile(paths.Private, []byte(pub.PrivateKeyString(priv.(crypto.Signer))), 0o600)
+	require.NoError(tb, err)
+
+	return paths
+}
+
+// newSigner is a helper that returns a new refreshed signer with new keys.
+func newSigner(tb testing.TB) (s *dnssign.Signer) {
+	tb.Helper()
+
+	kskDir, zskDir := tb.TempDir(), tb.TempDir()
+	s = dnssign.New(&dnssign.Config{
+		Logger: slogutil.NewDiscardLogger(),
+		Clock: &agdtest.Clock{
+			OnNow: func() (now time.Time) { return testNow },
+		},
+		ErrColl:  agdtest.NewErrorCollector(),
+		KSK:      newKeyFiles(tb, kskDir, dns.ZONE|dns.SEP),
+		ZSK:      newKeyFiles(tb, zskDir, dns.ZONE),
+		Zone:     "Block.Example",
+		Validity: 24 * time.Hour,
+	})
+
+	ctx := testutil.ContextWithTimeout(tb, testTimeout)
+	require.NoError(tb, s.Refresh(ctx))
+
+	return s
+}
+
+// findKey returns the DNSKEY with the tag from rrs.
+func findKey(rrs []dns.RR, tag uint16) (k *dns.DNSKEY) {
+	for _, rr := range rrs {
+		k, ok := rr.(*dns.DNSKEY)
+		if ok && k.KeyTag() == tag {
+			return k
+		}
+	}
+
+	return nil
+}
+
+func TestSigner_Sign(t *testing.T) {
+	t.Parallel()
+
+	s := newSigner(t)
+	assert.Equal(t, testZone, s.Zone())
+
+	keys, err := s.DNSKEYs()
+	require.NoError(t, err)
+	require.Len(t, keys, 3)
+
+	dnskeySig := testutil.RequireTypeAssert[*dns.RRSIG](t, keys[2])
+	ksk := findKey(keys, dnskeySig.KeyTag)
+	require.NotNil(t, ksk)
+
+	assert.NotZero(t, ksk.Flags&dns.SEP)
+	require.NoError(t, dnskeySig.Verify(ksk, keys[:2]))
+
+	inZone := &dns.A{
+		Hdr: dns.RR_Header{
+			Name:   "www.block.example.",
+			Rrtype: dns.TypeA,
+			Class:  dns.ClassINET,
+			Ttl:    10,
+		},
+		A: []byte{0, 0, 0, 0},
+	}
+	outOfZone := &dns.A{
+		Hdr: dns.RR_Header{
+			Name:   "example.org.",
+			Rrtype: dns.TypeA,
+			Class:  dns.ClassINET,
+			Ttl:    10,
+		},
+		A: []byte{0, 0, 0, 0},
+	}
+
+	resp := &dns.Msg{
+		Answer: []dns.RR{inZone, outOfZone},
+	}
+
+	require.NoError(t, s.Sign(resp))
+	require.Len(t, resp.Answer, 3)
+
+	sig := testutil.RequireTypeAssert[*dns.RRSIG](t, resp.Answer[2])
+	assert.Equal(t, dns.TypeA, sig.TypeCovered)
+	assert.Equal(t, inZone.Hdr.Name, sig.Hdr.Name)
+	assert.True(t, sig.ValidityPeriod(testNow))
+
+	zsk := findKey(keys, sig.KeyTag)
+	require.NotNil(t, zsk)
+
+	assert.Zero(t, zsk.Flags&dns.SEP)
+	require.NoError(t, sig.Verify(zsk, []dns.RR{inZone}))
+
+	// Make sure that the already signed RRsets aren't signed again.
+	require.NoError(t, s.Sign(resp))
+
+	assert.Len(t, resp.Answer, 3)
+}
+
+func TestSigner_Refresh(t *testing.T) {
+	t.Parallel()
+
+	errCh := make(chan error, 1)
+	errColl := &agdtest.ErrorCollector{
+		OnCollect: func(_ context.Context, err error) { errCh <- err },
+	}
+
+	ksk := newKeyFiles(t, t.TempDir(), dns.ZONE|dns.SEP)
+	s := dnssign.New(&dnssign.Config{
+		Logger:  slogutil.NewDiscardLogger(),
+		Clock:   agdtime.SystemClock{},
+		ErrColl: errColl,
+		KSK:     ksk,
+		// Use the KSK as ZSK to make sure that the SEP flag is checked.
+		ZSK:      newKeyFiles(t, t.TempDir(), dns.ZONE|dns.SEP),
+		Zone:     testZone,
+		Validity: time.Hour,
+	})
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	err := s.Refresh(ctx)
+	testutil.AssertErrorMsg(t, "zsk: sep flag: got true, want false", err)
+
+	collected, ok := testutil.RequireReceive(t, errCh, testTimeout)
+	require.True(t, ok)
+
+	assert.ErrorIs(t, collected, err)
+
+	keys, err := s.DNSKEYs()
+	require.NoError(t, err)
+
+	assert.Nil(t, keys)
+}
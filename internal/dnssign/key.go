@@ -0,0 +1,90 @@
+package dnssign
+
+import (
+	"crypto"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// key is a loaded DNSSEC key.
+type key struct {
+	pub  *dns.DNSKEY
+	priv crypto.Signer
+}
+
+// loadKey reads the key from the files at paths and checks that it belongs to
+// zone and has the SEP flag set if and only if isKSK is true.
+func loadKey(paths *KeyPaths, zone string, isKSK bool) (k *key, err error) {
+	pub, err := readPublicKey(paths.Public)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	if name := strings.ToLower(pub.Hdr.Name); name != zone {
+		return nil, fmt.Errorf("owner name: %q is not the zone %q", name, zone)
+	}
+
+	if isSEP := pub.Flags&dns.SEP != 0; isSEP != isKSK {
+		return nil, fmt.Errorf("sep flag: got %t, want %t", isSEP, isKSK)
+	}
+
+	priv, err := readPrivateKey(pub, paths.Private)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	return &key{
+		pub:  pub,
+		priv: priv,
+	}, nil
+}
+
+// readPublicKey reads the DNSKEY record from the file at path.
+func readPublicKey(path string) (pub *dns.DNSKEY, err error) {
+	// #nosec G304 -- Trust the file path that is given in the configuration.
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("public key: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+	rr, err := dns.ReadRR(f, path)
+	if err != nil {
+		return nil, fmt.Errorf("public key: parsing: %w", err)
+	}
+
+	pub, ok := rr.(*dns.DNSKEY)
+	if !ok {
+		return nil, fmt.Errorf("public key: unexpected record type %T", rr)
+	}
+
+	return pub, nil
+}
+
+// readPrivateKey reads the private key for pub from the file at path.
+func readPrivateKey(pub *dns.DNSKEY, path string) (priv crypto.Signer, err error) {
+	// #nosec G304 -- Trust the file path that is given in the configuration.
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("private key: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+	pk, err := pub.ReadPrivateKey(f, path)
+	if err != nil {
+		return nil, fmt.Errorf("private key: parsing: %w", err)
+	}
+
+	priv, ok := pk.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("private key: unsupported type %T", pk)
+	}
+
+	return priv, nil
+}
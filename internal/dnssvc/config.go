@@ -6,23 +6,40 @@ import (
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/access"
+	"github.com/AdguardTeam/AdGuardDNS/internal/accountlimit"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/billstat"
 	"github.com/AdguardTeam/AdGuardDNS/internal/cmd/plugin"
 	"github.com/AdguardTeam/AdGuardDNS/internal/connlimiter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnscheck"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsdb"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/ratelimit"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssign"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnstap"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/maintenance"
+	"github.com/AdguardTeam/AdGuardDNS/internal/poisonpill"
+	"github.com/AdguardTeam/AdGuardDNS/internal/popdomains"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnamelimit"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
 	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/reqlog"
 	"github.com/AdguardTeam/AdGuardDNS/internal/rulestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/sharedcounter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/snapshot"
+	"github.com/AdguardTeam/AdGuardDNS/internal/staticzone"
+	"github.com/AdguardTeam/AdGuardDNS/internal/topprofiles"
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -56,6 +73,10 @@ type Config struct {
 	// NonDNS is the handler for non-DNS HTTP requests.  It must not be nil.
 	NonDNS http.Handler
 
+	// QUICAdvisor, if not nil, is notified about the outcomes of the QUIC
+	// handshakes of the DoQ and DoH servers.
+	QUICAdvisor dnsserver.QUICAdvisor
+
 	// MetricsNamespace is a namespace for Prometheus metrics.  It must be a
 	// valid Prometheus metric label.
 	MetricsNamespace string
@@ -95,6 +116,10 @@ type HandlersConfig struct {
 	// Cache is the configuration for the DNS cache.
 	Cache *CacheConfig
 
+	// ClientKeys is used to create the cross-protocol keys of the clients.  It
+	// must not be nil.
+	ClientKeys *agd.ClientKeyHasher
+
 	// HumanIDParser is used to normalize and parse human-readable device
 	// identifiers.  It must not be nil if at least one server group has
 	// profiles enabled.
@@ -115,12 +140,24 @@ type HandlersConfig struct {
 	// AccessManager is used to block requests.  It must not be nil.
 	AccessManager access.Interface
 
+	// AccountLimiter is used to enforce the aggregate limits of accounts.  It
+	// must not be nil.
+	AccountLimiter accountlimit.Interface
+
 	// BillStat is used to collect billing statistics.  It must not be nil.
 	BillStat billstat.Recorder
 
 	// CacheManager is the global cache manager.  It must not be nil.
 	CacheManager agdcache.Manager
 
+	// Classifier is used to find the content categories of the requested
+	// hosts.  It must not be nil.
+	Classifier filter.Classifier
+
+	// DeviceStat is used to collect the per-device query statistics.  It must
+	// not be nil.
+	DeviceStat devicestat.Interface
+
 	// DNSCheck is used by clients to check if they use AdGuard DNS.  It must
 	// not be nil.
 	DNSCheck dnscheck.Interface
@@ -133,6 +170,10 @@ type HandlersConfig struct {
 	// non-critical errors.  It must not be nil.
 	ErrColl errcoll.Interface
 
+	// Exceptions is the storage of the temporary exceptions, which allow the
+	// blocked requests for a profile or a device.  It must not be nil.
+	Exceptions unblock.Storage
+
 	// FilterStorage is the storage of all filters.  It must not be nil.
 	FilterStorage filter.Storage
 
@@ -144,6 +185,12 @@ type HandlersConfig struct {
 	// middlewares.  It must not be nil.
 	Handler dnsserver.Handler
 
+	// ViewHandlers are the optional ultimate handlers of the DNS queries from
+	// the clients matching the views with the corresponding names.  The
+	// queries for the views without a handler are handled by Handler.  Each
+	// element must be non-nil.
+	ViewHandlers map[agd.ViewName]dnsserver.Handler
+
 	// HashMatcher is the safe-browsing hash matcher for TXT queries.  It must
 	// not be nil.
 	HashMatcher filter.HashMatcher
@@ -157,16 +204,67 @@ type HandlersConfig struct {
 	// be nil.
 	PrometheusRegisterer prometheus.Registerer
 
+	// PopularDomains is used to collect the differentially private statistics
+	// of the most popular domains.  It must not be nil.
+	PopularDomains popdomains.Interface
+
+	// QNAMELimiters are the limits of the unique names queried by the clients
+	// of each server group.  The clients of the server groups without a limiter
+	// are not limited.
+	QNAMELimiters map[agd.ServerGroupName]qnamelimit.Interface
+
+	// Quarantine is used to block the requests of the quarantined devices.  It
+	// must not be nil.
+	Quarantine quarantine.Interface
+
 	// QueryLog is used to write the logs into.  It must not be nil.
 	QueryLog querylog.Interface
 
 	// RateLimit is used for allow or decline requests.  It must not be nil.
 	RateLimit ratelimit.Interface
 
+	// RequestLog is used to write the structured per-request debug logs.  It
+	// must not be nil.
+	RequestLog reqlog.Interface
+
 	// RuleStat is used to collect statistics about matched filtering rules and
 	// rule lists.  It must not be nil.
 	RuleStat rulestat.Interface
 
+	// SharedCounter is used to enforce the custom ratelimits of profiles
+	// across all nodes.  It must not be nil.
+	SharedCounter sharedcounter.Interface
+
+	// SnapshotManager is used to save and restore the contents of the cache
+	// between runs.  It must not be nil.
+	SnapshotManager snapshot.Manager
+
+	// StaticZones is the storage of the static answers, which are served
+	// authoritatively before the filtering.  It must not be nil.
+	StaticZones staticzone.Interface
+
+	// TopProfiles is used to collect the statistics of the profiles with the
+	// most requests, errors, and blocked requests.  It must not be nil.
+	TopProfiles topprofiles.Interface
+
+	// Maintenance is used to refuse the queries to the server groups in the
+	// maintenance mode.  It must not be nil.
+	Maintenance *maintenance.Manager
+
+	// LeaseSource is used to find the devices sending plain-DNS queries by
+	// their DHCP leases.  It must not be nil.
+	LeaseSource dhcplease.Interface
+
+	// WhiteLabel is used to get the dynamic settings of the server groups,
+	// such as the device domains and the DDR targets, received from the
+	// backend.  It must not be nil.
+	WhiteLabel whitelabel.Interface
+
+	// QUICAdvisor is used to find the clients from the networks where QUIC is
+	// blocked, so that they aren't directed to the QUIC-based protocols in the
+	// DDR responses.  It must not be nil.
+	QUICAdvisor dnsserver.QUICAdvisor
+
 	// MetricsNamespace is a namespace for Prometheus metrics.  It must be a
 	// valid Prometheus metric label.
 	MetricsNamespace string
@@ -175,10 +273,50 @@ type HandlersConfig struct {
 	// non-nil.
 	FilteringGroups map[agd.FilteringGroupID]*agd.FilteringGroup
 
+	// Chaos is the optional configuration of the responses to the CHAOS-class
+	// diagnostic queries, such as version.bind. and id.server.  If it is nil,
+	// these queries are handled as the usual debug queries.
+	Chaos *ChaosConfig
+
+	// Leak is the optional configuration of the handling of the queries for
+	// the special-use domain names, such as localhost., .local, and .onion.
+	// If it is nil, these queries are processed as usual.
+	Leak *LeakConfig
+
+	// Signer is the optional signer of the synthesized responses within the
+	// zone controlled by the operator.  If it is nil, the responses aren't
+	// signed.
+	Signer *dnssign.Signer
+
+	// PTRZones are the IP ranges for which PTR responses are synthesized
+	// instead of being forwarded upstream.  Each element must be non-nil.  If
+	// empty, all PTR queries are forwarded.
+	PTRZones []*PTRZone
+
+	// Shadow is the optional configuration of the request shadowing.  If it is
+	// nil, the queries aren't mirrored.
+	Shadow *ShadowConfig
+
+	// Dnstap are the optional configurations of the dnstap output for the
+	// server groups.  The queries of the server groups that aren't in the map
+	// aren't written.  Each value must be non-nil.
+	Dnstap map[agd.ServerGroupName]*DnstapConfig
+
+	// PoisonPill is the optional guard that recovers the panics caused by
+	// single queries and drops the queries that repeatedly cause them.  If it
+	// is nil, the panics are only recovered by the servers.
+	PoisonPill *poisonpill.Guard
+
 	// ServerGroups are the DNS server groups for which to build handlers.  Each
 	// element and its servers must be non-nil.
 	ServerGroups []*agd.ServerGroup
 
+	// MaxCNAMEChainLen is the maximum number of CNAME records in a chain in
+	// the upstream responses.  Longer chains and chains with loops are
+	// truncated.  If it is zero, the chains aren't checked.  It must not be
+	// negative.
+	MaxCNAMEChainLen int
+
 	// EDEEnabled enables the addition of the Extended DNS Error (EDE) codes in
 	// the profiles' message constructors.
 	EDEEnabled bool
@@ -210,6 +348,28 @@ type CacheConfig struct {
 	// [CacheConfig.CacheType] is [CacheTypeSimple] or [CacheTypeECS].
 	NoECSCount int
 
+	// FilteredECSCount is the size of the DNS cache for domain names that
+	// support ECS for the requests that have been blocked or modified, in
+	// entries.  If it or [CacheConfig.FilteredNoECSCount] is zero, the
+	// responses to such requests aren't cached.  It is only used if
+	// [CacheConfig.CacheType] is [CacheTypeECS].
+	FilteredECSCount int
+
+	// FilteredNoECSCount is the size of the DNS cache for domain names that
+	// don't support ECS for the requests that have been blocked or modified, in
+	// entries.  If it or [CacheConfig.FilteredECSCount] is zero, the responses
+	// to such requests aren't cached.  It is only used if
+	// [CacheConfig.CacheType] is [CacheTypeECS].
+	FilteredNoECSCount int
+
+	// NSECCount is the number of zones the NSEC and NSEC3 records of which are
+	// cached to synthesize negative responses, in entries.  If it is zero, the
+	// records aren't cached, and [agd.ServerGroup.AggressiveNSECEnabled] has no
+	// effect.  It must not be negative.  It should only be positive if all
+	// upstreams are trusted to validate DNSSEC, since the signatures of the
+	// cached records aren't validated.
+	NSECCount int
+
 	// Type is the cache type.  It must be valid.
 	Type CacheType
 
@@ -217,6 +377,23 @@ type CacheConfig struct {
 	OverrideCacheTTL bool
 }
 
+// ShadowConfig is the configuration of the request shadowing, which mirrors a
+// share of the queries sent upstream to a secondary handler without serving
+// its responses.
+type ShadowConfig struct {
+	// Handler is the secondary handler to which the queries are mirrored.  It
+	// must not be nil.
+	Handler dnsserver.Handler
+
+	// Ratio is the share of queries that are mirrored.  It must be in the
+	// (0, 1] range.
+	Ratio float64
+
+	// MaxInFlight is the maximum number of mirrored queries processed at the
+	// same time.  It must be positive.
+	MaxInFlight int
+}
+
 // CacheType is the type of the cache to use.
 type CacheType uint8
 
@@ -226,3 +403,14 @@ const (
 	CacheTypeSimple
 	CacheTypeECS
 )
+
+// DnstapConfig is the configuration of the dnstap output of a server group.
+type DnstapConfig struct {
+	// Writer is used to write the queries and the responses.  It must not be
+	// nil.
+	Writer dnstap.Interface
+
+	// Ratio is the share of queries that are written.  It must be in the
+	// (0, 1] range.
+	Ratio float64
+}
@@ -33,3 +33,23 @@ func (c *contextConstructor) New() (ctx context.Context, cancel context.CancelFu
 
 	return ctx, cancel
 }
+
+// knownClientChecker is a [dnsserver.KnownClientChecker] implementation that
+// considers the clients, for which a device has been found, known.
+type knownClientChecker struct{}
+
+// type check
+var _ dnsserver.KnownClientChecker = knownClientChecker{}
+
+// IsKnownClient implements the [dnsserver.KnownClientChecker] interface for
+// knownClientChecker.
+func (knownClientChecker) IsKnownClient(ctx context.Context) (ok bool) {
+	ri, ok := agd.RequestInfoFromContext(ctx)
+	if !ok {
+		return false
+	}
+
+	_, ok = ri.DeviceResult.(*agd.DeviceResultOK)
+
+	return ok
+}
@@ -108,7 +108,7 @@ func newServers(
 			handler: handler,
 		}
 
-		s.listeners, err = newListeners(c, srv, handler, errCollListener, newListener)
+		s.listeners, err = newListeners(c, srvGrp, srv, handler, errCollListener, newListener)
 		if err != nil {
 			return nil, fmt.Errorf("server %q: %w", s.name, err)
 		}
@@ -122,11 +122,17 @@ func newServers(
 // newListeners creates a slice of listeners for a server.
 func newListeners(
 	c *Config,
+	srvGrp *agd.ServerGroup,
 	srv *agd.Server,
 	handler dnsserver.Handler,
 	errCollListener *errCollMetricsListener,
 	newListener NewListenerFunc,
 ) (listeners []*listener, err error) {
+	ctrlConf := c.ControlConf
+	if srv.ControlConf != nil {
+		ctrlConf = srv.ControlConf
+	}
+
 	bindData := srv.BindData()
 	listeners = make([]*listener, 0, len(bindData))
 	for i, bindData := range bindData {
@@ -145,15 +151,18 @@ func newListeners(
 			Handler:        handler,
 			Metrics:        errCollListener,
 			Disposer:       c.Cloner,
+			QUICAdvisor:    c.QUICAdvisor,
 			RequestContext: newContextConstructor(c.HandleTimeout),
 			ListenConfig: newListenConfig(
 				bindData.ListenConfig,
-				c.ControlConf,
+				ctrlConf,
 				c.ConnLimiter,
 				proto,
 			),
 			Name: name,
 			Addr: addr,
+
+			PaddingBlockSize: srvGrp.ResponsePaddingBlockSize,
 		}
 
 		l := &listener{
@@ -328,15 +337,22 @@ func NewListener(
 	switch p := s.Protocol; p {
 	case agd.ProtoDNS:
 		udpConf := s.UDPConf
-		l = dnsserver.NewServerDNS(dnsserver.ConfigDNS{
+		dnsConf := dnsserver.ConfigDNS{
 			ConfigBase:         baseConf,
 			ReadTimeout:        s.ReadTimeout,
 			WriteTimeout:       s.WriteTimeout,
 			MaxUDPRespSize:     udpConf.MaxRespSize,
+			UDPTruncation:      udpConf.TruncationPolicy,
 			TCPIdleTimeout:     tcpConf.IdleTimeout,
 			MaxPipelineCount:   tcpConf.MaxPipelineCount,
 			MaxPipelineEnabled: tcpConf.MaxPipelineEnabled,
-		})
+		}
+
+		if udpConf.TruncateUnknownClients {
+			dnsConf.KnownClients = knownClientChecker{}
+		}
+
+		l = dnsserver.NewServerDNS(dnsConf)
 	case agd.ProtoDNSCrypt:
 		dcConf := s.DNSCrypt
 		l = dnsserver.NewServerDNSCrypt(dnsserver.ConfigDNSCrypt{
@@ -345,14 +361,25 @@ func NewListener(
 			DNSCryptResolverCert: dcConf.Cert,
 		})
 	case agd.ProtoDoH:
-		l = dnsserver.NewServerHTTPS(dnsserver.ConfigHTTPS{
+		httpsConf := dnsserver.ConfigHTTPS{
 			ConfigBase:        baseConf,
 			TLSConfDefault:    s.TLS.Default,
 			TLSConfH3:         s.TLS.H3,
 			NonDNSHandler:     nonDNS,
 			MaxStreamsPerPeer: quicConf.MaxStreamsPerPeer,
 			QUICLimitsEnabled: quicConf.QUICLimitsEnabled,
-		})
+		}
+
+		if wsConf := s.WebSocketConf; wsConf != nil {
+			httpsConf.WebSocketMaxQueries = wsConf.MaxQueries
+			httpsConf.WebSocketEnabled = wsConf.Enabled
+		}
+
+		httpsConf.TLSFingerprint = newTLSFingerprintConfig(s.TLSFingerprintConf)
+		httpsConf.Limits = newHTTPLimitsConfig(s.HTTPLimitsConf)
+		httpsConf.Streams = newHTTPStreamsConfig(s.HTTPStreamsConf)
+
+		l = dnsserver.NewServerHTTPS(httpsConf)
 	case agd.ProtoDoQ:
 		l = dnsserver.NewServerQUIC(dnsserver.ConfigQUIC{
 			TLSConfig:         s.TLS.Default,
@@ -361,7 +388,7 @@ func NewListener(
 			QUICLimitsEnabled: quicConf.QUICLimitsEnabled,
 		})
 	case agd.ProtoDoT:
-		l = dnsserver.NewServerTLS(dnsserver.ConfigTLS{
+		tlsConf := dnsserver.ConfigTLS{
 			ConfigDNS: dnsserver.ConfigDNS{
 				ConfigBase:         baseConf,
 				ReadTimeout:        s.ReadTimeout,
@@ -370,11 +397,62 @@ func NewListener(
 				MaxPipelineCount:   tcpConf.MaxPipelineCount,
 				TCPIdleTimeout:     tcpConf.IdleTimeout,
 			},
-			TLSConfig: s.TLS.Default,
-		})
+			TLSConfig:      s.TLS.Default,
+			TLSFingerprint: newTLSFingerprintConfig(s.TLSFingerprintConf),
+		}
+
+		if inspector, ok := baseConf.Handler.(dnsserver.TLSConnInspector); ok {
+			tlsConf.ConnInspector = inspector
+		}
+
+		l = dnsserver.NewServerTLS(tlsConf)
 	default:
 		return nil, fmt.Errorf("protocol: %w: %d", errors.ErrBadEnumValue, p)
 	}
 
 	return l, nil
 }
+
+// newTLSFingerprintConfig converts c into the TLS fingerprinting configuration
+// of a DNS server.  conf is nil if c is nil.
+func newTLSFingerprintConfig(c *agd.TLSFingerprintConfig) (conf *dnsserver.TLSFingerprintConfig) {
+	if c == nil {
+		return nil
+	}
+
+	return &dnsserver.TLSFingerprintConfig{
+		KeepRaw: c.KeepRaw,
+	}
+}
+
+// newHTTPLimitsConfig returns the DoH limits configuration for a DNS server.
+// conf is nil if c is nil.
+func newHTTPLimitsConfig(c *agd.HTTPLimitsConfig) (conf *dnsserver.HTTPLimitsConfig) {
+	if c == nil {
+		return nil
+	}
+
+	return &dnsserver.HTTPLimitsConfig{
+		ReadHeaderTimeout:     c.ReadHeaderTimeout,
+		ReadTimeout:           c.ReadTimeout,
+		MaxBodySize:           c.MaxBodySize,
+		ConnRequestsPerSecond: c.ConnRequestsPerSecond,
+		CloseAbusiveConns:     c.CloseAbusiveConns,
+	}
+}
+
+// newHTTPStreamsConfig returns the configuration of the HTTP/2 and HTTP/3
+// streams for a DNS server.  conf is nil if c is nil.
+func newHTTPStreamsConfig(c *agd.HTTPStreamsConfig) (conf *dnsserver.HTTPStreamsConfig) {
+	if c == nil {
+		return nil
+	}
+
+	return &dnsserver.HTTPStreamsConfig{
+		WriteScheduler:       c.WriteScheduler,
+		MaxConcurrentStreams: c.MaxConcurrentStreams,
+		HeaderTableSize:      c.HeaderTableSize,
+		StreamWindowSize:     c.StreamWindowSize,
+		ConnWindowSize:       c.ConnWindowSize,
+	}
+}
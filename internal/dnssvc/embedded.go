@@ -0,0 +1,230 @@
+package dnssvc
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/access"
+	"github.com/AdguardTeam/AdGuardDNS/internal/accountlimit"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/billstat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnscheck"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsdb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/ratelimit"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/maintenance"
+	"github.com/AdguardTeam/AdGuardDNS/internal/popdomains"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
+	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/reqlog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/rulestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/sharedcounter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/snapshot"
+	"github.com/AdguardTeam/AdGuardDNS/internal/staticzone"
+	"github.com/AdguardTeam/AdGuardDNS/internal/topprofiles"
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Names of the server group and the server used by the embedded handler.
+const (
+	EmbeddedServerGroupName agd.ServerGroupName = "embedded"
+	EmbeddedServerName      agd.ServerName      = "embedded"
+)
+
+// EmbeddedDefaultFilteredResponseTTL is the TTL of the filtered responses of
+// the embedded handler, if [EmbeddedConfig.Messages] is nil.
+const EmbeddedDefaultFilteredResponseTTL = 10 * time.Second
+
+// EmbeddedConfig is the configuration of the DNS handler for embedding into
+// other DNS servers.  See [NewEmbeddedHandler].
+type EmbeddedConfig struct {
+	// Logger is used as the base logger for the middlewares.  It must not be
+	// nil.
+	Logger *slog.Logger
+
+	// Cache is the configuration for the DNS cache.  It must not be nil.
+	Cache *CacheConfig
+
+	// Messages is the optional message constructor used to create blocked and
+	// other messages.  If it is nil, the blocked requests are answered with
+	// unspecified IP addresses with the TTL of
+	// [EmbeddedDefaultFilteredResponseTTL].
+	Messages *dnsmsg.Constructor
+
+	// ErrColl is the error collector that is used to collect critical and
+	// non-critical errors.  It must not be nil.
+	ErrColl errcoll.Interface
+
+	// FilterStorage is the storage of the filters used by FilteringGroup.  It
+	// must not be nil.
+	FilterStorage filter.Storage
+
+	// GeoIP is the optional GeoIP database.  If it is nil, the requests have no
+	// location data.
+	GeoIP geoip.Interface
+
+	// Handler is the ultimate handler of the DNS query, such as a forwarding
+	// handler.  It must not be nil.
+	Handler dnsserver.Handler
+
+	// PrometheusRegisterer is used to register Prometheus metrics.  It must not
+	// be nil.
+	PrometheusRegisterer prometheus.Registerer
+
+	// RateLimit is the optional rate limiter.  If it is nil, the queries aren't
+	// rate limited.
+	RateLimit ratelimit.Interface
+
+	// FilteringGroup is the filtering group applied to all queries.  It must
+	// not be nil.
+	FilteringGroup *agd.FilteringGroup
+
+	// MetricsNamespace is a namespace for Prometheus metrics.  It must be a
+	// valid Prometheus metric label.
+	MetricsNamespace string
+
+	// Protocol is the protocol of the front end that serves the queries.  It
+	// must be valid.
+	Protocol agd.Protocol
+}
+
+// NewEmbeddedHandler returns the DNS handler with the filtering, cache, and
+// forwarding middlewares for embedding into other DNS servers.  Profiles, the
+// backend, and other AdGuard DNS-specific services aren't used.  c must not be
+// nil.
+//
+// The context passed to ServeDNS of the returned handler must contain the
+// [dnsserver.RequestInfo] and the [dnsserver.ServerInfo], as set by the
+// servers from package dnsserver.
+func NewEmbeddedHandler(
+	ctx context.Context,
+	c *EmbeddedConfig,
+) (h dnsserver.Handler, err error) {
+	srv := &agd.Server{
+		Name:     EmbeddedServerName,
+		Protocol: c.Protocol,
+	}
+
+	srvGrp := &agd.ServerGroup{
+		DDR:            &agd.DDR{},
+		Name:           EmbeddedServerGroupName,
+		FilteringGroup: c.FilteringGroup.ID,
+		Servers:        []*agd.Server{srv},
+	}
+
+	hc, err := newEmbeddedHandlersConfig(c, srvGrp)
+	if err != nil {
+		return nil, fmt.Errorf("embedded handler: %w", err)
+	}
+
+	hdlrs, err := NewHandlers(ctx, hc)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	h, ok := hdlrs[HandlerKey{Server: srv, ServerGroup: srvGrp}]
+	if !ok {
+		return nil, fmt.Errorf("embedded handler: %w", errors.ErrNoValue)
+	}
+
+	return h, nil
+}
+
+// newEmbeddedHandlersConfig returns the handlers configuration for the single
+// server group of the embedded handler.  The services that aren't configured
+// by c are replaced by their empty implementations.
+func newEmbeddedHandlersConfig(
+	c *EmbeddedConfig,
+	srvGrp *agd.ServerGroup,
+) (hc *HandlersConfig, err error) {
+	var geoIP geoip.Interface = geoip.Empty{}
+	if c.GeoIP != nil {
+		geoIP = c.GeoIP
+	}
+
+	var rateLimit ratelimit.Interface = ratelimit.Empty{}
+	if c.RateLimit != nil {
+		rateLimit = c.RateLimit
+	}
+
+	salt := make([]byte, 32)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return nil, fmt.Errorf("generating client key salt: %w", err)
+	}
+
+	cloner := dnsmsg.NewCloner(&dnsmsg.ClonerConfig{
+		Stat: dnsmsg.EmptyClonerStat{},
+	})
+
+	msgs := c.Messages
+	if msgs == nil {
+		msgs, err = dnsmsg.NewConstructor(&dnsmsg.ConstructorConfig{
+			Cloner:              cloner,
+			StructuredErrors:    &dnsmsg.StructuredDNSErrorsConfig{},
+			BlockingMode:        &dnsmsg.BlockingModeNullIP{},
+			FilteredResponseTTL: EmbeddedDefaultFilteredResponseTTL,
+			EDEEnabled:          false,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating message constructor: %w", err)
+		}
+	}
+
+	return &HandlersConfig{
+		BaseLogger:           c.Logger,
+		Cloner:               cloner,
+		Cache:                c.Cache,
+		ClientKeys:           agd.NewClientKeyHasher(salt),
+		HumanIDParser:        agd.NewHumanIDParser(),
+		Messages:             msgs,
+		StructuredErrors:     &dnsmsg.StructuredDNSErrorsConfig{},
+		AccessManager:        access.Empty{},
+		AccountLimiter:       accountlimit.Empty{},
+		BillStat:             billstat.EmptyRecorder{},
+		CacheManager:         agdcache.EmptyManager{},
+		Classifier:           filter.EmptyClassifier{},
+		DeviceStat:           devicestat.Empty{},
+		DNSCheck:             dnscheck.Empty{},
+		DNSDB:                dnsdb.Empty{},
+		ErrColl:              c.ErrColl,
+		Exceptions:           unblock.EmptyStorage{},
+		FilterStorage:        c.FilterStorage,
+		GeoIP:                geoIP,
+		Handler:              c.Handler,
+		HashMatcher:          filter.EmptyHashMatcher{},
+		PrometheusRegisterer: c.PrometheusRegisterer,
+		PopularDomains:       popdomains.Empty{},
+		Quarantine:           quarantine.Empty{},
+		QueryLog:             querylog.Empty{},
+		RateLimit:            rateLimit,
+		RequestLog:           reqlog.Empty{},
+		RuleStat:             rulestat.Empty{},
+		SharedCounter:        sharedcounter.Empty{},
+		SnapshotManager:      snapshot.EmptyManager{},
+		StaticZones:          staticzone.Empty{},
+		TopProfiles:          topprofiles.Empty{},
+		Maintenance:          maintenance.NewManager(nil),
+		LeaseSource:          dhcplease.Empty{},
+		WhiteLabel:           whitelabel.Empty{},
+		MetricsNamespace:     c.MetricsNamespace,
+		FilteringGroups: map[agd.FilteringGroupID]*agd.FilteringGroup{
+			c.FilteringGroup.ID: c.FilteringGroup,
+		},
+		ServerGroups: []*agd.ServerGroup{srvGrp},
+	}, nil
+}
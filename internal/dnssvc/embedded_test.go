@@ -0,0 +1,80 @@
+package dnssvc_test
+
+import (
+	"context"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEmbeddedHandler(t *testing.T) {
+	t.Parallel()
+
+	fltGrp := &agd.FilteringGroup{
+		FilterConfig: &filter.ConfigGroup{
+			Parental:     &filter.ConfigParental{},
+			RuleList:     &filter.ConfigRuleList{},
+			SafeBrowsing: &filter.ConfigSafeBrowsing{},
+		},
+		ID: dnssvctest.FilteringGroupID,
+	}
+
+	fltStrg := &agdtest.FilterStorage{
+		OnForConfig: func(_ context.Context, _ filter.Config) (f filter.Interface) {
+			return filter.Empty{}
+		},
+		OnHasListID: func(_ filter.ID) (ok bool) { panic("not implemented") },
+	}
+
+	ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+	h, err := dnssvc.NewEmbeddedHandler(ctx, &dnssvc.EmbeddedConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		Cache: &dnssvc.CacheConfig{
+			NoECSCount: 100,
+			Type:       dnssvc.CacheTypeSimple,
+		},
+		Messages:             agdtest.NewConstructor(t),
+		ErrColl:              agdtest.NewErrorCollector(),
+		FilterStorage:        fltStrg,
+		Handler:              dnsservertest.NewDefaultHandler(),
+		PrometheusRegisterer: agdtest.NewTestPrometheusRegisterer(),
+		FilteringGroup:       fltGrp,
+		MetricsNamespace:     path.Base(t.Name()),
+		Protocol:             agd.ProtoDNS,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, h)
+
+	ctx = dnsserver.ContextWithServerInfo(ctx, &dnsserver.ServerInfo{
+		Name:  string(dnssvc.EmbeddedServerName),
+		Proto: agd.ProtoDNS,
+	})
+	ctx = dnsserver.ContextWithRequestInfo(ctx, &dnsserver.RequestInfo{
+		StartTime: time.Now(),
+	})
+
+	req := dnsservertest.NewReq(dnssvctest.DomainFQDN, dns.TypeA, dns.ClassINET)
+	rw := dnsserver.NewNonWriterResponseWriter(dnssvctest.ServerTCPAddr, dnssvctest.ClientTCPAddr)
+
+	err = h.ServeDNS(ctx, rw, req)
+	require.NoError(t, err)
+
+	resp := rw.Msg()
+	require.NotNil(t, resp)
+
+	assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+	assert.Len(t, resp.Answer, 1)
+}
@@ -41,6 +41,32 @@ func (s *errCollMetricsListener) OnQUICAddressValidation(hit bool) {
 	s.baseListener.OnQUICAddressValidation(hit)
 }
 
+// OnWebSocketConnClosed implements the dnsserver.MetricsListener interface for
+// *errCollMetricsListener.
+func (s *errCollMetricsListener) OnWebSocketConnClosed(
+	ctx context.Context,
+	queries uint,
+	limited bool,
+) {
+	s.baseListener.OnWebSocketConnClosed(ctx, queries, limited)
+}
+
+// OnHTTPLimitExceeded implements the dnsserver.MetricsListener interface for
+// *errCollMetricsListener.
+func (s *errCollMetricsListener) OnHTTPLimitExceeded(
+	ctx context.Context,
+	limit dnsserver.HTTPLimit,
+	closed bool,
+) {
+	s.baseListener.OnHTTPLimitExceeded(ctx, limit, closed)
+}
+
+// OnHTTPStreamReset implements the dnsserver.MetricsListener interface for
+// *errCollMetricsListener.
+func (s *errCollMetricsListener) OnHTTPStreamReset(ctx context.Context) {
+	s.baseListener.OnHTTPStreamReset(ctx)
+}
+
 // OnPanic implements the dnsserver.MetricsListener interface for
 // *errCollMetricsListener.
 func (s *errCollMetricsListener) OnPanic(ctx context.Context, v any) {
@@ -5,17 +5,29 @@ import (
 	"fmt"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/cache"
 	dnssrvprom "github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/prometheus"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/cnamemw"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/devicefinder"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssecmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnstapmw"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/initial"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/localzonemw"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/mainmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/maintenancemw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/nsecmw"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/preservice"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/preupstream"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/ptrmw"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/ratelimitmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/shadowmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/signmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/staticzonemw"
 	"github.com/AdguardTeam/AdGuardDNS/internal/ecscache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnamelimit"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 )
@@ -23,7 +35,11 @@ import (
 // NewHandlers returns the main DNS handlers wrapped in all necessary
 // middlewares.  c must not be nil.
 func NewHandlers(ctx context.Context, c *HandlersConfig) (handlers Handlers, err error) {
-	handler := wrapPreUpstreamMw(ctx, c)
+	handler, err := wrapPreUpstreamMw(ctx, c)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
 
 	mainMwMtrc, err := newMainMiddlewareMetrics(c)
 	if err != nil {
@@ -32,20 +48,46 @@ func NewHandlers(ctx context.Context, c *HandlersConfig) (handlers Handlers, err
 	}
 
 	mainMw := mainmw.New(&mainmw.Config{
-		Cloner:        c.Cloner,
-		Logger:        c.BaseLogger.With(slogutil.KeyPrefix, "mainmw"),
-		Messages:      c.Messages,
-		BillStat:      c.BillStat,
-		ErrColl:       c.ErrColl,
-		FilterStorage: c.FilterStorage,
-		GeoIP:         c.GeoIP,
-		QueryLog:      c.QueryLog,
-		Metrics:       mainMwMtrc,
-		RuleStat:      c.RuleStat,
+		Cloner:         c.Cloner,
+		Logger:         c.BaseLogger.With(slogutil.KeyPrefix, "mainmw"),
+		Messages:       c.Messages,
+		BillStat:       c.BillStat,
+		Classifier:     c.Classifier,
+		DeviceStat:     c.DeviceStat,
+		ErrColl:        c.ErrColl,
+		Exceptions:     c.Exceptions,
+		FilterStorage:  c.FilterStorage,
+		GeoIP:          c.GeoIP,
+		Quarantine:     c.Quarantine,
+		QueryLog:       c.QueryLog,
+		RequestLog:     c.RequestLog,
+		Metrics:        mainMwMtrc,
+		PopularDomains: c.PopularDomains,
+		RuleStat:       c.RuleStat,
+		TopProfiles:    c.TopProfiles,
 	})
 
 	handler = mainMw.Wrap(handler)
 
+	if len(c.PTRZones) > 0 {
+		ptrMw := ptrmw.New(&ptrmw.Config{
+			Logger:    c.BaseLogger.With(slogutil.KeyPrefix, "ptrmw"),
+			Messages:  c.Messages,
+			ProfileDB: c.ProfileDB,
+			Zones:     c.PTRZones,
+		})
+
+		handler = ptrMw.Wrap(handler)
+	}
+
+	staticZoneMw := staticzonemw.New(&staticzonemw.Config{
+		Logger:   c.BaseLogger.With(slogutil.KeyPrefix, "staticzonemw"),
+		Messages: c.Messages,
+		Zones:    c.StaticZones,
+	})
+
+	handler = staticZoneMw.Wrap(handler)
+
 	preSvcMw := preservice.New(&preservice.Config{
 		Logger:      c.BaseLogger.With(slogutil.KeyPrefix, "presvcmw"),
 		Messages:    c.Messages,
@@ -61,11 +103,25 @@ func NewHandlers(ctx context.Context, c *HandlersConfig) (handlers Handlers, err
 	}
 
 	initMw := initial.New(&initial.Config{
-		Logger: c.BaseLogger.With(slogutil.KeyPrefix, "initmw"),
+		Logger:      c.BaseLogger.With(slogutil.KeyPrefix, "initmw"),
+		WhiteLabel:  c.WhiteLabel,
+		QUICAdvisor: c.QUICAdvisor,
+		Chaos:       c.Chaos,
+		Leak:        c.Leak,
 	})
 
 	handler = initMw.Wrap(handler)
 
+	if c.Signer != nil {
+		signMw := signmw.New(&signmw.Config{
+			Logger:  c.BaseLogger.With(slogutil.KeyPrefix, "signmw"),
+			ErrColl: c.ErrColl,
+			Signer:  c.Signer,
+		})
+
+		handler = signMw.Wrap(handler)
+	}
+
 	return newHandlersForServers(c, handler)
 }
 
@@ -74,20 +130,48 @@ func NewHandlers(ctx context.Context, c *HandlersConfig) (handlers Handlers, err
 //
 // TODO(a.garipov):  Adapt the cache tests that previously were in package
 // preupstream.
-func wrapPreUpstreamMw(ctx context.Context, c *HandlersConfig) (wrapped dnsserver.Handler) {
+func wrapPreUpstreamMw(
+	ctx context.Context,
+	c *HandlersConfig,
+) (wrapped dnsserver.Handler, err error) {
 	// TODO(a.garipov):  Use in other places if necessary.
 	l := c.BaseLogger.With(slogutil.KeyPrefix, "dnssvc")
 
-	wrapped = c.Handler
+	wrapped, err = wrapShadowMw(ctx, c, newViewHandler(c.Handler, c.ViewHandlers))
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	wrapped, err = wrapNSECMw(ctx, c, wrapped)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	wrapped, err = wrapCNAMEMw(ctx, c, wrapped)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
 	switch conf := c.Cache; conf.Type {
 	case CacheTypeNone:
 		l.WarnContext(ctx, "cache disabled")
 	case CacheTypeSimple:
 		l.InfoContext(ctx, "plain cache enabled", "count", conf.NoECSCount)
 
+		var cacheMtrc *dnssrvprom.CacheMetricsListener
+		cacheMtrc, err = dnssrvprom.NewCacheMetricsListener(
+			c.MetricsNamespace,
+			c.PrometheusRegisterer,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("cache metrics: %w", err)
+		}
+
 		cacheMw := cache.NewMiddleware(&cache.MiddlewareConfig{
-			// TODO(a.garipov):  Do not use promauto and refactor.
-			MetricsListener: dnssrvprom.NewCacheMetricsListener(metrics.Namespace()),
+			MetricsListener: cacheMtrc,
 			Count:           conf.NoECSCount,
 			MinTTL:          conf.MinTTL,
 			OverrideTTL:     conf.OverrideCacheTTL,
@@ -100,17 +184,22 @@ func wrapPreUpstreamMw(ctx context.Context, c *HandlersConfig) (wrapped dnsserve
 			"ecs cache enabled",
 			"ecs_count", conf.ECSCount,
 			"no_ecs_count", conf.NoECSCount,
+			"filtered_ecs_count", conf.FilteredECSCount,
+			"filtered_no_ecs_count", conf.FilteredNoECSCount,
 		)
 
 		cacheMw := ecscache.NewMiddleware(&ecscache.MiddlewareConfig{
-			Cloner:       c.Cloner,
-			Logger:       c.BaseLogger.With(slogutil.KeyPrefix, "ecscache"),
-			CacheManager: c.CacheManager,
-			GeoIP:        c.GeoIP,
-			NoECSCount:   conf.NoECSCount,
-			ECSCount:     conf.ECSCount,
-			MinTTL:       conf.MinTTL,
-			OverrideTTL:  conf.OverrideCacheTTL,
+			Cloner:             c.Cloner,
+			Logger:             c.BaseLogger.With(slogutil.KeyPrefix, "ecscache"),
+			CacheManager:       c.CacheManager,
+			GeoIP:              c.GeoIP,
+			SnapshotManager:    c.SnapshotManager,
+			NoECSCount:         conf.NoECSCount,
+			ECSCount:           conf.ECSCount,
+			FilteredNoECSCount: conf.FilteredNoECSCount,
+			FilteredECSCount:   conf.FilteredECSCount,
+			MinTTL:             conf.MinTTL,
+			OverrideTTL:        conf.OverrideCacheTTL,
 		})
 
 		wrapped = cacheMw.Wrap(wrapped)
@@ -118,13 +207,128 @@ func wrapPreUpstreamMw(ctx context.Context, c *HandlersConfig) (wrapped dnsserve
 		panic(fmt.Errorf("cache type: %w: %d", errors.ErrBadEnumValue, conf.Type))
 	}
 
+	// Wrap the cache, so that the cached responses are checked as well.
+	dnssecMtrc, err := metrics.NewDefaultDNSSECMiddleware(
+		c.MetricsNamespace,
+		c.PrometheusRegisterer,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("dnssec middleware metrics: %w", err)
+	}
+
+	dnssecMw := dnssecmw.New(&dnssecmw.Config{
+		Logger:  c.BaseLogger.With(slogutil.KeyPrefix, "dnssecmw"),
+		Metrics: dnssecMtrc,
+	})
+
+	wrapped = dnssecMw.Wrap(wrapped)
+
 	preUps := preupstream.New(ctx, &preupstream.Config{
 		DB: c.DNSDB,
 	})
 
 	wrapped = preUps.Wrap(wrapped)
 
-	return wrapped
+	localZoneMw := localzonemw.New(&localzonemw.Config{
+		Logger:   c.BaseLogger.With(slogutil.KeyPrefix, "localzonemw"),
+		Messages: c.Messages,
+	})
+
+	wrapped = localZoneMw.Wrap(wrapped)
+
+	return wrapped, nil
+}
+
+// wrapShadowMw returns h wrapped into the request-shadowing middleware, if it
+// is configured.  Otherwise, it returns h.
+func wrapShadowMw(
+	ctx context.Context,
+	c *HandlersConfig,
+	h dnsserver.Handler,
+) (wrapped dnsserver.Handler, err error) {
+	conf := c.Shadow
+	if conf == nil {
+		return h, nil
+	}
+
+	mtrc, err := metrics.NewDefaultShadowMiddleware(c.MetricsNamespace, c.PrometheusRegisterer)
+	if err != nil {
+		return nil, fmt.Errorf("shadow middleware metrics: %w", err)
+	}
+
+	l := c.BaseLogger.With(slogutil.KeyPrefix, "shadowmw")
+	l.InfoContext(ctx, "request shadowing enabled", "ratio", conf.Ratio)
+
+	shadowMw := shadowmw.New(&shadowmw.Config{
+		Logger:      l,
+		Handler:     conf.Handler,
+		Metrics:     mtrc,
+		Ratio:       conf.Ratio,
+		MaxInFlight: conf.MaxInFlight,
+	})
+
+	return shadowMw.Wrap(h), nil
+}
+
+// wrapNSECMw returns h wrapped into the aggressive NSEC middleware, if it is
+// configured.  Otherwise, it returns h.
+func wrapNSECMw(
+	ctx context.Context,
+	c *HandlersConfig,
+	h dnsserver.Handler,
+) (wrapped dnsserver.Handler, err error) {
+	count := c.Cache.NSECCount
+	if count == 0 {
+		return h, nil
+	}
+
+	mtrc, err := metrics.NewDefaultNSECMiddleware(c.MetricsNamespace, c.PrometheusRegisterer)
+	if err != nil {
+		return nil, fmt.Errorf("nsec middleware metrics: %w", err)
+	}
+
+	l := c.BaseLogger.With(slogutil.KeyPrefix, "nsecmw")
+	l.InfoContext(ctx, "aggressive nsec caching enabled", "count", count)
+
+	nsecMw := nsecmw.New(&nsecmw.Config{
+		Logger:       l,
+		CacheManager: c.CacheManager,
+		Clock:        agdtime.SystemClock{},
+		Messages:     c.Messages,
+		Metrics:      mtrc,
+		Count:        count,
+	})
+
+	return nsecMw.Wrap(h), nil
+}
+
+// wrapCNAMEMw returns h wrapped into the CNAME-chain middleware, if it is
+// configured.  Otherwise, it returns h.
+func wrapCNAMEMw(
+	ctx context.Context,
+	c *HandlersConfig,
+	h dnsserver.Handler,
+) (wrapped dnsserver.Handler, err error) {
+	maxLen := c.MaxCNAMEChainLen
+	if maxLen == 0 {
+		return h, nil
+	}
+
+	mtrc, err := metrics.NewDefaultCNAMEMiddleware(c.MetricsNamespace, c.PrometheusRegisterer)
+	if err != nil {
+		return nil, fmt.Errorf("cname middleware metrics: %w", err)
+	}
+
+	l := c.BaseLogger.With(slogutil.KeyPrefix, "cnamemw")
+	l.InfoContext(ctx, "cname chain checks enabled", "max_len", maxLen)
+
+	cnameMw := cnamemw.New(&cnamemw.Config{
+		Logger:      l,
+		Metrics:     mtrc,
+		MaxChainLen: maxLen,
+	})
+
+	return cnameMw.Wrap(h), nil
 }
 
 // newMainMiddlewareMetrics returns a filtering-middleware metrics
@@ -157,6 +361,7 @@ func newHandlersForServers(c *HandlersConfig, h dnsserver.Handler) (handlers Han
 	handlers = Handlers{}
 
 	rlMwLogger := c.BaseLogger.With(slogutil.KeyPrefix, "ratelimitmw")
+	maintMwLogger := c.BaseLogger.With(slogutil.KeyPrefix, "maintenancemw")
 	for _, srvGrp := range c.ServerGroups {
 		fltGrp, ok := c.FilteringGroups[srvGrp.FilteringGroup]
 		if !ok {
@@ -167,20 +372,37 @@ func newHandlersForServers(c *HandlersConfig, h dnsserver.Handler) (handlers Han
 			)
 		}
 
+		qnameLimiter, ok := c.QNAMELimiters[srvGrp.Name]
+		if !ok {
+			qnameLimiter = qnamelimit.Empty{}
+		}
+
+		maintMw := maintenancemw.New(&maintenancemw.Config{
+			Logger:      maintMwLogger,
+			Messages:    c.Messages,
+			Manager:     c.Maintenance,
+			ServerGroup: srvGrp.Name,
+		})
+
 		for _, srv := range srvGrp.Servers {
+			df := newDeviceFinder(c, srvGrp, srv)
 			rlMw := ratelimitmw.New(&ratelimitmw.Config{
 				Logger:           rlMwLogger,
+				ClientKeys:       c.ClientKeys,
 				Messages:         c.Messages,
 				FilteringGroup:   fltGrp,
 				ServerGroup:      srvGrp,
 				Server:           srv,
 				StructuredErrors: c.StructuredErrors,
 				AccessManager:    c.AccessManager,
-				DeviceFinder:     newDeviceFinder(c, srvGrp, srv),
+				AccountLimiter:   c.AccountLimiter,
+				DeviceFinder:     df,
 				ErrColl:          c.ErrColl,
 				GeoIP:            c.GeoIP,
 				Metrics:          rlMwMtrc,
 				Limiter:          c.RateLimit,
+				QNAMELimiter:     qnameLimiter,
+				SharedCounter:    c.SharedCounter,
 				Protocols:        []agd.Protocol{agd.ProtoDNS},
 				EDEEnabled:       c.EDEEnabled,
 			})
@@ -190,13 +412,42 @@ func newHandlersForServers(c *HandlersConfig, h dnsserver.Handler) (handlers Han
 				ServerGroup: srvGrp,
 			}
 
-			handlers[k] = rlMw.Wrap(h)
+			// Put the dnstap middleware after the ratelimit one, since the
+			// latter resolves the profile, the privacy settings of which the
+			// former must honor.
+			wrapped := maintMw.Wrap(rlMw.Wrap(wrapDnstapMw(c, srvGrp, h)))
+			if c.PoisonPill != nil {
+				wrapped = c.PoisonPill.Wrap(wrapped)
+			}
+
+			handlers[k] = withTLSConnInspector(wrapped, srv, df)
 		}
 	}
 
 	return handlers, nil
 }
 
+// wrapDnstapMw returns h wrapped into the dnstap middleware, if the dnstap
+// output is configured for g.  Otherwise, it returns h.
+func wrapDnstapMw(
+	c *HandlersConfig,
+	g *agd.ServerGroup,
+	h dnsserver.Handler,
+) (wrapped dnsserver.Handler) {
+	conf := c.Dnstap[g.Name]
+	if conf == nil {
+		return h
+	}
+
+	dnstapMw := dnstapmw.New(&dnstapmw.Config{
+		Logger: c.BaseLogger.With(slogutil.KeyPrefix, "dnstapmw"),
+		Writer: conf.Writer,
+		Ratio:  conf.Ratio,
+	})
+
+	return dnstapMw.Wrap(h)
+}
+
 // newDeviceFinder returns a new agd.DeviceFinder for a server based on the
 // configuration.  All arguments must not be nil.
 func newDeviceFinder(c *HandlersConfig, g *agd.ServerGroup, s *agd.Server) (df agd.DeviceFinder) {
@@ -205,10 +456,41 @@ func newDeviceFinder(c *HandlersConfig, g *agd.ServerGroup, s *agd.Server) (df a
 	}
 
 	return devicefinder.NewDefault(&devicefinder.Config{
-		Logger:        c.BaseLogger.With(slogutil.KeyPrefix, "devicefinder"),
-		ProfileDB:     c.ProfileDB,
-		HumanIDParser: c.HumanIDParser,
-		Server:        s,
-		DeviceDomains: g.DeviceDomains,
+		Logger:          c.BaseLogger.With(slogutil.KeyPrefix, "devicefinder"),
+		ProfileDB:       c.ProfileDB,
+		HumanIDParser:   c.HumanIDParser,
+		LeaseSource:     c.LeaseSource,
+		Server:          s,
+		WhiteLabel:      c.WhiteLabel,
+		ServerGroupName: g.Name,
+		DeviceDomains:   g.DeviceDomains,
+		StrictSNI:       s.StrictSNI,
 	})
 }
+
+// tlsConnInspectingHandler is a [dnsserver.Handler] that also inspects the DoT
+// connections to the server.  It is used by [NewListener] to set
+// [dnsserver.ConfigTLS.ConnInspector].
+type tlsConnInspectingHandler struct {
+	dnsserver.Handler
+	dnsserver.TLSConnInspector
+}
+
+// withTLSConnInspector returns h wrapped into a *tlsConnInspectingHandler if s
+// is a DoT server and df is able to inspect its connections.  Otherwise, it
+// returns h.
+func withTLSConnInspector(
+	h dnsserver.Handler,
+	s *agd.Server,
+	df agd.DeviceFinder,
+) (wrapped dnsserver.Handler) {
+	inspector, ok := df.(dnsserver.TLSConnInspector)
+	if s.Protocol != agd.ProtoDoT || !ok {
+		return h
+	}
+
+	return &tlsConnInspectingHandler{
+		Handler:          h,
+		TLSConnInspector: inspector,
+	}
+}
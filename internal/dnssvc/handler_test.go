@@ -7,15 +7,29 @@ import (
 	"testing"
 	"time"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/accountlimit"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/maintenance"
+	"github.com/AdguardTeam/AdGuardDNS/internal/popdomains"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
 	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/reqlog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/sharedcounter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/snapshot"
+	"github.com/AdguardTeam/AdGuardDNS/internal/staticzone"
+	"github.com/AdguardTeam/AdGuardDNS/internal/topprofiles"
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/testutil"
 	"github.com/miekg/dns"
@@ -154,26 +168,42 @@ func TestNewHandlers(t *testing.T) {
 				BaseLogger:       slogutil.NewDiscardLogger(),
 				Cloner:           agdtest.NewCloner(),
 				Cache:            tc.cacheConf,
+				ClientKeys:       agd.NewClientKeyHasher(nil),
 				HumanIDParser:    agd.NewHumanIDParser(),
 				Messages:         agdtest.NewConstructor(t),
 				PluginRegistry:   nil,
 				StructuredErrors: agdtest.NewSDEConfig(true),
 				AccessManager:    accessMgr,
+				AccountLimiter:   accountlimit.Empty{},
 				BillStat:         billStat,
 				// TODO(a.garipov):  Create a test implementation?
 				CacheManager:         agdcache.EmptyManager{},
+				Classifier:           filter.EmptyClassifier{},
+				DeviceStat:           devicestat.Empty{},
 				DNSCheck:             dnsCk,
 				DNSDB:                dnsDB,
 				ErrColl:              agdtest.NewErrorCollector(),
+				Exceptions:           unblock.EmptyStorage{},
 				FilterStorage:        fltStrg,
 				GeoIP:                agdtest.NewGeoIP(),
 				Handler:              dnsservertest.NewPanicHandler(),
 				HashMatcher:          hashMatcher,
 				ProfileDB:            agdtest.NewProfileDB(),
 				PrometheusRegisterer: agdtest.NewTestPrometheusRegisterer(),
+				PopularDomains:       popdomains.Empty{},
+				Quarantine:           quarantine.Empty{},
 				QueryLog:             queryLog,
 				RateLimit:            agdtest.NewRateLimit(),
+				RequestLog:           reqlog.Empty{},
 				RuleStat:             ruleStat,
+				SharedCounter:        sharedcounter.Empty{},
+				SnapshotManager:      snapshot.EmptyManager{},
+				StaticZones:          staticzone.Empty{},
+				TopProfiles:          topprofiles.Empty{},
+				Maintenance:          maintenance.NewManager(nil),
+				LeaseSource:          dhcplease.Empty{},
+				WhiteLabel:           whitelabel.Empty{},
+				QUICAdvisor:          dnsserver.EmptyQUICAdvisor{},
 				MetricsNamespace:     path.Base(t.Name()),
 				FilteringGroups:      fltGrps,
 				ServerGroups:         []*agd.ServerGroup{srvGrp},
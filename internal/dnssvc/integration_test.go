@@ -2,18 +2,24 @@ package dnssvc_test
 
 import (
 	"context"
+	"crypto/tls"
+	"io"
 	"net"
 	"net/http"
 	"net/netip"
+	"net/url"
 	"path"
 	"testing"
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/access"
+	"github.com/AdguardTeam/AdGuardDNS/internal/accountlimit"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdpasswd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
@@ -22,13 +28,24 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/hashprefix"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/maintenance"
+	"github.com/AdguardTeam/AdGuardDNS/internal/popdomains"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
 	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/reqlog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/sharedcounter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/snapshot"
+	"github.com/AdguardTeam/AdGuardDNS/internal/staticzone"
+	"github.com/AdguardTeam/AdGuardDNS/internal/topprofiles"
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/AdguardTeam/golibs/testutil"
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
 )
 
 // newTestService creates a new [dnssvc.Service] for tests.  The service built
@@ -61,6 +78,47 @@ func newTestService(
 ) (svc *dnssvc.Service, srvAddr netip.AddrPort) {
 	t.Helper()
 
+	srvAddr = netip.MustParseAddrPort("94.149.14.14:853")
+	srv := dnssvctest.NewServer(dnssvctest.ServerName, agd.ProtoDoT, &agd.ServerBindData{
+		AddrPort: srvAddr,
+	})
+
+	tl := newTestListener()
+	tl.onStart = func(_ context.Context) (err error) { return nil }
+	tl.onShutdown = func(_ context.Context) (err error) { return nil }
+
+	svc = newTestServiceWithServer(
+		t,
+		srv,
+		newTestListenerFunc(tl),
+		flt,
+		errCollCh,
+		profileDBCh,
+		querylogCh,
+		geoIPCh,
+		dnsDBCh,
+		ruleStatCh,
+	)
+
+	return svc, srvAddr
+}
+
+// newTestServiceWithServer is like [newTestService] but uses srv as the only
+// server of the server group and newListener to create its listeners.
+func newTestServiceWithServer(
+	t testing.TB,
+	srv *agd.Server,
+	newListener dnssvc.NewListenerFunc,
+	flt filter.Interface,
+	errCollCh chan<- error,
+	profileDBCh chan<- agd.DeviceID,
+	querylogCh chan<- *querylog.Entry,
+	geoIPCh chan<- string,
+	dnsDBCh chan<- *agd.RequestInfo,
+	ruleStatCh chan<- filter.RuleText,
+) (svc *dnssvc.Service) {
+	t.Helper()
+
 	pt := testutil.PanicT{}
 
 	dev := &agd.Device{
@@ -146,15 +204,6 @@ func newTestService(
 		},
 	}
 
-	srvAddr = netip.MustParseAddrPort("94.149.14.14:853")
-	srv := dnssvctest.NewServer(dnssvctest.ServerName, agd.ProtoDoT, &agd.ServerBindData{
-		AddrPort: srvAddr,
-	})
-
-	tl := newTestListener()
-	tl.onStart = func(_ context.Context) (err error) { return nil }
-	tl.onShutdown = func(_ context.Context) (err error) { return nil }
-
 	dnsCk := &agdtest.DNSCheck{
 		OnCheck: func(
 			_ context.Context,
@@ -216,9 +265,11 @@ func newTestService(
 		},
 		StructuredErrors: agdtest.NewSDEConfig(true),
 		Cloner:           agdtest.NewCloner(),
+		ClientKeys:       agd.NewClientKeyHasher(nil),
 		HumanIDParser:    agd.NewHumanIDParser(),
 		Messages:         agdtest.NewConstructor(t),
 		AccessManager:    accessManager,
+		AccountLimiter:   accountlimit.Empty{},
 		BillStat: &agdtest.BillStatRecorder{
 			OnRecord: func(
 				_ context.Context,
@@ -231,18 +282,32 @@ func newTestService(
 			},
 		},
 		CacheManager:         agdcache.EmptyManager{},
+		Classifier:           filter.EmptyClassifier{},
+		DeviceStat:           devicestat.Empty{},
 		DNSCheck:             dnsCk,
 		DNSDB:                dnsDB,
 		ErrColl:              errColl,
+		Exceptions:           unblock.EmptyStorage{},
 		FilterStorage:        fltStrg,
 		GeoIP:                geoIP,
 		Handler:              dnsservertest.NewDefaultHandler(),
 		HashMatcher:          hashprefix.NewMatcher(nil),
 		ProfileDB:            profDB,
 		PrometheusRegisterer: agdtest.NewTestPrometheusRegisterer(),
+		PopularDomains:       popdomains.Empty{},
+		Quarantine:           quarantine.Empty{},
 		QueryLog:             ql,
 		RateLimit:            rl,
+		RequestLog:           reqlog.Empty{},
 		RuleStat:             ruleStat,
+		SharedCounter:        sharedcounter.Empty{},
+		SnapshotManager:      snapshot.EmptyManager{},
+		StaticZones:          staticzone.Empty{},
+		TopProfiles:          topprofiles.Empty{},
+		Maintenance:          maintenance.NewManager(nil),
+		LeaseSource:          dhcplease.Empty{},
+		WhiteLabel:           whitelabel.Empty{},
+		QUICAdvisor:          dnsserver.EmptyQUICAdvisor{},
 		MetricsNamespace:     path.Base(t.Name()),
 		FilteringGroups: map[agd.FilteringGroupID]*agd.FilteringGroup{
 			dnssvctest.FilteringGroupID: fltGrp,
@@ -257,7 +322,7 @@ func newTestService(
 
 	c := &dnssvc.Config{
 		Handlers:         handlers,
-		NewListener:      newTestListenerFunc(tl),
+		NewListener:      newListener,
 		Cloner:           agdtest.NewCloner(),
 		ErrColl:          errColl,
 		NonDNS:           http.NotFoundHandler(),
@@ -276,7 +341,7 @@ func newTestService(
 		return svc.Shutdown(testutil.ContextWithTimeout(t, dnssvctest.Timeout))
 	})
 
-	return svc, srvAddr
+	return svc
 }
 
 // TODO(a.garipov):  Refactor to test handlers separately from the service.
@@ -457,3 +522,157 @@ func TestService_Wrap(t *testing.T) {
 		assert.Equal(t, cnameRule, <-ruleStatCh)
 	})
 }
+
+func TestService_webSocket(t *testing.T) {
+	profileDBCh := make(chan agd.DeviceID, 1)
+	querylogCh := make(chan *querylog.Entry, 1)
+	geoIPCh := make(chan string, 2)
+	dnsDBCh := make(chan *agd.RequestInfo, 1)
+	ruleStatCh := make(chan filter.RuleText, 1)
+
+	errCollCh := make(chan error, 1)
+	go func() {
+		for err := range errCollCh {
+			require.NoError(t, err)
+		}
+	}()
+
+	flt := &agdtest.Filter{
+		OnFilterRequest: func(_ context.Context, _ *filter.Request) (r filter.Result, err error) {
+			return nil, nil
+		},
+		OnFilterResponse: func(_ context.Context, _ *filter.Response) (r filter.Result, err error) {
+			return nil, nil
+		},
+	}
+
+	tlsConf := dnsservertest.CreateServerTLSConfig("example.org")
+	tlsConf.NextProtos = dnsserver.NextProtoDoH
+
+	srv := dnssvctest.NewServer(dnssvctest.ServerName, agd.ProtoDoH, &agd.ServerBindData{
+		AddrPort: netip.MustParseAddrPort("127.0.0.1:0"),
+	})
+	srv.TLS = &agd.TLSConfig{
+		Default: tlsConf,
+	}
+	srv.WebSocketConf = &agd.WebSocketConfig{
+		MaxQueries: 1,
+		Enabled:    true,
+	}
+
+	var l dnssvc.Listener
+	newListener := func(
+		s *agd.Server,
+		baseConf dnsserver.ConfigBase,
+		nonDNS http.Handler,
+	) (sl dnssvc.Listener, err error) {
+		// Don't start the HTTP/3 server, since WebSocket doesn't support it.
+		baseConf.Network = dnsserver.NetworkTCP
+		l, err = dnssvc.NewListener(s, baseConf, nonDNS)
+
+		return l, err
+	}
+
+	_ = newTestServiceWithServer(
+		t,
+		srv,
+		newListener,
+		flt,
+		errCollCh,
+		profileDBCh,
+		querylogCh,
+		geoIPCh,
+		dnsDBCh,
+		ruleStatCh,
+	)
+	require.NotNil(t, l)
+
+	addr := l.LocalTCPAddr()
+
+	t.Run("device_id_path", func(t *testing.T) {
+		wsPath := path.Join(dnsserver.PathWebSocket, dnssvctest.DeviceIDStr)
+		ws, err := websocket.DialConfig(newWebSocketConfig(t, tlsConf, addr, wsPath))
+		require.NoError(t, err)
+
+		// Don't require success, since the server closes the connection first.
+		t.Cleanup(func() { _ = ws.Close() })
+
+		req := dnsservertest.CreateMessage(dnssvctest.DomainFQDN, dns.TypeA)
+		resp := exchangeWebSocket(t, ws, req)
+		dnsservertest.RequireResponse(t, req, resp, 1, dns.RcodeSuccess, false)
+
+		assert.Equal(t, dnssvctest.DeviceID, <-profileDBCh)
+
+		logEntry := <-querylogCh
+		assert.Equal(t, dnssvctest.DomainFQDN, logEntry.DomainFQDN)
+
+		assert.Equal(t, "", <-geoIPCh)
+		assert.Equal(t, dnssvctest.Domain, <-geoIPCh)
+		assert.NotNil(t, <-dnsDBCh)
+		assert.Equal(t, filter.RuleText(""), <-ruleStatCh)
+
+		// The server must close the connection once the limit is reached.
+		var b []byte
+		err = websocket.Message.Receive(ws, &b)
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("cross_origin", func(t *testing.T) {
+		wsConf := newWebSocketConfig(t, tlsConf, addr, dnsserver.PathWebSocket)
+		wsConf.Origin = &url.URL{Scheme: "https", Host: "evil.example"}
+
+		_, err := websocket.DialConfig(wsConf)
+		require.Error(t, err)
+	})
+}
+
+// newWebSocketConfig returns a new WebSocket client configuration for the
+// DNS-over-WebSocket endpoint at urlPath of the server with the given TLS
+// configuration and address.  The origin is the same as the server's one.
+func newWebSocketConfig(
+	t testing.TB,
+	srvTLSConf *tls.Config,
+	addr net.Addr,
+	urlPath string,
+) (wsConf *websocket.Config) {
+	t.Helper()
+
+	u := &url.URL{
+		Scheme: "wss",
+		Host:   addr.String(),
+		Path:   urlPath,
+	}
+
+	origin := &url.URL{
+		Scheme: "https",
+		Host:   addr.String(),
+	}
+
+	wsConf, err := websocket.NewConfig(u.String(), origin.String())
+	require.NoError(t, err)
+
+	wsConf.TlsConfig = srvTLSConf.Clone()
+	wsConf.TlsConfig.NextProtos = []string{"http/1.1"}
+
+	return wsConf
+}
+
+// exchangeWebSocket sends req over ws and returns the response.
+func exchangeWebSocket(t testing.TB, ws *websocket.Conn, req *dns.Msg) (resp *dns.Msg) {
+	t.Helper()
+
+	b, err := req.Pack()
+	require.NoError(t, err)
+
+	err = websocket.Message.Send(ws, b)
+	require.NoError(t, err)
+
+	err = websocket.Message.Receive(ws, &b)
+	require.NoError(t, err)
+
+	resp = &dns.Msg{}
+	err = resp.Unpack(b)
+	require.NoError(t, err)
+
+	return resp
+}
@@ -0,0 +1,173 @@
+// Package cnamemw contains the middleware that enforces the maximum length of
+// the CNAME chains in the upstream responses and detects the CNAME loops.
+package cnamemw
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// Config is the configuration structure for the CNAME-chain middleware.
+type Config struct {
+	// Logger is used to log the truncated chains.  It must not be nil.
+	Logger *slog.Logger
+
+	// Metrics is used to collect the statistics.  It must not be nil.
+	Metrics Metrics
+
+	// MaxChainLen is the maximum number of CNAME records in a chain.  It must
+	// be positive.
+	MaxChainLen int
+}
+
+// Middleware truncates the CNAME chains in the responses of the wrapped handler
+// if they are longer than the configured maximum or contain loops.  Only the
+// CNAME records of the chain up to the point of truncation and their
+// signatures are kept in the answer section, so that neither the cache nor the
+// clients receive the pathological chains.
+type Middleware struct {
+	logger      *slog.Logger
+	metrics     Metrics
+	maxChainLen int
+}
+
+// New returns a new CNAME-chain middleware.  c must not be nil.
+func New(c *Config) (mw *Middleware) {
+	return &Middleware{
+		logger:      c.Logger,
+		metrics:     c.Metrics,
+		maxChainLen: c.MaxChainLen,
+	}
+}
+
+// type check
+var _ dnsserver.Middleware = (*Middleware)(nil)
+
+// Wrap implements the [dnsserver.Middleware] interface for *Middleware.
+func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
+	f := func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (err error) {
+		defer func() { err = errors.Annotate(err, "cnamemw: %w") }()
+
+		nwrw := internal.MakeNonWriter(rw)
+		err = next.ServeDNS(ctx, nwrw, req)
+		if err != nil {
+			// Don't wrap the error, because this is the main flow, and there is
+			// already errors.Annotate here.
+			return err
+		}
+
+		resp := nwrw.Msg()
+		mw.checkChain(ctx, req, resp)
+
+		err = rw.WriteMsg(ctx, req, resp)
+		if err != nil {
+			return fmt.Errorf("writing response: %w", err)
+		}
+
+		return nil
+	}
+
+	return dnsserver.HandlerFunc(f)
+}
+
+// checkChain follows the CNAME chain in resp starting from the name in the
+// question of req and truncates it if necessary.
+func (mw *Middleware) checkChain(ctx context.Context, req, resp *dns.Msg) {
+	if resp == nil || !hasCNAME(resp.Answer) {
+		return
+	}
+
+	name := req.Question[0].Name
+
+	// Use a slice instead of a map, since the chains are short.
+	chain := make([]*dns.CNAME, 0, mw.maxChainLen)
+	var isLoop bool
+	for {
+		cname := findCNAME(resp.Answer, name)
+		if cname == nil {
+			// The end of the chain.
+			return
+		}
+
+		if isLoop = isVisited(chain, cname); isLoop {
+			break
+		} else if len(chain) == mw.maxChainLen {
+			break
+		}
+
+		chain = append(chain, cname)
+		name = cname.Target
+	}
+
+	resp.Answer = truncate(resp.Answer, chain)
+	mw.metrics.OnTruncated(ctx, isLoop)
+
+	optslog.Debug3(
+		ctx,
+		mw.logger,
+		"truncated cname chain",
+		"qname", req.Question[0].Name,
+		"len", len(chain),
+		"loop", isLoop,
+	)
+}
+
+// hasCNAME returns true if rrs contain at least one CNAME record.
+func hasCNAME(rrs []dns.RR) (ok bool) {
+	return slices.ContainsFunc(rrs, func(rr dns.RR) (isCNAME bool) {
+		_, isCNAME = rr.(*dns.CNAME)
+
+		return isCNAME
+	})
+}
+
+// findCNAME returns the CNAME record with the owner name name from rrs or nil
+// if there is none.
+func findCNAME(rrs []dns.RR, name string) (cname *dns.CNAME) {
+	for _, rr := range rrs {
+		c, ok := rr.(*dns.CNAME)
+		if ok && strings.EqualFold(c.Hdr.Name, name) {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// isVisited returns true if the target of cname is the owner name of cname
+// itself or of one of the records in chain.
+func isVisited(chain []*dns.CNAME, cname *dns.CNAME) (ok bool) {
+	return strings.EqualFold(cname.Hdr.Name, cname.Target) || isOwner(chain, cname.Target)
+}
+
+// isOwner returns true if name is the owner name of one of the records in
+// chain.
+func isOwner(chain []*dns.CNAME, name string) (ok bool) {
+	return slices.ContainsFunc(chain, func(c *dns.CNAME) (eq bool) {
+		return strings.EqualFold(c.Hdr.Name, name)
+	})
+}
+
+// truncate returns the records from rrs that belong to chain: the CNAME records
+// themselves and the RRSIG records covering them.  rrs is modified.
+func truncate(rrs []dns.RR, chain []*dns.CNAME) (res []dns.RR) {
+	return slices.DeleteFunc(rrs, func(rr dns.RR) (del bool) {
+		switch rr := rr.(type) {
+		case *dns.CNAME:
+			return !slices.Contains(chain, rr)
+		case *dns.RRSIG:
+			return rr.TypeCovered != dns.TypeCNAME || !isOwner(chain, rr.Hdr.Name)
+		default:
+			return true
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package cnamemw_test
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/cnamemw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMaxChainLen is the maximum length of the CNAME chains for tests.
+const testMaxChainLen = 2
+
+// Common domain names for tests.
+const (
+	testName   = "example.com."
+	testFirst  = "first.example.net."
+	testSecond = "second.example.net."
+	testThird  = "third.example.net."
+)
+
+// testIP is the IP address of the final answer for tests.
+var testIP = netip.MustParseAddr("192.0.2.1")
+
+// testMetrics is a [cnamemw.Metrics] implementation for tests.
+type testMetrics struct {
+	onOnTruncated func(ctx context.Context, isLoop bool)
+}
+
+// type check
+var _ cnamemw.Metrics = (*testMetrics)(nil)
+
+// OnTruncated implements the [cnamemw.Metrics] interface for *testMetrics.
+func (m *testMetrics) OnTruncated(ctx context.Context, isLoop bool) {
+	m.onOnTruncated(ctx, isLoop)
+}
+
+func TestMiddleware_Wrap(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		wantLoop    assert.BoolAssertionFunc
+		name        string
+		answer      dnsservertest.SectionAnswer
+		wantAnswer  dnsservertest.SectionAnswer
+		wantTrunced bool
+	}{{
+		wantLoop: assert.False,
+		name:     "no_cname",
+		answer: dnsservertest.SectionAnswer{
+			dnsservertest.NewA(testName, 10, testIP),
+		},
+		wantAnswer: dnsservertest.SectionAnswer{
+			dnsservertest.NewA(testName, 10, testIP),
+		},
+		wantTrunced: false,
+	}, {
+		wantLoop: assert.False,
+		name:     "short_chain",
+		answer: dnsservertest.SectionAnswer{
+			dnsservertest.NewCNAME(testName, 10, testFirst),
+			dnsservertest.NewCNAME(testFirst, 10, testSecond),
+			dnsservertest.NewA(testSecond, 10, testIP),
+		},
+		wantAnswer: dnsservertest.SectionAnswer{
+			dnsservertest.NewCNAME(testName, 10, testFirst),
+			dnsservertest.NewCNAME(testFirst, 10, testSecond),
+			dnsservertest.NewA(testSecond, 10, testIP),
+		},
+		wantTrunced: false,
+	}, {
+		wantLoop: assert.False,
+		name:     "long_chain",
+		answer: dnsservertest.SectionAnswer{
+			dnsservertest.NewCNAME(testName, 10, testFirst),
+			dnsservertest.NewCNAME(testSecond, 10, testThird),
+			dnsservertest.NewCNAME(testFirst, 10, testSecond),
+			dnsservertest.NewA(testThird, 10, testIP),
+		},
+		wantAnswer: dnsservertest.SectionAnswer{
+			dnsservertest.NewCNAME(testName, 10, testFirst),
+			dnsservertest.NewCNAME(testFirst, 10, testSecond),
+		},
+		wantTrunced: true,
+	}, {
+		wantLoop: assert.True,
+		name:     "loop",
+		answer: dnsservertest.SectionAnswer{
+			dnsservertest.NewCNAME(testName, 10, testFirst),
+			dnsservertest.NewCNAME(testFirst, 10, "EXAMPLE.com."),
+		},
+		wantAnswer: dnsservertest.SectionAnswer{
+			dnsservertest.NewCNAME(testName, 10, testFirst),
+		},
+		wantTrunced: true,
+	}, {
+		wantLoop: assert.True,
+		name:     "self_loop",
+		answer: dnsservertest.SectionAnswer{
+			dnsservertest.NewCNAME(testName, 10, testName),
+		},
+		wantAnswer:  dnsservertest.SectionAnswer{},
+		wantTrunced: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotTrunced, gotLoop bool
+			mw := cnamemw.New(&cnamemw.Config{
+				Logger: slogutil.NewDiscardLogger(),
+				Metrics: &testMetrics{
+					onOnTruncated: func(_ context.Context, isLoop bool) {
+						gotTrunced, gotLoop = true, isLoop
+					},
+				},
+				MaxChainLen: testMaxChainLen,
+			})
+
+			upstream := dnsserver.HandlerFunc(func(
+				ctx context.Context,
+				rw dnsserver.ResponseWriter,
+				req *dns.Msg,
+			) (err error) {
+				resp := dnsservertest.NewResp(dns.RcodeSuccess, req, tc.answer)
+
+				return rw.WriteMsg(ctx, req, resp)
+			})
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			req := dnsservertest.NewReq(testName, dns.TypeA, dns.ClassINET)
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+
+			err := mw.Wrap(upstream).ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			assert.Equal(t, []dns.RR(tc.wantAnswer), resp.Answer)
+			assert.Equal(t, tc.wantTrunced, gotTrunced)
+			tc.wantLoop(t, gotLoop)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package cnamemw
+
+import "context"
+
+// Metrics is an interface for monitoring the [cnamemw.Middleware] state.
+type Metrics interface {
+	// OnTruncated is called when the CNAME chain in a response has been
+	// truncated.  isLoop is true if the chain has been truncated because of a
+	// loop and false if it has been truncated because of its length.
+	OnTruncated(ctx context.Context, isLoop bool)
+}
+
+// EmptyMetrics is an empty [Metrics] implementation that does nothing.
+type EmptyMetrics struct{}
+
+// type check
+var _ Metrics = EmptyMetrics{}
+
+// OnTruncated implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) OnTruncated(_ context.Context, _ bool) {}
@@ -0,0 +1,91 @@
+package devicefinder
+
+import (
+	"context"
+	"net/netip"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+)
+
+// connDeviceData is the device data extracted from the TLS server name of a
+// DoT connection.  It is used as the [dnsserver.RequestInfo.ConnHint] of the
+// requests sent over the connection.
+type connDeviceData struct {
+	// extID is the extended human-readable device identifier, if any.
+	extID *extHumanID
+
+	// id is the device ID, if any.
+	id agd.DeviceID
+}
+
+// type check
+var _ dnsserver.TLSConnInspector = (*Default)(nil)
+
+// InspectTLSConn implements the [dnsserver.TLSConnInspector] interface for
+// *Default.  It extracts the device data from serverName once per connection,
+// so that the queries sent over it don't have to.  If strict SNI is enabled,
+// it also rejects the connections the server names of which are invalid or
+// point to no known and authenticated device.
+func (f *Default) InspectTLSConn(
+	ctx context.Context,
+	serverName string,
+	raddr netip.AddrPort,
+	laddr netip.AddrPort,
+) (hint any, err error) {
+	srvReqInfo := &dnsserver.RequestInfo{
+		TLSServerName: serverName,
+	}
+
+	id, extID, err := f.deviceDataFromSrvReqInfo(ctx, srvReqInfo)
+	if err != nil {
+		if f.strictSNI {
+			// Don't wrap the error, because it's informative enough as is.
+			return nil, err
+		}
+
+		// Let the queries report the error.
+		return nil, nil
+	}
+
+	if f.strictSNI && (id != "" || extID != nil) {
+		err = f.checkSNIDevice(ctx, srvReqInfo, raddr, laddr, id, extID)
+		if err != nil {
+			// Don't wrap the error, because it's informative enough as is.
+			return nil, err
+		}
+	}
+
+	return &connDeviceData{
+		extID: extID,
+		id:    id,
+	}, nil
+}
+
+// checkSNIDevice returns an error if the device with the data from the TLS
+// server name isn't found or doesn't pass the authentication.  srvReqInfo must
+// not be nil.
+func (f *Default) checkSNIDevice(
+	ctx context.Context,
+	srvReqInfo *dnsserver.RequestInfo,
+	raddr netip.AddrPort,
+	laddr netip.AddrPort,
+	id agd.DeviceID,
+	extID *extHumanID,
+) (err error) {
+	r := f.findDevice(ctx, laddr, raddr.Addr(), id, extID)
+	if ok, isOK := r.(*agd.DeviceResultOK); isOK {
+		r = f.authenticatedResult(ctx, srvReqInfo, ok)
+	}
+
+	switch r := r.(type) {
+	case *agd.DeviceResultOK:
+		return nil
+	case *agd.DeviceResultAuthenticationFailure:
+		return r.Err
+	case *agd.DeviceResultError:
+		return r.Err
+	default:
+		return ErrUnknownDevice
+	}
+}
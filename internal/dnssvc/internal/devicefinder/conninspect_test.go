@@ -0,0 +1,131 @@
+package devicefinder_test
+
+import (
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/devicefinder"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault_InspectTLSConn(t *testing.T) {
+	t.Parallel()
+
+	profDB := agdtest.NewProfileDB()
+	profDB.OnProfileByDeviceID = newOnProfileByDeviceID(dnssvctest.DeviceID)
+
+	const (
+		unknownSrvName = "unknown." + dnssvctest.DomainForDevices
+		badSrvName     = "!!!." + dnssvctest.DomainForDevices
+	)
+
+	testCases := []struct {
+		wantRes     agd.DeviceResult
+		cliSrvName  string
+		name        string
+		wantErrMsg  string
+		strictSNI   bool
+		wantNilHint bool
+	}{{
+		wantRes:     resNormal,
+		cliSrvName:  dnssvctest.DeviceIDSrvName,
+		name:        "id_match",
+		wantErrMsg:  "",
+		strictSNI:   false,
+		wantNilHint: false,
+	}, {
+		wantRes:     resNormal,
+		cliSrvName:  dnssvctest.DeviceIDSrvName,
+		name:        "id_match_strict",
+		wantErrMsg:  "",
+		strictSNI:   true,
+		wantNilHint: false,
+	}, {
+		wantRes:     nil,
+		cliSrvName:  unknownSrvName,
+		name:        "unknown_id",
+		wantErrMsg:  "",
+		strictSNI:   false,
+		wantNilHint: false,
+	}, {
+		wantRes:     nil,
+		cliSrvName:  unknownSrvName,
+		name:        "unknown_id_strict",
+		wantErrMsg:  string(devicefinder.ErrUnknownDevice),
+		strictSNI:   true,
+		wantNilHint: true,
+	}, {
+		wantRes:     nil,
+		cliSrvName:  badSrvName,
+		name:        "bad_id",
+		wantErrMsg:  "",
+		strictSNI:   false,
+		wantNilHint: true,
+	}, {
+		wantRes:    nil,
+		cliSrvName: badSrvName,
+		name:       "bad_id_strict",
+		wantErrMsg: `tls server name device id check: bad device id "!!!": ` +
+			`bad hostname label rune '!'`,
+		strictSNI:   true,
+		wantNilHint: true,
+	}, {
+		wantRes:     nil,
+		cliSrvName:  "other.example",
+		name:        "no_match_strict",
+		wantErrMsg:  "",
+		strictSNI:   true,
+		wantNilHint: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			df := devicefinder.NewDefault(&devicefinder.Config{
+				Logger:        slogutil.NewDiscardLogger(),
+				ProfileDB:     profDB,
+				HumanIDParser: agd.NewHumanIDParser(),
+				LeaseSource:   dhcplease.Empty{},
+				Server:        srvDoT,
+				WhiteLabel:    whitelabel.Empty{},
+				DeviceDomains: []string{dnssvctest.DomainForDevices},
+				StrictSNI:     tc.strictSNI,
+			})
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			hint, err := df.InspectTLSConn(
+				ctx,
+				tc.cliSrvName,
+				dnssvctest.ClientAddrPort,
+				dnssvctest.ServerAddrPort,
+			)
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, err)
+
+			if tc.wantNilHint {
+				assert.Nil(t, hint)
+
+				return
+			}
+
+			require.NotNil(t, hint)
+
+			// Make sure that the device data are taken from the hint and not
+			// from the server name.
+			ctx = dnsserver.ContextWithRequestInfo(ctx, &dnsserver.RequestInfo{
+				ConnHint: hint,
+			})
+
+			got := df.Find(ctx, reqNormal, dnssvctest.ClientAddrPort, dnssvctest.ServerAddrPort)
+			assertEqualResult(t, tc.wantRes, got)
+		})
+	}
+}
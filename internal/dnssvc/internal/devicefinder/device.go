@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/netip"
+	"net/url"
+	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
@@ -71,11 +73,16 @@ func (f *Default) deviceFromDB(
 		return f.newDeviceResult(ctx, prof, dev, "human id", err)
 	}
 
-	if f.srv.Protocol == agd.ProtoDNS {
-		return f.deviceByAddrs(ctx, laddr, remoteIP)
+	if f.srv.Protocol != agd.ProtoDNS {
+		return nil
 	}
 
-	return nil
+	r = f.deviceByAddrs(ctx, laddr, remoteIP)
+	if r != nil {
+		return r
+	}
+
+	return f.deviceByLease(ctx, remoteIP)
 }
 
 // newDeviceResult is a helper that returns a result based on the error and the
@@ -139,6 +146,9 @@ func (f *Default) deviceByExtID(
 		// A rare case where a profile has been deleted between the check and
 		// the creation.
 		return nil, nil, nil
+	case errorIsOpt(err, profiledb.ErrDeviceNotFound):
+		// The creation of this device has recently failed.
+		return nil, nil, nil
 	default:
 		return nil, nil, fmt.Errorf("creating autodevice: %w", err)
 	}
@@ -187,6 +197,24 @@ func (f *Default) deviceByAddrs(
 	return f.newDeviceResult(ctx, prof, dev, "linked ip", err)
 }
 
+// deviceByLease finds the profile and the device by the DHCP lease of the
+// remote address.
+func (f *Default) deviceByLease(ctx context.Context, remoteIP netip.Addr) (r agd.DeviceResult) {
+	id, err := f.leases.DeviceID(ctx, remoteIP)
+	if err != nil {
+		// Unlikely, so wrap.
+		return &agd.DeviceResultError{
+			Err: fmt.Errorf("looking up by dhcp lease: %w", err),
+		}
+	} else if id == "" {
+		return nil
+	}
+
+	prof, dev, err := f.db.ProfileByDeviceID(ctx, id)
+
+	return f.newDeviceResult(ctx, prof, dev, "dhcp lease", err)
+}
+
 // deviceByLocalAddr finds the profile and the device by the local address.
 func (f *Default) deviceByLocalAddr(
 	ctx context.Context,
@@ -229,6 +257,22 @@ func (f *Default) deviceByLocalAddr(
 	}
 }
 
+// authenticateToken returns an error if the device with the given ID and auth
+// settings doesn't pass the authentication using the token from the query of
+// the DoH request URL u.  conf must not be nil.
+func authenticateToken(conf *agd.AuthSettings, id agd.DeviceID, u *url.URL) (err error) {
+	token := authTokenFromURL(u)
+	if token == "" || len(conf.DoHQueryKey) == 0 {
+		if conf.DoHAuthOnly {
+			return ErrNoUserInfo
+		}
+
+		return nil
+	}
+
+	return verifyAuthToken(conf.DoHQueryKey, id, token, time.Now())
+}
+
 // authenticatedResult authenticates the device, if necessary, and returns the
 // corresponding result.  It also logs its decisions.  All arguments must not be
 // nil.
@@ -242,11 +286,17 @@ func (f *Default) authenticatedResult(
 	if err != nil {
 		metrics.DNSSvcDoHAuthFailsTotal.Inc()
 
-		optslog.Debug2(
+		var ja4 string
+		if fp := srvReqInfo.TLSFingerprint; fp != nil {
+			ja4 = fp.JA4
+		}
+
+		optslog.Debug3(
 			ctx,
 			f.logger,
 			"authentication failed",
 			"dev_id", dev.ID,
+			"tls_fingerprint", ja4,
 			slogutil.KeyError, err,
 		)
 
@@ -280,11 +330,8 @@ func (f *Default) authenticate(
 
 	userinfo := srvReqInfo.Userinfo
 	if userinfo == nil {
-		if conf.DoHAuthOnly {
-			return ErrNoUserInfo
-		}
-
-		return nil
+		// Don't wrap the error, because it's informative enough as is.
+		return authenticateToken(conf, dev.ID, srvReqInfo.URL)
 	}
 
 	// NOTE: It is currently assumed that if the execution got here, the device
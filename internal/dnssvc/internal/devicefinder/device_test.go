@@ -8,10 +8,12 @@ import (
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/devicefinder"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/testutil"
@@ -88,7 +90,9 @@ func TestDefault_Find_plainAddrs(t *testing.T) {
 				Logger:        slogutil.NewDiscardLogger(),
 				ProfileDB:     profDB,
 				HumanIDParser: agd.NewHumanIDParser(),
+				LeaseSource:   dhcplease.Empty{},
 				Server:        tc.srv,
+				WhiteLabel:    whitelabel.Empty{},
 				DeviceDomains: nil,
 			})
 
@@ -158,7 +162,9 @@ func TestDefault_Find_plainEDNS(t *testing.T) {
 				Logger:        slogutil.NewDiscardLogger(),
 				ProfileDB:     profDB,
 				HumanIDParser: agd.NewHumanIDParser(),
+				LeaseSource:   dhcplease.Empty{},
 				Server:        tc.srv,
+				WhiteLabel:    whitelabel.Empty{},
 				DeviceDomains: nil,
 			})
 
@@ -170,6 +176,67 @@ func TestDefault_Find_plainEDNS(t *testing.T) {
 	}
 }
 
+func TestDefault_Find_dhcpLease(t *testing.T) {
+	t.Parallel()
+
+	leases := &agdtest.LeaseSource{
+		OnDeviceID: func(_ context.Context, ip netip.Addr) (id agd.DeviceID, err error) {
+			if ip == dnssvctest.ClientAddr {
+				return dnssvctest.DeviceID, nil
+			}
+
+			return "", nil
+		},
+	}
+
+	testCases := []struct {
+		wantRes agd.DeviceResult
+		srv     *agd.Server
+		raddr   netip.AddrPort
+		name    string
+	}{{
+		wantRes: resNormal,
+		srv:     srvPlainWithLinkedIP,
+		raddr:   dnssvctest.ClientAddrPort,
+		name:    "lease",
+	}, {
+		wantRes: nil,
+		srv:     srvPlainWithLinkedIP,
+		raddr:   dnssvctest.ServerAddrPort,
+		name:    "no_lease",
+	}, {
+		wantRes: nil,
+		srv:     srvDoT,
+		raddr:   dnssvctest.ClientAddrPort,
+		name:    "not_plain",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			profDB := agdtest.NewProfileDB()
+			profDB.OnProfileByDeviceID = newOnProfileByDeviceID(dnssvctest.DeviceID)
+			profDB.OnProfileByLinkedIP = newOnProfileByLinkedIP(dnssvctest.LinkedAddr)
+
+			df := devicefinder.NewDefault(&devicefinder.Config{
+				Logger:        slogutil.NewDiscardLogger(),
+				ProfileDB:     profDB,
+				HumanIDParser: agd.NewHumanIDParser(),
+				LeaseSource:   leases,
+				Server:        tc.srv,
+				WhiteLabel:    whitelabel.Empty{},
+				DeviceDomains: nil,
+			})
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = dnsserver.ContextWithRequestInfo(ctx, &dnsserver.RequestInfo{})
+			got := df.Find(ctx, reqNormal, tc.raddr, dnssvctest.ServerAddrPort)
+			assertEqualResult(t, tc.wantRes, got)
+		})
+	}
+}
+
 func TestDefault_Find_deleted(t *testing.T) {
 	t.Parallel()
 
@@ -185,7 +252,9 @@ func TestDefault_Find_deleted(t *testing.T) {
 		Logger:        slogutil.NewDiscardLogger(),
 		ProfileDB:     profDB,
 		HumanIDParser: agd.NewHumanIDParser(),
+		LeaseSource:   dhcplease.Empty{},
 		Server:        srvPlainWithLinkedIP,
+		WhiteLabel:    whitelabel.Empty{},
 	})
 
 	ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
@@ -223,7 +292,9 @@ func TestDefault_Find_byHumanID(t *testing.T) {
 		Logger:        slogutil.NewDiscardLogger(),
 		ProfileDB:     profDB,
 		HumanIDParser: agd.NewHumanIDParser(),
+		LeaseSource:   dhcplease.Empty{},
 		Server:        srvDoT,
+		WhiteLabel:    whitelabel.Empty{},
 		DeviceDomains: []string{dnssvctest.DomainForDevices},
 	})
 
@@ -3,7 +3,6 @@ package devicefinder
 import (
 	"context"
 	"fmt"
-	"net/url"
 	"path"
 	"strings"
 
@@ -23,6 +22,10 @@ func (f *Default) deviceData(
 	req *dns.Msg,
 	srvReqInfo *dnsserver.RequestInfo,
 ) (id agd.DeviceID, extID *extHumanID, err error) {
+	if hint, ok := srvReqInfo.ConnHint.(*connDeviceData); ok {
+		return hint.id, hint.extID, nil
+	}
+
 	if f.srv.Protocol.IsStdEncrypted() {
 		return f.deviceDataFromSrvReqInfo(ctx, srvReqInfo)
 	}
@@ -41,6 +44,9 @@ func (f *Default) deviceData(
 //  2. Secondly, the TLS Server Name is inspected using the device domains
 //     configured for the device finder.
 //
+//  3. Finally, the TLS Server Name is inspected using the dynamic device
+//     domains of the server group, if any.
+//
 // Any returned errors will have the underlying type of [*deviceDataError].
 func (f *Default) deviceDataFromSrvReqInfo(
 	ctx context.Context,
@@ -54,16 +60,30 @@ func (f *Default) deviceDataFromSrvReqInfo(
 		}
 	}
 
-	if len(f.deviceDomains) == 0 {
+	cliSrvName := srvReqInfo.TLSServerName
+	id, extID, err = f.deviceDataFromCliSrvName(ctx, cliSrvName, f.deviceDomains)
+	if id != "" || extID != nil || err != nil {
+		return id, extID, annotateCliSrvNameError(err)
+	}
+
+	s := f.whiteLabel.ServerGroupSettings(ctx, f.srvGrpName)
+	if s == nil {
 		return "", nil, nil
 	}
 
-	id, extID, err = f.deviceDataFromCliSrvName(ctx, srvReqInfo.TLSServerName)
-	if err != nil {
-		return "", nil, newDeviceDataError(err, "tls server name")
+	id, extID, err = f.deviceDataFromCliSrvName(ctx, cliSrvName, s.DeviceDomains)
+
+	return id, extID, annotateCliSrvNameError(err)
+}
+
+// annotateCliSrvNameError returns err wrapped into a [*deviceDataError] for the
+// TLS server name, if err is not nil.
+func annotateCliSrvNameError(err error) (annotated error) {
+	if err == nil {
+		return nil
 	}
 
-	return id, extID, nil
+	return newDeviceDataError(err, "tls server name")
 }
 
 // deviceDataForDoH extracts the device data from the DoH request information.
@@ -85,7 +105,7 @@ func (f *Default) deviceDataForDoH(
 		return id, nil, nil
 	}
 
-	id, extID, err = f.deviceDataFromDoHURL(srvReqInfo.URL)
+	id, extID, err = f.deviceDataFromDoHPath(srvReqInfo.HTTPPath())
 	if err != nil {
 		return "", nil, newDeviceDataError(err, "http url path")
 	}
@@ -94,12 +114,12 @@ func (f *Default) deviceDataForDoH(
 	return id, extID, nil
 }
 
-// deviceDataFromDoHURL extracts the device data from the path of the DoH
+// deviceDataFromDoHPath extracts the device data from the path of the DoH
 // request.
-func (f *Default) deviceDataFromDoHURL(
-	u *url.URL,
+func (f *Default) deviceDataFromDoHPath(
+	urlPath string,
 ) (id agd.DeviceID, extID *extHumanID, err error) {
-	elems, err := pathElements(u.Path)
+	elems, err := pathElements(urlPath)
 	if err != nil {
 		// Don't wrap the error, because it's informative enough as is.
 		return "", nil, err
@@ -133,7 +153,8 @@ func pathElements(urlPath string) (elems []string, err error) {
 	}
 
 	if !strings.HasSuffix(dnsserver.PathDoH, elems[0]) &&
-		!strings.HasSuffix(dnsserver.PathJSON, elems[0]) {
+		!strings.HasSuffix(dnsserver.PathJSON, elems[0]) &&
+		!strings.HasSuffix(dnsserver.PathWebSocket, elems[0]) {
 		return nil, errors.Error("not a dns path")
 	}
 
@@ -141,18 +162,20 @@ func pathElements(urlPath string) (elems []string, err error) {
 }
 
 // deviceDataFromCliSrvName extracts and validates device data.  cliSrvName is
-// the server name as sent by the client.
+// the server name as sent by the client, domains are the device domains to
+// match it against.
 func (f *Default) deviceDataFromCliSrvName(
 	ctx context.Context,
 	cliSrvName string,
+	domains []string,
 ) (id agd.DeviceID, extID *extHumanID, err error) {
-	if cliSrvName == "" {
+	if cliSrvName == "" || len(domains) == 0 {
 		// No server name in ClientHello, so the request is probably made on the
 		// IP address.
 		return "", nil, nil
 	}
 
-	matchedDomain := matchDomain(cliSrvName, f.deviceDomains)
+	matchedDomain := matchDomain(cliSrvName, domains)
 	if matchedDomain == "" {
 		return "", nil, nil
 	}
@@ -162,7 +185,7 @@ func (f *Default) deviceDataFromCliSrvName(
 		f.logger,
 		"matched device id from domain",
 		"domain", matchedDomain,
-		"domains", f.deviceDomains,
+		"domains", domains,
 	)
 
 	idStr := cliSrvName[:len(cliSrvName)-len(matchedDomain)-1]
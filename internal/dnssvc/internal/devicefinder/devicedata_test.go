@@ -2,16 +2,23 @@ package devicefinder_test
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	"net/url"
 	"path"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/devicefinder"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/testutil"
@@ -103,7 +110,9 @@ func TestDefault_Find_DoHAuth(t *testing.T) {
 				Logger:        slogutil.NewDiscardLogger(),
 				ProfileDB:     profDB,
 				HumanIDParser: agd.NewHumanIDParser(),
+				LeaseSource:   dhcplease.Empty{},
 				Server:        srvDoH,
+				WhiteLabel:    whitelabel.Empty{},
 				DeviceDomains: []string{},
 			})
 
@@ -199,7 +208,9 @@ func TestDefault_Find_DoHAuthOnly(t *testing.T) {
 				Logger:        slogutil.NewDiscardLogger(),
 				ProfileDB:     profDB,
 				HumanIDParser: agd.NewHumanIDParser(),
+				LeaseSource:   dhcplease.Empty{},
 				Server:        tc.srv,
+				WhiteLabel:    whitelabel.Empty{},
 				DeviceDomains: []string{dnssvctest.DomainForDevices},
 			})
 
@@ -219,6 +230,121 @@ func TestDefault_Find_DoHAuthOnly(t *testing.T) {
 	}
 }
 
+func TestDefault_Find_DoHAuthToken(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("test-key")
+	dev := newDevAuth(true, false)
+	dev.Auth.DoHQueryKey = key
+
+	now := time.Now()
+
+	testCases := []struct {
+		wantRes agd.DeviceResult
+		name    string
+		token   string
+	}{{
+		wantRes: &agd.DeviceResultOK{
+			Device:  dev,
+			Profile: profNormal,
+		},
+		name:  "success",
+		token: newAuthToken(key, dnssvctest.DeviceIDStr, now.Add(time.Hour)),
+	}, {
+		wantRes: &agd.DeviceResultAuthenticationFailure{
+			Err: devicefinder.ErrAuthTokenExpired,
+		},
+		name:  "expired",
+		token: newAuthToken(key, dnssvctest.DeviceIDStr, now.Add(-time.Hour)),
+	}, {
+		wantRes: &agd.DeviceResultAuthenticationFailure{
+			Err: devicefinder.ErrAuthTokenTooLong,
+		},
+		name: "too_long",
+		token: newAuthToken(
+			key,
+			dnssvctest.DeviceIDStr,
+			now.Add(devicefinder.MaxAuthTokenLifetime+time.Hour),
+		),
+	}, {
+		wantRes: &agd.DeviceResultAuthenticationFailure{
+			Err: devicefinder.ErrBadAuthToken,
+		},
+		name:  "bad_key",
+		token: newAuthToken([]byte("bad-key"), dnssvctest.DeviceIDStr, now.Add(time.Hour)),
+	}, {
+		wantRes: &agd.DeviceResultAuthenticationFailure{
+			Err: devicefinder.ErrBadAuthToken,
+		},
+		name:  "other_device",
+		token: newAuthToken(key, "otherdev", now.Add(time.Hour)),
+	}, {
+		wantRes: &agd.DeviceResultAuthenticationFailure{
+			Err: devicefinder.ErrBadAuthToken,
+		},
+		name:  "malformed",
+		token: "not-a-token",
+	}, {
+		wantRes: &agd.DeviceResultAuthenticationFailure{
+			Err: devicefinder.ErrNoUserInfo,
+		},
+		name:  "no_token",
+		token: "",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			profDB := agdtest.NewProfileDB()
+			profDB.OnProfileByDeviceID = func(
+				_ context.Context,
+				devID agd.DeviceID,
+			) (p *agd.Profile, d *agd.Device, err error) {
+				return profNormal, dev, nil
+			}
+
+			df := devicefinder.NewDefault(&devicefinder.Config{
+				Logger:        slogutil.NewDiscardLogger(),
+				ProfileDB:     profDB,
+				HumanIDParser: agd.NewHumanIDParser(),
+				LeaseSource:   dhcplease.Empty{},
+				Server:        srvDoH,
+				WhiteLabel:    whitelabel.Empty{},
+				DeviceDomains: []string{},
+			})
+
+			q := url.Values{}
+			if tc.token != "" {
+				q.Set(devicefinder.QueryParamAuthToken, tc.token)
+			}
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = dnsserver.ContextWithRequestInfo(ctx, &dnsserver.RequestInfo{
+				TLSServerName: dnssvctest.DomainForDevices,
+				URL: &url.URL{
+					Path:     path.Join(dnsserver.PathDoH, dnssvctest.DeviceIDStr),
+					RawQuery: q.Encode(),
+				},
+			})
+
+			got := df.Find(ctx, reqNormal, dnssvctest.ClientAddrPort, dnssvctest.ServerAddrPort)
+			assertEqualResult(t, tc.wantRes, got)
+		})
+	}
+}
+
+// newAuthToken returns a new authentication token for the device with the
+// given ID signed with key.
+func newAuthToken(key []byte, id string, exp time.Time) (token string) {
+	expStr := strconv.FormatInt(exp.Unix(), 10)
+
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(id + "." + expStr))
+
+	return expStr + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
 func TestDefault_Find_DoH(t *testing.T) {
 	t.Parallel()
 
@@ -232,6 +358,18 @@ func TestDefault_Find_DoH(t *testing.T) {
 			Path: path.Join(dnsserver.PathDoH, dnssvctest.DeviceIDStr),
 		},
 		name: "id_path_match",
+	}, {
+		wantRes: resNormal,
+		reqURL: &url.URL{
+			Path: path.Join(dnsserver.PathWebSocket, dnssvctest.DeviceIDStr),
+		},
+		name: "id_websocket_path_match",
+	}, {
+		wantRes: nil,
+		reqURL: &url.URL{
+			Path: dnsserver.PathWebSocket,
+		},
+		name: "no_id_websocket",
 	}, {
 		wantRes: &agd.DeviceResultError{
 			Err: errors.Error(
@@ -298,7 +436,9 @@ func TestDefault_Find_DoH(t *testing.T) {
 				Logger:        slogutil.NewDiscardLogger(),
 				ProfileDB:     profDB,
 				HumanIDParser: agd.NewHumanIDParser(),
+				LeaseSource:   dhcplease.Empty{},
 				Server:        srvDoH,
+				WhiteLabel:    whitelabel.Empty{},
 				DeviceDomains: []string{},
 			})
 
@@ -393,7 +533,9 @@ func TestDefault_Find_stdEncrypted(t *testing.T) {
 					Logger:        slogutil.NewDiscardLogger(),
 					ProfileDB:     profDB,
 					HumanIDParser: agd.NewHumanIDParser(),
+					LeaseSource:   dhcplease.Empty{},
 					Server:        sd.srv,
+					WhiteLabel:    whitelabel.Empty{},
 					DeviceDomains: tc.deviceDomains,
 				})
 
@@ -409,3 +551,86 @@ func TestDefault_Find_stdEncrypted(t *testing.T) {
 		}
 	}
 }
+
+func TestDefault_Find_whiteLabel(t *testing.T) {
+	t.Parallel()
+
+	const dynDomain = "d.partner.example"
+
+	wl := whitelabel.NewDynamic()
+	wl.Update(map[agd.ServerGroupName]*whitelabel.Settings{
+		dnssvctest.ServerGroupName: {
+			DeviceDomains: []string{dynDomain},
+		},
+	})
+
+	profDB := agdtest.NewProfileDB()
+	profDB.OnProfileByDeviceID = newOnProfileByDeviceID(dnssvctest.DeviceID)
+
+	testCases := []struct {
+		wantRes       agd.DeviceResult
+		srvGrpName    agd.ServerGroupName
+		cliSrvName    string
+		name          string
+		deviceDomains []string
+	}{{
+		wantRes:       resNormal,
+		srvGrpName:    dnssvctest.ServerGroupName,
+		cliSrvName:    dnssvctest.DeviceIDStr + "." + dynDomain,
+		name:          "dynamic_match",
+		deviceDomains: nil,
+	}, {
+		wantRes:       resNormal,
+		srvGrpName:    dnssvctest.ServerGroupName,
+		cliSrvName:    dnssvctest.DeviceIDStr + "." + dynDomain,
+		name:          "dynamic_match_after_static",
+		deviceDomains: []string{dnssvctest.DomainForDevices},
+	}, {
+		wantRes:       resNormal,
+		srvGrpName:    dnssvctest.ServerGroupName,
+		cliSrvName:    dnssvctest.DeviceIDSrvName,
+		name:          "static_match",
+		deviceDomains: []string{dnssvctest.DomainForDevices},
+	}, {
+		wantRes:       nil,
+		srvGrpName:    "other_server_group",
+		cliSrvName:    dnssvctest.DeviceIDStr + "." + dynDomain,
+		name:          "other_server_group",
+		deviceDomains: []string{dnssvctest.DomainForDevices},
+	}, {
+		wantRes: &agd.DeviceResultError{
+			Err: errors.Error(
+				`tls server name device id check: bad device id "!!!": bad hostname label rune '!'`,
+			),
+		},
+		srvGrpName:    dnssvctest.ServerGroupName,
+		cliSrvName:    "!!!." + dynDomain,
+		name:          "dynamic_bad_id",
+		deviceDomains: nil,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			df := devicefinder.NewDefault(&devicefinder.Config{
+				Logger:          slogutil.NewDiscardLogger(),
+				ProfileDB:       profDB,
+				HumanIDParser:   agd.NewHumanIDParser(),
+				LeaseSource:     dhcplease.Empty{},
+				Server:          srvDoT,
+				WhiteLabel:      wl,
+				ServerGroupName: tc.srvGrpName,
+				DeviceDomains:   tc.deviceDomains,
+			})
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = dnsserver.ContextWithRequestInfo(ctx, &dnsserver.RequestInfo{
+				TLSServerName: tc.cliSrvName,
+			})
+
+			got := df.Find(ctx, reqNormal, dnssvctest.ClientAddrPort, dnssvctest.ServerAddrPort)
+			assertEqualResult(t, tc.wantRes, got)
+		})
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"net/netip"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
 	"github.com/miekg/dns"
 )
 
@@ -26,13 +28,30 @@ type Config struct {
 	// identifiers.  It must not be nil.
 	HumanIDParser *agd.HumanIDParser
 
+	// LeaseSource is used to find the devices sending plain-DNS queries by
+	// their DHCP leases, if they couldn't be found by other means.  It must
+	// not be nil.
+	LeaseSource dhcplease.Interface
+
 	// Server contains the data of the server for which the profiles are found.
 	// It must not be nil.
 	Server *agd.Server
 
+	// WhiteLabel is used to get the additional device domains of the server
+	// group received from the backend.  It must not be nil.
+	WhiteLabel whitelabel.Interface
+
+	// ServerGroupName is the name of the server group of Server.
+	ServerGroupName agd.ServerGroupName
+
 	// DeviceDomains, if any, provides the domain names to use for looking up
 	// device ID from TLS server names.
 	DeviceDomains []string
+
+	// StrictSNI, if true, makes the device finder reject DoT connections the
+	// TLS server names of which match a device domain but don't point to a
+	// known and authenticated device.  It is only used for DoT servers.
+	StrictSNI bool
 }
 
 // Default is the default device finder.
@@ -42,8 +61,12 @@ type Default struct {
 	logger        *slog.Logger
 	db            profiledb.Interface
 	humanIDParser *agd.HumanIDParser
+	leases        dhcplease.Interface
 	srv           *agd.Server
+	whiteLabel    whitelabel.Interface
+	srvGrpName    agd.ServerGroupName
 	deviceDomains []string
+	strictSNI     bool
 }
 
 // NewDefault returns a new default device finder.  c must be valid and non-nil.
@@ -52,8 +75,12 @@ func NewDefault(c *Config) (f *Default) {
 		logger:        c.Logger,
 		db:            c.ProfileDB,
 		humanIDParser: c.HumanIDParser,
+		leases:        c.LeaseSource,
 		srv:           c.Server,
+		whiteLabel:    c.WhiteLabel,
+		srvGrpName:    c.ServerGroupName,
 		deviceDomains: c.DeviceDomains,
+		strictSNI:     c.StrictSNI,
 	}
 }
 
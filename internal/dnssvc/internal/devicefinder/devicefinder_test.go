@@ -11,12 +11,14 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdnet"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/devicefinder"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/testutil"
 	"github.com/miekg/dns"
@@ -257,9 +259,11 @@ func TestDefault_Find_dnscrypt(t *testing.T) {
 	df := devicefinder.NewDefault(&devicefinder.Config{
 		Logger:        slogutil.NewDiscardLogger(),
 		HumanIDParser: agd.NewHumanIDParser(),
+		LeaseSource:   dhcplease.Empty{},
 		Server: &agd.Server{
 			Protocol: agd.ProtoDNSCrypt,
 		},
+		WhiteLabel: whitelabel.Empty{},
 	})
 
 	ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
@@ -323,7 +327,9 @@ func BenchmarkDefault(b *testing.B) {
 			Logger:        slogutil.NewDiscardLogger(),
 			ProfileDB:     profDB,
 			HumanIDParser: agd.NewHumanIDParser(),
+			LeaseSource:   dhcplease.Empty{},
 			Server:        srvDoT,
+			WhiteLabel:    whitelabel.Empty{},
 			DeviceDomains: []string{dnssvctest.DomainForDevices},
 		},
 		req: reqNormal,
@@ -336,7 +342,9 @@ func BenchmarkDefault(b *testing.B) {
 			Logger:        slogutil.NewDiscardLogger(),
 			ProfileDB:     profDB,
 			HumanIDParser: agd.NewHumanIDParser(),
+			LeaseSource:   dhcplease.Empty{},
 			Server:        srvDoH,
+			WhiteLabel:    whitelabel.Empty{},
 			DeviceDomains: []string{dnssvctest.DomainForDevices},
 		},
 		req: reqNormal,
@@ -352,7 +360,9 @@ func BenchmarkDefault(b *testing.B) {
 			Logger:        slogutil.NewDiscardLogger(),
 			ProfileDB:     profDB,
 			HumanIDParser: agd.NewHumanIDParser(),
+			LeaseSource:   dhcplease.Empty{},
 			Server:        srvDoH,
+			WhiteLabel:    whitelabel.Empty{},
 			DeviceDomains: []string{dnssvctest.DomainForDevices},
 		},
 		req: reqNormal,
@@ -368,7 +378,9 @@ func BenchmarkDefault(b *testing.B) {
 			Logger:        slogutil.NewDiscardLogger(),
 			ProfileDB:     profDB,
 			HumanIDParser: agd.NewHumanIDParser(),
+			LeaseSource:   dhcplease.Empty{},
 			Server:        srvPlain,
+			WhiteLabel:    whitelabel.Empty{},
 			DeviceDomains: nil,
 		},
 		req:        reqEDNSDevID,
@@ -379,7 +391,9 @@ func BenchmarkDefault(b *testing.B) {
 			Logger:        slogutil.NewDiscardLogger(),
 			ProfileDB:     profDB,
 			HumanIDParser: agd.NewHumanIDParser(),
+			LeaseSource:   dhcplease.Empty{},
 			Server:        srvPlainWithBindData,
+			WhiteLabel:    whitelabel.Empty{},
 			DeviceDomains: nil,
 		},
 		req:        reqNormal,
@@ -390,7 +404,9 @@ func BenchmarkDefault(b *testing.B) {
 			Logger:        slogutil.NewDiscardLogger(),
 			ProfileDB:     profDB,
 			HumanIDParser: agd.NewHumanIDParser(),
+			LeaseSource:   dhcplease.Empty{},
 			Server:        srvPlainWithLinkedIP,
+			WhiteLabel:    whitelabel.Empty{},
 			DeviceDomains: nil,
 		},
 		req:        reqNormal,
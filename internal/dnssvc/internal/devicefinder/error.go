@@ -10,11 +10,18 @@ import (
 // Authentication errors.
 const (
 	ErrAuthenticationFailed errors.Error = "basic authentication failed"
+	ErrAuthTokenExpired     errors.Error = "auth token expired"
+	ErrAuthTokenTooLong     errors.Error = "auth token lifetime too long"
+	ErrBadAuthToken         errors.Error = "bad auth token"
 	ErrNoPassword           errors.Error = "no password"
 	ErrNoUserInfo           errors.Error = "no userinfo"
 	ErrNotDoH               errors.Error = "not doh"
 )
 
+// ErrUnknownDevice is returned by [Default.InspectTLSConn] when the TLS server
+// name of a connection points to no known device and strict SNI is enabled.
+const ErrUnknownDevice errors.Error = "unknown device"
+
 // deviceDataError is an error about bad device data or other issues found
 // during device data checking.
 type deviceDataError struct {
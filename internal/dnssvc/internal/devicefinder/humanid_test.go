@@ -5,9 +5,11 @@ import (
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dhcplease"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/devicefinder"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/testutil"
@@ -55,7 +57,9 @@ func TestDefault_Find_humanID(t *testing.T) {
 				Logger:        slogutil.NewDiscardLogger(),
 				ProfileDB:     agdtest.NewProfileDB(),
 				HumanIDParser: agd.NewHumanIDParser(),
+				LeaseSource:   dhcplease.Empty{},
 				Server:        srvDoT,
+				WhiteLabel:    whitelabel.Empty{},
 				DeviceDomains: []string{dnssvctest.DomainForDevices},
 			})
 
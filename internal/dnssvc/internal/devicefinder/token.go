@@ -0,0 +1,81 @@
+package devicefinder
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// QueryParamAuthToken is the name of the query parameter of a DoH request
+// containing the signed authentication token of the device.  The token has
+// the following format:
+//
+//	<expiry>.<signature>
+//
+// Where expiry is the Unix time in seconds after which the token is no longer
+// valid and signature is the unpadded URL-safe base64 encoding of the
+// HMAC-SHA256 of the string "<device ID>.<expiry>" using the
+// [agd.AuthSettings.DoHQueryKey] of the device.  The expiry must not be later
+// than [MaxAuthTokenLifetime] from the moment of the request.
+const QueryParamAuthToken = "token"
+
+// MaxAuthTokenLifetime is the maximum duration for which an authentication
+// token may be valid, so that a leaked token can't be used indefinitely.
+const MaxAuthTokenLifetime = 30 * 24 * time.Hour
+
+// authTokenFromURL returns the authentication token from the query of u, if
+// any.
+func authTokenFromURL(u *url.URL) (token string) {
+	if u == nil {
+		return ""
+	}
+
+	return u.Query().Get(QueryParamAuthToken)
+}
+
+// verifyAuthToken returns an error if token is not a valid authentication
+// token for the device with the given ID signed with key at the moment now.
+// key must not be empty.
+func verifyAuthToken(key []byte, id agd.DeviceID, token string, now time.Time) (err error) {
+	expStr, sigStr, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrBadAuthToken
+	}
+
+	exp, err := strconv.ParseInt(expStr, 10, 64)
+	if err != nil {
+		return ErrBadAuthToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(sigStr)
+	if err != nil {
+		return ErrBadAuthToken
+	}
+
+	if !hmac.Equal(sig, authTokenSignature(key, id, expStr)) {
+		return ErrBadAuthToken
+	} else if now.Unix() > exp {
+		return ErrAuthTokenExpired
+	} else if exp-now.Unix() > int64(MaxAuthTokenLifetime/time.Second) {
+		return ErrAuthTokenTooLong
+	}
+
+	return nil
+}
+
+// authTokenSignature returns the signature of the authentication token for the
+// device with the given ID and expiry.
+func authTokenSignature(key []byte, id agd.DeviceID, expStr string) (sig []byte) {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(id))
+	_, _ = mac.Write([]byte{'.'})
+	_, _ = mac.Write([]byte(expStr))
+
+	return mac.Sum(nil)
+}
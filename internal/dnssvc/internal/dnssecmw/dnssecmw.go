@@ -0,0 +1,138 @@
+// Package dnssecmw contains the middleware that enforces the DNSSEC validation
+// of the upstream responses for the profiles that require it.
+package dnssecmw
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// extraText is the text of the Extended DNS Error sent with the responses for
+// the unvalidated zones.
+const extraText = "dnssec validation required"
+
+// Config is the configuration structure for the DNSSEC-requirement middleware.
+type Config struct {
+	// Logger is used to log the rejected responses.  It must not be nil.
+	Logger *slog.Logger
+
+	// Metrics is used to collect the statistics.  It must not be nil.
+	Metrics Metrics
+}
+
+// Middleware makes sure that the devices of the profiles with
+// [agd.Profile.RequireDNSSEC] only receive DNSSEC-validated upstream data.  For
+// such requests, it asks the upstream to validate the data by setting the AD
+// bit and clearing the CD bit, and replaces the responses without the AD bit
+// with SERVFAIL ones with an Extended DNS Error.  Requests from other clients
+// are passed through as is.
+//
+// TODO:  Validate the responses locally once there is a validating
+// middleware.
+type Middleware struct {
+	logger  *slog.Logger
+	metrics Metrics
+}
+
+// New returns a new DNSSEC-requirement middleware.  c must not be nil.
+func New(c *Config) (mw *Middleware) {
+	return &Middleware{
+		logger:  c.Logger,
+		metrics: c.Metrics,
+	}
+}
+
+// type check
+var _ dnsserver.Middleware = (*Middleware)(nil)
+
+// Wrap implements the [dnsserver.Middleware] interface for *Middleware.
+func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
+	f := func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (err error) {
+		ri := agd.MustRequestInfoFromContext(ctx)
+		if p, _ := ri.DeviceData(); p == nil || !p.RequireDNSSEC {
+			return next.ServeDNS(ctx, rw, req)
+		}
+
+		defer func() { err = errors.Annotate(err, "dnssecmw: %w") }()
+
+		nwrw := internal.MakeNonWriter(rw)
+		err = next.ServeDNS(ctx, nwrw, newUpstreamReq(req))
+		if err != nil {
+			// Don't wrap the error, because this is the main flow, and there is
+			// already errors.Annotate here.
+			return err
+		}
+
+		resp := mw.checkResp(ctx, ri, req, nwrw.Msg())
+
+		err = rw.WriteMsg(ctx, req, resp)
+		if err != nil {
+			return fmt.Errorf("writing response: %w", err)
+		}
+
+		return nil
+	}
+
+	return dnsserver.HandlerFunc(f)
+}
+
+// newUpstreamReq returns a request that asks the upstream to validate the data
+// for req.  If req already does that, it is returned as is.
+func newUpstreamReq(req *dns.Msg) (upsReq *dns.Msg) {
+	if req.AuthenticatedData && !req.CheckingDisabled {
+		return req
+	}
+
+	// Per RFC 6840, a set AD bit in the request signals that the requestor
+	// understands the AD bit in the response, so the upstream and the caches
+	// keep it even when the DO bit is not set.
+	upsReq = dnsmsg.Clone(req)
+	upsReq.AuthenticatedData = true
+	upsReq.CheckingDisabled = false
+
+	return upsReq
+}
+
+// checkResp returns resp if it contains validated data or is a failure already.
+// Otherwise, it returns a SERVFAIL response for req.  resp may be nil.
+func (mw *Middleware) checkResp(
+	ctx context.Context,
+	ri *agd.RequestInfo,
+	req *dns.Msg,
+	resp *dns.Msg,
+) (checked *dns.Msg) {
+	if resp == nil || resp.Rcode == dns.RcodeServerFailure {
+		return resp
+	}
+
+	isValidated := resp.AuthenticatedData
+	mw.metrics.OnResponse(ctx, isValidated)
+
+	if isValidated {
+		// Restore the bits of the original request and only keep the AD bit if
+		// the client can understand it.
+		resp.CheckingDisabled = req.CheckingDisabled
+		resp.AuthenticatedData = req.AuthenticatedData || dnsmsg.IsDO(req)
+
+		return resp
+	}
+
+	optslog.Debug2(
+		ctx,
+		mw.logger,
+		"unvalidated response",
+		"qname", req.Question[0].Name,
+		"rcode", dns.RcodeToString[resp.Rcode],
+	)
+
+	return ri.Messages.NewRespDNSSECRequired(req, extraText)
+}
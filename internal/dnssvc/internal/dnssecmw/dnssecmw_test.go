@@ -0,0 +1,206 @@
+package dnssecmw_test
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssecmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testName is the domain name for tests.
+const testName = "example.com."
+
+// testIP is the IP address of the answers for tests.
+var testIP = netip.MustParseAddr("192.0.2.1")
+
+// testMetrics is a [dnssecmw.Metrics] implementation for tests.
+type testMetrics struct {
+	onOnResponse func(ctx context.Context, isValidated bool)
+}
+
+// type check
+var _ dnssecmw.Metrics = (*testMetrics)(nil)
+
+// OnResponse implements the [dnssecmw.Metrics] interface for *testMetrics.
+func (m *testMetrics) OnResponse(ctx context.Context, isValidated bool) {
+	m.onOnResponse(ctx, isValidated)
+}
+
+func TestMiddleware_Wrap(t *testing.T) {
+	t.Parallel()
+
+	var (
+		strictProf = &agd.Profile{RequireDNSSEC: true}
+		looseProf  = &agd.Profile{}
+	)
+
+	testCases := []struct {
+		prof          *agd.Profile
+		name          string
+		upsRcode      int
+		wantRcode     int
+		upsAD         bool
+		reqDO         bool
+		wantAD        bool
+		wantUpsAD     bool
+		wantValidated bool
+		wantChecked   bool
+	}{{
+		prof:          nil,
+		name:          "no_profile",
+		upsRcode:      dns.RcodeSuccess,
+		wantRcode:     dns.RcodeSuccess,
+		upsAD:         false,
+		reqDO:         false,
+		wantAD:        false,
+		wantUpsAD:     false,
+		wantValidated: false,
+		wantChecked:   false,
+	}, {
+		prof:          looseProf,
+		name:          "not_required",
+		upsRcode:      dns.RcodeSuccess,
+		wantRcode:     dns.RcodeSuccess,
+		upsAD:         false,
+		reqDO:         false,
+		wantAD:        false,
+		wantUpsAD:     false,
+		wantValidated: false,
+		wantChecked:   false,
+	}, {
+		prof:          strictProf,
+		name:          "validated",
+		upsRcode:      dns.RcodeSuccess,
+		wantRcode:     dns.RcodeSuccess,
+		upsAD:         true,
+		reqDO:         false,
+		wantAD:        false,
+		wantUpsAD:     true,
+		wantValidated: true,
+		wantChecked:   true,
+	}, {
+		prof:          strictProf,
+		name:          "validated_do",
+		upsRcode:      dns.RcodeSuccess,
+		wantRcode:     dns.RcodeSuccess,
+		upsAD:         true,
+		reqDO:         true,
+		wantAD:        true,
+		wantUpsAD:     true,
+		wantValidated: true,
+		wantChecked:   true,
+	}, {
+		prof:          strictProf,
+		name:          "unvalidated",
+		upsRcode:      dns.RcodeSuccess,
+		wantRcode:     dns.RcodeServerFailure,
+		upsAD:         false,
+		reqDO:         true,
+		wantAD:        false,
+		wantUpsAD:     true,
+		wantValidated: false,
+		wantChecked:   true,
+	}, {
+		prof:          strictProf,
+		name:          "unvalidated_nxdomain",
+		upsRcode:      dns.RcodeNameError,
+		wantRcode:     dns.RcodeServerFailure,
+		upsAD:         false,
+		reqDO:         false,
+		wantAD:        false,
+		wantUpsAD:     true,
+		wantValidated: false,
+		wantChecked:   true,
+	}, {
+		prof:          strictProf,
+		name:          "upstream_servfail",
+		upsRcode:      dns.RcodeServerFailure,
+		wantRcode:     dns.RcodeServerFailure,
+		upsAD:         false,
+		reqDO:         false,
+		wantAD:        false,
+		wantUpsAD:     true,
+		wantValidated: false,
+		wantChecked:   false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotChecked, gotValidated bool
+			mw := dnssecmw.New(&dnssecmw.Config{
+				Logger: slogutil.NewDiscardLogger(),
+				Metrics: &testMetrics{
+					onOnResponse: func(_ context.Context, isValidated bool) {
+						gotChecked, gotValidated = true, isValidated
+					},
+				},
+			})
+
+			var gotUpsAD, gotUpsCD bool
+			upstream := dnsserver.HandlerFunc(func(
+				ctx context.Context,
+				rw dnsserver.ResponseWriter,
+				req *dns.Msg,
+			) (err error) {
+				gotUpsAD, gotUpsCD = req.AuthenticatedData, req.CheckingDisabled
+
+				resp := dnsservertest.NewResp(tc.upsRcode, req, dnsservertest.SectionAnswer{
+					dnsservertest.NewA(testName, 10, testIP),
+				})
+				resp.AuthenticatedData = tc.upsAD
+
+				return rw.WriteMsg(ctx, req, resp)
+			})
+
+			ri := &agd.RequestInfo{
+				Messages: agdtest.NewConstructor(t),
+			}
+			if tc.prof != nil {
+				ri.DeviceResult = &agd.DeviceResultOK{
+					Device:  &agd.Device{},
+					Profile: tc.prof,
+				}
+			}
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = agd.ContextWithRequestInfo(ctx, ri)
+
+			req := dnsservertest.NewReq(testName, dns.TypeA, dns.ClassINET, dnsservertest.SectionExtra{
+				dnsservertest.NewOPT(tc.reqDO, dns.MaxMsgSize),
+			})
+			req.CheckingDisabled = true
+
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+
+			err := mw.Wrap(upstream).ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			assert.Equal(t, tc.wantRcode, resp.Rcode)
+			assert.Equal(t, tc.wantAD, resp.AuthenticatedData)
+			assert.Equal(t, tc.wantUpsAD, gotUpsAD)
+			assert.Equal(t, !tc.wantUpsAD, gotUpsCD)
+			assert.Equal(t, tc.wantChecked, gotChecked)
+			assert.Equal(t, tc.wantValidated, gotValidated)
+
+			// Make sure that the original request is not modified.
+			assert.False(t, req.AuthenticatedData)
+			assert.True(t, req.CheckingDisabled)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package dnssecmw
+
+import "context"
+
+// Metrics is an interface for monitoring the [dnssecmw.Middleware] state.
+type Metrics interface {
+	// OnResponse is called when an upstream response for a profile requiring
+	// DNSSEC has been checked.  isValidated is false if the response has been
+	// replaced with a SERVFAIL one.
+	OnResponse(ctx context.Context, isValidated bool)
+}
+
+// EmptyMetrics is an empty [Metrics] implementation that does nothing.
+type EmptyMetrics struct{}
+
+// type check
+var _ Metrics = EmptyMetrics{}
+
+// OnResponse implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) OnResponse(_ context.Context, _ bool) {}
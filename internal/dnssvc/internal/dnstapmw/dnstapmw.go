@@ -0,0 +1,205 @@
+// Package dnstapmw contains the middleware that writes a share of the queries
+// and responses in the dnstap format.
+//
+// The middleware must be placed after the one that adds the [agd.RequestInfo]
+// to the context, so that the privacy settings of the profiles are honored.
+package dnstapmw
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"net/netip"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnstap"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/netutil"
+	"github.com/miekg/dns"
+)
+
+// Config is the configuration structure for the dnstap middleware.
+type Config struct {
+	// Logger is used to log the operation of the middleware.  It must not be
+	// nil.
+	Logger *slog.Logger
+
+	// Writer is used to write the messages.  It must not be nil.
+	Writer dnstap.Interface
+
+	// Ratio is the share of queries that are written.  It must be in the
+	// (0, 1] range.
+	Ratio float64
+}
+
+// Middleware writes a share of the queries and responses in the dnstap
+// format.
+type Middleware struct {
+	logger *slog.Logger
+	writer dnstap.Interface
+	ratio  float64
+}
+
+// New returns a new dnstap middleware.  c must not be nil.
+func New(c *Config) (mw *Middleware) {
+	return &Middleware{
+		logger: c.Logger,
+		writer: c.Writer,
+		ratio:  c.Ratio,
+	}
+}
+
+// type check
+var _ dnsserver.Middleware = (*Middleware)(nil)
+
+// Wrap implements the [dnsserver.Middleware] interface for *Middleware.  The
+// queries of profiles with the query log disabled are never written, and the
+// client addresses of profiles with the IP log disabled are omitted.
+func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
+	f := func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (err error) {
+		logIP, ok := logSettings(ctx)
+		if !ok || rand.Float64() >= mw.ratio {
+			return next.ServeDNS(ctx, rw, req)
+		}
+
+		defer func() { err = errors.Annotate(err, "dnstapmw: %w") }()
+
+		m := mw.newMessage(ctx, rw, req, logIP)
+		mw.writer.Write(ctx, m)
+
+		tapRW := &responseWriter{
+			ResponseWriter: rw,
+			mw:             mw,
+			query:          m,
+		}
+
+		// Don't wrap the error, because there is already errors.Annotate here.
+		return next.ServeDNS(ctx, tapRW, req)
+	}
+
+	return dnsserver.HandlerFunc(f)
+}
+
+// logSettings returns the privacy settings of the profile of the request, if
+// any.  ok is false if the query must not be written at all.  logIP is false if
+// the client address must be omitted.
+func logSettings(ctx context.Context) (logIP, ok bool) {
+	ri, hasInfo := agd.RequestInfoFromContext(ctx)
+	if !hasInfo {
+		return true, true
+	}
+
+	prof, _ := ri.DeviceData()
+	if prof == nil {
+		return true, true
+	}
+
+	return prof.IPLogEnabled, prof.QueryLogEnabled
+}
+
+// newMessage returns a new client-query message for req.  If logIP is false,
+// the client address is omitted.
+func (mw *Middleware) newMessage(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	logIP bool,
+) (m *dnstap.Message) {
+	queryTime := time.Now()
+	if ri, ok := dnsserver.RequestInfoFromContext(ctx); ok {
+		queryTime = ri.StartTime
+	}
+
+	query, err := req.Pack()
+	if err != nil {
+		optslog.Debug1(ctx, mw.logger, "packing query", slogutil.KeyError, err)
+	}
+
+	var clientAddr netip.AddrPort
+	if logIP {
+		clientAddr = netutil.NetAddrToAddrPort(rw.RemoteAddr())
+	}
+
+	return &dnstap.Message{
+		QueryTime:  queryTime,
+		Query:      query,
+		ClientAddr: clientAddr,
+		ServerAddr: netutil.NetAddrToAddrPort(rw.LocalAddr()),
+		Protocol:   socketProtocol(ctx, rw),
+		Type:       dnstap.MessageTypeClientQuery,
+	}
+}
+
+// socketProtocol returns the dnstap socket protocol of the query.
+func socketProtocol(ctx context.Context, rw dnsserver.ResponseWriter) (p dnstap.SocketProtocol) {
+	si, ok := dnsserver.ServerInfoFromContext(ctx)
+	if !ok {
+		return 0
+	}
+
+	isTCP := dnsserver.NetworkFromAddr(rw.LocalAddr()) == dnsserver.NetworkTCP
+	switch si.Proto {
+	case dnsserver.ProtoDNS:
+		if isTCP {
+			return dnstap.SocketProtocolTCP
+		}
+
+		return dnstap.SocketProtocolUDP
+	case dnsserver.ProtoDNSCrypt:
+		if isTCP {
+			return dnstap.SocketProtocolDNSCryptTCP
+		}
+
+		return dnstap.SocketProtocolDNSCryptUDP
+	case dnsserver.ProtoDoT:
+		return dnstap.SocketProtocolDoT
+	case dnsserver.ProtoDoH:
+		return dnstap.SocketProtocolDoH
+	case dnsserver.ProtoDoQ:
+		return dnstap.SocketProtocolDoQ
+	default:
+		return 0
+	}
+}
+
+// responseWriter is a [dnsserver.ResponseWriter] that writes the responses in
+// the dnstap format after they have been written.
+type responseWriter struct {
+	dnsserver.ResponseWriter
+
+	mw    *Middleware
+	query *dnstap.Message
+}
+
+// type check
+var _ dnsserver.ResponseWriter = (*responseWriter)(nil)
+
+// WriteMsg implements the [dnsserver.ResponseWriter] interface for
+// *responseWriter.
+func (rw *responseWriter) WriteMsg(ctx context.Context, req, resp *dns.Msg) (err error) {
+	err = rw.ResponseWriter.WriteMsg(ctx, req, resp)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	packed, err := resp.Pack()
+	if err != nil {
+		optslog.Debug1(ctx, rw.mw.logger, "packing response", slogutil.KeyError, err)
+
+		return nil
+	}
+
+	m := *rw.query
+	m.Type = dnstap.MessageTypeClientResponse
+	m.ResponseTime = time.Now()
+	m.Response = packed
+
+	rw.mw.writer.Write(ctx, &m)
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package dnstapmw_test
+
+import (
+	"context"
+	"net/netip"
+	"sync"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnstapmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnstap"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testWriter is a [dnstap.Interface] implementation for tests.
+type testWriter struct {
+	mu       *sync.Mutex
+	messages []dnstap.Message
+}
+
+// type check
+var _ dnstap.Interface = (*testWriter)(nil)
+
+// Write implements the [dnstap.Interface] interface for *testWriter.
+func (w *testWriter) Write(_ context.Context, m *dnstap.Message) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.messages = append(w.messages, *m)
+}
+
+func TestMiddleware_Wrap(t *testing.T) {
+	t.Parallel()
+
+	ip := netip.MustParseAddr("192.0.2.1")
+	h := dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		ans := dnsservertest.SectionAnswer{dnsservertest.NewA(req.Question[0].Name, 100, ip)}
+
+		return rw.WriteMsg(ctx, req, dnsservertest.NewResp(dns.RcodeSuccess, req, ans))
+	})
+
+	allTypes := []dnstap.MessageType{
+		dnstap.MessageTypeClientQuery,
+		dnstap.MessageTypeClientResponse,
+	}
+
+	testCases := []struct {
+		prof           *agd.Profile
+		name           string
+		wantTypes      []dnstap.MessageType
+		wantClientAddr netip.AddrPort
+		ratio          float64
+	}{{
+		prof:           nil,
+		name:           "all",
+		wantTypes:      allTypes,
+		wantClientAddr: dnssvctest.ClientAddrPort,
+		ratio:          1,
+	}, {
+		prof:           nil,
+		name:           "none",
+		wantTypes:      nil,
+		wantClientAddr: dnssvctest.ClientAddrPort,
+		ratio:          0,
+	}, {
+		prof: &agd.Profile{
+			IPLogEnabled:    true,
+			QueryLogEnabled: true,
+		},
+		name:           "profile_all",
+		wantTypes:      allTypes,
+		wantClientAddr: dnssvctest.ClientAddrPort,
+		ratio:          1,
+	}, {
+		prof: &agd.Profile{
+			IPLogEnabled:    false,
+			QueryLogEnabled: true,
+		},
+		name:           "profile_no_ip_log",
+		wantTypes:      allTypes,
+		wantClientAddr: netip.AddrPort{},
+		ratio:          1,
+	}, {
+		prof: &agd.Profile{
+			IPLogEnabled:    true,
+			QueryLogEnabled: false,
+		},
+		name:           "profile_no_query_log",
+		wantTypes:      nil,
+		wantClientAddr: dnssvctest.ClientAddrPort,
+		ratio:          1,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := &testWriter{
+				mu: &sync.Mutex{},
+			}
+
+			mw := dnstapmw.New(&dnstapmw.Config{
+				Logger: slogutil.NewDiscardLogger(),
+				Writer: w,
+				Ratio:  tc.ratio,
+			})
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = dnsserver.ContextWithServerInfo(ctx, &dnsserver.ServerInfo{
+				Proto: dnsserver.ProtoDNS,
+			})
+
+			if tc.prof != nil {
+				ctx = agd.ContextWithRequestInfo(ctx, &agd.RequestInfo{
+					DeviceResult: &agd.DeviceResultOK{
+						Device:  &agd.Device{},
+						Profile: tc.prof,
+					},
+				})
+			}
+
+			req := dnsservertest.NewReq(dnssvctest.DomainFQDN, dns.TypeA, dns.ClassINET)
+			rw := dnsserver.NewNonWriterResponseWriter(
+				dnssvctest.ServerTCPAddr,
+				dnssvctest.ClientTCPAddr,
+			)
+
+			err := mw.Wrap(h).ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+			require.NotNil(t, rw.Msg())
+
+			var gotTypes []dnstap.MessageType
+			for _, m := range w.messages {
+				gotTypes = append(gotTypes, m.Type)
+
+				assert.Equal(t, tc.wantClientAddr, m.ClientAddr)
+				assert.Equal(t, dnstap.SocketProtocolTCP, m.Protocol)
+				assert.NotEmpty(t, m.Query)
+			}
+
+			assert.Equal(t, tc.wantTypes, gotTypes)
+
+			if len(w.messages) == 2 {
+				assert.NotEmpty(t, w.messages[1].Response)
+			}
+		})
+	}
+}
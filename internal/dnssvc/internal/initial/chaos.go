@@ -0,0 +1,159 @@
+package initial
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// Non-FQDN versions of the CHAOS-class names of the server identification
+// queries.
+//
+// See https://datatracker.ietf.org/doc/html/rfc4892.
+const (
+	ChaosHostnameBind  = "hostname.bind"
+	ChaosIDServer      = "id.server"
+	ChaosVersionBind   = "version.bind"
+	ChaosVersionServer = "version.server"
+)
+
+// ChaosConfig is the configuration of the responses to the CHAOS-class TXT
+// queries used by the clients and the support team for diagnostics.
+type ChaosConfig struct {
+	// NodeName is the name of this server node returned for the
+	// [ChaosHostnameBind] and [ChaosIDServer] queries.  It must not be empty.
+	NodeName string
+
+	// Version is the version of AdGuard DNS returned for the [ChaosVersionBind]
+	// and [ChaosVersionServer] queries.  If it is empty, these queries aren't
+	// answered.
+	Version string
+
+	// DebugName is the lowercased, non-FQDN secret domain name of the extended
+	// debug query, which returns the node name, the server group, and the
+	// outcome of the device detection for the querying client.  If it is
+	// empty, the extended debug query is disabled.
+	DebugName string
+}
+
+// chaosHandler returns a handler that can handle a CHAOS-class TXT query based
+// on the request info, as well as the handler's name for debugging.  The
+// CHAOS-class queries to other names are handled by the filtering middleware
+// as debug queries.
+func (mw *Middleware) chaosHandler(ri *agd.RequestInfo) (f reqInfoHandlerFunc, name string) {
+	c := mw.chaos
+	if c == nil || ri.QType != dns.TypeTXT {
+		return nil, ""
+	}
+
+	switch host := ri.Host; {
+	case host == ChaosHostnameBind, host == ChaosIDServer:
+		return mw.handleChaosID, "chaos_id"
+	case c.Version != "" && (host == ChaosVersionBind || host == ChaosVersionServer):
+		return mw.handleChaosVersion, "chaos_version"
+	case c.DebugName != "" && host == c.DebugName:
+		return mw.handleChaosDebug, "chaos_debug"
+	default:
+		return nil, ""
+	}
+}
+
+// handleChaosID responds to the server identification queries with the name of
+// the node.
+func (mw *Middleware) handleChaosID(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+) (err error) {
+	return mw.writeChaosTXT(ctx, rw, req, ri, mw.chaos.NodeName)
+}
+
+// handleChaosVersion responds to the server version queries with the version
+// of AdGuard DNS.
+func (mw *Middleware) handleChaosVersion(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+) (err error) {
+	return mw.writeChaosTXT(ctx, rw, req, ri, mw.chaos.Version)
+}
+
+// handleChaosDebug responds to the extended debug query with the information
+// about the node, the server, and the device of the client.
+func (mw *Middleware) handleChaosDebug(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+) (err error) {
+	strs := []string{
+		"node=" + mw.chaos.NodeName,
+		"server-group=" + string(ri.ServerGroup.Name),
+		"server=" + string(ri.Server),
+		"proto=" + ri.Proto.String(),
+		"device-result=" + deviceResultString(ri.DeviceResult),
+		"client-key=" + ri.ClientKey.String(),
+	}
+
+	if prof, dev := ri.DeviceData(); prof != nil {
+		strs = append(strs, "profile-id="+string(prof.ID), "device-id="+string(dev.ID))
+	}
+
+	return mw.writeChaosTXT(ctx, rw, req, ri, strs...)
+}
+
+// deviceResultString returns a short description of the outcome of the device
+// detection.
+func deviceResultString(r agd.DeviceResult) (s string) {
+	switch r.(type) {
+	case nil:
+		return "none"
+	case *agd.DeviceResultOK:
+		return "ok"
+	case *agd.DeviceResultAuthenticationFailure:
+		return "auth_failure"
+	case *agd.DeviceResultUnknownDedicated:
+		return "unknown_dedicated"
+	case *agd.DeviceResultError:
+		return "error"
+	default:
+		// Consider unhandled sum type members as unrecoverable programmer
+		// errors.
+		panic(&agd.ArgumentError{
+			Name:    "r",
+			Message: fmt.Sprintf("unexpected type %T", r),
+		})
+	}
+}
+
+// writeChaosTXT writes a CHAOS-class TXT response with strs to rw.  Each
+// element of strs must not be longer than [dnsmsg.MaxTXTStringLen].
+func (mw *Middleware) writeChaosTXT(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+	strs ...string,
+) (err error) {
+	defer func() { err = errors.Annotate(err, "writing chaos resp for %q: %w", ri.Host) }()
+
+	resp, err := ri.Messages.NewRespTXT(req, strs...)
+	if err != nil {
+		return fmt.Errorf("creating resp: %w", err)
+	}
+
+	resp.Authoritative = true
+	for _, rr := range resp.Answer {
+		hdr := rr.Header()
+		hdr.Class = dns.ClassCHAOS
+		hdr.Ttl = 0
+	}
+
+	return rw.WriteMsg(ctx, req, resp)
+}
@@ -0,0 +1,181 @@
+package initial_test
+
+import (
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/initial"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_Wrap_chaos(t *testing.T) {
+	t.Parallel()
+
+	const (
+		testNodeName  = "dns-node-1"
+		testVersion   = "v1.2.3"
+		testDebugName = "debug-secret.example.com"
+	)
+
+	chaosConf := &initial.ChaosConfig{
+		NodeName:  testNodeName,
+		Version:   testVersion,
+		DebugName: testDebugName,
+	}
+
+	testClientKey := agd.NewClientKeyHasher([]byte("salt")).ForIP(dnssvctest.ClientAddr)
+
+	devRes := &agd.DeviceResultOK{
+		Device:  &agd.Device{ID: dnssvctest.DeviceID},
+		Profile: &agd.Profile{ID: dnssvctest.ProfileID},
+	}
+
+	testCases := []struct {
+		conf    *initial.ChaosConfig
+		devRes  agd.DeviceResult
+		name    string
+		host    string
+		wantTXT []string
+		qtype   dnsmsg.RRType
+	}{{
+		conf:    chaosConf,
+		devRes:  nil,
+		name:    "id_server",
+		host:    initial.ChaosIDServer,
+		wantTXT: []string{testNodeName},
+		qtype:   dns.TypeTXT,
+	}, {
+		conf:    chaosConf,
+		devRes:  nil,
+		name:    "hostname_bind",
+		host:    initial.ChaosHostnameBind,
+		wantTXT: []string{testNodeName},
+		qtype:   dns.TypeTXT,
+	}, {
+		conf:    chaosConf,
+		devRes:  nil,
+		name:    "version_bind",
+		host:    initial.ChaosVersionBind,
+		wantTXT: []string{testVersion},
+		qtype:   dns.TypeTXT,
+	}, {
+		conf:   chaosConf,
+		devRes: devRes,
+		name:   "debug",
+		host:   testDebugName,
+		wantTXT: []string{
+			"node=" + testNodeName,
+			"server-group=" + string(dnssvctest.ServerGroupName),
+			"server=" + string(dnssvctest.ServerName),
+			"proto=dns",
+			"device-result=ok",
+			"client-key=" + testClientKey.String(),
+			"profile-id=" + dnssvctest.ProfileIDStr,
+			"device-id=" + dnssvctest.DeviceIDStr,
+		},
+		qtype: dns.TypeTXT,
+	}, {
+		conf:   chaosConf,
+		devRes: &agd.DeviceResultAuthenticationFailure{},
+		name:   "debug_auth_failure",
+		host:   testDebugName,
+		wantTXT: []string{
+			"node=" + testNodeName,
+			"server-group=" + string(dnssvctest.ServerGroupName),
+			"server=" + string(dnssvctest.ServerName),
+			"proto=dns",
+			"device-result=auth_failure",
+			"client-key=" + testClientKey.String(),
+		},
+		qtype: dns.TypeTXT,
+	}, {
+		conf:    chaosConf,
+		devRes:  nil,
+		name:    "other_name",
+		host:    dnssvctest.DomainAllowed,
+		wantTXT: nil,
+		qtype:   dns.TypeTXT,
+	}, {
+		conf:    chaosConf,
+		devRes:  nil,
+		name:    "other_qtype",
+		host:    initial.ChaosIDServer,
+		wantTXT: nil,
+		qtype:   dns.TypeA,
+	}, {
+		conf:    nil,
+		devRes:  nil,
+		name:    "disabled",
+		host:    initial.ChaosIDServer,
+		wantTXT: nil,
+		qtype:   dns.TypeTXT,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mw := initial.New(&initial.Config{
+				Logger:      slogutil.NewDiscardLogger(),
+				WhiteLabel:  whitelabel.Empty{},
+				QUICAdvisor: dnsserver.EmptyQUICAdvisor{},
+				Chaos:       tc.conf,
+			})
+
+			h := mw.Wrap(newSpecDomHandler(tc.wantTXT == nil))
+
+			ri := &agd.RequestInfo{
+				DeviceResult: tc.devRes,
+				ClientKey:    testClientKey,
+				Messages:     agdtest.NewConstructor(t),
+				ServerGroup: &agd.ServerGroup{
+					Name: dnssvctest.ServerGroupName,
+				},
+				Server: dnssvctest.ServerName,
+				Host:   tc.host,
+				QClass: dns.ClassCHAOS,
+				QType:  tc.qtype,
+				Proto:  agd.ProtoDNS,
+			}
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = agd.ContextWithRequestInfo(ctx, ri)
+
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+			req := &dns.Msg{
+				Question: []dns.Question{{
+					Name:   dns.Fqdn(tc.host),
+					Qtype:  tc.qtype,
+					Qclass: dns.ClassCHAOS,
+				}},
+			}
+
+			err := h.ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			if tc.wantTXT == nil {
+				assert.Empty(t, resp.Answer)
+
+				return
+			}
+
+			require.Len(t, resp.Answer, 1)
+
+			txt := testutil.RequireTypeAssert[*dns.TXT](t, resp.Answer[0])
+			assert.Equal(t, uint16(dns.ClassCHAOS), txt.Hdr.Class)
+			assert.Equal(t, tc.wantTXT, txt.Txt)
+		})
+	}
+}
@@ -1,7 +1,9 @@
-// Package initial contains the initial, outermost (except for ratelimit/access)
-// middleware of the AdGuard DNS server.  It handles Firefox canary hosts
-// requests, sets and resets the AD bit for further processing, as well as
-// handles some special domains.
+// Package initial contains the initial, outermost (except for ratelimit/access
+// and signing) middleware of the AdGuard DNS server.  It selects the view of
+// the server group for the client, handles Firefox canary hosts requests, sets
+// and resets the AD bit for further processing, as well as handles some special
+// domains, the CHAOS-class diagnostic queries, and the validation queries for
+// the pending custom domains.  It also refuses zone transfer requests.
 //
 // TODO(a.garipov):  Consider renaming the package into specialdomainmw or
 // merging with another middleware.
@@ -16,29 +18,58 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal"
 	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/miekg/dns"
 )
 
 // Middleware is the initial middleware of the AdGuard DNS server.  This
 // middleware must be the most outer middleware apart from the ratelimit/access
-// middleware.
+// and the signing middlewares.
 type Middleware struct {
-	logger *slog.Logger
+	logger      *slog.Logger
+	whiteLabel  whitelabel.Interface
+	quicAdvisor dnsserver.QUICAdvisor
+	chaos       *ChaosConfig
+	leak        *LeakConfig
 }
 
-// Config is the configuration structure for the initial middleware.  All fields
-// must be non-nil.
+// Config is the configuration structure for the initial middleware.
 type Config struct {
-	// Logger is used to log the operation of the middleware.
+	// Logger is used to log the operation of the middleware.  It must not be
+	// nil.
 	Logger *slog.Logger
+
+	// WhiteLabel is used to get the additional DDR targets and the validation
+	// tokens of the custom domains of the server groups received from the
+	// backend.  It must not be nil.
+	WhiteLabel whitelabel.Interface
+
+	// QUICAdvisor is used to find the clients from the networks where QUIC is
+	// blocked, so that they aren't directed to DoQ and HTTP/3 in the DDR
+	// responses.  It must not be nil.
+	QUICAdvisor dnsserver.QUICAdvisor
+
+	// Chaos is the configuration of the responses to the CHAOS-class
+	// diagnostic queries.  If it is nil, these queries aren't answered by this
+	// middleware.
+	Chaos *ChaosConfig
+
+	// Leak is the configuration of the handling of the queries for the
+	// special-use domain names, such as localhost., that must not leak to the
+	// global DNS.  If it is nil, these queries are processed as usual.
+	Leak *LeakConfig
 }
 
 // New returns a new initial middleware.  c must not be nil, and all its fields
 // must be valid.
 func New(c *Config) (mw *Middleware) {
 	return &Middleware{
-		logger: c.Logger,
+		logger:      c.Logger,
+		whiteLabel:  c.WhiteLabel,
+		quicAdvisor: c.QUICAdvisor,
+		chaos:       c.Chaos,
+		leak:        c.Leak,
 	}
 }
 
@@ -59,8 +90,9 @@ func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
 		req.AuthenticatedData = true
 
 		ri := agd.MustRequestInfoFromContext(ctx)
+		ctx, ri = mw.withView(ctx, ri)
 
-		if specHdlr, name := mw.reqInfoSpecialHandler(ri); specHdlr != nil {
+		if specHdlr, name := mw.reqInfoSpecialHandler(ctx, ri); specHdlr != nil {
 			optslog.Debug1(ctx, mw.logger, "using req-info special handler", "name", name)
 
 			// Don't wrap the error, because it's informative enough as is, and
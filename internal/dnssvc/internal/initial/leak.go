@@ -0,0 +1,180 @@
+package initial
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/netutil"
+	"github.com/miekg/dns"
+)
+
+// Non-FQDN versions of the special-use domain names, the queries for which
+// must not leak to the global DNS.
+const (
+	// LocalhostDomain is the special-use domain name for the loopback
+	// addresses.
+	//
+	// See https://datatracker.ietf.org/doc/html/rfc6761#section-6.3.
+	LocalhostDomain = "localhost"
+
+	// LocalDomain is the special-use domain name for the multicast DNS.
+	//
+	// See https://datatracker.ietf.org/doc/html/rfc6762#section-3.
+	LocalDomain = "local"
+
+	// OnionDomain is the special-use domain name for the Tor hidden services.
+	//
+	// See https://datatracker.ietf.org/doc/html/rfc7686#section-2.
+	OnionDomain = "onion"
+)
+
+// LeakAction is the action to take on a query for a special-use domain name.
+type LeakAction uint8
+
+// LeakAction values.
+const (
+	// LeakActionForward means that the query is processed as usual and may be
+	// forwarded to the upstreams.
+	LeakActionForward LeakAction = iota
+
+	// LeakActionNXDomain means that the query is answered with NXDOMAIN.
+	LeakActionNXDomain
+
+	// LeakActionRefused means that the query is answered with REFUSED.
+	LeakActionRefused
+)
+
+// LeakConfig is the configuration of the handling of the queries for the
+// special-use domain names that must not leak to the global DNS.
+type LeakConfig struct {
+	// Local is the action for the queries for [LocalDomain] and its
+	// subdomains.
+	Local LeakAction
+
+	// Onion is the action for the queries for [OnionDomain] and its
+	// subdomains.
+	Onion LeakAction
+
+	// Localhost, if true, makes the middleware answer the queries for
+	// [LocalhostDomain] and its subdomains with the loopback addresses.
+	Localhost bool
+}
+
+// Loopback addresses for the responses to the queries for [LocalhostDomain].
+var (
+	localhostIPv4 = netip.AddrFrom4([4]byte{127, 0, 0, 1})
+	localhostIPv6 = netip.IPv6Loopback()
+)
+
+// leakHandler returns a handler that can handle a query for a special-use
+// domain name that must not leak to the global DNS, as well as the handler's
+// name for debugging.
+func (mw *Middleware) leakHandler(ri *agd.RequestInfo) (f reqInfoHandlerFunc, name string) {
+	c := mw.leak
+	if c == nil {
+		return nil, ""
+	}
+
+	host := ri.Host
+	switch {
+	case c.Localhost && isDomainOrSubdomain(host, LocalhostDomain):
+		return mw.handleLocalhost, "leak_localhost"
+	case c.Local != LeakActionForward && isDomainOrSubdomain(host, LocalDomain):
+		return mw.handleLeakLocal, "leak_local"
+	case c.Onion != LeakActionForward && isDomainOrSubdomain(host, OnionDomain):
+		return mw.handleLeakOnion, "leak_onion"
+	default:
+		return nil, ""
+	}
+}
+
+// isDomainOrSubdomain returns true if host is either domain or its subdomain.
+func isDomainOrSubdomain(host, domain string) (ok bool) {
+	return host == domain || netutil.IsSubdomain(host, domain)
+}
+
+// incrementLeakRequests increments the number of the leak requests of the kind
+// for the server group of ri.
+func incrementLeakRequests(ri *agd.RequestInfo, kind string) {
+	metrics.DNSSvcLeakRequestsTotal.WithLabelValues(kind, string(ri.ServerGroup.Name)).Inc()
+}
+
+// handleLocalhost responds to the queries for [LocalhostDomain] with the
+// loopback addresses or with a NODATA response for the other query types.
+func (mw *Middleware) handleLocalhost(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+) (err error) {
+	defer func() { err = errors.Annotate(err, "writing localhost resp for %q: %w", ri.Host) }()
+
+	incrementLeakRequests(ri, "localhost")
+
+	var resp *dns.Msg
+	switch ri.QType {
+	case dns.TypeA:
+		resp, err = ri.Messages.NewRespIP(req, localhostIPv4)
+	case dns.TypeAAAA:
+		resp, err = ri.Messages.NewRespIP(req, localhostIPv6)
+	default:
+		resp = ri.Messages.NewRespRCode(req, dns.RcodeSuccess)
+	}
+
+	if err != nil {
+		return fmt.Errorf("creating resp: %w", err)
+	}
+
+	return rw.WriteMsg(ctx, req, resp)
+}
+
+// handleLeakLocal responds to the queries for [LocalDomain] in accordance
+// with the configured action.
+func (mw *Middleware) handleLeakLocal(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+) (err error) {
+	incrementLeakRequests(ri, "local")
+
+	return writeLeakResp(ctx, rw, req, ri, mw.leak.Local)
+}
+
+// handleLeakOnion responds to the queries for [OnionDomain] in accordance
+// with the configured action.
+func (mw *Middleware) handleLeakOnion(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+) (err error) {
+	incrementLeakRequests(ri, "onion")
+
+	return writeLeakResp(ctx, rw, req, ri, mw.leak.Onion)
+}
+
+// writeLeakResp writes the response to a query for a special-use domain name
+// in accordance with act, which must not be [LeakActionForward].
+func writeLeakResp(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+	act LeakAction,
+) (err error) {
+	var rcode dnsmsg.RCode = dns.RcodeRefused
+	if act == LeakActionNXDomain {
+		rcode = dns.RcodeNameError
+	}
+
+	err = rw.WriteMsg(ctx, req, ri.Messages.NewRespRCode(req, rcode))
+
+	return errors.Annotate(err, "writing leak resp for %q: %w", ri.Host)
+}
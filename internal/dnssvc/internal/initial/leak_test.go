@@ -0,0 +1,182 @@
+package initial_test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/initial"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_Wrap_leak(t *testing.T) {
+	t.Parallel()
+
+	leakConf := &initial.LeakConfig{
+		Local:     initial.LeakActionRefused,
+		Onion:     initial.LeakActionNXDomain,
+		Localhost: true,
+	}
+
+	testCases := []struct {
+		conf      *initial.LeakConfig
+		name      string
+		host      string
+		wantIP    net.IP
+		wantReach bool
+		qtype     dnsmsg.RRType
+		wantRCode dnsmsg.RCode
+	}{{
+		conf:      leakConf,
+		name:      "localhost_a",
+		host:      initial.LocalhostDomain,
+		wantIP:    net.IP{127, 0, 0, 1},
+		wantReach: false,
+		qtype:     dns.TypeA,
+		wantRCode: dns.RcodeSuccess,
+	}, {
+		conf:      leakConf,
+		name:      "localhost_aaaa",
+		host:      initial.LocalhostDomain,
+		wantIP:    net.IPv6loopback,
+		wantReach: false,
+		qtype:     dns.TypeAAAA,
+		wantRCode: dns.RcodeSuccess,
+	}, {
+		conf:      leakConf,
+		name:      "localhost_subdomain",
+		host:      "app." + initial.LocalhostDomain,
+		wantIP:    net.IP{127, 0, 0, 1},
+		wantReach: false,
+		qtype:     dns.TypeA,
+		wantRCode: dns.RcodeSuccess,
+	}, {
+		conf:      leakConf,
+		name:      "localhost_nodata",
+		host:      initial.LocalhostDomain,
+		wantIP:    nil,
+		wantReach: false,
+		qtype:     dns.TypeTXT,
+		wantRCode: dns.RcodeSuccess,
+	}, {
+		conf:      leakConf,
+		name:      "local",
+		host:      "printer." + initial.LocalDomain,
+		wantIP:    nil,
+		wantReach: false,
+		qtype:     dns.TypeA,
+		wantRCode: dns.RcodeRefused,
+	}, {
+		conf:      leakConf,
+		name:      "onion",
+		host:      "hidden." + initial.OnionDomain,
+		wantIP:    nil,
+		wantReach: false,
+		qtype:     dns.TypeA,
+		wantRCode: dns.RcodeNameError,
+	}, {
+		conf: &initial.LeakConfig{
+			Local:     initial.LeakActionForward,
+			Onion:     initial.LeakActionForward,
+			Localhost: false,
+		},
+		name:      "forward",
+		host:      "printer." + initial.LocalDomain,
+		wantIP:    nil,
+		wantReach: true,
+		qtype:     dns.TypeA,
+		wantRCode: dns.RcodeSuccess,
+	}, {
+		conf:      leakConf,
+		name:      "not_special",
+		host:      "notlocal",
+		wantIP:    nil,
+		wantReach: true,
+		qtype:     dns.TypeA,
+		wantRCode: dns.RcodeSuccess,
+	}, {
+		conf:      nil,
+		name:      "disabled",
+		host:      initial.LocalhostDomain,
+		wantIP:    nil,
+		wantReach: true,
+		qtype:     dns.TypeA,
+		wantRCode: dns.RcodeSuccess,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mw := initial.New(&initial.Config{
+				Logger:      slogutil.NewDiscardLogger(),
+				WhiteLabel:  whitelabel.Empty{},
+				QUICAdvisor: dnsserver.EmptyQUICAdvisor{},
+				Leak:        tc.conf,
+			})
+
+			h := mw.Wrap(newSpecDomHandler(tc.wantReach))
+
+			ri := &agd.RequestInfo{
+				Messages: agdtest.NewConstructor(t),
+				ServerGroup: &agd.ServerGroup{
+					Name: dnssvctest.ServerGroupName,
+				},
+				Server: dnssvctest.ServerName,
+				Host:   tc.host,
+				QClass: dns.ClassINET,
+				QType:  tc.qtype,
+				Proto:  agd.ProtoDNS,
+			}
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = agd.ContextWithRequestInfo(ctx, ri)
+
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+			req := &dns.Msg{
+				Question: []dns.Question{{
+					Name:   dns.Fqdn(tc.host),
+					Qtype:  tc.qtype,
+					Qclass: dns.ClassINET,
+				}},
+			}
+
+			err := h.ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			assert.Equal(t, tc.wantRCode, dnsmsg.RCode(resp.Rcode))
+
+			if tc.wantIP == nil {
+				assert.Empty(t, resp.Answer)
+
+				return
+			}
+
+			require.Len(t, resp.Answer, 1)
+
+			var gotIP net.IP
+			switch rr := resp.Answer[0].(type) {
+			case *dns.A:
+				gotIP = rr.A
+			case *dns.AAAA:
+				gotIP = rr.AAAA
+			default:
+				t.Fatalf("unexpected answer type %T", rr)
+			}
+
+			assert.True(t, tc.wantIP.Equal(gotIP))
+		})
+	}
+}
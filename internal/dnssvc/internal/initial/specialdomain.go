@@ -1,15 +1,19 @@
 package initial
 
 import (
+	"cmp"
 	"context"
+	"slices"
 	"strings"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
 	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/miekg/dns"
+	"github.com/quic-go/quic-go/http3"
 )
 
 // TODO(a.garipov): Consider creating a new prefiltering package for this kind
@@ -56,9 +60,19 @@ const (
 // reqInfoSpecialHandler returns a handler that can handle a special-domain
 // query based on the request info, as well as the handler's name for debugging.
 func (mw *Middleware) reqInfoSpecialHandler(
+	ctx context.Context,
 	ri *agd.RequestInfo,
 ) (f reqInfoHandlerFunc, name string) {
-	if ri.QClass != dns.ClassINET {
+	if isZoneTransfer(ri.QType) {
+		return mw.handleZoneTransfer, "zone_transfer"
+	}
+
+	switch ri.QClass {
+	case dns.ClassINET:
+		// Go on.
+	case dns.ClassCHAOS:
+		return mw.chaosHandler(ri)
+	default:
 		return nil, ""
 	}
 
@@ -67,22 +81,25 @@ func (mw *Middleware) reqInfoSpecialHandler(
 	// any type for any domain name under resolver.arpa with NODATA.
 	//
 	// TODO(e.burkov):  Consider adding SOA records for these NODATA responses.
-	if mw.isDDRRequest(ri) {
+	if mw.isDDRRequest(ctx, ri) {
 		if _, ok := ri.DeviceResult.(*agd.DeviceResultAuthenticationFailure); ok {
 			return mw.handleDDRNoData, "ddr_doh"
 		}
 
-		_, dev := ri.DeviceData()
-		if dev != nil && dev.Auth.Enabled && dev.Auth.DoHAuthOnly {
-			return mw.handleDDRNoData, "ddr_doh"
-		}
-
 		return mw.handleDDR, "ddr"
 	} else if netutil.IsSubdomain(ri.Host, ResolverARPADomain) {
 		// A badly formed resolver.arpa subdomain query.
 		return mw.handleBadResolverARPA, "bad_resolver_arpa"
 	}
 
+	if f, name = mw.validationHandler(ctx, ri); f != nil {
+		return f, name
+	}
+
+	if f, name = mw.leakHandler(ri); f != nil {
+		return f, name
+	}
+
 	return mw.specialDomainHandler(ri)
 }
 
@@ -100,7 +117,7 @@ type reqInfoHandlerFunc func(
 // ARPA if the requested host is a subdomain of resolver.arpa SUDN.
 //
 // See https://datatracker.ietf.org/doc/html/draft-ietf-add-ddr-07.
-func (mw *Middleware) isDDRRequest(ri *agd.RequestInfo) (ok bool) {
+func (mw *Middleware) isDDRRequest(ctx context.Context, ri *agd.RequestInfo) (ok bool) {
 	if ri.QType != dns.TypeSVCB {
 		// Resolvers should respond to queries of any type other than SVCB for
 		// _dns.resolver.arpa with NODATA and queries of any type for any domain
@@ -116,18 +133,26 @@ func (mw *Middleware) isDDRRequest(ri *agd.RequestInfo) (ok bool) {
 		return true
 	}
 
-	return isDDRDomain(ri, host)
+	return mw.isDDRDomain(ctx, ri, host)
 }
 
-// isDDRDomain returns true if host is a DDR domain.
-func isDDRDomain(ri *agd.RequestInfo, host string) (ok bool) {
+// isDDRDomain returns true if host is a DDR domain.  Besides the targets of the
+// server group, it also considers the dynamic targets received from the
+// backend.
+func (mw *Middleware) isDDRDomain(
+	ctx context.Context,
+	ri *agd.RequestInfo,
+	host string,
+) (ok bool) {
 	firstLabel, resolverDomain, cut := strings.Cut(host, ".")
 	if !cut || firstLabel != DDRLabel {
 		return false
 	}
 
 	ddr := ri.ServerGroup.DDR
-	if ddr.PublicTargets.Has(resolverDomain) {
+	wl := mw.whiteLabel.ServerGroupSettings(ctx, ri.ServerGroup.Name)
+	if ddr.PublicTargets.Has(resolverDomain) ||
+		(wl != nil && wl.DDRPublicTargets.Has(resolverDomain)) {
 		// The client may simply send a DNS SVCB query using the known name of
 		// the resolver.  This query can be issued to the named Encrypted
 		// Resolver itself or to any other resolver.  Unlike the case of
@@ -142,12 +167,13 @@ func isDDRDomain(ri *agd.RequestInfo, host string) (ok bool) {
 	}
 
 	firstLabel, resolverDomain, cut = strings.Cut(resolverDomain, ".")
-	if cut && firstLabel == string(dev.ID) {
-		// A request for the device ID resolver domain.
-		return ddr.DeviceTargets.Has(resolverDomain)
+	if !cut || firstLabel != string(dev.ID) {
+		return false
 	}
 
-	return false
+	// A request for the device ID resolver domain.
+	return ddr.DeviceTargets.Has(resolverDomain) ||
+		(wl != nil && wl.DDRDeviceTargets.Has(resolverDomain))
 }
 
 // handleDDR responds to Discovery of Designated Resolvers (DDR) queries with a
@@ -163,7 +189,7 @@ func (mw *Middleware) handleDDR(
 	metrics.DNSSvcDDRRequestsTotal.Inc()
 
 	if ri.ServerGroup.DDR.Enabled {
-		return rw.WriteMsg(ctx, req, mw.newRespDDR(req, ri))
+		return rw.WriteMsg(ctx, req, mw.newRespDDR(ctx, req, ri))
 	}
 
 	return rw.WriteMsg(ctx, req, ri.Messages.NewRespRCode(req, dns.RcodeNameError))
@@ -190,35 +216,109 @@ func (mw *Middleware) handleDDRNoData(
 
 // newRespDDR returns a new Discovery of Designated Resolvers response copying
 // it from the prebuilt templates in srvGrp and modifying it in accordance with
-// the request data.  req must not be nil.
-func (mw *Middleware) newRespDDR(req *dns.Msg, ri *agd.RequestInfo) (resp *dns.Msg) {
+// the request data.  The devices that can only be authenticated over DoH only
+// receive the DoH records.  The clients from the networks where QUIC is
+// considered blocked receive neither DoQ records nor HTTP/3 in the DoH ones.
+// req must not be nil.
+func (mw *Middleware) newRespDDR(
+	ctx context.Context,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+) (resp *dns.Msg) {
 	resp = ri.Messages.NewResp(req)
 	name := req.Question[0].Name
 	ddr := ri.ServerGroup.DDR
+	prios := ddrPriorities(ddr, ri.Location)
+
+	targetPrefix, dohOnly := "", false
 
 	// TODO(a.garipov):  Optimize calls to ri.DeviceData.
-	if _, dev := ri.DeviceData(); dev != nil {
-		for _, rr := range ddr.DeviceRecordTemplates {
-			rr = dns.Copy(rr).(*dns.SVCB)
-			rr.Hdr.Name = name
-			rr.Target = string(dev.ID) + "." + rr.Target
+	_, dev := ri.DeviceData()
+	tmpls := ddrTemplates(ddr, ri.Location, dev != nil)
+	if dev != nil {
+		targetPrefix = string(dev.ID) + "."
+		dohOnly = dev.Auth.Enabled && dev.Auth.DoHAuthOnly
+	}
 
-			resp.Answer = append(resp.Answer, rr)
-		}
+	noQUIC := mw.quicAdvisor.IsQUICBlocked(ctx, ri.RemoteIP)
 
-		return resp
-	}
+	for _, tmpl := range tmpls {
+		if (dohOnly && tmpl.Proto != agd.ProtoDoH) || (noQUIC && tmpl.Proto == agd.ProtoDoQ) {
+			continue
+		}
 
-	for _, rr := range ddr.PublicRecordTemplates {
-		rr = dns.Copy(rr).(*dns.SVCB)
+		rr := dns.Copy(tmpl.Record).(*dns.SVCB)
 		rr.Hdr.Name = name
+		rr.Target = targetPrefix + rr.Target
+
+		if noQUIC && tmpl.Proto == agd.ProtoDoH {
+			removeH3(rr)
+		}
+
+		if prio, ok := prios[tmpl.Proto]; ok {
+			rr.Priority = prio
+		}
 
 		resp.Answer = append(resp.Answer, rr)
 	}
 
+	if len(prios) > 0 {
+		slices.SortStableFunc(resp.Answer, func(a, b dns.RR) (res int) {
+			return cmp.Compare(a.(*dns.SVCB).Priority, b.(*dns.SVCB).Priority)
+		})
+	}
+
 	return resp
 }
 
+// removeH3 removes HTTP/3 from the ALPN parameter of rr.  rr must not be nil.
+func removeH3(rr *dns.SVCB) {
+	for _, kv := range rr.Value {
+		alpn, ok := kv.(*dns.SVCBAlpn)
+		if ok {
+			alpn.Alpn = slices.DeleteFunc(alpn.Alpn, func(id string) (isH3 bool) {
+				return id == http3.NextProtoH3
+			})
+		}
+	}
+}
+
+// ddrPriorities returns the priorities of the designated resolvers for the
+// clients from the country of loc.  prios is nil if there are none or if loc is
+// nil.
+func ddrPriorities(ddr *agd.DDR, loc *geoip.Location) (prios agd.DDRPriorities) {
+	if loc == nil {
+		return nil
+	}
+
+	return ddr.CountryPriorities[loc.Country]
+}
+
+// ddrTemplates returns the record templates for the clients from the country
+// of loc, if there are any, or the default ones otherwise.  isDevice shows if
+// the templates for the recognized devices are required.
+func ddrTemplates(
+	ddr *agd.DDR,
+	loc *geoip.Location,
+	isDevice bool,
+) (tmpls []*agd.DDRRecordTemplate) {
+	var ctryTmpls *agd.DDRCountryRecordTemplates
+	if loc != nil {
+		ctryTmpls = ddr.CountryRecordTemplates[loc.Country]
+	}
+
+	switch {
+	case isDevice && ctryTmpls != nil && len(ctryTmpls.DeviceRecordTemplates) > 0:
+		return ctryTmpls.DeviceRecordTemplates
+	case isDevice:
+		return ddr.DeviceRecordTemplates
+	case ctryTmpls != nil && len(ctryTmpls.PublicRecordTemplates) > 0:
+		return ctryTmpls.PublicRecordTemplates
+	default:
+		return ddr.PublicRecordTemplates
+	}
+}
+
 // handleBadResolverARPA responds to badly formed resolver.arpa queries with a
 // NODATA response.
 func (mw *Middleware) handleBadResolverARPA(
@@ -2,6 +2,7 @@ package initial_test
 
 import (
 	"context"
+	"net/netip"
 	"testing"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/access"
@@ -10,8 +11,12 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/initial"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
+	"github.com/AdguardTeam/golibs/container"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/testutil"
@@ -122,7 +127,9 @@ func TestMiddleware_Wrap_specialDomain(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			mw := initial.New(&initial.Config{
-				Logger: slogutil.NewDiscardLogger(),
+				Logger:      slogutil.NewDiscardLogger(),
+				WhiteLabel:  whitelabel.Empty{},
+				QUICAdvisor: dnsserver.EmptyQUICAdvisor{},
 			})
 
 			h := mw.Wrap(newSpecDomHandler(tc.wantRCode == dns.RcodeSuccess))
@@ -150,6 +157,403 @@ func TestMiddleware_Wrap_specialDomain(t *testing.T) {
 	}
 }
 
+func TestMiddleware_Wrap_ddr(t *testing.T) {
+	t.Parallel()
+
+	const (
+		target        = "dns.example"
+		ctryTarget    = "tr.dns.example"
+		ctryDevTarget = "tr." + dnssvctest.DomainForDevices
+	)
+
+	msgs := agdtest.NewConstructor(t)
+	newTmpls := func(tgt string) (tmpls []*agd.DDRRecordTemplate) {
+		for i, proto := range []agd.Protocol{agd.ProtoDoH, agd.ProtoDoT, agd.ProtoDoQ} {
+			// #nosec G115 -- The index is small.
+			prio := uint16(i + 1)
+			tmpls = append(tmpls, &agd.DDRRecordTemplate{
+				Record: msgs.NewDDRTemplate(proto, tgt, "/dns-query", nil, nil, 853, prio),
+				Proto:  proto,
+			})
+		}
+
+		return tmpls
+	}
+
+	srvGrp := &agd.ServerGroup{
+		DDR: &agd.DDR{
+			CountryPriorities: map[geoip.Country]agd.DDRPriorities{
+				geoip.CountryDE: {
+					agd.ProtoDoQ: 1,
+					agd.ProtoDoH: 3,
+				},
+			},
+			CountryRecordTemplates: map[geoip.Country]*agd.DDRCountryRecordTemplates{
+				geoip.CountryTR: {
+					DeviceRecordTemplates: newTmpls(ctryDevTarget),
+					PublicRecordTemplates: newTmpls(ctryTarget),
+				},
+			},
+			DeviceTargets:         container.NewMapSet(dnssvctest.DomainForDevices, ctryDevTarget),
+			PublicTargets:         container.NewMapSet(target, ctryTarget),
+			DeviceRecordTemplates: newTmpls(dnssvctest.DomainForDevices),
+			PublicRecordTemplates: newTmpls(target),
+			Enabled:               true,
+		},
+	}
+
+	locTR := &geoip.Location{
+		Country: geoip.CountryTR,
+	}
+
+	devAuth := &agd.Device{
+		Auth: &agd.AuthSettings{
+			PasswordHash: agdpasswd.AllowAuthenticator{},
+		},
+		ID: dnssvctest.DeviceID,
+	}
+
+	devDoHOnly := &agd.Device{
+		Auth: &agd.AuthSettings{
+			PasswordHash: agdpasswd.AllowAuthenticator{},
+			Enabled:      true,
+			DoHAuthOnly:  true,
+		},
+		ID: dnssvctest.DeviceID,
+	}
+
+	testCases := []struct {
+		dev        *agd.Device
+		loc        *geoip.Location
+		name       string
+		wantTarget string
+		wantPrios  []uint16
+		wantProtos []agd.Protocol
+	}{{
+		dev:        nil,
+		loc:        nil,
+		name:       "public",
+		wantTarget: target + ".",
+		wantPrios:  []uint16{1, 2, 3},
+		wantProtos: []agd.Protocol{agd.ProtoDoH, agd.ProtoDoT, agd.ProtoDoQ},
+	}, {
+		dev: nil,
+		loc: &geoip.Location{
+			Country: geoip.CountryDE,
+		},
+		name:       "public_country",
+		wantTarget: target + ".",
+		wantPrios:  []uint16{1, 2, 3},
+		wantProtos: []agd.Protocol{agd.ProtoDoQ, agd.ProtoDoT, agd.ProtoDoH},
+	}, {
+		dev: nil,
+		loc: &geoip.Location{
+			Country: geoip.CountryFR,
+		},
+		name:       "public_other_country",
+		wantTarget: target + ".",
+		wantPrios:  []uint16{1, 2, 3},
+		wantProtos: []agd.Protocol{agd.ProtoDoH, agd.ProtoDoT, agd.ProtoDoQ},
+	}, {
+		dev:        nil,
+		loc:        locTR,
+		name:       "public_country_records",
+		wantTarget: ctryTarget + ".",
+		wantPrios:  []uint16{1, 2, 3},
+		wantProtos: []agd.Protocol{agd.ProtoDoH, agd.ProtoDoT, agd.ProtoDoQ},
+	}, {
+		dev:        devAuth,
+		loc:        locTR,
+		name:       "device_country_records",
+		wantTarget: dnssvctest.DeviceIDStr + "." + ctryDevTarget + ".",
+		wantPrios:  []uint16{1, 2, 3},
+		wantProtos: []agd.Protocol{agd.ProtoDoH, agd.ProtoDoT, agd.ProtoDoQ},
+	}, {
+		dev:        devAuth,
+		loc:        nil,
+		name:       "device",
+		wantTarget: dnssvctest.DeviceIDSrvName + ".",
+		wantPrios:  []uint16{1, 2, 3},
+		wantProtos: []agd.Protocol{agd.ProtoDoH, agd.ProtoDoT, agd.ProtoDoQ},
+	}, {
+		dev:        devDoHOnly,
+		loc:        nil,
+		name:       "device_doh_only",
+		wantTarget: dnssvctest.DeviceIDSrvName + ".",
+		wantPrios:  []uint16{1},
+		wantProtos: []agd.Protocol{agd.ProtoDoH},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mw := initial.New(&initial.Config{
+				Logger:      slogutil.NewDiscardLogger(),
+				WhiteLabel:  whitelabel.Empty{},
+				QUICAdvisor: dnsserver.EmptyQUICAdvisor{},
+			})
+
+			h := mw.Wrap(newSpecDomHandler(false))
+
+			ri := &agd.RequestInfo{
+				Location:    tc.loc,
+				Messages:    msgs,
+				ServerGroup: srvGrp,
+				Host:        initial.DDRDomain,
+				QClass:      dns.ClassINET,
+				QType:       dns.TypeSVCB,
+			}
+
+			if tc.dev != nil {
+				ri.DeviceResult = &agd.DeviceResultOK{
+					Device:  tc.dev,
+					Profile: &agd.Profile{},
+				}
+			}
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = agd.ContextWithRequestInfo(ctx, ri)
+
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+			req := dnsservertest.NewReq(dns.Fqdn(ri.Host), ri.QType, ri.QClass)
+
+			err := h.ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+			require.Len(t, resp.Answer, len(tc.wantProtos))
+
+			for i, rr := range resp.Answer {
+				svcb := testutil.RequireTypeAssert[*dns.SVCB](t, rr)
+				require.NotEmpty(t, svcb.Value)
+
+				alpn := testutil.RequireTypeAssert[*dns.SVCBAlpn](t, svcb.Value[0])
+
+				assert.Equal(t, tc.wantTarget, svcb.Target)
+				assert.Equal(t, tc.wantPrios[i], svcb.Priority)
+				assert.Equal(t, tc.wantProtos[i].ALPN(), alpn.Alpn)
+			}
+		})
+	}
+}
+
+func TestMiddleware_Wrap_ddrWhiteLabel(t *testing.T) {
+	t.Parallel()
+
+	const (
+		target       = "dns.example"
+		dynTarget    = "dns.partner.example"
+		dynDevTarget = "d.partner.example"
+	)
+
+	msgs := agdtest.NewConstructor(t)
+	tmpl := &agd.DDRRecordTemplate{
+		Record: msgs.NewDDRTemplate(agd.ProtoDoH, target, "/dns-query", nil, nil, 443, 1),
+		Proto:  agd.ProtoDoH,
+	}
+
+	srvGrp := &agd.ServerGroup{
+		DDR: &agd.DDR{
+			DeviceTargets:         container.NewMapSet(dnssvctest.DomainForDevices),
+			PublicTargets:         container.NewMapSet(target),
+			DeviceRecordTemplates: []*agd.DDRRecordTemplate{tmpl},
+			PublicRecordTemplates: []*agd.DDRRecordTemplate{tmpl},
+			Enabled:               true,
+		},
+		Name: dnssvctest.ServerGroupName,
+	}
+
+	wl := whitelabel.NewDynamic()
+	wl.Update(map[agd.ServerGroupName]*whitelabel.Settings{
+		dnssvctest.ServerGroupName: {
+			DDRDeviceTargets: container.NewMapSet(dynDevTarget),
+			DDRPublicTargets: container.NewMapSet(dynTarget),
+		},
+	})
+
+	mw := initial.New(&initial.Config{
+		Logger:      slogutil.NewDiscardLogger(),
+		WhiteLabel:  wl,
+		QUICAdvisor: dnsserver.EmptyQUICAdvisor{},
+	})
+
+	dev := &agd.Device{
+		Auth: &agd.AuthSettings{
+			PasswordHash: agdpasswd.AllowAuthenticator{},
+		},
+		ID: dnssvctest.DeviceID,
+	}
+
+	testCases := []struct {
+		dev     *agd.Device
+		name    string
+		host    string
+		wantDDR bool
+	}{{
+		dev:     nil,
+		name:    "static_public",
+		host:    initial.DDRLabel + "." + target,
+		wantDDR: true,
+	}, {
+		dev:     nil,
+		name:    "dynamic_public",
+		host:    initial.DDRLabel + "." + dynTarget,
+		wantDDR: true,
+	}, {
+		dev:     dev,
+		name:    "dynamic_device",
+		host:    initial.DDRLabel + "." + dnssvctest.DeviceIDStr + "." + dynDevTarget,
+		wantDDR: true,
+	}, {
+		dev:     nil,
+		name:    "dynamic_device_no_device",
+		host:    initial.DDRLabel + "." + dnssvctest.DeviceIDStr + "." + dynDevTarget,
+		wantDDR: false,
+	}, {
+		dev:     nil,
+		name:    "unknown",
+		host:    initial.DDRLabel + ".dns.other.example",
+		wantDDR: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := mw.Wrap(newSpecDomHandler(!tc.wantDDR))
+
+			ri := &agd.RequestInfo{
+				Messages:    msgs,
+				ServerGroup: srvGrp,
+				Host:        tc.host,
+				QClass:      dns.ClassINET,
+				QType:       dns.TypeSVCB,
+			}
+
+			if tc.dev != nil {
+				ri.DeviceResult = &agd.DeviceResultOK{
+					Device:  tc.dev,
+					Profile: &agd.Profile{},
+				}
+			}
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = agd.ContextWithRequestInfo(ctx, ri)
+
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+			req := dnsservertest.NewReq(dns.Fqdn(ri.Host), ri.QType, ri.QClass)
+
+			err := h.ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			if tc.wantDDR {
+				assert.Len(t, resp.Answer, 1)
+			} else {
+				assert.Empty(t, resp.Answer)
+			}
+		})
+	}
+}
+
+func TestMiddleware_Wrap_ddrQUICBlocked(t *testing.T) {
+	t.Parallel()
+
+	const target = "dns.example"
+
+	msgs := agdtest.NewConstructor(t)
+	tmpls := []*agd.DDRRecordTemplate{{
+		Record: msgs.NewDDRTemplate(agd.ProtoDoQ, target, "", nil, nil, 853, 1),
+		Proto:  agd.ProtoDoQ,
+	}, {
+		Record: msgs.NewDDRTemplate(agd.ProtoDoH, target, "/dns-query", nil, nil, 443, 2),
+		Proto:  agd.ProtoDoH,
+	}}
+
+	srvGrp := &agd.ServerGroup{
+		DDR: &agd.DDR{
+			DeviceTargets:         container.NewMapSet[string](),
+			PublicTargets:         container.NewMapSet(target),
+			DeviceRecordTemplates: tmpls,
+			PublicRecordTemplates: tmpls,
+			Enabled:               true,
+		},
+		Name: dnssvctest.ServerGroupName,
+	}
+
+	advisor := &agdtest.QUICAdvisor{
+		OnOnQUICHandshake: func(_ context.Context, _ netip.Addr, _ bool) {
+			panic("not implemented")
+		},
+		OnIsQUICBlocked: func(_ context.Context, addr netip.Addr) (blocked bool) {
+			return addr == dnssvctest.ClientAddr
+		},
+	}
+
+	mw := initial.New(&initial.Config{
+		Logger:      slogutil.NewDiscardLogger(),
+		WhiteLabel:  whitelabel.Empty{},
+		QUICAdvisor: advisor,
+	})
+
+	testCases := []struct {
+		name       string
+		remoteIP   netip.Addr
+		wantProtos []agd.Protocol
+		wantALPN   []string
+	}{{
+		name:       "not_blocked",
+		remoteIP:   dnssvctest.ClientAddr.Next(),
+		wantProtos: []agd.Protocol{agd.ProtoDoQ, agd.ProtoDoH},
+		wantALPN:   []string{"h3", "h2", "http/1.1"},
+	}, {
+		name:       "blocked",
+		remoteIP:   dnssvctest.ClientAddr,
+		wantProtos: []agd.Protocol{agd.ProtoDoH},
+		wantALPN:   []string{"h2", "http/1.1"},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := mw.Wrap(newSpecDomHandler(false))
+
+			ri := &agd.RequestInfo{
+				Messages:    msgs,
+				ServerGroup: srvGrp,
+				Host:        initial.DDRLabel + "." + target,
+				RemoteIP:    tc.remoteIP,
+				QClass:      dns.ClassINET,
+				QType:       dns.TypeSVCB,
+			}
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = agd.ContextWithRequestInfo(ctx, ri)
+
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+			req := dnsservertest.NewReq(dns.Fqdn(ri.Host), ri.QType, ri.QClass)
+
+			err := h.ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+			require.Len(t, resp.Answer, len(tc.wantProtos))
+
+			doh := testutil.RequireTypeAssert[*dns.SVCB](t, resp.Answer[len(resp.Answer)-1])
+			require.NotEmpty(t, doh.Value)
+
+			alpn := testutil.RequireTypeAssert[*dns.SVCBAlpn](t, doh.Value[0])
+			assert.Equal(t, tc.wantALPN, alpn.Alpn)
+		})
+	}
+}
+
 // newSpecDomReqInfo is a helper that creates an *agd.RequestInfo from the given
 // parameters.
 func newSpecDomReqInfo(
@@ -0,0 +1,87 @@
+package initial
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// ValidationLabel is the leading label of the domain names, the TXT records of
+// which are used to validate the pending custom domains in the same way as the
+// DNS-01 challenge of ACME does it.
+//
+// See https://datatracker.ietf.org/doc/html/rfc8555#section-8.4.
+const ValidationLabel = "_acme-challenge"
+
+// validationHandler returns a handler that can handle a validation query for a
+// pending custom domain of the server group of ri, as well as the handler's
+// name for debugging.
+func (mw *Middleware) validationHandler(
+	ctx context.Context,
+	ri *agd.RequestInfo,
+) (f reqInfoHandlerFunc, name string) {
+	firstLabel, domain, cut := strings.Cut(ri.Host, ".")
+	if !cut || firstLabel != ValidationLabel {
+		return nil, ""
+	}
+
+	wl := mw.whiteLabel.ServerGroupSettings(ctx, ri.ServerGroup.Name)
+	if wl == nil {
+		return nil, ""
+	}
+
+	tokens := wl.ValidationTokens[domain]
+	if len(tokens) == 0 {
+		return nil, ""
+	}
+
+	f = func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+		ri *agd.RequestInfo,
+	) (err error) {
+		return mw.handleValidation(ctx, rw, req, ri, tokens)
+	}
+
+	return f, "custom_domain_validation"
+}
+
+// handleValidation responds to the TXT queries for the validation domain name
+// with a TXT record for each of tokens or with a NODATA response for the other
+// query types.  tokens must not be empty, and each of them must not be longer
+// than [dnsmsg.MaxTXTStringLen].
+func (mw *Middleware) handleValidation(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+	tokens []string,
+) (err error) {
+	defer func() { err = errors.Annotate(err, "writing validation resp for %q: %w", ri.Host) }()
+
+	resp := ri.Messages.NewResp(req)
+	resp.Authoritative = true
+
+	if ri.QType == dns.TypeTXT {
+		for _, t := range tokens {
+			var rr *dns.TXT
+			rr, err = ri.Messages.NewAnswerTXT(req, []string{t})
+			if err != nil {
+				return fmt.Errorf("creating answer: %w", err)
+			}
+
+			// Don't let the resolvers cache the tokens, since they change
+			// with every validation attempt.
+			rr.Hdr.Ttl = 0
+			resp.Answer = append(resp.Answer, rr)
+		}
+	}
+
+	return rw.WriteMsg(ctx, req, resp)
+}
@@ -0,0 +1,130 @@
+package initial_test
+
+import (
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/initial"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_Wrap_validation(t *testing.T) {
+	t.Parallel()
+
+	const (
+		domain      = "dns.custom.example"
+		otherDomain = "dns.other.example"
+		token1      = "token1234"
+		token2      = "token5678"
+	)
+
+	wl := whitelabel.NewDynamic()
+	wl.Update(map[agd.ServerGroupName]*whitelabel.Settings{
+		dnssvctest.ServerGroupName: {
+			ValidationTokens: map[string][]string{
+				domain: {token1, token2},
+			},
+		},
+	})
+
+	mw := initial.New(&initial.Config{
+		Logger:      slogutil.NewDiscardLogger(),
+		WhiteLabel:  wl,
+		QUICAdvisor: dnsserver.EmptyQUICAdvisor{},
+	})
+
+	testCases := []struct {
+		name       string
+		host       string
+		wantTokens []string
+		wantReach  bool
+		qtype      dnsmsg.RRType
+	}{{
+		name:       "txt",
+		host:       initial.ValidationLabel + "." + domain,
+		wantTokens: []string{token1, token2},
+		wantReach:  false,
+		qtype:      dns.TypeTXT,
+	}, {
+		name:       "nodata",
+		host:       initial.ValidationLabel + "." + domain,
+		wantTokens: nil,
+		wantReach:  false,
+		qtype:      dns.TypeA,
+	}, {
+		name:       "unknown_domain",
+		host:       initial.ValidationLabel + "." + otherDomain,
+		wantTokens: nil,
+		wantReach:  true,
+		qtype:      dns.TypeTXT,
+	}, {
+		name:       "not_validation",
+		host:       "www." + domain,
+		wantTokens: nil,
+		wantReach:  true,
+		qtype:      dns.TypeTXT,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			h := mw.Wrap(newSpecDomHandler(tc.wantReach))
+
+			ri := &agd.RequestInfo{
+				Messages: agdtest.NewConstructor(t),
+				ServerGroup: &agd.ServerGroup{
+					Name: dnssvctest.ServerGroupName,
+				},
+				Server: dnssvctest.ServerName,
+				Host:   tc.host,
+				QClass: dns.ClassINET,
+				QType:  tc.qtype,
+				Proto:  agd.ProtoDNS,
+			}
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = agd.ContextWithRequestInfo(ctx, ri)
+
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+			req := &dns.Msg{
+				Question: []dns.Question{{
+					Name:   dns.Fqdn(tc.host),
+					Qtype:  tc.qtype,
+					Qclass: dns.ClassINET,
+				}},
+			}
+
+			err := h.ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+
+			if tc.wantReach {
+				return
+			}
+
+			assert.True(t, resp.Authoritative)
+			require.Len(t, resp.Answer, len(tc.wantTokens))
+
+			for i, rr := range resp.Answer {
+				txt := testutil.RequireTypeAssert[*dns.TXT](t, rr)
+
+				assert.Equal(t, []string{tc.wantTokens[i]}, txt.Txt)
+				assert.Zero(t, txt.Hdr.Ttl)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package initial
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+)
+
+// withView returns the context and the request information with the view of
+// the server group matching the client set, if there is one.  Otherwise, it
+// returns parent and ri.
+func (mw *Middleware) withView(
+	parent context.Context,
+	ri *agd.RequestInfo,
+) (ctx context.Context, viewRI *agd.RequestInfo) {
+	v := matchView(ri)
+	if v == nil {
+		return parent, ri
+	}
+
+	optslog.Debug1(parent, mw.logger, "using view", "name", v.Name)
+
+	// Clone the request information, since the request information from
+	// current context must only be accessed for reading, see [agd.RequestInfo].
+	// Shallow copy is enough, because only the pointer fields are replaced.
+	viewRI = &agd.RequestInfo{}
+	*viewRI = *ri
+	viewRI.View = v
+	viewRI.FilteringGroup = v.FilteringGroup
+
+	return agd.ContextWithRequestInfo(parent, viewRI), viewRI
+}
+
+// matchView returns the first view of the server group of ri matching the
+// client or nil if there is none.
+func matchView(ri *agd.RequestInfo) (v *agd.View) {
+	views := ri.ServerGroup.Views
+	if len(views) == 0 {
+		return nil
+	}
+
+	ctry := geoip.CountryNone
+	if ri.Location != nil {
+		ctry = ri.Location.Country
+	}
+
+	for _, v = range views {
+		if v.Match(ri.RemoteIP, ctry) {
+			return v
+		}
+	}
+
+	return nil
+}
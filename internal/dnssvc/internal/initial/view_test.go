@@ -0,0 +1,130 @@
+package initial_test
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/initial"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_Wrap_view(t *testing.T) {
+	t.Parallel()
+
+	defaultFltGrp := &agd.FilteringGroup{
+		ID: dnssvctest.FilteringGroupID,
+	}
+
+	subnetView := &agd.View{
+		FilteringGroup: &agd.FilteringGroup{
+			ID: "subnet_group",
+		},
+		Name:    "subnet_view",
+		Subnets: []netip.Prefix{netip.PrefixFrom(dnssvctest.ClientAddr, 32)},
+	}
+
+	ctryView := &agd.View{
+		FilteringGroup: &agd.FilteringGroup{
+			ID: "country_group",
+		},
+		Name:      "country_view",
+		Countries: []geoip.Country{geoip.CountryCY},
+	}
+
+	testCases := []struct {
+		loc        *geoip.Location
+		wantView   *agd.View
+		name       string
+		wantFltGrp agd.FilteringGroupID
+		views      []*agd.View
+	}{{
+		loc:        nil,
+		wantView:   nil,
+		name:       "no_views",
+		wantFltGrp: dnssvctest.FilteringGroupID,
+		views:      nil,
+	}, {
+		loc:        nil,
+		wantView:   subnetView,
+		name:       "subnet",
+		wantFltGrp: subnetView.FilteringGroup.ID,
+		views:      []*agd.View{ctryView, subnetView},
+	}, {
+		loc:        &geoip.Location{Country: geoip.CountryCY},
+		wantView:   ctryView,
+		name:       "country_first",
+		wantFltGrp: ctryView.FilteringGroup.ID,
+		views:      []*agd.View{ctryView, subnetView},
+	}, {
+		loc:        &geoip.Location{Country: geoip.CountryAD},
+		wantView:   nil,
+		name:       "no_match",
+		wantFltGrp: dnssvctest.FilteringGroupID,
+		views:      []*agd.View{ctryView},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotRI *agd.RequestInfo
+			next := dnsserver.HandlerFunc(func(
+				ctx context.Context,
+				rw dnsserver.ResponseWriter,
+				req *dns.Msg,
+			) (err error) {
+				gotRI = agd.MustRequestInfoFromContext(ctx)
+
+				return rw.WriteMsg(ctx, req, (&dns.Msg{}).SetReply(req))
+			})
+
+			mw := initial.New(&initial.Config{
+				Logger:      slogutil.NewDiscardLogger(),
+				WhiteLabel:  whitelabel.Empty{},
+				QUICAdvisor: dnsserver.EmptyQUICAdvisor{},
+			})
+
+			ri := &agd.RequestInfo{
+				RemoteIP:       dnssvctest.ClientAddr,
+				Location:       tc.loc,
+				FilteringGroup: defaultFltGrp,
+				Messages:       agdtest.NewConstructor(t),
+				ServerGroup: &agd.ServerGroup{
+					Name:  dnssvctest.ServerGroupName,
+					Views: tc.views,
+				},
+				Server: dnssvctest.ServerName,
+				Host:   dnssvctest.DomainAllowed,
+				QClass: dns.ClassINET,
+				QType:  dns.TypeA,
+				Proto:  agd.ProtoDNS,
+			}
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = agd.ContextWithRequestInfo(ctx, ri)
+
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+			req := dnsservertest.NewReq(dnssvctest.DomainAllowedFQDN, dns.TypeA, dns.ClassINET)
+
+			err := mw.Wrap(next).ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+			require.NotNil(t, gotRI)
+
+			assert.Same(t, tc.wantView, gotRI.View)
+			assert.Equal(t, tc.wantFltGrp, gotRI.FilteringGroup.ID)
+			assert.Nil(t, ri.View)
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package initial
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// isZoneTransfer returns true if qt is the type of a zone transfer request.
+// AdGuard DNS is a resolver and doesn't serve any zones, so such requests are
+// never forwarded upstream.
+func isZoneTransfer(qt dnsmsg.RRType) (ok bool) {
+	return qt == dns.TypeAXFR || qt == dns.TypeIXFR
+}
+
+// handleZoneTransfer responds to the zone transfer requests with a REFUSED
+// response with the Prohibited extended DNS error, if enabled.
+func (mw *Middleware) handleZoneTransfer(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+) (err error) {
+	metrics.DNSSvcZoneTransferRequestsTotal.Inc()
+
+	mw.logger.InfoContext(
+		ctx,
+		"refusing zone transfer",
+		"qtype", dns.Type(ri.QType),
+		"host", ri.Host,
+		"remote_ip", ri.RemoteIP,
+		"proto", ri.Proto,
+	)
+
+	resp := ri.Messages.NewRespRCode(req, dns.RcodeRefused)
+	ri.Messages.AddEDE(req, resp, dns.ExtendedErrorCodeProhibited)
+
+	err = rw.WriteMsg(ctx, req, resp)
+
+	return errors.Annotate(err, "writing zone transfer resp for %q: %w", ri.Host)
+}
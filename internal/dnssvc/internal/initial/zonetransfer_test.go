@@ -0,0 +1,98 @@
+package initial_test
+
+import (
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/initial"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_Wrap_zoneTransfer(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name      string
+		wantRCode dnsmsg.RCode
+		qtype     dnsmsg.RRType
+		wantEDE   bool
+	}{{
+		name:      "axfr",
+		wantRCode: dns.RcodeRefused,
+		qtype:     dns.TypeAXFR,
+		wantEDE:   true,
+	}, {
+		name:      "ixfr",
+		wantRCode: dns.RcodeRefused,
+		qtype:     dns.TypeIXFR,
+		wantEDE:   true,
+	}, {
+		name:      "soa",
+		wantRCode: dns.RcodeSuccess,
+		qtype:     dns.TypeSOA,
+		wantEDE:   false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mw := initial.New(&initial.Config{
+				Logger:      slogutil.NewDiscardLogger(),
+				WhiteLabel:  whitelabel.Empty{},
+				QUICAdvisor: dnsserver.EmptyQUICAdvisor{},
+			})
+
+			isRefused := tc.wantRCode == dns.RcodeRefused
+			h := mw.Wrap(newSpecDomHandler(!isRefused))
+
+			ri := &agd.RequestInfo{
+				Messages: agdtest.NewConstructor(t),
+				ServerGroup: &agd.ServerGroup{
+					Name: dnssvctest.ServerGroupName,
+				},
+				Server:   dnssvctest.ServerName,
+				Host:     dnssvctest.DomainAllowed,
+				RemoteIP: dnssvctest.ClientAddr,
+				QClass:   dns.ClassINET,
+				QType:    tc.qtype,
+				Proto:    agd.ProtoDNS,
+			}
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			ctx = agd.ContextWithRequestInfo(ctx, ri)
+
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+			req := (&dns.Msg{}).SetQuestion(dns.Fqdn(dnssvctest.DomainAllowed), tc.qtype)
+			req.SetEdns0(dns.DefaultMsgSize, false)
+
+			err := h.ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			assert.Equal(t, tc.wantRCode, dnsmsg.RCode(resp.Rcode))
+
+			if !tc.wantEDE {
+				return
+			}
+
+			opt := resp.IsEdns0()
+			require.NotNil(t, opt)
+			require.NotEmpty(t, opt.Option)
+
+			ede := testutil.RequireTypeAssert[*dns.EDNS0_EDE](t, opt.Option[0])
+			assert.Equal(t, dns.ExtendedErrorCodeProhibited, ede.InfoCode)
+		})
+	}
+}
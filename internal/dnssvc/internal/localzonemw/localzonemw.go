@@ -0,0 +1,191 @@
+// Package localzonemw contains the middleware that answers queries for the
+// locally-served zones, such as the private reverse zones, as described in RFC
+// 6303, and for localhost, as described in RFC 6761, instead of forwarding
+// them upstream.
+package localzonemw
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// LocalhostZone is the non-FQDN version of the special-use localhost domain,
+// see RFC 6761, Section 6.3.
+const LocalhostZone = "localhost"
+
+// Config is the configuration structure for the local-zone middleware.  All
+// fields must be non-nil.
+type Config struct {
+	// Logger is used to log the operation of the middleware.
+	Logger *slog.Logger
+
+	// Messages is used to construct the responses.
+	Messages *dnsmsg.Constructor
+}
+
+// Middleware answers queries for the locally-served zones for the server
+// groups with [agd.ServerGroup.LocalZonesEnabled] set.
+type Middleware struct {
+	logger   *slog.Logger
+	messages *dnsmsg.Constructor
+	zones    *container.MapSet[string]
+}
+
+// New returns a new local-zone middleware.  c must not be nil.
+func New(c *Config) (mw *Middleware) {
+	return &Middleware{
+		logger:   c.Logger,
+		messages: c.Messages,
+		zones:    newZones(),
+	}
+}
+
+// newZones returns the set of the non-FQDN names of the locally-served zones
+// listed in RFC 6303, Section 4, and localhost.
+func newZones() (zones *container.MapSet[string]) {
+	zones = container.NewMapSet(
+		LocalhostZone,
+
+		// RFC 1918.
+		"10.in-addr.arpa",
+		"168.192.in-addr.arpa",
+
+		// RFC 5735 and RFC 5737.
+		"0.in-addr.arpa",
+		"127.in-addr.arpa",
+		"254.169.in-addr.arpa",
+		"2.0.192.in-addr.arpa",
+		"100.51.198.in-addr.arpa",
+		"113.0.203.in-addr.arpa",
+		"255.255.255.255.in-addr.arpa",
+
+		// RFC 4291.
+		"0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.ip6.arpa",
+		"1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.ip6.arpa",
+
+		// RFC 4193.
+		"d.f.ip6.arpa",
+
+		// RFC 4291.
+		"8.e.f.ip6.arpa",
+		"9.e.f.ip6.arpa",
+		"a.e.f.ip6.arpa",
+		"b.e.f.ip6.arpa",
+
+		// RFC 3849.
+		"8.b.d.0.1.0.0.2.ip6.arpa",
+	)
+
+	// RFC 1918, 172.16.0.0/12.
+	for i := 16; i <= 31; i++ {
+		zones.Add(strconv.Itoa(i) + ".172.in-addr.arpa")
+	}
+
+	return zones
+}
+
+// type check
+var _ dnsserver.Middleware = (*Middleware)(nil)
+
+// Wrap implements the [dnsserver.Middleware] interface for *Middleware.
+func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
+	f := func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (err error) {
+		defer func() { err = errors.Annotate(err, "localzonemw: %w") }()
+
+		ri := agd.MustRequestInfoFromContext(ctx)
+		zone, ok := mw.zoneFor(ri)
+		if !ok {
+			// Don't wrap the error, because this is the main flow, and there is
+			// already [errors.Annotate] here.
+			return next.ServeDNS(ctx, rw, req)
+		}
+
+		optslog.Debug2(ctx, mw.logger, "answering locally", "host", ri.Host, "zone", zone)
+
+		resp, err := mw.newResp(ri, req, zone)
+		if err != nil {
+			return fmt.Errorf("creating response: %w", err)
+		}
+
+		err = rw.WriteMsg(ctx, req, resp)
+		if err != nil {
+			return fmt.Errorf("writing local zone response: %w", err)
+		}
+
+		return nil
+	}
+
+	return dnsserver.HandlerFunc(f)
+}
+
+// zoneFor returns the locally-served zone containing the host of the request,
+// if the request should be answered locally.
+func (mw *Middleware) zoneFor(ri *agd.RequestInfo) (zone string, ok bool) {
+	if !ri.ServerGroup.LocalZonesEnabled || ri.QClass != dns.ClassINET {
+		return "", false
+	}
+
+	if p, _ := ri.DeviceData(); p != nil && p.ForwardLocalZones {
+		return "", false
+	}
+
+	for zone = ri.Host; zone != ""; {
+		if mw.zones.Has(zone) {
+			return zone, true
+		}
+
+		i := strings.IndexByte(zone, '.')
+		if i < 0 {
+			break
+		}
+
+		zone = zone[i+1:]
+	}
+
+	return "", false
+}
+
+// newResp returns the response for a query for a name within zone.  The
+// addresses of localhost and its subdomains are the loopback ones, as allowed
+// by RFC 6761, Section 6.3.  Other names within the zones don't exist, as
+// recommended by RFC 6303, Section 3.
+func (mw *Middleware) newResp(
+	ri *agd.RequestInfo,
+	req *dns.Msg,
+	zone string,
+) (resp *dns.Msg, err error) {
+	if zone == LocalhostZone {
+		switch ri.QType {
+		case dns.TypeA:
+			return mw.messages.NewRespIP(req, netip.AddrFrom4([4]byte{127, 0, 0, 1}))
+		case dns.TypeAAAA:
+			return mw.messages.NewRespIP(req, netip.IPv6Loopback())
+		default:
+			return mw.messages.NewRespRCode(req, dns.RcodeSuccess), nil
+		}
+	}
+
+	rcode := dns.RcodeNameError
+	if ri.Host == zone {
+		// The apex of the zone exists but has no records of the requested
+		// type.
+		rcode = dns.RcodeSuccess
+	}
+
+	resp = mw.messages.NewRespRCode(req, dnsmsg.RCode(rcode))
+	resp.Authoritative = true
+
+	return resp, nil
+}
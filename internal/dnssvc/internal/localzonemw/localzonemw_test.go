@@ -0,0 +1,210 @@
+package localzonemw_test
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/localzonemw"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_Wrap(t *testing.T) {
+	t.Parallel()
+
+	mw := localzonemw.New(&localzonemw.Config{
+		Logger:   slogutil.NewDiscardLogger(),
+		Messages: agdtest.NewConstructor(t),
+	})
+
+	h := mw.Wrap(dnsservertest.NewDefaultHandler())
+
+	// Set the context necessary for [dnsservertest.DefaultHandler].
+	ctx := context.Background()
+	ctx = dnsserver.ContextWithRequestInfo(ctx, &dnsserver.RequestInfo{
+		StartTime: time.Now(),
+	})
+	ctx = dnsserver.ContextWithServerInfo(ctx, &dnsserver.ServerInfo{})
+
+	var (
+		enabledGrp  = &agd.ServerGroup{LocalZonesEnabled: true}
+		disabledGrp = &agd.ServerGroup{}
+		fwdProf     = &agd.Profile{ForwardLocalZones: true}
+	)
+
+	testCases := []struct {
+		srvGrp    *agd.ServerGroup
+		prof      *agd.Profile
+		wantIP    netip.Addr
+		name      string
+		host      string
+		qt        dnsmsg.RRType
+		wantRCode dnsmsg.RCode
+		wantLocal bool
+	}{{
+		srvGrp:    enabledGrp,
+		prof:      nil,
+		wantIP:    netip.Addr{},
+		name:      "private_ptr",
+		host:      "1.0.168.192.in-addr.arpa",
+		qt:        dns.TypePTR,
+		wantRCode: dns.RcodeNameError,
+		wantLocal: true,
+	}, {
+		srvGrp:    enabledGrp,
+		prof:      nil,
+		wantIP:    netip.Addr{},
+		name:      "private_ptr_172",
+		host:      "1.0.20.172.in-addr.arpa",
+		qt:        dns.TypePTR,
+		wantRCode: dns.RcodeNameError,
+		wantLocal: true,
+	}, {
+		srvGrp:    enabledGrp,
+		prof:      nil,
+		wantIP:    netip.Addr{},
+		name:      "ula_ptr",
+		host:      "1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.d.f.ip6.arpa",
+		qt:        dns.TypePTR,
+		wantRCode: dns.RcodeNameError,
+		wantLocal: true,
+	}, {
+		srvGrp:    enabledGrp,
+		prof:      nil,
+		wantIP:    netip.Addr{},
+		name:      "apex",
+		host:      "10.in-addr.arpa",
+		qt:        dns.TypeNS,
+		wantRCode: dns.RcodeSuccess,
+		wantLocal: true,
+	}, {
+		srvGrp:    enabledGrp,
+		prof:      nil,
+		wantIP:    netip.AddrFrom4([4]byte{127, 0, 0, 1}),
+		name:      "localhost_a",
+		host:      "localhost",
+		qt:        dns.TypeA,
+		wantRCode: dns.RcodeSuccess,
+		wantLocal: true,
+	}, {
+		srvGrp:    enabledGrp,
+		prof:      nil,
+		wantIP:    netip.IPv6Loopback(),
+		name:      "localhost_sub_aaaa",
+		host:      "www.localhost",
+		qt:        dns.TypeAAAA,
+		wantRCode: dns.RcodeSuccess,
+		wantLocal: true,
+	}, {
+		srvGrp:    enabledGrp,
+		prof:      nil,
+		wantIP:    netip.Addr{},
+		name:      "public_ptr",
+		host:      "1.2.0.192.in-addr.arpa",
+		qt:        dns.TypePTR,
+		wantRCode: dns.RcodeNameError,
+		wantLocal: true,
+	}, {
+		srvGrp:    enabledGrp,
+		prof:      nil,
+		wantIP:    netip.Addr{},
+		name:      "not_local",
+		host:      "1.100.51.199.in-addr.arpa",
+		qt:        dns.TypePTR,
+		wantRCode: dns.RcodeSuccess,
+		wantLocal: false,
+	}, {
+		srvGrp:    enabledGrp,
+		prof:      nil,
+		wantIP:    netip.Addr{},
+		name:      "not_local_suffix",
+		host:      "notlocalhost",
+		qt:        dns.TypeA,
+		wantRCode: dns.RcodeSuccess,
+		wantLocal: false,
+	}, {
+		srvGrp:    disabledGrp,
+		prof:      nil,
+		wantIP:    netip.Addr{},
+		name:      "disabled",
+		host:      "1.0.168.192.in-addr.arpa",
+		qt:        dns.TypePTR,
+		wantRCode: dns.RcodeSuccess,
+		wantLocal: false,
+	}, {
+		srvGrp:    enabledGrp,
+		prof:      fwdProf,
+		wantIP:    netip.Addr{},
+		name:      "profile_exempt",
+		host:      "1.0.168.192.in-addr.arpa",
+		qt:        dns.TypePTR,
+		wantRCode: dns.RcodeSuccess,
+		wantLocal: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ri := &agd.RequestInfo{
+				ServerGroup: tc.srvGrp,
+				Host:        tc.host,
+				QType:       tc.qt,
+				QClass:      dns.ClassINET,
+			}
+			if tc.prof != nil {
+				ri.DeviceResult = &agd.DeviceResultOK{
+					Device:  &agd.Device{},
+					Profile: tc.prof,
+				}
+			}
+
+			req := dnsservertest.NewReq(dns.Fqdn(tc.host), tc.qt, dns.ClassINET)
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+
+			err := h.ServeDNS(agd.ContextWithRequestInfo(ctx, ri), rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			if !tc.wantLocal {
+				assert.Empty(t, resp.Ns)
+
+				return
+			}
+
+			assert.Equal(t, int(tc.wantRCode), resp.Rcode)
+			if !tc.wantIP.IsValid() {
+				assert.Empty(t, resp.Answer)
+				assert.Len(t, resp.Ns, 1)
+
+				return
+			}
+
+			require.Len(t, resp.Answer, 1)
+
+			var ip netip.Addr
+			switch ans := resp.Answer[0].(type) {
+			case *dns.A:
+				ip, _ = netip.AddrFromSlice(ans.A)
+			case *dns.AAAA:
+				ip, _ = netip.AddrFromSlice(ans.AAAA)
+			default:
+				t.Fatalf("unexpected answer type %T", ans)
+			}
+
+			assert.Equal(t, tc.wantIP, ip.Unmap())
+		})
+	}
+}
@@ -7,8 +7,11 @@ import (
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filterverdict"
+	"github.com/AdguardTeam/golibs/errors"
 	"github.com/miekg/dns"
 )
 
@@ -24,9 +27,17 @@ type filteringContext struct {
 	requestResult  filter.Result
 	responseResult filter.Result
 
+	// learnedResult is the parental-control blocking result that has not been
+	// enforced, because the device is in the learning mode.
+	learnedResult *filter.ResultBlocked
+
 	elapsed time.Duration
 
 	isDebug bool
+
+	// isQueryTypeDenied is true if the request has been refused or answered
+	// with NODATA because of its query type, so it must not be resolved.
+	isQueryTypeDenied bool
 }
 
 // newFilteringContext returns a new filtering context initialized with the data
@@ -48,8 +59,9 @@ func (mw *Middleware) newFilteringContext(req *dns.Msg) (fctx *filteringContext)
 
 // filterRequest applies f to req and sets the result of filtering in fctx.  If
 // the result is the CNAME rewrite, it also sets the modified request to
-// resolve.  It also adds the time elapsed on filtering.  All errors are
-// reported using [Middleware.reportf].
+// resolve.  The requests of the quarantined devices aren't filtered by f.  It
+// also adds the time elapsed on filtering.  All errors are reported using
+// [Middleware.reportf].
 func (mw *Middleware) filterRequest(
 	ctx context.Context,
 	fctx *filteringContext,
@@ -58,6 +70,21 @@ func (mw *Middleware) filterRequest(
 ) {
 	start := time.Now()
 
+	if res := mw.quarantineResult(ctx, ri); res != nil {
+		fctx.requestResult = res
+		fctx.elapsed += time.Since(start)
+
+		return
+	}
+
+	if res := mw.queryTypeResult(fctx.originalRequest, ri); res != nil {
+		fctx.requestResult = res
+		fctx.isQueryTypeDenied = true
+		fctx.elapsed += time.Since(start)
+
+		return
+	}
+
 	fltReq := mw.reqInfoToFltReq(fctx.originalRequest, ri)
 	defer mw.putFltReq(fltReq)
 
@@ -66,6 +93,8 @@ func (mw *Middleware) filterRequest(
 		errcoll.Collect(ctx, mw.errColl, mw.logger, "filtering request", err)
 	}
 
+	reqRes = mw.applyException(ctx, reqRes, ri)
+	reqRes = applyLearningMode(ctx, fctx, reqRes, ri)
 	if mod, ok := reqRes.(*filter.ResultModifiedRequest); ok {
 		fctx.modifiedRequest = mod.Msg
 	}
@@ -74,6 +103,73 @@ func (mw *Middleware) filterRequest(
 	fctx.elapsed += time.Since(start)
 }
 
+// quarantineResult returns the result for the request if its device is
+// quarantined.  The requests for the remediation domains are allowed, and all
+// other ones are blocked.  Otherwise, it returns nil.
+func (mw *Middleware) quarantineResult(
+	ctx context.Context,
+	ri *agd.RequestInfo,
+) (res filter.Result) {
+	_, d := ri.DeviceData()
+	if d == nil {
+		return nil
+	}
+
+	quarantined, allowed := mw.quarantine.Check(ctx, d, ri.Host)
+	if !quarantined {
+		return nil
+	}
+
+	rule := filter.RuleText(ri.Host)
+	if allowed {
+		return &filter.ResultAllowed{
+			List: filter.IDQuarantine,
+			Rule: rule,
+		}
+	}
+
+	return &filter.ResultBlocked{
+		List: filter.IDQuarantine,
+		Rule: rule,
+	}
+}
+
+// queryTypeResult returns the result for req if the profile of the request has
+// a special action for its query type.  Otherwise, it returns nil.
+func (mw *Middleware) queryTypeResult(
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+) (res *filter.ResultModifiedResponse) {
+	p, _ := ri.DeviceData()
+	if p == nil {
+		return nil
+	}
+
+	act, ok := p.QueryTypeActions[ri.QType]
+	if !ok {
+		return nil
+	}
+
+	var resp *dns.Msg
+	switch act {
+	case agd.QueryTypeActionRefused:
+		resp = ri.Messages.NewRespRCode(req, dns.RcodeRefused)
+		ri.Messages.AddEDE(req, resp, dns.ExtendedErrorCodeProhibited)
+	case agd.QueryTypeActionNODATA:
+		resp = ri.Messages.NewRespRCode(req, dns.RcodeSuccess)
+		ri.Messages.AddEDE(req, resp, dns.ExtendedErrorCodeFiltered)
+	default:
+		// Consider unhandled actions to be unrecoverable programmer errors.
+		panic(fmt.Errorf("query type action: %w: %d", errors.ErrBadEnumValue, act))
+	}
+
+	return &filter.ResultModifiedResponse{
+		Msg:  resp,
+		List: filter.IDQueryType,
+		Rule: filter.RuleText(dns.Type(ri.QType).String()),
+	}
+}
+
 // reqInfoToFltReq converts data from a DNS request and request info into a
 // *filter.Request.  The returned request data should be put back into the pool
 // by using [Middleware.putFltReq].
@@ -139,12 +235,81 @@ func (mw *Middleware) filterResponse(
 			errcoll.Collect(ctx, mw.errColl, mw.logger, "filtering response", err)
 		}
 
-		fctx.responseResult = respRes
+		respRes = mw.applyException(ctx, respRes, ri)
+		fctx.responseResult = applyLearningMode(ctx, fctx, respRes, ri)
 	}
 
 	fctx.elapsed += time.Since(start)
 }
 
+// applyException returns the result allowing the request if res is a blocking
+// result and there is a temporary exception for the host of the request for
+// the profile and the device.  Otherwise, it returns res.
+func (mw *Middleware) applyException(
+	ctx context.Context,
+	res filter.Result,
+	ri *agd.RequestInfo,
+) (applied filter.Result) {
+	if _, ok := res.(*filter.ResultBlocked); !ok {
+		return res
+	}
+
+	p, d := ri.DeviceData()
+	if p == nil || !mw.exceptions.Match(ctx, p.ID, d.ID, ri.Host) {
+		return res
+	}
+
+	return &filter.ResultAllowed{
+		List: filter.IDTemporaryException,
+		Rule: filter.RuleText(ri.Host),
+	}
+}
+
+// applyLearningMode returns nil and sets the learned result in fctx if res is a
+// blocking result of the parental-control filter and the device of the request
+// is in the learning mode.  Otherwise, it returns res.
+func applyLearningMode(
+	ctx context.Context,
+	fctx *filteringContext,
+	res filter.Result,
+	ri *agd.RequestInfo,
+) (applied filter.Result) {
+	blocked, ok := res.(*filter.ResultBlocked)
+	if !ok || !isParental(blocked.List) {
+		return res
+	}
+
+	p, d := ri.DeviceData()
+	if p == nil {
+		return res
+	}
+
+	period := p.FilterConfig.Parental.LearningPeriod
+	if period == 0 {
+		return res
+	}
+
+	start := dnsserver.MustRequestInfoFromContext(ctx).StartTime
+	if !d.IsLearning(period, start) {
+		return res
+	}
+
+	fctx.learnedResult = blocked
+
+	return nil
+}
+
+// isParental returns true if id is the ID of a filter of the parental-control
+// categories, the matches of which are not enforced in the learning mode.
+func isParental(id filter.ID) (ok bool) {
+	switch id {
+	case filter.IDAdultBlocking, filter.IDBlockedService:
+		return true
+	default:
+		return false
+	}
+}
+
 // reqInfoToFltResp converts data from a DNS response and request info into a
 // *filter.Response.  The returned response data should be put back into
 // the pool by using [Middleware.putFltResp].
@@ -296,3 +461,25 @@ func (mw *Middleware) setFilteredResponseNoReq(
 		})
 	}
 }
+
+// addFilterVerdict adds the signed filtering verdict to the filtered response in
+// fctx if the server group is configured to do that and the client has
+// requested it.
+func addFilterVerdict(fctx *filteringContext, ri *agd.RequestInfo) {
+	key := ri.ServerGroup.FilterVerdictKey
+	if len(key) == 0 || !filterverdict.IsRequested(fctx.originalRequest) {
+		return
+	}
+
+	res := fctx.requestResult
+	if res == nil {
+		res = fctx.responseResult
+	}
+
+	v := filterverdict.FromResult(res)
+	if v == nil {
+		return
+	}
+
+	filterverdict.Add(fctx.filteredResponse, key, v)
+}
@@ -6,11 +6,17 @@ import (
 	"net"
 	"net/netip"
 	"testing"
+	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filterverdict"
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
 	"github.com/AdguardTeam/golibs/testutil"
 	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
@@ -130,3 +136,483 @@ func TestMiddleware_setFilteredResponse(t *testing.T) {
 		})
 	})
 }
+
+func TestMiddleware_setFilteredResponse_svcb(t *testing.T) {
+	const domain = "example.com"
+
+	blockIP := netip.MustParseAddr("1.2.3.4")
+	customIPMsgs, err := dnsmsg.NewConstructor(&dnsmsg.ConstructorConfig{
+		Cloner: agdtest.NewCloner(),
+		BlockingMode: &dnsmsg.BlockingModeCustomIP{
+			IPv4: []netip.Addr{blockIP},
+		},
+		StructuredErrors:    agdtest.NewSDEConfig(true),
+		FilteredResponseTTL: agdtest.FilteredResponseTTL,
+		EDEEnabled:          true,
+	})
+	require.NoError(t, err)
+
+	mw := &Middleware{
+		errColl: agdtest.NewErrorCollector(),
+	}
+
+	testCases := []struct {
+		msgs     *dnsmsg.Constructor
+		name     string
+		wantHint net.IP
+		qt       dnsmsg.RRType
+	}{{
+		msgs:     agdtest.NewConstructor(t),
+		name:     "null_ip_https",
+		wantHint: nil,
+		qt:       dns.TypeHTTPS,
+	}, {
+		msgs:     agdtest.NewConstructor(t),
+		name:     "null_ip_svcb",
+		wantHint: nil,
+		qt:       dns.TypeSVCB,
+	}, {
+		msgs:     customIPMsgs,
+		name:     "custom_ip_https",
+		wantHint: blockIP.AsSlice(),
+		qt:       dns.TypeHTTPS,
+	}, {
+		msgs:     customIPMsgs,
+		name:     "custom_ip_svcb",
+		wantHint: blockIP.AsSlice(),
+		qt:       dns.TypeSVCB,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := dnsservertest.NewReq(domain, tc.qt, dns.ClassINET)
+			origResp := dnsservertest.NewResp(dns.RcodeSuccess, req, dnsservertest.SectionAnswer{
+				dnsservertest.NewHTTPS(domain, 60, []netip.Addr{netip.MustParseAddr("5.6.7.8")}, nil),
+			})
+
+			fctx := &filteringContext{
+				originalRequest:  req,
+				originalResponse: origResp,
+				requestResult:    &filter.ResultBlocked{},
+			}
+
+			mw.setFilteredResponse(context.Background(), fctx, &agd.RequestInfo{
+				Messages: tc.msgs,
+			})
+
+			filtered := fctx.filteredResponse
+			require.NotNil(t, filtered)
+
+			assert.Equal(t, dns.RcodeSuccess, filtered.Rcode)
+
+			if tc.wantHint == nil {
+				assert.Empty(t, filtered.Answer)
+
+				return
+			}
+
+			require.Len(t, filtered.Answer, 1)
+
+			ans := filtered.Answer[0]
+			assert.Equal(t, tc.qt, ans.Header().Rrtype)
+
+			var svcb *dns.SVCB
+			if tc.qt == dns.TypeHTTPS {
+				svcb = &testutil.RequireTypeAssert[*dns.HTTPS](t, ans).SVCB
+			} else {
+				svcb = testutil.RequireTypeAssert[*dns.SVCB](t, ans)
+			}
+
+			require.Len(t, svcb.Value, 1)
+
+			hint := testutil.RequireTypeAssert[*dns.SVCBIPv4Hint](t, svcb.Value[0])
+			assert.Equal(t, []net.IP{tc.wantHint}, hint.Hint)
+		})
+	}
+}
+
+func TestAddFilterVerdict(t *testing.T) {
+	t.Parallel()
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	res := &filter.ResultBlocked{
+		List: filter.IDAdGuardDNS,
+		Rule: "||example.com^",
+	}
+
+	testCases := []struct {
+		reqRes    filter.Result
+		want      *filterverdict.Verdict
+		name      string
+		key       []byte
+		requested bool
+	}{{
+		reqRes: res,
+		want: &filterverdict.Verdict{
+			ListID:   filter.IDAdGuardDNS,
+			Action:   filterverdict.ActionBlocked,
+			Category: filterverdict.CategoryRuleList,
+		},
+		name:      "success",
+		key:       key,
+		requested: true,
+	}, {
+		reqRes:    res,
+		want:      nil,
+		name:      "not_requested",
+		key:       key,
+		requested: false,
+	}, {
+		reqRes:    res,
+		want:      nil,
+		name:      "disabled",
+		key:       nil,
+		requested: true,
+	}, {
+		reqRes:    nil,
+		want:      nil,
+		name:      "not_filtered",
+		key:       key,
+		requested: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := dnsservertest.NewReq("example.com.", dns.TypeA, dns.ClassINET)
+			req.SetEdns0(dns.DefaultMsgSize, false)
+			if tc.requested {
+				opt := req.IsEdns0()
+				opt.Option = append(opt.Option, &dns.EDNS0_LOCAL{
+					Code: filterverdict.EDNSCode,
+				})
+			}
+
+			fctx := &filteringContext{
+				originalRequest:  req,
+				filteredResponse: dnsservertest.NewResp(dns.RcodeSuccess, req),
+				requestResult:    tc.reqRes,
+			}
+
+			addFilterVerdict(fctx, &agd.RequestInfo{
+				ServerGroup: &agd.ServerGroup{
+					FilterVerdictKey: tc.key,
+				},
+			})
+
+			got, err := filterverdict.Verify(fctx.filteredResponse, key)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestMiddleware_applyException(t *testing.T) {
+	t.Parallel()
+
+	const (
+		profID agd.ProfileID = "prof1234"
+		devID  agd.DeviceID  = "dev1234"
+
+		host = "blocked.example"
+	)
+
+	exceptions := unblock.NewDefaultStorage(&unblock.DefaultStorageConfig{
+		Clock:         agdtime.SystemClock{},
+		MaxPerProfile: 1,
+	})
+	exceptions.Add(context.Background(), &unblock.Exception{
+		Expires:   time.Now().Add(time.Hour),
+		ProfileID: profID,
+		DeviceID:  devID,
+		Host:      host,
+	})
+
+	mw := &Middleware{
+		exceptions: exceptions,
+	}
+
+	blocked := &filter.ResultBlocked{
+		List: "list",
+		Rule: "||" + host + "^",
+	}
+	modified := &filter.ResultModifiedResponse{
+		List: "list",
+		Rule: "||" + host + "^$dnsrewrite=1.2.3.4",
+	}
+	allowed := &filter.ResultAllowed{
+		List: filter.IDTemporaryException,
+		Rule: host,
+	}
+
+	testCases := []struct {
+		res  filter.Result
+		want filter.Result
+		dev  *agd.Device
+		prof *agd.Profile
+		name string
+		host string
+	}{{
+		res:  blocked,
+		want: allowed,
+		dev:  &agd.Device{ID: devID},
+		prof: &agd.Profile{ID: profID},
+		name: "blocked",
+		host: host,
+	}, {
+		res:  modified,
+		want: modified,
+		dev:  &agd.Device{ID: devID},
+		prof: &agd.Profile{ID: profID},
+		name: "modified",
+		host: host,
+	}, {
+		res:  nil,
+		want: nil,
+		dev:  &agd.Device{ID: devID},
+		prof: &agd.Profile{ID: profID},
+		name: "none",
+		host: host,
+	}, {
+		res:  blocked,
+		want: blocked,
+		dev:  &agd.Device{ID: "dev5678"},
+		prof: &agd.Profile{ID: profID},
+		name: "other_device",
+		host: host,
+	}, {
+		res:  blocked,
+		want: blocked,
+		dev:  &agd.Device{ID: devID},
+		prof: &agd.Profile{ID: profID},
+		name: "other_host",
+		host: "other.example",
+	}, {
+		res:  blocked,
+		want: blocked,
+		dev:  nil,
+		prof: nil,
+		name: "no_profile",
+		host: host,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ri := &agd.RequestInfo{
+				DeviceResult: &agd.DeviceResultOK{
+					Device:  tc.dev,
+					Profile: tc.prof,
+				},
+				Host: tc.host,
+			}
+
+			ctx := testutil.ContextWithTimeout(t, 1*time.Second)
+			assert.Equal(t, tc.want, mw.applyException(ctx, tc.res, ri))
+		})
+	}
+}
+
+func TestApplyLearningMode(t *testing.T) {
+	t.Parallel()
+
+	const period = 7 * 24 * time.Hour
+
+	start := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	learningDev := &agd.Device{
+		ID:        "dev1234",
+		CreatedAt: start.Add(-time.Hour),
+	}
+	enforcingDev := &agd.Device{
+		ID:        "dev5678",
+		CreatedAt: start.Add(-period),
+	}
+
+	prof := &agd.Profile{
+		FilterConfig: &filter.ConfigClient{
+			Parental: &filter.ConfigParental{
+				Enabled:        true,
+				LearningPeriod: period,
+			},
+		},
+	}
+	profNoLearning := &agd.Profile{
+		FilterConfig: &filter.ConfigClient{
+			Parental: &filter.ConfigParental{
+				Enabled: true,
+			},
+		},
+	}
+
+	adult := &filter.ResultBlocked{
+		List: filter.IDAdultBlocking,
+		Rule: "adult.example",
+	}
+	blockedSvc := &filter.ResultBlocked{
+		List: filter.IDBlockedService,
+		Rule: "example_service",
+	}
+	blockedRule := &filter.ResultBlocked{
+		List: "list",
+		Rule: "||blocked.example^",
+	}
+
+	testCases := []struct {
+		res         filter.Result
+		want        filter.Result
+		wantLearned *filter.ResultBlocked
+		dev         *agd.Device
+		prof        *agd.Profile
+		name        string
+	}{{
+		res:         adult,
+		want:        nil,
+		wantLearned: adult,
+		dev:         learningDev,
+		prof:        prof,
+		name:        "adult_blocking",
+	}, {
+		res:         blockedSvc,
+		want:        nil,
+		wantLearned: blockedSvc,
+		dev:         learningDev,
+		prof:        prof,
+		name:        "blocked_service",
+	}, {
+		res:         blockedRule,
+		want:        blockedRule,
+		wantLearned: nil,
+		dev:         learningDev,
+		prof:        prof,
+		name:        "not_parental",
+	}, {
+		res:         adult,
+		want:        adult,
+		wantLearned: nil,
+		dev:         enforcingDev,
+		prof:        prof,
+		name:        "expired",
+	}, {
+		res:         adult,
+		want:        adult,
+		wantLearned: nil,
+		dev:         learningDev,
+		prof:        profNoLearning,
+		name:        "no_learning_period",
+	}, {
+		res:         adult,
+		want:        adult,
+		wantLearned: nil,
+		dev:         nil,
+		prof:        nil,
+		name:        "no_profile",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ri := &agd.RequestInfo{
+				DeviceResult: &agd.DeviceResultOK{
+					Device:  tc.dev,
+					Profile: tc.prof,
+				},
+			}
+
+			ctx := testutil.ContextWithTimeout(t, 1*time.Second)
+			ctx = dnsserver.ContextWithRequestInfo(ctx, &dnsserver.RequestInfo{
+				StartTime: start,
+			})
+
+			fctx := &filteringContext{}
+			assert.Equal(t, tc.want, applyLearningMode(ctx, fctx, tc.res, ri))
+			assert.Equal(t, tc.wantLearned, fctx.learnedResult)
+		})
+	}
+}
+
+func TestMiddleware_quarantineResult(t *testing.T) {
+	t.Parallel()
+
+	const (
+		host            = "blocked.example"
+		remediationHost = "updates.example"
+	)
+
+	mw := &Middleware{
+		quarantine: &agdtest.Quarantine{
+			OnCheck: func(
+				_ context.Context,
+				d *agd.Device,
+				h string,
+			) (quarantined, allowed bool) {
+				return d.Quarantined, d.Quarantined && h == remediationHost
+			},
+		},
+	}
+
+	quarDev := &agd.Device{
+		ID:          "dev1234",
+		Quarantined: true,
+	}
+	prof := &agd.Profile{ID: "prof1234"}
+
+	testCases := []struct {
+		want filter.Result
+		dev  *agd.Device
+		prof *agd.Profile
+		name string
+		host string
+	}{{
+		want: &filter.ResultBlocked{
+			List: filter.IDQuarantine,
+			Rule: host,
+		},
+		dev:  quarDev,
+		prof: prof,
+		name: "blocked",
+		host: host,
+	}, {
+		want: &filter.ResultAllowed{
+			List: filter.IDQuarantine,
+			Rule: remediationHost,
+		},
+		dev:  quarDev,
+		prof: prof,
+		name: "remediation",
+		host: remediationHost,
+	}, {
+		want: nil,
+		dev:  &agd.Device{ID: "dev5678"},
+		prof: prof,
+		name: "not_quarantined",
+		host: host,
+	}, {
+		want: nil,
+		dev:  nil,
+		prof: nil,
+		name: "no_device",
+		host: host,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ri := &agd.RequestInfo{
+				DeviceResult: &agd.DeviceResultOK{
+					Device:  tc.dev,
+					Profile: tc.prof,
+				},
+				Host: tc.host,
+			}
+
+			ctx := testutil.ContextWithTimeout(t, 1*time.Second)
+			assert.Equal(t, tc.want, mw.quarantineResult(ctx, ri))
+		})
+	}
+}
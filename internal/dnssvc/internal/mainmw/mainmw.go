@@ -9,6 +9,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdnet"
 	"github.com/AdguardTeam/AdGuardDNS/internal/billstat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal"
@@ -16,8 +17,13 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
 	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/popdomains"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
 	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/reqlog"
 	"github.com/AdguardTeam/AdGuardDNS/internal/rulestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/topprofiles"
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/syncutil"
 	"github.com/miekg/dns"
@@ -32,12 +38,19 @@ type Middleware struct {
 	logger      *slog.Logger
 	messages    *dnsmsg.Constructor
 	billStat    billstat.Recorder
+	classifier  filter.Classifier
+	deviceStat  devicestat.Interface
 	errColl     errcoll.Interface
+	exceptions  unblock.Storage
 	fltStrg     filter.Storage
 	geoIP       geoip.Interface
 	metrics     Metrics
+	popDomains  popdomains.Interface
+	quarantine  quarantine.Interface
 	queryLog    querylog.Interface
+	reqLog      reqlog.Interface
 	ruleStat    rulestat.Interface
+	topProfiles topprofiles.Interface
 }
 
 // Config is the configuration structure for the main middleware.  All fields
@@ -57,10 +70,21 @@ type Config struct {
 	// BillStat is used to collect billing statistics.
 	BillStat billstat.Recorder
 
+	// Classifier is used to find the content categories of the requested
+	// hosts, which are then set in the request information.
+	Classifier filter.Classifier
+
+	// DeviceStat is used to collect the per-device query statistics.
+	DeviceStat devicestat.Interface
+
 	// ErrColl is the error collector that is used to collect critical and
 	// non-critical errors.
 	ErrColl errcoll.Interface
 
+	// Exceptions is the storage of the temporary exceptions, which allow the
+	// blocked requests for a profile or a device.
+	Exceptions unblock.Storage
+
 	// FilterStorage is the storage of all filters.
 	FilterStorage filter.Storage
 
@@ -71,12 +95,26 @@ type Config struct {
 	// Metrics is used to collect the statistics.
 	Metrics Metrics
 
+	// PopularDomains is used to collect the differentially private statistics
+	// of the most popular domains.
+	PopularDomains popdomains.Interface
+
+	// Quarantine is used to block the requests of the quarantined devices.
+	Quarantine quarantine.Interface
+
 	// QueryLog is used to write the logs into.
 	QueryLog querylog.Interface
 
+	// RequestLog is used to write the structured per-request debug logs.
+	RequestLog reqlog.Interface
+
 	// RuleStat is used to collect statistics about matched filtering rules and
 	// rule lists.
 	RuleStat rulestat.Interface
+
+	// TopProfiles is used to collect the statistics of the profiles with the
+	// most requests, errors, and blocked requests.
+	TopProfiles topprofiles.Interface
 }
 
 // New returns a new main middleware.  c must not be nil.
@@ -92,15 +130,22 @@ func New(c *Config) (mw *Middleware) {
 		fltRespPool: syncutil.NewPool(func() (v *filter.Response) {
 			return &filter.Response{}
 		}),
-		logger:   c.Logger,
-		messages: c.Messages,
-		billStat: c.BillStat,
-		errColl:  c.ErrColl,
-		fltStrg:  c.FilterStorage,
-		geoIP:    c.GeoIP,
-		metrics:  c.Metrics,
-		queryLog: c.QueryLog,
-		ruleStat: c.RuleStat,
+		logger:      c.Logger,
+		messages:    c.Messages,
+		billStat:    c.BillStat,
+		classifier:  c.Classifier,
+		deviceStat:  c.DeviceStat,
+		errColl:     c.ErrColl,
+		exceptions:  c.Exceptions,
+		fltStrg:     c.FilterStorage,
+		geoIP:       c.GeoIP,
+		metrics:     c.Metrics,
+		popDomains:  c.PopularDomains,
+		quarantine:  c.Quarantine,
+		queryLog:    c.QueryLog,
+		reqLog:      c.RequestLog,
+		ruleStat:    c.RuleStat,
+		topProfiles: c.TopProfiles,
 	}
 }
 
@@ -133,6 +178,9 @@ func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
 			"remote_ip", ri.RemoteIP,
 		)
 
+		ctx, trace := mw.newTrace(ctx, ri)
+		ctx, ri = mw.classify(ctx, ri)
+
 		flt := mw.filter(ctx, ri)
 		mw.filterRequest(ctx, fctx, flt, ri)
 
@@ -144,18 +192,18 @@ func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
 			return afterFilteringError{err: err}
 		}
 
-		nwrw := internal.MakeNonWriter(rw)
-		err = next.ServeDNS(mw.nextParams(ctx, fctx, nwrw, ri))
+		err = mw.resolve(ctx, next, fctx, flt, rw, ri)
 		if err != nil {
+			mw.recordTopProfiles(ctx, ri, false, true)
+			mw.logRequest(ctx, fctx, ri, trace, err)
+
 			return err
 		}
 
-		fctx.originalResponse = nwrw.Msg()
-		mw.filterResponse(ctx, fctx, flt, ri)
-
 		mw.reportMetrics(ctx, fctx, ri)
 
 		mw.setFilteredResponse(ctx, fctx, ri)
+		addFilterVerdict(fctx, ri)
 
 		if fctx.isDebug {
 			return mw.writeDebugResponse(ctx, fctx, rw)
@@ -168,6 +216,7 @@ func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
 		}
 
 		mw.recordQueryInfo(ctx, fctx, ri)
+		mw.logRequest(ctx, fctx, ri, trace, nil)
 
 		if fctx.filteredResponse != fctx.originalResponse {
 			mw.cloner.Dispose(fctx.originalResponse)
@@ -179,6 +228,59 @@ func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
 	return dnsserver.HandlerFunc(f)
 }
 
+// resolve sets the original response in fctx.  If the request has been denied
+// because of its query type, the response is taken from the request filtering
+// result.  Otherwise, resolve calls next and applies f to the response.
+func (mw *Middleware) resolve(
+	ctx context.Context,
+	next dnsserver.Handler,
+	fctx *filteringContext,
+	f filter.Interface,
+	rw dnsserver.ResponseWriter,
+	ri *agd.RequestInfo,
+) (err error) {
+	if fctx.isQueryTypeDenied {
+		// The result is always a modified response, see
+		// [Middleware.queryTypeResult].
+		fctx.originalResponse = fctx.requestResult.(*filter.ResultModifiedResponse).Msg
+
+		return nil
+	}
+
+	nwrw := internal.MakeNonWriter(rw)
+	err = next.ServeDNS(mw.nextParams(ctx, fctx, nwrw, ri))
+	if err != nil {
+		return err
+	}
+
+	fctx.originalResponse = nwrw.Msg()
+	mw.filterResponse(ctx, fctx, f, ri)
+
+	return nil
+}
+
+// classify returns the context and the request information with the content
+// categories of the requested host set, if there are any.  Otherwise, it
+// returns parent and ri.
+func (mw *Middleware) classify(
+	parent context.Context,
+	ri *agd.RequestInfo,
+) (ctx context.Context, classified *agd.RequestInfo) {
+	cats := mw.classifier.Classify(parent, ri.Host)
+	if len(cats) == 0 {
+		return parent, ri
+	}
+
+	// Clone the request information, since the request information from
+	// current context must only be accessed for reading, see [agd.RequestInfo].
+	// Shallow copy is enough, because only the categories are set.
+	classified = &agd.RequestInfo{}
+	*classified = *ri
+	classified.Categories = cats
+
+	return agd.ContextWithRequestInfo(parent, classified), classified
+}
+
 // filter returns a filter based on the request information.
 func (mw *Middleware) filter(ctx context.Context, ri *agd.RequestInfo) (f filter.Interface) {
 	p, d := ri.DeviceData()
@@ -203,8 +305,13 @@ func (mw *Middleware) nextParams(
 ) (ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) {
 	ctx = parent
 
+	outcome := filteringOutcome(fctx.requestResult)
 	modReq := fctx.modifiedRequest
 	if modReq == nil {
+		if outcome != agd.FilteringOutcomeUnfiltered {
+			ctx = agd.ContextWithRequestInfo(ctx, withFilteringOutcome(ri, outcome))
+		}
+
 		return ctx, origRW, fctx.originalRequest
 	}
 
@@ -214,10 +321,9 @@ func (mw *Middleware) nextParams(
 	// Clone the request information and replace the host name with the
 	// rewritten one, since the request information from current context must
 	// only be accessed for reading, see [agd.RequestInfo].  Shallow copy is
-	// enough, because we only change the [agd.RequestInfo.Host] field, which is
-	// a string.
-	modReqInfo := &agd.RequestInfo{}
-	*modReqInfo = *ri
+	// enough, because we only change the [agd.RequestInfo.Host] and
+	// [agd.RequestInfo.FilteringOutcome] fields, which are values.
+	modReqInfo := withFilteringOutcome(ri, outcome)
 	modReqInfo.Host = agdnet.NormalizeDomain(modReq.Question[0].Name)
 
 	ctx = agd.ContextWithRequestInfo(ctx, modReqInfo)
@@ -233,6 +339,30 @@ func (mw *Middleware) nextParams(
 	return ctx, origRW, modReq
 }
 
+// withFilteringOutcome returns a shallow copy of ri with the filtering outcome
+// set to outcome.
+func withFilteringOutcome(
+	ri *agd.RequestInfo,
+	outcome agd.FilteringOutcome,
+) (cloned *agd.RequestInfo) {
+	cloned = &agd.RequestInfo{}
+	*cloned = *ri
+	cloned.FilteringOutcome = outcome
+
+	return cloned
+}
+
+// filteringOutcome returns the class of the outcome of the request filtering
+// for reqRes.
+func filteringOutcome(reqRes filter.Result) (outcome agd.FilteringOutcome) {
+	switch reqRes.(type) {
+	case nil, *filter.ResultAllowed:
+		return agd.FilteringOutcomeUnfiltered
+	default:
+		return agd.FilteringOutcomeFiltered
+	}
+}
+
 // reportMetrics extracts filtering metrics data from the context and reports it
 // to Prometheus.
 func (mw *Middleware) reportMetrics(
@@ -245,12 +375,23 @@ func (mw *Middleware) reportMetrics(
 	if l := ri.Location; l != nil {
 		ctry, cont = string(l.Country), string(l.Continent)
 		asn = uint32(l.ASN)
+
+		mw.popDomains.Record(ctx, ri.ClientKey, ri.Host, l.Country, l.ASN)
 	}
 
 	id, _, isBlocked := filteringData(fctx)
 	p, _ := ri.DeviceData()
 
+	var cats []string
+	if len(ri.Categories) > 0 {
+		cats = make([]string, 0, len(ri.Categories))
+		for _, c := range ri.Categories {
+			cats = append(cats, string(c))
+		}
+	}
+
 	mw.metrics.OnRequest(ctx, &RequestMetrics{
+		Categories:        cats,
 		RemoteIP:          ri.RemoteIP,
 		Continent:         cont,
 		Country:           ctry,
@@ -260,4 +401,24 @@ func (mw *Middleware) reportMetrics(
 		IsAnonymous:       p == nil,
 		IsBlocked:         isBlocked,
 	})
+
+	resp := fctx.originalResponse
+	isError := resp != nil && resp.Rcode == dns.RcodeServerFailure
+	mw.recordTopProfiles(ctx, ri, isBlocked, isError)
+}
+
+// recordTopProfiles records the request into the top-profiles statistics, if
+// the request has a profile.
+func (mw *Middleware) recordTopProfiles(
+	ctx context.Context,
+	ri *agd.RequestInfo,
+	isBlocked bool,
+	isError bool,
+) {
+	p, _ := ri.DeviceData()
+	if p == nil {
+		return
+	}
+
+	mw.topProfiles.Record(ctx, p.ID, isBlocked, isError)
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdnet"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
@@ -16,7 +17,12 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/mainmw"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/popdomains"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
 	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/reqlog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/topprofiles"
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/testutil"
 	"github.com/miekg/dns"
@@ -216,16 +222,23 @@ func TestMiddleware_Wrap(t *testing.T) {
 			}
 
 			c := &mainmw.Config{
-				Cloner:        cloner,
-				Logger:        slogutil.NewDiscardLogger(),
-				Messages:      msgs,
-				BillStat:      tc.billStat,
-				ErrColl:       agdtest.NewErrorCollector(),
-				FilterStorage: fltStrg,
-				GeoIP:         geoIP,
-				Metrics:       mainmw.EmptyMetrics{},
-				QueryLog:      queryLog,
-				RuleStat:      ruleStat,
+				Cloner:         cloner,
+				Logger:         slogutil.NewDiscardLogger(),
+				Messages:       msgs,
+				BillStat:       tc.billStat,
+				Classifier:     filter.EmptyClassifier{},
+				DeviceStat:     devicestat.Empty{},
+				ErrColl:        agdtest.NewErrorCollector(),
+				Exceptions:     unblock.EmptyStorage{},
+				FilterStorage:  fltStrg,
+				GeoIP:          geoIP,
+				Metrics:        mainmw.EmptyMetrics{},
+				PopularDomains: popdomains.Empty{},
+				Quarantine:     quarantine.Empty{},
+				QueryLog:       queryLog,
+				RequestLog:     reqlog.Empty{},
+				RuleStat:       ruleStat,
+				TopProfiles:    topprofiles.Empty{},
 			}
 
 			mw := mainmw.New(c)
@@ -358,6 +371,9 @@ func newContext(
 		FilteringGroup: &agd.FilteringGroup{
 			FilterConfig: fltConf,
 		},
+		ServerGroup: &agd.ServerGroup{
+			Name: dnssvctest.ServerGroupName,
+		},
 		Messages: agdtest.NewConstructor(tb),
 		RemoteIP: dnssvctest.ClientAddr,
 		Host:     host,
@@ -693,16 +709,23 @@ func TestMiddleware_Wrap_filtering(t *testing.T) {
 			}
 
 			c := &mainmw.Config{
-				Cloner:        cloner,
-				Logger:        slogutil.NewDiscardLogger(),
-				Messages:      msgs,
-				BillStat:      tc.billStat,
-				ErrColl:       agdtest.NewErrorCollector(),
-				FilterStorage: fltStrg,
-				GeoIP:         geoIP,
-				Metrics:       mainmw.EmptyMetrics{},
-				QueryLog:      queryLog,
-				RuleStat:      ruleStat,
+				Cloner:         cloner,
+				Logger:         slogutil.NewDiscardLogger(),
+				Messages:       msgs,
+				BillStat:       tc.billStat,
+				Classifier:     filter.EmptyClassifier{},
+				DeviceStat:     devicestat.Empty{},
+				ErrColl:        agdtest.NewErrorCollector(),
+				Exceptions:     unblock.EmptyStorage{},
+				FilterStorage:  fltStrg,
+				GeoIP:          geoIP,
+				Metrics:        mainmw.EmptyMetrics{},
+				PopularDomains: popdomains.Empty{},
+				Quarantine:     quarantine.Empty{},
+				QueryLog:       queryLog,
+				RequestLog:     reqlog.Empty{},
+				RuleStat:       ruleStat,
+				TopProfiles:    topprofiles.Empty{},
 			}
 
 			mw := mainmw.New(c)
@@ -722,3 +745,289 @@ func TestMiddleware_Wrap_filtering(t *testing.T) {
 		})
 	}
 }
+
+func TestMiddleware_Wrap_classification(t *testing.T) {
+	t.Parallel()
+
+	const catStr = "social_network"
+
+	wantCats := []filter.Category{catStr}
+
+	flt := &agdtest.Filter{
+		OnFilterRequest: func(
+			_ context.Context,
+			_ *filter.Request,
+		) (r filter.Result, err error) {
+			return nil, nil
+		},
+		OnFilterResponse: func(
+			_ context.Context,
+			_ *filter.Response,
+		) (r filter.Result, err error) {
+			return nil, nil
+		},
+	}
+
+	fltStrg := &agdtest.FilterStorage{
+		OnForConfig: func(_ context.Context, _ filter.Config) (f filter.Interface) {
+			return flt
+		},
+		OnHasListID: func(_ filter.ID) (ok bool) { panic("not implemented") },
+	}
+
+	classifier := &agdtest.Classifier{
+		OnClassify: func(_ context.Context, host string) (cats []filter.Category) {
+			pt := testutil.PanicT{}
+			require.Equal(pt, dnssvctest.Domain, host)
+
+			return wantCats
+		},
+	}
+
+	geoIP := agdtest.NewGeoIP()
+	geoIP.OnData = func(_ string, _ netip.Addr) (l *geoip.Location, err error) {
+		return nil, nil
+	}
+
+	var gotEntryCats []filter.Category
+	queryLog := &agdtest.QueryLog{
+		OnWrite: func(_ context.Context, e *querylog.Entry) (err error) {
+			gotEntryCats = e.Categories
+
+			return nil
+		},
+	}
+
+	ruleStat := &agdtest.RuleStat{
+		OnCollect: func(_ context.Context, _ filter.ID, _ filter.RuleText) {},
+	}
+
+	reqStart := time.Now()
+	c := &mainmw.Config{
+		Cloner:   agdtest.NewCloner(),
+		Logger:   slogutil.NewDiscardLogger(),
+		Messages: agdtest.NewConstructor(t),
+		BillStat: &agdtest.BillStatRecorder{
+			OnRecord: func(
+				_ context.Context,
+				_ agd.DeviceID,
+				_ geoip.Country,
+				_ geoip.ASN,
+				_ time.Time,
+				_ agd.Protocol,
+			) {
+			},
+		},
+		Classifier:     classifier,
+		DeviceStat:     devicestat.Empty{},
+		ErrColl:        agdtest.NewErrorCollector(),
+		Exceptions:     unblock.EmptyStorage{},
+		FilterStorage:  fltStrg,
+		GeoIP:          geoIP,
+		Metrics:        mainmw.EmptyMetrics{},
+		PopularDomains: popdomains.Empty{},
+		Quarantine:     quarantine.Empty{},
+		QueryLog:       queryLog,
+		RequestLog:     reqlog.Empty{},
+		RuleStat:       ruleStat,
+		TopProfiles:    topprofiles.Empty{},
+	}
+
+	mw := mainmw.New(c)
+
+	req := dnsservertest.NewReq(dnssvctest.DomainFQDN, dns.TypeA, dns.ClassINET)
+	resp := dnsservertest.NewResp(dns.RcodeSuccess, req, dnsservertest.SectionAnswer{
+		wantAns(t, dns.TypeA),
+	})
+
+	var gotReqCats []filter.Category
+	handler := dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		r *dns.Msg,
+	) (err error) {
+		gotReqCats = agd.MustRequestInfoFromContext(ctx).Categories
+
+		return rw.WriteMsg(ctx, r, resp)
+	})
+
+	h := mw.Wrap(handler)
+
+	ctx := newContext(t, testDevice, testProfile, dnssvctest.Domain, dns.TypeA, reqStart)
+	rw := dnsserver.NewNonWriterResponseWriter(dnssvctest.ServerTCPAddr, dnssvctest.ClientTCPAddr)
+
+	err := h.ServeDNS(ctx, rw, req)
+	require.NoError(t, err)
+
+	assert.Equal(t, wantCats, gotReqCats)
+	assert.Equal(t, wantCats, gotEntryCats)
+}
+
+func TestMiddleware_Wrap_queryType(t *testing.T) {
+	t.Parallel()
+
+	flt := &agdtest.Filter{
+		OnFilterRequest: func(
+			_ context.Context,
+			_ *filter.Request,
+		) (r filter.Result, err error) {
+			return nil, nil
+		},
+		OnFilterResponse: func(
+			_ context.Context,
+			_ *filter.Response,
+		) (r filter.Result, err error) {
+			return nil, nil
+		},
+	}
+
+	fltStrg := &agdtest.FilterStorage{
+		OnForConfig: func(_ context.Context, _ filter.Config) (f filter.Interface) {
+			return flt
+		},
+		OnHasListID: func(_ filter.ID) (ok bool) { panic("not implemented") },
+	}
+
+	geoIP := agdtest.NewGeoIP()
+	geoIP.OnData = func(_ string, _ netip.Addr) (l *geoip.Location, err error) {
+		return nil, nil
+	}
+
+	prof := &agd.Profile{
+		ID: dnssvctest.ProfileID,
+		QueryTypeActions: map[dnsmsg.RRType]agd.QueryTypeAction{
+			dns.TypeAAAA: agd.QueryTypeActionNODATA,
+			dns.TypeANY:  agd.QueryTypeActionRefused,
+		},
+		QueryLogEnabled: true,
+	}
+
+	testCases := []struct {
+		name      string
+		wantRule  filter.RuleText
+		qtype     dnsmsg.RRType
+		wantRCode dnsmsg.RCode
+		wantEDE   uint16
+	}{{
+		name:      "allowed",
+		wantRule:  "",
+		qtype:     dns.TypeA,
+		wantRCode: dns.RcodeSuccess,
+		wantEDE:   0,
+	}, {
+		name:      "nodata",
+		wantRule:  "AAAA",
+		qtype:     dns.TypeAAAA,
+		wantRCode: dns.RcodeSuccess,
+		wantEDE:   dns.ExtendedErrorCodeFiltered,
+	}, {
+		name:      "refused",
+		wantRule:  "ANY",
+		qtype:     dns.TypeANY,
+		wantRCode: dns.RcodeRefused,
+		wantEDE:   dns.ExtendedErrorCodeProhibited,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var gotRes filter.Result
+			queryLog := &agdtest.QueryLog{
+				OnWrite: func(_ context.Context, e *querylog.Entry) (err error) {
+					gotRes = e.RequestResult
+
+					return nil
+				},
+			}
+
+			c := &mainmw.Config{
+				Cloner:   agdtest.NewCloner(),
+				Logger:   slogutil.NewDiscardLogger(),
+				Messages: agdtest.NewConstructor(t),
+				BillStat: &agdtest.BillStatRecorder{
+					OnRecord: func(
+						_ context.Context,
+						_ agd.DeviceID,
+						_ geoip.Country,
+						_ geoip.ASN,
+						_ time.Time,
+						_ agd.Protocol,
+					) {
+					},
+				},
+				Classifier:     filter.EmptyClassifier{},
+				DeviceStat:     devicestat.Empty{},
+				ErrColl:        agdtest.NewErrorCollector(),
+				Exceptions:     unblock.EmptyStorage{},
+				FilterStorage:  fltStrg,
+				GeoIP:          geoIP,
+				Metrics:        mainmw.EmptyMetrics{},
+				PopularDomains: popdomains.Empty{},
+				Quarantine:     quarantine.Empty{},
+				QueryLog:       queryLog,
+				RequestLog:     reqlog.Empty{},
+				RuleStat: &agdtest.RuleStat{
+					OnCollect: func(_ context.Context, _ filter.ID, _ filter.RuleText) {},
+				},
+				TopProfiles: topprofiles.Empty{},
+			}
+
+			mw := mainmw.New(c)
+
+			req := dnsservertest.NewReq(dnssvctest.DomainFQDN, tc.qtype, dns.ClassINET)
+			req.SetEdns0(dns.DefaultMsgSize, false)
+
+			upsResp := dnsservertest.NewResp(dns.RcodeSuccess, req)
+
+			var isResolved bool
+			handler := dnsserver.HandlerFunc(func(
+				ctx context.Context,
+				rw dnsserver.ResponseWriter,
+				r *dns.Msg,
+			) (err error) {
+				isResolved = true
+
+				return rw.WriteMsg(ctx, r, upsResp)
+			})
+
+			h := mw.Wrap(handler)
+
+			reqStart := time.Now()
+			ctx := newContext(t, testDevice, prof, dnssvctest.Domain, tc.qtype, reqStart)
+			rw := dnsserver.NewNonWriterResponseWriter(
+				dnssvctest.ServerTCPAddr,
+				dnssvctest.ClientTCPAddr,
+			)
+
+			err := h.ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			assert.Equal(t, tc.wantRCode, dnsmsg.RCode(resp.Rcode))
+			assert.Empty(t, resp.Answer)
+
+			if tc.wantRule == "" {
+				assert.True(t, isResolved)
+				assert.Nil(t, gotRes)
+
+				return
+			}
+
+			assert.False(t, isResolved)
+
+			modRes := testutil.RequireTypeAssert[*filter.ResultModifiedResponse](t, gotRes)
+			assert.Equal(t, filter.IDQueryType, modRes.List)
+			assert.Equal(t, tc.wantRule, modRes.Rule)
+
+			opt := resp.IsEdns0()
+			require.NotNil(t, opt)
+			require.NotEmpty(t, opt.Option)
+
+			ede := testutil.RequireTypeAssert[*dns.EDNS0_EDE](t, opt.Option[0])
+			assert.Equal(t, tc.wantEDE, ede.InfoCode)
+		})
+	}
+}
@@ -33,6 +33,9 @@ type RequestMetrics = struct {
 	// if any.
 	FilterListID string
 
+	// Categories are the content categories of the requested host, if any.
+	Categories []string
+
 	// FilteringDuration is the total amount of time spent filtering the query.
 	FilteringDuration time.Duration
 
@@ -12,22 +12,30 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
 	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
 	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/reqlog"
 	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/miekg/dns"
 )
 
 // recordQueryInfo extracts loggable information from request, response, and
-// filtering data and writes them to the query log, billing, and filtering-rule
-// statistics, handling non-critical errors.
+// filtering data and writes them to the query log, billing, per-device, and
+// filtering-rule statistics, handling non-critical errors.
 func (mw *Middleware) recordQueryInfo(
 	ctx context.Context,
 	fctx *filteringContext,
 	ri *agd.RequestInfo,
 ) {
 	id, text, blocked := filteringData(fctx)
+
+	learned := id == filter.IDNone && fctx.learnedResult != nil
+	if learned {
+		id, text = fctx.learnedResult.MatchedRule()
+	}
+
 	mw.ruleStat.Collect(ctx, id, text)
 
 	prof, dev := ri.DeviceData()
@@ -46,6 +54,7 @@ func (mw *Middleware) recordQueryInfo(
 	reqInfo := dnsserver.MustRequestInfoFromContext(ctx)
 	start := reqInfo.StartTime
 	mw.billStat.Record(ctx, devID, reqCtry, reqASN, start, ri.Proto)
+	mw.deviceStat.Record(ctx, devID, start, id, blocked, learned)
 
 	if !prof.QueryLogEnabled {
 		return
@@ -69,6 +78,8 @@ func (mw *Middleware) recordQueryInfo(
 	e := &querylog.Entry{
 		RequestResult:   fctx.requestResult,
 		ResponseResult:  fctx.responseResult,
+		LearnedResult:   fctx.learnedResult,
+		Categories:      ri.Categories,
 		Time:            start,
 		RequestID:       ri.ID,
 		ProfileID:       prof.ID,
@@ -81,6 +92,7 @@ func (mw *Middleware) recordQueryInfo(
 		RequestType:     ri.QType,
 		ResponseCode:    rcode,
 		Protocol:        ri.Proto,
+		ECSMode:         ri.ECSMode(),
 		DNSSEC:          respDNSSEC,
 		RemoteIP:        clientIP,
 	}
@@ -238,3 +250,59 @@ func (mw *Middleware) country(ctx context.Context, host string, ip netip.Addr) (
 
 	return geoip.CountryNone
 }
+
+// newTrace returns a new context with a new per-request debug log trace, if
+// the request should be logged.  Otherwise, it returns parent and nil.
+func (mw *Middleware) newTrace(
+	parent context.Context,
+	ri *agd.RequestInfo,
+) (ctx context.Context, t *reqlog.Trace) {
+	if !mw.reqLog.ShouldLog(parent, ri) {
+		return parent, nil
+	}
+
+	t = &reqlog.Trace{}
+
+	return reqlog.ContextWithTrace(parent, t), t
+}
+
+// logRequest writes the request into the per-request debug log, if t is not
+// nil.  err is the error that has happened while processing the request, if
+// any.
+func (mw *Middleware) logRequest(
+	ctx context.Context,
+	fctx *filteringContext,
+	ri *agd.RequestInfo,
+	t *reqlog.Trace,
+	err error,
+) {
+	if t == nil {
+		return
+	}
+
+	e := &reqlog.Entry{
+		RequestResult:     fctx.requestResult,
+		ResponseResult:    fctx.responseResult,
+		Error:             err,
+		Host:              ri.Host,
+		Upstream:          t.Upstream,
+		FilteringDuration: fctx.elapsed,
+		UpstreamDuration:  t.UpstreamDuration,
+		Elapsed:           dnsserver.MustRequestInfoFromContext(ctx).Elapsed(),
+		RequestID:         ri.ID,
+		RemoteIP:          ri.RemoteIP,
+		QType:             ri.QType,
+		RCode:             dns.RcodeServerFailure,
+		Protocol:          ri.Proto,
+	}
+
+	if resp := fctx.filteredResponse; resp != nil {
+		e.RCode = dnsmsg.RCode(resp.Rcode)
+	}
+
+	if p, d := ri.DeviceData(); p != nil {
+		e.ProfileID, e.DeviceID = p.ID, d.ID
+	}
+
+	mw.reqLog.Log(ctx, e)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/billstat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
@@ -157,11 +158,12 @@ func TestMiddleware_recordQueryInfo_respCtry(t *testing.T) {
 			}
 
 			mw := &Middleware{
-				logger:   slogutil.NewDiscardLogger(),
-				billStat: billstat.EmptyRecorder{},
-				geoIP:    geoIP,
-				queryLog: queryLog,
-				ruleStat: rulestat.Empty{},
+				logger:     slogutil.NewDiscardLogger(),
+				billStat:   billstat.EmptyRecorder{},
+				deviceStat: devicestat.Empty{},
+				geoIP:      geoIP,
+				queryLog:   queryLog,
+				ruleStat:   rulestat.Empty{},
 			}
 
 			ctx := dnsserver.ContextWithRequestInfo(context.Background(), &dnsserver.RequestInfo{
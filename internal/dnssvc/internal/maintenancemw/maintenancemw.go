@@ -0,0 +1,87 @@
+// Package maintenancemw contains the outermost middleware of AdGuard DNS,
+// which refuses all queries to a server group while it is in the maintenance
+// mode.
+package maintenancemw
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/maintenance"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// Config is the configuration structure for the maintenance middleware.  All
+// fields must be non-empty.
+type Config struct {
+	// Logger is used to log the operation of the middleware.
+	Logger *slog.Logger
+
+	// Messages is used to construct the REFUSED responses.
+	Messages *dnsmsg.Constructor
+
+	// Manager is used to get the maintenance-mode state of the server group.
+	Manager *maintenance.Manager
+
+	// ServerGroup is the name of the server group of the wrapped handler.
+	ServerGroup agd.ServerGroupName
+}
+
+// Middleware refuses all queries while the server group is in the maintenance
+// mode.  It must be the outermost middleware.
+type Middleware struct {
+	logger   *slog.Logger
+	messages *dnsmsg.Constructor
+	manager  *maintenance.Manager
+	group    agd.ServerGroupName
+}
+
+// New returns a new properly initialized *Middleware.  c must not be nil and
+// must be valid.
+func New(c *Config) (mw *Middleware) {
+	return &Middleware{
+		logger:   c.Logger,
+		messages: c.Messages,
+		manager:  c.Manager,
+		group:    c.ServerGroup,
+	}
+}
+
+// type check
+var _ dnsserver.Middleware = (*Middleware)(nil)
+
+// Wrap implements the [dnsserver.Middleware] interface for *Middleware.
+func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
+	f := func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (err error) {
+		retryAfter, enabled := mw.manager.Status(mw.group)
+		if !enabled {
+			return next.ServeDNS(ctx, rw, req)
+		}
+
+		optslog.Debug1(ctx, mw.logger, "refusing request", "server_group", mw.group)
+
+		resp := mw.messages.NewRespNotReady(req, retryAfterText(retryAfter))
+		err = rw.WriteMsg(ctx, req, resp)
+
+		return errors.Annotate(err, "maintenance mw: writing resp: %w")
+	}
+
+	return dnsserver.HandlerFunc(f)
+}
+
+// retryAfterText returns the extra text of the EDE.  If d is positive, it
+// advises the clients to retry after d.
+func retryAfterText(d time.Duration) (text string) {
+	if d <= 0 {
+		return "maintenance"
+	}
+
+	return fmt.Sprintf("maintenance; retry after %d seconds", int64(d.Seconds()))
+}
@@ -0,0 +1,95 @@
+package maintenancemw_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/maintenancemw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/maintenance"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// testServerGroup is the name of the server group for tests.
+const testServerGroup agd.ServerGroupName = "test_server_group"
+
+func TestMiddleware_Wrap(t *testing.T) {
+	t.Parallel()
+
+	manager := maintenance.NewManager(map[agd.ServerGroupName]*maintenance.GroupConfig{
+		testServerGroup: {
+			RetryAfter: 30 * time.Second,
+			Enabled:    false,
+		},
+	})
+
+	mw := maintenancemw.New(&maintenancemw.Config{
+		Logger:      slogutil.NewDiscardLogger(),
+		Messages:    agdtest.NewConstructor(t),
+		Manager:     manager,
+		ServerGroup: testServerGroup,
+	})
+
+	var nextCalled bool
+	next := dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		nextCalled = true
+
+		return rw.WriteMsg(ctx, req, (&dns.Msg{}).SetReply(req))
+	})
+
+	h := mw.Wrap(next)
+	req := dnsservertest.NewReq(
+		dnssvctest.DomainFQDN,
+		dns.TypeA,
+		dns.ClassINET,
+		dnsservertest.SectionExtra{dnsservertest.NewOPT(true, dns.MaxMsgSize)},
+	)
+
+	serve := func(t *testing.T) (resp *dns.Msg) {
+		t.Helper()
+
+		rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+		err := h.ServeDNS(testutil.ContextWithTimeout(t, testTimeout), rw, req)
+		require.NoError(t, err)
+
+		resp = rw.Msg()
+		require.NotNil(t, resp)
+
+		return resp
+	}
+
+	resp := serve(t)
+	assert.True(t, nextCalled)
+	assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+
+	require.NoError(t, manager.SetEnabled(testServerGroup, true))
+
+	nextCalled = false
+	resp = serve(t)
+	assert.False(t, nextCalled)
+	assert.Equal(t, dns.RcodeRefused, resp.Rcode)
+
+	opt := resp.IsEdns0()
+	require.NotNil(t, opt)
+	require.Len(t, opt.Option, 1)
+
+	ede := testutil.RequireTypeAssert[*dns.EDNS0_EDE](t, opt.Option[0])
+	assert.Equal(t, dns.ExtendedErrorCodeNotReady, ede.InfoCode)
+	assert.Equal(t, "maintenance; retry after 30 seconds", ede.ExtraText)
+}
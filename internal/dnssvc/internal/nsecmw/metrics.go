@@ -0,0 +1,28 @@
+package nsecmw
+
+import "context"
+
+// Metrics is an interface for monitoring the [nsecmw.Middleware] state.
+type Metrics interface {
+	// OnLookup is called when an eligible query has been checked against the
+	// cached denial-of-existence records.  hit is true if a negative response
+	// has been synthesized from them.
+	OnLookup(ctx context.Context, hit bool)
+
+	// OnStore is called when an NXDOMAIN response from the upstream has been
+	// processed.  stored is true if its denial-of-existence records have been
+	// cached.
+	OnStore(ctx context.Context, stored bool)
+}
+
+// EmptyMetrics is an empty [Metrics] implementation that does nothing.
+type EmptyMetrics struct{}
+
+// type check
+var _ Metrics = EmptyMetrics{}
+
+// OnLookup implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) OnLookup(_ context.Context, _ bool) {}
+
+// OnStore implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) OnStore(_ context.Context, _ bool) {}
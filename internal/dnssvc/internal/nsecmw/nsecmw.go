@@ -0,0 +1,218 @@
+// Package nsecmw contains the middleware that implements the aggressive use of
+// the DNSSEC-validated cache as defined in RFC 8198.  It caches the NSEC and
+// NSEC3 records of the validated negative responses and uses them to synthesize
+// NXDOMAIN responses for the names these records cover.
+package nsecmw
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// cacheID is the identifier of the zone cache for the cache manager.
+const cacheID = "dns/nsec"
+
+// Config is the configuration structure for the aggressive NSEC middleware.
+type Config struct {
+	// Logger is used to log the operation of the middleware.  It must not be
+	// nil.
+	Logger *slog.Logger
+
+	// CacheManager is the global cache manager.  It must not be nil.
+	CacheManager agdcache.Manager
+
+	// Clock is used to check the expiry of the cached records.  It must not be
+	// nil.
+	Clock agdtime.Clock
+
+	// Messages is used to construct the synthesized responses.  It must not be
+	// nil.
+	Messages *dnsmsg.Constructor
+
+	// Metrics is used to collect the statistics.  It must not be nil.
+	Metrics Metrics
+
+	// Count is the maximum number of zones the denial-of-existence records of
+	// which are cached.  It must be positive.
+	Count int
+}
+
+// Middleware synthesizes NXDOMAIN responses from the cached NSEC and NSEC3
+// records for the server groups with [agd.ServerGroup.AggressiveNSECEnabled]
+// set.
+//
+// Only the records from the responses with the AD bit set by the upstream are
+// cached, since the middleware doesn't validate the signatures itself.  Thus,
+// it must only be used if all upstreams are validating resolvers reached over
+// trusted network paths, since a single spoofed response with the AD bit set
+// would make it deny a whole range of names.  The records must also be signed
+// by and belong to the zone of the SOA record of the response, which protects
+// the cache from records injected by the other zones.
+type Middleware struct {
+	logger   *slog.Logger
+	clock    agdtime.Clock
+	messages *dnsmsg.Constructor
+	metrics  Metrics
+	zones    *agdcache.LRU[string, *zone]
+}
+
+// New returns a new aggressive NSEC middleware.  It also adds the cache to the
+// cache manager.  c must not be nil.
+func New(c *Config) (mw *Middleware) {
+	zones := agdcache.NewLRU[string, *zone](&agdcache.LRUConfig{
+		Count: c.Count,
+	})
+	c.CacheManager.Add(cacheID, zones)
+
+	return &Middleware{
+		logger:   c.Logger,
+		clock:    c.Clock,
+		messages: c.Messages,
+		metrics:  c.Metrics,
+		zones:    zones,
+	}
+}
+
+// type check
+var _ dnsserver.Middleware = (*Middleware)(nil)
+
+// Wrap implements the [dnsserver.Middleware] interface for *Middleware.
+func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
+	f := func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (err error) {
+		defer func() { err = errors.Annotate(err, "nsecmw: %w") }()
+
+		ri := agd.MustRequestInfoFromContext(ctx)
+		if !ri.ServerGroup.AggressiveNSECEnabled || !isEligible(req) {
+			return next.ServeDNS(ctx, rw, req)
+		}
+
+		now := mw.clock.Now()
+		qName := strings.ToLower(req.Question[0].Name)
+		resp := mw.synthesize(req, qName, now)
+		mw.metrics.OnLookup(ctx, resp != nil)
+		if resp != nil {
+			optslog.Debug1(ctx, mw.logger, "synthesized nxdomain", "qname", qName)
+
+			err = rw.WriteMsg(ctx, req, resp)
+			if err != nil {
+				return fmt.Errorf("writing synthesized response: %w", err)
+			}
+
+			return nil
+		}
+
+		nwrw := internal.MakeNonWriter(rw)
+		err = next.ServeDNS(ctx, nwrw, req)
+		if err != nil {
+			// Don't wrap the error, because this is the main flow, and there is
+			// already errors.Annotate here.
+			return err
+		}
+
+		resp = nwrw.Msg()
+		if resp == nil {
+			return nil
+		}
+
+		if resp.Rcode == dns.RcodeNameError {
+			mw.metrics.OnStore(ctx, mw.store(ctx, qName, resp, now))
+		}
+
+		err = rw.WriteMsg(ctx, req, resp)
+		if err != nil {
+			return fmt.Errorf("writing response: %w", err)
+		}
+
+		return nil
+	}
+
+	return dnsserver.HandlerFunc(f)
+}
+
+// isEligible returns true if the negative response to req may be synthesized
+// from the cached records.  Requests with the CD bit set must not be answered
+// from the cache, see RFC 8198, Section 5.1.
+func isEligible(req *dns.Msg) (ok bool) {
+	return req.Opcode == dns.OpcodeQuery &&
+		!req.CheckingDisabled &&
+		len(req.Question) == 1 &&
+		req.Question[0].Qclass == dns.ClassINET
+}
+
+// findZone returns the cached zone with the longest name that is a parent of or
+// equal to qName.  z is nil if there is no such zone.
+func (mw *Middleware) findZone(qName string) (z *zone) {
+	for off, end := 0, false; !end; off, end = dns.NextLabel(qName, off) {
+		var ok bool
+		z, ok = mw.zones.Get(qName[off:])
+		if ok {
+			return z
+		}
+	}
+
+	z, _ = mw.zones.Get(".")
+
+	return z
+}
+
+// synthesize returns the NXDOMAIN response to req if the cached records prove
+// that qName doesn't exist.  Otherwise, resp is nil.
+func (mw *Middleware) synthesize(req *dns.Msg, qName string, now time.Time) (resp *dns.Msg) {
+	z := mw.findZone(qName)
+	if z == nil {
+		return nil
+	}
+
+	proof := z.prove(qName, now)
+	if proof == nil {
+		return nil
+	}
+
+	var do bool
+	if opt := req.IsEdns0(); opt != nil {
+		do = opt.Do()
+	}
+
+	resp = mw.messages.NewResp(req)
+	resp.Rcode = dns.RcodeNameError
+
+	// See RFC 6840, Section 5.8.
+	resp.AuthenticatedData = do || req.AuthenticatedData
+
+	resp.Ns = appendRRs(resp.Ns, now, z.soaExpiry, z.soa)
+	if do {
+		resp.Ns = appendRRs(resp.Ns, now, z.soaExpiry, z.soaSigs...)
+		for _, d := range proof {
+			resp.Ns = appendRRs(resp.Ns, now, d.expiry, d.rr)
+			resp.Ns = appendRRs(resp.Ns, now, d.expiry, d.sigs...)
+		}
+	}
+
+	return resp
+}
+
+// appendRRs appends the copies of rrs with the TTLs set to the time remaining
+// until expiry to orig.
+func appendRRs(orig []dns.RR, now, expiry time.Time, rrs ...dns.RR) (res []dns.RR) {
+	ttl := uint32(expiry.Sub(now) / time.Second)
+	for _, rr := range rrs {
+		rr = dns.Copy(rr)
+		rr.Header().Ttl = ttl
+		orig = append(orig, rr)
+	}
+
+	return orig
+}
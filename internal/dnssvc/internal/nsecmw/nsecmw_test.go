@@ -0,0 +1,476 @@
+package nsecmw_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/nsecmw"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testZone is the name of the signed zone for tests.
+const testZone = "example.com."
+
+// Common domain names for tests.
+const (
+	testFirstName  = "c.example.com."
+	testSecondName = "c2.example.com."
+)
+
+// testNow is the current time for tests.
+var testNow = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newSOA returns the SOA record of [testZone] with the given negative TTL.
+func newSOA(minTTL uint32) (soa *dns.SOA) {
+	return &dns.SOA{
+		Hdr: dns.RR_Header{
+			Name:   testZone,
+			Rrtype: dns.TypeSOA,
+			Class:  dns.ClassINET,
+			Ttl:    3600,
+		},
+		Ns:      "ns." + testZone,
+		Mbox:    "hostmaster." + testZone,
+		Serial:  1,
+		Refresh: 3600,
+		Retry:   600,
+		Expire:  86400,
+		Minttl:  minTTL,
+	}
+}
+
+// newRRSIG returns an RRSIG record for the RRset with the given owner and type
+// that is valid at [testNow].
+func newRRSIG(owner string, covered uint16, signer string) (sig *dns.RRSIG) {
+	return &dns.RRSIG{
+		Hdr: dns.RR_Header{
+			Name:   owner,
+			Rrtype: dns.TypeRRSIG,
+			Class:  dns.ClassINET,
+			Ttl:    3600,
+		},
+		TypeCovered: covered,
+		Algorithm:   dns.ECDSAP256SHA256,
+		Labels:      uint8(dns.CountLabel(owner)),
+		OrigTtl:     3600,
+		Expiration:  uint32(testNow.Add(time.Hour).Unix()),
+		Inception:   uint32(testNow.Add(-time.Hour).Unix()),
+		KeyTag:      1234,
+		SignerName:  signer,
+		Signature:   "AAAA",
+	}
+}
+
+// newNSEC returns an NSEC record with the given owner name, next domain name,
+// and types.
+func newNSEC(owner, next string, types ...uint16) (nsec *dns.NSEC) {
+	return &dns.NSEC{
+		Hdr: dns.RR_Header{
+			Name:   owner,
+			Rrtype: dns.TypeNSEC,
+			Class:  dns.ClassINET,
+			Ttl:    3600,
+		},
+		NextDomain: next,
+		TypeBitMap: append(types, dns.TypeNSEC, dns.TypeRRSIG),
+	}
+}
+
+// newNSEC3Chain returns the NSEC3 records of [testZone] that prove that only
+// the names in existing exist, along with their signatures.
+func newNSEC3Chain(iterations uint16, flags uint8, existing ...string) (rrs []dns.RR) {
+	hashes := make([]string, 0, len(existing))
+	for _, name := range existing {
+		hashes = append(hashes, dns.HashName(name, dns.SHA1, iterations, ""))
+	}
+
+	slices.Sort(hashes)
+
+	for i, h := range hashes {
+		owner := strings.ToLower(h) + "." + testZone
+		rrs = append(rrs, &dns.NSEC3{
+			Hdr: dns.RR_Header{
+				Name:   owner,
+				Rrtype: dns.TypeNSEC3,
+				Class:  dns.ClassINET,
+				Ttl:    3600,
+			},
+			Hash:       dns.SHA1,
+			Flags:      flags,
+			Iterations: iterations,
+			SaltLength: 0,
+			Salt:       "",
+			HashLength: 20,
+			NextDomain: hashes[(i+1)%len(hashes)],
+			TypeBitMap: []uint16{dns.TypeA, dns.TypeRRSIG},
+		}, newRRSIG(owner, dns.TypeNSEC3, testZone))
+	}
+
+	return rrs
+}
+
+// newTestHandler returns a new handler wrapped into the middleware as well as
+// a pointer to the number of upstream queries.  ns is the authority section of
+// the upstream NXDOMAIN responses.  clock is used as the clock of the
+// middleware.
+func newTestHandler(
+	tb testing.TB,
+	clock *agdtest.Clock,
+	ad bool,
+	ns ...dns.RR,
+) (h dnsserver.Handler, upstreamNum *int) {
+	tb.Helper()
+
+	upstreamNum = new(int)
+	upstream := dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		*upstreamNum++
+
+		resp := (&dns.Msg{}).SetRcode(req, dns.RcodeNameError)
+		resp.AuthenticatedData = ad
+		for _, rr := range ns {
+			resp.Ns = append(resp.Ns, dns.Copy(rr))
+		}
+
+		return rw.WriteMsg(ctx, req, resp)
+	})
+
+	mw := nsecmw.New(&nsecmw.Config{
+		Logger:       slogutil.NewDiscardLogger(),
+		CacheManager: agdcache.EmptyManager{},
+		Clock:        clock,
+		Messages:     agdtest.NewConstructor(tb),
+		Metrics:      nsecmw.EmptyMetrics{},
+		Count:        100,
+	})
+
+	return mw.Wrap(upstream), upstreamNum
+}
+
+// serve sends a query for name to h and returns the response.  srvGrp is used
+// as the server group of the request.
+func serve(
+	tb testing.TB,
+	h dnsserver.Handler,
+	srvGrp *agd.ServerGroup,
+	name string,
+	cd bool,
+) (resp *dns.Msg) {
+	tb.Helper()
+
+	ctx := testutil.ContextWithTimeout(tb, dnssvctest.Timeout)
+	ctx = agd.ContextWithRequestInfo(ctx, &agd.RequestInfo{
+		ServerGroup: srvGrp,
+	})
+
+	req := dnsservertest.NewReq(name, dns.TypeA, dns.ClassINET)
+	req.CheckingDisabled = cd
+	req.SetEdns0(dns.DefaultMsgSize, true)
+
+	rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+	err := h.ServeDNS(ctx, rw, req)
+	require.NoError(tb, err)
+
+	resp = rw.Msg()
+	require.NotNil(tb, resp)
+
+	return resp
+}
+
+func TestMiddleware_Wrap_nsec(t *testing.T) {
+	t.Parallel()
+
+	soa := newSOA(300)
+	soaSig := newRRSIG(testZone, dns.TypeSOA, testZone)
+
+	cover := newNSEC("b."+testZone, "d."+testZone, dns.TypeA)
+	coverSig := newRRSIG(cover.Hdr.Name, dns.TypeNSEC, testZone)
+
+	apex := newNSEC(testZone, "a."+testZone, dns.TypeSOA, dns.TypeNS)
+	apexSig := newRRSIG(testZone, dns.TypeNSEC, testZone)
+
+	wrongSig := newRRSIG(cover.Hdr.Name, dns.TypeNSEC, "com.")
+	delegation := newNSEC("b."+testZone, "d."+testZone, dns.TypeNS)
+	emptyNonTerminal := newNSEC("b."+testZone, "x."+testSecondName, dns.TypeA)
+	outOfZone := newNSEC("b."+testZone, "d.example.org.", dns.TypeA)
+
+	validNS := []dns.RR{soa, soaSig, cover, coverSig, apex, apexSig}
+
+	testCases := []struct {
+		name            string
+		secondName      string
+		ns              []dns.RR
+		elapsed         time.Duration
+		wantUpstreamNum int
+		ad              bool
+		disabled        bool
+		cd              bool
+	}{{
+		name:            "synthesized",
+		secondName:      testSecondName,
+		ns:              validNS,
+		elapsed:         time.Minute,
+		wantUpstreamNum: 1,
+		ad:              true,
+		disabled:        false,
+		cd:              false,
+	}, {
+		name:            "not_authenticated",
+		secondName:      testSecondName,
+		ns:              validNS,
+		elapsed:         time.Minute,
+		wantUpstreamNum: 2,
+		ad:              false,
+		disabled:        false,
+		cd:              false,
+	}, {
+		name:            "disabled",
+		secondName:      testSecondName,
+		ns:              validNS,
+		elapsed:         time.Minute,
+		wantUpstreamNum: 2,
+		ad:              true,
+		disabled:        true,
+		cd:              false,
+	}, {
+		name:            "checking_disabled",
+		secondName:      testSecondName,
+		ns:              validNS,
+		elapsed:         time.Minute,
+		wantUpstreamNum: 2,
+		ad:              true,
+		disabled:        false,
+		cd:              true,
+	}, {
+		name:            "expired",
+		secondName:      testSecondName,
+		ns:              validNS,
+		elapsed:         10 * time.Minute,
+		wantUpstreamNum: 2,
+		ad:              true,
+		disabled:        false,
+		cd:              false,
+	}, {
+		name:            "not_covered",
+		secondName:      "e." + testZone,
+		ns:              validNS,
+		elapsed:         time.Minute,
+		wantUpstreamNum: 2,
+		ad:              true,
+		disabled:        false,
+		cd:              false,
+	}, {
+		name:            "exists",
+		secondName:      cover.Hdr.Name,
+		ns:              validNS,
+		elapsed:         time.Minute,
+		wantUpstreamNum: 2,
+		ad:              true,
+		disabled:        false,
+		cd:              false,
+	}, {
+		name:            "wrong_signer",
+		secondName:      testSecondName,
+		ns:              []dns.RR{soa, soaSig, cover, wrongSig, apex, apexSig},
+		elapsed:         time.Minute,
+		wantUpstreamNum: 2,
+		ad:              true,
+		disabled:        false,
+		cd:              false,
+	}, {
+		name:            "unsigned_soa",
+		secondName:      testSecondName,
+		ns:              []dns.RR{soa, cover, coverSig, apex, apexSig},
+		elapsed:         time.Minute,
+		wantUpstreamNum: 2,
+		ad:              true,
+		disabled:        false,
+		cd:              false,
+	}, {
+		name:            "out_of_zone",
+		secondName:      testSecondName,
+		ns:              []dns.RR{soa, soaSig, outOfZone, coverSig, apex, apexSig},
+		elapsed:         time.Minute,
+		wantUpstreamNum: 2,
+		ad:              true,
+		disabled:        false,
+		cd:              false,
+	}, {
+		name:            "no_wildcard_proof",
+		secondName:      testSecondName,
+		ns:              []dns.RR{soa, soaSig, cover, coverSig},
+		elapsed:         time.Minute,
+		wantUpstreamNum: 2,
+		ad:              true,
+		disabled:        false,
+		cd:              false,
+	}, {
+		name:            "delegation",
+		secondName:      "x.b." + testZone,
+		ns:              []dns.RR{soa, soaSig, delegation, coverSig, apex, apexSig},
+		elapsed:         time.Minute,
+		wantUpstreamNum: 2,
+		ad:              true,
+		disabled:        false,
+		cd:              false,
+	}, {
+		name:            "empty_non_terminal",
+		secondName:      testSecondName,
+		ns:              []dns.RR{soa, soaSig, emptyNonTerminal, coverSig, apex, apexSig},
+		elapsed:         time.Minute,
+		wantUpstreamNum: 2,
+		ad:              true,
+		disabled:        false,
+		cd:              false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			now := testNow
+			clock := &agdtest.Clock{
+				OnNow: func() (n time.Time) { return now },
+			}
+
+			h, upstreamNum := newTestHandler(t, clock, tc.ad, tc.ns...)
+			srvGrp := &agd.ServerGroup{
+				AggressiveNSECEnabled: !tc.disabled,
+			}
+
+			resp := serve(t, h, srvGrp, testFirstName, false)
+			require.Equal(t, dns.RcodeNameError, resp.Rcode)
+
+			now = now.Add(tc.elapsed)
+
+			resp = serve(t, h, srvGrp, tc.secondName, tc.cd)
+			assert.Equal(t, dns.RcodeNameError, resp.Rcode)
+			assert.Equal(t, tc.wantUpstreamNum, *upstreamNum)
+		})
+	}
+}
+
+func TestMiddleware_Wrap_synthesized(t *testing.T) {
+	t.Parallel()
+
+	const minTTL = 300
+
+	now := testNow
+	clock := &agdtest.Clock{
+		OnNow: func() (n time.Time) { return now },
+	}
+
+	cover := newNSEC("b."+testZone, "d."+testZone, dns.TypeA)
+	apex := newNSEC(testZone, "a."+testZone, dns.TypeSOA, dns.TypeNS)
+
+	h, _ := newTestHandler(
+		t,
+		clock,
+		true,
+		newSOA(minTTL),
+		newRRSIG(testZone, dns.TypeSOA, testZone),
+		cover,
+		newRRSIG(cover.Hdr.Name, dns.TypeNSEC, testZone),
+		apex,
+		newRRSIG(testZone, dns.TypeNSEC, testZone),
+	)
+
+	srvGrp := &agd.ServerGroup{
+		AggressiveNSECEnabled: true,
+	}
+
+	_ = serve(t, h, srvGrp, testFirstName, false)
+
+	const elapsed = 100 * time.Second
+	now = now.Add(elapsed)
+
+	resp := serve(t, h, srvGrp, testSecondName, false)
+	require.Equal(t, dns.RcodeNameError, resp.Rcode)
+
+	assert.True(t, resp.AuthenticatedData)
+	assert.Empty(t, resp.Answer)
+
+	var types []uint16
+	for _, rr := range resp.Ns {
+		types = append(types, rr.Header().Rrtype)
+		assert.Equal(t, uint32(minTTL-elapsed/time.Second), rr.Header().Ttl)
+	}
+
+	assert.Equal(t, []uint16{
+		dns.TypeSOA,
+		dns.TypeRRSIG,
+		dns.TypeNSEC,
+		dns.TypeRRSIG,
+		dns.TypeNSEC,
+		dns.TypeRRSIG,
+	}, types)
+}
+
+func TestMiddleware_Wrap_nsec3(t *testing.T) {
+	t.Parallel()
+
+	soa := newSOA(300)
+	soaSig := newRRSIG(testZone, dns.TypeSOA, testZone)
+
+	existing := []string{testZone, "a." + testZone, "z." + testZone}
+
+	testCases := []struct {
+		name            string
+		ns              []dns.RR
+		wantUpstreamNum int
+	}{{
+		name:            "synthesized",
+		ns:              append([]dns.RR{soa, soaSig}, newNSEC3Chain(0, 0, existing...)...),
+		wantUpstreamNum: 1,
+	}, {
+		name:            "opt_out",
+		ns:              append([]dns.RR{soa, soaSig}, newNSEC3Chain(0, 1, existing...)...),
+		wantUpstreamNum: 2,
+	}, {
+		name:            "too_many_iterations",
+		ns:              append([]dns.RR{soa, soaSig}, newNSEC3Chain(500, 0, existing...)...),
+		wantUpstreamNum: 2,
+	}, {
+		name:            "no_closest_encloser",
+		ns:              append([]dns.RR{soa, soaSig}, newNSEC3Chain(0, 0, "a."+testZone)...),
+		wantUpstreamNum: 2,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			clock := &agdtest.Clock{
+				OnNow: func() (n time.Time) { return testNow },
+			}
+
+			h, upstreamNum := newTestHandler(t, clock, true, tc.ns...)
+			srvGrp := &agd.ServerGroup{
+				AggressiveNSECEnabled: true,
+			}
+
+			resp := serve(t, h, srvGrp, testFirstName, false)
+			require.Equal(t, dns.RcodeNameError, resp.Rcode)
+
+			resp = serve(t, h, srvGrp, testSecondName, false)
+			assert.Equal(t, dns.RcodeNameError, resp.Rcode)
+			assert.Equal(t, tc.wantUpstreamNum, *upstreamNum)
+		})
+	}
+}
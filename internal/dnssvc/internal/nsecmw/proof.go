@@ -0,0 +1,209 @@
+package nsecmw
+
+import (
+	"bytes"
+	"cmp"
+	"slices"
+
+	"github.com/miekg/dns"
+)
+
+// proveNSEC returns the NSEC records from denials that prove that qName doesn't
+// exist.  proof is nil if there are no such records.  See RFC 4035, Section
+// 5.4.
+func proveNSEC(denials []*denial, qName string) (proof []*denial) {
+	nameCover := findNSECCover(denials, qName)
+
+	// If the next domain name is below qName, qName is an empty non-terminal,
+	// which exists.
+	if nameCover == nil || dns.IsSubDomain(qName, nameCover.end) {
+		return nil
+	}
+
+	closestEncloser := ancestor(qName, max(
+		dns.CompareDomainName(qName, nameCover.start),
+		dns.CompareDomainName(qName, nameCover.end),
+	))
+
+	wildcardCover := findNSECCover(denials, wildcard(closestEncloser))
+	if wildcardCover == nil {
+		return nil
+	}
+
+	return appendUnique(proof, nameCover, wildcardCover)
+}
+
+// findNSECCover returns the NSEC record from denials that covers name.  d is
+// nil if there is no such record or if name exists.
+func findNSECCover(denials []*denial, name string) (d *denial) {
+	for _, n := range denials {
+		switch {
+		case n.start == name:
+			return nil
+		case n.isDelegation && dns.IsSubDomain(n.start, name):
+			// The names below a delegation point belong to another zone.
+			continue
+		case d == nil && nsecCovers(n, name):
+			d = n
+		}
+	}
+
+	return d
+}
+
+// nsecCovers returns true if name is between the owner name and the next domain
+// name of the NSEC record d in the canonical order.
+func nsecCovers(d *denial, name string) (ok bool) {
+	if compareNames(d.start, d.end) < 0 {
+		return compareNames(d.start, name) < 0 && compareNames(name, d.end) < 0
+	}
+
+	// This is the last NSEC record of the zone, the next domain name of which
+	// is the apex of the zone.
+	return compareNames(d.start, name) < 0 || compareNames(name, d.end) < 0
+}
+
+// proveNSEC3 returns the NSEC3 records from denials that prove that qName
+// doesn't exist in the zone with the lowercased name zoneName.  params is used
+// to hash the names.  proof is nil if there are no such records.  See RFC 5155,
+// Section 8.4.
+func proveNSEC3(denials []*denial, params *dns.NSEC3, qName, zoneName string) (proof []*denial) {
+	hash := func(name string) (h string) {
+		return dns.HashName(name, params.Hash, params.Iterations, params.Salt)
+	}
+
+	name, nextCloser := qName, ""
+	closestEncloser := findNSEC3Match(denials, hash(name))
+	for closestEncloser == nil {
+		if name == zoneName {
+			return nil
+		}
+
+		name, nextCloser = parent(name), name
+		closestEncloser = findNSEC3Match(denials, hash(name))
+	}
+
+	if nextCloser == "" || closestEncloser.isDelegation {
+		return nil
+	}
+
+	// An Opt-Out record only proves that there is no secure delegation.
+	nextCloserCover := findNSEC3Cover(denials, hash(nextCloser))
+	if nextCloserCover == nil || nextCloserCover.isOptOut() {
+		return nil
+	}
+
+	wildcardHash := hash(wildcard(name))
+	if findNSEC3Match(denials, wildcardHash) != nil {
+		return nil
+	}
+
+	wildcardCover := findNSEC3Cover(denials, wildcardHash)
+	if wildcardCover == nil {
+		return nil
+	}
+
+	return appendUnique(proof, closestEncloser, nextCloserCover, wildcardCover)
+}
+
+// findNSEC3Match returns the NSEC3 record from denials the owner hash of which
+// is h.  d is nil if there is no such record.
+func findNSEC3Match(denials []*denial, h string) (d *denial) {
+	i := slices.IndexFunc(denials, func(n *denial) (ok bool) { return n.start == h })
+	if i < 0 {
+		return nil
+	}
+
+	return denials[i]
+}
+
+// findNSEC3Cover returns the NSEC3 record from denials that covers the hash h.
+// d is nil if there is no such record.
+func findNSEC3Cover(denials []*denial, h string) (d *denial) {
+	i := slices.IndexFunc(denials, func(n *denial) (ok bool) {
+		switch {
+		case n.start == n.end:
+			// The only NSEC3 record in the zone.
+			return h != n.start
+		case n.start < n.end:
+			return n.start < h && h < n.end
+		default:
+			// The last NSEC3 record in the hash order.
+			return h > n.start || h < n.end
+		}
+	})
+	if i < 0 {
+		return nil
+	}
+
+	return denials[i]
+}
+
+// appendUnique appends the records from ds that aren't in orig yet to orig.
+func appendUnique(orig []*denial, ds ...*denial) (res []*denial) {
+	for _, d := range ds {
+		if !slices.Contains(orig, d) {
+			orig = append(orig, d)
+		}
+	}
+
+	return orig
+}
+
+// ancestor returns the ancestor of the fully-qualified domain name name which
+// has n labels.  n must not be greater than the number of labels in name.
+func ancestor(name string, n int) (a string) {
+	if n == 0 {
+		return "."
+	}
+
+	idx := dns.Split(name)
+
+	return name[idx[len(idx)-n]:]
+}
+
+// compareNames compares the fully-qualified domain names a and b using the
+// canonical DNS name order.  See RFC 4034, Section 6.1.
+func compareNames(a, b string) (res int) {
+	aLabels, bLabels := dns.SplitDomainName(a), dns.SplitDomainName(b)
+	for i, j := len(aLabels)-1, len(bLabels)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		res = bytes.Compare(canonicalLabel(aLabels[i]), canonicalLabel(bLabels[j]))
+		if res != 0 {
+			return res
+		}
+	}
+
+	return cmp.Compare(len(aLabels), len(bLabels))
+}
+
+// canonicalLabel returns the wire-format contents of the presentation-format
+// label l with the uppercase US-ASCII letters converted to lowercase.
+func canonicalLabel(l string) (b []byte) {
+	b = make([]byte, 0, len(l))
+	for i := 0; i < len(l); i++ {
+		c := l[i]
+		if c == '\\' && i+1 < len(l) {
+			if i+3 < len(l) && isDigit(l[i+1]) && isDigit(l[i+2]) && isDigit(l[i+3]) {
+				n := int(l[i+1]-'0')*100 + int(l[i+2]-'0')*10 + int(l[i+3]-'0')
+				c = byte(n)
+				i += 3
+			} else {
+				i++
+				c = l[i]
+			}
+		}
+
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+
+		b = append(b, c)
+	}
+
+	return b
+}
+
+// isDigit returns true if c is an ASCII digit.
+func isDigit(c byte) (ok bool) {
+	return '0' <= c && c <= '9'
+}
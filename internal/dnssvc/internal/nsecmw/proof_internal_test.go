@@ -0,0 +1,37 @@
+package nsecmw
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareNames(t *testing.T) {
+	t.Parallel()
+
+	// The names in the canonical order, see RFC 4034, Section 6.1.
+	names := []string{
+		"example.",
+		"a.example.",
+		"yljkjljk.a.example.",
+		"Z.a.example.",
+		`zABC.a.EXAMPLE.`,
+		"z.example.",
+		`\001.z.example.`,
+		"*.z.example.",
+		`\200.z.example.`,
+	}
+
+	for i, a := range names {
+		for j, b := range names {
+			want := 0
+			if i < j {
+				want = -1
+			} else if i > j {
+				want = 1
+			}
+
+			assert.Equalf(t, want, compareNames(a, b), "comparing %q and %q", a, b)
+		}
+	}
+}
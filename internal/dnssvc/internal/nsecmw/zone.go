@@ -0,0 +1,364 @@
+package nsecmw
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/miekg/dns"
+)
+
+// maxDenials is the maximum number of denial-of-existence records cached for a
+// single zone.
+const maxDenials = 256
+
+// maxNSEC3Iterations is the maximum number of additional NSEC3 hash iterations
+// of the cacheable records.  See RFC 9276, Section 3.2.
+const maxNSEC3Iterations = 100
+
+// Errors returned by [newZone].
+const (
+	errInconsistent     errors.Error = "inconsistent denial records"
+	errNoDenials        errors.Error = "no denial records"
+	errNotAuthenticated errors.Error = "response is not authenticated"
+	errOutOfZone        errors.Error = "out of zone"
+	errUnsigned         errors.Error = "no valid signatures"
+	errUnsupported      errors.Error = "unsupported nsec3 parameters"
+)
+
+// store caches the denial-of-existence records of the NXDOMAIN response resp
+// to a query for qName.  stored is false if resp hasn't passed the checks.
+func (mw *Middleware) store(
+	ctx context.Context,
+	qName string,
+	resp *dns.Msg,
+	now time.Time,
+) (stored bool) {
+	z, err := newZone(qName, resp, now)
+	if err != nil {
+		optslog.Debug2(
+			ctx,
+			mw.logger,
+			"not caching denial records",
+			"qname", qName,
+			slogutil.KeyError, err,
+		)
+
+		return false
+	}
+
+	old, ok := mw.zones.Get(z.name)
+	if ok {
+		z.merge(old, now)
+	}
+
+	mw.zones.Set(z.name, z)
+
+	return true
+}
+
+// zone is the cached denial-of-existence data of a single DNSSEC-signed zone.
+// It is never modified after it has been added to the cache.
+type zone struct {
+	// soaExpiry is the time when the SOA record and, consequently, the whole
+	// zone data expire.
+	soaExpiry time.Time
+
+	// soa is the SOA record of the zone.  It is never nil.
+	soa *dns.SOA
+
+	// name is the lowercased, fully-qualified name of the zone.
+	name string
+
+	// soaSigs are the RRSIG records of soa.  It is never empty.
+	soaSigs []dns.RR
+
+	// denials are the NSEC or NSEC3 records of the zone, the newest ones
+	// first.  All records are of the same type and, in case of NSEC3, have the
+	// same hashing parameters.  It is never empty.
+	denials []*denial
+}
+
+// newZone returns the zone data from the NXDOMAIN response resp to a query for
+// qName.  err is not nil if resp isn't safe to cache.
+func newZone(qName string, resp *dns.Msg, now time.Time) (z *zone, err error) {
+	if !resp.AuthenticatedData {
+		return nil, errNotAuthenticated
+	}
+
+	soa, err := findSOA(resp.Ns)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	name := strings.ToLower(soa.Hdr.Name)
+	if !dns.IsSubDomain(name, qName) {
+		return nil, fmt.Errorf("soa %q: %w", name, errOutOfZone)
+	}
+
+	sigs := zoneSigs(resp.Ns, name, now)
+
+	// See RFC 8198, Section 5.4.
+	negTTL := time.Duration(min(soa.Hdr.Ttl, soa.Minttl)) * time.Second
+	z = &zone{
+		soaExpiry: now.Add(negTTL),
+		soa:       dns.Copy(soa).(*dns.SOA),
+		name:      name,
+		soaSigs:   sigs[sigKey{owner: name, typ: dns.TypeSOA}],
+	}
+
+	if len(z.soaSigs) == 0 {
+		return nil, fmt.Errorf("soa: %w", errUnsigned)
+	}
+
+	for _, rr := range resp.Ns {
+		var d *denial
+		d, err = newDenial(rr, name)
+		if err != nil {
+			// Don't wrap the error, because it's informative enough as is.
+			return nil, err
+		} else if d == nil {
+			continue
+		}
+
+		typ := rr.Header().Rrtype
+		d.sigs = sigs[sigKey{owner: d.owner, typ: typ}]
+		if len(d.sigs) == 0 {
+			return nil, fmt.Errorf("%s %q: %w", dns.TypeToString[typ], d.owner, errUnsigned)
+		}
+
+		d.expiry = now.Add(min(time.Duration(rr.Header().Ttl)*time.Second, negTTL))
+
+		if len(z.denials) > 0 && !z.denials[0].isCompatible(d) {
+			return nil, errInconsistent
+		}
+
+		z.denials = append(z.denials, d)
+	}
+
+	if len(z.denials) == 0 {
+		return nil, errNoDenials
+	}
+
+	return z, nil
+}
+
+// findSOA returns the only SOA record in rrs.
+func findSOA(rrs []dns.RR) (soa *dns.SOA, err error) {
+	for _, rr := range rrs {
+		s, ok := rr.(*dns.SOA)
+		if !ok {
+			continue
+		} else if soa != nil {
+			return nil, fmt.Errorf("soa: %w", errors.ErrDuplicated)
+		}
+
+		soa = s
+	}
+
+	if soa == nil {
+		return nil, fmt.Errorf("soa: %w", errors.ErrNoValue)
+	}
+
+	return soa, nil
+}
+
+// sigKey is the key of the map of RRSIG records.
+type sigKey struct {
+	// owner is the lowercased owner name of the signed RRset.
+	owner string
+
+	// typ is the type of the signed RRset.
+	typ uint16
+}
+
+// zoneSigs returns the copies of the RRSIG records in rrs, which have been made
+// by the zone with the lowercased name zoneName and which are valid at now.
+func zoneSigs(rrs []dns.RR, zoneName string, now time.Time) (sigs map[sigKey][]dns.RR) {
+	sigs = map[sigKey][]dns.RR{}
+	for _, rr := range rrs {
+		sig, ok := rr.(*dns.RRSIG)
+		if !ok || !strings.EqualFold(sig.SignerName, zoneName) || !sig.ValidityPeriod(now) {
+			continue
+		}
+
+		k := sigKey{
+			owner: strings.ToLower(sig.Hdr.Name),
+			typ:   sig.TypeCovered,
+		}
+
+		sigs[k] = append(sigs[k], dns.Copy(sig))
+	}
+
+	return sigs
+}
+
+// merge adds the unexpired records of old, which aren't superseded by the
+// records of z, to z.  z must not have been added to the cache yet.
+func (z *zone) merge(old *zone, now time.Time) {
+	ref := z.denials[0]
+	for _, d := range old.denials {
+		if len(z.denials) >= maxDenials {
+			break
+		}
+
+		if !d.expiry.After(now) || !ref.isCompatible(d) {
+			continue
+		}
+
+		if !slices.ContainsFunc(z.denials, func(n *denial) (ok bool) { return n.owner == d.owner }) {
+			z.denials = append(z.denials, d)
+		}
+	}
+}
+
+// prove returns the records that prove that qName doesn't exist in z.  proof
+// is nil if the cached records are not enough to prove that.
+func (z *zone) prove(qName string, now time.Time) (proof []*denial) {
+	if !z.soaExpiry.After(now) {
+		return nil
+	}
+
+	denials := make([]*denial, 0, len(z.denials))
+	for _, d := range z.denials {
+		if d.expiry.After(now) {
+			denials = append(denials, d)
+		}
+	}
+
+	if len(denials) == 0 {
+		return nil
+	}
+
+	if nsec3, ok := denials[0].rr.(*dns.NSEC3); ok {
+		return proveNSEC3(denials, nsec3, qName, z.name)
+	}
+
+	return proveNSEC(denials, qName)
+}
+
+// denial is a cached NSEC or NSEC3 record.
+type denial struct {
+	// expiry is the time when the record expires.
+	expiry time.Time
+
+	// rr is the copy of the NSEC or NSEC3 record.
+	rr dns.RR
+
+	// owner is the lowercased owner name of rr.
+	owner string
+
+	// start and end are the bounds of the interval covered by rr.  These are
+	// the lowercased owner and next domain names for NSEC and the uppercased
+	// hashes of those for NSEC3.
+	start string
+	end   string
+
+	// sigs are the RRSIG records of rr.
+	sigs []dns.RR
+
+	// isDelegation is true if rr is the record of a delegation point or of a
+	// DNAME record, in which case it doesn't prove anything about the names
+	// below it.  See RFC 8198, Section 5.1.
+	isDelegation bool
+}
+
+// newDenial returns a denial for rr if it is an NSEC or NSEC3 record of the
+// zone with the lowercased name zoneName.  d is nil if rr is of any other type.
+func newDenial(rr dns.RR, zoneName string) (d *denial, err error) {
+	owner := strings.ToLower(rr.Header().Name)
+	switch rr := rr.(type) {
+	case *dns.NSEC:
+		next := strings.ToLower(rr.NextDomain)
+		if !dns.IsSubDomain(zoneName, owner) || !dns.IsSubDomain(zoneName, next) {
+			return nil, fmt.Errorf("nsec %q: %w", owner, errOutOfZone)
+		}
+
+		return &denial{
+			rr:           dns.Copy(rr),
+			owner:        owner,
+			start:        owner,
+			end:          next,
+			isDelegation: isDelegation(rr.TypeBitMap),
+		}, nil
+	case *dns.NSEC3:
+		hashEnd, _ := dns.NextLabel(owner, 0)
+		if hashEnd == 0 || parent(owner) != zoneName {
+			return nil, fmt.Errorf("nsec3 %q: %w", owner, errOutOfZone)
+		} else if rr.Hash != dns.SHA1 || rr.Iterations > maxNSEC3Iterations {
+			return nil, fmt.Errorf("nsec3 %q: %w", owner, errUnsupported)
+		}
+
+		return &denial{
+			rr:           dns.Copy(rr),
+			owner:        owner,
+			start:        strings.ToUpper(owner[:hashEnd-1]),
+			end:          strings.ToUpper(rr.NextDomain),
+			isDelegation: isDelegation(rr.TypeBitMap),
+		}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// isDelegation returns true if bitmap is the type bitmap of a delegation point
+// or of a name with a DNAME record.
+func isDelegation(bitmap []uint16) (ok bool) {
+	if slices.Contains(bitmap, dns.TypeDNAME) {
+		return true
+	}
+
+	return slices.Contains(bitmap, dns.TypeNS) && !slices.Contains(bitmap, dns.TypeSOA)
+}
+
+// isCompatible returns true if other can be used together with d to prove the
+// nonexistence of a name.
+func (d *denial) isCompatible(other *denial) (ok bool) {
+	if reflect.TypeOf(d.rr) != reflect.TypeOf(other.rr) {
+		return false
+	}
+
+	nsec3, ok := d.rr.(*dns.NSEC3)
+	if !ok {
+		return true
+	}
+
+	otherNSEC3 := other.rr.(*dns.NSEC3)
+
+	return nsec3.Hash == otherNSEC3.Hash &&
+		nsec3.Iterations == otherNSEC3.Iterations &&
+		strings.EqualFold(nsec3.Salt, otherNSEC3.Salt)
+}
+
+// isOptOut returns true if d is an NSEC3 record with the Opt-Out flag set.
+func (d *denial) isOptOut() (ok bool) {
+	nsec3, ok := d.rr.(*dns.NSEC3)
+
+	return ok && nsec3.Flags&1 != 0
+}
+
+// parent returns the parent domain of the fully-qualified domain name name.
+func parent(name string) (p string) {
+	off, end := dns.NextLabel(name, 0)
+	if end {
+		return "."
+	}
+
+	return name[off:]
+}
+
+// wildcard returns the wildcard name for the fully-qualified domain name name.
+func wildcard(name string) (wc string) {
+	if name == "." {
+		return "*."
+	}
+
+	return "*." + name
+}
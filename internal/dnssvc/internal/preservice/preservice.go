@@ -12,6 +12,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/hashprefix"
 	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
@@ -106,11 +107,11 @@ func (mw *Middleware) respondWithHashes(
 	if err != nil {
 		// Don't return or collect this error to prevent DDoS of the error
 		// collector by sending bad requests.
-		mw.logger.ErrorContext(
-			ctx,
-			"matching hashes",
-			slogutil.KeyError, err,
-		)
+		if errors.Is(err, hashprefix.ErrCategoryLookupLimited) {
+			optslog.Debug1(ctx, mw.logger, "matching hashes", slogutil.KeyError, err)
+		} else {
+			mw.logger.ErrorContext(ctx, "matching hashes", slogutil.KeyError, err)
+		}
 
 		resp := mw.messages.NewRespRCode(req, dns.RcodeRefused)
 		err = rw.WriteMsg(ctx, req, resp)
@@ -0,0 +1,232 @@
+// Package ptrmw contains the middleware that synthesizes authoritative PTR
+// responses for the IP ranges owned by the operator, such as the pools of
+// linked and dedicated IP addresses, instead of forwarding them upstream.
+package ptrmw
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/netutil"
+	"github.com/miekg/dns"
+)
+
+// Template placeholders.
+const (
+	// PlaceholderIP is replaced with the IP address from the query, with all
+	// dots and colons replaced with hyphens.  IPv6 addresses are expanded.
+	PlaceholderIP = "${IP}"
+
+	// PlaceholderDeviceName is replaced with the name of the device converted
+	// into a valid hostname label.
+	PlaceholderDeviceName = "${DEVICE_NAME}"
+)
+
+// Zone is a single IP range for which PTR responses are synthesized.
+type Zone struct {
+	// Prefix is the IP range for which the responses are synthesized.  It
+	// must be valid and masked.
+	Prefix netip.Prefix
+
+	// Template is the template of the domain name used in the responses.  It
+	// must not be empty and may contain [PlaceholderIP].
+	Template string
+
+	// DeviceTemplate is the optional template of the domain name used in the
+	// responses when the IP address belongs to a device of the profile that
+	// makes the query.  If not empty, it must contain [PlaceholderDeviceName]
+	// and may contain [PlaceholderIP].
+	DeviceTemplate string
+
+	// TTL is the TTL of the synthesized records.  It must not be negative.
+	TTL time.Duration
+}
+
+// Config is the configuration structure for the PTR middleware.  All fields
+// must be non-nil.
+type Config struct {
+	// Logger is used to log the operation of the middleware.
+	Logger *slog.Logger
+
+	// Messages is used to construct PTR responses.
+	Messages *dnsmsg.Constructor
+
+	// ProfileDB is used to find the devices by their dedicated and linked IP
+	// addresses.  It is only used when the query is made by a device with a
+	// profile.
+	ProfileDB profiledb.Interface
+
+	// Zones are the IP ranges for which the responses are synthesized.  Items
+	// must not be nil.  If several zones contain the same address, the first
+	// one is used.
+	Zones []*Zone
+}
+
+// Middleware synthesizes PTR responses for the configured zones.
+type Middleware struct {
+	logger    *slog.Logger
+	messages  *dnsmsg.Constructor
+	profileDB profiledb.Interface
+	zones     []*Zone
+}
+
+// New returns a new PTR middleware.  c must not be nil.
+func New(c *Config) (mw *Middleware) {
+	return &Middleware{
+		logger:    c.Logger,
+		messages:  c.Messages,
+		profileDB: c.ProfileDB,
+		zones:     c.Zones,
+	}
+}
+
+// type check
+var _ dnsserver.Middleware = (*Middleware)(nil)
+
+// Wrap implements the [dnsserver.Middleware] interface for *Middleware.
+func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
+	f := func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (err error) {
+		defer func() { err = errors.Annotate(err, "ptrmw: %w") }()
+
+		ri := agd.MustRequestInfoFromContext(ctx)
+		if ri.QType != dns.TypePTR {
+			// Don't wrap the error, because this is the main flow, and there is
+			// already [errors.Annotate] here.
+			return next.ServeDNS(ctx, rw, req)
+		}
+
+		ip, err := netutil.IPFromReversedAddr(ri.Host)
+		if err != nil {
+			// Not a full reversed address, perhaps a query for a zone
+			// delegation.  Let the upstream handle it.
+			return next.ServeDNS(ctx, rw, req)
+		}
+
+		z := mw.zoneFor(ip)
+		if z == nil {
+			return next.ServeDNS(ctx, rw, req)
+		}
+
+		name := mw.ptrName(ctx, ri, z, ip)
+		optslog.Debug2(ctx, mw.logger, "synthesizing ptr", "ip", ip, "name", name)
+
+		resp := mw.messages.NewResp(req)
+		resp.Authoritative = true
+
+		ptr := mw.messages.NewAnswerPTR(req, name)
+		ptr.Hdr.Ttl = uint32(z.TTL.Seconds())
+		resp.Answer = append(resp.Answer, ptr)
+
+		err = rw.WriteMsg(ctx, req, resp)
+		if err != nil {
+			return fmt.Errorf("writing ptr response: %w", err)
+		}
+
+		return nil
+	}
+
+	return dnsserver.HandlerFunc(f)
+}
+
+// zoneFor returns the first zone containing ip or nil if there is none.
+func (mw *Middleware) zoneFor(ip netip.Addr) (z *Zone) {
+	for _, z = range mw.zones {
+		if z.Prefix.Contains(ip) {
+			return z
+		}
+	}
+
+	return nil
+}
+
+// ptrName returns the domain name for the PTR record for ip in z.  The device
+// template is only used when the device with ip belongs to the profile that
+// makes the query, so that the device names are never disclosed to other
+// users.
+func (mw *Middleware) ptrName(
+	ctx context.Context,
+	ri *agd.RequestInfo,
+	z *Zone,
+	ip netip.Addr,
+) (name string) {
+	ipLabel := ipToLabel(ip)
+	name = strings.ReplaceAll(z.Template, PlaceholderIP, ipLabel)
+	if z.DeviceTemplate == "" {
+		return name
+	}
+
+	reqProf, _ := ri.DeviceData()
+	if reqProf == nil {
+		return name
+	}
+
+	devLabel := mw.deviceLabel(ctx, reqProf.ID, ip)
+	if devLabel == "" {
+		return name
+	}
+
+	name = strings.ReplaceAll(z.DeviceTemplate, PlaceholderIP, ipLabel)
+
+	return strings.ReplaceAll(name, PlaceholderDeviceName, devLabel)
+}
+
+// deviceLabel returns the hostname label created from the name of the device
+// with the dedicated or linked IP address ip, if it belongs to the profile with
+// the given ID.  Otherwise, it returns an empty string.
+func (mw *Middleware) deviceLabel(
+	ctx context.Context,
+	profID agd.ProfileID,
+	ip netip.Addr,
+) (label string) {
+	p, d, err := mw.profileDB.ProfileByDedicatedIP(ctx, ip)
+	if err != nil {
+		p, d, err = mw.profileDB.ProfileByLinkedIP(ctx, ip)
+	}
+
+	if err != nil || p.ID != profID {
+		return ""
+	}
+
+	return nameToLabel(string(d.Name))
+}
+
+// ipToLabel returns a hostname label created from ip.
+func ipToLabel(ip netip.Addr) (label string) {
+	if ip.Is4() {
+		return strings.ReplaceAll(ip.String(), ".", "-")
+	}
+
+	return strings.ReplaceAll(ip.StringExpanded(), ":", "-")
+}
+
+// nameToLabel converts an arbitrary device name into a valid hostname label.
+// It returns an empty string if there are no suitable characters in name.
+func nameToLabel(name string) (label string) {
+	b := &strings.Builder{}
+	lastHyphen := true
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			_, _ = b.WriteRune(r)
+			lastHyphen = false
+		} else if !lastHyphen {
+			_ = b.WriteByte('-')
+			lastHyphen = true
+		}
+
+		if b.Len() >= netutil.MaxDomainLabelLen {
+			break
+		}
+	}
+
+	return strings.Trim(b.String(), "-")
+}
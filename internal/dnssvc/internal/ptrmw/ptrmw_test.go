@@ -0,0 +1,170 @@
+package ptrmw_test
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/ptrmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_Wrap(t *testing.T) {
+	t.Parallel()
+
+	const ttl = 3600
+
+	var (
+		devIP   = netip.MustParseAddr("192.0.2.1")
+		otherIP = netip.MustParseAddr("192.0.2.2")
+		outIP   = netip.MustParseAddr("198.51.100.1")
+		v6IP    = netip.MustParseAddr("2001:db8::1")
+	)
+
+	prof := &agd.Profile{
+		ID: dnssvctest.ProfileID,
+	}
+	dev := &agd.Device{
+		ID:   dnssvctest.DeviceID,
+		Name: "My Laptop (Work)",
+	}
+	otherProf := &agd.Profile{
+		ID: "prof5678",
+	}
+
+	db := &agdtest.ProfileDB{
+		OnProfileByDedicatedIP: func(
+			_ context.Context,
+			ip netip.Addr,
+		) (p *agd.Profile, d *agd.Device, err error) {
+			if ip == devIP {
+				return prof, dev, nil
+			}
+
+			return nil, nil, profiledb.ErrDeviceNotFound
+		},
+		OnProfileByLinkedIP: func(
+			_ context.Context,
+			_ netip.Addr,
+		) (p *agd.Profile, d *agd.Device, err error) {
+			return nil, nil, profiledb.ErrDeviceNotFound
+		},
+	}
+
+	mw := ptrmw.New(&ptrmw.Config{
+		Logger:    slogutil.NewDiscardLogger(),
+		Messages:  agdtest.NewConstructor(t),
+		ProfileDB: db,
+		Zones: []*ptrmw.Zone{{
+			Prefix:         netip.MustParsePrefix("192.0.2.0/24"),
+			Template:       "ip-${IP}.dedicated.example",
+			DeviceTemplate: "${DEVICE_NAME}.dedicated.example",
+			TTL:            ttl * time.Second,
+		}, {
+			Prefix:   netip.MustParsePrefix("2001:db8::/32"),
+			Template: "${IP}.v6.example",
+			TTL:      ttl * time.Second,
+		}},
+	})
+
+	h := mw.Wrap(dnsservertest.NewDefaultHandler())
+
+	// Set the context necessary for [dnsservertest.DefaultHandler].
+	ctx := context.Background()
+	ctx = dnsserver.ContextWithRequestInfo(ctx, &dnsserver.RequestInfo{
+		StartTime: time.Now(),
+	})
+	ctx = dnsserver.ContextWithServerInfo(ctx, &dnsserver.ServerInfo{})
+
+	testCases := []struct {
+		reqProf  *agd.Profile
+		ip       netip.Addr
+		name     string
+		wantName string
+	}{{
+		reqProf:  prof,
+		ip:       devIP,
+		name:     "own_device",
+		wantName: "my-laptop-work.dedicated.example.",
+	}, {
+		reqProf:  otherProf,
+		ip:       devIP,
+		name:     "other_profile",
+		wantName: "ip-192-0-2-1.dedicated.example.",
+	}, {
+		reqProf:  nil,
+		ip:       devIP,
+		name:     "no_profile",
+		wantName: "ip-192-0-2-1.dedicated.example.",
+	}, {
+		reqProf:  prof,
+		ip:       otherIP,
+		name:     "no_device",
+		wantName: "ip-192-0-2-2.dedicated.example.",
+	}, {
+		reqProf:  nil,
+		ip:       v6IP,
+		name:     "ipv6",
+		wantName: "2001-0db8-0000-0000-0000-0000-0000-0001.v6.example.",
+	}, {
+		reqProf:  nil,
+		ip:       outIP,
+		name:     "not_in_zone",
+		wantName: "",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			arpa, err := dns.ReverseAddr(tc.ip.String())
+			require.NoError(t, err)
+
+			ri := &agd.RequestInfo{
+				Host:  arpa[:len(arpa)-1],
+				QType: dns.TypePTR,
+			}
+			if tc.reqProf != nil {
+				ri.DeviceResult = &agd.DeviceResultOK{
+					Device:  &agd.Device{},
+					Profile: tc.reqProf,
+				}
+			}
+
+			req := dnsservertest.NewReq(arpa, dns.TypePTR, dns.ClassINET)
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+
+			tctx := agd.ContextWithRequestInfo(ctx, ri)
+
+			err = h.ServeDNS(tctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			if tc.wantName == "" {
+				assert.False(t, resp.Authoritative)
+
+				return
+			}
+
+			assert.True(t, resp.Authoritative)
+			require.Len(t, resp.Answer, 1)
+
+			ptr := testutil.RequireTypeAssert[*dns.PTR](t, resp.Answer[0])
+			assert.Equal(t, tc.wantName, ptr.Ptr)
+			assert.Equal(t, uint32(ttl), ptr.Hdr.Ttl)
+		})
+	}
+}
@@ -20,16 +20,23 @@ func (mw *Middleware) isBlockedByAccess(
 	// NOTE:  Global access has priority over the profile one.
 	if mw.accessManager.IsBlockedIP(raddr.Addr()) {
 		mw.metrics.IncrementAccessBlockedBySubnet(ctx)
-		optslog.Debug1(ctx, mw.logger, "access denied globally by ip", "remote_ip", ri.RemoteIP)
+		optslog.Debug2(
+			ctx,
+			mw.logger,
+			"access denied globally by ip",
+			"remote_ip", ri.RemoteIP,
+			"client_key", ri.ClientKey,
+		)
 
 		return true
 	} else if mw.accessManager.IsBlockedHost(ri.Host, ri.QType) {
 		mw.metrics.IncrementAccessBlockedByHost(ctx)
-		optslog.Debug2(
+		optslog.Debug3(
 			ctx,
 			mw.logger,
 			"access denied globally by rule",
 			"remote_ip", ri.RemoteIP,
+			"client_key", ri.ClientKey,
 			"host", ri.Host,
 		)
 
@@ -43,11 +50,12 @@ func (mw *Middleware) isBlockedByAccess(
 
 	if p.Access.IsBlocked(req, raddr, ri.Location) {
 		mw.metrics.IncrementAccessBlockedByProfile(ctx)
-		optslog.Debug2(
+		optslog.Debug3(
 			ctx,
 			mw.logger,
 			"access denied by profile",
 			"remote_ip", ri.RemoteIP,
+			"client_key", ri.ClientKey,
 			"profile_id", p.ID,
 		)
 
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/access"
+	"github.com/AdguardTeam/AdGuardDNS/internal/accountlimit"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
@@ -16,6 +17,8 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/ratelimitmw"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnamelimit"
+	"github.com/AdguardTeam/AdGuardDNS/internal/sharedcounter"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/testutil"
@@ -52,6 +55,7 @@ func TestMiddleware_Wrap_access(t *testing.T) {
 			errors.Must(blockedClient1IP.Prefix(blockedClient1IP.BitLen())),
 			blockedClient2Prefix,
 		},
+		nil,
 	)
 	require.NoError(t, accessErr)
 
@@ -62,6 +66,7 @@ func TestMiddleware_Wrap_access(t *testing.T) {
 
 	rlMw := ratelimitmw.New(&ratelimitmw.Config{
 		Logger:         slogutil.NewDiscardLogger(),
+		ClientKeys:     agd.NewClientKeyHasher(nil),
 		Messages:       agdtest.NewConstructor(t),
 		FilteringGroup: &agd.FilteringGroup{},
 		ServerGroup:    &agd.ServerGroup{},
@@ -71,15 +76,18 @@ func TestMiddleware_Wrap_access(t *testing.T) {
 		},
 		StructuredErrors: agdtest.NewSDEConfig(true),
 		AccessManager:    accessMgr,
+		AccountLimiter:   accountlimit.Empty{},
 		DeviceFinder: &agdtest.DeviceFinder{
 			OnFind: func(_ context.Context, _ *dns.Msg, _, _ netip.AddrPort) (r agd.DeviceResult) {
 				return nil
 			},
 		},
-		ErrColl: agdtest.NewErrorCollector(),
-		GeoIP:   geoIP,
-		Metrics: ratelimitmw.EmptyMetrics{},
-		Limiter: agdtest.NewRateLimit(),
+		ErrColl:       agdtest.NewErrorCollector(),
+		GeoIP:         geoIP,
+		Metrics:       ratelimitmw.EmptyMetrics{},
+		Limiter:       agdtest.NewRateLimit(),
+		QNAMELimiter:  qnamelimit.Empty{},
+		SharedCounter: sharedcounter.Empty{},
 		Protocols: []agd.Protocol{
 			agd.ProtoDNS,
 		},
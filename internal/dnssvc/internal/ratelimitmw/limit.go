@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"slices"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/accountlimit"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
@@ -49,7 +50,14 @@ func (mw *Middleware) serveWithGlobalRatelimiting(
 		return fmt.Errorf("checking global ratelimit: %w", err)
 	} else if shouldDrop {
 		mw.metrics.OnRateLimited(ctx, req, rw)
-		optslog.Debug1(ctx, mw.logger, "ratelimited globally", "remote_ip", ri.RemoteIP)
+		optslog.Debug3(
+			ctx,
+			mw.logger,
+			"ratelimited globally",
+			"remote_ip", ri.RemoteIP,
+			"client_key", ri.ClientKey,
+			"tls_fingerprint", ri.TLSFingerprint,
+		)
 
 		return nil
 	} else if isAllowlisted {
@@ -88,21 +96,41 @@ func (mw *Middleware) serveWithProfileRatelimiting(
 	ri *agd.RequestInfo,
 	next dnsserver.Handler,
 ) (shouldReturn bool, err error) {
-	prof, _ := ri.DeviceData()
+	prof, dev := ri.DeviceData()
 	if prof == nil {
 		return false, nil
 	}
 
+	if accRes := mw.accountLimiter.Check(ctx, prof, dev); accRes != accountlimit.ResultPass {
+		optslog.Debug4(
+			ctx,
+			mw.logger,
+			"ratelimited by account",
+			"remote_ip", ri.RemoteIP,
+			"profile_id", prof.ID,
+			"account_id", prof.AccountID,
+			"result", accRes,
+		)
+
+		return true, nil
+	}
+
 	res := prof.Ratelimiter.Check(ctx, req, ri.RemoteIP)
+	if res == agd.RatelimitResultPass && mw.isSharedLimitExceeded(ctx, prof) {
+		res = agd.RatelimitResultDrop
+	}
+
 	switch res {
 	case agd.RatelimitResultDrop:
 		mw.metrics.IncrementRatelimitedByProfile(ctx)
-		optslog.Debug2(
+		optslog.Debug4(
 			ctx,
 			mw.logger,
 			"ratelimited by profile",
 			"remote_ip", ri.RemoteIP,
+			"client_key", ri.ClientKey,
 			"profile_id", prof.ID,
+			"tls_fingerprint", ri.TLSFingerprint,
 		)
 
 		return true, nil
@@ -138,3 +166,11 @@ func (mw *Middleware) serveWithProfileRatelimiting(
 
 	return true, nil
 }
+
+// isSharedLimitExceeded returns true if the number of requests of the profile
+// across all nodes exceeds its custom limit.  prof must not be nil.
+func (mw *Middleware) isSharedLimitExceeded(ctx context.Context, prof *agd.Profile) (ok bool) {
+	total := mw.sharedCounter.Add(ctx, prof.ID)
+
+	return total > uint64(prof.Ratelimiter.Config().RPS)
+}
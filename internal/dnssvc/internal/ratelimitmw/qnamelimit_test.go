@@ -0,0 +1,117 @@
+package ratelimitmw_test
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/access"
+	"github.com/AdguardTeam/AdGuardDNS/internal/accountlimit"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/ratelimitmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/sharedcounter"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_Wrap_qnameLimit(t *testing.T) {
+	const hostLimited = "random." + dnssvctest.DomainBlocked
+
+	geoIP := agdtest.NewGeoIP()
+	geoIP.OnData = func(_ string, _ netip.Addr) (l *geoip.Location, err error) {
+		return nil, nil
+	}
+
+	var gotKey agd.ClientKey
+	limiter := &agdtest.QNAMELimiter{
+		OnIsLimited: func(_ context.Context, key agd.ClientKey, host string) (limited bool) {
+			gotKey = key
+
+			return host == hostLimited
+		},
+	}
+
+	accessMgr, err := access.NewGlobal(nil, nil, nil)
+	require.NoError(t, err)
+
+	rlMw := ratelimitmw.New(&ratelimitmw.Config{
+		Logger:         slogutil.NewDiscardLogger(),
+		ClientKeys:     agd.NewClientKeyHasher(nil),
+		Messages:       agdtest.NewConstructor(t),
+		FilteringGroup: &agd.FilteringGroup{},
+		ServerGroup:    &agd.ServerGroup{},
+		Server: &agd.Server{
+			// Use a DoT server to prevent ratelimiting.
+			Protocol: agd.ProtoDoT,
+		},
+		StructuredErrors: agdtest.NewSDEConfig(true),
+		AccessManager:    accessMgr,
+		AccountLimiter:   accountlimit.Empty{},
+		DeviceFinder: &agdtest.DeviceFinder{
+			OnFind: func(_ context.Context, _ *dns.Msg, _, _ netip.AddrPort) (r agd.DeviceResult) {
+				return nil
+			},
+		},
+		ErrColl:       agdtest.NewErrorCollector(),
+		GeoIP:         geoIP,
+		Metrics:       ratelimitmw.EmptyMetrics{},
+		Limiter:       agdtest.NewRateLimit(),
+		QNAMELimiter:  limiter,
+		SharedCounter: sharedcounter.Empty{},
+		Protocols: []agd.Protocol{
+			agd.ProtoDNS,
+		},
+		EDEEnabled: true,
+	})
+
+	handler := dnsserver.HandlerFunc(
+		func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (err error) {
+			return rw.WriteMsg(ctx, req, dnsservertest.NewResp(dns.RcodeSuccess, req))
+		},
+	)
+
+	testCases := []struct {
+		name      string
+		host      string
+		wantRCode int
+	}{{
+		name:      "pass",
+		host:      dnssvctest.DomainAllowed,
+		wantRCode: dns.RcodeSuccess,
+	}, {
+		name:      "limited",
+		host:      hostLimited,
+		wantRCode: dns.RcodeRefused,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotKey = agd.ClientKey{}
+
+			rw := dnsserver.NewNonWriterResponseWriter(nil, &net.TCPAddr{
+				IP:   net.IP{192, 0, 2, 1},
+				Port: 5357,
+			})
+			req := dnsservertest.NewReq(tc.host, dns.TypeA, dns.ClassINET)
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			err = rlMw.Wrap(handler).ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			assert.Equal(t, tc.wantRCode, resp.Rcode)
+			assert.NotZero(t, gotKey)
+		})
+	}
+}
@@ -6,9 +6,11 @@ package ratelimitmw
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/access"
+	"github.com/AdguardTeam/AdGuardDNS/internal/accountlimit"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
@@ -16,6 +18,8 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
 	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnamelimit"
+	"github.com/AdguardTeam/AdGuardDNS/internal/sharedcounter"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/netutil"
@@ -31,18 +35,22 @@ var _ dnsserver.Middleware = (*Middleware)(nil)
 // ratelimiting rules, it also finds the profile data and adds [agd.RequestInfo]
 // to the context.
 type Middleware struct {
-	logger        *slog.Logger
-	messages      *dnsmsg.Constructor
-	pool          *syncutil.Pool[agd.RequestInfo]
-	sdeConf       *dnsmsg.StructuredDNSErrorsConfig
-	accessManager access.Interface
-	deviceFinder  agd.DeviceFinder
-	errColl       errcoll.Interface
-	geoIP         geoip.Interface
-	limiter       ratelimit.Interface
-	metrics       Metrics
-	protos        []dnsserver.Protocol
-	edeEnabled    bool
+	logger         *slog.Logger
+	clientKeys     *agd.ClientKeyHasher
+	messages       *dnsmsg.Constructor
+	pool           *syncutil.Pool[agd.RequestInfo]
+	sdeConf        *dnsmsg.StructuredDNSErrorsConfig
+	accessManager  access.Interface
+	accountLimiter accountlimit.Interface
+	deviceFinder   agd.DeviceFinder
+	errColl        errcoll.Interface
+	geoIP          geoip.Interface
+	limiter        ratelimit.Interface
+	metrics        Metrics
+	qnameLimiter   qnamelimit.Interface
+	sharedCounter  sharedcounter.Interface
+	protos         []dnsserver.Protocol
+	edeEnabled     bool
 }
 
 // Config is the configuration structure for the access and ratelimiting
@@ -51,6 +59,9 @@ type Config struct {
 	// Logger is used to log the operation of the middleware.
 	Logger *slog.Logger
 
+	// ClientKeys is used to create the cross-protocol keys of the clients.
+	ClientKeys *agd.ClientKeyHasher
+
 	// Messages is used to build the responses specific for a request's context.
 	Messages *dnsmsg.Constructor
 
@@ -70,6 +81,10 @@ type Config struct {
 	// AccessManager is the global access manager.
 	AccessManager access.Interface
 
+	// AccountLimiter is used to enforce the aggregate limits of the accounts of
+	// the profiles.
+	AccountLimiter accountlimit.Interface
+
 	// DeviceFinder is used to set the device and profile for a request, if any.
 	DeviceFinder agd.DeviceFinder
 
@@ -85,6 +100,14 @@ type Config struct {
 	// Limiter defines whether the query should be dropped or not.
 	Limiter ratelimit.Interface
 
+	// QNAMELimiter is used to refuse the queries of the clients that query too
+	// many unique names under a single registrable domain.
+	QNAMELimiter qnamelimit.Interface
+
+	// SharedCounter is used to enforce the custom ratelimits of profiles
+	// across all nodes.
+	SharedCounter sharedcounter.Interface
+
 	// Protocols is a list of protocols this middleware applies ratelimiting
 	// logic to.  Protocols must not be changed after calling [New].
 	Protocols []agd.Protocol
@@ -97,8 +120,9 @@ type Config struct {
 // New returns a new access middleware.  c must not be nil.
 func New(c *Config) (mw *Middleware) {
 	return &Middleware{
-		logger:   c.Logger,
-		messages: c.Messages,
+		logger:     c.Logger,
+		clientKeys: c.ClientKeys,
+		messages:   c.Messages,
 		pool: syncutil.NewPool(func() (v *agd.RequestInfo) {
 			// Set the filtering-group and server information here immediately.
 			return &agd.RequestInfo{
@@ -108,15 +132,18 @@ func New(c *Config) (mw *Middleware) {
 				Proto:          c.Server.Protocol,
 			}
 		}),
-		sdeConf:       c.StructuredErrors,
-		accessManager: c.AccessManager,
-		deviceFinder:  c.DeviceFinder,
-		errColl:       c.ErrColl,
-		geoIP:         c.GeoIP,
-		limiter:       c.Limiter,
-		metrics:       c.Metrics,
-		protos:        c.Protocols,
-		edeEnabled:    c.EDEEnabled,
+		sdeConf:        c.StructuredErrors,
+		accessManager:  c.AccessManager,
+		accountLimiter: c.AccountLimiter,
+		deviceFinder:   c.DeviceFinder,
+		errColl:        c.ErrColl,
+		geoIP:          c.GeoIP,
+		limiter:        c.Limiter,
+		metrics:        c.Metrics,
+		qnameLimiter:   c.QNAMELimiter,
+		sharedCounter:  c.SharedCounter,
+		protos:         c.Protocols,
+		edeEnabled:     c.EDEEnabled,
 	}
 }
 
@@ -158,6 +185,12 @@ func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
 			return nil
 		}
 
+		if mw.qnameLimiter.IsLimited(ctx, ri.ClientKey, ri.Host) {
+			// Don't wrap the error, because this is the main flow, and there is
+			// already [errors.Annotate] here.
+			return mw.refuseQNAMELimited(ctx, rw, req, ri)
+		}
+
 		ctx = agd.ContextWithRequestInfo(ctx, ri)
 
 		// Don't wrap the error, because this is the main flow, and there is
@@ -168,6 +201,32 @@ func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
 	return dnsserver.HandlerFunc(f)
 }
 
+// refuseQNAMELimited writes a REFUSED response to the request of a client that
+// has exceeded the limit of unique names.
+func (mw *Middleware) refuseQNAMELimited(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+) (err error) {
+	optslog.Debug3(
+		ctx,
+		mw.logger,
+		"refused by qname limit",
+		"remote_ip", ri.RemoteIP,
+		"client_key", ri.ClientKey,
+		"host", ri.Host,
+	)
+
+	resp := mw.messages.NewRespRCode(req, dns.RcodeRefused)
+	err = rw.WriteMsg(ctx, req, resp)
+	if err != nil {
+		return fmt.Errorf("writing refused resp: %w", err)
+	}
+
+	return nil
+}
+
 // processLocationErr processes the error returned by [Middleware.location] and
 // returns the properly handled and/or wrapped error.
 func (mw *Middleware) processLocationErr(
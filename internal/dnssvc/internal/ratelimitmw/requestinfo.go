@@ -9,6 +9,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdnet"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
 	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
@@ -18,7 +19,8 @@ import (
 
 // newRequestInfo returns the new request information structure using the
 // middleware's configuration and values from ctx.  ri is never nil and should
-// be returned to the pool.
+// be returned to the pool.  If the server group randomizes the responses, the
+// message constructor of ri is randomized for each request.
 func (mw *Middleware) newRequestInfo(
 	ctx context.Context,
 	req *dns.Msg,
@@ -48,9 +50,12 @@ func (mw *Middleware) newRequestInfo(
 	// stack.
 	ri.ID, _ = agd.RequestIDFromContext(ctx)
 
+	ri.TLSFingerprint = tlsFingerprint(ctx)
+
 	// Add the profile information, if any.
 	localAddr := netutil.NetAddrToAddrPort(laddr)
 	ri.DeviceResult = mw.deviceFinder.Find(ctx, req, raddr, localAddr)
+	ri.ClientKey = mw.clientKey(ri)
 	if r, ok := ri.DeviceResult.(*agd.DeviceResultOK); ok {
 		p, cloner := r.Profile, mw.messages.Cloner()
 		messages, err := dnsmsg.NewConstructor(&dnsmsg.ConstructorConfig{
@@ -68,9 +73,34 @@ func (mw *Middleware) newRequestInfo(
 		}
 	}
 
+	if j := ri.ServerGroup.ResponseJitter; j != nil {
+		ri.Messages = ri.Messages.WithJitter(j)
+	}
+
 	return ri
 }
 
+// tlsFingerprint returns the JA4 fingerprint of the TLS client from the
+// request information of the server in ctx, if any.
+func tlsFingerprint(ctx context.Context) (ja4 string) {
+	srvReqInfo, ok := dnsserver.RequestInfoFromContext(ctx)
+	if !ok || srvReqInfo.TLSFingerprint == nil {
+		return ""
+	}
+
+	return srvReqInfo.TLSFingerprint.JA4
+}
+
+// clientKey returns the cross-protocol key of the client.  ri must have the
+// device result and the remote IP address set.
+func (mw *Middleware) clientKey(ri *agd.RequestInfo) (k agd.ClientKey) {
+	if p, d := ri.DeviceData(); p != nil {
+		return mw.clientKeys.ForDevice(p.ID, d.ID)
+	}
+
+	return mw.clientKeys.ForIP(ri.RemoteIP)
+}
+
 // location returns the GeoIP location information about the client's remote
 // address as well as the EDNS Client Subnet information, if there is one.  err
 // is not nil only if req contains a malformed EDNS Client Subnet option.
@@ -0,0 +1,27 @@
+package shadowmw
+
+import "context"
+
+// Metrics is an interface for monitoring the [shadowmw.Middleware] state.
+type Metrics interface {
+	// IncrementSkipped is called when a query isn't mirrored, because there
+	// are too many mirrored queries in flight.
+	IncrementSkipped(ctx context.Context)
+
+	// OnShadowed is called when the response of the secondary handler has
+	// been compared to the primary one.  divergence is one of the Divergence
+	// constants, such as [DivergenceNone].
+	OnShadowed(ctx context.Context, divergence string)
+}
+
+// EmptyMetrics is an empty [Metrics] implementation that does nothing.
+type EmptyMetrics struct{}
+
+// type check
+var _ Metrics = EmptyMetrics{}
+
+// IncrementSkipped implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) IncrementSkipped(_ context.Context) {}
+
+// OnShadowed implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) OnShadowed(_ context.Context, _ string) {}
@@ -0,0 +1,185 @@
+// Package shadowmw contains the middleware that mirrors a share of queries to a
+// secondary handler, such as an alternative upstream, without serving its
+// responses, and reports the divergence between the responses.
+package shadowmw
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/miekg/dns"
+)
+
+// Divergence values.
+const (
+	// DivergenceNone means that the shadow response is the same as the
+	// primary one.
+	DivergenceNone = "none"
+
+	// DivergenceError means that the secondary handler has returned an error
+	// or no response at all.
+	DivergenceError = "error"
+
+	// DivergenceRcode means that the response codes of the responses differ.
+	DivergenceRcode = "rcode"
+
+	// DivergenceAnswer means that the answer sections of the responses differ,
+	// disregarding the TTLs and the order of the records.
+	DivergenceAnswer = "answer"
+)
+
+// Config is the configuration structure for the shadowing middleware.
+type Config struct {
+	// Logger is used to log the operation of the middleware.  It must not be
+	// nil.
+	Logger *slog.Logger
+
+	// Handler is the secondary handler to which the queries are mirrored.  It
+	// must not be nil.
+	Handler dnsserver.Handler
+
+	// Metrics is used to collect the statistics.  It must not be nil.
+	Metrics Metrics
+
+	// Ratio is the share of queries that are mirrored.  It must be in the
+	// (0, 1] range.
+	Ratio float64
+
+	// MaxInFlight is the maximum number of mirrored queries processed at the
+	// same time.  Queries exceeding this limit are not mirrored.  It must be
+	// positive.
+	MaxInFlight int
+}
+
+// Middleware mirrors a share of queries to a secondary handler.
+type Middleware struct {
+	logger   *slog.Logger
+	handler  dnsserver.Handler
+	metrics  Metrics
+	inFlight chan struct{}
+	ratio    float64
+}
+
+// New returns a new shadowing middleware.  c must not be nil.
+func New(c *Config) (mw *Middleware) {
+	return &Middleware{
+		logger:   c.Logger,
+		handler:  c.Handler,
+		metrics:  c.Metrics,
+		inFlight: make(chan struct{}, c.MaxInFlight),
+		ratio:    c.Ratio,
+	}
+}
+
+// type check
+var _ dnsserver.Middleware = (*Middleware)(nil)
+
+// Wrap implements the [dnsserver.Middleware] interface for *Middleware.
+func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
+	f := func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (err error) {
+		defer func() { err = errors.Annotate(err, "shadowmw: %w") }()
+
+		nwrw := internal.MakeNonWriter(rw)
+		err = next.ServeDNS(ctx, nwrw, req)
+		if err != nil {
+			// Don't wrap the error, because this is the main flow, and there is
+			// already errors.Annotate here.
+			return err
+		}
+
+		resp := nwrw.Msg()
+		if resp != nil && rand.Float64() < mw.ratio {
+			// Clone the messages, since they are going to be used after
+			// they have been written and possibly disposed of.
+			mw.mirror(ctx, dnsmsg.Clone(req), dnsmsg.Clone(resp))
+		}
+
+		err = rw.WriteMsg(ctx, req, resp)
+		if err != nil {
+			return fmt.Errorf("writing response: %w", err)
+		}
+
+		return nil
+	}
+
+	return dnsserver.HandlerFunc(f)
+}
+
+// mirror sends req to the secondary handler in a separate goroutine and
+// compares the result with resp, unless there are too many mirrored queries
+// in flight already.
+func (mw *Middleware) mirror(ctx context.Context, req, resp *dns.Msg) {
+	select {
+	case mw.inFlight <- struct{}{}:
+		// Go on.
+	default:
+		mw.metrics.IncrementSkipped(ctx)
+
+		return
+	}
+
+	// Don't let the cancellation of the primary query affect the mirrored one,
+	// but keep the values, since the secondary handler may need them.
+	ctx = context.WithoutCancel(ctx)
+
+	go mw.serveShadow(ctx, req, resp)
+}
+
+// serveShadow sends req to the secondary handler and reports the divergence
+// between its response and resp.  It is intended to be used as a goroutine.
+func (mw *Middleware) serveShadow(ctx context.Context, req, resp *dns.Msg) {
+	defer func() { <-mw.inFlight }()
+	defer slogutil.RecoverAndLog(ctx, mw.logger)
+
+	// TODO:  Use the actual addresses if the secondary handlers
+	// ever need them.
+	nwrw := dnsserver.NewNonWriterResponseWriter(nil, nil)
+	err := mw.handler.ServeDNS(ctx, nwrw, req)
+	if err != nil {
+		optslog.Debug1(ctx, mw.logger, "shadow query failed", slogutil.KeyError, err)
+	}
+
+	d := divergence(resp, nwrw.Msg(), err)
+	optslog.Debug1(ctx, mw.logger, "shadow query finished", "divergence", d)
+
+	mw.metrics.OnShadowed(ctx, d)
+}
+
+// divergence returns the kind of divergence between the primary response and
+// the shadow one.  primary must not be nil.
+func divergence(primary, shadow *dns.Msg, err error) (d string) {
+	switch {
+	case err != nil, shadow == nil:
+		return DivergenceError
+	case primary.Rcode != shadow.Rcode:
+		return DivergenceRcode
+	case !slices.Equal(answerKeys(primary.Answer), answerKeys(shadow.Answer)):
+		return DivergenceAnswer
+	default:
+		return DivergenceNone
+	}
+}
+
+// answerKeys returns the sorted string representations of rrs with the TTLs
+// set to zero.
+func answerKeys(rrs []dns.RR) (keys []string) {
+	keys = make([]string, 0, len(rrs))
+	for _, rr := range rrs {
+		rr = dns.Copy(rr)
+		rr.Header().Ttl = 0
+		keys = append(keys, rr.String())
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
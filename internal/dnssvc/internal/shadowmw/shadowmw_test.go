@@ -0,0 +1,126 @@
+package shadowmw_test
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/shadowmw"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMetrics is a [shadowmw.Metrics] implementation for tests.
+type testMetrics struct {
+	onShadowed func(ctx context.Context, divergence string)
+}
+
+// type check
+var _ shadowmw.Metrics = (*testMetrics)(nil)
+
+// IncrementSkipped implements the [shadowmw.Metrics] interface for
+// *testMetrics.
+func (m *testMetrics) IncrementSkipped(_ context.Context) {}
+
+// OnShadowed implements the [shadowmw.Metrics] interface for *testMetrics.
+func (m *testMetrics) OnShadowed(ctx context.Context, divergence string) {
+	m.onShadowed(ctx, divergence)
+}
+
+// newHandler returns a handler that responds with rcode and the A records
+// with ips.  If err is not nil, it is returned instead.
+func newHandler(rcode int, err error, ips ...netip.Addr) (h dnsserver.Handler) {
+	f := func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (e error) {
+		if err != nil {
+			return err
+		}
+
+		ans := dnsservertest.SectionAnswer{}
+		for _, ip := range ips {
+			ans = append(ans, dnsservertest.NewA(req.Question[0].Name, 100, ip))
+		}
+
+		return rw.WriteMsg(ctx, req, dnsservertest.NewResp(rcode, req, ans))
+	}
+
+	return dnsserver.HandlerFunc(f)
+}
+
+func TestMiddleware_Wrap(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ip1 = netip.MustParseAddr("192.0.2.1")
+		ip2 = netip.MustParseAddr("192.0.2.2")
+	)
+
+	primary := newHandler(dns.RcodeSuccess, nil, ip1, ip2)
+
+	testCases := []struct {
+		shadow dnsserver.Handler
+		name   string
+		want   string
+	}{{
+		shadow: newHandler(dns.RcodeSuccess, nil, ip2, ip1),
+		name:   "same",
+		want:   shadowmw.DivergenceNone,
+	}, {
+		shadow: newHandler(dns.RcodeSuccess, errors.Error("test error")),
+		name:   "error",
+		want:   shadowmw.DivergenceError,
+	}, {
+		shadow: newHandler(dns.RcodeServerFailure, nil),
+		name:   "rcode",
+		want:   shadowmw.DivergenceRcode,
+	}, {
+		shadow: newHandler(dns.RcodeSuccess, nil, ip1),
+		name:   "answer",
+		want:   shadowmw.DivergenceAnswer,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			divCh := make(chan string, 1)
+			mw := shadowmw.New(&shadowmw.Config{
+				Logger:  slogutil.NewDiscardLogger(),
+				Handler: tc.shadow,
+				Metrics: &testMetrics{
+					onShadowed: func(_ context.Context, divergence string) {
+						divCh <- divergence
+					},
+				},
+				Ratio:       1,
+				MaxInFlight: 1,
+			})
+
+			h := mw.Wrap(primary)
+
+			ctx := testutil.ContextWithTimeout(t, dnssvctest.Timeout)
+			req := dnsservertest.NewReq(dnssvctest.DomainFQDN, dns.TypeA, dns.ClassINET)
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+
+			err := h.ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			assert.Equal(t, dns.RcodeSuccess, resp.Rcode)
+			assert.Len(t, resp.Answer, 2)
+
+			got, ok := testutil.RequireReceive(t, divCh, dnssvctest.Timeout)
+			require.True(t, ok)
+
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
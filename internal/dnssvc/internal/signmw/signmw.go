@@ -0,0 +1,116 @@
+// Package signmw contains the middleware that signs the synthesized responses,
+// such as the blocked responses and the answers to the DDR queries, within the
+// zone controlled by the operator for the clients that set the DO bit.
+package signmw
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssign"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// Config is the configuration structure for the signing middleware.  All
+// fields must be non-nil.
+type Config struct {
+	// Logger is used to log the operation of the middleware.
+	Logger *slog.Logger
+
+	// ErrColl is used to collect the signing errors.
+	ErrColl errcoll.Interface
+
+	// Signer is used to sign the responses and to answer the DNSKEY queries
+	// for the zone.
+	Signer *dnssign.Signer
+}
+
+// Middleware signs the synthesized responses within the zone of its signer.
+// It must wrap the initial middleware so that the special-domain answers are
+// signed as well.
+type Middleware struct {
+	logger  *slog.Logger
+	errColl errcoll.Interface
+	signer  *dnssign.Signer
+}
+
+// New returns a new signing middleware.  c must not be nil.
+func New(c *Config) (mw *Middleware) {
+	return &Middleware{
+		logger:  c.Logger,
+		errColl: c.ErrColl,
+		signer:  c.Signer,
+	}
+}
+
+// type check
+var _ dnsserver.Middleware = (*Middleware)(nil)
+
+// Wrap implements the [dnsserver.Middleware] interface for *Middleware.
+func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
+	f := func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (err error) {
+		defer func() { err = errors.Annotate(err, "signmw: %w") }()
+
+		if !dnsmsg.IsDO(req) {
+			// Don't wrap the error, because this is the main flow, and there is
+			// already [errors.Annotate] here.
+			return next.ServeDNS(ctx, rw, req)
+		}
+
+		ri := agd.MustRequestInfoFromContext(ctx)
+		if ri.QType == dns.TypeDNSKEY && dns.Fqdn(ri.Host) == mw.signer.Zone() {
+			return mw.writeDNSKEY(ctx, rw, req, ri)
+		}
+
+		nwrw := internal.MakeNonWriter(rw)
+		err = next.ServeDNS(ctx, nwrw, req)
+		if err != nil {
+			// Don't wrap the error, because this is the main flow, and there is
+			// already [errors.Annotate] here.
+			return err
+		}
+
+		resp := nwrw.Msg()
+		err = mw.signer.Sign(resp)
+		if err != nil {
+			// Send the response unsigned, since the validators treat it the
+			// same way as a response from an insecure zone.
+			errcoll.Collect(ctx, mw.errColl, mw.logger, "signing resp", err)
+		}
+
+		err = rw.WriteMsg(ctx, req, resp)
+
+		return errors.Annotate(err, "writing resp: %w")
+	}
+
+	return dnsserver.HandlerFunc(f)
+}
+
+// writeDNSKEY writes the response with the signed DNSKEY records of the zone to
+// rw.
+func (mw *Middleware) writeDNSKEY(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+	ri *agd.RequestInfo,
+) (err error) {
+	keys, err := mw.signer.DNSKEYs()
+	if err != nil {
+		return fmt.Errorf("getting dnskey: %w", err)
+	}
+
+	resp := ri.Messages.NewResp(req)
+	resp.Authoritative = true
+	resp.Answer = keys
+
+	err = rw.WriteMsg(ctx, req, resp)
+
+	return errors.Annotate(err, "writing dnskey resp: %w")
+}
@@ -0,0 +1,162 @@
+package signmw_test
+
+import (
+	"context"
+	"crypto"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssign"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/signmw"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// testZone is the common signed zone for tests.
+const testZone = "block.example."
+
+// newKeyFiles is a helper that generates a new ECDSA key for testZone with
+// flags and writes it into a temporary directory.
+func newKeyFiles(tb testing.TB, flags uint16) (paths *dnssign.KeyPaths) {
+	tb.Helper()
+
+	pub := &dns.DNSKEY{
+		Hdr: dns.RR_Header{
+			Name:   testZone,
+			Rrtype: dns.TypeDNSKEY,
+			Class:  dns.ClassINET,
+			Ttl:    3600,
+		},
+		Flags:     flags,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+
+	priv, err := pub.Generate(256)
+	require.NoError(tb, err)
+
+	dir := tb.TempDir()
+	paths = &dnssign.KeyPaths{
+		Public:  filepath.Join(dir, "pub"),
+		Private: filepath.Join(dir, "priv"),
+	}
+
+	err = os.WriteFile(paths.Public, []byte(pub.String()+"\n"), 0o600)
+	require.NoError(tb, err)
+
+	err = os.WriteFile(paths.Private, []byte(pub.PrivateKeyString(priv.(crypto.Signer))), 0o600)
+	require.NoError(tb, err)
+
+	return paths
+}
+
+func TestMiddleware_Wrap(t *testing.T) {
+	t.Parallel()
+
+	signer := dnssign.New(&dnssign.Config{
+		Logger:   slogutil.NewDiscardLogger(),
+		Clock:    agdtime.SystemClock{},
+		ErrColl:  agdtest.NewErrorCollector(),
+		KSK:      newKeyFiles(t, dns.ZONE|dns.SEP),
+		ZSK:      newKeyFiles(t, dns.ZONE),
+		Zone:     testZone,
+		Validity: time.Hour,
+	})
+
+	require.NoError(t, signer.Refresh(testutil.ContextWithTimeout(t, testTimeout)))
+
+	mw := signmw.New(&signmw.Config{
+		Logger:  slogutil.NewDiscardLogger(),
+		ErrColl: agdtest.NewErrorCollector(),
+		Signer:  signer,
+	})
+
+	h := mw.Wrap(dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		resp := dnsservertest.NewResp(dns.RcodeSuccess, req, dnsservertest.SectionAnswer{
+			dnsservertest.NewA(req.Question[0].Name, 10, netip.IPv4Unspecified()),
+		})
+
+		return rw.WriteMsg(ctx, req, resp)
+	}))
+
+	testCases := []struct {
+		name      string
+		host      string
+		wantTypes []uint16
+		qType     uint16
+		do        bool
+	}{{
+		name:      "signed",
+		host:      "www.block.example",
+		wantTypes: []uint16{dns.TypeA, dns.TypeRRSIG},
+		qType:     dns.TypeA,
+		do:        true,
+	}, {
+		name:      "no_do",
+		host:      "www.block.example",
+		wantTypes: []uint16{dns.TypeA},
+		qType:     dns.TypeA,
+		do:        false,
+	}, {
+		name:      "not_in_zone",
+		host:      "www.example.org",
+		wantTypes: []uint16{dns.TypeA},
+		qType:     dns.TypeA,
+		do:        true,
+	}, {
+		name:      "dnskey",
+		host:      "block.example",
+		wantTypes: []uint16{dns.TypeDNSKEY, dns.TypeDNSKEY, dns.TypeRRSIG},
+		qType:     dns.TypeDNSKEY,
+		do:        true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := dnsservertest.NewReq(dns.Fqdn(tc.host), tc.qType, dns.ClassINET)
+			req.SetEdns0(dns.DefaultMsgSize, tc.do)
+
+			ctx := agd.ContextWithRequestInfo(context.Background(), &agd.RequestInfo{
+				Messages: agdtest.NewConstructor(t),
+				Host:     tc.host,
+				QType:    tc.qType,
+				QClass:   dns.ClassINET,
+			})
+
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+			err := h.ServeDNS(ctx, rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			gotTypes := make([]uint16, 0, len(resp.Answer))
+			for _, rr := range resp.Answer {
+				gotTypes = append(gotTypes, rr.Header().Rrtype)
+			}
+
+			assert.Equal(t, tc.wantTypes, gotTypes)
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Package staticzonemw contains the middleware that serves the static answers
+// loaded from the operator-defined zone files authoritatively, before the
+// queries are filtered and forwarded upstream.
+package staticzonemw
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/staticzone"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// Config is the configuration structure for the static-zone middleware.  All
+// fields must be non-nil.
+type Config struct {
+	// Logger is used to log the operation of the middleware.
+	Logger *slog.Logger
+
+	// Messages is used to construct the responses.
+	Messages *dnsmsg.Constructor
+
+	// Zones is the storage of the static answers.
+	Zones staticzone.Interface
+}
+
+// Middleware serves the static answers from the zones.
+type Middleware struct {
+	logger   *slog.Logger
+	messages *dnsmsg.Constructor
+	zones    staticzone.Interface
+}
+
+// New returns a new static-zone middleware.  c must not be nil.
+func New(c *Config) (mw *Middleware) {
+	return &Middleware{
+		logger:   c.Logger,
+		messages: c.Messages,
+		zones:    c.Zones,
+	}
+}
+
+// type check
+var _ dnsserver.Middleware = (*Middleware)(nil)
+
+// Wrap implements the [dnsserver.Middleware] interface for *Middleware.
+func (mw *Middleware) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
+	f := func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (err error) {
+		defer func() { err = errors.Annotate(err, "staticzonemw: %w") }()
+
+		ri := agd.MustRequestInfoFromContext(ctx)
+		if ri.QClass != dns.ClassINET {
+			// Don't wrap the error, because this is the main flow, and there is
+			// already [errors.Annotate] here.
+			return next.ServeDNS(ctx, rw, req)
+		}
+
+		ans, found := mw.zones.Answer(ctx, ri.Host, ri.QType)
+		if !found {
+			return next.ServeDNS(ctx, rw, req)
+		}
+
+		optslog.Debug2(ctx, mw.logger, "serving static answer", "host", ri.Host, "num", len(ans))
+
+		resp := mw.messages.NewResp(req)
+		resp.Authoritative = true
+
+		// Copy the records, since ans must not be modified, and the later
+		// handlers, such as the signing middleware, may change the response.
+		resp.Answer = make([]dns.RR, 0, len(ans))
+		for _, rr := range ans {
+			resp.Answer = append(resp.Answer, dns.Copy(rr))
+		}
+
+		err = rw.WriteMsg(ctx, req, resp)
+		if err != nil {
+			return fmt.Errorf("writing static response: %w", err)
+		}
+
+		return nil
+	}
+
+	return dnsserver.HandlerFunc(f)
+}
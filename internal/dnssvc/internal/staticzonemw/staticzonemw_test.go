@@ -0,0 +1,141 @@
+package staticzonemw_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssvctest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/staticzonemw"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testZones is a [staticzone.Interface] implementation for tests.
+type testZones map[string][]dns.RR
+
+// Answer implements the [staticzone.Interface] interface for testZones.
+func (z testZones) Answer(
+	_ context.Context,
+	host string,
+	qt dnsmsg.RRType,
+) (ans []dns.RR, found bool) {
+	rrs, found := z[host]
+	for _, rr := range rrs {
+		if rr.Header().Rrtype == qt {
+			ans = append(ans, rr)
+		}
+	}
+
+	return ans, found
+}
+
+func TestMiddleware_Wrap(t *testing.T) {
+	t.Parallel()
+
+	const (
+		staticHost = "ntp.example.net"
+		otherHost  = "other.example.net"
+	)
+
+	staticRR := &dns.A{
+		Hdr: dns.RR_Header{
+			Name:   staticHost + ".",
+			Rrtype: dns.TypeA,
+			Class:  dns.ClassINET,
+			Ttl:    300,
+		},
+		A: net.IP{192, 0, 2, 1},
+	}
+
+	mw := staticzonemw.New(&staticzonemw.Config{
+		Logger:   slogutil.NewDiscardLogger(),
+		Messages: agdtest.NewConstructor(t),
+		Zones: testZones{
+			staticHost: {staticRR},
+		},
+	})
+
+	h := mw.Wrap(dnsservertest.NewDefaultHandler())
+
+	// Set the context necessary for [dnsservertest.DefaultHandler].
+	ctx := context.Background()
+	ctx = dnsserver.ContextWithRequestInfo(ctx, &dnsserver.RequestInfo{
+		StartTime: time.Now(),
+	})
+	ctx = dnsserver.ContextWithServerInfo(ctx, &dnsserver.ServerInfo{})
+
+	testCases := []struct {
+		name     string
+		host     string
+		qt       dnsmsg.RRType
+		qc       dnsmsg.Class
+		wantAuth bool
+		wantLen  int
+	}{{
+		name:     "static",
+		host:     staticHost,
+		qt:       dns.TypeA,
+		qc:       dns.ClassINET,
+		wantAuth: true,
+		wantLen:  1,
+	}, {
+		name:     "nodata",
+		host:     staticHost,
+		qt:       dns.TypeAAAA,
+		qc:       dns.ClassINET,
+		wantAuth: true,
+		wantLen:  0,
+	}, {
+		name:     "other_host",
+		host:     otherHost,
+		qt:       dns.TypeA,
+		qc:       dns.ClassINET,
+		wantAuth: false,
+		wantLen:  1,
+	}, {
+		name:     "other_class",
+		host:     staticHost,
+		qt:       dns.TypeA,
+		qc:       dns.ClassCHAOS,
+		wantAuth: false,
+		wantLen:  1,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ri := &agd.RequestInfo{
+				Host:   tc.host,
+				QType:  tc.qt,
+				QClass: tc.qc,
+			}
+
+			req := dnsservertest.NewReq(dns.Fqdn(tc.host), tc.qt, tc.qc)
+			rw := dnsserver.NewNonWriterResponseWriter(nil, dnssvctest.ClientTCPAddr)
+
+			err := h.ServeDNS(agd.ContextWithRequestInfo(ctx, ri), rw, req)
+			require.NoError(t, err)
+
+			resp := rw.Msg()
+			require.NotNil(t, resp)
+
+			assert.Equal(t, tc.wantAuth, resp.Authoritative)
+			assert.Len(t, resp.Answer, tc.wantLen)
+
+			if tc.wantAuth && tc.wantLen > 0 {
+				assert.Equal(t, staticRR, resp.Answer[0])
+				assert.NotSame(t, staticRR, resp.Answer[0])
+			}
+		})
+	}
+}
@@ -1,16 +1,69 @@
 package dnssvc
 
 import (
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/cnamemw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/dnssecmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/initial"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/mainmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/nsecmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/ptrmw"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/ratelimitmw"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnssvc/internal/shadowmw"
 )
 
 type (
+	// CNAMEMiddlewareMetrics is a re-export of the metrics interface of the
+	// internal CNAME-chain middleware.
+	CNAMEMiddlewareMetrics = cnamemw.Metrics
+
+	// DNSSECMiddlewareMetrics is a re-export of the metrics interface of the
+	// internal DNSSEC-requirement middleware.
+	DNSSECMiddlewareMetrics = dnssecmw.Metrics
+
+	// ChaosConfig is a re-export of the configuration of the responses to the
+	// CHAOS-class diagnostic queries of the internal initial middleware.
+	ChaosConfig = initial.ChaosConfig
+
+	// LeakAction is a re-export of the type of the actions on the queries for
+	// the special-use domain names of the internal initial middleware.
+	LeakAction = initial.LeakAction
+
+	// LeakConfig is a re-export of the configuration of the handling of the
+	// queries for the special-use domain names of the internal initial
+	// middleware.
+	LeakConfig = initial.LeakConfig
+
 	// MainMiddlewareMetrics is a re-export of the internal filtering-middleware
 	// metrics interface.
 	MainMiddlewareMetrics = mainmw.Metrics
 
+	// NSECMiddlewareMetrics is a re-export of the metrics interface of the
+	// internal aggressive NSEC middleware.
+	NSECMiddlewareMetrics = nsecmw.Metrics
+
+	// PTRZone is a re-export of the zone configuration of the internal PTR
+	// middleware.
+	PTRZone = ptrmw.Zone
+
 	// RatelimitMiddlewareMetrics is a re-export of the metrics interface of the
 	// internal access and ratelimiting middleware.
 	RatelimitMiddlewareMetrics = ratelimitmw.Metrics
+
+	// ShadowMiddlewareMetrics is a re-export of the metrics interface of the
+	// internal request-shadowing middleware.
+	ShadowMiddlewareMetrics = shadowmw.Metrics
+)
+
+// Re-exports of the actions on the queries for the special-use domain names of
+// the internal initial middleware.
+const (
+	LeakActionForward  = initial.LeakActionForward
+	LeakActionNXDomain = initial.LeakActionNXDomain
+	LeakActionRefused  = initial.LeakActionRefused
+)
+
+// Re-exports of the template placeholders of the internal PTR middleware.
+const (
+	PTRPlaceholderIP         = ptrmw.PlaceholderIP
+	PTRPlaceholderDeviceName = ptrmw.PlaceholderDeviceName
 )
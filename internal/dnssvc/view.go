@@ -0,0 +1,57 @@
+package dnssvc
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/miekg/dns"
+)
+
+// viewHandler is a [dnsserver.Handler] that passes the queries to the handler
+// of the view of the request, if there is one.
+type viewHandler struct {
+	// defaultHandler handles the queries without a view or for the views
+	// without a handler.
+	defaultHandler dnsserver.Handler
+
+	// handlers are the handlers of the views by their names.
+	handlers map[agd.ViewName]dnsserver.Handler
+}
+
+// newViewHandler returns h wrapped into a *viewHandler if there are view
+// handlers.  Otherwise, it returns h.
+func newViewHandler(
+	h dnsserver.Handler,
+	handlers map[agd.ViewName]dnsserver.Handler,
+) (wrapped dnsserver.Handler) {
+	if len(handlers) == 0 {
+		return h
+	}
+
+	return &viewHandler{
+		defaultHandler: h,
+		handlers:       handlers,
+	}
+}
+
+// type check
+var _ dnsserver.Handler = (*viewHandler)(nil)
+
+// ServeDNS implements the [dnsserver.Handler] interface for *viewHandler.
+func (h *viewHandler) ServeDNS(
+	ctx context.Context,
+	rw dnsserver.ResponseWriter,
+	req *dns.Msg,
+) (err error) {
+	ri := agd.MustRequestInfoFromContext(ctx)
+	if ri.View != nil {
+		if vh, ok := h.handlers[ri.View.Name]; ok {
+			// Don't wrap the error, because it's informative enough as is.
+			return vh.ServeDNS(ctx, rw, req)
+		}
+	}
+
+	// Don't wrap the error, because it's informative enough as is.
+	return h.defaultHandler.ServeDNS(ctx, rw, req)
+}
@@ -0,0 +1,90 @@
+// Package dnstap contains the implementation of the dnstap output of DNS
+// messages, which is the Protocol Buffers-based format understood by the
+// standard DNS analytics tools, transmitted using the Frame Streams protocol.
+//
+// See https://dnstap.info.
+package dnstap
+
+import (
+	"context"
+	"net/netip"
+	"time"
+)
+
+// Interface is the interface for writers of dnstap messages.
+//
+// All methods must be safe for concurrent use.
+type Interface interface {
+	// Write writes m.  m must not be nil.  Implementations must not retain m
+	// and must not block for long, so they may drop m instead.
+	Write(ctx context.Context, m *Message)
+}
+
+// type check
+var _ Interface = Empty{}
+
+// Empty is an [Interface] implementation that does nothing.
+type Empty struct{}
+
+// Write implements the [Interface] interface for Empty.
+func (Empty) Write(_ context.Context, _ *Message) {}
+
+// MessageType is the type of a dnstap message.
+type MessageType uint8
+
+// MessageType values.  Keep in sync with the Message.Type enum of the dnstap
+// schema.
+const (
+	// MessageTypeClientQuery is the type of a message containing a query
+	// received from a client.
+	MessageTypeClientQuery MessageType = 5
+
+	// MessageTypeClientResponse is the type of a message containing a
+	// response sent to a client.
+	MessageTypeClientResponse MessageType = 6
+)
+
+// SocketProtocol is the transport protocol of a dnstap message.
+type SocketProtocol uint8
+
+// SocketProtocol values.  Keep in sync with the SocketProtocol enum of the
+// dnstap schema.
+const (
+	SocketProtocolUDP         SocketProtocol = 1
+	SocketProtocolTCP         SocketProtocol = 2
+	SocketProtocolDoT         SocketProtocol = 3
+	SocketProtocolDoH         SocketProtocol = 4
+	SocketProtocolDNSCryptUDP SocketProtocol = 5
+	SocketProtocolDNSCryptTCP SocketProtocol = 6
+	SocketProtocolDoQ         SocketProtocol = 7
+)
+
+// Message is a single DNS message to be written in the dnstap format.
+type Message struct {
+	// QueryTime is the time when the query has been received.  It must not be
+	// zero.
+	QueryTime time.Time
+
+	// ResponseTime is the time when the response has been sent.  It is only
+	// used for the messages of type [MessageTypeClientResponse].
+	ResponseTime time.Time
+
+	// Query is the packed query.  It may be nil in responses.
+	Query []byte
+
+	// ClientAddr is the address of the client.
+	ClientAddr netip.AddrPort
+
+	// ServerAddr is the address of the server that has received the query.
+	ServerAddr netip.AddrPort
+
+	// Response is the packed response.  It is only used for the messages of
+	// type [MessageTypeClientResponse].
+	Response []byte
+
+	// Protocol is the transport protocol of the query.
+	Protocol SocketProtocol
+
+	// Type is the type of the message.
+	Type MessageType
+}
@@ -0,0 +1,308 @@
+package dnstap_test
+
+import (
+	"encoding/binary"
+	"io"
+	"io/fs"
+	"net"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnstap"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/c2h5oh/datasize"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// Control frame types for tests.
+const (
+	testControlAccept uint32 = 0x01
+	testControlStart  uint32 = 0x02
+	testControlStop   uint32 = 0x03
+	testControlReady  uint32 = 0x04
+	testControlFinish uint32 = 0x05
+)
+
+// Common addresses for tests.
+var (
+	testClientAddr = netip.MustParseAddrPort("192.0.2.1:12345")
+	testServerAddr = netip.MustParseAddrPort("198.51.100.1:53")
+)
+
+// testQuery is a fake packed query for tests.
+var testQuery = []byte{0x01, 0x02, 0x03}
+
+// newTestMessage returns a new client-query message for tests.
+func newTestMessage() (m *dnstap.Message) {
+	return &dnstap.Message{
+		QueryTime:  time.Unix(1_700_000_000, 123),
+		Query:      testQuery,
+		ClientAddr: testClientAddr,
+		ServerAddr: testServerAddr,
+		Protocol:   dnstap.SocketProtocolUDP,
+		Type:       dnstap.MessageTypeClientQuery,
+	}
+}
+
+// frame is a decoded Frame Streams frame.  If control is zero, it's a data
+// frame.
+type frame struct {
+	data    []byte
+	control uint32
+}
+
+// readFrame reads a single frame from r.  It uses [require.TestingT], since it
+// is also used in goroutines.
+func readFrame(tb require.TestingT, r io.Reader) (f *frame) {
+	var l uint32
+	err := binary.Read(r, binary.BigEndian, &l)
+	require.NoError(tb, err)
+
+	if l != 0 {
+		f = &frame{data: make([]byte, l)}
+		_, err = io.ReadFull(r, f.data)
+		require.NoError(tb, err)
+
+		return f
+	}
+
+	err = binary.Read(r, binary.BigEndian, &l)
+	require.NoError(tb, err)
+
+	b := make([]byte, l)
+	_, err = io.ReadFull(r, b)
+	require.NoError(tb, err)
+
+	return &frame{
+		control: binary.BigEndian.Uint32(b[:4]),
+	}
+}
+
+// writeTestControl writes a control frame of type typ without fields to w.
+func writeTestControl(tb require.TestingT, w io.Writer, typ uint32) {
+	b := binary.BigEndian.AppendUint32(nil, 0)
+	b = binary.BigEndian.AppendUint32(b, 4)
+	b = binary.BigEndian.AppendUint32(b, typ)
+
+	_, err := w.Write(b)
+	require.NoError(tb, err)
+}
+
+// messageFields returns the values of the varint and bytes fields of the
+// Message message inside the Dnstap message b.
+func messageFields(
+	tb testing.TB,
+	b []byte,
+) (varints map[protowire.Number]uint64, bytes map[protowire.Number][]byte) {
+	tb.Helper()
+
+	var msg []byte
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		require.Positive(tb, n)
+
+		b = b[n:]
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		require.Positive(tb, n)
+
+		if num == 14 {
+			msg, _ = protowire.ConsumeBytes(b)
+		}
+
+		b = b[n:]
+	}
+
+	require.NotNil(tb, msg)
+
+	varints = map[protowire.Number]uint64{}
+	bytes = map[protowire.Number][]byte{}
+	for len(msg) > 0 {
+		num, typ, n := protowire.ConsumeTag(msg)
+		require.Positive(tb, n)
+
+		msg = msg[n:]
+		switch typ {
+		case protowire.VarintType:
+			varints[num], n = protowire.ConsumeVarint(msg)
+		case protowire.BytesType:
+			bytes[num], n = protowire.ConsumeBytes(msg)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, msg)
+		}
+
+		require.Positive(tb, n)
+
+		msg = msg[n:]
+	}
+
+	return varints, bytes
+}
+
+// newTestWriter returns a new started dnstap writer for tests.
+func newTestWriter(tb testing.TB, path string, typ dnstap.SinkType) (w *dnstap.Default) {
+	tb.Helper()
+
+	return newTestWriterWithSize(tb, path, typ, 0)
+}
+
+// newTestWriterWithSize is like [newTestWriter] but also sets the maximum file
+// size.
+func newTestWriterWithSize(
+	tb testing.TB,
+	path string,
+	typ dnstap.SinkType,
+	maxSize datasize.ByteSize,
+) (w *dnstap.Default) {
+	tb.Helper()
+
+	w = dnstap.New(&dnstap.Config{
+		Logger:      slogutil.NewDiscardLogger(),
+		ErrColl:     agdtest.NewErrorCollector(),
+		Metrics:     dnstap.EmptyMetrics{},
+		Identity:    "test",
+		Path:        path,
+		SinkType:    typ,
+		QueueSize:   10,
+		MaxBackups:  2,
+		MaxFileSize: maxSize,
+	})
+
+	err := w.Start(testutil.ContextWithTimeout(tb, testTimeout))
+	require.NoError(tb, err)
+
+	return w
+}
+
+func TestDefault_file(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dnstap.fstrm")
+	w := newTestWriter(t, path, dnstap.SinkTypeFile)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	w.Write(ctx, newTestMessage())
+
+	err := w.Shutdown(ctx)
+	require.NoError(t, err)
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, f.Close)
+
+	assert.Equal(t, testControlStart, readFrame(t, f).control)
+
+	data := readFrame(t, f)
+	require.Zero(t, data.control)
+
+	varints, bytes := messageFields(t, data.data)
+	assert.Equal(t, uint64(dnstap.MessageTypeClientQuery), varints[1])
+	assert.Equal(t, uint64(dnstap.SocketProtocolUDP), varints[3])
+	assert.Equal(t, uint64(testClientAddr.Port()), varints[6])
+	assert.Equal(t, testClientAddr.Addr().AsSlice(), bytes[4])
+	assert.Equal(t, testQuery, bytes[10])
+
+	assert.Equal(t, testControlStop, readFrame(t, f).control)
+}
+
+// readControls returns the types of the frames in the file at path.
+func readControls(tb testing.TB, path string) (controls []uint32) {
+	tb.Helper()
+
+	b, err := os.ReadFile(path)
+	require.NoError(tb, err)
+
+	r := strings.NewReader(string(b))
+	for r.Len() > 0 {
+		controls = append(controls, readFrame(tb, r).control)
+	}
+
+	return controls
+}
+
+func TestDefault_fileRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "dnstap.fstrm")
+
+	err := os.WriteFile(path, []byte("previous"), 0o600)
+	require.NoError(t, err)
+
+	// Make the file rotate after each message.
+	w := newTestWriterWithSize(t, path, dnstap.SinkTypeFile, 1)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	for range 3 {
+		w.Write(ctx, newTestMessage())
+	}
+
+	err = w.Shutdown(ctx)
+	require.NoError(t, err)
+
+	// The oldest file, the one that existed before the start, must have been
+	// removed, since only two backups are kept.
+	_, err = os.Stat(path + ".3")
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+
+	wantControls := []uint32{testControlStart, 0, testControlStop}
+	assert.Equal(t, wantControls, readControls(t, path+".1"))
+	assert.Equal(t, wantControls, readControls(t, path+".2"))
+
+	// The current file is closed right after the last message is written, so
+	// it's not reopened.
+	assert.Equal(t, wantControls, readControls(t, path))
+}
+
+func TestDefault_socket(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dnstap.sock")
+	l, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, l.Close)
+
+	frames := make(chan *frame, 10)
+	go func() {
+		pt := testutil.PanicT{}
+		defer close(frames)
+
+		conn, acceptErr := l.Accept()
+		require.NoError(pt, acceptErr)
+
+		defer func() { _ = conn.Close() }()
+
+		require.Equal(pt, testControlReady, readFrame(pt, conn).control)
+		writeTestControl(pt, conn, testControlAccept)
+
+		for {
+			f := readFrame(pt, conn)
+			frames <- f
+
+			if f.control == testControlStop {
+				writeTestControl(pt, conn, testControlFinish)
+
+				return
+			}
+		}
+	}()
+
+	w := newTestWriter(t, path, dnstap.SinkTypeSocket)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	w.Write(ctx, newTestMessage())
+
+	err = w.Shutdown(ctx)
+	require.NoError(t, err)
+
+	got := []uint32{}
+	for f := range frames {
+		got = append(got, f.control)
+	}
+
+	assert.Equal(t, []uint32{testControlStart, 0, testControlStop}, got)
+}
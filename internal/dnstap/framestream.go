@@ -0,0 +1,95 @@
+package dnstap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// controlType is the type of a control frame of the Frame Streams protocol.
+type controlType uint32
+
+// controlType values.
+const (
+	controlAccept controlType = 0x01
+	controlStart  controlType = 0x02
+	controlStop   controlType = 0x03
+	controlReady  controlType = 0x04
+	controlFinish controlType = 0x05
+)
+
+// controlFieldContentType is the type of the content-type field of a control
+// frame.
+const controlFieldContentType uint32 = 0x01
+
+// maxControlFrameLen is the maximum length of a control frame accepted from
+// the reader.
+const maxControlFrameLen = 512
+
+// writeControl writes the control frame of type typ with the dnstap content
+// type to w.
+func writeControl(w io.Writer, typ controlType) (err error) {
+	b := make([]byte, 0, 4+4+4+4+4+len(ContentType))
+	b = binary.BigEndian.AppendUint32(b, 0)
+	b = binary.BigEndian.AppendUint32(b, uint32(4+4+4+len(ContentType)))
+	b = binary.BigEndian.AppendUint32(b, uint32(typ))
+	b = binary.BigEndian.AppendUint32(b, controlFieldContentType)
+	b = binary.BigEndian.AppendUint32(b, uint32(len(ContentType)))
+	b = append(b, ContentType...)
+
+	_, err = w.Write(b)
+	if err != nil {
+		return fmt.Errorf("writing control frame %d: %w", typ, err)
+	}
+
+	return nil
+}
+
+// readControl reads a control frame from r and returns an error if its type
+// isn't want.
+func readControl(r io.Reader, want controlType) (err error) {
+	var hdr [12]byte
+	_, err = io.ReadFull(r, hdr[:])
+	if err != nil {
+		return fmt.Errorf("reading control frame: %w", err)
+	}
+
+	escape := binary.BigEndian.Uint32(hdr[0:4])
+	l := binary.BigEndian.Uint32(hdr[4:8])
+	typ := controlType(binary.BigEndian.Uint32(hdr[8:12]))
+	switch {
+	case escape != 0:
+		return errors.Error("not a control frame")
+	case l < 4 || l > maxControlFrameLen:
+		return fmt.Errorf("control frame length: %w: %d", errors.ErrOutOfRange, l)
+	case typ != want:
+		return fmt.Errorf("control frame type: got %d, want %d", typ, want)
+	}
+
+	// Skip the fields, since the only content type is the one we've sent.
+	_, err = io.CopyN(io.Discard, r, int64(l-4))
+	if err != nil {
+		return fmt.Errorf("reading control frame fields: %w", err)
+	}
+
+	return nil
+}
+
+// writeData writes the data frame with the payload b to w.
+func writeData(w io.Writer, b []byte) (err error) {
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(len(b)))
+
+	_, err = w.Write(l[:])
+	if err == nil {
+		_, err = w.Write(b)
+	}
+
+	if err != nil {
+		return fmt.Errorf("writing data frame: %w", err)
+	}
+
+	return nil
+}
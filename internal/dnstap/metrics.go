@@ -0,0 +1,25 @@
+package dnstap
+
+import "context"
+
+// Metrics is an interface for monitoring the dnstap output.
+type Metrics interface {
+	// IncrementDropped is called when a message is dropped, because the queue
+	// is full or the sink is unavailable.
+	IncrementDropped(ctx context.Context)
+
+	// IncrementWritten is called when a message is written to the sink.
+	IncrementWritten(ctx context.Context)
+}
+
+// EmptyMetrics is an empty [Metrics] implementation that does nothing.
+type EmptyMetrics struct{}
+
+// type check
+var _ Metrics = EmptyMetrics{}
+
+// IncrementDropped implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) IncrementDropped(_ context.Context) {}
+
+// IncrementWritten implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) IncrementWritten(_ context.Context) {}
@@ -0,0 +1,155 @@
+package dnstap
+
+import (
+	"net/netip"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ContentType is the content type of the Frame Streams containing dnstap
+// messages.
+const ContentType = "protobuf:dnstap.Dnstap"
+
+// Field numbers of the Dnstap message of the dnstap schema.
+const (
+	fieldDnstapIdentity protowire.Number = 1
+	fieldDnstapVersion  protowire.Number = 2
+	fieldDnstapMessage  protowire.Number = 14
+	fieldDnstapType     protowire.Number = 15
+)
+
+// dnstapTypeMessage is the value of the Dnstap.Type enum for the Dnstap
+// messages containing a Message.
+const dnstapTypeMessage = 1
+
+// Field numbers of the Message message of the dnstap schema.
+const (
+	fieldMsgType             protowire.Number = 1
+	fieldMsgSocketFamily     protowire.Number = 2
+	fieldMsgSocketProtocol   protowire.Number = 3
+	fieldMsgQueryAddress     protowire.Number = 4
+	fieldMsgResponseAddress  protowire.Number = 5
+	fieldMsgQueryPort        protowire.Number = 6
+	fieldMsgResponsePort     protowire.Number = 7
+	fieldMsgQueryTimeSec     protowire.Number = 8
+	fieldMsgQueryTimeNsec    protowire.Number = 9
+	fieldMsgQueryMessage     protowire.Number = 10
+	fieldMsgResponseTimeSec  protowire.Number = 12
+	fieldMsgResponseTimeNsec protowire.Number = 13
+	fieldMsgResponseMessage  protowire.Number = 14
+)
+
+// Values of the SocketFamily enum of the dnstap schema.
+const (
+	socketFamilyINET  = 1
+	socketFamilyINET6 = 2
+)
+
+// appendDnstap appends the dnstap representation of m, identified by identity
+// and version, to b.
+func appendDnstap(b []byte, m *Message, identity, version string) (res []byte) {
+	if identity != "" {
+		b = protowire.AppendTag(b, fieldDnstapIdentity, protowire.BytesType)
+		b = protowire.AppendString(b, identity)
+	}
+
+	if version != "" {
+		b = protowire.AppendTag(b, fieldDnstapVersion, protowire.BytesType)
+		b = protowire.AppendString(b, version)
+	}
+
+	b = protowire.AppendTag(b, fieldDnstapMessage, protowire.BytesType)
+	b = protowire.AppendBytes(b, appendMessage(nil, m))
+
+	b = protowire.AppendTag(b, fieldDnstapType, protowire.VarintType)
+
+	return protowire.AppendVarint(b, dnstapTypeMessage)
+}
+
+// appendMessage appends the representation of m as the Message message of the
+// dnstap schema to b.
+func appendMessage(b []byte, m *Message) (res []byte) {
+	b = appendVarint(b, fieldMsgType, uint64(m.Type))
+
+	if fam := socketFamily(m.ClientAddr.Addr()); fam != 0 {
+		b = appendVarint(b, fieldMsgSocketFamily, fam)
+	}
+
+	if m.Protocol != 0 {
+		b = appendVarint(b, fieldMsgSocketProtocol, uint64(m.Protocol))
+	}
+
+	b = appendAddrPort(b, fieldMsgQueryAddress, fieldMsgQueryPort, m.ClientAddr)
+	b = appendAddrPort(b, fieldMsgResponseAddress, fieldMsgResponsePort, m.ServerAddr)
+	b = appendTime(b, fieldMsgQueryTimeSec, fieldMsgQueryTimeNsec, m.QueryTime)
+
+	if m.Query != nil {
+		b = protowire.AppendTag(b, fieldMsgQueryMessage, protowire.BytesType)
+		b = protowire.AppendBytes(b, m.Query)
+	}
+
+	if m.Type != MessageTypeClientResponse {
+		return b
+	}
+
+	b = appendTime(b, fieldMsgResponseTimeSec, fieldMsgResponseTimeNsec, m.ResponseTime)
+	if m.Response != nil {
+		b = protowire.AppendTag(b, fieldMsgResponseMessage, protowire.BytesType)
+		b = protowire.AppendBytes(b, m.Response)
+	}
+
+	return b
+}
+
+// socketFamily returns the value of the SocketFamily enum of the dnstap schema
+// for ip or zero if ip is invalid.
+func socketFamily(ip netip.Addr) (fam uint64) {
+	switch {
+	case !ip.IsValid():
+		return 0
+	case ip.Unmap().Is4():
+		return socketFamilyINET
+	default:
+		return socketFamilyINET6
+	}
+}
+
+// appendVarint appends the varint field with the number num and the value v to
+// b.
+func appendVarint(b []byte, num protowire.Number, v uint64) (res []byte) {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+
+	return protowire.AppendVarint(b, v)
+}
+
+// appendAddrPort appends the address and port fields with the numbers addrNum
+// and portNum to b, if addrPort is valid.
+func appendAddrPort(
+	b []byte,
+	addrNum protowire.Number,
+	portNum protowire.Number,
+	addrPort netip.AddrPort,
+) (res []byte) {
+	if !addrPort.IsValid() {
+		return b
+	}
+
+	b = protowire.AppendTag(b, addrNum, protowire.BytesType)
+	b = protowire.AppendBytes(b, addrPort.Addr().Unmap().AsSlice())
+
+	return appendVarint(b, portNum, uint64(addrPort.Port()))
+}
+
+// appendTime appends the time fields with the numbers secNum and nsecNum to b,
+// if t is not zero.
+func appendTime(b []byte, secNum, nsecNum protowire.Number, t time.Time) (res []byte) {
+	if t.IsZero() {
+		return b
+	}
+
+	b = appendVarint(b, secNum, uint64(t.Unix()))
+	b = protowire.AppendTag(b, nsecNum, protowire.Fixed32Type)
+
+	return protowire.AppendFixed32(b, uint32(t.Nanosecond()))
+}
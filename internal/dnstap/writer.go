@@ -0,0 +1,428 @@
+package dnstap
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/service"
+	"github.com/c2h5oh/datasize"
+)
+
+// SinkType is the type of the destination of the dnstap output.
+type SinkType uint8
+
+// SinkType values.
+const (
+	// SinkTypeFile means that the output is written into a file, which is
+	// rotated on start and, optionally, when it grows too large.
+	SinkTypeFile SinkType = iota + 1
+
+	// SinkTypeSocket means that the output is sent to a reader listening on a
+	// Unix socket using the bidirectional Frame Streams protocol.
+	SinkTypeSocket
+)
+
+// Timeouts and intervals of the writer.
+//
+// TODO:  Consider making configurable.
+const (
+	// flushInterval is the interval between the flushes of the buffered
+	// output.
+	flushInterval = 1 * time.Second
+
+	// reopenInterval is the minimum interval between the attempts to open the
+	// sink after a failure.
+	reopenInterval = 5 * time.Second
+
+	// socketTimeout is the timeout of dialing, the handshake, and each write
+	// to the socket sink.
+	socketTimeout = 5 * time.Second
+)
+
+// Config is the configuration structure for the dnstap writer.
+type Config struct {
+	// Logger is used to log the operation of the writer.  It must not be nil.
+	Logger *slog.Logger
+
+	// ErrColl is used to collect the errors of the sink.  It must not be nil.
+	ErrColl errcoll.Interface
+
+	// Metrics is used to collect the statistics.  It must not be nil.
+	Metrics Metrics
+
+	// Identity is the name of the server written into each message, if any.
+	Identity string
+
+	// Version is the version of the server written into each message, if any.
+	Version string
+
+	// Path is the path to the file or the Unix socket.  It must not be empty.
+	Path string
+
+	// SinkType is the type of the sink.  It must be a valid [SinkType].
+	SinkType SinkType
+
+	// QueueSize is the number of messages that can wait to be written.  The
+	// messages that don't fit are dropped.  It must be positive.
+	QueueSize int
+
+	// MaxBackups is the number of the rotated files that are kept.  The
+	// rotated files have the suffixes ".1", ".2", and so on, with ".1" being
+	// the most recent one.  It must be positive if SinkType is [SinkTypeFile].
+	MaxBackups int
+
+	// MaxFileSize is the size of the file after which it is rotated.  If it is
+	// zero, the file is only rotated on start.  It is ignored unless SinkType
+	// is [SinkTypeFile].
+	MaxFileSize datasize.ByteSize
+}
+
+// Default is the [Interface] implementation that writes the messages into a
+// file or a Unix socket in a separate goroutine.
+type Default struct {
+	logger   *slog.Logger
+	errColl  errcoll.Interface
+	metrics  Metrics
+	queue    chan []byte
+	stop     chan struct{}
+	done     chan struct{}
+	identity string
+	version  string
+	sink     io.WriteCloser
+	sock     net.Conn
+
+	// The fields below are only used by the writing goroutine.
+
+	out         *bufio.Writer
+	lastFail    time.Time
+	path        string
+	written     uint64
+	maxFileSize uint64
+	maxBackups  int
+	sinkType    SinkType
+}
+
+// New returns a new properly initialized *Default.  c must not be nil.
+func New(c *Config) (w *Default) {
+	return &Default{
+		logger:      c.Logger,
+		errColl:     c.ErrColl,
+		metrics:     c.Metrics,
+		queue:       make(chan []byte, c.QueueSize),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+		identity:    c.Identity,
+		version:     c.Version,
+		path:        c.Path,
+		maxFileSize: c.MaxFileSize.Bytes(),
+		maxBackups:  c.MaxBackups,
+		sinkType:    c.SinkType,
+	}
+}
+
+// type check
+var _ Interface = (*Default)(nil)
+
+// Write implements the [Interface] interface for *Default.  It encodes m and
+// queues it for writing or drops it if the queue is full.
+func (w *Default) Write(ctx context.Context, m *Message) {
+	b := appendDnstap(nil, m, w.identity, w.version)
+
+	select {
+	case w.queue <- b:
+		// Go on.
+	default:
+		w.metrics.IncrementDropped(ctx)
+	}
+}
+
+// type check
+var _ service.Interface = (*Default)(nil)
+
+// Start implements the [service.Interface] interface for *Default.  It starts
+// the writing goroutine.  err is always nil.
+func (w *Default) Start(ctx context.Context) (err error) {
+	go w.run(context.WithoutCancel(ctx))
+
+	return nil
+}
+
+// Shutdown implements the [service.Interface] interface for *Default.  It
+// writes the queued messages and closes the sink.
+func (w *Default) Shutdown(ctx context.Context) (err error) {
+	close(w.stop)
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for dnstap writer: %w", ctx.Err())
+	}
+}
+
+// run writes the queued messages until the writer is shut down.  It is
+// intended to be used as a goroutine.
+func (w *Default) run(ctx context.Context) {
+	defer close(w.done)
+	defer slogutil.RecoverAndLog(ctx, w.logger)
+
+	t := time.NewTicker(flushInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case b := <-w.queue:
+			w.writeFrame(ctx, b)
+		case <-t.C:
+			w.flush(ctx)
+		case <-w.stop:
+			w.drain(ctx)
+			w.closeSink(ctx)
+
+			return
+		}
+	}
+}
+
+// drain writes the messages remaining in the queue.
+func (w *Default) drain(ctx context.Context) {
+	for {
+		select {
+		case b := <-w.queue:
+			w.writeFrame(ctx, b)
+		default:
+			return
+		}
+	}
+}
+
+// writeFrame writes b as a data frame, opening the sink if necessary.
+func (w *Default) writeFrame(ctx context.Context, b []byte) {
+	if !w.openSink(ctx) {
+		w.metrics.IncrementDropped(ctx)
+
+		return
+	}
+
+	w.setDeadline()
+	err := writeData(w.out, b)
+	if err != nil {
+		w.handleError(ctx, err)
+		w.metrics.IncrementDropped(ctx)
+
+		return
+	}
+
+	w.metrics.IncrementWritten(ctx)
+
+	// Add the size of the frame length.
+	w.written += uint64(len(b)) + 4
+	if w.sinkType == SinkTypeFile && w.maxFileSize > 0 && w.written >= w.maxFileSize {
+		// Close the file, so that it's rotated when it's opened again.
+		w.closeSink(ctx)
+	}
+}
+
+// flush flushes the buffered output, if any.
+func (w *Default) flush(ctx context.Context) {
+	if w.out == nil || w.out.Buffered() == 0 {
+		return
+	}
+
+	w.setDeadline()
+	err := w.out.Flush()
+	if err != nil {
+		w.handleError(ctx, fmt.Errorf("flushing: %w", err))
+	}
+}
+
+// openSink opens the sink, if it isn't open already, unless the previous
+// attempt has failed too recently.  ok is true if the sink is open.
+func (w *Default) openSink(ctx context.Context) (ok bool) {
+	if w.out != nil {
+		return true
+	} else if time.Since(w.lastFail) < reopenInterval {
+		return false
+	}
+
+	var err error
+	switch w.sinkType {
+	case SinkTypeFile:
+		err = w.openFile()
+	case SinkTypeSocket:
+		err = w.openSocket()
+	default:
+		panic(fmt.Errorf("dnstap sink type: %w: %d", errors.ErrBadEnumValue, w.sinkType))
+	}
+
+	if err != nil {
+		w.lastFail = time.Now()
+		errcoll.Collect(ctx, w.errColl, w.logger, "opening dnstap sink", err)
+
+		return false
+	}
+
+	w.logger.InfoContext(ctx, "sink opened", "path", w.path)
+
+	return true
+}
+
+// openFile rotates the previous file, if any, opens the file sink, and writes
+// the start frame.
+func (w *Default) openFile() (err error) {
+	err = rotateFiles(w.path, w.maxBackups)
+	if err != nil {
+		return fmt.Errorf("rotating: %w", err)
+	}
+
+	f, err := os.OpenFile(w.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, agd.DefaultPerm)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	out := bufio.NewWriter(f)
+	err = writeControl(out, controlStart)
+	if err != nil {
+		return errors.WithDeferred(err, f.Close())
+	}
+
+	w.sink, w.out = f, out
+	w.written = 0
+
+	return nil
+}
+
+// rotateFiles renames the non-empty file at path, if any, into the first of at
+// most n backups, shifting the previous backups and removing the oldest one.
+func rotateFiles(path string, n int) (err error) {
+	fi, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	} else if fi.Size() == 0 {
+		return nil
+	}
+
+	for i := n - 1; i > 0; i-- {
+		err = os.Rename(backupPath(path, i), backupPath(path, i+1))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			// Don't wrap the error, because it's informative enough as is.
+			return err
+		}
+	}
+
+	return os.Rename(path, backupPath(path, 1))
+}
+
+// backupPath returns the path of the backup number i of the file at path.
+func backupPath(path string, i int) (backup string) {
+	return path + "." + strconv.Itoa(i)
+}
+
+// openSocket connects to the socket sink and performs the handshake.
+func (w *Default) openSocket() (err error) {
+	conn, err := net.DialTimeout("unix", w.path, socketTimeout)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	err = handshake(conn)
+	if err != nil {
+		return errors.WithDeferred(err, conn.Close())
+	}
+
+	w.sink, w.sock, w.out = conn, conn, bufio.NewWriter(conn)
+
+	return nil
+}
+
+// handshake performs the handshake of the bidirectional Frame Streams protocol
+// on conn.
+func handshake(conn net.Conn) (err error) {
+	err = conn.SetDeadline(time.Now().Add(socketTimeout))
+	if err != nil {
+		return fmt.Errorf("setting deadline: %w", err)
+	}
+
+	err = writeControl(conn, controlReady)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	err = readControl(conn, controlAccept)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	err = writeControl(conn, controlStart)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	return conn.SetDeadline(time.Time{})
+}
+
+// setDeadline sets the write deadline of the socket sink, if any.
+func (w *Default) setDeadline() {
+	if w.sock != nil {
+		// Errors are reported by the following write.
+		_ = w.sock.SetWriteDeadline(time.Now().Add(socketTimeout))
+	}
+}
+
+// handleError collects err and closes the sink, so that it is reopened later.
+func (w *Default) handleError(ctx context.Context, err error) {
+	errcoll.Collect(ctx, w.errColl, w.logger, "writing dnstap", err)
+
+	err = w.sink.Close()
+	if err != nil {
+		w.logger.DebugContext(ctx, "closing sink after error", slogutil.KeyError, err)
+	}
+
+	w.sink, w.sock, w.out = nil, nil, nil
+	w.lastFail = time.Now()
+}
+
+// closeSink finishes the stream and closes the sink, if it is open.
+func (w *Default) closeSink(ctx context.Context) {
+	if w.out == nil {
+		return
+	}
+
+	w.setDeadline()
+	err := writeControl(w.out, controlStop)
+	if err == nil {
+		err = w.out.Flush()
+	}
+
+	if err == nil && w.sock != nil {
+		_ = w.sock.SetReadDeadline(time.Now().Add(socketTimeout))
+		err = readControl(w.sock, controlFinish)
+	}
+
+	err = errors.WithDeferred(err, w.sink.Close())
+	if err != nil {
+		errcoll.Collect(ctx, w.errColl, w.logger, "closing dnstap sink", err)
+	}
+
+	w.sink, w.sock, w.out = nil, nil, nil
+}
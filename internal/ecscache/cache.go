@@ -7,6 +7,7 @@ import (
 	"net/netip"
 	"time"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
@@ -24,12 +25,23 @@ type cacheRequest struct {
 	// with GeoIP.
 	subnet netip.Prefix
 
+	// fltGrp is the ID of the filtering group of the server that handles the
+	// request.
+	fltGrp agd.FilteringGroupID
+
+	// view is the name of the view of the server group that handles the
+	// request, if any.
+	view agd.ViewName
+
 	// qType is the question type of the DNS request.
 	qType uint16
 
 	// qClass is the class of the DNS request.
 	qClass uint16
 
+	// outcome is the class of the filtering outcome of the request.
+	outcome agd.FilteringOutcome
+
 	// reqDO is the state of DNSSEC OK bit from the DNS request.
 	reqDO bool
 
@@ -49,8 +61,13 @@ func (mw *Middleware) get(
 	req *dns.Msg,
 	cr *cacheRequest,
 ) (resp *dns.Msg, isECSDependent bool) {
+	p := mw.partition(cr.outcome)
+	if p == nil {
+		return nil, false
+	}
+
 	key := mw.toCacheKey(cr, false)
-	item, ok := mw.itemFromCache(ctx, mw.cache, key, cr)
+	item, ok := mw.itemFromCache(ctx, p.cache, key, cr)
 	if ok {
 		return fromCacheItem(item, mw.cloner, req, cr.reqDO), false
 	} else if cr.isECSDeclined {
@@ -59,7 +76,7 @@ func (mw *Middleware) get(
 
 	// Try ECS-aware cache.
 	key = mw.toCacheKey(cr, true)
-	item, ok = mw.itemFromCache(ctx, mw.ecsCache, key, cr)
+	item, ok = mw.itemFromCache(ctx, p.ecsCache, key, cr)
 	if ok {
 		return fromCacheItem(item, mw.cloner, req, cr.reqDO), true
 	}
@@ -67,9 +84,9 @@ func (mw *Middleware) get(
 	return nil, false
 }
 
-// itemFromCache retrieves a DNS message for the given key.  cr.host is used to
-// detect key collisions.  If there is a key collision, it returns nil and
-// false.
+// itemFromCache retrieves a DNS message for the given key.  cr.host,
+// cr.fltGrp, and cr.view are used to detect key collisions.  If there is a key
+// collision, it returns nil and false.
 func (mw *Middleware) itemFromCache(
 	ctx context.Context,
 	cache agdcache.Interface[uint64, *cacheItem],
@@ -82,7 +99,7 @@ func (mw *Middleware) itemFromCache(
 	}
 
 	// Check for cache key collisions.
-	if item.host != cr.host {
+	if item.host != cr.host || item.fltGrp != cr.fltGrp || item.view != cr.view {
 		optslog.Warn2(ctx, mw.logger, "cache collision", "item", item, "host", cr.host)
 
 		return nil, false
@@ -106,8 +123,16 @@ func (mw *Middleware) toCacheKey(cr *cacheRequest, respIsECSDependent bool) (key
 
 	_, _ = h.WriteString(cr.host)
 
+	// Partition the cache by filtering group so that the responses to the
+	// requests handled by different filtering groups never share entries.
+	_, _ = h.WriteString(string(cr.fltGrp))
+
+	// Partition the cache by view as well, since the views may use different
+	// upstreams.
+	_, _ = h.WriteString(string(cr.view))
+
 	// Save on allocations by reusing a buffer.
-	var buf [6]byte
+	var buf [7]byte
 	binary.LittleEndian.PutUint16(buf[:2], cr.qType)
 	binary.LittleEndian.PutUint16(buf[2:4], cr.qClass)
 
@@ -115,6 +140,7 @@ func (mw *Middleware) toCacheKey(cr *cacheRequest, respIsECSDependent bool) (key
 
 	addr := cr.subnet.Addr()
 	buf[5] = mathutil.BoolToNumber[byte](addr.Is6())
+	buf[6] = byte(cr.outcome)
 
 	_, _ = h.Write(buf[:])
 
@@ -128,17 +154,24 @@ func (mw *Middleware) toCacheKey(cr *cacheRequest, respIsECSDependent bool) (key
 	return h.Sum64()
 }
 
-// set saves resp to the cache if it's cacheable.  If msg cannot be cached, it
-// is ignored.
+// set saves resp to the cache partition for cr if it's cacheable.  If msg
+// cannot be cached or there is no such partition, it is ignored.
 func (mw *Middleware) set(resp *dns.Msg, cr *cacheRequest, respIsECSDependent bool) {
+	p := mw.partition(cr.outcome)
+	if p == nil {
+		return
+	}
+
+	defer mw.updateSizeMetrics(p, respIsECSDependent)
+
 	ttl := dnsmsg.FindLowestTTL(resp)
 	if ttl == 0 || !isCacheable(resp) {
 		return
 	}
 
-	cache := mw.cache
+	cache := p.cache
 	if respIsECSDependent {
-		cache = mw.ecsCache
+		cache = p.ecsCache
 	}
 
 	exp := time.Duration(ttl) * time.Second
@@ -151,7 +184,7 @@ func (mw *Middleware) set(resp *dns.Msg, cr *cacheRequest, respIsECSDependent bo
 
 	cachedResp := mw.cloner.Clone(resp)
 
-	cache.SetWithExpire(key, toCacheItem(cachedResp, cr.host), exp)
+	cache.SetWithExpire(key, toCacheItem(cachedResp, cr), exp)
 }
 
 // cacheItem represents an item that we will store in the cache.
@@ -165,14 +198,48 @@ type cacheItem struct {
 	// host is the cached normalized hostname for later cache key collision
 	// checks.
 	host string
+
+	// fltGrp is the ID of the filtering group for later cache key collision
+	// checks.
+	fltGrp agd.FilteringGroupID
+
+	// view is the name of the view for later cache key collision checks.
+	view agd.ViewName
+
+	// subnet is the network of the request used to recalculate the cache key
+	// when the item is restored from a snapshot.
+	subnet netip.Prefix
+
+	// reqDO is the state of DNSSEC OK bit from the request used to recalculate
+	// the cache key when the item is restored from a snapshot.
+	reqDO bool
+
+	// isECSDeclined shows if the client of the request declined the use of
+	// ECS.  It is used to recalculate the cache key when the item is restored
+	// from a snapshot.
+	isECSDeclined bool
+}
+
+// type check
+var _ agdcache.HostItem = (*cacheItem)(nil)
+
+// CachedHost implements the [agdcache.HostItem] interface for *cacheItem.
+func (item *cacheItem) CachedHost() (host string) {
+	return item.host
 }
 
-// toCacheItem creates a *cacheItem from a DNS message.
-func toCacheItem(resp *dns.Msg, host string) (item *cacheItem) {
+// toCacheItem creates a *cacheItem from a DNS message and the data of the
+// cache request.
+func toCacheItem(resp *dns.Msg, cr *cacheRequest) (item *cacheItem) {
 	return &cacheItem{
-		msg:  resp,
-		when: time.Now(),
-		host: host,
+		msg:           resp,
+		when:          time.Now(),
+		host:          cr.host,
+		fltGrp:        cr.fltGrp,
+		view:          cr.view,
+		subnet:        cr.subnet,
+		reqDO:         cr.reqDO,
+		isECSDeclined: cr.isECSDeclined,
 	}
 }
 
@@ -5,7 +5,7 @@ import (
 	"net/netip"
 	"testing"
 
-	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
 	"github.com/miekg/dns"
 )
@@ -14,12 +14,7 @@ var msgSink *dns.Msg
 
 func BenchmarkMiddleware_Get(b *testing.B) {
 	mw := &Middleware{
-		cache: agdcache.NewLRU[uint64, *cacheItem](&agdcache.LRUConfig{
-			Count: 10,
-		}),
-		ecsCache: agdcache.NewLRU[uint64, *cacheItem](&agdcache.LRUConfig{
-			Count: 10,
-		}),
+		unfiltered: newCachePartition(agd.FilteringOutcomeUnfiltered, 10, 10),
 	}
 
 	const (
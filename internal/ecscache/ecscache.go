@@ -6,6 +6,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/netip"
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
@@ -15,10 +16,12 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
 	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
 	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/snapshot"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/AdguardTeam/golibs/syncutil"
 	"github.com/miekg/dns"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Constants that define cache identifiers for the cache manager.
@@ -26,6 +29,9 @@ const (
 	cachePrefix    = "dns/"
 	cacheIDWithECS = cachePrefix + "ecscache_with_ecs"
 	cacheIDNoECS   = cachePrefix + "ecscache_no_ecs"
+
+	cacheIDFilteredWithECS = cachePrefix + "ecscache_filtered_with_ecs"
+	cacheIDFilteredNoECS   = cachePrefix + "ecscache_filtered_no_ecs"
 )
 
 // Middleware is a dnsserver.Middleware with ECS-aware caching.
@@ -39,11 +45,14 @@ type Middleware struct {
 	// logger is used to log the operation of the middleware.
 	logger *slog.Logger
 
-	// cache is the LRU cache for results indicating no support for ECS.
-	cache agdcache.Interface[uint64, *cacheItem]
+	// unfiltered is the cache partition for the requests that have not been
+	// filtered.  It is never nil.
+	unfiltered *cachePartition
 
-	// ecsCache is the LRU cache for results indicating ECS support.
-	ecsCache agdcache.Interface[uint64, *cacheItem]
+	// filtered is the cache partition for the requests that have been blocked
+	// or modified.  It is nil if the responses to such requests must not be
+	// cached.
+	filtered *cachePartition
 
 	// geoIP is used to get subnets for countries.
 	geoIP geoip.Interface
@@ -71,6 +80,10 @@ type MiddlewareConfig struct {
 	// not be nil.
 	GeoIP geoip.Interface
 
+	// SnapshotManager is used to save and restore the contents of the cache
+	// between runs.  It must not be nil.
+	SnapshotManager snapshot.Manager
+
 	// MinTTL is the minimum supported TTL for cache items.
 	MinTTL time.Duration
 
@@ -82,36 +95,71 @@ type MiddlewareConfig struct {
 	// support ECS, in entries.  It must be greater than zero.
 	ECSCount int
 
+	// FilteredNoECSCount is the number of entities to hold in the cache for
+	// hosts that don't support ECS for the requests that have been blocked or
+	// modified, in entries.  If it or FilteredECSCount is zero, the responses
+	// to such requests aren't cached.  It must not be negative.
+	FilteredNoECSCount int
+
+	// FilteredECSCount is the number of entities to hold in the cache for
+	// hosts that support ECS for the requests that have been blocked or
+	// modified, in entries.  If it or FilteredNoECSCount is zero, the responses
+	// to such requests aren't cached.  It must not be negative.
+	FilteredECSCount int
+
 	// OverrideTTL shows if the TTL overrides logic should be used.
 	OverrideTTL bool
 }
 
 // NewMiddleware initializes a new ECS-aware LRU caching middleware.  It also
-// adds the caches with IDs [CacheIDNoECS] and [CacheIDWithECS] to the cache
-// manager.  c must not be nil.
+// adds the caches to the cache manager and itself to the snapshot manager.  c
+// must not be nil.
 func NewMiddleware(c *MiddlewareConfig) (m *Middleware) {
-	cache := agdcache.NewLRU[uint64, *cacheItem](&agdcache.LRUConfig{
-		Count: c.NoECSCount,
-	})
-	ecsCache := agdcache.NewLRU[uint64, *cacheItem](&agdcache.LRUConfig{
-		Count: c.ECSCount,
-	})
-
-	c.CacheManager.Add(cacheIDNoECS, cache)
-	c.CacheManager.Add(cacheIDWithECS, ecsCache)
+	unfiltered := newCachePartition(
+		agd.FilteringOutcomeUnfiltered,
+		c.NoECSCount,
+		c.ECSCount,
+	)
+	c.CacheManager.Add(cacheIDNoECS, unfiltered.cache)
+	c.CacheManager.Add(cacheIDWithECS, unfiltered.ecsCache)
+
+	var filtered *cachePartition
+	if c.FilteredNoECSCount > 0 && c.FilteredECSCount > 0 {
+		filtered = newCachePartition(
+			agd.FilteringOutcomeFiltered,
+			c.FilteredNoECSCount,
+			c.FilteredECSCount,
+		)
+		c.CacheManager.Add(cacheIDFilteredNoECS, filtered.cache)
+		c.CacheManager.Add(cacheIDFilteredWithECS, filtered.ecsCache)
+	}
 
-	return &Middleware{
+	m = &Middleware{
 		cloner: c.Cloner,
 		logger: c.Logger,
 		cacheReqPool: syncutil.NewPool(func() (req *cacheRequest) {
 			return &cacheRequest{}
 		}),
-		cache:       cache,
-		ecsCache:    ecsCache,
+		unfiltered:  unfiltered,
+		filtered:    filtered,
 		geoIP:       c.GeoIP,
 		cacheMinTTL: c.MinTTL,
 		overrideTTL: c.OverrideTTL,
 	}
+
+	c.SnapshotManager.Add(snapshotName, m)
+
+	return m
+}
+
+// partition returns the cache partition for the requests with the given
+// filtering outcome.  p is nil if such requests must not be cached.
+func (mw *Middleware) partition(outcome agd.FilteringOutcome) (p *cachePartition) {
+	if outcome == agd.FilteringOutcomeUnfiltered {
+		return mw.unfiltered
+	}
+
+	return mw.filtered
 }
 
 // type check
@@ -242,10 +290,8 @@ func (mw *Middleware) writeUpstreamResponse(
 	respIsECS := respIsECSDependent(scope, req.Question[0].Name)
 	if respIsECS {
 		metrics.ECSCacheLookupHasSupportMisses.Inc()
-		metrics.ECSHasSupportCacheSize.Set(float64(mw.ecsCache.Len()))
 	} else {
 		metrics.ECSCacheLookupNoSupportMisses.Inc()
-		metrics.ECSNoSupportCacheSize.Set(float64(mw.cache.Len()))
 
 		cr.subnet = netutil.ZeroPrefix(ecsFam)
 	}
@@ -302,38 +348,23 @@ func (mh *mwHandler) ServeDNS(
 
 	cr.host, cr.qType, cr.qClass = ri.Host, ri.QType, ri.QClass
 	cr.reqDO = dnsmsg.IsDO(req)
+	cr.outcome = ri.FilteringOutcome
+	cr.fltGrp = ""
+	if ri.FilteringGroup != nil {
+		cr.fltGrp = ri.FilteringGroup.ID
+	}
 
-	ecsFam := ecsFamFromReq(ri)
-
-	cr.isECSDeclined = ri.ECS != nil && ri.ECS.Subnet.Bits() == 0
-	if cr.isECSDeclined {
-		// Don't perform subnet lookup when ECS contains zero-length prefix.
-		// Cache key calculation shouldn't consider the subnet of the cache
-		// request in this case, but the actual DNS request generated on cache
-		// miss will use this data.
-		mw.logger.DebugContext(ctx, "explicitly declined ecs")
+	cr.view = ""
+	if ri.View != nil {
+		cr.view = ri.View.Name
+	}
 
-		cr.subnet = netutil.ZeroPrefix(ecsFam)
-	} else {
-		loc := locFromReq(ri)
-		cr.subnet, err = mw.geoIP.SubnetByLocation(loc, ecsFam)
-		if err != nil {
-			return fmt.Errorf(
-				"getting subnet for country %s (family: %d): %w",
-				loc.Country,
-				ecsFam,
-				err,
-			)
-		}
+	ecsFam := ecsFamFromReq(ri)
 
-		optslog.Debug3(
-			ctx,
-			mw.logger,
-			"request data",
-			"ctry", loc.Country,
-			"asn", loc.ASN,
-			"subnet", cr.subnet,
-		)
+	err = mw.setSubnet(ctx, cr, ri, ecsFam)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
 	}
 
 	// Try getting a cached result using the subnet of the location or zero one
@@ -377,6 +408,73 @@ func (mh *mwHandler) ServeDNS(
 	return mw.writeUpstreamResponse(ctx, rw, req, resp, ri, cr, ecsFam)
 }
 
+// setSubnet sets the subnet of cr, which is used both for the cache key and the
+// upstream request, according to the ECS mode of the request.
+func (mw *Middleware) setSubnet(
+	ctx context.Context,
+	cr *cacheRequest,
+	ri *agd.RequestInfo,
+	ecsFam netutil.AddrFamily,
+) (err error) {
+	mode := ri.ECSMode()
+
+	cr.isECSDeclined = mode == agd.ECSModeStrip
+	switch mode {
+	case agd.ECSModeStrip:
+		// Don't perform subnet lookup when ECS contains zero-length prefix or
+		// the profile requires stripping it.  Cache key calculation shouldn't
+		// consider the subnet of the cache request in this case, but the actual
+		// DNS request generated on cache miss will use this data.
+		mw.logger.DebugContext(ctx, "declined ecs")
+
+		cr.subnet = netutil.ZeroPrefix(ecsFam)
+	case agd.ECSModeForward:
+		cr.subnet = forwardedSubnet(ri.ECS.Subnet)
+
+		optslog.Debug1(ctx, mw.logger, "forwarding ecs", "subnet", cr.subnet)
+	default:
+		loc := locFromReq(ri)
+		cr.subnet, err = mw.geoIP.SubnetByLocation(loc, ecsFam)
+		if err != nil {
+			return fmt.Errorf(
+				"getting subnet for country %s (family: %d): %w",
+				loc.Country,
+				ecsFam,
+				err,
+			)
+		}
+
+		optslog.Debug3(
+			ctx,
+			mw.logger,
+			"request data",
+			"ctry", loc.Country,
+			"asn", loc.ASN,
+			"subnet", cr.subnet,
+		)
+	}
+
+	return nil
+}
+
+// forwardedSubnet returns the client's subnet reduced to the maximum prefix
+// length allowed for forwarding.
+func forwardedSubnet(subnet netip.Prefix) (fwd netip.Prefix) {
+	maxBits := agd.ECSForwardMaxBitsIPv6
+	if subnet.Addr().Is4() {
+		maxBits = agd.ECSForwardMaxBitsIPv4
+	}
+
+	if subnet.Bits() <= maxBits {
+		return subnet.Masked()
+	}
+
+	// The error is always nil, since maxBits is valid for the family.
+	fwd, _ = subnet.Addr().Prefix(maxBits)
+
+	return fwd
+}
+
 // respIsECSDependent returns true if the response should be considered as ESC
 // dependent.
 //
@@ -391,3 +489,70 @@ func respIsECSDependent(scope uint8, fqdn string) (ok bool) {
 
 	return !FakeECSFQDNs.Has(fqdn)
 }
+
+// cachePartition is a pair of caches for the requests with the same class of
+// filtering outcomes.
+type cachePartition struct {
+	// cache is the LRU cache for results indicating no support for ECS.
+	cache agdcache.Interface[uint64, *cacheItem]
+
+	// ecsCache is the LRU cache for results indicating ECS support.
+	ecsCache agdcache.Interface[uint64, *cacheItem]
+
+	// noECSSize is the gauge with the number of items in cache.
+	noECSSize prometheus.Gauge
+
+	// ecsSize is the gauge with the number of items in ecsCache.
+	ecsSize prometheus.Gauge
+}
+
+// newCachePartition returns a new cache partition for the requests with the
+// given filtering outcome.  noECSCount and ecsCount must be positive.
+func newCachePartition(
+	outcome agd.FilteringOutcome,
+	noECSCount int,
+	ecsCount int,
+) (p *cachePartition) {
+	outcomeStr := outcome.String()
+
+	return &cachePartition{
+		cache: agdcache.NewLRU[uint64, *cacheItem](&agdcache.LRUConfig{
+			Count: noECSCount,
+		}),
+		ecsCache: agdcache.NewLRU[uint64, *cacheItem](&agdcache.LRUConfig{
+			Count: ecsCount,
+		}),
+		noECSSize: metrics.ECSCachePartitionSize.WithLabelValues(outcomeStr, "no"),
+		ecsSize:   metrics.ECSCachePartitionSize.WithLabelValues(outcomeStr, "yes"),
+	}
+}
+
+// updateSizeMetrics updates the size metrics of the caches of p depending on
+// whether the response is ECS-dependent.  p must not be nil.
+func (mw *Middleware) updateSizeMetrics(p *cachePartition, respIsECSDependent bool) {
+	if respIsECSDependent {
+		p.ecsSize.Set(float64(p.ecsCache.Len()))
+		metrics.ECSHasSupportCacheSize.Set(float64(mw.totalLen(true)))
+	} else {
+		p.noECSSize.Set(float64(p.cache.Len()))
+		metrics.ECSNoSupportCacheSize.Set(float64(mw.totalLen(false)))
+	}
+}
+
+// totalLen returns the total number of items in the ECS-aware caches of all
+// partitions, if ecs is true, or in the ECS-unaware ones otherwise.
+func (mw *Middleware) totalLen(ecs bool) (n int) {
+	for _, p := range []*cachePartition{mw.unfiltered, mw.filtered} {
+		if p == nil {
+			continue
+		}
+
+		if ecs {
+			n += p.ecsCache.Len()
+		} else {
+			n += p.cache.Len()
+		}
+	}
+
+	return n
+}
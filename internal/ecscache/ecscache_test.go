@@ -16,6 +16,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/ecscache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/snapshot"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/AdguardTeam/golibs/testutil"
@@ -227,6 +228,99 @@ func TestMiddleware_Wrap_noECS(t *testing.T) {
 	}
 }
 
+func TestMiddleware_Wrap_partitions(t *testing.T) {
+	req := dnsservertest.NewReq(reqHostname, dns.TypeA, dns.ClassINET)
+	resp := dnsservertest.NewResp(dns.RcodeSuccess, req, dnsservertest.SectionAnswer{
+		dnsservertest.NewA(reqHostname, defaultTTL, remoteIP),
+	})
+
+	grp1 := &agd.FilteringGroup{ID: "group_1"}
+	grp2 := &agd.FilteringGroup{ID: "group_2"}
+
+	view := &agd.View{FilteringGroup: grp1, Name: "view_1"}
+
+	newRI := func(g *agd.FilteringGroup, outcome agd.FilteringOutcome) (ri *agd.RequestInfo) {
+		return &agd.RequestInfo{
+			FilteringGroup:   g,
+			Host:             reqHostname,
+			RemoteIP:         remoteIP,
+			FilteringOutcome: outcome,
+		}
+	}
+
+	viewRI := newRI(grp1, agd.FilteringOutcomeUnfiltered)
+	viewRI.View = view
+
+	reqInfos := []*agd.RequestInfo{
+		newRI(grp1, agd.FilteringOutcomeUnfiltered),
+		newRI(grp2, agd.FilteringOutcomeUnfiltered),
+		viewRI,
+		newRI(grp1, agd.FilteringOutcomeFiltered),
+		newRI(grp2, agd.FilteringOutcomeFiltered),
+	}
+
+	testCases := []struct {
+		name          string
+		filteredCount int
+		wantNumReq    int
+	}{{
+		name:          "filtered_cached",
+		filteredCount: 100,
+		wantNumReq:    len(reqInfos),
+	}, {
+		name:          "filtered_not_cached",
+		filteredCount: 0,
+		// Three requests for the unfiltered partitions and two requests for
+		// each of the filtered ones.
+		wantNumReq: 3 + 2*2,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			numReq := 0
+			handler := dnsserver.HandlerFunc(
+				func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) error {
+					numReq++
+
+					return rw.WriteMsg(ctx, req, resp)
+				},
+			)
+
+			geoIP := agdtest.NewGeoIP()
+			geoIP.OnSubnetByLocation = func(
+				_ *geoip.Location,
+				_ netutil.AddrFamily,
+			) (n netip.Prefix, err error) {
+				return netutil.ZeroPrefix(netutil.AddrFamilyIPv4), nil
+			}
+
+			withCache := dnsserver.WithMiddlewares(
+				handler,
+				ecscache.NewMiddleware(&ecscache.MiddlewareConfig{
+					Cloner:             agdtest.NewCloner(),
+					Logger:             slogutil.NewDiscardLogger(),
+					CacheManager:       agdcache.EmptyManager{},
+					GeoIP:              geoIP,
+					SnapshotManager:    snapshot.EmptyManager{},
+					NoECSCount:         100,
+					ECSCount:           100,
+					FilteredNoECSCount: tc.filteredCount,
+					FilteredECSCount:   tc.filteredCount,
+				}),
+			)
+
+			for range 2 {
+				for _, ri := range reqInfos {
+					msg := exchange(t, ri, withCache, req)
+					assert.Equal(t, resp.Answer, msg.Answer)
+				}
+			}
+
+			assert.Equal(t, tc.wantNumReq, numReq)
+		})
+	}
+}
+
 const prefixLen = 24
 
 // newAReq returns new test A request with ECS option.
@@ -397,6 +491,89 @@ func TestMiddleware_Wrap_ecs(t *testing.T) {
 	}
 }
 
+func TestMiddleware_Wrap_ecsMode(t *testing.T) {
+	t.Parallel()
+
+	const ctry = geoip.CountryAD
+
+	ctrySubnet := netip.MustParsePrefix("1.2.0.0/16")
+	clientIP := net.IP{1, 2, 3, 4}
+
+	testCases := []struct {
+		clientECS  *dnsmsg.ECS
+		wantSubnet netip.Prefix
+		name       string
+		mode       agd.ECSMode
+	}{{
+		clientECS:  nil,
+		wantSubnet: ctrySubnet,
+		name:       "replace",
+		mode:       agd.ECSModeReplace,
+	}, {
+		clientECS: &dnsmsg.ECS{
+			Location: &geoip.Location{Country: ctry},
+			Subnet:   netip.PrefixFrom(netip.AddrFrom4([4]byte(clientIP)), 32),
+		},
+		wantSubnet: netip.MustParsePrefix("1.2.3.0/24"),
+		name:       "forward",
+		mode:       agd.ECSModeForward,
+	}, {
+		clientECS:  nil,
+		wantSubnet: ctrySubnet,
+		name:       "forward_no_ecs",
+		mode:       agd.ECSModeForward,
+	}, {
+		clientECS:  nil,
+		wantSubnet: netutil.ZeroPrefix(netutil.AddrFamilyIPv4),
+		name:       "strip",
+		mode:       agd.ECSModeStrip,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := dnsservertest.NewReq(reqHostname, dns.TypeA, dns.ClassINET)
+			if tc.clientECS != nil {
+				req = newAReq(reqHostname, clientIP)
+			}
+
+			var gotSubnet netip.Prefix
+			handler := dnsserver.HandlerFunc(func(
+				ctx context.Context,
+				rw dnsserver.ResponseWriter,
+				upsReq *dns.Msg,
+			) (err error) {
+				pt := testutil.PanicT{}
+
+				gotSubnet, _, err = dnsmsg.ECSFromMsg(upsReq)
+				require.NoError(pt, err)
+
+				return rw.WriteMsg(ctx, upsReq, dnsservertest.NewResp(dns.RcodeSuccess, upsReq))
+			})
+
+			withCache := newWithCache(t, handler, ctry, ctrySubnet, 0, false)
+			ri := &agd.RequestInfo{
+				DeviceResult: &agd.DeviceResultOK{
+					Device: &agd.Device{},
+					Profile: &agd.Profile{
+						ECSMode: tc.mode,
+					},
+				},
+				Location: &geoip.Location{
+					Country: ctry,
+				},
+				ECS:      tc.clientECS,
+				Host:     reqHostname,
+				RemoteIP: remoteIP,
+			}
+
+			_ = exchange(t, ri, withCache, req)
+			assert.Equal(t, tc.wantSubnet, gotSubnet)
+		})
+	}
+}
+
 // assertEDNSOpt is a helper function that checks ECS and EDNS0 options.
 func assertEDNSOpt(t *testing.T, ecs *dnsmsg.ECS, edns *dns.OPT) {
 	t.Helper()
@@ -680,14 +857,15 @@ func newWithCache(
 	return dnsserver.WithMiddlewares(
 		h,
 		ecscache.NewMiddleware(&ecscache.MiddlewareConfig{
-			Cloner:       agdtest.NewCloner(),
-			Logger:       slogutil.NewDiscardLogger(),
-			CacheManager: agdcache.EmptyManager{},
-			GeoIP:        geoIP,
-			NoECSCount:   100,
-			ECSCount:     100,
-			MinTTL:       minTTL,
-			OverrideTTL:  useTTLOverride,
+			Cloner:          agdtest.NewCloner(),
+			Logger:          slogutil.NewDiscardLogger(),
+			CacheManager:    agdcache.EmptyManager{},
+			GeoIP:           geoIP,
+			SnapshotManager: snapshot.EmptyManager{},
+			NoECSCount:      100,
+			ECSCount:        100,
+			MinTTL:          minTTL,
+			OverrideTTL:     useTTLOverride,
 		}),
 	)
 }
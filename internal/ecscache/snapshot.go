@@ -0,0 +1,207 @@
+package ecscache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/snapshot"
+	"github.com/miekg/dns"
+)
+
+// snapshotName is the name of the cache in the runtime state snapshots.
+const snapshotName = "ecscache"
+
+// snapshotItem is the representation of a cached response in a runtime state
+// snapshot.  The cache keys are not saved, since the hash seed changes between
+// runs, so all data necessary to recalculate them is saved instead.
+type snapshotItem struct {
+	// When is the time when the response was cached.
+	When time.Time `json:"when"`
+
+	// Subnet is the network of the request.
+	Subnet netip.Prefix `json:"subnet"`
+
+	// Host is the normalized hostname of the request.
+	Host string `json:"host"`
+
+	// FilteringGroup is the ID of the filtering group of the request.
+	FilteringGroup agd.FilteringGroupID `json:"filtering_group"`
+
+	// View is the name of the view of the request, if any.
+	View agd.ViewName `json:"view,omitempty"`
+
+	// Msg is the cached response in the wire format.
+	Msg []byte `json:"msg"`
+
+	// IsFiltered is true if the response is in the partition for the filtered
+	// requests.
+	IsFiltered bool `json:"is_filtered"`
+
+	// IsECSDependent is true if the response is in the ECS-aware cache.
+	IsECSDependent bool `json:"is_ecs_dependent"`
+
+	// ReqDO is the state of DNSSEC OK bit from the request.
+	ReqDO bool `json:"req_do"`
+
+	// IsECSDeclined shows if the client of the request declined the use of
+	// ECS.
+	IsECSDeclined bool `json:"is_ecs_declined"`
+}
+
+// type check
+var _ snapshot.Component = (*Middleware)(nil)
+
+// Snapshot implements the [snapshot.Component] interface for *Middleware.  The
+// items that cannot be packed are skipped.
+func (mw *Middleware) Snapshot(ctx context.Context) (state any, err error) {
+	var items []*snapshotItem
+	for _, p := range []*cachePartition{mw.unfiltered, mw.filtered} {
+		if p == nil {
+			continue
+		}
+
+		isFiltered := p == mw.filtered
+		items = appendSnapshotItems(items, p.cache, isFiltered, false)
+		items = appendSnapshotItems(items, p.ecsCache, isFiltered, true)
+	}
+
+	mw.logger.DebugContext(ctx, "taking snapshot", "items", len(items))
+
+	return items, nil
+}
+
+// appendSnapshotItems appends the snapshot items for the unexpired responses
+// from cache to orig.
+func appendSnapshotItems(
+	orig []*snapshotItem,
+	cache agdcache.Interface[uint64, *cacheItem],
+	isFiltered bool,
+	isECSDependent bool,
+) (res []*snapshotItem) {
+	res = orig
+	r, ok := cache.(agdcache.Ranger[uint64, *cacheItem])
+	if !ok {
+		return res
+	}
+
+	r.Range(func(_ uint64, item *cacheItem) (cont bool) {
+		b, err := item.msg.Pack()
+		if err != nil {
+			// Skip the responses that cannot be packed, since there is nothing
+			// to restore them from.
+			return true
+		}
+
+		res = append(res, &snapshotItem{
+			When:           item.when,
+			Subnet:         item.subnet,
+			Host:           item.host,
+			FilteringGroup: item.fltGrp,
+			View:           item.view,
+			Msg:            b,
+			IsFiltered:     isFiltered,
+			IsECSDependent: isECSDependent,
+			ReqDO:          item.reqDO,
+			IsECSDeclined:  item.isECSDeclined,
+		})
+
+		return true
+	})
+
+	return res
+}
+
+// Restore implements the [snapshot.Component] interface for *Middleware.  The
+// expired items, the invalid items, and the items of the partitions that are
+// disabled are skipped.
+func (mw *Middleware) Restore(ctx context.Context, data []byte) (err error) {
+	var items []*snapshotItem
+	err = json.Unmarshal(data, &items)
+	if err != nil {
+		return fmt.Errorf("decoding items: %w", err)
+	}
+
+	// Restore the older items first so that the newer ones are the most
+	// recently used ones in the LRU caches.
+	slices.SortFunc(items, func(a, b *snapshotItem) (res int) {
+		return a.When.Compare(b.When)
+	})
+
+	now := time.Now()
+	restored := 0
+	for _, item := range items {
+		if mw.restoreItem(item, now) {
+			restored++
+		}
+	}
+
+	for _, p := range []*cachePartition{mw.unfiltered, mw.filtered} {
+		if p != nil {
+			mw.updateSizeMetrics(p, false)
+			mw.updateSizeMetrics(p, true)
+		}
+	}
+
+	mw.logger.InfoContext(ctx, "restored snapshot", "items", restored, "total", len(items))
+
+	return nil
+}
+
+// restoreItem adds the response from item into the cache, if it's valid and
+// not expired yet.  ok is true if the item has been added.  item must not be
+// nil.
+func (mw *Middleware) restoreItem(item *snapshotItem, now time.Time) (ok bool) {
+	outcome := agd.FilteringOutcomeUnfiltered
+	if item.IsFiltered {
+		outcome = agd.FilteringOutcomeFiltered
+	}
+
+	p := mw.partition(outcome)
+	if p == nil {
+		return false
+	}
+
+	msg := &dns.Msg{}
+	err := msg.Unpack(item.Msg)
+	if err != nil || len(msg.Question) != 1 {
+		return false
+	}
+
+	ttl := time.Duration(dnsmsg.FindLowestTTL(msg)) * time.Second
+	exp := item.When.Add(ttl).Sub(now)
+	if exp <= 0 {
+		return false
+	}
+
+	q := msg.Question[0]
+	cr := &cacheRequest{
+		subnet:        item.Subnet,
+		host:          item.Host,
+		fltGrp:        item.FilteringGroup,
+		view:          item.View,
+		qType:         q.Qtype,
+		qClass:        q.Qclass,
+		outcome:       outcome,
+		reqDO:         item.ReqDO,
+		isECSDeclined: item.IsECSDeclined,
+	}
+
+	cache := p.cache
+	if item.IsECSDependent {
+		cache = p.ecsCache
+	}
+
+	cached := toCacheItem(msg, cr)
+	cached.when = item.When
+
+	cache.SetWithExpire(mw.toCacheKey(cr, item.IsECSDependent), cached, exp)
+
+	return true
+}
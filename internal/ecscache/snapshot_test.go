@@ -0,0 +1,97 @@
+package ecscache_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/netip"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/ecscache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/snapshot"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/netutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddleware_Restore(t *testing.T) {
+	const ctry = geoip.CountryAD
+
+	ansIP := netip.MustParseAddr("192.0.2.1")
+
+	numReq := 0
+	handler := dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		numReq++
+
+		resp := dnsservertest.NewResp(dns.RcodeSuccess, req, dnsservertest.SectionAnswer{
+			dnsservertest.NewA(reqHostname, defaultTTL, ansIP),
+		})
+
+		return rw.WriteMsg(ctx, req, resp)
+	})
+
+	geoIP := agdtest.NewGeoIP()
+	geoIP.OnSubnetByLocation = func(
+		_ *geoip.Location,
+		_ netutil.AddrFamily,
+	) (n netip.Prefix, err error) {
+		return netutil.ZeroPrefix(netutil.AddrFamilyIPv4), nil
+	}
+
+	newMw := func() (mw *ecscache.Middleware) {
+		return ecscache.NewMiddleware(&ecscache.MiddlewareConfig{
+			Cloner:          agdtest.NewCloner(),
+			Logger:          slogutil.NewDiscardLogger(),
+			CacheManager:    agdcache.EmptyManager{},
+			GeoIP:           geoIP,
+			SnapshotManager: snapshot.EmptyManager{},
+			NoECSCount:      100,
+			ECSCount:        100,
+		})
+	}
+
+	ri := &agd.RequestInfo{
+		Location: &geoip.Location{Country: ctry},
+		Host:     reqHostname,
+		RemoteIP: remoteIP,
+		QType:    dns.TypeA,
+		QClass:   dns.ClassINET,
+	}
+	req := dnsservertest.NewReq(reqHostname, dns.TypeA, dns.ClassINET)
+
+	saved := newMw()
+	_ = exchange(t, ri, saved.Wrap(handler), req)
+	require.Equal(t, 1, numReq)
+
+	ctx := context.Background()
+	state, err := saved.Snapshot(ctx)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(state)
+	require.NoError(t, err)
+
+	restored := newMw()
+	err = restored.Restore(ctx, data)
+	require.NoError(t, err)
+
+	resp := exchange(t, ri, restored.Wrap(handler), req)
+	assert.Equal(t, 1, numReq)
+
+	require.Len(t, resp.Answer, 1)
+
+	a := testutil.RequireTypeAssert[*dns.A](t, resp.Answer[0])
+	assert.Equal(t, ansIP.AsSlice(), []byte(a.A.To4()))
+	assert.LessOrEqual(t, a.Hdr.Ttl, defaultTTL)
+}
@@ -0,0 +1,107 @@
+package errcoll
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/log"
+)
+
+// LimitedErrorCollectorConfig is the configuration structure for a
+// *LimitedErrorCollector.
+type LimitedErrorCollectorConfig struct {
+	// Next is the collector to which the errors are passed.  It must not be
+	// nil.
+	Next Interface
+
+	// Clock is used to get the current time.  It must not be nil.
+	Clock agdtime.Clock
+
+	// Window is the duration of the window within which the errors are
+	// deduplicated and counted.  It must be positive.
+	Window time.Duration
+
+	// Limit is the maximum number of errors passed to Next within a window.  It
+	// must be positive.
+	Limit uint
+}
+
+// LimitedErrorCollector is an [Interface] implementation that deduplicates
+// errors and limits the rate at which they are passed to the underlying
+// collector.  Errors with the same message are only passed once within a
+// window.
+type LimitedErrorCollector struct {
+	clock agdtime.Clock
+	next  Interface
+
+	// mu protects seen, windowStart, and count.
+	mu   *sync.Mutex
+	seen *container.MapSet[string]
+
+	windowStart time.Time
+	window      time.Duration
+	limit       uint
+	count       uint
+}
+
+// NewLimitedErrorCollector returns a new properly initialized
+// *LimitedErrorCollector.  c must be valid.
+func NewLimitedErrorCollector(c *LimitedErrorCollectorConfig) (coll *LimitedErrorCollector) {
+	return &LimitedErrorCollector{
+		clock:  c.Clock,
+		next:   c.Next,
+		window: c.Window,
+		limit:  c.Limit,
+		mu:     &sync.Mutex{},
+		seen:   container.NewMapSet[string](),
+	}
+}
+
+// type check
+var _ ErrorFlushCollector = (*LimitedErrorCollector)(nil)
+
+// Collect implements the [Interface] interface for *LimitedErrorCollector.
+func (c *LimitedErrorCollector) Collect(ctx context.Context, err error) {
+	if !c.allow(err.Error()) {
+		log.Debug("errcoll: limited: dropping error: %s", err)
+
+		return
+	}
+
+	c.next.Collect(ctx, err)
+}
+
+// allow returns true if the error with the given message should be passed to
+// the underlying collector.
+func (c *LimitedErrorCollector) allow(msg string) (ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	if now.Sub(c.windowStart) >= c.window {
+		c.windowStart = now
+		c.count = 0
+		c.seen.Clear()
+	}
+
+	if c.count >= c.limit || c.seen.Has(msg) {
+		return false
+	}
+
+	c.count++
+	c.seen.Add(msg)
+
+	return true
+}
+
+// Flush implements the [ErrorFlushCollector] interface for
+// *LimitedErrorCollector.  It flushes the underlying collector, if it
+// implements [ErrorFlushCollector].
+func (c *LimitedErrorCollector) Flush() {
+	if f, ok := c.next.(ErrorFlushCollector); ok {
+		f.Flush()
+	}
+}
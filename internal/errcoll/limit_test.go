@@ -0,0 +1,47 @@
+package errcoll_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimitedErrorCollector(t *testing.T) {
+	const window = time.Minute
+
+	var collected []string
+	next := &agdtest.ErrorCollector{
+		OnCollect: func(_ context.Context, err error) {
+			collected = append(collected, err.Error())
+		},
+	}
+
+	now := time.Now()
+	c := errcoll.NewLimitedErrorCollector(&errcoll.LimitedErrorCollectorConfig{
+		Next: next,
+		Clock: &agdtest.Clock{
+			OnNow: func() (n time.Time) { return now },
+		},
+		Window: window,
+		Limit:  2,
+	})
+
+	ctx := context.Background()
+	c.Collect(ctx, errors.Error("first"))
+	c.Collect(ctx, errors.Error("first"))
+	c.Collect(ctx, errors.Error("second"))
+	c.Collect(ctx, errors.Error("third"))
+
+	assert.Equal(t, []string{"first", "second"}, collected)
+
+	now = now.Add(window)
+	c.Collect(ctx, errors.Error("first"))
+	c.Collect(ctx, errors.Error("third"))
+
+	assert.Equal(t, []string{"first", "second", "first", "third"}, collected)
+}
@@ -0,0 +1,264 @@
+package errcoll
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/log"
+)
+
+// SyslogErrorCollectorConfig is the configuration structure for a
+// *SyslogErrorCollector.
+type SyslogErrorCollectorConfig struct {
+	// Clock is used to get the timestamps of the messages.  It must not be nil.
+	Clock agdtime.Clock
+
+	// Network is the network of the syslog server, either "udp" or "tcp".
+	Network string
+
+	// Address is the address of the syslog server.  It must not be empty.
+	Address string
+
+	// Hostname is the name of the host sent in the messages.  If empty, the
+	// NILVALUE is used.
+	Hostname string
+
+	// AppName is the name of the application sent in the messages.  If empty,
+	// the NILVALUE is used.
+	AppName string
+
+	// Metrics is used to collect the statistics.  It must not be nil.
+	Metrics SyslogMetrics
+
+	// Timeout is the timeout for connecting and writing to the server.  It
+	// must be positive.
+	Timeout time.Duration
+
+	// QueueSize is the number of messages that can wait to be sent.  The
+	// messages that don't fit are dropped.  It must be positive.
+	QueueSize int
+}
+
+// SyslogMetrics is an interface for monitoring the syslog error collector.
+type SyslogMetrics interface {
+	// IncrementDropped is called when a message is dropped, because the queue
+	// is full.
+	IncrementDropped(ctx context.Context)
+}
+
+// EmptySyslogMetrics is an empty [SyslogMetrics] implementation that does
+// nothing.
+type EmptySyslogMetrics struct{}
+
+// type check
+var _ SyslogMetrics = EmptySyslogMetrics{}
+
+// IncrementDropped implements the [SyslogMetrics] interface for
+// EmptySyslogMetrics.
+func (EmptySyslogMetrics) IncrementDropped(_ context.Context) {}
+
+// SyslogErrorCollector is an [Interface] implementation that sends errors to a
+// syslog server using the format from RFC 5424.  Over TCP, the messages are
+// framed using the octet counting from RFC 6587.
+//
+// The messages are sent by a separate goroutine, so that slow or unavailable
+// servers don't block the callers of Collect.
+type SyslogErrorCollector struct {
+	clock    agdtime.Clock
+	metrics  SyslogMetrics
+	queue    chan []byte
+	network  string
+	address  string
+	hostname string
+	appName  string
+	procID   string
+	timeout  time.Duration
+
+	// conn is only used by the sending goroutine.
+	conn net.Conn
+}
+
+// NewSyslogErrorCollector returns a new properly initialized
+// *SyslogErrorCollector and starts its sending goroutine, which runs for the
+// lifetime of the process.  c must be valid.  The connection is established
+// lazily.
+func NewSyslogErrorCollector(c *SyslogErrorCollectorConfig) (coll *SyslogErrorCollector) {
+	coll = &SyslogErrorCollector{
+		clock:    c.Clock,
+		metrics:  c.Metrics,
+		queue:    make(chan []byte, c.QueueSize),
+		network:  c.Network,
+		address:  c.Address,
+		hostname: syslogHeaderValue(c.Hostname, 255),
+		appName:  syslogHeaderValue(c.AppName, 48),
+		procID:   strconv.Itoa(os.Getpid()),
+		timeout:  c.Timeout,
+	}
+
+	go coll.send()
+
+	return coll
+}
+
+// type check
+var _ Interface = (*SyslogErrorCollector)(nil)
+
+// Collect implements the [Interface] interface for *SyslogErrorCollector.
+func (c *SyslogErrorCollector) Collect(ctx context.Context, err error) {
+	if !isReportable(err) {
+		log.Debug("errcoll: syslog: non-reportable error: %s", err)
+
+		return
+	}
+
+	msg := c.message(tagsFromCtx(ctx), err)
+
+	select {
+	case c.queue <- msg:
+		// Go on.
+	default:
+		c.metrics.IncrementDropped(ctx)
+		log.Debug("errcoll: syslog: queue is full, dropping error: %s", err)
+	}
+}
+
+// send sends the queued messages to the server.  It is intended to be used as
+// a goroutine.
+func (c *SyslogErrorCollector) send() {
+	defer log.OnPanic("errcoll: syslog")
+
+	for msg := range c.queue {
+		err := c.write(msg)
+		if err != nil {
+			log.Error("errcoll: syslog: sending message: %s", err)
+		}
+	}
+}
+
+// Syslog message constants.
+const (
+	// syslogPriority is the priority of the messages: facility 3, "system
+	// daemons", and severity 3, "error".  See RFC 5424, Section 6.2.1.
+	syslogPriority = 3*8 + 3
+
+	// syslogNilValue is the NILVALUE of the RFC 5424 format.
+	syslogNilValue = "-"
+
+	// syslogSDID is the ID of the structured data element containing the
+	// tags.  32473 is the private enterprise number reserved for
+	// documentation, see RFC 5612.
+	syslogSDID = "tags@32473"
+)
+
+// message returns the RFC 5424 message for err with tags.
+func (c *SyslogErrorCollector) message(tags sentryTags, err error) (msg []byte) {
+	b := &bytes.Buffer{}
+
+	// HEADER.
+	_, _ = fmt.Fprintf(
+		b,
+		"<%d>1 %s %s %s %s %s ",
+		syslogPriority,
+		c.clock.Now().UTC().Format(time.RFC3339Nano),
+		c.hostname,
+		c.appName,
+		c.procID,
+		syslogNilValue,
+	)
+
+	// STRUCTURED-DATA.
+	if len(tags) == 0 {
+		_, _ = b.WriteString(syslogNilValue)
+	} else {
+		_, _ = b.WriteString("[" + syslogSDID)
+		for _, k := range slices.Sorted(maps.Keys(tags)) {
+			_, _ = fmt.Fprintf(b, " %s=\"%s\"", k, escapeSDParam(tags[k]))
+		}
+
+		_ = b.WriteByte(']')
+	}
+
+	// MSG.
+	_ = b.WriteByte(' ')
+	_, _ = b.WriteString(err.Error())
+
+	return b.Bytes()
+}
+
+// sdParamReplacer escapes the characters that must be escaped in the values of
+// structured data parameters.  See RFC 5424, Section 6.3.3.
+var sdParamReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// escapeSDParam escapes the value of a structured data parameter.
+func escapeSDParam(v string) (escaped string) {
+	return sdParamReplacer.Replace(v)
+}
+
+// syslogHeaderValue returns v in a form suitable for the HEADER fields, which
+// can only contain printable US-ASCII characters and have a maximum length.
+func syslogHeaderValue(v string, maxLen int) (res string) {
+	v = strings.Map(func(r rune) (res rune) {
+		if r < '!' || r > '~' {
+			return -1
+		}
+
+		return r
+	}, v)
+
+	if v == "" {
+		return syslogNilValue
+	}
+
+	return v[:min(len(v), maxLen)]
+}
+
+// write writes msg to the server, connecting or reconnecting if necessary.  It
+// must only be called by the sending goroutine.
+func (c *SyslogErrorCollector) write(msg []byte) (err error) {
+	if c.network == "tcp" {
+		msg = append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+
+	// Try to reconnect once, since the server could have closed the previous
+	// connection.
+	for range 2 {
+		if c.conn == nil {
+			c.conn, err = net.DialTimeout(c.network, c.address, c.timeout)
+			if err != nil {
+				return fmt.Errorf("connecting: %w", err)
+			}
+		}
+
+		err = c.writeConn(msg)
+		if err == nil {
+			return nil
+		}
+
+		err = errors.WithDeferred(err, c.conn.Close())
+		c.conn = nil
+	}
+
+	return fmt.Errorf("writing: %w", err)
+}
+
+// writeConn writes msg into the current connection.
+func (c *SyslogErrorCollector) writeConn(msg []byte) (err error) {
+	err = c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	if err != nil {
+		return fmt.Errorf("setting deadline: %w", err)
+	}
+
+	_, err = c.conn.Write(msg)
+
+	return err
+}
@@ -0,0 +1,51 @@
+package errcoll_test
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyslogErrorCollector(t *testing.T) {
+	const timeout = time.Second
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, conn.Close)
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := errcoll.NewSyslogErrorCollector(&errcoll.SyslogErrorCollectorConfig{
+		Clock: &agdtest.Clock{
+			OnNow: func() (n time.Time) { return now },
+		},
+		Metrics:   errcoll.EmptySyslogMetrics{},
+		Network:   "udp",
+		Address:   conn.LocalAddr().String(),
+		Hostname:  "dns host",
+		AppName:   "adguard-dns",
+		Timeout:   timeout,
+		QueueSize: 1,
+	})
+
+	c.Collect(context.Background(), errors.Error("test error"))
+
+	err = conn.SetReadDeadline(time.Now().Add(timeout))
+	require.NoError(t, err)
+
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+
+	wantRx := `^<27>1 2026-01-02T03:04:05Z dnshost adguard-dns \d+ - ` +
+		`\[tags@32473 git_revision="[^"]*"\] test error$`
+	assert.Regexp(t, regexp.MustCompile(wantRx), string(buf[:n]))
+}
@@ -1,6 +1,10 @@
 package filter
 
-import "github.com/AdguardTeam/AdGuardDNS/internal/filter/internal"
+import (
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal"
+)
 
 // Config is the sum type of [Storage.ForConfig] configurations.
 //
@@ -14,6 +18,11 @@ type Config interface {
 
 // ConfigClient is a [Config] for a client.
 type ConfigClient struct {
+	// BlockPage is the configuration of the block page of the client.  If it
+	// is nil, the global replacement hosts of the safe-browsing and
+	// parental-control filters are used.
+	BlockPage *ConfigBlockPage
+
 	// Custom is the configuration for identification or construction of a
 	// custom filter for a client.  It must not be nil.
 	Custom *ConfigCustom
@@ -29,6 +38,18 @@ type ConfigClient struct {
 	// SafeBrowsing is the configuration for safe-browsing filtering.  It must
 	// not be nil.
 	SafeBrowsing *ConfigSafeBrowsing
+
+	// BlockUntil is the time until which all requests of the client are
+	// blocked, except those explicitly allowed by the custom rules and the
+	// essential-services list.  It is used for temporary overrides, such as
+	// blocking the internet for an hour, and the override expires on its own.
+	// If it is zero or in the past, no override is applied.
+	BlockUntil time.Time
+
+	// AllowlistOnly shows whether the client is in the allowlist-only mode, in
+	// which all requests are blocked except those explicitly allowed by the
+	// custom rules and the essential-services list.
+	AllowlistOnly bool
 }
 
 // type check
@@ -37,6 +58,10 @@ var _ Config = (*ConfigClient)(nil)
 // isConfig implements the [Config] interface for *ConfigClient.
 func (*ConfigClient) isConfig() {}
 
+// ConfigBlockPage is the configuration of the addresses of the block page of an
+// operator.
+type ConfigBlockPage = internal.ConfigBlockPage
+
 // ConfigCustom is the configuration for identification or construction of a
 // custom filter for a client.
 type ConfigCustom = internal.ConfigCustom
@@ -53,6 +78,13 @@ type ConfigParental struct {
 	// [ConfigParental.Enabled] is false.
 	BlockedServices []BlockedServiceID
 
+	// LearningPeriod is the duration of the learning mode of the devices after
+	// their creation.  During the learning mode, the requests matched by the
+	// adult-blocking filter and the blocked services are recorded but not
+	// blocked.  If it is zero, the devices are never in the learning mode.
+	// It is ignored if [ConfigParental.Enabled] is false.
+	LearningPeriod time.Duration
+
 	// Enabled shows whether the parental-control feature is enabled.
 	Enabled bool
 
@@ -98,6 +130,11 @@ type ConfigSafeBrowsing struct {
 
 // ConfigGroup is a [Config] for a filtering group.
 type ConfigGroup struct {
+	// BlockPage is the configuration of the block page of the filtering group.
+	// If it is nil, the global replacement hosts of the safe-browsing and
+	// parental-control filters are used.
+	BlockPage *ConfigBlockPage
+
 	// Parental is the configuration for parental-control filtering.  It must
 	// not be nil.
 	Parental *ConfigParental
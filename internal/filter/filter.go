@@ -59,14 +59,20 @@ type ID = internal.ID
 const (
 	IDNone = internal.IDNone
 
-	IDAdGuardDNS        = internal.IDAdGuardDNS
-	IDAdultBlocking     = internal.IDAdultBlocking
-	IDBlockedService    = internal.IDBlockedService
-	IDCustom            = internal.IDCustom
-	IDGeneralSafeSearch = internal.IDGeneralSafeSearch
-	IDNewRegDomains     = internal.IDNewRegDomains
-	IDSafeBrowsing      = internal.IDSafeBrowsing
-	IDYoutubeSafeSearch = internal.IDYoutubeSafeSearch
+	IDAdGuardDNS         = internal.IDAdGuardDNS
+	IDAdultBlocking      = internal.IDAdultBlocking
+	IDAllowlistOnly      = internal.IDAllowlistOnly
+	IDBlockUntil         = internal.IDBlockUntil
+	IDBlockedService     = internal.IDBlockedService
+	IDCustom             = internal.IDCustom
+	IDEssentialServices  = internal.IDEssentialServices
+	IDGeneralSafeSearch  = internal.IDGeneralSafeSearch
+	IDNewRegDomains      = internal.IDNewRegDomains
+	IDQuarantine         = internal.IDQuarantine
+	IDQueryType          = internal.IDQueryType
+	IDSafeBrowsing       = internal.IDSafeBrowsing
+	IDTemporaryException = internal.IDTemporaryException
+	IDYoutubeSafeSearch  = internal.IDYoutubeSafeSearch
 )
 
 // NewID converts a simple string into an ID and makes sure that it's valid.
@@ -94,21 +100,61 @@ func NewBlockedServiceID(s string) (id BlockedServiceID, err error) {
 	return internal.NewBlockedServiceID(s)
 }
 
-// HashMatcher is the interface for a safe-browsing and adult-blocking hash
-// matcher, which is used to respond to a TXT query based on the domain name.
+// Category is the content category of a domain name, for example
+// "social_network".  It is an opaque string.
+type Category = internal.Category
+
+// Classifier is the interface for entities that find the content categories of
+// hosts regardless of whether the requests for them are blocked.
+type Classifier = internal.Classifier
+
+// EmptyClassifier is the implementation of the [Classifier] interface that
+// never returns any categories.
+type EmptyClassifier = internal.EmptyClassifier
+
+// HashMatcher is the interface for a safe-browsing, adult-blocking, and
+// content-category hash matcher, which is used to respond to a TXT query based
+// on the domain name.
 type HashMatcher interface {
 	MatchByPrefix(ctx context.Context, host string) (hashes []string, matched bool, err error)
 }
 
+// type check
+var _ HashMatcher = EmptyHashMatcher{}
+
+// EmptyHashMatcher is the implementation of the [HashMatcher] interface that
+// never matches any hosts.
+type EmptyHashMatcher struct{}
+
+// MatchByPrefix implements the [HashMatcher] interface for EmptyHashMatcher.
+// matched is always false.
+func (EmptyHashMatcher) MatchByPrefix(
+	_ context.Context,
+	_ string,
+) (hashes []string, matched bool, err error) {
+	return nil, false, nil
+}
+
 // Default safe-browsing host suffixes.
 const (
 	GeneralTXTSuffix       = ".sb.dns.adguard.com"
 	AdultBlockingTXTSuffix = ".pc.dns.adguard.com"
 )
 
+// CategoryTXTSuffix is the default host suffix of the content-category
+// lookups.
+const CategoryTXTSuffix = ".ct.dns.adguard.com"
+
 // Metrics is the interface for metrics of filters.
 type Metrics = internal.Metrics
 
+// Refresh types for [Metrics.IncrementRefresh].
+const (
+	RefreshTypeFull        = internal.RefreshTypeFull
+	RefreshTypeDelta       = internal.RefreshTypeDelta
+	RefreshTypeNotModified = internal.RefreshTypeNotModified
+)
+
 // EmptyMetrics is the implementation of the [Metrics] interface that does
 // nothing.
 type EmptyMetrics = internal.EmptyMetrics
@@ -0,0 +1,143 @@
+package filterstorage
+
+import (
+	"context"
+	"fmt"
+	"hash/maphash"
+	"path"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/rulelist"
+)
+
+// ruleListsSnapshot is an immutable snapshot of the rule-list filters of a
+// storage along with the combined filters built from them.
+type ruleListsSnapshot struct {
+	// lists are the rule-list filters by their IDs.
+	lists ruleLists
+
+	// combined are the combined filters by the keys of their rule-list IDs.
+	combined map[combinedKey]*rulelist.Combined
+}
+
+// newRuleListsSnapshotPtr returns a pointer to an empty snapshot.
+func newRuleListsSnapshotPtr() (p *atomic.Pointer[ruleListsSnapshot]) {
+	p = &atomic.Pointer[ruleListsSnapshot]{}
+	p.Store(&ruleListsSnapshot{})
+
+	return p
+}
+
+// combinedKey is the key of an ordered combination of rule-list IDs.
+type combinedKey uint64
+
+// newCombinedKey returns the key for the IDs of lists.
+func newCombinedKey(seed maphash.Seed, lists []*rulelist.Refreshable) (k combinedKey) {
+	h := &maphash.Hash{}
+	h.SetSeed(seed)
+	for _, rl := range lists {
+		id, _ := rl.ID()
+		_, _ = h.WriteString(string(id))
+		_ = h.WriteByte(0)
+	}
+
+	return combinedKey(h.Sum64())
+}
+
+// combined returns the combined filter for lists from snap, if there is one.
+// Otherwise, it requests the combined filter to be built during the next
+// refresh and returns nil.
+func (s *Default) combined(
+	snap *ruleListsSnapshot,
+	lists []*rulelist.Refreshable,
+) (c *rulelist.Combined) {
+	if s.maxCombined == 0 || len(lists) < 2 {
+		return nil
+	}
+
+	k := newCombinedKey(s.combinedSeed, lists)
+	c = snap.combined[k]
+	if c != nil && c.Matches(lists) {
+		return c
+	}
+
+	s.combinedReqsMu.Lock()
+	defer s.combinedReqsMu.Unlock()
+
+	if _, ok := s.combinedReqs[k]; ok || len(s.combinedReqs) >= s.maxCombined {
+		return nil
+	}
+
+	ids := make([]filter.ID, 0, len(lists))
+	for _, rl := range lists {
+		id, _ := rl.ID()
+		ids = append(ids, id)
+	}
+
+	s.combinedReqs[k] = ids
+
+	return nil
+}
+
+// buildCombined builds the requested combined filters from lists and reports
+// the metrics.  Combinations with missing rule lists are skipped.
+func (s *Default) buildCombined(
+	ctx context.Context,
+	lists ruleLists,
+) (combined map[combinedKey]*rulelist.Combined) {
+	if s.maxCombined == 0 {
+		return nil
+	}
+
+	s.combinedReqsMu.Lock()
+	defer s.combinedReqsMu.Unlock()
+
+	combined = make(map[combinedKey]*rulelist.Combined, len(s.combinedReqs))
+
+	var ruleCount int
+	var size uint64
+	start := time.Now()
+	for _, ids := range s.combinedReqs {
+		rls := make([]*rulelist.Refreshable, 0, len(ids))
+		for _, id := range ids {
+			if rl := lists[id]; rl != nil {
+				rls = append(rls, rl)
+			}
+		}
+
+		if len(rls) != len(ids) {
+			continue
+		}
+
+		cacheID := path.Join(cachePrefixRuleListCombined, strconv.Itoa(len(combined)))
+		cache := rulelist.NewManagedResultCache(
+			s.cacheManager,
+			cacheID,
+			s.ruleListResCacheCount,
+			s.ruleListCacheEnabled,
+		)
+
+		c, err := rulelist.NewCombined(rls, cache)
+		if err != nil {
+			err = fmt.Errorf("combining rule lists %q: %w", ids, err)
+			errcoll.Collect(ctx, s.errColl, s.logger, "combined rule lists", err)
+
+			continue
+		}
+
+		combined[newCombinedKey(s.combinedSeed, rls)] = c
+		ruleCount += c.RulesCount()
+		size += c.Size()
+	}
+
+	dur := time.Since(start)
+	s.metrics.SetCombinedStatus(ctx, len(combined), ruleCount, size, dur)
+
+	s.logger.InfoContext(ctx, "combined lists", "num", len(combined), "elapsed", dur)
+
+	return combined
+}
@@ -0,0 +1,123 @@
+package filterstorage_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/filterstorage"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/filtertest"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// combinedMetrics is a [filter.Metrics] implementation that sends the numbers
+// of the combined filters into a channel.
+type combinedMetrics struct {
+	filter.EmptyMetrics
+
+	nums chan int
+}
+
+// SetCombinedStatus implements the [filter.Metrics] interface for
+// *combinedMetrics.
+func (m *combinedMetrics) SetCombinedStatus(
+	_ context.Context,
+	num int,
+	_ int,
+	_ uint64,
+	_ time.Duration,
+) {
+	m.nums <- num
+}
+
+func TestDefault_ForConfig_combined(t *testing.T) {
+	const (
+		allowRuleText = "@@" + filtertest.RuleBlockStr
+		allowRule     = allowRuleText + "\n"
+		blockRule     = filtertest.RuleBlockStr + "\n"
+	)
+
+	_, blockURL := filtertest.PrepareRefreshable(t, nil, blockRule, http.StatusOK)
+	_, allowURL := filtertest.PrepareRefreshable(t, nil, allowRule, http.StatusOK)
+
+	rlIdxData := errors.Must(json.Marshal(map[string]any{
+		"filters": []map[string]any{{
+			"filterKey":   filtertest.RuleListID1Str,
+			"downloadUrl": blockURL.String(),
+		}, {
+			"filterKey":   filtertest.RuleListID2Str,
+			"downloadUrl": allowURL.String(),
+		}},
+	}))
+
+	_, ruleListIdxURL := filtertest.PrepareRefreshable(t, nil, string(rlIdxData), http.StatusOK)
+
+	mtrc := &combinedMetrics{
+		nums: make(chan int, 2),
+	}
+
+	rlConf := newConfigRuleLists(ruleListIdxURL)
+	rlConf.MaxCombined = 1
+
+	c := newDisabledConfig(t, rlConf)
+	c.Metrics = mtrc
+
+	s, err := filterstorage.New(c)
+	require.NoError(t, err)
+
+	ctx := testutil.ContextWithTimeout(t, filtertest.Timeout)
+	err = s.RefreshInitial(ctx)
+	require.NoError(t, err)
+
+	// No combinations have been requested yet.
+	num, _ := testutil.RequireReceive(t, mtrc.nums, filtertest.Timeout)
+	assert.Equal(t, 0, num)
+
+	fltConf := &filter.ConfigGroup{
+		Parental: newFltConfigParental(false, false, false, false),
+		RuleList: &filter.ConfigRuleList{
+			IDs: []filter.ID{
+				filtertest.RuleListID1,
+				filtertest.RuleListID2,
+			},
+			Enabled: true,
+		},
+		SafeBrowsing: newFltConfigSafeBrowsing(false, false),
+	}
+
+	wantRes := &filter.ResultAllowed{
+		List: filtertest.RuleListID2,
+		Rule: allowRuleText,
+	}
+
+	req := filtertest.NewARequest(t, filtertest.HostBlocked)
+
+	require.True(t, t.Run("separate", func(t *testing.T) {
+		ctx = testutil.ContextWithTimeout(t, filtertest.Timeout)
+		res, fltErr := s.ForConfig(ctx, fltConf).FilterRequest(ctx, req)
+		require.NoError(t, fltErr)
+
+		filtertest.AssertEqualResult(t, wantRes, res)
+	}))
+
+	ctx = testutil.ContextWithTimeout(t, filtertest.Timeout)
+	err = s.Refresh(ctx)
+	require.NoError(t, err)
+
+	num, _ = testutil.RequireReceive(t, mtrc.nums, filtertest.Timeout)
+	assert.Equal(t, 1, num)
+
+	require.True(t, t.Run("combined", func(t *testing.T) {
+		ctx = testutil.ContextWithTimeout(t, filtertest.Timeout)
+		res, fltErr := s.ForConfig(ctx, fltConf).FilterRequest(ctx, req)
+		require.NoError(t, fltErr)
+
+		filtertest.AssertEqualResult(t, wantRes, res)
+	}))
+}
@@ -31,6 +31,10 @@ type Config struct {
 	// filter storage.  It must not be nil
 	Custom *ConfigCustom
 
+	// EssentialServices is the configuration of the essential services allowed
+	// for clients in the allowlist-only mode.  It must not be nil.
+	EssentialServices *ConfigEssentialServices
+
 	// HashPrefix is the hashprefix-filter configuration for a default filter
 	// storage.  It must not be nil
 	HashPrefix *ConfigHashPrefix
@@ -74,6 +78,11 @@ type ConfigBlockedServices struct {
 	// [ConfigBlockedServices.Enabled] is false.
 	IndexURL *url.URL
 
+	// CategoryHashes, if not nil, is reset with the hashes of the domain names
+	// of the content categories on each refresh of the index.  It is ignored
+	// if [ConfigBlockedServices.Enabled] is false.
+	CategoryHashes *hashprefix.CategoryStorage
+
 	// IndexMaxSize is the maximum size of the downloadable blocked-service
 	// index content.  It must be positive.  It is ignored if
 	// [ConfigBlockedServices.Enabled] is false.
@@ -110,6 +119,16 @@ type ConfigCustom struct {
 	CacheCount int
 }
 
+// ConfigEssentialServices is the configuration of the essential services
+// allowed for clients in the allowlist-only mode.
+type ConfigEssentialServices struct {
+	// Domains are the domain names of the essential services.  Their
+	// subdomains are allowed as well.  All items must be valid domain names.
+	// If empty, all requests of clients in the allowlist-only mode are blocked
+	// unless allowed by their custom rules.
+	Domains []string
+}
+
 // ConfigHashPrefix is the hashprefix-filter configuration for a default filter
 // storage.
 type ConfigHashPrefix struct {
@@ -160,6 +179,13 @@ type ConfigRuleLists struct {
 	// a single rule-list filter.  It must be greater than zero.
 	ResultCacheCount int
 
+	// MaxCombined is the maximum number of combined rule-list filters, each of
+	// which uses a single engine for all rule lists of a filtering
+	// configuration.  The combined filters are built during refreshes for the
+	// combinations of rule lists requested since the start.  If it is zero,
+	// the combined filters are not used.  It must not be negative.
+	MaxCombined int
+
 	// ResultCacheEnabled enables caching of results of the rule-list filters.
 	ResultCacheEnabled bool
 }
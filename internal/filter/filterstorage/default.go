@@ -3,10 +3,13 @@ package filterstorage
 import (
 	"context"
 	"fmt"
+	"hash/maphash"
 	"log/slog"
 	"path"
 	"path/filepath"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
@@ -22,6 +25,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/serviceblock"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/stringutil"
 	"github.com/c2h5oh/datasize"
 )
 
@@ -36,6 +40,8 @@ type Default struct {
 
 	custom *custom.Filters
 
+	essential *rulelist.Immutable
+
 	adult           *hashprefix.Filter
 	dangerous       *hashprefix.Filter
 	newlyRegistered *hashprefix.Filter
@@ -43,9 +49,16 @@ type Default struct {
 	safeSearchGeneral *safesearch.Filter
 	safeSearchYouTube *safesearch.Filter
 
-	// ruleListsMu protects [Default.ruleLists].
-	ruleListsMu *sync.RWMutex
-	ruleLists   ruleLists
+	// ruleLists is the current immutable snapshot of the rule-list filters.
+	// It is replaced as a whole on each refresh.
+	ruleLists *atomic.Pointer[ruleListsSnapshot]
+
+	// combinedReqsMu protects [Default.combinedReqs].
+	combinedReqsMu *sync.Mutex
+
+	// combinedReqs are the combinations of the rule-list IDs, for which the
+	// combined filters should be built, by their keys.
+	combinedReqs map[combinedKey][]filter.ID
 
 	ruleListIdxRefr *refreshable.Refreshable
 
@@ -61,9 +74,14 @@ type Default struct {
 
 	ruleListMaxSize datasize.ByteSize
 
+	// combinedSeed is the seed for the keys of [Default.combinedReqs].
+	combinedSeed maphash.Seed
+
 	ruleListResCacheCount int
 	serviceResCacheCount  int
 
+	maxCombined int
+
 	ruleListCacheEnabled   bool
 	serviceResCacheEnabled bool
 }
@@ -86,6 +104,9 @@ func New(c *Config) (s *Default, err error) {
 		// Initialized in [Default.initCustom].
 		custom: nil,
 
+		// Initialized in [Default.initEssentialServices].
+		essential: nil,
+
 		adult:           c.HashPrefix.Adult,
 		dangerous:       c.HashPrefix.Dangerous,
 		newlyRegistered: c.HashPrefix.NewlyRegistered,
@@ -94,10 +115,11 @@ func New(c *Config) (s *Default, err error) {
 		safeSearchGeneral: nil,
 		safeSearchYouTube: nil,
 
-		ruleListsMu: &sync.RWMutex{},
+		// Set in [Default.RefreshInitial].
+		ruleLists: newRuleListsSnapshotPtr(),
 
-		// Initialized in [Default.RefreshInitial].
-		ruleLists: nil,
+		combinedReqsMu: &sync.Mutex{},
+		combinedReqs:   map[combinedKey][]filter.ID{},
 
 		// Initialized in [Default.initRuleListRefr].
 		ruleListIdxRefr: nil,
@@ -114,9 +136,13 @@ func New(c *Config) (s *Default, err error) {
 
 		ruleListMaxSize: c.RuleLists.MaxSize,
 
+		combinedSeed: maphash.MakeSeed(),
+
 		ruleListResCacheCount: c.RuleLists.ResultCacheCount,
 		serviceResCacheCount:  c.BlockedServices.ResultCacheCount,
 
+		maxCombined: c.RuleLists.MaxCombined,
+
 		ruleListCacheEnabled:   c.RuleLists.ResultCacheEnabled,
 		serviceResCacheEnabled: c.BlockedServices.ResultCacheEnabled,
 	}
@@ -135,6 +161,12 @@ func (s *Default) init(c *Config) (err error) {
 	s.initCustom(c.Custom)
 
 	var errs []error
+	err = s.initEssentialServices(c.EssentialServices)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		errs = append(errs, err)
+	}
+
 	err = s.initBlockedServices(c.BlockedServices)
 	if err != nil {
 		// Don't wrap the error, because it's informative enough as is.
@@ -171,6 +203,31 @@ func (s *Default) initCustom(c *ConfigCustom) {
 	})
 }
 
+// initEssentialServices initializes the essential-services filter in s.  c
+// must not be nil.
+func (s *Default) initEssentialServices(c *ConfigEssentialServices) (err error) {
+	if len(c.Domains) == 0 {
+		return nil
+	}
+
+	b := &strings.Builder{}
+	for _, d := range c.Domains {
+		stringutil.WriteToBuilder(b, "@@||", d, "^\n")
+	}
+
+	s.essential, err = rulelist.NewImmutable(
+		b.String(),
+		filter.IDEssentialServices,
+		"",
+		rulelist.ResultCacheEmpty{},
+	)
+	if err != nil {
+		return fmt.Errorf("essential services: %w", err)
+	}
+
+	return nil
+}
+
 // initBlockedServices initializes the blocked-service filter in s.  c must not
 // be nil.
 func (s *Default) initBlockedServices(c *ConfigBlockedServices) (err error) {
@@ -182,6 +239,7 @@ func (s *Default) initBlockedServices(c *ConfigBlockedServices) (err error) {
 		Logger: s.baseLogger.With(
 			slogutil.KeyPrefix, path.Join("filters", string(FilterIDBlockedServiceIndex)),
 		),
+		Metrics:   s.metrics,
 		URL:       c.IndexURL,
 		ID:        filter.ID(FilterIDBlockedServiceIndex),
 		CachePath: filepath.Join(s.cacheDir, indexFileNameBlockedServices),
@@ -191,9 +249,10 @@ func (s *Default) initBlockedServices(c *ConfigBlockedServices) (err error) {
 	}
 
 	s.services, err = serviceblock.New(&serviceblock.Config{
-		Refreshable: refrConf,
-		ErrColl:     s.errColl,
-		Metrics:     s.metrics,
+		Refreshable:    refrConf,
+		ErrColl:        s.errColl,
+		Metrics:        s.metrics,
+		CategoryHashes: c.CategoryHashes,
 	})
 	if err != nil {
 		return fmt.Errorf("blocked-service filter: %w", err)
@@ -205,12 +264,12 @@ func (s *Default) initBlockedServices(c *ConfigBlockedServices) (err error) {
 // initSafeSearch initializes the safe-search filters in s.  gen and yt must not
 // be nil.
 func (s *Default) initSafeSearch(gen, yt *ConfigSafeSearch) (err error) {
-	s.safeSearchGeneral, err = newSafeSearch(s.baseLogger, gen, s.cacheManager, s.cacheDir)
+	s.safeSearchGeneral, err = newSafeSearch(s.baseLogger, s.metrics, gen, s.cacheManager, s.cacheDir)
 	if err != nil {
 		return fmt.Errorf("general safe search: %w", err)
 	}
 
-	s.safeSearchYouTube, err = newSafeSearch(s.baseLogger, yt, s.cacheManager, s.cacheDir)
+	s.safeSearchYouTube, err = newSafeSearch(s.baseLogger, s.metrics, yt, s.cacheManager, s.cacheDir)
 	if err != nil {
 		return fmt.Errorf("youtube safe search: %w", err)
 	}
@@ -222,6 +281,7 @@ func (s *Default) initSafeSearch(gen, yt *ConfigSafeSearch) (err error) {
 // arguments must not be empty.
 func newSafeSearch(
 	baseLogger *slog.Logger,
+	mtrc filter.Metrics,
 	c *ConfigSafeSearch,
 	cacheMgr agdcache.Manager,
 	cacheDir string,
@@ -238,6 +298,7 @@ func newSafeSearch(
 		&safesearch.Config{
 			Refreshable: &refreshable.Config{
 				Logger:    baseLogger.With(slogutil.KeyPrefix, cacheID),
+				Metrics:   mtrc,
 				URL:       c.URL,
 				ID:        c.ID,
 				CachePath: filepath.Join(cacheDir, fltIDStr),
@@ -258,6 +319,7 @@ func (s *Default) initRuleListRefr(c *ConfigRuleLists) (err error) {
 		Logger: s.baseLogger.With(
 			slogutil.KeyPrefix, path.Join("filters", string(FilterIDRuleListIndex)),
 		),
+		Metrics:   s.metrics,
 		URL:       c.IndexURL,
 		ID:        filter.ID(FilterIDRuleListIndex),
 		CachePath: filepath.Join(s.cacheDir, indexFileNameRuleLists),
@@ -292,7 +354,9 @@ func (s *Default) ForConfig(ctx context.Context, c filter.Config) (f filter.Inte
 // forClient returns a new filter based on a client configuration.  c must not
 // be nil.
 func (s *Default) forClient(ctx context.Context, c *filter.ConfigClient) (f filter.Interface) {
-	compConf := &composite.Config{}
+	compConf := &composite.Config{
+		BlockPage: c.BlockPage,
+	}
 
 	s.setParental(ctx, compConf, c.Parental)
 	s.setRuleLists(compConf, c.RuleList)
@@ -300,6 +364,12 @@ func (s *Default) forClient(ctx context.Context, c *filter.ConfigClient) (f filt
 
 	compConf.Custom = s.custom.Get(ctx, c.Custom)
 
+	compConf.AllowlistOnly = c.AllowlistOnly
+	compConf.BlockAll = s.clock.Now().Before(c.BlockUntil)
+	if compConf.AllowlistOnly || compConf.BlockAll {
+		compConf.EssentialServices = s.essential
+	}
+
 	return composite.New(compConf)
 }
 
@@ -343,15 +413,15 @@ func (s *Default) setRuleLists(compConf *composite.Config, c *filter.ConfigRuleL
 		return
 	}
 
-	s.ruleListsMu.RLock()
-	defer s.ruleListsMu.RUnlock()
-
+	snap := s.ruleLists.Load()
 	for _, id := range c.IDs {
-		rl := s.ruleLists[id]
+		rl := snap.lists[id]
 		if rl != nil {
 			compConf.RuleLists = append(compConf.RuleLists, rl)
 		}
 	}
+
+	compConf.CombinedRuleLists = s.combined(snap, compConf.RuleLists)
 }
 
 // setSafeBrowsing sets the safe-browsing filters in compConf from c.  c must
@@ -373,7 +443,9 @@ func (s *Default) setSafeBrowsing(compConf *composite.Config, c *filter.ConfigSa
 // forGroup returns a new filter based on a group configuration.  c must not be
 // nil.
 func (s *Default) forGroup(ctx context.Context, c *filter.ConfigGroup) (f filter.Interface) {
-	compConf := &composite.Config{}
+	compConf := &composite.Config{
+		BlockPage: c.BlockPage,
+	}
 
 	s.setParental(ctx, compConf, c.Parental)
 	s.setRuleLists(compConf, c.RuleList)
@@ -382,12 +454,22 @@ func (s *Default) forGroup(ctx context.Context, c *filter.ConfigGroup) (f filter
 	return composite.New(compConf)
 }
 
+// type check
+var _ filter.Classifier = (*Default)(nil)
+
+// Classify implements the [filter.Classifier] interface for *Default.  The
+// categories are based on the groups of the blocked services.
+func (s *Default) Classify(ctx context.Context, host string) (cats []filter.Category) {
+	if s.services == nil {
+		return nil
+	}
+
+	return s.services.Classify(ctx, host)
+}
+
 // HasListID implements the [filter.Storage] interface for *Default.
 func (s *Default) HasListID(id filter.ID) (ok bool) {
-	s.ruleListsMu.RLock()
-	defer s.ruleListsMu.RUnlock()
-
-	_, ok = s.ruleLists[id]
+	_, ok = s.ruleLists.Load().lists[id]
 
 	return ok
 }
@@ -159,6 +159,71 @@ func TestDefault_ForConfig_client(t *testing.T) {
 
 		assert.Nil(t, r)
 	}))
+
+	require.True(t, t.Run("allowlist_only", func(t *testing.T) {
+		conf := newFltConfigCli(
+			newFltConfigParental(false, false, false, false),
+			newFltConfigRuleList(false),
+			newFltConfigSafeBrowsing(false, false),
+		)
+
+		conf.AllowlistOnly = true
+
+		ctx := testutil.ContextWithTimeout(t, filtertest.Timeout)
+		f := s.ForConfig(ctx, conf)
+		require.NotNil(t, f)
+
+		ctx = testutil.ContextWithTimeout(t, filtertest.Timeout)
+		r, err := f.FilterRequest(ctx, filtertest.NewARequest(t, filtertest.HostBlocked))
+		require.NoError(t, err)
+
+		filtertest.AssertEqualResult(t, &filter.ResultBlocked{
+			List: filter.IDAllowlistOnly,
+		}, r)
+
+		ctx = testutil.ContextWithTimeout(t, filtertest.Timeout)
+		r, err = f.FilterRequest(ctx, filtertest.NewARequest(t, filtertest.Host))
+		require.NoError(t, err)
+
+		filtertest.AssertEqualResult(t, &filter.ResultAllowed{
+			List: filter.IDEssentialServices,
+			Rule: "@@||" + filtertest.Host + "^",
+		}, r)
+	}))
+
+	require.True(t, t.Run("block_until", func(t *testing.T) {
+		conf := newFltConfigCli(
+			newFltConfigParental(false, false, false, false),
+			newFltConfigRuleList(false),
+			newFltConfigSafeBrowsing(false, false),
+		)
+
+		conf.BlockUntil = time.Now().Add(1 * time.Hour)
+
+		ctx := testutil.ContextWithTimeout(t, filtertest.Timeout)
+		f := s.ForConfig(ctx, conf)
+		require.NotNil(t, f)
+
+		ctx = testutil.ContextWithTimeout(t, filtertest.Timeout)
+		r, err := f.FilterRequest(ctx, filtertest.NewARequest(t, filtertest.HostBlocked))
+		require.NoError(t, err)
+
+		filtertest.AssertEqualResult(t, &filter.ResultBlocked{
+			List: filter.IDBlockUntil,
+		}, r)
+
+		conf.BlockUntil = time.Now().Add(-1 * time.Hour)
+
+		ctx = testutil.ContextWithTimeout(t, filtertest.Timeout)
+		f = s.ForConfig(ctx, conf)
+		require.NotNil(t, f)
+
+		ctx = testutil.ContextWithTimeout(t, filtertest.Timeout)
+		r, err = f.FilterRequest(ctx, filtertest.NewARequest(t, filtertest.HostBlocked))
+		require.NoError(t, err)
+
+		assert.Nil(t, r)
+	}))
 }
 
 func TestDefault_ForConfig_common(t *testing.T) {
@@ -27,4 +27,8 @@ const (
 
 	// cachePrefixRuleList is used a cache prefix for rule-list filters.
 	cachePrefixRuleList = "filters/rulelist"
+
+	// cachePrefixRuleListCombined is used a cache prefix for combined
+	// rule-list filters.
+	cachePrefixRuleListCombined = "filters/rulelist_combined"
 )
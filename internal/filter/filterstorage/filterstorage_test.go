@@ -76,6 +76,7 @@ var (
 //     [filtertest.BlockedServiceID1] blocking [filtertest.HostBlockedService1].
 //   - All hash-prefix filters, which block [filtertest.HostAdultContent],
 //     [filtertest.HostDangerous], and [filtertest.HostNewlyRegistered].
+//   - An essential-services filter allowing [filtertest.Host].
 func newDefault(tb testing.TB) (s *filterstorage.Default) {
 	const (
 		blockData = filtertest.RuleBlockStr + "\n"
@@ -109,6 +110,9 @@ func newDefault(tb testing.TB) (s *filterstorage.Default) {
 
 	c := newDisabledConfig(tb, newConfigRuleLists(ruleListIdxURL))
 	c.BlockedServices = newConfigBlockedServices(svcIdxURL)
+	c.EssentialServices = &filterstorage.ConfigEssentialServices{
+		Domains: []string{filtertest.Host},
+	}
 	c.HashPrefix = &filterstorage.ConfigHashPrefix{
 		Adult:           filtertest.NewHashprefixFilter(tb, filter.IDAdultBlocking),
 		Dangerous:       filtertest.NewHashprefixFilter(tb, filter.IDSafeBrowsing),
@@ -151,8 +155,9 @@ func newDisabledConfig(
 		Custom: &filterstorage.ConfigCustom{
 			CacheCount: filtertest.CacheCount,
 		},
-		HashPrefix: &filterstorage.ConfigHashPrefix{},
-		RuleLists:  rlConf,
+		EssentialServices: &filterstorage.ConfigEssentialServices{},
+		HashPrefix:        &filterstorage.ConfigHashPrefix{},
+		RuleLists:         rlConf,
 		SafeSearchGeneral: &filterstorage.ConfigSafeSearch{
 			ID:      filter.IDGeneralSafeSearch,
 			Enabled: false,
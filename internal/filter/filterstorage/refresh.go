@@ -77,7 +77,10 @@ func (s *Default) refresh(ctx context.Context, acceptStale bool) (err error) {
 		return err
 	}
 
-	s.resetRuleLists(newRuleLists)
+	s.ruleLists.Store(&ruleListsSnapshot{
+		lists:    newRuleLists,
+		combined: s.buildCombined(ctx, newRuleLists),
+	})
 
 	return nil
 }
@@ -131,6 +134,7 @@ func (s *Default) addRuleList(
 	rl, err := rulelist.NewRefreshable(
 		&refreshable.Config{
 			Logger:    s.baseLogger.With(slogutil.KeyPrefix, cacheID),
+			Metrics:   s.metrics,
 			URL:       fl.url,
 			ID:        fl.id,
 			CachePath: filepath.Join(s.cacheDir, fltIDStr),
@@ -170,10 +174,7 @@ func (s *Default) reportRuleListError(ctx context.Context, fl *indexData, err er
 // setPrevRuleList adds the previous version of the filter to newRuleLists, if
 // there is one.
 func (s *Default) setPrevRuleList(newRuleLists ruleLists, id filter.ID) {
-	s.ruleListsMu.RLock()
-	defer s.ruleListsMu.RUnlock()
-
-	if rl, ok := s.ruleLists[id]; ok {
+	if rl, ok := s.ruleLists.Load().lists[id]; ok {
 		newRuleLists[id] = rl
 	}
 }
@@ -217,14 +218,6 @@ func (s *Default) refreshSafeSearch(ctx context.Context, acceptStale bool) (err
 	return nil
 }
 
-// resetRuleLists replaces the storage's rule lists.
-func (s *Default) resetRuleLists(rls ruleLists) {
-	s.ruleListsMu.Lock()
-	defer s.ruleListsMu.Unlock()
-
-	s.ruleLists = rls
-}
-
 // RefreshInitial loads the content of the storage, using cached files if any,
 // regardless of their staleness.
 func (s *Default) RefreshInitial(ctx context.Context) (err error) {
@@ -0,0 +1,59 @@
+package filterstorage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/composite"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/rulelist"
+)
+
+// NewRuleListFilter returns a new filter that filters requests and responses
+// using the rules from text, which must be in the AdGuard DNS filtering-rule
+// syntax.  The results of the filter have [filter.IDCustom] as their list ID.
+// cacheCount is the number of cached results; if it is zero or less, the
+// results are not cached.
+func NewRuleListFilter(text string, cacheCount int) (f filter.Interface, err error) {
+	cache := rulelist.NewResultCache(cacheCount, cacheCount > 0)
+	rl, err := rulelist.NewImmutable(text, filter.IDCustom, "", cache)
+	if err != nil {
+		return nil, fmt.Errorf("creating rule-list filter: %w", err)
+	}
+
+	return composite.New(&composite.Config{
+		Custom: rl,
+	}), nil
+}
+
+// Static is a [filter.Storage] that returns the same filter for every
+// configuration.
+type Static struct {
+	filter filter.Interface
+}
+
+// NewStatic returns a new static filter storage that always returns f.  f must
+// not be nil.
+func NewStatic(f filter.Interface) (s *Static) {
+	return &Static{
+		filter: f,
+	}
+}
+
+// type check
+var _ filter.Storage = (*Static)(nil)
+
+// ForConfig implements the [filter.Storage] interface for *Static.  It returns
+// [filter.Empty] if c is nil.
+func (s *Static) ForConfig(_ context.Context, c filter.Config) (f filter.Interface) {
+	if c == nil {
+		return filter.Empty{}
+	}
+
+	return s.filter
+}
+
+// HasListID implements the [filter.Storage] interface for *Static.
+func (s *Static) HasListID(id filter.ID) (ok bool) {
+	return id == filter.IDCustom
+}
@@ -0,0 +1,58 @@
+package filterstorage_test
+
+import (
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/filterstorage"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/filtertest"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatic(t *testing.T) {
+	t.Parallel()
+
+	f, err := filterstorage.NewRuleListFilter(filtertest.RuleBlockStr+"\n", 100)
+	require.NoError(t, err)
+
+	s := filterstorage.NewStatic(f)
+
+	ctx := testutil.ContextWithTimeout(t, filtertest.Timeout)
+
+	assert.IsType(t, filter.Empty{}, s.ForConfig(ctx, nil))
+	assert.True(t, s.HasListID(filter.IDCustom))
+	assert.False(t, s.HasListID(filtertest.RuleListID1))
+
+	got := s.ForConfig(ctx, &filter.ConfigGroup{})
+	require.NotNil(t, got)
+
+	testCases := []struct {
+		want filter.Result
+		name string
+		host string
+	}{{
+		want: &filter.ResultBlocked{
+			List: filter.IDCustom,
+			Rule: filtertest.RuleBlock,
+		},
+		name: "blocked",
+		host: filtertest.HostBlocked,
+	}, {
+		want: nil,
+		name: "other",
+		host: filtertest.Host,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := filtertest.NewRequest(t, "", tc.host, filtertest.IPv4Client, dns.TypeA)
+			res, fltErr := got.FilterRequest(ctx, req)
+			require.NoError(t, fltErr)
+
+			assert.Equal(t, tc.want, res)
+		})
+	}
+}
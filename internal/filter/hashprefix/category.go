@@ -0,0 +1,85 @@
+package hashprefix
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+)
+
+// CategoryStorage stores the hashes of the domain names along with their
+// content categories.  All methods are safe for concurrent use.
+type CategoryStorage struct {
+	// table contains the current records by the prefixes of their hashes.  It
+	// is an atomic pointer to make sure that calls to [CategoryStorage.Reset]
+	// do not block the lookups.
+	table *atomic.Pointer[categoryTable]
+}
+
+// categoryTable is a mapping of hash prefixes to the TXT strings of the records
+// with hashes starting with them.
+type categoryTable = map[Prefix][]string
+
+// NewCategoryStorage returns a new empty category storage.
+func NewCategoryStorage() (s *CategoryStorage) {
+	s = &CategoryStorage{
+		table: &atomic.Pointer[categoryTable]{},
+	}
+
+	s.table.Store(&categoryTable{})
+
+	return s
+}
+
+// type check
+var _ HashSource = (*CategoryStorage)(nil)
+
+// Hashes implements the [HashSource] interface for *CategoryStorage.  Each
+// returned string has the form "<hash>:<category>[,<category>...]", where the
+// categories are sorted.
+func (s *CategoryStorage) Hashes(prefs []Prefix) (hashes []string) {
+	t := *s.table.Load()
+	for _, pref := range prefs {
+		hashes = append(hashes, t[pref]...)
+	}
+
+	return hashes
+}
+
+// Reset replaces the records in the storage with the ones for hosts, which is
+// a mapping of lowercased domain names to their content categories, and
+// returns the number of records.
+func (s *CategoryStorage) Reset(hosts map[string][]filter.Category) (n int) {
+	t := make(categoryTable, len(hosts))
+	for host, cats := range hosts {
+		if len(cats) == 0 {
+			continue
+		}
+
+		sum := sha256.Sum256([]byte(host))
+		pref := Prefix(sum[:PrefixLen])
+		t[pref] = append(t[pref], categoryRecord(sum, cats))
+		n++
+	}
+
+	s.table.Store(&t)
+
+	return n
+}
+
+// categoryRecord returns the TXT string for the host with the hash sum and the
+// content categories cats.
+func categoryRecord(sum [hashLen]byte, cats []filter.Category) (rec string) {
+	catStrs := make([]string, 0, len(cats))
+	for _, c := range cats {
+		catStrs = append(catStrs, string(c))
+	}
+
+	slices.Sort(catStrs)
+	catStrs = slices.Compact(catStrs)
+
+	return hex.EncodeToString(sum[:]) + ":" + strings.Join(catStrs, ",")
+}
@@ -0,0 +1,221 @@
+package hashprefix
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+	"sync"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/golibs/errors"
+	"golang.org/x/time/rate"
+)
+
+// CategoryMetrics is an interface for collection of the statistics of the
+// category lookups.
+type CategoryMetrics interface {
+	// OnLookup records a valid category lookup.  found is true if there were
+	// any records for the requested prefixes.
+	OnLookup(ctx context.Context, found bool)
+
+	// IncrementInvalid increments the number of invalid category lookups.
+	IncrementInvalid(ctx context.Context)
+
+	// IncrementLimited increments the number of rate-limited category
+	// lookups.
+	IncrementLimited(ctx context.Context)
+}
+
+// EmptyCategoryMetrics is an implementation of the [CategoryMetrics] interface
+// that does nothing.
+type EmptyCategoryMetrics struct{}
+
+// type check
+var _ CategoryMetrics = EmptyCategoryMetrics{}
+
+// OnLookup implements the [CategoryMetrics] interface for EmptyCategoryMetrics.
+func (EmptyCategoryMetrics) OnLookup(_ context.Context, _ bool) {}
+
+// IncrementInvalid implements the [CategoryMetrics] interface for
+// EmptyCategoryMetrics.
+func (EmptyCategoryMetrics) IncrementInvalid(_ context.Context) {}
+
+// IncrementLimited implements the [CategoryMetrics] interface for
+// EmptyCategoryMetrics.
+func (EmptyCategoryMetrics) IncrementLimited(_ context.Context) {}
+
+// ErrCategoryLookupLimited is returned by [CategoryMatcher.MatchByPrefix] when
+// the client has sent too many category lookups.
+const ErrCategoryLookupLimited errors.Error = "too many category lookups"
+
+// CategoryMatcherConfig is the configuration structure for [CategoryMatcher].
+type CategoryMatcherConfig struct {
+	// Storage is the source of the hashes and their categories.  It must not
+	// be nil.
+	Storage *CategoryStorage
+
+	// Metrics is used for the collection of the statistics of the lookups.  It
+	// must not be nil.
+	Metrics CategoryMetrics
+
+	// Suffix is the domain-name suffix of the category lookups, for example
+	// [filter.CategoryTXTSuffix].  It must not be empty.
+	Suffix string
+
+	// RPS is the number of lookups per second allowed for a single client
+	// network.  It must be positive.
+	RPS float64
+
+	// Burst is the number of lookups a single client network can make at once.
+	// It must be positive.
+	Burst int
+
+	// CacheSize is the maximum number of client networks the limiters of which
+	// are kept.  It must be positive.
+	CacheSize int
+
+	// MaxPrefixes is the maximum number of hash prefixes in a single lookup.
+	// It must be positive.
+	MaxPrefixes int
+
+	// IPv4SubnetKeyLen is the length of the subnet prefix used to group the
+	// IPv4 clients.  It must be in the [1, 32] range.
+	IPv4SubnetKeyLen int
+
+	// IPv6SubnetKeyLen is the length of the subnet prefix used to group the
+	// IPv6 clients.  It must be in the [1, 128] range.
+	IPv6SubnetKeyLen int
+}
+
+// CategoryMatcher is a [filter.HashMatcher] that serves the content categories
+// of the domain names by the prefixes of their hashes.  The lookups are
+// rate-limited per client network.  It is safe for concurrent use.
+type CategoryMatcher struct {
+	storage *CategoryStorage
+	metrics CategoryMetrics
+
+	// limitersMu protects limiters.
+	limitersMu *sync.Mutex
+
+	// limiters are the rate limiters of the client networks.
+	limiters agdcache.Interface[netip.Prefix, *rate.Limiter]
+
+	suffix           string
+	rps              rate.Limit
+	burst            int
+	maxPrefixes      int
+	ipv4SubnetKeyLen int
+	ipv6SubnetKeyLen int
+}
+
+// NewCategoryMatcher returns a new properly initialized *CategoryMatcher.  c
+// must be valid.
+func NewCategoryMatcher(c *CategoryMatcherConfig) (m *CategoryMatcher) {
+	return &CategoryMatcher{
+		storage:    c.Storage,
+		metrics:    c.Metrics,
+		limitersMu: &sync.Mutex{},
+		limiters: agdcache.NewLRU[netip.Prefix, *rate.Limiter](&agdcache.LRUConfig{
+			Count: c.CacheSize,
+		}),
+		suffix:           c.Suffix,
+		rps:              rate.Limit(c.RPS),
+		burst:            c.Burst,
+		maxPrefixes:      c.MaxPrefixes,
+		ipv4SubnetKeyLen: c.IPv4SubnetKeyLen,
+		ipv6SubnetKeyLen: c.IPv6SubnetKeyLen,
+	}
+}
+
+// type check
+var _ filter.HashMatcher = (*CategoryMatcher)(nil)
+
+// MatchByPrefix implements the [filter.HashMatcher] interface for
+// *CategoryMatcher.  ctx must contain a request info.  If the client network
+// has made too many lookups, err is [ErrCategoryLookupLimited].
+func (m *CategoryMatcher) MatchByPrefix(
+	ctx context.Context,
+	host string,
+) (hashes []string, matched bool, err error) {
+	prefixesStr, ok := strings.CutSuffix(host, m.suffix)
+	if !ok {
+		return nil, false, nil
+	}
+
+	if !m.allow(agd.MustRequestInfoFromContext(ctx).RemoteIP) {
+		m.metrics.IncrementLimited(ctx)
+
+		return nil, false, ErrCategoryLookupLimited
+	}
+
+	hashPrefixes, err := prefixesFromStr(prefixesStr)
+	if err == nil && len(hashPrefixes) > m.maxPrefixes {
+		err = fmt.Errorf("too many hash prefixes: %d", len(hashPrefixes))
+	}
+
+	if err != nil {
+		m.metrics.IncrementInvalid(ctx)
+
+		return nil, false, err
+	}
+
+	hashes = m.storage.Hashes(hashPrefixes)
+	m.metrics.OnLookup(ctx, len(hashes) > 0)
+
+	return hashes, true, nil
+}
+
+// allow returns true if the client with the address addr is allowed to make
+// another lookup.
+func (m *CategoryMatcher) allow(addr netip.Addr) (ok bool) {
+	addr = addr.Unmap()
+
+	keyLen := m.ipv6SubnetKeyLen
+	if addr.Is4() {
+		keyLen = m.ipv4SubnetKeyLen
+	}
+
+	subnet, err := addr.Prefix(keyLen)
+	if err != nil {
+		// Technically shouldn't happen, since the address of the client is
+		// always valid.
+		panic(fmt.Errorf("hashprefix: getting subnet: %w", err))
+	}
+
+	m.limitersMu.Lock()
+	defer m.limitersMu.Unlock()
+
+	l, ok := m.limiters.Get(subnet)
+	if !ok {
+		l = rate.NewLimiter(m.rps, m.burst)
+		m.limiters.Set(subnet, l)
+	}
+
+	return l.Allow()
+}
+
+// MultiMatcher is a [filter.HashMatcher] that uses the first of its matchers
+// that has matched the host.
+type MultiMatcher []filter.HashMatcher
+
+// type check
+var _ filter.HashMatcher = MultiMatcher(nil)
+
+// MatchByPrefix implements the [filter.HashMatcher] interface for
+// MultiMatcher.
+func (mm MultiMatcher) MatchByPrefix(
+	ctx context.Context,
+	host string,
+) (hashes []string, matched bool, err error) {
+	for _, m := range mm {
+		hashes, matched, err = m.MatchByPrefix(ctx, host)
+		if matched || err != nil {
+			return hashes, matched, err
+		}
+	}
+
+	return nil, false, nil
+}
@@ -0,0 +1,132 @@
+package hashprefix_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/hashprefix"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCategoryCtx returns a new context with the request info of a client with
+// the address addr.
+func newCategoryCtx(addr netip.Addr) (ctx context.Context) {
+	return agd.ContextWithRequestInfo(context.Background(), &agd.RequestInfo{
+		RemoteIP: addr,
+	})
+}
+
+func TestCategoryMatcher(t *testing.T) {
+	t.Parallel()
+
+	const (
+		suffix = filter.CategoryTXTSuffix
+		host   = "social.example"
+	)
+
+	sum := sha256.Sum256([]byte(host))
+	hashStr := hex.EncodeToString(sum[:])
+	prefix := hashStr[:hashprefix.PrefixEncLen]
+
+	strg := hashprefix.NewCategoryStorage()
+	n := strg.Reset(map[string][]filter.Category{
+		host:              {"social_network", "chat", "social_network"},
+		"other.example":   {"gaming"},
+		"no-cats.example": nil,
+	})
+	require.Equal(t, 2, n)
+
+	m := hashprefix.NewCategoryMatcher(&hashprefix.CategoryMatcherConfig{
+		Storage:          strg,
+		Metrics:          hashprefix.EmptyCategoryMetrics{},
+		Suffix:           suffix,
+		RPS:              1,
+		Burst:            3,
+		CacheSize:        100,
+		MaxPrefixes:      2,
+		IPv4SubnetKeyLen: 24,
+		IPv6SubnetKeyLen: 56,
+	})
+
+	ctx := newCategoryCtx(netip.MustParseAddr("192.0.2.1"))
+
+	t.Run("found", func(t *testing.T) {
+		hashes, matched, err := m.MatchByPrefix(ctx, prefix+suffix)
+		require.NoError(t, err)
+
+		assert.True(t, matched)
+		assert.Contains(t, hashes, hashStr+":chat,social_network")
+	})
+
+	t.Run("other_suffix", func(t *testing.T) {
+		hashes, matched, err := m.MatchByPrefix(ctx, prefix+filter.GeneralTXTSuffix)
+		require.NoError(t, err)
+
+		assert.False(t, matched)
+		assert.Nil(t, hashes)
+	})
+
+	t.Run("too_many_prefixes", func(t *testing.T) {
+		prefixes := strings.Join([]string{"0000", "0001", "0002"}, ".")
+		_, matched, err := m.MatchByPrefix(ctx, prefixes+suffix)
+		require.Error(t, err)
+
+		assert.False(t, matched)
+	})
+
+	t.Run("limited", func(t *testing.T) {
+		// The same network as the one above, which has made two lookups
+		// already.
+		limitedCtx := newCategoryCtx(netip.MustParseAddr("192.0.2.2"))
+		_, _, err := m.MatchByPrefix(limitedCtx, prefix+suffix)
+		require.NoError(t, err)
+
+		_, _, err = m.MatchByPrefix(limitedCtx, prefix+suffix)
+		assert.ErrorIs(t, err, hashprefix.ErrCategoryLookupLimited)
+
+		otherCtx := newCategoryCtx(netip.MustParseAddr("198.51.100.1"))
+		_, matched, err := m.MatchByPrefix(otherCtx, prefix+suffix)
+		require.NoError(t, err)
+
+		assert.True(t, matched)
+	})
+}
+
+func TestMultiMatcher(t *testing.T) {
+	t.Parallel()
+
+	hostnames := "scam.example.net"
+	strg, err := hashprefix.NewStorage(hostnames)
+	require.NoError(t, err)
+
+	sum := sha256.Sum256([]byte(hostnames))
+	hashStr := hex.EncodeToString(sum[:])
+	host := hashStr[:hashprefix.PrefixEncLen] + filter.GeneralTXTSuffix
+
+	mm := hashprefix.MultiMatcher{
+		filter.EmptyHashMatcher{},
+		hashprefix.NewMatcher(map[string]hashprefix.HashSource{
+			filter.GeneralTXTSuffix: strg,
+		}),
+	}
+
+	ctx := context.Background()
+	hashes, matched, err := mm.MatchByPrefix(ctx, host)
+	require.NoError(t, err)
+
+	assert.True(t, matched)
+	assert.Equal(t, []string{hashStr}, hashes)
+
+	hashes, matched, err = mm.MatchByPrefix(ctx, "example.org")
+	require.NoError(t, err)
+
+	assert.False(t, matched)
+	assert.Nil(t, hashes)
+}
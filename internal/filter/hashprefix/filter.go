@@ -92,6 +92,14 @@ type cacheItem struct {
 	host string
 }
 
+// type check
+var _ agdcache.HostItem = (*cacheItem)(nil)
+
+// CachedHost implements the [agdcache.HostItem] interface for *cacheItem.
+func (item *cacheItem) CachedHost() (host string) {
+	return item.host
+}
+
 // Filter is a filter that matches hosts by their hashes based on a hash-prefix
 // table.  It should be initially refreshed with [Filter.RefreshInitial].
 type Filter struct {
@@ -150,6 +158,7 @@ func NewFilter(c *FilterConfig) (f *Filter, err error) {
 
 	f.refr, err = refreshable.New(&refreshable.Config{
 		Logger:    f.logger,
+		Metrics:   c.Metrics,
 		URL:       c.URL,
 		ID:        id,
 		CachePath: c.CachePath,
@@ -219,6 +228,54 @@ func (f *Filter) FilterRequest(
 	return r, nil
 }
 
+// FilterRequestWithBlockPage is like [Filter.FilterRequest], but if bp is not
+// nil, the responses to the matched address queries contain the addresses from
+// bp instead of the ones of the replacement host of f.
+func (f *Filter) FilterRequestWithBlockPage(
+	ctx context.Context,
+	req *internal.Request,
+	bp *internal.ConfigBlockPage,
+) (r internal.Result, err error) {
+	r, err = f.FilterRequest(ctx, req)
+	if err != nil || r == nil || bp == nil {
+		return r, err
+	}
+
+	fam, _ := isFilterable(req.QType)
+	if fam == netutil.AddrFamilyNone {
+		return r, nil
+	}
+
+	ip := bp.IPv4
+	if fam == netutil.AddrFamilyIPv6 {
+		ip = bp.IPv6
+	}
+
+	resp, err := respForFamily(req, fam, ip)
+	if err != nil {
+		return nil, fmt.Errorf("filter %s: creating block page result: %w", f.id, err)
+	}
+
+	return &internal.ResultModifiedResponse{
+		Msg:  resp,
+		List: f.id,
+		Rule: ruleOf(r),
+	}, nil
+}
+
+// ruleOf returns the rule of r.  r must be either
+// [*internal.ResultModifiedRequest] or [*internal.ResultModifiedResponse].
+func ruleOf(r internal.Result) (rule internal.RuleText) {
+	switch r := r.(type) {
+	case *internal.ResultModifiedRequest:
+		return r.Rule
+	case *internal.ResultModifiedResponse:
+		return r.Rule
+	default:
+		panic(fmt.Errorf("hashprefix: unexpected type for result: %T(%[1]v)", r))
+	}
+}
+
 // itemFromCache retrieves a cache item for the given key.  host is used to
 // detect key collisions.  If there is a key collision, it returns nil and
 // false.
@@ -250,7 +307,7 @@ func (f *Filter) ID() (id internal.ID) {
 // filterable with a blocked page, fam is the address family for the IP
 // addresses of the blocked page; otherwise fam is [netutil.AddrFamilyNone].
 func isFilterable(qt dnsmsg.RRType) (fam netutil.AddrFamily, ok bool) {
-	if qt == dns.TypeHTTPS {
+	if qt == dns.TypeHTTPS || qt == dns.TypeSVCB {
 		return netutil.AddrFamilyNone, true
 	}
 
@@ -292,7 +349,7 @@ func (f *Filter) filteredResult(
 		}, nil
 	}
 
-	resp, err := f.respForFamily(req, fam)
+	resp, err := respForFamily(req, fam, f.repIP)
 	if err != nil {
 		return nil, fmt.Errorf("filter %s: creating modified result: %w", f.id, err)
 	}
@@ -304,23 +361,22 @@ func (f *Filter) filteredResult(
 	}, nil
 }
 
-// respForFamily returns a filtered response in accordance with the protocol
-// family and question type.
-func (f *Filter) respForFamily(
+// respForFamily returns a filtered response with ip in accordance with the
+// protocol family and question type.
+func respForFamily(
 	req *internal.Request,
 	fam netutil.AddrFamily,
+	ip netip.Addr,
 ) (resp *dns.Msg, err error) {
 	if fam == netutil.AddrFamilyNone {
-		// This is an HTTPS query.  For them, just return NODATA or other
-		// blocked response.  See AGDNS-1551.
+		// This is an HTTPS or SVCB query.  For them, just return the blocked
+		// response according to the blocking mode.  See AGDNS-1551.
 		//
 		// TODO(ameshkov): Consider putting the resolved IP addresses into hints
 		// to show the blocked page here as well?
 		return req.Messages.NewBlockedResp(req.DNS)
 	}
 
-	ip := f.repIP
-
 	switch {
 	case ip.Is4() && fam == netutil.AddrFamilyIPv4:
 		return req.Messages.NewBlockedRespIP(req.DNS, ip)
@@ -373,6 +429,20 @@ func (f *Filter) updateCacheSizeMetrics(size int) {
 	}
 }
 
+// updateStorageSizeMetrics updates hash storage size metrics.
+func (f *Filter) updateStorageSizeMetrics(size int) {
+	switch id := f.id; id {
+	case internal.IDSafeBrowsing:
+		metrics.HashPrefixFilterSafeBrowsingStorageSize.Set(float64(size))
+	case internal.IDAdultBlocking:
+		metrics.HashPrefixFilterAdultBlockingStorageSize.Set(float64(size))
+	case internal.IDNewRegDomains:
+		metrics.HashPrefixFilterNewRegDomainsStorageSize.Set(float64(size))
+	default:
+		panic(fmt.Errorf("unsupported FilterListID %s", id))
+	}
+}
+
 // updateCacheLookupsMetrics updates cache lookups metrics.
 func (f *Filter) updateCacheLookupsMetrics(hit bool) {
 	var hitsMetric, missesMetric prometheus.Counter
@@ -446,7 +516,10 @@ func (f *Filter) refresh(ctx context.Context, acceptStale bool) (err error) {
 
 	f.resCache.Clear()
 
-	f.logger.InfoContext(ctx, "reset hosts", "num", count)
+	size := f.hashes.Size()
+	f.updateStorageSizeMetrics(size)
+
+	f.logger.InfoContext(ctx, "reset hosts", "num", count, "size", size)
 
 	return nil
 }
@@ -172,6 +172,26 @@ func TestFilter_FilterRequest_host(t *testing.T) {
 		filtertest.AssertEqualResult(t, wantRes, r)
 	}))
 
+	require.True(t, t.Run("svcb", func(t *testing.T) {
+		f := filtertest.NewHashprefixFilter(t, internal.IDAdultBlocking)
+
+		req := filtertest.NewRequest(
+			t,
+			"",
+			filtertest.HostAdultContent,
+			filtertest.IPv4Client,
+			dns.TypeSVCB,
+		)
+
+		ctx := testutil.ContextWithTimeout(t, filtertest.Timeout)
+		r, err := f.FilterRequest(ctx, req)
+		require.NoError(t, err)
+		require.NotNil(t, r)
+
+		wantRes := newModReqResult(req.DNS, filtertest.HostAdultContent)
+		filtertest.AssertEqualResult(t, wantRes, r)
+	}))
+
 	require.True(t, t.Run("https_ip", func(t *testing.T) {
 		f := filtertest.NewHashprefixFilterWithRepl(
 			t,
@@ -201,6 +221,91 @@ func TestFilter_FilterRequest_host(t *testing.T) {
 	}))
 }
 
+func TestFilter_FilterRequestWithBlockPage(t *testing.T) {
+	t.Parallel()
+
+	f := filtertest.NewHashprefixFilter(t, internal.IDAdultBlocking)
+	bp := &internal.ConfigBlockPage{
+		IPv4: netip.MustParseAddr("192.0.2.1"),
+	}
+
+	testCases := []struct {
+		bp      *internal.ConfigBlockPage
+		wantIP  netip.Addr
+		name    string
+		host    string
+		qType   dnsmsg.RRType
+		wantMod bool
+	}{{
+		bp:      bp,
+		wantIP:  bp.IPv4,
+		name:    "ipv4",
+		host:    filtertest.HostAdultContent,
+		qType:   dns.TypeA,
+		wantMod: true,
+	}, {
+		bp:      bp,
+		wantIP:  netip.Addr{},
+		name:    "no_ipv6",
+		host:    filtertest.HostAdultContent,
+		qType:   dns.TypeAAAA,
+		wantMod: true,
+	}, {
+		bp:      bp,
+		wantIP:  netip.Addr{},
+		name:    "no_match",
+		host:    filtertest.Host,
+		qType:   dns.TypeA,
+		wantMod: false,
+	}, {
+		bp:      nil,
+		wantIP:  netip.Addr{},
+		name:    "default",
+		host:    filtertest.HostAdultContent,
+		qType:   dns.TypeA,
+		wantMod: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := filtertest.NewRequest(t, "", tc.host, filtertest.IPv4Client, tc.qType)
+
+			ctx := testutil.ContextWithTimeout(t, filtertest.Timeout)
+			r, err := f.FilterRequestWithBlockPage(ctx, req, tc.bp)
+			require.NoError(t, err)
+
+			if !tc.wantMod {
+				def, defErr := f.FilterRequest(ctx, req)
+				require.NoError(t, defErr)
+
+				filtertest.AssertEqualResult(t, def, r)
+
+				return
+			}
+
+			m := testutil.RequireTypeAssert[*internal.ResultModifiedResponse](t, r)
+			require.NotNil(t, m.Msg)
+
+			assert.Equal(t, filter.IDAdultBlocking, m.List)
+			assert.Equal(t, internal.RuleText(filtertest.HostAdultContent), m.Rule)
+			assert.Equal(t, dns.RcodeSuccess, m.Msg.Rcode)
+
+			if !tc.wantIP.IsValid() {
+				assert.Empty(t, m.Msg.Answer)
+
+				return
+			}
+
+			want, respErr := req.Messages.NewRespIP(req.DNS, tc.wantIP)
+			require.NoError(t, respErr)
+
+			assert.Equal(t, want.Answer, m.Msg.Answer)
+		})
+	}
+}
+
 // newModRespResult is a helper for creating modified results for tests.
 func newModRespResult(
 	tb testing.TB,
@@ -16,9 +16,6 @@ const (
 	// hashLen is the length of the whole hash of the checked hostname.
 	hashLen = sha256.Size
 
-	// suffixLen is the length of the hash suffix of the filtered hostname.
-	suffixLen = hashLen - PrefixLen
-
 	// hashEncLen is the encoded length of the hash.  Two text bytes per one
 	// binary byte.
 	hashEncLen = hashLen * 2
@@ -27,6 +24,3 @@ const (
 // Prefix is the type of the SHA256 hash prefix used to match against the
 // domain-name database.
 type Prefix [PrefixLen]byte
-
-// suffix is the type of the rest of a SHA256 hash of the filtered domain names.
-type suffix [suffixLen]byte
@@ -9,18 +9,28 @@ import (
 	"github.com/AdguardTeam/golibs/container"
 )
 
+// HashSource is the source of the TXT strings for the hash-prefix lookups.
+type HashSource interface {
+	// Hashes returns the TXT strings for all hashes starting with the given
+	// prefixes, if any.
+	Hashes(prefs []Prefix) (hashes []string)
+}
+
+// type check
+var _ HashSource = (*Storage)(nil)
+
 // Matcher is a hash-prefix matcher that uses the hash-prefix storages as the
 // source of its data.
 type Matcher struct {
 	// storages is a mapping of domain-name suffixes to the storage containing
 	// hashes for this domain.
-	storages map[string]*Storage
+	storages map[string]HashSource
 }
 
 // NewMatcher returns a new hash-prefix matcher.  storages is a mapping of
 // domain-name suffixes to the storage containing hashes for this domain.  If
 // storages is empty, m.MatchByPrefix always returns nil, false, and nil.
-func NewMatcher(storages map[string]*Storage) (m *Matcher) {
+func NewMatcher(storages map[string]HashSource) (m *Matcher) {
 	return &Matcher{
 		storages: storages,
 	}
@@ -38,7 +48,7 @@ func (m *Matcher) MatchByPrefix(
 	var (
 		suffix      string
 		prefixesStr string
-		strg        *Storage
+		strg        HashSource
 	)
 
 	for suffix, strg = range m.storages {
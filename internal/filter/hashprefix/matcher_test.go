@@ -76,7 +76,7 @@ func TestMatcher(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			srv := hashprefix.NewMatcher(map[string]*hashprefix.Storage{
+			srv := hashprefix.NewMatcher(map[string]hashprefix.HashSource{
 				suffix: hashes,
 			})
 
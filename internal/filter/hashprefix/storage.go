@@ -1,7 +1,6 @@
 package hashprefix
 
 import (
-	"bufio"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
@@ -15,29 +14,26 @@ import (
 //
 // TODO(a.garipov): See if we could unexport this.
 type Storage struct {
-	// resetMu makes sure that only one reset is taking place at a time.  It
-	// also protects prev.
+	// resetMu makes sure that only one reset is taking place at a time.
 	resetMu *sync.Mutex
 
-	// hashSuffixes contains the hashSuffixes map.  It is an atomic pointer to
+	// table contains the current table of hashes.  It is an atomic pointer to
 	// make sure that calls to [Storage.Reset] do not block [Storage.Matches]
 	// and thus filtering.
-	hashSuffixes *atomic.Pointer[suffixMap]
-}
+	table *atomic.Pointer[hashTable]
 
-// suffixMap is a convenient alias for a map of hash prefixes to its suffixes.
-type suffixMap = map[Prefix][]suffix
+	// filePath is the path to the binary file, into which the table is written
+	// and from which it is mapped into memory.  If it is empty, the table is
+	// kept on the heap.
+	filePath string
+}
 
 // NewStorage returns a new hash storage containing hashes of the domain names
 // listed in hostnames, one domain name per line, requirements are described in
-// [Storage.Reset].  Empty string causes no errors.
+// [Storage.Reset].  Empty string causes no errors.  The hashes are kept on the
+// heap.
 func NewStorage(hostnames string) (s *Storage, err error) {
-	s = &Storage{
-		resetMu:      &sync.Mutex{},
-		hashSuffixes: &atomic.Pointer[suffixMap]{},
-	}
-
-	s.hashSuffixes.Store(&suffixMap{})
+	s = newStorage("")
 
 	if hostnames != "" {
 		_, err = s.Reset(hostnames)
@@ -49,6 +45,38 @@ func NewStorage(hostnames string) (s *Storage, err error) {
 	return s, nil
 }
 
+// NewFileStorage returns a new empty hash storage, which writes the hashes into
+// the binary file with the given path on each [Storage.Reset] and maps the file
+// into memory instead of keeping the hashes on the heap.  The directory of
+// filePath must exist and be writable.
+func NewFileStorage(filePath string) (s *Storage) {
+	return newStorage(filePath)
+}
+
+// newStorage returns a new empty hash storage with the given file path.
+func newStorage(filePath string) (s *Storage) {
+	s = &Storage{
+		resetMu:  &sync.Mutex{},
+		table:    &atomic.Pointer[hashTable]{},
+		filePath: filePath,
+	}
+
+	s.table.Store(newHashTable(nil, nil))
+
+	return s
+}
+
+// acquireTable returns the current table with an additional reference.  The
+// caller must call [hashTable.release] when it's done with the table.
+func (s *Storage) acquireTable() (t *hashTable) {
+	for {
+		t = s.table.Load()
+		if t.acquire() {
+			return t
+		}
+	}
+}
+
 // Hashes returns all hashes starting with the given prefixes, if any.  The
 // resulting slice shares storage for all underlying strings.
 //
@@ -58,12 +86,14 @@ func (s *Storage) Hashes(prefs []Prefix) (hashes []string) {
 		return nil
 	}
 
+	t := s.acquireTable()
+	defer t.release()
+
 	// First, calculate the number of hashes to allocate the buffer.
-	hashSuffixes := *s.hashSuffixes.Load()
 	l := 0
 	for _, pref := range prefs {
-		hashSufs := hashSuffixes[pref]
-		l += len(hashSufs)
+		start, end := t.prefixRange(pref)
+		l += end - start
 	}
 
 	// Then, allocate the buffer of the appropriate size and write all hashes
@@ -71,8 +101,8 @@ func (s *Storage) Hashes(prefs []Prefix) (hashes []string) {
 	// garbage collector's work easier.  This assumes that all references to
 	// this buffer will become unreachable at the same time.
 	//
-	// The fact that we iterate over the [s.hashSuffixes] map twice shouldn't
-	// matter, since we assume that len(hps) will be below 5 most of the time.
+	// The fact that we search the table twice shouldn't matter, since we
+	// assume that len(hps) will be below 5 most of the time.
 	b := &strings.Builder{}
 	b.Grow(l * hashEncLen)
 
@@ -81,10 +111,9 @@ func (s *Storage) Hashes(prefs []Prefix) (hashes []string) {
 	// performance hit.
 	var buf [hashEncLen]byte
 	for _, pref := range prefs {
-		hashSufs := hashSuffixes[pref]
-		for _, suf := range hashSufs {
-			hex.Encode(buf[:], pref[:])
-			hex.Encode(buf[PrefixEncLen:], suf[:])
+		start, end := t.prefixRange(pref)
+		for i := start; i < end; i++ {
+			hex.Encode(buf[:], t.hashAt(i))
 			_, _ = b.Write(buf[:])
 		}
 	}
@@ -101,32 +130,20 @@ func (s *Storage) Hashes(prefs []Prefix) (hashes []string) {
 // Matches returns true if the host matches one of the hashes.
 func (s *Storage) Matches(host string) (ok bool) {
 	sum := sha256.Sum256([]byte(host))
-	pref := Prefix(sum[:PrefixLen])
-
-	var buf [hashLen]byte
-	hashSufs, ok := s.loadHashSuffixes(pref)
-	if !ok {
-		return false
-	}
 
-	copy(buf[:], pref[:])
-	for _, suf := range hashSufs {
-		copy(buf[PrefixLen:], suf[:])
-		if buf == sum {
-			return true
-		}
-	}
+	t := s.acquireTable()
+	defer t.release()
 
-	return false
+	return t.contains(&sum)
 }
 
-// loadHashSuffixes returns hash suffixes for the given prefix.  It is safe for
-// concurrent use.  sufs must not be modified.
-func (s *Storage) loadHashSuffixes(pref Prefix) (sufs []suffix, ok bool) {
-	suffixes := *s.hashSuffixes.Load()
-	sufs, ok = suffixes[pref]
+// Size returns the size of the current table of hashes in bytes.  If the
+// storage has a file, this is the size of the memory mapped from that file.
+func (s *Storage) Size() (n int) {
+	t := s.acquireTable()
+	defer t.release()
 
-	return sufs, ok
+	return len(t.data)
 }
 
 // Reset resets the hosts in the index using the domain names listed in
@@ -137,32 +154,30 @@ func (s *Storage) Reset(hostnames string) (n int, err error) {
 	s.resetMu.Lock()
 	defer s.resetMu.Unlock()
 
-	next := make(suffixMap, len(*s.hashSuffixes.Load()))
+	sums, n, err := parseHashes(hostnames)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return 0, err
+	}
 
-	sc := bufio.NewScanner(strings.NewReader(hostnames))
-	for sc.Scan() {
-		host := sc.Text()
-		if len(host) == 0 || host[0] == '#' {
-			continue
+	var next *hashTable
+	if s.filePath == "" {
+		data := make([]byte, 0, len(sums)*hashLen)
+		for _, sum := range sums {
+			data = append(data, sum[:]...)
 		}
 
-		sum := sha256.Sum256([]byte(host))
-		pref := Prefix(sum[:PrefixLen])
-		suf := suffix(sum[PrefixLen:])
-		next[pref] = append(next[pref], suf)
-
-		n++
-	}
-
-	err = sc.Err()
-	if err != nil {
-		return 0, fmt.Errorf("scanning hosts: %w", err)
+		next = newHashTable(data, nil)
+	} else {
+		next, err = writeTableFile(s.filePath, sums)
+		if err != nil {
+			return 0, fmt.Errorf("writing table: %w", err)
+		}
 	}
 
-	s.hashSuffixes.Store(&next)
-
-	// Do not try to clear and reuse the previous map.  Any attempt to do that
-	// in a thread-safe fashion will result in excessive locking and complexity.
+	// The previous table is unmapped as soon as the concurrent calls to
+	// [Storage.Matches] and [Storage.Hashes] are done with it.
+	s.table.Swap(next).release()
 
 	return n, nil
 }
@@ -4,6 +4,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -75,8 +77,49 @@ func TestStorage_Reset(t *testing.T) {
 	assert.True(t, s.Matches(filtertest.Host))
 }
 
+func TestFileStorage(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "hashes"+hashprefix.TableFileExt)
+	s := hashprefix.NewFileStorage(filePath)
+
+	assert.False(t, s.Matches(filtertest.HostAdultContent))
+	assert.Zero(t, s.Size())
+
+	n, err := s.Reset(testHashes)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, n)
+	assert.True(t, s.Matches(filtertest.HostAdultContent))
+	assert.False(t, s.Matches(filtertest.Host))
+	assert.Equal(t, sha256.Size, s.Size())
+
+	const newHashes = filtertest.Host + "\n" + filtertest.Host + "\n" +
+		filtertest.HostAdultContent + "\n"
+
+	n, err = s.Reset(newHashes)
+	require.NoError(t, err)
+
+	// Make sure that the duplicates are counted as rules but are only stored
+	// once.
+	assert.Equal(t, 3, n)
+	assert.True(t, s.Matches(filtertest.Host))
+	assert.True(t, s.Matches(filtertest.HostAdultContent))
+	assert.Equal(t, 2*sha256.Size, s.Size())
+
+	h := sha256.Sum256([]byte(filtertest.Host))
+	want := []string{hex.EncodeToString(h[:])}
+
+	got := s.Hashes([]hashprefix.Prefix{{h[0], h[1]}})
+	assert.Equal(t, want, got)
+
+	fi, err := os.Stat(filePath)
+	require.NoError(t, err)
+
+	assert.Greater(t, fi.Size(), int64(s.Size()))
+}
+
 // Sinks for benchmarks.
 var (
+	boolSink bool
 	errSink  error
 	strsSink []string
 )
@@ -109,16 +152,78 @@ func BenchmarkStorage_Hashes(b *testing.B) {
 		})
 	}
 
-	// Most recent results, on a ThinkPad X13 with a Ryzen Pro 7 CPU:
+	// Most recent results:
+	//
+	//	goos: linux
+	//	goarch: amd64
+	//	pkg: github.com/AdguardTeam/AdGuardDNS/internal/filter/hashprefix
+	//	cpu: Intel(R) Xeon(R) Processor
+	//	BenchmarkStorage_Hashes/1   	 1830309	       638.9 ns/op	      80 B/op	       2 allocs/op
+	//	BenchmarkStorage_Hashes/2   	 1278153	       891.2 ns/op	      80 B/op	       2 allocs/op
+	//	BenchmarkStorage_Hashes/3   	 1237300	       815.8 ns/op	      80 B/op	       2 allocs/op
+	//	BenchmarkStorage_Hashes/4   	 1000000	      1019 ns/op	      80 B/op	       2 allocs/op
+}
+
+func BenchmarkStorage_Matches(b *testing.B) {
+	const N = 100_000
+
+	var hosts []string
+	for i := range N {
+		hosts = append(hosts, fmt.Sprintf("%d."+filtertest.HostAdultContent, i))
+	}
+
+	hostnames := strings.Join(hosts, "\n")
+
+	heapStrg, err := hashprefix.NewStorage(hostnames)
+	require.NoError(b, err)
+
+	fileStrg := hashprefix.NewFileStorage(filepath.Join(b.TempDir(), "hashes"))
+	_, err = fileStrg.Reset(hostnames)
+	require.NoError(b, err)
+
+	benchCases := []struct {
+		strg *hashprefix.Storage
+		name string
+	}{{
+		strg: heapStrg,
+		name: "heap",
+	}, {
+		strg: fileStrg,
+		name: "file",
+	}}
+
+	for _, bc := range benchCases {
+		b.Run(bc.name+"_match", func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				boolSink = bc.strg.Matches(hosts[N/2])
+			}
+
+			require.True(b, boolSink)
+		})
+
+		b.Run(bc.name+"_no_match", func(b *testing.B) {
+			b.ReportAllocs()
+			b.ResetTimer()
+			for range b.N {
+				boolSink = bc.strg.Matches(filtertest.Host)
+			}
+
+			require.False(b, boolSink)
+		})
+	}
+
+	// Most recent results:
 	//
 	//	goos: linux
 	//	goarch: amd64
 	//	pkg: github.com/AdguardTeam/AdGuardDNS/internal/filter/hashprefix
-	//	cpu: AMD Ryzen 7 PRO 4750U with Radeon Graphics
-	//	BenchmarkStorage_Hashes/1-16      	156682185	        40.31 ns/op	       0 B/op	       0 allocs/op
-	//	BenchmarkStorage_Hashes/2-16      	81397060	        67.39 ns/op	       0 B/op	       0 allocs/op
-	//	BenchmarkStorage_Hashes/3-16      	61833548	       104.3 ns/op	       0 B/op	       0 allocs/op
-	//	BenchmarkStorage_Hashes/4-16      	44809807	       146.9 ns/op	       0 B/op	       0 allocs/op
+	//	cpu: Intel(R) Xeon(R) Processor
+	//	BenchmarkStorage_Matches/heap_match         	 4290364	       257.5 ns/op	       0 B/op	       0 allocs/op
+	//	BenchmarkStorage_Matches/heap_no_match      	 4811623	       279.2 ns/op	       0 B/op	       0 allocs/op
+	//	BenchmarkStorage_Matches/file_match         	 3749811	       314.9 ns/op	       0 B/op	       0 allocs/op
+	//	BenchmarkStorage_Matches/file_no_match      	 3956707	       308.0 ns/op	       0 B/op	       0 allocs/op
 }
 
 func BenchmarkStorage_ResetHosts(b *testing.B) {
@@ -141,11 +246,11 @@ func BenchmarkStorage_ResetHosts(b *testing.B) {
 
 	require.NoError(b, errSink)
 
-	// Most recent results, on a ThinkPad X13 with a Ryzen Pro 7 CPU:
+	// Most recent results:
 	//
 	//	goos: linux
 	//	goarch: amd64
 	//	pkg: github.com/AdguardTeam/AdGuardDNS/internal/filter/hashprefix
-	//	cpu: AMD Ryzen 7 PRO 4750U with Radeon Graphics
-	//	BenchmarkStorage_ResetHosts-16    	   16890	    344785 ns/op	  101968 B/op	    1006 allocs/op
+	//	cpu: Intel(R) Xeon(R) Processor
+	//	BenchmarkStorage_ResetHosts                 	    2161	    516739 ns/op	  137912 B/op	      17 allocs/op
 }
@@ -0,0 +1,270 @@
+package hashprefix
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/google/renameio/v2"
+	"golang.org/x/sys/unix"
+)
+
+// TableFileExt is the conventional extension of the hash-table files.
+const TableFileExt = ".hashes"
+
+// Binary format constants.
+//
+// The binary format of the hash table is a header followed by the sorted and
+// deduplicated SHA256 hashes of the hostnames.  The header consists of:
+//
+//  1. The magic bytes, [tableMagic].
+//  2. The version of the format, [tableVersion], as a big-endian uint16.
+//  3. The number of hashes as a big-endian uint64.
+const (
+	// tableMagic are the magic bytes at the beginning of a hash-table file.
+	tableMagic = "AGDHPS"
+
+	// tableVersion is the current version of the binary format.
+	tableVersion uint16 = 1
+
+	// tableHeaderLen is the length of the header of a hash-table file.
+	tableHeaderLen = len(tableMagic) + 2 + 8
+)
+
+// hashTable is an immutable table of sorted SHA256 hashes of hostnames.  The
+// data may be either allocated on the heap or mapped from a file.
+type hashTable struct {
+	// refs is the number of references to the table.  When it reaches zero,
+	// the data is unmapped.
+	refs *atomic.Int64
+
+	// unmap releases the mapped data, if any.  It is nil for tables on the
+	// heap.
+	unmap func() (err error)
+
+	// data are the sorted hashes, each [hashLen] bytes long.
+	data []byte
+}
+
+// newHashTable returns a new hash table with the given data and a single
+// reference.  unmap may be nil.
+func newHashTable(data []byte, unmap func() (err error)) (t *hashTable) {
+	t = &hashTable{
+		refs:  &atomic.Int64{},
+		unmap: unmap,
+		data:  data,
+	}
+
+	t.refs.Store(1)
+
+	return t
+}
+
+// acquire increments the number of references to t.  It returns false if the
+// table has already been released.
+func (t *hashTable) acquire() (ok bool) {
+	for {
+		n := t.refs.Load()
+		if n <= 0 {
+			return false
+		}
+
+		if t.refs.CompareAndSwap(n, n+1) {
+			return true
+		}
+	}
+}
+
+// release decrements the number of references to t and unmaps the data when
+// there are no more references.
+func (t *hashTable) release() {
+	if t.refs.Add(-1) != 0 || t.unmap == nil {
+		return
+	}
+
+	err := t.unmap()
+	if err != nil {
+		// Don't expect errors here, since the mapping is always valid.
+		panic(fmt.Errorf("hashprefix: unmapping table: %w", err))
+	}
+}
+
+// len returns the number of hashes in t.
+func (t *hashTable) len() (n int) {
+	return len(t.data) / hashLen
+}
+
+// hashAt returns the hash with index i.  sum must not be modified.
+func (t *hashTable) hashAt(i int) (sum []byte) {
+	return t.data[i*hashLen : (i+1)*hashLen]
+}
+
+// search returns the index of the first hash in t that is not less than key.
+// key must not be longer than [hashLen].
+func (t *hashTable) search(key []byte) (i int) {
+	return sort.Search(t.len(), func(i int) (ok bool) {
+		return bytes.Compare(t.hashAt(i)[:len(key)], key) >= 0
+	})
+}
+
+// contains returns true if t contains sum.
+func (t *hashTable) contains(sum *[hashLen]byte) (ok bool) {
+	i := t.search(sum[:])
+
+	return i < t.len() && bytes.Equal(t.hashAt(i), sum[:])
+}
+
+// prefixRange returns the range of indexes of the hashes starting with pref.
+func (t *hashTable) prefixRange(pref Prefix) (start, end int) {
+	start = t.search(pref[:])
+	for end = start; end < t.len(); end++ {
+		if !bytes.HasPrefix(t.hashAt(end), pref[:]) {
+			break
+		}
+	}
+
+	return start, end
+}
+
+// parseHashes returns the sorted and deduplicated hashes of the domain names
+// listed in hostnames as well as the total number of processed rules.  See
+// [Storage.Reset] for the requirements for hostnames.
+func parseHashes(hostnames string) (sums [][hashLen]byte, n int, err error) {
+	sc := bufio.NewScanner(strings.NewReader(hostnames))
+	for sc.Scan() {
+		host := sc.Text()
+		if len(host) == 0 || host[0] == '#' {
+			continue
+		}
+
+		sums = append(sums, sha256.Sum256([]byte(host)))
+
+		n++
+	}
+
+	err = sc.Err()
+	if err != nil {
+		return nil, 0, fmt.Errorf("scanning hosts: %w", err)
+	}
+
+	slices.SortFunc(sums, func(a, b [hashLen]byte) (res int) {
+		return bytes.Compare(a[:], b[:])
+	})
+
+	return slices.Compact(sums), n, nil
+}
+
+// writeTable writes sums in the binary format into w.  sums must be sorted and
+// deduplicated.
+func writeTable(w io.Writer, sums [][hashLen]byte) (err error) {
+	var hdr [tableHeaderLen]byte
+	copy(hdr[:], tableMagic)
+	binary.BigEndian.PutUint16(hdr[len(tableMagic):], tableVersion)
+	binary.BigEndian.PutUint64(hdr[len(tableMagic)+2:], uint64(len(sums)))
+
+	_, err = w.Write(hdr[:])
+	if err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	for _, sum := range sums {
+		_, err = w.Write(sum[:])
+		if err != nil {
+			return fmt.Errorf("writing hash: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// tableData validates the header of b, which must be the whole content of a
+// hash-table file, and returns the hashes from it.
+func tableData(b []byte) (data []byte, err error) {
+	if len(b) < tableHeaderLen {
+		return nil, fmt.Errorf("header: %w: %d bytes", errors.ErrOutOfRange, len(b))
+	}
+
+	if string(b[:len(tableMagic)]) != tableMagic {
+		return nil, fmt.Errorf("bad magic %q", b[:len(tableMagic)])
+	}
+
+	ver := binary.BigEndian.Uint16(b[len(tableMagic):])
+	if ver != tableVersion {
+		return nil, fmt.Errorf("version: %w: %d", errors.ErrBadEnumValue, ver)
+	}
+
+	data = b[tableHeaderLen:]
+	n := binary.BigEndian.Uint64(b[len(tableMagic)+2:])
+	if uint64(len(data)) != n*hashLen {
+		return nil, fmt.Errorf("%d hashes in %d bytes: %w", n, len(data), errors.ErrOutOfRange)
+	}
+
+	return data, nil
+}
+
+// writeTableFile atomically writes sums in the binary format into the file
+// with the given path and maps it into memory.
+func writeTableFile(filePath string, sums [][hashLen]byte) (t *hashTable, err error) {
+	tmpFile, err := renameio.NewPendingFile(filePath, renameio.WithPermissions(agd.DefaultPerm))
+	if err != nil {
+		return nil, fmt.Errorf("creating temporary file: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, tmpFile.Cleanup()) }()
+
+	w := bufio.NewWriter(tmpFile)
+	err = writeTable(w, sums)
+	if err != nil {
+		return nil, fmt.Errorf("writing table: %w", err)
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return nil, fmt.Errorf("flushing table: %w", err)
+	}
+
+	err = tmpFile.CloseAtomicallyReplace()
+	if err != nil {
+		return nil, fmt.Errorf("replacing table file: %w", err)
+	}
+
+	return openTableFile(filePath)
+}
+
+// openTableFile maps the hash-table file with the given path into memory and
+// returns the table with a single reference.
+func openTableFile(filePath string) (t *hashTable, err error) {
+	// #nosec G304 -- Trust the path to the file, since it's based on the path
+	// from the environment.
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("opening table file: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("getting file info: %w", err)
+	}
+
+	b, err := unix.Mmap(int(f.Fd()), 0, int(fi.Size()), unix.PROT_READ, unix.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("mapping table file: %w", err)
+	}
+
+	data, err := tableData(b)
+	if err != nil {
+		return nil, errors.WithDeferred(fmt.Errorf("table file: %w", err), unix.Munmap(b))
+	}
+
+	return newHashTable(data, func() (err error) { return unix.Munmap(b) }), nil
+}
@@ -0,0 +1,27 @@
+package internal
+
+import "context"
+
+// Category is the content category of a domain name, for example
+// "social_network".  It is an opaque string.
+type Category string
+
+// Classifier is the interface for entities that find the content categories of
+// hosts regardless of whether the requests for them are blocked.
+type Classifier interface {
+	// Classify returns the sorted content categories of host, if any.  host
+	// must be a lowercased, non-FQDN domain name.  cats must not be modified.
+	Classify(ctx context.Context, host string) (cats []Category)
+}
+
+// EmptyClassifier is the implementation of the [Classifier] interface that
+// never returns any categories.
+type EmptyClassifier struct{}
+
+// type check
+var _ Classifier = EmptyClassifier{}
+
+// Classify implements the [Classifier] interface for EmptyClassifier.
+func (EmptyClassifier) Classify(_ context.Context, _ string) (cats []Category) {
+	return nil
+}
@@ -5,6 +5,8 @@ package composite
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/netip"
 	"strings"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
@@ -13,6 +15,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/rulelist"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/safesearch"
+	"github.com/AdguardTeam/urlfilter"
 	"github.com/miekg/dns"
 )
 
@@ -26,10 +29,25 @@ type Filter struct {
 	// group.
 	ruleLists []*rulelist.Refreshable
 
+	// combined is the filter built from ruleLists, if any.  If it is not nil,
+	// it is used instead of ruleLists for matching.
+	combined *rulelist.Combined
+
 	// svcLists are the rule-list filters of the profile's enabled blocked
 	// services, if any.
 	svcLists []*rulelist.Immutable
 
+	// essential is the rule-list filter of the essential services, which are
+	// allowed in the allowlist-only mode and during block-until overrides, if
+	// any.
+	essential *rulelist.Immutable
+
+	// blockedList is the ID of the special list used in the results for
+	// requests that are blocked, because they aren't explicitly allowed.  It is
+	// empty if neither the allowlist-only mode nor a block-until override is
+	// enabled.
+	blockedList internal.ID
+
 	// reqFilters are the safe-browsing and safe-search request filters in the
 	// composite filter.
 	reqFilters []internal.RequestFilter
@@ -53,6 +71,10 @@ type Config struct {
 	// YouTubeSafeSearch is the youtube safe-search filter to apply, if any.
 	YouTubeSafeSearch *safesearch.Filter
 
+	// BlockPage is the configuration of the block page used in the responses
+	// of the hash-prefix filters instead of their replacement hosts, if any.
+	BlockPage *internal.ConfigBlockPage
+
 	// Custom is the custom rule-list filter of the profile, if any.
 	Custom *rulelist.Immutable
 
@@ -60,9 +82,29 @@ type Config struct {
 	// group, if any.  All items must not be nil.
 	RuleLists []*rulelist.Refreshable
 
+	// EssentialServices is the rule-list filter with the allowlist rules of
+	// the essential services, if any.  It is ignored if both
+	// [Config.AllowlistOnly] and [Config.BlockAll] are false.
+	EssentialServices *rulelist.Immutable
+
+	// CombinedRuleLists is the filter built from [Config.RuleLists], if any.
+	// If it is not nil, it must match [Config.RuleLists], see
+	// [rulelist.Combined.Matches].
+	CombinedRuleLists *rulelist.Combined
+
 	// ServiceLists are the rule-list filters of the profile's enabled blocked
 	// services, if any.  All items must not be nil.
 	ServiceLists []*rulelist.Immutable
+
+	// AllowlistOnly, if true, makes the filter block all requests except those
+	// allowed by the custom filter or by [Config.EssentialServices].
+	AllowlistOnly bool
+
+	// BlockAll, if true, makes the filter block all requests except those
+	// allowed by the custom filter or by [Config.EssentialServices] with
+	// [internal.IDBlockUntil] as the list.  It is used for block-until
+	// overrides and takes precedence over [Config.AllowlistOnly].
+	BlockAll bool
 }
 
 // New returns a new composite filter.  c must not be nil.
@@ -70,19 +112,76 @@ func New(c *Config) (f *Filter) {
 	f = &Filter{
 		custom:    c.Custom,
 		ruleLists: c.RuleLists,
+		combined:  c.CombinedRuleLists,
 		svcLists:  c.ServiceLists,
 	}
 
+	switch {
+	case c.BlockAll:
+		f.blockedList = internal.IDBlockUntil
+	case c.AllowlistOnly:
+		f.blockedList = internal.IDAllowlistOnly
+	default:
+		// Go on.
+	}
+
+	if f.blockedList != internal.IDNone {
+		f.essential = c.EssentialServices
+	}
+
 	// DO NOT change the order of request filters without necessity.
-	f.reqFilters = appendReqFilter(f.reqFilters, c.SafeBrowsing)
-	f.reqFilters = appendReqFilter(f.reqFilters, c.AdultBlocking)
+	f.reqFilters = appendHashPrefix(f.reqFilters, c.SafeBrowsing, c.BlockPage)
+	f.reqFilters = appendHashPrefix(f.reqFilters, c.AdultBlocking, c.BlockPage)
 	f.reqFilters = appendReqFilter(f.reqFilters, c.GeneralSafeSearch)
 	f.reqFilters = appendReqFilter(f.reqFilters, c.YouTubeSafeSearch)
-	f.reqFilters = appendReqFilter(f.reqFilters, c.NewRegisteredDomains)
+	f.reqFilters = appendHashPrefix(f.reqFilters, c.NewRegisteredDomains, c.BlockPage)
 
 	return f
 }
 
+// appendHashPrefix appends flt to flts if flt is not nil.  If bp is not nil,
+// flt is wrapped to use it in the responses.
+func appendHashPrefix(
+	flts []internal.RequestFilter,
+	flt *hashprefix.Filter,
+	bp *internal.ConfigBlockPage,
+) (res []internal.RequestFilter) {
+	if flt == nil {
+		return flts
+	} else if bp == nil {
+		return append(flts, flt)
+	}
+
+	return append(flts, &blockPageFilter{
+		flt: flt,
+		bp:  bp,
+	})
+}
+
+// blockPageFilter is a hash-prefix filter that uses the block page of the
+// profile or filtering group in its responses.
+type blockPageFilter struct {
+	flt *hashprefix.Filter
+	bp  *internal.ConfigBlockPage
+}
+
+// type check
+var _ internal.RequestFilter = (*blockPageFilter)(nil)
+
+// FilterRequest implements the [internal.RequestFilter] interface for
+// *blockPageFilter.
+func (f *blockPageFilter) FilterRequest(
+	ctx context.Context,
+	req *internal.Request,
+) (r internal.Result, err error) {
+	return f.flt.FilterRequestWithBlockPage(ctx, req, f.bp)
+}
+
+// ID implements the [internal.RequestFilter] interface for *blockPageFilter.
+func (f *blockPageFilter) ID() (id internal.ID) {
+	return f.flt.ID()
+}
+
 // appendReqFilter appends flt to flts if flt is not nil.
 func appendReqFilter[T *hashprefix.Filter | *safesearch.Filter](
 	flts []internal.RequestFilter,
@@ -110,6 +209,10 @@ var _ filter.Interface = (*Filter)(nil)
 //  7. YouTube safe-search filter.
 //  8. Newly-registered domains filter.
 //
+// In the allowlist-only mode and during block-until overrides, requests that
+// aren't allowed by the custom filter are blocked, unless they are allowed by
+// the essential-services filter, before the request filters are applied.
+//
 // If f is empty, it returns nil with no error.
 func (f *Filter) FilterRequest(
 	ctx context.Context,
@@ -137,6 +240,15 @@ func (f *Filter) FilterRequest(
 		// Go on.
 	}
 
+	if f.blockedList != internal.IDNone {
+		rlRes = f.filterReqWithEssential(req)
+		if rlRes == nil {
+			return &internal.ResultBlocked{
+				List: f.blockedList,
+			}, nil
+		}
+	}
+
 	for _, rf := range f.reqFilters {
 		r, err = rf.FilterRequest(ctx, req)
 		if err != nil {
@@ -171,37 +283,92 @@ func (f *Filter) filterReqWithRuleLists(req *internal.Request) (r internal.Resul
 		ufRes.Add(dr)
 	}
 
+	if f.combined != nil {
+		dr := f.combined.DNSResult(ip, "", host, qt, false)
+		if mod := f.processCombinedDNSRewrites(req, dr); mod != nil {
+			return mod
+		}
+
+		ufRes.Add(dr)
+	} else {
+		for _, rl := range f.ruleLists {
+			id, _ := rl.ID()
+			dr := rl.DNSResult(ip, "", host, qt, false)
+			mod := rulelist.ProcessDNSRewrites(req, dr.DNSRewrites(), id)
+			if mod != nil {
+				// DNS rewrites have higher priority, so a modified request must
+				// be returned immediately.
+				return mod
+			}
+
+			ufRes.Add(dr)
+		}
+	}
+
+	for _, rl := range f.svcLists {
+		ufRes.Add(rl.DNSResult(ip, "", host, qt, false))
+	}
+
+	return ufRes.ToInternal(f, qt)
+}
+
+// processCombinedDNSRewrites processes the $dnsrewrite rules from the result of
+// the combined filter the same way they would have been processed with the
+// separate rule-list filters, that is list by list.  req must not be nil.
+func (f *Filter) processCombinedDNSRewrites(
+	req *internal.Request,
+	dr *urlfilter.DNSResult,
+) (r internal.Result) {
+	if !rulelist.HasDNSRewrites(dr) {
+		return nil
+	}
+
 	for _, rl := range f.ruleLists {
 		id, _ := rl.ID()
-		dr := rl.DNSResult(ip, "", host, qt, false)
-		mod := rulelist.ProcessDNSRewrites(req, dr.DNSRewrites(), id)
+		dnsr := rulelist.DNSRewritesForList(dr, rl.URLFilterID())
+		mod := rulelist.ProcessDNSRewrites(req, dnsr, id)
 		if mod != nil {
 			// DNS rewrites have higher priority, so a modified request must be
 			// returned immediately.
 			return mod
 		}
+	}
 
-		ufRes.Add(dr)
+	return nil
+}
+
+// filterReqWithEssential returns the allowed result if the request is allowed
+// by the essential-services filter.  Otherwise, it returns nil.  req must not be
+// nil.
+func (f *Filter) filterReqWithEssential(req *internal.Request) (r internal.Result) {
+	if f.essential == nil {
+		return nil
 	}
 
-	for _, rl := range f.svcLists {
-		ufRes.Add(rl.DNSResult(ip, "", host, qt, false))
+	ufRes := &rulelist.URLFilterResult{}
+	ufRes.Add(f.essential.DNSResult(req.RemoteIP, "", req.Host, req.QType, false))
+
+	r = ufRes.ToInternal(f, req.QType)
+	if _, ok := r.(*internal.ResultAllowed); !ok {
+		return nil
 	}
 
-	return ufRes.ToInternal(f, qt)
+	return r
 }
 
 // FilterResponse implements the [internal.Interface] interface for *Filter.  It
 // returns the action created from the filter list network rule with the highest
-// priority.  If f is empty, it returns nil with no error.  Note that rewrite
-// results are not applied to responses.
+// priority.  All answers are checked, and a blocking result for an address of
+// either family takes precedence over the other results.  If f is empty, it
+// returns nil with no error.  Note that rewrite results are not applied to
+// responses.
 func (f *Filter) FilterResponse(
 	_ context.Context,
 	resp *internal.Response,
 ) (r internal.Result, err error) {
 	for _, ans := range resp.DNS.Answer {
-		r = f.filterAnswer(resp, ans)
-		if r != nil {
+		r = stricterResult(r, f.filterAnswer(resp, ans))
+		if isBlocked(r) {
 			break
 		}
 	}
@@ -209,19 +376,61 @@ func (f *Filter) FilterResponse(
 	return r, nil
 }
 
+// stricterResult returns next if it is a blocking result and cur is not or if
+// cur is nil.  Otherwise, it returns cur.
+func stricterResult(cur, next internal.Result) (r internal.Result) {
+	if cur == nil || (isBlocked(next) && !isBlocked(cur)) {
+		return next
+	}
+
+	return cur
+}
+
+// isBlocked returns true if r is a blocking result.
+func isBlocked(r internal.Result) (ok bool) {
+	_, ok = r.(*internal.ResultBlocked)
+
+	return ok
+}
+
 // filterAnswer filters a single answer of a response.  r is not nil if the
 // response is filtered.
 func (f *Filter) filterAnswer(resp *internal.Response, ans dns.RR) (r internal.Result) {
-	if rr, ok := ans.(*dns.HTTPS); ok {
-		return f.filterHTTPSAnswer(resp, rr)
+	switch rr := ans.(type) {
+	case *dns.A:
+		return f.filterRespIP(resp, rr.A, dns.TypeA)
+	case *dns.AAAA:
+		return f.filterRespIP(resp, rr.AAAA, dns.TypeAAAA)
+	case *dns.CNAME:
+		return f.filterRespWithRuleLists(resp, strings.TrimSuffix(rr.Target, "."), dns.TypeCNAME)
+	case *dns.HTTPS:
+		return f.filterSVCBAnswer(resp, &rr.SVCB)
+	case *dns.SVCB:
+		return f.filterSVCBAnswer(resp, rr)
+	default:
+		return nil
 	}
+}
 
-	host, rrType, ok := parseRespAnswer(ans)
+// filterRespIP filters an IP address from an answer through all rule-list
+// filters of the composite filter.  IPv4-mapped IPv6 addresses are checked in
+// both forms, so that the rules for either family apply to them.
+func (f *Filter) filterRespIP(
+	resp *internal.Response,
+	netIP net.IP,
+	rrType dnsmsg.RRType,
+) (r internal.Result) {
+	ip, ok := netip.AddrFromSlice(netIP)
 	if !ok {
 		return nil
 	}
 
-	return f.filterRespWithRuleLists(resp, host, rrType)
+	r = f.filterRespWithRuleLists(resp, ip.Unmap().String(), rrType)
+	if ip.Is4In6() && !isBlocked(r) {
+		r = stricterResult(r, f.filterRespWithRuleLists(resp, ip.String(), rrType))
+	}
+
+	return r
 }
 
 // filterRespWithRuleLists filters one answer's information through all
@@ -232,8 +441,12 @@ func (f *Filter) filterRespWithRuleLists(
 	rrType dnsmsg.RRType,
 ) (r internal.Result) {
 	ufRes := &rulelist.URLFilterResult{}
-	for _, rl := range f.ruleLists {
-		ufRes.Add(rl.DNSResult(resp.RemoteIP, "", host, rrType, true))
+	if f.combined != nil {
+		ufRes.Add(f.combined.DNSResult(resp.RemoteIP, "", host, rrType, true))
+	} else {
+		for _, rl := range f.ruleLists {
+			ufRes.Add(rl.DNSResult(resp.RemoteIP, "", host, rrType, true))
+		}
 	}
 
 	if f.custom != nil {
@@ -247,51 +460,43 @@ func (f *Filter) filterRespWithRuleLists(
 	return ufRes.ToInternal(f, rrType)
 }
 
-// filterHTTPSAnswer filters HTTPS answers information through all rule list
-// filters of the composite filter.
-func (f *Filter) filterHTTPSAnswer(resp *internal.Response, rr *dns.HTTPS) (r internal.Result) {
-	for _, kv := range rr.Value {
-		switch kv.Key() {
-		case dns.SVCB_IPV4HINT, dns.SVCB_IPV6HINT:
-			r = f.filterSVCBHint(kv.String(), resp)
-			if r != nil {
-				return r
-			}
-		default:
-			// Go on.
+// filterSVCBAnswer filters the target and the IP hints of HTTPS and SVCB
+// answers through all rule list filters of the composite filter.
+func (f *Filter) filterSVCBAnswer(resp *internal.Response, rr *dns.SVCB) (r internal.Result) {
+	rrType := rr.Hdr.Rrtype
+
+	// Target "." means the owner name, which has already been filtered with
+	// the request, as well as the owner name itself.
+	target := strings.TrimSuffix(rr.Target, ".")
+	if target != "" && !strings.EqualFold(rr.Target, rr.Hdr.Name) {
+		r = f.filterRespWithRuleLists(resp, target, rrType)
+		if isBlocked(r) {
+			return r
 		}
 	}
 
-	return nil
-}
+	for _, kv := range rr.Value {
+		// NOTE:  Don't use the String methods of the hints, since they return
+		// "<nil>" for the IPv6 hints containing IPv4-mapped addresses.
+		var hint []net.IP
+		switch kv := kv.(type) {
+		case *dns.SVCBIPv4Hint:
+			hint = kv.Hint
+		case *dns.SVCBIPv6Hint:
+			hint = kv.Hint
+		default:
+			continue
+		}
 
-// filterSVCBHint filters SVCB hint information through all rule list filters of
-// the composite filter.
-func (f *Filter) filterSVCBHint(hint string, resp *internal.Response) (r internal.Result) {
-	for _, s := range strings.Split(hint, ",") {
-		r = f.filterRespWithRuleLists(resp, s, dns.TypeHTTPS)
-		if r != nil {
-			return r
+		for _, netIP := range hint {
+			r = stricterResult(r, f.filterRespIP(resp, netIP, rrType))
+			if isBlocked(r) {
+				return r
+			}
 		}
 	}
 
-	return nil
-}
-
-// parseRespAnswer parses hostname and rrType from the answer if there are any.
-// If ans is of a type that doesn't have an IP address or a hostname in it, ok
-// is false.
-func parseRespAnswer(ans dns.RR) (hostname string, rrType dnsmsg.RRType, ok bool) {
-	switch ans := ans.(type) {
-	case *dns.A:
-		return ans.A.String(), dns.TypeA, true
-	case *dns.AAAA:
-		return ans.AAAA.String(), dns.TypeAAAA, true
-	case *dns.CNAME:
-		return strings.TrimSuffix(ans.Target, "."), dns.TypeCNAME, true
-	default:
-		return "", dns.TypeNone, false
-	}
+	return r
 }
 
 // type check
@@ -317,6 +522,10 @@ func (f *Filter) Map(id int) (fltID internal.ID, svcID internal.BlockedServiceID
 		}
 	}
 
+	if rl := f.essential; rl != nil && rl.URLFilterID() == id {
+		return rl.ID()
+	}
+
 	// Technically shouldn't happen, since id is supposed to be among the rule
 	// list filters in the composite filter.
 	panic(fmt.Errorf("filter: synthetic id %d not found", id))
@@ -2,6 +2,7 @@ package composite_test
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"net/netip"
 	"testing"
@@ -161,6 +162,120 @@ func TestFilter_FilterRequest_customAllow(t *testing.T) {
 	assert.Equal(t, want, res)
 }
 
+func TestFilter_FilterRequest_allowlistOnly(t *testing.T) {
+	const (
+		allowRule     = "@@" + filtertest.RuleBlockStr
+		essentialRule = "@@|" + filtertest.Host + "^"
+	)
+
+	essentialRL := newImmutable(t, essentialRule, internal.IDEssentialServices)
+
+	testCases := []struct {
+		custom  *rulelist.Immutable
+		wantRes internal.Result
+		name    string
+		host    string
+	}{{
+		custom:  nil,
+		wantRes: &internal.ResultBlocked{List: internal.IDAllowlistOnly},
+		name:    "not_allowed",
+		host:    filtertest.HostBlocked,
+	}, {
+		custom: newImmutable(t, allowRule, internal.IDCustom),
+		wantRes: &internal.ResultAllowed{
+			List: internal.IDCustom,
+			Rule: allowRule,
+		},
+		name: "custom_allowed",
+		host: filtertest.HostBlocked,
+	}, {
+		custom: nil,
+		wantRes: &internal.ResultAllowed{
+			List: internal.IDEssentialServices,
+			Rule: essentialRule,
+		},
+		name: "essential",
+		host: filtertest.Host,
+	}, {
+		custom: newImmutable(t, "|"+filtertest.Host+"^", internal.IDCustom),
+		wantRes: &internal.ResultBlocked{
+			List: internal.IDCustom,
+			Rule: internal.RuleText("|" + filtertest.Host + "^"),
+		},
+		name: "essential_custom_blocked",
+		host: filtertest.Host,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := composite.New(&composite.Config{
+				Custom:            tc.custom,
+				EssentialServices: essentialRL,
+				AllowlistOnly:     true,
+			})
+
+			ctx, req := newReqData(t)
+			req.Host = tc.host
+			req.DNS = dnsservertest.NewReq(dns.Fqdn(tc.host), dns.TypeA, dns.ClassINET)
+
+			res, err := f.FilterRequest(ctx, req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
+
+func TestFilter_FilterRequest_blockAll(t *testing.T) {
+	const essentialRule = "@@|" + filtertest.Host + "^"
+
+	essentialRL := newImmutable(t, essentialRule, internal.IDEssentialServices)
+
+	testCases := []struct {
+		wantRes       internal.Result
+		name          string
+		host          string
+		allowlistOnly bool
+	}{{
+		wantRes:       &internal.ResultBlocked{List: internal.IDBlockUntil},
+		name:          "blocked",
+		host:          filtertest.HostBlocked,
+		allowlistOnly: false,
+	}, {
+		wantRes:       &internal.ResultBlocked{List: internal.IDBlockUntil},
+		name:          "blocked_allowlist_only",
+		host:          filtertest.HostBlocked,
+		allowlistOnly: true,
+	}, {
+		wantRes: &internal.ResultAllowed{
+			List: internal.IDEssentialServices,
+			Rule: essentialRule,
+		},
+		name:          "essential",
+		host:          filtertest.Host,
+		allowlistOnly: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			f := composite.New(&composite.Config{
+				EssentialServices: essentialRL,
+				AllowlistOnly:     tc.allowlistOnly,
+				BlockAll:          true,
+			})
+
+			ctx, req := newReqData(t)
+			req.Host = tc.host
+			req.DNS = dnsservertest.NewReq(dns.Fqdn(tc.host), dns.TypeA, dns.ClassINET)
+
+			res, err := f.FilterRequest(ctx, req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantRes, res)
+		})
+	}
+}
+
 func TestFilter_FilterRequest_dnsrewrite(t *testing.T) {
 	const (
 		blockRule             = filtertest.RuleBlockStr
@@ -405,6 +520,7 @@ func TestFilter_FilterRequest_safeSearch(t *testing.T) {
 		&safesearch.Config{
 			Refreshable: &refreshable.Config{
 				Logger:    slogutil.NewDiscardLogger(),
+				Metrics:   internal.EmptyMetrics{},
 				URL:       srvURL,
 				ID:        fltListID,
 				CachePath: cachePath,
@@ -476,17 +592,23 @@ func TestFilter_FilterResponse(t *testing.T) {
 
 	const (
 		passedIPv4Str  = "1.1.1.1"
+		allowedIPv4Str = "5.6.7.8"
 		blockedIPv4Str = "1.2.3.4"
 		blockedIPv6Str = "1234::cdef"
 		blockRules     = filtertest.HostBlocked + "\n" +
 			blockedIPv4Str + "\n" +
-			blockedIPv6Str + "\n"
+			blockedIPv6Str + "\n" +
+			"@@||" + allowedIPv4Str + "^\n"
 	)
 
 	var (
 		passedIPv4  = netip.MustParseAddr(passedIPv4Str)
+		allowedIPv4 = netip.MustParseAddr(allowedIPv4Str)
 		blockedIPv4 = netip.MustParseAddr(blockedIPv4Str)
 		blockedIPv6 = netip.MustParseAddr(blockedIPv6Str)
+
+		passedMappedIPv6  = netip.AddrFrom16(passedIPv4.As16())
+		blockedMappedIPv6 = netip.AddrFrom16(blockedIPv4.As16())
 	)
 
 	blockingRL := newFromStr(t, blockRules, filtertest.RuleListID1)
@@ -535,6 +657,23 @@ func TestFilter_FilterResponse(t *testing.T) {
 			dnsservertest.NewAAAA(filtertest.FQDNBlocked, ttl, blockedIPv6),
 		},
 		qType: dns.TypeAAAA,
+	}, {
+		name:     "ipv4_mapped_ipv6",
+		reqFQDN:  filtertest.FQDNBlocked,
+		wantRule: blockedIPv4Str,
+		respAns: dnsservertest.SectionAnswer{
+			dnsservertest.NewAAAA(filtertest.FQDNBlocked, ttl, blockedMappedIPv6),
+		},
+		qType: dns.TypeAAAA,
+	}, {
+		name:     "allowed_ipv4_blocked_ipv6",
+		reqFQDN:  filtertest.FQDNBlocked,
+		wantRule: blockedIPv6Str,
+		respAns: dnsservertest.SectionAnswer{
+			dnsservertest.NewA(filtertest.FQDNBlocked, ttl, allowedIPv4),
+			dnsservertest.NewAAAA(filtertest.FQDNBlocked, ttl, blockedIPv6),
+		},
+		qType: dns.TypeA,
 	}, {
 		name:     "ipv4hint",
 		reqFQDN:  filtertest.FQDNBlocked,
@@ -568,6 +707,28 @@ func TestFilter_FilterResponse(t *testing.T) {
 			[]netip.Addr{blockedIPv6},
 		)},
 		qType: dns.TypeHTTPS,
+	}, {
+		name:     "ipv6hint_mapped",
+		reqFQDN:  filtertest.FQDNBlocked,
+		wantRule: blockedIPv6Str,
+		respAns: dnsservertest.SectionAnswer{dnsservertest.NewHTTPS(
+			filtertest.FQDNBlocked,
+			ttl,
+			[]netip.Addr{},
+			[]netip.Addr{passedMappedIPv6, blockedIPv6},
+		)},
+		qType: dns.TypeHTTPS,
+	}, {
+		name:     "ipv6hint_mapped_ipv4",
+		reqFQDN:  filtertest.FQDNBlocked,
+		wantRule: blockedIPv4Str,
+		respAns: dnsservertest.SectionAnswer{dnsservertest.NewHTTPS(
+			filtertest.FQDNBlocked,
+			ttl,
+			[]netip.Addr{allowedIPv4},
+			[]netip.Addr{blockedMappedIPv6},
+		)},
+		qType: dns.TypeHTTPS,
 	}, {
 		name:     "pass_hints",
 		reqFQDN:  filtertest.FQDNBlocked,
@@ -579,6 +740,59 @@ func TestFilter_FilterResponse(t *testing.T) {
 			[]netip.Addr{},
 		)},
 		qType: dns.TypeHTTPS,
+	}, {
+		name:     "https_target",
+		reqFQDN:  cnameReqFQDN,
+		wantRule: filtertest.HostBlocked,
+		respAns: dnsservertest.SectionAnswer{&dns.HTTPS{
+			SVCB: dns.SVCB{
+				Hdr: dns.RR_Header{
+					Name:   cnameReqFQDN,
+					Rrtype: dns.TypeHTTPS,
+					Class:  dns.ClassINET,
+					Ttl:    ttl,
+				},
+				Priority: 1,
+				Target:   filtertest.FQDNBlocked,
+			},
+		}},
+		qType: dns.TypeHTTPS,
+	}, {
+		name:     "svcb_ipv4hint",
+		reqFQDN:  filtertest.FQDNBlocked,
+		wantRule: blockedIPv4Str,
+		respAns: dnsservertest.SectionAnswer{&dns.SVCB{
+			Hdr: dns.RR_Header{
+				Name:   filtertest.FQDNBlocked,
+				Rrtype: dns.TypeSVCB,
+				Class:  dns.ClassINET,
+				Ttl:    ttl,
+			},
+			Priority: 1,
+			Target:   ".",
+			Value: []dns.SVCBKeyValue{&dns.SVCBIPv4Hint{
+				Hint: []net.IP{blockedIPv4.AsSlice()},
+			}},
+		}},
+		qType: dns.TypeSVCB,
+	}, {
+		name:     "svcb_pass",
+		reqFQDN:  filtertest.FQDNBlocked,
+		wantRule: "",
+		respAns: dnsservertest.SectionAnswer{&dns.SVCB{
+			Hdr: dns.RR_Header{
+				Name:   filtertest.FQDNBlocked,
+				Rrtype: dns.TypeSVCB,
+				Class:  dns.ClassINET,
+				Ttl:    ttl,
+			},
+			Priority: 1,
+			Target:   ".",
+			Value: []dns.SVCBKeyValue{&dns.SVCBIPv4Hint{
+				Hint: []net.IP{passedIPv4.AsSlice()},
+			}},
+		}},
+		qType: dns.TypeSVCB,
 	}}
 
 	for _, tc := range testCases {
@@ -607,3 +821,89 @@ func TestFilter_FilterResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestFilter_FilterRequest_combined(t *testing.T) {
+	const (
+		blockRule        = filtertest.RuleBlockStr
+		allowRule        = "@@" + filtertest.RuleBlockStr
+		refusedRule      = filtertest.RuleBlockStr + "$dnsrewrite=REFUSED"
+		rewriteRule      = filtertest.RuleBlockStr + "$dnsrewrite=1.2.3.4"
+		rewriteAllowRule = allowRule + "$dnsrewrite"
+	)
+
+	ctx, req := newReqData(t)
+
+	testCases := []struct {
+		wantRes internal.Result
+		name    string
+		text1   string
+		text2   string
+	}{{
+		wantRes: &internal.ResultBlocked{
+			List: filtertest.RuleListID1,
+			Rule: blockRule,
+		},
+		name:  "block",
+		text1: blockRule,
+		text2: "! Comment.",
+	}, {
+		wantRes: &internal.ResultAllowed{
+			List: filtertest.RuleListID2,
+			Rule: allowRule,
+		},
+		name:  "allow_other_list",
+		text1: blockRule,
+		text2: allowRule,
+	}, {
+		wantRes: &internal.ResultModifiedResponse{
+			Msg:  dnsservertest.NewResp(dns.RcodeRefused, req.DNS),
+			List: filtertest.RuleListID1,
+			Rule: refusedRule,
+		},
+		name:  "dnsrewrite_first_list",
+		text1: refusedRule,
+		text2: rewriteRule,
+	}, {
+		wantRes: &internal.ResultModifiedResponse{
+			Msg:  dnsservertest.NewResp(dns.RcodeRefused, req.DNS),
+			List: filtertest.RuleListID1,
+			Rule: refusedRule,
+		},
+		name:  "dnsrewrite_exception_other_list",
+		text1: refusedRule,
+		text2: rewriteAllowRule,
+	}}
+
+	for _, tc := range testCases {
+		rls := []*rulelist.Refreshable{
+			newFromStr(t, tc.text1, filtertest.RuleListID1),
+			newFromStr(t, tc.text2, filtertest.RuleListID2),
+		}
+
+		combined, err := rulelist.NewCombined(rls, rulelist.ResultCacheEmpty{})
+		require.NoError(t, err)
+
+		t.Run(tc.name+"_separate", func(t *testing.T) {
+			f := composite.New(&composite.Config{
+				RuleLists: rls,
+			})
+
+			res, fltErr := f.FilterRequest(ctx, req)
+			require.NoError(t, fltErr)
+
+			filtertest.AssertEqualResult(t, tc.wantRes, res)
+		})
+
+		t.Run(tc.name+"_combined", func(t *testing.T) {
+			f := composite.New(&composite.Config{
+				RuleLists:         rls,
+				CombinedRuleLists: combined,
+			})
+
+			res, fltErr := f.FilterRequest(ctx, req)
+			require.NoError(t, fltErr)
+
+			filtertest.AssertEqualResult(t, tc.wantRes, res)
+		})
+	}
+}
@@ -88,6 +88,13 @@ const (
 	BlockedServiceIDDoesNotExist internal.BlockedServiceID = BlockedServiceIDDoesNotExistStr
 )
 
+// Common content categories for tests.
+const (
+	CategoryStr = "social_network"
+
+	Category internal.Category = CategoryStr
+)
+
 // BlockedServiceIndex is a service-index response for tests.
 //
 // See https://github.com/AdguardTeam/HostlistsRegistry/blob/main/assets/services.json.
@@ -96,6 +103,7 @@ const BlockedServiceIndex string = `{
     {
       "id": "` + BlockedServiceID1Str + `",
       "name": "Service 1",
+      "group": "` + CategoryStr + `",
       "rules": [
         "||` + HostBlockedService1 + `^"
       ]
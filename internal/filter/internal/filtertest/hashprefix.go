@@ -65,7 +65,9 @@ func NewHashprefixFilterWithRepl(
 		Logger: slogutil.NewDiscardLogger(),
 		// TODO(a.garipov):  Use [agdtest.NewCloner] when the import cycle is
 		// resolved.
-		Cloner:       dnsmsg.NewCloner(dnsmsg.EmptyClonerStat{}),
+		Cloner: dnsmsg.NewCloner(&dnsmsg.ClonerConfig{
+			Stat: dnsmsg.EmptyClonerStat{},
+		}),
 		CacheManager: agdcache.EmptyManager{},
 		Hashes:       strg,
 		URL:          srvURL,
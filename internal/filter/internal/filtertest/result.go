@@ -70,7 +70,9 @@ func NewRequest(
 	// TODO(a.garipov):  Use [agdtest.NewConstructor] when the import cycle is
 	// resolved.
 	msgs, err := dnsmsg.NewConstructor(&dnsmsg.ConstructorConfig{
-		Cloner:       dnsmsg.NewCloner(dnsmsg.EmptyClonerStat{}),
+		Cloner: dnsmsg.NewCloner(&dnsmsg.ClonerConfig{
+			Stat: dnsmsg.EmptyClonerStat{},
+		}),
 		BlockingMode: &dnsmsg.BlockingModeNullIP{},
 		StructuredErrors: &dnsmsg.StructuredDNSErrorsConfig{
 			Enabled: false,
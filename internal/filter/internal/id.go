@@ -119,6 +119,34 @@ const (
 	// IDAdGuardDNS is the special filter ID of the main AdGuard DNS
 	// filtering-rule list.  For this list, rule statistics are collected.
 	IDAdGuardDNS ID = "adguard_dns_filter"
+
+	// IDAllowlistOnly is the special shared filter ID used when a request was
+	// blocked, because the profile is in the allowlist-only mode and the
+	// domain isn't allowed explicitly.
+	IDAllowlistOnly ID = "allowlist_only"
+
+	// IDBlockUntil is the special shared filter ID used when a request was
+	// blocked, because the profile has an active block-until override and the
+	// domain isn't allowed explicitly.
+	IDBlockUntil ID = "block_until"
+
+	// IDEssentialServices is the special shared filter ID used when a request
+	// was allowed by the essential-services list in the allowlist-only mode.
+	IDEssentialServices ID = "essential_services"
+
+	// IDQueryType is the special shared filter ID used when a request was
+	// refused or answered with NODATA, because the profile has a special
+	// action for its query type.
+	IDQueryType ID = "query_type"
+
+	// IDQuarantine is the special shared filter ID used when a request of a
+	// quarantined device was blocked or, if it's for a remediation domain,
+	// allowed.
+	IDQuarantine ID = "quarantine"
+
+	// IDTemporaryException is the special shared filter ID used when a blocked
+	// request was allowed by a temporary exception created from the block page.
+	IDTemporaryException ID = "temporary_exception"
 )
 
 // RuleText is the text of a single rule within a rule-list filter.
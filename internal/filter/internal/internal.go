@@ -92,6 +92,19 @@ type RequestFilter interface {
 	ID() (id ID)
 }
 
+// ConfigBlockPage is the configuration of the addresses of the block page of an
+// operator, which are returned instead of the global replacement hosts of the
+// hash-prefix filters.
+type ConfigBlockPage struct {
+	// IPv4 is the IPv4 address of the block page.  If it is not valid, the A
+	// queries for the blocked hosts receive an empty response.
+	IPv4 netip.Addr
+
+	// IPv6 is the IPv6 address of the block page.  If it is not valid, the
+	// AAAA queries for the blocked hosts receive an empty response.
+	IPv6 netip.Addr
+}
+
 // ConfigCustom is the configuration for identification or construction of a
 // custom filter for a client.
 type ConfigCustom struct {
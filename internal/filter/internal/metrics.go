@@ -5,8 +5,27 @@ import (
 	"time"
 )
 
+// Refresh types for [Metrics.IncrementRefresh].
+const (
+	// RefreshTypeFull means that the whole data has been downloaded.
+	RefreshTypeFull = "full"
+
+	// RefreshTypeDelta means that the data has been updated using a
+	// differential patch.
+	RefreshTypeDelta = "delta"
+
+	// RefreshTypeNotModified means that the server has reported that the data
+	// hasn't changed since the previous refresh.
+	RefreshTypeNotModified = "not_modified"
+)
+
 // Metrics is the interface for metrics of filters.
 type Metrics interface {
+	// IncrementRefresh increments the number of refreshes of the filter with
+	// the given id from its URL.  typ must be one of the refresh types, for
+	// example [RefreshTypeFull].
+	IncrementRefresh(ctx context.Context, id, typ string)
+
 	// SetFilterStatus sets the status of a filter by its id.  If err is not
 	// nil, updTime and ruleCount are ignored.
 	SetFilterStatus(
@@ -16,6 +35,18 @@ type Metrics interface {
 		ruleCount int,
 		err error,
 	)
+
+	// SetCombinedStatus sets the status of the combined rule-list filters
+	// built during a refresh.  num is the number of the filters, ruleCount is
+	// the total number of rules in them, size is the total size of their rule
+	// texts in bytes, and dur is the total time spent on building them.
+	SetCombinedStatus(
+		ctx context.Context,
+		num int,
+		ruleCount int,
+		size uint64,
+		dur time.Duration,
+	)
 }
 
 // EmptyMetrics is the implementation of the [Metrics] interface that does
@@ -27,3 +58,16 @@ var _ Metrics = EmptyMetrics{}
 
 // SetFilterStatus implements the [Metrics] interface for EmptyMetrics.
 func (EmptyMetrics) SetFilterStatus(_ context.Context, _ string, _ time.Time, _ int, _ error) {}
+
+// IncrementRefresh implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) IncrementRefresh(_ context.Context, _, _ string) {}
+
+// SetCombinedStatus implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) SetCombinedStatus(
+	_ context.Context,
+	_ int,
+	_ int,
+	_ uint64,
+	_ time.Duration,
+) {
+}
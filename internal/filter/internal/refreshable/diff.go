@@ -0,0 +1,247 @@
+package refreshable
+
+import (
+	// #nosec G505 -- SHA-1 is used by the diff format for the checksums, not
+	// for security.
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// diffPathPrefix is the prefix of the header line of a rule list that contains
+// the path to the differential patch that updates the list to its next
+// version.
+const diffPathPrefix = "! Diff-Path:"
+
+// maxHeaderLines is the maximum number of the lines at the beginning of a rule
+// list that are inspected when looking for the header lines.
+const maxHeaderLines = 50
+
+// diffPath returns the path to the next differential patch from the header of
+// text.  If there is no such path, p is empty.
+func diffPath(text string) (p string) {
+	for i := 0; i < maxHeaderLines && text != ""; i++ {
+		var line string
+		line, text, _ = strings.Cut(text, "\n")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		} else if !strings.HasPrefix(line, "!") {
+			// The header is over.
+			return ""
+		}
+
+		if p, ok := strings.CutPrefix(line, diffPathPrefix); ok {
+			return strings.TrimSpace(p)
+		}
+	}
+
+	return ""
+}
+
+// errNoDiff is returned by [applyDiff] when the patch does not contain the
+// diff with the requested name.
+const errNoDiff errors.Error = "no diff with this name"
+
+// applyDiff applies patch to text and returns the result.  patch must be in the
+// RCS format, optionally preceded by a diff header line, and name, if not
+// empty, is the name of the diff within a batch patch.  If the header contains
+// a checksum, it is checked against the SHA-1 sum of res.
+//
+// See https://github.com/ameshkov/diffupdates.
+func applyDiff(text, patch, name string) (res string, err error) {
+	cmds := splitLines(patch)
+
+	var h *diffHeader
+	cmds, h, err = selectDiff(cmds, name)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return "", err
+	}
+
+	lines := splitLines(text)
+	lines, err = applyRCS(lines, cmds)
+	if err != nil {
+		return "", fmt.Errorf("applying rcs diff: %w", err)
+	}
+
+	res = strings.Join(lines, "\n")
+	if res != "" {
+		res += "\n"
+	}
+
+	if h != nil && h.checksum != "" {
+		// #nosec G401 -- See the comment on the import.
+		sum := sha1.Sum([]byte(res))
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, h.checksum) {
+			return "", fmt.Errorf("checksum mismatch: want %q, got %q", h.checksum, got)
+		}
+	}
+
+	return res, nil
+}
+
+// splitLines splits s into lines, ignoring the last newline.
+func splitLines(s string) (lines []string) {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, "\n")
+}
+
+// diffHeader is a parsed header line of a diff.  For example:
+//
+//	diff name:list checksum:0a1b2c lines:3
+type diffHeader struct {
+	name     string
+	checksum string
+	lines    int
+}
+
+// diffHeaderPrefix is the prefix of a diff header line.
+const diffHeaderPrefix = "diff "
+
+// parseDiffHeader parses a diff header line.
+func parseDiffHeader(line string) (h *diffHeader, err error) {
+	h = &diffHeader{
+		lines: -1,
+	}
+
+	for _, f := range strings.Fields(strings.TrimPrefix(line, diffHeaderPrefix)) {
+		k, v, _ := strings.Cut(f, ":")
+		switch k {
+		case "name":
+			h.name = v
+		case "checksum":
+			h.checksum = v
+		case "lines":
+			h.lines, err = strconv.Atoi(v)
+			if err != nil || h.lines < 0 {
+				return nil, fmt.Errorf("bad lines value %q", v)
+			}
+		default:
+			// Ignore unknown fields for forward compatibility.
+		}
+	}
+
+	return h, nil
+}
+
+// selectDiff returns the RCS commands of the diff with the given name from the
+// patch lines.  If name is empty, the whole patch is used.  h is nil if there
+// is no header.
+func selectDiff(patch []string, name string) (cmds []string, h *diffHeader, err error) {
+	for i := 0; i < len(patch); i++ {
+		line := patch[i]
+		if !strings.HasPrefix(line, diffHeaderPrefix) {
+			if name == "" {
+				return patch, nil, nil
+			}
+
+			continue
+		}
+
+		h, err = parseDiffHeader(line)
+		if err != nil {
+			return nil, nil, fmt.Errorf("diff header at line %d: %w", i+1, err)
+		}
+
+		cmds = patch[i+1:]
+		if h.lines >= 0 {
+			if h.lines > len(cmds) {
+				return nil, nil, fmt.Errorf("diff header at line %d: too few lines", i+1)
+			}
+
+			cmds = cmds[:h.lines]
+		}
+
+		if name == "" || h.name == name {
+			return cmds, h, nil
+		}
+
+		i += len(cmds)
+	}
+
+	if name != "" {
+		return nil, nil, errNoDiff
+	}
+
+	return nil, nil, nil
+}
+
+// applyRCS applies the RCS diff commands to src and returns the result.  The
+// line numbers in the commands refer to the lines of src, and the commands
+// must be sorted by them.
+func applyRCS(src, cmds []string) (res []string, err error) {
+	res = make([]string, 0, len(src))
+
+	// pos is the number of lines of src that have already been processed.
+	pos := 0
+	for i := 0; i < len(cmds); i++ {
+		var op byte
+		var n, count int
+		op, n, count, err = parseRCSCommand(cmds[i])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch op {
+		case 'a':
+			if n < pos || n > len(src) {
+				return nil, fmt.Errorf("line %d: line number %d out of range", i+1, n)
+			} else if i+count >= len(cmds) {
+				return nil, fmt.Errorf("line %d: too few lines to add", i+1)
+			}
+
+			res = append(res, src[pos:n]...)
+			res = append(res, cmds[i+1:i+1+count]...)
+			pos = n
+			i += count
+		case 'd':
+			start := n - 1
+			if start < pos || start+count > len(src) {
+				return nil, fmt.Errorf("line %d: line range %d,%d out of range", i+1, n, count)
+			}
+
+			res = append(res, src[pos:start]...)
+			pos = start + count
+		}
+	}
+
+	return append(res, src[pos:]...), nil
+}
+
+// parseRCSCommand parses an RCS diff command, such as "a12 3" or "d4 1".
+func parseRCSCommand(cmd string) (op byte, n, count int, err error) {
+	if cmd == "" {
+		return 0, 0, 0, errors.ErrEmptyValue
+	}
+
+	op = cmd[0]
+	if op != 'a' && op != 'd' {
+		return 0, 0, 0, fmt.Errorf("bad command %q", cmd)
+	}
+
+	nStr, countStr, ok := strings.Cut(cmd[1:], " ")
+	if !ok {
+		return 0, 0, 0, fmt.Errorf("bad command %q", cmd)
+	}
+
+	n, err = strconv.Atoi(nStr)
+	if err != nil || n < 0 || (op == 'd' && n == 0) {
+		return 0, 0, 0, fmt.Errorf("bad line number in command %q", cmd)
+	}
+
+	count, err = strconv.Atoi(countStr)
+	if err != nil || count <= 0 {
+		return 0, 0, 0, fmt.Errorf("bad line count in command %q", cmd)
+	}
+
+	return op, n, count, nil
+}
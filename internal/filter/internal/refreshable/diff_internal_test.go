@@ -0,0 +1,116 @@
+package refreshable
+
+import (
+	"testing"
+
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name string
+		text string
+		want string
+	}{{
+		name: "empty",
+		text: "",
+		want: "",
+	}, {
+		name: "no_header",
+		text: "||example.com^\n",
+		want: "",
+	}, {
+		name: "header",
+		text: "! Title: Test\n! Diff-Path: ../patches/v1.patch\n||example.com^\n",
+		want: "../patches/v1.patch",
+	}, {
+		name: "after_rules",
+		text: "! Title: Test\n||example.com^\n! Diff-Path: ../patches/v1.patch\n",
+		want: "",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, diffPath(tc.text))
+		})
+	}
+}
+
+func TestApplyDiff(t *testing.T) {
+	t.Parallel()
+
+	const text = "! Diff-Path: v1.patch\n||a.example^\n||b.example^\n||c.example^\n"
+
+	testCases := []struct {
+		name       string
+		patch      string
+		diffName   string
+		want       string
+		wantErrMsg string
+	}{{
+		name:       "add_and_delete",
+		patch:      "d1 1\na1 1\n! Diff-Path: v2.patch\nd3 1\na4 1\n||d.example^\n",
+		diffName:   "",
+		want:       "! Diff-Path: v2.patch\n||a.example^\n||c.example^\n||d.example^\n",
+		wantErrMsg: "",
+	}, {
+		name:       "header_checksum",
+		patch:      "diff checksum:d1edc90457c2a0bb94ab439abd0dbfde5cce902f lines:1\nd2 1\n",
+		diffName:   "",
+		want:       "! Diff-Path: v1.patch\n||b.example^\n||c.example^\n",
+		wantErrMsg: "",
+	}, {
+		name:     "bad_checksum",
+		patch:    "diff checksum:0123 lines:1\nd2 1\n",
+		diffName: "",
+		want:     "",
+		wantErrMsg: `checksum mismatch: want "0123", ` +
+			`got "d1edc90457c2a0bb94ab439abd0dbfde5cce902f"`,
+	}, {
+		name: "batch",
+		patch: "diff name:other lines:1\nd2 1\n" +
+			"diff name:list lines:1\nd3 1\n",
+		diffName:   "list",
+		want:       "! Diff-Path: v1.patch\n||a.example^\n||c.example^\n",
+		wantErrMsg: "",
+	}, {
+		name:       "batch_no_name",
+		patch:      "diff name:other lines:1\nd2 1\n",
+		diffName:   "list",
+		want:       "",
+		wantErrMsg: errNoDiff.Error(),
+	}, {
+		name:       "bad_command",
+		patch:      "x1 1\n",
+		diffName:   "",
+		want:       "",
+		wantErrMsg: `applying rcs diff: line 1: bad command "x1 1"`,
+	}, {
+		name:       "out_of_range",
+		patch:      "d4 2\n",
+		diffName:   "",
+		want:       "",
+		wantErrMsg: "applying rcs diff: line 1: line range 4,2 out of range",
+	}, {
+		name:       "too_few_lines",
+		patch:      "a1 2\n||d.example^\n",
+		diffName:   "",
+		want:       "",
+		wantErrMsg: "applying rcs diff: line 1: too few lines to add",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := applyDiff(text, tc.patch, tc.diffName)
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/httphdr"
 	"github.com/AdguardTeam/golibs/ioutil"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/netutil/urlutil"
 	"github.com/c2h5oh/datasize"
 	renameio "github.com/google/renameio/v2"
@@ -29,6 +30,7 @@ import (
 type Refreshable struct {
 	logger    *slog.Logger
 	http      *agdhttp.Client
+	metrics   filter.Metrics
 	url       *url.URL
 	id        filter.ID
 	cachePath string
@@ -41,6 +43,10 @@ type Config struct {
 	// Logger is used to log errors during refreshes.
 	Logger *slog.Logger
 
+	// Metrics is used for the collection of the refresh statistics.  It must
+	// not be nil.
+	Metrics filter.Metrics
+
 	// URL is the URL used to refresh the data.  URL should be either a file URL
 	// or an HTTP(S) URL and should not be nil.
 	URL *url.URL
@@ -48,7 +54,9 @@ type Config struct {
 	// ID is the filter list ID for this filter.
 	ID filter.ID
 
-	// CachePath is the path to the file containing the cached data.
+	// CachePath is the path to the file containing the cached data.  The
+	// entity tag of the cached data, if any, is stored in the file with the
+	// same path and the [etagExt] extension.
 	CachePath string
 
 	// Staleness is the time after which a file is considered stale.
@@ -75,6 +83,7 @@ func New(c *Config) (f *Refreshable, err error) {
 		http: agdhttp.NewClient(&agdhttp.ClientConfig{
 			Timeout: c.Timeout,
 		}),
+		metrics:   c.Metrics,
 		url:       c.URL,
 		id:        c.ID,
 		cachePath: c.CachePath,
@@ -186,21 +195,186 @@ func (f *Refreshable) refreshFromFile(
 	return b.String(), nil
 }
 
-// refreshFromURL loads the data from u, puts it into the file specified by
-// cachePath, returns its content, and also sets its atime and mtime to updTime.
+// etagExt is the extension of the file containing the entity tag of the cached
+// data.
+const etagExt = ".etag"
+
+// refreshFromURL loads the data from the URL of f, puts it into the file
+// specified by cachePath, returns its content, and also sets its atime and
+// mtime to updTime.  If there is cached data, refreshFromURL first tries to
+// update it using a differential patch and then using a conditional request.
 func (f *Refreshable) refreshFromURL(
 	ctx context.Context,
 	updTime time.Time,
 ) (text string, err error) {
-	// TODO(a.garipov): Cache these like renameio recommends.
-	tmpDir := renameio.TempDir(filepath.Dir(f.cachePath))
-	tmpFile, err := renameio.TempFile(tmpDir, f.cachePath)
+	prev, prevMtime, err := f.readStaleCache()
 	if err != nil {
-		return "", fmt.Errorf("creating temporary refreshable file: %w", err)
+		return "", fmt.Errorf("reading stale cache: %w", err)
+	}
+
+	if prev != "" {
+		text = f.refreshFromDiff(ctx, prev, updTime)
+		if text != "" {
+			f.metrics.IncrementRefresh(ctx, string(f.id), filter.RefreshTypeDelta)
+
+			return text, nil
+		}
+	}
+
+	text, err = f.refreshFull(ctx, prev != "", prevMtime, updTime)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return "", err
+	} else if text != "" {
+		f.metrics.IncrementRefresh(ctx, string(f.id), filter.RefreshTypeFull)
+
+		return text, nil
+	}
+
+	f.metrics.IncrementRefresh(ctx, string(f.id), filter.RefreshTypeNotModified)
+
+	// Set the modification and access times to the moment the refresh started
+	// so that the cached data is considered fresh again.
+	err = os.Chtimes(f.cachePath, updTime, updTime)
+	if err != nil {
+		return "", fmt.Errorf("updating cache file times: %w", err)
+	}
+
+	return prev, nil
+}
+
+// readStaleCache returns the cached data, regardless of its staleness, as well
+// as the modification time of the cache file.  text is empty if there is no
+// cached data.
+func (f *Refreshable) readStaleCache() (text string, mtime time.Time, err error) {
+	fi, err := os.Stat(f.cachePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", time.Time{}, nil
+	} else if err != nil {
+		return "", time.Time{}, err
+	}
+
+	text, err = f.refreshFromFile(true, f.cachePath, time.Time{})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return text, fi.ModTime(), nil
+}
+
+// refreshFromDiff tries to update prev using the differential patch from the
+// header of prev.  text is empty if the data could not be updated this way, for
+// example, if there is no patch yet.  The errors are logged and not returned,
+// since the caller should fall back to a full refresh.
+func (f *Refreshable) refreshFromDiff(
+	ctx context.Context,
+	prev string,
+	updTime time.Time,
+) (text string) {
+	p := diffPath(prev)
+	if p == "" {
+		return ""
+	}
+
+	text, err := f.downloadAndApplyDiff(ctx, prev, p)
+	if err != nil {
+		f.logger.WarnContext(ctx, "applying diff", "path", p, slogutil.KeyError, err)
+
+		return ""
+	} else if text == "" {
+		f.logger.DebugContext(ctx, "no diff", "path", p)
+
+		return ""
+	}
+
+	err = renameio.WriteFile(f.cachePath, []byte(text), 0o600)
+	if err == nil {
+		// The entity tag of the full data is unknown now.
+		err = removeIfExists(f.cachePath + etagExt)
+	}
+
+	if err == nil {
+		err = os.Chtimes(f.cachePath, updTime, updTime)
 	}
-	defer func() { err = f.withDeferredTmpCleanup(err, tmpFile, updTime) }()
 
-	resp, err := f.http.Get(ctx, f.url)
+	if err != nil {
+		f.logger.WarnContext(ctx, "writing patched data", slogutil.KeyError, err)
+
+		return ""
+	}
+
+	f.logger.InfoContext(ctx, "updated data using diff", "path", p)
+
+	return text
+}
+
+// downloadAndApplyDiff downloads the differential patch from p, which may be
+// relative to the URL of f, and applies it to prev.  text is empty if there is
+// no patch yet.
+func (f *Refreshable) downloadAndApplyDiff(
+	ctx context.Context,
+	prev string,
+	p string,
+) (text string, err error) {
+	u, err := f.url.Parse(p)
+	if err != nil {
+		return "", fmt.Errorf("parsing diff path: %w", err)
+	} else if u.Scheme != f.url.Scheme || u.Host != f.url.Host {
+		return "", fmt.Errorf("diff url %q: must have the same origin", urlutil.RedactUserinfo(u))
+	}
+
+	name := u.Fragment
+	u.Fragment = ""
+
+	resp, err := f.http.Get(ctx, u)
+	if err != nil {
+		return "", fmt.Errorf("requesting: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, resp.Body.Close()) }()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// Go on.
+	case http.StatusNotFound, http.StatusNoContent:
+		// The patch is not published yet.
+		return "", nil
+	default:
+		// Don't wrap the error, because it's informative enough as is.
+		return "", agdhttp.CheckStatus(resp, http.StatusOK)
+	}
+
+	b := &strings.Builder{}
+	_, err = io.Copy(b, ioutil.LimitReader(resp.Body, f.maxSize.Bytes()))
+	if err != nil {
+		return "", agdhttp.WrapServerError(fmt.Errorf("reading diff: %w", err), resp)
+	} else if b.Len() == 0 {
+		return "", nil
+	}
+
+	text, err = applyDiff(prev, b.String(), name)
+	if errors.Is(err, errNoDiff) {
+		return "", nil
+	}
+
+	// Don't wrap the error, because it's informative enough as is.
+	return text, err
+}
+
+// refreshFull downloads the whole data.  If conditional is true, the request
+// is made conditional using the entity tag of the cached data and prevMtime,
+// and text is empty if the data hasn't been modified.
+func (f *Refreshable) refreshFull(
+	ctx context.Context,
+	conditional bool,
+	prevMtime time.Time,
+	updTime time.Time,
+) (text string, err error) {
+	var h http.Header
+	if conditional {
+		h = f.conditionalHeader(ctx, prevMtime)
+	}
+
+	resp, err := f.http.GetWithHeader(ctx, f.url, h)
 	if err != nil {
 		return "", fmt.Errorf("requesting: %w", err)
 	}
@@ -215,12 +389,88 @@ func (f *Refreshable) refreshFromURL(
 		"url", urlutil.RedactUserinfo(f.url),
 	)
 
+	if conditional && resp.StatusCode == http.StatusNotModified {
+		return "", nil
+	}
+
 	err = agdhttp.CheckStatus(resp, http.StatusOK)
 	if err != nil {
 		// Don't wrap the error, because it's informative enough as is.
 		return "", err
 	}
 
+	text, err = f.writeCache(resp, updTime)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return "", err
+	}
+
+	f.saveETag(ctx, resp.Header.Get(httphdr.ETag))
+
+	return text, nil
+}
+
+// conditionalHeader returns the headers for a conditional request for the data
+// cached at prevMtime.
+func (f *Refreshable) conditionalHeader(ctx context.Context, prevMtime time.Time) (h http.Header) {
+	h = http.Header{}
+	h.Set(httphdr.IfModifiedSince, prevMtime.UTC().Format(http.TimeFormat))
+
+	// #nosec G304 -- Assume that cachePath is always cacheDir + a valid,
+	// no-slash ID or a path from the index env.
+	etag, err := os.ReadFile(f.cachePath + etagExt)
+	if err == nil {
+		h.Set(httphdr.IfNoneMatch, string(etag))
+	} else if !errors.Is(err, os.ErrNotExist) {
+		f.logger.WarnContext(ctx, "reading etag", slogutil.KeyError, err)
+	}
+
+	return h
+}
+
+// saveETag saves the entity tag of the newly cached data.  If etag is empty,
+// the previous one is removed.  The errors are only logged, since the entity
+// tag is only an optimization.
+func (f *Refreshable) saveETag(ctx context.Context, etag string) {
+	etagPath := f.cachePath + etagExt
+
+	var err error
+	if etag == "" {
+		err = removeIfExists(etagPath)
+	} else {
+		err = renameio.WriteFile(etagPath, []byte(etag), 0o600)
+	}
+
+	if err != nil {
+		f.logger.WarnContext(ctx, "saving etag", slogutil.KeyError, err)
+
+		// Make sure that a stale entity tag doesn't prevent the next update.
+		_ = os.Remove(etagPath)
+	}
+}
+
+// removeIfExists removes the file at path, ignoring the error if it doesn't
+// exist.
+func removeIfExists(path string) (err error) {
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// writeCache reads the data from resp into the file specified by cachePath,
+// returns its content, and also sets its atime and mtime to updTime.
+func (f *Refreshable) writeCache(resp *http.Response, updTime time.Time) (text string, err error) {
+	// TODO(a.garipov): Cache these like renameio recommends.
+	tmpDir := renameio.TempDir(filepath.Dir(f.cachePath))
+	tmpFile, err := renameio.TempFile(tmpDir, f.cachePath)
+	if err != nil {
+		return "", fmt.Errorf("creating temporary refreshable file: %w", err)
+	}
+	defer func() { err = f.withDeferredTmpCleanup(err, tmpFile, updTime) }()
+
 	b := &strings.Builder{}
 	mw := io.MultiWriter(b, tmpFile)
 	_, err = io.Copy(mw, ioutil.LimitReader(resp.Body, f.maxSize.Bytes()))
@@ -1,7 +1,10 @@
 package refreshable_test
 
 import (
+	"context"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -9,8 +12,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/filtertest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/refreshable"
+	"github.com/AdguardTeam/golibs/httphdr"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/netutil/urlutil"
 	"github.com/AdguardTeam/golibs/testutil"
@@ -111,6 +116,7 @@ func TestRefreshable_Refresh(t *testing.T) {
 
 			c := &refreshable.Config{
 				Logger:    slogutil.NewDiscardLogger(),
+				Metrics:   filter.EmptyMetrics{},
 				URL:       srvURL,
 				ID:        refrID,
 				CachePath: cachePath,
@@ -169,6 +175,7 @@ func TestRefreshable_Refresh_properStaleness(t *testing.T) {
 
 	c := &refreshable.Config{
 		Logger:    slogutil.NewDiscardLogger(),
+		Metrics:   filter.EmptyMetrics{},
 		URL:       addr,
 		ID:        refrID,
 		CachePath: cachePath,
@@ -224,7 +231,8 @@ func TestRefreshable_Refresh_fileURL(t *testing.T) {
 	require.NoError(t, fltFile.Close())
 
 	c := &refreshable.Config{
-		Logger: slogutil.NewDiscardLogger(),
+		Logger:  slogutil.NewDiscardLogger(),
+		Metrics: filter.EmptyMetrics{},
 		URL: &url.URL{
 			Scheme: urlutil.SchemeFile,
 			Path:   fltFile.Name(),
@@ -245,3 +253,114 @@ func TestRefreshable_Refresh_fileURL(t *testing.T) {
 
 	assert.Equal(t, testFileText, text)
 }
+
+// testMetrics is a [filter.Metrics] implementation that sends the types of the
+// refreshes into a channel.
+type testMetrics struct {
+	filter.EmptyMetrics
+
+	refreshTypes chan string
+}
+
+// IncrementRefresh implements the [filter.Metrics] interface for *testMetrics.
+func (m *testMetrics) IncrementRefresh(_ context.Context, _, typ string) {
+	m.refreshTypes <- typ
+}
+
+func TestRefreshable_Refresh_conditional(t *testing.T) {
+	const (
+		prevText  = "! Diff-Path: patches/v1.patch\n||a.example^\n"
+		patch     = "d2 1\na2 1\n||b.example^\n"
+		wantDelta = "! Diff-Path: patches/v1.patch\n||b.example^\n"
+	)
+
+	testCases := []struct {
+		name     string
+		patch    string
+		wantText string
+		wantType string
+	}{{
+		name:     "delta",
+		patch:    patch,
+		wantText: wantDelta,
+		wantType: filter.RefreshTypeDelta,
+	}, {
+		name:     "not_modified",
+		patch:    "",
+		wantText: prevText,
+		wantType: filter.RefreshTypeNotModified,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/patches/v1.patch", func(w http.ResponseWriter, _ *http.Request) {
+				if tc.patch == "" {
+					w.WriteHeader(http.StatusNotFound)
+
+					return
+				}
+
+				_, _ = io.WriteString(w, tc.patch)
+			})
+			mux.HandleFunc("/list.txt", func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get(httphdr.IfModifiedSince) != "" {
+					w.WriteHeader(http.StatusNotModified)
+
+					return
+				}
+
+				_, _ = io.WriteString(w, testURLText)
+			})
+
+			srv := httptest.NewServer(mux)
+			t.Cleanup(srv.Close)
+
+			srvURL, err := url.Parse(srv.URL + "/list.txt")
+			require.NoError(t, err)
+
+			cachePath := filepath.Join(t.TempDir(), refrID)
+			err = os.WriteFile(cachePath, []byte(prevText), 0o600)
+			require.NoError(t, err)
+
+			mtime := time.Now().Add(-time.Hour)
+			err = os.Chtimes(cachePath, mtime, mtime)
+			require.NoError(t, err)
+
+			m := &testMetrics{
+				refreshTypes: make(chan string, 1),
+			}
+
+			f, err := refreshable.New(&refreshable.Config{
+				Logger:    slogutil.NewDiscardLogger(),
+				Metrics:   m,
+				URL:       srvURL,
+				ID:        refrID,
+				CachePath: cachePath,
+				Staleness: filtertest.Staleness,
+				Timeout:   filtertest.Timeout,
+				MaxSize:   filtertest.FilterMaxSize,
+			})
+			require.NoError(t, err)
+
+			ctx := testutil.ContextWithTimeout(t, filtertest.Timeout)
+			text, err := f.Refresh(ctx, false)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantText, text)
+
+			gotType, _ := testutil.RequireReceive(t, m.refreshTypes, filtertest.Timeout)
+			assert.Equal(t, tc.wantType, gotType)
+
+			cached, err := os.ReadFile(cachePath)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.wantText, string(cached))
+
+			fi, err := os.Stat(cachePath)
+			require.NoError(t, err)
+
+			assert.True(t, fi.ModTime().After(mtime))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package rulelist
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/urlfilter"
+	"github.com/AdguardTeam/urlfilter/filterlist"
+	"github.com/AdguardTeam/urlfilter/rules"
+)
+
+// Combined is an immutable DNS request and response filter that uses a single
+// urlfilter engine compiled from the rules of several rule-list filters.  The
+// rules keep the synthetic urlfilter IDs of their rule lists, so the results
+// can be mapped using the same [IDMapper] as the results of the original
+// filters.
+type Combined struct {
+	// flt is the underlying filter with the combined engine.  Its IDs are not
+	// used.
+	flt *filter
+
+	// lists are the rule-list filters, from which the engine has been built,
+	// in the original order.
+	lists []*Refreshable
+
+	// size is the total size of the rule texts of lists in bytes.
+	size uint64
+}
+
+// NewCombined returns a new combined filter built from the current rules of
+// lists.  lists must not be empty and must not be modified after calling
+// NewCombined.  Refreshing the items of lists does not affect the combined
+// filter.
+func NewCombined(lists []*Refreshable, cache ResultCache) (c *Combined, err error) {
+	c = &Combined{
+		flt: &filter{
+			cache: cache,
+		},
+		lists: lists,
+	}
+
+	ruleLists := make([]filterlist.RuleList, 0, len(lists))
+	for _, rl := range lists {
+		text := rl.ruleText()
+		c.size += uint64(len(text))
+
+		// TODO(a.garipov): Add filterlist.BytesRuleList.
+		ruleLists = append(ruleLists, &filterlist.StringRuleList{
+			ID:             rl.urlFilterID,
+			RulesText:      text,
+			IgnoreCosmetic: true,
+		})
+	}
+
+	s, err := filterlist.NewRuleStorage(ruleLists)
+	if err != nil {
+		return nil, fmt.Errorf("creating rule storage: %w", err)
+	}
+
+	c.flt.engine = urlfilter.NewDNSEngine(s)
+
+	return c, nil
+}
+
+// DNSResult returns the result of applying the combined urlfilter DNS filtering
+// engine.  If the request is not filtered, DNSResult returns nil.
+func (c *Combined) DNSResult(
+	clientIP netip.Addr,
+	clientName string,
+	host string,
+	rrType dnsmsg.RRType,
+	isAns bool,
+) (res *urlfilter.DNSResult) {
+	return c.flt.DNSResult(clientIP, clientName, host, rrType, isAns)
+}
+
+// Matches returns true if c has been built from exactly lists in the same
+// order.
+func (c *Combined) Matches(lists []*Refreshable) (ok bool) {
+	return slices.Equal(c.lists, lists)
+}
+
+// RulesCount returns the number of rules in the combined engine.
+func (c *Combined) RulesCount() (n int) {
+	return c.flt.RulesCount()
+}
+
+// Size returns the total size of the rule texts of the combined engine in
+// bytes.
+func (c *Combined) Size() (n uint64) {
+	return c.size
+}
+
+// DNSRewritesForList returns the $dnsrewrite rules from dr that would have been
+// returned by [urlfilter.DNSResult.DNSRewrites] for the engine of the rule list
+// with the synthetic urlfilter ID ufID alone.  It is used to keep the per-list
+// semantics of $dnsrewrite rules when using a [Combined] filter.
+func DNSRewritesForList(dr *urlfilter.DNSResult, ufID int) (nrules []*rules.NetworkRule) {
+	if dr == nil {
+		return nil
+	}
+
+	listRes := &urlfilter.DNSResult{}
+	for _, nr := range dr.NetworkRules {
+		if nr.DNSRewrite != nil && nr.FilterListID == ufID {
+			listRes.NetworkRules = append(listRes.NetworkRules, nr)
+		}
+	}
+
+	return listRes.DNSRewrites()
+}
+
+// HasDNSRewrites returns true if dr contains any $dnsrewrite rules, including
+// the exceptions.
+func HasDNSRewrites(dr *urlfilter.DNSResult) (ok bool) {
+	return dr != nil && slices.ContainsFunc(dr.NetworkRules, func(nr *rules.NetworkRule) (ok bool) {
+		return nr.DNSRewrite != nil
+	})
+}
@@ -0,0 +1,48 @@
+package rulelist_test
+
+import (
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/rulelist"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCombined(t *testing.T) {
+	const (
+		rewriteRule = "||" + testReqHost + "^$dnsrewrite=REFUSED\n"
+		otherID     = internal.ID("fl2")
+	)
+
+	cache := rulelist.ResultCacheEmpty{}
+	rl1, err := rulelist.NewFromString(testBlockRule, testFltListID, "", cache)
+	require.NoError(t, err)
+
+	rl2, err := rulelist.NewFromString(rewriteRule, otherID, "", cache)
+	require.NoError(t, err)
+
+	lists := []*rulelist.Refreshable{rl1, rl2}
+	c, err := rulelist.NewCombined(lists, cache)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, c.RulesCount())
+	assert.Equal(t, uint64(len(testBlockRule)+len(rewriteRule)), c.Size())
+
+	assert.True(t, c.Matches(lists))
+	assert.False(t, c.Matches([]*rulelist.Refreshable{rl2, rl1}))
+	assert.False(t, c.Matches([]*rulelist.Refreshable{rl1}))
+
+	dr := c.DNSResult(testRemoteIP, "", testReqHost, dns.TypeA, false)
+	require.NotNil(t, dr)
+	require.Len(t, dr.NetworkRules, 2)
+
+	assert.True(t, rulelist.HasDNSRewrites(dr))
+	assert.Empty(t, rulelist.DNSRewritesForList(dr, rl1.URLFilterID()))
+	assert.Len(t, rulelist.DNSRewritesForList(dr, rl2.URLFilterID()), 1)
+
+	dr = c.DNSResult(testRemoteIP, "", "other.example", dns.TypeA, false)
+	assert.Nil(t, dr)
+	assert.False(t, rulelist.HasDNSRewrites(dr))
+}
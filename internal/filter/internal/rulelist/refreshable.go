@@ -17,17 +17,14 @@ import (
 )
 
 // Refreshable is a refreshable DNS request and response filter based on filter
-// rule lists.
-//
-// TODO(a.garipov): Consider adding a separate version that uses a single engine
-// for multiple rule lists and using it to optimize the filtering using default
-// filtering groups.
+// rule lists.  See [Combined] for a filter that uses a single engine for
+// multiple rule lists.
 type Refreshable struct {
 	*filter
 
 	logger *slog.Logger
 
-	// mu protects [filter.engine].
+	// mu protects [filter.engine] and text.
 	//
 	// Do not add it to [filter], because the latter is used in [Immutable],
 	// where serialization of access is not required.
@@ -35,6 +32,11 @@ type Refreshable struct {
 
 	// refr contains data for refreshing the filter.
 	refr *refreshable.Refreshable
+
+	// text is the rule text of the current engine.  It is used to build
+	// [Combined] filters.  Since the engine keeps the text in its rule list
+	// anyway, this doesn't require any additional memory.
+	text string
 }
 
 // NewRefreshable returns a new refreshable DNS request and response filter
@@ -52,6 +54,7 @@ func NewRefreshable(c *refreshable.Config, cache ResultCache) (f *Refreshable, e
 
 	f.refr, err = refreshable.New(&refreshable.Config{
 		Logger:    c.Logger,
+		Metrics:   c.Metrics,
 		URL:       c.URL,
 		ID:        c.ID,
 		CachePath: c.CachePath,
@@ -91,6 +94,7 @@ func NewFromString(
 	return &Refreshable{
 		mu:     &sync.RWMutex{},
 		filter: filter,
+		text:   text,
 	}, nil
 }
 
@@ -137,6 +141,7 @@ func (f *Refreshable) Refresh(ctx context.Context, acceptStale bool) (err error)
 	f.cache.Clear()
 
 	f.engine = urlfilter.NewDNSEngine(s)
+	f.text = text
 
 	f.logger.InfoContext(ctx, "reset rules", "num", f.engine.RulesCount)
 
@@ -150,3 +155,11 @@ func (f *Refreshable) RulesCount() (n int) {
 
 	return f.filter.RulesCount()
 }
+
+// ruleText returns the rule text of the current engine.
+func (f *Refreshable) ruleText() (text string) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.text
+}
@@ -90,6 +90,7 @@ func TestRefreshable_Refresh(t *testing.T) {
 	rl, err := rulelist.NewRefreshable(
 		&refreshable.Config{
 			Logger:    slogutil.NewDiscardLogger(),
+			Metrics:   internal.EmptyMetrics{},
 			URL:       srvURL,
 			ID:        testFltListID,
 			CachePath: cachePath,
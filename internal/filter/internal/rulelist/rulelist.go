@@ -73,6 +73,14 @@ type CacheItem struct {
 	host string
 }
 
+// type check
+var _ agdcache.HostItem = (*CacheItem)(nil)
+
+// CachedHost implements the [agdcache.HostItem] interface for *CacheItem.
+func (item *CacheItem) CachedHost() (host string) {
+	return item.host
+}
+
 // itemFromCache retrieves a cache item for the given key.  host is used to
 // detect key collisions.  If there is a key collision, it returns nil and
 // false.
@@ -50,6 +50,7 @@ func TestFilter(t *testing.T) {
 		&safesearch.Config{
 			Refreshable: &refreshable.Config{
 				Logger:    slogutil.NewDiscardLogger(),
+				Metrics:   internal.EmptyMetrics{},
 				ID:        internal.IDGeneralSafeSearch,
 				URL:       srvURL,
 				CachePath: cachePath,
@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"path"
+	"slices"
 	"strings"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
@@ -12,6 +14,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/rulelist"
 	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/netutil"
 )
 
 // indexResp is the struct for the JSON response from a blocked service index
@@ -60,16 +63,111 @@ func (r *indexResp) toInternal(
 	return services, nil
 }
 
+// toCategories converts the services from the index to the rule lists of their
+// groups, which are used as the content categories.  The services without a
+// group are skipped.  cats are sorted by category.
+func (r *indexResp) toCategories(
+	cacheManager agdcache.Manager,
+	cacheCount int,
+	useCache bool,
+) (cats []*categoryRuleList, err error) {
+	groupRules := map[internal.Category][]string{}
+	for _, svc := range r.BlockedServices {
+		if svc.Group == "" {
+			continue
+		}
+
+		cat := internal.Category(svc.Group)
+		groupRules[cat] = append(groupRules[cat], svc.Rules...)
+	}
+
+	for _, cat := range slices.Sorted(maps.Keys(groupRules)) {
+		fltIDStr := path.Join(
+			cachePrefix,
+			string(internal.IDBlockedService),
+			categoriesCacheSubdir,
+			string(cat),
+		)
+		cache := rulelist.NewManagedResultCache(cacheManager, fltIDStr, cacheCount, useCache)
+
+		var rl *rulelist.Immutable
+		rl, err = rulelist.NewImmutable(
+			strings.Join(groupRules[cat], "\n"),
+			internal.IDBlockedService,
+			"",
+			cache,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("compiling category %q: %w", cat, err)
+		}
+
+		cats = append(cats, &categoryRuleList{
+			ruleList: rl,
+			category: cat,
+		})
+	}
+
+	return cats, nil
+}
+
+// categoryHosts returns the domain names blocked by the services from the index
+// mapped to the groups of these services, which are used as the content
+// categories.  Only the basic rules of the form "||host^" are used, and the
+// services without a group are skipped.
+func (r *indexResp) categoryHosts() (hosts map[string][]internal.Category) {
+	hosts = map[string][]internal.Category{}
+	for _, svc := range r.BlockedServices {
+		if svc.Group == "" {
+			continue
+		}
+
+		cat := internal.Category(svc.Group)
+		for _, rule := range svc.Rules {
+			host, ok := ruleHost(rule)
+			if ok && !slices.Contains(hosts[host], cat) {
+				hosts[host] = append(hosts[host], cat)
+			}
+		}
+	}
+
+	return hosts
+}
+
+// ruleHost returns the lowercased domain name from rule, if rule is a basic
+// rule of the form "||host^".
+func ruleHost(rule string) (host string, ok bool) {
+	host, ok = strings.CutPrefix(rule, "||")
+	if !ok {
+		return "", false
+	}
+
+	host, ok = strings.CutSuffix(host, "^")
+	if !ok || netutil.ValidateDomainName(host) != nil {
+		return "", false
+	}
+
+	return strings.ToLower(host), true
+}
+
 // indexRespService is the struct for a filter from the JSON response from a
 // blocked service index API.
 type indexRespService struct {
-	ID    string   `json:"id"`
+	ID string `json:"id"`
+
+	// Group is the group of the service, which is used as its content
+	// category.  It may be empty.
+	Group string `json:"group"`
+
 	Rules []string `json:"rules"`
 }
 
 // cachePrefix is used as a cache category for filter's caches.
 const cachePrefix = "filters"
 
+// categoriesCacheSubdir is the part of the cache IDs of the category rule
+// lists that separates them from the caches of the services.
+const categoriesCacheSubdir = "categories"
+
 // toInternal converts the service from the index to a rule-list filter.  It
 // also adds the cache with ID "[internal.IDBlockedService]/[svc.ID]" to
 // the cache manager.
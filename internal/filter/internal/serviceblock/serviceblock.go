@@ -8,29 +8,37 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/netip"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/hashprefix"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/refreshable"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/rulelist"
+	"github.com/AdguardTeam/urlfilter/rules"
+	"github.com/miekg/dns"
 )
 
 // Filter is a service-blocking filter that uses rule lists that it gets from an
 // index.
 type Filter struct {
-	logger *slog.Logger
-	refr   *refreshable.Refreshable
-
-	// mu protects services.
-	mu       *sync.RWMutex
-	services serviceRuleLists
-
 	errColl errcoll.Interface
 	metrics internal.Metrics
+	logger  *slog.Logger
+	refr    *refreshable.Refreshable
+
+	// catHashes, if not nil, is reset with the hashes of the domain names of
+	// the categories on each refresh.
+	catHashes *hashprefix.CategoryStorage
+
+	// mu protects services and categories.
+	mu         *sync.RWMutex
+	services   serviceRuleLists
+	categories []*categoryRuleList
 }
 
 // serviceRuleLists is convenient alias for an ID to filter mapping.
@@ -49,6 +57,10 @@ type Config struct {
 	// Metrics are the metrics for the service-blocking filter.  It must not be
 	// nil.
 	Metrics internal.Metrics
+
+	// CategoryHashes, if not nil, is reset with the hashes of the domain names
+	// of the content categories on each refresh.
+	CategoryHashes *hashprefix.CategoryStorage
 }
 
 // New returns a fully initialized service blocker.  c must not be nil and must
@@ -60,12 +72,13 @@ func New(c *Config) (f *Filter, err error) {
 	}
 
 	return &Filter{
-		logger:   c.Refreshable.Logger,
-		refr:     refr,
-		mu:       &sync.RWMutex{},
-		services: serviceRuleLists{},
-		errColl:  c.ErrColl,
-		metrics:  c.Metrics,
+		logger:    c.Refreshable.Logger,
+		refr:      refr,
+		catHashes: c.CategoryHashes,
+		mu:        &sync.RWMutex{},
+		services:  serviceRuleLists{},
+		errColl:   c.ErrColl,
+		metrics:   c.Metrics,
 	}, nil
 }
 
@@ -120,18 +133,69 @@ func (f *Filter) Refresh(
 		return err
 	}
 
+	cats, err := resp.toCategories(cacheManager, cacheCount, useCache)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
 	for _, s := range services {
 		count += s.RulesCount()
 	}
 
+	if f.catHashes != nil {
+		n := f.catHashes.Reset(resp.categoryHosts())
+		f.logger.DebugContext(ctx, "reset category hashes", "num", n)
+	}
+
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
 	f.services = services
+	f.categories = cats
 
 	return nil
 }
 
+// type check
+var _ internal.Classifier = (*Filter)(nil)
+
+// Classify implements the [internal.Classifier] interface for *Filter.  The
+// categories are the groups of the services that the host belongs to.
+func (f *Filter) Classify(_ context.Context, host string) (cats []internal.Category) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, c := range f.categories {
+		if c.matches(host) {
+			cats = append(cats, c.category)
+		}
+	}
+
+	return cats
+}
+
+// categoryRuleList is the rule list containing the rules of all services of a
+// single category.
+type categoryRuleList struct {
+	ruleList *rulelist.Immutable
+	category internal.Category
+}
+
+// matches returns true if host is blocked by the rules of the category.
+func (c *categoryRuleList) matches(host string) (ok bool) {
+	dr := c.ruleList.DNSResult(netip.Addr{}, "", host, dns.TypeA, false)
+	if dr == nil {
+		return false
+	}
+
+	if nr := rules.GetDNSBasicRule(dr.NetworkRules); nr != nil {
+		return !nr.Whitelist
+	}
+
+	return len(dr.HostRulesV4) > 0 || len(dr.HostRulesV6) > 0
+}
+
 // loadIndex fetches, decodes, and returns the blocked service index data.
 func (f *Filter) loadIndex(ctx context.Context, acceptStale bool) (resp *indexResp, err error) {
 	text, err := f.refr.Refresh(ctx, acceptStale)
@@ -2,12 +2,15 @@ package serviceblock_test
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"net/http"
 	"testing"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter/hashprefix"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/filtertest"
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter/internal/refreshable"
@@ -27,9 +30,11 @@ func TestFilter(t *testing.T) {
 		http.StatusOK,
 	)
 
+	catHashes := hashprefix.NewCategoryStorage()
 	f, err := serviceblock.New(&serviceblock.Config{
 		Refreshable: &refreshable.Config{
 			Logger:    slogutil.NewDiscardLogger(),
+			Metrics:   internal.EmptyMetrics{},
 			URL:       srvURL,
 			ID:        internal.IDBlockedService,
 			CachePath: cachePath,
@@ -37,8 +42,9 @@ func TestFilter(t *testing.T) {
 			Timeout:   filtertest.Timeout,
 			MaxSize:   filtertest.FilterMaxSize,
 		},
-		ErrColl: agdtest.NewErrorCollector(),
-		Metrics: filter.EmptyMetrics{},
+		ErrColl:        agdtest.NewErrorCollector(),
+		Metrics:        filter.EmptyMetrics{},
+		CategoryHashes: catHashes,
 	})
 
 	require.NoError(t, err)
@@ -68,4 +74,38 @@ func TestFilter(t *testing.T) {
 	assert.Equal(t, internal.IDBlockedService, gotFltIDs[0])
 	assert.Equal(t, internal.IDBlockedService, gotFltIDs[1])
 	assert.ElementsMatch(t, wantSvcIDs, gotSvcIDs)
+
+	testCases := []struct {
+		name     string
+		host     string
+		wantCats []internal.Category
+	}{{
+		name:     "category",
+		host:     filtertest.HostBlockedService1,
+		wantCats: []internal.Category{filtertest.Category},
+	}, {
+		name:     "subdomain",
+		host:     "sub." + filtertest.HostBlockedService1,
+		wantCats: []internal.Category{filtertest.Category},
+	}, {
+		name:     "no_group",
+		host:     "service-2.example",
+		wantCats: nil,
+	}, {
+		name:     "none",
+		host:     filtertest.Host,
+		wantCats: nil,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantCats, f.Classify(ctx, tc.host))
+		})
+	}
+
+	sum := sha256.Sum256([]byte(filtertest.HostBlockedService1))
+	wantRec := hex.EncodeToString(sum[:]) + ":" + filtertest.CategoryStr
+
+	gotRecs := catHashes.Hashes([]hashprefix.Prefix{hashprefix.Prefix(sum[:hashprefix.PrefixLen])})
+	assert.Equal(t, []string{wantRec}, gotRecs)
 }
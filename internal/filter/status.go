@@ -0,0 +1,102 @@
+package filter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Status is the refresh status of a single filter.
+type Status struct {
+	// LastRefresh is the time of the last successful refresh.  It is zero if
+	// there were none.
+	LastRefresh time.Time
+
+	// LastError is the error of the last refresh.  It is nil if the last
+	// refresh was successful.
+	LastError error
+
+	// RuleCount is the number of rules after the last successful refresh.
+	RuleCount int
+}
+
+// StatusMetrics is a [Metrics] implementation that records the refresh statuses
+// of filters in addition to passing them to the underlying metrics.
+type StatusMetrics struct {
+	// metrics is the underlying metrics.
+	metrics Metrics
+
+	// mu protects statuses.
+	mu *sync.Mutex
+
+	// statuses are the refresh statuses of filters by their IDs.
+	statuses map[string]*Status
+}
+
+// NewStatusMetrics returns a new properly initialized *StatusMetrics.  m must
+// not be nil.
+func NewStatusMetrics(m Metrics) (sm *StatusMetrics) {
+	return &StatusMetrics{
+		metrics:  m,
+		mu:       &sync.Mutex{},
+		statuses: map[string]*Status{},
+	}
+}
+
+// type check
+var _ Metrics = (*StatusMetrics)(nil)
+
+// IncrementRefresh implements the [Metrics] interface for *StatusMetrics.
+func (m *StatusMetrics) IncrementRefresh(ctx context.Context, id, typ string) {
+	m.metrics.IncrementRefresh(ctx, id, typ)
+}
+
+// SetFilterStatus implements the [Metrics] interface for *StatusMetrics.
+func (m *StatusMetrics) SetFilterStatus(
+	ctx context.Context,
+	id string,
+	updTime time.Time,
+	ruleCount int,
+	err error,
+) {
+	m.metrics.SetFilterStatus(ctx, id, updTime, ruleCount, err)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.statuses[id]
+	if !ok {
+		s = &Status{}
+		m.statuses[id] = s
+	}
+
+	s.LastError = err
+	if err == nil {
+		s.LastRefresh = updTime
+		s.RuleCount = ruleCount
+	}
+}
+
+// SetCombinedStatus implements the [Metrics] interface for *StatusMetrics.
+func (m *StatusMetrics) SetCombinedStatus(
+	ctx context.Context,
+	num int,
+	ruleCount int,
+	size uint64,
+	dur time.Duration,
+) {
+	m.metrics.SetCombinedStatus(ctx, num, ruleCount, size, dur)
+}
+
+// Statuses returns a copy of the refresh statuses of the filters by their IDs.
+func (m *StatusMetrics) Statuses() (statuses map[string]Status) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses = make(map[string]Status, len(m.statuses))
+	for id, s := range m.statuses {
+		statuses[id] = *s
+	}
+
+	return statuses
+}
@@ -0,0 +1,103 @@
+// Package filterverdict contains the EDNS(0) option that carries the filtering
+// verdict to the trusted downstream resolvers.  The option is signed with a key
+// shared with the operator of the downstream resolver, so that the verdict
+// can't be spoofed.
+package filterverdict
+
+import (
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+)
+
+// EDNSCode is the code of the local EDNS option that carries the filtering
+// verdict.  Clients request the verdict by adding an empty option with this
+// code to the query.  See RFC 6891, Section 9.
+const EDNSCode uint16 = 65101
+
+// Version is the current version of the format of the option data.
+const Version uint8 = 1
+
+// MinKeyLen is the minimum length of the signing key, in bytes.
+const MinKeyLen = 32
+
+// macLen is the length of the truncated HMAC-SHA256 of the option data.
+const macLen = 16
+
+// Action is the action taken by the filter.
+type Action uint8
+
+// Valid actions.
+const (
+	ActionAllowed  Action = 1
+	ActionBlocked  Action = 2
+	ActionModified Action = 3
+)
+
+// Category is the category of the filter that has matched the request.
+type Category uint8
+
+// Valid categories.
+const (
+	CategoryRuleList       Category = 1
+	CategoryCustom         Category = 2
+	CategoryBlockedService Category = 3
+	CategorySafeBrowsing   Category = 4
+	CategoryAdultBlocking  Category = 5
+	CategoryNewRegDomains  Category = 6
+	CategorySafeSearch     Category = 7
+)
+
+// CategoryFromID returns the category of the filter with the given ID.
+func CategoryFromID(id filter.ID) (c Category) {
+	switch id {
+	case filter.IDCustom:
+		return CategoryCustom
+	case filter.IDBlockedService:
+		return CategoryBlockedService
+	case filter.IDSafeBrowsing:
+		return CategorySafeBrowsing
+	case filter.IDAdultBlocking:
+		return CategoryAdultBlocking
+	case filter.IDNewRegDomains:
+		return CategoryNewRegDomains
+	case filter.IDGeneralSafeSearch, filter.IDYoutubeSafeSearch:
+		return CategorySafeSearch
+	default:
+		return CategoryRuleList
+	}
+}
+
+// Verdict is the filtering verdict carried by the option.
+type Verdict struct {
+	// ListID is the ID of the filter list that has matched the request.
+	ListID filter.ID
+
+	// Action is the action taken by the filter.
+	Action Action
+
+	// Category is the category of the filter.
+	Category Category
+}
+
+// FromResult returns the verdict for the filtering result res.  v is nil if res
+// is nil.
+func FromResult(res filter.Result) (v *Verdict) {
+	var action Action
+	switch res.(type) {
+	case nil:
+		return nil
+	case *filter.ResultAllowed:
+		action = ActionAllowed
+	case *filter.ResultBlocked:
+		action = ActionBlocked
+	default:
+		action = ActionModified
+	}
+
+	id, _ := res.MatchedRule()
+
+	return &Verdict{
+		ListID:   id,
+		Action:   action,
+		Category: CategoryFromID(id),
+	}
+}
@@ -0,0 +1,136 @@
+package filterverdict
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// headerLen is the length of the fixed part of the option data: the version,
+// the action, the category, and the length of the list ID.
+const headerLen = 4
+
+// Errors returned by [Verify].
+const (
+	errBadMAC     errors.Error = "bad mac"
+	errBadVersion errors.Error = "bad version"
+	errTooShort   errors.Error = "data too short"
+)
+
+// IsRequested returns true if req contains the option with [EDNSCode], which
+// means that the client wants to receive the verdict.
+func IsRequested(req *dns.Msg) (ok bool) {
+	opt := req.IsEdns0()
+	if opt == nil {
+		return false
+	}
+
+	return slices.ContainsFunc(opt.Option, isVerdictOption)
+}
+
+// isVerdictOption returns true if o is a local option with [EDNSCode].
+func isVerdictOption(o dns.EDNS0) (ok bool) {
+	local, ok := o.(*dns.EDNS0_LOCAL)
+
+	return ok && local.Code == EDNSCode
+}
+
+// Add adds the option carrying v signed with key to resp, replacing the
+// existing ones, if any.  resp must have at most one question, key must be at
+// least [MinKeyLen] bytes long, and v must not be nil.
+//
+// The data of the option consists of the version, the action, the category,
+// the length of the list ID, the list ID, and the first 16 bytes of the
+// HMAC-SHA256 of the message ID, the lowercased question name, the question
+// type, and all the preceding data.
+func Add(resp *dns.Msg, key []byte, v *Verdict) {
+	// Valid filter IDs are shorter than that, but make sure that the length
+	// fits into a byte anyway.
+	id := v.ListID[:min(len(v.ListID), math.MaxUint8)]
+
+	data := make([]byte, 0, headerLen+len(id)+macLen)
+	data = append(data, Version, byte(v.Action), byte(v.Category), byte(len(id)))
+	data = append(data, id...)
+	data = append(data, sign(resp, key, data)...)
+
+	local := &dns.EDNS0_LOCAL{
+		Code: EDNSCode,
+		Data: data,
+	}
+
+	opt := resp.IsEdns0()
+	if opt == nil {
+		opt = &dns.OPT{
+			Hdr: dns.RR_Header{
+				Name:   ".",
+				Rrtype: dns.TypeOPT,
+				Class:  dns.DefaultMsgSize,
+			},
+		}
+		resp.Extra = append(resp.Extra, opt)
+	}
+
+	opt.Option = slices.DeleteFunc(opt.Option, isVerdictOption)
+	opt.Option = append(opt.Option, local)
+}
+
+// Verify returns the verdict from the option in resp if its signature made with
+// key is valid.  v is nil if there is no such option.
+func Verify(resp *dns.Msg, key []byte) (v *Verdict, err error) {
+	opt := resp.IsEdns0()
+	if opt == nil {
+		return nil, nil
+	}
+
+	i := slices.IndexFunc(opt.Option, isVerdictOption)
+	if i < 0 {
+		return nil, nil
+	}
+
+	data := opt.Option[i].(*dns.EDNS0_LOCAL).Data
+	if len(data) < headerLen+macLen {
+		return nil, errTooShort
+	} else if data[0] != Version {
+		return nil, fmt.Errorf("%w: %d", errBadVersion, data[0])
+	}
+
+	signedLen := headerLen + int(data[3])
+	if len(data) != signedLen+macLen {
+		return nil, errTooShort
+	}
+
+	if !hmac.Equal(data[signedLen:], sign(resp, key, data[:signedLen])) {
+		return nil, errBadMAC
+	}
+
+	return &Verdict{
+		ListID:   filter.ID(data[headerLen:signedLen]),
+		Action:   Action(data[1]),
+		Category: Category(data[2]),
+	}, nil
+}
+
+// sign returns the truncated HMAC-SHA256 of the option data bound to resp.
+func sign(resp *dns.Msg, key, data []byte) (mac []byte) {
+	h := hmac.New(sha256.New, key)
+
+	// Don't check the errors, since [hash.Hash.Write] never returns one.
+	_, _ = h.Write(binary.BigEndian.AppendUint16(nil, resp.Id))
+	if len(resp.Question) > 0 {
+		q := resp.Question[0]
+		_, _ = h.Write([]byte(strings.ToLower(q.Name)))
+		_, _ = h.Write(binary.BigEndian.AppendUint16(nil, q.Qtype))
+	}
+
+	_, _ = h.Write(data)
+
+	return h.Sum(nil)[:macLen]
+}
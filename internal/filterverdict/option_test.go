@@ -0,0 +1,195 @@
+package filterverdict_test
+
+import (
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filterverdict"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey is the common signing key for tests.
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+// newTestResp is a helper that returns a new response with a single question.
+func newTestResp(tb testing.TB) (resp *dns.Msg) {
+	tb.Helper()
+
+	req := dnsservertest.CreateMessage("Example.ORG.", dns.TypeA)
+	resp = (&dns.Msg{}).SetReply(req)
+
+	return resp
+}
+
+func TestIsRequested(t *testing.T) {
+	t.Parallel()
+
+	req := dnsservertest.CreateMessage("example.org.", dns.TypeA)
+	assert.False(t, filterverdict.IsRequested(req))
+
+	req.SetEdns0(dns.DefaultMsgSize, false)
+	assert.False(t, filterverdict.IsRequested(req))
+
+	opt := req.IsEdns0()
+	opt.Option = append(opt.Option, &dns.EDNS0_LOCAL{
+		Code: filterverdict.EDNSCode,
+	})
+	assert.True(t, filterverdict.IsRequested(req))
+}
+
+func TestAdd(t *testing.T) {
+	t.Parallel()
+
+	want := &filterverdict.Verdict{
+		ListID:   filter.IDAdultBlocking,
+		Action:   filterverdict.ActionModified,
+		Category: filterverdict.CategoryAdultBlocking,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		resp := newTestResp(t)
+		filterverdict.Add(resp, testKey, want)
+
+		got, err := filterverdict.Verify(resp, testKey)
+		require.NoError(t, err)
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		t.Parallel()
+
+		spoofed := &filterverdict.Verdict{
+			ListID:   filter.IDCustom,
+			Action:   filterverdict.ActionAllowed,
+			Category: filterverdict.CategoryCustom,
+		}
+
+		resp := newTestResp(t)
+		filterverdict.Add(resp, []byte("spoofed_key_spoofed_key_spoofed_"), spoofed)
+		filterverdict.Add(resp, testKey, want)
+
+		require.Len(t, resp.IsEdns0().Option, 1)
+
+		got, err := filterverdict.Verify(resp, testKey)
+		require.NoError(t, err)
+
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("no_option", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := filterverdict.Verify(newTestResp(t), testKey)
+		require.NoError(t, err)
+
+		assert.Nil(t, got)
+	})
+
+	t.Run("other_key", func(t *testing.T) {
+		t.Parallel()
+
+		resp := newTestResp(t)
+		filterverdict.Add(resp, testKey, want)
+
+		_, err := filterverdict.Verify(resp, []byte("other_key_other_key_other_key_ot"))
+		testutil.AssertErrorMsg(t, "bad mac", err)
+	})
+
+	t.Run("other_question", func(t *testing.T) {
+		t.Parallel()
+
+		resp := newTestResp(t)
+		filterverdict.Add(resp, testKey, want)
+		resp.Question[0].Name = "example.com."
+
+		_, err := filterverdict.Verify(resp, testKey)
+		testutil.AssertErrorMsg(t, "bad mac", err)
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		t.Parallel()
+
+		resp := newTestResp(t)
+		filterverdict.Add(resp, testKey, want)
+
+		local := resp.IsEdns0().Option[0].(*dns.EDNS0_LOCAL)
+		local.Data[1] = byte(filterverdict.ActionAllowed)
+
+		_, err := filterverdict.Verify(resp, testKey)
+		testutil.AssertErrorMsg(t, "bad mac", err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		t.Parallel()
+
+		resp := newTestResp(t)
+		filterverdict.Add(resp, testKey, want)
+
+		local := resp.IsEdns0().Option[0].(*dns.EDNS0_LOCAL)
+		local.Data = local.Data[:len(local.Data)-1]
+
+		_, err := filterverdict.Verify(resp, testKey)
+		testutil.AssertErrorMsg(t, "data too short", err)
+	})
+}
+
+func TestFromResult(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		res  filter.Result
+		want *filterverdict.Verdict
+		name string
+	}{{
+		res:  nil,
+		want: nil,
+		name: "nil",
+	}, {
+		res: &filter.ResultBlocked{
+			List: filter.IDAdGuardDNS,
+			Rule: "||example.org^",
+		},
+		want: &filterverdict.Verdict{
+			ListID:   filter.IDAdGuardDNS,
+			Action:   filterverdict.ActionBlocked,
+			Category: filterverdict.CategoryRuleList,
+		},
+		name: "blocked",
+	}, {
+		res: &filter.ResultAllowed{
+			List: filter.IDCustom,
+			Rule: "@@||example.org^",
+		},
+		want: &filterverdict.Verdict{
+			ListID:   filter.IDCustom,
+			Action:   filterverdict.ActionAllowed,
+			Category: filterverdict.CategoryCustom,
+		},
+		name: "allowed",
+	}, {
+		res: &filter.ResultModifiedResponse{
+			List: filter.IDYoutubeSafeSearch,
+		},
+		want: &filterverdict.Verdict{
+			ListID:   filter.IDYoutubeSafeSearch,
+			Action:   filterverdict.ActionModified,
+			Category: filterverdict.CategorySafeSearch,
+		},
+		name: "modified",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, filterverdict.FromResult(tc.res))
+		})
+	}
+}
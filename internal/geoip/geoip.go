@@ -19,3 +19,20 @@ type Interface interface {
 	// data if ip is netip.Addr{}.
 	Data(host string, ip netip.Addr) (l *Location, err error)
 }
+
+// type check
+var _ Interface = Empty{}
+
+// Empty is an [Interface] implementation that has no GeoIP data.
+type Empty struct{}
+
+// SubnetByLocation implements the [Interface] interface for Empty.  n is always
+// an unspecified subnet of fam.
+func (Empty) SubnetByLocation(_ *Location, fam netutil.AddrFamily) (n netip.Prefix, err error) {
+	return netutil.ZeroPrefix(fam), nil
+}
+
+// Data implements the [Interface] interface for Empty.  l is always nil.
+func (Empty) Data(_ string, _ netip.Addr) (l *Location, err error) {
+	return nil, nil
+}
@@ -0,0 +1,140 @@
+// Package maintenance contains the runtime-switchable maintenance mode of the
+// server groups, which is used to drain the traffic from a node in a
+// controlled manner, for example during upgrades.
+package maintenance
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// ErrNoServerGroup is returned by [Manager.SetEnabled] when there is no server
+// group with the given name.
+const ErrNoServerGroup errors.Error = "no such server group"
+
+// GroupConfig is the maintenance-mode configuration of a single server group.
+type GroupConfig struct {
+	// RetryAfter is the duration after which the clients are advised to retry
+	// their queries.  If it is zero, no advice is given.  It must not be
+	// negative.
+	RetryAfter time.Duration
+
+	// FailHealthCheck, if true, makes [Manager.IsHealthy] return false while
+	// the server group is in the maintenance mode, so that the load balancers
+	// stop sending traffic to the node.
+	FailHealthCheck bool
+
+	// Enabled is the initial state of the maintenance mode.
+	Enabled bool
+}
+
+// Manager keeps the maintenance-mode state of the server groups.  It is safe
+// for concurrent use.
+type Manager struct {
+	// groups are the states of the server groups.  The map itself is never
+	// modified after construction, so it doesn't need a lock.
+	groups map[agd.ServerGroupName]*groupState
+}
+
+// groupState is the maintenance-mode state of a single server group.
+type groupState struct {
+	enabled         *atomic.Bool
+	retryAfter      time.Duration
+	failHealthCheck bool
+}
+
+// NewManager returns a new properly initialized *Manager for the server groups
+// in confs.  confs must not contain nil values.
+func NewManager(confs map[agd.ServerGroupName]*GroupConfig) (m *Manager) {
+	m = &Manager{
+		groups: make(map[agd.ServerGroupName]*groupState, len(confs)),
+	}
+
+	for name, c := range confs {
+		s := &groupState{
+			enabled:         &atomic.Bool{},
+			retryAfter:      c.RetryAfter,
+			failHealthCheck: c.FailHealthCheck,
+		}
+
+		s.enabled.Store(c.Enabled)
+		m.groups[name] = s
+	}
+
+	return m
+}
+
+// Status returns true if the server group with the given name is in the
+// maintenance mode as well as the duration after which the clients are advised
+// to retry.  Unknown server groups are never in the maintenance mode.
+func (m *Manager) Status(name agd.ServerGroupName) (retryAfter time.Duration, enabled bool) {
+	s, ok := m.groups[name]
+	if !ok || !s.enabled.Load() {
+		return 0, false
+	}
+
+	return s.retryAfter, true
+}
+
+// SetEnabled switches the maintenance mode of the server group with the given
+// name.  If there is no such server group, err is [ErrNoServerGroup].
+func (m *Manager) SetEnabled(name agd.ServerGroupName, enabled bool) (err error) {
+	s, ok := m.groups[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrNoServerGroup, name)
+	}
+
+	s.enabled.Store(enabled)
+
+	return nil
+}
+
+// IsHealthy returns false if at least one server group configured to fail the
+// health check is in the maintenance mode.
+func (m *Manager) IsHealthy() (ok bool) {
+	for _, s := range m.groups {
+		if s.failHealthCheck && s.enabled.Load() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// State is the maintenance-mode state of a single server group.
+type State struct {
+	// Name is the name of the server group.
+	Name agd.ServerGroupName
+
+	// RetryAfter is the duration after which the clients are advised to retry
+	// their queries.
+	RetryAfter time.Duration
+
+	// FailHealthCheck is true if the maintenance mode of this server group
+	// fails the health check.
+	FailHealthCheck bool
+
+	// Enabled is true if the server group is in the maintenance mode.
+	Enabled bool
+}
+
+// States returns the current states of all server groups sorted by name.
+func (m *Manager) States() (states []*State) {
+	for _, name := range slices.Sorted(maps.Keys(m.groups)) {
+		s := m.groups[name]
+		states = append(states, &State{
+			Name:            name,
+			RetryAfter:      s.retryAfter,
+			FailHealthCheck: s.failHealthCheck,
+			Enabled:         s.enabled.Load(),
+		})
+	}
+
+	return states
+}
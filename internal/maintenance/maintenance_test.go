@@ -0,0 +1,71 @@
+package maintenance_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/maintenance"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Server group names for tests.
+const (
+	testGroupDrain agd.ServerGroupName = "drain"
+	testGroupKeep  agd.ServerGroupName = "keep"
+)
+
+// testRetryAfter is the common retry-after duration for tests.
+const testRetryAfter = 30 * time.Second
+
+func TestManager(t *testing.T) {
+	t.Parallel()
+
+	m := maintenance.NewManager(map[agd.ServerGroupName]*maintenance.GroupConfig{
+		testGroupDrain: {
+			RetryAfter:      testRetryAfter,
+			FailHealthCheck: true,
+			Enabled:         false,
+		},
+		testGroupKeep: {
+			RetryAfter:      0,
+			FailHealthCheck: false,
+			Enabled:         true,
+		},
+	})
+
+	retryAfter, enabled := m.Status(testGroupKeep)
+	assert.True(t, enabled)
+	assert.Zero(t, retryAfter)
+
+	_, enabled = m.Status(testGroupDrain)
+	assert.False(t, enabled)
+
+	_, enabled = m.Status("unknown")
+	assert.False(t, enabled)
+
+	assert.True(t, m.IsHealthy())
+
+	require.NoError(t, m.SetEnabled(testGroupDrain, true))
+
+	retryAfter, enabled = m.Status(testGroupDrain)
+	assert.True(t, enabled)
+	assert.Equal(t, testRetryAfter, retryAfter)
+	assert.False(t, m.IsHealthy())
+
+	assert.Equal(t, []*maintenance.State{{
+		Name:            testGroupDrain,
+		RetryAfter:      testRetryAfter,
+		FailHealthCheck: true,
+		Enabled:         true,
+	}, {
+		Name:            testGroupKeep,
+		RetryAfter:      0,
+		FailHealthCheck: false,
+		Enabled:         true,
+	}}, m.States())
+
+	err := m.SetEnabled("unknown", true)
+	assert.ErrorIs(t, err, maintenance.ErrNoServerGroup)
+}
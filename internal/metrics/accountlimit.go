@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// AccountLimit is the Prometheus-based implementation of the
+// [accountlimit.Metrics] interface.
+type AccountLimit struct {
+	// requestsTotal is the counter of the checked requests of the accounts
+	// with limits by the tier of the account and the result of the check.
+	requestsTotal *prometheus.CounterVec
+}
+
+// NewAccountLimit registers the account-limit metrics in reg and returns a
+// properly initialized *AccountLimit.
+func NewAccountLimit(namespace string, reg prometheus.Registerer) (m *AccountLimit, err error) {
+	const requestsTotal = "requests_total"
+
+	m = &AccountLimit{
+		requestsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      requestsTotal,
+			Namespace: namespace,
+			Subsystem: subsystemAccountLimit,
+			Help: "The total number of DNS queries of the accounts with aggregate " +
+				"limits.  Label tier is the tier of the account.  Label result is " +
+				"either pass, rps, or devices.",
+		}, []string{"tier", "result"}),
+	}
+
+	err = reg.Register(m.requestsTotal)
+	if err != nil {
+		return nil, fmt.Errorf("registering metrics %q: %w", requestsTotal, err)
+	}
+
+	return m, nil
+}
+
+// OnCheck implements the [accountlimit.Metrics] interface for *AccountLimit.
+func (m *AccountLimit) OnCheck(_ context.Context, tier, result string) {
+	m.requestsTotal.WithLabelValues(tier, result).Inc()
+}
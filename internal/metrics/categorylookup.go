@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CategoryLookup is the Prometheus-based implementation of the
+// [hashprefix.CategoryMetrics] interface.
+type CategoryLookup struct {
+	// foundTotal is the counter of the valid content-category lookups with
+	// records.
+	foundTotal prometheus.Counter
+
+	// notFoundTotal is the counter of the valid content-category lookups
+	// without records.
+	notFoundTotal prometheus.Counter
+
+	// invalidTotal is the counter of the invalid content-category lookups.
+	invalidTotal prometheus.Counter
+
+	// limitedTotal is the counter of the rate-limited content-category
+	// lookups.
+	limitedTotal prometheus.Counter
+}
+
+// NewCategoryLookup registers the content-category lookup metrics in reg and
+// returns a properly initialized *CategoryLookup.
+func NewCategoryLookup(
+	namespace string,
+	reg prometheus.Registerer,
+) (m *CategoryLookup, err error) {
+	const lookupsTotal = "category_lookups_total"
+
+	lookupsTotalCounters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      lookupsTotal,
+		Namespace: namespace,
+		Subsystem: subsystemFilter,
+		Help: "The total number of content-category TXT lookups.  " +
+			"Label result is one of found, not_found, invalid, and limited.",
+	}, []string{"result"})
+
+	err = reg.Register(lookupsTotalCounters)
+	if err != nil {
+		return nil, fmt.Errorf("registering metrics %q: %w", lookupsTotal, err)
+	}
+
+	return &CategoryLookup{
+		foundTotal:    lookupsTotalCounters.WithLabelValues("found"),
+		notFoundTotal: lookupsTotalCounters.WithLabelValues("not_found"),
+		invalidTotal:  lookupsTotalCounters.WithLabelValues("invalid"),
+		limitedTotal:  lookupsTotalCounters.WithLabelValues("limited"),
+	}, nil
+}
+
+// OnLookup implements the [hashprefix.CategoryMetrics] interface for
+// *CategoryLookup.
+func (m *CategoryLookup) OnLookup(_ context.Context, found bool) {
+	if found {
+		m.foundTotal.Inc()
+	} else {
+		m.notFoundTotal.Inc()
+	}
+}
+
+// IncrementInvalid implements the [hashprefix.CategoryMetrics] interface for
+// *CategoryLookup.
+func (m *CategoryLookup) IncrementInvalid(_ context.Context) {
+	m.invalidTotal.Inc()
+}
+
+// IncrementLimited implements the [hashprefix.CategoryMetrics] interface for
+// *CategoryLookup.
+func (m *CategoryLookup) IncrementLimited(_ context.Context) {
+	m.limitedTotal.Inc()
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CNAMEMiddleware is an interface for collection of the statistics of the
+// CNAME-chain middleware.
+//
+// NOTE:  Keep in sync with [dnssvc.CNAMEMiddlewareMetrics].
+type CNAMEMiddleware interface {
+	OnTruncated(ctx context.Context, isLoop bool)
+}
+
+// DefaultCNAMEMiddleware is the Prometheus-based implementation of the
+// [CNAMEMiddleware] interface.
+type DefaultCNAMEMiddleware struct {
+	truncatedLoop   prometheus.Counter
+	truncatedLength prometheus.Counter
+}
+
+// NewDefaultCNAMEMiddleware registers the metrics of the CNAME-chain middleware
+// in reg and returns a properly initialized *DefaultCNAMEMiddleware.
+func NewDefaultCNAMEMiddleware(
+	namespace string,
+	reg prometheus.Registerer,
+) (m *DefaultCNAMEMiddleware, err error) {
+	const truncatedTotal = "truncated_chains_total"
+
+	truncatedTotalCounters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      truncatedTotal,
+		Namespace: namespace,
+		Subsystem: subsystemCNAME,
+		Help: "The total number of responses with truncated CNAME chains.  Label " +
+			"reason is either loop or length.",
+	}, []string{"reason"})
+
+	err = reg.Register(truncatedTotalCounters)
+	if err != nil {
+		return nil, fmt.Errorf("registering metrics %q: %w", truncatedTotal, err)
+	}
+
+	return &DefaultCNAMEMiddleware{
+		truncatedLoop:   truncatedTotalCounters.WithLabelValues("loop"),
+		truncatedLength: truncatedTotalCounters.WithLabelValues("length"),
+	}, nil
+}
+
+// type check
+var _ CNAMEMiddleware = (*DefaultCNAMEMiddleware)(nil)
+
+// OnTruncated implements the [CNAMEMiddleware] interface for
+// *DefaultCNAMEMiddleware.
+func (m *DefaultCNAMEMiddleware) OnTruncated(_ context.Context, isLoop bool) {
+	IncrementCond(isLoop, m.truncatedLoop, m.truncatedLength)
+}
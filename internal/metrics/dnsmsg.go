@@ -29,6 +29,52 @@ var (
 	dnsMsgPartialClones = fullClones.With(prometheus.Labels{
 		"full": "0",
 	})
+
+	// rrSliceGets is a counter with the total number of resource-record slices
+	// taken from the size-classed pools of the cloner.  "hit" is either "1"
+	// (a pooled slice was reused) or "0" (a new slice was allocated).
+	rrSliceGets = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name:      "rr_slice_pool_gets_total",
+		Subsystem: subsystemDNSMsg,
+		Namespace: namespace,
+		Help: "Total number of resource-record slices taken from the pools. " +
+			"hit=1 means that a pooled slice was reused.",
+	}, []string{"hit"})
+
+	// dnsMsgRRSliceHits is a counter with the total number of reused
+	// resource-record slices.
+	dnsMsgRRSliceHits = rrSliceGets.With(prometheus.Labels{
+		"hit": "1",
+	})
+
+	// dnsMsgRRSliceMisses is a counter with the total number of allocated
+	// resource-record slices.
+	dnsMsgRRSliceMisses = rrSliceGets.With(prometheus.Labels{
+		"hit": "0",
+	})
+
+	// interns is a counter with the total number of domain names interned by
+	// the cloner.  "hit" is either "1" (the name has already been interned) or
+	// "0" (the name has been added to the table).
+	interns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name:      "interns_total",
+		Subsystem: subsystemDNSMsg,
+		Namespace: namespace,
+		Help: "Total number of domain names interned by the cloner. " +
+			"hit=1 means that the name has already been interned.",
+	}, []string{"hit"})
+
+	// dnsMsgInternHits is a counter with the total number of the domain names
+	// that have already been interned.
+	dnsMsgInternHits = interns.With(prometheus.Labels{
+		"hit": "1",
+	})
+
+	// dnsMsgInternMisses is a counter with the total number of the domain
+	// names that have been added to the table.
+	dnsMsgInternMisses = interns.With(prometheus.Labels{
+		"hit": "0",
+	})
 )
 
 // ClonerStat is the Prometheus-based implementation of the [dnsmsg.ClonerStat]
@@ -41,3 +87,13 @@ type ClonerStat struct{}
 func (ClonerStat) OnClone(isFull bool) {
 	IncrementCond(isFull, dnsMsgFullClones, dnsMsgPartialClones)
 }
+
+// OnRRSliceGet implements the [dnsmsg.ClonerStat] interface for ClonerStat.
+func (ClonerStat) OnRRSliceGet(isHit bool) {
+	IncrementCond(isHit, dnsMsgRRSliceHits, dnsMsgRRSliceMisses)
+}
+
+// OnIntern implements the [dnsmsg.ClonerStat] interface for ClonerStat.
+func (ClonerStat) OnIntern(isHit bool) {
+	IncrementCond(isHit, dnsMsgInternHits, dnsMsgInternMisses)
+}
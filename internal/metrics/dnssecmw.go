@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DNSSECMiddleware is an interface for collection of the statistics of the
+// DNSSEC-requirement middleware.
+//
+// NOTE:  Keep in sync with [dnssvc.DNSSECMiddlewareMetrics].
+type DNSSECMiddleware interface {
+	OnResponse(ctx context.Context, isValidated bool)
+}
+
+// DefaultDNSSECMiddleware is the Prometheus-based implementation of the
+// [DNSSECMiddleware] interface.
+type DefaultDNSSECMiddleware struct {
+	validated   prometheus.Counter
+	unvalidated prometheus.Counter
+}
+
+// NewDefaultDNSSECMiddleware registers the metrics of the DNSSEC-requirement
+// middleware in reg and returns a properly initialized
+// *DefaultDNSSECMiddleware.
+func NewDefaultDNSSECMiddleware(
+	namespace string,
+	reg prometheus.Registerer,
+) (m *DefaultDNSSECMiddleware, err error) {
+	const requiredRespTotal = "required_responses_total"
+
+	requiredRespTotalCounters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      requiredRespTotal,
+		Namespace: namespace,
+		Subsystem: subsystemDNSSEC,
+		Help: "The total number of upstream responses checked for profiles " +
+			"requiring DNSSEC.  Label validated is 0 if the response has been " +
+			"replaced with SERVFAIL.",
+	}, []string{"validated"})
+
+	err = reg.Register(requiredRespTotalCounters)
+	if err != nil {
+		return nil, fmt.Errorf("registering metrics %q: %w", requiredRespTotal, err)
+	}
+
+	return &DefaultDNSSECMiddleware{
+		validated:   requiredRespTotalCounters.WithLabelValues("1"),
+		unvalidated: requiredRespTotalCounters.WithLabelValues("0"),
+	}, nil
+}
+
+// type check
+var _ DNSSECMiddleware = (*DefaultDNSSECMiddleware)(nil)
+
+// OnResponse implements the [DNSSECMiddleware] interface for
+// *DefaultDNSSECMiddleware.
+func (m *DefaultDNSSECMiddleware) OnResponse(_ context.Context, isValidated bool) {
+	IncrementCond(isValidated, m.validated, m.unvalidated)
+}
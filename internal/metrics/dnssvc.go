@@ -46,6 +46,12 @@ var (
 		"kind": "apple_private_relay",
 	})
 
+	// DNSSvcZoneTransferRequestsTotal is a counter with total number of the
+	// refused zone transfer requests, that is AXFR and IXFR ones.
+	DNSSvcZoneTransferRequestsTotal = specialRequestsTotal.With(prometheus.Labels{
+		"kind": "zone_transfer",
+	})
+
 	// DNSSvcDoHAuthFailsTotal is the counter of DoH basic authentication
 	// failures.
 	DNSSvcDoHAuthFailsTotal = promauto.NewCounter(prometheus.CounterOpts{
@@ -55,3 +61,14 @@ var (
 		Help:      "The number of authentication failures for DoH auth.",
 	})
 )
+
+// DNSSvcLeakRequestsTotal is a counter with the total number of requests for
+// the special-use domain names that must not leak to the global DNS.  "kind"
+// is either "localhost", "local", or "onion".  "server_group" is the name of
+// the server group that received the request.
+var DNSSvcLeakRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name:      "leak_requests_total",
+	Namespace: namespace,
+	Subsystem: subsystemDNSSvc,
+	Help:      "The number of DNS requests for special-use domain names that must not leak.",
+}, []string{"kind", "server_group"})
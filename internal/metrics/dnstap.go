@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultDnstap is the Prometheus-based implementation of the
+// [dnstap.Metrics] interface.
+type DefaultDnstap struct {
+	droppedTotal prometheus.Counter
+	writtenTotal prometheus.Counter
+}
+
+// NewDefaultDnstap registers the metrics of the dnstap output in reg and
+// returns a properly initialized *DefaultDnstap.
+func NewDefaultDnstap(
+	namespace string,
+	reg prometheus.Registerer,
+) (m *DefaultDnstap, err error) {
+	const (
+		droppedTotal = "dropped_total"
+		writtenTotal = "written_total"
+	)
+
+	m = &DefaultDnstap{
+		droppedTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:      droppedTotal,
+			Namespace: namespace,
+			Subsystem: subsystemDnstap,
+			Help: "The total number of dnstap messages dropped, because the " +
+				"queue was full or the sink was unavailable.",
+		}),
+		writtenTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:      writtenTotal,
+			Namespace: namespace,
+			Subsystem: subsystemDnstap,
+			Help:      "The total number of dnstap messages written.",
+		}),
+	}
+
+	var errs []error
+	collectors := container.KeyValues[string, prometheus.Collector]{{
+		Key:   droppedTotal,
+		Value: m.droppedTotal,
+	}, {
+		Key:   writtenTotal,
+		Value: m.writtenTotal,
+	}}
+
+	for _, c := range collectors {
+		err = reg.Register(c.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("registering metrics %q: %w", c.Key, err))
+		}
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// IncrementDropped implements the [dnstap.Metrics] interface for
+// *DefaultDnstap.
+func (m *DefaultDnstap) IncrementDropped(_ context.Context) {
+	m.droppedTotal.Inc()
+}
+
+// IncrementWritten implements the [dnstap.Metrics] interface for
+// *DefaultDnstap.
+func (m *DefaultDnstap) IncrementWritten(_ context.Context) {
+	m.writtenTotal.Inc()
+}
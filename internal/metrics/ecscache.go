@@ -28,6 +28,17 @@ var (
 	ECSHasSupportCacheSize = ecsCacheSize.With(prometheus.Labels{
 		"supports": "yes",
 	})
+
+	// ECSCachePartitionSize is the gauge with the number of items in a cache
+	// partition.  "outcome" is the class of the filtering outcome of the
+	// requests in the partition, see agd.FilteringOutcome.  "supports" is
+	// either "yes" or "no", see ecsCacheSize.
+	ECSCachePartitionSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "partition_size",
+		Namespace: namespace,
+		Subsystem: subsystemECSCache,
+		Help:      "The number of items in an ECS cache partition.",
+	}, []string{"outcome", "supports"})
 )
 
 // Lookup metrics.
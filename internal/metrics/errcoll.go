@@ -0,0 +1,46 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultErrCollSyslog is the Prometheus-based implementation of the
+// [errcoll.SyslogMetrics] interface.
+type DefaultErrCollSyslog struct {
+	droppedTotal prometheus.Counter
+}
+
+// NewDefaultErrCollSyslog registers the metrics of the syslog error collector
+// in reg and returns a properly initialized *DefaultErrCollSyslog.
+func NewDefaultErrCollSyslog(
+	namespace string,
+	reg prometheus.Registerer,
+) (m *DefaultErrCollSyslog, err error) {
+	const droppedTotal = "syslog_dropped_total"
+
+	m = &DefaultErrCollSyslog{
+		droppedTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:      droppedTotal,
+			Namespace: namespace,
+			Subsystem: subsystemErrColl,
+			Help: "The total number of errors not sent to the syslog server, " +
+				"because the queue was full.",
+		}),
+	}
+
+	err = reg.Register(m.droppedTotal)
+	if err != nil {
+		return nil, fmt.Errorf("registering metrics %q: %w", droppedTotal, err)
+	}
+
+	return m, nil
+}
+
+// IncrementDropped implements the [errcoll.SyslogMetrics] interface for
+// *DefaultErrCollSyslog.
+func (m *DefaultErrCollSyslog) IncrementDropped(_ context.Context) {
+	m.droppedTotal.Inc()
+}
@@ -111,6 +111,34 @@ var (
 		"hit":    "0",
 		"filter": "newly_registered_domains",
 	})
+
+	// hashPrefixFilterStorageSize is a gauge with the size of the table of
+	// hashes of a HashPrefixFilter in bytes.
+	hashPrefixFilterStorageSize = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "hash_prefix_storage_size_bytes",
+		Subsystem: subsystemFilter,
+		Namespace: namespace,
+		Help: "The size of the table of hashes of the HashPrefixFilter in bytes. " +
+			"For file-backed storages, this is the size of the mapped memory.",
+	}, []string{"filter"})
+
+	// HashPrefixFilterSafeBrowsingStorageSize is the gauge with the size of
+	// the table of hashes for safe browsing filter.
+	HashPrefixFilterSafeBrowsingStorageSize = hashPrefixFilterStorageSize.With(prometheus.Labels{
+		"filter": "safe_browsing",
+	})
+
+	// HashPrefixFilterAdultBlockingStorageSize is the gauge with the size of
+	// the table of hashes for adult blocking filter.
+	HashPrefixFilterAdultBlockingStorageSize = hashPrefixFilterStorageSize.With(prometheus.Labels{
+		"filter": "adult_blocking",
+	})
+
+	// HashPrefixFilterNewRegDomainsStorageSize is the gauge with the size of
+	// the table of hashes for newly registered domains filter.
+	HashPrefixFilterNewRegDomainsStorageSize = hashPrefixFilterStorageSize.With(prometheus.Labels{
+		"filter": "newly_registered_domains",
+	})
 )
 
 // Filter is the Prometheus-based implementation of the [Filter]
@@ -127,15 +155,40 @@ type Filter struct {
 	// updateTime is the gauge vector with the last time when the filter was
 	// last updated.
 	updatedTime *prometheus.GaugeVec
+
+	// refreshesTotal is the counter vector with the number of refreshes of
+	// filters from their URLs by the type of the refresh.
+	refreshesTotal *prometheus.CounterVec
+
+	// combinedTotal is the gauge with the number of combined rule-list
+	// filters.
+	combinedTotal prometheus.Gauge
+
+	// combinedRulesTotal is the gauge with the total number of rules in the
+	// combined rule-list filters.
+	combinedRulesTotal prometheus.Gauge
+
+	// combinedSize is the gauge with the total size of the rule texts of the
+	// combined rule-list filters in bytes.
+	combinedSize prometheus.Gauge
+
+	// combinedBuildDuration is the gauge with the time spent on building the
+	// combined rule-list filters during the last refresh in seconds.
+	combinedBuildDuration prometheus.Gauge
 }
 
 // NewFilter registers the filtering metrics in reg and returns a properly
 // initialized *Filter.
 func NewFilter(namespace string, reg prometheus.Registerer) (m *Filter, err error) {
 	const (
-		rulesTotal   = "rules_total"
-		updateStatus = "update_status"
-		updatedTime  = "updated_time"
+		combinedBuildDuration = "combined_build_duration_seconds"
+		combinedRulesTotal    = "combined_rules_total"
+		combinedSize          = "combined_size_bytes"
+		combinedTotal         = "combined_total"
+		refreshesTotal        = "refreshes_total"
+		rulesTotal            = "rules_total"
+		updateStatus          = "update_status"
+		updatedTime           = "updated_time"
 	)
 
 	m = &Filter{
@@ -159,6 +212,43 @@ func NewFilter(namespace string, reg prometheus.Registerer) (m *Filter, err erro
 			Namespace: namespace,
 			Help:      "Time when the filter was last time updated.",
 		}, []string{"filter"}),
+
+		refreshesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      refreshesTotal,
+			Subsystem: subsystemFilter,
+			Namespace: namespace,
+			Help: "The number of refreshes of filters from their urls. " +
+				"type is one of full, delta, or not_modified.",
+		}, []string{"filter", "type"}),
+
+		combinedTotal: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:      combinedTotal,
+			Subsystem: subsystemFilter,
+			Namespace: namespace,
+			Help:      "The number of combined rule-list filters.",
+		}),
+
+		combinedRulesTotal: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:      combinedRulesTotal,
+			Subsystem: subsystemFilter,
+			Namespace: namespace,
+			Help:      "The total number of rules in combined rule-list filters.",
+		}),
+
+		combinedSize: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:      combinedSize,
+			Subsystem: subsystemFilter,
+			Namespace: namespace,
+			Help:      "The total size of the rule texts of combined rule-list filters.",
+		}),
+
+		combinedBuildDuration: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:      combinedBuildDuration,
+			Subsystem: subsystemFilter,
+			Namespace: namespace,
+			Help: "The time spent on building combined rule-list filters " +
+				"during the last refresh.",
+		}),
 	}
 
 	var errs []error
@@ -171,6 +261,21 @@ func NewFilter(namespace string, reg prometheus.Registerer) (m *Filter, err erro
 	}, {
 		Key:   updatedTime,
 		Value: m.updatedTime,
+	}, {
+		Key:   refreshesTotal,
+		Value: m.refreshesTotal,
+	}, {
+		Key:   combinedTotal,
+		Value: m.combinedTotal,
+	}, {
+		Key:   combinedRulesTotal,
+		Value: m.combinedRulesTotal,
+	}, {
+		Key:   combinedSize,
+		Value: m.combinedSize,
+	}, {
+		Key:   combinedBuildDuration,
+		Value: m.combinedBuildDuration,
 	}}
 
 	for _, c := range collectors {
@@ -205,3 +310,22 @@ func (m *Filter) SetFilterStatus(
 	m.updateStatus.WithLabelValues(id).Set(1)
 	m.updatedTime.WithLabelValues(id).Set(float64(updTime.UnixNano()) / float64(time.Second))
 }
+
+// IncrementRefresh implements the [filter.Metrics] interface for *Filter.
+func (m *Filter) IncrementRefresh(_ context.Context, id, typ string) {
+	m.refreshesTotal.WithLabelValues(id, typ).Inc()
+}
+
+// SetCombinedStatus implements the [filter.Metrics] interface for *Filter.
+func (m *Filter) SetCombinedStatus(
+	_ context.Context,
+	num int,
+	ruleCount int,
+	size uint64,
+	dur time.Duration,
+) {
+	m.combinedTotal.Set(float64(num))
+	m.combinedRulesTotal.Set(float64(ruleCount))
+	m.combinedSize.Set(float64(size))
+	m.combinedBuildDuration.Set(dur.Seconds())
+}
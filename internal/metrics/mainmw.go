@@ -29,12 +29,18 @@ type MainMiddlewareRequestMetrics = struct {
 	Continent         string
 	Country           string
 	FilterListID      string
+	Categories        []string
 	FilteringDuration time.Duration
 	ASN               uint32
 	IsAnonymous       bool
 	IsBlocked         bool
 }
 
+// topASNLimit is the approximate maximum number of ASNs for which the per-ASN
+// series of the request_per_top_asn_total metric are kept.  The actual limit is
+// rounded up to a multiple of [topASNShardsNum].
+const topASNLimit = 100
+
 // DefaultMainMiddleware is the Prometheus-based implementation of the
 // [MainMiddleware] interface.
 type DefaultMainMiddleware struct {
@@ -46,6 +52,16 @@ type DefaultMainMiddleware struct {
 	// processed labeled by country and AS number.
 	requestPerASNTotal *prometheus.CounterVec
 
+	// requestBlockedPerCountryTotal is a counter with the total number of
+	// queries blocked or rewritten labeled by country.  Together with
+	// requestPerCountryTotal, it shows the blocked ratio by country.
+	requestBlockedPerCountryTotal *prometheus.CounterVec
+
+	// requestPerCategoryTotal is a counter with the total number of queries
+	// processed labeled by the content category of the requested host.
+	// Queries for hosts with several categories are counted for each of them.
+	requestPerCategoryTotal *prometheus.CounterVec
+
 	// requestPerCountryTotal is a counter with the total number of queries
 	// processed labeled by country, continent, and whether any filter has been
 	// applied.
@@ -58,6 +74,10 @@ type DefaultMainMiddleware struct {
 	// from a AdGuard DNS customer, otherwise it is "1".
 	requestPerFilterTotal *prometheus.CounterVec
 
+	// requestPerTopASN is a counter with the total number of queries
+	// processed labeled by AS number for the ASNs with the most queries.
+	requestPerTopASN *topASNCounter
+
 	// userCounter is the main user statistics counter.
 	userCounter *UserCounter
 }
@@ -69,12 +89,15 @@ func NewDefaultMainMiddleware(
 	reg prometheus.Registerer,
 ) (m *DefaultMainMiddleware, err error) {
 	const (
-		filteringDuration      = "filtering_duration_seconds"
-		requestPerASNTotal     = "request_per_asn_total"
-		requestPerCountryTotal = "request_per_country_total"
-		requestPerFilterTotal  = "request_per_filter_total"
-		usersLastDayCount      = "users_last_day_count"
-		usersLastHourCount     = "users_last_hour_count"
+		filteringDuration             = "filtering_duration_seconds"
+		requestBlockedPerCountryTotal = "request_blocked_per_country_total"
+		requestPerASNTotal            = "request_per_asn_total"
+		requestPerCategoryTotal       = "request_per_category_total"
+		requestPerCountryTotal        = "request_per_country_total"
+		requestPerFilterTotal         = "request_per_filter_total"
+		requestPerTopASNTotal         = "request_per_top_asn_total"
+		usersLastDayCount             = "users_last_day_count"
+		usersLastHourCount            = "users_last_hour_count"
 	)
 
 	m = &DefaultMainMiddleware{
@@ -114,6 +137,20 @@ func NewDefaultMainMiddleware(
 			},
 		}, []string{"country", "asn"}),
 
+		requestBlockedPerCountryTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      requestBlockedPerCountryTotal,
+			Namespace: namespace,
+			Subsystem: subsystemDNSSvc,
+			Help:      "The number of blocked or rewritten DNS requests labeled by country.",
+		}, []string{"country"}),
+
+		requestPerCategoryTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      requestPerCategoryTotal,
+			Namespace: namespace,
+			Subsystem: subsystemDNSSvc,
+			Help:      "The number of processed DNS requests labeled by content category.",
+		}, []string{"category"}),
+
 		requestPerCountryTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name:      requestPerCountryTotal,
 			Namespace: namespace,
@@ -130,6 +167,17 @@ func NewDefaultMainMiddleware(
 		}, []string{"filter", "anonymous"}),
 	}
 
+	perTopASN := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      requestPerTopASNTotal,
+		Namespace: namespace,
+		Subsystem: subsystemDNSSvc,
+		Help: "The number of processed DNS requests labeled by ASN. " +
+			"Only the ASNs with the most requests are kept, and the series of " +
+			"an ASN that gets back into the top starts anew.",
+	}, []string{"asn"})
+
+	m.requestPerTopASN = newTopASNCounter(perTopASN, topASNLimit)
+
 	ipsLastDay := prometheus.NewGauge(prometheus.GaugeOpts{
 		Name:      usersLastDayCount,
 		Namespace: namespace,
@@ -150,15 +198,24 @@ func NewDefaultMainMiddleware(
 	collectors := container.KeyValues[string, prometheus.Collector]{{
 		Key:   filteringDuration,
 		Value: m.filteringDuration,
+	}, {
+		Key:   requestBlockedPerCountryTotal,
+		Value: m.requestBlockedPerCountryTotal,
 	}, {
 		Key:   requestPerASNTotal,
 		Value: m.requestPerASNTotal,
+	}, {
+		Key:   requestPerCategoryTotal,
+		Value: m.requestPerCategoryTotal,
 	}, {
 		Key:   requestPerCountryTotal,
 		Value: m.requestPerCountryTotal,
 	}, {
 		Key:   requestPerFilterTotal,
 		Value: m.requestPerFilterTotal,
+	}, {
+		Key:   requestPerTopASNTotal,
+		Value: perTopASN,
 	}, {
 		Key:   usersLastDayCount,
 		Value: ipsLastDay,
@@ -187,13 +244,21 @@ func (m *DefaultMainMiddleware) OnRequest(_ context.Context, rm *MainMiddlewareR
 
 	asnStr := strconv.FormatUint(uint64(rm.ASN), 10)
 	m.requestPerASNTotal.WithLabelValues(rm.Country, asnStr).Inc()
+	m.requestPerTopASN.record(rm.ASN)
 
 	// FilterListID is only empty if no filter has been applied.
 	filtersApplied := BoolString(rm.FilterListID != "")
 	m.requestPerCountryTotal.WithLabelValues(rm.Continent, rm.Country, filtersApplied).Inc()
+	if rm.IsBlocked {
+		m.requestBlockedPerCountryTotal.WithLabelValues(rm.Country).Inc()
+	}
 
 	m.requestPerFilterTotal.WithLabelValues(rm.FilterListID, BoolString(rm.IsAnonymous)).Inc()
 
+	for _, c := range rm.Categories {
+		m.requestPerCategoryTotal.WithLabelValues(c).Inc()
+	}
+
 	// Assume that ip is the remote IP address, which has already been unmapped
 	// by [netutil.NetAddrToAddrPort].
 	ipArr := rm.RemoteIP.As16()
@@ -24,24 +24,37 @@ var namespace = cmp.Or(os.Getenv("METRICS_NAMESPACE"), "dns")
 // Constants with the subsystem names that we use in our prometheus metrics.
 const (
 	subsystemAccess       = "access"
+	subsystemAccountLimit = "accountlimit"
 	subsystemApplication  = "app"
 	subsystemBackend      = "backend"
 	subsystemBillStat     = "billstat"
 	subsystemBindToDevice = "bindtodevice"
+	subsystemCNAME        = "cname"
 	subsystemConnLimiter  = "connlimiter"
 	subsystemConsul       = "consul"
 	subsystemDNSCheck     = "dnscheck"
 	subsystemDNSDB        = "dnsdb"
 	subsystemDNSMsg       = "dnsmsg"
+	subsystemDNSSEC       = "dnssec"
 	subsystemDNSSvc       = "dnssvc"
+	subsystemDnstap       = "dnstap"
 	subsystemECSCache     = "ecscache"
+	subsystemErrColl      = "errcoll"
 	subsystemFilter       = "filter"
 	subsystemGeoIP        = "geoip"
+	subsystemNSEC         = "nsec"
+	subsystemPoisonPill   = "poisonpill"
+	subsystemQuarantine   = "quarantine"
+	subsystemQNAMELimit   = "qnamelimit"
+	subsystemQUICAdvisor  = "quicadvisor"
 	subsystemQueryLog     = "querylog"
 	subsystemResearch     = "research"
+	subsystemShadow       = "shadow"
+	subsystemStaticZone   = "staticzone"
 	subsystemRuleStat     = "rulestat"
 	subsystemTLS          = "tls"
 	subsystemWebSvc       = "websvc"
+	subsystemXDPFilter    = "xdpfilter"
 )
 
 // Constants that should be kept in sync with ones in package prometheus in
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NSECMiddleware is an interface for collection of the statistics of the
+// aggressive NSEC middleware.
+//
+// NOTE:  Keep in sync with [dnssvc.NSECMiddlewareMetrics].
+type NSECMiddleware interface {
+	OnLookup(ctx context.Context, hit bool)
+	OnStore(ctx context.Context, stored bool)
+}
+
+// DefaultNSECMiddleware is the Prometheus-based implementation of the
+// [NSECMiddleware] interface.
+type DefaultNSECMiddleware struct {
+	lookupsHits   prometheus.Counter
+	lookupsMisses prometheus.Counter
+	storesStored  prometheus.Counter
+	storesSkipped prometheus.Counter
+}
+
+// NewDefaultNSECMiddleware registers the metrics of the aggressive NSEC
+// middleware in reg and returns a properly initialized *DefaultNSECMiddleware.
+func NewDefaultNSECMiddleware(
+	namespace string,
+	reg prometheus.Registerer,
+) (m *DefaultNSECMiddleware, err error) {
+	const (
+		lookupsTotal = "lookups_total"
+		storesTotal  = "stores_total"
+	)
+
+	lookupsTotalCounters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      lookupsTotal,
+		Namespace: namespace,
+		Subsystem: subsystemNSEC,
+		Help: "The total number of DNS queries checked against the cached NSEC " +
+			"and NSEC3 records.  Label hit is 1 if a negative response has been " +
+			"synthesized.",
+	}, []string{"hit"})
+
+	storesTotalCounters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      storesTotal,
+		Namespace: namespace,
+		Subsystem: subsystemNSEC,
+		Help: "The total number of upstream NXDOMAIN responses processed.  Label " +
+			"stored is 1 if their NSEC or NSEC3 records have been cached.",
+	}, []string{"stored"})
+
+	m = &DefaultNSECMiddleware{
+		lookupsHits:   lookupsTotalCounters.WithLabelValues("1"),
+		lookupsMisses: lookupsTotalCounters.WithLabelValues("0"),
+		storesStored:  storesTotalCounters.WithLabelValues("1"),
+		storesSkipped: storesTotalCounters.WithLabelValues("0"),
+	}
+
+	var errs []error
+	collectors := container.KeyValues[string, prometheus.Collector]{{
+		Key:   lookupsTotal,
+		Value: lookupsTotalCounters,
+	}, {
+		Key:   storesTotal,
+		Value: storesTotalCounters,
+	}}
+
+	for _, c := range collectors {
+		err = reg.Register(c.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("registering metrics %q: %w", c.Key, err))
+		}
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// type check
+var _ NSECMiddleware = (*DefaultNSECMiddleware)(nil)
+
+// OnLookup implements the [NSECMiddleware] interface for
+// *DefaultNSECMiddleware.
+func (m *DefaultNSECMiddleware) OnLookup(_ context.Context, hit bool) {
+	IncrementCond(hit, m.lookupsHits, m.lookupsMisses)
+}
+
+// OnStore implements the [NSECMiddleware] interface for
+// *DefaultNSECMiddleware.
+func (m *DefaultNSECMiddleware) OnStore(_ context.Context, stored bool) {
+	IncrementCond(stored, m.storesStored, m.storesSkipped)
+}
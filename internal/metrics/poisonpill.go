@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoisonPill is the Prometheus-based implementation of the
+// [poisonpill.Metrics] interface.
+type PoisonPill struct {
+	// panicsTotal is the counter of the recovered panics caused by the
+	// processing of DNS queries.
+	panicsTotal prometheus.Counter
+
+	// dropRulesTotal is the counter of the added drop rules.
+	dropRulesTotal prometheus.Counter
+
+	// droppedTotal is the counter of the DNS queries dropped by the drop
+	// rules.
+	droppedTotal prometheus.Counter
+}
+
+// NewPoisonPill registers the poison-pill detection metrics in reg and returns
+// a properly initialized *PoisonPill.
+func NewPoisonPill(namespace string, reg prometheus.Registerer) (m *PoisonPill, err error) {
+	const (
+		panicsTotal    = "panics_total"
+		dropRulesTotal = "drop_rules_total"
+		droppedTotal   = "dropped_total"
+	)
+
+	m = &PoisonPill{
+		panicsTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:      panicsTotal,
+			Namespace: namespace,
+			Subsystem: subsystemPoisonPill,
+			Help:      "The total number of recovered panics caused by DNS queries.",
+		}),
+		dropRulesTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:      dropRulesTotal,
+			Namespace: namespace,
+			Subsystem: subsystemPoisonPill,
+			Help:      "The total number of added short-term drop rules.",
+		}),
+		droppedTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:      droppedTotal,
+			Namespace: namespace,
+			Subsystem: subsystemPoisonPill,
+			Help:      "The total number of DNS queries dropped by the drop rules.",
+		}),
+	}
+
+	var errs []error
+	collectors := container.KeyValues[string, prometheus.Collector]{{
+		Key:   panicsTotal,
+		Value: m.panicsTotal,
+	}, {
+		Key:   dropRulesTotal,
+		Value: m.dropRulesTotal,
+	}, {
+		Key:   droppedTotal,
+		Value: m.droppedTotal,
+	}}
+
+	for _, c := range collectors {
+		err = reg.Register(c.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("registering metrics %q: %w", c.Key, err))
+		}
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// IncrementPanics implements the [poisonpill.Metrics] interface for
+// *PoisonPill.
+func (m *PoisonPill) IncrementPanics(_ context.Context) {
+	m.panicsTotal.Inc()
+}
+
+// IncrementDropRules implements the [poisonpill.Metrics] interface for
+// *PoisonPill.
+func (m *PoisonPill) IncrementDropRules(_ context.Context) {
+	m.dropRulesTotal.Inc()
+}
+
+// IncrementDropped implements the [poisonpill.Metrics] interface for
+// *PoisonPill.
+func (m *PoisonPill) IncrementDropped(_ context.Context) {
+	m.droppedTotal.Inc()
+}
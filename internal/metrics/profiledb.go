@@ -47,6 +47,15 @@ type ProfileDB struct {
 	// TODO(d.kolyshev): Add a metric for deleted devices.
 	profilesDeletedTotal prometheus.Counter
 
+	// profilesPrunedTotal is a counter with the total number of user profiles
+	// marked as deleted which have been removed by the garbage collection.
+	profilesPrunedTotal prometheus.Counter
+
+	// devicesPrunedTotal is a counter with the total number of user devices
+	// not belonging to any profile which have been removed by the garbage
+	// collection.
+	devicesPrunedTotal prometheus.Counter
+
 	// profilesSyncTime is a gauge with the timestamp when the profiles were
 	// synced last time.
 	profilesSyncTime prometheus.Gauge
@@ -69,6 +78,22 @@ type ProfileDB struct {
 	// profilesSyncPartTimeouts is a gauge with the total number of timeout
 	// errors occurred during partial profiles sync.
 	profilesSyncPartTimeouts prometheus.Gauge
+
+	// humanIDCacheHits is a counter with the total number of lookups of
+	// devices by human-readable IDs found in the cache.
+	humanIDCacheHits prometheus.Counter
+
+	// humanIDCacheMisses is a counter with the total number of lookups of
+	// devices by human-readable IDs not found in the cache.
+	humanIDCacheMisses prometheus.Counter
+
+	// humanIDCacheNegativeHits is a counter with the total number of failed
+	// automatic device creations found in the cache.
+	humanIDCacheNegativeHits prometheus.Counter
+
+	// humanIDCacheNegativeMisses is a counter with the total number of failed
+	// automatic device creations not found in the cache.
+	humanIDCacheNegativeMisses prometheus.Counter
 }
 
 // NewProfileDB registers the user profiles metrics in reg and returns a
@@ -77,14 +102,17 @@ func NewProfileDB(namespace string, reg prometheus.Registerer) (m *ProfileDB, er
 	const (
 		devicesCount             = "devices_total"
 		devicesNewCount          = "devices_newly_synced_total"
+		devicesPrunedTotal       = "devices_pruned_total"
 		profilesCount            = "profiles_total"
 		profilesNewCount         = "profiles_newly_synced_total"
 		profilesDeletedTotal     = "profiles_deleted_total"
+		profilesPrunedTotal      = "profiles_pruned_total"
 		profilesSyncTime         = "profiles_sync_timestamp"
 		profilesSyncStatus       = "profiles_sync_status"
 		profilesSyncDuration     = "profiles_sync_duration_seconds"
 		profilesFullSyncDuration = "profiles_full_sync_duration_seconds"
 		profilesSyncTimeouts     = "profiles_sync_timeouts_total"
+		humanIDCacheLookups      = "devices_human_id_cache_lookups_total"
 	)
 
 	// profilesSyncTimeoutsGaugeVec is a gauge with the total number of timeout
@@ -96,6 +124,17 @@ func NewProfileDB(namespace string, reg prometheus.Registerer) (m *ProfileDB, er
 		Help:      "The total number of timeout errors during profiles sync.",
 	}, []string{"is_full_sync"})
 
+	// humanIDCacheLookupsCounterVec is a counter with the total number of
+	// lookups in the cache of devices by human-readable IDs.
+	humanIDCacheLookupsCounterVec := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      humanIDCacheLookups,
+		Namespace: namespace,
+		Subsystem: subsystemBackend,
+		Help: "The total number of lookups in the cache of devices by human-readable IDs. " +
+			"Label hit is the lookup result, either 1 for hit or 0 for miss. " +
+			"Label negative is 1 for lookups of failed automatic device creations.",
+	}, []string{"hit", "negative"})
+
 	m = &ProfileDB{
 		devicesCount: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name:      devicesCount,
@@ -129,6 +168,19 @@ func NewProfileDB(namespace string, reg prometheus.Registerer) (m *ProfileDB, er
 			Namespace: namespace,
 			Help:      "The total number of deleted user profiles loaded from the backend.",
 		}),
+		profilesPrunedTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:      profilesPrunedTotal,
+			Subsystem: subsystemBackend,
+			Namespace: namespace,
+			Help:      "The total number of deleted user profiles removed from the memory.",
+		}),
+		devicesPrunedTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:      devicesPrunedTotal,
+			Subsystem: subsystemBackend,
+			Namespace: namespace,
+			Help: "The total number of user devices not belonging to any profile " +
+				"removed from the memory.",
+		}),
 		profilesSyncTime: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name:      profilesSyncTime,
 			Subsystem: subsystemBackend,
@@ -162,6 +214,10 @@ func NewProfileDB(namespace string, reg prometheus.Registerer) (m *ProfileDB, er
 		profilesSyncPartTimeouts: profilesSyncTimeoutsGaugeVec.With(prometheus.Labels{
 			"is_full_sync": "0",
 		}),
+		humanIDCacheHits:           humanIDCacheLookupsCounterVec.WithLabelValues("1", "0"),
+		humanIDCacheMisses:         humanIDCacheLookupsCounterVec.WithLabelValues("0", "0"),
+		humanIDCacheNegativeHits:   humanIDCacheLookupsCounterVec.WithLabelValues("1", "1"),
+		humanIDCacheNegativeMisses: humanIDCacheLookupsCounterVec.WithLabelValues("0", "1"),
 	}
 
 	collectors := container.KeyValues[string, prometheus.Collector]{{
@@ -179,6 +235,12 @@ func NewProfileDB(namespace string, reg prometheus.Registerer) (m *ProfileDB, er
 	}, {
 		Key:   profilesDeletedTotal,
 		Value: m.profilesDeletedTotal,
+	}, {
+		Key:   profilesPrunedTotal,
+		Value: m.profilesPrunedTotal,
+	}, {
+		Key:   devicesPrunedTotal,
+		Value: m.devicesPrunedTotal,
 	}, {
 		Key:   profilesSyncTime,
 		Value: m.profilesSyncTime,
@@ -194,6 +256,9 @@ func NewProfileDB(namespace string, reg prometheus.Registerer) (m *ProfileDB, er
 	}, {
 		Key:   profilesSyncTimeouts,
 		Value: profilesSyncTimeoutsGaugeVec,
+	}, {
+		Key:   humanIDCacheLookups,
+		Value: humanIDCacheLookupsCounterVec,
 	}}
 
 	var errs []error
@@ -254,6 +319,23 @@ func (m *ProfileDB) IncrementDeleted(_ context.Context) {
 	m.profilesDeletedTotal.Inc()
 }
 
+// IncrementPruned implements the [profilesdb.Metrics] interface for
+// *ProfileDB.
+func (m *ProfileDB) IncrementPruned(_ context.Context, profNum, devNum uint) {
+	m.profilesPrunedTotal.Add(float64(profNum))
+	m.devicesPrunedTotal.Add(float64(devNum))
+}
+
+// IncrementHumanIDCacheLookups implements the [profilesdb.Metrics] interface
+// for *ProfileDB.
+func (m *ProfileDB) IncrementHumanIDCacheLookups(_ context.Context, hit, isNegative bool) {
+	if isNegative {
+		IncrementCond(hit, m.humanIDCacheNegativeHits, m.humanIDCacheNegativeMisses)
+	} else {
+		IncrementCond(hit, m.humanIDCacheHits, m.humanIDCacheMisses)
+	}
+}
+
 // BackendProfileDB is the Prometheus-based implementation of the
 // [backendpb.ProfileDBMetrics] interface.
 type BackendProfileDB struct {
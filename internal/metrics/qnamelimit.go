@@ -0,0 +1,123 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// QNAMELimit contains the Prometheus metrics of the limits of the unique names
+// queried by the clients of all server groups.
+type QNAMELimit struct {
+	// limitedTotal is the counter of the clients that have exceeded the limit
+	// for a registrable domain.
+	limitedTotal *prometheus.CounterVec
+
+	// refusedTotal is the counter of the refused requests of the limited
+	// clients.
+	refusedTotal *prometheus.CounterVec
+
+	// clients is the gauge with the numbers of the tracked and the limited
+	// pairs of clients and registrable domains.
+	clients *prometheus.GaugeVec
+}
+
+// NewQNAMELimit registers the unique-name limit metrics in reg and returns a
+// properly initialized *QNAMELimit.
+func NewQNAMELimit(namespace string, reg prometheus.Registerer) (m *QNAMELimit, err error) {
+	const (
+		limitedTotal = "limited_total"
+		refusedTotal = "refused_total"
+		clients      = "clients"
+	)
+
+	m = &QNAMELimit{
+		limitedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      limitedTotal,
+			Namespace: namespace,
+			Subsystem: subsystemQNAMELimit,
+			Help: "The total number of times a client has exceeded the limit of " +
+				"unique names for a registrable domain.",
+		}, []string{"server_group"}),
+		refusedTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      refusedTotal,
+			Namespace: namespace,
+			Subsystem: subsystemQNAMELimit,
+			Help:      "The total number of refused DNS queries of the limited clients.",
+		}, []string{"server_group"}),
+		clients: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name:      clients,
+			Namespace: namespace,
+			Subsystem: subsystemQNAMELimit,
+			Help: "The number of pairs of clients and registrable domains.  " +
+				"Label state is either tracked or limited.",
+		}, []string{"server_group", "state"}),
+	}
+
+	var errs []error
+	collectors := container.KeyValues[string, prometheus.Collector]{{
+		Key:   limitedTotal,
+		Value: m.limitedTotal,
+	}, {
+		Key:   refusedTotal,
+		Value: m.refusedTotal,
+	}, {
+		Key:   clients,
+		Value: m.clients,
+	}}
+
+	for _, c := range collectors {
+		err = reg.Register(c.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("registering metrics %q: %w", c.Key, err))
+		}
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ForServerGroup returns the metrics of the limits of the server group with
+// the given name.
+func (m *QNAMELimit) ForServerGroup(name string) (gm *QNAMELimitGroup) {
+	return &QNAMELimitGroup{
+		limitedTotal: m.limitedTotal.WithLabelValues(name),
+		refusedTotal: m.refusedTotal.WithLabelValues(name),
+		tracked:      m.clients.WithLabelValues(name, "tracked"),
+		limited:      m.clients.WithLabelValues(name, "limited"),
+	}
+}
+
+// QNAMELimitGroup is the Prometheus-based implementation of the
+// [qnamelimit.Metrics] interface for a single server group.
+type QNAMELimitGroup struct {
+	limitedTotal prometheus.Counter
+	refusedTotal prometheus.Counter
+	tracked      prometheus.Gauge
+	limited      prometheus.Gauge
+}
+
+// OnLimited implements the [qnamelimit.Metrics] interface for
+// *QNAMELimitGroup.
+func (m *QNAMELimitGroup) OnLimited(_ context.Context) {
+	m.limitedTotal.Inc()
+}
+
+// OnRefused implements the [qnamelimit.Metrics] interface for
+// *QNAMELimitGroup.
+func (m *QNAMELimitGroup) OnRefused(_ context.Context) {
+	m.refusedTotal.Inc()
+}
+
+// SetClients implements the [qnamelimit.Metrics] interface for
+// *QNAMELimitGroup.
+func (m *QNAMELimitGroup) SetClients(_ context.Context, tracked, limited uint) {
+	m.tracked.Set(float64(tracked))
+	m.limited.Set(float64(limited))
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Quarantine is the Prometheus-based implementation of the
+// [quarantine.Metrics] interface.
+type Quarantine struct {
+	// allowedTotal is the counter of the requests of the quarantined devices
+	// for the remediation domains.
+	allowedTotal prometheus.Counter
+
+	// blockedTotal is the counter of the blocked requests of the quarantined
+	// devices.
+	blockedTotal prometheus.Counter
+
+	// devices is the gauge with the number of the devices quarantined using
+	// the debug API.
+	devices prometheus.Gauge
+}
+
+// NewQuarantine registers the quarantine metrics in reg and returns a properly
+// initialized *Quarantine.
+func NewQuarantine(namespace string, reg prometheus.Registerer) (m *Quarantine, err error) {
+	const (
+		requestsTotal = "requests_total"
+		devices       = "devices"
+	)
+
+	requestsTotalCounters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      requestsTotal,
+		Namespace: namespace,
+		Subsystem: subsystemQuarantine,
+		Help: "The total number of DNS queries of the quarantined devices.  " +
+			"Label action is either allowed or blocked.",
+	}, []string{"action"})
+
+	m = &Quarantine{
+		allowedTotal: requestsTotalCounters.WithLabelValues("allowed"),
+		blockedTotal: requestsTotalCounters.WithLabelValues("blocked"),
+		devices: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:      devices,
+			Namespace: namespace,
+			Subsystem: subsystemQuarantine,
+			Help:      "The number of devices quarantined using the debug API.",
+		}),
+	}
+
+	var errs []error
+	collectors := container.KeyValues[string, prometheus.Collector]{{
+		Key:   requestsTotal,
+		Value: requestsTotalCounters,
+	}, {
+		Key:   devices,
+		Value: m.devices,
+	}}
+
+	for _, c := range collectors {
+		err = reg.Register(c.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("registering metrics %q: %w", c.Key, err))
+		}
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// OnRequest implements the [quarantine.Metrics] interface for *Quarantine.
+func (m *Quarantine) OnRequest(_ context.Context, allowed bool) {
+	if allowed {
+		m.allowedTotal.Inc()
+	} else {
+		m.blockedTotal.Inc()
+	}
+}
+
+// SetDevicesNum implements the [quarantine.Metrics] interface for *Quarantine.
+func (m *Quarantine) SetDevicesNum(_ context.Context, n uint) {
+	m.devices.Set(float64(n))
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// QUICAdvisor is the Prometheus-based implementation of the
+// [quicadvisor.Metrics] interface.
+type QUICAdvisor struct {
+	// handshakesSuccess is the counter of the completed QUIC handshakes.
+	handshakesSuccess prometheus.Counter
+
+	// handshakesFailure is the counter of the QUIC connections closed before
+	// their handshakes were completed.
+	handshakesFailure prometheus.Counter
+
+	// blockedTotal is the counter of the times a client network has been
+	// considered to block QUIC.
+	blockedTotal prometheus.Counter
+}
+
+// NewQUICAdvisor registers the QUIC advisor metrics in reg and returns a
+// properly initialized *QUICAdvisor.
+func NewQUICAdvisor(namespace string, reg prometheus.Registerer) (m *QUICAdvisor, err error) {
+	const (
+		handshakesTotal = "handshakes_total"
+		blockedTotal    = "blocked_networks_total"
+	)
+
+	handshakesTotalCounters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      handshakesTotal,
+		Namespace: namespace,
+		Subsystem: subsystemQUICAdvisor,
+		Help: "The total number of observed QUIC handshakes.  " +
+			"Label success is 1 if the handshake has been completed.",
+	}, []string{"success"})
+
+	m = &QUICAdvisor{
+		handshakesSuccess: handshakesTotalCounters.WithLabelValues("1"),
+		handshakesFailure: handshakesTotalCounters.WithLabelValues("0"),
+		blockedTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:      blockedTotal,
+			Namespace: namespace,
+			Subsystem: subsystemQUICAdvisor,
+			Help:      "The total number of times a client network has been considered to block QUIC.",
+		}),
+	}
+
+	var errs []error
+	collectors := container.KeyValues[string, prometheus.Collector]{{
+		Key:   handshakesTotal,
+		Value: handshakesTotalCounters,
+	}, {
+		Key:   blockedTotal,
+		Value: m.blockedTotal,
+	}}
+
+	for _, c := range collectors {
+		err = reg.Register(c.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("registering metrics %q: %w", c.Key, err))
+		}
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// OnHandshake implements the [quicadvisor.Metrics] interface for *QUICAdvisor.
+func (m *QUICAdvisor) OnHandshake(_ context.Context, ok bool) {
+	IncrementCond(ok, m.handshakesSuccess, m.handshakesFailure)
+}
+
+// IncrementBlocked implements the [quicadvisor.Metrics] interface for
+// *QUICAdvisor.
+func (m *QUICAdvisor) IncrementBlocked(_ context.Context) {
+	m.blockedTotal.Inc()
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/ratelimit"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DefaultRatelimitBackoff is the Prometheus-based implementation of the
+// [ratelimit.BackoffMetrics] interface.
+type DefaultRatelimitBackoff struct {
+	limitedBandwidth prometheus.Counter
+	limitedQPS       prometheus.Counter
+}
+
+// NewDefaultRatelimitBackoff registers the metrics of the backoff rate limiter
+// in reg and returns a properly initialized *DefaultRatelimitBackoff.
+func NewDefaultRatelimitBackoff(
+	namespace string,
+	reg prometheus.Registerer,
+) (m *DefaultRatelimitBackoff, err error) {
+	const limitedTotal = "limited_total"
+
+	limitedTotalCounters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      limitedTotal,
+		Namespace: namespace,
+		Subsystem: subsystemRateLimit,
+		Help: "The total number of DNS queries that have gone above a limit of the " +
+			"backoff rate limiter.  Label reason is the limit that has been exceeded.",
+	}, []string{"reason"})
+
+	err = reg.Register(limitedTotalCounters)
+	if err != nil {
+		return nil, fmt.Errorf("registering metrics %q: %w", limitedTotal, err)
+	}
+
+	return &DefaultRatelimitBackoff{
+		limitedBandwidth: limitedTotalCounters.WithLabelValues(
+			string(ratelimit.LimitReasonBandwidth),
+		),
+		limitedQPS: limitedTotalCounters.WithLabelValues(string(ratelimit.LimitReasonQPS)),
+	}, nil
+}
+
+// type check
+var _ ratelimit.BackoffMetrics = (*DefaultRatelimitBackoff)(nil)
+
+// OnLimited implements the [ratelimit.BackoffMetrics] interface for
+// *DefaultRatelimitBackoff.
+func (m *DefaultRatelimitBackoff) OnLimited(_ context.Context, reason ratelimit.LimitReason) {
+	switch reason {
+	case ratelimit.LimitReasonBandwidth:
+		m.limitedBandwidth.Inc()
+	case ratelimit.LimitReasonQPS:
+		m.limitedQPS.Inc()
+	default:
+		panic(fmt.Errorf("reason: %w: %q", errors.ErrBadEnumValue, reason))
+	}
+}
@@ -22,9 +22,21 @@ type RedisKV struct {
 	errors prometheus.Counter
 }
 
-// NewRedisKV registers the Redis KV metrics in reg and returns a properly
-// initialized [RedisKV].
+// NewRedisKV registers the Redis KV metrics of the DNS checker in reg and
+// returns a properly initialized [RedisKV].
 func NewRedisKV(namespace string, reg prometheus.Registerer) (m *RedisKV, err error) {
+	return newRedisKV(namespace, subsystemDNSCheck, reg)
+}
+
+// NewSharedCounterRedisKV registers the Redis KV metrics of the shared
+// ratelimit counters in reg and returns a properly initialized [RedisKV].
+func NewSharedCounterRedisKV(namespace string, reg prometheus.Registerer) (m *RedisKV, err error) {
+	return newRedisKV(namespace, subsystemRateLimit, reg)
+}
+
+// newRedisKV registers the Redis KV metrics with the given subsystem in reg and
+// returns a properly initialized [RedisKV].
+func newRedisKV(namespace, subsystem string, reg prometheus.Registerer) (m *RedisKV, err error) {
 	const (
 		redisActiveConnections = "redis_active_connections"
 		redisErrors            = "redis_errors_total"
@@ -33,13 +45,13 @@ func NewRedisKV(namespace string, reg prometheus.Registerer) (m *RedisKV, err er
 	m = &RedisKV{
 		activeConnections: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name:      redisActiveConnections,
-			Subsystem: subsystemDNSCheck,
+			Subsystem: subsystem,
 			Namespace: namespace,
 			Help:      "Total number of active connections in redis pool",
 		}),
 		errors: prometheus.NewCounter(prometheus.CounterOpts{
 			Name:      redisErrors,
-			Subsystem: subsystemDNSCheck,
+			Subsystem: subsystem,
 			Namespace: namespace,
 			Help:      "Total number of errors encountered with redis pool",
 		}),
@@ -29,4 +29,12 @@ var (
 		Subsystem: subsystemRuleStat,
 		Help:      "Time when stats were uploaded last time.",
 	})
+	// RuleStatPendingWindows is a gauge with the number of the aggregation
+	// windows that haven't been uploaded yet.
+	RuleStatPendingWindows = promauto.NewGauge(prometheus.GaugeOpts{
+		Name:      "stats_pending_windows",
+		Namespace: namespace,
+		Subsystem: subsystemRuleStat,
+		Help:      "Number of stats windows not yet uploaded.",
+	})
 )
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/syncutil"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ShadowMiddleware is an interface for collection of the statistics of the
+// request-shadowing middleware.
+//
+// NOTE:  Keep in sync with [dnssvc.ShadowMiddlewareMetrics].
+type ShadowMiddleware interface {
+	IncrementSkipped(ctx context.Context)
+	OnShadowed(ctx context.Context, divergence string)
+}
+
+// DefaultShadowMiddleware is the Prometheus-based implementation of the
+// [ShadowMiddleware] interface.
+type DefaultShadowMiddleware struct {
+	shadowedTotalCounters *syncutil.OnceConstructor[string, prometheus.Counter]
+	skippedTotal          prometheus.Counter
+}
+
+// NewDefaultShadowMiddleware registers the metrics of the request-shadowing
+// middleware in reg and returns a properly initialized
+// *DefaultShadowMiddleware.
+func NewDefaultShadowMiddleware(
+	namespace string,
+	reg prometheus.Registerer,
+) (m *DefaultShadowMiddleware, err error) {
+	const (
+		shadowedTotal = "queries_total"
+		skippedTotal  = "skipped_total"
+	)
+
+	shadowedTotalCounters := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      shadowedTotal,
+		Namespace: namespace,
+		Subsystem: subsystemShadow,
+		Help: "The total number of mirrored DNS queries by the divergence of " +
+			"the shadow response from the primary one.",
+	}, []string{"divergence"})
+
+	m = &DefaultShadowMiddleware{
+		shadowedTotalCounters: syncutil.NewOnceConstructor(
+			func(divergence string) (c prometheus.Counter) {
+				return shadowedTotalCounters.WithLabelValues(divergence)
+			},
+		),
+
+		skippedTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name:      skippedTotal,
+			Namespace: namespace,
+			Subsystem: subsystemShadow,
+			Help:      "The total number of DNS queries not mirrored due to the limit.",
+		}),
+	}
+
+	var errs []error
+	collectors := container.KeyValues[string, prometheus.Collector]{{
+		Key:   shadowedTotal,
+		Value: shadowedTotalCounters,
+	}, {
+		Key:   skippedTotal,
+		Value: m.skippedTotal,
+	}}
+
+	for _, c := range collectors {
+		err = reg.Register(c.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("registering metrics %q: %w", c.Key, err))
+		}
+	}
+
+	if err = errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// type check
+var _ ShadowMiddleware = (*DefaultShadowMiddleware)(nil)
+
+// IncrementSkipped implements the [ShadowMiddleware] interface for
+// *DefaultShadowMiddleware.
+func (m *DefaultShadowMiddleware) IncrementSkipped(_ context.Context) {
+	m.skippedTotal.Inc()
+}
+
+// OnShadowed implements the [ShadowMiddleware] interface for
+// *DefaultShadowMiddleware.
+func (m *DefaultShadowMiddleware) OnShadowed(_ context.Context, divergence string) {
+	m.shadowedTotalCounters.Get(divergence).Inc()
+}
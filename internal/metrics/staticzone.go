@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// StaticZone is the Prometheus-based implementation of the
+// [staticzone.Metrics] interface.
+type StaticZone struct {
+	// zonemdVerificationsTotal is the counter of the ZONEMD verifications of
+	// the static zone files.
+	zonemdVerificationsTotal *prometheus.CounterVec
+}
+
+// NewStaticZone registers the static zone metrics in reg and returns a
+// properly initialized *StaticZone.
+func NewStaticZone(namespace string, reg prometheus.Registerer) (m *StaticZone, err error) {
+	const zonemdVerificationsTotal = "zonemd_verifications_total"
+
+	m = &StaticZone{
+		zonemdVerificationsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      zonemdVerificationsTotal,
+			Namespace: namespace,
+			Subsystem: subsystemStaticZone,
+			Help: "The total number of ZONEMD verifications of the static zone " +
+				"files by zone apex and result.",
+		}, []string{"zone", "result"}),
+	}
+
+	err = reg.Register(m.zonemdVerificationsTotal)
+	if err != nil {
+		return nil, fmt.Errorf("registering metrics %q: %w", zonemdVerificationsTotal, err)
+	}
+
+	return m, nil
+}
+
+// OnZONEMDVerification implements the [staticzone.Metrics] interface for
+// *StaticZone.
+func (m *StaticZone) OnZONEMDVerification(_ context.Context, zone, result string) {
+	m.zonemdVerificationsTotal.WithLabelValues(zone, result).Inc()
+}
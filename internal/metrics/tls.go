@@ -25,6 +25,10 @@ type TLSConfig struct {
 	// expires.
 	certificateNotAfter *prometheus.GaugeVec
 
+	// certificateChoicesTotal is a counter with the total number of handshakes
+	// by the public key algorithm of the chosen certificate.
+	certificateChoicesTotal *prometheus.CounterVec
+
 	// sessionTicketsRotateStatus is a gauge with the status of the last tickets
 	// rotation.
 	sessionTicketsRotateStatus prometheus.Gauge
@@ -33,6 +37,15 @@ type TLSConfig struct {
 	// tickets were rotated.
 	sessionTicketsRotateTime prometheus.Gauge
 
+	// sessionTicketsKeysHash is a gauge with the hash of the TLS session ticket
+	// keys in use.  It should be the same on all nodes of a server group.
+	sessionTicketsKeysHash prometheus.Gauge
+
+	// sessionTicketsDivergence is a gauge with the number of seconds for which
+	// the TLS session ticket keys in use have differed from the ones of the
+	// other nodes.
+	sessionTicketsDivergence prometheus.Gauge
+
 	// handshakeAttemptsTotal is a counter with the total number of attempts to
 	// establish a TLS connection.  "supported_protos" is a comma-separated list
 	// of the protocols supported by the client.
@@ -48,8 +61,11 @@ func NewTLSConfig(namespace string, reg prometheus.Registerer) (m *TLSConfig, er
 	const (
 		certInfo                = "cert_info"
 		certNotAfter            = "cert_not_after"
+		certChoicesTotal        = "cert_choices_total"
 		sessTicketsRotateStatus = "session_tickets_rotate_status"
 		sessTicketsRotateTime   = "session_tickets_rotate_time"
+		sessTicketsKeysHash     = "session_tickets_keys_hash"
+		sessTicketsDivergence   = "session_tickets_divergence_seconds"
 		handshakeAttemptsTotal  = "handshake_attempts_total"
 		handshakeTotal          = "handshake_total"
 	)
@@ -67,6 +83,12 @@ func NewTLSConfig(namespace string, reg prometheus.Registerer) (m *TLSConfig, er
 			Subsystem: subsystemTLS,
 			Help:      "Time when the certificate expires.",
 		}, []string{"subject"}),
+		certificateChoicesTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name:      certChoicesTotal,
+			Namespace: namespace,
+			Subsystem: subsystemTLS,
+			Help:      "Total count of handshakes by the key algorithm of the chosen certificate.",
+		}, []string{"auth_algo"}),
 		sessionTicketsRotateStatus: prometheus.NewGauge(prometheus.GaugeOpts{
 			Name:      sessTicketsRotateStatus,
 			Namespace: namespace,
@@ -79,6 +101,19 @@ func NewTLSConfig(namespace string, reg prometheus.Registerer) (m *TLSConfig, er
 			Subsystem: subsystemTLS,
 			Help:      "Time when the TLS session tickets were rotated.",
 		}),
+		sessionTicketsKeysHash: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:      sessTicketsKeysHash,
+			Namespace: namespace,
+			Subsystem: subsystemTLS,
+			Help:      "Hash of the TLS session ticket keys in use.",
+		}),
+		sessionTicketsDivergence: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name:      sessTicketsDivergence,
+			Namespace: namespace,
+			Subsystem: subsystemTLS,
+			Help: "Number of seconds for which the TLS session ticket keys " +
+				"have differed from the ones of the other nodes.",
+		}),
 		handshakeAttemptsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
 			Name:      handshakeAttemptsTotal,
 			Namespace: namespace,
@@ -111,12 +146,21 @@ func NewTLSConfig(namespace string, reg prometheus.Registerer) (m *TLSConfig, er
 	}, {
 		Key:   certNotAfter,
 		Value: m.certificateNotAfter,
+	}, {
+		Key:   certChoicesTotal,
+		Value: m.certificateChoicesTotal,
 	}, {
 		Key:   sessTicketsRotateStatus,
 		Value: m.sessionTicketsRotateStatus,
 	}, {
 		Key:   sessTicketsRotateTime,
 		Value: m.sessionTicketsRotateTime,
+	}, {
+		Key:   sessTicketsKeysHash,
+		Value: m.sessionTicketsKeysHash,
+	}, {
+		Key:   sessTicketsDivergence,
+		Value: m.sessionTicketsDivergence,
 	}, {
 		Key:   handshakeAttemptsTotal,
 		Value: m.handshakeAttemptsTotal,
@@ -208,6 +252,12 @@ func (m *TLSConfig) SetCertificateInfo(_ context.Context, algo, subj string, not
 	}).Set(float64(notAfter.Unix()))
 }
 
+// IncrementCertificateChoices implements the [tlsconfig.Metrics] interface for
+// *TLSConfig.
+func (m *TLSConfig) IncrementCertificateChoices(_ context.Context, algo string) {
+	m.certificateChoicesTotal.WithLabelValues(algo).Inc()
+}
+
 // SetSessionTicketRotationStatus implements the [tlsconfig.Metrics] interface
 // for *TLSConfig.
 func (m *TLSConfig) SetSessionTicketRotationStatus(_ context.Context, enabled bool) {
@@ -221,6 +271,18 @@ func (m *TLSConfig) SetSessionTicketRotationStatus(_ context.Context, enabled bo
 	m.sessionTicketsRotateTime.SetToCurrentTime()
 }
 
+// SetSessionTicketKeysHash implements the [tlsconfig.Metrics] interface for
+// *TLSConfig.
+func (m *TLSConfig) SetSessionTicketKeysHash(_ context.Context, hash uint32) {
+	m.sessionTicketsKeysHash.Set(float64(hash))
+}
+
+// SetSessionTicketDivergence implements the [tlsconfig.Metrics] interface for
+// *TLSConfig.
+func (m *TLSConfig) SetSessionTicketDivergence(_ context.Context, d time.Duration) {
+	m.sessionTicketsDivergence.Set(d.Seconds())
+}
+
 // tlsVersionToString converts TLS version to string.
 func tlsVersionToString(ver uint16) (tlsVersion string) {
 	switch ver {
@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"container/heap"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// topASNShardsNum is the number of shards of a [topASNCounter].  Each shard
+// has its own lock, which reduces the contention between the requests from
+// different ASNs.
+const topASNShardsNum = 16
+
+// topASNCounter is a counter of requests per AS number, which only keeps the
+// series of the ASNs with the most requests to bound the cardinality of the
+// metric.  The ASNs are split between [topASNShardsNum] shards by their
+// numbers, and each shard uses the Space-Saving algorithm: once all of its
+// slots are taken, a new ASN replaces the one with the lowest count and
+// inherits that count, and the series of the replaced ASN is deleted.
+//
+// The inherited count is only an estimate used to decide which ASNs to keep,
+// so it isn't added to the series of the new ASN.  Thus, the series of an ASN
+// that has been evicted and then got back into the top starts at one again,
+// which Prometheus treats as a counter reset.
+type topASNCounter struct {
+	// counter is the counter vector with a single label, "asn".
+	counter *prometheus.CounterVec
+
+	// shards are the shards of the tracked ASNs.  The shard for an ASN is
+	// selected by the ASN modulo the number of shards.
+	shards [topASNShardsNum]*topASNShard
+}
+
+// newTopASNCounter returns a new *topASNCounter that keeps at most about limit
+// series of counter.  limit must be positive.
+func newTopASNCounter(counter *prometheus.CounterVec, limit int) (c *topASNCounter) {
+	c = &topASNCounter{
+		counter: counter,
+	}
+
+	shardLimit := max((limit+topASNShardsNum-1)/topASNShardsNum, 1)
+	for i := range c.shards {
+		c.shards[i] = &topASNShard{
+			mu:    &sync.Mutex{},
+			items: make(map[uint32]*topASNItem, shardLimit),
+			heap:  make(topASNHeap, 0, shardLimit),
+			limit: shardLimit,
+		}
+	}
+
+	return c
+}
+
+// record increments the counter for asn, if it is among the top ASNs.
+func (c *topASNCounter) record(asn uint32) {
+	s := c.shards[asn%topASNShardsNum]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	evicted, ok := s.record(asn)
+	if ok {
+		c.counter.DeleteLabelValues(strconv.FormatUint(uint64(evicted), 10))
+	}
+
+	c.counter.WithLabelValues(strconv.FormatUint(uint64(asn), 10)).Inc()
+}
+
+// topASNShard is a single shard of a [topASNCounter].
+type topASNShard struct {
+	// mu protects items and heap.
+	mu *sync.Mutex
+
+	// items are the currently tracked ASNs.
+	items map[uint32]*topASNItem
+
+	// heap is the min-heap of the tracked ASNs by their estimated counts.
+	heap topASNHeap
+
+	// limit is the maximum number of tracked ASNs in the shard.
+	limit int
+}
+
+// record increments the estimated count of asn and returns the ASN that it has
+// replaced, if any.  s.mu must be locked.
+func (s *topASNShard) record(asn uint32) (evicted uint32, ok bool) {
+	item, ok := s.items[asn]
+	switch {
+	case ok:
+		item.count++
+		heap.Fix(&s.heap, item.index)
+
+		return 0, false
+	case len(s.heap) < s.limit:
+		item = &topASNItem{
+			asn:   asn,
+			count: 1,
+		}
+
+		s.items[asn] = item
+		heap.Push(&s.heap, item)
+
+		return 0, false
+	default:
+		// Replace the ASN with the lowest count.
+		item = s.heap[0]
+		evicted = item.asn
+		delete(s.items, evicted)
+
+		item.asn = asn
+		item.count++
+		s.items[asn] = item
+		heap.Fix(&s.heap, item.index)
+
+		return evicted, true
+	}
+}
+
+// topASNItem is a single tracked ASN.
+type topASNItem struct {
+	// count is the estimated number of requests from the ASN.
+	count uint64
+
+	// index is the index of the item in the heap.
+	index int
+
+	// asn is the AS number.
+	asn uint32
+}
+
+// topASNHeap is a min-heap of the tracked ASNs by their estimated counts.
+type topASNHeap []*topASNItem
+
+// type check
+var _ heap.Interface = (*topASNHeap)(nil)
+
+// Len implements the [heap.Interface] interface for *topASNHeap.
+func (h *topASNHeap) Len() (n int) { return len(*h) }
+
+// Less implements the [heap.Interface] interface for *topASNHeap.
+func (h *topASNHeap) Less(i, j int) (less bool) { return (*h)[i].count < (*h)[j].count }
+
+// Swap implements the [heap.Interface] interface for *topASNHeap.
+func (h *topASNHeap) Swap(i, j int) {
+	s := *h
+	s[i], s[j] = s[j], s[i]
+	s[i].index = i
+	s[j].index = j
+}
+
+// Push implements the [heap.Interface] interface for *topASNHeap.  x must be a
+// *topASNItem.
+func (h *topASNHeap) Push(x any) {
+	item := x.(*topASNItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+// Pop implements the [heap.Interface] interface for *topASNHeap.
+func (h *topASNHeap) Pop() (x any) {
+	s := *h
+	n := len(s)
+	item := s[n-1]
+	s[n-1] = nil
+	*h = s[:n-1]
+
+	return item
+}
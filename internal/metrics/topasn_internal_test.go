@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopASNCounter_record(t *testing.T) {
+	t.Parallel()
+
+	// Use two slots per shard and the ASNs from the same shard.
+	const (
+		limit = 2 * topASNShardsNum
+
+		asn1 = 1
+		asn2 = asn1 + topASNShardsNum
+		asn3 = asn2 + topASNShardsNum
+
+		// otherASN is in a different shard.
+		otherASN = asn1 + 1
+	)
+
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "test_request_per_top_asn_total",
+	}, []string{"asn"})
+
+	c := newTopASNCounter(counter, limit)
+
+	for range 4 {
+		c.record(asn1)
+	}
+
+	for range 2 {
+		c.record(asn2)
+	}
+
+	assert.Equal(t, 2, promtestutil.CollectAndCount(counter))
+	assert.Equal(t, 4.0, promtestutil.ToFloat64(counter.WithLabelValues("1")))
+	assert.Equal(t, 2.0, promtestutil.ToFloat64(counter.WithLabelValues("17")))
+
+	// A new ASN replaces the one with the lowest count.
+	c.record(asn3)
+
+	assert.Equal(t, 2, promtestutil.CollectAndCount(counter))
+	assert.Equal(t, 4.0, promtestutil.ToFloat64(counter.WithLabelValues("1")))
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(counter.WithLabelValues("33")))
+
+	// The replaced ASN inherits the count, so the top one is kept.  The series
+	// of the returned ASN starts anew.
+	c.record(asn2)
+
+	assert.Equal(t, 2, promtestutil.CollectAndCount(counter))
+	assert.Equal(t, 4.0, promtestutil.ToFloat64(counter.WithLabelValues("1")))
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(counter.WithLabelValues("17")))
+
+	// ASNs from other shards don't replace the tracked ones.
+	c.record(otherASN)
+
+	assert.Equal(t, 3, promtestutil.CollectAndCount(counter))
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(counter.WithLabelValues("2")))
+	assert.Equal(t, 1.0, promtestutil.ToFloat64(counter.WithLabelValues("17")))
+}
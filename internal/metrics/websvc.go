@@ -37,6 +37,24 @@ var (
 		"kind": "dnscheck_test",
 	})
 
+	// WebSvcDeviceStatsRequestsTotal is a counter with total number of
+	// requests for the per-device statistics.
+	WebSvcDeviceStatsRequestsTotal = webSvcRequestsTotal.With(prometheus.Labels{
+		"kind": "device_stats",
+	})
+
+	// WebSvcDoHTestRequestsTotal is a counter with total number of requests
+	// for the DNS-over-HTTPS test page.
+	WebSvcDoHTestRequestsTotal = webSvcRequestsTotal.With(prometheus.Labels{
+		"kind": "doh_test",
+	})
+
+	// WebSvcUnblockRequestsTotal is a counter with total number of requests
+	// for the temporary unblocking from the block pages.
+	WebSvcUnblockRequestsTotal = webSvcRequestsTotal.With(prometheus.Labels{
+		"kind": "unblock",
+	})
+
 	// WebSvcRobotsTxtRequestsTotal is a counter with total number of
 	// requests for robots_txt.
 	WebSvcRobotsTxtRequestsTotal = webSvcRequestsTotal.With(prometheus.Labels{
@@ -49,6 +67,12 @@ var (
 		"kind": "root_redirect",
 	})
 
+	// WebSvcHTTPSRedirectRequestsTotal is a counter with total number of
+	// plain-HTTP requests redirected to HTTPS by the domain policies.
+	WebSvcHTTPSRedirectRequestsTotal = webSvcRequestsTotal.With(prometheus.Labels{
+		"kind": "https_redirect",
+	})
+
 	// WebSvcLinkedIPProxyRequestsTotal is a counter with total number of
 	// requests with linked ip.
 	WebSvcLinkedIPProxyRequestsTotal = webSvcRequestsTotal.With(prometheus.Labels{
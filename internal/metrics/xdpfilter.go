@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// XDPFilterDroppedTotal is a counter with the total number of packets from
+	// the banned subnets dropped by the XDP program in the kernel.
+	XDPFilterDroppedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name:      "dropped_total",
+		Namespace: namespace,
+		Subsystem: subsystemXDPFilter,
+		Help:      "The total number of packets dropped in the kernel.",
+	})
+
+	// XDPFilterBannedSubnets is a gauge with the current number of subnets
+	// banned in the kernel.
+	XDPFilterBannedSubnets = promauto.NewGauge(prometheus.GaugeOpts{
+		Name:      "banned_subnets",
+		Namespace: namespace,
+		Subsystem: subsystemXDPFilter,
+		Help:      "The current number of subnets banned in the kernel.",
+	})
+)
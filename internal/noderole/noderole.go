@@ -0,0 +1,249 @@
+// Package noderole contains the coordination of the roles of the paired nodes,
+// one of which actively serves the traffic while the other one is a hot
+// standby, for example in VRRP setups.
+package noderole
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/remotekv"
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// Role is the role of a node within a pair.
+type Role uint8
+
+// Valid roles.
+const (
+	RoleActive Role = iota + 1
+	RoleStandby
+)
+
+// Role string constants.
+const (
+	roleStrActive  = "active"
+	roleStrStandby = "standby"
+)
+
+// String implements the [fmt.Stringer] interface for Role.
+func (r Role) String() (s string) {
+	switch r {
+	case RoleActive:
+		return roleStrActive
+	case RoleStandby:
+		return roleStrStandby
+	default:
+		return fmt.Sprintf("!bad_role_%d", r)
+	}
+}
+
+// ErrBadRole is returned by [ParseRole] when the role is unknown.
+const ErrBadRole errors.Error = "bad role"
+
+// ParseRole returns the role from its string representation.
+func ParseRole(s string) (r Role, err error) {
+	switch s {
+	case roleStrActive:
+		return RoleActive, nil
+	case roleStrStandby:
+		return RoleStandby, nil
+	default:
+		return 0, fmt.Errorf("%w: %q", ErrBadRole, s)
+	}
+}
+
+// StandbySetter is a component of the node that changes its behavior when the
+// node becomes a standby or active one.
+type StandbySetter interface {
+	// SetStandby is called every time the role of the node changes.
+	SetStandby(standby bool)
+}
+
+// Config is the configuration structure for a *Manager.
+type Config struct {
+	// Logger is used to log the role changes.  It must not be nil.
+	Logger *slog.Logger
+
+	// ErrColl is used to collect the errors of the lease refreshes.  It must
+	// not be nil.
+	ErrColl errcoll.Interface
+
+	// KV, if not nil, is the storage shared by the nodes of the pair, in which
+	// the lease of the active role is kept.  The keys of the storage must
+	// expire after a TTL, which is the time after which the standby node takes
+	// over if the active one stops renewing the lease.  If KV is nil, the role
+	// is only changed using [Manager.SetRole].
+	KV remotekv.Interface
+
+	// LeaseKey is the key of the lease in KV.  It must not be empty if KV is
+	// not nil.
+	LeaseKey string
+
+	// NodeName is the name of this node, which is stored in the lease.  It
+	// must not be empty if KV is not nil.
+	NodeName string
+
+	// Setters are notified of the role changes.  Items must not be nil.
+	Setters []StandbySetter
+
+	// InitialRole is the role of the node before the first refresh.  It must
+	// be valid.
+	InitialRole Role
+}
+
+// Manager keeps and coordinates the role of the node.  It is safe for
+// concurrent use.
+//
+// When there is a shared storage, the node that holds the lease is the active
+// one.  The nodes don't preempt each other: a node only acquires the lease if
+// it is free, and a node can only be promoted while the other one is alive
+// using [Manager.SetRole].
+type Manager struct {
+	logger   *slog.Logger
+	errColl  errcoll.Interface
+	kv       remotekv.Interface
+	leaseKey string
+	nodeName string
+
+	// mu protects role and canAcquire.
+	mu *sync.Mutex
+
+	setters []StandbySetter
+	role    Role
+
+	// canAcquire is false if the node has been demoted manually and must not
+	// acquire the lease until it is promoted.
+	canAcquire bool
+}
+
+// New returns a new properly initialized *Manager.  c must be valid.  The
+// setters are notified of the initial role.
+func New(c *Config) (m *Manager) {
+	m = &Manager{
+		logger:     c.Logger,
+		errColl:    c.ErrColl,
+		kv:         c.KV,
+		setters:    c.Setters,
+		leaseKey:   c.LeaseKey,
+		nodeName:   c.NodeName,
+		mu:         &sync.Mutex{},
+		role:       c.InitialRole,
+		canAcquire: true,
+	}
+
+	m.notify()
+
+	return m
+}
+
+// Role returns the current role of the node.
+func (m *Manager) Role() (r Role) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.role
+}
+
+// IsHealthy returns false if the node is a standby one, so that the load
+// balancers and health checkers don't send the traffic to it.
+func (m *Manager) IsHealthy() (ok bool) {
+	return m.Role() == RoleActive
+}
+
+// SetRole switches the role of the node manually.  Promoting the node takes
+// over the lease from the other node, if there is a shared storage.  A demoted
+// node doesn't acquire the lease again until it is promoted.
+func (m *Manager) SetRole(ctx context.Context, r Role) (err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch r {
+	case RoleActive:
+		if m.kv != nil {
+			err = m.kv.Set(ctx, m.leaseKey, []byte(m.nodeName))
+			if err != nil {
+				return fmt.Errorf("taking over lease: %w", err)
+			}
+		}
+
+		m.canAcquire = true
+	case RoleStandby:
+		m.canAcquire = false
+	default:
+		return fmt.Errorf("%w: %d", ErrBadRole, r)
+	}
+
+	m.setRoleLocked(ctx, r)
+
+	return nil
+}
+
+// type check
+var _ agdservice.Refresher = (*Manager)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for *Manager.  It
+// acquires or renews the lease, if possible, and switches the role of the node
+// accordingly.  If the storage is unavailable, the role is not changed.
+func (m *Manager) Refresh(ctx context.Context) (err error) {
+	if m.kv == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	holder, ok, err := m.kv.Get(ctx, m.leaseKey)
+	if err != nil {
+		err = fmt.Errorf("getting lease: %w", err)
+		errcoll.Collect(ctx, m.errColl, m.logger, "refreshing node role", err)
+
+		return err
+	}
+
+	if ok && string(holder) != m.nodeName {
+		m.setRoleLocked(ctx, RoleStandby)
+
+		return nil
+	} else if !m.canAcquire {
+		return nil
+	}
+
+	err = m.kv.Set(ctx, m.leaseKey, []byte(m.nodeName))
+	if err != nil {
+		err = fmt.Errorf("renewing lease: %w", err)
+		errcoll.Collect(ctx, m.errColl, m.logger, "refreshing node role", err)
+
+		return err
+	}
+
+	m.setRoleLocked(ctx, RoleActive)
+
+	return nil
+}
+
+// setRoleLocked sets the role of the node and notifies the setters if it has
+// changed.  m.mu must be locked.
+func (m *Manager) setRoleLocked(ctx context.Context, r Role) {
+	if m.role == r {
+		return
+	}
+
+	m.logger.InfoContext(ctx, "switching role", "from", m.role, "to", r)
+
+	m.role = r
+	m.notify()
+}
+
+// notify notifies the setters of the current role.  m.mu must be locked or m
+// must not be used concurrently yet.
+func (m *Manager) notify() {
+	standby := m.role == RoleStandby
+	for _, s := range m.setters {
+		s.SetStandby(standby)
+	}
+}
@@ -0,0 +1,173 @@
+package noderole_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/noderole"
+	"github.com/AdguardTeam/AdGuardDNS/internal/remotekv"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// testLeaseKey is the common lease key for tests.
+const testLeaseKey = "noderole:pair"
+
+// standbySetter is a [noderole.StandbySetter] for tests.
+type standbySetter struct {
+	standby *atomic.Bool
+}
+
+// SetStandby implements the [noderole.StandbySetter] interface for
+// *standbySetter.
+func (s *standbySetter) SetStandby(standby bool) {
+	s.standby.Store(standby)
+}
+
+// newTestManager is a helper that returns a new manager with the given name
+// using kv as well as the setter it notifies.
+func newTestManager(
+	tb testing.TB,
+	kv remotekv.Interface,
+	name string,
+	initRole noderole.Role,
+) (m *noderole.Manager, s *standbySetter) {
+	tb.Helper()
+
+	s = &standbySetter{
+		standby: &atomic.Bool{},
+	}
+
+	m = noderole.New(&noderole.Config{
+		Logger:      slogutil.NewDiscardLogger(),
+		ErrColl:     agdtest.NewErrorCollector(),
+		KV:          kv,
+		Setters:     []noderole.StandbySetter{s},
+		LeaseKey:    testLeaseKey,
+		NodeName:    name,
+		InitialRole: initRole,
+	})
+
+	return m, s
+}
+
+func TestManager_Refresh(t *testing.T) {
+	t.Parallel()
+
+	cache := agdcache.NewLRU[string, []byte](&agdcache.LRUConfig{
+		Count: 10,
+	})
+	kv := remotekv.NewCache(&remotekv.CacheConfig{
+		Cache: cache,
+	})
+
+	first, firstSetter := newTestManager(t, kv, "first", noderole.RoleActive)
+	second, secondSetter := newTestManager(t, kv, "second", noderole.RoleStandby)
+
+	assert.False(t, firstSetter.standby.Load())
+	assert.True(t, secondSetter.standby.Load())
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, first.Refresh(ctx))
+	require.NoError(t, second.Refresh(ctx))
+
+	assert.True(t, first.IsHealthy())
+	assert.False(t, second.IsHealthy())
+
+	t.Run("promote", func(t *testing.T) {
+		require.NoError(t, second.SetRole(ctx, noderole.RoleActive))
+		require.NoError(t, first.Refresh(ctx))
+
+		assert.Equal(t, noderole.RoleStandby, first.Role())
+		assert.True(t, firstSetter.standby.Load())
+		assert.Equal(t, noderole.RoleActive, second.Role())
+		assert.False(t, secondSetter.standby.Load())
+	})
+
+	t.Run("demote", func(t *testing.T) {
+		require.NoError(t, second.SetRole(ctx, noderole.RoleStandby))
+
+		// Simulate the expiration of the lease.
+		cache.Clear()
+
+		require.NoError(t, second.Refresh(ctx))
+		require.NoError(t, first.Refresh(ctx))
+
+		assert.Equal(t, noderole.RoleActive, first.Role())
+		assert.Equal(t, noderole.RoleStandby, second.Role())
+	})
+
+	t.Run("takeover", func(t *testing.T) {
+		require.NoError(t, second.SetRole(ctx, noderole.RoleActive))
+		require.NoError(t, first.Refresh(ctx))
+
+		// Simulate the failure of the active node.
+		cache.Clear()
+
+		require.NoError(t, first.Refresh(ctx))
+
+		assert.Equal(t, noderole.RoleActive, first.Role())
+	})
+}
+
+func TestManager_SetRole_static(t *testing.T) {
+	t.Parallel()
+
+	m, s := newTestManager(t, nil, "", noderole.RoleStandby)
+	assert.False(t, m.IsHealthy())
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, m.Refresh(ctx))
+	assert.Equal(t, noderole.RoleStandby, m.Role())
+
+	require.NoError(t, m.SetRole(ctx, noderole.RoleActive))
+	assert.True(t, m.IsHealthy())
+	assert.False(t, s.standby.Load())
+
+	err := m.SetRole(ctx, 0)
+	assert.ErrorIs(t, err, noderole.ErrBadRole)
+}
+
+func TestParseRole(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		in         string
+		wantErrMsg string
+		name       string
+		want       noderole.Role
+	}{{
+		in:         "active",
+		wantErrMsg: "",
+		want:       noderole.RoleActive,
+		name:       "active",
+	}, {
+		in:         "standby",
+		wantErrMsg: "",
+		want:       noderole.RoleStandby,
+		name:       "standby",
+	}, {
+		in:         "leader",
+		wantErrMsg: `bad role: "leader"`,
+		want:       0,
+		name:       "bad",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := noderole.ParseRole(tc.in)
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, err)
+			assert.Equal(t, tc.want, r)
+		})
+	}
+}
@@ -0,0 +1,328 @@
+package poisonpill
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"maps"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/miekg/dns"
+)
+
+// Config is the configuration structure for [Guard].
+type Config struct {
+	// Logger is used to log the recovered panics and the added drop rules.  It
+	// must not be nil.
+	Logger *slog.Logger
+
+	// Clock is used to get the current time.  It must not be nil.
+	Clock agdtime.Clock
+
+	// ErrColl is used to collect the recovered panics.  It must not be nil.
+	ErrColl errcoll.Interface
+
+	// Metrics is used for the collection of the statistics of the guard.  It
+	// must not be nil.
+	Metrics Metrics
+
+	// Window is the period within which Threshold panics must be caused by
+	// the same query for a drop rule to be added.  It must be positive.
+	Window time.Duration
+
+	// DropDuration is the time for which the queries matching a drop rule are
+	// dropped.  It must be positive.
+	DropDuration time.Duration
+
+	// RecentCount is the number of the most recent records kept in memory for
+	// [Guard.Recent].  It must be positive.
+	RecentCount uint
+
+	// CacheSize is the maximum number of the queries the panic counters of
+	// which are kept.  It must be positive.
+	CacheSize int
+
+	// Threshold is the number of panics caused by the same query after which
+	// a drop rule is added for it.  It must be positive.
+	Threshold uint
+}
+
+// Guard is a [dnsserver.Middleware] that recovers the panics in the handlers,
+// records the queries that have caused them, and drops the queries that have
+// repeatedly caused them for some time.  It is safe for concurrent use.
+type Guard struct {
+	logger  *slog.Logger
+	clock   agdtime.Clock
+	errColl errcoll.Interface
+	metrics Metrics
+
+	// mu protects recent, pills, and drops.
+	mu *sync.Mutex
+
+	// recent are the records of the most recent panics.
+	recent *container.RingBuffer[*Record]
+
+	// pills are the panic counters of the queries.
+	pills agdcache.Interface[queryHash, *pillState]
+
+	// drops are the expiration times of the drop rules.
+	drops map[queryHash]time.Time
+
+	// dropsNum is the number of the drop rules.  It is used to avoid hashing
+	// the queries when there are no drop rules.
+	dropsNum *atomic.Int64
+
+	window       time.Duration
+	dropDuration time.Duration
+	threshold    uint
+}
+
+// queryHash is the SHA-256 hash of the wire format of a query with a zero
+// message ID.
+type queryHash = [sha256.Size]byte
+
+// pillState is the panic counter of a query.
+type pillState struct {
+	// first is the time of the first panic in the current window.
+	first time.Time
+
+	// panics is the number of panics in the current window.
+	panics uint
+}
+
+// New returns a new properly initialized *Guard.  c must be valid.
+func New(c *Config) (g *Guard) {
+	return &Guard{
+		logger:  c.Logger,
+		clock:   c.Clock,
+		errColl: c.ErrColl,
+		metrics: c.Metrics,
+		mu:      &sync.Mutex{},
+		recent:  container.NewRingBuffer[*Record](c.RecentCount),
+		pills: agdcache.NewLRU[queryHash, *pillState](&agdcache.LRUConfig{
+			Count: c.CacheSize,
+		}),
+		drops:        map[queryHash]time.Time{},
+		dropsNum:     &atomic.Int64{},
+		window:       c.Window,
+		dropDuration: c.DropDuration,
+		threshold:    c.Threshold,
+	}
+}
+
+// type check
+var _ dnsserver.Middleware = (*Guard)(nil)
+
+// Wrap implements the [dnsserver.Middleware] interface for *Guard.  If the
+// wrapped handler panics, the panic is recovered, and a non-nil error is
+// returned.
+func (g *Guard) Wrap(next dnsserver.Handler) (wrapped dnsserver.Handler) {
+	f := func(ctx context.Context, rw dnsserver.ResponseWriter, req *dns.Msg) (err error) {
+		if g.isDropped(ctx, req) {
+			g.metrics.IncrementDropped(ctx)
+
+			return nil
+		}
+
+		defer g.recoverPanic(ctx, req, &err)
+
+		return next.ServeDNS(ctx, rw, req)
+	}
+
+	return dnsserver.HandlerFunc(f)
+}
+
+// isDropped returns true if req matches an active drop rule.
+func (g *Guard) isDropped(ctx context.Context, req *dns.Msg) (ok bool) {
+	if g.dropsNum.Load() == 0 {
+		return false
+	}
+
+	wire, err := pack(req)
+	if err != nil {
+		optslog.Debug1(ctx, g.logger, "packing query", slogutil.KeyError, err)
+
+		return false
+	}
+
+	h := hashWire(wire)
+	now := g.clock.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	expires, ok := g.drops[h]
+	if !ok {
+		return false
+	}
+
+	if now.Before(expires) {
+		return true
+	}
+
+	delete(g.drops, h)
+	g.dropsNum.Store(int64(len(g.drops)))
+
+	return false
+}
+
+// recoverPanic recovers a panic, if there is one, records it, and sets errPtr
+// to the error describing it.  It must be called as a deferred function.
+func (g *Guard) recoverPanic(ctx context.Context, req *dns.Msg, errPtr *error) {
+	v := recover()
+	if v == nil {
+		return
+	}
+
+	g.metrics.IncrementPanics(ctx)
+
+	err := fmt.Errorf("poisonpill: recovered panic: %v", v)
+	*errPtr = err
+
+	g.logger.ErrorContext(ctx, "recovered panic", "stack", string(debug.Stack()))
+	errcoll.Collect(ctx, g.errColl, g.logger, "handling query", err)
+
+	wire, packErr := pack(req)
+	if packErr != nil {
+		// Don't add the drop rules for the queries that can't be packed, since
+		// these can't be matched anyway.
+		g.logger.WarnContext(ctx, "packing query", slogutil.KeyError, packErr)
+	}
+
+	g.record(ctx, wire, v)
+}
+
+// record adds the record of the panic caused by the query with wire format
+// wire and adds a drop rule for it, if necessary.
+func (g *Guard) record(ctx context.Context, wire []byte, v any) {
+	h := hashWire(wire)
+	now := g.clock.Now()
+
+	rec := &Record{
+		Time:  now,
+		Hash:  hex.EncodeToString(h[:]),
+		Panic: fmt.Sprint(v),
+	}
+
+	if wire != nil {
+		rec.Hexdump = hex.Dump(wire)
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.recent.Push(rec)
+
+	if wire == nil {
+		return
+	}
+
+	st, ok := g.pills.Get(h)
+	if !ok || now.Sub(st.first) > g.window {
+		st = &pillState{
+			first: now,
+		}
+
+		g.pills.Set(h, st)
+	}
+
+	st.panics++
+	if st.panics < g.threshold {
+		return
+	}
+
+	st.panics = 0
+	g.removeExpired(now)
+	g.drops[h] = now.Add(g.dropDuration)
+	g.dropsNum.Store(int64(len(g.drops)))
+
+	g.metrics.IncrementDropRules(ctx)
+	g.logger.WarnContext(ctx, "added drop rule", "hash", rec.Hash, "duration", g.dropDuration)
+}
+
+// removeExpired removes the expired drop rules.  g.mu must be locked.
+func (g *Guard) removeExpired(now time.Time) {
+	maps.DeleteFunc(g.drops, func(_ queryHash, expires time.Time) (del bool) {
+		return !now.Before(expires)
+	})
+}
+
+// Recent returns the records of the most recent panics, the oldest first.  The
+// records must not be modified.
+func (g *Guard) Recent() (recs []*Record) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	recs = make([]*Record, 0, g.recent.Len())
+	g.recent.Range(func(r *Record) (cont bool) {
+		recs = append(recs, r)
+
+		return true
+	})
+
+	return recs
+}
+
+// DropRules returns the active drop rules sorted by their expiration times.
+func (g *Guard) DropRules() (rules []*DropRule) {
+	now := g.clock.Now()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	rules = make([]*DropRule, 0, len(g.drops))
+	for h, expires := range g.drops {
+		if !now.Before(expires) {
+			continue
+		}
+
+		rules = append(rules, &DropRule{
+			Expires: expires,
+			Hash:    hex.EncodeToString(h[:]),
+		})
+	}
+
+	slices.SortFunc(rules, func(a, b *DropRule) (res int) {
+		return a.Expires.Compare(b.Expires)
+	})
+
+	return rules
+}
+
+// pack returns the wire format of req with a zero message ID.  It recovers the
+// panics in [dns.Msg.Pack], since the query may be the reason of the panic.
+func pack(req *dns.Msg) (wire []byte, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			wire, err = nil, fmt.Errorf("panic: %v", v)
+		}
+	}()
+
+	wire, err = req.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	// Zero the message ID, since the clients set it randomly.
+	wire[0], wire[1] = 0, 0
+
+	return wire, nil
+}
+
+// hashWire returns the hash of the wire format of a query.  wire may be nil.
+func hashWire(wire []byte) (h queryHash) {
+	return sha256.Sum256(wire)
+}
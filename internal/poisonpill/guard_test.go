@@ -0,0 +1,125 @@
+package poisonpill_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/dnsservertest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/poisonpill"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Configuration values for tests.
+const (
+	testWindow       = 1 * time.Minute
+	testDropDuration = 10 * time.Minute
+	testThreshold    = 2
+)
+
+// testPanicDomain is the domain name of the queries that cause panics in
+// tests.
+const testPanicDomain = "panic.example."
+
+// newTestGuard returns a new *poisonpill.Guard for tests that uses the time
+// from now.
+func newTestGuard(now *atomic.Pointer[time.Time]) (g *poisonpill.Guard) {
+	return poisonpill.New(&poisonpill.Config{
+		Logger: slogutil.NewDiscardLogger(),
+		Clock: &agdtest.Clock{
+			OnNow: func() (n time.Time) { return *now.Load() },
+		},
+		ErrColl: &agdtest.ErrorCollector{
+			OnCollect: func(_ context.Context, _ error) {},
+		},
+		Metrics:      poisonpill.EmptyMetrics{},
+		Window:       testWindow,
+		DropDuration: testDropDuration,
+		RecentCount:  2,
+		CacheSize:    100,
+		Threshold:    testThreshold,
+	})
+}
+
+func TestGuard(t *testing.T) {
+	t.Parallel()
+
+	now := &atomic.Pointer[time.Time]{}
+	start := time.Unix(1_000, 0)
+	now.Store(&start)
+
+	g := newTestGuard(now)
+
+	var calls atomic.Int64
+	h := g.Wrap(dnsserver.HandlerFunc(func(
+		ctx context.Context,
+		rw dnsserver.ResponseWriter,
+		req *dns.Msg,
+	) (err error) {
+		calls.Add(1)
+		if req.Question[0].Name == testPanicDomain {
+			panic("test panic")
+		}
+
+		return rw.WriteMsg(ctx, req, dnsservertest.NewResp(dns.RcodeSuccess, req))
+	}))
+
+	ctx := context.Background()
+	serve := func(name string, id uint16) (err error) {
+		req := dnsservertest.NewReq(name, dns.TypeA, dns.ClassINET)
+		req.Id = id
+
+		rw := dnsserver.NewNonWriterResponseWriter(nil, &net.UDPAddr{})
+
+		return h.ServeDNS(ctx, rw, req)
+	}
+
+	err := serve(testPanicDomain, 1)
+	require.Error(t, err)
+
+	recs := g.Recent()
+	require.Len(t, recs, 1)
+
+	assert.Equal(t, "test panic", recs[0].Panic)
+	assert.NotEmpty(t, recs[0].Hexdump)
+	assert.Empty(t, g.DropRules())
+
+	// A different message ID must not prevent the detection.
+	err = serve(testPanicDomain, 2)
+	require.Error(t, err)
+
+	rules := g.DropRules()
+	require.Len(t, rules, 1)
+
+	assert.Equal(t, recs[0].Hash, rules[0].Hash)
+	assert.Equal(t, start.Add(testDropDuration), rules[0].Expires)
+
+	calls.Store(0)
+
+	err = serve(testPanicDomain, 3)
+	require.NoError(t, err)
+
+	assert.Zero(t, calls.Load())
+
+	err = serve("example.org.", 4)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), calls.Load())
+
+	later := start.Add(testDropDuration)
+	now.Store(&later)
+
+	assert.Empty(t, g.DropRules())
+
+	err = serve(testPanicDomain, 5)
+	require.Error(t, err)
+
+	assert.Len(t, g.Recent(), 2)
+}
@@ -0,0 +1,69 @@
+// Package poisonpill contains the isolation of the panics caused by the
+// processing of single DNS queries and the detection of the queries that
+// repeatedly cause them.
+package poisonpill
+
+import (
+	"context"
+	"time"
+)
+
+// Record is the information about a DNS query the processing of which has
+// caused a panic.
+type Record struct {
+	// Time is the time of the panic.
+	Time time.Time `json:"time"`
+
+	// Hash is the hex-encoded SHA-256 hash of the wire format of the query with
+	// a zero message ID.
+	Hash string `json:"hash"`
+
+	// Panic is the string form of the value with which the handler panicked.
+	Panic string `json:"panic"`
+
+	// Hexdump is the hexdump of the wire format of the query.  It is empty if
+	// the query could not be packed.
+	Hexdump string `json:"hexdump"`
+}
+
+// DropRule is a short-term rule that drops the DNS queries that have
+// repeatedly caused panics.
+type DropRule struct {
+	// Expires is the time after which the rule is removed.
+	Expires time.Time `json:"expires"`
+
+	// Hash is the hex-encoded SHA-256 hash of the wire format of the dropped
+	// queries with a zero message ID.
+	Hash string `json:"hash"`
+}
+
+// Metrics is an interface for collection of the statistics of the poison-pill
+// detection.
+type Metrics interface {
+	// IncrementPanics increments the number of recovered panics caused by the
+	// processing of DNS queries.
+	IncrementPanics(ctx context.Context)
+
+	// IncrementDropRules increments the number of added drop rules.
+	IncrementDropRules(ctx context.Context)
+
+	// IncrementDropped increments the number of DNS queries dropped by the drop
+	// rules.
+	IncrementDropped(ctx context.Context)
+}
+
+// EmptyMetrics is an implementation of the [Metrics] interface that does
+// nothing.
+type EmptyMetrics struct{}
+
+// type check
+var _ Metrics = EmptyMetrics{}
+
+// IncrementPanics implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) IncrementPanics(_ context.Context) {}
+
+// IncrementDropRules implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) IncrementDropRules(_ context.Context) {}
+
+// IncrementDropped implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) IncrementDropped(_ context.Context) {}
@@ -0,0 +1,315 @@
+package popdomains
+
+import (
+	"cmp"
+	"context"
+	cryptorand "crypto/rand"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"golang.org/x/net/publicsuffix"
+)
+
+// DefaultConfig is the configuration structure for a *Default.
+type DefaultConfig struct {
+	// Logger is used to log the operation of the statistics.  It must not be
+	// nil.
+	Logger *slog.Logger
+
+	// Clock is used to get the boundaries of the windows.  It must not be nil.
+	Clock agdtime.Clock
+
+	// ErrColl is used to collect the upload errors.  It must not be nil.
+	ErrColl errcoll.Interface
+
+	// Uploader is used to upload the statistics of the closed windows.  It
+	// must not be nil.
+	Uploader Uploader
+
+	// Epsilon is the privacy budget spent on a single window.  The smaller it
+	// is, the more noise is added to the counts.  It must be positive.
+	Epsilon float64
+
+	// MaxContributions is the maximum number of requests of a single client
+	// counted within a window.  It must be positive.
+	MaxContributions uint
+
+	// Threshold is the minimum noisy count of a domain for it to be included
+	// into the statistics.
+	Threshold uint64
+
+	// MaxASNs is the maximum number of ASNs tracked within a window.  It must
+	// be positive.
+	MaxASNs int
+
+	// MaxDomains is the maximum number of domains tracked for each country and
+	// ASN within a window.  It must be positive.
+	MaxDomains int
+
+	// TopSize is the maximum number of domains uploaded for each country and
+	// ASN.  It must be positive.
+	TopSize int
+}
+
+// Default is the default [Interface] implementation.  It counts the requests
+// for each registrable domain within a window, bounding the number of
+// requests counted for each client.  When a window is closed, discrete Laplace
+// noise calibrated to that bound is added to every count, and only the noisy
+// counts above the threshold are uploaded, which makes the uploaded statistics
+// (ε, δ)-differentially private with respect to the requests of a single
+// client, where δ depends on the threshold.
+//
+// The noise is integer, so the noisy counts don't reveal the low-order bits of
+// floating-point noise, and it is taken from a generator seeded with a
+// cryptographically secure random seed.
+//
+// The requests of the clients without a client key are not counted, since
+// their contributions cannot be bounded.
+type Default struct {
+	logger   *slog.Logger
+	clock    agdtime.Clock
+	errColl  errcoll.Interface
+	uploader Uploader
+
+	// mu protects contributions, countries, asns, and windowStart.
+	mu            *sync.Mutex
+	contributions map[agd.ClientKey]uint
+	countries     map[geoip.Country]domainCounts
+	asns          map[geoip.ASN]domainCounts
+	windowStart   time.Time
+
+	// noiseMu protects noiseRand.
+	noiseMu *sync.Mutex
+
+	// noiseRand is the source of the noise added to the counts.
+	noiseRand *rand.Rand
+
+	// noiseScale is the scale of the discrete Laplace noise added to the
+	// counts.
+	noiseScale float64
+
+	threshold        uint64
+	maxContributions uint
+	maxASNs          int
+	maxDomains       int
+	topSize          int
+}
+
+// domainCounts is a helpful alias for the counts of requests per domain.
+type domainCounts = map[string]uint64
+
+// NewDefault returns a new properly initialized *Default.  c must be valid.
+func NewDefault(c *DefaultConfig) (d *Default, err error) {
+	var seed [32]byte
+	_, err = cryptorand.Read(seed[:])
+	if err != nil {
+		return nil, fmt.Errorf("generating noise seed: %w", err)
+	}
+
+	return &Default{
+		logger:        c.Logger,
+		clock:         c.Clock,
+		errColl:       c.ErrColl,
+		uploader:      c.Uploader,
+		mu:            &sync.Mutex{},
+		contributions: map[agd.ClientKey]uint{},
+		countries:     map[geoip.Country]domainCounts{},
+		asns:          map[geoip.ASN]domainCounts{},
+		windowStart:   c.Clock.Now(),
+		noiseMu:       &sync.Mutex{},
+		noiseRand:     rand.New(rand.NewChaCha8(seed)),
+		// Each counted request increases the counts of both its country and
+		// its ASN, so the L1 sensitivity of all counts is twice the number of
+		// contributions of a client.
+		noiseScale:       2 * float64(c.MaxContributions) / c.Epsilon,
+		threshold:        c.Threshold,
+		maxContributions: c.MaxContributions,
+		maxASNs:          c.MaxASNs,
+		maxDomains:       c.MaxDomains,
+		topSize:          c.TopSize,
+	}, nil
+}
+
+// type check
+var _ Interface = (*Default)(nil)
+
+// Record implements the [Interface] interface for *Default.
+func (d *Default) Record(
+	_ context.Context,
+	key agd.ClientKey,
+	host string,
+	ctry geoip.Country,
+	asn geoip.ASN,
+) {
+	hasCtry := ctry != geoip.CountryNone && ctry != geoip.CountryNotApplicable
+	if key == (agd.ClientKey{}) || host == "" || (!hasCtry && asn == 0) {
+		return
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		// The host is a public suffix itself.
+		domain = host
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n := d.contributions[key]
+	if n >= d.maxContributions {
+		return
+	}
+
+	d.contributions[key] = n + 1
+
+	if hasCtry {
+		addCount(d.countries, ctry, domain, math.MaxInt, d.maxDomains)
+	}
+
+	if asn != 0 {
+		addCount(d.asns, asn, domain, d.maxASNs, d.maxDomains)
+	}
+}
+
+// addCount increments the count of domain within group.  New groups are only
+// added while there are less than maxGroups of them, and new domains are only
+// added while the group has less than maxDomains of them.
+func addCount[K comparable](
+	groups map[K]domainCounts,
+	group K,
+	domain string,
+	maxGroups int,
+	maxDomains int,
+) {
+	counts, ok := groups[group]
+	if !ok {
+		if len(groups) >= maxGroups {
+			return
+		}
+
+		counts = domainCounts{}
+		groups[group] = counts
+	}
+
+	if _, ok = counts[domain]; ok || len(counts) < maxDomains {
+		counts[domain]++
+	}
+}
+
+// type check
+var _ agdservice.Refresher = (*Default)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for *Default.  It
+// closes the current window, uploads its statistics, and starts a new one.  The
+// statistics of a window are not retried if the upload fails, since uploading
+// them again with different noise would spend the privacy budget twice.
+func (d *Default) Refresh(ctx context.Context) (err error) {
+	d.logger.DebugContext(ctx, "refresh started")
+	defer d.logger.DebugContext(ctx, "refresh finished")
+
+	s := d.closeWindow()
+	if len(s.Countries) == 0 && len(s.ASNs) == 0 {
+		d.logger.DebugContext(ctx, "no statistics to upload")
+
+		return nil
+	}
+
+	err = d.uploader.Upload(ctx, s)
+	if err != nil {
+		errcoll.Collect(ctx, d.errColl, d.logger, "uploading popular domains", err)
+
+		return fmt.Errorf("uploading: %w", err)
+	}
+
+	d.logger.DebugContext(ctx, "uploaded", "countries", len(s.Countries), "asns", len(s.ASNs))
+
+	return nil
+}
+
+// closeWindow resets the counts, starts a new window, and returns the
+// differentially private statistics of the previous one.
+func (d *Default) closeWindow() (s *Statistics) {
+	now := d.clock.Now()
+
+	d.mu.Lock()
+	countries, asns, start := d.countries, d.asns, d.windowStart
+	d.contributions = map[agd.ClientKey]uint{}
+	d.countries = map[geoip.Country]domainCounts{}
+	d.asns = map[geoip.ASN]domainCounts{}
+	d.windowStart = now
+	d.mu.Unlock()
+
+	return &Statistics{
+		WindowStart: start,
+		WindowEnd:   now,
+		Countries:   release(d, countries),
+		ASNs:        release(d, asns),
+	}
+}
+
+// release returns the noisy top domains for each group.  The groups without
+// any domains above the threshold are omitted.
+func release[K comparable](d *Default, groups map[K]domainCounts) (res map[K][]*DomainCount) {
+	res = make(map[K][]*DomainCount, len(groups))
+	for group, counts := range groups {
+		top := d.noisyTop(counts)
+		if len(top) > 0 {
+			res[group] = top
+		}
+	}
+
+	return res
+}
+
+// noisyTop adds the noise to counts and returns at most d.topSize domains with
+// the largest noisy counts that are not less than d.threshold.
+func (d *Default) noisyTop(counts domainCounts) (top []*DomainCount) {
+	for domain, n := range counts {
+		// #nosec G115 -- The counts are bounded by the number of requests
+		// within a window, which is far below the maximum of int64.
+		noisy := int64(n) + d.laplaceNoise()
+		if noisy < 0 || uint64(noisy) < d.threshold {
+			continue
+		}
+
+		top = append(top, &DomainCount{
+			Domain: domain,
+			Count:  uint64(noisy),
+		})
+	}
+
+	slices.SortFunc(top, func(a, b *DomainCount) (res int) {
+		if res = cmp.Compare(b.Count, a.Count); res != 0 {
+			return res
+		}
+
+		return cmp.Compare(a.Domain, b.Domain)
+	})
+
+	return top[:min(len(top), d.topSize)]
+}
+
+// laplaceNoise returns a random value from the discrete Laplace distribution,
+// also known as the two-sided geometric distribution, centered at zero with the
+// scale of d.noiseScale.  It is the difference of two independent geometric
+// random values, each of which is the integer part of an exponential random
+// value with the same scale.
+func (d *Default) laplaceNoise() (noise int64) {
+	d.noiseMu.Lock()
+	defer d.noiseMu.Unlock()
+
+	pos := math.Floor(d.noiseRand.ExpFloat64() * d.noiseScale)
+	neg := math.Floor(d.noiseRand.ExpFloat64() * d.noiseScale)
+
+	return int64(pos) - int64(neg)
+}
@@ -0,0 +1,51 @@
+package popdomains
+
+import (
+	"math"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault_laplaceNoise(t *testing.T) {
+	t.Parallel()
+
+	// Don't set the error collector and the uploader, since the statistics
+	// aren't uploaded.
+	d, err := NewDefault(&DefaultConfig{
+		Logger:           slogutil.NewDiscardLogger(),
+		Clock:            agdtime.SystemClock{},
+		ErrColl:          nil,
+		Uploader:         nil,
+		Epsilon:          1,
+		MaxContributions: 1,
+		Threshold:        1,
+		MaxASNs:          1,
+		MaxDomains:       1,
+		TopSize:          1,
+	})
+	require.NoError(t, err)
+
+	const n = 100_000
+
+	var sum, sumSq float64
+	for range n {
+		noise := float64(d.laplaceNoise())
+		sum += noise
+		sumSq += noise * noise
+	}
+
+	mean := sum / n
+	variance := sumSq/n - mean*mean
+
+	// The variance of the discrete Laplace distribution with the parameter p is
+	// 2p/(1-p)².
+	p := math.Exp(-1 / d.noiseScale)
+	wantVariance := 2 * p / ((1 - p) * (1 - p))
+
+	assert.InDelta(t, 0, mean, 0.1)
+	assert.InEpsilon(t, wantVariance, variance, 0.05)
+}
@@ -0,0 +1,168 @@
+package popdomains_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+	"github.com/AdguardTeam/AdGuardDNS/internal/popdomains"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// testASN is the common ASN for tests.
+const testASN geoip.ASN = 42
+
+// Client keys for tests.
+var (
+	testKey      = agd.ClientKey{1}
+	testKeyOther = agd.ClientKey{2}
+)
+
+// testEpsilon is the privacy budget so large that the noise is always zero,
+// which makes the results of the tests deterministic.
+const testEpsilon = 1e12
+
+// newTestDefault returns a new *popdomains.Default with the given contribution
+// bound and threshold that sends the uploaded statistics to the returned
+// channel.
+func newTestDefault(
+	tb testing.TB,
+	maxContribs uint,
+	threshold uint64,
+) (d *popdomains.Default, uploads chan *popdomains.Statistics) {
+	tb.Helper()
+
+	uploads = make(chan *popdomains.Statistics, 1)
+	start := time.Unix(0, 0)
+
+	d, err := popdomains.NewDefault(&popdomains.DefaultConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		Clock: &agdtest.Clock{
+			OnNow: func() (now time.Time) { return start },
+		},
+		ErrColl: agdtest.NewErrorCollector(),
+		Uploader: &agdtest.PopularDomainsUploader{
+			OnUpload: func(_ context.Context, s *popdomains.Statistics) (err error) {
+				uploads <- s
+
+				return nil
+			},
+		},
+		Epsilon:          testEpsilon,
+		MaxContributions: maxContribs,
+		Threshold:        threshold,
+		MaxASNs:          2,
+		MaxDomains:       3,
+		TopSize:          2,
+	})
+	require.NoError(tb, err)
+
+	return d, uploads
+}
+
+func TestDefault_Refresh(t *testing.T) {
+	t.Parallel()
+
+	d, uploads := newTestDefault(t, 10, 2)
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	// Subdomains are counted as their registrable domain.
+	d.Record(ctx, testKey, "www.example.com", geoip.CountryAD, testASN)
+	d.Record(ctx, testKey, "mail.example.com", geoip.CountryAD, testASN)
+	d.Record(ctx, testKeyOther, "example.com", geoip.CountryAD, 0)
+
+	d.Record(ctx, testKey, "a.example.co.uk", geoip.CountryNone, testASN)
+	d.Record(ctx, testKeyOther, "b.example.co.uk", geoip.CountryNotApplicable, testASN)
+
+	// Below the threshold.
+	d.Record(ctx, testKey, "example.org", geoip.CountryAD, testASN)
+
+	// Unknown location.
+	d.Record(ctx, testKey, "example.net", geoip.CountryNone, 0)
+
+	// No client key.
+	d.Record(ctx, agd.ClientKey{}, "example.net", geoip.CountryAD, testASN)
+
+	require.NoError(t, d.Refresh(ctx))
+
+	s, ok := testutil.RequireReceive(t, uploads, testTimeout)
+	require.True(t, ok)
+
+	assert.Equal(t, map[geoip.Country][]*popdomains.DomainCount{
+		geoip.CountryAD: {{
+			Domain: "example.com",
+			Count:  3,
+		}},
+	}, s.Countries)
+
+	assert.Equal(t, map[geoip.ASN][]*popdomains.DomainCount{
+		testASN: {{
+			Domain: "example.co.uk",
+			Count:  2,
+		}, {
+			Domain: "example.com",
+			Count:  2,
+		}},
+	}, s.ASNs)
+
+	// The new window is empty, so nothing is uploaded.
+	require.NoError(t, d.Refresh(ctx))
+	assert.Empty(t, uploads)
+}
+
+func TestDefault_Record_bounds(t *testing.T) {
+	t.Parallel()
+
+	d, uploads := newTestDefault(t, 3, 1)
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	// Only three contributions of a single client are counted.
+	for range 5 {
+		d.Record(ctx, testKey, "example.com", geoip.CountryAD, testASN)
+	}
+
+	// Only three domains are tracked for a single group.
+	d.Record(ctx, testKeyOther, "example.org", geoip.CountryAD, 0)
+	d.Record(ctx, testKeyOther, "example.net", geoip.CountryAD, 0)
+	d.Record(ctx, testKeyOther, "example.info", geoip.CountryAD, 0)
+
+	// Only two ASNs are tracked.
+	d.Record(ctx, agd.ClientKey{3}, "example.com", geoip.CountryNone, testASN+1)
+	d.Record(ctx, agd.ClientKey{4}, "example.com", geoip.CountryNone, testASN+2)
+
+	require.NoError(t, d.Refresh(ctx))
+
+	s, ok := testutil.RequireReceive(t, uploads, testTimeout)
+	require.True(t, ok)
+
+	// Only two top domains are uploaded.
+	assert.Equal(t, map[geoip.Country][]*popdomains.DomainCount{
+		geoip.CountryAD: {{
+			Domain: "example.com",
+			Count:  3,
+		}, {
+			Domain: "example.net",
+			Count:  1,
+		}},
+	}, s.Countries)
+
+	assert.Equal(t, map[geoip.ASN][]*popdomains.DomainCount{
+		testASN: {{
+			Domain: "example.com",
+			Count:  3,
+		}},
+		testASN + 1: {{
+			Domain: "example.com",
+			Count:  1,
+		}},
+	}, s.ASNs)
+}
@@ -0,0 +1,75 @@
+// Package popdomains contains the statistics of the most popular domains per
+// country and per ASN.  The counts are aggregated under differential privacy
+// before they leave the collector, so that the uploaded statistics don't
+// reveal the queries of any particular client, and no per-client query data is
+// retained after a window is closed.
+package popdomains
+
+import (
+	"context"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
+)
+
+// Interface is the interface for the popular-domains statistics collectors.
+type Interface interface {
+	// Record records a single request for host from the client with the given
+	// key.  ctry and asn are the location of the client, if known.
+	Record(
+		ctx context.Context,
+		key agd.ClientKey,
+		host string,
+		ctry geoip.Country,
+		asn geoip.ASN,
+	)
+}
+
+// Empty is an [Interface] implementation that does nothing.
+type Empty struct{}
+
+// type check
+var _ Interface = Empty{}
+
+// Record implements the [Interface] interface for Empty.
+func (Empty) Record(_ context.Context, _ agd.ClientKey, _ string, _ geoip.Country, _ geoip.ASN) {}
+
+// Uploader is the interface for a backend that accepts the popular-domains
+// statistics.
+type Uploader interface {
+	// Upload uploads the statistics to the backend.  s must not be nil.
+	Upload(ctx context.Context, s *Statistics) (err error)
+}
+
+// Statistics are the differentially private statistics of the most popular
+// domains within a single window.
+type Statistics struct {
+	// WindowStart is the start of the window within which the statistics have
+	// been collected.
+	WindowStart time.Time
+
+	// WindowEnd is the end of the window within which the statistics have
+	// been collected.
+	WindowEnd time.Time
+
+	// Countries are the most popular domains for each country, sorted by their
+	// counts in descending order.  Countries without any domains above the
+	// threshold are omitted.
+	Countries map[geoip.Country][]*DomainCount
+
+	// ASNs are the most popular domains for each ASN, sorted by their counts
+	// in descending order.  ASNs without any domains above the threshold are
+	// omitted.
+	ASNs map[geoip.ASN][]*DomainCount
+}
+
+// DomainCount is the noisy count of requests for a domain.
+type DomainCount struct {
+	// Domain is the registrable domain, that is the public suffix plus one
+	// label, of the requested hosts.
+	Domain string
+
+	// Count is the number of requests for Domain with the noise added.
+	Count uint64
+}
@@ -0,0 +1,185 @@
+package profiledb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal"
+	"github.com/AdguardTeam/golibs/container"
+)
+
+// collectGarbage removes the profiles marked as deleted and the devices that
+// don't belong to any profile, if they have been such for at least
+// db.gcRetention, and rewrites the cache if anything has been removed.  The
+// removal happens at most once per db.gcRetention.  It must only be called
+// under the refreshMu lock.
+func (db *Default) collectGarbage(ctx context.Context) (err error) {
+	if db.gcRetention == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if now.Sub(db.lastGC) < db.gcRetention {
+		return nil
+	}
+
+	db.lastGC = now
+
+	profNum, devNum := db.prune(now)
+	db.metrics.IncrementPruned(ctx, profNum, devNum)
+	if profNum == 0 && devNum == 0 {
+		return nil
+	}
+
+	db.logger.InfoContext(ctx, "pruned", "prof_num", profNum, "dev_num", devNum)
+
+	if db.fullSyncTime.IsZero() {
+		// There has been no full synchronization yet, and so there is nothing
+		// to compact.
+		return nil
+	}
+
+	err = db.cache.Store(ctx, db.compactFileCache())
+	if err != nil {
+		return fmt.Errorf("compacting cache: %w", err)
+	}
+
+	return nil
+}
+
+// prune removes the expired deleted profiles and unreferenced devices as well
+// as marks the devices that have become unreferenced since the previous call.
+func (db *Default) prune(now time.Time) (profNum, devNum uint) {
+	db.mapsMu.Lock()
+	defer db.mapsMu.Unlock()
+
+	for id, deletedAt := range db.deletedAt {
+		if now.Sub(deletedAt) < db.gcRetention {
+			continue
+		}
+
+		delete(db.deletedAt, id)
+
+		p, ok := db.profiles[id]
+		if !ok || !p.Deleted {
+			continue
+		}
+
+		delete(db.profiles, id)
+		profNum++
+	}
+
+	referenced := db.referencedDevices()
+	for id, d := range db.devices {
+		if referenced.Has(id) {
+			delete(db.orphanedAt, id)
+
+			continue
+		}
+
+		orphanedAt, ok := db.orphanedAt[id]
+		if !ok {
+			db.orphanedAt[id] = now
+		} else if now.Sub(orphanedAt) >= db.gcRetention {
+			delete(db.orphanedAt, id)
+			db.removeDeviceData(d)
+			devNum++
+		}
+	}
+
+	for id := range db.orphanedAt {
+		if _, ok := db.devices[id]; !ok {
+			delete(db.orphanedAt, id)
+		}
+	}
+
+	return profNum, devNum
+}
+
+// referencedDevices returns the IDs of the devices that belong to the profiles
+// not marked as deleted.  It assumes that db.mapsMu is locked.
+func (db *Default) referencedDevices() (ids *container.MapSet[agd.DeviceID]) {
+	ids = container.NewMapSet[agd.DeviceID]()
+	for _, p := range db.profiles {
+		if p.Deleted {
+			continue
+		}
+
+		for _, id := range p.DeviceIDs {
+			ids.Add(id)
+		}
+	}
+
+	return ids
+}
+
+// removeDeviceData removes d and all links to it from the database.  It
+// assumes that db.mapsMu is locked for writing.
+func (db *Default) removeDeviceData(d *agd.Device) {
+	id := d.ID
+	delete(db.devices, id)
+
+	for _, ip := range d.DedicatedIPs {
+		if db.dedicatedIPToDeviceID[ip] == id {
+			delete(db.dedicatedIPToDeviceID, ip)
+		}
+	}
+
+	for _, p := range d.DedicatedPrefixes {
+		if curID, _ := db.dedicatedPrefixToDeviceID.get(p); curID == id {
+			db.dedicatedPrefixToDeviceID.remove(p)
+		}
+	}
+
+	if db.linkedIPToDeviceID[d.LinkedIP] == id {
+		delete(db.linkedIPToDeviceID, d.LinkedIP)
+	}
+
+	profID, ok := db.deviceIDToProfileID[id]
+	if !ok {
+		return
+	}
+
+	delete(db.deviceIDToProfileID, id)
+
+	k := humanIDKey{
+		lower:   d.HumanIDLower,
+		profile: profID,
+	}
+	if db.humanIDToDeviceID[k] == id {
+		delete(db.humanIDToDeviceID, k)
+	}
+}
+
+// compactFileCache returns the file cache with the current profiles not marked
+// as deleted and their devices.  The synchronization time is the one of the
+// last full synchronization, so that the data received since then is requested
+// again after a restart.
+func (db *Default) compactFileCache() (c *internal.FileCache) {
+	db.mapsMu.RLock()
+	defer db.mapsMu.RUnlock()
+
+	c = &internal.FileCache{
+		SyncTime: db.fullSyncTime,
+		Profiles: make([]*agd.Profile, 0, len(db.profiles)),
+		Devices:  make([]*agd.Device, 0, len(db.devices)),
+		Version:  internal.FileCacheVersion,
+	}
+
+	for _, p := range db.profiles {
+		if p.Deleted {
+			continue
+		}
+
+		c.Profiles = append(c.Profiles, p)
+		for _, id := range p.DeviceIDs {
+			if d, ok := db.devices[id]; ok {
+				c.Devices = append(c.Devices, d)
+			}
+		}
+	}
+
+	return c
+}
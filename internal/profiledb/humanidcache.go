@@ -0,0 +1,74 @@
+package profiledb
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// newHumanIDCache returns a new cache for the lookups of devices by their
+// human-readable IDs.  If count is zero, the returned cache does nothing.
+//
+// The values of the cache are the IDs of the found devices.  An empty device ID
+// means a negative entry, which is set when the automatic creation of a device
+// has failed.
+func newHumanIDCache(count int) (c agdcache.Interface[humanIDKey, agd.DeviceID]) {
+	if count == 0 {
+		return agdcache.Empty[humanIDKey, agd.DeviceID]{}
+	}
+
+	return agdcache.NewLRU[humanIDKey, agd.DeviceID](&agdcache.LRUConfig{
+		Count: count,
+	})
+}
+
+// cachedDeviceID returns the ID of the device cached for k, if there is a
+// positive entry for it.
+func (db *Default) cachedDeviceID(ctx context.Context, k humanIDKey) (id agd.DeviceID, ok bool) {
+	id, ok = db.humanIDCache.Get(k)
+	ok = ok && id != ""
+	db.metrics.IncrementHumanIDCacheLookups(ctx, ok, false)
+
+	return id, ok
+}
+
+// isNegativelyCached returns true if the automatic creation of a device for k
+// has recently failed.
+func (db *Default) isNegativelyCached(ctx context.Context, k humanIDKey) (ok bool) {
+	if db.humanIDNegativeTTL == 0 {
+		return false
+	}
+
+	id, ok := db.humanIDCache.Get(k)
+	ok = ok && id == ""
+	db.metrics.IncrementHumanIDCacheLookups(ctx, ok, true)
+
+	return ok
+}
+
+// setNegative caches the failed automatic creation of a device for k, if err is
+// one of the errors that are not expected to go away on an immediate retry.
+func (db *Default) setNegative(k humanIDKey, err error) {
+	if db.humanIDNegativeTTL == 0 || !isPersistentCreateError(err) {
+		return
+	}
+
+	db.humanIDCache.SetWithExpire(k, "", db.humanIDNegativeTTL)
+}
+
+// isPersistentCreateError returns true if err is an error returned by
+// [Storage.CreateAutoDevice] that is not expected to go away on an immediate
+// retry.  Network errors and timeouts are not considered persistent.
+func isPersistentCreateError(err error) (ok bool) {
+	var (
+		badReqErr    *BadRequestError
+		quotaErr     *DeviceQuotaExceededError
+		rateLimitErr *RateLimitedError
+	)
+
+	return errors.As(err, &badReqErr) ||
+		errors.As(err, &quotaErr) ||
+		errors.As(err, &rateLimitErr)
+}
@@ -3,7 +3,9 @@ package filecachepb
 
 import (
 	"fmt"
+	"maps"
 	"net/netip"
+	"slices"
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/access"
@@ -82,7 +84,13 @@ func (x *Profile) toInternal(respSzEst datasize.ByteSize) (prof *agd.Profile, er
 		return nil, fmt.Errorf("pause schedule: %w", err)
 	}
 
+	blockPage, err := pbFltConf.blockPageToInternal()
+	if err != nil {
+		return nil, fmt.Errorf("block page: %w", err)
+	}
+
 	fltConf := &filter.ConfigClient{
+		BlockPage: blockPage,
 		Custom: &filter.ConfigCustom{
 			ID:         pbFltConf.Custom.Id,
 			UpdateTime: pbFltConf.Custom.UpdateTime.AsTime(),
@@ -100,6 +108,7 @@ func (x *Profile) toInternal(respSzEst datasize.ByteSize) (prof *agd.Profile, er
 			AdultBlockingEnabled:     pbFltConf.Parental.AdultBlockingEnabled,
 			SafeSearchGeneralEnabled: pbFltConf.Parental.SafeSearchGeneralEnabled,
 			SafeSearchYouTubeEnabled: pbFltConf.Parental.SafeSearchYoutubeEnabled,
+			LearningPeriod:           pbFltConf.Parental.LearningPeriod.AsDuration(),
 		},
 		RuleList: &filter.ConfigRuleList{
 			// Consider rule-list IDs to have been prevalidated.
@@ -111,6 +120,11 @@ func (x *Profile) toInternal(respSzEst datasize.ByteSize) (prof *agd.Profile, er
 			DangerousDomainsEnabled:       pbFltConf.SafeBrowsing.DangerousDomainsEnabled,
 			NewlyRegisteredDomainsEnabled: pbFltConf.SafeBrowsing.NewlyRegisteredDomainsEnabled,
 		},
+		AllowlistOnly: pbFltConf.AllowlistOnly,
+	}
+
+	if bu := pbFltConf.BlockUntil; bu != nil {
+		fltConf.BlockUntil = bu.AsTime()
 	}
 
 	return &agd.Profile{
@@ -120,7 +134,12 @@ func (x *Profile) toInternal(respSzEst datasize.ByteSize) (prof *agd.Profile, er
 		BlockingMode: m,
 		Ratelimiter:  x.Ratelimiter.toInternal(respSzEst),
 
-		ID: agd.ProfileID(x.ProfileId),
+		AccountLimits: x.accountLimitsToInternal(),
+
+		QueryTypeActions: x.queryTypeActionsToInternal(),
+
+		ID:        agd.ProfileID(x.ProfileId),
+		AccountID: agd.AccountID(x.AccountId),
 
 		// Consider device IDs to have been prevalidated.
 		DeviceIDs: unsafelyConvertStrSlice[string, agd.DeviceID](x.DeviceIds),
@@ -128,17 +147,79 @@ func (x *Profile) toInternal(respSzEst datasize.ByteSize) (prof *agd.Profile, er
 		// Consider rule-list IDs to have been prevalidated.
 		FilteredResponseTTL: x.FilteredResponseTtl.AsDuration(),
 
+		// #nosec G115 -- The value has been put there by profileToProtobuf.
+		ECSMode: agd.ECSMode(x.EcsMode),
+
 		AutoDevicesEnabled:  x.AutoDevicesEnabled,
 		BlockChromePrefetch: x.BlockChromePrefetch,
 		BlockFirefoxCanary:  x.BlockFirefoxCanary,
 		BlockPrivateRelay:   x.BlockPrivateRelay,
 		Deleted:             x.Deleted,
 		FilteringEnabled:    x.FilteringEnabled,
+		ForwardLocalZones:   x.ForwardLocalZones,
 		IPLogEnabled:        x.IpLogEnabled,
 		QueryLogEnabled:     x.QueryLogEnabled,
+		RequireDNSSEC:       x.RequireDnssec,
 	}, nil
 }
 
+// accountLimitsToInternal converts the aggregate account limits of x to an
+// internal structure.  If the account has no limits, l is nil.
+func (x *Profile) accountLimitsToInternal() (l *agd.AccountLimits) {
+	if x.AccountRps == 0 && x.AccountMaxDevices == 0 {
+		return nil
+	}
+
+	return &agd.AccountLimits{
+		Tier:       agd.AccountTier(x.AccountTier),
+		RPS:        x.AccountRps,
+		MaxDevices: x.AccountMaxDevices,
+	}
+}
+
+// queryTypeActionsToInternal converts the query types with special actions of x
+// to an internal map.
+func (x *Profile) queryTypeActionsToInternal() (actions map[dnsmsg.RRType]agd.QueryTypeAction) {
+	l := len(x.RefusedQueryTypes) + len(x.NodataQueryTypes)
+	if l == 0 {
+		return nil
+	}
+
+	actions = make(map[dnsmsg.RRType]agd.QueryTypeAction, l)
+	for _, qt := range x.RefusedQueryTypes {
+		// #nosec G115 -- The value has been put there by profileToProtobuf.
+		actions[dnsmsg.RRType(qt)] = agd.QueryTypeActionRefused
+	}
+
+	for _, qt := range x.NodataQueryTypes {
+		// #nosec G115 -- The value has been put there by profileToProtobuf.
+		actions[dnsmsg.RRType(qt)] = agd.QueryTypeActionNODATA
+	}
+
+	return actions
+}
+
+// blockPageToInternal converts the block-page addresses of x to an internal
+// structure.  If x contains no addresses, c is nil.
+func (x *FilterConfig) blockPageToInternal() (c *filter.ConfigBlockPage, err error) {
+	if len(x.BlockPageIpv4) == 0 && len(x.BlockPageIpv6) == 0 {
+		return nil, nil
+	}
+
+	c = &filter.ConfigBlockPage{}
+	err = c.IPv4.UnmarshalBinary(x.BlockPageIpv4)
+	if err != nil {
+		return nil, fmt.Errorf("ipv4: %w", err)
+	}
+
+	err = c.IPv6.UnmarshalBinary(x.BlockPageIpv6)
+	if err != nil {
+		return nil, fmt.Errorf("ipv6: %w", err)
+	}
+
+	return c, nil
+}
+
 // toInternal converts a protobuf protection-schedule structure to an internal
 // one.  If x is nil, c is nil.
 func (x *FilterConfig_Schedule) toInternal() (c *filter.ConfigSchedule, err error) {
@@ -249,17 +330,25 @@ func (x *Device) toInternal() (d *agd.Device, err error) {
 		return nil, fmt.Errorf("auth: %s: %w", x.DeviceId, err)
 	}
 
+	var createdAt time.Time
+	if ca := x.CreatedAt; ca != nil {
+		createdAt = ca.AsTime()
+	}
+
 	return &agd.Device{
 		Auth: auth,
 		// Consider device IDs to have been prevalidated.
-		ID:       agd.DeviceID(x.DeviceId),
-		LinkedIP: linkedIP,
+		ID:        agd.DeviceID(x.DeviceId),
+		CreatedAt: createdAt,
+		LinkedIP:  linkedIP,
 		// Consider device names to have been prevalidated.
 		Name: agd.DeviceName(x.DeviceName),
 		// Consider lowercase HumanIDs to have been prevalidated.
-		HumanIDLower:     agd.HumanIDLower(x.HumanIdLower),
-		DedicatedIPs:     dedicatedIPs,
-		FilteringEnabled: x.FilteringEnabled,
+		HumanIDLower:      agd.HumanIDLower(x.HumanIdLower),
+		DedicatedIPs:      dedicatedIPs,
+		DedicatedPrefixes: cidrRangeToInternal(x.DedicatedPrefixes),
+		FilteringEnabled:  x.FilteringEnabled,
+		Quarantined:       x.Quarantined,
 	}, nil
 }
 
@@ -281,6 +370,7 @@ func (x *AuthenticationSettings) toInternal() (s *agd.AuthSettings, err error) {
 
 	return &agd.AuthSettings{
 		PasswordHash: ph,
+		DoHQueryKey:  x.DohQueryKey,
 		Enabled:      true,
 		DoHAuthOnly:  x.DohAuthOnly,
 	}, nil
@@ -361,31 +451,71 @@ func asnToInternal(asns []uint32) (out []geoip.ASN) {
 func profilesToProtobuf(profiles []*agd.Profile) (pbProfiles []*Profile) {
 	pbProfiles = make([]*Profile, 0, len(profiles))
 	for _, p := range profiles {
-		pbProfiles = append(pbProfiles, &Profile{
-			FilterConfig:        filterConfigToProtobuf(p.FilterConfig),
-			Access:              accessToProtobuf(p.Access.Config()),
-			BlockingMode:        blockingModeToProtobuf(p.BlockingMode),
-			Ratelimiter:         ratelimiterToProtobuf(p.Ratelimiter.Config()),
-			ProfileId:           string(p.ID),
-			DeviceIds:           unsafelyConvertStrSlice[agd.DeviceID, string](p.DeviceIDs),
-			FilteredResponseTtl: durationpb.New(p.FilteredResponseTTL),
-			AutoDevicesEnabled:  p.AutoDevicesEnabled,
-			BlockChromePrefetch: p.BlockChromePrefetch,
-			BlockFirefoxCanary:  p.BlockFirefoxCanary,
-			BlockPrivateRelay:   p.BlockPrivateRelay,
-			Deleted:             p.Deleted,
-			FilteringEnabled:    p.FilteringEnabled,
-			IpLogEnabled:        p.IPLogEnabled,
-			QueryLogEnabled:     p.QueryLogEnabled,
-		})
+		pbProfiles = append(pbProfiles, profileToProtobuf(p))
 	}
 
 	return pbProfiles
 }
 
+// profileToProtobuf converts a profile to a protobuf structure.
+func profileToProtobuf(p *agd.Profile) (pbProf *Profile) {
+	refused, nodata := queryTypeActionsToProtobuf(p.QueryTypeActions)
+
+	pbProf = &Profile{
+		FilterConfig:        filterConfigToProtobuf(p.FilterConfig),
+		Access:              accessToProtobuf(p.Access.Config()),
+		BlockingMode:        blockingModeToProtobuf(p.BlockingMode),
+		Ratelimiter:         ratelimiterToProtobuf(p.Ratelimiter.Config()),
+		ProfileId:           string(p.ID),
+		DeviceIds:           unsafelyConvertStrSlice[agd.DeviceID, string](p.DeviceIDs),
+		FilteredResponseTtl: durationpb.New(p.FilteredResponseTTL),
+		EcsMode:             uint32(p.ECSMode),
+		AutoDevicesEnabled:  p.AutoDevicesEnabled,
+		BlockChromePrefetch: p.BlockChromePrefetch,
+		BlockFirefoxCanary:  p.BlockFirefoxCanary,
+		BlockPrivateRelay:   p.BlockPrivateRelay,
+		Deleted:             p.Deleted,
+		FilteringEnabled:    p.FilteringEnabled,
+		ForwardLocalZones:   p.ForwardLocalZones,
+		IpLogEnabled:        p.IPLogEnabled,
+		QueryLogEnabled:     p.QueryLogEnabled,
+		RefusedQueryTypes:   refused,
+		NodataQueryTypes:    nodata,
+		RequireDnssec:       p.RequireDNSSEC,
+		AccountId:           string(p.AccountID),
+	}
+
+	if l := p.AccountLimits; l != nil {
+		pbProf.AccountTier = string(l.Tier)
+		pbProf.AccountRps = l.RPS
+		pbProf.AccountMaxDevices = l.MaxDevices
+	}
+
+	return pbProf
+}
+
+// queryTypeActionsToProtobuf converts the query types with special actions to
+// the sorted protobuf lists.
+func queryTypeActionsToProtobuf(
+	actions map[dnsmsg.RRType]agd.QueryTypeAction,
+) (refused, nodata []uint32) {
+	for _, qt := range slices.Sorted(maps.Keys(actions)) {
+		switch actions[qt] {
+		case agd.QueryTypeActionRefused:
+			refused = append(refused, uint32(qt))
+		case agd.QueryTypeActionNODATA:
+			nodata = append(nodata, uint32(qt))
+		default:
+			// Go on.
+		}
+	}
+
+	return refused, nodata
+}
+
 // filterConfigToProtobuf converts the filtering configration to protobuf.
 func filterConfigToProtobuf(c *filter.ConfigClient) (fc *FilterConfig) {
-	return &FilterConfig{
+	fc = &FilterConfig{
 		Custom: &FilterConfig_Custom{
 			Id:         string(c.Custom.ID),
 			UpdateTime: timestamppb.New(c.Custom.UpdateTime),
@@ -401,6 +531,7 @@ func filterConfigToProtobuf(c *filter.ConfigClient) (fc *FilterConfig) {
 			AdultBlockingEnabled:     c.Parental.AdultBlockingEnabled,
 			SafeSearchGeneralEnabled: c.Parental.SafeSearchGeneralEnabled,
 			SafeSearchYoutubeEnabled: c.Parental.SafeSearchYouTubeEnabled,
+			LearningPeriod:           durationpb.New(c.Parental.LearningPeriod),
 		},
 		RuleList: &FilterConfig_RuleList{
 			Ids:     unsafelyConvertStrSlice[filter.ID, string](c.RuleList.IDs),
@@ -411,7 +542,19 @@ func filterConfigToProtobuf(c *filter.ConfigClient) (fc *FilterConfig) {
 			DangerousDomainsEnabled:       c.SafeBrowsing.DangerousDomainsEnabled,
 			NewlyRegisteredDomainsEnabled: c.SafeBrowsing.NewlyRegisteredDomainsEnabled,
 		},
+		AllowlistOnly: c.AllowlistOnly,
+	}
+
+	if bp := c.BlockPage; bp != nil {
+		fc.BlockPageIpv4 = ipToBytes(bp.IPv4)
+		fc.BlockPageIpv6 = ipToBytes(bp.IPv6)
 	}
+
+	if !c.BlockUntil.IsZero() {
+		fc.BlockUntil = timestamppb.New(c.BlockUntil)
+	}
+
+	return fc
 }
 
 // scheduleToProtobuf converts schedule configuration to protobuf.  If c is nil,
@@ -555,20 +698,33 @@ func ratelimiterToProtobuf(c *agd.RatelimitConfig) (r *Ratelimiter) {
 func devicesToProtobuf(devices []*agd.Device) (pbDevices []*Device) {
 	pbDevices = make([]*Device, 0, len(devices))
 	for _, d := range devices {
-		pbDevices = append(pbDevices, &Device{
-			Authentication:   authToProtobuf(d.Auth),
-			DeviceId:         string(d.ID),
-			LinkedIp:         ipToBytes(d.LinkedIP),
-			HumanIdLower:     string(d.HumanIDLower),
-			DeviceName:       string(d.Name),
-			DedicatedIps:     ipsToByteSlices(d.DedicatedIPs),
-			FilteringEnabled: d.FilteringEnabled,
-		})
+		pbDevices = append(pbDevices, deviceToProtobuf(d))
 	}
 
 	return pbDevices
 }
 
+// deviceToProtobuf converts a device to a protobuf structure.
+func deviceToProtobuf(d *agd.Device) (pbDev *Device) {
+	pbDev = &Device{
+		Authentication:    authToProtobuf(d.Auth),
+		DeviceId:          string(d.ID),
+		LinkedIp:          ipToBytes(d.LinkedIP),
+		HumanIdLower:      string(d.HumanIDLower),
+		DeviceName:        string(d.Name),
+		DedicatedIps:      ipsToByteSlices(d.DedicatedIPs),
+		DedicatedPrefixes: prefixesToProtobuf(d.DedicatedPrefixes),
+		FilteringEnabled:  d.FilteringEnabled,
+		Quarantined:       d.Quarantined,
+	}
+
+	if !d.CreatedAt.IsZero() {
+		pbDev.CreatedAt = timestamppb.New(d.CreatedAt)
+	}
+
+	return pbDev
+}
+
 // authToProtobuf converts an auth device settings to a protobuf struct.
 // Returns nil if the given settings have Enabled field set to false.
 func authToProtobuf(s *agd.AuthSettings) (a *AuthenticationSettings) {
@@ -579,6 +735,7 @@ func authToProtobuf(s *agd.AuthSettings) (a *AuthenticationSettings) {
 	return &AuthenticationSettings{
 		DohAuthOnly:     s.DoHAuthOnly,
 		DohPasswordHash: dohPasswordToProtobuf(s.PasswordHash),
+		DohQueryKey:     s.DoHQueryKey,
 	}
 }
 
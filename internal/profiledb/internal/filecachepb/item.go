@@ -0,0 +1,53 @@
+package filecachepb
+
+import (
+	"fmt"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/c2h5oh/datasize"
+	"google.golang.org/protobuf/proto"
+)
+
+// MarshalProfile encodes a single profile using protobuf.  p must not be nil.
+// It is used by file-cache storages that store profiles separately.
+func MarshalProfile(p *agd.Profile) (b []byte, err error) {
+	b, err = proto.Marshal(profileToProtobuf(p))
+	if err != nil {
+		return nil, fmt.Errorf("encoding profile %q: %w", p.ID, err)
+	}
+
+	return b, nil
+}
+
+// UnmarshalProfile decodes a single profile encoded with [MarshalProfile].
+func UnmarshalProfile(b []byte, respSzEst datasize.ByteSize) (p *agd.Profile, err error) {
+	pbProf := &Profile{}
+	err = proto.Unmarshal(b, pbProf)
+	if err != nil {
+		return nil, fmt.Errorf("decoding protobuf: %w", err)
+	}
+
+	return pbProf.toInternal(respSzEst)
+}
+
+// MarshalDevice encodes a single device using protobuf.  d must not be nil.
+// It is used by file-cache storages that store devices separately.
+func MarshalDevice(d *agd.Device) (b []byte, err error) {
+	b, err = proto.Marshal(deviceToProtobuf(d))
+	if err != nil {
+		return nil, fmt.Errorf("encoding device %q: %w", d.ID, err)
+	}
+
+	return b, nil
+}
+
+// UnmarshalDevice decodes a single device encoded with [MarshalDevice].
+func UnmarshalDevice(b []byte) (d *agd.Device, err error) {
+	pbDev := &Device{}
+	err = proto.Unmarshal(b, pbDev)
+	if err != nil {
+		return nil, fmt.Errorf("decoding protobuf: %w", err)
+	}
+
+	return pbDev.toInternal()
+}
@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/cachecrypt"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/c2h5oh/datasize"
@@ -16,14 +17,22 @@ import (
 // Storage is the file-cache storage that encodes data using protobuf.
 type Storage struct {
 	logger    *slog.Logger
+	cipher    *cachecrypt.Cipher
 	path      string
 	respSzEst datasize.ByteSize
 }
 
-// New returns a new protobuf-encoded file-cache storage.
-func New(logger *slog.Logger, cachePath string, respSzEst datasize.ByteSize) (s *Storage) {
+// New returns a new protobuf-encoded file-cache storage.  If cipher is not nil,
+// the file is encrypted with it.
+func New(
+	logger *slog.Logger,
+	cachePath string,
+	respSzEst datasize.ByteSize,
+	cipher *cachecrypt.Cipher,
+) (s *Storage) {
 	return &Storage{
 		logger:    logger,
+		cipher:    cipher,
 		path:      cachePath,
 		respSzEst: respSzEst,
 	}
@@ -46,6 +55,12 @@ func (s *Storage) Load(ctx context.Context) (c *internal.FileCache, err error) {
 		return nil, err
 	}
 
+	b, err = s.decrypt(ctx, b)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
 	fc := &FileCache{}
 	err = proto.Unmarshal(b, fc)
 	if err != nil {
@@ -79,6 +94,84 @@ func (s *Storage) Store(ctx context.Context, c *internal.FileCache) (err error)
 		return fmt.Errorf("encoding protobuf: %w", err)
 	}
 
+	b, err = s.encrypt(ctx, b)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
 	// Don't wrap the error, because it's informative enough as is.
 	return renameio.WriteFile(s.path, b, 0o600)
 }
+
+// Close implements the [internal.FileCacheStorage] interface for *Storage.  It
+// does nothing, since the file is only open during loading and storing, and
+// always returns nil.
+func (s *Storage) Close() (err error) {
+	return nil
+}
+
+// decrypt returns the decrypted contents of the cache file b.  If the contents
+// are in plaintext or have been encrypted with a previous key, the file is
+// encrypted again with the current key.
+func (s *Storage) decrypt(ctx context.Context, b []byte) (plaintext []byte, err error) {
+	isEncrypted := cachecrypt.IsEncrypted(b)
+	if s.cipher == nil {
+		if isEncrypted {
+			return nil, errors.Error("file is encrypted, but no keys are set")
+		}
+
+		return b, nil
+	}
+
+	if !isEncrypted {
+		s.logger.WarnContext(ctx, "file is not encrypted, encrypting")
+
+		return b, s.reencrypt(ctx, b)
+	}
+
+	plaintext, rotated, err := s.cipher.Open(ctx, b)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting: %w", err)
+	}
+
+	if rotated {
+		s.logger.InfoContext(ctx, "file is encrypted with previous key, reencrypting")
+
+		return plaintext, s.reencrypt(ctx, plaintext)
+	}
+
+	return plaintext, nil
+}
+
+// reencrypt writes plaintext encrypted with the current key into the cache
+// file.
+func (s *Storage) reencrypt(ctx context.Context, plaintext []byte) (err error) {
+	b, err := s.encrypt(ctx, plaintext)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	err = renameio.WriteFile(s.path, b, 0o600)
+	if err != nil {
+		return fmt.Errorf("reencrypting: %w", err)
+	}
+
+	return nil
+}
+
+// encrypt returns b encrypted with the current key, if the storage has a
+// cipher.  Otherwise, it returns b.
+func (s *Storage) encrypt(ctx context.Context, b []byte) (data []byte, err error) {
+	if s.cipher == nil {
+		return b, nil
+	}
+
+	data, err = s.cipher.Seal(ctx, b)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting: %w", err)
+	}
+
+	return data, nil
+}
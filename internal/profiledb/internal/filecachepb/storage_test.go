@@ -1,11 +1,13 @@
 package filecachepb_test
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/cachecrypt"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal/filecachepb"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal/profiledbtest"
@@ -18,7 +20,7 @@ import (
 func TestStorage(t *testing.T) {
 	prof, dev := profiledbtest.NewProfile(t)
 	cachePath := filepath.Join(t.TempDir(), "profiles.pb")
-	s := filecachepb.New(slogutil.NewDiscardLogger(), cachePath, profiledbtest.RespSzEst)
+	s := filecachepb.New(slogutil.NewDiscardLogger(), cachePath, profiledbtest.RespSzEst, nil)
 	require.NotNil(t, s)
 
 	fc := &internal.FileCache{
@@ -42,7 +44,7 @@ func TestStorage(t *testing.T) {
 
 func TestStorage_Load_noFile(t *testing.T) {
 	cachePath := filepath.Join(t.TempDir(), "profiles.pb")
-	s := filecachepb.New(slogutil.NewDiscardLogger(), cachePath, profiledbtest.RespSzEst)
+	s := filecachepb.New(slogutil.NewDiscardLogger(), cachePath, profiledbtest.RespSzEst, nil)
 	require.NotNil(t, s)
 
 	ctx := testutil.ContextWithTimeout(t, testTimeout)
@@ -50,3 +52,73 @@ func TestStorage_Load_noFile(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Nil(t, fc)
 }
+
+func TestStorage_encryption(t *testing.T) {
+	prof, dev := profiledbtest.NewProfile(t)
+	fc := &internal.FileCache{
+		SyncTime: time.Now().Round(0).UTC(),
+		Profiles: []*agd.Profile{prof},
+		Devices:  []*agd.Device{dev},
+		Version:  internal.FileCacheVersion,
+	}
+
+	oldKey := &cachecrypt.Key{1}
+	newKey := &cachecrypt.Key{2}
+
+	cachePath := filepath.Join(t.TempDir(), "profiles.pb")
+	logger := slogutil.NewDiscardLogger()
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	plain := filecachepb.New(logger, cachePath, profiledbtest.RespSzEst, nil)
+	require.NoError(t, plain.Store(ctx, fc))
+
+	t.Run("encrypt_plaintext", func(t *testing.T) {
+		cipher := cachecrypt.NewCipher(cachecrypt.NewStaticKeys(oldKey))
+		s := filecachepb.New(logger, cachePath, profiledbtest.RespSzEst, cipher)
+
+		gotFC, err := s.Load(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, fc, gotFC)
+		assertEncrypted(t, cachePath)
+	})
+
+	t.Run("no_keys", func(t *testing.T) {
+		gotFC, err := plain.Load(ctx)
+		assert.Error(t, err)
+		assert.Nil(t, gotFC)
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		cipher := cachecrypt.NewCipher(cachecrypt.NewStaticKeys(newKey, oldKey))
+		s := filecachepb.New(logger, cachePath, profiledbtest.RespSzEst, cipher)
+
+		gotFC, err := s.Load(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, fc, gotFC)
+		assertEncrypted(t, cachePath)
+	})
+
+	t.Run("old_key_removed", func(t *testing.T) {
+		cipher := cachecrypt.NewCipher(cachecrypt.NewStaticKeys(newKey))
+		s := filecachepb.New(logger, cachePath, profiledbtest.RespSzEst, cipher)
+
+		gotFC, err := s.Load(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, fc, gotFC)
+	})
+}
+
+// assertEncrypted is a test helper that checks that the file at path is
+// encrypted.
+func assertEncrypted(tb testing.TB, path string) {
+	tb.Helper()
+
+	b, err := os.ReadFile(path)
+	require.NoError(tb, err)
+
+	assert.True(tb, cachecrypt.IsEncrypted(b))
+}
@@ -0,0 +1,629 @@
+// Package filecachesql contains the SQLite-backed storage for the profile
+// cache.  Unlike the protobuf one, it stores every profile and device in a
+// separate row, which allows incremental updates.
+package filecachesql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/cachecrypt"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal/filecachepb"
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/c2h5oh/datasize"
+
+	// Register the pure-Go "sqlite" driver.
+	_ "modernc.org/sqlite"
+)
+
+// Metadata keys.
+const (
+	keySyncTime = "sync_time"
+	keyVersion  = "version"
+)
+
+// schema is the SQL schema of the cache database.  The profiles and devices are
+// stored as protobuf-encoded blobs, which are encrypted if the storage has a
+// cipher.
+const schema = `
+PRAGMA journal_mode = WAL;
+CREATE TABLE IF NOT EXISTS metadata (
+	key TEXT PRIMARY KEY,
+	value INTEGER NOT NULL
+);
+CREATE TABLE IF NOT EXISTS profiles (
+	id TEXT PRIMARY KEY,
+	data BLOB NOT NULL
+);
+CREATE TABLE IF NOT EXISTS devices (
+	id TEXT PRIMARY KEY,
+	data BLOB NOT NULL
+);
+`
+
+// Upsert queries.  They don't rewrite rows with the same data.
+const (
+	upsertProfileQuery = `
+INSERT INTO profiles (id, data) VALUES (?, ?)
+ON CONFLICT (id) DO UPDATE SET data = excluded.data
+WHERE profiles.data != excluded.data;`
+
+	upsertDeviceQuery = `
+INSERT INTO devices (id, data) VALUES (?, ?)
+ON CONFLICT (id) DO UPDATE SET data = excluded.data
+WHERE devices.data != excluded.data;`
+
+	upsertMetadataQuery = `
+INSERT INTO metadata (key, value) VALUES (?, ?)
+ON CONFLICT (key) DO UPDATE SET value = excluded.value;`
+)
+
+// Config is the configuration structure for the SQLite file-cache storage.
+type Config struct {
+	// Logger is used for logging the operation of the storage.  It must not be
+	// nil.
+	Logger *slog.Logger
+
+	// Cipher, if not nil, is used to encrypt the profile and device data.
+	// Plaintext rows as well as the rows encrypted with a previous key are
+	// encrypted with the current key when they are stored next time.
+	Cipher *cachecrypt.Cipher
+
+	// Migration, if not nil, is the storage from which the data is loaded and
+	// migrated when the database is empty.
+	Migration internal.FileCacheStorage
+
+	// Path is the path to the database file.  It must not be empty.
+	Path string
+
+	// ResponseSizeEstimate is the estimate of the size of one DNS response for
+	// the purposes of custom ratelimiting.
+	ResponseSizeEstimate datasize.ByteSize
+}
+
+// Storage is the file-cache storage that keeps data in an SQLite database.
+type Storage struct {
+	logger    *slog.Logger
+	cipher    *cachecrypt.Cipher
+	db        *sql.DB
+	migration internal.FileCacheStorage
+	respSzEst datasize.ByteSize
+}
+
+// New returns a new SQLite-backed file-cache storage.  It opens the database
+// and creates the schema, if necessary.  c must not be nil.
+func New(ctx context.Context, c *Config) (s *Storage, err error) {
+	db, err := sql.Open("sqlite", c.Path)
+	if err != nil {
+		return nil, fmt.Errorf("opening database: %w", err)
+	}
+
+	// SQLite doesn't support concurrent writers, and pragmas are per
+	// connection, so use only one.
+	db.SetMaxOpenConns(1)
+
+	_, err = db.ExecContext(ctx, schema)
+	if err != nil {
+		return nil, errors.WithDeferred(
+			fmt.Errorf("creating schema: %w", err),
+			db.Close(),
+		)
+	}
+
+	return &Storage{
+		logger:    c.Logger,
+		cipher:    c.Cipher,
+		db:        db,
+		migration: c.Migration,
+		respSzEst: c.ResponseSizeEstimate,
+	}, nil
+}
+
+// type check
+var (
+	_ internal.FileCacheStorage = (*Storage)(nil)
+	_ internal.FileCacheUpdater = (*Storage)(nil)
+)
+
+// Load implements the [internal.FileCacheStorage] interface for *Storage.
+// Profiles and devices that cannot be decoded are skipped and logged, so that
+// a single broken record doesn't invalidate the whole cache.
+func (s *Storage) Load(ctx context.Context) (c *internal.FileCache, err error) {
+	s.logger.InfoContext(ctx, "loading")
+
+	version, ok, err := s.metadata(ctx, keyVersion)
+	if err != nil {
+		return nil, fmt.Errorf("reading version: %w", err)
+	} else if !ok {
+		return s.migrate(ctx)
+	}
+
+	if version != internal.FileCacheVersion {
+		return nil, fmt.Errorf(
+			"%w: version %d is different from %d",
+			internal.CacheVersionError,
+			version,
+			internal.FileCacheVersion,
+		)
+	}
+
+	syncTimeNano, _, err := s.metadata(ctx, keySyncTime)
+	if err != nil {
+		return nil, fmt.Errorf("reading sync time: %w", err)
+	}
+
+	profiles, err := loadRows(ctx, s, "profiles", s.unmarshalProfile)
+	if err != nil {
+		return nil, fmt.Errorf("loading profiles: %w", err)
+	}
+
+	devices, err := loadRows(ctx, s, "devices", filecachepb.UnmarshalDevice)
+	if err != nil {
+		return nil, fmt.Errorf("loading devices: %w", err)
+	}
+
+	return &internal.FileCache{
+		SyncTime: time.Unix(0, syncTimeNano).UTC(),
+		Profiles: profiles,
+		Devices:  devices,
+		Version:  internal.FileCacheVersion,
+	}, nil
+}
+
+// migrate loads the data from the migration storage, if any, and stores it in
+// the database.
+func (s *Storage) migrate(ctx context.Context) (c *internal.FileCache, err error) {
+	if s.migration == nil {
+		s.logger.WarnContext(ctx, "database is empty")
+
+		return nil, nil
+	}
+
+	s.logger.InfoContext(ctx, "database is empty; migrating")
+
+	c, err = s.migration.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("loading migration data: %w", err)
+	} else if c == nil {
+		return nil, nil
+	}
+
+	err = s.Store(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("storing migration data: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "migrated", "prof_num", len(c.Profiles), "dev_num", len(c.Devices))
+
+	return c, nil
+}
+
+// metadata returns the metadata value for key.  ok is false if there is no
+// such value.
+func (s *Storage) metadata(ctx context.Context, key string) (v int64, ok bool, err error) {
+	row := s.db.QueryRowContext(ctx, "SELECT value FROM metadata WHERE key = ?;", key)
+	err = row.Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	return v, true, nil
+}
+
+// loadRows loads and decodes all rows of the table.  Rows that cannot be
+// decoded are logged and skipped.
+func loadRows[T any](
+	ctx context.Context,
+	s *Storage,
+	table string,
+	decode func(b []byte) (v T, err error),
+) (vals []T, err error) {
+	// #nosec G202 -- table is always one of the constant table names.
+	rows, err := s.db.QueryContext(ctx, "SELECT id, data FROM "+table+";")
+	if err != nil {
+		return nil, fmt.Errorf("querying: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, rows.Close()) }()
+
+	for rows.Next() {
+		var id string
+		var b []byte
+		err = rows.Scan(&id, &b)
+		if err != nil {
+			return nil, fmt.Errorf("scanning: %w", err)
+		}
+
+		var v T
+		v, err = openRow(ctx, s, b, decode)
+		if err != nil {
+			s.logger.WarnContext(ctx, "skipping row", "table", table, "id", id, slogutil.KeyError, err)
+
+			continue
+		}
+
+		vals = append(vals, v)
+	}
+
+	return vals, rows.Err()
+}
+
+// Store implements the [internal.FileCacheStorage] interface for *Storage.  It
+// only rewrites the rows that have changed and removes the rows that are not
+// in c.
+func (s *Storage) Store(ctx context.Context, c *internal.FileCache) (err error) {
+	profNum := len(c.Profiles)
+
+	s.logger.InfoContext(ctx, "saving profiles", "num", profNum)
+	defer s.logger.InfoContext(ctx, "saved profiles", "num", profNum)
+
+	return s.inTx(ctx, func(tx *sql.Tx) (err error) {
+		err = upsertAll(ctx, s, tx, upsertProfileQuery, c.Profiles, profileRow)
+		if err != nil {
+			return fmt.Errorf("upserting profiles: %w", err)
+		}
+
+		err = upsertAll(ctx, s, tx, upsertDeviceQuery, c.Devices, deviceRow)
+		if err != nil {
+			return fmt.Errorf("upserting devices: %w", err)
+		}
+
+		err = removeStale(ctx, tx, "profiles", c.Profiles, profileID)
+		if err != nil {
+			return fmt.Errorf("removing profiles: %w", err)
+		}
+
+		err = removeStale(ctx, tx, "devices", c.Devices, deviceID)
+		if err != nil {
+			return fmt.Errorf("removing devices: %w", err)
+		}
+
+		return setMetadata(ctx, tx, c)
+	})
+}
+
+// Update implements the [internal.FileCacheUpdater] interface for *Storage.  It
+// doesn't change the stored synchronization time, so that the data received
+// since the last full synchronization is requested again after a restart.
+func (s *Storage) Update(ctx context.Context, c *internal.FileCache) (err error) {
+	return s.inTx(ctx, func(tx *sql.Tx) (err error) {
+		err = s.deleteRemovedDevices(ctx, tx, c.Profiles)
+		if err != nil {
+			return fmt.Errorf("deleting removed devices: %w", err)
+		}
+
+		existing := make([]*agd.Profile, 0, len(c.Profiles))
+		for _, p := range c.Profiles {
+			if !p.Deleted {
+				existing = append(existing, p)
+
+				continue
+			}
+
+			err = deleteProfile(ctx, tx, p)
+			if err != nil {
+				return fmt.Errorf("deleting profile %q: %w", p.ID, err)
+			}
+		}
+
+		err = upsertAll(ctx, s, tx, upsertProfileQuery, existing, profileRow)
+		if err != nil {
+			return fmt.Errorf("upserting profiles: %w", err)
+		}
+
+		err = upsertAll(ctx, s, tx, upsertDeviceQuery, c.Devices, deviceRow)
+		if err != nil {
+			return fmt.Errorf("upserting devices: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// deleteRemovedDevices removes the devices that the stored versions of the
+// profiles have but their new versions from profiles don't.  The devices that
+// are still referenced by any of profiles are kept, since they could have been
+// moved to another profile.
+func (s *Storage) deleteRemovedDevices(
+	ctx context.Context,
+	tx *sql.Tx,
+	profiles []*agd.Profile,
+) (err error) {
+	referenced := container.NewMapSet[agd.DeviceID]()
+	for _, p := range profiles {
+		if p.Deleted {
+			continue
+		}
+
+		for _, devID := range p.DeviceIDs {
+			referenced.Add(devID)
+		}
+	}
+
+	for _, p := range profiles {
+		if p.Deleted {
+			// The devices of the deleted profiles are removed by
+			// [deleteProfile].
+			continue
+		}
+
+		var prev *agd.Profile
+		prev, err = s.storedProfile(ctx, tx, p.ID)
+		if err != nil {
+			return fmt.Errorf("profile %q: %w", p.ID, err)
+		} else if prev == nil {
+			continue
+		}
+
+		for _, devID := range prev.DeviceIDs {
+			if referenced.Has(devID) {
+				continue
+			}
+
+			_, err = tx.ExecContext(ctx, "DELETE FROM devices WHERE id = ?;", string(devID))
+			if err != nil {
+				return fmt.Errorf("device %q: %w", devID, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// storedProfile returns the stored version of the profile with the given ID.
+// prev is nil if there is no such profile or if it cannot be decoded.
+func (s *Storage) storedProfile(
+	ctx context.Context,
+	tx *sql.Tx,
+	id agd.ProfileID,
+) (prev *agd.Profile, err error) {
+	var b []byte
+	row := tx.QueryRowContext(ctx, "SELECT data FROM profiles WHERE id = ?;", string(id))
+	err = row.Scan(&b)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("querying: %w", err)
+	}
+
+	prev, err = openRow(ctx, s, b, s.unmarshalProfile)
+	if err != nil {
+		s.logger.WarnContext(ctx, "skipping stored profile", "id", id, slogutil.KeyError, err)
+
+		return nil, nil
+	}
+
+	return prev, nil
+}
+
+// inTx runs f in a transaction, which is committed if f returns no error and
+// is rolled back otherwise.
+func (s *Storage) inTx(ctx context.Context, f func(tx *sql.Tx) (err error)) (err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("starting transaction: %w", err)
+	}
+
+	err = f(tx)
+	if err != nil {
+		return errors.WithDeferred(err, tx.Rollback())
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+// Close implements the [internal.FileCacheStorage] interface for *Storage.  It
+// closes the underlying database as well as the migration storage, if any.
+func (s *Storage) Close() (err error) {
+	err = s.db.Close()
+	if err != nil {
+		err = fmt.Errorf("closing database: %w", err)
+	}
+
+	if s.migration != nil {
+		migrErr := s.migration.Close()
+		if migrErr != nil {
+			err = errors.Join(err, fmt.Errorf("closing migration storage: %w", migrErr))
+		}
+	}
+
+	return err
+}
+
+// unmarshalProfile decodes a profile from the protobuf data b.
+func (s *Storage) unmarshalProfile(b []byte) (p *agd.Profile, err error) {
+	return filecachepb.UnmarshalProfile(b, s.respSzEst)
+}
+
+// seal returns b encrypted with the current key, if s has a cipher.
+// Otherwise, it returns b.
+//
+// NOTE:  Since every encryption uses a new nonce, the rows of an encrypted
+// database are always rewritten on upsert.
+func (s *Storage) seal(ctx context.Context, b []byte) (data []byte, err error) {
+	if s.cipher == nil {
+		return b, nil
+	}
+
+	data, err = s.cipher.Seal(ctx, b)
+	if err != nil {
+		return nil, fmt.Errorf("encrypting: %w", err)
+	}
+
+	return data, nil
+}
+
+// openRow decrypts the row data b, if necessary, and decodes it using decode.
+// Plaintext data is accepted if s has a cipher, so that an existing database
+// is encrypted with the next store, but encrypted data is rejected if s has
+// none.
+func openRow[T any](
+	ctx context.Context,
+	s *Storage,
+	b []byte,
+	decode func(b []byte) (v T, err error),
+) (v T, err error) {
+	isEncrypted := cachecrypt.IsEncrypted(b)
+	switch {
+	case s.cipher == nil && isEncrypted:
+		return v, errors.Error("data is encrypted, but no keys are set")
+	case s.cipher != nil && isEncrypted:
+		b, _, err = s.cipher.Open(ctx, b)
+		if err != nil {
+			return v, fmt.Errorf("decrypting: %w", err)
+		}
+	default:
+		// Go on.
+	}
+
+	return decode(b)
+}
+
+// profileRow returns the row data for a profile.
+func profileRow(p *agd.Profile) (id string, b []byte, err error) {
+	b, err = filecachepb.MarshalProfile(p)
+
+	return string(p.ID), b, err
+}
+
+// profileID returns the row ID of a profile.
+func profileID(p *agd.Profile) (id string) { return string(p.ID) }
+
+// deviceRow returns the row data for a device.
+func deviceRow(d *agd.Device) (id string, b []byte, err error) {
+	b, err = filecachepb.MarshalDevice(d)
+
+	return string(d.ID), b, err
+}
+
+// deviceID returns the row ID of a device.
+func deviceID(d *agd.Device) (id string) { return string(d.ID) }
+
+// upsertAll adds or updates the rows for all vals using query.  The data is
+// encrypted if s has a cipher.
+func upsertAll[T any](
+	ctx context.Context,
+	s *Storage,
+	tx *sql.Tx,
+	query string,
+	vals []T,
+	toRow func(v T) (id string, b []byte, err error),
+) (err error) {
+	stmt, err := tx.PrepareContext(ctx, query)
+	if err != nil {
+		return fmt.Errorf("preparing: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, stmt.Close()) }()
+
+	for _, v := range vals {
+		id, b, rowErr := toRow(v)
+		if rowErr != nil {
+			return rowErr
+		}
+
+		b, err = s.seal(ctx, b)
+		if err != nil {
+			return fmt.Errorf("row %q: %w", id, err)
+		}
+
+		_, err = stmt.ExecContext(ctx, id, b)
+		if err != nil {
+			return fmt.Errorf("row %q: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// removeStale removes the rows of the table with the IDs not present in vals.
+func removeStale[T any](
+	ctx context.Context,
+	tx *sql.Tx,
+	table string,
+	vals []T,
+	idOf func(v T) (id string),
+) (err error) {
+	keep := make(map[string]struct{}, len(vals))
+	for _, v := range vals {
+		keep[idOf(v)] = struct{}{}
+	}
+
+	// #nosec G202 -- table is always one of the constant table names.
+	rows, err := tx.QueryContext(ctx, "SELECT id FROM "+table+";")
+	if err != nil {
+		return fmt.Errorf("querying: %w", err)
+	}
+
+	var stale []string
+	for rows.Next() {
+		var id string
+		err = rows.Scan(&id)
+		if err != nil {
+			return errors.WithDeferred(fmt.Errorf("scanning: %w", err), rows.Close())
+		}
+
+		if _, ok := keep[id]; !ok {
+			stale = append(stale, id)
+		}
+	}
+
+	err = errors.Join(rows.Err(), rows.Close())
+	if err != nil {
+		return fmt.Errorf("reading rows: %w", err)
+	}
+
+	for _, id := range stale {
+		// #nosec G202 -- table is always one of the constant table names.
+		_, err = tx.ExecContext(ctx, "DELETE FROM "+table+" WHERE id = ?;", id)
+		if err != nil {
+			return fmt.Errorf("deleting %q: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// deleteProfile removes the profile and its devices.
+func deleteProfile(ctx context.Context, tx *sql.Tx, p *agd.Profile) (err error) {
+	_, err = tx.ExecContext(ctx, "DELETE FROM profiles WHERE id = ?;", string(p.ID))
+	if err != nil {
+		return err
+	}
+
+	for _, devID := range p.DeviceIDs {
+		_, err = tx.ExecContext(ctx, "DELETE FROM devices WHERE id = ?;", string(devID))
+		if err != nil {
+			return fmt.Errorf("device %q: %w", devID, err)
+		}
+	}
+
+	return nil
+}
+
+// setMetadata sets the version and the synchronization time from c.
+func setMetadata(ctx context.Context, tx *sql.Tx, c *internal.FileCache) (err error) {
+	_, err = tx.ExecContext(ctx, upsertMetadataQuery, keyVersion, int64(c.Version))
+	if err != nil {
+		return fmt.Errorf("setting version: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, upsertMetadataQuery, keySyncTime, c.SyncTime.UnixNano())
+	if err != nil {
+		return fmt.Errorf("setting sync time: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,320 @@
+package filecachesql_test
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/cachecrypt"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal/filecachepb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal/filecachesql"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal/profiledbtest"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// newStorage is a helper that returns a new storage with the database in a
+// temporary directory.
+func newStorage(tb testing.TB, migration internal.FileCacheStorage) (s *filecachesql.Storage) {
+	tb.Helper()
+
+	ctx := testutil.ContextWithTimeout(tb, testTimeout)
+	s, err := filecachesql.New(ctx, &filecachesql.Config{
+		Logger:               slogutil.NewDiscardLogger(),
+		Migration:            migration,
+		Path:                 filepath.Join(tb.TempDir(), "profiles.db"),
+		ResponseSizeEstimate: profiledbtest.RespSzEst,
+	})
+	require.NoError(tb, err)
+	testutil.CleanupAndRequireSuccess(tb, s.Close)
+
+	return s
+}
+
+// newEncryptedStorage is a helper that returns a new storage with the database
+// at dbPath and the given cipher.
+func newEncryptedStorage(
+	tb testing.TB,
+	dbPath string,
+	cipher *cachecrypt.Cipher,
+) (s *filecachesql.Storage) {
+	tb.Helper()
+
+	ctx := testutil.ContextWithTimeout(tb, testTimeout)
+	s, err := filecachesql.New(ctx, &filecachesql.Config{
+		Logger:               slogutil.NewDiscardLogger(),
+		Cipher:               cipher,
+		Path:                 dbPath,
+		ResponseSizeEstimate: profiledbtest.RespSzEst,
+	})
+	require.NoError(tb, err)
+	testutil.CleanupAndRequireSuccess(tb, s.Close)
+
+	return s
+}
+
+// newFileCache returns a new file cache with the common profile and device for
+// tests.
+func newFileCache(tb testing.TB) (fc *internal.FileCache) {
+	tb.Helper()
+
+	prof, dev := profiledbtest.NewProfile(tb)
+
+	return &internal.FileCache{
+		SyncTime: time.Now().Round(0).UTC(),
+		Profiles: []*agd.Profile{prof},
+		Devices:  []*agd.Device{dev},
+		Version:  internal.FileCacheVersion,
+	}
+}
+
+func TestStorage(t *testing.T) {
+	s := newStorage(t, nil)
+	fc := newFileCache(t)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	err := s.Store(ctx, fc)
+	require.NoError(t, err)
+
+	gotFC, err := s.Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, gotFC)
+
+	assert.Equal(t, fc, gotFC)
+
+	err = s.Store(ctx, &internal.FileCache{
+		SyncTime: fc.SyncTime,
+		Version:  internal.FileCacheVersion,
+	})
+	require.NoError(t, err)
+
+	gotFC, err = s.Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, gotFC)
+
+	assert.Empty(t, gotFC.Profiles)
+	assert.Empty(t, gotFC.Devices)
+}
+
+func TestStorage_Load_empty(t *testing.T) {
+	s := newStorage(t, nil)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	fc, err := s.Load(ctx)
+	assert.NoError(t, err)
+	assert.Nil(t, fc)
+}
+
+func TestStorage_Load_migration(t *testing.T) {
+	pbPath := filepath.Join(t.TempDir(), "profiles.pb")
+	pbStrg := filecachepb.New(slogutil.NewDiscardLogger(), pbPath, profiledbtest.RespSzEst, nil)
+
+	fc := newFileCache(t)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	err := pbStrg.Store(ctx, fc)
+	require.NoError(t, err)
+
+	s := newStorage(t, pbStrg)
+
+	gotFC, err := s.Load(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, fc, gotFC)
+
+	// Make sure that the data is now in the database.
+	err = pbStrg.Store(ctx, &internal.FileCache{Version: internal.FileCacheVersion})
+	require.NoError(t, err)
+
+	gotFC, err = s.Load(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, fc, gotFC)
+}
+
+func TestStorage_Update(t *testing.T) {
+	s := newStorage(t, nil)
+	fc := newFileCache(t)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	err := s.Store(ctx, fc)
+	require.NoError(t, err)
+
+	prof := fc.Profiles[0]
+	deletedProf := &agd.Profile{
+		ID:        prof.ID,
+		DeviceIDs: prof.DeviceIDs,
+		Deleted:   true,
+	}
+
+	err = s.Update(ctx, &internal.FileCache{
+		SyncTime: time.Now(),
+		Profiles: []*agd.Profile{deletedProf},
+		Version:  internal.FileCacheVersion,
+	})
+	require.NoError(t, err)
+
+	gotFC, err := s.Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, gotFC)
+
+	assert.Empty(t, gotFC.Profiles)
+	assert.Empty(t, gotFC.Devices)
+
+	// The synchronization time must not change on updates.
+	assert.Equal(t, fc.SyncTime, gotFC.SyncTime)
+}
+
+func TestStorage_Update_removedDevices(t *testing.T) {
+	s := newStorage(t, nil)
+	fc := newFileCache(t)
+
+	prof, dev := fc.Profiles[0], fc.Devices[0]
+
+	const (
+		devIDMoved   agd.DeviceID = "moved"
+		devIDRemoved agd.DeviceID = "removed"
+	)
+
+	devMoved := &agd.Device{ID: devIDMoved}
+	devRemoved := &agd.Device{ID: devIDRemoved}
+
+	otherProf := &agd.Profile{}
+	*otherProf = *prof
+	otherProf.ID = "other"
+	otherProf.DeviceIDs = nil
+
+	prof.DeviceIDs = []agd.DeviceID{dev.ID, devIDMoved, devIDRemoved}
+	fc.Profiles = append(fc.Profiles, otherProf)
+	fc.Devices = append(fc.Devices, devMoved, devRemoved)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	err := s.Store(ctx, fc)
+	require.NoError(t, err)
+
+	newProf := &agd.Profile{}
+	*newProf = *prof
+	newProf.DeviceIDs = []agd.DeviceID{dev.ID}
+
+	newOtherProf := &agd.Profile{}
+	*newOtherProf = *otherProf
+	newOtherProf.DeviceIDs = []agd.DeviceID{devIDMoved}
+
+	err = s.Update(ctx, &internal.FileCache{
+		SyncTime: time.Now(),
+		Profiles: []*agd.Profile{newProf, newOtherProf},
+		Version:  internal.FileCacheVersion,
+	})
+	require.NoError(t, err)
+
+	gotFC, err := s.Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, gotFC)
+
+	gotIDs := make([]agd.DeviceID, 0, len(gotFC.Devices))
+	for _, d := range gotFC.Devices {
+		gotIDs = append(gotIDs, d.ID)
+	}
+
+	assert.ElementsMatch(t, []agd.DeviceID{dev.ID, devIDMoved}, gotIDs)
+}
+
+func TestStorage_encryption(t *testing.T) {
+	fc := newFileCache(t)
+
+	oldKey := &cachecrypt.Key{1}
+	newKey := &cachecrypt.Key{2}
+
+	dbPath := filepath.Join(t.TempDir(), "profiles.db")
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	plain := newEncryptedStorage(t, dbPath, nil)
+	require.NoError(t, plain.Store(ctx, fc))
+
+	t.Run("encrypt_plaintext", func(t *testing.T) {
+		cipher := cachecrypt.NewCipher(cachecrypt.NewStaticKeys(oldKey))
+		s := newEncryptedStorage(t, dbPath, cipher)
+
+		gotFC, err := s.Load(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, fc, gotFC)
+
+		err = s.Store(ctx, gotFC)
+		require.NoError(t, err)
+
+		assertEncrypted(t, dbPath)
+	})
+
+	t.Run("no_keys", func(t *testing.T) {
+		gotFC, err := plain.Load(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, gotFC)
+
+		assert.Empty(t, gotFC.Profiles)
+		assert.Empty(t, gotFC.Devices)
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		cipher := cachecrypt.NewCipher(cachecrypt.NewStaticKeys(newKey, oldKey))
+		s := newEncryptedStorage(t, dbPath, cipher)
+
+		gotFC, err := s.Load(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, fc, gotFC)
+
+		err = s.Store(ctx, gotFC)
+		require.NoError(t, err)
+
+		assertEncrypted(t, dbPath)
+	})
+
+	t.Run("old_key_removed", func(t *testing.T) {
+		cipher := cachecrypt.NewCipher(cachecrypt.NewStaticKeys(newKey))
+		s := newEncryptedStorage(t, dbPath, cipher)
+
+		gotFC, err := s.Load(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, fc, gotFC)
+	})
+}
+
+// assertEncrypted is a test helper that checks that all profile and device
+// rows of the database at dbPath are encrypted.
+func assertEncrypted(tb testing.TB, dbPath string) {
+	tb.Helper()
+
+	db, err := sql.Open("sqlite", dbPath)
+	require.NoError(tb, err)
+	testutil.CleanupAndRequireSuccess(tb, db.Close)
+
+	for _, table := range []string{"profiles", "devices"} {
+		rows, qErr := db.Query("SELECT data FROM " + table + ";")
+		require.NoError(tb, qErr)
+
+		n := 0
+		for rows.Next() {
+			var b []byte
+			require.NoError(tb, rows.Scan(&b))
+
+			assert.True(tb, cachecrypt.IsEncrypted(b))
+			n++
+		}
+
+		require.NoError(tb, rows.Err())
+		require.NoError(tb, rows.Close())
+
+		assert.Positive(tb, n)
+	}
+}
@@ -13,7 +13,7 @@ import (
 // FileCacheVersion is the version of cached data structure.  It must be
 // manually incremented on every change in [agd.Device], [agd.Profile], and any
 // file-cache structures.
-const FileCacheVersion = 15
+const FileCacheVersion = 27
 
 // CacheVersionError is returned from [FileCacheStorage.Load] method if the
 // stored cache version doesn't match current [FileCacheVersion].
@@ -36,6 +36,10 @@ type FileCacheStorage interface {
 	// Store writes the data to the cache file.  c must not be nil.  Store must
 	// return an informative error.
 	Store(ctx context.Context, c *FileCache) (err error)
+
+	// Close releases the resources of the storage.  The storage must not be
+	// used after it is closed.  Close must return an informative error.
+	Close() (err error)
 }
 
 // EmptyFileCacheStorage is the empty file-cache storage that does nothing and
@@ -52,3 +56,19 @@ func (EmptyFileCacheStorage) Load(_ context.Context) (_ *FileCache, _ error) { r
 // Store implements the [FileCacheStorage] interface for EmptyFileCacheStorage.
 // It does nothing and returns nil.
 func (EmptyFileCacheStorage) Store(_ context.Context, _ *FileCache) (_ error) { return nil }
+
+// Close implements the [FileCacheStorage] interface for EmptyFileCacheStorage.
+// It does nothing and returns nil.
+func (EmptyFileCacheStorage) Close() (_ error) { return nil }
+
+// FileCacheUpdater is the optional interface for file caches that support
+// incremental updates between full synchronizations.
+type FileCacheUpdater interface {
+	// Update adds or replaces the profiles and devices from c and removes the
+	// profiles marked as deleted along with their devices as well as the
+	// devices that have been removed from the profiles in c and aren't
+	// referenced by any other profile in c.  Unlike [FileCacheStorage.Store],
+	// it must not remove any other data.  c must not be nil.  Update must return an
+	// informative error.
+	Update(ctx context.Context, c *FileCache) (err error)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
 	"github.com/c2h5oh/datasize"
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/require"
 )
 
@@ -49,14 +50,20 @@ func NewProfile(tb testing.TB) (p *agd.Profile, d *agd.Device) {
 			Enabled:      true,
 			DoHAuthOnly:  true,
 			PasswordHash: agdpasswd.NewPasswordHashBcrypt([]byte("test")),
+			DoHQueryKey:  []byte("test-key"),
 		},
-		ID:       DeviceID,
-		LinkedIP: netip.MustParseAddr("1.2.3.4"),
-		Name:     "dev1",
+		ID:        DeviceID,
+		CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		LinkedIP:  netip.MustParseAddr("1.2.3.4"),
+		Name:      "dev1",
 		DedicatedIPs: []netip.Addr{
 			netip.MustParseAddr("1.2.4.5"),
 		},
+		DedicatedPrefixes: []netip.Prefix{
+			netip.MustParsePrefix("2001:db8:1::/48"),
+		},
 		FilteringEnabled: true,
+		Quarantined:      true,
 	}
 
 	const schedEnd = 701
@@ -74,11 +81,16 @@ func NewProfile(tb testing.TB) (p *agd.Profile, d *agd.Device) {
 			},
 			TimeZone: loc,
 		},
-		Enabled: true,
+		LearningPeriod: 7 * 24 * time.Hour,
+		Enabled:        true,
 	}
 
 	return &agd.Profile{
 		FilterConfig: &filter.ConfigClient{
+			BlockPage: &filter.ConfigBlockPage{
+				IPv4: netip.MustParseAddr("192.0.2.1"),
+				IPv6: netip.MustParseAddr("2001:db8::1"),
+			},
 			Custom: &filter.ConfigCustom{
 				ID:         string(ProfileID),
 				UpdateTime: time.Now().UTC(),
@@ -95,6 +107,8 @@ func NewProfile(tb testing.TB) (p *agd.Profile, d *agd.Device) {
 				DangerousDomainsEnabled:       true,
 				NewlyRegisteredDomainsEnabled: false,
 			},
+			BlockUntil:    time.Now().Add(1 * time.Hour).UTC(),
+			AllowlistOnly: true,
 		},
 		Access: access.NewDefaultProfile(&access.ProfileConfig{
 			AllowedNets:          []netip.Prefix{netip.MustParsePrefix("1.1.1.0/24")},
@@ -109,16 +123,29 @@ func NewProfile(tb testing.TB) (p *agd.Profile, d *agd.Device) {
 			RPS:           100,
 			Enabled:       true,
 		}, RespSzEst),
+		AccountLimits: &agd.AccountLimits{
+			Tier:       "team",
+			RPS:        1000,
+			MaxDevices: 20,
+		},
+		QueryTypeActions: map[dnsmsg.RRType]agd.QueryTypeAction{
+			dns.TypeAAAA: agd.QueryTypeActionNODATA,
+			dns.TypeANY:  agd.QueryTypeActionRefused,
+		},
 		ID:                  ProfileID,
+		AccountID:           "acc1234",
 		DeviceIDs:           []agd.DeviceID{dev.ID},
 		FilteredResponseTTL: 10 * time.Second,
+		ECSMode:             agd.ECSModeStrip,
 		AutoDevicesEnabled:  true,
 		BlockChromePrefetch: true,
 		BlockFirefoxCanary:  true,
 		BlockPrivateRelay:   true,
 		Deleted:             false,
 		FilteringEnabled:    true,
+		ForwardLocalZones:   true,
 		IPLogEnabled:        true,
 		QueryLogEnabled:     true,
+		RequireDNSSEC:       true,
 	}, dev
 }
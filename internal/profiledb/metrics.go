@@ -21,6 +21,15 @@ type Metrics interface {
 
 	// IncrementDeleted increments the total number of deleted user profiles.
 	IncrementDeleted(ctx context.Context)
+
+	// IncrementPruned increments the total numbers of user profiles and
+	// devices removed by the garbage collection.
+	IncrementPruned(ctx context.Context, profNum, devNum uint)
+
+	// IncrementHumanIDCacheLookups increments the number of lookups in the
+	// cache of devices by their human-readable IDs.  isNegative is true if the
+	// lookup is for a failed automatic creation of a device.
+	IncrementHumanIDCacheLookups(ctx context.Context, hit, isNegative bool)
 }
 
 // UpdateMetrics is an alias for a structure that contains the information about
@@ -64,3 +73,10 @@ func (EmptyMetrics) IncrementSyncTimeouts(_ context.Context, _ bool) {}
 
 // IncrementDeleted implements the [Metrics] interface for EmptyMetrics.
 func (EmptyMetrics) IncrementDeleted(_ context.Context) {}
+
+// IncrementPruned implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) IncrementPruned(_ context.Context, _, _ uint) {}
+
+// IncrementHumanIDCacheLookups implements the [Metrics] interface for
+// EmptyMetrics.
+func (EmptyMetrics) IncrementHumanIDCacheLookups(_ context.Context, _, _ bool) {}
@@ -0,0 +1,131 @@
+package profiledb
+
+import (
+	"net/netip"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// prefixTrie is a binary trie that maps IPv6 prefixes to the IDs of the devices
+// these prefixes are dedicated to.  It is not safe for concurrent use.
+type prefixTrie struct {
+	root *prefixTrieNode
+}
+
+// prefixTrieNode is a node of a [prefixTrie].
+type prefixTrieNode struct {
+	// children are the nodes for the next bit being 0 and 1 respectively.
+	children [2]*prefixTrieNode
+
+	// id is the ID of the device that the prefix ending at this node is
+	// dedicated to.  It is empty if there is no such prefix.
+	id agd.DeviceID
+}
+
+// newPrefixTrie returns a new empty *prefixTrie.
+func newPrefixTrie() (t *prefixTrie) {
+	return &prefixTrie{
+		root: &prefixTrieNode{},
+	}
+}
+
+// addrBit returns the bit of b at index i, counting from the most significant
+// one.
+func addrBit(b *[16]byte, i int) (bit byte) {
+	return b[i/8] >> (7 - i%8) & 1
+}
+
+// set maps p to id.  p must be a masked IPv6 prefix and id must not be empty.
+func (t *prefixTrie) set(p netip.Prefix, id agd.DeviceID) {
+	b := p.Addr().As16()
+	n := t.root
+	for i := range p.Bits() {
+		bit := addrBit(&b, i)
+		if n.children[bit] == nil {
+			n.children[bit] = &prefixTrieNode{}
+		}
+
+		n = n.children[bit]
+	}
+
+	n.id = id
+}
+
+// get returns the ID of the device that exactly p is mapped to, if any.
+func (t *prefixTrie) get(p netip.Prefix) (id agd.DeviceID, ok bool) {
+	b := p.Addr().As16()
+	n := t.root
+	for i := range p.Bits() {
+		n = n.children[addrBit(&b, i)]
+		if n == nil {
+			return "", false
+		}
+	}
+
+	return n.id, n.id != ""
+}
+
+// lookup returns the longest prefix containing ip and the ID of the device it
+// is mapped to, if any.  ip must be valid.
+func (t *prefixTrie) lookup(ip netip.Addr) (p netip.Prefix, id agd.DeviceID, ok bool) {
+	if !ip.Is6() || ip.Is4In6() {
+		return netip.Prefix{}, "", false
+	}
+
+	b := ip.As16()
+	n := t.root
+	bits := -1
+	for i := 0; n != nil; i++ {
+		if n.id != "" {
+			id, bits = n.id, i
+		}
+
+		if i == len(b)*8 {
+			break
+		}
+
+		n = n.children[addrBit(&b, i)]
+	}
+
+	if bits < 0 {
+		return netip.Prefix{}, "", false
+	}
+
+	p, _ = ip.Prefix(bits)
+
+	return p, id, true
+}
+
+// remove removes the mapping of p, if any, and the nodes that are no longer
+// needed.  p must be a masked IPv6 prefix.
+func (t *prefixTrie) remove(p netip.Prefix) {
+	b := p.Addr().As16()
+	path := make([]*prefixTrieNode, 0, p.Bits()+1)
+
+	n := t.root
+	for i := range p.Bits() {
+		path = append(path, n)
+		n = n.children[addrBit(&b, i)]
+		if n == nil {
+			return
+		}
+	}
+
+	n.id = ""
+
+	// Remove the nodes that don't have a mapping or any children, going up
+	// from the node of p.
+	for i := len(path) - 1; i >= 0; i-- {
+		if n.id != "" || n.children[0] != nil || n.children[1] != nil {
+			return
+		}
+
+		path[i].children[addrBit(&b, i)] = nil
+		n = path[i]
+	}
+}
+
+// clear removes all mappings from t.
+func (t *prefixTrie) clear() {
+	t.root = &prefixTrieNode{}
+}
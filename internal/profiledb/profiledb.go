@@ -8,17 +8,23 @@ import (
 	"net/netip"
 	"path/filepath"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/auditlog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/cachecrypt"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal/filecachepb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal/filecachesql"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/osutil"
+	"github.com/AdguardTeam/golibs/service"
 	"github.com/AdguardTeam/golibs/timeutil"
 	"github.com/c2h5oh/datasize"
 )
@@ -124,6 +130,9 @@ type Config struct {
 	// Logger is used for logging the operation of profile database.
 	Logger *slog.Logger
 
+	// AuditLog is used to record the applied refreshes.
+	AuditLog auditlog.Interface
+
 	// Storage returns the data for this profile DB.
 	Storage Storage
 
@@ -133,8 +142,15 @@ type Config struct {
 	// Metrics is used for the collection of the user profiles statistics.
 	Metrics Metrics
 
+	// CacheKeys is the optional source of the keys used to encrypt the
+	// profile cache, both the protobuf and the SQLite one.  If it is nil, the
+	// cache is not encrypted.
+	CacheKeys cachecrypt.KeySource
+
 	// CacheFilePath is the path to the profile cache file.  If cacheFilePath is
-	// the string "none", filesystem cache is disabled.
+	// the string "none", filesystem cache is disabled.  Files with the ".pb"
+	// extension are protobuf caches, and files with the ".db" or ".sqlite"
+	// extensions are SQLite databases.
 	CacheFilePath string
 
 	// FullSyncIvl is the interval between two full synchronizations with the
@@ -145,6 +161,30 @@ type Config struct {
 	// synchronizations with the storage.
 	FullSyncRetryIvl time.Duration
 
+	// FullSyncPageSize is the maximum number of profiles received from the
+	// storage in one page of a full synchronization.  If a page fails, the
+	// next retry resumes the synchronization from that page.  If it is zero,
+	// all profiles are received at once.
+	FullSyncPageSize uint32
+
+	// GCRetention is the time after which the profiles marked as deleted and
+	// the devices that don't belong to any profile are removed from the memory
+	// and the cache between full synchronizations.  If it is zero, they are
+	// only removed by full synchronizations.
+	GCRetention time.Duration
+
+	// HumanIDCacheCount is the maximum number of entries in the cache of the
+	// lookups of devices by their human-readable IDs.  If it is zero, the
+	// cache is disabled.
+	HumanIDCacheCount int
+
+	// HumanIDNegativeTTL is the time for which the failed automatic creations
+	// of devices are cached, so that repeated requests with the same unknown
+	// human-readable IDs don't reach the storage.  If it is zero, the failed
+	// creations are not cached.  It has no effect if HumanIDCacheCount is
+	// zero.
+	HumanIDNegativeTTL time.Duration
+
 	// ResponseSizeEstimate is the estimate of the size of one DNS response for
 	// the purposes of custom ratelimiting.  Responses over this estimate are
 	// counted as several responses.
@@ -157,8 +197,12 @@ type Config struct {
 type Default struct {
 	logger *slog.Logger
 
+	// auditLog is used to record the applied refreshes.
+	auditLog auditlog.Interface
+
 	// mapsMu protects the profiles, devices, deviceIDToProfileID,
-	// dedicatedIPToDeviceID, humanIDToDeviceID, and linkedIPToDeviceID maps.
+	// dedicatedIPToDeviceID, humanIDToDeviceID, linkedIPToDeviceID, deletedAt,
+	// and orphanedAt maps as well as the dedicatedPrefixToDeviceID trie.
 	mapsMu *sync.RWMutex
 
 	// refreshMu serializes Refresh calls and access to all values used inside
@@ -177,6 +221,11 @@ type Default struct {
 	// storage returns the data for this profile DB.
 	storage Storage
 
+	// humanIDCache contains the results of the lookups of devices by their
+	// human-readable IDs as well as the failed automatic creations of devices.
+	// See [newHumanIDCache].
+	humanIDCache agdcache.Interface[humanIDKey, agd.DeviceID]
+
 	// profiles maps profile IDs to profile records.
 	profiles map[agd.ProfileID]*agd.Profile
 
@@ -187,6 +236,11 @@ type Default struct {
 	// devices.
 	dedicatedIPToDeviceID map[netip.Addr]agd.DeviceID
 
+	// dedicatedPrefixToDeviceID maps dedicated IPv6 prefixes to the IDs of
+	// their devices.  It is only used if there is no device with the exact
+	// dedicated IP address.
+	dedicatedPrefixToDeviceID *prefixTrie
+
 	// deviceIDToProfileID maps device IDs to the ID of their profile.
 	deviceIDToProfileID map[agd.DeviceID]agd.ProfileID
 
@@ -197,11 +251,23 @@ type Default struct {
 	// linkedIPToDeviceID maps linked IP addresses to the IDs of their devices.
 	linkedIPToDeviceID map[netip.Addr]agd.DeviceID
 
+	// deletedAt maps the IDs of the profiles marked as deleted to the time
+	// when they have been received.
+	deletedAt map[agd.ProfileID]time.Time
+
+	// orphanedAt maps the IDs of the devices that don't belong to any profile
+	// to the time when the garbage collection has first found them.
+	orphanedAt map[agd.DeviceID]time.Time
+
 	// syncTime is the time of the last synchronization point.  It is received
 	// from the storage during a refresh and is then used in consecutive
 	// requests to the storage, unless it's a full synchronization.
 	syncTime time.Time
 
+	// fullSyncTime is the synchronization point of the last successful full
+	// synchronization.
+	fullSyncTime time.Time
+
 	// lastFullSync is the time of the last successful full synchronization.
 	lastFullSync time.Time
 
@@ -210,6 +276,13 @@ type Default struct {
 	// field is time.Time{}.
 	lastFullSyncError time.Time
 
+	// fullSync is the state of the current paged full synchronization.  It is
+	// nil if there is no full synchronization in progress.
+	fullSync *fullSyncState
+
+	// lastGC is the time of the last garbage collection.
+	lastGC time.Time
+
 	// fullSyncIvl is the interval between two full synchronizations with the
 	// storage.
 	fullSyncIvl time.Duration
@@ -217,6 +290,53 @@ type Default struct {
 	// fullSyncRetryIvl is the interval between two retries of full
 	// synchronizations with the storage.
 	fullSyncRetryIvl time.Duration
+
+	// fullSyncPageSize is the maximum number of profiles in one page of a full
+	// synchronization.
+	fullSyncPageSize uint32
+
+	// gcRetention is the time after which the deleted profiles and the
+	// unreferenced devices are removed.  If it is zero, the garbage collection
+	// is disabled.
+	gcRetention time.Duration
+
+	// humanIDNegativeTTL is the time for which the failed automatic creations
+	// of devices are cached.  If it is zero, they are not cached.
+	humanIDNegativeTTL time.Duration
+}
+
+// fullSyncState is the state of a paged full synchronization, which allows
+// resuming it from the page that has failed.
+type fullSyncState struct {
+	// start is the time when the synchronization has started.
+	start time.Time
+
+	// syncTime is the synchronization point received with the first page.
+	syncTime time.Time
+
+	// pageToken is the token of the next page to receive.
+	pageToken string
+
+	// profiles are the profiles received with the previous pages.
+	profiles []*agd.Profile
+
+	// devices are the devices received with the previous pages.
+	devices []*agd.Device
+
+	// pages is the number of the received pages.
+	pages uint
+}
+
+// addPage adds the data of a successfully received page to s.
+func (s *fullSyncState) addPage(page *StorageProfilesResponse) {
+	if s.pages == 0 {
+		s.syncTime = page.SyncTime
+	}
+
+	s.pages++
+	s.pageToken = page.NextPageToken
+	s.profiles = append(s.profiles, page.Profiles...)
+	s.devices = append(s.devices, page.Devices...)
 }
 
 // humanIDKey is the data necessary to identify a device by the lowercase
@@ -235,40 +355,45 @@ type humanIDKey struct {
 // filesystem cache is disabled.  db is not nil if the error is from getting the
 // file cache.
 func New(c *Config) (db *Default, err error) {
-	var cacheStorage internal.FileCacheStorage
-	if c.CacheFilePath == "none" {
-		cacheStorage = internal.EmptyFileCacheStorage{}
-	} else if ext := filepath.Ext(c.CacheFilePath); ext == ".pb" {
-		logger := c.Logger.With("cache_type", "pb")
-		cacheStorage = filecachepb.New(logger, c.CacheFilePath, c.ResponseSizeEstimate)
-	} else {
-		return nil, fmt.Errorf("file %q is not protobuf", c.CacheFilePath)
+	// TODO(a.garipov):  Separate the file cache read and use context from the
+	// arguments.
+	ctx := context.Background()
+
+	cacheStorage, err := newFileCacheStorage(ctx, c)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
 	}
 
 	db = &Default{
-		logger:                c.Logger,
-		mapsMu:                &sync.RWMutex{},
-		refreshMu:             &sync.Mutex{},
-		errColl:               c.ErrColl,
-		metrics:               c.Metrics,
-		cache:                 cacheStorage,
-		storage:               c.Storage,
-		syncTime:              time.Time{},
-		lastFullSync:          time.Time{},
-		lastFullSyncError:     time.Time{},
-		profiles:              make(map[agd.ProfileID]*agd.Profile),
-		devices:               make(map[agd.DeviceID]*agd.Device),
-		deviceIDToProfileID:   make(map[agd.DeviceID]agd.ProfileID),
-		dedicatedIPToDeviceID: make(map[netip.Addr]agd.DeviceID),
-		humanIDToDeviceID:     make(map[humanIDKey]agd.DeviceID),
-		linkedIPToDeviceID:    make(map[netip.Addr]agd.DeviceID),
-		fullSyncIvl:           c.FullSyncIvl,
-		fullSyncRetryIvl:      c.FullSyncRetryIvl,
+		logger:                    c.Logger,
+		auditLog:                  c.AuditLog,
+		mapsMu:                    &sync.RWMutex{},
+		refreshMu:                 &sync.Mutex{},
+		errColl:                   c.ErrColl,
+		metrics:                   c.Metrics,
+		cache:                     cacheStorage,
+		storage:                   c.Storage,
+		humanIDCache:              newHumanIDCache(c.HumanIDCacheCount),
+		syncTime:                  time.Time{},
+		lastFullSync:              time.Time{},
+		lastFullSyncError:         time.Time{},
+		profiles:                  make(map[agd.ProfileID]*agd.Profile),
+		devices:                   make(map[agd.DeviceID]*agd.Device),
+		deviceIDToProfileID:       make(map[agd.DeviceID]agd.ProfileID),
+		dedicatedIPToDeviceID:     make(map[netip.Addr]agd.DeviceID),
+		dedicatedPrefixToDeviceID: newPrefixTrie(),
+		humanIDToDeviceID:         make(map[humanIDKey]agd.DeviceID),
+		linkedIPToDeviceID:        make(map[netip.Addr]agd.DeviceID),
+		deletedAt:                 make(map[agd.ProfileID]time.Time),
+		orphanedAt:                make(map[agd.DeviceID]time.Time),
+		fullSyncIvl:               c.FullSyncIvl,
+		fullSyncRetryIvl:          c.FullSyncRetryIvl,
+		fullSyncPageSize:          c.FullSyncPageSize,
+		gcRetention:               c.GCRetention,
+		humanIDNegativeTTL:        c.HumanIDNegativeTTL,
 	}
 
-	// TODO(a.garipov):  Separate the file cache read and use context from the
-	// arguments.
-	ctx := context.Background()
 	err = db.loadFileCache(ctx)
 	if err != nil {
 		db.logger.WarnContext(ctx, "error loading fs cache", slogutil.KeyError, err)
@@ -277,6 +402,77 @@ func New(c *Config) (db *Default, err error) {
 	return db, nil
 }
 
+// newFileCacheStorage returns a new file-cache storage depending on the
+// extension of c.CacheFilePath.  For SQLite databases, the data is migrated
+// from the protobuf file with the same name and the ".pb" extension, if the
+// database is empty.
+func newFileCacheStorage(
+	ctx context.Context,
+	c *Config,
+) (cacheStorage internal.FileCacheStorage, err error) {
+	cachePath := c.CacheFilePath
+	if cachePath == "none" {
+		return internal.EmptyFileCacheStorage{}, nil
+	}
+
+	var cipher *cachecrypt.Cipher
+	if c.CacheKeys != nil {
+		cipher = cachecrypt.NewCipher(c.CacheKeys)
+	}
+
+	switch ext := filepath.Ext(cachePath); ext {
+	case ".pb":
+		logger := c.Logger.With("cache_type", "pb")
+
+		return filecachepb.New(logger, cachePath, c.ResponseSizeEstimate, cipher), nil
+	case ".db", ".sqlite":
+		pbPath := strings.TrimSuffix(cachePath, ext) + ".pb"
+		pbLogger := c.Logger.With("cache_type", "pb")
+
+		cacheStorage, err = filecachesql.New(ctx, &filecachesql.Config{
+			Logger:               c.Logger.With("cache_type", "sqlite"),
+			Cipher:               cipher,
+			Migration:            filecachepb.New(pbLogger, pbPath, c.ResponseSizeEstimate, cipher),
+			Path:                 cachePath,
+			ResponseSizeEstimate: c.ResponseSizeEstimate,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("creating sqlite cache: %w", err)
+		}
+
+		return cacheStorage, nil
+	default:
+		return nil, fmt.Errorf("file %q is neither protobuf nor sqlite", cachePath)
+	}
+}
+
+// type check
+var _ service.Interface = (*Default)(nil)
+
+// Start implements the [service.Interface] interface for *Default.  It does
+// nothing and returns nil, since the database is refreshed by a separate
+// worker.
+func (db *Default) Start(_ context.Context) (err error) {
+	return nil
+}
+
+// Shutdown implements the [service.Interface] interface for *Default.  It waits
+// for the current refresh, if any, to finish and closes the filesystem-cache
+// storage.  db must not be refreshed after that.
+func (db *Default) Shutdown(ctx context.Context) (err error) {
+	db.refreshMu.Lock()
+	defer db.refreshMu.Unlock()
+
+	err = db.cache.Close()
+	if err != nil {
+		return fmt.Errorf("closing cache: %w", err)
+	}
+
+	db.logger.DebugContext(ctx, "closed cache")
+
+	return nil
+}
+
 // type check
 var _ agdservice.Refresher = (*Default)(nil)
 
@@ -342,10 +538,20 @@ func (db *Default) Refresh(ctx context.Context) (err error) {
 		"dev_num", devNum,
 	)
 
-	db.setProfiles(ctx, profiles, devices, isFullSync)
+	removedDevNum := db.setProfiles(ctx, profiles, devices, isFullSync)
+	db.auditLog.Record(ctx, &auditlog.Event{
+		Data: &auditlog.ProfilesRefresh{
+			ProfilesNum:       profNum,
+			DevicesNum:        devNum,
+			RemovedDevicesNum: removedDevNum,
+			IsFullSync:        isFullSync,
+		},
+		Type: auditlog.EventTypeProfilesRefresh,
+	})
 
 	db.syncTime = resp.SyncTime
 	if isFullSync {
+		db.fullSyncTime = resp.SyncTime
 		db.lastFullSync = time.Now()
 		db.lastFullSyncError = time.Time{}
 
@@ -358,9 +564,26 @@ func (db *Default) Refresh(ctx context.Context) (err error) {
 		if err != nil {
 			return fmt.Errorf("saving cache: %w", err)
 		}
+	} else if updater, ok := db.cache.(internal.FileCacheUpdater); ok {
+		err = updater.Update(ctx, &internal.FileCache{
+			SyncTime: resp.SyncTime,
+			Profiles: profiles,
+			Devices:  devices,
+			Version:  internal.FileCacheVersion,
+		})
+		if err != nil {
+			return fmt.Errorf("updating cache: %w", err)
+		}
 	}
 
-	return nil
+	if isFullSync {
+		// A full synchronization replaces all data, so there is nothing to
+		// collect.
+		return nil
+	}
+
+	// Don't wrap the error, because it's informative enough as is.
+	return db.collectGarbage(ctx)
 }
 
 // fetchProfiles fetches the profiles and devices from the storage.  It returns
@@ -372,31 +595,81 @@ func (db *Default) fetchProfiles(
 	sinceLastAttempt time.Duration,
 	isFullSync bool,
 ) (sr *StorageProfilesResponse, err error) {
-	syncTime := db.syncTime
 	if isFullSync {
 		db.logger.InfoContext(ctx, "full sync", "since_last_attempt", timeutil.Duration{
 			Duration: sinceLastAttempt,
 		})
 
-		syncTime = time.Time{}
+		return db.fetchFullSync(ctx)
 	}
 
 	sr, err = db.storage.Profiles(ctx, &StorageProfilesRequest{
-		SyncTime: syncTime,
+		SyncTime: db.syncTime,
 	})
-	if err == nil {
-		return sr, nil
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			db.metrics.IncrementSyncTimeouts(ctx, false)
+		}
+
+		return nil, fmt.Errorf("updating profiles: %w", err)
 	}
 
-	if isFullSync {
-		db.lastFullSyncError = time.Now()
+	return sr, nil
+}
+
+// fetchFullSync fetches all pages of a full synchronization from the storage,
+// resuming the previous unfinished synchronization, if there is one.  The pages
+// received before an error are kept for the next attempt.  It must only be
+// called under the refreshMu lock.
+func (db *Default) fetchFullSync(ctx context.Context) (sr *StorageProfilesResponse, err error) {
+	fs := db.fullSync
+	if fs != nil && time.Since(fs.start) >= db.fullSyncIvl {
+		// Don't resume synchronizations that are too old, since their page
+		// tokens have likely expired.
+		db.logger.InfoContext(ctx, "discarding stale full sync", "pages", fs.pages)
+
+		fs = nil
 	}
 
-	if errors.Is(err, context.DeadlineExceeded) {
-		db.metrics.IncrementSyncTimeouts(ctx, isFullSync)
+	if fs == nil {
+		fs = &fullSyncState{
+			start: time.Now(),
+		}
+		db.fullSync = fs
+	} else {
+		db.logger.InfoContext(ctx, "resuming full sync", "pages", fs.pages)
 	}
 
-	return nil, fmt.Errorf("updating profiles: %w", err)
+	for {
+		var page *StorageProfilesResponse
+		page, err = db.storage.Profiles(ctx, &StorageProfilesRequest{
+			PageToken: fs.pageToken,
+			PageSize:  db.fullSyncPageSize,
+		})
+		if err != nil {
+			db.lastFullSyncError = time.Now()
+			if errors.Is(err, context.DeadlineExceeded) {
+				db.metrics.IncrementSyncTimeouts(ctx, true)
+			}
+
+			return nil, fmt.Errorf("updating profiles: page %d: %w", fs.pages+1, err)
+		}
+
+		fs.addPage(page)
+		if fs.pageToken == "" {
+			break
+		}
+
+		db.logger.DebugContext(ctx, "received full sync page", "pages", fs.pages)
+	}
+
+	db.fullSync = nil
+
+	return &StorageProfilesResponse{
+		SyncTime: fs.syncTime,
+		Profiles: fs.profiles,
+		Devices:  fs.devices,
+	}, nil
 }
 
 // needsFullSync determines if a full synchronization is necessary.  If the last
@@ -465,31 +738,45 @@ func (db *Default) loadFileCache(ctx context.Context) (err error) {
 	}
 
 	db.setProfiles(ctx, c.Profiles, c.Devices, true)
-	db.syncTime, db.lastFullSync = c.SyncTime, c.SyncTime
+	db.syncTime, db.lastFullSync, db.fullSyncTime = c.SyncTime, c.SyncTime, c.SyncTime
 
 	return nil
 }
 
 // setProfiles adds or updates the data for all profiles and devices.
+// removedDevNum is the number of devices that are removed by the update.
 func (db *Default) setProfiles(
 	ctx context.Context,
 	profiles []*agd.Profile,
 	devices []*agd.Device,
 	isFullSync bool,
-) {
+) (removedDevNum uint) {
 	db.mapsMu.Lock()
 	defer db.mapsMu.Unlock()
 
 	if isFullSync {
+		removedDevNum = db.removedDevicesNum(devices)
+
 		clear(db.profiles)
 		clear(db.devices)
 		clear(db.dedicatedIPToDeviceID)
+		db.dedicatedPrefixToDeviceID.clear()
 		clear(db.deviceIDToProfileID)
 		clear(db.humanIDToDeviceID)
 		clear(db.linkedIPToDeviceID)
+		clear(db.deletedAt)
+		clear(db.orphanedAt)
+
+		db.humanIDCache.Clear()
 	}
 
+	now := time.Now()
+
 	for _, p := range profiles {
+		if prev, ok := db.profiles[p.ID]; ok {
+			removedDevNum += removedProfileDevicesNum(prev, p)
+		}
+
 		db.profiles[p.ID] = p
 
 		for _, devID := range p.DeviceIDs {
@@ -503,10 +790,52 @@ func (db *Default) setProfiles(
 			// the profiles slice does not include the deleted profiles, so we
 			// can update metric correctly.
 			db.metrics.IncrementDeleted(ctx)
+
+			if _, ok := db.deletedAt[p.ID]; !ok {
+				db.deletedAt[p.ID] = now
+			}
+		} else {
+			delete(db.deletedAt, p.ID)
 		}
 	}
 
 	db.setDevices(ctx, devices)
+
+	return removedDevNum
+}
+
+// removedDevicesNum returns the number of the current devices that are missing
+// from devices.  It assumes that db.mapsMu is locked.
+func (db *Default) removedDevicesNum(devices []*agd.Device) (n uint) {
+	ids := make(map[agd.DeviceID]struct{}, len(devices))
+	for _, d := range devices {
+		ids[d.ID] = struct{}{}
+	}
+
+	for id := range db.devices {
+		if _, ok := ids[id]; !ok {
+			n++
+		}
+	}
+
+	return n
+}
+
+// removedProfileDevicesNum returns the number of the devices of prev that are
+// missing from its new version p.  All devices are considered removed if p is
+// deleted.
+func removedProfileDevicesNum(prev, p *agd.Profile) (n uint) {
+	if p.Deleted {
+		return uint(len(prev.DeviceIDs))
+	}
+
+	for _, id := range prev.DeviceIDs {
+		if !slices.Contains(p.DeviceIDs, id) {
+			n++
+		}
+	}
+
+	return n
 }
 
 // setDevices adds or updates the data for the given devices.  It assumes that
@@ -520,6 +849,10 @@ func (db *Default) setDevices(ctx context.Context, devices []*agd.Device) {
 			db.dedicatedIPToDeviceID[dedIP] = devID
 		}
 
+		for _, dedPref := range d.DedicatedPrefixes {
+			db.dedicatedPrefixToDeviceID.set(dedPref, devID)
+		}
+
 		if d.LinkedIP != (netip.Addr{}) {
 			db.linkedIPToDeviceID[d.LinkedIP] = devID
 		}
@@ -569,16 +902,28 @@ func (db *Default) CreateAutoDevice(
 		return nil, nil, ErrProfileNotFound
 	}
 
+	k := humanIDKey{
+		lower:   agd.HumanIDToLower(humanID),
+		profile: id,
+	}
+	if db.isNegativelyCached(ctx, k) {
+		// The creation has recently failed, so don't query the storage again.
+		return nil, nil, ErrDeviceNotFound
+	}
+
 	resp, err := db.storage.CreateAutoDevice(ctx, &StorageCreateAutoDeviceRequest{
 		ProfileID:  id,
 		HumanID:    humanID,
 		DeviceType: devType,
 	})
 	if err != nil {
+		db.setNegative(k, err)
+
 		return nil, nil, err
 	}
 
 	d = resp.Device
+	db.humanIDCache.Set(k, d.ID)
 
 	func() {
 		db.mapsMu.Lock()
@@ -597,7 +942,9 @@ func (db *Default) CreateAutoDevice(
 }
 
 // ProfileByDedicatedIP implements the [Interface] interface for *Default.  ip
-// must be valid.
+// must be valid.  The devices with ip as one of their dedicated IP addresses
+// take precedence over the ones with a dedicated prefix containing ip, and the
+// longest such prefix is used.
 func (db *Default) ProfileByDedicatedIP(
 	ctx context.Context,
 	ip netip.Addr,
@@ -611,7 +958,7 @@ func (db *Default) ProfileByDedicatedIP(
 
 	id, ok := db.dedicatedIPToDeviceID[ip]
 	if !ok {
-		return nil, nil, ErrDeviceNotFound
+		return db.profileByDedicatedPrefix(ctx, ip)
 	}
 
 	const errPrefix = "profile by device dedicated ip"
@@ -643,6 +990,47 @@ func (db *Default) ProfileByDedicatedIP(
 	return p, d, nil
 }
 
+// profileByDedicatedPrefix returns the profile and the device by the dedicated
+// IPv6 prefix containing ip, if found.  It assumes that db.mapsMu is locked for
+// reading.
+func (db *Default) profileByDedicatedPrefix(
+	ctx context.Context,
+	ip netip.Addr,
+) (p *agd.Profile, d *agd.Device, err error) {
+	pref, id, ok := db.dedicatedPrefixToDeviceID.lookup(ip)
+	if !ok {
+		return nil, nil, ErrDeviceNotFound
+	}
+
+	const errPrefix = "profile by device dedicated prefix"
+	p, d, err = db.profileByDeviceID(ctx, id)
+	if err != nil {
+		if errors.Is(err, ErrDeviceNotFound) {
+			// Probably, the device has been deleted.  Remove it from our
+			// profile DB in a goroutine, since that requires a write lock.
+			go db.removeDedicatedPrefix(ctx, pref, id)
+		}
+
+		// Don't add the device ID to the error here, since it is already added
+		// by profileByDeviceID.
+		return nil, nil, fmt.Errorf("%s: %w", errPrefix, err)
+	}
+
+	if !slices.Contains(d.DedicatedPrefixes, pref) {
+		// Perhaps, the device has changed its dedicated prefixes.  Remove it
+		// from our profile DB in a goroutine, since that requires a write lock.
+		go db.removeDedicatedPrefix(ctx, pref, id)
+
+		return nil, nil, fmt.Errorf(
+			"%s: rechecking dedicated prefixes: %w",
+			errPrefix,
+			ErrDeviceNotFound,
+		)
+	}
+
+	return p, d, nil
+}
+
 // ProfileByDeviceID implements the [Interface] interface for *Default.
 func (db *Default) ProfileByDeviceID(
 	ctx context.Context,
@@ -727,6 +1115,20 @@ func (db *Default) removeDedicatedIP(ctx context.Context, ip netip.Addr) {
 	delete(db.dedicatedIPToDeviceID, ip)
 }
 
+// removeDedicatedPrefix removes the link for the given dedicated prefix from
+// the profile database, unless it has since been linked to another device.  It
+// is intended to be used as a goroutine.
+func (db *Default) removeDedicatedPrefix(ctx context.Context, p netip.Prefix, id agd.DeviceID) {
+	defer slogutil.RecoverAndExit(ctx, db.logger, osutil.ExitCodeFailure)
+
+	db.mapsMu.Lock()
+	defer db.mapsMu.Unlock()
+
+	if curID, _ := db.dedicatedPrefixToDeviceID.get(p); curID == id {
+		db.dedicatedPrefixToDeviceID.remove(p)
+	}
+}
+
 // ProfileByHumanID implements the [Interface] interface for *Default.
 func (db *Default) ProfileByHumanID(
 	ctx context.Context,
@@ -752,7 +1154,18 @@ func (db *Default) ProfileByHumanID(
 		lower:   humanID,
 		profile: id,
 	}
-	devID, ok := db.humanIDToDeviceID[k]
+
+	devID, ok := db.cachedDeviceID(ctx, k)
+	if ok {
+		p, d, err = db.profileByDeviceID(ctx, devID)
+		if err == nil && humanID == d.HumanIDLower {
+			return p, d, nil
+		}
+
+		// The cached entry is stale, so look the device up in the maps.
+	}
+
+	devID, ok = db.humanIDToDeviceID[k]
 	if !ok {
 		return nil, nil, ErrDeviceNotFound
 	}
@@ -780,6 +1193,8 @@ func (db *Default) ProfileByHumanID(
 		return nil, nil, fmt.Errorf("%s: rechecking human id: %w", errPrefix, ErrDeviceNotFound)
 	}
 
+	db.humanIDCache.Set(k, devID)
+
 	return p, d, nil
 }
 
@@ -9,6 +9,7 @@ import (
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/auditlog"
 	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
 	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb/internal"
@@ -71,6 +72,7 @@ func newDefaultProfileDB(tb testing.TB, devices <-chan []*agd.Device) (db *profi
 
 	db, err := profiledb.New(&profiledb.Config{
 		Logger:               slogutil.NewDiscardLogger(),
+		AuditLog:             auditlog.Empty{},
 		Storage:              ps,
 		ErrColl:              agdtest.NewErrorCollector(),
 		Metrics:              profiledb.EmptyMetrics{},
@@ -242,6 +244,128 @@ func TestDefaultProfileDB_ProfileByDedicatedIP_deviceNewIP(t *testing.T) {
 	}, testTimeout, testTimeout/10)
 }
 
+func TestDefaultProfileDB_ProfileByDedicatedIP_prefix(t *testing.T) {
+	t.Parallel()
+
+	var (
+		dedicatedIPv6 = netip.MustParseAddr("2001:db8:1:2::53")
+		prefix48      = netip.MustParsePrefix("2001:db8:1::/48")
+		prefix64      = netip.MustParsePrefix("2001:db8:1:2::/64")
+	)
+
+	devices := []*agd.Device{{
+		ID:                "dev48",
+		DedicatedPrefixes: []netip.Prefix{prefix48},
+	}, {
+		ID:                "dev64",
+		DedicatedPrefixes: []netip.Prefix{prefix64},
+	}, {
+		ID:           "devip",
+		DedicatedIPs: []netip.Addr{dedicatedIPv6},
+	}}
+
+	devicesCh := make(chan []*agd.Device, 1)
+	devicesCh <- devices
+	db := newDefaultProfileDB(t, devicesCh)
+
+	testCases := []struct {
+		ip     netip.Addr
+		name   string
+		wantID agd.DeviceID
+	}{{
+		ip:     dedicatedIPv6,
+		name:   "exact_ip",
+		wantID: "devip",
+	}, {
+		ip:     netip.MustParseAddr("2001:db8:1:2::1234"),
+		name:   "longest_prefix",
+		wantID: "dev64",
+	}, {
+		ip:     netip.MustParseAddr("2001:db8:1:3::53"),
+		name:   "shorter_prefix",
+		wantID: "dev48",
+	}, {
+		ip:     netip.MustParseAddr("2001:db8:2::53"),
+		name:   "outside",
+		wantID: "",
+	}, {
+		ip:     testDedicatedIPv4,
+		name:   "ipv4",
+		wantID: "",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testutil.ContextWithTimeout(t, testTimeout)
+			p, d, err := db.ProfileByDedicatedIP(ctx, tc.ip)
+			if tc.wantID == "" {
+				assert.ErrorIs(t, err, profiledb.ErrDeviceNotFound)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, profiledbtest.ProfileID, p.ID)
+			assert.Equal(t, tc.wantID, d.ID)
+		})
+	}
+}
+
+func TestDefaultProfileDB_ProfileByDedicatedIP_deviceNewPrefix(t *testing.T) {
+	t.Parallel()
+
+	var (
+		ip        = netip.MustParseAddr("2001:db8:1:2::53")
+		prefix48  = netip.MustParsePrefix("2001:db8:1::/48")
+		prefix64  = netip.MustParsePrefix("2001:db8:1:2::/64")
+		newPrefix = netip.MustParsePrefix("2001:db8:2::/64")
+	)
+
+	dev48 := &agd.Device{
+		ID:                "dev48",
+		DedicatedPrefixes: []netip.Prefix{prefix48},
+	}
+	dev64 := &agd.Device{
+		ID:                "dev64",
+		DedicatedPrefixes: []netip.Prefix{prefix64},
+	}
+
+	devicesCh := make(chan []*agd.Device, 2)
+	devicesCh <- []*agd.Device{dev48, dev64}
+
+	db := newDefaultProfileDB(t, devicesCh)
+
+	ctx := context.Background()
+	_, d, err := db.ProfileByDedicatedIP(ctx, ip)
+	require.NoError(t, err)
+
+	assert.Equal(t, dev64, d)
+
+	// The second response, the device has a new prefix.
+	newDev64 := &agd.Device{
+		ID:                dev64.ID,
+		DedicatedPrefixes: []netip.Prefix{newPrefix},
+	}
+	devicesCh <- []*agd.Device{dev48, newDev64}
+
+	err = db.Refresh(ctx)
+	require.NoError(t, err)
+
+	assert.Eventually(t, func() (ok bool) {
+		_, d, err = db.ProfileByDedicatedIP(ctx, ip)
+		if err != nil || d.ID != dev48.ID {
+			return false
+		}
+
+		_, d, err = db.ProfileByDedicatedIP(ctx, newPrefix.Addr().Next())
+
+		return err == nil && d.ID == dev64.ID
+	}, testTimeout, testTimeout/10)
+}
+
 func TestDefaultProfileDB_ProfileByHumanID_removedDevice(t *testing.T) {
 	t.Parallel()
 
@@ -392,6 +516,350 @@ func TestDefaultProfileDB_ProfileByLinkedIP_deviceNewIP(t *testing.T) {
 	}, testTimeout, testTimeout/10)
 }
 
+func TestDefaultProfileDB_Refresh_auditLog(t *testing.T) {
+	t.Parallel()
+
+	devIDsCh := make(chan []agd.DeviceID, 2)
+	onProfiles := func(
+		_ context.Context,
+		_ *profiledb.StorageProfilesRequest,
+	) (resp *profiledb.StorageProfilesResponse, err error) {
+		devIDs, _ := testutil.RequireReceive(t, devIDsCh, testTimeout)
+
+		return &profiledb.StorageProfilesResponse{
+			Profiles: []*agd.Profile{{
+				BlockingMode: &dnsmsg.BlockingModeNullIP{},
+				ID:           profiledbtest.ProfileID,
+				DeviceIDs:    devIDs,
+			}},
+		}, nil
+	}
+
+	ps := &agdtest.ProfileStorage{
+		OnCreateAutoDevice: func(
+			_ context.Context,
+			_ *profiledb.StorageCreateAutoDeviceRequest,
+		) (resp *profiledb.StorageCreateAutoDeviceResponse, err error) {
+			panic("not implemented")
+		},
+		OnProfiles: onProfiles,
+	}
+
+	eventsCh := make(chan *auditlog.Event, 2)
+	auditLog := &agdtest.AuditLog{
+		OnRecord: func(_ context.Context, e *auditlog.Event) {
+			testutil.RequireSend(t, eventsCh, e, testTimeout)
+		},
+	}
+
+	db, err := profiledb.New(&profiledb.Config{
+		Logger:               slogutil.NewDiscardLogger(),
+		AuditLog:             auditLog,
+		Storage:              ps,
+		ErrColl:              agdtest.NewErrorCollector(),
+		Metrics:              profiledb.EmptyMetrics{},
+		CacheFilePath:        "none",
+		FullSyncIvl:          1 * time.Minute,
+		FullSyncRetryIvl:     1 * time.Minute,
+		ResponseSizeEstimate: profiledbtest.RespSzEst,
+	})
+	require.NoError(t, err)
+
+	devIDsCh <- []agd.DeviceID{profiledbtest.DeviceID, profiledbtest.DeviceIDAuto}
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, db.Refresh(ctx))
+
+	e, _ := testutil.RequireReceive(t, eventsCh, testTimeout)
+	assert.Equal(t, &auditlog.Event{
+		Data: &auditlog.ProfilesRefresh{
+			ProfilesNum:       1,
+			DevicesNum:        0,
+			RemovedDevicesNum: 0,
+			IsFullSync:        true,
+		},
+		Type: auditlog.EventTypeProfilesRefresh,
+	}, e)
+
+	devIDsCh <- []agd.DeviceID{profiledbtest.DeviceID}
+
+	ctx = testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, db.Refresh(ctx))
+
+	e, _ = testutil.RequireReceive(t, eventsCh, testTimeout)
+	assert.Equal(t, &auditlog.Event{
+		Data: &auditlog.ProfilesRefresh{
+			ProfilesNum:       1,
+			DevicesNum:        0,
+			RemovedDevicesNum: 1,
+			IsFullSync:        false,
+		},
+		Type: auditlog.EventTypeProfilesRefresh,
+	}, e)
+}
+
+func TestDefaultProfileDB_Refresh_fullSyncPages(t *testing.T) {
+	t.Parallel()
+
+	const (
+		pageSize  = 1
+		pageToken = "page_2"
+
+		otherProfileID agd.ProfileID = "prof5678"
+	)
+
+	reqsCh := make(chan *profiledb.StorageProfilesRequest, 3)
+	errsCh := make(chan error, 3)
+	onProfiles := func(
+		_ context.Context,
+		req *profiledb.StorageProfilesRequest,
+	) (resp *profiledb.StorageProfilesResponse, err error) {
+		testutil.RequireSend(t, reqsCh, req, testTimeout)
+		err, _ = testutil.RequireReceive(t, errsCh, testTimeout)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.PageToken == "" {
+			return &profiledb.StorageProfilesResponse{
+				Profiles: []*agd.Profile{{
+					BlockingMode: &dnsmsg.BlockingModeNullIP{},
+					ID:           profiledbtest.ProfileID,
+					DeviceIDs:    []agd.DeviceID{profiledbtest.DeviceID},
+				}},
+				Devices: []*agd.Device{{
+					ID: profiledbtest.DeviceID,
+				}},
+				NextPageToken: pageToken,
+			}, nil
+		}
+
+		return &profiledb.StorageProfilesResponse{
+			Profiles: []*agd.Profile{{
+				BlockingMode: &dnsmsg.BlockingModeNullIP{},
+				ID:           otherProfileID,
+				DeviceIDs:    []agd.DeviceID{profiledbtest.DeviceIDAuto},
+			}},
+			Devices: []*agd.Device{{
+				ID: profiledbtest.DeviceIDAuto,
+			}},
+		}, nil
+	}
+
+	ps := &agdtest.ProfileStorage{
+		OnCreateAutoDevice: func(
+			_ context.Context,
+			_ *profiledb.StorageCreateAutoDeviceRequest,
+		) (resp *profiledb.StorageCreateAutoDeviceResponse, err error) {
+			panic("not implemented")
+		},
+		OnProfiles: onProfiles,
+	}
+
+	const errTest errors.Error = "test error"
+
+	db, err := profiledb.New(&profiledb.Config{
+		Logger:   slogutil.NewDiscardLogger(),
+		AuditLog: auditlog.Empty{},
+		Storage:  ps,
+		ErrColl: &agdtest.ErrorCollector{
+			OnCollect: func(_ context.Context, err error) {
+				assert.ErrorIs(t, err, errTest)
+			},
+		},
+		Metrics:              profiledb.EmptyMetrics{},
+		CacheFilePath:        "none",
+		FullSyncIvl:          1 * time.Minute,
+		FullSyncRetryIvl:     1 * time.Nanosecond,
+		FullSyncPageSize:     pageSize,
+		ResponseSizeEstimate: profiledbtest.RespSzEst,
+	})
+	require.NoError(t, err)
+
+	errsCh <- nil
+	errsCh <- errTest
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	err = db.Refresh(ctx)
+	require.ErrorIs(t, err, errTest)
+
+	req, _ := testutil.RequireReceive(t, reqsCh, testTimeout)
+	assert.Equal(t, &profiledb.StorageProfilesRequest{PageSize: pageSize}, req)
+
+	req, _ = testutil.RequireReceive(t, reqsCh, testTimeout)
+	assert.Equal(t, pageToken, req.PageToken)
+
+	// The retry must resume the synchronization from the failed page.
+	errsCh <- nil
+
+	ctx = testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, db.Refresh(ctx))
+
+	req, _ = testutil.RequireReceive(t, reqsCh, testTimeout)
+	assert.Equal(t, pageToken, req.PageToken)
+	assert.Empty(t, reqsCh)
+
+	ctx = testutil.ContextWithTimeout(t, testTimeout)
+	p, d, err := db.ProfileByDeviceID(ctx, profiledbtest.DeviceID)
+	require.NoError(t, err)
+
+	assert.Equal(t, profiledbtest.ProfileID, p.ID)
+	assert.Equal(t, profiledbtest.DeviceID, d.ID)
+
+	p, d, err = db.ProfileByDeviceID(ctx, profiledbtest.DeviceIDAuto)
+	require.NoError(t, err)
+
+	assert.Equal(t, otherProfileID, p.ID)
+	assert.Equal(t, profiledbtest.DeviceIDAuto, d.ID)
+}
+
+// testPruneMetrics is a [profiledb.Metrics] implementation for tests that
+// records the numbers of pruned profiles and devices.
+type testPruneMetrics struct {
+	profiledb.EmptyMetrics
+
+	profNum uint
+	devNum  uint
+}
+
+// IncrementPruned implements the [profiledb.Metrics] interface for
+// *testPruneMetrics.
+func (m *testPruneMetrics) IncrementPruned(_ context.Context, profNum, devNum uint) {
+	m.profNum += profNum
+	m.devNum += devNum
+}
+
+func TestDefaultProfileDB_Refresh_gc(t *testing.T) {
+	t.Parallel()
+
+	const (
+		delProfID agd.ProfileID = "prof5678"
+
+		orphanDevID agd.DeviceID = "dev5678"
+		delDevID    agd.DeviceID = "dev9012"
+	)
+
+	prof, dev := profiledbtest.NewProfile(t)
+	orphanDev := &agd.Device{
+		ID:               orphanDevID,
+		FilteringEnabled: true,
+	}
+	delDev := &agd.Device{
+		ID:               delDevID,
+		FilteringEnabled: true,
+	}
+
+	fullProf := &agd.Profile{}
+	*fullProf = *prof
+	fullProf.DeviceIDs = []agd.DeviceID{dev.ID, orphanDevID}
+
+	otherProf := &agd.Profile{}
+	*otherProf = *prof
+	otherProf.ID = delProfID
+	otherProf.DeviceIDs = []agd.DeviceID{delDevID}
+
+	delProf := &agd.Profile{}
+	*delProf = *otherProf
+	delProf.Deleted = true
+
+	respCh := make(chan *profiledb.StorageProfilesResponse, 3)
+	ps := &agdtest.ProfileStorage{
+		OnCreateAutoDevice: func(
+			_ context.Context,
+			_ *profiledb.StorageCreateAutoDeviceRequest,
+		) (resp *profiledb.StorageCreateAutoDeviceResponse, err error) {
+			panic("not implemented")
+		},
+		OnProfiles: func(
+			_ context.Context,
+			_ *profiledb.StorageProfilesRequest,
+		) (resp *profiledb.StorageProfilesResponse, err error) {
+			resp, _ = testutil.RequireReceive(t, respCh, testTimeout)
+
+			return resp, nil
+		},
+	}
+
+	cacheFilePath := filepath.Join(t.TempDir(), "profiles.pb")
+	mtrc := &testPruneMetrics{}
+	db, err := profiledb.New(&profiledb.Config{
+		Logger:               slogutil.NewDiscardLogger(),
+		AuditLog:             auditlog.Empty{},
+		Storage:              ps,
+		ErrColl:              agdtest.NewErrorCollector(),
+		Metrics:              mtrc,
+		CacheFilePath:        cacheFilePath,
+		FullSyncIvl:          1 * time.Minute,
+		FullSyncRetryIvl:     1 * time.Minute,
+		GCRetention:          1 * time.Nanosecond,
+		ResponseSizeEstimate: profiledbtest.RespSzEst,
+	})
+	require.NoError(t, err)
+
+	// Use the time with monotonic clocks stripped down.
+	syncTime := time.Now().Round(0).UTC()
+
+	respCh <- &profiledb.StorageProfilesResponse{
+		SyncTime: syncTime,
+		Profiles: []*agd.Profile{fullProf, otherProf},
+		Devices:  []*agd.Device{dev, orphanDev, delDev},
+	}
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, db.Refresh(ctx))
+
+	// Remove a device from the profile and delete the other profile.  The
+	// deleted profile is removed right away, since its retention has passed,
+	// and the devices are only marked as unreferenced.
+	respCh <- &profiledb.StorageProfilesResponse{
+		SyncTime: syncTime.Add(1 * time.Second),
+		Profiles: []*agd.Profile{prof, delProf},
+	}
+
+	ctx = testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, db.Refresh(ctx))
+
+	assert.Equal(t, uint(1), mtrc.profNum)
+	assert.Equal(t, uint(0), mtrc.devNum)
+
+	respCh <- &profiledb.StorageProfilesResponse{
+		SyncTime: syncTime.Add(2 * time.Second),
+	}
+
+	ctx = testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, db.Refresh(ctx))
+
+	assert.Equal(t, uint(1), mtrc.profNum)
+	assert.Equal(t, uint(2), mtrc.devNum)
+
+	_, _, err = db.ProfileByDeviceID(ctx, delDevID)
+	assert.ErrorIs(t, err, profiledb.ErrDeviceNotFound)
+
+	_, _, err = db.ProfileByDeviceID(ctx, orphanDevID)
+	assert.ErrorIs(t, err, profiledb.ErrDeviceNotFound)
+
+	p, d, err := db.ProfileByDeviceID(ctx, dev.ID)
+	require.NoError(t, err)
+
+	assert.Equal(t, prof, p)
+	assert.Equal(t, dev, d)
+
+	pbCache := filecachepb.New(
+		slogutil.NewDiscardLogger(),
+		cacheFilePath,
+		profiledbtest.RespSzEst,
+		nil,
+	)
+
+	c, err := pbCache.Load(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, c)
+
+	assert.Equal(t, syncTime, c.SyncTime)
+	assert.Equal(t, []*agd.Profile{prof}, c.Profiles)
+	assert.Equal(t, []*agd.Device{dev}, c.Devices)
+}
+
 func TestDefaultProfileDB_fileCache_success(t *testing.T) {
 	t.Parallel()
 
@@ -422,7 +890,7 @@ func TestDefaultProfileDB_fileCache_success(t *testing.T) {
 
 	cacheFilePath := filepath.Join(t.TempDir(), "profiles.pb")
 	logger := slogutil.NewDiscardLogger()
-	pbCache := filecachepb.New(logger, cacheFilePath, profiledbtest.RespSzEst)
+	pbCache := filecachepb.New(logger, cacheFilePath, profiledbtest.RespSzEst, nil)
 
 	ctx := testutil.ContextWithTimeout(t, testTimeout)
 	err := pbCache.Store(ctx, &internal.FileCache{
@@ -435,6 +903,7 @@ func TestDefaultProfileDB_fileCache_success(t *testing.T) {
 
 	db, err := profiledb.New(&profiledb.Config{
 		Logger:               logger,
+		AuditLog:             auditlog.Empty{},
 		Storage:              ps,
 		ErrColl:              agdtest.NewErrorCollector(),
 		Metrics:              profiledb.EmptyMetrics{},
@@ -480,7 +949,7 @@ func TestDefaultProfileDB_fileCache_badVersion(t *testing.T) {
 
 	cacheFilePath := filepath.Join(t.TempDir(), "profiles.pb")
 	logger := slogutil.NewDiscardLogger()
-	pbCache := filecachepb.New(logger, cacheFilePath, profiledbtest.RespSzEst)
+	pbCache := filecachepb.New(logger, cacheFilePath, profiledbtest.RespSzEst, nil)
 
 	ctx := testutil.ContextWithTimeout(t, testTimeout)
 	err := pbCache.Store(ctx, &internal.FileCache{
@@ -490,6 +959,7 @@ func TestDefaultProfileDB_fileCache_badVersion(t *testing.T) {
 
 	db, err := profiledb.New(&profiledb.Config{
 		Logger:               logger,
+		AuditLog:             auditlog.Empty{},
 		Storage:              ps,
 		ErrColl:              agdtest.NewErrorCollector(),
 		Metrics:              profiledb.EmptyMetrics{},
@@ -507,6 +977,47 @@ func TestDefaultProfileDB_fileCache_badVersion(t *testing.T) {
 	assert.True(t, storageCalled)
 }
 
+func TestDefaultProfileDB_Shutdown(t *testing.T) {
+	t.Parallel()
+
+	ps := &agdtest.ProfileStorage{
+		OnCreateAutoDevice: func(
+			_ context.Context,
+			_ *profiledb.StorageCreateAutoDeviceRequest,
+		) (resp *profiledb.StorageCreateAutoDeviceResponse, err error) {
+			panic("not implemented")
+		},
+		OnProfiles: func(
+			_ context.Context,
+			_ *profiledb.StorageProfilesRequest,
+		) (resp *profiledb.StorageProfilesResponse, err error) {
+			return &profiledb.StorageProfilesResponse{}, nil
+		},
+	}
+
+	db, err := profiledb.New(&profiledb.Config{
+		Logger:   slogutil.NewDiscardLogger(),
+		AuditLog: auditlog.Empty{},
+		Storage:  ps,
+		ErrColl: &agdtest.ErrorCollector{
+			OnCollect: func(_ context.Context, _ error) {},
+		},
+		Metrics:              profiledb.EmptyMetrics{},
+		CacheFilePath:        filepath.Join(t.TempDir(), "profiles.db"),
+		FullSyncIvl:          1 * time.Minute,
+		FullSyncRetryIvl:     1 * time.Minute,
+		ResponseSizeEstimate: profiledbtest.RespSzEst,
+	})
+	require.NoError(t, err)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, db.Refresh(ctx))
+	require.NoError(t, db.Shutdown(ctx))
+
+	// The cache must not be used after the shutdown.
+	assert.Error(t, db.Refresh(ctx))
+}
+
 func TestDefaultProfileDB_CreateAutoDevice(t *testing.T) {
 	t.Parallel()
 
@@ -543,6 +1054,7 @@ func TestDefaultProfileDB_CreateAutoDevice(t *testing.T) {
 
 	db, err := profiledb.New(&profiledb.Config{
 		Logger:               slogutil.NewDiscardLogger(),
+		AuditLog:             auditlog.Empty{},
 		Storage:              ps,
 		ErrColl:              agdtest.NewErrorCollector(),
 		Metrics:              profiledb.EmptyMetrics{},
@@ -569,6 +1081,77 @@ func TestDefaultProfileDB_CreateAutoDevice(t *testing.T) {
 	assert.Equal(t, wantProf, p)
 }
 
+func TestDefaultProfileDB_CreateAutoDevice_negativeCache(t *testing.T) {
+	t.Parallel()
+
+	prof := &agd.Profile{
+		BlockingMode:       &dnsmsg.BlockingModeNullIP{},
+		ID:                 profiledbtest.ProfileID,
+		DeviceIDs:          nil,
+		AutoDevicesEnabled: true,
+	}
+
+	var createCalls int
+	ps := &agdtest.ProfileStorage{
+		OnCreateAutoDevice: func(
+			_ context.Context,
+			_ *profiledb.StorageCreateAutoDeviceRequest,
+		) (resp *profiledb.StorageCreateAutoDeviceResponse, err error) {
+			createCalls++
+
+			return nil, &profiledb.DeviceQuotaExceededError{
+				Message: "test quota exceeded",
+			}
+		},
+		OnProfiles: func(
+			_ context.Context,
+			_ *profiledb.StorageProfilesRequest,
+		) (resp *profiledb.StorageProfilesResponse, err error) {
+			return &profiledb.StorageProfilesResponse{
+				Profiles: []*agd.Profile{prof},
+				Devices:  nil,
+			}, nil
+		},
+	}
+
+	db, err := profiledb.New(&profiledb.Config{
+		Logger:               slogutil.NewDiscardLogger(),
+		AuditLog:             auditlog.Empty{},
+		Storage:              ps,
+		ErrColl:              agdtest.NewErrorCollector(),
+		Metrics:              profiledb.EmptyMetrics{},
+		CacheFilePath:        "none",
+		FullSyncIvl:          1 * time.Minute,
+		FullSyncRetryIvl:     1 * time.Minute,
+		HumanIDCacheCount:    100,
+		HumanIDNegativeTTL:   1 * time.Minute,
+		ResponseSizeEstimate: profiledbtest.RespSzEst,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, db)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, db.Refresh(ctx))
+
+	_, _, err = db.CreateAutoDevice(
+		ctx,
+		profiledbtest.ProfileID,
+		profiledbtest.HumanID,
+		agd.DeviceTypeOther,
+	)
+	quotaErr := &profiledb.DeviceQuotaExceededError{}
+	require.ErrorAs(t, err, &quotaErr)
+
+	_, _, err = db.CreateAutoDevice(
+		ctx,
+		profiledbtest.ProfileID,
+		profiledbtest.HumanID,
+		agd.DeviceTypeOther,
+	)
+	assert.ErrorIs(t, err, profiledb.ErrDeviceNotFound)
+	assert.Equal(t, 1, createCalls)
+}
+
 // Sinks for benchmarks.
 var (
 	profSink *agd.Profile
@@ -49,6 +49,15 @@ type StorageCreateAutoDeviceResponse struct {
 type StorageProfilesRequest struct {
 	// SyncTime is the last time profiles were synced.
 	SyncTime time.Time
+
+	// PageToken is the token of the page of a full synchronization to return,
+	// as returned in the previous [StorageProfilesResponse.NextPageToken].  If
+	// it is empty, the first page is returned.
+	PageToken string
+
+	// PageSize is the maximum number of profiles in one page of a full
+	// synchronization.  If it is zero, all profiles are returned at once.
+	PageSize uint32
 }
 
 // StorageProfilesResponse is the response from the [Storage.Profiles] method.
@@ -60,6 +69,10 @@ type StorageProfilesResponse struct {
 	// Profiles are the profiles data from the [Storage].
 	Profiles []*agd.Profile
 
+	// NextPageToken is the token of the next page of a full synchronization.
+	// If it is empty, this is the last page.
+	NextPageToken string
+
 	// Devices are the device data from the [Storage].
 	Devices []*agd.Device
 }
@@ -0,0 +1,276 @@
+package qnamelimit
+
+import (
+	"context"
+	"hash/maphash"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/golibs/container"
+	"golang.org/x/net/publicsuffix"
+)
+
+// DefaultConfig is the configuration structure for [Default].  All fields must
+// not be empty.
+type DefaultConfig struct {
+	// Logger is used to log the operation of the limiter.
+	Logger *slog.Logger
+
+	// Clock is used to get the current time.
+	Clock agdtime.Clock
+
+	// Metrics is used for the collection of the statistics of the limiter.
+	Metrics Metrics
+
+	// Window is the duration of the sliding window within which the unique
+	// names are counted.  It must be positive.
+	Window time.Duration
+
+	// BlockDuration is the duration for which a client that has exceeded the
+	// limit for a registrable domain remains limited.  It must be positive.
+	BlockDuration time.Duration
+
+	// MaxUnique is the maximum number of unique names under a single
+	// registrable domain that a single client may query within the window.  It
+	// must be positive.
+	MaxUnique uint
+
+	// MaxTracked is the maximum number of pairs of clients and registrable
+	// domains tracked at the same time.  The requests of the pairs that are
+	// not tracked because of this limit are not limited.  It must be positive.
+	MaxTracked int
+}
+
+// shardsNum is the number of shards of the state of [Default].  Since client
+// keys are hashes, their first bytes are distributed uniformly.
+const shardsNum = 64
+
+// Default is the default [Interface] implementation.  It approximates the
+// number of unique names queried by a client under a registrable domain within
+// a sliding window by the number of names in the current fixed window plus the
+// number of names in the previous one weighted by the part of it that is still
+// within the sliding window.  Once a client exceeds the limit for a domain, all
+// its requests for that domain are limited until the block expires, after
+// which it is tracked anew.
+//
+// Only the hashes of the names are stored, and at most [DefaultConfig.MaxUnique]
+// of them for each pair of a client and a domain, so the memory usage is
+// bounded.
+type Default struct {
+	logger  *slog.Logger
+	clock   agdtime.Clock
+	metrics Metrics
+	shards  [shardsNum]*shard
+	seed    maphash.Seed
+
+	window        time.Duration
+	blockDuration time.Duration
+	maxUnique     uint
+
+	// maxTrackedPerShard is the maximum number of states within a single
+	// shard.
+	maxTrackedPerShard int
+}
+
+// shard is a part of the state of [Default].
+type shard struct {
+	// mu protects states.
+	mu     *sync.Mutex
+	states map[stateKey]*state
+}
+
+// stateKey is the key of the state of a client for a registrable domain.
+type stateKey struct {
+	domain string
+	client agd.ClientKey
+}
+
+// state is the state of a client for a registrable domain.
+type state struct {
+	// curr are the hashes of the names first queried within the current fixed
+	// window.
+	curr *container.MapSet[uint64]
+
+	// prev are the hashes of the names first queried within the previous fixed
+	// window.
+	prev *container.MapSet[uint64]
+
+	// currStart is the start of the current fixed window.
+	currStart time.Time
+
+	// blockedUntil is the time until which the client is limited for the
+	// domain.  It is zero if the client isn't limited.
+	blockedUntil time.Time
+}
+
+// NewDefault returns a new properly initialized *Default.  c must be valid.
+func NewDefault(c *DefaultConfig) (d *Default) {
+	d = &Default{
+		logger:             c.Logger,
+		clock:              c.Clock,
+		metrics:            c.Metrics,
+		seed:               maphash.MakeSeed(),
+		window:             c.Window,
+		blockDuration:      c.BlockDuration,
+		maxUnique:          c.MaxUnique,
+		maxTrackedPerShard: max(c.MaxTracked/shardsNum, 1),
+	}
+
+	for i := range d.shards {
+		d.shards[i] = &shard{
+			mu:     &sync.Mutex{},
+			states: map[stateKey]*state{},
+		}
+	}
+
+	return d
+}
+
+// type check
+var _ Interface = (*Default)(nil)
+
+// IsLimited implements the [Interface] interface for *Default.
+func (d *Default) IsLimited(ctx context.Context, key agd.ClientKey, host string) (limited bool) {
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		// The host is either empty or a public suffix itself, so there are no
+		// subdomains to count.
+		return false
+	}
+
+	now := d.clock.Now()
+	h := maphash.String(d.seed, host)
+	sk := stateKey{
+		domain: domain,
+		client: key,
+	}
+
+	s := d.shards[key[0]%shardsNum]
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.states[sk]
+	if st == nil {
+		if len(s.states) >= d.maxTrackedPerShard {
+			return false
+		}
+
+		st = &state{
+			curr:      container.NewMapSet[uint64](),
+			prev:      container.NewMapSet[uint64](),
+			currStart: now,
+		}
+
+		s.states[sk] = st
+	}
+
+	if now.Before(st.blockedUntil) {
+		d.metrics.OnRefused(ctx)
+
+		return true
+	}
+
+	if !st.add(h, now, d.window, d.maxUnique) {
+		return false
+	}
+
+	st.blockedUntil = now.Add(d.blockDuration)
+	st.curr.Clear()
+	st.prev.Clear()
+
+	d.metrics.OnLimited(ctx)
+	d.metrics.OnRefused(ctx)
+
+	optslog.Debug2(ctx, d.logger, "client limited", "client_key", key, "domain", domain)
+
+	return true
+}
+
+// add adds the hash of a name to the state at now and returns true if the
+// approximate number of unique names within the sliding window exceeds
+// maxUnique.
+func (st *state) add(
+	h uint64,
+	now time.Time,
+	window time.Duration,
+	maxUnique uint,
+) (exceeded bool) {
+	st.rotate(now, window)
+
+	if !st.curr.Has(h) && !st.prev.Has(h) {
+		st.curr.Add(h)
+	}
+
+	prevPart := 1 - float64(now.Sub(st.currStart))/float64(window)
+	n := float64(st.curr.Len()) + float64(st.prev.Len())*prevPart
+
+	return n > float64(maxUnique)
+}
+
+// rotate moves the fixed windows of st so that now is within the current one.
+func (st *state) rotate(now time.Time, window time.Duration) {
+	elapsed := now.Sub(st.currStart)
+	switch {
+	case elapsed < window:
+		// Go on.
+	case elapsed < 2*window:
+		st.prev, st.curr = st.curr, st.prev
+		st.curr.Clear()
+		st.currStart = st.currStart.Add(window)
+	default:
+		st.prev.Clear()
+		st.curr.Clear()
+		st.currStart = now
+	}
+}
+
+// isExpired returns true if st doesn't affect any requests made at now or
+// later.
+func (st *state) isExpired(now time.Time, window time.Duration) (ok bool) {
+	return !now.Before(st.blockedUntil) && now.Sub(st.currStart) >= 2*window
+}
+
+// type check
+var _ agdservice.Refresher = (*Default)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for *Default.  It
+// removes the expired states and updates the metrics.  err is always nil.
+func (d *Default) Refresh(ctx context.Context) (err error) {
+	now := d.clock.Now()
+
+	var tracked, limited uint
+	for _, s := range d.shards {
+		t, l := s.removeExpired(now, d.window)
+		tracked, limited = tracked+t, limited+l
+	}
+
+	d.metrics.SetClients(ctx, tracked, limited)
+
+	return nil
+}
+
+// removeExpired removes the expired states from s and returns the numbers of
+// the remaining and the limited ones.
+func (s *shard) removeExpired(now time.Time, window time.Duration) (tracked, limited uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for k, st := range s.states {
+		if st.isExpired(now, window) {
+			delete(s.states, k)
+
+			continue
+		}
+
+		if now.Before(st.blockedUntil) {
+			limited++
+		}
+	}
+
+	return uint(len(s.states)), limited
+}
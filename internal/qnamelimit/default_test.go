@@ -0,0 +1,171 @@
+package qnamelimit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnamelimit"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// Common constants for tests.
+const (
+	testWindow        = 10 * time.Second
+	testBlockDuration = 1 * time.Minute
+	testMaxUnique     = 3
+)
+
+// Client keys for tests.
+var (
+	testKey      = agd.ClientKey{1}
+	testKeyOther = agd.ClientKey{2}
+)
+
+// testMetrics is a [qnamelimit.Metrics] implementation for tests.
+type testMetrics struct {
+	limited uint
+	refused uint
+	tracked uint
+	blocked uint
+}
+
+// type check
+var _ qnamelimit.Metrics = (*testMetrics)(nil)
+
+// OnLimited implements the [qnamelimit.Metrics] interface for *testMetrics.
+func (m *testMetrics) OnLimited(_ context.Context) { m.limited++ }
+
+// OnRefused implements the [qnamelimit.Metrics] interface for *testMetrics.
+func (m *testMetrics) OnRefused(_ context.Context) { m.refused++ }
+
+// SetClients implements the [qnamelimit.Metrics] interface for *testMetrics.
+func (m *testMetrics) SetClients(_ context.Context, tracked, limited uint) {
+	m.tracked, m.blocked = tracked, limited
+}
+
+// newTestDefault returns a new *qnamelimit.Default with the test parameters,
+// its metrics, and the pointer to the current time of its clock.
+func newTestDefault(tb testing.TB) (d *qnamelimit.Default, m *testMetrics, now *time.Time) {
+	tb.Helper()
+
+	now = new(time.Time)
+	*now = time.Unix(0, 0)
+	m = &testMetrics{}
+
+	d = qnamelimit.NewDefault(&qnamelimit.DefaultConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		Clock: &agdtest.Clock{
+			OnNow: func() (n time.Time) { return *now },
+		},
+		Metrics:       m,
+		Window:        testWindow,
+		BlockDuration: testBlockDuration,
+		MaxUnique:     testMaxUnique,
+		MaxTracked:    1_000,
+	})
+
+	return d, m, now
+}
+
+// queryRandom makes n queries for unique subdomains of domain from the client
+// with the given key and returns the result of the last one.
+func queryRandom(
+	ctx context.Context,
+	d *qnamelimit.Default,
+	key agd.ClientKey,
+	domain string,
+	n int,
+) (limited bool) {
+	for i := range n {
+		limited = d.IsLimited(ctx, key, fmt.Sprintf("r%d.%s", i, domain))
+	}
+
+	return limited
+}
+
+func TestDefault_IsLimited(t *testing.T) {
+	t.Parallel()
+
+	d, m, now := newTestDefault(t)
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	// Repeated queries for the same names aren't counted.
+	for range 10 {
+		require.False(t, d.IsLimited(ctx, testKey, "www.example.com"))
+		require.False(t, d.IsLimited(ctx, testKey, "example.com"))
+	}
+
+	assert.False(t, queryRandom(ctx, d, testKey, "example.com", 1))
+	assert.True(t, queryRandom(ctx, d, testKey, "example.com", 2))
+	assert.Equal(t, uint(1), m.limited)
+
+	// The limited client is limited even for the names queried before.
+	assert.True(t, d.IsLimited(ctx, testKey, "www.example.com"))
+	assert.Equal(t, uint(2), m.refused)
+
+	// Other domains and clients aren't affected.
+	assert.False(t, d.IsLimited(ctx, testKey, "www.example.org"))
+	assert.False(t, d.IsLimited(ctx, testKeyOther, "www.example.com"))
+
+	// Public suffixes are never limited.
+	assert.False(t, queryRandom(ctx, d, testKey, "co.uk", 10))
+
+	*now = now.Add(testBlockDuration)
+	assert.False(t, d.IsLimited(ctx, testKey, "www.example.com"))
+}
+
+func TestDefault_IsLimited_slidingWindow(t *testing.T) {
+	t.Parallel()
+
+	d, _, now := newTestDefault(t)
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	start := *now
+	require.False(t, queryRandom(ctx, d, testKey, "example.com", testMaxUnique))
+
+	// Half of the previous window is still within the sliding one, so the
+	// estimate is 3*0.5 + 2 = 3.5.
+	*now = start.Add(testWindow + testWindow/2)
+	assert.False(t, d.IsLimited(ctx, testKey, "a.example.com"))
+	assert.True(t, d.IsLimited(ctx, testKey, "b.example.com"))
+
+	// The names queried more than two windows ago are forgotten.
+	require.False(t, queryRandom(ctx, d, testKeyOther, "example.com", testMaxUnique))
+
+	*now = now.Add(3 * testWindow)
+	assert.False(t, queryRandom(ctx, d, testKeyOther, "example.org", testMaxUnique))
+}
+
+func TestDefault_Refresh(t *testing.T) {
+	t.Parallel()
+
+	d, m, now := newTestDefault(t)
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	require.True(t, queryRandom(ctx, d, testKey, "example.com", testMaxUnique+1))
+	require.False(t, d.IsLimited(ctx, testKeyOther, "www.example.com"))
+
+	require.NoError(t, d.Refresh(ctx))
+	assert.Equal(t, uint(2), m.tracked)
+	assert.Equal(t, uint(1), m.blocked)
+
+	*now = now.Add(2 * testWindow)
+	require.NoError(t, d.Refresh(ctx))
+	assert.Equal(t, uint(1), m.tracked)
+	assert.Equal(t, uint(1), m.blocked)
+
+	*now = now.Add(testBlockDuration)
+	require.NoError(t, d.Refresh(ctx))
+	assert.Zero(t, m.tracked)
+	assert.Zero(t, m.blocked)
+}
@@ -0,0 +1,66 @@
+// Package qnamelimit contains the limits of the numbers of unique names that a
+// single client queries under a single registrable domain.  These limits are
+// the defense against the random-subdomain attacks, also known as the "water
+// torture" attacks, in which the clients send floods of queries for random
+// nonexistent subdomains of a victim domain, so that the queries can't be
+// answered from the cache and have to be resolved by the upstreams and the
+// authoritative servers of the victim domain.
+package qnamelimit
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// Interface is the interface for the limits of the unique names queried by the
+// clients.  All methods must be safe for concurrent use.
+type Interface interface {
+	// IsLimited records the request for host from the client with the given
+	// key and returns true if the client has exceeded the limit for the
+	// registrable domain of host, so the request must not be resolved.
+	IsLimited(ctx context.Context, key agd.ClientKey, host string) (limited bool)
+}
+
+// Empty is an [Interface] implementation that never limits requests.
+type Empty struct{}
+
+// type check
+var _ Interface = Empty{}
+
+// IsLimited implements the [Interface] interface for Empty.  It always returns
+// false.
+func (Empty) IsLimited(_ context.Context, _ agd.ClientKey, _ string) (limited bool) {
+	return false
+}
+
+// Metrics is an interface for collection of the statistics of the limits of
+// the unique names.
+type Metrics interface {
+	// OnLimited is called when a client exceeds the limit for a registrable
+	// domain.
+	OnLimited(ctx context.Context)
+
+	// OnRefused is called when a request of a limited client is refused.
+	OnRefused(ctx context.Context)
+
+	// SetClients sets the numbers of the tracked and the limited pairs of
+	// clients and registrable domains.
+	SetClients(ctx context.Context, tracked, limited uint)
+}
+
+// EmptyMetrics is an implementation of the [Metrics] interface that does
+// nothing.
+type EmptyMetrics struct{}
+
+// type check
+var _ Metrics = EmptyMetrics{}
+
+// OnLimited implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) OnLimited(_ context.Context) {}
+
+// OnRefused implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) OnRefused(_ context.Context) {}
+
+// SetClients implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) SetClients(_ context.Context, _, _ uint) {}
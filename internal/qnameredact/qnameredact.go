@@ -0,0 +1,153 @@
+// Package qnameredact contains the redaction of the sensitive parts of the
+// requested domain names before they are written into the query log, DNSDB,
+// and other sinks.
+package qnameredact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// Action is the action performed on the sensitive part of a domain name.
+type Action string
+
+// Valid [Action] values.
+const (
+	// ActionHash replaces the sensitive part with a truncated hex-encoded
+	// SHA-256 hash of it, so that the entries with the same name can still be
+	// correlated.
+	ActionHash Action = "hash"
+
+	// ActionStrip replaces the sensitive part with [Stripped].
+	ActionStrip Action = "strip"
+)
+
+// Validate returns an error if a is not a valid action.
+func (a Action) Validate() (err error) {
+	switch a {
+	case ActionHash, ActionStrip:
+		return nil
+	default:
+		return fmt.Errorf("action: %w: %q", errors.ErrBadEnumValue, a)
+	}
+}
+
+// Stripped is the label that replaces the sensitive part of a domain name for
+// [ActionStrip].
+const Stripped = "redacted"
+
+// hashLen is the length of the hex-encoded hash for [ActionHash].
+const hashLen = 16
+
+// Rule is a single redaction rule.  Exactly one of Regexp and Suffix must be
+// set.
+type Rule struct {
+	// Regexp, if not nil, matches the sensitive part of the lowercased domain
+	// name without the trailing dot.  If it has a capturing group, only the
+	// first group is considered sensitive, otherwise the whole match is.
+	Regexp *regexp.Regexp
+
+	// Action is the action performed on the sensitive part.  It must be valid.
+	Action Action
+
+	// Suffix, if not empty, is the lowercased domain name without the trailing
+	// dot, the subdomains of which are considered sensitive.  The labels before
+	// the suffix are the sensitive part.
+	Suffix string
+}
+
+// Redactor redacts domain names using the first matching rule.  A nil
+// *Redactor returns the names unchanged.  It is safe for concurrent use.
+type Redactor struct {
+	rules []*Rule
+}
+
+// New returns a new *Redactor with the given rules.  If rules are empty, r is
+// nil.  rules must be valid.
+func New(rules []*Rule) (r *Redactor) {
+	if len(rules) == 0 {
+		return nil
+	}
+
+	return &Redactor{
+		rules: rules,
+	}
+}
+
+// Redact returns name with the sensitive part redacted according to the first
+// matching rule.  If no rule matches, res is name.  The trailing dot, if any,
+// is kept.
+func (r *Redactor) Redact(name string) (res string) {
+	if r == nil || name == "" {
+		return name
+	}
+
+	host, isFQDN := strings.CutSuffix(name, ".")
+	host = strings.ToLower(host)
+	for _, rule := range r.rules {
+		var ok bool
+		res, ok = rule.redact(host)
+		if !ok {
+			continue
+		}
+
+		if isFQDN {
+			res += "."
+		}
+
+		return res
+	}
+
+	return name
+}
+
+// redact returns host with the sensitive part redacted and true if rule
+// matches host.
+func (rule *Rule) redact(host string) (res string, ok bool) {
+	if rule.Regexp == nil {
+		return rule.redactSuffix(host)
+	}
+
+	loc := rule.Regexp.FindStringSubmatchIndex(host)
+	if loc == nil {
+		return "", false
+	}
+
+	start, end := loc[0], loc[1]
+	if len(loc) >= 4 && loc[2] >= 0 {
+		start, end = loc[2], loc[3]
+	}
+
+	if start == end {
+		return host, true
+	}
+
+	return host[:start] + rule.replacement(host[start:end]) + host[end:], true
+}
+
+// redactSuffix returns host with the labels before rule.Suffix redacted and
+// true if host is a subdomain of rule.Suffix.
+func (rule *Rule) redactSuffix(host string) (res string, ok bool) {
+	sub, ok := strings.CutSuffix(host, "."+rule.Suffix)
+	if !ok || sub == "" {
+		return "", false
+	}
+
+	return rule.replacement(sub) + "." + rule.Suffix, true
+}
+
+// replacement returns the replacement of the sensitive part s.
+func (rule *Rule) replacement(s string) (repl string) {
+	if rule.Action == ActionStrip {
+		return Stripped
+	}
+
+	sum := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(sum[:hashLen/2])
+}
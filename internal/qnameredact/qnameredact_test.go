@@ -0,0 +1,81 @@
+package qnameredact_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnameredact"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	t.Parallel()
+
+	r := qnameredact.New([]*qnameredact.Rule{{
+		Action: qnameredact.ActionStrip,
+		Suffix: "corp.example",
+	}, {
+		Action: qnameredact.ActionHash,
+		Suffix: "in-addr.arpa",
+	}, {
+		Regexp: regexp.MustCompile(`^([a-z0-9]{32,})\.`),
+		Action: qnameredact.ActionStrip,
+	}, {
+		Regexp: regexp.MustCompile(`token-[a-z0-9]+`),
+		Action: qnameredact.ActionHash,
+	}})
+
+	testCases := []struct {
+		name string
+		in   string
+		want string
+	}{{
+		name: "no_match",
+		in:   "www.example.com.",
+		want: "www.example.com.",
+	}, {
+		name: "suffix_strip",
+		in:   "Host.Team.corp.example.",
+		want: "redacted.corp.example.",
+	}, {
+		name: "suffix_exact",
+		in:   "corp.example.",
+		want: "corp.example.",
+	}, {
+		name: "suffix_not_subdomain",
+		in:   "evilcorp.example.",
+		want: "evilcorp.example.",
+	}, {
+		name: "suffix_hash",
+		in:   "1.2.0.192.in-addr.arpa.",
+		want: "79cf98577046ed3d.in-addr.arpa.",
+	}, {
+		name: "regexp_group",
+		in:   "aaaabbbbccccddddeeeeffff0000111122223333.tunnel.example.",
+		want: "redacted.tunnel.example.",
+	}, {
+		name: "regexp_whole_match",
+		in:   "token-abc123.example.com",
+		want: "d88f4b1bd2ec8589.example.com",
+	}, {
+		name: "empty",
+		in:   "",
+		want: "",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, r.Redact(tc.in))
+		})
+	}
+}
+
+func TestRedactor_Redact_nil(t *testing.T) {
+	t.Parallel()
+
+	r := qnameredact.New(nil)
+	assert.Nil(t, r)
+	assert.Equal(t, "www.example.com.", r.Redact("www.example.com."))
+}
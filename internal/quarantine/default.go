@@ -0,0 +1,108 @@
+package quarantine
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/AdguardTeam/golibs/netutil"
+)
+
+// DefaultConfig is the configuration structure for [Default].
+type DefaultConfig struct {
+	// Metrics is used for the collection of the quarantine statistics.  It
+	// must not be nil.
+	Metrics Metrics
+
+	// RemediationDomains are the lowercased, non-FQDN domain names that the
+	// quarantined devices are allowed to resolve along with their subdomains.
+	RemediationDomains []string
+}
+
+// Default is the default [Interface] implementation.  A device is quarantined
+// if either the backend has quarantined it, see [agd.Device.Quarantined], or
+// it has been quarantined with [Default.SetQuarantined].  It is safe for
+// concurrent use.
+type Default struct {
+	metrics Metrics
+
+	// mu protects devices.
+	mu *sync.RWMutex
+
+	// devices are the IDs of the devices quarantined with
+	// [Default.SetQuarantined].
+	devices *container.MapSet[agd.DeviceID]
+
+	remediation []string
+}
+
+// NewDefault returns a new properly initialized *Default.  c must be valid.
+func NewDefault(c *DefaultConfig) (d *Default) {
+	return &Default{
+		metrics:     c.Metrics,
+		remediation: c.RemediationDomains,
+		mu:          &sync.RWMutex{},
+		devices:     container.NewMapSet[agd.DeviceID](),
+	}
+}
+
+// type check
+var _ Interface = (*Default)(nil)
+
+// Check implements the [Interface] interface for *Default.
+func (d *Default) Check(
+	ctx context.Context,
+	dev *agd.Device,
+	host string,
+) (quarantined, allowed bool) {
+	if !dev.Quarantined && !d.has(dev.ID) {
+		return false, false
+	}
+
+	allowed = slices.ContainsFunc(d.remediation, func(domain string) (ok bool) {
+		return host == domain || netutil.IsSubdomain(host, domain)
+	})
+
+	d.metrics.OnRequest(ctx, allowed)
+
+	return true, allowed
+}
+
+// has returns true if the device with the given ID has been quarantined with
+// [Default.SetQuarantined].
+func (d *Default) has(id agd.DeviceID) (ok bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.devices.Has(id)
+}
+
+// SetQuarantined quarantines the device with the given ID or releases it from
+// the quarantine.  The devices quarantined by the backend can't be released
+// this way.
+func (d *Default) SetQuarantined(ctx context.Context, id agd.DeviceID, quarantined bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if quarantined {
+		d.devices.Add(id)
+	} else {
+		d.devices.Delete(id)
+	}
+
+	d.metrics.SetDevicesNum(ctx, uint(d.devices.Len()))
+}
+
+// DeviceIDs returns the sorted IDs of the devices quarantined with
+// [Default.SetQuarantined].
+func (d *Default) DeviceIDs() (ids []agd.DeviceID) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ids = d.devices.Values()
+	slices.Sort(ids)
+
+	return ids
+}
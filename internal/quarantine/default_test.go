@@ -0,0 +1,91 @@
+package quarantine_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quarantine"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// Common IDs for tests.
+const (
+	testDevID      agd.DeviceID = "dev1234"
+	testOtherDevID agd.DeviceID = "dev5678"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+func TestDefault_Check(t *testing.T) {
+	t.Parallel()
+
+	q := quarantine.NewDefault(&quarantine.DefaultConfig{
+		Metrics:            quarantine.EmptyMetrics{},
+		RemediationDomains: []string{"updates.example"},
+	})
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	q.SetQuarantined(ctx, testDevID, true)
+	q.SetQuarantined(ctx, testOtherDevID, true)
+	q.SetQuarantined(ctx, testOtherDevID, false)
+
+	assert.Equal(t, []agd.DeviceID{testDevID}, q.DeviceIDs())
+
+	testCases := []struct {
+		dev             *agd.Device
+		name            string
+		host            string
+		wantQuarantined bool
+		wantAllowed     bool
+	}{{
+		dev:             &agd.Device{ID: testDevID},
+		name:            "blocked",
+		host:            "www.example.com",
+		wantQuarantined: true,
+		wantAllowed:     false,
+	}, {
+		dev:             &agd.Device{ID: testDevID},
+		name:            "remediation",
+		host:            "updates.example",
+		wantQuarantined: true,
+		wantAllowed:     true,
+	}, {
+		dev:             &agd.Device{ID: testDevID},
+		name:            "remediation_subdomain",
+		host:            "dl.updates.example",
+		wantQuarantined: true,
+		wantAllowed:     true,
+	}, {
+		dev:             &agd.Device{ID: testDevID},
+		name:            "not_subdomain",
+		host:            "evilupdates.example",
+		wantQuarantined: true,
+		wantAllowed:     false,
+	}, {
+		dev:             &agd.Device{ID: testOtherDevID, Quarantined: true},
+		name:            "backend",
+		host:            "www.example.com",
+		wantQuarantined: true,
+		wantAllowed:     false,
+	}, {
+		dev:             &agd.Device{ID: testOtherDevID},
+		name:            "released",
+		host:            "www.example.com",
+		wantQuarantined: false,
+		wantAllowed:     false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			subCtx := testutil.ContextWithTimeout(t, testTimeout)
+			quarantined, allowed := q.Check(subCtx, tc.dev, tc.host)
+			assert.Equal(t, tc.wantQuarantined, quarantined)
+			assert.Equal(t, tc.wantAllowed, allowed)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+// Package quarantine contains the quarantine of the compromised devices, all
+// queries of which are blocked except the ones for the remediation domains.
+package quarantine
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// Interface is the interface for the quarantine of the devices.
+type Interface interface {
+	// Check returns true as quarantined if d is quarantined, and true as
+	// allowed if d is quarantined and host is a remediation domain or its
+	// subdomain.  d must not be nil.
+	Check(ctx context.Context, d *agd.Device, host string) (quarantined, allowed bool)
+}
+
+// Empty is an [Interface] implementation that never quarantines devices.
+type Empty struct{}
+
+// type check
+var _ Interface = Empty{}
+
+// Check implements the [Interface] interface for Empty.  It always returns
+// false.
+func (Empty) Check(_ context.Context, _ *agd.Device, _ string) (quarantined, allowed bool) {
+	return false, false
+}
+
+// Metrics is an interface for collection of the statistics of the quarantine.
+type Metrics interface {
+	// OnRequest records a request of a quarantined device.  allowed is true if
+	// the request is for a remediation domain.
+	OnRequest(ctx context.Context, allowed bool)
+
+	// SetDevicesNum sets the number of the devices quarantined with
+	// [Default.SetQuarantined].
+	SetDevicesNum(ctx context.Context, n uint)
+}
+
+// EmptyMetrics is an implementation of the [Metrics] interface that does
+// nothing.
+type EmptyMetrics struct{}
+
+// type check
+var _ Metrics = EmptyMetrics{}
+
+// OnRequest implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) OnRequest(_ context.Context, _ bool) {}
+
+// SetDevicesNum implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) SetDevicesNum(_ context.Context, _ uint) {}
@@ -13,6 +13,9 @@ import (
 
 // Entry is a single query log entry.
 type Entry struct {
+	// Time is the time of receiving the request.
+	Time time.Time
+
 	// RemoteIP is the remote IP address of the client.
 	RemoteIP netip.Addr
 
@@ -22,8 +25,14 @@ type Entry struct {
 	// ResponseResult is the result of filtering the DNS response.
 	ResponseResult filter.Result
 
-	// Time is the time of receiving the request.
-	Time time.Time
+	// LearnedResult is the parental-control blocking result that has not been
+	// enforced, because the device is in the learning mode, if any.  It is
+	// only logged if both RequestResult and ResponseResult are nil.
+	LearnedResult *filter.ResultBlocked
+
+	// ResponseCountry is the detected country of the first IP in the response
+	// sent to the client, if any.
+	ResponseCountry geoip.Country
 
 	// ProfileID is the detected profile ID, if any.
 	ProfileID agd.ProfileID
@@ -34,17 +43,11 @@ type Entry struct {
 	// ClientCountry is the detected country of the client's IP address, if any.
 	ClientCountry geoip.Country
 
-	// ResponseCountry is the detected country of the first IP in the response
-	// sent to the client, if any.
-	ResponseCountry geoip.Country
-
 	// DomainFQDN is the fully-qualified name of the requested resource.
 	DomainFQDN string
 
-	// RequestID is the ID of the request.
-	//
-	// TODO(a.garipov): Remove once not necessary anymore.
-	RequestID agd.RequestID
+	// Categories are the content categories of the requested host, if any.
+	Categories []filter.Category
 
 	// Elapsed is the time passed since the beginning of the request processing.
 	Elapsed time.Duration
@@ -59,9 +62,17 @@ type Entry struct {
 	// ResponseCode is the response code sent to the client.
 	ResponseCode dnsmsg.RCode
 
+	// RequestID is the ID of the request.
+	//
+	// TODO(a.garipov): Remove once not necessary anymore.
+	RequestID agd.RequestID
+
 	// Protocol is the DNS protocol used.
 	Protocol agd.Protocol
 
+	// ECSMode is the EDNS Client Subnet mode applied to the request.
+	ECSMode agd.ECSMode
+
 	// DNSSEC is set to true if the response was validated with DNSSEC.
 	DNSSEC bool
 }
@@ -83,11 +94,23 @@ const (
 	resultCodeReqAllowed  resultCode = 4
 	resultCodeRespAllowed resultCode = 5
 	resultCodeModified    resultCode = 6
+	resultCodeLearned     resultCode = 7
 )
 
 // resultData returns the resultCode, filter list ID, and filtering rule from
-// the request and response filtering results.
-func resultData(req, resp filter.Result) (c resultCode, id filter.ID, r filter.RuleText) {
+// the request and response filtering results as well as the result learned
+// but not enforced, if any.
+func resultData(
+	req filter.Result,
+	resp filter.Result,
+	learned *filter.ResultBlocked,
+) (c resultCode, id filter.ID, r filter.RuleText) {
+	if req == nil && resp == nil && learned != nil {
+		id, r = learned.MatchedRule()
+
+		return resultCodeLearned, id, r
+	}
+
 	if req == nil {
 		c = toResultCode(resp, true)
 		if resp != nil {
@@ -133,6 +156,36 @@ func toResultCode(r filter.Result, resp bool) (c resultCode) {
 	}
 }
 
+// ecsCode is the code that identifies the EDNS Client Subnet data sent to the
+// upstreams.
+type ecsCode uint8
+
+// ECS code values.
+const (
+	// NOTE: DO NOT change the numerical values or use iota, because other
+	// packages and modules may depend on the numerical values.  These numerical
+	// values are a part of the API.
+
+	ecsCodeInvalid   ecsCode = 0
+	ecsCodeReplaced  ecsCode = 1
+	ecsCodeForwarded ecsCode = 2
+	ecsCodeStripped  ecsCode = 3
+)
+
+// toECSCode converts an agd.ECSMode into an ecsCode.
+func toECSCode(m agd.ECSMode) (c ecsCode) {
+	switch m {
+	case agd.ECSModeReplace:
+		return ecsCodeReplaced
+	case agd.ECSModeForward:
+		return ecsCodeForwarded
+	case agd.ECSModeStrip:
+		return ecsCodeStripped
+	default:
+		return ecsCodeInvalid
+	}
+}
+
 // jsonlEntry is a single JSONL query log jsonlEntry / line.
 type jsonlEntry struct {
 	// RemoteIP is the remote IP address of the client.  This field is optional.
@@ -187,6 +240,12 @@ type jsonlEntry struct {
 	// The short name "m" stands for "match".
 	FilterRule filter.RuleText `json:"m,omitempty"`
 
+	// Categories are the content categories of the requested host.  If there
+	// are none, this field is omitted.
+	//
+	// The short name "cat" stands for "categories".
+	Categories []filter.Category `json:"cat,omitempty"`
+
 	// Timestamp is the Unix time of receiving the request in milliseconds.
 	//
 	// The short name "t" stands for "time".
@@ -235,4 +294,9 @@ type jsonlEntry struct {
 	//
 	// The short name "p" stands for "protocol".
 	Protocol agd.Protocol `json:"p"`
+
+	// ECS is the EDNS Client Subnet data sent to the upstreams.
+	//
+	// The name "ecs" stands for "EDNS Client Subnet".
+	ECS ecsCode `json:"ecs"`
 }
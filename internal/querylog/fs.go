@@ -108,8 +108,9 @@ func (l *FileSystem) Write(ctx context.Context, e *Entry) (err error) {
 		remoteIP = &e.RemoteIP
 	}
 
-	c, id, r := resultData(e.RequestResult, e.ResponseResult)
+	c, id, r := resultData(e.RequestResult, e.ResponseResult, e.LearnedResult)
 	*entBuf.ent = jsonlEntry{
+		Categories:      e.Categories,
 		RequestID:       e.RequestID.String(),
 		ProfileID:       e.ProfileID,
 		DeviceID:        e.DeviceID,
@@ -120,7 +121,7 @@ func (l *FileSystem) Write(ctx context.Context, e *Entry) (err error) {
 		FilterRule:      r,
 		Timestamp:       e.Time.UnixMilli(),
 		ClientASN:       e.ClientASN,
-		Elapsed:         l.convertElapsed(ctx, e.Elapsed),
+		Elapsed:         convertElapsed(ctx, l.logger, e.Elapsed),
 		RequestType:     e.RequestType,
 		ResponseCode:    e.ResponseCode,
 		// #nosec G115 -- The overflow is safe, since this is a random number.
@@ -129,6 +130,7 @@ func (l *FileSystem) Write(ctx context.Context, e *Entry) (err error) {
 		Protocol:   e.Protocol,
 		ResultCode: c,
 		RemoteIP:   remoteIP,
+		ECS:        toECSCode(e.ECSMode),
 	}
 
 	var f *os.File
@@ -158,17 +160,21 @@ func (l *FileSystem) Write(ctx context.Context, e *Entry) (err error) {
 
 // convertElapsed converts the elapsed duration and writes warnings to the log
 // if the value is outside of the allowed limits.
-func (l *FileSystem) convertElapsed(ctx context.Context, elapsed time.Duration) (elapsedMs uint32) {
+func convertElapsed(
+	ctx context.Context,
+	logger *slog.Logger,
+	elapsed time.Duration,
+) (elapsedMs uint32) {
 	elapsedMs64 := elapsed.Milliseconds()
 	if elapsedMs64 < 0 {
-		l.logger.WarnContext(ctx, "elapsed below zero; setting to zero")
+		logger.WarnContext(ctx, "elapsed below zero; setting to zero")
 
 		return 0
 	}
 
 	const maxElapsedMs = math.MaxUint32
 	if elapsedMs64 > maxElapsedMs {
-		l.logger.WarnContext(ctx, "elapsed above max uint32; setting to max uint32")
+		logger.WarnContext(ctx, "elapsed above max uint32; setting to max uint32")
 
 		return maxElapsedMs
 	}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
 	"github.com/AdguardTeam/AdGuardDNS/internal/geoip"
 	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
@@ -60,7 +61,8 @@ func TestFileSystem_Write(t *testing.T) {
   "rn":35121,
   "f":2,
   "s":1,
-  "p":8
+  "p":8,
+  "ecs":1
 }`) + "\n"
 
 	assert.Equal(t, want, string(b))
@@ -97,7 +99,52 @@ func TestFileSystem_Write(t *testing.T) {
   "rn":47387,
   "f":1,
   "s":1,
-  "p":8
+  "p":8,
+  "ecs":1
+}`) + "\n"
+
+		assert.Equal(t, want, string(b))
+	})
+
+	t.Run("learned", func(t *testing.T) {
+		e = testEntry()
+		e.RequestResult, e.ResponseResult = nil, nil
+		e.LearnedResult = &filter.ResultBlocked{
+			List: filter.IDAdultBlocking,
+			Rule: "adult.example",
+		}
+
+		err = l.Write(ctx, e)
+		require.NoError(t, err)
+
+		b, err = io.ReadAll(f)
+		require.NoError(t, err)
+
+		rep = strings.NewReplacer(
+			" ", "",
+			"\n", "",
+			"REQID", testRequestID.String(),
+		)
+		want = rep.Replace(`
+{
+  "u":"REQID",
+  "b":"prof1234",
+  "i":"dev1234",
+  "c":"RU",
+  "d":"US",
+  "n":"example.com.",
+  "l":"adult_blocking",
+  "m":"adult.example",
+  "t":123000,
+  "a":1234,
+  "e":5,
+  "q":1,
+  "r":0,
+  "rn":406,
+  "f":7,
+  "s":1,
+  "p":8,
+  "ecs":1
 }`) + "\n"
 
 		assert.Equal(t, want, string(b))
@@ -0,0 +1,394 @@
+package querylog
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/querylog/querylogpb"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/c2h5oh/datasize"
+	renameio "github.com/google/renameio/v2"
+	"golang.org/x/exp/rand"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// CompressedExt is the extension of the compressed rotated protobuf query log
+// files.
+const CompressedExt = ".gz"
+
+// rotatedTimeFormat is the format of the timestamp appended to the names of the
+// rotated protobuf query log files.  It has a fixed width, so that the names
+// are sorted chronologically.
+const rotatedTimeFormat = "20060102T150405.000000000"
+
+// ProtobufFileSystemConfig is the configuration of the protobuf file system
+// query log.
+type ProtobufFileSystemConfig struct {
+	// Logger is used for debug logging.  It must not be nil.
+	Logger *slog.Logger
+
+	// Clock is used to get the timestamps of the rotated files.  It must not
+	// be nil.
+	Clock agdtime.Clock
+
+	// Path is the path to the log file.  It must not be empty.
+	Path string
+
+	// RandSeed is used to set the random field of the entries.
+	RandSeed uint64
+
+	// MaxSize is the size of the log file after which it is rotated.  It must
+	// be positive.
+	MaxSize datasize.ByteSize
+
+	// MaxBackups is the number of the rotated files to keep.  If it is zero,
+	// all rotated files are kept.  It must not be negative.
+	MaxBackups int
+
+	// Compress shows if the rotated files are compressed with gzip.
+	Compress bool
+}
+
+// ProtobufFileSystem is the file system implementation of the AdGuard DNS query
+// log that writes length-delimited protobuf records, see package querylogpb.
+// Unlike [FileSystem], it keeps the file open and rotates it on its own.
+type ProtobufFileSystem struct {
+	clock  agdtime.Clock
+	logger *slog.Logger
+	rng    *rand.Rand
+
+	// mu protects file, size, and buf.
+	mu *sync.Mutex
+
+	// file is the current log file.  It is nil if the file hasn't been opened
+	// yet or has just been rotated.
+	file *os.File
+
+	path string
+
+	// buf is the reused buffer for the serialized records.
+	buf []byte
+
+	maxSize int64
+
+	maxBackups int
+
+	// size is the current size of file.
+	size int64
+
+	compress bool
+}
+
+// NewProtobufFileSystem creates a new protobuf file system query log.  The log
+// is safe for concurrent use.  c must not be nil and must be valid.
+func NewProtobufFileSystem(c *ProtobufFileSystemConfig) (l *ProtobufFileSystem) {
+	rng := rand.New(&rand.LockedSource{})
+	rng.Seed(c.RandSeed)
+
+	return &ProtobufFileSystem{
+		logger: c.Logger,
+		clock:  c.Clock,
+		rng:    rng,
+		path:   c.Path,
+		// #nosec G115 -- The maximum size is validated in the configuration
+		// and is much less than [math.MaxInt64].
+		maxSize:    int64(c.MaxSize.Bytes()),
+		maxBackups: c.MaxBackups,
+		compress:   c.Compress,
+		mu:         &sync.Mutex{},
+	}
+}
+
+// type check
+var _ Interface = (*ProtobufFileSystem)(nil)
+
+// Write implements the Interface interface for *ProtobufFileSystem.
+func (l *ProtobufFileSystem) Write(ctx context.Context, e *Entry) (err error) {
+	optslog.Trace1(ctx, l.logger, "writing protobuf logs", "req_id", e.RequestID)
+	defer func() {
+		optslog.Trace2(
+			ctx,
+			l.logger,
+			"writing protobuf logs",
+			"req_id", e.RequestID,
+			slogutil.KeyError, err,
+		)
+	}()
+
+	startTime := time.Now()
+	defer func() {
+		metrics.QueryLogWriteDuration.Observe(time.Since(startTime).Seconds())
+		metrics.QueryLogItemsCount.Inc()
+	}()
+
+	ent := l.toProtobuf(ctx, e)
+
+	rotated, err := l.write(ent)
+	if err != nil {
+		return fmt.Errorf("writing log: %w", err)
+	}
+
+	if rotated != "" {
+		// Don't wrap the error, because it's informative enough as is.
+		return l.processRotated(ctx, rotated)
+	}
+
+	return nil
+}
+
+// toProtobuf converts e into a protobuf entry.
+func (l *ProtobufFileSystem) toProtobuf(ctx context.Context, e *Entry) (ent *querylogpb.Entry) {
+	c, id, r := resultData(e.RequestResult, e.ResponseResult, e.LearnedResult)
+
+	ent = &querylogpb.Entry{
+		RequestId:       e.RequestID.String(),
+		ProfileId:       string(e.ProfileID),
+		DeviceId:        string(e.DeviceID),
+		ClientCountry:   string(e.ClientCountry),
+		ResponseCountry: string(e.ResponseCountry),
+		DomainFqdn:      e.DomainFQDN,
+		FilterListId:    string(id),
+		FilterRule:      string(r),
+		TimestampMs:     e.Time.UnixMilli(),
+		ClientAsn:       uint32(e.ClientASN),
+		ElapsedMs:       convertElapsed(ctx, l.logger, e.Elapsed),
+		RequestType:     uint32(e.RequestType),
+		ResponseCode:    uint32(e.ResponseCode),
+		// #nosec G115 -- The overflow is safe, since this is a random number.
+		Random:     uint32(uint16(l.rng.Uint32())),
+		ResultCode: uint32(c),
+		Dnssec:     e.DNSSEC,
+		Protocol:   uint32(e.Protocol),
+		Ecs:        uint32(toECSCode(e.ECSMode)),
+	}
+
+	if e.RemoteIP.IsValid() {
+		ent.RemoteIp = e.RemoteIP.AsSlice()
+	}
+
+	return ent
+}
+
+// write writes ent into the log file, opening it if necessary, and rotates the
+// file if it has become too large.  rotated is the path to the rotated file, if
+// any.
+func (l *ProtobufFileSystem) write(ent *querylogpb.Entry) (rotated string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		err = l.openLocked()
+		if err != nil {
+			// Don't wrap the error, because it's informative enough as is.
+			return "", err
+		}
+	}
+
+	written, err := l.writeRecordLocked(ent)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return "", err
+	}
+
+	metrics.QueryLogItemSize.Observe(float64(written))
+
+	if l.size < l.maxSize {
+		return "", nil
+	}
+
+	return l.rotateLocked()
+}
+
+// openLocked opens the log file and writes the header into it, if the file is
+// empty.  l.mu must be locked.
+func (l *ProtobufFileSystem) openLocked() (err error) {
+	f, err := os.OpenFile(l.path, agd.DefaultWOFlags, agd.DefaultPerm)
+	if err != nil {
+		return fmt.Errorf("opening query log file: %w", err)
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return errors.WithDeferred(fmt.Errorf("getting file info: %w", err), f.Close())
+	}
+
+	l.file, l.size = f, fi.Size()
+	if l.size > 0 {
+		return nil
+	}
+
+	_, err = l.writeRecordLocked(&querylogpb.Header{
+		Version: querylogpb.SchemaVersion,
+	})
+	if err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+
+	return nil
+}
+
+// writeRecordLocked writes m as a length-delimited record into the log file.
+// l.mu must be locked, and the file must be open.
+func (l *ProtobufFileSystem) writeRecordLocked(m proto.Message) (written int, err error) {
+	l.buf = protowire.AppendVarint(l.buf[:0], uint64(proto.Size(m)))
+	l.buf, err = proto.MarshalOptions{}.MarshalAppend(l.buf, m)
+	if err != nil {
+		return 0, fmt.Errorf("marshaling: %w", err)
+	}
+
+	written, err = l.file.Write(l.buf)
+	l.size += int64(written)
+	if err != nil {
+		return written, fmt.Errorf("writing: %w", err)
+	}
+
+	return written, nil
+}
+
+// rotateLocked closes the log file and renames it.  The next write opens a new
+// log file.  l.mu must be locked, and the file must be open.
+func (l *ProtobufFileSystem) rotateLocked() (rotated string, err error) {
+	err = l.file.Close()
+	l.file, l.size = nil, 0
+	if err != nil {
+		return "", fmt.Errorf("closing file for rotation: %w", err)
+	}
+
+	rotated = l.path + "." + l.clock.Now().UTC().Format(rotatedTimeFormat)
+	err = os.Rename(l.path, rotated)
+	if err != nil {
+		return "", fmt.Errorf("rotating file: %w", err)
+	}
+
+	return rotated, nil
+}
+
+// processRotated compresses the rotated file, if necessary, and removes the
+// rotated files that exceed the limit.
+func (l *ProtobufFileSystem) processRotated(ctx context.Context, rotated string) (err error) {
+	l.logger.DebugContext(ctx, "rotated query log", "path", rotated)
+
+	if l.compress {
+		err = compressFile(rotated)
+		if err != nil {
+			return fmt.Errorf("compressing rotated file: %w", err)
+		}
+	}
+
+	if l.maxBackups == 0 {
+		return nil
+	}
+
+	err = l.removeOldBackups(ctx)
+	if err != nil {
+		return fmt.Errorf("removing old rotated files: %w", err)
+	}
+
+	return nil
+}
+
+// compressFile compresses the file at path with gzip into a new file with
+// [CompressedExt] and removes the original one.
+func compressFile(path string) (err error) {
+	// #nosec G304 -- Trust the path of the rotated file, since it's based on
+	// the path from the environment.
+	src, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("opening: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, src.Close()) }()
+
+	dst, err := renameio.NewPendingFile(
+		path+CompressedExt,
+		renameio.WithPermissions(agd.DefaultPerm),
+	)
+	if err != nil {
+		return fmt.Errorf("creating compressed file: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, dst.Cleanup()) }()
+
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err != nil {
+		return fmt.Errorf("compressing: %w", err)
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return fmt.Errorf("flushing compressed data: %w", err)
+	}
+
+	err = dst.CloseAtomicallyReplace()
+	if err != nil {
+		return fmt.Errorf("saving compressed file: %w", err)
+	}
+
+	return os.Remove(path)
+}
+
+// removeOldBackups removes the oldest rotated files, so that at most
+// l.maxBackups of them remain.
+func (l *ProtobufFileSystem) removeOldBackups(ctx context.Context) (err error) {
+	dir, base := filepath.Split(l.path)
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return fmt.Errorf("reading dir: %w", err)
+	}
+
+	var backups []string
+	for _, ent := range entries {
+		name := ent.Name()
+		if ent.Type().IsRegular() && isRotatedName(name, base) {
+			backups = append(backups, name)
+		}
+	}
+
+	if len(backups) <= l.maxBackups {
+		return nil
+	}
+
+	// The names only differ in the fixed-width timestamps and, possibly, the
+	// compression extension, so the lexical order is chronological.
+	slices.Sort(backups)
+
+	var errs []error
+	for _, name := range backups[:len(backups)-l.maxBackups] {
+		l.logger.DebugContext(ctx, "removing rotated query log", "name", name)
+
+		err = os.Remove(filepath.Join(dir, name))
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// isRotatedName returns true if name is the name of a rotated file for the log
+// file with the name base.
+func isRotatedName(name, base string) (ok bool) {
+	ts, ok := strings.CutPrefix(name, base+".")
+	if !ok {
+		return false
+	}
+
+	ts = strings.TrimSuffix(ts, CompressedExt)
+	_, err := time.Parse(rotatedTimeFormat, ts)
+
+	return err == nil
+}
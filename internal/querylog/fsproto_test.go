@@ -0,0 +1,148 @@
+package querylog_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/querylog/querylogpb"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/c2h5oh/datasize"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/proto"
+)
+
+// readProtobufLog is a helper that reads the header and the entries from the
+// protobuf query log in r.
+func readProtobufLog(tb testing.TB, r io.Reader) (ents []*querylogpb.Entry) {
+	tb.Helper()
+
+	br := bufio.NewReader(r)
+
+	hdr := &querylogpb.Header{}
+	err := protodelim.UnmarshalFrom(br, hdr)
+	require.NoError(tb, err)
+
+	require.Equal(tb, querylogpb.SchemaVersion, hdr.Version)
+
+	for {
+		ent := &querylogpb.Entry{}
+		err = protodelim.UnmarshalFrom(br, ent)
+		if err == io.EOF {
+			return ents
+		}
+
+		require.NoError(tb, err)
+
+		ents = append(ents, ent)
+	}
+}
+
+func TestProtobufFileSystem_Write(t *testing.T) {
+	t.Parallel()
+
+	logPath := filepath.Join(t.TempDir(), "querylog.pb")
+	l := querylog.NewProtobufFileSystem(&querylog.ProtobufFileSystemConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		Clock: &agdtest.Clock{
+			OnNow: time.Now,
+		},
+		Path:     logPath,
+		RandSeed: 0,
+		MaxSize:  datasize.MB,
+	})
+
+	ctx := context.Background()
+	for range 2 {
+		require.NoError(t, l.Write(ctx, testEntry()))
+	}
+
+	f, err := os.Open(logPath)
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, f.Close)
+
+	ents := readProtobufLog(t, f)
+	require.Len(t, ents, 2)
+
+	want := &querylogpb.Entry{
+		RequestId:       testRequestID.String(),
+		ProfileId:       "prof1234",
+		DeviceId:        "dev1234",
+		ClientCountry:   "RU",
+		ResponseCountry: "US",
+		DomainFqdn:      "example.com.",
+		FilterListId:    "adguard_dns_filter",
+		FilterRule:      "||example.com^",
+		TimestampMs:     123_000,
+		ClientAsn:       1234,
+		ElapsedMs:       5,
+		RequestType:     1,
+		ResponseCode:    0,
+		Random:          ents[0].Random,
+		ResultCode:      2,
+		Dnssec:          true,
+		Protocol:        8,
+		Ecs:             1,
+	}
+
+	assert.True(t, proto.Equal(want, ents[0]), "got %v", ents[0])
+}
+
+func TestProtobufFileSystem_Write_rotation(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	clock := &agdtest.Clock{
+		OnNow: func() (n time.Time) {
+			now = now.Add(time.Second)
+
+			return now
+		},
+	}
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "querylog.pb")
+	l := querylog.NewProtobufFileSystem(&querylog.ProtobufFileSystemConfig{
+		Logger:     slogutil.NewDiscardLogger(),
+		Clock:      clock,
+		Path:       logPath,
+		RandSeed:   0,
+		MaxSize:    1 * datasize.B,
+		MaxBackups: 2,
+		Compress:   true,
+	})
+
+	ctx := context.Background()
+	for range 3 {
+		require.NoError(t, l.Write(ctx, testEntry()))
+	}
+
+	rotated, err := filepath.Glob(logPath + ".*" + querylog.CompressedExt)
+	require.NoError(t, err)
+	require.Len(t, rotated, 2)
+
+	assert.NoFileExists(t, logPath)
+
+	// Make sure that the oldest file has been removed.
+	assert.Equal(t, logPath+".20231114T221322.000000000"+querylog.CompressedExt, rotated[0])
+
+	f, err := os.Open(rotated[1])
+	require.NoError(t, err)
+	testutil.CleanupAndRequireSuccess(t, f.Close)
+
+	zr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+
+	ents := readProtobufLog(t, zr)
+	assert.Len(t, ents, 1)
+}
@@ -4,6 +4,9 @@ package querylog
 
 import (
 	"context"
+	"fmt"
+
+	"github.com/AdguardTeam/golibs/errors"
 )
 
 // Interface is the query log interface.  All methods must be safe for
@@ -24,3 +27,27 @@ var _ Interface = Empty{}
 func (Empty) Write(_ context.Context, _ *Entry) (err error) {
 	return nil
 }
+
+// FileFormat is the format of the file system query log.
+type FileFormat string
+
+// Valid [FileFormat] values.
+const (
+	// FileFormatJSONL is the format of [FileSystem], where each entry is a JSON
+	// object on its own line.
+	FileFormatJSONL FileFormat = "jsonl"
+
+	// FileFormatProtobuf is the format of [ProtobufFileSystem], where each
+	// entry is a length-delimited protobuf record.
+	FileFormatProtobuf FileFormat = "protobuf"
+)
+
+// Validate returns an error if f is not a valid format.
+func (f FileFormat) Validate() (err error) {
+	switch f {
+	case FileFormatJSONL, FileFormatProtobuf:
+		return nil
+	default:
+		return fmt.Errorf("format: %w: %q", errors.ErrBadEnumValue, f)
+	}
+}
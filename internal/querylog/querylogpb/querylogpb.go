@@ -0,0 +1,11 @@
+// Package querylogpb contains the protobuf structures for the query log files
+// in the length-delimited protobuf format.
+//
+// Each file consists of a [Header] record followed by [Entry] records.  Each
+// record is prefixed with its length encoded as a varint, see package
+// [google.golang.org/protobuf/encoding/protodelim].
+package querylogpb
+
+// SchemaVersion is the current version of the schema of the query log files.
+// It must be changed every time an incompatible change is made to the schema.
+const SchemaVersion uint32 = 1
@@ -0,0 +1,313 @@
+package querylog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/golibs/errors"
+	renameio "github.com/google/renameio/v2"
+)
+
+// IndexExt is the extension of the index sidecar files of the query log files.
+const IndexExt = ".idx"
+
+// Index is the index of the entries of a query log file by profile.  It is
+// stored in a sidecar file next to the query log file to avoid scanning the
+// whole file on every redaction.
+type Index struct {
+	// Profiles maps profile IDs to the locations of their entries.
+	Profiles map[agd.ProfileID][]*IndexSpan `json:"profiles"`
+
+	// Size is the number of bytes at the beginning of the query log file that
+	// are covered by the index.
+	Size int64 `json:"size"`
+}
+
+// IndexSpan is the location of a single entry in a query log file.
+type IndexSpan struct {
+	// DeviceID is the ID of the device of the entry, if any.
+	DeviceID agd.DeviceID `json:"i,omitempty"`
+
+	// Offset is the offset of the entry from the beginning of the file.
+	Offset int64 `json:"o"`
+
+	// Length is the length of the entry excluding the trailing newline.
+	Length int64 `json:"l"`
+}
+
+// UpdateIndex reads the index sidecar file of the query log file at logPath,
+// if there is one, adds the entries appended to the log file since the index
+// has been written, and returns the result.  The index is not written back.
+func UpdateIndex(logPath string) (idx *Index, err error) {
+	idx, err = readIndex(logPath + IndexExt)
+	if err != nil {
+		return nil, fmt.Errorf("reading index: %w", err)
+	}
+
+	f, err := os.Open(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening query log: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("getting query log info: %w", err)
+	}
+
+	if fi.Size() < idx.Size {
+		// The file has been replaced, so the index is stale.
+		idx = newIndex()
+	}
+
+	_, err = f.Seek(idx.Size, io.SeekStart)
+	if err != nil {
+		return nil, fmt.Errorf("seeking query log: %w", err)
+	}
+
+	err = idx.scan(f)
+	if err != nil {
+		return nil, fmt.Errorf("indexing query log: %w", err)
+	}
+
+	return idx, nil
+}
+
+// newIndex returns a new empty *Index.
+func newIndex() (idx *Index) {
+	return &Index{
+		Profiles: map[agd.ProfileID][]*IndexSpan{},
+	}
+}
+
+// readIndex reads the index from the file at path.  If there is no such file,
+// idx is a new empty index.
+func readIndex(path string) (idx *Index, err error) {
+	// #nosec G304 -- Trust the path, since it's derived from the path to the
+	// query log file given by the operator.
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return newIndex(), nil
+	} else if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	idx = newIndex()
+	err = json.Unmarshal(b, idx)
+	if err != nil {
+		return nil, fmt.Errorf("decoding: %w", err)
+	}
+
+	return idx, nil
+}
+
+// WriteIndex writes idx into the index sidecar file of the query log file at
+// logPath atomically.
+func WriteIndex(logPath string, idx *Index) (err error) {
+	b, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("encoding index: %w", err)
+	}
+
+	err = renameio.WriteFile(logPath+IndexExt, b, agd.DefaultPerm)
+	if err != nil {
+		return fmt.Errorf("writing index: %w", err)
+	}
+
+	return nil
+}
+
+// indexedEntry contains the properties of an entry used for indexing.
+type indexedEntry struct {
+	ProfileID agd.ProfileID `json:"b"`
+	DeviceID  agd.DeviceID  `json:"i"`
+}
+
+// scan adds the entries read from r to idx.  r must be positioned at idx.Size.
+// An incomplete last line, which may still be being written, is not indexed.
+func (idx *Index) scan(r io.Reader) (err error) {
+	br := bufio.NewReader(r)
+	for {
+		var line []byte
+		line, err = br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("reading at offset %d: %w", idx.Size, err)
+		}
+
+		span := &IndexSpan{
+			Offset: idx.Size,
+			Length: int64(len(line) - 1),
+		}
+		idx.Size += int64(len(line))
+
+		e := &indexedEntry{}
+		err = json.Unmarshal(line, e)
+		if err != nil {
+			return fmt.Errorf("decoding entry at offset %d: %w", span.Offset, err)
+		} else if e.ProfileID == "" {
+			continue
+		}
+
+		span.DeviceID = e.DeviceID
+		idx.Profiles[e.ProfileID] = append(idx.Profiles[e.ProfileID], span)
+	}
+}
+
+// RedactMode is the mode of redacting query log entries.
+type RedactMode string
+
+// Valid [RedactMode] values.
+const (
+	// RedactModeDelete replaces the entries with empty JSON objects.
+	RedactModeDelete RedactMode = "delete"
+
+	// RedactModeAnonymize removes the properties that identify the client,
+	// such as the profile, device, and IP address, as well as the requested
+	// domain name and the matched rule, from the entries.
+	RedactModeAnonymize RedactMode = "anonymize"
+)
+
+// RedactConfig is the configuration for redacting the entries of a query log
+// file.
+type RedactConfig struct {
+	// ProfileID is the ID of the profile the entries of which are redacted.
+	// It must not be empty.
+	ProfileID agd.ProfileID
+
+	// DeviceID, if not empty, limits the redaction to the entries of this
+	// device.
+	DeviceID agd.DeviceID
+
+	// Mode is the redaction mode.  It must be a valid [RedactMode] value.
+	Mode RedactMode
+}
+
+// Redact redacts the entries of the query log file at logPath matching c in
+// place, updating and writing the index sidecar file.  The size of the file
+// and the offsets of the entries don't change, since every redacted entry is
+// padded with spaces to its original length, so the query log file can still
+// be written into.  n is the number of redacted entries.
+func Redact(logPath string, c *RedactConfig) (n int, err error) {
+	switch c.Mode {
+	case RedactModeDelete, RedactModeAnonymize:
+		// Go on.
+	default:
+		return 0, fmt.Errorf("mode: %w: %q", errors.ErrBadEnumValue, c.Mode)
+	}
+
+	idx, err := UpdateIndex(logPath)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return 0, err
+	}
+
+	n, err = redactIndexed(logPath, idx, c)
+	if err != nil {
+		// Write the index anyway to save the work on indexing, but don't lose
+		// the original error.
+		return n, errors.WithDeferred(err, WriteIndex(logPath, idx))
+	}
+
+	return n, WriteIndex(logPath, idx)
+}
+
+// redactIndexed redacts the entries of the query log file at logPath matching c
+// using idx and removes the redacted entries from idx.
+func redactIndexed(logPath string, idx *Index, c *RedactConfig) (n int, err error) {
+	spans := idx.Profiles[c.ProfileID]
+	if len(spans) == 0 {
+		return 0, nil
+	}
+
+	f, err := os.OpenFile(logPath, os.O_RDWR, agd.DefaultPerm)
+	if err != nil {
+		return 0, fmt.Errorf("opening query log: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+	// Remove the spans as they are redacted so that the index stays consistent
+	// even if there is an error in the middle.
+	var redactErr error
+	spans = slices.DeleteFunc(spans, func(s *IndexSpan) (ok bool) {
+		if redactErr != nil || (c.DeviceID != "" && s.DeviceID != c.DeviceID) {
+			return false
+		}
+
+		redactErr = redactSpan(f, s, c)
+		if redactErr != nil {
+			return false
+		}
+
+		n++
+
+		return true
+	})
+
+	if len(spans) == 0 {
+		delete(idx.Profiles, c.ProfileID)
+	} else {
+		idx.Profiles[c.ProfileID] = spans
+	}
+
+	return n, redactErr
+}
+
+// redactSpan redacts the entry located at s in f according to c.
+func redactSpan(f *os.File, s *IndexSpan, c *RedactConfig) (err error) {
+	line := make([]byte, s.Length)
+	_, err = f.ReadAt(line, s.Offset)
+	if err != nil {
+		return fmt.Errorf("reading entry at offset %d: %w", s.Offset, err)
+	}
+
+	e := &jsonlEntry{}
+	err = json.Unmarshal(line, e)
+	if err != nil {
+		return fmt.Errorf("decoding entry at offset %d: %w", s.Offset, err)
+	} else if e.ProfileID != c.ProfileID {
+		return fmt.Errorf("entry at offset %d: stale index: got profile %q", s.Offset, e.ProfileID)
+	}
+
+	redacted := redactedEntry(e, c.Mode, len(line))
+	_, err = f.WriteAt(redacted, s.Offset)
+	if err != nil {
+		return fmt.Errorf("writing entry at offset %d: %w", s.Offset, err)
+	}
+
+	return nil
+}
+
+// emptyEntry is the data of a deleted entry.
+const emptyEntry = "{}"
+
+// redactedEntry returns the redacted data of e padded with spaces to l bytes.
+// l must be at least len(emptyEntry).
+func redactedEntry(e *jsonlEntry, mode RedactMode, l int) (b []byte) {
+	b = []byte(emptyEntry)
+	if mode == RedactModeAnonymize {
+		e.RemoteIP = nil
+		e.ProfileID = ""
+		e.DeviceID = ""
+		e.ClientCountry = ""
+		e.DomainFQDN = ""
+		e.FilterRule = ""
+		e.ClientASN = 0
+
+		anon, err := json.Marshal(e)
+		if err == nil && len(anon) <= l {
+			b = anon
+		}
+	}
+
+	return append(b, bytes.Repeat([]byte{' '}, l-len(b))...)
+}
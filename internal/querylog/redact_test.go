@@ -0,0 +1,127 @@
+package querylog_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readEntries returns the entries from the query log file at path as maps.
+func readEntries(tb testing.TB, path string) (entries []map[string]any) {
+	tb.Helper()
+
+	f, err := os.Open(path)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { require.NoError(tb, f.Close()) })
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var e map[string]any
+		require.NoError(tb, json.Unmarshal(s.Bytes(), &e))
+
+		entries = append(entries, e)
+	}
+
+	require.NoError(tb, s.Err())
+
+	return entries
+}
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "querylog.jsonl")
+	l := querylog.NewFileSystem(&querylog.FileSystemConfig{
+		Logger:   slogutil.NewDiscardLogger(),
+		Path:     path,
+		RandSeed: 0,
+	})
+
+	const (
+		otherProfileID agd.ProfileID = "prof5678"
+		otherDeviceID  agd.DeviceID  = "dev5678"
+	)
+
+	ctx := context.Background()
+	write := func(profID agd.ProfileID, devID agd.DeviceID) {
+		t.Helper()
+
+		e := testEntry()
+		e.ProfileID, e.DeviceID = profID, devID
+		require.NoError(t, l.Write(ctx, e))
+	}
+
+	write("prof1234", "dev1234")
+	write("prof1234", otherDeviceID)
+	write(otherProfileID, "dev1234")
+
+	n, err := querylog.Redact(path, &querylog.RedactConfig{
+		ProfileID: "prof1234",
+		DeviceID:  otherDeviceID,
+		Mode:      querylog.RedactModeDelete,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "prof1234", entries[0]["b"])
+	assert.Empty(t, entries[1])
+	assert.Equal(t, string(otherProfileID), entries[2]["b"])
+
+	// Add an entry after the index has been written.
+	write("prof1234", "dev1234")
+
+	n, err = querylog.Redact(path, &querylog.RedactConfig{
+		ProfileID: "prof1234",
+		Mode:      querylog.RedactModeAnonymize,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	entries = readEntries(t, path)
+	require.Len(t, entries, 4)
+
+	for _, i := range []int{0, 3} {
+		e := entries[i]
+		assert.Empty(t, e["b"])
+		assert.Empty(t, e["i"])
+		assert.Empty(t, e["n"])
+		assert.NotContains(t, e, "m")
+		assert.NotContains(t, e, "c")
+		assert.NotContains(t, e, "a")
+	}
+
+	assert.Equal(t, "example.com.", entries[2]["n"])
+
+	idx, err := querylog.UpdateIndex(path)
+	require.NoError(t, err)
+
+	assert.NotContains(t, idx.Profiles, agd.ProfileID("prof1234"))
+	assert.Len(t, idx.Profiles[otherProfileID], 1)
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, fi.Size(), idx.Size)
+}
+
+func TestRedact_badMode(t *testing.T) {
+	t.Parallel()
+
+	_, err := querylog.Redact(filepath.Join(t.TempDir(), "querylog.jsonl"), &querylog.RedactConfig{
+		ProfileID: "prof1234",
+		Mode:      "bad",
+	})
+	assert.ErrorContains(t, err, `mode: bad enum value: "bad"`)
+}
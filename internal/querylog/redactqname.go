@@ -0,0 +1,39 @@
+package querylog
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnameredact"
+)
+
+// QNameRedacting is a query log that redacts the domain names of the entries
+// before writing them into another query log.
+type QNameRedacting struct {
+	log      Interface
+	redactor *qnameredact.Redactor
+}
+
+// NewQNameRedacting returns a new query log that redacts the domain names
+// using r before writing the entries into l.  If r is nil, l is returned as
+// is.  l must not be nil.
+func NewQNameRedacting(l Interface, r *qnameredact.Redactor) (res Interface) {
+	if r == nil {
+		return l
+	}
+
+	return &QNameRedacting{
+		log:      l,
+		redactor: r,
+	}
+}
+
+// type check
+var _ Interface = (*QNameRedacting)(nil)
+
+// Write implements the [Interface] interface for *QNameRedacting.
+func (l *QNameRedacting) Write(ctx context.Context, e *Entry) (err error) {
+	e.DomainFQDN = l.redactor.Redact(e.DomainFQDN)
+
+	// Don't wrap the error, because it's informative enough as is.
+	return l.log.Write(ctx, e)
+}
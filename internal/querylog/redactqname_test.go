@@ -0,0 +1,36 @@
+package querylog_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/qnameredact"
+	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQNameRedacting_Write(t *testing.T) {
+	t.Parallel()
+
+	var got string
+	l := &agdtest.QueryLog{
+		OnWrite: func(_ context.Context, e *querylog.Entry) (err error) {
+			got = e.DomainFQDN
+
+			return nil
+		},
+	}
+
+	r := qnameredact.New([]*qnameredact.Rule{{
+		Action: qnameredact.ActionStrip,
+		Suffix: "com",
+	}})
+
+	e := testEntry()
+	err := querylog.NewQNameRedacting(l, r).Write(context.Background(), e)
+	require.NoError(t, err)
+
+	assert.Equal(t, "redacted.com.", got)
+}
@@ -0,0 +1,189 @@
+package quicadvisor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver"
+	"github.com/AdguardTeam/AdGuardDNS/internal/optslog"
+)
+
+// Config is the configuration structure for [Default].
+type Config struct {
+	// Logger is used to log the operation of the advisor.  It must not be nil.
+	Logger *slog.Logger
+
+	// Clock is used to get the current time.  It must not be nil.
+	Clock agdtime.Clock
+
+	// Metrics is used for the collection of the statistics of the advisor.  It
+	// must not be nil.
+	Metrics Metrics
+
+	// FailureWindow is the period within which FailureThreshold failed
+	// handshakes must happen for a network to be considered to block QUIC.  It
+	// must be positive.
+	FailureWindow time.Duration
+
+	// BlockDuration is the time for which the QUIC-based protocols aren't
+	// advertised to a network that has been considered to block QUIC.  It
+	// must be positive.
+	BlockDuration time.Duration
+
+	// CacheSize is the maximum number of client networks the state of which is
+	// kept.  It must be positive.
+	CacheSize int
+
+	// FailureThreshold is the number of the failed handshakes after which a
+	// network is considered to block QUIC.  It must be positive.
+	FailureThreshold uint
+
+	// IPv4SubnetKeyLen is the length of the subnet prefix used to group the
+	// IPv4 clients.  It must be in the [1, 32] range.
+	IPv4SubnetKeyLen int
+
+	// IPv6SubnetKeyLen is the length of the subnet prefix used to group the
+	// IPv6 clients.  It must be in the [1, 128] range.
+	IPv6SubnetKeyLen int
+}
+
+// Default is the default [dnsserver.QUICAdvisor] implementation.  It considers
+// a client network to block QUIC if there have been a number of failed QUIC
+// handshakes from the clients of that network within a period of time, and no
+// completed ones.  It is safe for concurrent use.
+type Default struct {
+	logger  *slog.Logger
+	clock   agdtime.Clock
+	metrics Metrics
+
+	// mu protects the values of networks.
+	mu *sync.Mutex
+
+	// networks contains the states of the client networks.
+	networks agdcache.Interface[netip.Prefix, *networkState]
+
+	failureWindow    time.Duration
+	blockDuration    time.Duration
+	failureThreshold uint
+	ipv4SubnetKeyLen int
+	ipv6SubnetKeyLen int
+}
+
+// networkState is the state of a client network.
+type networkState struct {
+	// firstFailure is the time of the first failed handshake in the current
+	// failure window.
+	firstFailure time.Time
+
+	// blockedUntil is the time until which QUIC is considered blocked in the
+	// network.
+	blockedUntil time.Time
+
+	// failures is the number of failed handshakes in the current failure
+	// window.
+	failures uint
+}
+
+// New returns a new properly initialized *Default.  c must be valid.
+func New(c *Config) (a *Default) {
+	return &Default{
+		logger:  c.Logger,
+		clock:   c.Clock,
+		metrics: c.Metrics,
+		mu:      &sync.Mutex{},
+		networks: agdcache.NewLRU[netip.Prefix, *networkState](&agdcache.LRUConfig{
+			Count: c.CacheSize,
+		}),
+		failureWindow:    c.FailureWindow,
+		blockDuration:    c.BlockDuration,
+		failureThreshold: c.FailureThreshold,
+		ipv4SubnetKeyLen: c.IPv4SubnetKeyLen,
+		ipv6SubnetKeyLen: c.IPv6SubnetKeyLen,
+	}
+}
+
+// type check
+var _ dnsserver.QUICAdvisor = (*Default)(nil)
+
+// OnQUICHandshake implements the [dnsserver.QUICAdvisor] interface for
+// *Default.
+func (a *Default) OnQUICHandshake(ctx context.Context, addr netip.Addr, ok bool) {
+	a.metrics.OnHandshake(ctx, ok)
+
+	subnet := a.subnet(addr)
+	now := a.clock.Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	st, has := a.networks.Get(subnet)
+	if ok {
+		if has {
+			// A completed handshake shows that QUIC is not blocked at least
+			// for some of the clients, so start counting anew.  Don't lift
+			// the existing block, since the network may be mixed.
+			st.failures = 0
+		}
+
+		return
+	}
+
+	if !has {
+		st = &networkState{}
+		a.networks.Set(subnet, st)
+	}
+
+	if now.Sub(st.firstFailure) > a.failureWindow {
+		st.firstFailure = now
+		st.failures = 0
+	}
+
+	st.failures++
+	if st.failures < a.failureThreshold {
+		return
+	}
+
+	st.failures = 0
+	st.blockedUntil = now.Add(a.blockDuration)
+
+	a.metrics.IncrementBlocked(ctx)
+	optslog.Debug1(ctx, a.logger, "quic considered blocked", "subnet", subnet)
+}
+
+// IsQUICBlocked implements the [dnsserver.QUICAdvisor] interface for *Default.
+func (a *Default) IsQUICBlocked(_ context.Context, addr netip.Addr) (blocked bool) {
+	subnet := a.subnet(addr)
+	now := a.clock.Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	st, ok := a.networks.Get(subnet)
+
+	return ok && now.Before(st.blockedUntil)
+}
+
+// subnet returns the client network of addr.  addr must be valid.
+func (a *Default) subnet(addr netip.Addr) (subnet netip.Prefix) {
+	addr = addr.Unmap()
+
+	var err error
+	if addr.Is4() {
+		subnet, err = addr.Prefix(a.ipv4SubnetKeyLen)
+	} else {
+		subnet, err = addr.Prefix(a.ipv6SubnetKeyLen)
+	}
+
+	if err != nil {
+		// Technically shouldn't happen, since addr is required to be valid.
+		panic(fmt.Errorf("quicadvisor: getting subnet: %w", err))
+	}
+
+	return subnet
+}
@@ -0,0 +1,110 @@
+package quicadvisor_test
+
+import (
+	"context"
+	"net/netip"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/quicadvisor"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// Common values for tests.
+var (
+	testAddr      = netip.MustParseAddr("192.0.2.1")
+	testOtherAddr = netip.MustParseAddr("192.0.2.2")
+	testFarAddr   = netip.MustParseAddr("198.51.100.1")
+)
+
+// Configuration values for tests.
+const (
+	testFailureWindow    = 1 * time.Minute
+	testBlockDuration    = 10 * time.Minute
+	testFailureThreshold = 3
+)
+
+// newTestDefault returns a new *quicadvisor.Default for tests that uses the
+// time from now.
+func newTestDefault(now *atomic.Pointer[time.Time]) (a *quicadvisor.Default) {
+	return quicadvisor.New(&quicadvisor.Config{
+		Logger: slogutil.NewDiscardLogger(),
+		Clock: &agdtest.Clock{
+			OnNow: func() (n time.Time) { return *now.Load() },
+		},
+		Metrics:          quicadvisor.EmptyMetrics{},
+		FailureWindow:    testFailureWindow,
+		BlockDuration:    testBlockDuration,
+		CacheSize:        100,
+		FailureThreshold: testFailureThreshold,
+		IPv4SubnetKeyLen: 24,
+		IPv6SubnetKeyLen: 56,
+	})
+}
+
+func TestDefault(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("blocked", func(t *testing.T) {
+		t.Parallel()
+
+		now := &atomic.Pointer[time.Time]{}
+		start := time.Unix(1_000, 0)
+		now.Store(&start)
+
+		a := newTestDefault(now)
+
+		a.OnQUICHandshake(ctx, testAddr, false)
+		a.OnQUICHandshake(ctx, testOtherAddr, false)
+		assert.False(t, a.IsQUICBlocked(ctx, testAddr))
+
+		a.OnQUICHandshake(ctx, testAddr, false)
+		assert.True(t, a.IsQUICBlocked(ctx, testAddr))
+		assert.True(t, a.IsQUICBlocked(ctx, testOtherAddr))
+		assert.False(t, a.IsQUICBlocked(ctx, testFarAddr))
+
+		end := start.Add(testBlockDuration)
+		now.Store(&end)
+		assert.False(t, a.IsQUICBlocked(ctx, testAddr))
+	})
+
+	t.Run("window", func(t *testing.T) {
+		t.Parallel()
+
+		now := &atomic.Pointer[time.Time]{}
+		start := time.Unix(1_000, 0)
+		now.Store(&start)
+
+		a := newTestDefault(now)
+
+		a.OnQUICHandshake(ctx, testAddr, false)
+		a.OnQUICHandshake(ctx, testAddr, false)
+
+		later := start.Add(testFailureWindow + time.Second)
+		now.Store(&later)
+
+		a.OnQUICHandshake(ctx, testAddr, false)
+		assert.False(t, a.IsQUICBlocked(ctx, testAddr))
+	})
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		now := &atomic.Pointer[time.Time]{}
+		start := time.Unix(1_000, 0)
+		now.Store(&start)
+
+		a := newTestDefault(now)
+
+		a.OnQUICHandshake(ctx, testAddr, false)
+		a.OnQUICHandshake(ctx, testAddr, false)
+		a.OnQUICHandshake(ctx, testOtherAddr, true)
+		a.OnQUICHandshake(ctx, testAddr, false)
+		assert.False(t, a.IsQUICBlocked(ctx, testAddr))
+	})
+}
@@ -0,0 +1,34 @@
+// Package quicadvisor contains the detection of the client networks in which
+// the QUIC-based protocols don't work, for example because UDP port 443 is
+// blocked, so that the clients from these networks are directed to the
+// TCP-based protocols.
+package quicadvisor
+
+import (
+	"context"
+)
+
+// Metrics is an interface for collection of the statistics of the QUIC
+// advisor.
+type Metrics interface {
+	// OnHandshake records the outcome of a QUIC handshake.  ok is true if the
+	// handshake has been completed.
+	OnHandshake(ctx context.Context, ok bool)
+
+	// IncrementBlocked increments the number of times a client network has
+	// been considered to block QUIC.
+	IncrementBlocked(ctx context.Context)
+}
+
+// EmptyMetrics is an implementation of the [Metrics] interface that does
+// nothing.
+type EmptyMetrics struct{}
+
+// type check
+var _ Metrics = EmptyMetrics{}
+
+// OnHandshake implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) OnHandshake(_ context.Context, _ bool) {}
+
+// IncrementBlocked implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) IncrementBlocked(_ context.Context) {}
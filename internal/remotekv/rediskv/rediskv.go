@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/remotekv"
+	"github.com/AdguardTeam/AdGuardDNS/internal/sharedcounter"
 	"github.com/AdguardTeam/golibs/errors"
 	"github.com/AdguardTeam/golibs/netutil"
 	"github.com/gomodule/redigo/redis"
@@ -126,9 +127,11 @@ func checkConnRole(c redis.Conn, _ time.Time) (err error) {
 
 // Redis commands, parameters, and other constants.
 const (
-	redisCmdGET  = "GET"
-	redisCmdROLE = "ROLE"
-	redisCmdSET  = "SET"
+	redisCmdGET     = "GET"
+	redisCmdINCRBY  = "INCRBY"
+	redisCmdPEXPIRE = "PEXPIRE"
+	redisCmdROLE    = "ROLE"
+	redisCmdSET     = "SET"
 
 	redisParamMs = "PX"
 
@@ -186,3 +189,52 @@ func (kv *RedisKV) Set(ctx context.Context, key string, val []byte) (err error)
 
 	return nil
 }
+
+// type check
+var _ sharedcounter.Storage = (*RedisKV)(nil)
+
+// Increment implements the [sharedcounter.Storage] interface for *RedisKV.  It
+// adds delta to the integer value of key and returns the new value.
+// The expiration of the key is set to the TTL from the configuration.
+func (kv *RedisKV) Increment(ctx context.Context, key string, delta uint64) (val uint64, err error) {
+	defer func() { err = errors.Annotate(err, "incrementing %q: %w", key) }()
+
+	defer func() {
+		// #nosec G115 -- Assume that pool.ActiveCount is always non-negative.
+		kv.metrics.UpdateMetrics(ctx, uint(kv.pool.ActiveCount()), err == nil)
+	}()
+
+	c, err := kv.pool.GetContext(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("getting from pool: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, c.Close()) }()
+
+	// Pipeline the commands to only make one round trip.
+	err = c.Send(redisCmdINCRBY, key, delta)
+	if err != nil {
+		return 0, fmt.Errorf("sending incrby command: %w", err)
+	}
+
+	err = c.Send(redisCmdPEXPIRE, key, kv.ttl.Milliseconds())
+	if err != nil {
+		return 0, fmt.Errorf("sending pexpire command: %w", err)
+	}
+
+	err = c.Flush()
+	if err != nil {
+		return 0, fmt.Errorf("flushing: %w", err)
+	}
+
+	val, err = redis.Uint64(c.Receive())
+	if err != nil {
+		return 0, fmt.Errorf("incrby command: %w", err)
+	}
+
+	_, err = c.Receive()
+	if err != nil {
+		return 0, fmt.Errorf("pexpire command: %w", err)
+	}
+
+	return val, nil
+}
@@ -0,0 +1,202 @@
+package reqlog
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+)
+
+// Subnet lengths to which the client IP addresses are truncated in the log.
+const (
+	SubnetLenIPv4 = 24
+	SubnetLenIPv6 = 56
+)
+
+// Verdicts of filtering as written into the log.
+const (
+	VerdictNone     = "none"
+	VerdictAllowed  = "allowed"
+	VerdictBlocked  = "blocked"
+	VerdictModified = "modified"
+)
+
+// DefaultConfig is the configuration structure for [Default].
+type DefaultConfig struct {
+	// Logger is used to write the entries.  It must not be nil.
+	Logger *slog.Logger
+
+	// Clock is used to check the expiration of the targets.  It must not be
+	// nil.
+	Clock agdtime.Clock
+
+	// SampleRate is the share of the requests that are logged regardless of
+	// the targets.  It must be within the [0, 1] range.
+	SampleRate float64
+}
+
+// Targets are the profiles and devices, the requests of which are always
+// logged until the expiration time.
+type Targets struct {
+	// Expires is the time after which the targets are no longer used.
+	Expires time.Time
+
+	// ProfileIDs are the IDs of the profiles, all requests of which are
+	// logged.
+	ProfileIDs []agd.ProfileID
+
+	// DeviceIDs are the IDs of the devices, all requests of which are logged.
+	DeviceIDs []agd.DeviceID
+}
+
+// Default is the default [Interface] implementation that writes the entries
+// into a structured logger.  The requests are sampled at a fixed rate, and
+// the requests of the targeted profiles and devices are always logged.
+type Default struct {
+	logger *slog.Logger
+	clock  agdtime.Clock
+
+	// targetsMu protects targets.
+	targetsMu *sync.RWMutex
+
+	// targets are the current targets.  It is nil if there are none.
+	targets *Targets
+
+	sampleRate float64
+}
+
+// NewDefault returns a new properly initialized *Default.  c must be valid.
+func NewDefault(c *DefaultConfig) (d *Default) {
+	return &Default{
+		logger:     c.Logger,
+		clock:      c.Clock,
+		sampleRate: c.SampleRate,
+		targetsMu:  &sync.RWMutex{},
+	}
+}
+
+// SetTargets sets the targets of the log.  If t is nil, the targets are
+// removed.  t must not be modified after calling SetTargets.
+func (d *Default) SetTargets(t *Targets) {
+	d.targetsMu.Lock()
+	defer d.targetsMu.Unlock()
+
+	d.targets = t
+}
+
+// Targets returns the current targets, if any.  Expired targets are not
+// returned.  t must not be modified.
+func (d *Default) Targets() (t *Targets) {
+	d.targetsMu.RLock()
+	defer d.targetsMu.RUnlock()
+
+	if d.targets == nil || !d.clock.Now().Before(d.targets.Expires) {
+		return nil
+	}
+
+	return d.targets
+}
+
+// type check
+var _ Interface = (*Default)(nil)
+
+// ShouldLog implements the [Interface] interface for *Default.
+func (d *Default) ShouldLog(_ context.Context, ri *agd.RequestInfo) (ok bool) {
+	if d.isTarget(ri) {
+		return true
+	}
+
+	return d.sampleRate > 0 && rand.Float64() < d.sampleRate
+}
+
+// isTarget returns true if the device or the profile of ri is targeted.
+func (d *Default) isTarget(ri *agd.RequestInfo) (ok bool) {
+	p, dev := ri.DeviceData()
+	if p == nil {
+		return false
+	}
+
+	t := d.Targets()
+	if t == nil {
+		return false
+	}
+
+	return slices.Contains(t.ProfileIDs, p.ID) || slices.Contains(t.DeviceIDs, dev.ID)
+}
+
+// Log implements the [Interface] interface for *Default.
+func (d *Default) Log(ctx context.Context, e *Entry) {
+	res := e.RequestResult
+	if res == nil {
+		res = e.ResponseResult
+	}
+
+	verdict, fltID, rule := resultData(res)
+
+	attrs := []slog.Attr{
+		slog.String("req_id", e.RequestID.String()),
+		slog.String("client_subnet", truncate(e.RemoteIP).String()),
+		slog.String("proto", e.Protocol.String()),
+		slog.String("profile_id", string(e.ProfileID)),
+		slog.String("device_id", string(e.DeviceID)),
+		slog.String("host", e.Host),
+		slog.Int("qtype", int(e.QType)),
+		slog.Int("rcode", int(e.RCode)),
+		slog.String("verdict", verdict),
+		slog.String("filter_list_id", string(fltID)),
+		slog.String("rule", string(rule)),
+		slog.String("upstream", e.Upstream),
+		slog.Duration("filtering_duration", e.FilteringDuration),
+		slog.Duration("upstream_duration", e.UpstreamDuration),
+		slog.Duration("elapsed", e.Elapsed),
+	}
+
+	if e.Error != nil {
+		attrs = append(attrs, slog.Any(slogutil.KeyError, e.Error))
+	}
+
+	d.logger.LogAttrs(ctx, slog.LevelInfo, "request", attrs...)
+}
+
+// truncate returns the subnet of ip that is safe to write into the log.
+func truncate(ip netip.Addr) (subnet netip.Prefix) {
+	if !ip.IsValid() {
+		return netip.Prefix{}
+	}
+
+	ip = ip.Unmap()
+	bits := SubnetLenIPv6
+	if ip.Is4() {
+		bits = SubnetLenIPv4
+	}
+
+	// Don't check the error, since the length is always valid.
+	subnet, _ = ip.Prefix(bits)
+
+	return subnet
+}
+
+// resultData returns the verdict and the matched rule of res.
+func resultData(res filter.Result) (verdict string, id filter.ID, rule filter.RuleText) {
+	if res == nil {
+		return VerdictNone, filter.IDNone, ""
+	}
+
+	id, rule = res.MatchedRule()
+	switch res.(type) {
+	case *filter.ResultAllowed:
+		return VerdictAllowed, id, rule
+	case *filter.ResultBlocked:
+		return VerdictBlocked, id, rule
+	default:
+		return VerdictModified, id, rule
+	}
+}
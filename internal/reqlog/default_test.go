@@ -0,0 +1,131 @@
+package reqlog_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/reqlog"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+// Common IDs for tests.
+const (
+	testProfileID agd.ProfileID = "prof1234"
+	testDeviceID  agd.DeviceID  = "dev1234"
+)
+
+func TestDefault_ShouldLog(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	clock := &agdtest.Clock{
+		OnNow: func() (n time.Time) { return now },
+	}
+
+	l := reqlog.NewDefault(&reqlog.DefaultConfig{
+		Logger:     slogutil.NewDiscardLogger(),
+		Clock:      clock,
+		SampleRate: 0,
+	})
+
+	newRI := func(profID agd.ProfileID, devID agd.DeviceID) (ri *agd.RequestInfo) {
+		return &agd.RequestInfo{
+			DeviceResult: &agd.DeviceResultOK{
+				Device:  &agd.Device{ID: devID},
+				Profile: &agd.Profile{ID: profID},
+			},
+		}
+	}
+
+	var (
+		riAnon    = &agd.RequestInfo{}
+		riProf    = newRI(testProfileID, "dev5678")
+		riDev     = newRI("prof5678", testDeviceID)
+		riOther   = newRI("prof5678", "dev5678")
+		ctx       = context.Background()
+		expiresAt = now.Add(1 * time.Minute)
+	)
+
+	assert.False(t, l.ShouldLog(ctx, riProf))
+	assert.Nil(t, l.Targets())
+
+	l.SetTargets(&reqlog.Targets{
+		Expires:    expiresAt,
+		ProfileIDs: []agd.ProfileID{testProfileID},
+		DeviceIDs:  []agd.DeviceID{testDeviceID},
+	})
+
+	assert.NotNil(t, l.Targets())
+	assert.True(t, l.ShouldLog(ctx, riProf))
+	assert.True(t, l.ShouldLog(ctx, riDev))
+	assert.False(t, l.ShouldLog(ctx, riOther))
+	assert.False(t, l.ShouldLog(ctx, riAnon))
+
+	now = expiresAt
+	assert.Nil(t, l.Targets())
+	assert.False(t, l.ShouldLog(ctx, riProf))
+
+	sampled := reqlog.NewDefault(&reqlog.DefaultConfig{
+		Logger:     slogutil.NewDiscardLogger(),
+		Clock:      clock,
+		SampleRate: 1,
+	})
+
+	assert.True(t, sampled.ShouldLog(ctx, riAnon))
+}
+
+func TestDefault_Log(t *testing.T) {
+	t.Parallel()
+
+	buf := &bytes.Buffer{}
+	l := reqlog.NewDefault(&reqlog.DefaultConfig{
+		Logger: slog.New(slog.NewTextHandler(buf, nil)),
+		Clock: &agdtest.Clock{
+			OnNow: time.Now,
+		},
+	})
+
+	l.Log(context.Background(), &reqlog.Entry{
+		RequestResult: &filter.ResultBlocked{
+			List: "test_list",
+			Rule: "||example.com^",
+		},
+		ProfileID: testProfileID,
+		DeviceID:  testDeviceID,
+		Host:      "example.com",
+		Upstream:  "1.1.1.1:53",
+		RemoteIP:  netip.MustParseAddr("192.0.2.123"),
+		QType:     dns.TypeA,
+		RCode:     dns.RcodeSuccess,
+		Protocol:  agd.ProtoDoT,
+	})
+
+	out := buf.String()
+	assert.Contains(t, out, "client_subnet=192.0.2.0/24")
+	assert.NotContains(t, out, "192.0.2.123")
+	assert.Contains(t, out, "proto=dot")
+	assert.Contains(t, out, "profile_id="+string(testProfileID))
+	assert.Contains(t, out, "device_id="+string(testDeviceID))
+	assert.Contains(t, out, "verdict="+reqlog.VerdictBlocked)
+	assert.Contains(t, out, "filter_list_id=test_list")
+	assert.Contains(t, out, "upstream=1.1.1.1:53")
+
+	buf.Reset()
+	l.Log(context.Background(), &reqlog.Entry{
+		RemoteIP: netip.MustParseAddr("2001:db8:1:2:3::1"),
+		Protocol: agd.ProtoDNS,
+	})
+
+	out = buf.String()
+	assert.Contains(t, out, "client_subnet=2001:db8:1::/56")
+	assert.Contains(t, out, "verdict="+reqlog.VerdictNone)
+}
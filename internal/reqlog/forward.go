@@ -0,0 +1,45 @@
+package reqlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/forward"
+	"github.com/miekg/dns"
+)
+
+// ForwardMetricsListener is a [forward.MetricsListener] that records the
+// upstream data into the [Trace] of the logged requests and passes all events
+// to the wrapped listener.
+type ForwardMetricsListener struct {
+	forward.MetricsListener
+}
+
+// NewForwardMetricsListener returns a new properly initialized
+// *ForwardMetricsListener.  l must not be nil.
+func NewForwardMetricsListener(l forward.MetricsListener) (ml *ForwardMetricsListener) {
+	return &ForwardMetricsListener{
+		MetricsListener: l,
+	}
+}
+
+// type check
+var _ forward.MetricsListener = (*ForwardMetricsListener)(nil)
+
+// OnForwardRequest implements the [forward.MetricsListener] interface for
+// *ForwardMetricsListener.
+func (ml *ForwardMetricsListener) OnForwardRequest(
+	ctx context.Context,
+	ups forward.Upstream,
+	req, resp *dns.Msg,
+	nw forward.Network,
+	startTime time.Time,
+	err error,
+) {
+	if t, ok := TraceFromContext(ctx); ok {
+		t.Upstream = ups.String()
+		t.UpstreamDuration = time.Since(startTime)
+	}
+
+	ml.MetricsListener.OnForwardRequest(ctx, ups, req, resp, nw, startTime, err)
+}
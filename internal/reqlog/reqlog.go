@@ -0,0 +1,121 @@
+// Package reqlog contains the structured per-request debug log of AdGuard DNS.
+// Unlike the query log, it's not limited to the profiles with query logging
+// enabled and is intended for reproducing the issues of particular clients in
+// production.
+package reqlog
+
+import (
+	"context"
+	"net/netip"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+)
+
+// Interface is the interface for the structured per-request debug logs.
+type Interface interface {
+	// ShouldLog returns true if the request described by ri should be logged.
+	// ri must not be nil.
+	ShouldLog(ctx context.Context, ri *agd.RequestInfo) (ok bool)
+
+	// Log writes e into the log.  e must not be nil.
+	Log(ctx context.Context, e *Entry)
+}
+
+// Empty is an [Interface] implementation that does nothing.
+type Empty struct{}
+
+// type check
+var _ Interface = Empty{}
+
+// ShouldLog implements the [Interface] interface for Empty.  It always
+// returns false.
+func (Empty) ShouldLog(_ context.Context, _ *agd.RequestInfo) (ok bool) { return false }
+
+// Log implements the [Interface] interface for Empty.
+func (Empty) Log(_ context.Context, _ *Entry) {}
+
+// Entry is a single entry of the per-request debug log.
+type Entry struct {
+	// RequestResult is the result of filtering the request, if any.
+	RequestResult filter.Result
+
+	// ResponseResult is the result of filtering the response, if any.
+	ResponseResult filter.Result
+
+	// Error is the error that has happened while processing the request, if
+	// any.
+	Error error
+
+	// ProfileID is the ID of the profile, if any.
+	ProfileID agd.ProfileID
+
+	// DeviceID is the ID of the device, if any.
+	DeviceID agd.DeviceID
+
+	// Host is the lowercased, non-FQDN version of the requested hostname.
+	Host string
+
+	// RemoteIP is the IP address of the client.  It is truncated to a subnet
+	// before being logged.
+	RemoteIP netip.Addr
+
+	// Upstream is the upstream to which the request has been forwarded, if
+	// any.
+	Upstream string
+
+	// FilteringDuration is the duration of filtering the request.
+	FilteringDuration time.Duration
+
+	// UpstreamDuration is the duration of the upstream exchange, if any.
+	UpstreamDuration time.Duration
+
+	// Elapsed is the total duration of processing the request.
+	Elapsed time.Duration
+
+	// RequestID is the ID of the request.
+	RequestID agd.RequestID
+
+	// QType is the type of the question of the request.
+	QType dnsmsg.RRType
+
+	// RCode is the response code of the response, if any.
+	RCode dnsmsg.RCode
+
+	// Protocol is the protocol by which the request has been made.
+	Protocol agd.Protocol
+}
+
+// Trace contains the data about a logged request that is collected by the
+// handlers further down the chain.  It must only be accessed by the goroutine
+// that serves the request.
+type Trace struct {
+	// Upstream is the upstream to which the request has been forwarded, if
+	// any.
+	Upstream string
+
+	// UpstreamDuration is the duration of the upstream exchange, if any.
+	UpstreamDuration time.Duration
+}
+
+// ctxKey is the type for context keys within this package.
+type ctxKey uint8
+
+// ctxKeyTrace is the context key for [*Trace].
+const ctxKeyTrace ctxKey = 0
+
+// ContextWithTrace returns a new context with the given trace.  t must not be
+// nil.
+func ContextWithTrace(parent context.Context, t *Trace) (ctx context.Context) {
+	return context.WithValue(parent, ctxKeyTrace, t)
+}
+
+// TraceFromContext returns the trace for the request from the context, if
+// there is one, that is, if the request should be logged.
+func TraceFromContext(ctx context.Context) (t *Trace, ok bool) {
+	t, ok = ctx.Value(ctxKeyTrace).(*Trace)
+
+	return t, ok
+}
@@ -11,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
@@ -30,17 +31,31 @@ const statFilterListLegacyID filter.ID = "15"
 // HTTP is the filtering rule statistics collector that uploads the statistics
 // to the given URL when it's refreshed.
 //
-// TODO(a.garipov): Add tests.
+// Each refresh closes the current aggregation window and uploads it along with
+// the windows that previously failed to upload.  Each window has a unique key,
+// which the backend can use to deduplicate the windows that are uploaded more
+// than once, for example if the node restarts before the successful upload is
+// recorded.
 type HTTP struct {
-	logger  *slog.Logger
-	url     *url.URL
-	http    *agdhttp.Client
-	errColl errcoll.Interface
+	logger    *slog.Logger
+	url       *url.URL
+	http      *agdhttp.Client
+	errColl   errcoll.Interface
+	spoolPath string
 
 	// mu protects stats and recordedHits.
-	mu           *sync.Mutex
-	stats        statsSet
+	mu *sync.Mutex
+
+	stats statsSet
+
+	// uploadMu protects pending, spoolLoaded, and the spool file.
+	uploadMu *sync.Mutex
+
+	pending      []*window
 	recordedHits int64
+	spoolLoaded  bool
+
+	maxPending int
 }
 
 // statsSet is an alias for the stats set type.
@@ -57,6 +72,17 @@ type HTTPConfig struct {
 
 	// URL is the URL to which the statistics is uploaded.
 	URL *url.URL
+
+	// SpoolPath is the path to the append-only file to which the closed
+	// aggregation windows and their successful uploads are recorded, so that
+	// the windows that haven't been uploaded survive restarts.  If it is
+	// empty, such windows are only kept in memory.
+	SpoolPath string
+
+	// MaxPendingWindows is the maximum number of the aggregation windows that
+	// haven't been uploaded yet.  When it's exceeded, the oldest windows are
+	// dropped.  It must be positive.
+	MaxPendingWindows int
 }
 
 // NewHTTP returns a new statistics collector with HTTP upload.
@@ -68,10 +94,14 @@ func NewHTTP(c *HTTPConfig) (s *HTTP) {
 			// TODO(ameshkov): Consider making configurable.
 			Timeout: 30 * time.Second,
 		}),
-		errColl: c.ErrColl,
+		errColl:   c.ErrColl,
+		spoolPath: c.SpoolPath,
 
 		mu:    &sync.Mutex{},
 		stats: statsSet{},
+
+		uploadMu:   &sync.Mutex{},
+		maxPending: c.MaxPendingWindows,
 	}
 }
 
@@ -107,9 +137,9 @@ func (s *HTTP) Collect(_ context.Context, id filter.ID, text filter.RuleText) {
 // type check
 var _ agdservice.Refresher = (*HTTP)(nil)
 
-// Refresh implements the [agdservice.Refresher] interface for *HTTP.  It
-// uploads the collected statistics to s.u and starts collecting a new set of
-// statistics.
+// Refresh implements the [agdservice.Refresher] interface for *HTTP.  It closes
+// the current aggregation window, starts collecting a new one, and uploads all
+// windows that haven't been uploaded yet to s.url.
 func (s *HTTP) Refresh(ctx context.Context) (err error) {
 	s.logger.InfoContext(ctx, "refresh started")
 	defer s.logger.InfoContext(ctx, "refresh finished")
@@ -128,11 +158,73 @@ func (s *HTTP) Refresh(ctx context.Context) (err error) {
 	return nil
 }
 
-// refresh uploads the collected statistics and resets the collected stats.
+// refresh closes the current window and uploads the pending windows, oldest
+// first.  It stops at the first failed upload, keeping the failed window and
+// the ones after it pending.
 func (s *HTTP) refresh(ctx context.Context) (err error) {
-	stats := s.replaceStats()
+	s.uploadMu.Lock()
+	defer s.uploadMu.Unlock()
+
+	if !s.spoolLoaded {
+		s.pending = append(s.loadSpool(ctx), s.pending...)
+		s.spoolLoaded = true
+	}
+
+	w := &window{
+		Key:     agd.NewRequestID().String(),
+		Filters: s.replaceStats(),
+	}
+	s.appendSpool(ctx, w)
+	s.pending = append(s.pending, w)
+
+	dropped := s.dropOldest(ctx)
+	defer func() { metrics.RuleStatPendingWindows.Set(float64(len(s.pending))) }()
+
+	uploaded := 0
+	for _, pw := range s.pending {
+		err = s.upload(ctx, pw)
+		if err != nil {
+			break
+		}
+
+		uploaded++
+		s.appendSpool(ctx, &window{
+			Key:      pw.Key,
+			Uploaded: true,
+		})
+	}
+
+	s.pending = s.pending[uploaded:]
+	if uploaded > 0 || dropped {
+		s.compactSpool(ctx)
+	}
+
+	return err
+}
+
+// dropOldest removes the oldest pending windows exceeding the limit.  dropped
+// is true if any windows have been removed.
+func (s *HTTP) dropOldest(ctx context.Context) (dropped bool) {
+	n := len(s.pending) - s.maxPending
+	if n <= 0 {
+		return false
+	}
+
+	s.logger.WarnContext(ctx, "dropping oldest pending windows", "num", n)
+	s.pending = s.pending[n:]
+
+	return true
+}
+
+// upload sends the statistics of w to s.url.
+func (s *HTTP) upload(ctx context.Context, w *window) (err error) {
 	req := &filtersReq{
-		Filters: stats,
+		Key:     w.Key,
+		Filters: w.Filters,
+	}
+	if req.Filters == nil {
+		// Windows without hits are spooled without filters.
+		req.Filters = statsSet{}
 	}
 
 	b, err := json.Marshal(req)
@@ -165,4 +257,8 @@ func (s *HTTP) replaceStats() (prev statsSet) {
 // filtersReq is the JSON filtering rule list statistics request structure.
 type filtersReq struct {
 	Filters statsSet `json:"filters"`
+
+	// Key is the unique key of the aggregation window, which the backend can
+	// use to deduplicate the repeated uploads of the same window.
+	Key string `json:"key"`
 }
@@ -3,10 +3,13 @@ package rulestat_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
@@ -19,6 +22,15 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// testMaxPendingWindows is the maximum number of pending windows for tests.
+const testMaxPendingWindows = 10
+
+// testFiltersReq is the structure of the upload request for tests.
+type testFiltersReq struct {
+	Key     string          `json:"key"`
+	Filters json.RawMessage `json:"filters"`
+}
+
 // handleWithURL starts the test server with h, finishes it on cleanup, and
 // returns it's URL.
 func handleWithURL(t *testing.T, h http.Handler) (u *url.URL) {
@@ -42,9 +54,10 @@ func TestHTTP_Collect(t *testing.T) {
 		rw.WriteHeader(http.StatusOK)
 	}))
 	conf := &rulestat.HTTPConfig{
-		ErrColl: agdtest.NewErrorCollector(),
-		Logger:  slogutil.NewDiscardLogger(),
-		URL:     u,
+		ErrColl:           agdtest.NewErrorCollector(),
+		Logger:            slogutil.NewDiscardLogger(),
+		URL:               u,
+		MaxPendingWindows: testMaxPendingWindows,
 	}
 
 	testCases := []struct {
@@ -53,15 +66,15 @@ func TestHTTP_Collect(t *testing.T) {
 		rules []filter.RuleText
 	}{{
 		name:  "single",
-		want:  `{"filters":{"15":{"||example.org^":1}}}`,
+		want:  `{"15":{"||example.org^":1}}`,
 		rules: []filter.RuleText{"||example.org^"},
 	}, {
 		name:  "several_alike",
-		want:  `{"filters":{"15":{"||example.org^":3}}}`,
+		want:  `{"15":{"||example.org^":3}}`,
 		rules: []filter.RuleText{"||example.org^", "||example.org^", "||example.org^"},
 	}, {
 		name:  "several_different",
-		want:  `{"filters":{"15":{"||example.org^":1, "||example.com^":1, "||пример.рф^":1}}}`,
+		want:  `{"15":{"||example.org^":1, "||example.com^":1, "||пример.рф^":1}}`,
 		rules: []filter.RuleText{"||example.org^", "||example.com^", "||пример.рф^"},
 	}}
 
@@ -79,7 +92,12 @@ func TestHTTP_Collect(t *testing.T) {
 			err := h.Refresh(testutil.ContextWithTimeout(t, testTimeout))
 			require.NoError(t, err)
 
-			assert.JSONEq(t, tc.want, b.String())
+			req := &testFiltersReq{}
+			err = json.Unmarshal(b.Bytes(), req)
+			require.NoError(t, err)
+
+			assert.JSONEq(t, tc.want, string(req.Filters))
+			assert.NotEmpty(t, req.Key)
 		})
 	}
 }
@@ -100,6 +118,7 @@ func TestHTTP_Refresh_errors(t *testing.T) {
 				Scheme: "badscheme",
 				Host:   "0.0.0.0",
 			},
+			MaxPendingWindows: testMaxPendingWindows,
 		})
 
 		err := h.Refresh(testutil.ContextWithTimeout(t, testTimeout))
@@ -117,7 +136,8 @@ func TestHTTP_Refresh_errors(t *testing.T) {
 					require.NotNil(t, err)
 				},
 			},
-			URL: u,
+			URL:               u,
+			MaxPendingWindows: testMaxPendingWindows,
 		})
 
 		var serr *agdhttp.StatusError
@@ -127,3 +147,68 @@ func TestHTTP_Refresh_errors(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, serr.Got)
 	})
 }
+
+func TestHTTP_Refresh_spool(t *testing.T) {
+	var (
+		fail atomic.Bool
+		reqs []*testFiltersReq
+	)
+
+	u := handleWithURL(t, http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		pt := testutil.PanicT{}
+
+		req := &testFiltersReq{}
+		err := json.NewDecoder(r.Body).Decode(req)
+		require.NoError(pt, err)
+
+		if fail.Load() {
+			rw.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		reqs = append(reqs, req)
+		rw.WriteHeader(http.StatusOK)
+	}))
+
+	spoolPath := filepath.Join(t.TempDir(), "rulestat.jsonl")
+	conf := &rulestat.HTTPConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		ErrColl: &agdtest.ErrorCollector{
+			OnCollect: func(_ context.Context, err error) {
+				require.Error(t, err)
+			},
+		},
+		URL:               u,
+		SpoolPath:         spoolPath,
+		MaxPendingWindows: testMaxPendingWindows,
+	}
+
+	fail.Store(true)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	h := rulestat.NewHTTP(conf)
+	h.Collect(ctx, filter.IDAdGuardDNS, "||example.org^")
+
+	err := h.Refresh(ctx)
+	require.Error(t, err)
+
+	require.FileExists(t, spoolPath)
+
+	// Imitate a restart.
+	fail.Store(false)
+
+	h = rulestat.NewHTTP(conf)
+	h.Collect(ctx, filter.IDAdGuardDNS, "||example.com^")
+
+	err = h.Refresh(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, reqs, 2)
+
+	assert.JSONEq(t, `{"15":{"||example.org^":1}}`, string(reqs[0].Filters))
+	assert.JSONEq(t, `{"15":{"||example.com^":1}}`, string(reqs[1].Filters))
+	assert.NotEqual(t, reqs[0].Key, reqs[1].Key)
+
+	assert.NoFileExists(t, spoolPath)
+}
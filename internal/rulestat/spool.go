@@ -0,0 +1,153 @@
+package rulestat
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/golibs/errors"
+	renameio "github.com/google/renameio/v2"
+)
+
+// window is a closed aggregation window of the statistics.  It is also the
+// structure of a line of the spool file.
+type window struct {
+	// Filters are the statistics collected during the window.
+	Filters statsSet `json:"filters,omitempty"`
+
+	// Key is the unique key of the window.
+	Key string `json:"key"`
+
+	// Uploaded is true if the line records the successful upload of the window
+	// with Key rather than the window itself.
+	Uploaded bool `json:"uploaded,omitempty"`
+}
+
+// loadSpool returns the windows from the spool file, if any, that haven't been
+// uploaded.  The lines that can't be decoded, for example the ones that have
+// been partially written before a crash, are skipped.
+func (s *HTTP) loadSpool(ctx context.Context) (windows []*window) {
+	if s.spoolPath == "" {
+		return nil
+	}
+
+	b, err := os.ReadFile(s.spoolPath)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			errcoll.Collect(ctx, s.errColl, s.logger, "reading rulestat spool", err)
+		}
+
+		return nil
+	}
+
+	uploaded := map[string]struct{}{}
+	invalid := 0
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	sc.Buffer(nil, len(b)+1)
+	for sc.Scan() {
+		w := &window{}
+		err = json.Unmarshal(sc.Bytes(), w)
+		if err != nil || w.Key == "" {
+			invalid++
+
+			continue
+		}
+
+		if w.Uploaded {
+			uploaded[w.Key] = struct{}{}
+		} else {
+			windows = append(windows, w)
+		}
+	}
+
+	if invalid > 0 {
+		s.logger.WarnContext(ctx, "skipped invalid spool lines", "num", invalid)
+	}
+
+	pending := windows[:0]
+	for _, w := range windows {
+		if _, ok := uploaded[w.Key]; !ok {
+			pending = append(pending, w)
+		}
+	}
+
+	s.logger.InfoContext(ctx, "loaded spooled windows", "num", len(pending))
+
+	return pending
+}
+
+// appendSpool appends w to the spool file and syncs it.  Errors are collected
+// and not returned, since w is kept in memory anyway.
+func (s *HTTP) appendSpool(ctx context.Context, w *window) {
+	if s.spoolPath == "" {
+		return
+	}
+
+	err := appendLine(s.spoolPath, w)
+	if err != nil {
+		errcoll.Collect(ctx, s.errColl, s.logger, "appending to rulestat spool", err)
+	}
+}
+
+// appendLine appends w to the file at path as a JSON line and syncs the file.
+func appendLine(path string, w *window) (err error) {
+	b, err := json.Marshal(w)
+	if err != nil {
+		return fmt.Errorf("encoding window: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, agd.DefaultPerm)
+	if err != nil {
+		return fmt.Errorf("opening spool: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+	_, err = f.Write(append(b, '\n'))
+	if err != nil {
+		return fmt.Errorf("writing spool: %w", err)
+	}
+
+	return f.Sync()
+}
+
+// compactSpool atomically replaces the spool file with the one containing only
+// the pending windows or removes it if there are none.
+func (s *HTTP) compactSpool(ctx context.Context) {
+	if s.spoolPath == "" {
+		return
+	}
+
+	var err error
+	if len(s.pending) == 0 {
+		err = os.Remove(s.spoolPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			err = nil
+		}
+	} else {
+		err = s.writePending()
+	}
+
+	if err != nil {
+		errcoll.Collect(ctx, s.errColl, s.logger, "compacting rulestat spool", err)
+	}
+}
+
+// writePending atomically writes the pending windows into the spool file.
+func (s *HTTP) writePending() (err error) {
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	for _, w := range s.pending {
+		err = enc.Encode(w)
+		if err != nil {
+			return fmt.Errorf("encoding window: %w", err)
+		}
+	}
+
+	return renameio.WriteFile(s.spoolPath, buf.Bytes(), agd.DefaultPerm)
+}
@@ -0,0 +1,196 @@
+package sharedcounter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// DefaultConfig is the configuration structure for a *Default.  All fields
+// must not be empty.
+type DefaultConfig struct {
+	// Logger is used to log the synchronization of the counters.
+	Logger *slog.Logger
+
+	// Clock is used to get the current second.
+	Clock agdtime.Clock
+
+	// ErrColl is used to collect the synchronization errors.
+	ErrColl errcoll.Interface
+
+	// Storage is the remote storage of the counters shared by all nodes.
+	Storage Storage
+
+	// KeyPrefix is the prefix of the keys of the counters in Storage.  The
+	// keys have the following format:
+	//
+	//	<prefix><profile ID>:<unix time in seconds>
+	//	<prefix>account:<account ID>:<unix time in seconds>
+	KeyPrefix string
+}
+
+// Default is the default [Interface] implementation.  It counts the requests
+// locally and only sends the accumulated counts to the storage when Refresh is
+// called, so the error of the returned totals is bounded by the number of
+// requests received by the other nodes between two refreshes.
+type Default struct {
+	logger  *slog.Logger
+	clock   agdtime.Clock
+	errColl errcoll.Interface
+	storage Storage
+
+	// mu protects counters.
+	mu       *sync.Mutex
+	counters map[string]*counter
+
+	keyPrefix string
+}
+
+// accountCounterPrefix is the prefix of the names of the per-account counters.
+// It cannot clash with the profile IDs, since those cannot contain colons.
+const accountCounterPrefix = "account:"
+
+// counter is the state of a counter of a single profile or account.
+type counter struct {
+	// sec is the Unix time in seconds of the second being counted.
+	sec int64
+
+	// local is the number of requests that haven't been sent to the storage
+	// yet.
+	local uint64
+
+	// shared is the latest known total from the storage, which includes all
+	// the requests sent from this node.
+	shared uint64
+}
+
+// NewDefault returns a new properly initialized *Default.  c must be valid.
+func NewDefault(c *DefaultConfig) (d *Default) {
+	return &Default{
+		logger:    c.Logger,
+		clock:     c.Clock,
+		errColl:   c.ErrColl,
+		storage:   c.Storage,
+		keyPrefix: c.KeyPrefix,
+		mu:        &sync.Mutex{},
+		counters:  map[string]*counter{},
+	}
+}
+
+// type check
+var _ Interface = (*Default)(nil)
+
+// Add implements the [Interface] interface for *Default.
+func (d *Default) Add(_ context.Context, id agd.ProfileID) (total uint64) {
+	return d.add(string(id))
+}
+
+// AddAccount implements the [Interface] interface for *Default.
+func (d *Default) AddAccount(_ context.Context, id agd.AccountID) (total uint64) {
+	return d.add(accountCounterPrefix + string(id))
+}
+
+// add counts one request for the counter with the given name and returns the
+// estimated total.
+func (d *Default) add(name string) (total uint64) {
+	sec := d.clock.Now().Unix()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.counters[name]
+	if c == nil || c.sec != sec {
+		c = &counter{
+			sec: sec,
+		}
+
+		d.counters[name] = c
+	}
+
+	c.local++
+
+	return c.shared + c.local
+}
+
+// update is a pending update of a shared counter.
+type update struct {
+	name  string
+	sec   int64
+	delta uint64
+}
+
+// type check
+var _ agdservice.Refresher = (*Default)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for *Default.  It
+// sends the locally accumulated counts to the storage and updates the totals.
+func (d *Default) Refresh(ctx context.Context) (err error) {
+	updates := d.takeUpdates()
+
+	var errs []error
+	for _, u := range updates {
+		key := fmt.Sprintf("%s%s:%d", d.keyPrefix, u.name, u.sec)
+		total, incErr := d.storage.Increment(ctx, key, u.delta)
+		if incErr != nil {
+			errs = append(errs, fmt.Errorf("counter %q: %w", u.name, incErr))
+
+			continue
+		}
+
+		d.setShared(u.name, u.sec, total)
+	}
+
+	err = errors.Join(errs...)
+	if err != nil {
+		errcoll.Collect(ctx, d.errColl, d.logger, "syncing shared counters", err)
+	}
+
+	return err
+}
+
+// takeUpdates returns the pending updates, resets the local counts, and removes
+// the counters of the previous seconds.
+func (d *Default) takeUpdates() (updates []update) {
+	sec := d.clock.Now().Unix()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for name, c := range d.counters {
+		if c.local > 0 {
+			updates = append(updates, update{
+				name:  name,
+				sec:   c.sec,
+				delta: c.local,
+			})
+
+			c.shared += c.local
+			c.local = 0
+		}
+
+		if c.sec < sec {
+			delete(d.counters, name)
+		}
+	}
+
+	return updates
+}
+
+// setShared sets the shared total of the counter with the given name, if it is
+// still counting the same second.
+func (d *Default) setShared(name string, sec int64, total uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c := d.counters[name]
+	if c != nil && c.sec == sec {
+		c.shared = max(c.shared, total)
+	}
+}
@@ -0,0 +1,170 @@
+package sharedcounter_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/sharedcounter"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// testProfileID is the common profile ID for tests.
+const testProfileID agd.ProfileID = "prof1234"
+
+// testStorage is a [sharedcounter.Storage] implementation for tests.
+type testStorage struct {
+	mu   *sync.Mutex
+	vals map[string]uint64
+	err  error
+}
+
+// type check
+var _ sharedcounter.Storage = (*testStorage)(nil)
+
+// Increment implements the [sharedcounter.Storage] interface for *testStorage.
+func (s *testStorage) Increment(
+	_ context.Context,
+	key string,
+	delta uint64,
+) (val uint64, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	s.vals[key] += delta
+
+	return s.vals[key], nil
+}
+
+// newTestDefault returns a new *sharedcounter.Default for tests.
+func newTestDefault(
+	tb testing.TB,
+	clock *agdtest.Clock,
+	s sharedcounter.Storage,
+) (d *sharedcounter.Default) {
+	tb.Helper()
+
+	errColl := &agdtest.ErrorCollector{
+		OnCollect: func(_ context.Context, _ error) {},
+	}
+
+	return sharedcounter.NewDefault(&sharedcounter.DefaultConfig{
+		Logger:    slogutil.NewDiscardLogger(),
+		Clock:     clock,
+		ErrColl:   errColl,
+		Storage:   s,
+		KeyPrefix: "test:",
+	})
+}
+
+func TestDefault(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_000, 0)
+	clock := &agdtest.Clock{
+		OnNow: func() (n time.Time) { return now },
+	}
+
+	s := &testStorage{
+		mu:   &sync.Mutex{},
+		vals: map[string]uint64{},
+	}
+
+	first := newTestDefault(t, clock, s)
+	second := newTestDefault(t, clock, s)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	for range 3 {
+		_ = first.Add(ctx, testProfileID)
+	}
+
+	assert.Equal(t, uint64(1), second.Add(ctx, testProfileID))
+
+	require.NoError(t, first.Refresh(ctx))
+	require.NoError(t, second.Refresh(ctx))
+
+	assert.Equal(t, uint64(4), s.vals["test:prof1234:1000"])
+
+	// The second node has learned about the requests of the first one, but
+	// the first one hasn't learned about the request of the second one yet.
+	assert.Equal(t, uint64(5), second.Add(ctx, testProfileID))
+	assert.Equal(t, uint64(4), first.Add(ctx, testProfileID))
+
+	require.NoError(t, first.Refresh(ctx))
+	assert.Equal(t, uint64(6), first.Add(ctx, testProfileID))
+
+	// A new second starts a new counter.
+	now = now.Add(time.Second)
+	assert.Equal(t, uint64(1), first.Add(ctx, testProfileID))
+}
+
+func TestDefault_Refresh_error(t *testing.T) {
+	t.Parallel()
+
+	clock := &agdtest.Clock{
+		OnNow: func() (n time.Time) { return time.Unix(1_000, 0) },
+	}
+
+	const testError errors.Error = "test error"
+
+	s := &testStorage{
+		mu:   &sync.Mutex{},
+		vals: map[string]uint64{},
+		err:  testError,
+	}
+
+	d := newTestDefault(t, clock, s)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	assert.Equal(t, uint64(1), d.Add(ctx, testProfileID))
+
+	err := d.Refresh(ctx)
+	assert.ErrorIs(t, err, testError)
+
+	// The local count is kept despite the error.
+	assert.Equal(t, uint64(2), d.Add(ctx, testProfileID))
+}
+
+func TestDefault_AddAccount(t *testing.T) {
+	t.Parallel()
+
+	clock := &agdtest.Clock{
+		OnNow: func() (n time.Time) { return time.Unix(1_000, 0) },
+	}
+
+	s := &testStorage{
+		mu:   &sync.Mutex{},
+		vals: map[string]uint64{},
+	}
+
+	d := newTestDefault(t, clock, s)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	const testAccountID agd.AccountID = "acc1234"
+
+	// The account counters are separate from the profile ones.
+	assert.Equal(t, uint64(1), d.Add(ctx, testProfileID))
+	assert.Equal(t, uint64(1), d.AddAccount(ctx, testAccountID))
+	assert.Equal(t, uint64(2), d.AddAccount(ctx, testAccountID))
+
+	require.NoError(t, d.Refresh(ctx))
+
+	assert.Equal(t, uint64(1), s.vals["test:prof1234:1000"])
+	assert.Equal(t, uint64(2), s.vals["test:account:acc1234:1000"])
+}
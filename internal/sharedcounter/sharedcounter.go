@@ -0,0 +1,48 @@
+// Package sharedcounter contains the per-profile and per-account request
+// counters shared across the AdGuard DNS nodes, which are used to enforce the
+// custom ratelimits of profiles and the aggregate limits of accounts
+// fleet-wide.
+package sharedcounter
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// Interface is the interface for the shared per-profile and per-account request
+// counters.
+//
+// NOTE:  Implementations are expected to communicate with the shared storage
+// asynchronously, so the totals returned by Add are estimates.
+type Interface interface {
+	// Add counts one request for the profile with the given ID and returns the
+	// estimated number of requests of that profile during the current second
+	// across all nodes, including the current one.
+	Add(ctx context.Context, id agd.ProfileID) (total uint64)
+
+	// AddAccount counts one request for the account with the given ID and
+	// returns the estimated number of requests of all profiles of that account
+	// during the current second across all nodes, including the current one.
+	AddAccount(ctx context.Context, id agd.AccountID) (total uint64)
+}
+
+// Empty is an [Interface] implementation that does nothing.
+type Empty struct{}
+
+// type check
+var _ Interface = Empty{}
+
+// Add implements the [Interface] interface for Empty.  total is always zero.
+func (Empty) Add(_ context.Context, _ agd.ProfileID) (total uint64) { return 0 }
+
+// AddAccount implements the [Interface] interface for Empty.  total is always
+// zero.
+func (Empty) AddAccount(_ context.Context, _ agd.AccountID) (total uint64) { return 0 }
+
+// Storage is the interface for the remote storage of the shared counters.
+type Storage interface {
+	// Increment adds delta to the counter with the given key and returns the
+	// new value of the counter.
+	Increment(ctx context.Context, key string, delta uint64) (val uint64, err error)
+}
@@ -0,0 +1,227 @@
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/service"
+	renameio "github.com/google/renameio/v2"
+)
+
+// DefaultManagerConfig is the configuration structure for [NewDefaultManager].
+type DefaultManagerConfig struct {
+	// Logger is used to log the operation of the manager.  It must not be nil.
+	Logger *slog.Logger
+
+	// ErrColl is used to collect the errors of saving and restoring the
+	// snapshots.  It must not be nil.
+	ErrColl errcoll.Interface
+
+	// Path is the path to the snapshot file.  It must not be empty.
+	Path string
+
+	// MaxAge is the maximum age of a snapshot that is restored.  Older
+	// snapshots are ignored.  It must be positive.
+	MaxAge time.Duration
+}
+
+// DefaultManager is the [Manager] implementation that saves the state of the
+// components into a file on shutdown and restores it on startup.
+type DefaultManager struct {
+	logger     *slog.Logger
+	errColl    errcoll.Interface
+	mu         *sync.Mutex
+	components map[string]Component
+	path       string
+	maxAge     time.Duration
+}
+
+// NewDefaultManager returns a new properly initialized *DefaultManager.  c must
+// not be nil.
+func NewDefaultManager(c *DefaultManagerConfig) (m *DefaultManager) {
+	return &DefaultManager{
+		logger:     c.Logger,
+		errColl:    c.ErrColl,
+		mu:         &sync.Mutex{},
+		components: map[string]Component{},
+		path:       c.Path,
+		maxAge:     c.MaxAge,
+	}
+}
+
+// type check
+var _ Manager = (*DefaultManager)(nil)
+
+// Add implements the [Manager] interface for *DefaultManager.  Note that it
+// replaces the component with the same name if there is one.
+func (m *DefaultManager) Add(name string, c Component) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.components[name] = c
+}
+
+// Restore restores the state of the added components from the snapshot file,
+// if there is one and it is not too old.  The errors are collected and don't
+// prevent the other components from being restored.
+func (m *DefaultManager) Restore(ctx context.Context) {
+	f, err := m.read()
+	if err != nil {
+		errcoll.Collect(ctx, m.errColl, m.logger, "reading snapshot", err)
+
+		return
+	} else if f == nil {
+		m.logger.InfoContext(ctx, "no snapshot", "path", m.path)
+
+		return
+	}
+
+	age := time.Since(f.Time)
+	if age > m.maxAge {
+		m.logger.InfoContext(ctx, "snapshot too old", "age", age, "max_age", m.maxAge)
+
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, name := range slices.Sorted(maps.Keys(m.components)) {
+		data, ok := f.Components[name]
+		if !ok {
+			continue
+		}
+
+		start := time.Now()
+		err = m.components[name].Restore(ctx, data)
+		if err != nil {
+			err = fmt.Errorf("component %q: %w", name, err)
+			errcoll.Collect(ctx, m.errColl, m.logger, "restoring snapshot", err)
+
+			continue
+		}
+
+		m.logger.InfoContext(
+			ctx,
+			"restored component",
+			"name", name,
+			"elapsed", time.Since(start),
+		)
+	}
+}
+
+// read reads and decodes the snapshot file.  f is nil if there is no file or
+// its version is different from the current one.
+func (m *DefaultManager) read() (f *file, err error) {
+	b, err := os.ReadFile(m.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		// Don't wrap the error, since it contains the path.
+		return nil, err
+	}
+
+	f = &file{}
+	err = json.Unmarshal(b, f)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %q: %w", m.path, err)
+	}
+
+	if f.Version != formatVersion {
+		return nil, nil
+	}
+
+	return f, nil
+}
+
+// type check
+var _ service.Interface = (*DefaultManager)(nil)
+
+// Start implements the [service.Interface] interface for *DefaultManager.  It
+// does nothing, use [DefaultManager.Restore] to restore the state.
+func (m *DefaultManager) Start(_ context.Context) (err error) {
+	return nil
+}
+
+// Shutdown implements the [service.Interface] interface for *DefaultManager.
+// It saves the state of the added components into the snapshot file.  The
+// components that failed to produce their state are skipped.
+func (m *DefaultManager) Shutdown(ctx context.Context) (err error) {
+	f := &file{
+		Components: m.snapshotComponents(ctx),
+		Time:       time.Now(),
+		Version:    formatVersion,
+	}
+
+	b, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("encoding snapshot: %w", err)
+	}
+
+	err = renameio.WriteFile(m.path, b, agd.DefaultPerm)
+	if err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+
+	m.logger.InfoContext(
+		ctx,
+		"saved snapshot",
+		"path", m.path,
+		"components", len(f.Components),
+		"size", len(b),
+	)
+
+	return nil
+}
+
+// snapshotComponents returns the JSON-encoded states of the added components.
+func (m *DefaultManager) snapshotComponents(
+	ctx context.Context,
+) (comps map[string]json.RawMessage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	comps = make(map[string]json.RawMessage, len(m.components))
+	for name, c := range m.components {
+		data, err := snapshotComponent(ctx, c)
+		if err != nil {
+			err = fmt.Errorf("component %q: %w", name, err)
+			errcoll.Collect(ctx, m.errColl, m.logger, "taking snapshot", err)
+
+			continue
+		}
+
+		comps[name] = data
+	}
+
+	return comps
+}
+
+// snapshotComponent returns the JSON-encoded state of c.
+func snapshotComponent(ctx context.Context, c Component) (data json.RawMessage, err error) {
+	state, err := c.Snapshot(ctx)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	data, err = json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("encoding state: %w", err)
+	}
+
+	return data, nil
+}
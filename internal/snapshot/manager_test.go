@@ -0,0 +1,123 @@
+package snapshot_test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/snapshot"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// testComponent is a [snapshot.Component] for tests.
+type testComponent struct {
+	onSnapshot func(ctx context.Context) (state any, err error)
+	onRestore  func(ctx context.Context, data []byte) (err error)
+}
+
+// type check
+var _ snapshot.Component = (*testComponent)(nil)
+
+// Snapshot implements the [snapshot.Component] interface for *testComponent.
+func (c *testComponent) Snapshot(ctx context.Context) (state any, err error) {
+	return c.onSnapshot(ctx)
+}
+
+// Restore implements the [snapshot.Component] interface for *testComponent.
+func (c *testComponent) Restore(ctx context.Context, data []byte) (err error) {
+	return c.onRestore(ctx, data)
+}
+
+// newManager is a helper that returns a new *snapshot.DefaultManager for tests.
+func newManager(tb testing.TB, path string, maxAge time.Duration) (m *snapshot.DefaultManager) {
+	tb.Helper()
+
+	errColl := agdtest.NewErrorCollector()
+	errColl.OnCollect = func(_ context.Context, err error) {
+		tb.Errorf("unexpected error: %v", err)
+	}
+
+	return snapshot.NewDefaultManager(&snapshot.DefaultManagerConfig{
+		Logger:  slogutil.NewDiscardLogger(),
+		ErrColl: errColl,
+		Path:    path,
+		MaxAge:  maxAge,
+	})
+}
+
+func TestDefaultManager(t *testing.T) {
+	const (
+		compName = "test"
+		state    = "state"
+	)
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	saved := newManager(t, path, time.Minute)
+	saved.Add(compName, &testComponent{
+		onSnapshot: func(_ context.Context) (s any, err error) {
+			return state, nil
+		},
+		onRestore: func(_ context.Context, _ []byte) (err error) {
+			panic(errors.Error("unexpected call to restore"))
+		},
+	})
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	err := saved.Shutdown(ctx)
+	require.NoError(t, err)
+
+	t.Run("restore", func(t *testing.T) {
+		var got string
+		m := newManager(t, path, time.Minute)
+		m.Add(compName, &testComponent{
+			onSnapshot: func(_ context.Context) (s any, err error) {
+				panic(errors.Error("unexpected call to snapshot"))
+			},
+			onRestore: func(_ context.Context, data []byte) (err error) {
+				return json.Unmarshal(data, &got)
+			},
+		})
+
+		m.Restore(testutil.ContextWithTimeout(t, testTimeout))
+		assert.Equal(t, state, got)
+	})
+
+	t.Run("too_old", func(t *testing.T) {
+		m := newManager(t, path, time.Nanosecond)
+		m.Add(compName, &testComponent{
+			onSnapshot: func(_ context.Context) (s any, err error) {
+				panic(errors.Error("unexpected call to snapshot"))
+			},
+			onRestore: func(_ context.Context, _ []byte) (err error) {
+				panic(errors.Error("unexpected call to restore"))
+			},
+		})
+
+		m.Restore(testutil.ContextWithTimeout(t, testTimeout))
+	})
+
+	t.Run("no_file", func(t *testing.T) {
+		m := newManager(t, filepath.Join(t.TempDir(), "none.json"), time.Minute)
+		m.Add(compName, &testComponent{
+			onSnapshot: func(_ context.Context) (s any, err error) {
+				panic(errors.Error("unexpected call to snapshot"))
+			},
+			onRestore: func(_ context.Context, _ []byte) (err error) {
+				panic(errors.Error("unexpected call to restore"))
+			},
+		})
+
+		m.Restore(testutil.ContextWithTimeout(t, testTimeout))
+	})
+}
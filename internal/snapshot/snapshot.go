@@ -0,0 +1,55 @@
+// Package snapshot contains the types for saving the hot runtime state, such as
+// the DNS cache and the rate limiter bans, to disk on shutdown and restoring it
+// on startup, which shrinks the cold-start period of a replacement node.
+package snapshot
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Component is a part of the runtime state that can be saved into and restored
+// from a snapshot.  All methods must be safe for concurrent use.
+type Component interface {
+	// Snapshot returns the current state of the component.  state must be
+	// encodable as JSON.
+	Snapshot(ctx context.Context) (state any, err error)
+
+	// Restore restores the state of the component from data, which is the JSON
+	// encoding of a state previously returned by Snapshot.  It is called before
+	// the component starts serving.
+	Restore(ctx context.Context, data []byte) (err error)
+}
+
+// Manager is the interface for registries of the snapshot components.  All
+// methods must be safe for concurrent use.
+type Manager interface {
+	// Add adds a component by its unique name.  c must not be nil.
+	Add(name string, c Component)
+}
+
+// EmptyManager is the [Manager] implementation that does nothing.
+type EmptyManager struct{}
+
+// type check
+var _ Manager = EmptyManager{}
+
+// Add implements the [Manager] interface for EmptyManager.
+func (EmptyManager) Add(_ string, _ Component) {}
+
+// formatVersion is the current version of the snapshot file format.  Bump it
+// when the format of the file or any component changes incompatibly.
+const formatVersion uint = 1
+
+// file is the structure of the snapshot file.
+type file struct {
+	// Components are the JSON-encoded states of the components by their names.
+	Components map[string]json.RawMessage `json:"components"`
+
+	// Time is the time when the snapshot was taken.
+	Time time.Time `json:"time"`
+
+	// Version is the version of the format of the file.
+	Version uint `json:"version"`
+}
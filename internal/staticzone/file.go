@@ -0,0 +1,245 @@
+package staticzone
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// FileConfig is the configuration structure for a *File.
+type FileConfig struct {
+	// Logger is used to log the operation of the storage.  It must not be nil.
+	Logger *slog.Logger
+
+	// ErrColl is used to collect the refresh errors.  It must not be nil.
+	ErrColl errcoll.Interface
+
+	// Metrics is used for the collection of the static zone statistics.  It
+	// must not be nil.
+	Metrics Metrics
+
+	// Paths are the paths to the zone files in the RFC 1035 format.  It must
+	// not be empty.
+	Paths []string
+
+	// RequireZONEMD, if true, makes the storage refuse the zone files without
+	// verifiable ZONEMD records.  The zone files with ZONEMD records that fail
+	// the verification are always refused.
+	RequireZONEMD bool
+}
+
+// File is an [Interface] implementation that reads the static answers from
+// zone files.  It should be initially refreshed before use.
+type File struct {
+	logger  *slog.Logger
+	errColl errcoll.Interface
+	metrics Metrics
+
+	// mu protects names.
+	mu    *sync.RWMutex
+	names map[string]*name
+
+	paths []string
+
+	requireZONEMD bool
+}
+
+// name contains the static records of a single domain name.
+type name struct {
+	// rrs are the records by their types.
+	rrs map[dnsmsg.RRType][]dns.RR
+
+	// cname is the CNAME record of the name, if any.
+	cname dns.RR
+}
+
+// NewFile returns a new properly initialized *File.  c must be valid.
+func NewFile(c *FileConfig) (f *File) {
+	return &File{
+		logger:        c.Logger,
+		errColl:       c.ErrColl,
+		metrics:       c.Metrics,
+		mu:            &sync.RWMutex{},
+		names:         map[string]*name{},
+		paths:         c.Paths,
+		requireZONEMD: c.RequireZONEMD,
+	}
+}
+
+// type check
+var _ Interface = (*File)(nil)
+
+// Answer implements the [Interface] interface for *File.
+func (f *File) Answer(
+	_ context.Context,
+	host string,
+	qt dnsmsg.RRType,
+) (ans []dns.RR, found bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	n := f.names[host]
+	if n == nil {
+		return nil, false
+	}
+
+	if rrs := n.rrs[qt]; len(rrs) > 0 {
+		return rrs, true
+	}
+
+	if n.cname != nil && qt != dns.TypeCNAME {
+		return []dns.RR{n.cname}, true
+	}
+
+	return nil, true
+}
+
+// type check
+var _ agdservice.Refresher = (*File)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for *File.  It
+// rereads the zone files and verifies their ZONEMD records, if any.  If any of
+// them is invalid, the current records are kept.
+func (f *File) Refresh(ctx context.Context) (err error) {
+	f.logger.DebugContext(ctx, "refresh started")
+	defer f.logger.DebugContext(ctx, "refresh finished")
+
+	names := map[string]*name{}
+	numRRs := 0
+	for _, p := range f.paths {
+		var n int
+		n, err = f.readZoneFile(ctx, names, p)
+		if err != nil {
+			errcoll.Collect(ctx, f.errColl, f.logger, "refreshing static zones", err)
+
+			// Don't wrap the error, because it's informative enough as is.
+			return err
+		}
+
+		numRRs += n
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.names = names
+
+	f.logger.InfoContext(ctx, "refresh successful", "num_names", len(names), "num_rrs", numRRs)
+
+	return nil
+}
+
+// readZoneFile reads the zone file at path, verifies its ZONEMD records, and
+// adds its records to names.  n is the number of the added records.
+func (f *File) readZoneFile(
+	ctx context.Context,
+	names map[string]*name,
+	path string,
+) (n int, err error) {
+	// #nosec G304 -- Trust the path, since it's given by the operator.
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("opening zone file: %w", err)
+	}
+	defer func() { err = errors.WithDeferred(err, file.Close()) }()
+
+	rrs, err := parseZone(file, path)
+	if err != nil {
+		return 0, fmt.Errorf("parsing zone file %q: %w", path, err)
+	}
+
+	err = f.verifyZone(ctx, rrs)
+	if err != nil {
+		return 0, fmt.Errorf("verifying zone file %q: %w", path, err)
+	}
+
+	for _, rr := range rrs {
+		err = addRR(names, rr)
+		if err != nil {
+			return 0, fmt.Errorf("zone file %q: %w", path, err)
+		}
+	}
+
+	return len(rrs), nil
+}
+
+// verifyZone verifies the ZONEMD records of the zone consisting of rrs and
+// reports the result to the metrics.  It returns an error if the zone must be
+// refused.
+func (f *File) verifyZone(ctx context.Context, rrs []dns.RR) (err error) {
+	apex, res, err := verifyZONEMD(rrs)
+	f.metrics.OnZONEMDVerification(ctx, strings.ToLower(apex), string(res))
+
+	f.logger.DebugContext(ctx, "zonemd verification", "zone", apex, "result", res)
+
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return err
+	}
+
+	if f.requireZONEMD && res != ZONEMDResultVerified {
+		return fmt.Errorf("zonemd required: got result %q", res)
+	}
+
+	return nil
+}
+
+// parseZone parses the zone data from r.  path is used for error reporting.
+func parseZone(r io.Reader, path string) (rrs []dns.RR, err error) {
+	zp := dns.NewZoneParser(r, "", path)
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		rrs = append(rrs, rr)
+	}
+
+	err = zp.Err()
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	return rrs, nil
+}
+
+// addRR adds rr to names.  It returns an error if rr is not of class IN or if
+// it conflicts with a CNAME record.
+func addRR(names map[string]*name, rr dns.RR) (err error) {
+	hdr := rr.Header()
+	if hdr.Class != dns.ClassINET {
+		return fmt.Errorf("record %q: bad class %s", hdr.Name, dns.Class(hdr.Class))
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(hdr.Name, "."))
+	n := names[host]
+	if n == nil {
+		n = &name{
+			rrs: map[dnsmsg.RRType][]dns.RR{},
+		}
+
+		names[host] = n
+	}
+
+	qt := hdr.Rrtype
+	if qt == dns.TypeCNAME {
+		if n.cname != nil || len(n.rrs) > 0 {
+			return fmt.Errorf("record %q: cname and other data", hdr.Name)
+		}
+
+		n.cname = rr
+	} else if n.cname != nil {
+		return fmt.Errorf("record %q: cname and other data", hdr.Name)
+	}
+
+	n.rrs[qt] = append(n.rrs[qt], rr)
+
+	return nil
+}
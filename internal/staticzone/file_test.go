@@ -0,0 +1,284 @@
+package staticzone_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/AdguardTeam/AdGuardDNS/internal/staticzone"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// testZone is the common content of the zone file for tests.
+const testZone = `$ORIGIN example.net.
+$TTL 300
+ntp        IN A     192.0.2.1
+ntp        IN A     192.0.2.2
+NTP        IN AAAA  2001:db8::1
+health     IN TXT   "ok"
+alias      IN CNAME ntp.example.net.
+`
+
+// newTestFile is a helper that writes content into a temporary zone file and
+// returns a new *staticzone.File reading it.
+func newTestFile(tb testing.TB, content string) (f *staticzone.File, path string) {
+	tb.Helper()
+
+	return newTestFileWithConf(tb, content, staticzone.EmptyMetrics{}, false)
+}
+
+// newTestFileWithConf is a helper that writes content into a temporary zone
+// file and returns a new *staticzone.File reading it with the given metrics
+// and ZONEMD requirement.
+func newTestFileWithConf(
+	tb testing.TB,
+	content string,
+	mtrc staticzone.Metrics,
+	requireZONEMD bool,
+) (f *staticzone.File, path string) {
+	tb.Helper()
+
+	path = filepath.Join(tb.TempDir(), "static.zone")
+	err := os.WriteFile(path, []byte(content), 0o600)
+	require.NoError(tb, err)
+
+	f = staticzone.NewFile(&staticzone.FileConfig{
+		Logger: slogutil.NewDiscardLogger(),
+		ErrColl: &agdtest.ErrorCollector{
+			OnCollect: func(_ context.Context, _ error) {},
+		},
+		Metrics:       mtrc,
+		Paths:         []string{path},
+		RequireZONEMD: requireZONEMD,
+	})
+
+	return f, path
+}
+
+func TestFile_Answer(t *testing.T) {
+	t.Parallel()
+
+	f, _ := newTestFile(t, testZone)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, f.Refresh(ctx))
+
+	testCases := []struct {
+		name      string
+		host      string
+		wantTypes []dnsmsg.RRType
+		qt        dnsmsg.RRType
+		wantFound bool
+	}{{
+		name:      "a",
+		host:      "ntp.example.net",
+		qt:        dns.TypeA,
+		wantTypes: []dnsmsg.RRType{dns.TypeA, dns.TypeA},
+		wantFound: true,
+	}, {
+		name:      "aaaa_case",
+		host:      "ntp.example.net",
+		qt:        dns.TypeAAAA,
+		wantTypes: []dnsmsg.RRType{dns.TypeAAAA},
+		wantFound: true,
+	}, {
+		name:      "nodata",
+		host:      "health.example.net",
+		qt:        dns.TypeA,
+		wantTypes: nil,
+		wantFound: true,
+	}, {
+		name:      "cname",
+		host:      "alias.example.net",
+		qt:        dns.TypeA,
+		wantTypes: []dnsmsg.RRType{dns.TypeCNAME},
+		wantFound: true,
+	}, {
+		name:      "not_found",
+		host:      "other.example.net",
+		qt:        dns.TypeA,
+		wantTypes: nil,
+		wantFound: false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ans, found := f.Answer(testutil.ContextWithTimeout(t, testTimeout), tc.host, tc.qt)
+			assert.Equal(t, tc.wantFound, found)
+
+			var types []dnsmsg.RRType
+			for _, rr := range ans {
+				types = append(types, rr.Header().Rrtype)
+			}
+
+			assert.Equal(t, tc.wantTypes, types)
+		})
+	}
+}
+
+func TestFile_Refresh(t *testing.T) {
+	t.Parallel()
+
+	f, path := newTestFile(t, testZone)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	require.NoError(t, f.Refresh(ctx))
+
+	const newZone = `other.example.net. 60 IN A 192.0.2.3` + "\n"
+
+	err := os.WriteFile(path, []byte(newZone), 0o600)
+	require.NoError(t, err)
+
+	require.NoError(t, f.Refresh(ctx))
+
+	_, found := f.Answer(ctx, "ntp.example.net", dns.TypeA)
+	assert.False(t, found)
+
+	ans, found := f.Answer(ctx, "other.example.net", dns.TypeA)
+	assert.True(t, found)
+	assert.Len(t, ans, 1)
+
+	t.Run("bad", func(t *testing.T) {
+		badZones := []string{
+			"alias.example.net. 60 IN CNAME a.example.\nalias.example.net. 60 IN A 192.0.2.1\n",
+			"id.example.net. 60 CH TXT \"x\"\n",
+			"bad.example.net. 60 IN A not-an-ip\n",
+		}
+
+		for _, z := range badZones {
+			err = os.WriteFile(path, []byte(z), 0o600)
+			require.NoError(t, err)
+
+			assert.Error(t, f.Refresh(ctx))
+
+			// The previous records must be kept.
+			_, found = f.Answer(ctx, "other.example.net", dns.TypeA)
+			assert.True(t, found)
+		}
+	})
+}
+
+// testZONEMDZone is the simple example zone from RFC 8976 Appendix A.1.
+const testZONEMDZone = `$ORIGIN example.
+example.      86400  IN  SOA     ns1 admin 2018031900 1800 900 604800 86400
+              86400  IN  NS      ns1
+              86400  IN  NS      ns2
+              86400  IN  ZONEMD  2018031900 1 1 (
+                                 c68090d90a7aed716bc459f9340e3d7c
+                                 1370d4d24b7e2fc3a1ddc0b9a87153b9
+                                 a9713b3c9ae5cc27777f98b8e730044c )
+ns1           3600   IN  A       203.0.113.63
+NS2           3600   IN  AAAA    2001:db8::63
+`
+
+// testZONEMDMetrics is a [staticzone.Metrics] implementation for tests that
+// records the last result.
+type testZONEMDMetrics struct {
+	zone   string
+	result string
+}
+
+// type check
+var _ staticzone.Metrics = (*testZONEMDMetrics)(nil)
+
+// OnZONEMDVerification implements the [staticzone.Metrics] interface for
+// *testZONEMDMetrics.
+func (m *testZONEMDMetrics) OnZONEMDVerification(_ context.Context, zone, result string) {
+	m.zone, m.result = zone, result
+}
+
+func TestFile_Refresh_zonemd(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name          string
+		content       string
+		wantZone      string
+		wantResult    staticzone.ZONEMDResult
+		wantErrMsg    string
+		requireZONEMD bool
+	}{{
+		name:          "verified",
+		content:       testZONEMDZone,
+		wantZone:      "example.",
+		wantResult:    staticzone.ZONEMDResultVerified,
+		wantErrMsg:    "",
+		requireZONEMD: true,
+	}, {
+		name:          "tampered",
+		content:       strings.Replace(testZONEMDZone, "203.0.113.63", "203.0.113.64", 1),
+		wantZone:      "example.",
+		wantResult:    staticzone.ZONEMDResultFailed,
+		wantErrMsg:    "digest mismatch",
+		requireZONEMD: false,
+	}, {
+		name:          "truncated",
+		content:       strings.Replace(testZONEMDZone, "NS2           3600   IN  AAAA    2001:db8::63\n", "", 1),
+		wantZone:      "example.",
+		wantResult:    staticzone.ZONEMDResultFailed,
+		wantErrMsg:    "digest mismatch",
+		requireZONEMD: false,
+	}, {
+		name:          "serial_mismatch",
+		content:       strings.Replace(testZONEMDZone, "ZONEMD  2018031900", "ZONEMD  2018031901", 1),
+		wantZone:      "example.",
+		wantResult:    staticzone.ZONEMDResultFailed,
+		wantErrMsg:    "does not match soa serial",
+		requireZONEMD: false,
+	}, {
+		name:          "unsupported",
+		content:       strings.Replace(testZONEMDZone, "ZONEMD  2018031900 1 1", "ZONEMD  2018031900 1 240", 1),
+		wantZone:      "example.",
+		wantResult:    staticzone.ZONEMDResultUnsupported,
+		wantErrMsg:    "",
+		requireZONEMD: false,
+	}, {
+		name:          "missing",
+		content:       testZone,
+		wantZone:      "",
+		wantResult:    staticzone.ZONEMDResultMissing,
+		wantErrMsg:    "",
+		requireZONEMD: false,
+	}, {
+		name:          "missing_required",
+		content:       testZone,
+		wantZone:      "",
+		wantResult:    staticzone.ZONEMDResultMissing,
+		wantErrMsg:    "zonemd required",
+		requireZONEMD: true,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			mtrc := &testZONEMDMetrics{}
+			f, _ := newTestFileWithConf(t, tc.content, mtrc, tc.requireZONEMD)
+
+			err := f.Refresh(testutil.ContextWithTimeout(t, testTimeout))
+			if tc.wantErrMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.wantErrMsg)
+			}
+
+			assert.Equal(t, tc.wantZone, mtrc.zone)
+			assert.Equal(t, string(tc.wantResult), mtrc.result)
+		})
+	}
+}
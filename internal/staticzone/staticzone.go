@@ -0,0 +1,56 @@
+// Package staticzone contains the storages of the static answers, which are
+// loaded from operator-defined zone files and served authoritatively instead of
+// being filtered and forwarded upstream.
+package staticzone
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsmsg"
+	"github.com/miekg/dns"
+)
+
+// Interface is the storage of the static answers.
+//
+// All methods must be safe for concurrent use.
+type Interface interface {
+	// Answer returns the static records for host and qt.  host must be a
+	// lowercase, non-FQDN domain name.  found is true if host has any static
+	// records at all, in which case the query must be answered authoritatively
+	// with ans, which is empty for a NODATA response.  If host has a CNAME
+	// record and qt is not CNAME, ans contains that CNAME record.  ans must
+	// not be modified.
+	Answer(ctx context.Context, host string, qt dnsmsg.RRType) (ans []dns.RR, found bool)
+}
+
+// type check
+var _ Interface = Empty{}
+
+// Empty is an [Interface] implementation that has no static answers.
+type Empty struct{}
+
+// Answer implements the [Interface] interface for Empty.  found is always
+// false.
+func (Empty) Answer(_ context.Context, _ string, _ dnsmsg.RRType) (ans []dns.RR, found bool) {
+	return nil, false
+}
+
+// Metrics is an interface for collection of the statistics of the static
+// zones.
+type Metrics interface {
+	// OnZONEMDVerification records the result of the verification of the
+	// ZONEMD records of the zone with the given lowercase apex.  zone is empty
+	// if the zone file has no SOA record.  result is one of the [ZONEMDResult]
+	// values.
+	OnZONEMDVerification(ctx context.Context, zone, result string)
+}
+
+// EmptyMetrics is an implementation of the [Metrics] interface that does
+// nothing.
+type EmptyMetrics struct{}
+
+// type check
+var _ Metrics = EmptyMetrics{}
+
+// OnZONEMDVerification implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) OnZONEMDVerification(_ context.Context, _, _ string) {}
@@ -0,0 +1,363 @@
+package staticzone
+
+import (
+	"bytes"
+	"cmp"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"slices"
+	"strings"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/miekg/dns"
+)
+
+// ZONEMDResult is the result of the verification of the ZONEMD records of a
+// zone file.  It is used as a metrics label.
+type ZONEMDResult string
+
+// ZONEMDResult constants.
+const (
+	// ZONEMDResultVerified means that the zone has a ZONEMD record with a
+	// supported scheme and hash algorithm and its digest matches the data of
+	// the zone.
+	ZONEMDResultVerified ZONEMDResult = "verified"
+
+	// ZONEMDResultMissing means that the zone has no SOA record or no ZONEMD
+	// records at its apex.
+	ZONEMDResultMissing ZONEMDResult = "missing"
+
+	// ZONEMDResultUnsupported means that none of the ZONEMD records of the zone
+	// have a supported scheme and hash algorithm.
+	ZONEMDResultUnsupported ZONEMDResult = "unsupported"
+
+	// ZONEMDResultFailed means that the ZONEMD records of the zone are invalid
+	// or none of the digests match the data of the zone, so the zone must be
+	// refused.
+	ZONEMDResultFailed ZONEMDResult = "failed"
+)
+
+// zonemdSchemeSimple is the SIMPLE ZONEMD scheme, see RFC 8976 Section 5.2.
+const zonemdSchemeSimple uint8 = 1
+
+// ZONEMD hash algorithms, see RFC 8976 Section 5.3.
+const (
+	zonemdHashSHA384 uint8 = 1
+	zonemdHashSHA512 uint8 = 2
+)
+
+// newZONEMDHash returns a new hash for the ZONEMD hash algorithm alg.  h is nil
+// if alg is not supported.
+func newZONEMDHash(alg uint8) (h hash.Hash) {
+	switch alg {
+	case zonemdHashSHA384:
+		return sha512.New384()
+	case zonemdHashSHA512:
+		return sha512.New()
+	default:
+		return nil
+	}
+}
+
+// verifyZONEMD verifies the zone data rrs using the ZONEMD records at the apex
+// of the zone as defined by RFC 8976.  The apex is the owner name of the SOA
+// record.  err is not nil only if res is [ZONEMDResultFailed].
+func verifyZONEMD(rrs []dns.RR) (apex string, res ZONEMDResult, err error) {
+	soa, err := findSOA(rrs)
+	if err != nil {
+		return "", ZONEMDResultFailed, err
+	} else if soa == nil {
+		return "", ZONEMDResultMissing, nil
+	}
+
+	apex = soa.Hdr.Name
+	data, zonemds := splitApexZONEMD(rrs, apex)
+	if len(zonemds) == 0 {
+		return apex, ZONEMDResultMissing, nil
+	}
+
+	defer func() { err = errors.Annotate(err, "zonemd for %q: %w", apex) }()
+
+	res, err = checkZONEMD(data, zonemds, soa.Serial)
+
+	return apex, res, err
+}
+
+// findSOA returns the only SOA record of rrs.  soa is nil if there are no SOA
+// records.
+func findSOA(rrs []dns.RR) (soa *dns.SOA, err error) {
+	for _, rr := range rrs {
+		s, ok := rr.(*dns.SOA)
+		if !ok {
+			continue
+		} else if soa != nil {
+			return nil, errors.Error("multiple soa records")
+		}
+
+		soa = s
+	}
+
+	return soa, nil
+}
+
+// splitApexZONEMD returns the ZONEMD records with the owner name apex and the
+// rest of rrs, excluding the signatures of those ZONEMD records, as required
+// by RFC 8976 Section 3.3.1.1.
+func splitApexZONEMD(rrs []dns.RR, apex string) (data []dns.RR, zonemds []*dns.ZONEMD) {
+	data = make([]dns.RR, 0, len(rrs))
+	for _, rr := range rrs {
+		if !strings.EqualFold(rr.Header().Name, apex) {
+			data = append(data, rr)
+
+			continue
+		}
+
+		switch rr := rr.(type) {
+		case *dns.ZONEMD:
+			zonemds = append(zonemds, rr)
+		case *dns.RRSIG:
+			if rr.TypeCovered != dns.TypeZONEMD {
+				data = append(data, rr)
+			}
+		default:
+			data = append(data, rr)
+		}
+	}
+
+	return data, zonemds
+}
+
+// checkZONEMD checks the digests of zonemds against data.  serial is the
+// serial number from the SOA record of the zone.
+func checkZONEMD(
+	data []dns.RR,
+	zonemds []*dns.ZONEMD,
+	serial uint32,
+) (res ZONEMDResult, err error) {
+	type schemeHash struct {
+		scheme uint8
+		hash   uint8
+	}
+
+	var recs []*canonicalRR
+	seen := map[schemeHash]struct{}{}
+	var errs []error
+	for _, z := range zonemds {
+		sh := schemeHash{scheme: z.Scheme, hash: z.Hash}
+		if _, ok := seen[sh]; ok {
+			return ZONEMDResultFailed, fmt.Errorf(
+				"duplicate scheme %d and hash algorithm %d",
+				z.Scheme,
+				z.Hash,
+			)
+		}
+
+		seen[sh] = struct{}{}
+
+		h := newZONEMDHash(z.Hash)
+		if z.Scheme != zonemdSchemeSimple || h == nil {
+			continue
+		}
+
+		if recs == nil {
+			recs, err = canonicalRRs(data)
+			if err != nil {
+				return ZONEMDResultFailed, err
+			}
+		}
+
+		err = checkDigest(z, h, recs, serial)
+		if err == nil {
+			return ZONEMDResultVerified, nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	if len(errs) == 0 {
+		return ZONEMDResultUnsupported, nil
+	}
+
+	return ZONEMDResultFailed, errors.Join(errs...)
+}
+
+// checkDigest returns an error if the serial number or the digest of z don't
+// match the zone.  h must be the hash for the hash algorithm of z, recs must be
+// sorted and deduplicated.
+func checkDigest(z *dns.ZONEMD, h hash.Hash, recs []*canonicalRR, serial uint32) (err error) {
+	if z.Serial != serial {
+		return fmt.Errorf(
+			"hash algorithm %d: serial %d: does not match soa serial %d",
+			z.Hash,
+			z.Serial,
+			serial,
+		)
+	}
+
+	want, err := hex.DecodeString(z.Digest)
+	if err != nil {
+		return fmt.Errorf("hash algorithm %d: decoding digest: %w", z.Hash, err)
+	}
+
+	for _, r := range recs {
+		// The writes to a hash never fail.
+		_, _ = h.Write(r.wire)
+	}
+
+	if !bytes.Equal(h.Sum(nil), want) {
+		return fmt.Errorf("hash algorithm %d: digest mismatch", z.Hash)
+	}
+
+	return nil
+}
+
+// canonicalRR is a record in the canonical form as defined by RFC 4034
+// Section 6.2.
+type canonicalRR struct {
+	// labels are the labels of the owner name in the wire format.
+	labels [][]byte
+
+	// rdata is the RDATA of the record in the wire format.
+	rdata []byte
+
+	// wire is the whole record in the wire format.
+	wire []byte
+
+	// rrType is the type of the record.
+	rrType uint16
+}
+
+// canonicalRRs returns the records from rrs in the canonical form, sorted in
+// the canonical order and deduplicated, as required by RFC 8976 Section 3.3.
+func canonicalRRs(rrs []dns.RR) (recs []*canonicalRR, err error) {
+	recs = make([]*canonicalRR, 0, len(rrs))
+	for _, rr := range rrs {
+		var r *canonicalRR
+		r, err = newCanonicalRR(rr)
+		if err != nil {
+			return nil, fmt.Errorf("record %q: %w", rr.Header().Name, err)
+		}
+
+		recs = append(recs, r)
+	}
+
+	slices.SortFunc(recs, compareCanonicalRRs)
+
+	return slices.CompactFunc(recs, func(a, b *canonicalRR) (ok bool) {
+		return bytes.Equal(a.wire, b.wire)
+	}), nil
+}
+
+// newCanonicalRR returns rr converted into the canonical form.
+func newCanonicalRR(rr dns.RR) (r *canonicalRR, err error) {
+	rr = dns.Copy(rr)
+	hdr := rr.Header()
+	hdr.Name = strings.ToLower(hdr.Name)
+	lowerRDATANames(rr)
+
+	wire := make([]byte, dns.Len(rr))
+	n, err := dns.PackRR(rr, wire, 0, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("packing: %w", err)
+	}
+
+	wire = wire[:n]
+	rdataStart := n - int(hdr.Rdlength)
+
+	// The fixed part of the header after the owner name consists of the type,
+	// class, TTL, and RDLENGTH fields.
+	const fixedHdrLen = 10
+
+	return &canonicalRR{
+		labels: wireLabels(wire[:rdataStart-fixedHdrLen]),
+		rdata:  wire[rdataStart:],
+		wire:   wire,
+		rrType: hdr.Rrtype,
+	}, nil
+}
+
+// lowerRDATANames converts the domain names within the RDATA of rr to
+// lowercase for the types listed in RFC 4034 Section 6.2, as updated by RFC
+// 6840 Section 5.1.
+func lowerRDATANames(rr dns.RR) {
+	switch rr := rr.(type) {
+	case *dns.NS:
+		rr.Ns = strings.ToLower(rr.Ns)
+	case *dns.MD:
+		rr.Md = strings.ToLower(rr.Md)
+	case *dns.MF:
+		rr.Mf = strings.ToLower(rr.Mf)
+	case *dns.CNAME:
+		rr.Target = strings.ToLower(rr.Target)
+	case *dns.SOA:
+		rr.Ns = strings.ToLower(rr.Ns)
+		rr.Mbox = strings.ToLower(rr.Mbox)
+	case *dns.MB:
+		rr.Mb = strings.ToLower(rr.Mb)
+	case *dns.MG:
+		rr.Mg = strings.ToLower(rr.Mg)
+	case *dns.MR:
+		rr.Mr = strings.ToLower(rr.Mr)
+	case *dns.PTR:
+		rr.Ptr = strings.ToLower(rr.Ptr)
+	case *dns.MINFO:
+		rr.Rmail = strings.ToLower(rr.Rmail)
+		rr.Email = strings.ToLower(rr.Email)
+	case *dns.MX:
+		rr.Mx = strings.ToLower(rr.Mx)
+	case *dns.RP:
+		rr.Mbox = strings.ToLower(rr.Mbox)
+		rr.Txt = strings.ToLower(rr.Txt)
+	case *dns.AFSDB:
+		rr.Hostname = strings.ToLower(rr.Hostname)
+	case *dns.RT:
+		rr.Host = strings.ToLower(rr.Host)
+	case *dns.SIG:
+		rr.SignerName = strings.ToLower(rr.SignerName)
+	case *dns.PX:
+		rr.Map822 = strings.ToLower(rr.Map822)
+		rr.Mapx400 = strings.ToLower(rr.Mapx400)
+	case *dns.NAPTR:
+		rr.Replacement = strings.ToLower(rr.Replacement)
+	case *dns.KX:
+		rr.Exchanger = strings.ToLower(rr.Exchanger)
+	case *dns.SRV:
+		rr.Target = strings.ToLower(rr.Target)
+	case *dns.DNAME:
+		rr.Target = strings.ToLower(rr.Target)
+	case *dns.RRSIG:
+		rr.SignerName = strings.ToLower(rr.SignerName)
+	}
+}
+
+// wireLabels returns the labels of the uncompressed domain name in the wire
+// format.  The root label is not included.
+func wireLabels(name []byte) (labels [][]byte) {
+	for len(name) > 0 && name[0] != 0 {
+		l := int(name[0])
+		labels = append(labels, name[1:1+l])
+		name = name[1+l:]
+	}
+
+	return labels
+}
+
+// compareCanonicalRRs compares a and b by their owner names in the canonical
+// order defined by RFC 4034 Section 6.1, then by their types, and then by
+// their RDATA.
+func compareCanonicalRRs(a, b *canonicalRR) (res int) {
+	for i, j := len(a.labels)-1, len(b.labels)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+		res = bytes.Compare(a.labels[i], b.labels[j])
+		if res != 0 {
+			return res
+		}
+	}
+
+	return cmp.Or(
+		cmp.Compare(len(a.labels), len(b.labels)),
+		cmp.Compare(a.rrType, b.rrType),
+		bytes.Compare(a.rdata, b.rdata),
+	)
+}
@@ -2,6 +2,7 @@ package tlsconfig
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"slices"
 
 	"github.com/AdguardTeam/golibs/errors"
@@ -47,22 +48,60 @@ func (s *certStorage) count() (n int) {
 	return len(s.certs)
 }
 
-// certFor returns the TLS certificate for chi.  chi must not be nil.  cert must
-// not be modified.
+// certFor returns the TLS certificate for chi.  If several certificates are
+// supported by the client, the one with the most preferred public key algorithm
+// is returned, see [keyAlgoRank].  chi must not be nil.  cert must not be
+// modified.
 func (s *certStorage) certFor(chi *tls.ClientHelloInfo) (cert *tls.Certificate, err error) {
 	var errs []error
+	bestRank := -1
 	for _, c := range s.certs {
 		err = chi.SupportsCertificate(c)
-		if err == nil {
-			return c, nil
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		rank := keyAlgoRank(c)
+		if cert == nil || rank < bestRank {
+			cert, bestRank = c, rank
 		}
+	}
 
-		errs = append(errs, err)
+	if cert != nil {
+		return cert, nil
 	}
 
 	return nil, errors.Join(errs...)
 }
 
+// keyAlgoRank returns the rank of the public key algorithm of cert.  Lower
+// ranks are preferred.  ECDSA is preferred to Ed25519, since it's supported by
+// more clients, and both are preferred to RSA, which is only needed for older
+// clients.  cert must not be nil.
+func keyAlgoRank(cert *tls.Certificate) (rank int) {
+	switch keyAlgo(cert) {
+	case x509.ECDSA:
+		return 0
+	case x509.Ed25519:
+		return 1
+	case x509.RSA:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// keyAlgo returns the public key algorithm of cert.  cert must not be nil.
+func keyAlgo(cert *tls.Certificate) (algo x509.PublicKeyAlgorithm) {
+	if cert.Leaf == nil {
+		return x509.UnknownPublicKeyAlgorithm
+	}
+
+	return cert.Leaf.PublicKeyAlgorithm
+}
+
 // rangeFn calls fn for each stored TLS certificate and its paths.  fn must not
 // be nil.  Neither cert nor cp must be modified.
 func (s *certStorage) rangeFn(fn func(cert *tls.Certificate, cp *certPaths) (cont bool)) {
@@ -1,6 +1,10 @@
 package tlsconfig
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"testing"
@@ -90,3 +94,75 @@ func TestCertStorage(t *testing.T) {
 		return true
 	})
 }
+
+func TestCertStorage_certFor_keyAlgo(t *testing.T) {
+	t.Parallel()
+
+	const domain = "a.com"
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	certRSA := &tls.Certificate{
+		PrivateKey: rsaKey,
+		Leaf: &x509.Certificate{
+			DNSNames:           []string{domain},
+			PublicKeyAlgorithm: x509.RSA,
+		},
+	}
+
+	certECDSA := &tls.Certificate{
+		PrivateKey: ecdsaKey,
+		Leaf: &x509.Certificate{
+			DNSNames:           []string{domain},
+			PublicKeyAlgorithm: x509.ECDSA,
+		},
+	}
+
+	s := &certStorage{}
+	s.add(certRSA, &certPaths{certPath: "rsa_cert", keyPath: "rsa_key"})
+	s.add(certECDSA, &certPaths{certPath: "ecdsa_cert", keyPath: "ecdsa_key"})
+
+	testCases := []struct {
+		want    *tls.Certificate
+		name    string
+		schemes []tls.SignatureScheme
+	}{{
+		want:    certECDSA,
+		name:    "modern",
+		schemes: []tls.SignatureScheme{tls.PSSWithSHA256, tls.ECDSAWithP256AndSHA256},
+	}, {
+		want:    certRSA,
+		name:    "rsa_only",
+		schemes: []tls.SignatureScheme{tls.PSSWithSHA256},
+	}, {
+		want:    certECDSA,
+		name:    "ecdsa_only",
+		schemes: []tls.SignatureScheme{tls.ECDSAWithP256AndSHA256},
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, certErr := s.certFor(&tls.ClientHelloInfo{
+				ServerName:        domain,
+				SupportedVersions: []uint16{tls.VersionTLS13},
+				SignatureSchemes:  tc.schemes,
+			})
+			require.NoError(t, certErr)
+
+			assert.Same(t, tc.want, got)
+		})
+	}
+
+	_, err = s.certFor(&tls.ClientHelloInfo{
+		ServerName:        domain,
+		SupportedVersions: []uint16{tls.VersionTLS13},
+		SignatureSchemes:  []tls.SignatureScheme{tls.Ed25519},
+	})
+	assert.Error(t, err)
+}
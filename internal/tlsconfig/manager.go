@@ -2,15 +2,19 @@ package tlsconfig
 
 import (
 	"context"
+	"crypto/sha256"
 	"crypto/tls"
+	"encoding/binary"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/auditlog"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
 	"github.com/AdguardTeam/golibs/errors"
 )
@@ -35,6 +39,10 @@ type DefaultManagerConfig struct {
 	// Logger is used for logging the operation of the TLS manager.
 	Logger *slog.Logger
 
+	// AuditLog is used to record the certificate changes and the session
+	// ticket rotations.
+	AuditLog auditlog.Interface
+
 	// ErrColl is used to collect TLS-related errors.
 	ErrColl errcoll.Interface
 
@@ -51,9 +59,10 @@ type DefaultManagerConfig struct {
 // DefaultManager is the default implementation of [Manager].
 type DefaultManager struct {
 	// mu protects fields certStorage, clones, clonesWithMetrics,
-	// sessTicketPaths.
+	// sessTicketPaths, sessTicketsInfo.
 	mu                *sync.Mutex
 	logger            *slog.Logger
+	auditLog          auditlog.Interface
 	errColl           errcoll.Interface
 	metrics           Metrics
 	certStorage       *certStorage
@@ -61,6 +70,7 @@ type DefaultManager struct {
 	clones            []*tls.Config
 	clonesWithMetrics []*tls.Config
 	sessTicketPaths   []string
+	sessTicketsInfo   SessionTicketsInfo
 }
 
 // NewDefaultManager returns a new initialized *DefaultManager.
@@ -77,6 +87,7 @@ func NewDefaultManager(conf *DefaultManagerConfig) (m *DefaultManager, err error
 	m = &DefaultManager{
 		mu:              &sync.Mutex{},
 		logger:          conf.Logger,
+		auditLog:        conf.AuditLog,
 		errColl:         conf.ErrColl,
 		metrics:         conf.Metrics,
 		certStorage:     &certStorage{},
@@ -172,7 +183,15 @@ func (m *DefaultManager) getCertificate(chi *tls.ClientHelloInfo) (c *tls.Certif
 		return nil, errors.Error("no certificates")
 	}
 
-	return m.certStorage.certFor(chi)
+	c, err = m.certStorage.certFor(chi)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+
+	m.metrics.IncrementCertificateChoices(chi.Context(), keyAlgo(c).String())
+
+	return c, nil
 }
 
 // CloneWithMetrics implements the [Manager] interface for *DefaultManager.
@@ -230,6 +249,13 @@ func (m *DefaultManager) Refresh(ctx context.Context) (err error) {
 
 		if m.certStorage.update(cp, cert) {
 			m.logger.InfoContext(ctx, "refreshed certificate", "cert", cp.certPath, "key", cp.keyPath)
+			m.auditLog.Record(ctx, &auditlog.Event{
+				Data: &auditlog.TLSCertificate{
+					CertPath: cp.certPath,
+					KeyPath:  cp.keyPath,
+				},
+				Type: auditlog.EventTypeTLSCertificate,
+			})
 		} else {
 			m.logger.WarnContext(ctx, "certificate did not refresh", "cert", cp.certPath, "key", cp.keyPath)
 		}
@@ -275,6 +301,7 @@ func (m *DefaultManager) RotateTickets(ctx context.Context) (err error) {
 	}()
 
 	tickets := make([]sessionTicket, 0, len(files))
+	hash := sha256.New()
 	for _, fileName := range files {
 		var ticket sessionTicket
 		ticket, err = readSessionTicketKey(fileName)
@@ -283,8 +310,13 @@ func (m *DefaultManager) RotateTickets(ctx context.Context) (err error) {
 		}
 
 		tickets = append(tickets, ticket)
+
+		// Don't check the error, since [hash.Hash.Write] never returns one.
+		_, _ = hash.Write(ticket[:])
 	}
 
+	keysHash := binary.BigEndian.Uint32(hash.Sum(nil))
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -296,18 +328,63 @@ func (m *DefaultManager) RotateTickets(ctx context.Context) (err error) {
 		conf.SetSessionTicketKeys(tickets)
 	}
 
+	prevHash := m.sessTicketsInfo.KeysHash
+	m.sessTicketsInfo = SessionTicketsInfo{
+		RotatedAt: time.Now(),
+		NumKeys:   len(tickets),
+		KeysHash:  keysHash,
+	}
+
 	m.logger.InfoContext(
 		ctx,
 		"ticket rotation successful",
 		"num_configs", m.certStorage.count(),
 		"num_tickets", len(tickets),
+		"keys_hash", keysHash,
+		"keys_changed", prevHash != keysHash,
 	)
 
+	m.auditLog.Record(ctx, &auditlog.Event{
+		Data: &auditlog.TLSSessionTickets{
+			KeysNum:     len(tickets),
+			KeysHash:    keysHash,
+			KeysChanged: prevHash != keysHash,
+		},
+		Type: auditlog.EventTypeTLSSessionTickets,
+	})
+
 	m.metrics.SetSessionTicketRotationStatus(ctx, true)
+	m.metrics.SetSessionTicketKeysHash(ctx, keysHash)
 
 	return nil
 }
 
+// SessionTicketsInfo is the information about the TLS session ticket keys
+// currently used by a [DefaultManager].  Comparing it between the nodes of a
+// server group allows detecting the nodes that failed to pick up the new keys
+// after a rotation, which breaks TLS session resumption.
+type SessionTicketsInfo struct {
+	// RotatedAt is the time of the last successful rotation.  It is zero if
+	// there were none.
+	RotatedAt time.Time
+
+	// NumKeys is the number of the session ticket keys in use.
+	NumKeys int
+
+	// KeysHash is the first four bytes of the SHA-256 hash of the session
+	// ticket keys in use, in the order of their priority.
+	KeysHash uint32
+}
+
+// SessionTicketsInfo returns the information about the current TLS session
+// ticket keys.
+func (m *DefaultManager) SessionTicketsInfo() (info SessionTicketsInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.sessTicketsInfo
+}
+
 // readSessionTicketKey reads a single TLS session ticket from a file.
 func readSessionTicketKey(fn string) (ticket sessionTicket, err error) {
 	// #nosec G304 -- Trust the file paths that are given to us in the
@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/auditlog"
 	"github.com/AdguardTeam/AdGuardDNS/internal/tlsconfig"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"github.com/AdguardTeam/golibs/testutil"
@@ -120,9 +121,10 @@ func TestDefaultManager_Refresh(t *testing.T) {
 	)
 
 	m, err := tlsconfig.NewDefaultManager(&tlsconfig.DefaultManagerConfig{
-		Logger:  slogutil.NewDiscardLogger(),
-		ErrColl: agdtest.NewErrorCollector(),
-		Metrics: tlsconfig.EmptyMetrics{},
+		Logger:   slogutil.NewDiscardLogger(),
+		AuditLog: auditlog.Empty{},
+		ErrColl:  agdtest.NewErrorCollector(),
+		Metrics:  tlsconfig.EmptyMetrics{},
 	})
 	require.NoError(t, err)
 
@@ -163,6 +165,7 @@ func TestDefaultManager_RotateTickets(t *testing.T) {
 
 	m, err := tlsconfig.NewDefaultManager(&tlsconfig.DefaultManagerConfig{
 		Logger:             slogutil.NewDiscardLogger(),
+		AuditLog:           auditlog.Empty{},
 		ErrColl:            agdtest.NewErrorCollector(),
 		Metrics:            tlsconfig.EmptyMetrics{},
 		SessionTicketPaths: []string{sessKeyPath},
@@ -180,8 +183,25 @@ func TestDefaultManager_RotateTickets(t *testing.T) {
 	err = m.Add(ctx, certPath, keyPath)
 	require.NoError(t, err)
 
+	assert.Zero(t, m.SessionTicketsInfo())
+
+	err = m.RotateTickets(ctx)
+	require.NoError(t, err)
+
+	info := m.SessionTicketsInfo()
+	assert.Equal(t, 1, info.NumKeys)
+	assert.False(t, info.RotatedAt.IsZero())
+
 	err = m.RotateTickets(ctx)
 	require.NoError(t, err)
 
+	assert.Equal(t, info.KeysHash, m.SessionTicketsInfo().KeysHash)
+
+	writeSessionKey(t, sessKeyPath)
+	err = m.RotateTickets(ctx)
+	require.NoError(t, err)
+
+	assert.NotEqual(t, info.KeysHash, m.SessionTicketsInfo().KeysHash)
+
 	// TODO(s.chzhen):  Find a way to test session ticket changes.
 }
@@ -25,9 +25,22 @@ type Metrics interface {
 	// SetCertificateInfo sets the TLS certificate information.
 	SetCertificateInfo(ctx context.Context, algo, subj string, notAfter time.Time)
 
+	// IncrementCertificateChoices increments the number of handshakes, in
+	// which the certificate with the public key algorithm algo has been chosen.
+	IncrementCertificateChoices(ctx context.Context, algo string)
+
 	// SetSessionTicketRotationStatus sets the TLS session ticket rotation
 	// status.
 	SetSessionTicketRotationStatus(ctx context.Context, enabled bool)
+
+	// SetSessionTicketKeysHash sets the hash of the TLS session ticket keys in
+	// use.  See [SessionTicketsInfo.KeysHash].
+	SetSessionTicketKeysHash(ctx context.Context, hash uint32)
+
+	// SetSessionTicketDivergence sets the duration for which the TLS session
+	// ticket keys in use have differed from the ones of the other nodes.  d is
+	// zero if they are the same.
+	SetSessionTicketDivergence(ctx context.Context, d time.Duration)
 }
 
 // EmptyMetrics is the implementation of the [Metrics] interface that does
@@ -63,6 +76,17 @@ func (EmptyMetrics) AfterHandshake(
 // SetCertificateInfo implements the [Metrics] interface for EmptyMetrics.
 func (EmptyMetrics) SetCertificateInfo(_ context.Context, _, _ string, _ time.Time) {}
 
+// IncrementCertificateChoices implements the [Metrics] interface for
+// EmptyMetrics.
+func (EmptyMetrics) IncrementCertificateChoices(_ context.Context, _ string) {}
+
 // SetSessionTicketRotationStatus implements the [Metrics] interface for
 // EmptyMetrics.
 func (EmptyMetrics) SetSessionTicketRotationStatus(_ context.Context, _ bool) {}
+
+// SetSessionTicketKeysHash implements the [Metrics] interface for EmptyMetrics.
+func (EmptyMetrics) SetSessionTicketKeysHash(_ context.Context, _ uint32) {}
+
+// SetSessionTicketDivergence implements the [Metrics] interface for
+// EmptyMetrics.
+func (EmptyMetrics) SetSessionTicketDivergence(_ context.Context, _ time.Duration) {}
@@ -0,0 +1,196 @@
+package tlsconfig
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/remotekv"
+)
+
+// TicketSyncConfig is the configuration structure for a *TicketSync.
+type TicketSyncConfig struct {
+	// Logger is used to log the operation of the synchronizer.  It must not be
+	// nil.
+	Logger *slog.Logger
+
+	// Clock is used to measure the duration of the divergence.  It must not be
+	// nil.
+	Clock agdtime.Clock
+
+	// Manager is the manager, the session ticket keys of which are checked.
+	// It must not be nil.
+	Manager *DefaultManager
+
+	// Metrics is used to report the divergence.  It must not be nil.
+	Metrics Metrics
+
+	// KV is the storage shared by the nodes, through which they exchange the
+	// hashes of their keys.  It must not be nil.
+	KV remotekv.Interface
+
+	// Key is the key of the hash in KV.  The nodes that must use the same
+	// session ticket keys must use the same key.  It must not be empty.
+	Key string
+}
+
+// TicketSync detects the nodes which session ticket keys differ from the ones
+// of the other nodes and makes them reread the keys.
+//
+// When a node picks up new keys, it stores their hash in the shared storage.
+// Otherwise, it compares the stored hash to the hash of its own keys and, if
+// they differ, rereads the keys and reports the duration of the divergence.
+type TicketSync struct {
+	logger  *slog.Logger
+	clock   agdtime.Clock
+	manager *DefaultManager
+	metrics Metrics
+	kv      remotekv.Interface
+	key     string
+
+	// mu protects the fields below.
+	mu *sync.Mutex
+
+	// divergedSince is the time when the divergence was first detected.  It
+	// is zero if the keys are the same.
+	divergedSince time.Time
+
+	// lastHash is the hash of the keys seen during the previous refresh.  It
+	// is only valid if hasLastHash is true.
+	lastHash    uint32
+	hasLastHash bool
+}
+
+// NewTicketSync returns a new properly initialized *TicketSync.  c must not be
+// nil.
+func NewTicketSync(c *TicketSyncConfig) (s *TicketSync) {
+	return &TicketSync{
+		logger:  c.Logger,
+		clock:   c.Clock,
+		manager: c.Manager,
+		metrics: c.Metrics,
+		kv:      c.KV,
+		key:     c.Key,
+		mu:      &sync.Mutex{},
+	}
+}
+
+// type check
+var _ agdservice.Refresher = (*TicketSync)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for *TicketSync.  It
+// should be called after the rotations of the session tickets.
+func (s *TicketSync) Refresh(ctx context.Context) (err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info := s.manager.SessionTicketsInfo()
+	if info.RotatedAt.IsZero() {
+		// There are no keys to compare yet.
+		return nil
+	}
+
+	local := info.KeysHash
+
+	// Don't store the hash seen on start, since it can be the stale one.
+	changed := s.hasLastHash && local != s.lastHash
+	s.lastHash, s.hasLastHash = local, true
+
+	val, ok, err := s.kv.Get(ctx, s.key)
+	if err != nil {
+		return fmt.Errorf("getting keys hash: %w", err)
+	}
+
+	remote, parseErr := parseKeysHash(val)
+	if changed || !ok || parseErr != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return s.store(ctx, local)
+	} else if remote == local {
+		s.setConverged(ctx)
+
+		return nil
+	}
+
+	err = s.manager.RotateTickets(ctx)
+	if err != nil {
+		return fmt.Errorf("rereading keys: %w", err)
+	}
+
+	reread := s.manager.SessionTicketsInfo().KeysHash
+	s.lastHash = reread
+
+	switch reread {
+	case remote:
+		s.logger.InfoContext(ctx, "keys converged after rereading", "keys_hash", reread)
+		s.setConverged(ctx)
+
+		return nil
+	case local:
+		s.setDiverged(ctx, local, remote)
+
+		return nil
+	default:
+		// Don't wrap the error, because it's informative enough as is.
+		return s.store(ctx, reread)
+	}
+}
+
+// store stores hash in the shared storage and resets the divergence.
+func (s *TicketSync) store(ctx context.Context, hash uint32) (err error) {
+	err = s.kv.Set(ctx, s.key, []byte(formatKeysHash(hash)))
+	if err != nil {
+		return fmt.Errorf("setting keys hash: %w", err)
+	}
+
+	s.setConverged(ctx)
+
+	return nil
+}
+
+// setConverged resets the divergence.
+func (s *TicketSync) setConverged(ctx context.Context) {
+	s.divergedSince = time.Time{}
+	s.metrics.SetSessionTicketDivergence(ctx, 0)
+}
+
+// setDiverged reports the divergence of the local keys from the remote ones.
+func (s *TicketSync) setDiverged(ctx context.Context, local, remote uint32) {
+	now := s.clock.Now()
+	if s.divergedSince.IsZero() {
+		s.divergedSince = now
+	}
+
+	d := now.Sub(s.divergedSince)
+	s.metrics.SetSessionTicketDivergence(ctx, d)
+
+	s.logger.WarnContext(
+		ctx,
+		"keys diverged",
+		"keys_hash", formatKeysHash(local),
+		"remote_keys_hash", formatKeysHash(remote),
+		"duration", d,
+	)
+}
+
+// formatKeysHash returns the string representation of the hash of the session
+// ticket keys.
+func formatKeysHash(hash uint32) (s string) {
+	return fmt.Sprintf("%08x", hash)
+}
+
+// parseKeysHash parses the hash of the session ticket keys formatted with
+// [formatKeysHash].
+func parseKeysHash(b []byte) (hash uint32, err error) {
+	u, err := strconv.ParseUint(string(b), 16, 32)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return 0, err
+	}
+
+	return uint32(u), nil
+}
@@ -0,0 +1,140 @@
+package tlsconfig_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/auditlog"
+	"github.com/AdguardTeam/AdGuardDNS/internal/remotekv"
+	"github.com/AdguardTeam/AdGuardDNS/internal/tlsconfig"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// divergenceMetrics is a [tlsconfig.Metrics] that records the last reported
+// divergence.
+type divergenceMetrics struct {
+	tlsconfig.EmptyMetrics
+
+	divergence time.Duration
+}
+
+// SetSessionTicketDivergence implements the [tlsconfig.Metrics] interface for
+// *divergenceMetrics.
+func (m *divergenceMetrics) SetSessionTicketDivergence(_ context.Context, d time.Duration) {
+	m.divergence = d
+}
+
+// newTicketSync is a helper that returns a new rotated manager that reads the
+// session ticket key from sessKeyPath and a ticket sync for it.
+func newTicketSync(
+	tb testing.TB,
+	kv remotekv.Interface,
+	clock *agdtest.Clock,
+	mtrc tlsconfig.Metrics,
+	sessKeyPath string,
+) (m *tlsconfig.DefaultManager, s *tlsconfig.TicketSync) {
+	tb.Helper()
+
+	m, err := tlsconfig.NewDefaultManager(&tlsconfig.DefaultManagerConfig{
+		Logger:             slogutil.NewDiscardLogger(),
+		AuditLog:           auditlog.Empty{},
+		ErrColl:            agdtest.NewErrorCollector(),
+		Metrics:            tlsconfig.EmptyMetrics{},
+		SessionTicketPaths: []string{sessKeyPath},
+	})
+	require.NoError(tb, err)
+
+	err = m.RotateTickets(testutil.ContextWithTimeout(tb, testTimeout))
+	require.NoError(tb, err)
+
+	s = tlsconfig.NewTicketSync(&tlsconfig.TicketSyncConfig{
+		Logger:  slogutil.NewDiscardLogger(),
+		Clock:   clock,
+		Manager: m,
+		Metrics: mtrc,
+		KV:      kv,
+		Key:     "test",
+	})
+
+	return m, s
+}
+
+func TestTicketSync_Refresh(t *testing.T) {
+	t.Parallel()
+
+	kv := remotekv.NewCache(&remotekv.CacheConfig{
+		Cache: agdcache.NewLRU[string, []byte](&agdcache.LRUConfig{
+			Count: 10,
+		}),
+	})
+
+	now := time.Now()
+	clock := &agdtest.Clock{
+		OnNow: func() (n time.Time) { return now },
+	}
+
+	tmpDir := t.TempDir()
+	pathA := filepath.Join(tmpDir, "a.key")
+	pathB := filepath.Join(tmpDir, "b.key")
+	writeSessionKey(t, pathA)
+	writeSessionKey(t, pathB)
+
+	mtrcA, mtrcB := &divergenceMetrics{}, &divergenceMetrics{}
+	mgrA, syncA := newTicketSync(t, kv, clock, mtrcA, pathA)
+	mgrB, syncB := newTicketSync(t, kv, clock, mtrcB, pathB)
+
+	require.NotEqual(t, mgrA.SessionTicketsInfo().KeysHash, mgrB.SessionTicketsInfo().KeysHash)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+
+	require.NoError(t, syncA.Refresh(ctx))
+	assert.Zero(t, mtrcA.divergence)
+
+	require.NoError(t, syncB.Refresh(ctx))
+	require.NoError(t, syncB.Refresh(ctx))
+	assert.Zero(t, mtrcB.divergence)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, syncB.Refresh(ctx))
+	assert.Equal(t, time.Minute, mtrcB.divergence)
+
+	t.Run("converged", func(t *testing.T) {
+		keyA, err := os.ReadFile(pathA)
+		require.NoError(t, err)
+
+		err = os.WriteFile(pathB, keyA, 0o600)
+		require.NoError(t, err)
+
+		require.NoError(t, syncB.Refresh(ctx))
+		assert.Zero(t, mtrcB.divergence)
+		assert.Equal(t, mgrA.SessionTicketsInfo().KeysHash, mgrB.SessionTicketsInfo().KeysHash)
+	})
+
+	t.Run("rotated", func(t *testing.T) {
+		writeSessionKey(t, pathA)
+		require.NoError(t, mgrA.RotateTickets(ctx))
+
+		newHash := mgrA.SessionTicketsInfo().KeysHash
+
+		require.NoError(t, syncA.Refresh(ctx))
+		assert.Zero(t, mtrcA.divergence)
+
+		keyA, err := os.ReadFile(pathA)
+		require.NoError(t, err)
+
+		err = os.WriteFile(pathB, keyA, 0o600)
+		require.NoError(t, err)
+
+		require.NoError(t, syncB.Refresh(ctx))
+		assert.Zero(t, mtrcB.divergence)
+		assert.Equal(t, newHash, mgrB.SessionTicketsInfo().KeysHash)
+	})
+}
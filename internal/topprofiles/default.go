@@ -0,0 +1,184 @@
+package topprofiles
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+)
+
+// DefaultConfig is the configuration structure for a *Default.
+type DefaultConfig struct {
+	// Clock is used to get the boundaries of the windows.  It must not be nil.
+	Clock agdtime.Clock
+
+	// Size is the maximum number of profiles in each of the top lists.  It
+	// must be positive.
+	Size int
+}
+
+// Default is the default [Interface] implementation.  It uses count-min
+// sketches and min-heaps, so its memory usage doesn't depend on the number of
+// profiles.  The statistics are collected within windows, which are closed by
+// calling Refresh.
+type Default struct {
+	clock agdtime.Clock
+
+	// mu protects the fields below.
+	mu          *sync.Mutex
+	requests    *tracker
+	errors      *tracker
+	blocked     *tracker
+	windowStart time.Time
+	snapshot    *Snapshot
+}
+
+// NewDefault returns a new properly initialized *Default.  c must be valid.
+func NewDefault(c *DefaultConfig) (d *Default) {
+	return &Default{
+		clock:       c.Clock,
+		mu:          &sync.Mutex{},
+		requests:    newTracker(c.Size),
+		errors:      newTracker(c.Size),
+		blocked:     newTracker(c.Size),
+		windowStart: c.Clock.Now(),
+	}
+}
+
+// type check
+var _ Interface = (*Default)(nil)
+
+// Record implements the [Interface] interface for *Default.
+func (d *Default) Record(_ context.Context, id agd.ProfileID, isBlocked, isError bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.requests.add(id)
+	if isBlocked {
+		d.blocked.add(id)
+	}
+
+	if isError {
+		d.errors.add(id)
+	}
+}
+
+// type check
+var _ agdservice.Refresher = (*Default)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for *Default.  It
+// closes the current window, making its statistics available through
+// [Default.Snapshot], and starts a new one.
+func (d *Default) Refresh(_ context.Context) (err error) {
+	now := d.clock.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.snapshot = d.newSnapshot(now)
+
+	d.requests.reset()
+	d.errors.reset()
+	d.blocked.reset()
+	d.windowStart = now
+
+	return nil
+}
+
+// newSnapshot returns the statistics of the current window ending at end.
+// d.mu must be locked.
+func (d *Default) newSnapshot(end time.Time) (s *Snapshot) {
+	s = &Snapshot{
+		Start: d.windowStart,
+		End:   end,
+	}
+
+	secs := end.Sub(d.windowStart).Seconds()
+	for _, item := range d.requests.top() {
+		var rate float64
+		if secs > 0 {
+			rate = float64(item.count) / secs
+		}
+
+		s.Requests = append(s.Requests, &ProfileCount{
+			ProfileID: item.id,
+			Count:     item.count,
+			Rate:      rate,
+		})
+	}
+
+	s.Errors = d.ratioCounts(d.errors)
+	s.Blocked = d.ratioCounts(d.blocked)
+
+	return s
+}
+
+// ratioCounts returns the top profiles of t with the rates being the ratios to
+// the estimated numbers of their requests.  d.mu must be locked.
+func (d *Default) ratioCounts(t *tracker) (counts []*ProfileCount) {
+	for _, item := range t.top() {
+		var rate float64
+		if total := d.requests.sketch.estimate(item.id); total > 0 {
+			// Both counts are overestimated independently, so make sure that
+			// the ratio makes sense.
+			rate = min(float64(item.count)/float64(total), 1)
+		}
+
+		counts = append(counts, &ProfileCount{
+			ProfileID: item.id,
+			Count:     item.count,
+			Rate:      rate,
+		})
+	}
+
+	return counts
+}
+
+// Snapshot returns the statistics of the last closed window.  s is nil if no
+// window has been closed yet.  s must not be modified.
+func (d *Default) Snapshot() (s *Snapshot) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.snapshot
+}
+
+// Snapshot is the statistics of a single window.
+type Snapshot struct {
+	// Start is the start of the window.
+	Start time.Time
+
+	// End is the end of the window.
+	End time.Time
+
+	// Requests are the profiles with the most requests sorted by the count in
+	// descending order.  The rates are the numbers of requests per second.
+	Requests []*ProfileCount
+
+	// Errors are the profiles with the most failed requests sorted by the
+	// count in descending order.  The rates are the ratios of the failed
+	// requests to all requests of the profile.
+	Errors []*ProfileCount
+
+	// Blocked are the profiles with the most blocked requests sorted by the
+	// count in descending order.  The rates are the ratios of the blocked
+	// requests to all requests of the profile.
+	Blocked []*ProfileCount
+}
+
+// ProfileCount is the estimated statistics of a single profile.
+type ProfileCount struct {
+	// ProfileID is the ID of the profile.
+	ProfileID agd.ProfileID
+
+	// Count is the estimated number of events.  It may be overestimated, but
+	// is never underestimated.
+	Count uint64
+
+	// Rate is either the number of events per second or the ratio of the
+	// events to all requests, depending on the list.
+	Rate float64
+}
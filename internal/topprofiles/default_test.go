@@ -0,0 +1,87 @@
+package topprofiles_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/topprofiles"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// Profile IDs for tests.
+const (
+	testProfileID      agd.ProfileID = "prof1234"
+	testProfileIDOther agd.ProfileID = "prof5678"
+)
+
+func TestDefault(t *testing.T) {
+	t.Parallel()
+
+	start := time.Unix(0, 0)
+	now := start
+	clock := &agdtest.Clock{
+		OnNow: func() (n time.Time) { return now },
+	}
+
+	d := topprofiles.NewDefault(&topprofiles.DefaultConfig{
+		Clock: clock,
+		Size:  1,
+	})
+
+	assert.Nil(t, d.Snapshot())
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	for range 10 {
+		d.Record(ctx, testProfileID, false, false)
+	}
+
+	for range 4 {
+		d.Record(ctx, testProfileIDOther, true, false)
+	}
+
+	d.Record(ctx, testProfileIDOther, false, true)
+
+	now = start.Add(10 * time.Second)
+	require.NoError(t, d.Refresh(ctx))
+
+	s := d.Snapshot()
+	require.NotNil(t, s)
+
+	assert.Equal(t, start, s.Start)
+	assert.Equal(t, now, s.End)
+
+	assert.Equal(t, []*topprofiles.ProfileCount{{
+		ProfileID: testProfileID,
+		Count:     10,
+		Rate:      1,
+	}}, s.Requests)
+
+	assert.Equal(t, []*topprofiles.ProfileCount{{
+		ProfileID: testProfileIDOther,
+		Count:     4,
+		Rate:      0.8,
+	}}, s.Blocked)
+
+	assert.Equal(t, []*topprofiles.ProfileCount{{
+		ProfileID: testProfileIDOther,
+		Count:     1,
+		Rate:      0.2,
+	}}, s.Errors)
+
+	now = start.Add(20 * time.Second)
+	require.NoError(t, d.Refresh(ctx))
+
+	s = d.Snapshot()
+	require.NotNil(t, s)
+
+	assert.Empty(t, s.Requests)
+	assert.Empty(t, s.Blocked)
+	assert.Empty(t, s.Errors)
+}
@@ -0,0 +1,176 @@
+package topprofiles
+
+import (
+	"cmp"
+	"container/heap"
+	"hash/maphash"
+	"slices"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// Count-min sketch parameters.  With these, the overestimation of a count is
+// at most about 0.1% of the total count with the probability of about 98%.
+const (
+	sketchDepth = 4
+	sketchWidth = 2048
+)
+
+// sketch is a count-min sketch of the number of events per profile.  See
+// https://en.wikipedia.org/wiki/Count%E2%80%93min_sketch.
+type sketch struct {
+	seeds  [sketchDepth]maphash.Seed
+	counts [sketchDepth][sketchWidth]uint64
+}
+
+// newSketch returns a new properly initialized *sketch.
+func newSketch() (s *sketch) {
+	s = &sketch{}
+	for i := range s.seeds {
+		s.seeds[i] = maphash.MakeSeed()
+	}
+
+	return s
+}
+
+// add increments the count of id and returns its new estimate.
+func (s *sketch) add(id agd.ProfileID) (est uint64) {
+	for i, seed := range s.seeds {
+		c := &s.counts[i][maphash.String(seed, string(id))%sketchWidth]
+		*c++
+
+		if i == 0 || *c < est {
+			est = *c
+		}
+	}
+
+	return est
+}
+
+// estimate returns the estimated count of id.
+func (s *sketch) estimate(id agd.ProfileID) (est uint64) {
+	for i, seed := range s.seeds {
+		c := s.counts[i][maphash.String(seed, string(id))%sketchWidth]
+		if i == 0 || c < est {
+			est = c
+		}
+	}
+
+	return est
+}
+
+// reset sets all counts to zero.
+func (s *sketch) reset() {
+	s.counts = [sketchDepth][sketchWidth]uint64{}
+}
+
+// topItem is an item of a [topHeap].
+type topItem struct {
+	id    agd.ProfileID
+	count uint64
+	idx   int
+}
+
+// topHeap is a min-heap of the items by their counts.  It implements
+// [heap.Interface].
+type topHeap []*topItem
+
+// type check
+var _ heap.Interface = (*topHeap)(nil)
+
+// Len implements the [heap.Interface] interface for *topHeap.
+func (h *topHeap) Len() (n int) { return len(*h) }
+
+// Less implements the [heap.Interface] interface for *topHeap.
+func (h *topHeap) Less(i, j int) (ok bool) { return (*h)[i].count < (*h)[j].count }
+
+// Swap implements the [heap.Interface] interface for *topHeap.
+func (h *topHeap) Swap(i, j int) {
+	(*h)[i], (*h)[j] = (*h)[j], (*h)[i]
+	(*h)[i].idx = i
+	(*h)[j].idx = j
+}
+
+// Push implements the [heap.Interface] interface for *topHeap.  x must be a
+// *topItem.
+func (h *topHeap) Push(x any) {
+	item := x.(*topItem)
+	item.idx = len(*h)
+	*h = append(*h, item)
+}
+
+// Pop implements the [heap.Interface] interface for *topHeap.
+func (h *topHeap) Pop() (x any) {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+
+	return item
+}
+
+// tracker tracks the profiles with the highest counts of events.
+type tracker struct {
+	sketch *sketch
+	heap   *topHeap
+	items  map[agd.ProfileID]*topItem
+	size   int
+}
+
+// newTracker returns a new properly initialized *tracker that tracks at most
+// size profiles.  size must be positive.
+func newTracker(size int) (t *tracker) {
+	return &tracker{
+		sketch: newSketch(),
+		heap:   &topHeap{},
+		items:  make(map[agd.ProfileID]*topItem, size),
+		size:   size,
+	}
+}
+
+// add records an event for id.
+func (t *tracker) add(id agd.ProfileID) {
+	est := t.sketch.add(id)
+
+	if item, ok := t.items[id]; ok {
+		item.count = est
+		heap.Fix(t.heap, item.idx)
+
+		return
+	}
+
+	if t.heap.Len() >= t.size {
+		if (*t.heap)[0].count >= est {
+			return
+		}
+
+		removed := heap.Pop(t.heap).(*topItem)
+		delete(t.items, removed.id)
+	}
+
+	item := &topItem{
+		id:    id,
+		count: est,
+	}
+	heap.Push(t.heap, item)
+	t.items[id] = item
+}
+
+// top returns the tracked items sorted by their counts in descending order.
+func (t *tracker) top() (items []*topItem) {
+	items = slices.Clone(*t.heap)
+	slices.SortFunc(items, func(a, b *topItem) (res int) {
+		// Sort in descending order by count and then by ID for stability.
+		return cmp.Or(cmp.Compare(b.count, a.count), cmp.Compare(a.id, b.id))
+	})
+
+	return items
+}
+
+// reset removes all data from t.
+func (t *tracker) reset() {
+	t.sketch.reset()
+	*t.heap = (*t.heap)[:0]
+	clear(t.items)
+}
@@ -0,0 +1,43 @@
+package topprofiles
+
+import (
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	t.Parallel()
+
+	tr := newTracker(2)
+
+	counts := map[agd.ProfileID]int{
+		"prof1": 1,
+		"prof2": 5,
+		"prof3": 3,
+		"prof4": 2,
+	}
+
+	for id, n := range counts {
+		for range n {
+			tr.add(id)
+		}
+	}
+
+	top := tr.top()
+	require.Len(t, top, 2)
+
+	assert.Equal(t, agd.ProfileID("prof2"), top[0].id)
+	assert.GreaterOrEqual(t, top[0].count, uint64(5))
+	assert.Equal(t, agd.ProfileID("prof3"), top[1].id)
+	assert.GreaterOrEqual(t, top[1].count, uint64(3))
+
+	assert.GreaterOrEqual(t, tr.sketch.estimate("prof1"), uint64(1))
+	assert.GreaterOrEqual(t, tr.sketch.estimate("prof4"), uint64(2))
+
+	tr.reset()
+	assert.Empty(t, tr.top())
+	assert.Zero(t, tr.sketch.estimate("prof2"))
+}
@@ -0,0 +1,28 @@
+// Package topprofiles contains the statistics of the profiles that send the
+// most requests, get the most errors, or have the most requests blocked.  The
+// statistics are collected using fixed-size data structures, so that they can
+// be used in place of the Prometheus metrics with profile IDs as labels.
+package topprofiles
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// Interface is the interface for the top-profiles statistics collectors.
+type Interface interface {
+	// Record records a single request of the profile with the given ID.
+	// isBlocked is true if the request has been blocked or rewritten.  isError
+	// is true if the request has failed.
+	Record(ctx context.Context, id agd.ProfileID, isBlocked, isError bool)
+}
+
+// Empty is an [Interface] implementation that does nothing.
+type Empty struct{}
+
+// type check
+var _ Interface = Empty{}
+
+// Record implements the [Interface] interface for Empty.
+func (Empty) Record(_ context.Context, _ agd.ProfileID, _, _ bool) {}
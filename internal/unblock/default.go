@@ -0,0 +1,101 @@
+package unblock
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/golibs/netutil"
+)
+
+// DefaultStorageConfig is the configuration structure for [DefaultStorage].
+type DefaultStorageConfig struct {
+	// Clock is used to check the expiration of the exceptions.  It must not be
+	// nil.
+	Clock agdtime.Clock
+
+	// MaxPerProfile is the maximum number of exceptions kept for a single
+	// profile.  When it is reached, the exceptions that expire first are
+	// removed.  It must be positive.
+	MaxPerProfile int
+}
+
+// DefaultStorage is the default in-memory [Storage] implementation.  The
+// exceptions are only kept on the node that has received them.
+type DefaultStorage struct {
+	clock agdtime.Clock
+
+	// mu protects exceptions.
+	mu *sync.Mutex
+
+	// exceptions maps the IDs of the profiles to their exceptions.
+	exceptions map[agd.ProfileID][]*Exception
+
+	maxPerProfile int
+}
+
+// NewDefaultStorage returns a new properly initialized *DefaultStorage.  c must
+// be valid.
+func NewDefaultStorage(c *DefaultStorageConfig) (s *DefaultStorage) {
+	return &DefaultStorage{
+		clock:         c.Clock,
+		mu:            &sync.Mutex{},
+		exceptions:    map[agd.ProfileID][]*Exception{},
+		maxPerProfile: c.MaxPerProfile,
+	}
+}
+
+// type check
+var _ Storage = (*DefaultStorage)(nil)
+
+// Add implements the [Storage] interface for *DefaultStorage.  It also removes
+// the expired exceptions of the profile as well as the previous exception for
+// the same device and host, if any.
+func (s *DefaultStorage) Add(_ context.Context, e *Exception) {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	excs := slices.DeleteFunc(s.exceptions[e.ProfileID], func(prev *Exception) (ok bool) {
+		return !now.Before(prev.Expires) ||
+			(prev.DeviceID == e.DeviceID && prev.Host == e.Host)
+	})
+
+	excs = append(excs, e)
+	if len(excs) > s.maxPerProfile {
+		slices.SortStableFunc(excs, func(a, b *Exception) (res int) {
+			return b.Expires.Compare(a.Expires)
+		})
+
+		clear(excs[s.maxPerProfile:])
+		excs = excs[:s.maxPerProfile]
+	}
+
+	s.exceptions[e.ProfileID] = excs
+}
+
+// Match implements the [Storage] interface for *DefaultStorage.
+func (s *DefaultStorage) Match(
+	_ context.Context,
+	profID agd.ProfileID,
+	devID agd.DeviceID,
+	host string,
+) (ok bool) {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, e := range s.exceptions[profID] {
+		if (e.DeviceID == "" || e.DeviceID == devID) &&
+			now.Before(e.Expires) &&
+			(host == e.Host || netutil.IsSubdomain(host, e.Host)) {
+			return true
+		}
+	}
+
+	return false
+}
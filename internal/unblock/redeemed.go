@@ -0,0 +1,51 @@
+package unblock
+
+import (
+	"sync"
+	"time"
+)
+
+// RedeemedTokens is the set of the IDs of the unblock tokens that have already
+// been redeemed, which prevents the reuse of the tokens.  The IDs are kept until
+// the tokens expire.  Like [DefaultStorage], it is only kept on the node that
+// has received the tokens.
+type RedeemedTokens struct {
+	// mu protects ids.
+	mu *sync.Mutex
+
+	// ids maps the IDs of the redeemed tokens to their expiry times as Unix
+	// time in seconds.
+	ids map[string]int64
+}
+
+// NewRedeemedTokens returns a new empty *RedeemedTokens.
+func NewRedeemedTokens() (r *RedeemedTokens) {
+	return &RedeemedTokens{
+		mu:  &sync.Mutex{},
+		ids: map[string]int64{},
+	}
+}
+
+// redeem marks the token with the given ID, which expires at exp, as redeemed
+// at the moment now.  ok is false if the token has already been redeemed.  It
+// also removes the IDs of the expired tokens.
+func (r *RedeemedTokens) redeem(id string, exp int64, now time.Time) (ok bool) {
+	nowUnix := now.Unix()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k, v := range r.ids {
+		if nowUnix > v {
+			delete(r.ids, k)
+		}
+	}
+
+	if _, ok = r.ids[id]; ok {
+		return false
+	}
+
+	r.ids[id] = exp
+
+	return true
+}
@@ -0,0 +1,180 @@
+package unblock
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/golibs/errors"
+)
+
+// MinKeyLen is the minimum length of the key used to sign the tokens, in
+// bytes.
+const MinKeyLen = 32
+
+// Token errors.
+const (
+	// ErrBadToken is returned by [ParseToken] when the token is malformed or
+	// has an invalid signature.
+	ErrBadToken errors.Error = "bad unblock token"
+
+	// ErrTokenExpired is returned by [ParseToken] when the token has a valid
+	// signature but is already expired.
+	ErrTokenExpired errors.Error = "unblock token expired"
+
+	// ErrTokenRedeemed is returned by [ParseToken] when the token is valid but
+	// has already been redeemed.
+	ErrTokenRedeemed errors.Error = "unblock token already redeemed"
+)
+
+// TokenData is the data of an unblock token.
+type TokenData struct {
+	// Expires is the time after which the token itself is no longer valid.
+	Expires time.Time
+
+	// ProfileID is the ID of the profile to create an exception for.  It must
+	// not be empty.
+	ProfileID agd.ProfileID
+
+	// DeviceID is the ID of the device to create an exception for.  If it is
+	// empty, the exception is created for the whole profile.
+	DeviceID agd.DeviceID
+
+	// Host is the domain name to create an exception for.  It must not be
+	// empty.
+	Host string
+
+	// Duration is the duration of the exception.  It must be positive.
+	Duration time.Duration
+}
+
+// tokenPartsNum is the number of dot-separated parts of a token.
+const tokenPartsNum = 6
+
+// NewToken returns a new unblock token with the given data signed with key.
+// key must be at least [MinKeyLen] bytes long and d must be valid.  The token
+// has the following format:
+//
+//	<profile ID>.<device ID>.<host>.<duration>.<expiry>.<signature>
+//
+// Where host is the unpadded URL-safe base64 encoding of the domain name,
+// duration is the duration of the exception in seconds, expiry is the Unix
+// time in seconds after which the token is no longer valid, and signature is
+// the unpadded URL-safe base64 encoding of the HMAC-SHA256 of all the previous
+// parts including the dots.
+func NewToken(key []byte, d *TokenData) (token string) {
+	signed := strings.Join([]string{
+		string(d.ProfileID),
+		string(d.DeviceID),
+		base64.RawURLEncoding.EncodeToString([]byte(d.Host)),
+		strconv.FormatInt(int64(d.Duration/time.Second), 10),
+		strconv.FormatInt(d.Expires.Unix(), 10),
+	}, ".")
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sign(key, signed))
+}
+
+// ParseToken verifies token signed with key at the moment now and returns the
+// exception it describes.  The duration of the exception is limited by maxDur.
+// Each token can only be redeemed once, so the valid tokens are added to
+// redeemed.  key must not be empty, maxDur must be positive, and redeemed must
+// not be nil.  If the token is invalid, err is [ErrBadToken],
+// [ErrTokenExpired], or [ErrTokenRedeemed].
+func ParseToken(
+	key []byte,
+	token string,
+	now time.Time,
+	maxDur time.Duration,
+	redeemed *RedeemedTokens,
+) (e *Exception, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != tokenPartsNum {
+		return nil, ErrBadToken
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[tokenPartsNum-1])
+	if err != nil {
+		return nil, ErrBadToken
+	}
+
+	signed := token[:len(token)-len(parts[tokenPartsNum-1])-1]
+	if !hmac.Equal(sig, sign(key, signed)) {
+		return nil, ErrBadToken
+	}
+
+	exp, err := strconv.ParseInt(parts[4], 10, 64)
+	if err != nil {
+		return nil, ErrBadToken
+	} else if now.Unix() > exp {
+		return nil, ErrTokenExpired
+	}
+
+	e, err = exceptionFromParts(parts, now, maxDur)
+	if err != nil {
+		// Don't wrap the error, since it's informative enough as is.
+		return nil, err
+	}
+
+	// Use the decoded signature as the ID of the token, since the lenient
+	// base64 decoding allows several encodings of the same signature.
+	if !redeemed.redeem(string(sig), exp, now) {
+		return nil, ErrTokenRedeemed
+	}
+
+	return e, nil
+}
+
+// exceptionFromParts returns the exception described by the signed parts of a
+// token.
+func exceptionFromParts(
+	parts []string,
+	now time.Time,
+	maxDur time.Duration,
+) (e *Exception, err error) {
+	profID, err := agd.NewProfileID(parts[0])
+	if err != nil {
+		return nil, ErrBadToken
+	}
+
+	var devID agd.DeviceID
+	if parts[1] != "" {
+		devID, err = agd.NewDeviceID(parts[1])
+		if err != nil {
+			return nil, ErrBadToken
+		}
+	}
+
+	hostData, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(hostData) == 0 {
+		return nil, ErrBadToken
+	}
+
+	secs, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil || secs <= 0 {
+		return nil, ErrBadToken
+	}
+
+	dur := maxDur
+	if secs < int64(maxDur/time.Second) {
+		dur = time.Duration(secs) * time.Second
+	}
+
+	return &Exception{
+		Expires:   now.Add(dur),
+		ProfileID: profID,
+		DeviceID:  devID,
+		Host:      strings.ToLower(strings.TrimSuffix(string(hostData), ".")),
+	}, nil
+}
+
+// sign returns the HMAC-SHA256 of signed using key.
+func sign(key []byte, signed string) (sig []byte) {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write([]byte(signed))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,130 @@
+package unblock_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseToken(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1_700_000_000, 0)
+	data := &unblock.TokenData{
+		Expires:   now.Add(time.Minute),
+		ProfileID: testProfID,
+		DeviceID:  testDevID,
+		Host:      "Example.COM.",
+		Duration:  30 * time.Minute,
+	}
+
+	validToken := unblock.NewToken(testKey, data)
+
+	t.Run("success", func(t *testing.T) {
+		t.Parallel()
+
+		e, err := unblock.ParseToken(
+			testKey,
+			validToken,
+			now,
+			time.Hour,
+			unblock.NewRedeemedTokens(),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, &unblock.Exception{
+			Expires:   now.Add(30 * time.Minute),
+			ProfileID: testProfID,
+			DeviceID:  testDevID,
+			Host:      testHost,
+		}, e)
+	})
+
+	t.Run("max_duration", func(t *testing.T) {
+		t.Parallel()
+
+		e, err := unblock.ParseToken(
+			testKey,
+			validToken,
+			now,
+			time.Minute,
+			unblock.NewRedeemedTokens(),
+		)
+		require.NoError(t, err)
+
+		assert.Equal(t, now.Add(time.Minute), e.Expires)
+	})
+
+	t.Run("redeemed", func(t *testing.T) {
+		t.Parallel()
+
+		redeemed := unblock.NewRedeemedTokens()
+		_, err := unblock.ParseToken(testKey, validToken, now, time.Hour, redeemed)
+		require.NoError(t, err)
+
+		e, err := unblock.ParseToken(testKey, validToken, now, time.Hour, redeemed)
+		assert.Nil(t, e)
+		assert.ErrorIs(t, err, unblock.ErrTokenRedeemed)
+
+		// Change the unused trailing bits of the signature, which doesn't change
+		// the decoded signature.
+		const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+		last := strings.IndexByte(alphabet, validToken[len(validToken)-1])
+		other := validToken[:len(validToken)-1] + alphabet[last^1:last^1+1]
+		e, err = unblock.ParseToken(testKey, other, now, time.Hour, redeemed)
+		assert.Nil(t, e)
+		assert.ErrorIs(t, err, unblock.ErrTokenRedeemed)
+	})
+
+	testCases := []struct {
+		wantErr error
+		name    string
+		now     time.Time
+		token   string
+	}{{
+		wantErr: unblock.ErrTokenExpired,
+		name:    "expired",
+		token:   validToken,
+		now:     now.Add(2 * time.Minute),
+	}, {
+		wantErr: unblock.ErrBadToken,
+		name:    "bad_signature",
+		token:   strings.Replace(validToken, string(testDevID), "dev0000", 1),
+		now:     now,
+	}, {
+		wantErr: unblock.ErrBadToken,
+		name:    "other_key",
+		token:   unblock.NewToken([]byte(strings.Repeat("x", unblock.MinKeyLen)), data),
+		now:     now,
+	}, {
+		wantErr: unblock.ErrBadToken,
+		name:    "malformed",
+		token:   "a.b.c",
+		now:     now,
+	}, {
+		wantErr: unblock.ErrBadToken,
+		name:    "empty",
+		token:   "",
+		now:     now,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			e, err := unblock.ParseToken(
+				testKey,
+				tc.token,
+				tc.now,
+				time.Hour,
+				unblock.NewRedeemedTokens(),
+			)
+			assert.Nil(t, e)
+			assert.ErrorIs(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+// Package unblock contains the storage of the temporary exceptions, which allow
+// the domain names blocked for a profile or a device for a short time, as well
+// as the signed tokens, with which the block page creates them.
+package unblock
+
+import (
+	"context"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// Exception is a temporary exception that allows a domain name and its
+// subdomains for a profile or a single device of a profile.
+type Exception struct {
+	// Expires is the time after which the exception is no longer applied.
+	Expires time.Time
+
+	// ProfileID is the ID of the profile, to which the exception applies.  It
+	// must not be empty.
+	ProfileID agd.ProfileID
+
+	// DeviceID is the ID of the device, to which the exception applies.  If it
+	// is empty, the exception applies to all devices of the profile.
+	DeviceID agd.DeviceID
+
+	// Host is the lowercased, non-FQDN domain name that is allowed along with
+	// its subdomains.  It must not be empty.
+	Host string
+}
+
+// Storage is the interface for the storages of the temporary exceptions.
+type Storage interface {
+	// Add adds e to the storage.  e must not be nil and must not be modified
+	// after calling Add.
+	Add(ctx context.Context, e *Exception)
+
+	// Match returns true if there is an unexpired exception for the profile
+	// and the device with the given IDs that allows host.
+	Match(ctx context.Context, profID agd.ProfileID, devID agd.DeviceID, host string) (ok bool)
+}
+
+// EmptyStorage is the [Storage] implementation that does nothing.
+type EmptyStorage struct{}
+
+// type check
+var _ Storage = EmptyStorage{}
+
+// Add implements the [Storage] interface for EmptyStorage.
+func (EmptyStorage) Add(_ context.Context, _ *Exception) {}
+
+// Match implements the [Storage] interface for EmptyStorage.  It always
+// returns false.
+func (EmptyStorage) Match(
+	_ context.Context,
+	_ agd.ProfileID,
+	_ agd.DeviceID,
+	_ string,
+) (ok bool) {
+	return false
+}
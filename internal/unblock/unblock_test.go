@@ -0,0 +1,148 @@
+package unblock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// Common IDs and hosts for tests.
+const (
+	testProfID      agd.ProfileID = "prof1234"
+	testOtherProfID agd.ProfileID = "prof5678"
+	testDevID       agd.DeviceID  = "dev1234"
+	testOtherDevID  agd.DeviceID  = "dev5678"
+
+	testHost = "example.com"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// testKey is the common signing key for tests.
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func TestDefaultStorage_Match(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	clock := &agdtest.Clock{
+		OnNow: func() (n time.Time) { return now },
+	}
+
+	s := unblock.NewDefaultStorage(&unblock.DefaultStorageConfig{
+		Clock:         clock,
+		MaxPerProfile: 10,
+	})
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	s.Add(ctx, &unblock.Exception{
+		Expires:   now.Add(time.Minute),
+		ProfileID: testProfID,
+		DeviceID:  testDevID,
+		Host:      testHost,
+	})
+	s.Add(ctx, &unblock.Exception{
+		Expires:   now.Add(time.Minute),
+		ProfileID: testOtherProfID,
+		Host:      testHost,
+	})
+	s.Add(ctx, &unblock.Exception{
+		Expires:   now,
+		ProfileID: testProfID,
+		Host:      "expired.example",
+	})
+
+	testCases := []struct {
+		name   string
+		profID agd.ProfileID
+		devID  agd.DeviceID
+		host   string
+		want   bool
+	}{{
+		name:   "device",
+		profID: testProfID,
+		devID:  testDevID,
+		host:   testHost,
+		want:   true,
+	}, {
+		name:   "subdomain",
+		profID: testProfID,
+		devID:  testDevID,
+		host:   "sub." + testHost,
+		want:   true,
+	}, {
+		name:   "not_subdomain",
+		profID: testProfID,
+		devID:  testDevID,
+		host:   "not" + testHost,
+		want:   false,
+	}, {
+		name:   "other_device",
+		profID: testProfID,
+		devID:  testOtherDevID,
+		host:   testHost,
+		want:   false,
+	}, {
+		name:   "whole_profile",
+		profID: testOtherProfID,
+		devID:  testOtherDevID,
+		host:   testHost,
+		want:   true,
+	}, {
+		name:   "expired",
+		profID: testProfID,
+		devID:  testDevID,
+		host:   "expired.example",
+		want:   false,
+	}, {
+		name:   "other_profile",
+		profID: "prof0000",
+		devID:  testDevID,
+		host:   testHost,
+		want:   false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := testutil.ContextWithTimeout(t, testTimeout)
+			assert.Equal(t, tc.want, s.Match(ctx, tc.profID, tc.devID, tc.host))
+		})
+	}
+}
+
+func TestDefaultStorage_Add_max(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	clock := &agdtest.Clock{
+		OnNow: func() (n time.Time) { return now },
+	}
+
+	s := unblock.NewDefaultStorage(&unblock.DefaultStorageConfig{
+		Clock:         clock,
+		MaxPerProfile: 1,
+	})
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	s.Add(ctx, &unblock.Exception{
+		Expires:   now.Add(time.Minute),
+		ProfileID: testProfID,
+		Host:      "first.example",
+	})
+	s.Add(ctx, &unblock.Exception{
+		Expires:   now.Add(2 * time.Minute),
+		ProfileID: testProfID,
+		Host:      "second.example",
+	})
+
+	assert.False(t, s.Match(ctx, testProfID, "", "first.example"))
+	assert.True(t, s.Match(ctx, testProfID, "", "second.example"))
+}
@@ -53,6 +53,13 @@ type blockPageServer struct {
 	// contentFilePath is the path to HTML block page content file.
 	contentFilePath string
 
+	// unblock is the optional configuration of the temporary-unblocking API.
+	unblock *UnblockConfig
+
+	// domainPolicies is the storage of the HTTP security policies of the
+	// domains.  It must not be nil.
+	domainPolicies DomainPolicyStorage
+
 	// name is the server identification used for logging and metrics.
 	name blockPageName
 
@@ -60,15 +67,23 @@ type blockPageServer struct {
 	bind []*BindData
 }
 
-// newBlockPageServer initializes a new instance of blockPageServer.  The server
-// must be refreshed with [blockPageServer.Refresh] before use.
-func newBlockPageServer(conf *BlockPageServerConfig, srvName blockPageName) (srv *blockPageServer) {
+// newBlockPageServer initializes a new instance of blockPageServer.  unblockConf
+// may be nil.  domainPolicies must not be nil.  The server must be refreshed
+// with [blockPageServer.Refresh] before use.
+func newBlockPageServer(
+	conf *BlockPageServerConfig,
+	unblockConf *UnblockConfig,
+	domainPolicies DomainPolicyStorage,
+	srvName blockPageName,
+) (srv *blockPageServer) {
 	if conf == nil {
 		return nil
 	}
 
 	return &blockPageServer{
 		mu:              &sync.RWMutex{},
+		unblock:         unblockConf,
+		domainPolicies:  domainPolicies,
 		contentFilePath: conf.ContentFilePath,
 		name:            srvName,
 		bind:            conf.Bind,
@@ -136,6 +151,10 @@ func (srv *blockPageServer) blockPageHandler() (h http.Handler) {
 		respHdr := w.Header()
 		respHdr.Set(httphdr.Server, agdhttp.UserAgent())
 
+		if applyDomainPolicy(srv.domainPolicies, respHdr, w, r) {
+			return
+		}
+
 		switch r.URL.Path {
 		case "/favicon.ico":
 			// Don't serve the HTML page to the favicon requests.
@@ -144,6 +163,8 @@ func (srv *blockPageServer) blockPageHandler() (h http.Handler) {
 			// Don't serve the HTML page to the robots.txt requests.  Serve the
 			// predefined response instead.
 			serveRobotsDisallow(respHdr, w, srv.name)
+		case PathUnblock:
+			srv.serveUnblock(w, r)
 		default:
 			srv.mu.RLock()
 			defer srv.mu.RUnlock()
@@ -0,0 +1,174 @@
+package websvc
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/log"
+)
+
+// PathDeviceStats is the path of the per-device statistics API.
+const PathDeviceStats = "/device_stats"
+
+// DeviceStatsConfig is the configuration of the per-device statistics API.
+type DeviceStatsConfig struct {
+	// ProfileDB is used to find and authenticate the devices.  It must not be
+	// nil.
+	ProfileDB profiledb.Interface
+
+	// Stats is used to get the statistics of the devices.  It must not be nil.
+	Stats devicestat.Interface
+}
+
+// Device authentication cache parameters.
+//
+// TODO:  Make configurable.
+const (
+	// deviceAuthCacheCount is the maximum number of successful device
+	// authentications kept in the cache.
+	deviceAuthCacheCount = 10_000
+
+	// deviceAuthCacheTTL is the time during which a successful device
+	// authentication is reused.  A changed password may thus still be accepted
+	// for this long.
+	deviceAuthCacheTTL = 1 * time.Minute
+)
+
+// deviceAuthKey is the key of the device authentication cache.
+type deviceAuthKey struct {
+	// id is the ID of the authenticated device.
+	id agd.DeviceID
+
+	// passwdHash is the SHA-256 hash of the password, so that the passwords
+	// themselves aren't kept in memory.
+	passwdHash [sha256.Size]byte
+}
+
+// newDeviceAuthCache returns a new cache of successful device
+// authentications.
+func newDeviceAuthCache() (c agdcache.Interface[deviceAuthKey, struct{}]) {
+	return agdcache.NewLRU[deviceAuthKey, struct{}](&agdcache.LRUConfig{
+		Count: deviceAuthCacheCount,
+	})
+}
+
+// deviceStatsResp is the response of the per-device statistics API.
+type deviceStatsResp struct {
+	// Categories maps the IDs of filters to the numbers of queries blocked by
+	// them.
+	Categories map[filter.ID]uint64 `json:"categories"`
+
+	// Learned maps the IDs of parental-control filters to the numbers of
+	// queries that would have been blocked by them, if the device weren't in
+	// the learning mode.
+	Learned map[filter.ID]uint64 `json:"learned"`
+
+	// Total is the total number of queries.
+	Total uint64 `json:"total"`
+
+	// Blocked is the number of blocked queries.
+	Blocked uint64 `json:"blocked"`
+}
+
+// serveDeviceStats serves the statistics of the device authenticated with the
+// basic HTTP authentication, in which the username is the device ID and the
+// password is the DNS-over-HTTPS password of the device.  Only devices with
+// enabled authentication may receive their statistics.
+func (svc *Service) serveDeviceStats(w http.ResponseWriter, r *http.Request) {
+	c := svc.deviceStats
+	if c == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	metrics.WebSvcDeviceStatsRequestsTotal.Inc()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	ctx := r.Context()
+	devID, ok := svc.authenticateDevice(r)
+	if !ok {
+		w.Header().Set(httphdr.WWWAuthenticate, `Basic realm="device stats"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+
+		return
+	}
+
+	s := c.Stats.Stats(ctx, devID)
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err := json.NewEncoder(w).Encode(&deviceStatsResp{
+		Categories: s.Categories,
+		Learned:    s.Learned,
+		Total:      s.Total,
+		Blocked:    s.Blocked,
+	})
+	if err != nil {
+		logErrorByType(err, "websvc: device stats: writing response: %s", err)
+	}
+}
+
+// authenticateDevice returns the ID of the device authenticated by the basic
+// HTTP authentication data in r.  ok is false if the device could not be
+// authenticated.
+func (svc *Service) authenticateDevice(r *http.Request) (id agd.DeviceID, ok bool) {
+	user, passwd, ok := r.BasicAuth()
+	if !ok {
+		return "", false
+	}
+
+	id, err := agd.NewDeviceID(user)
+	if err != nil {
+		return "", false
+	}
+
+	ctx := r.Context()
+	_, dev, err := svc.deviceStats.ProfileDB.ProfileByDeviceID(ctx, id)
+	if err != nil {
+		if !errors.Is(err, profiledb.ErrDeviceNotFound) {
+			log.Error("websvc: device stats: finding device %q: %s", id, err)
+		}
+
+		return "", false
+	}
+
+	auth := dev.Auth
+	if !auth.Enabled {
+		return "", false
+	}
+
+	// Don't run the password hashing, which is expensive by design, on every
+	// request of the same device.
+	key := deviceAuthKey{
+		id:         id,
+		passwdHash: sha256.Sum256([]byte(passwd)),
+	}
+
+	if _, ok = svc.deviceAuthCache.Get(key); ok {
+		return id, true
+	}
+
+	if !auth.PasswordHash.Authenticate(ctx, []byte(passwd)) {
+		return "", false
+	}
+
+	svc.deviceAuthCache.SetWithExpire(key, struct{}{}, deviceAuthCacheTTL)
+
+	return id, true
+}
@@ -0,0 +1,228 @@
+package websvc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/devicestat"
+	"github.com/AdguardTeam/AdGuardDNS/internal/filter"
+	"github.com/AdguardTeam/AdGuardDNS/internal/profiledb"
+	"github.com/AdguardTeam/AdGuardDNS/internal/websvc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ServeHTTP_deviceStats(t *testing.T) {
+	const (
+		devID       agd.DeviceID = "dev1234"
+		noAuthDevID agd.DeviceID = "dev5678"
+		passwd                   = "password"
+	)
+
+	auth := &agdtest.Authenticator{
+		OnAuthenticate: func(_ context.Context, p []byte) (ok bool) {
+			return string(p) == passwd
+		},
+	}
+
+	profDB := agdtest.NewProfileDB()
+	profDB.OnProfileByDeviceID = func(
+		_ context.Context,
+		id agd.DeviceID,
+	) (p *agd.Profile, d *agd.Device, err error) {
+		switch id {
+		case devID:
+			return &agd.Profile{}, &agd.Device{
+				Auth: &agd.AuthSettings{
+					PasswordHash: auth,
+					Enabled:      true,
+				},
+				ID: id,
+			}, nil
+		case noAuthDevID:
+			return &agd.Profile{}, &agd.Device{
+				Auth: &agd.AuthSettings{
+					PasswordHash: auth,
+					Enabled:      false,
+				},
+				ID: id,
+			}, nil
+		default:
+			return nil, nil, profiledb.ErrDeviceNotFound
+		}
+	}
+
+	stats := &agdtest.DeviceStat{
+		OnRecord: func(_ context.Context, _ agd.DeviceID, _ time.Time, _ filter.ID, _, _ bool) {
+			panic("not implemented")
+		},
+		OnStats: func(_ context.Context, _ agd.DeviceID) (s *devicestat.Stats) {
+			return &devicestat.Stats{
+				Categories: map[filter.ID]uint64{
+					filter.IDAdultBlocking: 1,
+				},
+				Learned: map[filter.ID]uint64{
+					filter.IDBlockedService: 2,
+				},
+				Total:   10,
+				Blocked: 1,
+			}
+		},
+	}
+
+	svc := websvc.New(&websvc.Config{
+		StaticContent: http.NotFoundHandler(),
+		DeviceStats: &websvc.DeviceStatsConfig{
+			ProfileDB: profDB,
+			Stats:     stats,
+		},
+	})
+	require.NotNil(t, svc)
+
+	const successBody = `{"categories":{"adult_blocking":1},"learned":{"blocked_service":2},` +
+		`"total":10,"blocked":1}` + "\n"
+
+	testCases := []struct {
+		name     string
+		user     string
+		passwd   string
+		wantBody string
+		wantCode int
+		noAuth   bool
+	}{{
+		name:     "success",
+		user:     string(devID),
+		passwd:   passwd,
+		wantBody: successBody,
+		wantCode: http.StatusOK,
+		noAuth:   false,
+	}, {
+		name:     "no_auth",
+		user:     "",
+		passwd:   "",
+		wantBody: "",
+		wantCode: http.StatusUnauthorized,
+		noAuth:   true,
+	}, {
+		name:     "bad_password",
+		user:     string(devID),
+		passwd:   "bad",
+		wantBody: "",
+		wantCode: http.StatusUnauthorized,
+		noAuth:   false,
+	}, {
+		name:     "auth_disabled",
+		user:     string(noAuthDevID),
+		passwd:   passwd,
+		wantBody: "",
+		wantCode: http.StatusUnauthorized,
+		noAuth:   false,
+	}, {
+		name:     "not_found",
+		user:     "unknown",
+		passwd:   passwd,
+		wantBody: "",
+		wantCode: http.StatusUnauthorized,
+		noAuth:   false,
+	}, {
+		name:     "bad_device_id",
+		user:     "bad/id",
+		passwd:   passwd,
+		wantBody: "",
+		wantCode: http.StatusUnauthorized,
+		noAuth:   false,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, websvc.PathDeviceStats, nil)
+			if !tc.noAuth {
+				r.SetBasicAuth(tc.user, tc.passwd)
+			}
+
+			rw := httptest.NewRecorder()
+			svc.ServeHTTP(rw, r)
+
+			assert.Equal(t, tc.wantCode, rw.Code)
+			if tc.wantBody != "" {
+				assert.Equal(t, tc.wantBody, rw.Body.String())
+			}
+		})
+	}
+}
+
+func TestService_ServeHTTP_deviceStatsAuthCache(t *testing.T) {
+	const (
+		devID  agd.DeviceID = "dev1234"
+		passwd              = "password"
+	)
+
+	var numAuth atomic.Int32
+	auth := &agdtest.Authenticator{
+		OnAuthenticate: func(_ context.Context, p []byte) (ok bool) {
+			numAuth.Add(1)
+
+			return string(p) == passwd
+		},
+	}
+
+	profDB := agdtest.NewProfileDB()
+	profDB.OnProfileByDeviceID = func(
+		_ context.Context,
+		id agd.DeviceID,
+	) (p *agd.Profile, d *agd.Device, err error) {
+		return &agd.Profile{}, &agd.Device{
+			Auth: &agd.AuthSettings{
+				PasswordHash: auth,
+				Enabled:      true,
+			},
+			ID: id,
+		}, nil
+	}
+
+	stats := &agdtest.DeviceStat{
+		OnRecord: func(_ context.Context, _ agd.DeviceID, _ time.Time, _ filter.ID, _, _ bool) {
+			panic("not implemented")
+		},
+		OnStats: func(_ context.Context, _ agd.DeviceID) (s *devicestat.Stats) {
+			return &devicestat.Stats{}
+		},
+	}
+
+	svc := websvc.New(&websvc.Config{
+		StaticContent: http.NotFoundHandler(),
+		DeviceStats: &websvc.DeviceStatsConfig{
+			ProfileDB: profDB,
+			Stats:     stats,
+		},
+	})
+	require.NotNil(t, svc)
+
+	serve := func(user, pass string) (code int) {
+		r := httptest.NewRequest(http.MethodGet, websvc.PathDeviceStats, nil)
+		r.SetBasicAuth(user, pass)
+
+		rw := httptest.NewRecorder()
+		svc.ServeHTTP(rw, r)
+
+		return rw.Code
+	}
+
+	for range 3 {
+		assert.Equal(t, http.StatusOK, serve(string(devID), passwd))
+	}
+
+	assert.Equal(t, int32(1), numAuth.Load())
+
+	for range 2 {
+		assert.Equal(t, http.StatusUnauthorized, serve(string(devID), "bad"))
+	}
+
+	assert.Equal(t, int32(3), numAuth.Load())
+}
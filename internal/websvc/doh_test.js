@@ -0,0 +1,208 @@
+'use strict';
+
+(function () {
+	const infoPath = '/doh_test/info';
+	const dnsMessageType = 'application/dns-message';
+
+	const form = document.getElementById('form');
+	const pathInput = document.getElementById('path');
+	const methodInput = document.getElementById('method');
+	const countInput = document.getElementById('count');
+	const runButton = document.getElementById('run');
+	const connTable = document.getElementById('conn');
+	const queriesBody = document.querySelector('#queries tbody');
+	const checkTable = document.getElementById('check');
+	const reportPre = document.getElementById('report');
+
+	let info = null;
+
+	// randomID returns a random identifier suitable for the DNS check API.
+	function randomID() {
+		const buf = new Uint8Array(8);
+		crypto.getRandomValues(buf);
+
+		return Array.from(buf, (b) => b.toString(16).padStart(2, '0')).join('');
+	}
+
+	// newQuery returns the wire-format DNS query of type A for name.
+	function newQuery(name) {
+		const labels = name.split('.').filter((l) => l.length > 0);
+		const bytes = [
+			// ID is zero as per RFC 8484, flags have RD set, QDCOUNT is one.
+			0, 0, 1, 0, 0, 1, 0, 0, 0, 0, 0, 0,
+		];
+
+		for (const l of labels) {
+			bytes.push(l.length);
+			for (let i = 0; i < l.length; i++) {
+				bytes.push(l.charCodeAt(i));
+			}
+		}
+
+		// The root label, QTYPE A, and QCLASS IN.
+		bytes.push(0, 0, 1, 0, 1);
+
+		return new Uint8Array(bytes);
+	}
+
+	// base64URL encodes data using the unpadded URL-safe base64 encoding.
+	function base64URL(data) {
+		let s = '';
+		data.forEach((b) => { s += String.fromCharCode(b); });
+
+		return btoa(s).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+	}
+
+	// fillTable replaces the contents of table with the rows from obj.
+	function fillTable(table, obj) {
+		table.replaceChildren();
+		for (const [k, v] of Object.entries(obj)) {
+			const row = table.insertRow();
+			const th = document.createElement('th');
+			th.textContent = k;
+			row.appendChild(th);
+			row.insertCell().textContent = v === '' || v === undefined ? '-' : String(v);
+		}
+	}
+
+	// nextHopProtocol returns the protocol used to fetch url, as reported by
+	// the Resource Timing API.
+	function nextHopProtocol(url) {
+		const entries = performance.getEntriesByName(url);
+		if (entries.length === 0) {
+			return '';
+		}
+
+		return entries[entries.length - 1].nextHopProtocol || '';
+	}
+
+	// sendQuery sends a single DoH query for name and returns its result.
+	async function sendQuery(path, method, name) {
+		const msg = newQuery(name);
+		let url = new URL(path, location.href).href;
+		const init = {
+			cache: 'no-store',
+			method: method,
+			headers: { 'Accept': dnsMessageType },
+		};
+
+		if (method === 'GET') {
+			url += (url.includes('?') ? '&' : '?') + 'dns=' + base64URL(msg);
+		} else {
+			init.headers['Content-Type'] = dnsMessageType;
+			init.body = msg;
+		}
+
+		const res = { protocol: '', status: '', rcode: '', answers: '', rtt: '' };
+		const start = performance.now();
+		try {
+			const resp = await fetch(url, init);
+			const body = new Uint8Array(await resp.arrayBuffer());
+			res.rtt = (performance.now() - start).toFixed(1);
+			res.status = resp.status;
+			if (resp.ok && body.length >= 12) {
+				res.rcode = body[3] & 0x0f;
+				res.answers = (body[6] << 8) | body[7];
+			}
+		} catch (err) {
+			res.status = 'error: ' + err.message;
+		}
+
+		res.protocol = nextHopProtocol(url);
+
+		return res;
+	}
+
+	// fetchCheck requests the result of the DNS check for the random ID id.
+	async function fetchCheck(id) {
+		const url = 'https://' + id + '-' + info.check_domain + '/dnscheck/test';
+		try {
+			const resp = await fetch(url, { cache: 'no-store' });
+			if (!resp.ok) {
+				return { error: 'status ' + resp.status };
+			}
+
+			return await resp.json();
+		} catch (err) {
+			return { error: err.message };
+		}
+	}
+
+	// loadInfo requests the information about the current connection.
+	async function loadInfo() {
+		const resp = await fetch(infoPath, { cache: 'no-store' });
+		info = await resp.json();
+		if (pathInput.value === '') {
+			pathInput.value = info.doh_path;
+		}
+
+		fillTable(connTable, {
+			'HTTP protocol': info.protocol,
+			'TLS version': info.tls_version,
+			'ALPN': info.alpn,
+			'TLS server name': info.server_name,
+			'Client address': info.client_addr,
+		});
+	}
+
+	// run runs the test and fills in the report.
+	async function run() {
+		runButton.disabled = true;
+		queriesBody.replaceChildren();
+		checkTable.replaceChildren();
+		reportPre.textContent = '';
+		reportPre.className = '';
+
+		try {
+			await loadInfo();
+
+			const path = pathInput.value;
+			const method = methodInput.value;
+			const count = Math.min(Math.max(parseInt(countInput.value, 10) || 1, 1), 20);
+			const id = randomID();
+			const results = [];
+
+			for (let i = 0; i < count; i++) {
+				// Only the first query uses the name with the check ID, the
+				// rest ones are used to measure the RTT and to let the browser
+				// upgrade the protocol.
+				const name = (i === 0 ? id : randomID()) + '-' + info.check_domain;
+				const res = await sendQuery(path, method, name);
+				results.push(res);
+
+				const row = queriesBody.insertRow();
+				[i + 1, res.protocol, res.status, res.rcode, res.answers, res.rtt].forEach((v) => {
+					row.insertCell().textContent = v === '' ? '-' : String(v);
+				});
+			}
+
+			const check = await fetchCheck(id);
+			fillTable(checkTable, check);
+
+			reportPre.textContent = JSON.stringify({
+				time: new Date().toISOString(),
+				user_agent: navigator.userAgent,
+				path: path,
+				method: method,
+				connection: info,
+				queries: results,
+				dnscheck: check,
+			}, null, 2);
+		} catch (err) {
+			reportPre.textContent = 'error: ' + err.message;
+			reportPre.className = 'error';
+		} finally {
+			runButton.disabled = false;
+		}
+	}
+
+	form.addEventListener('submit', (e) => {
+		e.preventDefault();
+		run();
+	});
+
+	loadInfo().catch((err) => {
+		reportPre.textContent = 'error: ' + err.message;
+		reportPre.className = 'error';
+	});
+})();
@@ -0,0 +1,131 @@
+package websvc
+
+import (
+	"crypto/tls"
+	_ "embed"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/golibs/httphdr"
+)
+
+// Paths of the DoH test page and its resources.
+const (
+	PathDoHTest       = "/doh_test"
+	PathDoHTestInfo   = "/doh_test/info"
+	PathDoHTestScript = "/doh_test/doh_test.js"
+)
+
+// DoHTestConfig is the configuration of the built-in DNS-over-HTTPS test page.
+type DoHTestConfig struct {
+	// CheckDomain is the DNS check domain used to generate the names of the
+	// test queries, so that their results can be requested from the DNS check
+	// API.  It must be a valid domain name.
+	CheckDomain string
+
+	// DoHPath is the default path of the DNS-over-HTTPS endpoint to which the
+	// test queries are sent.  It must not be empty.
+	DoHPath string
+}
+
+// dohTestPage is the HTML content of the DoH test page.
+//
+//go:embed doh_test.html
+var dohTestPage []byte
+
+// dohTestScript is the JavaScript code of the DoH test page.  It is served
+// separately to allow strict content security policies.
+//
+//go:embed doh_test.js
+var dohTestScript []byte
+
+// dohTestInfoResp is the response of the DoH test information API.
+type dohTestInfoResp struct {
+	// CheckDomain is the DNS check domain to use for the test queries.
+	CheckDomain string `json:"check_domain"`
+
+	// DoHPath is the default path of the DNS-over-HTTPS endpoint.
+	DoHPath string `json:"doh_path"`
+
+	// Protocol is the HTTP protocol of the request, for example "HTTP/2.0".
+	Protocol string `json:"protocol"`
+
+	// TLSVersion is the name of the TLS version of the connection.  It is
+	// empty if the connection is not encrypted.
+	TLSVersion string `json:"tls_version"`
+
+	// ALPN is the negotiated application protocol of the connection.
+	ALPN string `json:"alpn"`
+
+	// ServerName is the TLS server name sent by the client.
+	ServerName string `json:"server_name"`
+
+	// ClientAddr is the remote address of the client.
+	ClientAddr string `json:"client_addr"`
+}
+
+// serveDoHTest serves the DoH test page, its script, and the information about
+// the connection used by the test page.
+func (svc *Service) serveDoHTest(w http.ResponseWriter, r *http.Request) {
+	c := svc.dohTest
+	if c == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	metrics.WebSvcDoHTestRequestsTotal.Inc()
+
+	if r.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	h := w.Header()
+	switch r.URL.Path {
+	case PathDoHTest:
+		h.Set(httphdr.ContentType, agdhttp.HdrValTextHTML)
+		writeDoHTestContent(w, dohTestPage)
+	case PathDoHTestScript:
+		h.Set(httphdr.ContentType, agdhttp.HdrValTextJavaScript)
+		writeDoHTestContent(w, dohTestScript)
+	default:
+		h.Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+		h.Set(httphdr.CacheControl, "no-store")
+		err := json.NewEncoder(w).Encode(newDoHTestInfoResp(c, r))
+		if err != nil {
+			logErrorByType(err, "websvc: doh test: writing info: %s", err)
+		}
+	}
+}
+
+// writeDoHTestContent writes the static content of the DoH test page to w.
+func writeDoHTestContent(w io.Writer, content []byte) {
+	_, err := w.Write(content)
+	if err != nil {
+		logErrorByType(err, "websvc: doh test: writing response: %s", err)
+	}
+}
+
+// newDoHTestInfoResp returns the information about the connection of r for the
+// DoH test page.
+func newDoHTestInfoResp(c *DoHTestConfig, r *http.Request) (resp *dohTestInfoResp) {
+	resp = &dohTestInfoResp{
+		CheckDomain: c.CheckDomain,
+		DoHPath:     c.DoHPath,
+		Protocol:    r.Proto,
+		ClientAddr:  r.RemoteAddr,
+	}
+
+	if cs := r.TLS; cs != nil {
+		resp.TLSVersion = tls.VersionName(cs.Version)
+		resp.ALPN = cs.NegotiatedProtocol
+		resp.ServerName = cs.ServerName
+	}
+
+	return resp
+}
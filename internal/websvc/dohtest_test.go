@@ -0,0 +1,101 @@
+package websvc_test
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/websvc"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ServeHTTP_dohTest(t *testing.T) {
+	const (
+		checkDomain = "dnscheck.example"
+		dohPath     = "/dns-query"
+		serverName  = "dev1234.dns.example"
+	)
+
+	svc := websvc.New(&websvc.Config{
+		StaticContent: http.NotFoundHandler(),
+		DoHTest: &websvc.DoHTestConfig{
+			CheckDomain: checkDomain,
+			DoHPath:     dohPath,
+		},
+	})
+	require.NotNil(t, svc)
+
+	t.Run("page", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, websvc.PathDoHTest, nil)
+		rw := httptest.NewRecorder()
+		svc.ServeHTTP(rw, r)
+
+		assert.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, agdhttp.HdrValTextHTML, rw.Header().Get(httphdr.ContentType))
+		assert.Contains(t, rw.Body.String(), websvc.PathDoHTestScript)
+	})
+
+	t.Run("script", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, websvc.PathDoHTestScript, nil)
+		rw := httptest.NewRecorder()
+		svc.ServeHTTP(rw, r)
+
+		assert.Equal(t, http.StatusOK, rw.Code)
+		assert.Equal(t, agdhttp.HdrValTextJavaScript, rw.Header().Get(httphdr.ContentType))
+		assert.Contains(t, rw.Body.String(), websvc.PathDoHTestInfo)
+	})
+
+	t.Run("info", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, websvc.PathDoHTestInfo, nil)
+		r.Proto = "HTTP/2.0"
+		r.TLS = &tls.ConnectionState{
+			Version:            tls.VersionTLS13,
+			NegotiatedProtocol: "h2",
+			ServerName:         serverName,
+		}
+
+		rw := httptest.NewRecorder()
+		svc.ServeHTTP(rw, r)
+
+		require.Equal(t, http.StatusOK, rw.Code)
+
+		info := map[string]string{}
+		err := json.NewDecoder(rw.Body).Decode(&info)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{
+			"check_domain": checkDomain,
+			"doh_path":     dohPath,
+			"protocol":     "HTTP/2.0",
+			"tls_version":  "TLS 1.3",
+			"alpn":         "h2",
+			"server_name":  serverName,
+			"client_addr":  r.RemoteAddr,
+		}, info)
+	})
+
+	t.Run("bad_method", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodPost, websvc.PathDoHTest, nil)
+		rw := httptest.NewRecorder()
+		svc.ServeHTTP(rw, r)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		disabled := websvc.New(&websvc.Config{
+			StaticContent: http.NotFoundHandler(),
+		})
+
+		r := httptest.NewRequest(http.MethodGet, websvc.PathDoHTest, nil)
+		rw := httptest.NewRecorder()
+		disabled.ServeHTTP(rw, r)
+
+		assert.Equal(t, http.StatusNotFound, rw.Code)
+	})
+}
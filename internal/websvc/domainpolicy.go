@@ -0,0 +1,195 @@
+package websvc
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/golibs/httphdr"
+)
+
+// DomainPolicy is the HTTP security policy of a domain served by the web
+// service.
+type DomainPolicy struct {
+	// HSTS is the optional configuration of the Strict-Transport-Security
+	// header.  If it is nil, the header is not sent.
+	HSTS *HSTSConfig
+
+	// RedirectHTTPS, if true, makes the web service redirect plain-HTTP
+	// requests to HTTPS.
+	RedirectHTTPS bool
+
+	// SecurityHeaders, if true, makes the web service add the common security
+	// headers, such as X-Content-Type-Options, to the responses.
+	SecurityHeaders bool
+}
+
+// HSTSConfig is the configuration of the Strict-Transport-Security header.
+type HSTSConfig struct {
+	// MaxAge is the time during which the browsers must only use HTTPS to
+	// access the domain.  It must be positive.
+	MaxAge time.Duration
+
+	// IncludeSubdomains, if true, makes the policy apply to the subdomains as
+	// well.
+	IncludeSubdomains bool
+
+	// Preload, if true, allows the domain to be included into the preload
+	// lists of the browsers.
+	Preload bool
+}
+
+// HSTSPreloadMinMaxAge is the minimum max-age of the HSTS header required for
+// the domain to be included into the preload list.
+//
+// See https://hstspreload.org/#submission-requirements.
+const HSTSPreloadMinMaxAge = 365 * 24 * time.Hour
+
+// headerValue returns the value of the Strict-Transport-Security header.
+func (c *HSTSConfig) headerValue() (v string) {
+	b := &strings.Builder{}
+	_, _ = b.WriteString("max-age=")
+	_, _ = b.WriteString(strconv.FormatInt(int64(c.MaxAge/time.Second), 10))
+
+	if c.IncludeSubdomains {
+		_, _ = b.WriteString("; includeSubDomains")
+	}
+
+	if c.Preload {
+		_, _ = b.WriteString("; preload")
+	}
+
+	return b.String()
+}
+
+// DomainPolicyStorage is the storage of the HTTP security policies of the
+// domains.
+//
+// All methods must be safe for concurrent use.
+type DomainPolicyStorage interface {
+	// DomainPolicy returns the policy for host, which must be a lowercase
+	// domain name without a port.  p is nil if there is no policy for host.
+	// p must not be modified.
+	DomainPolicy(ctx context.Context, host string) (p *DomainPolicy)
+}
+
+// type check
+var _ DomainPolicyStorage = EmptyDomainPolicyStorage{}
+
+// EmptyDomainPolicyStorage is a [DomainPolicyStorage] implementation that has
+// no policies.
+type EmptyDomainPolicyStorage struct{}
+
+// DomainPolicy implements the [DomainPolicyStorage] interface for
+// EmptyDomainPolicyStorage.  p is always nil.
+func (EmptyDomainPolicyStorage) DomainPolicy(_ context.Context, _ string) (p *DomainPolicy) {
+	return nil
+}
+
+// DefaultDomainPolicyStorage is a [DomainPolicyStorage] implementation with
+// static policies from the configuration file.
+//
+// The keys of the map are either lowercase domain names, for exact matches, or
+// lowercase wildcard domain names, such as "*.example.com", which match the
+// direct subdomains of the domain.
+type DefaultDomainPolicyStorage struct {
+	policies map[string]*DomainPolicy
+}
+
+// NewDefaultDomainPolicyStorage returns a new properly initialized
+// *DefaultDomainPolicyStorage.  policies must not be modified after calling
+// NewDefaultDomainPolicyStorage.
+func NewDefaultDomainPolicyStorage(
+	policies map[string]*DomainPolicy,
+) (s *DefaultDomainPolicyStorage) {
+	return &DefaultDomainPolicyStorage{
+		policies: policies,
+	}
+}
+
+// type check
+var _ DomainPolicyStorage = (*DefaultDomainPolicyStorage)(nil)
+
+// DomainPolicy implements the [DomainPolicyStorage] interface for
+// *DefaultDomainPolicyStorage.
+func (s *DefaultDomainPolicyStorage) DomainPolicy(
+	_ context.Context,
+	host string,
+) (p *DomainPolicy) {
+	return policyFor(s.policies, host)
+}
+
+// policyFor returns the policy for host from policies, first looking up the
+// exact match and then the wildcard one.
+func policyFor(policies map[string]*DomainPolicy, host string) (p *DomainPolicy) {
+	if len(policies) == 0 {
+		return nil
+	}
+
+	p = policies[host]
+	if p != nil {
+		return p
+	}
+
+	_, parent, ok := strings.Cut(host, ".")
+	if !ok {
+		return nil
+	}
+
+	return policies["*."+parent]
+}
+
+// requestHost returns the lowercase host of r without the port.
+func requestHost(r *http.Request) (host string) {
+	host = r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.ToLower(host)
+}
+
+// applyDomainPolicy applies the policy of the domain of r from s, if there is
+// one.  If r has been redirected to HTTPS, applyDomainPolicy writes the
+// response and returns true.  Otherwise, it only sets the headers required by
+// the policy.  s must not be nil.
+func applyDomainPolicy(
+	s DomainPolicyStorage,
+	respHdr http.Header,
+	w http.ResponseWriter,
+	r *http.Request,
+) (redirected bool) {
+	p := s.DomainPolicy(r.Context(), requestHost(r))
+	if p == nil {
+		return false
+	}
+
+	if r.TLS == nil && p.RedirectHTTPS {
+		u := *r.URL
+		u.Scheme = "https"
+		u.Host = r.Host
+		http.Redirect(w, r, u.String(), http.StatusMovedPermanently)
+
+		metrics.WebSvcHTTPSRedirectRequestsTotal.Inc()
+
+		return true
+	}
+
+	// Only send the HSTS header over HTTPS, see RFC 6797, Section 7.2.
+	if r.TLS != nil && p.HSTS != nil {
+		respHdr.Set(httphdr.StrictTransportSecurity, p.HSTS.headerValue())
+	}
+
+	if p.SecurityHeaders {
+		respHdr.Set(httphdr.XContentTypeOptions, agdhttp.HdrValNoSniff)
+		respHdr.Set(agdhttp.HdrXFrameOptions, agdhttp.HdrValDeny)
+		respHdr.Set(agdhttp.HdrReferrerPolicy, agdhttp.HdrValNoReferrer)
+	}
+
+	return false
+}
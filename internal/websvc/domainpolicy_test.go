@@ -0,0 +1,156 @@
+package websvc_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/websvc"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ServeHTTP_domainPolicy(t *testing.T) {
+	t.Parallel()
+
+	const (
+		hostHSTS     = "hsts.example"
+		hostRedirect = "redirect.example"
+		hostExact    = "exact." + hostRedirect
+		hostOther    = "other.example"
+	)
+
+	hsts := &websvc.HSTSConfig{
+		MaxAge:            websvc.HSTSPreloadMinMaxAge,
+		IncludeSubdomains: true,
+		Preload:           true,
+	}
+
+	policies := websvc.NewDefaultDomainPolicyStorage(map[string]*websvc.DomainPolicy{
+		hostHSTS: {
+			HSTS:            hsts,
+			SecurityHeaders: true,
+		},
+		"*." + hostRedirect: {
+			RedirectHTTPS: true,
+		},
+		hostExact: {
+			HSTS: &websvc.HSTSConfig{
+				MaxAge: 1 * time.Hour,
+			},
+		},
+	})
+
+	svc := websvc.New(&websvc.Config{
+		StaticContent:  http.NotFoundHandler(),
+		DomainPolicies: policies,
+		DNSCheck: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}),
+	})
+	require.NotNil(t, svc)
+
+	testCases := []struct {
+		wantHdr      http.Header
+		name         string
+		host         string
+		wantLocation string
+		isTLS        bool
+		wantCode     int
+	}{{
+		wantHdr: http.Header{
+			httphdr.StrictTransportSecurity: {"max-age=31536000; includeSubDomains; preload"},
+			httphdr.XContentTypeOptions:     {agdhttp.HdrValNoSniff},
+			agdhttp.HdrXFrameOptions:        {agdhttp.HdrValDeny},
+			agdhttp.HdrReferrerPolicy:       {agdhttp.HdrValNoReferrer},
+		},
+		name:         "hsts_tls",
+		host:         hostHSTS,
+		wantLocation: "",
+		isTLS:        true,
+		wantCode:     http.StatusOK,
+	}, {
+		wantHdr: http.Header{
+			httphdr.XContentTypeOptions: {agdhttp.HdrValNoSniff},
+			agdhttp.HdrXFrameOptions:    {agdhttp.HdrValDeny},
+			agdhttp.HdrReferrerPolicy:   {agdhttp.HdrValNoReferrer},
+		},
+		name:         "hsts_plain",
+		host:         hostHSTS,
+		wantLocation: "",
+		isTLS:        false,
+		wantCode:     http.StatusOK,
+	}, {
+		wantHdr:      http.Header{},
+		name:         "redirect_wildcard",
+		host:         "www." + hostRedirect + ":8080",
+		wantLocation: "https://www." + hostRedirect + ":8080/dnscheck/test",
+		isTLS:        false,
+		wantCode:     http.StatusMovedPermanently,
+	}, {
+		wantHdr:      http.Header{},
+		name:         "redirect_wildcard_tls",
+		host:         "www." + hostRedirect,
+		wantLocation: "",
+		isTLS:        true,
+		wantCode:     http.StatusOK,
+	}, {
+		wantHdr:      http.Header{},
+		name:         "exact_over_wildcard_plain",
+		host:         hostExact,
+		wantLocation: "",
+		isTLS:        false,
+		wantCode:     http.StatusOK,
+	}, {
+		wantHdr: http.Header{
+			httphdr.StrictTransportSecurity: {"max-age=3600"},
+		},
+		name:         "exact_over_wildcard_tls",
+		host:         hostExact,
+		wantLocation: "",
+		isTLS:        true,
+		wantCode:     http.StatusOK,
+	}, {
+		wantHdr:      http.Header{},
+		name:         "other",
+		host:         hostOther,
+		wantLocation: "",
+		isTLS:        false,
+		wantCode:     http.StatusOK,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r := httptest.NewRequest(http.MethodGet, "/dnscheck/test", nil)
+			r.Host = tc.host
+			if tc.isTLS {
+				r.TLS = &tls.ConnectionState{}
+			}
+
+			rw := httptest.NewRecorder()
+			svc.ServeHTTP(rw, r)
+
+			assert.Equal(t, tc.wantCode, rw.Code)
+
+			hdr := rw.Header()
+			assert.Equal(t, tc.wantLocation, hdr.Get(httphdr.Location))
+
+			for k := range hdr {
+				if k != httphdr.StrictTransportSecurity &&
+					k != httphdr.XContentTypeOptions &&
+					k != agdhttp.HdrXFrameOptions &&
+					k != agdhttp.HdrReferrerPolicy {
+					hdr.Del(k)
+				}
+			}
+
+			assert.Equal(t, tc.wantHdr, hdr)
+		})
+	}
+}
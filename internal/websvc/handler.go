@@ -35,6 +35,10 @@ func (svc *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if applyDomainPolicy(svc.domainPolicies, respHdr, w, r) {
+		return
+	}
+
 	// TODO(a.garipov):  Refactor the 404 and 500 handling and use
 	// [httputil.CodeRecorderResponseWriter] instead.
 	rec := httptest.NewRecorder()
@@ -55,6 +59,10 @@ func (svc *Service) serveHTTP(rec *httptest.ResponseRecorder, r *http.Request) {
 		svc.dnsCheck.ServeHTTP(rec, r)
 
 		metrics.WebSvcDNSCheckTestRequestsTotal.Inc()
+	case PathDeviceStats:
+		svc.serveDeviceStats(rec, r)
+	case PathDoHTest, PathDoHTestInfo, PathDoHTestScript:
+		svc.serveDoHTest(rec, r)
 	case "/robots.txt":
 		serveRobotsDisallow(rec.Header(), rec, "handler")
 	case "/":
@@ -0,0 +1,100 @@
+package websvc
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdhttp"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtime"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/httphdr"
+	"github.com/AdguardTeam/golibs/log"
+)
+
+// PathUnblock is the path of the temporary-unblocking API on the block-page
+// servers.
+const PathUnblock = "/unblock"
+
+// FormKeyUnblockToken is the name of the form field containing the signed
+// unblock token.  See [unblock.NewToken].
+const FormKeyUnblockToken = "token"
+
+// UnblockConfig is the configuration of the temporary-unblocking API.
+type UnblockConfig struct {
+	// Clock is used to check the expiration of the tokens and to set the
+	// expiration of the exceptions.  It must not be nil.
+	Clock agdtime.Clock
+
+	// Storage is used to store the exceptions.  It must not be nil.
+	Storage unblock.Storage
+
+	// Redeemed is used to make sure that each token is only redeemed once.  It
+	// must not be nil.
+	Redeemed *unblock.RedeemedTokens
+
+	// Key is the key used to verify the tokens.  It must be at least
+	// [unblock.MinKeyLen] bytes long.
+	Key []byte
+
+	// MaxDuration is the maximum duration of an exception.  It must be
+	// positive.
+	MaxDuration time.Duration
+}
+
+// unblockResp is the response of the temporary-unblocking API.
+type unblockResp struct {
+	// Expires is the time after which the exception is no longer applied.
+	Expires time.Time `json:"expires"`
+
+	// Host is the domain name allowed by the exception.
+	Host string `json:"host"`
+}
+
+// serveUnblock verifies the unblock token from the form in r and, if it's
+// valid, adds a temporary exception for the profile or the device.
+func (srv *blockPageServer) serveUnblock(w http.ResponseWriter, r *http.Request) {
+	c := srv.unblock
+	if c == nil {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	metrics.WebSvcUnblockRequestsTotal.Inc()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+
+		return
+	}
+
+	token := r.PostFormValue(FormKeyUnblockToken)
+	e, err := unblock.ParseToken(c.Key, token, c.Clock.Now(), c.MaxDuration, c.Redeemed)
+	if err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, unblock.ErrTokenExpired) || errors.Is(err, unblock.ErrTokenRedeemed) {
+			code = http.StatusForbidden
+		}
+
+		http.Error(w, err.Error(), code)
+
+		return
+	}
+
+	ctx := r.Context()
+	c.Storage.Add(ctx, e)
+
+	log.Debug("websvc: %s: unblocked %q for profile %q", srv.name, e.Host, e.ProfileID)
+
+	w.Header().Set(httphdr.ContentType, agdhttp.HdrValApplicationJSON)
+	err = json.NewEncoder(w).Encode(&unblockResp{
+		Expires: e.Expires,
+		Host:    e.Host,
+	})
+	if err != nil {
+		logErrorByType(err, "websvc: %s: unblock: writing response: %s", srv.name, err)
+	}
+}
@@ -0,0 +1,135 @@
+package websvc_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdtest"
+	"github.com/AdguardTeam/AdGuardDNS/internal/unblock"
+	"github.com/AdguardTeam/AdGuardDNS/internal/websvc"
+	"github.com/AdguardTeam/golibs/netutil/urlutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockPageServers_unblock(t *testing.T) {
+	const (
+		profID agd.ProfileID = "prof1234"
+		devID  agd.DeviceID  = "dev1234"
+
+		host = "blocked.example"
+	)
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	now := time.Unix(1_700_000_000, 0).UTC()
+	strg := unblock.NewDefaultStorage(&unblock.DefaultStorageConfig{
+		Clock: &agdtest.Clock{
+			OnNow: func() (n time.Time) { return now },
+		},
+		MaxPerProfile: 1,
+	})
+
+	addr := netip.MustParseAddrPort("127.0.0.1:3004")
+	conf := &websvc.Config{
+		GeneralBlocking: &websvc.BlockPageServerConfig{
+			ContentFilePath: filepath.Join("testdata", blockPageFileName),
+			Bind: []*websvc.BindData{{
+				TLS:     nil,
+				Address: addr,
+			}},
+		},
+		Unblock: &websvc.UnblockConfig{
+			Clock: &agdtest.Clock{
+				OnNow: func() (n time.Time) { return now },
+			},
+			Storage:     strg,
+			Redeemed:    unblock.NewRedeemedTokens(),
+			Key:         key,
+			MaxDuration: time.Hour,
+		},
+		Timeout: testTimeout,
+	}
+
+	startService(t, conf)
+
+	u := (&url.URL{
+		Scheme: urlutil.SchemeHTTP,
+		Host:   addr.String(),
+		Path:   websvc.PathUnblock,
+	}).String()
+
+	c := &http.Client{
+		Timeout: testTimeout,
+	}
+
+	post := func(token string) (resp *http.Response) {
+		t.Helper()
+
+		var err error
+		require.Eventually(t, func() (ok bool) {
+			resp, err = c.PostForm(u, url.Values{
+				websvc.FormKeyUnblockToken: []string{token},
+			})
+
+			return err == nil
+		}, testTimeout, testTimeout/10)
+
+		testutil.CleanupAndRequireSuccess(t, resp.Body.Close)
+
+		return resp
+	}
+
+	t.Run("bad_token", func(t *testing.T) {
+		resp := post("bad")
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		resp := post(unblock.NewToken(key, &unblock.TokenData{
+			Expires:   now.Add(-time.Minute),
+			ProfileID: profID,
+			DeviceID:  devID,
+			Host:      host,
+			Duration:  30 * time.Minute,
+		}))
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	token := unblock.NewToken(key, &unblock.TokenData{
+		Expires:   now.Add(time.Minute),
+		ProfileID: profID,
+		DeviceID:  devID,
+		Host:      host,
+		Duration:  30 * time.Minute,
+	})
+
+	t.Run("success", func(t *testing.T) {
+		resp := post(token)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var got struct {
+			Expires time.Time `json:"expires"`
+			Host    string    `json:"host"`
+		}
+		err := json.NewDecoder(resp.Body).Decode(&got)
+		require.NoError(t, err)
+
+		assert.Equal(t, host, got.Host)
+		assert.True(t, now.Add(30*time.Minute).Equal(got.Expires))
+
+		ctx := testutil.ContextWithTimeout(t, testTimeout)
+		assert.True(t, strg.Match(ctx, profID, devID, host))
+	})
+
+	t.Run("redeemed", func(t *testing.T) {
+		resp := post(token)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
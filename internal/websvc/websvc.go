@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdcache"
 	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
 	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
 	"github.com/AdguardTeam/golibs/container"
@@ -43,6 +44,24 @@ type Config struct {
 	// DNSCheck is the HTTP handler for DNS checks.
 	DNSCheck http.Handler
 
+	// DeviceStats is the optional configuration of the per-device statistics
+	// API.  If it is nil, the API is not served.
+	DeviceStats *DeviceStatsConfig
+
+	// DoHTest is the optional configuration of the built-in DNS-over-HTTPS test
+	// page.  If it is nil, the page is not served.
+	DoHTest *DoHTestConfig
+
+	// Unblock is the optional configuration of the temporary-unblocking API
+	// served by the block-page servers.  If it is nil, the API is not served.
+	Unblock *UnblockConfig
+
+	// DomainPolicies is the optional storage of the HTTP security policies of
+	// the domains, which are applied to the block pages and the main handler,
+	// including the DNS check endpoints.  If it is nil, no policies are
+	// applied.
+	DomainPolicies DomainPolicyStorage
+
 	// ErrColl is used to collect linked IP proxy errors and other errors.
 	ErrColl errcoll.Interface
 
@@ -89,6 +108,13 @@ type Service struct {
 
 	dnsCheck http.Handler
 
+	domainPolicies DomainPolicyStorage
+
+	deviceStats     *DeviceStatsConfig
+	deviceAuthCache agdcache.Interface[deviceAuthKey, struct{}]
+
+	dohTest *DoHTestConfig
+
 	error404 []byte
 	error500 []byte
 
@@ -111,15 +137,42 @@ func New(c *Config) (svc *Service) {
 		return nil
 	}
 
-	adultBlockingBPS := newBlockPageServer(c.AdultBlocking, adultBlockingName)
-	generalBlockingBPS := newBlockPageServer(c.GeneralBlocking, generalBlockingName)
-	safeBrowsingBPS := newBlockPageServer(c.SafeBrowsing, safeBrowsingName)
+	domainPolicies := c.DomainPolicies
+	if domainPolicies == nil {
+		domainPolicies = EmptyDomainPolicyStorage{}
+	}
+
+	adultBlockingBPS := newBlockPageServer(
+		c.AdultBlocking,
+		c.Unblock,
+		domainPolicies,
+		adultBlockingName,
+	)
+	generalBlockingBPS := newBlockPageServer(
+		c.GeneralBlocking,
+		c.Unblock,
+		domainPolicies,
+		generalBlockingName,
+	)
+	safeBrowsingBPS := newBlockPageServer(
+		c.SafeBrowsing,
+		c.Unblock,
+		domainPolicies,
+		safeBrowsingName,
+	)
 
 	svc = &Service{
 		staticContent: c.StaticContent,
 
 		dnsCheck: c.DNSCheck,
 
+		domainPolicies: domainPolicies,
+
+		deviceStats:     c.DeviceStats,
+		deviceAuthCache: agdcache.Empty[deviceAuthKey, struct{}]{},
+
+		dohTest: c.DoHTest,
+
 		error404: c.Error404,
 		error500: c.Error500,
 
@@ -132,6 +185,10 @@ func New(c *Config) (svc *Service) {
 		safeBrowsing:    blockPageServers(safeBrowsingBPS, c.Timeout),
 	}
 
+	if c.DeviceStats != nil {
+		svc.deviceAuthCache = newDeviceAuthCache()
+	}
+
 	if c.RootRedirectURL != nil {
 		// Use a string to prevent allocation of a string on every call to the
 		// main handler.
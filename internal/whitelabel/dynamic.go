@@ -0,0 +1,46 @@
+package whitelabel
+
+import (
+	"context"
+	"sync"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+)
+
+// Dynamic is an [Interface] implementation, the settings of which are replaced
+// using [Dynamic.Update].
+type Dynamic struct {
+	// mu protects settings.
+	mu       *sync.RWMutex
+	settings map[agd.ServerGroupName]*Settings
+}
+
+// NewDynamic returns a new properly initialized *Dynamic without any settings.
+func NewDynamic() (d *Dynamic) {
+	return &Dynamic{
+		mu: &sync.RWMutex{},
+	}
+}
+
+// type check
+var _ Interface = (*Dynamic)(nil)
+
+// ServerGroupSettings implements the [Interface] interface for *Dynamic.
+func (d *Dynamic) ServerGroupSettings(
+	_ context.Context,
+	name agd.ServerGroupName,
+) (s *Settings) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.settings[name]
+}
+
+// Update replaces the current settings of all server groups with settings.
+// settings must not be modified after calling Update.
+func (d *Dynamic) Update(settings map[agd.ServerGroupName]*Settings) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.settings = settings
+}
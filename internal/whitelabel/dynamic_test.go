@@ -0,0 +1,39 @@
+package whitelabel_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/whitelabel"
+	"github.com/AdguardTeam/golibs/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynamic(t *testing.T) {
+	t.Parallel()
+
+	const (
+		grpName      agd.ServerGroupName = "default"
+		otherGrpName agd.ServerGroupName = "other"
+	)
+
+	s := &whitelabel.Settings{
+		DDRDeviceTargets: container.NewMapSet("d.partner.example"),
+		DDRPublicTargets: container.NewMapSet("dns.partner.example"),
+		DeviceDomains:    []string{"d.partner.example"},
+	}
+
+	ctx := context.Background()
+	d := whitelabel.NewDynamic()
+	assert.Nil(t, d.ServerGroupSettings(ctx, grpName))
+
+	d.Update(map[agd.ServerGroupName]*whitelabel.Settings{
+		grpName: s,
+	})
+	assert.Same(t, s, d.ServerGroupSettings(ctx, grpName))
+	assert.Nil(t, d.ServerGroupSettings(ctx, otherGrpName))
+
+	d.Update(nil)
+	assert.Nil(t, d.ServerGroupSettings(ctx, grpName))
+}
@@ -0,0 +1,57 @@
+// Package whitelabel contains the dynamic settings of the server groups, which
+// are received from the backend and allow onboarding white-label partners
+// without restarting AdGuard DNS.
+package whitelabel
+
+import (
+	"context"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/golibs/container"
+)
+
+// Settings are the dynamic settings of a server group.  They supplement the
+// static settings of the server group from the configuration file.
+type Settings struct {
+	// DDRDeviceTargets is the set of the additional domain names, subdomains
+	// of which should be checked for DDR queries with device IDs.  It must not
+	// be nil.
+	DDRDeviceTargets *container.MapSet[string]
+
+	// DDRPublicTargets is the set of the additional public domain names, DDR
+	// queries for which should be processed.  It must not be nil.
+	DDRPublicTargets *container.MapSet[string]
+
+	// ValidationTokens are the TXT-record tokens of the pending custom domains
+	// by their lowercase non-FQDN domain names.  The queries for the
+	// _acme-challenge subdomains of these domains are answered with the tokens,
+	// which allows validating the domains without an HTTP server.  Values must
+	// not be empty.
+	ValidationTokens map[string][]string
+
+	// DeviceDomains are the additional domain names used to detect device IDs
+	// from clients' server names.
+	DeviceDomains []string
+}
+
+// Interface is the storage of the dynamic settings of the server groups.
+//
+// All methods must be safe for concurrent use.
+type Interface interface {
+	// ServerGroupSettings returns the dynamic settings of the server group
+	// with the given name.  s is nil if there are none.  s must not be
+	// modified.
+	ServerGroupSettings(ctx context.Context, name agd.ServerGroupName) (s *Settings)
+}
+
+// type check
+var _ Interface = Empty{}
+
+// Empty is an [Interface] implementation that has no settings.
+type Empty struct{}
+
+// ServerGroupSettings implements the [Interface] interface for Empty.  s is
+// always nil.
+func (Empty) ServerGroupSettings(_ context.Context, _ agd.ServerGroupName) (s *Settings) {
+	return nil
+}
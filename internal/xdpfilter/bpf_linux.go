@@ -0,0 +1,125 @@
+//go:build linux
+
+package xdpfilter
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"github.com/AdguardTeam/golibs/errors"
+	"golang.org/x/sys/unix"
+)
+
+// bpfMap is a BPF map pinned into the BPF file system.
+type bpfMap struct {
+	fd int
+}
+
+// bpfObjGetAttr is the part of union bpf_attr used by the BPF_OBJ_GET command.
+type bpfObjGetAttr struct {
+	pathname  uint64
+	bpfFD     uint32
+	fileFlags uint32
+}
+
+// bpfMapElemAttr is the part of union bpf_attr used by the commands that
+// operate on the map elements.
+type bpfMapElemAttr struct {
+	mapFD uint32
+	_     uint32
+	key   uint64
+	value uint64
+	flags uint64
+}
+
+// bpfNoExist is the BPF_NOEXIST flag of BPF_MAP_UPDATE_ELEM, which makes it
+// fail if the element already exists.
+const bpfNoExist = 1
+
+// openPinnedMap opens the BPF map pinned at path.  The file descriptor is kept
+// open for the lifetime of the process.
+func openPinnedMap(path string) (m *bpfMap, err error) {
+	p, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return nil, fmt.Errorf("path: %w", err)
+	}
+
+	attr := &bpfObjGetAttr{
+		pathname:  uint64(uintptr(unsafe.Pointer(p))),
+		fileFlags: 0,
+	}
+
+	fd, err := bpf(unix.BPF_OBJ_GET, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+	runtime.KeepAlive(p)
+	if err != nil {
+		return nil, fmt.Errorf("getting pinned object %q: %w", path, err)
+	}
+
+	return &bpfMap{
+		fd: fd,
+	}, nil
+}
+
+// add adds the element with key and val to m.  added is false if the element
+// already exists.
+func (m *bpfMap) add(key, val []byte) (added bool, err error) {
+	err = m.elemCmd(unix.BPF_MAP_UPDATE_ELEM, key, val, bpfNoExist)
+	if errors.Is(err, unix.EEXIST) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// delete deletes the element with key from m.  deleted is false if there is no
+// such element.
+func (m *bpfMap) delete(key []byte) (deleted bool, err error) {
+	err = m.elemCmd(unix.BPF_MAP_DELETE_ELEM, key, nil, 0)
+	if errors.Is(err, unix.ENOENT) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// lookup reads the value of the element with key from m into val.  val must
+// have the size of the values of m.
+func (m *bpfMap) lookup(key, val []byte) (err error) {
+	return m.elemCmd(unix.BPF_MAP_LOOKUP_ELEM, key, val, 0)
+}
+
+// elemCmd runs the element command cmd on m.  key must not be empty.
+func (m *bpfMap) elemCmd(cmd int, key, val []byte, flags uint64) (err error) {
+	attr := &bpfMapElemAttr{
+		// #nosec G115 -- File descriptors are never negative.
+		mapFD: uint32(m.fd),
+		key:   uint64(uintptr(unsafe.Pointer(&key[0]))),
+		flags: flags,
+	}
+
+	if len(val) > 0 {
+		attr.value = uint64(uintptr(unsafe.Pointer(&val[0])))
+	}
+
+	_, err = bpf(cmd, unsafe.Pointer(attr), unsafe.Sizeof(*attr))
+	runtime.KeepAlive(key)
+	runtime.KeepAlive(val)
+
+	return err
+}
+
+// bpf calls the bpf system call with the command cmd and the attributes attr
+// of the size sz.
+func bpf(cmd int, attr unsafe.Pointer, sz uintptr) (res int, err error) {
+	r, _, errno := unix.Syscall(unix.SYS_BPF, uintptr(cmd), uintptr(attr), sz)
+	if errno != 0 {
+		return 0, errno
+	}
+
+	return int(r), nil
+}
@@ -0,0 +1,32 @@
+//go:build !linux
+
+package xdpfilter
+
+import "github.com/AdguardTeam/golibs/errors"
+
+// errNotSupported is returned by the BPF operations on the OSs other than
+// Linux.
+const errNotSupported errors.Error = "bpf maps are only supported on linux"
+
+// bpfMap is a stub of a pinned BPF map.
+type bpfMap struct{}
+
+// openPinnedMap returns an error, since BPF maps are only supported on Linux.
+func openPinnedMap(_ string) (m *bpfMap, err error) {
+	return nil, errNotSupported
+}
+
+// add returns an error, since BPF maps are only supported on Linux.
+func (m *bpfMap) add(_, _ []byte) (added bool, err error) {
+	return false, errNotSupported
+}
+
+// delete returns an error, since BPF maps are only supported on Linux.
+func (m *bpfMap) delete(_ []byte) (deleted bool, err error) {
+	return false, errNotSupported
+}
+
+// lookup returns an error, since BPF maps are only supported on Linux.
+func (m *bpfMap) lookup(_, _ []byte) (err error) {
+	return errNotSupported
+}
@@ -0,0 +1,173 @@
+// Package xdpfilter contains the integration with an XDP program, which drops
+// the packets from the banned subnets in the kernel before they reach the DNS
+// servers.  The program and its maps are loaded and pinned by an external
+// loader; see scripts/xdpfilter for the reference one.
+package xdpfilter
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"sync/atomic"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agdservice"
+	"github.com/AdguardTeam/AdGuardDNS/internal/dnsserver/netext"
+	"github.com/AdguardTeam/AdGuardDNS/internal/errcoll"
+	"github.com/AdguardTeam/AdGuardDNS/internal/metrics"
+)
+
+// Sizes of the keys and values of the pinned maps.  Keep in sync with
+// scripts/xdpfilter/xdpfilter.c.
+const (
+	// banKeyLen is the size of struct bpf_lpm_trie_key with an IPv6 address,
+	// which is a 32-bit prefix length followed by the address.
+	banKeyLen = 4 + 16
+
+	// banValLen is the size of the value of the bans map.
+	banValLen = 1
+
+	// dropsKeyLen is the size of the key of the drops map.
+	dropsKeyLen = 4
+
+	// dropsValLen is the size of the value of the drops map.
+	dropsValLen = 8
+)
+
+// ipv4MappedPrefixLen is the length of the prefix of the IPv4-mapped IPv6
+// addresses, which are used to store IPv4 subnets in the bans map.
+const ipv4MappedPrefixLen = 96
+
+// Config is the configuration structure for a *Filter.
+type Config struct {
+	// Logger is used to log the operation of the filter.  It must not be nil.
+	Logger *slog.Logger
+
+	// ErrColl is used to collect the errors of updating the maps.  It must not
+	// be nil.
+	ErrColl errcoll.Interface
+
+	// BansPath is the path to the pinned BPF_MAP_TYPE_LPM_TRIE map with the
+	// banned subnets.  It must not be empty.
+	BansPath string
+
+	// DropsPath is the path to the pinned BPF_MAP_TYPE_ARRAY map with a single
+	// 64-bit counter of the dropped packets.  It must not be empty.
+	DropsPath string
+}
+
+// Filter is a [netext.PacketFilter] that updates the maps of an XDP program
+// pinned into the BPF file system.
+type Filter struct {
+	logger  *slog.Logger
+	errColl errcoll.Interface
+	bans    *bpfMap
+	drops   *bpfMap
+
+	// lastDropped is the value of the drops counter at the previous refresh.
+	lastDropped *atomic.Uint64
+}
+
+// New returns a new properly initialized *Filter.  c must be valid.  It
+// returns an error if the pinned maps cannot be opened, for example if the
+// program hasn't been loaded or if the current OS isn't Linux.
+func New(c *Config) (f *Filter, err error) {
+	bans, err := openPinnedMap(c.BansPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening bans map: %w", err)
+	}
+
+	drops, err := openPinnedMap(c.DropsPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening drops map: %w", err)
+	}
+
+	return &Filter{
+		logger:      c.Logger,
+		errColl:     c.ErrColl,
+		bans:        bans,
+		drops:       drops,
+		lastDropped: &atomic.Uint64{},
+	}, nil
+}
+
+// type check
+var _ netext.PacketFilter = (*Filter)(nil)
+
+// Ban implements the [netext.PacketFilter] interface for *Filter.
+func (f *Filter) Ban(ctx context.Context, subnet netip.Prefix) {
+	added, err := f.bans.add(banKey(subnet), make([]byte, banValLen))
+	if err != nil {
+		err = fmt.Errorf("banning %s: %w", subnet, err)
+		errcoll.Collect(ctx, f.errColl, f.logger, "updating xdp filter", err)
+
+		return
+	}
+
+	if added {
+		metrics.XDPFilterBannedSubnets.Inc()
+		f.logger.DebugContext(ctx, "banned", "subnet", subnet)
+	}
+}
+
+// Unban implements the [netext.PacketFilter] interface for *Filter.
+func (f *Filter) Unban(ctx context.Context, subnet netip.Prefix) {
+	deleted, err := f.bans.delete(banKey(subnet))
+	if err != nil {
+		err = fmt.Errorf("unbanning %s: %w", subnet, err)
+		errcoll.Collect(ctx, f.errColl, f.logger, "updating xdp filter", err)
+
+		return
+	}
+
+	if deleted {
+		metrics.XDPFilterBannedSubnets.Dec()
+		f.logger.DebugContext(ctx, "unbanned", "subnet", subnet)
+	}
+}
+
+// type check
+var _ agdservice.Refresher = (*Filter)(nil)
+
+// Refresh implements the [agdservice.Refresher] interface for *Filter.  It
+// reads the counter of the dropped packets and updates the metrics.
+func (f *Filter) Refresh(ctx context.Context) (err error) {
+	val := make([]byte, dropsValLen)
+	err = f.drops.lookup(make([]byte, dropsKeyLen), val)
+	if err != nil {
+		err = fmt.Errorf("reading drops counter: %w", err)
+		errcoll.Collect(ctx, f.errColl, f.logger, "refreshing xdp filter", err)
+
+		return err
+	}
+
+	n := binary.NativeEndian.Uint64(val)
+	prev := f.lastDropped.Swap(n)
+	if n >= prev {
+		metrics.XDPFilterDroppedTotal.Add(float64(n - prev))
+	} else {
+		// The program has been reloaded and the counter has been reset.
+		metrics.XDPFilterDroppedTotal.Add(float64(n))
+	}
+
+	return nil
+}
+
+// banKey returns the key of the bans map for subnet.  IPv4 subnets are stored
+// as IPv4-mapped IPv6 ones.
+func banKey(subnet netip.Prefix) (key []byte) {
+	addr, bits := subnet.Addr(), subnet.Bits()
+	if addr.Is4() {
+		addr = netip.AddrFrom16(addr.As16())
+		bits += ipv4MappedPrefixLen
+	}
+
+	key = make([]byte, 4, banKeyLen)
+
+	// #nosec G115 -- The prefix length is never negative or greater than 128.
+	binary.NativeEndian.PutUint32(key, uint32(bits))
+	a16 := addr.As16()
+
+	return append(key, a16[:]...)
+}
@@ -0,0 +1,44 @@
+package xdpfilter
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBanKey(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		subnet   netip.Prefix
+		wantAddr netip.Addr
+		name     string
+		wantBits uint32
+	}{{
+		subnet:   netip.MustParsePrefix("192.0.2.0/24"),
+		wantAddr: netip.MustParseAddr("::ffff:192.0.2.0"),
+		name:     "ipv4",
+		wantBits: 120,
+	}, {
+		subnet:   netip.MustParsePrefix("2001:db8::/56"),
+		wantAddr: netip.MustParseAddr("2001:db8::"),
+		name:     "ipv6",
+		wantBits: 56,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			key := banKey(tc.subnet)
+			assert.Len(t, key, banKeyLen)
+			assert.Equal(t, tc.wantBits, binary.NativeEndian.Uint32(key))
+
+			addr, ok := netip.AddrFromSlice(key[4:])
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantAddr, addr)
+		})
+	}
+}
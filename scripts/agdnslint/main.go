@@ -0,0 +1,97 @@
+// agdnslint validates the AdGuard DNS configuration file and environment
+// offline and prints the problems found as JSON objects, one per line.  It is
+// intended to be used in the CI of the repositories with the deployment
+// configurations.
+//
+// Usage:
+//
+//	go run ./scripts/agdnslint [-env FILE] [CONFIG]
+//
+// If CONFIG is not set, the path from the CONFIG_PATH environment variable is
+// used.  FILE is a file with the environment variables in the KEY=VALUE format,
+// one per line.  Empty lines and lines starting with "#" are ignored.  If FILE
+// is not set, the environment of the process is used.
+//
+// The exit code is zero if no problems are found.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/cmd"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/osutil"
+)
+
+func main() {
+	l := slogutil.New(nil)
+
+	envPath := flag.String("env", "", "path to the file with the environment variables")
+	flag.Parse()
+
+	if flag.NArg() > 1 {
+		flag.Usage()
+
+		os.Exit(osutil.ExitCodeArgumentError)
+	}
+
+	c := &cmd.LintConfig{
+		ConfPath: flag.Arg(0),
+	}
+
+	if *envPath != "" {
+		var err error
+		c.Environment, err = readEnvFile(*envPath)
+		if err != nil {
+			l.Error("reading environment", slogutil.KeyError, err)
+
+			os.Exit(osutil.ExitCodeArgumentError)
+		}
+	}
+
+	diags := cmd.Lint(c)
+
+	enc := json.NewEncoder(os.Stdout)
+	for _, d := range diags {
+		errors.Check(enc.Encode(d))
+	}
+
+	if len(diags) > 0 {
+		os.Exit(osutil.ExitCodeFailure)
+	}
+}
+
+// readEnvFile reads the environment variables from the file at path.
+func readEnvFile(path string) (vars map[string]string, err error) {
+	// #nosec G304 -- Trust the path given by the user.
+	f, err := os.Open(path)
+	if err != nil {
+		// Don't wrap the error, because it's informative enough as is.
+		return nil, err
+	}
+	defer func() { err = errors.WithDeferred(err, f.Close()) }()
+
+	vars = map[string]string{}
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: no %q", n, "=")
+		}
+
+		vars[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+
+	return vars, s.Err()
+}
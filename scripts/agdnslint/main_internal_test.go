@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadEnvFile(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		want       map[string]string
+		name       string
+		data       string
+		wantErrMsg string
+	}{{
+		want:       map[string]string{},
+		name:       "empty",
+		data:       "",
+		wantErrMsg: "",
+	}, {
+		want: map[string]string{
+			"CONFIG_PATH": "./config.yaml",
+			"VERBOSE":     "1",
+			"EMPTY":       "",
+			"WITH_EQUALS": "a=b",
+		},
+		name: "valid",
+		data: "# Comment.\n" +
+			"CONFIG_PATH=./config.yaml\n" +
+			"\n" +
+			"  VERBOSE = 1  \n" +
+			"EMPTY=\n" +
+			"WITH_EQUALS=a=b\n",
+		wantErrMsg: "",
+	}, {
+		want:       nil,
+		name:       "no_equals",
+		data:       "CONFIG_PATH=./config.yaml\nVERBOSE\n",
+		wantErrMsg: `line 2: no "="`,
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "env")
+			err := os.WriteFile(path, []byte(tc.data), 0o600)
+			require.NoError(t, err)
+
+			vars, err := readEnvFile(path)
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, err)
+
+			assert.Equal(t, tc.want, vars)
+		})
+	}
+}
+
+func TestReadEnvFile_notExist(t *testing.T) {
+	t.Parallel()
+
+	_, err := readEnvFile(filepath.Join(t.TempDir(), "env"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
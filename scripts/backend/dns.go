@@ -14,7 +14,9 @@ import (
 	"github.com/AdguardTeam/golibs/httphdr"
 	"github.com/AdguardTeam/golibs/logutil/slogutil"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
@@ -22,13 +24,16 @@ import (
 // mockDNSServiceServer is the mock [backendpb.DNSServiceServer].
 type mockDNSServiceServer struct {
 	backendpb.UnimplementedDNSServiceServer
-	log *slog.Logger
+	log      *slog.Logger
+	scenario *scenario
 }
 
-// newMockDNSServiceServer creates a new instance of *mockDNSServiceServer.
-func newMockDNSServiceServer(log *slog.Logger) (srv *mockDNSServiceServer) {
+// newMockDNSServiceServer creates a new instance of *mockDNSServiceServer.  sc
+// must not be nil.
+func newMockDNSServiceServer(log *slog.Logger, sc *scenario) (srv *mockDNSServiceServer) {
 	return &mockDNSServiceServer{
-		log: log,
+		log:      log,
+		scenario: sc,
 	}
 }
 
@@ -51,6 +56,13 @@ func (s *mockDNSServiceServer) CreateDeviceByHumanId(
 		"req", req,
 	)
 
+	err = s.scenario.createDeviceStep().apply(ctx)
+	if err != nil {
+		s.log.InfoContext(ctx, "responding with scripted error", slogutil.KeyError, err)
+
+		return nil, err
+	}
+
 	p := newDNSProfile()
 
 	return &backendpb.CreateDeviceResponse{
@@ -71,23 +83,75 @@ func (s *mockDNSServiceServer) GetDNSProfiles(
 		"getting dns profiles",
 		"auth", md.Get(httphdr.Authorization),
 		"sync_time", req.SyncTime.AsTime(),
+		"page_token", req.PageToken,
+		"page_size", req.PageSize,
 	)
 
+	st := s.scenario.profilesStep()
+	if st != nil {
+		err = st.apply(ctx)
+		if err != nil {
+			s.log.InfoContext(ctx, "responding with scripted error", slogutil.KeyError, err)
+
+			return err
+		}
+	}
+
 	t := time.Now()
 	syncTime := strconv.FormatInt(t.UnixMilli(), 10)
 	trailerMD := metadata.MD{
 		"sync_time": []string{syncTime},
 	}
 
+	profiles := st.profiles()
+	if req.SyncTime.AsTime().IsZero() && req.PageSize > 0 {
+		var nextPageToken string
+		profiles, nextPageToken, err = page(profiles, req.PageToken, int(req.PageSize))
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		if nextPageToken != "" {
+			trailerMD["next_page_token"] = []string{nextPageToken}
+		}
+	}
+
 	srv.SetTrailer(trailerMD)
-	err = srv.Send(newDNSProfile())
-	if err != nil {
-		s.log.WarnContext(ctx, "sending dns profile", slogutil.KeyError, err)
+	for _, p := range profiles {
+		err = srv.Send(p)
+		if err != nil {
+			s.log.WarnContext(ctx, "sending dns profile", slogutil.KeyError, err)
+
+			return nil
+		}
 	}
 
 	return nil
 }
 
+// page returns the page of profiles for a full synchronization starting from
+// the offset in pageToken as well as the token of the next page, if any.
+func page(
+	profiles []*backendpb.DNSProfile,
+	pageToken string,
+	pageSize int,
+) (pageProfiles []*backendpb.DNSProfile, nextPageToken string, err error) {
+	start := 0
+	if pageToken != "" {
+		start, err = strconv.Atoi(pageToken)
+		if err != nil || start < 0 || start > len(profiles) {
+			return nil, "", fmt.Errorf("bad page token %q", pageToken)
+		}
+	}
+
+	end := min(start+pageSize, len(profiles))
+	if end < len(profiles) {
+		nextPageToken = strconv.Itoa(end)
+	}
+
+	return profiles[start:end], nextPageToken, nil
+}
+
 // SaveDevicesBillingStat implements the [backendpb.DNSServiceServer] interface
 // for *mockDNSServiceServer
 func (s *mockDNSServiceServer) SaveDevicesBillingStat(
@@ -97,6 +161,13 @@ func (s *mockDNSServiceServer) SaveDevicesBillingStat(
 	md, _ := metadata.FromIncomingContext(ctx)
 	s.log.InfoContext(ctx, "saving devices", "auth", md.Get(httphdr.Authorization))
 
+	err = s.scenario.saveBillStatStep().apply(ctx)
+	if err != nil {
+		s.log.InfoContext(ctx, "responding with scripted error", slogutil.KeyError, err)
+
+		return err
+	}
+
 	for {
 		var bs *backendpb.DeviceBillingStat
 		bs, err = srv.Recv()
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/backendpb"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// testTimeout is the common timeout for tests.
+const testTimeout = 1 * time.Second
+
+// newTestDNSClient is a helper that starts the mock DNS service with the
+// scenario from data and returns a client connected to it.
+func newTestDNSClient(tb testing.TB, data string) (c backendpb.DNSServiceClient) {
+	tb.Helper()
+
+	sc, err := readScenario(writeScenario(tb, data))
+	require.NoError(tb, err)
+
+	lsnr := bufconn.Listen(1024 * 1024)
+
+	srv := grpc.NewServer()
+	l := slogutil.NewDiscardLogger()
+	backendpb.RegisterDNSServiceServer(srv, newMockDNSServiceServer(l, sc))
+
+	go func() {
+		_ = srv.Serve(lsnr)
+	}()
+	tb.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(
+		"passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (conn net.Conn, err error) {
+			return lsnr.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(tb, err)
+	testutil.CleanupAndRequireSuccess(tb, conn.Close)
+
+	return backendpb.NewDNSServiceClient(conn)
+}
+
+// getProfiles is a helper that requests the profiles and returns them along
+// with the next page token.
+func getProfiles(
+	tb testing.TB,
+	c backendpb.DNSServiceClient,
+	req *backendpb.DNSProfilesRequest,
+) (profiles []*backendpb.DNSProfile, nextPageToken string, err error) {
+	tb.Helper()
+
+	ctx := testutil.ContextWithTimeout(tb, testTimeout)
+	stream, err := c.GetDNSProfiles(ctx, req)
+	require.NoError(tb, err)
+
+	for {
+		var p *backendpb.DNSProfile
+		p, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, "", err
+		}
+
+		profiles = append(profiles, p)
+	}
+
+	tokens := stream.Trailer().Get("next_page_token")
+	if len(tokens) > 0 {
+		nextPageToken = tokens[0]
+	}
+
+	return profiles, nextPageToken, nil
+}
+
+func TestMockDNSServiceServer_GetDNSProfiles(t *testing.T) {
+	t.Parallel()
+
+	c := newTestDNSClient(
+		t,
+		"get_dns_profiles:\n"+
+			"  - error:\n"+
+			"      type: 'rate_limited'\n"+
+			"      retry_delay: '1s'\n"+
+			"  - profiles_num: 5\n"+
+			"    devices_num: 2\n"+
+			"    deleted_profiles_num: 1\n",
+	)
+
+	// A full synchronization, which is paged.
+	req := &backendpb.DNSProfilesRequest{
+		SyncTime: timestamppb.New(time.Time{}),
+		PageSize: 2,
+	}
+
+	_, _, err := getProfiles(t, c, req)
+	require.Error(t, err)
+
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+
+	assert.Equal(t, codes.ResourceExhausted, s.Code())
+
+	var all []*backendpb.DNSProfile
+	for range 3 {
+		var profiles []*backendpb.DNSProfile
+		profiles, req.PageToken, err = getProfiles(t, c, req)
+		require.NoError(t, err)
+
+		all = append(all, profiles...)
+	}
+
+	assert.Empty(t, req.PageToken)
+	require.Len(t, all, 5)
+
+	for i, p := range all {
+		assert.Len(t, p.Devices, 2)
+		assert.Equal(t, i == 4, p.Deleted)
+	}
+
+	// An incremental synchronization, which isn't paged.
+	req = &backendpb.DNSProfilesRequest{
+		SyncTime: timestamppb.Now(),
+		PageSize: 2,
+	}
+
+	profiles, nextPageToken, err := getProfiles(t, c, req)
+	require.NoError(t, err)
+
+	assert.Len(t, profiles, 5)
+	assert.Empty(t, nextPageToken)
+
+	// A bad page token.
+	req = &backendpb.DNSProfilesRequest{
+		SyncTime:  timestamppb.New(time.Time{}),
+		PageToken: "bad",
+		PageSize:  2,
+	}
+
+	_, _, err = getProfiles(t, c, req)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestMockDNSServiceServer_CreateDeviceByHumanId(t *testing.T) {
+	t.Parallel()
+
+	c := newTestDNSClient(
+		t,
+		"create_device_by_human_id:\n"+
+			"  - error:\n"+
+			"      type: 'device_quota_exceeded'\n"+
+			"  - delay: '1ms'\n",
+	)
+
+	ctx := testutil.ContextWithTimeout(t, testTimeout)
+	req := &backendpb.CreateDeviceRequest{}
+
+	_, err := c.CreateDeviceByHumanId(ctx, req)
+	require.Error(t, err)
+
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+
+	assert.Equal(t, codes.FailedPrecondition, s.Code())
+
+	details := s.Details()
+	require.Len(t, details, 1)
+
+	assert.IsType(t, &backendpb.DeviceQuotaExceededError{}, details[0])
+
+	resp, err := c.CreateDeviceByHumanId(ctx, req)
+	require.NoError(t, err)
+
+	assert.NotNil(t, resp.Device)
+}
+
+func TestMockDNSServiceServer_SaveDevicesBillingStat(t *testing.T) {
+	t.Parallel()
+
+	c := newTestDNSClient(
+		t,
+		"save_devices_billing_stat:\n"+
+			"  - error:\n"+
+			"      type: 'unavailable'\n"+
+			"  - delay: '1ms'\n",
+	)
+
+	send := func() (err error) {
+		ctx := testutil.ContextWithTimeout(t, testTimeout)
+		stream, err := c.SaveDevicesBillingStat(ctx)
+		require.NoError(t, err)
+
+		// Don't check the error, since the scripted error is only returned by
+		// CloseAndRecv.
+		_ = stream.Send(&backendpb.DeviceBillingStat{
+			DeviceId: "dev1234",
+		})
+
+		_, err = stream.CloseAndRecv()
+
+		return err
+	}
+
+	assert.Equal(t, codes.Unavailable, status.Code(send()))
+	assert.NoError(t, send())
+}
@@ -1,8 +1,12 @@
 // main implements a single mock GRPC server for backend services defined by
 // BILLSTAT_URL, PROFILES_URL, and REMOTE_KV_URL environment variables.
+//
+// The responses of the DNS service may be scripted using a YAML scenario file
+// passed with the -scenario flag.  See the scenario type.
 package main
 
 import (
+	"flag"
 	"net"
 	"os"
 
@@ -13,8 +17,18 @@ import (
 )
 
 func main() {
+	scenarioPath := flag.String("scenario", "", "path to the yaml scenario file")
+	flag.Parse()
+
 	l := slogutil.New(nil)
 
+	sc, err := readScenario(*scenarioPath)
+	if err != nil {
+		l.Error("reading scenario", slogutil.KeyError, err)
+
+		os.Exit(osutil.ExitCodeArgumentError)
+	}
+
 	const listenAddr = "localhost:6062"
 
 	lsnr, err := net.Listen("tcp", listenAddr)
@@ -25,7 +39,7 @@ func main() {
 	}
 
 	grpcSrv := grpc.NewServer()
-	dnsSrv := newMockDNSServiceServer(l.With(slogutil.KeyPrefix, "dns"))
+	dnsSrv := newMockDNSServiceServer(l.With(slogutil.KeyPrefix, "dns"), sc)
 	backendpb.RegisterDNSServiceServer(grpcSrv, dnsSrv)
 
 	kvSrv := newMockRemoteKVServiceServer(l.With(slogutil.KeyPrefix, "remote_kv"))
@@ -0,0 +1,382 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/backendpb"
+	"github.com/AdguardTeam/golibs/errors"
+	"github.com/AdguardTeam/golibs/timeutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"gopkg.in/yaml.v2"
+)
+
+// scenario is a script of the responses of the mock DNS service.  The steps of
+// each method are used in order, one step per call, and the last step is
+// repeated once all of them have been used.  A method without steps uses the
+// default responses.
+//
+// An example of a scenario file:
+//
+//	get_dns_profiles:
+//	  - error:
+//	      type: 'rate_limited'
+//	      retry_delay: '1s'
+//	  - profiles_num: 5
+//	    devices_num: 2
+//	  - delay: '3s'
+//	    profiles_num: 1
+//	    devices_num: 3
+//	    deleted_profiles_num: 1
+//	create_device_by_human_id:
+//	  - error:
+//	      type: 'device_quota_exceeded'
+type scenario struct {
+	// mu protects the step indexes.
+	mu *sync.Mutex
+
+	// GetDNSProfiles are the steps of the GetDNSProfiles method.
+	GetDNSProfiles []*profilesStep `yaml:"get_dns_profiles"`
+
+	// CreateDevice are the steps of the CreateDeviceByHumanId method.
+	CreateDevice []*step `yaml:"create_device_by_human_id"`
+
+	// SaveBillStat are the steps of the SaveDevicesBillingStat method.
+	SaveBillStat []*step `yaml:"save_devices_billing_stat"`
+
+	// profilesIdx, createDeviceIdx, and saveBillStatIdx are the indexes of the
+	// next steps of the corresponding methods.
+	profilesIdx     int
+	createDeviceIdx int
+	saveBillStatIdx int
+}
+
+// readScenario reads and validates the scenario from the file with the given
+// path.  If filePath is empty, s is an empty scenario, which uses the default
+// responses.
+func readScenario(filePath string) (s *scenario, err error) {
+	s = &scenario{
+		mu: &sync.Mutex{},
+	}
+
+	if filePath == "" {
+		return s, nil
+	}
+
+	// #nosec G304 -- Trust the path to the file, since it's given by the user
+	// of the script.
+	b, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("reading scenario: %w", err)
+	}
+
+	err = yaml.Unmarshal(b, s)
+	if err != nil {
+		return nil, fmt.Errorf("decoding scenario: %w", err)
+	}
+
+	err = s.validate()
+	if err != nil {
+		return nil, fmt.Errorf("validating scenario: %w", err)
+	}
+
+	return s, nil
+}
+
+// validate returns an error if the scenario is invalid.
+func (s *scenario) validate() (err error) {
+	var errs []error
+	for i, st := range s.GetDNSProfiles {
+		err = st.validate()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("get_dns_profiles: at index %d: %w", i, err))
+		}
+	}
+
+	for i, st := range s.CreateDevice {
+		err = st.validate()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("create_device_by_human_id: at index %d: %w", i, err))
+		}
+	}
+
+	for i, st := range s.SaveBillStat {
+		err = st.validate()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("save_devices_billing_stat: at index %d: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// nextStep returns the next step from steps using and advancing the index at
+// idxPtr.  It returns nil if there are no steps.  The lock protecting idxPtr
+// must be held.
+func nextStep[T any](steps []*T, idxPtr *int) (st *T) {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	i := min(*idxPtr, len(steps)-1)
+	*idxPtr = i + 1
+
+	return steps[i]
+}
+
+// profilesStep returns the next step of the GetDNSProfiles method or nil if
+// the default response should be used.
+func (s *scenario) profilesStep() (st *profilesStep) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return nextStep(s.GetDNSProfiles, &s.profilesIdx)
+}
+
+// createDeviceStep returns the next step of the CreateDeviceByHumanId method
+// or nil if the default response should be used.
+func (s *scenario) createDeviceStep() (st *step) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return nextStep(s.CreateDevice, &s.createDeviceIdx)
+}
+
+// saveBillStatStep returns the next step of the SaveDevicesBillingStat method
+// or nil if the default response should be used.
+func (s *scenario) saveBillStatStep() (st *step) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return nextStep(s.SaveBillStat, &s.saveBillStatIdx)
+}
+
+// step is a single scripted response of a method.
+type step struct {
+	// Error is the optional error to respond with.
+	Error *stepError `yaml:"error"`
+
+	// Delay is the duration to wait before responding.
+	Delay timeutil.Duration `yaml:"delay"`
+}
+
+// validate returns an error if the step is invalid.  st may be nil.
+func (st *step) validate() (err error) {
+	switch {
+	case st == nil:
+		return errors.ErrNoValue
+	case st.Delay.Duration < 0:
+		return fmt.Errorf("delay: %w: %s", errors.ErrNegative, st.Delay)
+	default:
+		return st.Error.validate()
+	}
+}
+
+// apply waits for the delay of the step and returns its error, if any.  st may
+// be nil.
+func (st *step) apply(ctx context.Context) (err error) {
+	if st == nil {
+		return nil
+	}
+
+	if st.Delay.Duration > 0 {
+		t := time.NewTimer(st.Delay.Duration)
+		defer t.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	return st.Error.toStatus()
+}
+
+// profilesStep is a single scripted response of the GetDNSProfiles method.
+type profilesStep struct {
+	step `yaml:",inline"`
+
+	// ProfilesNum is the number of the generated profiles to send.  If it is
+	// zero, the default profile is sent.
+	ProfilesNum int `yaml:"profiles_num"`
+
+	// DevicesNum is the number of the generated devices in each profile.  If
+	// it is zero, the default devices are used.
+	DevicesNum int `yaml:"devices_num"`
+
+	// DeletedProfilesNum is the number of the profiles, starting from the
+	// last one, that are sent as deleted.
+	DeletedProfilesNum int `yaml:"deleted_profiles_num"`
+}
+
+// validate returns an error if the step is invalid.  st may be nil.
+func (st *profilesStep) validate() (err error) {
+	switch {
+	case st == nil:
+		return errors.ErrNoValue
+	case st.ProfilesNum < 0:
+		return fmt.Errorf("profiles_num: %w: %d", errors.ErrNegative, st.ProfilesNum)
+	case st.DevicesNum < 0:
+		return fmt.Errorf("devices_num: %w: %d", errors.ErrNegative, st.DevicesNum)
+	case st.DeletedProfilesNum < 0 || st.DeletedProfilesNum > max(st.ProfilesNum, 1):
+		return fmt.Errorf(
+			"deleted_profiles_num: %w: %d",
+			errors.ErrOutOfRange,
+			st.DeletedProfilesNum,
+		)
+	default:
+		return st.step.validate()
+	}
+}
+
+// profiles returns the profiles to send according to the step.  st may be
+// nil.
+func (st *profilesStep) profiles() (profiles []*backendpb.DNSProfile) {
+	if st == nil || st.ProfilesNum == 0 {
+		profiles = []*backendpb.DNSProfile{newDNSProfile()}
+	} else {
+		profiles = make([]*backendpb.DNSProfile, 0, st.ProfilesNum)
+		for i := range st.ProfilesNum {
+			p := newDNSProfile()
+			p.DnsId = fmt.Sprintf("mock%04d", i)
+			profiles = append(profiles, p)
+		}
+	}
+
+	if st == nil {
+		return profiles
+	}
+
+	for _, p := range profiles {
+		if st.DevicesNum > 0 {
+			p.Devices = newDevices(p.DnsId, st.DevicesNum)
+		}
+	}
+
+	for _, p := range profiles[len(profiles)-st.DeletedProfilesNum:] {
+		p.Deleted = true
+	}
+
+	return profiles
+}
+
+// newDevices returns n generated devices for the profile with the given ID.
+func newDevices(profID string, n int) (devices []*backendpb.DeviceSettings) {
+	devices = make([]*backendpb.DeviceSettings, 0, n)
+	for i := range n {
+		devices = append(devices, &backendpb.DeviceSettings{
+			Id:               fmt.Sprintf("%sd%d", profID, i),
+			Name:             fmt.Sprintf("Device %d", i),
+			FilteringEnabled: true,
+		})
+	}
+
+	return devices
+}
+
+// stepErrorType is the type of a scripted error.
+type stepErrorType string
+
+// stepErrorType values.
+const (
+	stepErrorTypeAuthFailed          stepErrorType = "authentication_failed"
+	stepErrorTypeBadRequest          stepErrorType = "bad_request"
+	stepErrorTypeDeadlineExceeded    stepErrorType = "deadline_exceeded"
+	stepErrorTypeDeviceQuotaExceeded stepErrorType = "device_quota_exceeded"
+	stepErrorTypeInternal            stepErrorType = "internal"
+	stepErrorTypeRateLimited         stepErrorType = "rate_limited"
+	stepErrorTypeUnavailable         stepErrorType = "unavailable"
+)
+
+// stepError is a scripted error response.
+type stepError struct {
+	// Type is the type of the error.
+	Type stepErrorType `yaml:"type"`
+
+	// Message is the message of the error.  If it is empty, a message based on
+	// the type is used.
+	Message string `yaml:"message"`
+
+	// RetryDelay is the retry delay of a [stepErrorTypeRateLimited] error.
+	RetryDelay timeutil.Duration `yaml:"retry_delay"`
+}
+
+// validate returns an error if the error is invalid.  e may be nil.
+func (e *stepError) validate() (err error) {
+	if e == nil {
+		return nil
+	}
+
+	switch e.Type {
+	case
+		stepErrorTypeAuthFailed,
+		stepErrorTypeBadRequest,
+		stepErrorTypeDeadlineExceeded,
+		stepErrorTypeDeviceQuotaExceeded,
+		stepErrorTypeInternal,
+		stepErrorTypeRateLimited,
+		stepErrorTypeUnavailable:
+		return nil
+	default:
+		return fmt.Errorf("error: type: %w: %q", errors.ErrBadEnumValue, e.Type)
+	}
+}
+
+// toStatus returns the gRPC status error for e, including the structured error
+// details the backend sends.  e may be nil.
+func (e *stepError) toStatus() (err error) {
+	if e == nil {
+		return nil
+	}
+
+	msg := e.Message
+	if msg == "" {
+		msg = "mock " + string(e.Type) + " error"
+	}
+
+	var code codes.Code
+	var details protoadapt.MessageV1
+	switch e.Type {
+	case stepErrorTypeAuthFailed:
+		code = codes.Unauthenticated
+		details = &backendpb.AuthenticationFailedError{Message: msg}
+	case stepErrorTypeBadRequest:
+		code = codes.InvalidArgument
+		details = &backendpb.BadRequestError{Message: msg}
+	case stepErrorTypeDeadlineExceeded:
+		code = codes.DeadlineExceeded
+	case stepErrorTypeDeviceQuotaExceeded:
+		code = codes.FailedPrecondition
+		details = &backendpb.DeviceQuotaExceededError{Message: msg}
+	case stepErrorTypeRateLimited:
+		code = codes.ResourceExhausted
+		details = &backendpb.RateLimitedError{
+			Message:    msg,
+			RetryDelay: durationpb.New(e.RetryDelay.Duration),
+		}
+	case stepErrorTypeUnavailable:
+		code = codes.Unavailable
+	default:
+		code = codes.Internal
+	}
+
+	s := status.New(code, msg)
+	if details == nil {
+		return s.Err()
+	}
+
+	s, err = s.WithDetails(details)
+	if err != nil {
+		// Should never happen, since the details are always valid.
+		panic(fmt.Errorf("adding error details: %w", err))
+	}
+
+	return s.Err()
+}
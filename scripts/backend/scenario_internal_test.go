@@ -0,0 +1,210 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/backendpb"
+	"github.com/AdguardTeam/golibs/testutil"
+	"github.com/AdguardTeam/golibs/timeutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// writeScenario is a helper that writes data into a scenario file and returns
+// its path.
+func writeScenario(tb testing.TB, data string) (filePath string) {
+	tb.Helper()
+
+	filePath = filepath.Join(tb.TempDir(), "scenario.yaml")
+	err := os.WriteFile(filePath, []byte(data), 0o600)
+	require.NoError(tb, err)
+
+	return filePath
+}
+
+func TestReadScenario(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name       string
+		data       string
+		wantErrMsg string
+	}{{
+		name: "valid",
+		data: "get_dns_profiles:\n" +
+			"  - error:\n" +
+			"      type: 'rate_limited'\n" +
+			"      retry_delay: '1s'\n" +
+			"  - profiles_num: 5\n" +
+			"    devices_num: 2\n" +
+			"    deleted_profiles_num: 1\n" +
+			"create_device_by_human_id:\n" +
+			"  - error:\n" +
+			"      type: 'device_quota_exceeded'\n" +
+			"save_devices_billing_stat:\n" +
+			"  - delay: '1s'\n",
+		wantErrMsg: "",
+	}, {
+		name:       "empty",
+		data:       "",
+		wantErrMsg: "",
+	}, {
+		name: "bad_error_type",
+		data: "create_device_by_human_id:\n" +
+			"  - error:\n" +
+			"      type: 'bad'\n",
+		wantErrMsg: "validating scenario: create_device_by_human_id: at index 0: " +
+			`error: type: bad enum value: "bad"`,
+	}, {
+		name: "negative_delay",
+		data: "save_devices_billing_stat:\n" +
+			"  - delay: '-1s'\n",
+		wantErrMsg: "validating scenario: save_devices_billing_stat: at index 0: " +
+			"delay: negative value: -1s",
+	}, {
+		name: "too_many_deleted",
+		data: "get_dns_profiles:\n" +
+			"  - profiles_num: 2\n" +
+			"    deleted_profiles_num: 3\n",
+		wantErrMsg: "validating scenario: get_dns_profiles: at index 0: " +
+			"deleted_profiles_num: out of range: 3",
+	}, {
+		name: "several_errors",
+		data: "get_dns_profiles:\n" +
+			"  - profiles_num: -1\n" +
+			"create_device_by_human_id:\n" +
+			"  - \n",
+		wantErrMsg: "validating scenario: get_dns_profiles: at index 0: " +
+			"profiles_num: negative value: -1\n" +
+			"create_device_by_human_id: at index 0: no value",
+	}, {
+		name: "bad_yaml",
+		data: "get_dns_profiles: 1\n",
+		wantErrMsg: "decoding scenario: yaml: unmarshal errors:\n  line 1: cannot " +
+			"unmarshal !!int `1` into []*main.profilesStep",
+	}}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := readScenario(writeScenario(t, tc.data))
+			testutil.AssertErrorMsg(t, tc.wantErrMsg, err)
+		})
+	}
+}
+
+func TestReadScenario_noFile(t *testing.T) {
+	t.Parallel()
+
+	s, err := readScenario("")
+	require.NoError(t, err)
+
+	assert.Nil(t, s.profilesStep())
+	assert.Nil(t, s.createDeviceStep())
+	assert.Nil(t, s.saveBillStatStep())
+
+	_, err = readScenario(filepath.Join(t.TempDir(), "scenario.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestScenario_steps(t *testing.T) {
+	t.Parallel()
+
+	s, err := readScenario(writeScenario(
+		t,
+		"create_device_by_human_id:\n"+
+			"  - error:\n"+
+			"      type: 'internal'\n"+
+			"  - delay: '1ms'\n",
+	))
+	require.NoError(t, err)
+
+	st := s.createDeviceStep()
+	require.NotNil(t, st)
+	require.NotNil(t, st.Error)
+
+	assert.Equal(t, stepErrorTypeInternal, st.Error.Type)
+
+	// The last step is repeated.
+	for range 2 {
+		st = s.createDeviceStep()
+		require.NotNil(t, st)
+
+		assert.Nil(t, st.Error)
+		assert.Equal(t, time.Millisecond, st.Delay.Duration)
+	}
+
+	// Other methods use the default responses.
+	assert.Nil(t, s.profilesStep())
+}
+
+func TestProfilesStep_profiles(t *testing.T) {
+	t.Parallel()
+
+	var nilStep *profilesStep
+	profiles := nilStep.profiles()
+	require.Len(t, profiles, 1)
+
+	assert.False(t, profiles[0].Deleted)
+
+	st := &profilesStep{
+		ProfilesNum:        3,
+		DevicesNum:         2,
+		DeletedProfilesNum: 1,
+	}
+
+	profiles = st.profiles()
+	require.Len(t, profiles, 3)
+
+	for i, p := range profiles {
+		assert.Len(t, p.Devices, 2)
+		assert.Equal(t, i == 2, p.Deleted)
+	}
+
+	assert.Equal(t, "mock0000", profiles[0].DnsId)
+	assert.Equal(t, "mock0000d1", profiles[0].Devices[1].Id)
+}
+
+func TestStepError_toStatus(t *testing.T) {
+	t.Parallel()
+
+	var nilErr *stepError
+	require.NoError(t, nilErr.toStatus())
+
+	e := &stepError{
+		Type:       stepErrorTypeRateLimited,
+		RetryDelay: timeutil.Duration{Duration: time.Second},
+	}
+
+	s, ok := status.FromError(e.toStatus())
+	require.True(t, ok)
+
+	assert.Equal(t, codes.ResourceExhausted, s.Code())
+	assert.Equal(t, "mock rate_limited error", s.Message())
+
+	details := s.Details()
+	require.Len(t, details, 1)
+
+	rlErr, ok := details[0].(*backendpb.RateLimitedError)
+	require.True(t, ok)
+
+	assert.Equal(t, time.Second, rlErr.RetryDelay.AsDuration())
+
+	e = &stepError{
+		Type:    stepErrorTypeUnavailable,
+		Message: "custom",
+	}
+
+	s, ok = status.FromError(e.toStatus())
+	require.True(t, ok)
+
+	assert.Equal(t, codes.Unavailable, s.Code())
+	assert.Equal(t, "custom", s.Message())
+	assert.Empty(t, s.Details())
+}
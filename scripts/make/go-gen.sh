@@ -44,6 +44,11 @@ readonly go
 	protoc --go_opt=paths=source_relative --go_out=. ./filecache.proto
 )
 
+(
+	cd ./internal/querylog/querylogpb/
+	protoc --go_opt=paths=source_relative --go_out=. ./querylog.proto
+)
+
 (
 	cd ./internal/backendpb/
 	protoc \
@@ -21,9 +21,11 @@ fi
 run_linter -e shfmt --binary-next-line -d -p -s \
 	./scripts/hooks/* \
 	./scripts/make/*.sh \
+	./scripts/xdpfilter/*.sh \
 	;
 
 shellcheck -e 'SC2250' -f 'gcc' -o 'all' -x -- \
 	./scripts/hooks/* \
 	./scripts/make/*.sh \
+	./scripts/xdpfilter/*.sh \
 	;
@@ -0,0 +1,66 @@
+// querylogredact redacts the entries of a profile or a device from the query
+// log files in place.  It is used to fulfill data-subject deletion requests.
+//
+// Usage:
+//
+//	go run ./scripts/querylogredact -profile ID [-device ID] [-mode MODE] FILE...
+//
+// The files must be uncompressed.  For each file, an index sidecar file with
+// the extension ".idx" is created or updated to speed up the following
+// redactions.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/AdguardTeam/AdGuardDNS/internal/agd"
+	"github.com/AdguardTeam/AdGuardDNS/internal/querylog"
+	"github.com/AdguardTeam/golibs/logutil/slogutil"
+	"github.com/AdguardTeam/golibs/osutil"
+)
+
+func main() {
+	l := slogutil.New(nil)
+
+	profID := flag.String("profile", "", "id of the profile the entries of which to redact")
+	devID := flag.String("device", "", "id of the device the entries of which to redact")
+	mode := flag.String(
+		"mode",
+		string(querylog.RedactModeDelete),
+		fmt.Sprintf(
+			"redaction mode: %q or %q",
+			querylog.RedactModeDelete,
+			querylog.RedactModeAnonymize,
+		),
+	)
+	flag.Parse()
+
+	if *profID == "" || flag.NArg() == 0 {
+		flag.Usage()
+
+		os.Exit(osutil.ExitCodeArgumentError)
+	}
+
+	c := &querylog.RedactConfig{
+		ProfileID: agd.ProfileID(*profID),
+		DeviceID:  agd.DeviceID(*devID),
+		Mode:      querylog.RedactMode(*mode),
+	}
+
+	code := osutil.ExitCodeSuccess
+	for _, path := range flag.Args() {
+		n, err := querylog.Redact(path, c)
+		if err != nil {
+			l.Error("redacting", "path", path, "redacted", n, slogutil.KeyError, err)
+			code = osutil.ExitCodeFailure
+
+			continue
+		}
+
+		l.Info("redacted", "path", path, "redacted", n)
+	}
+
+	os.Exit(code)
+}
@@ -0,0 +1,64 @@
+#!/bin/sh
+
+# This is the reference loader of the XDP filter of AdGuard DNS.  It compiles
+# xdpfilter.c, loads it, pins the program and its maps into the BPF file
+# system, and attaches the program to the network interface.
+#
+# Usage:
+#
+#	IFACE='eth0' sh ./scripts/xdpfilter/load.sh
+#
+# After that, set ratelimit.xdp_filter.bans_map_path and
+# ratelimit.xdp_filter.drops_map_path in the configuration file to
+# "${PIN_DIR}/agdns_bans" and "${PIN_DIR}/agdns_drops" respectively.
+#
+# To detach the program and remove the pinned objects, run the script with
+# ACTION set to "unload".
+
+verbose="${VERBOSE:-0}"
+readonly verbose
+
+if [ "$verbose" -gt '0' ]
+then
+	set -x
+fi
+
+set -e -f -u
+
+action="${ACTION:-load}"
+clang="${CLANG:-clang}"
+iface="${IFACE:?please set IFACE}"
+mode="${XDP_MODE:-xdp}"
+pin_dir="${PIN_DIR:-/sys/fs/bpf/agdns}"
+readonly action clang iface mode pin_dir
+
+src_dir="$( dirname "$0" )"
+obj="${TMPDIR:-/tmp}/agdns_xdpfilter.o"
+readonly src_dir obj
+
+case "$action"
+in
+('load')
+	"$clang"\
+		-O2\
+		-g\
+		-target bpf\
+		-c "${src_dir}/xdpfilter.c"\
+		-o "$obj"\
+		;
+
+	mkdir -p "$pin_dir"
+
+	bpftool prog load "$obj" "${pin_dir}/prog" type xdp pinmaps "$pin_dir"
+	ip link set dev "$iface" "$mode" pinned "${pin_dir}/prog"
+	;;
+('unload')
+	ip link set dev "$iface" "$mode" off
+	rm -f -r "$pin_dir"
+	;;
+(*)
+	echo "bad action: ${action}" 1>&2
+
+	exit 1
+	;;
+esac
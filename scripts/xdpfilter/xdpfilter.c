@@ -0,0 +1,127 @@
+// This is the reference XDP program for the XDP filter of AdGuard DNS.  It
+// drops the UDP and TCP packets to port 53 from the subnets in the bans map and
+// counts them in the drops map.  AdGuard DNS updates the bans map with the
+// subnets in the rate-limiting back off.
+//
+// Keep the map definitions in sync with package internal/xdpfilter.
+
+#include <linux/bpf.h>
+#include <linux/if_ether.h>
+#include <linux/in.h>
+#include <linux/ip.h>
+#include <linux/ipv6.h>
+#include <linux/tcp.h>
+#include <linux/udp.h>
+
+#include <bpf/bpf_endian.h>
+#include <bpf/bpf_helpers.h>
+
+#define DNS_PORT 53
+#define MAX_BANS 65536
+
+// ban_key is the key of the bans map.  IPv4 addresses are stored as
+// IPv4-mapped IPv6 ones.
+struct ban_key {
+	__u32 prefixlen;
+	__u8 addr[16];
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_LPM_TRIE);
+	__uint(map_flags, BPF_F_NO_PREALLOC);
+	__uint(max_entries, MAX_BANS);
+	__type(key, struct ban_key);
+	__type(value, __u8);
+} agdns_bans SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_ARRAY);
+	__uint(max_entries, 1);
+	__type(key, __u32);
+	__type(value, __u64);
+} agdns_drops SEC(".maps");
+
+// is_dns_port returns true if the transport header at l4 of the protocol proto
+// has the destination port of DNS.
+static __always_inline int is_dns_port(void *l4, void *end, __u8 proto) {
+	if (proto == IPPROTO_UDP) {
+		struct udphdr *udp = l4;
+		if ((void *)(udp + 1) > end) {
+			return 0;
+		}
+
+		return udp->dest == bpf_htons(DNS_PORT);
+	} else if (proto == IPPROTO_TCP) {
+		struct tcphdr *tcp = l4;
+		if ((void *)(tcp + 1) > end) {
+			return 0;
+		}
+
+		return tcp->dest == bpf_htons(DNS_PORT);
+	}
+
+	return 0;
+}
+
+SEC("xdp")
+int agdns_xdp_filter(struct xdp_md *ctx) {
+	void *data = (void *)(long)ctx->data;
+	void *end = (void *)(long)ctx->data_end;
+
+	struct ethhdr *eth = data;
+	if ((void *)(eth + 1) > end) {
+		return XDP_PASS;
+	}
+
+	struct ban_key key = {0};
+	void *l4;
+	__u8 proto;
+
+	if (eth->h_proto == bpf_htons(ETH_P_IP)) {
+		struct iphdr *ip = (void *)(eth + 1);
+		if ((void *)(ip + 1) > end) {
+			return XDP_PASS;
+		}
+
+		key.prefixlen = 128;
+		key.addr[10] = 0xff;
+		key.addr[11] = 0xff;
+		__builtin_memcpy(&key.addr[12], &ip->saddr, 4);
+
+		l4 = (void *)ip + ip->ihl * 4;
+		proto = ip->protocol;
+	} else if (eth->h_proto == bpf_htons(ETH_P_IPV6)) {
+		struct ipv6hdr *ip6 = (void *)(eth + 1);
+		if ((void *)(ip6 + 1) > end) {
+			return XDP_PASS;
+		}
+
+		key.prefixlen = 128;
+		__builtin_memcpy(key.addr, &ip6->saddr, 16);
+
+		// Extension headers aren't supported, since the DNS clients don't
+		// normally send them.
+		l4 = (void *)(ip6 + 1);
+		proto = ip6->nexthdr;
+	} else {
+		return XDP_PASS;
+	}
+
+	if (!is_dns_port(l4, end, proto)) {
+		return XDP_PASS;
+	}
+
+	if (!bpf_map_lookup_elem(&agdns_bans, &key)) {
+		return XDP_PASS;
+	}
+
+	__u32 zero = 0;
+	__u64 *drops = bpf_map_lookup_elem(&agdns_drops, &zero);
+	if (drops) {
+		__sync_fetch_and_add(drops, 1);
+	}
+
+	return XDP_DROP;
+}
+
+char _license[] SEC("license") = "GPL";